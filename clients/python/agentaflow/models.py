@@ -0,0 +1,887 @@
+# Code generated by pyclientgen from api/openapi/dashboard.yaml and api/proto/agentaflow/serving/v1/inference.proto. DO NOT EDIT.
+"""Models of the AgentaFlow Dashboard API v1."""
+
+from __future__ import annotations
+
+from dataclasses import dataclass, fields, is_dataclass
+from datetime import datetime, timezone
+import re
+from typing import Any, Callable, Dict, List, Optional
+
+_FRACTION = re.compile(r"\.(\d+)")
+
+
+def _parse_time(value: str) -> datetime:
+    """Parses an RFC 3339 timestamp, with fractions of a second padded or
+    truncated to the microseconds datetime supports."""
+    value = _FRACTION.sub(lambda match: "." + match.group(1)[:6].ljust(6, "0"), value, count=1)
+    if value.endswith("Z"):
+        value = value[:-1] + "+00:00"
+    return datetime.fromisoformat(value)
+
+
+def _identity(value: Any) -> Any:
+    return value
+
+
+def _list_of(decode: Callable[[Any], Any]) -> Callable[[Any], List[Any]]:
+    return lambda values: [decode(v) for v in values]
+
+
+def _map_of(decode: Callable[[Any], Any]) -> Callable[[Any], Dict[str, Any]]:
+    return lambda values: {k: decode(v) for k, v in values.items()}
+
+
+def _decode(decode: Callable[[Any], Any], value: Any) -> Any:
+    return None if value is None else decode(value)
+
+
+def _encode(value: Any) -> Any:
+    """Converts models, timestamps and containers of them to JSON values."""
+    if is_dataclass(value):
+        return value.to_dict()
+    if isinstance(value, datetime):
+        if value.tzinfo is None:
+            value = value.replace(tzinfo=timezone.utc)
+        return value.isoformat()
+    if isinstance(value, list):
+        return [_encode(v) for v in value]
+    if isinstance(value, dict):
+        return {k: _encode(v) for k, v in value.items()}
+    return value
+
+
+@dataclass
+class GPUMetrics:
+    gpu_id: Optional[str] = None
+    node_id: Optional[str] = None
+    name: Optional[str] = None
+    utilization_gpu: Optional[float] = None
+    utilization_memory: Optional[float] = None
+    memory_total: Optional[int] = None
+    memory_used: Optional[int] = None
+    memory_free: Optional[int] = None
+    temperature: Optional[float] = None
+    power_draw: Optional[float] = None
+    power_limit: Optional[float] = None
+    fan_speed: Optional[float] = None
+    clock_graphics: Optional[int] = None
+    clock_memory: Optional[int] = None
+    process_count: Optional[int] = None
+    encoder_sessions: Optional[int] = None
+    decoder_sessions: Optional[int] = None
+    encoder_utilization: Optional[float] = None
+    decoder_utilization: Optional[float] = None
+    timestamp: Optional[datetime] = None
+    mps_shared: Optional[bool] = None
+    mps_clients: Optional[List[Dict[str, Any]]] = None
+    virtualization_mode: Optional[str] = None
+    vgpu_profile: Optional[str] = None
+    unavailable: Optional[List[str]] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "GPUMetrics":
+        return cls(
+            gpu_id=_decode(str, data.get("gpu_id")),
+            node_id=_decode(str, data.get("node_id")),
+            name=_decode(str, data.get("name")),
+            utilization_gpu=_decode(float, data.get("utilization_gpu")),
+            utilization_memory=_decode(float, data.get("utilization_memory")),
+            memory_total=_decode(int, data.get("memory_total")),
+            memory_used=_decode(int, data.get("memory_used")),
+            memory_free=_decode(int, data.get("memory_free")),
+            temperature=_decode(float, data.get("temperature")),
+            power_draw=_decode(float, data.get("power_draw")),
+            power_limit=_decode(float, data.get("power_limit")),
+            fan_speed=_decode(float, data.get("fan_speed")),
+            clock_graphics=_decode(int, data.get("clock_graphics")),
+            clock_memory=_decode(int, data.get("clock_memory")),
+            process_count=_decode(int, data.get("process_count")),
+            encoder_sessions=_decode(int, data.get("encoder_sessions")),
+            decoder_sessions=_decode(int, data.get("decoder_sessions")),
+            encoder_utilization=_decode(float, data.get("encoder_utilization")),
+            decoder_utilization=_decode(float, data.get("decoder_utilization")),
+            timestamp=_decode(_parse_time, data.get("timestamp")),
+            mps_shared=_decode(bool, data.get("mps_shared")),
+            mps_clients=_decode(_list_of(_identity), data.get("mps_clients")),
+            virtualization_mode=_decode(str, data.get("virtualization_mode")),
+            vgpu_profile=_decode(str, data.get("vgpu_profile")),
+            unavailable=_decode(_list_of(str), data.get("unavailable")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "gpu_id": "gpu_id",
+            "node_id": "node_id",
+            "name": "name",
+            "utilization_gpu": "utilization_gpu",
+            "utilization_memory": "utilization_memory",
+            "memory_total": "memory_total",
+            "memory_used": "memory_used",
+            "memory_free": "memory_free",
+            "temperature": "temperature",
+            "power_draw": "power_draw",
+            "power_limit": "power_limit",
+            "fan_speed": "fan_speed",
+            "clock_graphics": "clock_graphics",
+            "clock_memory": "clock_memory",
+            "process_count": "process_count",
+            "encoder_sessions": "encoder_sessions",
+            "decoder_sessions": "decoder_sessions",
+            "encoder_utilization": "encoder_utilization",
+            "decoder_utilization": "decoder_utilization",
+            "timestamp": "timestamp",
+            "mps_shared": "mps_shared",
+            "mps_clients": "mps_clients",
+            "virtualization_mode": "virtualization_mode",
+            "vgpu_profile": "vgpu_profile",
+            "unavailable": "unavailable",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class Distribution:
+    count: Optional[int] = None
+    mean: Optional[float] = None
+    min: Optional[float] = None
+    max: Optional[float] = None
+    p50: Optional[float] = None
+    p90: Optional[float] = None
+    p99: Optional[float] = None
+    gini: Optional[float] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "Distribution":
+        return cls(
+            count=_decode(int, data.get("count")),
+            mean=_decode(float, data.get("mean")),
+            min=_decode(float, data.get("min")),
+            max=_decode(float, data.get("max")),
+            p50=_decode(float, data.get("p50")),
+            p90=_decode(float, data.get("p90")),
+            p99=_decode(float, data.get("p99")),
+            gini=_decode(float, data.get("gini")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "count": "count",
+            "mean": "mean",
+            "min": "min",
+            "max": "max",
+            "p50": "p50",
+            "p90": "p90",
+            "p99": "p99",
+            "gini": "gini",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class SystemStats:
+    total_gpus: Optional[int] = None
+    active_gpus: Optional[int] = None
+    average_utilization: Optional[float] = None
+    total_memory_gb: Optional[float] = None
+    used_memory_gb: Optional[float] = None
+    average_temperature: Optional[float] = None
+    total_power_watts: Optional[float] = None
+    efficiency_score: Optional[float] = None
+    utilization_distribution: Optional[Distribution] = None
+    memory_distribution: Optional[Distribution] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "SystemStats":
+        return cls(
+            total_gpus=_decode(int, data.get("total_gpus")),
+            active_gpus=_decode(int, data.get("active_gpus")),
+            average_utilization=_decode(float, data.get("average_utilization")),
+            total_memory_gb=_decode(float, data.get("total_memory_gb")),
+            used_memory_gb=_decode(float, data.get("used_memory_gb")),
+            average_temperature=_decode(float, data.get("average_temperature")),
+            total_power_watts=_decode(float, data.get("total_power_watts")),
+            efficiency_score=_decode(float, data.get("efficiency_score")),
+            utilization_distribution=_decode(Distribution.from_dict, data.get("utilization_distribution")),
+            memory_distribution=_decode(Distribution.from_dict, data.get("memory_distribution")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "total_gpus": "total_gpus",
+            "active_gpus": "active_gpus",
+            "average_utilization": "average_utilization",
+            "total_memory_gb": "total_memory_gb",
+            "used_memory_gb": "used_memory_gb",
+            "average_temperature": "average_temperature",
+            "total_power_watts": "total_power_watts",
+            "efficiency_score": "efficiency_score",
+            "utilization_distribution": "utilization_distribution",
+            "memory_distribution": "memory_distribution",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class CostSummary:
+    total_cost: Optional[float] = None
+    period: Optional[str] = None
+    currency: Optional[str] = None
+    gpu_hours: Optional[float] = None
+    avg_cost_per_hr: Optional[float] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "CostSummary":
+        return cls(
+            total_cost=_decode(float, data.get("total_cost")),
+            period=_decode(str, data.get("period")),
+            currency=_decode(str, data.get("currency")),
+            gpu_hours=_decode(float, data.get("gpu_hours")),
+            avg_cost_per_hr=_decode(float, data.get("avg_cost_per_hr")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "total_cost": "total_cost",
+            "period": "period",
+            "currency": "currency",
+            "gpu_hours": "gpu_hours",
+            "avg_cost_per_hr": "avg_cost_per_hr",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class Alert:
+    id: Optional[str] = None
+    level: Optional[str] = None
+    message: Optional[str] = None
+    source: Optional[str] = None
+    tenants: Optional[List[str]] = None
+    timestamp: Optional[datetime] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "Alert":
+        return cls(
+            id=_decode(str, data.get("id")),
+            level=_decode(str, data.get("level")),
+            message=_decode(str, data.get("message")),
+            source=_decode(str, data.get("source")),
+            tenants=_decode(_list_of(str), data.get("tenants")),
+            timestamp=_decode(_parse_time, data.get("timestamp")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "id": "id",
+            "level": "level",
+            "message": "message",
+            "source": "source",
+            "tenants": "tenants",
+            "timestamp": "timestamp",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class OptimizationTip:
+    type: Optional[str] = None
+    message: Optional[str] = None
+    impact: Optional[str] = None
+    savings: Optional[float] = None
+    action: Optional[str] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "OptimizationTip":
+        return cls(
+            type=_decode(str, data.get("type")),
+            message=_decode(str, data.get("message")),
+            impact=_decode(str, data.get("impact")),
+            savings=_decode(float, data.get("savings")),
+            action=_decode(str, data.get("action")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "type": "type",
+            "message": "message",
+            "impact": "impact",
+            "savings": "savings",
+            "action": "action",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class PerformanceMetrics:
+    utilization_trend: Optional[float] = None
+    cost_trend: Optional[float] = None
+    efficiency_trend: Optional[float] = None
+    predicted_cost_24h: Optional[float] = None
+    optimization_tips: Optional[List[OptimizationTip]] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "PerformanceMetrics":
+        return cls(
+            utilization_trend=_decode(float, data.get("utilization_trend")),
+            cost_trend=_decode(float, data.get("cost_trend")),
+            efficiency_trend=_decode(float, data.get("efficiency_trend")),
+            predicted_cost_24h=_decode(float, data.get("predicted_cost_24h")),
+            optimization_tips=_decode(_list_of(OptimizationTip.from_dict), data.get("optimization_tips")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "utilization_trend": "utilization_trend",
+            "cost_trend": "cost_trend",
+            "efficiency_trend": "efficiency_trend",
+            "predicted_cost_24h": "predicted_cost_24h",
+            "optimization_tips": "optimization_tips",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class Incident:
+    id: Optional[str] = None
+    key: Optional[str] = None
+    labels: Optional[Dict[str, str]] = None
+    title: Optional[str] = None
+    severity: Optional[str] = None
+    status: Optional[str] = None
+    alerts: Optional[List[Dict[str, Any]]] = None
+    alert_count: Optional[int] = None
+    gpu_count: Optional[int] = None
+    started_at: Optional[datetime] = None
+    updated_at: Optional[datetime] = None
+    resolved_at: Optional[datetime] = None
+    acked_by: Optional[str] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "Incident":
+        return cls(
+            id=_decode(str, data.get("id")),
+            key=_decode(str, data.get("key")),
+            labels=_decode(_map_of(str), data.get("labels")),
+            title=_decode(str, data.get("title")),
+            severity=_decode(str, data.get("severity")),
+            status=_decode(str, data.get("status")),
+            alerts=_decode(_list_of(_identity), data.get("alerts")),
+            alert_count=_decode(int, data.get("alert_count")),
+            gpu_count=_decode(int, data.get("gpu_count")),
+            started_at=_decode(_parse_time, data.get("started_at")),
+            updated_at=_decode(_parse_time, data.get("updated_at")),
+            resolved_at=_decode(_parse_time, data.get("resolved_at")),
+            acked_by=_decode(str, data.get("acked_by")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "id": "id",
+            "key": "key",
+            "labels": "labels",
+            "title": "title",
+            "severity": "severity",
+            "status": "status",
+            "alerts": "alerts",
+            "alert_count": "alert_count",
+            "gpu_count": "gpu_count",
+            "started_at": "started_at",
+            "updated_at": "updated_at",
+            "resolved_at": "resolved_at",
+            "acked_by": "acked_by",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class IncidentChange:
+    change: Optional[str] = None
+    incident: Optional[Incident] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "IncidentChange":
+        return cls(
+            change=_decode(str, data.get("change")),
+            incident=_decode(Incident.from_dict, data.get("incident")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "change": "change",
+            "incident": "incident",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class IncidentList:
+    incidents: Optional[List[Incident]] = None
+    count: Optional[int] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "IncidentList":
+        return cls(
+            incidents=_decode(_list_of(Incident.from_dict), data.get("incidents")),
+            count=_decode(int, data.get("count")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "incidents": "incidents",
+            "count": "count",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class DashboardMetrics:
+    timestamp: Optional[datetime] = None
+    gpu_metrics: Optional[Dict[str, GPUMetrics]] = None
+    system_stats: Optional[SystemStats] = None
+    cost_data: Optional[CostSummary] = None
+    alerts: Optional[List[Alert]] = None
+    incidents: Optional[List[Incident]] = None
+    performance: Optional[PerformanceMetrics] = None
+    removed_gpus: Optional[List[str]] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "DashboardMetrics":
+        return cls(
+            timestamp=_decode(_parse_time, data.get("timestamp")),
+            gpu_metrics=_decode(_map_of(GPUMetrics.from_dict), data.get("gpu_metrics")),
+            system_stats=_decode(SystemStats.from_dict, data.get("system_stats")),
+            cost_data=_decode(CostSummary.from_dict, data.get("cost_data")),
+            alerts=_decode(_list_of(Alert.from_dict), data.get("alerts")),
+            incidents=_decode(_list_of(Incident.from_dict), data.get("incidents")),
+            performance=_decode(PerformanceMetrics.from_dict, data.get("performance")),
+            removed_gpus=_decode(_list_of(str), data.get("removed_gpus")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "timestamp": "timestamp",
+            "gpu_metrics": "gpu_metrics",
+            "system_stats": "system_stats",
+            "cost_data": "cost_data",
+            "alerts": "alerts",
+            "incidents": "incidents",
+            "performance": "performance",
+            "removed_gpus": "removed_gpus",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class GPUSummary:
+    id: Optional[str] = None
+    name: Optional[str] = None
+    status: Optional[str] = None
+    utilization: Optional[float] = None
+    temperature: Optional[float] = None
+    memory_total: Optional[int] = None
+    memory_used: Optional[int] = None
+    power_draw: Optional[float] = None
+    last_updated: Optional[datetime] = None
+    labels: Optional[Dict[str, str]] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "GPUSummary":
+        return cls(
+            id=_decode(str, data.get("id")),
+            name=_decode(str, data.get("name")),
+            status=_decode(str, data.get("status")),
+            utilization=_decode(float, data.get("utilization")),
+            temperature=_decode(float, data.get("temperature")),
+            memory_total=_decode(int, data.get("memory_total")),
+            memory_used=_decode(int, data.get("memory_used")),
+            power_draw=_decode(float, data.get("power_draw")),
+            last_updated=_decode(_parse_time, data.get("last_updated")),
+            labels=_decode(_map_of(str), data.get("labels")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "id": "id",
+            "name": "name",
+            "status": "status",
+            "utilization": "utilization",
+            "temperature": "temperature",
+            "memory_total": "memory_total",
+            "memory_used": "memory_used",
+            "power_draw": "power_draw",
+            "last_updated": "last_updated",
+            "labels": "labels",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class GPUList:
+    gpus: Optional[List[GPUSummary]] = None
+    total: Optional[int] = None
+    timestamp: Optional[datetime] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "GPUList":
+        return cls(
+            gpus=_decode(_list_of(GPUSummary.from_dict), data.get("gpus")),
+            total=_decode(int, data.get("total")),
+            timestamp=_decode(_parse_time, data.get("timestamp")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "gpus": "gpus",
+            "total": "total",
+            "timestamp": "timestamp",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class GPUHistoryPoint:
+    timestamp: Optional[datetime] = None
+    utilization: Optional[float] = None
+    temperature: Optional[float] = None
+    memory_used: Optional[int] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "GPUHistoryPoint":
+        return cls(
+            timestamp=_decode(_parse_time, data.get("timestamp")),
+            utilization=_decode(float, data.get("utilization")),
+            temperature=_decode(float, data.get("temperature")),
+            memory_used=_decode(int, data.get("memory_used")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "timestamp": "timestamp",
+            "utilization": "utilization",
+            "temperature": "temperature",
+            "memory_used": "memory_used",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class GPUHistory:
+    gpu_id: Optional[str] = None
+    since: Optional[datetime] = None
+    history: Optional[List[GPUHistoryPoint]] = None
+    count: Optional[int] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "GPUHistory":
+        return cls(
+            gpu_id=_decode(str, data.get("gpu_id")),
+            since=_decode(_parse_time, data.get("since")),
+            history=_decode(_list_of(GPUHistoryPoint.from_dict), data.get("history")),
+            count=_decode(int, data.get("count")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "gpu_id": "gpu_id",
+            "since": "since",
+            "history": "history",
+            "count": "count",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class WorkloadInfo:
+    id: Optional[str] = None
+    name: Optional[str] = None
+    tenant: Optional[str] = None
+    status: Optional[str] = None
+    class_: Optional[str] = None
+    priority: Optional[int] = None
+    effective_priority: Optional[int] = None
+    memory_required_mb: Optional[int] = None
+    encoder_sessions: Optional[int] = None
+    decoder_sessions: Optional[int] = None
+    assigned_gpu: Optional[str] = None
+    submitted_at: Optional[datetime] = None
+    started_at: Optional[datetime] = None
+    wait_seconds: Optional[float] = None
+    pool: Optional[str] = None
+    labels: Optional[Dict[str, str]] = None
+    selector: Optional[Dict[str, str]] = None
+    artifacts: Optional[List[Dict[str, Any]]] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "WorkloadInfo":
+        return cls(
+            id=_decode(str, data.get("id")),
+            name=_decode(str, data.get("name")),
+            tenant=_decode(str, data.get("tenant")),
+            status=_decode(str, data.get("status")),
+            class_=_decode(str, data.get("class")),
+            priority=_decode(int, data.get("priority")),
+            effective_priority=_decode(int, data.get("effective_priority")),
+            memory_required_mb=_decode(int, data.get("memory_required_mb")),
+            encoder_sessions=_decode(int, data.get("encoder_sessions")),
+            decoder_sessions=_decode(int, data.get("decoder_sessions")),
+            assigned_gpu=_decode(str, data.get("assigned_gpu")),
+            submitted_at=_decode(_parse_time, data.get("submitted_at")),
+            started_at=_decode(_parse_time, data.get("started_at")),
+            wait_seconds=_decode(float, data.get("wait_seconds")),
+            pool=_decode(str, data.get("pool")),
+            labels=_decode(_map_of(str), data.get("labels")),
+            selector=_decode(_map_of(str), data.get("selector")),
+            artifacts=_decode(_list_of(_identity), data.get("artifacts")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "id": "id",
+            "name": "name",
+            "tenant": "tenant",
+            "status": "status",
+            "class_": "class",
+            "priority": "priority",
+            "effective_priority": "effective_priority",
+            "memory_required_mb": "memory_required_mb",
+            "encoder_sessions": "encoder_sessions",
+            "decoder_sessions": "decoder_sessions",
+            "assigned_gpu": "assigned_gpu",
+            "submitted_at": "submitted_at",
+            "started_at": "started_at",
+            "wait_seconds": "wait_seconds",
+            "pool": "pool",
+            "labels": "labels",
+            "selector": "selector",
+            "artifacts": "artifacts",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class WorkloadList:
+    workloads: Optional[List[WorkloadInfo]] = None
+    count: Optional[int] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "WorkloadList":
+        return cls(
+            workloads=_decode(_list_of(WorkloadInfo.from_dict), data.get("workloads")),
+            count=_decode(int, data.get("count")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "workloads": "workloads",
+            "count": "count",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class PoolStatus:
+    name: Optional[str] = None
+    strategy: Optional[str] = None
+    gpus: Optional[int] = None
+    busy_gpus: Optional[int] = None
+    memory_total_mb: Optional[int] = None
+    memory_used_mb: Optional[int] = None
+    running: Optional[int] = None
+    pending: Optional[int] = None
+    max_gpus: Optional[int] = None
+    max_memory_mb: Optional[int] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "PoolStatus":
+        return cls(
+            name=_decode(str, data.get("name")),
+            strategy=_decode(str, data.get("strategy")),
+            gpus=_decode(int, data.get("gpus")),
+            busy_gpus=_decode(int, data.get("busy_gpus")),
+            memory_total_mb=_decode(int, data.get("memory_total_mb")),
+            memory_used_mb=_decode(int, data.get("memory_used_mb")),
+            running=_decode(int, data.get("running")),
+            pending=_decode(int, data.get("pending")),
+            max_gpus=_decode(int, data.get("max_gpus")),
+            max_memory_mb=_decode(int, data.get("max_memory_mb")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "name": "name",
+            "strategy": "strategy",
+            "gpus": "gpus",
+            "busy_gpus": "busy_gpus",
+            "memory_total_mb": "memory_total_mb",
+            "memory_used_mb": "memory_used_mb",
+            "running": "running",
+            "pending": "pending",
+            "max_gpus": "max_gpus",
+            "max_memory_mb": "max_memory_mb",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class PoolList:
+    pools: Optional[List[PoolStatus]] = None
+    count: Optional[int] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "PoolList":
+        return cls(
+            pools=_decode(_list_of(PoolStatus.from_dict), data.get("pools")),
+            count=_decode(int, data.get("count")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "pools": "pools",
+            "count": "count",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class WhatIfScenario:
+    name: Optional[str] = None
+    cost_per_hour: Optional[Dict[str, float]] = None
+    spot_fraction: Optional[float] = None
+    spot_discount: Optional[float] = None
+    reserved_fraction: Optional[float] = None
+    reserved_discount: Optional[float] = None
+    target_utilization: Optional[float] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "WhatIfScenario":
+        return cls(
+            name=_decode(str, data.get("name")),
+            cost_per_hour=_decode(_map_of(float), data.get("cost_per_hour")),
+            spot_fraction=_decode(float, data.get("spot_fraction")),
+            spot_discount=_decode(float, data.get("spot_discount")),
+            reserved_fraction=_decode(float, data.get("reserved_fraction")),
+            reserved_discount=_decode(float, data.get("reserved_discount")),
+            target_utilization=_decode(float, data.get("target_utilization")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "name": "name",
+            "cost_per_hour": "cost_per_hour",
+            "spot_fraction": "spot_fraction",
+            "spot_discount": "spot_discount",
+            "reserved_fraction": "reserved_fraction",
+            "reserved_discount": "reserved_discount",
+            "target_utilization": "target_utilization",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class WhatIfRequest:
+    hours: Optional[int] = None
+    scenarios: Optional[List[WhatIfScenario]] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "WhatIfRequest":
+        return cls(
+            hours=_decode(int, data.get("hours")),
+            scenarios=_decode(_list_of(WhatIfScenario.from_dict), data.get("scenarios")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "hours": "hours",
+            "scenarios": "scenarios",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class WhatIfResult:
+    name: Optional[str] = None
+    cost: Optional[float] = None
+    gpu_hours: Optional[float] = None
+    delta: Optional[float] = None
+    delta_percent: Optional[float] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "WhatIfResult":
+        return cls(
+            name=_decode(str, data.get("name")),
+            cost=_decode(float, data.get("cost")),
+            gpu_hours=_decode(float, data.get("gpu_hours")),
+            delta=_decode(float, data.get("delta")),
+            delta_percent=_decode(float, data.get("delta_percent")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "name": "name",
+            "cost": "cost",
+            "gpu_hours": "gpu_hours",
+            "delta": "delta",
+            "delta_percent": "delta_percent",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class WhatIfReport:
+    start: Optional[datetime] = None
+    end: Optional[datetime] = None
+    currency: Optional[str] = None
+    baseline: Optional[WhatIfResult] = None
+    scenarios: Optional[List[WhatIfResult]] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "WhatIfReport":
+        return cls(
+            start=_decode(_parse_time, data.get("start")),
+            end=_decode(_parse_time, data.get("end")),
+            currency=_decode(str, data.get("currency")),
+            baseline=_decode(WhatIfResult.from_dict, data.get("baseline")),
+            scenarios=_decode(_list_of(WhatIfResult.from_dict), data.get("scenarios")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "start": "start",
+            "end": "end",
+            "currency": "currency",
+            "baseline": "baseline",
+            "scenarios": "scenarios",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class EmbeddingRequest:
+    id: Optional[str] = None
+    model_id: Optional[str] = None
+    inputs: Optional[List[str]] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "EmbeddingRequest":
+        return cls(
+            id=_decode(str, data.get("id")),
+            model_id=_decode(str, data.get("model_id")),
+            inputs=_decode(_list_of(str), data.get("inputs")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "id": "id",
+            "model_id": "model_id",
+            "inputs": "inputs",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
+
+@dataclass
+class EmbeddingResponse:
+    id: Optional[str] = None
+    model_id: Optional[str] = None
+    embeddings: Optional[List[List[float]]] = None
+    latency_ms: Optional[float] = None
+    queue_wait_ms: Optional[float] = None
+    max_batch_size: Optional[int] = None
+
+    @classmethod
+    def from_dict(cls, data: Dict[str, Any]) -> "EmbeddingResponse":
+        return cls(
+            id=_decode(str, data.get("id")),
+            model_id=_decode(str, data.get("model_id")),
+            embeddings=_decode(_list_of(_list_of(float)), data.get("embeddings")),
+            latency_ms=_decode(float, data.get("latency_ms")),
+            queue_wait_ms=_decode(float, data.get("queue_wait_ms")),
+            max_batch_size=_decode(int, data.get("max_batch_size")),
+        )
+
+    def to_dict(self) -> Dict[str, Any]:
+        names = {
+            "id": "id",
+            "model_id": "model_id",
+            "embeddings": "embeddings",
+            "latency_ms": "latency_ms",
+            "queue_wait_ms": "queue_wait_ms",
+            "max_batch_size": "max_batch_size",
+        }
+        return {names[f.name]: _encode(getattr(self, f.name)) for f in fields(self) if getattr(self, f.name) is not None}
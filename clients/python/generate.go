@@ -0,0 +1,6 @@
+// Package python holds the generated Python client. Its sources are
+// api/openapi/dashboard.yaml and the serving proto; regenerate after changing
+// either with go generate ./clients/python.
+package python
+
+//go:generate go run ../../cmd/pyclientgen -openapi ../../api/openapi/dashboard.yaml -proto ../../api/proto/agentaflow/serving/v1/inference.proto -out . -version 0.1.0
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/agent"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/client"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// runAgent implements `agentaflow agent`
+func runAgent(args []string) error {
+	fs := flag.NewFlagSet("agent", flag.ExitOnError)
+	endpoint := fs.String("endpoint", "http://localhost:8080", "Base URL of the dashboard")
+	endpoints := fs.String("endpoints", "", "Comma-separated further dashboard addresses, e.g. a warm standby's")
+	token := fs.String("token", os.Getenv("AGENTAFLOW_TOKEN"), "API key with the push-metrics scope; defaults to AGENTAFLOW_TOKEN")
+	node := fs.String("node", "", "Name of this node; defaults to the hostname")
+	interval := fs.Duration("interval", agent.DefaultInterval, "Time between uploads")
+	collectInterval := fs.Duration("collect-interval", 5*time.Second, "Time between GPU samples")
+	maxBatch := fs.Int("max-batch", agent.DefaultMaxBatchSamples, "Samples per upload")
+	compression := fs.String("compression", "gzip", "Content-Encoding for uploads: gzip or snappy; empty to send them uncompressed")
+	spoolDir := fs.String("spool-dir", "", "Directory spooling uploads while the server is unreachable; empty keeps them in memory")
+	spoolMaxMB := fs.Int64("spool-max-mb", agent.DefaultSpoolMaxBytes>>20, "Spool size beyond which the oldest uploads are dropped")
+	mockGPUs := fs.Int("mock-gpus", 0, "Upload N simulated GPUs instead of reading nvidia-smi")
+	fs.Parse(args)
+
+	config := client.DefaultConfig(*endpoint)
+	config.Token = *token
+	config.UserAgent = "agentaflow-agent"
+	config.Compression = *compression
+	if *endpoints != "" {
+		config.Endpoints = strings.Split(*endpoints, ",")
+	}
+	server, err := client.New(config)
+	if err != nil {
+		return err
+	}
+
+	var collector gpu.MetricsCollectorInterface
+	if *mockGPUs > 0 {
+		collector = gpu.NewMockMetricsCollector(*collectInterval, *mockGPUs)
+	} else {
+		collector = gpu.NewMetricsCollector(*collectInterval)
+	}
+	uploader, err := agent.New(agent.Config{
+		Node:            *node,
+		Interval:        *interval,
+		MaxBatchSamples: *maxBatch,
+		SpoolDir:        *spoolDir,
+		SpoolMaxBytes:   *spoolMaxMB << 20,
+	}, collector, server)
+	if err != nil {
+		return err
+	}
+	if err := collector.Start(); err != nil {
+		return fmt.Errorf("failed to start GPU metrics collection: %w", err)
+	}
+	defer collector.Stop()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	log.Printf("agent: uploading GPU metrics to %s every %s", *endpoint, *interval)
+	uploader.Start()
+	<-ctx.Done()
+	uploader.Stop()
+
+	// Send what was collected since the last upload before exiting
+	flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return uploader.Upload(flushCtx)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/client"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+)
+
+// runAnnotate implements `agentaflow annotate`, for CI pipelines marking
+// deployments on the dashboard's charts
+func runAnnotate(args []string) error {
+	fs := flag.NewFlagSet("annotate", flag.ExitOnError)
+	endpoint := fs.String("endpoint", "http://localhost:8080", "Base URL of the dashboard")
+	token := fs.String("token", os.Getenv("AGENTAFLOW_TOKEN"), "API key with the write-annotations scope; defaults to AGENTAFLOW_TOKEN")
+	text := fs.String("text", "", "Annotation text, e.g. \"model v2 deployed\"")
+	tags := fs.String("tags", "", "Comma-separated tags, e.g. deploy,chat-v2")
+	at := fs.String("time", "", "When it happened, RFC 3339; defaults to now")
+	duration := fs.Duration("duration", 0, "Length of the annotated period, e.g. 30m for a maintenance window; 0 marks a moment")
+	fs.Parse(args)
+
+	if *text == "" {
+		return fmt.Errorf("--text is required")
+	}
+	annotation := observability.Annotation{Text: *text, Tags: splitList(*tags)}
+	if *at != "" {
+		parsed, err := time.Parse(time.RFC3339, *at)
+		if err != nil {
+			return fmt.Errorf("invalid --time, expected RFC 3339: %w", err)
+		}
+		annotation.Time = parsed
+	}
+	if *duration > 0 {
+		if annotation.Time.IsZero() {
+			annotation.Time = time.Now()
+		}
+		end := annotation.Time.Add(*duration)
+		annotation.End = &end
+	}
+
+	config := client.DefaultConfig(*endpoint)
+	config.Token = *token
+	config.UserAgent = "agentaflow-annotate"
+	server, err := client.New(config)
+	if err != nil {
+		return err
+	}
+	created, err := server.Annotate(context.Background(), annotation)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created %s at %s: %s", created.ID, created.Time.Format(time.RFC3339), created.Text)
+	if len(created.Tags) > 0 {
+		fmt.Printf(" [%s]", strings.Join(created.Tags, ", "))
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/benchmark"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// runBenchmark implements `agentaflow benchmark`
+func runBenchmark(args []string) error {
+	if len(args) == 0 || args[0] != "gpu" {
+		return fmt.Errorf("usage: agentaflow benchmark gpu [flags]")
+	}
+	defaults := benchmark.DefaultConfig()
+
+	fs := flag.NewFlagSet("benchmark gpu", flag.ExitOnError)
+	gpus := fs.String("gpus", "", "Comma-separated GPU IDs to load; all GPUs when empty")
+	duration := fs.Duration("duration", defaults.Duration, "Load applied to each GPU")
+	runnerName := fs.String("runner", "gpu-burn", "Load generator: gpu-burn, or command to run --command")
+	gpuBurn := fs.String("gpu-burn", "gpu_burn", "gpu-burn binary")
+	doubles := fs.Bool("doubles", false, "Run gpu-burn in double precision")
+	tensorCores := fs.Bool("tensor-cores", false, "Run gpu-burn on tensor cores")
+	command := fs.String("command", "", "Benchmark tool printing \"<n> TFLOPS\"; {gpu} and {seconds} are replaced")
+	maxTemp := fs.Float64("max-temp", defaults.MaxTemperature, "Temperature in Celsius above which a GPU fails burn-in; 0 disables")
+	node := fs.String("node", "", "Name of this node; defaults to the hostname")
+	profilesPath := fs.String("profiles", "gpu-profiles.json", "File storing baseline hardware profiles; empty to not store results")
+	mockGPUs := fs.Int("mock-gpus", 0, "Benchmark N simulated GPUs instead of running a load generator")
+	jsonOutput := fs.Bool("json", false, "Print the report as JSON")
+	fs.Parse(args[1:])
+
+	config := defaults
+	config.Duration = *duration
+	config.GPUs = splitList(*gpus)
+	config.MaxTemperature = *maxTemp
+	config.Node = *node
+
+	var collector gpu.MetricsCollectorInterface
+	switch {
+	case *mockGPUs > 0:
+		mock := gpu.NewMockMetricsCollector(time.Second, *mockGPUs)
+		collector = mock
+		config.Runner = benchmark.RunnerFunc(func(ctx context.Context, gpuID string, duration time.Duration) (benchmark.Throughput, error) {
+			mock.TriggerWorkloadChange(gpuID, "Training")
+			select {
+			case <-time.After(duration):
+			case <-ctx.Done():
+				return benchmark.Throughput{}, ctx.Err()
+			}
+			utilization := mock.GetLatestMetrics()[gpuID].UtilizationGPU
+			return benchmark.Throughput{TFLOPs: 0.2 * utilization}, nil
+		})
+	case *runnerName == "gpu-burn":
+		collector = gpu.NewMetricsCollector(time.Second)
+		config.Runner = &benchmark.GPUBurn{Path: *gpuBurn, Doubles: *doubles, TensorCores: *tensorCores}
+	case *runnerName == "command":
+		if *command == "" {
+			return fmt.Errorf("--runner command needs --command")
+		}
+		collector = gpu.NewMetricsCollector(time.Second)
+		config.Runner = &benchmark.Command{Args: strings.Fields(*command)}
+	default:
+		return fmt.Errorf("unknown runner %q: expected gpu-burn or command", *runnerName)
+	}
+	if err := collector.Start(); err != nil {
+		return fmt.Errorf("failed to start GPU metrics collection: %w", err)
+	}
+	defer collector.Stop()
+	config.Collector = collector
+
+	// Wait for the first samples, which name the GPUs
+	for deadline := time.Now().Add(5 * time.Second); len(collector.GetLatestMetrics()) == 0 && time.Now().Before(deadline); {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := benchmark.Run(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	var updated []benchmark.Profile
+	if *profilesPath != "" {
+		store, err := benchmark.OpenProfiles(*profilesPath)
+		if err != nil {
+			return err
+		}
+		updated = store.Record(report)
+		if err := store.Save(); err != nil {
+			return err
+		}
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("=== GPU Benchmark (%s, %v) ===\n", report.Runner, config.Duration)
+		for _, result := range report.Results {
+			status := "PASS"
+			if !result.Passed {
+				status = "FAIL"
+			}
+			fmt.Printf("[%s] GPU %-4s %-24s %7.2f TFLOPs  %5.1fC max  %6.1fW avg  %.3f TFLOPs/W\n",
+				status, result.GPUID, result.Model, result.TFLOPs, result.MaxTemperature, result.AvgPowerWatts, result.TFLOPsPerWatt)
+			if result.Failure != "" {
+				fmt.Printf("       %s\n", result.Failure)
+			}
+		}
+		for _, profile := range updated {
+			fmt.Printf("\nBaseline %s (%s): %.2f TFLOPs mean over %d GPU(s), saved to %s\n",
+				profile.Model, profile.Runner, profile.TFLOPs, profile.GPUs, *profilesPath)
+		}
+	}
+
+	if !report.Passed {
+		return fmt.Errorf("burn-in failed")
+	}
+	return nil
+}
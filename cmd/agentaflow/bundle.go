@@ -0,0 +1,89 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/secrets"
+)
+
+// runBundle implements `agentaflow bundle keygen|sign|verify`
+func runBundle(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: agentaflow bundle keygen|sign|verify [flags]")
+	}
+
+	switch args[0] {
+	case "keygen":
+		fs := flag.NewFlagSet("bundle keygen", flag.ExitOnError)
+		out := fs.String("out", "bundle-signing.key", "Private key file to write")
+		fs.Parse(args[1:])
+
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*out, []byte(base64.StdEncoding.EncodeToString(private)+"\n"), 0600); err != nil {
+			return err
+		}
+		fmt.Printf("Private key written to %s\n", *out)
+		fmt.Printf("Trusted key: %s\n", base64.StdEncoding.EncodeToString(public))
+		return nil
+
+	case "sign":
+		fs := flag.NewFlagSet("bundle sign", flag.ExitOnError)
+		keyPath := fs.String("key", "bundle-signing.key", "Private key file from bundle keygen")
+		fs.Parse(args[1:])
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: agentaflow bundle sign --key FILE BUNDLE")
+		}
+
+		encoded, err := os.ReadFile(*keyPath)
+		if err != nil {
+			return err
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+		if err != nil || len(key) != ed25519.PrivateKeySize {
+			return fmt.Errorf("%s is not a base64 Ed25519 private key", *keyPath)
+		}
+		data, err := os.ReadFile(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		signature := observability.SignConfigBundle(data, ed25519.PrivateKey(key))
+		if err := os.WriteFile(fs.Arg(0)+".sig", []byte(signature+"\n"), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Signed %s with key %s\n", fs.Arg(0), observability.BundleKeyID(ed25519.PrivateKey(key).Public().(ed25519.PublicKey)))
+		return nil
+
+	case "verify":
+		fs := flag.NewFlagSet("bundle verify", flag.ExitOnError)
+		trusted := fs.String("trusted-keys", "", "Comma-separated base64 public keys")
+		pinned := fs.Int("pin", 0, "Only accept this bundle version")
+		state := fs.String("state", "", "Bundle state file of the server, to check the version against what it applied")
+		fs.Parse(args[1:])
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: agentaflow bundle verify --trusted-keys KEYS BUNDLE")
+		}
+
+		loader, err := observability.NewBundleLoader(observability.BundleConfig{TrustedKeys: splitList(*trusted), PinnedVersion: *pinned, StateFile: *state})
+		if err != nil {
+			return err
+		}
+		loader.SetSecretResolver(secrets.NewResolver(secrets.DefaultConfig()))
+		bundle, err := loader.Load(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: version %d signed by %s, sha256 %s\n", fs.Arg(0), bundle.Version, bundle.KeyID, bundle.Digest)
+		return nil
+	}
+	return fmt.Errorf("unknown bundle command %q (expected keygen, sign or verify)", args[0])
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/doctor"
+)
+
+// runDoctor implements `agentaflow doctor`
+func runDoctor(args []string) error {
+	defaults := doctor.DefaultConfig()
+
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	nvidiaSMI := fs.String("nvidia-smi", defaults.NvidiaSMIPath, "nvidia-smi binary")
+	requireGPU := fs.Bool("require-gpu", defaults.RequireGPU, "Fail when no GPUs are found (warn otherwise)")
+	minDriver := fs.String("min-driver", defaults.MinDriverVersion, "Minimum NVIDIA driver version")
+	clockURL := fs.String("clock-url", "", "HTTP server to compare the local clock against")
+	maxSkew := fs.Duration("max-clock-skew", defaults.MaxClockSkew, "Maximum allowed clock skew")
+	ports := fs.String("ports", joinPorts(defaults.Ports), "Comma-separated ports that must be free")
+	kubernetes := fs.Bool("kubernetes", false, "Check Kubernetes RBAC permissions with kubectl")
+	namespace := fs.String("namespace", defaults.Namespace, "Namespace workloads are scheduled into")
+	statePath := fs.String("state-file", "", "Snapshot file whose directory must be writable")
+	airGapped := fs.Bool("air-gapped", false, "Fail when an endpoint is outside the allowed networks")
+	allowedNetworks := fs.String("allowed-networks", "", "Comma-separated CIDR ranges an air-gapped site may reach (default loopback, private and link-local)")
+	endpoints := fs.String("endpoints", "", "Comma-separated URLs AgentaFlow connects to, e.g. tracing collectors and webhooks")
+	timeout := fs.Duration("timeout", defaults.Timeout, "Per-check timeout")
+	jsonOutput := fs.Bool("json", false, "Print the report as JSON")
+	fs.Parse(args)
+
+	config := defaults
+	config.NvidiaSMIPath = *nvidiaSMI
+	config.RequireGPU = *requireGPU
+	config.MinDriverVersion = *minDriver
+	config.ClockReferenceURL = *clockURL
+	config.MaxClockSkew = *maxSkew
+	config.CheckKubernetes = *kubernetes
+	config.Namespace = *namespace
+	config.StatePath = *statePath
+	config.AirGapped = *airGapped
+	config.AllowedNetworks = splitList(*allowedNetworks)
+	config.Endpoints = splitList(*endpoints)
+	config.Timeout = *timeout
+
+	config.Ports = nil
+	for _, field := range strings.Split(*ports, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		port, err := strconv.Atoi(field)
+		if err != nil {
+			return fmt.Errorf("invalid port %q", field)
+		}
+		config.Ports = append(config.Ports, port)
+	}
+
+	report := doctor.Run(context.Background(), config)
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println("=== AgentaFlow Doctor ===")
+		for _, result := range report.Results {
+			fmt.Printf("[%-4s] %-16s %s\n", strings.ToUpper(result.Status), result.Name, result.Message)
+		}
+		fmt.Printf("\n%d passed, %d warnings, %d failed, %d skipped\n",
+			report.Summary[doctor.StatusPass], report.Summary[doctor.StatusWarn],
+			report.Summary[doctor.StatusFail], report.Summary[doctor.StatusSkip])
+	}
+
+	if !report.Passed {
+		return fmt.Errorf("%d check(s) failed", report.Summary[doctor.StatusFail])
+	}
+	return nil
+}
+
+// joinPorts formats ports as a comma-separated flag value
+func joinPorts(ports []int) string {
+	fields := make([]string, len(ports))
+	for i, port := range ports {
+		fields[i] = strconv.Itoa(port)
+	}
+	return strings.Join(fields, ",")
+}
@@ -0,0 +1,4 @@
+package main
+
+// Database drivers for `agentaflow migrate`; sqlite needs the sqlite build tag
+import _ "github.com/lib/pq" // Registers "postgres"
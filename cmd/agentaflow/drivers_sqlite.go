@@ -0,0 +1,6 @@
+//go:build sqlite
+// +build sqlite
+
+package main
+
+import _ "github.com/mattn/go-sqlite3" // Registers "sqlite3"; needs cgo
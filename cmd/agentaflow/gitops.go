@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/client"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gitops"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/secrets"
+)
+
+// runGitOps implements `agentaflow gitops`
+func runGitOps(args []string) error {
+	fs := flag.NewFlagSet("gitops", flag.ExitOnError)
+	repo := fs.String("repo", "", "Git repository holding the desired config")
+	branch := fs.String("branch", "main", "Branch to follow")
+	path := fs.String("path", "", "Directory within the repository holding the config")
+	checkoutDir := fs.String("checkout-dir", filepath.Join(os.TempDir(), "agentaflow-gitops"), "Where to keep the clone")
+	dir := fs.String("dir", "", "Directory holding the desired config, such as a mounted ConfigMap, instead of --repo")
+	endpoint := fs.String("endpoint", "http://localhost:8080", "Base URL of the dashboard")
+	token := fs.String("token", os.Getenv("AGENTAFLOW_TOKEN"), "Control token or admin API key; defaults to AGENTAFLOW_TOKEN")
+	interval := fs.Duration("interval", gitops.DefaultInterval, "Time between syncs")
+	prune := fs.Bool("prune", false, "Delete live resources missing from the desired config")
+	dryRun := fs.Bool("dry-run", false, "Report drift without changing anything")
+	once := fs.Bool("once", false, "Sync once, print the status and exit non-zero unless in sync")
+	statusAddr := fs.String("status-addr", ":9094", "Address serving /status and /healthz; empty to disable")
+	fs.Parse(args)
+
+	var source gitops.Source
+	switch {
+	case *repo != "" && *dir != "":
+		return fmt.Errorf("pass either --repo or --dir, not both")
+	case *repo != "":
+		source = &gitops.GitSource{Repo: *repo, Branch: *branch, Path: *path, Dir: *checkoutDir}
+	case *dir != "":
+		source = &gitops.DirSource{Dir: *dir}
+	default:
+		return fmt.Errorf("no source; pass --repo or --dir")
+	}
+
+	config := client.DefaultConfig(*endpoint)
+	config.Token = *token
+	config.UserAgent = "agentaflow-gitops"
+	live, err := client.New(config)
+	if err != nil {
+		return err
+	}
+	controller := gitops.NewController(source, live, gitops.Config{
+		Interval: *interval,
+		Prune:    *prune,
+		DryRun:   *dryRun,
+		Secrets:  secrets.NewResolver(secrets.DefaultConfig()),
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *once {
+		status := controller.Sync(ctx)
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(status); err != nil {
+			return err
+		}
+		if status.Error != "" {
+			return fmt.Errorf("%s", status.Error)
+		}
+		if !status.Synced {
+			return fmt.Errorf("%d resources out of sync", len(status.Drift))
+		}
+		return nil
+	}
+
+	if *statusAddr != "" {
+		server := &http.Server{Addr: *statusAddr, Handler: controller.Handler()}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("gitops: status server failed: %v", err)
+			}
+		}()
+		defer server.Close()
+	}
+
+	log.Printf("gitops: syncing %s to %s every %s", source, *endpoint, *interval)
+	controller.Run(ctx)
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/loadtest"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving"
+)
+
+// runLoadTest implements `agentaflow loadtest`
+func runLoadTest(args []string) error {
+	fs := flag.NewFlagSet("loadtest", flag.ExitOnError)
+	qps := fs.Float64("qps", 50, "Target requests per second (0 replays trace timing as recorded)")
+	duration := fs.Duration("duration", 30*time.Second, "Maximum test duration")
+	concurrency := fs.Int("concurrency", 64, "Maximum in-flight requests")
+	tracePath := fs.String("trace", "", "JSON-lines trace to replay (offset_ms, model_id, input, session_id)")
+	models := fs.String("models", "model-default", "Comma-separated models for synthetic traffic")
+	minInput := fs.Int("min-input", 64, "Minimum synthetic input size in bytes")
+	maxInput := fs.Int("max-input", 1024, "Maximum synthetic input size in bytes")
+	poisson := fs.Bool("poisson", false, "Use Poisson arrivals for synthetic traffic")
+	backendLatency := fs.Duration("backend-latency", 20*time.Millisecond, "Simulated backend execution time")
+	cacheTTL := fs.Duration("cache-ttl", 0, "Serving cache TTL (0 disables caching)")
+	mockGPUs := fs.Int("mock-gpus", 0, "Correlate with N simulated GPUs (0 uses nvidia-smi when available)")
+	jsonOutput := fs.Bool("json", false, "Print the report as JSON")
+	fs.Parse(args)
+
+	config := loadtest.DefaultConfig()
+	config.QPS = *qps
+	config.Duration = *duration
+	config.Concurrency = *concurrency
+	config.Synthetic = loadtest.SyntheticConfig{
+		Models:       strings.Split(*models, ","),
+		MinInputSize: *minInput,
+		MaxInputSize: *maxInput,
+		Poisson:      *poisson,
+		Seed:         time.Now().UnixNano(),
+	}
+
+	if *tracePath != "" {
+		trace, err := loadtest.LoadTrace(*tracePath)
+		if err != nil {
+			return err
+		}
+		config.Trace = trace
+	}
+
+	// Serve requests in-process with a simulated backend
+	servingMgr := serving.NewServingManager(nil, *cacheTTL)
+	servingMgr.SetInferenceBackend(func(req *serving.InferenceRequest) ([]byte, error) {
+		time.Sleep(*backendLatency)
+		return []byte(fmt.Sprintf("processed_%s", req.ID)), nil
+	})
+	config.Target = servingMgr.SubmitInferenceRequest
+
+	var collector gpu.MetricsCollectorInterface
+	if *mockGPUs > 0 {
+		collector = gpu.NewMockMetricsCollector(time.Second, *mockGPUs)
+	} else {
+		collector = gpu.NewMetricsCollector(time.Second)
+	}
+	if err := collector.Start(); err == nil {
+		defer collector.Stop()
+		config.Sampler = loadtest.CollectorSampler(collector)
+	} else {
+		fmt.Fprintf(os.Stderr, "GPU metrics unavailable, skipping utilization correlation: %v\n", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := loadtest.Run(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	fmt.Println("=== Load Test Report ===")
+	fmt.Printf("Requests:    %d (succeeded %d, failed %d, cache hits %d)\n",
+		report.Requests, report.Succeeded, report.Failed, report.CacheHits)
+	fmt.Printf("Duration:    %v\n", report.Duration.Round(time.Millisecond))
+	fmt.Printf("Throughput:  %.1f req/s\n", report.ThroughputRPS)
+	fmt.Printf("Latency:     mean=%.1fms p50=%.1fms p90=%.1fms p95=%.1fms p99=%.1fms max=%.1fms\n",
+		report.LatencyMeanMs, report.LatencyP50Ms, report.LatencyP90Ms,
+		report.LatencyP95Ms, report.LatencyP99Ms, report.LatencyMaxMs)
+	if config.Sampler != nil {
+		fmt.Printf("GPU:         avg utilization=%.1f%%\n", report.AvgUtilization)
+		fmt.Printf("Correlation: throughput~utilization=%.2f latency~utilization=%.2f\n",
+			report.ThroughputUtilizationCorr, report.LatencyUtilizationCorr)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
 	"time"
 
 	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
@@ -11,6 +12,62 @@ import (
 )
 
 func main() {
+	// Subcommands; without one the feature demo runs
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "loadtest":
+			if err := runLoadTest(os.Args[2:]); err != nil {
+				log.Fatalf("loadtest failed: %v", err)
+			}
+			return
+		case "doctor":
+			if err := runDoctor(os.Args[2:]); err != nil {
+				log.Fatalf("doctor failed: %v", err)
+			}
+			return
+		case "backup":
+			if err := runBackup(os.Args[2:]); err != nil {
+				log.Fatalf("backup failed: %v", err)
+			}
+			return
+		case "restore":
+			if err := runRestore(os.Args[2:]); err != nil {
+				log.Fatalf("restore failed: %v", err)
+			}
+			return
+		case "migrate":
+			if err := runMigrate(os.Args[2:]); err != nil {
+				log.Fatalf("migrate failed: %v", err)
+			}
+			return
+		case "bundle":
+			if err := runBundle(os.Args[2:]); err != nil {
+				log.Fatalf("bundle failed: %v", err)
+			}
+			return
+		case "gitops":
+			if err := runGitOps(os.Args[2:]); err != nil {
+				log.Fatalf("gitops failed: %v", err)
+			}
+			return
+		case "agent":
+			if err := runAgent(os.Args[2:]); err != nil {
+				log.Fatalf("agent failed: %v", err)
+			}
+			return
+		case "benchmark":
+			if err := runBenchmark(os.Args[2:]); err != nil {
+				log.Fatalf("benchmark failed: %v", err)
+			}
+			return
+		case "annotate":
+			if err := runAnnotate(os.Args[2:]); err != nil {
+				log.Fatalf("annotate failed: %v", err)
+			}
+			return
+		}
+	}
+
 	fmt.Println("=== AgentaFlow SRO - AI Infrastructure Tooling ===")
 	fmt.Println()
 
@@ -176,8 +233,40 @@ func demoModelServing(monitor *observability.MonitoringService, debugger *observ
 			fmt.Sprintf("Registered model: %s", m.Name), nil)
 	}
 
+	// Attach SLAs and forward SLA decisions to the monitoring event log
+	servingMgr.SetModelSLA("model-gpt", serving.ModelSLA{
+		MaxP95Latency:   200 * time.Millisecond,
+		MinAvailability: 0.99,
+		Tier:            1,
+	})
+	servingMgr.SetModelSLA("model-bert", serving.ModelSLA{
+		MaxP95Latency:   500 * time.Millisecond,
+		MinAvailability: 0.95,
+		Tier:            2,
+	})
+	servingMgr.GetSLAManager().OnEvent(func(e serving.SLAEvent) {
+		severity := "info"
+		if e.Status != serving.SLAStatusOK {
+			severity = "warning"
+		}
+		monitor.RecordEvent(observability.Event{
+			ID:       fmt.Sprintf("sla-%s-%s-%d", e.ModelID, e.Type, e.Timestamp.UnixNano()),
+			Type:     e.Type,
+			Severity: severity,
+			Message:  e.Message,
+			Source:   "serving_sla",
+			Metadata: map[string]interface{}{
+				"model_id":       e.ModelID,
+				"p95_latency_ms": e.P95Latency,
+				"availability":   e.Availability,
+			},
+		})
+	})
+
 	// Create router
 	router := serving.NewRouter(serving.RouteLeastLatency)
+	router.StartSessionCleanup(time.Minute)
+	defer router.StopSessionCleanup()
 
 	// Register model instances
 	instances := []*serving.ModelInstance{
@@ -227,6 +316,10 @@ func demoModelServing(monitor *observability.MonitoringService, debugger *observ
 		},
 	}
 
+	// Per-stage latency goes to the exporter's inference_stage_latency_seconds histogram
+	exporter := observability.NewPrometheusExporter(monitor, observability.DefaultPrometheusConfig())
+	exporter.RegisterServingMetrics()
+
 	fmt.Println("Processing inference requests...")
 	for _, req := range requests {
 		resp, err := servingMgr.SubmitInferenceRequest(req)
@@ -253,6 +346,9 @@ func demoModelServing(monitor *observability.MonitoringService, debugger *observ
 				"cache": cacheStatus,
 			},
 		})
+		if err := exporter.ObserveInferenceStages(req.ModelID, resp.Breakdown); err != nil {
+			log.Printf("Failed to export stage latency of request %s: %v", req.ID, err)
+		}
 	}
 
 	// Display serving metrics
@@ -262,6 +358,17 @@ func demoModelServing(monitor *observability.MonitoringService, debugger *observ
 		fmt.Printf("  %s: %v\n", key, value)
 	}
 
+	fmt.Printf("\nLatency Breakdown:\n")
+	for modelID, stages := range servingMgr.GetLatencyBreakdown() {
+		fmt.Printf("  %s: %v\n", modelID, stages)
+	}
+
+	fmt.Printf("\nSLA Status:\n")
+	for _, report := range servingMgr.EvaluateSLAs() {
+		fmt.Printf("  %s: status=%s p95=%.0fms availability=%.3f share=%.1fx\n",
+			report.ModelID, report.Status, report.P95Latency, report.Availability, report.CapacityShare)
+	}
+
 	fmt.Printf("\nCache Metrics:\n")
 	cacheMetrics := servingMgr.GetCacheMetrics()
 	for key, value := range cacheMetrics {
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/migrate"
+)
+
+// runMigrate implements `agentaflow migrate`
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	driverName := fs.String("driver", "postgres", "database/sql driver name")
+	dialect := fs.String("dialect", "", "SQL dialect, postgres or sqlite (defaults from the driver)")
+	dsn := fs.String("dsn", "", "Database connection string")
+	dryRun := fs.Bool("dry-run", false, "Print pending migrations without applying them")
+	fs.Parse(args)
+
+	migrations := migrate.Builtin()
+	if *dsn == "" {
+		if !*dryRun {
+			return fmt.Errorf("--dsn is required")
+		}
+		// Without a database every builtin migration is listed
+		printMigrations("Builtin migrations", migrations)
+		return nil
+	}
+
+	if *dialect == "" {
+		*dialect = migrate.DialectPostgres
+		if strings.Contains(*driverName, "sqlite") {
+			*dialect = migrate.DialectSQLite
+		}
+	}
+	db, err := sql.Open(*driverName, *dsn)
+	if err != nil {
+		return fmt.Errorf("%w (drivers built in: %s)", err, strings.Join(sql.Drivers(), ", "))
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, *dialect, migrations)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if *dryRun {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		printMigrations("Pending migrations", pending)
+		return nil
+	}
+
+	applied, err := migrator.Up(ctx)
+	for _, migration := range applied {
+		fmt.Printf("Applied %04d_%s\n", migration.Version, migration.Name)
+	}
+	if err == nil && len(applied) == 0 {
+		fmt.Println("Schema is up to date")
+	}
+	return err
+}
+
+// printMigrations lists migrations with their SQL
+func printMigrations(title string, migrations []migrate.Migration) {
+	fmt.Printf("%s: %d\n", title, len(migrations))
+	for _, migration := range migrations {
+		fmt.Printf("\n-- %04d_%s\n%s\n", migration.Version, migration.Name, strings.TrimSpace(migration.SQL))
+	}
+}
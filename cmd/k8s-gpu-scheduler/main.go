@@ -75,6 +75,8 @@ func runScheduler(ctx context.Context, namespace, strategyName string) error {
 		strategy = gpu.StrategyPriority
 	case "round_robin":
 		strategy = gpu.StrategyRoundRobin
+	case "energy_aware":
+		strategy = gpu.StrategyEnergyAware
 	default:
 		return fmt.Errorf("unknown scheduling strategy: %s", strategyName)
 	}
@@ -162,6 +164,8 @@ func runCLI(namespace, strategyName string, args []string) error {
 		strategy = gpu.StrategyPriority
 	case "round_robin":
 		strategy = gpu.StrategyRoundRobin
+	case "energy_aware":
+		strategy = gpu.StrategyEnergyAware
 	default:
 		return fmt.Errorf("unknown scheduling strategy: %s", strategyName)
 	}
@@ -175,15 +179,6 @@ func runCLI(namespace, strategyName string, args []string) error {
 	// Create CLI
 	cli := k8s.NewGPUSchedulerCLI(scheduler)
 
-	// Handle special CLI commands
-	if len(args) > 0 && args[0] == "generate-template" {
-		filename := "workload-template.yaml"
-		if len(args) > 1 {
-			filename = args[1]
-		}
-		return cli.GenerateWorkloadTemplate(filename)
-	}
-
 	// Execute CLI command
 	return cli.ExecuteCommand(args)
 }
@@ -0,0 +1,126 @@
+// Command pyclientgen generates the in-tree Python client from the dashboard
+// OpenAPI document and the serving proto. It runs through go generate:
+//
+//	go generate ./clients/python
+package main
+
+import (
+	"bytes"
+	"embed"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// outputs maps each generated file, relative to the output directory, to
+// its template
+var outputs = map[string]string{
+	"agentaflow/__init__.py":  "__init__.py.tmpl",
+	"agentaflow/models.py":    "models.py.tmpl",
+	"agentaflow/client.py":    "client.py.tmpl",
+	"agentaflow/inference.py": "inference.py.tmpl",
+	"pyproject.toml":          "pyproject.toml.tmpl",
+}
+
+// templateData is what every template renders from
+type templateData struct {
+	API     *API
+	Proto   *ProtoFile
+	Version string
+}
+
+func main() {
+	openapiPath := flag.String("openapi", "", "OpenAPI document of the dashboard API")
+	protoPath := flag.String("proto", "", "Proto file of the serving gRPC API")
+	out := flag.String("out", ".", "Directory of the Python project")
+	version := flag.String("version", "0.1.0", "Version of the Python package")
+	check := flag.Bool("check", false, "Fail if the generated files are out of date instead of writing them")
+	flag.Parse()
+
+	if *openapiPath == "" || *protoPath == "" {
+		fmt.Fprintln(os.Stderr, "pyclientgen: --openapi and --proto are required")
+		os.Exit(2)
+	}
+	files, err := generate(*openapiPath, *protoPath, *version)
+	if err == nil {
+		err = writeFiles(*out, files, *check)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pyclientgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// generate renders every output file
+func generate(openapiPath, protoPath, version string) (map[string][]byte, error) {
+	api, err := loadOpenAPI(openapiPath)
+	if err != nil {
+		return nil, err
+	}
+	proto, err := loadProto(protoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sources are named relative to the repository root, whatever directory
+	// go generate runs in
+	header := fmt.Sprintf("# Code generated by pyclientgen from %s and %s. DO NOT EDIT.",
+		strings.TrimLeft(filepath.ToSlash(openapiPath), "./"), strings.TrimLeft(filepath.ToSlash(protoPath), "./"))
+	templates, err := template.New("").Funcs(template.FuncMap{
+		"header":     func() string { return header },
+		"trimSuffix": strings.TrimSuffix,
+	}).ParseFS(templateFS, "templates/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	data := templateData{API: api, Proto: proto, Version: version}
+	files := make(map[string][]byte, len(outputs))
+	for name, templateName := range outputs {
+		var buf bytes.Buffer
+		if err := templates.ExecuteTemplate(&buf, templateName, data); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		files[name] = buf.Bytes()
+	}
+	return files, nil
+}
+
+// writeFiles writes the generated files under dir or, with check, reports
+// those that differ from what is there
+func writeFiles(dir string, files map[string][]byte, check bool) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var stale []string
+	for _, name := range names {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if check {
+			if existing, err := ioutil.ReadFile(path); err != nil || !bytes.Equal(existing, files[name]) {
+				stale = append(stale, name)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, files[name], 0644); err != nil {
+			return err
+		}
+	}
+	if len(stale) > 0 {
+		return fmt.Errorf("out of date, run go generate ./clients/python: %s", strings.Join(stale, ", "))
+	}
+	return nil
+}
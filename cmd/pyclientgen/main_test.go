@@ -0,0 +1,87 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	testOpenAPI = "../../api/openapi/dashboard.yaml"
+	testProto   = "../../api/proto/agentaflow/serving/v1/inference.proto"
+)
+
+func TestSnakeCase(t *testing.T) {
+	for in, expected := range map[string]string{
+		"getMetrics":      "get_metrics",
+		"getGPUMetrics":   "get_gpu_metrics",
+		"listGPUs":        "list_gpus",
+		"listGPUsByNode":  "list_gpus_by_node",
+		"InferBatch":      "infer_batch",
+		"getP99Latency":   "get_p99_latency",
+		"resolveAlert":    "resolve_alert",
+		"simulateCosts":   "simulate_costs",
+		"getHTTPSettings": "get_http_settings",
+	} {
+		if actual := snakeCase(in); actual != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, in, actual)
+		}
+	}
+}
+
+func TestLoadOpenAPIUnwrapsResults(t *testing.T) {
+	api, err := loadOpenAPI(testOpenAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	operations := make(map[string]Operation)
+	for _, op := range api.Operations {
+		operations[op.Name] = op
+	}
+
+	workloads := operations["list_workloads"]
+	if workloads.ReturnType != "List[WorkloadInfo]" || workloads.Decoder != "_list_of(WorkloadInfo.from_dict)" || len(workloads.QueryParams) != 3 {
+		t.Errorf("Expected workloads unwrapped from their list, got %+v", workloads)
+	}
+	if history := operations["get_gpu_history"]; history.Path != "/api/v1/gpu/{_quote(gpu_id)}/history" {
+		t.Errorf("Expected the path parameter substituted, got %s", history.Path)
+	}
+	if resolve := operations["resolve_alert"]; resolve.Method != "POST" || resolve.ReturnType != "None" {
+		t.Errorf("Expected a POST without a result, got %+v", resolve)
+	}
+	if len(api.Streams) == 0 {
+		t.Error("Expected the WebSocket message types")
+	}
+}
+
+func TestLoadProto(t *testing.T) {
+	file, err := loadProto(testProto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpcs := file.Services["InferenceService"]
+	if len(rpcs) != 2 || rpcs[1].Method != "/agentaflow.serving.v1.InferenceService/InferBatch" || !rpcs[1].ServerStreaming {
+		t.Fatalf("Expected Infer and the streaming InferBatch, got %+v", rpcs)
+	}
+	for _, message := range file.Messages {
+		if message.Name != "InferResponse" {
+			continue
+		}
+		breakdown := message.Fields[5]
+		if breakdown.Name != "breakdown" || !breakdown.Message || breakdown.Number != 6 {
+			t.Errorf("Expected the nested breakdown message, got %+v", breakdown)
+		}
+	}
+}
+
+func TestGeneratedClientIsUpToDate(t *testing.T) {
+	files, err := generate(testOpenAPI, testProto, "0.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(files["agentaflow/client.py"]), "async def list_workloads(") {
+		t.Error("Expected a method per operation")
+	}
+	if err := writeFiles("../../clients/python", files, true); err != nil {
+		t.Errorf("Expected the checked-in client to match the generator: %v", err)
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// schema is the subset of an OpenAPI schema object the generator reads
+type schema struct {
+	Ref                  string        `yaml:"$ref"`
+	Type                 string        `yaml:"type"`
+	Format               string        `yaml:"format"`
+	Items                *schema       `yaml:"items"`
+	Properties           yaml.MapSlice `yaml:"properties"` // Kept in document order
+	AdditionalProperties *schema       `yaml:"additionalProperties"`
+}
+
+// property decodes the named property of an object schema
+func (s *schema) property(name string) (*schema, error) {
+	for _, item := range s.Properties {
+		if item.Key == name {
+			var property schema
+			if err := remarshal(item.Value, &property); err != nil {
+				return nil, err
+			}
+			return &property, nil
+		}
+	}
+	return nil, fmt.Errorf("no property %q", name)
+}
+
+type parameter struct {
+	Name     string `yaml:"name"`
+	In       string `yaml:"in"`
+	Required bool   `yaml:"required"`
+	Schema   schema `yaml:"schema"`
+}
+
+type mediaTypes struct {
+	Content map[string]struct {
+		Schema *schema `yaml:"schema"`
+	} `yaml:"content"`
+}
+
+type operation struct {
+	OperationID string                `yaml:"operationId"`
+	Summary     string                `yaml:"summary"`
+	Result      string                `yaml:"x-result"` // Property of the response returned on its own
+	Parameters  []parameter           `yaml:"parameters"`
+	RequestBody *mediaTypes           `yaml:"requestBody"`
+	Responses   map[string]mediaTypes `yaml:"responses"`
+}
+
+type openAPISpec struct {
+	Info struct {
+		Title   string `yaml:"title"`
+		Version string `yaml:"version"`
+	} `yaml:"info"`
+	Paths      map[string]map[string]*operation `yaml:"paths"`
+	Streams    map[string]*schema               `yaml:"x-streams"`
+	Components struct {
+		Schemas yaml.MapSlice `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+// Model is a component schema rendered as a Python dataclass
+type Model struct {
+	Name   string
+	Fields []Field
+}
+
+// Field is a property of a model
+type Field struct {
+	Name    string // Python attribute name
+	JSON    string // JSON property name
+	Type    string // Python type annotation
+	Decoder string // Python callable converting the JSON value
+}
+
+// Param is an operation parameter
+type Param struct {
+	Name     string // Python argument name
+	Wire     string // Name in the path or query string
+	Type     string
+	Required bool
+}
+
+// Operation is an API operation rendered as a client method
+type Operation struct {
+	Method      string
+	Name        string
+	Summary     string
+	Path        string // Python f-string body with path parameters substituted
+	PathParams  []Param
+	QueryParams []Param
+	BodyType    string
+	Result      string // Property of the response returned, if any
+	ReturnType  string
+	Decoder     string
+}
+
+// Stream maps a WebSocket message type to the decoder of its data
+type Stream struct {
+	Type    string
+	Decoder string
+}
+
+// API is everything the Python templates need from an OpenAPI document
+type API struct {
+	Title      string
+	Version    string
+	Models     []Model
+	Operations []Operation
+	Streams    []Stream
+}
+
+// loadOpenAPI reads an OpenAPI document into the models, operations and
+// streams of the generated client
+func loadOpenAPI(path string) (*API, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var spec openAPISpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	api := &API{Title: spec.Info.Title, Version: spec.Info.Version}
+	components := make(map[string]*schema)
+	for _, item := range spec.Components.Schemas {
+		name := item.Key.(string)
+		s := new(schema)
+		if err := remarshal(item.Value, s); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+		components[name] = s
+
+		model := Model{Name: name}
+		for _, property := range s.Properties {
+			key := property.Key.(string)
+			p, err := s.property(key)
+			if err != nil {
+				return nil, fmt.Errorf("schema %s: %w", name, err)
+			}
+			model.Fields = append(model.Fields, Field{
+				Name:    pythonIdentifier(key),
+				JSON:    key,
+				Type:    pythonType(p),
+				Decoder: pythonDecoder(p),
+			})
+		}
+		api.Models = append(api.Models, model)
+	}
+
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		methods := make([]string, 0, len(spec.Paths[path]))
+		for method := range spec.Paths[path] {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			op, err := buildOperation(path, method, spec.Paths[path][method], components)
+			if err != nil {
+				return nil, err
+			}
+			api.Operations = append(api.Operations, op)
+		}
+	}
+
+	streamTypes := make([]string, 0, len(spec.Streams))
+	for messageType := range spec.Streams {
+		streamTypes = append(streamTypes, messageType)
+	}
+	sort.Strings(streamTypes)
+	for _, messageType := range streamTypes {
+		api.Streams = append(api.Streams, Stream{Type: messageType, Decoder: pythonDecoder(spec.Streams[messageType])})
+	}
+	return api, nil
+}
+
+// buildOperation describes one path and method as a client method
+func buildOperation(path, method string, op *operation, components map[string]*schema) (Operation, error) {
+	if op.OperationID == "" {
+		return Operation{}, fmt.Errorf("%s %s has no operationId", strings.ToUpper(method), path)
+	}
+	result := Operation{
+		Method:     strings.ToUpper(method),
+		Name:       snakeCase(op.OperationID),
+		Summary:    op.Summary,
+		Path:       path,
+		Result:     op.Result,
+		ReturnType: "None",
+	}
+	for _, p := range op.Parameters {
+		param := Param{Name: pythonIdentifier(p.Name), Wire: p.Name, Type: pythonType(&p.Schema), Required: p.Required}
+		switch p.In {
+		case "path":
+			result.PathParams = append(result.PathParams, param)
+			result.Path = strings.Replace(result.Path, "{"+p.Name+"}", "{_quote("+param.Name+")}", 1)
+		case "query":
+			result.QueryParams = append(result.QueryParams, param)
+		default:
+			return Operation{}, fmt.Errorf("%s: unsupported parameter location %q", op.OperationID, p.In)
+		}
+	}
+	if op.RequestBody != nil {
+		if body, ok := op.RequestBody.Content["application/json"]; ok && body.Schema != nil {
+			result.BodyType = pythonType(body.Schema)
+		}
+	}
+
+	response, ok := op.Responses["200"].Content["application/json"]
+	if !ok || response.Schema == nil {
+		return result, nil
+	}
+	returned := response.Schema
+	if op.Result != "" {
+		component, ok := components[refName(returned.Ref)]
+		if !ok {
+			return Operation{}, fmt.Errorf("%s: x-result needs a response referencing a component schema", op.OperationID)
+		}
+		property, err := component.property(op.Result)
+		if err != nil {
+			return Operation{}, fmt.Errorf("%s: x-result: %w", op.OperationID, err)
+		}
+		returned = property
+	}
+	result.ReturnType = pythonType(returned)
+	result.Decoder = pythonDecoder(returned)
+	return result, nil
+}
+
+// refName returns the schema name of a local $ref
+func refName(ref string) string {
+	return strings.TrimPrefix(ref, "#/components/schemas/")
+}
+
+// remarshal decodes a generic YAML value into a typed one
+func remarshal(in, out interface{}) error {
+	data, err := yaml.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(data, out)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ProtoField is a field of a proto3 message
+type ProtoField struct {
+	Name     string // Python attribute name
+	Number   int
+	Type     string // Proto scalar type, or the message name
+	Message  bool
+	Repeated bool
+}
+
+// PythonType returns the field's type annotation
+func (f ProtoField) PythonType() string {
+	var t string
+	switch {
+	case f.Message:
+		t = f.Type
+	case f.Type == "string":
+		t = "str"
+	case f.Type == "bytes":
+		t = "bytes"
+	case f.Type == "bool":
+		t = "bool"
+	default:
+		t = "int"
+	}
+	if f.Repeated {
+		return "List[" + t + "]"
+	}
+	if f.Message {
+		return "Optional[" + t + "]"
+	}
+	return t
+}
+
+// Default returns the field's Python default value
+func (f ProtoField) Default() string {
+	switch {
+	case f.Repeated:
+		return "field(default_factory=list)"
+	case f.Message:
+		return "None"
+	case f.Type == "string":
+		return `""`
+	case f.Type == "bytes":
+		return `b""`
+	case f.Type == "bool":
+		return "False"
+	}
+	return "0"
+}
+
+// WireType returns the protobuf wire type of the field's values
+func (f ProtoField) WireType() int {
+	if f.Message || f.Type == "string" || f.Type == "bytes" {
+		return 2
+	}
+	return 0
+}
+
+// ProtoMessage is a proto3 message
+type ProtoMessage struct {
+	Name   string
+	Fields []ProtoField
+}
+
+// ProtoRPC is a method of a proto service
+type ProtoRPC struct {
+	Name            string // Python method name
+	Method          string // Full gRPC method path
+	Request         string
+	Response        string
+	ServerStreaming bool
+	Comment         string
+}
+
+// ProtoFile holds the messages and services of a .proto file
+type ProtoFile struct {
+	Package  string
+	Messages []ProtoMessage
+	Services map[string][]ProtoRPC
+}
+
+var (
+	protoComment  = regexp.MustCompile(`(?m)^\s*//\s?(.*)$`)
+	protoPackage  = regexp.MustCompile(`(?m)^package\s+([\w.]+)\s*;`)
+	protoBlock    = regexp.MustCompile(`(?s)(message|service)\s+(\w+)\s*\{(.*?)\n\}`)
+	protoField    = regexp.MustCompile(`^(repeated\s+)?(\w+)\s+(\w+)\s*=\s*(\d+)\s*;`)
+	protoRPC      = regexp.MustCompile(`^rpc\s+(\w+)\s*\(\s*(\w+)\s*\)\s*returns\s*\(\s*(stream\s+)?(\w+)\s*\)`)
+	protoScalars  = map[string]bool{"string": true, "bytes": true, "bool": true, "int32": true, "int64": true, "uint32": true, "uint64": true}
+	protoComments = regexp.MustCompile(`//.*`)
+)
+
+// loadProto parses the flat proto3 messages and services of a .proto file.
+// Nested messages, enums, maps and oneofs are not supported.
+func loadProto(path string) (*ProtoFile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	source := string(data)
+
+	file := &ProtoFile{Services: make(map[string][]ProtoRPC)}
+	if match := protoPackage.FindStringSubmatch(source); match != nil {
+		file.Package = match[1]
+	}
+
+	messages := make(map[string]bool)
+	for _, block := range protoBlock.FindAllStringSubmatch(source, -1) {
+		if block[1] == "message" {
+			messages[block[2]] = true
+		}
+	}
+
+	for _, block := range protoBlock.FindAllStringSubmatch(source, -1) {
+		kind, name, body := block[1], block[2], block[3]
+		if kind == "service" {
+			rpcs, err := parseService(file.Package, name, body)
+			if err != nil {
+				return nil, err
+			}
+			file.Services[name] = rpcs
+			continue
+		}
+
+		message := ProtoMessage{Name: name}
+		for _, line := range strings.Split(protoComments.ReplaceAllString(body, ""), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			match := protoField.FindStringSubmatch(line)
+			if match == nil {
+				return nil, fmt.Errorf("message %s: unsupported line %q", name, line)
+			}
+			number, _ := strconv.Atoi(match[4])
+			field := ProtoField{Name: pythonIdentifier(match[3]), Number: number, Type: match[2], Repeated: match[1] != ""}
+			switch {
+			case messages[field.Type]:
+				field.Message = true
+			case !protoScalars[field.Type]:
+				return nil, fmt.Errorf("message %s: unsupported type %s", name, field.Type)
+			}
+			message.Fields = append(message.Fields, field)
+		}
+		file.Messages = append(file.Messages, message)
+	}
+	return file, nil
+}
+
+// parseService reads the rpcs of a service body, with their leading comments
+func parseService(pkg, service, body string) ([]ProtoRPC, error) {
+	var rpcs []ProtoRPC
+	var comment []string
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if match := protoComment.FindStringSubmatch(line); match != nil {
+			comment = append(comment, match[1])
+			continue
+		}
+		if line == "" {
+			comment = nil
+			continue
+		}
+		match := protoRPC.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("service %s: unsupported line %q", service, line)
+		}
+		rpcs = append(rpcs, ProtoRPC{
+			Name:            snakeCase(match[1]),
+			Method:          "/" + pkg + "." + service + "/" + match[1],
+			Request:         match[2],
+			Response:        match[4],
+			ServerStreaming: match[3] != "",
+			Comment:         strings.Join(comment, " "),
+		})
+		comment = nil
+	}
+	return rpcs, nil
+}
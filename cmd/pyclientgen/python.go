@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// pythonKeywords are JSON names that need a trailing underscore as attributes
+var pythonKeywords = map[string]bool{
+	"and": true, "as": true, "assert": true, "async": true, "await": true, "break": true,
+	"class": true, "continue": true, "def": true, "del": true, "elif": true, "else": true,
+	"except": true, "finally": true, "for": true, "from": true, "global": true, "if": true,
+	"import": true, "in": true, "is": true, "lambda": true, "nonlocal": true, "not": true,
+	"or": true, "pass": true, "raise": true, "return": true, "try": true, "while": true,
+	"with": true, "yield": true, "None": true, "True": true, "False": true,
+}
+
+// pythonIdentifier makes a JSON or proto name usable as a Python attribute
+func pythonIdentifier(name string) string {
+	if pythonKeywords[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// snakeCase converts an operation ID such as listGPUs or getGPUMetrics to
+// list_gpus and get_gpu_metrics
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			previous := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// A lowercase plural after an acronym, as in GPUs, stays attached
+			plural := nextLower && runes[i+1] == 's' && (i+2 == len(runes) || unicode.IsUpper(runes[i+2]))
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextLower && !plural) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// pythonType returns the type annotation of a schema
+func pythonType(s *schema) string {
+	if s.Ref != "" {
+		return refName(s.Ref)
+	}
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			return "datetime"
+		}
+		return "str"
+	case "integer":
+		return "int"
+	case "number":
+		return "float"
+	case "boolean":
+		return "bool"
+	case "array":
+		if s.Items == nil {
+			return "List[Any]"
+		}
+		return "List[" + pythonType(s.Items) + "]"
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "Dict[str, " + pythonType(s.AdditionalProperties) + "]"
+		}
+		return "Dict[str, Any]"
+	}
+	return "Any"
+}
+
+// pythonDecoder returns a Python callable converting a JSON value of the
+// schema, using the helpers of the models template
+func pythonDecoder(s *schema) string {
+	if s.Ref != "" {
+		return refName(s.Ref) + ".from_dict"
+	}
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			return "_parse_time"
+		}
+		return "str"
+	case "integer":
+		return "int"
+	case "number":
+		return "float"
+	case "boolean":
+		return "bool"
+	case "array":
+		if s.Items != nil {
+			return "_list_of(" + pythonDecoder(s.Items) + ")"
+		}
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "_map_of(" + pythonDecoder(s.AdditionalProperties) + ")"
+		}
+	}
+	return "_identity"
+}
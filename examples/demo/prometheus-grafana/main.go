@@ -207,9 +207,12 @@ func main() {
 	}
 }
 
+// demoTickInterval is how often demo metrics are generated
+const demoTickInterval = 5 * time.Second
+
 // generateDemoMetrics generates realistic demo metrics for Prometheus/Grafana visualization
 func generateDemoMetrics(ctx context.Context, exporter *observability.PrometheusExporter, integration *observability.GPUMetricsIntegration) {
-	ticker := time.NewTicker(5 * time.Second)
+	ticker := time.NewTicker(demoTickInterval)
 	defer ticker.Stop()
 
 	gpuCounter := 0
@@ -271,26 +274,26 @@ func generateSystemMetrics(exporter *observability.PrometheusExporter) {
 		powerLimit := 400.0
 
 		// Update metrics
-		exporter.UpdateMetric("gpu_utilization_percent", utilization, labels)
-		exporter.UpdateMetric("gpu_temperature_celsius", temperature, labels)
-		exporter.UpdateMetric("gpu_memory_used_bytes", memoryUsed, labels)
-		exporter.UpdateMetric("gpu_memory_total_bytes", memoryTotal, labels)
-		exporter.UpdateMetric("gpu_power_draw_watts", powerDraw, labels)
-		exporter.UpdateMetric("gpu_power_limit_watts", powerLimit, labels)
+		logMetricError(exporter.SetGauge("gpu_utilization_percent", utilization, labels))
+		logMetricError(exporter.SetGauge("gpu_temperature_celsius", temperature, labels))
+		logMetricError(exporter.SetGauge("gpu_memory_used_bytes", memoryUsed, labels))
+		logMetricError(exporter.SetGauge("gpu_memory_total_bytes", memoryTotal, labels))
+		logMetricError(exporter.SetGauge("gpu_power_draw_watts", powerDraw, labels))
+		logMetricError(exporter.SetGauge("gpu_power_limit_watts", powerLimit, labels))
 
 		// Clock speeds
-		exporter.UpdateMetric("gpu_clock_graphics_mhz", 1400.0+200.0*(utilization/100.0), labels)
-		exporter.UpdateMetric("gpu_clock_memory_mhz", 1215.0, labels)
+		logMetricError(exporter.SetGauge("gpu_clock_graphics_mhz", 1400.0+200.0*(utilization/100.0), labels))
+		logMetricError(exporter.SetGauge("gpu_clock_memory_mhz", 1215.0, labels))
 
 		// Process count and efficiency
 		processes := float64(1 + i%3)
-		exporter.UpdateMetric("gpu_process_count", processes, labels)
+		logMetricError(exporter.SetGauge("gpu_process_count", processes, labels))
 
 		efficiency := utilization / (powerDraw / 100.0) / 100.0
-		exporter.UpdateMetric("gpu_efficiency_score", efficiency, labels)
+		logMetricError(exporter.SetGauge("gpu_efficiency_score", efficiency, labels))
 
 		idleTime := 100.0 - utilization
-		exporter.UpdateMetric("gpu_idle_time_percent", idleTime, labels)
+		logMetricError(exporter.SetGauge("gpu_idle_time_percent", idleTime, labels))
 
 		// Health status (0=unhealthy, 1=warning, 2=healthy)
 		var healthStatus float64 = 2
@@ -306,14 +309,14 @@ func generateSystemMetrics(exporter *observability.PrometheusExporter) {
 		}
 		statusNames := []string{"unhealthy", "warning", "healthy"}
 		healthLabels["status"] = statusNames[int(healthStatus)]
-		exporter.UpdateMetric("gpu_health_status", healthStatus, healthLabels)
+		logMetricError(exporter.SetGauge("gpu_health_status", healthStatus, healthLabels))
 	}
 
 	// System-wide metrics
-	exporter.UpdateMetric("gpus_total", 4, map[string]string{"node": "cluster", "gpu_type": "mixed"})
-	exporter.UpdateMetric("gpus_available", 3, map[string]string{"node": "cluster", "gpu_type": "mixed"})
-	exporter.UpdateMetric("cluster_utilization_percent", 55.0+20.0*getWaveValue(baseTime, 5*time.Minute), map[string]string{})
-	exporter.UpdateMetric("cluster_efficiency_score", 0.65+0.15*getWaveValue(baseTime, 7*time.Minute), map[string]string{})
+	logMetricError(exporter.SetGauge("gpus_total", 4, map[string]string{"node": "cluster", "gpu_type": "mixed"}))
+	logMetricError(exporter.SetGauge("gpus_available", 3, map[string]string{"node": "cluster", "gpu_type": "mixed"}))
+	logMetricError(exporter.SetGauge("cluster_utilization_percent", 55.0+20.0*getWaveValue(baseTime, 5*time.Minute), map[string]string{}))
+	logMetricError(exporter.SetGauge("cluster_efficiency_score", 0.65+0.15*getWaveValue(baseTime, 7*time.Minute), map[string]string{}))
 }
 
 // generateWorkloadMetrics creates scheduling and workload metrics
@@ -323,85 +326,86 @@ func generateWorkloadMetrics(exporter *observability.PrometheusExporter, counter
 	pendingHigh := 3.0 + 5.0*getWaveValue(baseTime, 3*time.Minute)
 	pendingLow := 1.0 + 2.0*getWaveValue(baseTime, 4*time.Minute)
 
-	exporter.UpdateMetric("workloads_pending", pendingHigh, map[string]string{"priority": "high"})
-	exporter.UpdateMetric("workloads_pending", pendingLow, map[string]string{"priority": "low"})
+	logMetricError(exporter.SetGauge("workloads_pending", pendingHigh, map[string]string{"priority": "high"}))
+	logMetricError(exporter.SetGauge("workloads_pending", pendingLow, map[string]string{"priority": "low"}))
 
 	// Running workloads
-	exporter.UpdateMetric("workloads_running", 2, map[string]string{"gpu_id": "gpu-0", "priority": "high"})
-	exporter.UpdateMetric("workloads_running", 1, map[string]string{"gpu_id": "gpu-1", "priority": "low"})
-	exporter.UpdateMetric("workloads_running", 1, map[string]string{"gpu_id": "gpu-2", "priority": "high"})
+	logMetricError(exporter.SetGauge("workloads_running", 2, map[string]string{"gpu_id": "gpu-0", "priority": "high"}))
+	logMetricError(exporter.SetGauge("workloads_running", 1, map[string]string{"gpu_id": "gpu-1", "priority": "low"}))
+	logMetricError(exporter.SetGauge("workloads_running", 1, map[string]string{"gpu_id": "gpu-2", "priority": "high"}))
 
 	// Completed workloads (counters)
 	if counter%10 == 0 {
-		exporter.UpdateMetric("workloads_completed", 1, map[string]string{"status": "success", "priority": "high"})
+		logMetricError(exporter.IncCounter("workloads_completed", 1, map[string]string{"status": "success", "priority": "high"}))
 	}
 	if counter%15 == 0 {
-		exporter.UpdateMetric("workloads_completed", 1, map[string]string{"status": "success", "priority": "low"})
+		logMetricError(exporter.IncCounter("workloads_completed", 1, map[string]string{"status": "success", "priority": "low"}))
 	}
 	if counter%50 == 0 {
-		exporter.UpdateMetric("workloads_completed", 1, map[string]string{"status": "failed", "priority": "high"})
+		logMetricError(exporter.IncCounter("workloads_completed", 1, map[string]string{"status": "failed", "priority": "high"}))
 	}
 
 	// Scheduling performance
 	schedulingDuration := 0.1 + 0.4*getWaveValue(baseTime, 2*time.Minute)
-	exporter.UpdateMetric("scheduling_duration_seconds", schedulingDuration, map[string]string{"strategy": "least_utilized"})
+	logMetricError(exporter.ObserveHistogram("scheduling_duration_seconds", schedulingDuration, map[string]string{"strategy": "least_utilized"}))
 
 	if counter%5 == 0 {
-		exporter.UpdateMetric("scheduling_decisions_total", 1, map[string]string{"strategy": "least_utilized", "outcome": "success"})
+		logMetricError(exporter.IncCounter("scheduling_decisions_total", 1, map[string]string{"strategy": "least_utilized", "outcome": "success"}))
 	}
 
 	efficiency := 75.0 + 15.0*getWaveValue(baseTime, 6*time.Minute)
-	exporter.UpdateMetric("gpu_allocation_efficiency", efficiency, map[string]string{"strategy": "least_utilized"})
+	logMetricError(exporter.SetGauge("gpu_allocation_efficiency", efficiency, map[string]string{"strategy": "least_utilized"}))
 }
 
 // generateCostMetrics creates cost tracking metrics
 func generateCostMetrics(exporter *observability.PrometheusExporter) {
 	baseTime := time.Now()
+	tickHours := demoTickInterval.Hours()
 
-	// Hourly cost variations
-	inferenceCost := 5.0 + 3.0*getWaveValue(baseTime, 4*time.Minute)
-	trainingCost := 15.0 + 10.0*getWaveValue(baseTime, 8*time.Minute)
+	// Hourly cost rates vary; counters accrue the cost of one tick at that rate
+	inferenceCost := (5.0 + 3.0*getWaveValue(baseTime, 4*time.Minute)) * tickHours
+	trainingCost := (15.0 + 10.0*getWaveValue(baseTime, 8*time.Minute)) * tickHours
 
-	exporter.UpdateMetric("cost_total_dollars", inferenceCost, map[string]string{
+	logMetricError(exporter.IncCounter("cost_total_dollars", inferenceCost, map[string]string{
 		"operation": "inference",
 		"model_id":  "gpt-model",
 		"currency":  "USD",
-	})
+	}))
 
-	exporter.UpdateMetric("cost_total_dollars", trainingCost, map[string]string{
+	logMetricError(exporter.IncCounter("cost_total_dollars", trainingCost, map[string]string{
 		"operation": "training",
 		"model_id":  "llama-model",
 		"currency":  "USD",
-	})
+	}))
 
 	// Cost per hour rates
-	exporter.UpdateMetric("cost_per_hour_dollars", 3.06, map[string]string{
+	logMetricError(exporter.SetGauge("cost_per_hour_dollars", 3.06, map[string]string{
 		"resource_type": "a100",
 		"currency":      "USD",
-	})
+	}))
 
-	exporter.UpdateMetric("cost_per_hour_dollars", 0.526, map[string]string{
+	logMetricError(exporter.SetGauge("cost_per_hour_dollars", 0.526, map[string]string{
 		"resource_type": "t4",
 		"currency":      "USD",
-	})
+	}))
 
-	// GPU hours consumed
-	a100Hours := 2.5 + 1.0*getWaveValue(baseTime, 5*time.Minute)
-	t4Hours := 1.2 + 0.8*getWaveValue(baseTime, 6*time.Minute)
+	// GPU hours consumed during this tick by a varying number of busy GPUs
+	a100Hours := (2.5 + 1.0*getWaveValue(baseTime, 5*time.Minute)) * tickHours
+	t4Hours := (1.2 + 0.8*getWaveValue(baseTime, 6*time.Minute)) * tickHours
 
-	exporter.UpdateMetric("gpu_hours_consumed", a100Hours, map[string]string{
+	logMetricError(exporter.IncCounter("gpu_hours_consumed", a100Hours, map[string]string{
 		"gpu_type":      "a100",
 		"workload_type": "training",
-	})
+	}))
 
-	exporter.UpdateMetric("gpu_hours_consumed", t4Hours, map[string]string{
+	logMetricError(exporter.IncCounter("gpu_hours_consumed", t4Hours, map[string]string{
 		"gpu_type":      "t4",
 		"workload_type": "inference",
-	})
+	}))
 
 	// Monthly cost estimates
-	exporter.UpdateMetric("estimated_monthly_cost_dollars", 2200.0+300.0*getWaveValue(baseTime, 10*time.Minute),
-		map[string]string{"resource_type": "gpu_cluster"})
+	logMetricError(exporter.SetGauge("estimated_monthly_cost_dollars", 2200.0+300.0*getWaveValue(baseTime, 10*time.Minute),
+		map[string]string{"resource_type": "gpu_cluster"}))
 }
 
 // generateAlertMetrics creates alert and health metrics
@@ -412,19 +416,19 @@ func generateAlertMetrics(exporter *observability.PrometheusExporter, integratio
 	alertCounter := int(baseTime.Unix()/30) % 10
 
 	if alertCounter == 0 {
-		exporter.UpdateMetric("alerts_total", 1, map[string]string{
+		logMetricError(exporter.IncCounter("alerts_total", 1, map[string]string{
 			"severity": "warning",
 			"type":     "temperature",
 			"source":   "gpu_monitor",
-		})
+		}))
 	}
 
 	if alertCounter == 5 {
-		exporter.UpdateMetric("alerts_total", 1, map[string]string{
+		logMetricError(exporter.IncCounter("alerts_total", 1, map[string]string{
 			"severity": "info",
 			"type":     "utilization",
 			"source":   "scheduler",
-		})
+		}))
 	}
 
 	// Active alerts
@@ -434,15 +438,15 @@ func generateAlertMetrics(exporter *observability.PrometheusExporter, integratio
 		activeCritical = 1
 	}
 
-	exporter.UpdateMetric("active_alerts", activeWarnings, map[string]string{
+	logMetricError(exporter.SetGauge("active_alerts", activeWarnings, map[string]string{
 		"severity": "warning",
 		"type":     "temperature",
-	})
+	}))
 
-	exporter.UpdateMetric("active_alerts", activeCritical, map[string]string{
+	logMetricError(exporter.SetGauge("active_alerts", activeCritical, map[string]string{
 		"severity": "critical",
 		"type":     "memory",
-	})
+	}))
 
 	// System health
 	components := []string{"gpu_monitor", "scheduler", "cost_tracker"}
@@ -452,14 +456,14 @@ func generateAlertMetrics(exporter *observability.PrometheusExporter, integratio
 			health = 1.0 // degraded
 		}
 
-		exporter.UpdateMetric("component_health_status", health, map[string]string{
+		logMetricError(exporter.SetGauge("component_health_status", health, map[string]string{
 			"component": component,
-		})
+		}))
 
 		uptime := float64(time.Now().Unix() - 1000*int64(i))
-		exporter.UpdateMetric("system_uptime_seconds", uptime, map[string]string{
+		logMetricError(exporter.SetGauge("system_uptime_seconds", uptime, map[string]string{
 			"component": component,
-		})
+		}))
 	}
 }
 
@@ -469,3 +473,10 @@ func getWaveValue(baseTime time.Time, period time.Duration) float64 {
 	radians := (float64(elapsed) / float64(period)) * 2.0 * math.Pi
 	return (1.0 + 0.8*math.Sin(radians)) / 2.0
 }
+
+// logMetricError reports updates rejected by the exporter, e.g. a type mismatch
+func logMetricError(err error) {
+	if err != nil {
+		log.Printf("metric update rejected: %v", err)
+	}
+}
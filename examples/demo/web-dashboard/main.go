@@ -1,16 +1,24 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"math/rand"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	_ "github.com/lib/pq"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
 	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/migrate"
 	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/secrets"
 )
 
 func main() {
@@ -74,6 +82,39 @@ func main() {
 	fmt.Println("🌐 Setting up web dashboard...")
 	dashboard := observability.NewWebDashboard(monitoringService, mockCollector, prometheusExporter, dashboardConfig)
 
+	// Keep API keys in a postgres database when one is configured; opening
+	// the store applies pending schema migrations
+	if databaseURL := os.Getenv("AGENTAFLOW_DATABASE_URL"); databaseURL != "" {
+		db, err := sql.Open("postgres", databaseURL)
+		if err != nil {
+			log.Fatalf("Failed to open database: %v", err)
+		}
+		defer db.Close()
+		keys, err := apikeys.OpenSQLStore(context.Background(), db, migrate.DialectPostgres)
+		if err != nil {
+			log.Fatalf("Failed to open API key store: %v", err)
+		}
+		dashboard.SetAPIKeyStore(keys)
+		fmt.Println("🔑 API keys stored in the database, schema up to date")
+	}
+
+	// Apply a signed configuration bundle before serving, when one is configured
+	if bundlePath := os.Getenv("AGENTAFLOW_CONFIG_BUNDLE"); bundlePath != "" {
+		loader, err := observability.NewBundleLoader(observability.BundleConfig{
+			TrustedKeys: strings.Split(os.Getenv("AGENTAFLOW_BUNDLE_TRUSTED_KEYS"), ","),
+			StateFile:   os.Getenv("AGENTAFLOW_BUNDLE_STATE"),
+		})
+		if err != nil {
+			log.Fatalf("Failed to create bundle loader: %v", err)
+		}
+		loader.SetSecretResolver(secrets.NewResolver(secrets.DefaultConfig()))
+		status, err := loader.LoadAndApply(bundlePath, observability.BundleTargets{Integration: integration, Dashboard: dashboard})
+		if err != nil {
+			log.Fatalf("Failed to apply configuration bundle: %v", err)
+		}
+		fmt.Printf("📦 Applied configuration bundle %s version %d signed by %s\n", status.Source, status.Version, status.KeyID)
+	}
+
 	// Start mock metrics collection
 	fmt.Println("📡 Starting MOCK GPU metrics collection...")
 	if err := mockCollector.Start(); err != nil {
@@ -0,0 +1,335 @@
+// Package agent runs on GPU nodes: it collects metrics locally and uploads
+// them to the AgentaFlow server, which measures and corrects the node's
+// clock skew from each upload.
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+)
+
+// Defaults used when a Config leaves them unset
+const (
+	DefaultInterval        = 15 * time.Second
+	DefaultMaxBatchSamples = 5000
+)
+
+// Server receives uploads; *client.Client satisfies it
+type Server interface {
+	PushMetrics(ctx context.Context, batch observability.AgentBatch) (*observability.AgentIngestResult, error)
+}
+
+// Config configures an agent
+type Config struct {
+	Node     string        `yaml:"node" json:"node"`         // Names this node to the server; the hostname when empty
+	Interval time.Duration `yaml:"interval" json:"interval"` // Time between uploads, each carrying every collection cycle since the last
+
+	// Samples per upload; a backlog built up while the server was
+	// unreachable goes in several
+	MaxBatchSamples int `yaml:"max_batch_samples" json:"max_batch_samples"`
+
+	// Directory spooling batches until the server acknowledges them, so
+	// they survive an outage of the server or a restart of the agent;
+	// batches are kept in memory only when empty
+	SpoolDir      string `yaml:"spool_dir" json:"spool_dir"`
+	SpoolMaxBytes int64  `yaml:"spool_max_bytes" json:"spool_max_bytes"` // The oldest batches are dropped beyond this
+}
+
+// Agent uploads the samples its collector took since the last upload
+type Agent struct {
+	config    Config
+	collector gpu.MetricsCollectorInterface
+	server    Server
+
+	sent     map[string]time.Time        // Newest sample uploaded per GPU
+	session  string                      // Distinguishes this run's batch numbers from a previous run's
+	seq      uint64                      // Number of the last batch built
+	inflight *observability.AgentBatch   // Built but not acknowledged; resent unchanged
+	spool    *Spool                      // Optional, holds batches instead of inflight
+	dropped  []observability.SampleRange // Dropped from the full spool; reported with the next upload
+
+	uploads   int
+	failures  int
+	resent    int
+	samples   int
+	lost      int           // Samples dropped from the full spool
+	skew      time.Duration // As the server last measured it
+	corrected bool          // The server is correcting this node's timestamps
+	lastErr   error
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+	mu     sync.Mutex
+}
+
+// New creates an agent uploading what collector collects to server
+func New(config Config, collector gpu.MetricsCollectorInterface, server Server) (*Agent, error) {
+	if collector == nil || server == nil {
+		return nil, fmt.Errorf("agent needs a metrics collector and a server")
+	}
+	if config.Node == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("node name is required: %w", err)
+		}
+		config.Node = hostname
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.MaxBatchSamples <= 0 {
+		config.MaxBatchSamples = DefaultMaxBatchSamples
+	}
+
+	session := make([]byte, 8)
+	if _, err := rand.Read(session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	agent := &Agent{
+		config:    config,
+		collector: collector,
+		server:    server,
+		sent:      make(map[string]time.Time),
+		session:   hex.EncodeToString(session),
+	}
+	if config.SpoolDir != "" {
+		spool, err := OpenSpool(config.SpoolDir, config.SpoolMaxBytes)
+		if err != nil {
+			return nil, err
+		}
+		agent.spool = spool
+	}
+	return agent, nil
+}
+
+// pending returns the samples taken since the last upload, oldest first
+func (a *Agent) pending() []gpu.GPUMetrics {
+	var metrics []gpu.GPUMetrics
+	for gpuID := range a.collector.GetLatestMetrics() {
+		metrics = append(metrics, a.collector.GetMetricsHistory(gpuID, a.sent[gpuID])...)
+	}
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return metrics[i].Timestamp.Before(metrics[j].Timestamp)
+	})
+	return metrics
+}
+
+// Upload sends the samples taken since the last upload, in batches of at
+// most MaxBatchSamples. A batch that fails is resent unchanged by the next
+// call, so the server can recognise one it stored before the failure. With
+// a spool, samples are spooled first and the spool is then sent oldest
+// first.
+func (a *Agent) Upload(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.spool != nil {
+		if err := a.spoolPending(); err != nil {
+			a.lastErr = err
+			return err
+		}
+	}
+	for {
+		batch, err := a.next()
+		if err != nil {
+			a.lastErr = err
+			return err
+		}
+		if batch == nil {
+			return nil
+		}
+		batch.Dropped = a.dropped
+
+		result, err := a.server.PushMetrics(ctx, *batch)
+		a.lastErr = err
+		if err != nil {
+			a.failures++
+			return fmt.Errorf("failed to upload batch %d of %d samples: %w", batch.Seq, len(batch.Metrics), err)
+		}
+		if result.Duplicate {
+			a.resent++
+		}
+		a.dropped = a.dropped[len(batch.Dropped):]
+		if a.spool != nil {
+			if err := a.spool.RemoveOldest(); err != nil {
+				return err
+			}
+		}
+		a.acknowledge(batch, result)
+	}
+}
+
+// next returns the batch to send: the oldest spooled one, or the one in
+// flight, or one built from the samples taken since the last upload. It
+// returns nil when there is nothing to send.
+func (a *Agent) next() (*observability.AgentBatch, error) {
+	if a.spool != nil {
+		batch, err := a.spool.Oldest()
+		if batch != nil {
+			spooled, _ := a.spool.Len()
+			batch.Backlog = spooled - 1
+		}
+		return batch, err
+	}
+
+	if a.inflight == nil {
+		metrics := a.pending()
+		if len(metrics) == 0 {
+			return nil, nil
+		}
+		a.inflight = a.newBatch(metrics)
+		// Tell the server more is coming
+		if len(metrics) > a.config.MaxBatchSamples {
+			a.inflight.Backlog = 1
+		}
+	}
+	return a.inflight, nil
+}
+
+// newBatch numbers a batch of at most MaxBatchSamples of metrics
+func (a *Agent) newBatch(metrics []gpu.GPUMetrics) *observability.AgentBatch {
+	if len(metrics) > a.config.MaxBatchSamples {
+		metrics = metrics[:a.config.MaxBatchSamples]
+	}
+	a.seq++
+	return &observability.AgentBatch{Node: a.config.Node, Session: a.session, Seq: a.seq, Metrics: metrics}
+}
+
+// spoolPending moves the samples taken since the last upload to the spool
+func (a *Agent) spoolPending() error {
+	for {
+		metrics := a.pending()
+		if len(metrics) == 0 {
+			return nil
+		}
+		batch := a.newBatch(metrics)
+		dropped, err := a.spool.Append(*batch)
+		for _, span := range dropped {
+			log.Printf("agent: spool is full, dropped %d samples taken from %s to %s", span.Samples, span.From.Format(time.RFC3339), span.To.Format(time.RFC3339))
+			a.lost += span.Samples
+		}
+		a.dropped = append(a.dropped, dropped...)
+		if err != nil {
+			return err
+		}
+		a.advance(batch.Metrics)
+	}
+}
+
+// advance moves past samples handed to the server or the spool
+func (a *Agent) advance(metrics []gpu.GPUMetrics) {
+	for _, sample := range metrics {
+		if sample.Timestamp.After(a.sent[sample.GPUID]) {
+			a.sent[sample.GPUID] = sample.Timestamp
+		}
+	}
+}
+
+// acknowledge advances past a batch the server stored
+func (a *Agent) acknowledge(batch *observability.AgentBatch, result *observability.AgentIngestResult) {
+	a.advance(batch.Metrics)
+	a.inflight = nil
+	a.uploads++
+	a.samples += len(batch.Metrics)
+	a.skew = result.ClockSkew
+	if result.Corrected != a.corrected {
+		if result.Corrected {
+			log.Printf("agent: clock on %s is %s off the server's; the server is correcting its timestamps, sync it with NTP", a.config.Node, result.ClockSkew.Round(time.Millisecond))
+		} else {
+			log.Printf("agent: clock on %s is back in line with the server's", a.config.Node)
+		}
+		a.corrected = result.Corrected
+	}
+}
+
+// Start uploads every interval until stopped
+func (a *Agent) Start() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.stopCh != nil {
+		return
+	}
+	a.stopCh = make(chan struct{})
+	a.doneCh = make(chan struct{})
+	go a.run(a.stopCh, a.doneCh)
+}
+
+// Stop halts uploading
+func (a *Agent) Stop() {
+	a.mu.Lock()
+	stopCh, doneCh := a.stopCh, a.doneCh
+	a.stopCh, a.doneCh = nil, nil
+	a.mu.Unlock()
+
+	if stopCh == nil {
+		return
+	}
+	close(stopCh)
+	<-doneCh
+}
+
+// run uploads until stopped
+func (a *Agent) run(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	ticker := time.NewTicker(a.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := a.Upload(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("agent: %v", err)
+			}
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// GetStats returns upload counts and the clock skew the server measured
+func (a *Agent) GetStats() map[string]interface{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stats := map[string]interface{}{
+		"node":               a.config.Node,
+		"uploads":            a.uploads,
+		"failures":           a.failures,
+		"resent":             a.resent,
+		"samples":            a.samples,
+		"clock_skew_seconds": a.skew.Seconds(),
+		"clock_corrected":    a.corrected,
+	}
+	if a.inflight != nil {
+		stats["unacknowledged_samples"] = len(a.inflight.Metrics)
+	}
+	if a.spool != nil {
+		batches, size := a.spool.Len()
+		stats["spooled_batches"] = batches
+		stats["spool_bytes"] = size
+		stats["lost_samples"] = a.lost
+	}
+	if a.lastErr != nil {
+		stats["last_error"] = a.lastErr.Error()
+	}
+	return stats
+}
@@ -0,0 +1,192 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/client"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+)
+
+func newIngest(t *testing.T, collector *gpu.MetricsCollector) *observability.AgentIngest {
+	ingest, err := observability.NewAgentIngest(observability.DefaultAgentIngestConfig(), collector, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create ingest: %v", err)
+	}
+	return ingest
+}
+
+func TestAgentUploadsCompressedBatches(t *testing.T) {
+	received := gpu.NewMetricsCollector(time.Second)
+	ingest := newIngest(t, received)
+	handler := observability.DecompressionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch observability.AgentBatch
+		json.NewDecoder(r.Body).Decode(&batch)
+		result, err := ingest.Ingest(batch, time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(result)
+	}))
+	compressed := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			compressed++
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	config := client.DefaultConfig(server.URL)
+	config.Compression = "gzip"
+	api, err := client.New(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	// Three collection cycles of four GPUs
+	collector := gpu.NewMetricsCollector(time.Second)
+	start := time.Now().Add(-time.Minute)
+	for cycle := 0; cycle < 3; cycle++ {
+		for i := 0; i < 4; i++ {
+			collector.Ingest(gpu.GPUMetrics{GPUID: fmt.Sprint(i), Name: "NVIDIA A100-SXM4-80GB", Timestamp: start.Add(time.Duration(cycle) * 5 * time.Second), UtilizationGPU: 40})
+		}
+	}
+
+	agent, err := New(Config{Node: "node-a", MaxBatchSamples: 5}, collector, api)
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+	if err := agent.Upload(context.Background()); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	if err := agent.Upload(context.Background()); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	collector.Ingest(gpu.GPUMetrics{GPUID: "0", Timestamp: start.Add(15 * time.Second), UtilizationGPU: 50})
+	if err := agent.Upload(context.Background()); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+
+	if stats := agent.GetStats(); stats["uploads"] != 4 || stats["samples"] != 13 {
+		t.Errorf("Expected 13 samples in 4 uploads, got %v", stats)
+	}
+	if history := received.GetMetricsHistory("node-a:0", time.Time{}); len(history) != 4 || history[0].NodeID != "node-a" {
+		t.Errorf("Expected the 4 samples of node-a:0 on the server, got %v", history)
+	}
+	// Batches of a sample or two are too small to be worth compressing
+	if compressed != 2 {
+		t.Errorf("Expected the 2 full batches compressed, got %d", compressed)
+	}
+}
+
+// lossyServer stores batches in an ingest, losing some responses
+type lossyServer struct {
+	ingest *observability.AgentIngest
+	skew   time.Duration
+	lose   int // Responses still to lose
+	down   bool
+}
+
+func (s *lossyServer) PushMetrics(ctx context.Context, batch observability.AgentBatch) (*observability.AgentIngestResult, error) {
+	if s.down {
+		return nil, fmt.Errorf("connection refused")
+	}
+	now := time.Now()
+	batch.SentAt = now.Add(s.skew)
+	result, err := s.ingest.Ingest(batch, now)
+	if err != nil {
+		return nil, err
+	}
+	if s.lose > 0 {
+		s.lose--
+		return nil, fmt.Errorf("connection reset by peer")
+	}
+	return &result, nil
+}
+
+func TestAgentResendsUnacknowledgedBatches(t *testing.T) {
+	received := gpu.NewMetricsCollector(time.Second)
+	collector := gpu.NewMetricsCollector(time.Second)
+	collector.Ingest(gpu.GPUMetrics{GPUID: "0", Timestamp: time.Now().Add(-time.Second)})
+
+	server := &lossyServer{ingest: newIngest(t, received), down: true}
+	agent, err := New(Config{Node: "node-a"}, collector, server)
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+	if err := agent.Upload(context.Background()); err == nil {
+		t.Fatal("Expected the failed upload reported")
+	}
+
+	// The server stores the batch but the response is lost
+	server.down, server.lose = false, 1
+	if err := agent.Upload(context.Background()); err == nil {
+		t.Fatal("Expected the lost response reported")
+	}
+	collector.Ingest(gpu.GPUMetrics{GPUID: "0", Timestamp: time.Now()})
+
+	server.skew = 90 * time.Second
+	if err := agent.Upload(context.Background()); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	if history := received.GetMetricsHistory("node-a:0", time.Time{}); len(history) != 2 {
+		t.Errorf("Expected each sample stored once, got %d", len(history))
+	}
+	stats := agent.GetStats()
+	if stats["failures"] != 2 || stats["resent"] != 1 || stats["uploads"] != 2 || stats["clock_corrected"] != true {
+		t.Errorf("Expected the resent batch acknowledged and the skew reported, got %v", stats)
+	}
+}
+
+func TestAgentBackfillsFromItsSpool(t *testing.T) {
+	received := gpu.NewMetricsCollector(time.Second)
+	ingest, _ := observability.NewAgentIngest(observability.AgentIngestConfig{OutageAfter: time.Millisecond}, received, nil, nil)
+	server := &lossyServer{ingest: ingest}
+	collector := gpu.NewMetricsCollector(time.Second)
+	config := Config{Node: "node-a", MaxBatchSamples: 2, SpoolDir: t.TempDir()}
+
+	agent, err := New(config, collector, server)
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+	start := time.Now().Add(-time.Minute)
+	collector.Ingest(gpu.GPUMetrics{GPUID: "0", Timestamp: start})
+	if err := agent.Upload(context.Background()); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+
+	// The server goes away while the agent keeps collecting, then the agent restarts
+	server.down = true
+	for i := 1; i <= 5; i++ {
+		collector.Ingest(gpu.GPUMetrics{GPUID: "0", Timestamp: start.Add(time.Duration(i) * time.Second)})
+		agent.Upload(context.Background())
+	}
+	if stats := agent.GetStats(); stats["spooled_batches"] != 5 {
+		t.Fatalf("Expected 5 spooled batches, got %v", stats)
+	}
+	time.Sleep(5 * time.Millisecond)
+	restarted, err := New(config, gpu.NewMetricsCollector(time.Second), server)
+	if err != nil {
+		t.Fatalf("Failed to restart agent: %v", err)
+	}
+
+	server.down = false
+	if err := restarted.Upload(context.Background()); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	if history := received.GetMetricsHistory("node-a:0", time.Time{}); len(history) != 6 {
+		t.Errorf("Expected all 6 samples on the server, got %d", len(history))
+	}
+	gaps := ingest.Gaps(start, time.Now())
+	if len(gaps) != 1 || gaps[0].Status != observability.GapBackfilled {
+		t.Errorf("Expected the outage marked backfilled, got %+v", gaps)
+	}
+}
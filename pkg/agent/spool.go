@@ -0,0 +1,183 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+)
+
+// DefaultSpoolMaxBytes bounds a spool when none is configured
+const DefaultSpoolMaxBytes = 256 << 20
+
+// spoolSuffix names the files holding spooled batches
+const spoolSuffix = ".batch"
+
+// Spool keeps batches on local disk, one file each, until the server
+// acknowledges them. A full spool drops its oldest batches.
+type Spool struct {
+	dir      string
+	maxBytes int64
+
+	files []spoolFile // Oldest first
+	size  int64
+	next  uint64 // Number of the next file
+	mu    sync.Mutex
+}
+
+// spoolFile is one spooled batch
+type spoolFile struct {
+	name string
+	size int64
+}
+
+// OpenSpool opens the spool in dir, creating it if needed, with the batches
+// a previous run left there
+func OpenSpool(dir string, maxBytes int64) (*Spool, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultSpoolMaxBytes
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool directory: %w", err)
+	}
+
+	s := &Spool{dir: dir, maxBytes: maxBytes}
+	for _, entry := range entries {
+		number, err := strconv.ParseUint(strings.TrimSuffix(entry.Name(), spoolSuffix), 10, 64)
+		if err != nil || !strings.HasSuffix(entry.Name(), spoolSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", entry.Name(), err)
+		}
+		s.files = append(s.files, spoolFile{name: entry.Name(), size: info.Size()})
+		s.size += info.Size()
+		if number >= s.next {
+			s.next = number + 1
+		}
+	}
+	sort.Slice(s.files, func(i, j int) bool { return s.files[i].name < s.files[j].name })
+	return s, nil
+}
+
+// Append spools a batch, dropping the oldest batches to make room. It
+// returns the spans of samples dropped.
+func (s *Spool) Append(batch observability.AgentBatch) ([]observability.SampleRange, error) {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode batch: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var dropped []observability.SampleRange
+	for len(s.files) > 0 && s.size+int64(len(data)) > s.maxBytes {
+		if oldest, err := s.read(s.files[0]); err == nil {
+			dropped = append(dropped, sampleRange(oldest.Metrics))
+		}
+		if err := s.removeOldest(); err != nil {
+			return dropped, err
+		}
+	}
+
+	// Write then rename, so a crash never leaves a partial batch
+	name := fmt.Sprintf("%020d%s", s.next, spoolSuffix)
+	tmp := filepath.Join(s.dir, name+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return dropped, fmt.Errorf("failed to spool batch: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, name)); err != nil {
+		return dropped, fmt.Errorf("failed to spool batch: %w", err)
+	}
+	s.next++
+	s.files = append(s.files, spoolFile{name: name, size: int64(len(data))})
+	s.size += int64(len(data))
+	return dropped, nil
+}
+
+// Oldest returns the oldest spooled batch, or nil when the spool is empty.
+// Unreadable batches are discarded.
+func (s *Spool) Oldest() (*observability.AgentBatch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.files) > 0 {
+		batch, err := s.read(s.files[0])
+		if err == nil {
+			return batch, nil
+		}
+		log.Printf("agent: discarding unreadable spooled batch %s: %v", s.files[0].name, err)
+		if err := s.removeOldest(); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// RemoveOldest removes the oldest spooled batch once the server has it
+func (s *Spool) RemoveOldest() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.files) == 0 {
+		return nil
+	}
+	return s.removeOldest()
+}
+
+// Len returns the number of spooled batches and their size on disk
+func (s *Spool) Len() (int, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.files), s.size
+}
+
+func (s *Spool) read(file spoolFile) (*observability.AgentBatch, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, file.name))
+	if err != nil {
+		return nil, err
+	}
+	var batch observability.AgentBatch
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (s *Spool) removeOldest() error {
+	oldest := s.files[0]
+	if err := os.Remove(filepath.Join(s.dir, oldest.name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove spooled batch: %w", err)
+	}
+	s.files = s.files[1:]
+	s.size -= oldest.size
+	return nil
+}
+
+// sampleRange returns the span of a batch's samples
+func sampleRange(metrics []gpu.GPUMetrics) observability.SampleRange {
+	var span observability.SampleRange
+	for _, sample := range metrics {
+		if span.From.IsZero() || sample.Timestamp.Before(span.From) {
+			span.From = sample.Timestamp
+		}
+		if sample.Timestamp.After(span.To) {
+			span.To = sample.Timestamp
+		}
+	}
+	span.Samples = len(metrics)
+	return span
+}
@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+)
+
+func TestSpoolDropsOldestWhenFull(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := OpenSpool(dir, 1000)
+	if err != nil {
+		t.Fatalf("Failed to open spool: %v", err)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	batch := func(seq uint64) observability.AgentBatch {
+		taken := start.Add(time.Duration(seq) * time.Minute)
+		return observability.AgentBatch{Node: "node-a", Seq: seq, Metrics: []gpu.GPUMetrics{
+			{GPUID: "0", Timestamp: taken}, {GPUID: "1", Timestamp: taken.Add(time.Second)},
+		}}
+	}
+	var dropped []observability.SampleRange
+	for seq := uint64(1); seq <= 6; seq++ {
+		spans, err := spool.Append(batch(seq))
+		if err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+		dropped = append(dropped, spans...)
+	}
+	count, size := spool.Len()
+	if len(dropped) == 0 || size > 1000 {
+		t.Fatalf("Expected the oldest batches dropped to stay within 1000 bytes, got %d batches of %d bytes", count, size)
+	}
+	if first := dropped[0]; first.Samples != 2 || !first.From.Equal(start.Add(time.Minute)) || !first.To.Equal(start.Add(time.Minute+time.Second)) {
+		t.Errorf("Expected the first batch's span reported, got %+v", first)
+	}
+
+	// A restarted agent finds the spooled batches in order
+	reopened, err := OpenSpool(dir, 10000)
+	if err != nil {
+		t.Fatalf("Failed to reopen spool: %v", err)
+	}
+	oldest, _ := reopened.Oldest()
+	if oldest == nil || oldest.Seq != uint64(len(dropped)+1) {
+		t.Fatalf("Expected batch %d oldest, got %+v", len(dropped)+1, oldest)
+	}
+	reopened.Append(batch(7))
+	for seq := uint64(len(dropped) + 1); seq <= 7; seq++ {
+		oldest, _ := reopened.Oldest()
+		if oldest == nil || oldest.Seq != seq {
+			t.Fatalf("Expected batch %d next, got %+v", seq, oldest)
+		}
+		reopened.RemoveOldest()
+	}
+	if count, size := reopened.Len(); count != 0 || size != 0 {
+		t.Errorf("Expected the spool empty, got %d batches of %d bytes", count, size)
+	}
+}
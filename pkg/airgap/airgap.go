@@ -0,0 +1,262 @@
+// Package airgap keeps AgentaFlow inside the local network for edge and
+// air-gapped sites: a guard refuses connections to addresses outside it and
+// records every attempt, so anything still reaching out shows up in checks
+package airgap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAllowedNetworks are the loopback, private and link-local ranges
+var DefaultAllowedNetworks = []string{
+	"127.0.0.0/8",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"169.254.0.0/16",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+}
+
+// ErrOutbound is returned for a connection the guard refused
+var ErrOutbound = errors.New("outbound connection blocked in air-gapped mode")
+
+// maxViolations caps the attempts kept, dropping the oldest
+const maxViolations = 100
+
+// directDialer dials for DialContext while no guard is installed
+var directDialer = &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+
+var (
+	installed   *Guard // Set by Install
+	installedMu sync.Mutex
+)
+
+// Violation is a refused connection attempt
+type Violation struct {
+	Address   string    `json:"address"`
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Guard refuses connections outside its allowed networks
+type Guard struct {
+	networks []*net.IPNet
+	resolver interface {
+		LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+	}
+	dialer *net.Dialer
+
+	violations  []Violation
+	blocked     int
+	onViolation func(Violation)
+	restore     http.RoundTripper // http.DefaultTransport before Install
+	mu          sync.Mutex
+}
+
+// NewGuard creates a guard allowing the given CIDR ranges; none allows
+// DefaultAllowedNetworks
+func NewGuard(allowed []string) (*Guard, error) {
+	if len(allowed) == 0 {
+		allowed = DefaultAllowedNetworks
+	}
+	g := &Guard{
+		resolver: net.DefaultResolver,
+		dialer:   &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+	}
+	for _, cidr := range allowed {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed network %q: %w", cidr, err)
+		}
+		g.networks = append(g.networks, network)
+	}
+	return g, nil
+}
+
+// OnViolation calls fn for every refused connection
+func (g *Guard) OnViolation(fn func(Violation)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onViolation = fn
+}
+
+// Allows reports whether ip is in an allowed network
+func (g *Guard) Allows(ip net.IP) bool {
+	for _, network := range g.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check resolves host and returns an error unless every address it resolves
+// to is allowed; a name that does not resolve cannot be reached either
+func (g *Guard) Check(ctx context.Context, host string) error {
+	_, err := g.resolve(ctx, host)
+	return err
+}
+
+// resolve returns the addresses host resolves to, failing like Check unless
+// all of them are allowed
+func (g *Guard) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		if !g.Allows(ip) {
+			return nil, fmt.Errorf("%s is outside the allowed networks", host)
+		}
+		return []net.IP{ip}, nil
+	}
+	addrs, err := g.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("%s does not resolve: %w", host, err)
+	}
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		if !g.Allows(addr.IP) {
+			return nil, fmt.Errorf("%s resolves to %s, outside the allowed networks", host, addr.IP)
+		}
+		ips = append(ips, addr.IP)
+	}
+	return ips, nil
+}
+
+// CheckURL checks the host of an endpoint URL, such as a tracing collector
+// or webhook; an empty URL passes
+func (g *Guard) CheckURL(ctx context.Context, rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return fmt.Errorf("invalid URL %q", rawURL)
+	}
+	return g.Check(ctx, parsed.Hostname())
+}
+
+// DialContext dials like net.Dialer, refusing addresses outside the allowed
+// networks. It connects to the addresses it checked rather than the name,
+// which could resolve somewhere else when looked up again.
+func (g *Guard) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := g.resolve(ctx, host)
+	if err != nil {
+		g.record(Violation{Address: address, Reason: err.Error(), Timestamp: time.Now()})
+		return nil, fmt.Errorf("%w: %v", ErrOutbound, err)
+	}
+
+	var lastErr error
+	for _, ip := range ips {
+		conn, err := g.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// DialContext dials through the installed guard, or directly when none is
+// installed. Clients that bring their own dialer instead of using
+// http.DefaultTransport, such as gRPC connections and the OTLP trace
+// exporter, dial with it so the guard covers them too.
+func DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	installedMu.Lock()
+	guard := installed
+	installedMu.Unlock()
+	if guard == nil {
+		return directDialer.DialContext(ctx, network, address)
+	}
+	return guard.DialContext(ctx, network, address)
+}
+
+// Transport returns an HTTP transport dialing through the guard
+func (g *Guard) Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = g.DialContext
+	return transport
+}
+
+// Install routes http.DefaultTransport, which every HTTP client without its
+// own transport uses, and the package's DialContext through the guard
+func (g *Guard) Install() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.restore != nil {
+		return
+	}
+	g.restore = http.DefaultTransport
+	http.DefaultTransport = g.Transport()
+
+	installedMu.Lock()
+	installed = g
+	installedMu.Unlock()
+}
+
+// Uninstall restores the transport Install replaced
+func (g *Guard) Uninstall() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.restore == nil {
+		return
+	}
+	http.DefaultTransport = g.restore
+	g.restore = nil
+
+	installedMu.Lock()
+	if installed == g {
+		installed = nil
+	}
+	installedMu.Unlock()
+}
+
+func (g *Guard) record(violation Violation) {
+	g.mu.Lock()
+	g.blocked++
+	g.violations = append(g.violations, violation)
+	if len(g.violations) > maxViolations {
+		g.violations = g.violations[len(g.violations)-maxViolations:]
+	}
+	onViolation := g.onViolation
+	g.mu.Unlock()
+
+	if onViolation != nil {
+		onViolation(violation)
+	}
+}
+
+// Violations returns the most recent refused connections, oldest first
+func (g *Guard) Violations() []Violation {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]Violation(nil), g.violations...)
+}
+
+// GetStats returns how many connections were refused
+func (g *Guard) GetStats() map[string]interface{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	networks := make([]string, len(g.networks))
+	for i, network := range g.networks {
+		networks[i] = network.String()
+	}
+	return map[string]interface{}{
+		"installed":        g.restore != nil,
+		"allowed_networks": networks,
+		"blocked":          g.blocked,
+	}
+}
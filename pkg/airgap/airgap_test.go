@@ -0,0 +1,129 @@
+package airgap
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGuardRefusesOutboundConnections(t *testing.T) {
+	guard, err := NewGuard(nil)
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	var reported []Violation
+	guard.OnViolation(func(v Violation) { reported = append(reported, v) })
+
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer local.Close()
+
+	original := http.DefaultTransport
+	guard.Install()
+	defer guard.Uninstall()
+	if response, err := http.Get(local.URL); err != nil {
+		t.Fatalf("Expected the local server reachable, got %v", err)
+	} else {
+		response.Body.Close()
+	}
+	// 203.0.113.0/24 is reserved for documentation, so never reachable anyway
+	if _, err := http.Get("http://203.0.113.7/v1/traces"); !errors.Is(err, ErrOutbound) {
+		t.Errorf("Expected the outbound request blocked, got %v", err)
+	}
+	if len(reported) != 1 || reported[0].Address != "203.0.113.7:80" || len(guard.Violations()) != 1 {
+		t.Errorf("Expected the attempt recorded, got %+v", reported)
+	}
+
+	guard.Uninstall()
+	if http.DefaultTransport != original || guard.GetStats()["installed"] != false {
+		t.Error("Expected the default transport restored")
+	}
+}
+
+func TestGuardChecksEndpoints(t *testing.T) {
+	guard, err := NewGuard([]string{"10.0.0.0/8", "127.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	for endpoint, allowed := range map[string]bool{
+		"":                                 true,
+		"http://127.0.0.1:4318/v1/traces":  true,
+		"http://10.1.2.3:14268/api/traces": true,
+		"https://192.168.1.10/hooks":       false,
+		"https://203.0.113.7/v1/traces":    false,
+	} {
+		if err := guard.CheckURL(context.Background(), endpoint); (err == nil) != allowed {
+			t.Errorf("%q: expected allowed %v, got %v", endpoint, allowed, err)
+		}
+	}
+	if _, err := NewGuard([]string{"10.0.0.0"}); err == nil {
+		t.Error("Expected a network without a prefix length rejected")
+	}
+}
+
+// staticResolver resolves names from a map
+type staticResolver map[string][]net.IPAddr
+
+func (r staticResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	if addrs, exists := r[host]; exists {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestGuardDialsTheCheckedAddress(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	guard, err := NewGuard(nil)
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	// The system resolver does not know these names, so a dial that looked
+	// the name up again instead of using the checked address would fail
+	guard.resolver = staticResolver{
+		"collector.internal": {{IP: net.ParseIP("127.0.0.1")}},
+		"rebound.internal":   {{IP: net.ParseIP("127.0.0.1")}, {IP: net.ParseIP("203.0.113.7")}},
+	}
+
+	conn, err := guard.DialContext(context.Background(), "tcp", net.JoinHostPort("collector.internal", port))
+	if err != nil {
+		t.Fatalf("Expected the checked address dialed, got %v", err)
+	}
+	conn.Close()
+
+	if _, err := guard.DialContext(context.Background(), "tcp", net.JoinHostPort("rebound.internal", port)); !errors.Is(err, ErrOutbound) {
+		t.Errorf("Expected a name with any outside address refused, got %v", err)
+	}
+}
+
+func TestInstalledGuardCoversPackageDialer(t *testing.T) {
+	guard, err := NewGuard(nil)
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	guard.Install()
+	defer guard.Uninstall()
+
+	if _, err := DialContext(context.Background(), "tcp", "203.0.113.7:4317"); !errors.Is(err, ErrOutbound) {
+		t.Errorf("Expected the installed guard to refuse the dial, got %v", err)
+	}
+	if len(guard.Violations()) != 1 {
+		t.Errorf("Expected the attempt recorded, got %+v", guard.Violations())
+	}
+}
@@ -0,0 +1,69 @@
+//go:build cgo
+// +build cgo
+
+package apikeys
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/migrate"
+)
+
+func TestSQLStoreUpgradesSchemaAndKeepsKeys(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "agentaflow.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	store, err := OpenSQLStore(ctx, db, migrate.DialectSQLite)
+	if err != nil {
+		t.Fatalf("OpenSQLStore: %v", err)
+	}
+	var applied int
+	if err := db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&applied); err != nil || applied != len(migrate.Builtin()) {
+		t.Errorf("Expected every migration applied on open, got %d, %v", applied, err)
+	}
+
+	expires := time.Now().Add(time.Hour)
+	key, secret, err := store.Create(KeyRequest{Name: "ci", Scopes: []Scope{ScopeReadMetrics, ScopePushMetrics}, Tenant: "vision", ExpiresAt: &expires})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	store.now = func() time.Time { return time.Now().Add(lastUsedPersistInterval) }
+	if _, err := store.Authenticate(secret); err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+
+	// Reopening applies nothing new and loads the keys from their rows
+	reopened, err := OpenSQLStore(ctx, db, migrate.DialectSQLite)
+	if err != nil {
+		t.Fatalf("OpenSQLStore again: %v", err)
+	}
+	saved, exists := reopened.Get(key.ID)
+	if !exists || saved.Tenant != "vision" || len(saved.Scopes) != 2 || !saved.HasScope(ScopePushMetrics) ||
+		saved.ExpiresAt == nil || saved.LastUsedAt == nil || saved.RevokedAt != nil {
+		t.Errorf("reopened key = %+v", saved)
+	}
+	if _, err := reopened.Authenticate(secret); err != nil {
+		t.Errorf("reopened Authenticate: %v", err)
+	}
+
+	if _, err := reopened.Revoke(key.ID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	revoked, err := OpenSQLStore(ctx, db, migrate.DialectSQLite)
+	if err != nil {
+		t.Fatalf("OpenSQLStore after revoke: %v", err)
+	}
+	if _, err := revoked.Authenticate(secret); err == nil {
+		t.Error("revoked key authenticated after reopening")
+	}
+}
@@ -0,0 +1,428 @@
+// Package apikeys stores API keys with scopes for the REST API and gRPC
+// interceptors. Only a SHA-256 hash of each secret is kept.
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/migrate"
+)
+
+// Scope is what an API key may do
+type Scope string
+
+const (
+	ScopeReadMetrics      Scope = "read-metrics"
+	ScopeSubmitWorkloads  Scope = "submit-workloads"
+	ScopePushMetrics      Scope = "push-metrics"      // Node agents uploading the metrics they collect
+	ScopeWriteAnnotations Scope = "write-annotations" // CI pipelines marking deployments on charts
+	ScopeAdmin            Scope = "admin"             // Grants every scope and key management
+)
+
+// secretPrefix starts every secret so leaked keys are easy to recognize
+const secretPrefix = "afk_"
+
+// lastUsedPersistInterval bounds how often authentication rewrites the key
+// file or row just to update last-used times
+const lastUsedPersistInterval = time.Minute
+
+// Key is an API key without its secret
+type Key struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []Scope    `json:"scopes"`
+	Tenant     string     `json:"tenant,omitempty"` // Tenant the key acts for; empty for every tenant
+	Prefix     string     `json:"prefix"`           // Start of the secret, to tell keys apart
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// HasScope reports whether the key grants a scope
+func (k Key) HasScope(scope Scope) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether the key can authenticate at now
+func (k Key) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// storedKey is a key with the SHA-256 of its secret
+type storedKey struct {
+	Key
+	Hash string `json:"hash"`
+}
+
+// KeyRequest describes a key to create or the fields of a key to update
+type KeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []Scope    `json:"scopes"`
+	Tenant    string     `json:"tenant"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// Validate checks the name and scopes
+func (r KeyRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("API key name cannot be empty")
+	}
+	if len(r.Scopes) == 0 {
+		return fmt.Errorf("API key needs at least one scope")
+	}
+	for _, scope := range r.Scopes {
+		switch scope {
+		case ScopeReadMetrics, ScopeSubmitWorkloads, ScopePushMetrics, ScopeWriteAnnotations, ScopeAdmin:
+		default:
+			return fmt.Errorf("unknown scope %q (expected %s, %s, %s, %s or %s)", scope, ScopeReadMetrics, ScopeSubmitWorkloads, ScopePushMetrics, ScopeWriteAnnotations, ScopeAdmin)
+		}
+	}
+	return nil
+}
+
+// Store keeps API keys, optionally persisted to a JSON file or a database
+type Store struct {
+	path      string
+	db        *sql.DB // Set by OpenSQLStore instead of path
+	dialect   string
+	keys      map[string]*storedKey
+	persisted time.Time // Last write of the key file or a key row
+	now       func() time.Time
+	mu        sync.RWMutex
+}
+
+// NewStore creates a store, loading keys saved at path. An empty path keeps
+// keys in memory only.
+func NewStore(path string) (*Store, error) {
+	store := &Store{
+		path: path,
+		keys: make(map[string]*storedKey),
+		now:  time.Now,
+	}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys: %w", err)
+	}
+	var saved []storedKey
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to decode API keys: %w", err)
+	}
+	for i := range saved {
+		store.keys[saved[i].ID] = &saved[i]
+	}
+	return store, nil
+}
+
+// keyColumns are the api_keys columns, in the order rows are scanned and written
+const keyColumns = "id, name, scopes, tenant, prefix, secret_hash, created_at, expires_at, last_used_at, revoked_at"
+
+// OpenSQLStore creates a store kept in the api_keys table of a postgres or
+// sqlite database. Pending schema migrations are applied first, so a new
+// release upgrades the database when it starts.
+func OpenSQLStore(ctx context.Context, db *sql.DB, dialect string) (*Store, error) {
+	if _, err := migrate.Upgrade(ctx, db, dialect); err != nil {
+		return nil, fmt.Errorf("failed to upgrade API key schema: %w", err)
+	}
+	store := &Store{
+		db:      db,
+		dialect: dialect,
+		keys:    make(map[string]*storedKey),
+		now:     time.Now,
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT "+keyColumns+" FROM api_keys")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			key                            storedKey
+			scopes                         string
+			expiresAt, lastUsed, revokedAt sql.NullTime
+		)
+		if err := rows.Scan(&key.ID, &key.Name, &scopes, &key.Tenant, &key.Prefix, &key.Hash,
+			&key.CreatedAt, &expiresAt, &lastUsed, &revokedAt); err != nil {
+			return nil, fmt.Errorf("failed to read API keys: %w", err)
+		}
+		for _, scope := range strings.Split(scopes, ",") {
+			key.Scopes = append(key.Scopes, Scope(scope))
+		}
+		key.ExpiresAt, key.LastUsedAt, key.RevokedAt = timePtr(expiresAt), timePtr(lastUsed), timePtr(revokedAt)
+		store.keys[key.ID] = &key
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read API keys: %w", err)
+	}
+	return store, nil
+}
+
+// Create adds a key and returns it with its secret, which is not stored and
+// cannot be retrieved again
+func (s *Store) Create(request KeyRequest) (Key, string, error) {
+	if err := request.Validate(); err != nil {
+		return Key{}, "", err
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return Key{}, "", err
+	}
+	random, err := randomHex(24)
+	if err != nil {
+		return Key{}, "", err
+	}
+	secret := secretPrefix + id + "_" + random
+
+	key := &storedKey{
+		Key: Key{
+			ID:        id,
+			Name:      strings.TrimSpace(request.Name),
+			Scopes:    append([]Scope(nil), request.Scopes...),
+			Tenant:    request.Tenant,
+			Prefix:    secret[:len(secretPrefix)+len(id)+5],
+			CreatedAt: s.now(),
+			ExpiresAt: request.ExpiresAt,
+		},
+		Hash: hashSecret(secret),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[id] = key
+	if err := s.save(key); err != nil {
+		delete(s.keys, id)
+		return Key{}, "", err
+	}
+	return key.Key, secret, nil
+}
+
+// Get returns a key by ID
+func (s *Store) Get(id string) (Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, exists := s.keys[id]
+	if !exists {
+		return Key{}, false
+	}
+	return key.Key, true
+}
+
+// List returns every key, including revoked ones, oldest first
+func (s *Store) List() []Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]Key, 0, len(s.keys))
+	for _, key := range s.sorted() {
+		keys = append(keys, key.Key)
+	}
+	return keys
+}
+
+// Update replaces a key's name, scopes, tenant and expiry
+func (s *Store) Update(id string, request KeyRequest) (Key, error) {
+	if err := request.Validate(); err != nil {
+		return Key{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, exists := s.keys[id]
+	if !exists {
+		return Key{}, fmt.Errorf("API key %s not found", id)
+	}
+	previous := *key
+	key.Name = strings.TrimSpace(request.Name)
+	key.Scopes = append([]Scope(nil), request.Scopes...)
+	key.Tenant = request.Tenant
+	key.ExpiresAt = request.ExpiresAt
+	if err := s.save(key); err != nil {
+		*key = previous
+		return Key{}, err
+	}
+	return key.Key, nil
+}
+
+// Revoke stops a key from authenticating; revoked keys stay listed for audit
+func (s *Store) Revoke(id string) (Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, exists := s.keys[id]
+	if !exists {
+		return Key{}, fmt.Errorf("API key %s not found", id)
+	}
+	if key.RevokedAt == nil {
+		now := s.now()
+		key.RevokedAt = &now
+		if err := s.save(key); err != nil {
+			key.RevokedAt = nil
+			return Key{}, err
+		}
+	}
+	return key.Key, nil
+}
+
+// Authenticate returns the active key a secret belongs to and records its use
+func (s *Store) Authenticate(secret string) (Key, error) {
+	id := ""
+	if strings.HasPrefix(secret, secretPrefix) {
+		if parts := strings.SplitN(strings.TrimPrefix(secret, secretPrefix), "_", 2); len(parts) == 2 {
+			id = parts[0]
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, exists := s.keys[id]
+	if !exists || subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(key.Hash)) != 1 {
+		return Key{}, fmt.Errorf("invalid API key")
+	}
+	now := s.now()
+	if !key.Active(now) {
+		return Key{}, fmt.Errorf("API key %s is revoked or expired", key.ID)
+	}
+
+	key.LastUsedAt = &now
+	if now.Sub(s.persisted) >= lastUsedPersistInterval {
+		// Last-used times are advisory, so a failed write does not fail authentication
+		s.save(key)
+	}
+	return key.Key, nil
+}
+
+// sorted copies the keys in creation order; callers must hold the lock
+func (s *Store) sorted() []storedKey {
+	keys := make([]storedKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, *key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys
+}
+
+// save persists a created or changed key, writing its row or atomically
+// rewriting the key file; callers must hold the lock
+func (s *Store) save(key *storedKey) error {
+	var err error
+	switch {
+	case s.db != nil:
+		err = s.saveRow(key)
+	case s.path != "":
+		err = s.saveFile()
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.persisted = s.now()
+	return nil
+}
+
+// saveRow inserts or updates a key's row
+func (s *Store) saveRow(key *storedKey) error {
+	query := "INSERT INTO api_keys (" + keyColumns + ") VALUES (" + migrate.Placeholders(s.dialect, 10) + ")" +
+		" ON CONFLICT (id) DO UPDATE SET name = excluded.name, scopes = excluded.scopes, tenant = excluded.tenant," +
+		" expires_at = excluded.expires_at, last_used_at = excluded.last_used_at, revoked_at = excluded.revoked_at"
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+	_, err := s.db.ExecContext(context.Background(), query, key.ID, key.Name, strings.Join(scopes, ","), key.Tenant,
+		key.Prefix, key.Hash, key.CreatedAt.UTC(), nullTime(key.ExpiresAt), nullTime(key.LastUsedAt), nullTime(key.RevokedAt))
+	if err != nil {
+		return fmt.Errorf("failed to write API key %s: %w", key.ID, err)
+	}
+	return nil
+}
+
+// saveFile atomically rewrites the key file
+func (s *Store) saveFile() error {
+	data, err := json.MarshalIndent(s.sorted(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode API keys: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create API key file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to restrict API key file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write API keys: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close API keys: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace API keys: %w", err)
+	}
+	return nil
+}
+
+// nullTime converts an optional time to a query parameter, NULL when unset
+func nullTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// timePtr converts a nullable column to an optional time
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// hashSecret returns the hex SHA-256 of a secret. Secrets are random, so an
+// unsalted fast hash cannot be brute-forced.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex returns n random bytes as hex
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,101 @@
+package apikeys
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeysAuthenticateUntilRevoked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	key, secret, err := store.Create(KeyRequest{Name: "ci", Scopes: []Scope{ScopeSubmitWorkloads}, Tenant: "vision"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !strings.HasPrefix(secret, key.Prefix) || !key.HasScope(ScopeSubmitWorkloads) || key.HasScope(ScopeReadMetrics) {
+		t.Errorf("key = %+v, secret %q", key, secret)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), secret) || !strings.Contains(string(data), hashSecret(secret)) {
+		t.Errorf("key file stores the secret instead of its hash: %s", data)
+	}
+
+	// Last use is written at most once a minute
+	store.now = func() time.Time { return time.Now().Add(lastUsedPersistInterval) }
+	authenticated, err := store.Authenticate(secret)
+	if err != nil || authenticated.ID != key.ID || authenticated.LastUsedAt == nil {
+		t.Fatalf("Authenticate = %+v, %v", authenticated, err)
+	}
+	for _, wrong := range []string{"", secret + "x", "afk_" + key.ID + "_00", "not-a-key"} {
+		if _, err := store.Authenticate(wrong); err == nil {
+			t.Errorf("authenticated %q", wrong)
+		}
+	}
+
+	// Keys and their last use survive a restart
+	reloaded, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	if saved, exists := reloaded.Get(key.ID); !exists || saved.Tenant != "vision" || saved.LastUsedAt == nil {
+		t.Errorf("reloaded key = %+v", saved)
+	}
+	if _, err := reloaded.Authenticate(secret); err != nil {
+		t.Errorf("reloaded Authenticate: %v", err)
+	}
+
+	if _, err := reloaded.Revoke(key.ID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := reloaded.Authenticate(secret); err == nil {
+		t.Error("revoked key authenticated")
+	}
+	if listed := reloaded.List(); len(listed) != 1 || listed[0].RevokedAt == nil {
+		t.Errorf("listed = %+v, want the revoked key kept for audit", listed)
+	}
+}
+
+func TestKeyUpdateAndExpiry(t *testing.T) {
+	store, _ := NewStore("")
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	expires := now.Add(time.Hour)
+	key, secret, err := store.Create(KeyRequest{Name: "grafana", Scopes: []Scope{ScopeReadMetrics}, ExpiresAt: &expires})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	updated, err := store.Update(key.ID, KeyRequest{Name: "ops", Scopes: []Scope{ScopeAdmin}, ExpiresAt: &expires})
+	if err != nil || updated.Name != "ops" || !updated.HasScope(ScopeSubmitWorkloads) {
+		t.Errorf("Update = %+v, %v", updated, err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, err := store.Authenticate(secret); err == nil {
+		t.Error("expired key authenticated")
+	}
+
+	invalid := []KeyRequest{
+		{Scopes: []Scope{ScopeAdmin}},
+		{Name: "none"},
+		{Name: "typo", Scopes: []Scope{"write-everything"}},
+	}
+	for _, request := range invalid {
+		if _, _, err := store.Create(request); err == nil {
+			t.Errorf("created %+v", request)
+		}
+	}
+	if _, err := store.Update("missing", KeyRequest{Name: "x", Scopes: []Scope{ScopeAdmin}}); err == nil {
+		t.Error("updated a missing key")
+	}
+	if _, err := store.Revoke("missing"); err == nil {
+		t.Error("revoked a missing key")
+	}
+}
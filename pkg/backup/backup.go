@@ -0,0 +1,424 @@
+// Package backup archives AgentaFlow's persistent state — the state snapshot
+// (metrics, events, cost history, alert history and scheduler state), the
+// scheduler WAL, API keys, notification preferences and configuration files —
+// into one versioned tar.gz, and restores it.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the archive format written by Create. Restore migrates
+// older archives up to it and refuses newer ones.
+const SchemaVersion = 2
+
+// manifestName is the archive member describing the rest of the archive
+const manifestName = "manifest.json"
+
+// Kind is the sort of state a backed-up file holds
+type Kind string
+
+// Kinds of backed-up files
+const (
+	KindState             Kind = "state" // Snapshotter file: metrics, events, costs, alert history, scheduler
+	KindWAL               Kind = "wal"
+	KindAPIKeys           Kind = "apikeys"
+	KindNotificationPrefs Kind = "notification_prefs"
+	KindConfig            Kind = "config"
+)
+
+// Source is a file, or for configuration a directory, to back up
+type Source struct {
+	Kind Kind
+	Path string
+}
+
+// Entry is one file in an archive
+type Entry struct {
+	Kind   Kind   `json:"kind"`
+	Name   string `json:"name"` // Archive member
+	Path   string `json:"path"` // Where the file was backed up from, slash-separated and relative to the filesystem root
+	Mode   uint32 `json:"mode"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest describes an archive's contents
+type Manifest struct {
+	SchemaVersion int       `json:"schema_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Hostname      string    `json:"hostname,omitempty"`
+	Entries       []Entry   `json:"entries"`
+}
+
+// Migration upgrades an archive from one schema version to the next,
+// rewriting the manifest and file contents in place
+type Migration func(manifest *Manifest, files map[string][]byte) error
+
+// migrations are keyed by the schema version they upgrade from
+var migrations = map[int]Migration{
+	// Version 1 recorded absolute paths
+	1: func(manifest *Manifest, files map[string][]byte) error {
+		for i, entry := range manifest.Entries {
+			if filepath.IsAbs(entry.Path) {
+				manifest.Entries[i].Path = archivePath(entry.Path)
+			}
+		}
+		return nil
+	},
+}
+
+// archivePath turns an absolute path into the path recorded in a manifest
+func archivePath(absolute string) string {
+	path := filepath.ToSlash(strings.TrimPrefix(absolute, filepath.VolumeName(absolute)))
+	return strings.TrimLeft(path, "/")
+}
+
+// localPath checks a path from a manifest, which is not signed, and returns
+// it for joining under the restore target. Absolute paths and paths with a
+// ".." element are refused so no entry can land outside the target.
+func localPath(path string) (string, error) {
+	if path == "" || strings.HasPrefix(path, "/") || filepath.IsAbs(path) || filepath.VolumeName(path) != "" {
+		return "", fmt.Errorf("path %q is not relative", path)
+	}
+	for _, element := range strings.Split(path, "/") {
+		if element == ".." {
+			return "", fmt.Errorf("path %q leaves the target directory", path)
+		}
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// Create writes a gzipped tar archive of the sources to w. Every file is
+// read completely before anything is written, so a source that cannot be
+// read fails the backup instead of leaving it incomplete.
+func Create(w io.Writer, sources []Source) (*Manifest, error) {
+	hostname, _ := os.Hostname()
+	manifest := &Manifest{SchemaVersion: SchemaVersion, CreatedAt: time.Now().UTC(), Hostname: hostname}
+	files := make(map[string][]byte)
+
+	for _, source := range sources {
+		paths, err := expand(source)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			absolute, err := filepath.Abs(path)
+			if err != nil {
+				return nil, err
+			}
+
+			name := fmt.Sprintf("files/%s/%d-%s", source.Kind, len(manifest.Entries), filepath.Base(path))
+			sum := sha256.Sum256(data)
+			manifest.Entries = append(manifest.Entries, Entry{
+				Kind:   source.Kind,
+				Name:   name,
+				Path:   archivePath(absolute),
+				Mode:   uint32(info.Mode().Perm()),
+				Size:   int64(len(data)),
+				SHA256: hex.EncodeToString(sum[:]),
+			})
+			files[name] = data
+		}
+	}
+
+	if err := writeArchive(w, manifest, files); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// writeArchive writes the manifest followed by its files as a gzipped tar
+func writeArchive(w io.Writer, manifest *Manifest, files map[string][]byte) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeMember(tw, manifestName, 0644, manifest.CreatedAt, manifestData); err != nil {
+		return err
+	}
+	for _, entry := range manifest.Entries {
+		if err := writeMember(tw, entry.Name, int64(entry.Mode), manifest.CreatedAt, files[entry.Name]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// CreateFile writes an archive to path, replacing it atomically
+func CreateFile(path string, sources []Source) (*Manifest, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	manifest, err := Create(tmp, sources)
+	if err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to sync archive: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close archive: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("failed to replace archive: %w", err)
+	}
+	return manifest, nil
+}
+
+// expand lists the files a source covers; only configuration may be a directory
+func expand(source Source) ([]string, error) {
+	info, err := os.Stat(source.Path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot back up %s %s: %w", source.Kind, source.Path, err)
+	}
+	if !info.IsDir() {
+		return []string{source.Path}, nil
+	}
+	if source.Kind != KindConfig {
+		return nil, fmt.Errorf("%s %s is a directory", source.Kind, source.Path)
+	}
+
+	var paths []string
+	err = filepath.Walk(source.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	sort.Strings(paths)
+	return paths, err
+}
+
+// writeMember adds one regular file to a tar archive
+func writeMember(tw *tar.Writer, name string, mode int64, modTime time.Time, data []byte) error {
+	header := &tar.Header{Name: name, Mode: mode, Size: int64(len(data)), ModTime: modTime, Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// Read loads an archive, verifies every file's checksum and migrates it to
+// the current schema version
+func Read(path string) (*Manifest, map[string][]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s is not a backup archive: %w", path, err)
+	}
+	tr := tar.NewReader(gz)
+	var manifest *Manifest
+	files := make(map[string][]byte)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		if header.Name == manifestName {
+			manifest = &Manifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, nil, fmt.Errorf("invalid manifest: %w", err)
+			}
+			continue
+		}
+		files[header.Name] = data
+	}
+	if manifest == nil {
+		return nil, nil, fmt.Errorf("%s has no manifest", path)
+	}
+
+	for _, entry := range manifest.Entries {
+		data, exists := files[entry.Name]
+		if !exists {
+			return nil, nil, fmt.Errorf("archive is missing %s", entry.Name)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != entry.SHA256 {
+			return nil, nil, fmt.Errorf("checksum mismatch for %s", entry.Name)
+		}
+	}
+
+	if err := migrate(manifest, files); err != nil {
+		return nil, nil, err
+	}
+	return manifest, files, nil
+}
+
+// migrate upgrades an archive to SchemaVersion one version at a time
+func migrate(manifest *Manifest, files map[string][]byte) error {
+	if manifest.SchemaVersion > SchemaVersion {
+		return fmt.Errorf("archive schema version %d is newer than supported version %d", manifest.SchemaVersion, SchemaVersion)
+	}
+	for manifest.SchemaVersion < SchemaVersion {
+		migration, exists := migrations[manifest.SchemaVersion]
+		if !exists {
+			return fmt.Errorf("no migration from archive schema version %d", manifest.SchemaVersion)
+		}
+		from := manifest.SchemaVersion
+		if err := migration(manifest, files); err != nil {
+			return fmt.Errorf("failed to migrate archive from schema version %d: %w", from, err)
+		}
+		if manifest.SchemaVersion <= from {
+			manifest.SchemaVersion = from + 1
+		}
+	}
+	return nil
+}
+
+// RestoreOptions controls where and how an archive is restored
+type RestoreOptions struct {
+	// Restore under this directory, keeping the backed-up layout; "/" puts
+	// files back at the paths they were backed up from. Required.
+	TargetDir string
+
+	// Replace files that already exist; otherwise they fail the restore
+	Overwrite bool
+
+	// Only restore these kinds; all when empty
+	Kinds []Kind
+
+	// Report what would be restored without writing anything
+	DryRun bool
+}
+
+// RestoredFile is a file written, or to be written, by Restore
+type RestoredFile struct {
+	Kind     Kind   `json:"kind"`
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+	Replaced bool   `json:"replaced"` // An existing file was overwritten
+}
+
+// Restore writes an archive's files back under the target directory. Every
+// checksum and destination is checked before the first file is written, and
+// each file is replaced atomically. Stop AgentaFlow before restoring so it
+// does not overwrite the restored state.
+func Restore(path string, options RestoreOptions) ([]RestoredFile, error) {
+	if options.TargetDir == "" {
+		return nil, fmt.Errorf("restore needs a target directory; use / to restore to the backed-up paths")
+	}
+	manifest, files, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[Kind]bool)
+	for _, kind := range options.Kinds {
+		wanted[kind] = true
+	}
+
+	var restored []RestoredFile
+	for _, entry := range manifest.Entries {
+		if len(wanted) > 0 && !wanted[entry.Kind] {
+			continue
+		}
+		relative, err := localPath(entry.Path)
+		if err != nil {
+			return nil, fmt.Errorf("archive member %s: %w", entry.Name, err)
+		}
+		destination := filepath.Join(options.TargetDir, relative)
+		_, err = os.Stat(destination)
+		exists := err == nil
+		if exists && !options.Overwrite {
+			return nil, fmt.Errorf("%s already exists; restore with overwrite to replace it", destination)
+		}
+		restored = append(restored, RestoredFile{Kind: entry.Kind, Path: destination, Size: int64(len(files[entry.Name])), Replaced: exists})
+	}
+	if options.DryRun {
+		return restored, nil
+	}
+
+	i := 0
+	for _, entry := range manifest.Entries {
+		if len(wanted) > 0 && !wanted[entry.Kind] {
+			continue
+		}
+		mode := os.FileMode(entry.Mode)
+		if mode == 0 {
+			mode = 0644
+		}
+		if err := writeFileAtomic(restored[i].Path, files[entry.Name], mode); err != nil {
+			return restored[:i], err
+		}
+		i++
+	}
+	return restored, nil
+}
+
+// writeFileAtomic writes data to a temporary file and renames it into place
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files under dir and returns their paths by name
+func writeFiles(t *testing.T, dir string, contents map[string]string) map[string]string {
+	t.Helper()
+	paths := make(map[string]string)
+	for name, content := range contents {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		paths[name] = path
+	}
+	return paths
+}
+
+func TestBackupAndRestoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	paths := writeFiles(t, dir, map[string]string{
+		"state.json":         `{"version":1}`,
+		"scheduler.wal":      "{\"seq\":1}\n",
+		"apikeys.json":       `[]`,
+		"config/alerts.yaml": "high_temperature: 80\n",
+		"config/costs.yaml":  "currency: EUR\n",
+	})
+	archive := filepath.Join(dir, "backup.tar.gz")
+
+	manifest, err := CreateFile(archive, []Source{
+		{Kind: KindState, Path: paths["state.json"]},
+		{Kind: KindWAL, Path: paths["scheduler.wal"]},
+		{Kind: KindAPIKeys, Path: paths["apikeys.json"]},
+		{Kind: KindConfig, Path: filepath.Join(dir, "config")},
+	})
+	if err != nil {
+		t.Fatalf("CreateFile: %v", err)
+	}
+	if manifest.SchemaVersion != SchemaVersion || len(manifest.Entries) != 5 {
+		t.Fatalf("Unexpected manifest: %+v", manifest)
+	}
+
+	if _, err := Restore(archive, RestoreOptions{}); err == nil || !strings.Contains(err.Error(), "target directory") {
+		t.Errorf("Expected a restore without a target directory refused, got %v", err)
+	}
+
+	// Restoring over existing files needs overwrite
+	root := string(filepath.Separator)
+	if _, err := Restore(archive, RestoreOptions{TargetDir: root}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected existing files protected, got %v", err)
+	}
+
+	os.WriteFile(paths["scheduler.wal"], []byte("corrupted"), 0600)
+	os.Remove(paths["config/costs.yaml"])
+	restored, err := Restore(archive, RestoreOptions{TargetDir: root, Overwrite: true, Kinds: []Kind{KindWAL, KindConfig}})
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if len(restored) != 3 {
+		t.Errorf("Expected the WAL and both config files restored, got %+v", restored)
+	}
+	if data, _ := os.ReadFile(paths["scheduler.wal"]); string(data) != "{\"seq\":1}\n" {
+		t.Errorf("Expected the WAL restored, got %q", data)
+	}
+	if data, _ := os.ReadFile(paths["config/costs.yaml"]); string(data) != "currency: EUR\n" {
+		t.Errorf("Expected the deleted config restored, got %q", data)
+	}
+	if info, _ := os.Stat(paths["scheduler.wal"]); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the file mode kept, got %v", info.Mode())
+	}
+
+	target := filepath.Join(dir, "elsewhere")
+	restored, err = Restore(archive, RestoreOptions{TargetDir: target, DryRun: true})
+	if err != nil || len(restored) != 5 || !strings.HasPrefix(restored[0].Path, target) {
+		t.Errorf("Expected a dry run listing relocated files, got %+v, %v", restored, err)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Error("Expected a dry run to write nothing")
+	}
+
+	// The backed-up layout is kept under the target directory
+	if _, err := Restore(archive, RestoreOptions{TargetDir: target, Kinds: []Kind{KindConfig}}); err != nil {
+		t.Fatalf("Restore to target: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(target, paths["config/alerts.yaml"])); string(data) != "high_temperature: 80\n" {
+		t.Errorf("Expected the config restored with its layout under the target, got %q", data)
+	}
+}
+
+func TestRestoreRefusesPathsOutsideTarget(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{"/etc/cron.d/agentaflow", "../outside.json", "state/../../outside.json", ""} {
+		manifest := &Manifest{SchemaVersion: SchemaVersion, Entries: []Entry{{Kind: KindState, Name: "files/state/0-state.json", Path: path}}}
+		files := map[string][]byte{"files/state/0-state.json": []byte(`{}`)}
+		sum := sha256.Sum256(files["files/state/0-state.json"])
+		manifest.Entries[0].SHA256 = hex.EncodeToString(sum[:])
+
+		archive := filepath.Join(dir, "crafted.tar.gz")
+		file, err := os.Create(archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeArchive(file, manifest, files)
+		file.Close()
+
+		target := filepath.Join(dir, "target")
+		if _, err := Restore(archive, RestoreOptions{TargetDir: target}); err == nil {
+			t.Errorf("Expected path %q refused", path)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "outside.json")); !os.IsNotExist(err) {
+		t.Error("Expected nothing written outside the target")
+	}
+}
+
+func TestMigrateMakesVersion1PathsRelative(t *testing.T) {
+	manifest := &Manifest{SchemaVersion: 1, Entries: []Entry{{Kind: KindState, Path: "/var/lib/agentaflow/state.json"}}}
+	if err := migrate(manifest, map[string][]byte{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if manifest.Entries[0].Path != "var/lib/agentaflow/state.json" {
+		t.Errorf("Expected a path relative to the root, got %q", manifest.Entries[0].Path)
+	}
+}
+
+func TestBackupFailsOnMissingSource(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := Create(&buf, []Source{{Kind: KindState, Path: filepath.Join(t.TempDir(), "missing.json")}})
+	if err == nil || !strings.Contains(err.Error(), "cannot back up state") {
+		t.Errorf("Expected a missing source reported, got %v", err)
+	}
+}
+
+func TestReadRejectsCorruptAndNewerArchives(t *testing.T) {
+	dir := t.TempDir()
+	paths := writeFiles(t, dir, map[string]string{"state.json": `{"version":1}`})
+	archive := filepath.Join(dir, "backup.tar.gz")
+	if _, err := CreateFile(archive, []Source{{Kind: KindState, Path: paths["state.json"]}}); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, files, err := Read(archive)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	files[manifest.Entries[0].Name] = []byte("tampered")
+	tampered, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeArchive(tampered, manifest, files)
+	tampered.Close()
+	if _, _, err := Read(archive); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Expected a checksum mismatch, got %v", err)
+	}
+
+	if err := migrate(&Manifest{SchemaVersion: SchemaVersion + 1}, nil); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("Expected a newer archive refused, got %v", err)
+	}
+}
+
+func TestMigrateRunsEachStep(t *testing.T) {
+	defer func(saved map[int]Migration) { migrations = saved }(migrations)
+	migrations = map[int]Migration{
+		0: func(manifest *Manifest, files map[string][]byte) error {
+			files["files/state/0-state.json"] = []byte(`{"version":1}`)
+			return nil
+		},
+		1: migrations[1],
+	}
+
+	manifest := &Manifest{SchemaVersion: 0}
+	files := map[string][]byte{"files/state/0-state.json": []byte(`{}`)}
+	if err := migrate(manifest, files); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	var state map[string]int
+	json.Unmarshal(files["files/state/0-state.json"], &state)
+	if manifest.SchemaVersion != SchemaVersion || state["version"] != 1 {
+		t.Errorf("Expected the archive migrated to version %d, got %d %v", SchemaVersion, manifest.SchemaVersion, state)
+	}
+}
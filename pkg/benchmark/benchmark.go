@@ -0,0 +1,194 @@
+// Package benchmark runs standardized load on GPUs, for burn-in of new
+// hardware and to record what each GPU model achieves: throughput, thermals
+// and power, kept as baseline hardware profiles
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// Config controls a benchmark run
+type Config struct {
+	Runner   Runner
+	Duration time.Duration // Load applied to each GPU
+	GPUs     []string      // GPU IDs to load, all at once; every GPU the collector reports when empty
+	Node     string        // Host the GPUs are attached to; the hostname when empty
+
+	// A GPU running hotter than MaxTemperature (Celsius) fails burn-in; 0
+	// disables the limit
+	MaxTemperature float64
+
+	// Samples thermals and power during the run; collect at least every
+	// few seconds so short runs get several samples
+	Collector gpu.MetricsCollectorInterface
+}
+
+// DefaultConfig returns a one-minute gpu-burn run
+func DefaultConfig() Config {
+	return Config{
+		Runner:         &GPUBurn{Path: "gpu_burn"},
+		Duration:       time.Minute,
+		MaxTemperature: 90,
+	}
+}
+
+// Result is what one GPU achieved under load
+type Result struct {
+	Node      string        `json:"node"`
+	GPUID     string        `json:"gpu_id"`
+	Model     string        `json:"model"`
+	Runner    string        `json:"runner"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+
+	TFLOPs         float64 `json:"tflops"`
+	Errors         int     `json:"errors"` // Miscomputed results the runner detected
+	AvgUtilization float64 `json:"avg_utilization"`
+	AvgTemperature float64 `json:"avg_temperature"`
+	MaxTemperature float64 `json:"max_temperature"`
+	AvgPowerWatts  float64 `json:"avg_power_watts"`
+	MaxPowerWatts  float64 `json:"max_power_watts"`
+	AvgClockMHz    float64 `json:"avg_clock_mhz"` // Graphics clock under load
+	TFLOPsPerWatt  float64 `json:"tflops_per_watt"`
+	Samples        int     `json:"samples"` // Collector samples taken during the run
+
+	Passed  bool   `json:"passed"`
+	Failure string `json:"failure,omitempty"`
+}
+
+// Report is the outcome of a benchmark run. Passed is false if any GPU
+// failed burn-in.
+type Report struct {
+	Runner    string        `json:"runner"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	Passed    bool          `json:"passed"`
+	Results   []Result      `json:"results"`
+}
+
+// Run loads every selected GPU at once for the configured duration, so
+// burn-in also stresses the node's power and cooling
+func Run(ctx context.Context, config Config) (*Report, error) {
+	if config.Runner == nil {
+		return nil, fmt.Errorf("benchmark needs a runner")
+	}
+	if config.Duration <= 0 {
+		return nil, fmt.Errorf("duration must be positive")
+	}
+	if config.Node == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("node name is required: %w", err)
+		}
+		config.Node = hostname
+	}
+
+	var latest map[string]gpu.GPUMetrics
+	if config.Collector != nil {
+		latest = config.Collector.GetLatestMetrics()
+	}
+	gpuIDs := config.GPUs
+	if len(gpuIDs) == 0 {
+		for gpuID := range latest {
+			gpuIDs = append(gpuIDs, gpuID)
+		}
+		sort.Strings(gpuIDs)
+	}
+	if len(gpuIDs) == 0 {
+		return nil, fmt.Errorf("no GPUs to benchmark")
+	}
+
+	report := &Report{
+		Runner:    config.Runner.Name(),
+		StartedAt: time.Now(),
+		Passed:    true,
+		Results:   make([]Result, len(gpuIDs)),
+	}
+	var wg sync.WaitGroup
+	for i, gpuID := range gpuIDs {
+		wg.Add(1)
+		go func(i int, gpuID string) {
+			defer wg.Done()
+			report.Results[i] = runGPU(ctx, config, gpuID, latest[gpuID].Name)
+		}(i, gpuID)
+	}
+	wg.Wait()
+
+	report.Duration = time.Since(report.StartedAt)
+	for _, result := range report.Results {
+		if !result.Passed {
+			report.Passed = false
+		}
+	}
+	return report, nil
+}
+
+// runGPU loads one GPU and judges the result
+func runGPU(ctx context.Context, config Config, gpuID, model string) Result {
+	result := Result{
+		Node:      config.Node,
+		GPUID:     gpuID,
+		Model:     model,
+		Runner:    config.Runner.Name(),
+		StartedAt: time.Now(),
+	}
+	throughput, err := config.Runner.Run(ctx, gpuID, config.Duration)
+	result.Duration = time.Since(result.StartedAt)
+	result.TFLOPs = throughput.TFLOPs
+	result.Errors = throughput.Errors
+
+	if config.Collector != nil {
+		result.observe(config.Collector.GetMetricsHistory(gpuID, result.StartedAt))
+	}
+	if result.AvgPowerWatts > 0 {
+		result.TFLOPsPerWatt = result.TFLOPs / result.AvgPowerWatts
+	}
+
+	switch {
+	case err != nil:
+		result.Failure = err.Error()
+	case result.Errors > 0:
+		result.Failure = fmt.Sprintf("%d computation errors", result.Errors)
+	case config.MaxTemperature > 0 && result.MaxTemperature > config.MaxTemperature:
+		result.Failure = fmt.Sprintf("reached %.0fC, above the %.0fC limit", result.MaxTemperature, config.MaxTemperature)
+	case result.TFLOPs <= 0:
+		result.Failure = "runner reported no throughput"
+	default:
+		result.Passed = true
+	}
+	return result
+}
+
+// observe summarises the collector samples taken during the run
+func (r *Result) observe(samples []gpu.GPUMetrics) {
+	var utilization, temperature, power, clock float64
+	for _, sample := range samples {
+		if r.Model == "" {
+			r.Model = sample.Name
+		}
+		utilization += sample.UtilizationGPU
+		temperature += sample.Temperature
+		power += sample.PowerDraw
+		clock += float64(sample.ClockGraphics)
+		if sample.Temperature > r.MaxTemperature {
+			r.MaxTemperature = sample.Temperature
+		}
+		if sample.PowerDraw > r.MaxPowerWatts {
+			r.MaxPowerWatts = sample.PowerDraw
+		}
+	}
+	r.Samples = len(samples)
+	if r.Samples > 0 {
+		r.AvgUtilization = utilization / float64(r.Samples)
+		r.AvgTemperature = temperature / float64(r.Samples)
+		r.AvgPowerWatts = power / float64(r.Samples)
+		r.AvgClockMHz = clock / float64(r.Samples)
+	}
+}
@@ -0,0 +1,106 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// fakeCollector reports fixed samples for each GPU
+type fakeCollector struct {
+	gpu.MetricsCollectorInterface
+	samples map[string][]gpu.GPUMetrics
+}
+
+func (f *fakeCollector) GetLatestMetrics() map[string]gpu.GPUMetrics {
+	latest := make(map[string]gpu.GPUMetrics)
+	for gpuID, samples := range f.samples {
+		latest[gpuID] = samples[len(samples)-1]
+	}
+	return latest
+}
+
+func (f *fakeCollector) GetMetricsHistory(gpuID string, since time.Time) []gpu.GPUMetrics {
+	return f.samples[gpuID]
+}
+
+func newFakeCollector() *fakeCollector {
+	sample := func(gpuID string, temperature, power float64) gpu.GPUMetrics {
+		return gpu.GPUMetrics{GPUID: gpuID, Name: "NVIDIA A100-SXM4-40GB", UtilizationGPU: 100, Temperature: temperature, PowerDraw: power, ClockGraphics: 1410}
+	}
+	return &fakeCollector{samples: map[string][]gpu.GPUMetrics{
+		"0": {sample("0", 60, 380), sample("0", 70, 400)},
+		"1": {sample("1", 80, 390), sample("1", 95, 410)},
+	}}
+}
+
+func TestRunRecordsThroughputThermalsAndPower(t *testing.T) {
+	config := DefaultConfig()
+	config.Node = "gpu-node-1"
+	config.Duration = time.Millisecond
+	config.Collector = newFakeCollector()
+	config.Runner = RunnerFunc(func(ctx context.Context, gpuID string, duration time.Duration) (Throughput, error) {
+		return Throughput{TFLOPs: 19.5}, nil
+	})
+
+	report, err := Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(report.Results) != 2 {
+		t.Fatalf("Expected every GPU benchmarked, got %d results", len(report.Results))
+	}
+
+	first := report.Results[0]
+	if first.GPUID != "0" || first.Model != "NVIDIA A100-SXM4-40GB" || first.Node != "gpu-node-1" {
+		t.Errorf("Expected GPU 0 identified, got %+v", first)
+	}
+	if first.MaxTemperature != 70 || first.AvgTemperature != 65 || first.AvgPowerWatts != 390 || first.MaxPowerWatts != 400 || first.AvgClockMHz != 1410 {
+		t.Errorf("Expected thermals and power from the samples, got %+v", first)
+	}
+	if first.TFLOPsPerWatt != 19.5/390 || !first.Passed {
+		t.Errorf("Expected a passing result at 0.05 TFLOPs/W, got %+v", first)
+	}
+
+	second := report.Results[1]
+	if second.Passed || !strings.Contains(second.Failure, "95C") {
+		t.Errorf("Expected GPU 1 to fail burn-in above 90C, got %+v", second)
+	}
+	if report.Passed {
+		t.Error("Expected the report to fail with a GPU failing")
+	}
+}
+
+func TestRunFailsGPUsWithErrors(t *testing.T) {
+	config := DefaultConfig()
+	config.Node = "gpu-node-1"
+	config.Duration = time.Millisecond
+	config.GPUs = []string{"0", "1"}
+	config.MaxTemperature = 0
+	config.Runner = RunnerFunc(func(ctx context.Context, gpuID string, duration time.Duration) (Throughput, error) {
+		if gpuID == "1" {
+			return Throughput{}, errors.New("CUDA error: out of memory")
+		}
+		return Throughput{TFLOPs: 14.2, Errors: 3}, nil
+	})
+
+	report, err := Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if report.Results[0].Passed || report.Results[0].Failure != "3 computation errors" {
+		t.Errorf("Expected computation errors to fail GPU 0, got %+v", report.Results[0])
+	}
+	if report.Results[1].Passed || !strings.Contains(report.Results[1].Failure, "out of memory") {
+		t.Errorf("Expected the runner error to fail GPU 1, got %+v", report.Results[1])
+	}
+
+	config.GPUs = nil
+	if _, err := Run(context.Background(), config); err == nil {
+		t.Error("Expected an error with no GPUs to benchmark")
+	}
+}
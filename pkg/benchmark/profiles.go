@@ -0,0 +1,188 @@
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Profile is the baseline performance of a GPU model under one runner,
+// from the latest passing result of each GPU benchmarked
+type Profile struct {
+	Model          string    `json:"model"`
+	Runner         string    `json:"runner"`
+	GPUs           int       `json:"gpus"`
+	TFLOPs         float64   `json:"tflops"` // Mean across GPUs
+	MinTFLOPs      float64   `json:"min_tflops"`
+	MaxTFLOPs      float64   `json:"max_tflops"`
+	AvgPowerWatts  float64   `json:"avg_power_watts"`
+	AvgClockMHz    float64   `json:"avg_clock_mhz"`
+	MaxTemperature float64   `json:"max_temperature"`
+	TFLOPsPerWatt  float64   `json:"tflops_per_watt"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	Results        []Result  `json:"results"`
+}
+
+// ProfileStore keeps profiles in a JSON file
+type ProfileStore struct {
+	path     string
+	profiles map[string]*Profile // By profileKey
+	mu       sync.RWMutex
+}
+
+// profileKey identifies a model's profile under a runner
+func profileKey(model, runner string) string {
+	return model + "\x00" + runner
+}
+
+// OpenProfiles loads the profiles stored at path; a missing file starts an
+// empty store
+func OpenProfiles(path string) (*ProfileStore, error) {
+	store := &ProfileStore{path: path, profiles: make(map[string]*Profile)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+
+	var profiles []*Profile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles %s: %w", path, err)
+	}
+	for _, profile := range profiles {
+		store.profiles[profileKey(profile.Model, profile.Runner)] = profile
+	}
+	return store, nil
+}
+
+// Record adds a report's passing results, replacing earlier results for the
+// same GPUs, and returns the profiles it changed. Failed GPUs are left out
+// so they do not drag down their model's baseline.
+func (s *ProfileStore) Record(report *Report) []Profile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := make(map[string]*Profile)
+	for _, result := range report.Results {
+		if !result.Passed || result.Model == "" {
+			continue
+		}
+		key := profileKey(result.Model, result.Runner)
+		profile, exists := s.profiles[key]
+		if !exists {
+			profile = &Profile{Model: result.Model, Runner: result.Runner}
+			s.profiles[key] = profile
+		}
+		replaced := false
+		for i, previous := range profile.Results {
+			if previous.Node == result.Node && previous.GPUID == result.GPUID {
+				profile.Results[i] = result
+				replaced = true
+			}
+		}
+		if !replaced {
+			profile.Results = append(profile.Results, result)
+		}
+		changed[key] = profile
+	}
+
+	updated := make([]Profile, 0, len(changed))
+	for _, profile := range changed {
+		profile.summarise()
+		updated = append(updated, *profile)
+	}
+	sortProfiles(updated)
+	return updated
+}
+
+// summarise recomputes a profile's baseline from its results
+func (p *Profile) summarise() {
+	var tflops, power, clock float64
+	p.GPUs = len(p.Results)
+	p.MinTFLOPs, p.MaxTFLOPs, p.MaxTemperature = 0, 0, 0
+	for i, result := range p.Results {
+		tflops += result.TFLOPs
+		power += result.AvgPowerWatts
+		clock += result.AvgClockMHz
+		if i == 0 || result.TFLOPs < p.MinTFLOPs {
+			p.MinTFLOPs = result.TFLOPs
+		}
+		if result.TFLOPs > p.MaxTFLOPs {
+			p.MaxTFLOPs = result.TFLOPs
+		}
+		if result.MaxTemperature > p.MaxTemperature {
+			p.MaxTemperature = result.MaxTemperature
+		}
+		if result.StartedAt.After(p.UpdatedAt) {
+			p.UpdatedAt = result.StartedAt
+		}
+	}
+	p.TFLOPs = tflops / float64(p.GPUs)
+	p.AvgPowerWatts = power / float64(p.GPUs)
+	p.AvgClockMHz = clock / float64(p.GPUs)
+	p.TFLOPsPerWatt = 0
+	if p.AvgPowerWatts > 0 {
+		p.TFLOPsPerWatt = p.TFLOPs / p.AvgPowerWatts
+	}
+}
+
+// Get returns a model's profile under a runner
+func (s *ProfileStore) Get(model, runner string) (Profile, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	profile, exists := s.profiles[profileKey(model, runner)]
+	if !exists {
+		return Profile{}, false
+	}
+	return *profile, true
+}
+
+// Profiles returns every profile sorted by model and runner
+func (s *ProfileStore) Profiles() []Profile {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	profiles := make([]Profile, 0, len(s.profiles))
+	for _, profile := range s.profiles {
+		profiles = append(profiles, *profile)
+	}
+	sortProfiles(profiles)
+	return profiles
+}
+
+// Save writes the profiles to the store's file
+func (s *ProfileStore) Save() error {
+	data, err := json.MarshalIndent(s.Profiles(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode profiles: %w", err)
+	}
+	if dir := filepath.Dir(s.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create profile directory: %w", err)
+		}
+	}
+
+	// Write then rename, so a crash never leaves a partial file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write profiles: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write profiles: %w", err)
+	}
+	return nil
+}
+
+func sortProfiles(profiles []Profile) {
+	sort.Slice(profiles, func(i, j int) bool {
+		if profiles[i].Model != profiles[j].Model {
+			return profiles[i].Model < profiles[j].Model
+		}
+		return profiles[i].Runner < profiles[j].Runner
+	})
+}
@@ -0,0 +1,59 @@
+package benchmark
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func result(node, gpuID string, tflops, power float64, passed bool) Result {
+	return Result{
+		Node:           node,
+		GPUID:          gpuID,
+		Model:          "NVIDIA A100-SXM4-40GB",
+		Runner:         "gpu-burn",
+		StartedAt:      time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		TFLOPs:         tflops,
+		AvgPowerWatts:  power,
+		MaxTemperature: 70,
+		Passed:         passed,
+	}
+}
+
+func TestProfileStoreRecordsBaselines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles", "gpus.json")
+	store, err := OpenProfiles(path)
+	if err != nil {
+		t.Fatalf("OpenProfiles failed: %v", err)
+	}
+
+	updated := store.Record(&Report{Results: []Result{
+		result("node-1", "0", 14, 400, true),
+		result("node-1", "1", 12, 400, true),
+		result("node-1", "2", 3, 400, false),
+	}})
+	if len(updated) != 1 || updated[0].GPUs != 2 || updated[0].TFLOPs != 13 || updated[0].MinTFLOPs != 12 {
+		t.Errorf("Expected a baseline of the passing GPUs, got %+v", updated)
+	}
+
+	// A rerun replaces the GPU's earlier result
+	store.Record(&Report{Results: []Result{result("node-1", "1", 14, 350, true)}})
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	reopened, err := OpenProfiles(path)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	profile, ok := reopened.Get("NVIDIA A100-SXM4-40GB", "gpu-burn")
+	if !ok {
+		t.Fatal("Expected the profile saved")
+	}
+	if profile.GPUs != 2 || profile.TFLOPs != 14 || profile.AvgPowerWatts != 375 || profile.TFLOPsPerWatt != 14.0/375 {
+		t.Errorf("Expected the rerun to replace GPU 1's result, got %+v", profile)
+	}
+	if len(reopened.Profiles()) != 1 {
+		t.Errorf("Expected one profile, got %d", len(reopened.Profiles()))
+	}
+}
@@ -0,0 +1,160 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Throughput is what a runner measured on one GPU
+type Throughput struct {
+	TFLOPs float64
+	Errors int // Miscomputed results, for runners that verify their output
+}
+
+// Runner applies a standardized load to one GPU. Implementations for other
+// tools plug in through this interface, or RunnerFunc.
+type Runner interface {
+	Name() string
+	Run(ctx context.Context, gpuID string, duration time.Duration) (Throughput, error)
+}
+
+// RunnerFunc adapts a function to a Runner named "custom"
+type RunnerFunc func(ctx context.Context, gpuID string, duration time.Duration) (Throughput, error)
+
+// Name returns "custom"
+func (f RunnerFunc) Name() string { return "custom" }
+
+// Run calls f
+func (f RunnerFunc) Run(ctx context.Context, gpuID string, duration time.Duration) (Throughput, error) {
+	return f(ctx, gpuID, duration)
+}
+
+// execCommand runs a benchmark tool and returns its combined output;
+// replaced in tests
+var execCommand = func(ctx context.Context, dir string, env []string, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	return cmd.CombinedOutput()
+}
+
+// visibleDevice confines a tool to one GPU
+func visibleDevice(gpuID string) []string {
+	return []string{"CUDA_VISIBLE_DEVICES=" + gpuID}
+}
+
+var (
+	gpuBurnGflopsPattern = regexp.MustCompile(`\(([0-9.]+) Gflop/s\)`)
+	gpuBurnErrorsPattern = regexp.MustCompile(`errors: ([0-9]+)`)
+)
+
+// GPUBurn runs gpu-burn (github.com/wilicc/gpu-burn), a matrix multiply
+// burn-in that checks every result
+type GPUBurn struct {
+	Path        string // gpu_burn binary
+	Dir         string // Holds compare.ptx, which gpu_burn loads; the binary's directory when empty
+	Doubles     bool   // Use double precision
+	TensorCores bool   // Use tensor cores
+}
+
+// Name returns "gpu-burn"
+func (g *GPUBurn) Name() string { return "gpu-burn" }
+
+// Run burns one GPU for duration, rounded up to whole seconds
+func (g *GPUBurn) Run(ctx context.Context, gpuID string, duration time.Duration) (Throughput, error) {
+	var args []string
+	if g.Doubles {
+		args = append(args, "-d")
+	}
+	if g.TensorCores {
+		args = append(args, "-tc")
+	}
+	args = append(args, strconv.Itoa(seconds(duration)))
+
+	dir := g.Dir
+	if dir == "" && strings.ContainsRune(g.Path, os.PathSeparator) {
+		dir = filepath.Dir(g.Path)
+	}
+	output, err := execCommand(ctx, dir, visibleDevice(gpuID), g.Path, args...)
+	return parseGPUBurn(output, err)
+}
+
+// parseGPUBurn reads the last progress line of gpu-burn output, e.g.
+// "100.0%  proc'd: 2230 (14235 Gflop/s)   errors: 0   temps: 64 C"
+func parseGPUBurn(output []byte, runErr error) (Throughput, error) {
+	var throughput Throughput
+	gflops := gpuBurnGflopsPattern.FindAllSubmatch(output, -1)
+	if len(gflops) == 0 {
+		if runErr != nil {
+			return throughput, fmt.Errorf("gpu-burn failed: %w: %s", runErr, lastLine(output))
+		}
+		return throughput, fmt.Errorf("gpu-burn reported no throughput: %s", lastLine(output))
+	}
+	value, _ := strconv.ParseFloat(string(gflops[len(gflops)-1][1]), 64)
+	throughput.TFLOPs = value / 1000
+	if errs := gpuBurnErrorsPattern.FindAllSubmatch(output, -1); len(errs) > 0 {
+		throughput.Errors, _ = strconv.Atoi(string(errs[len(errs)-1][1]))
+	}
+	if runErr != nil && throughput.Errors == 0 {
+		return throughput, fmt.Errorf("gpu-burn failed: %w", runErr)
+	}
+	return throughput, nil
+}
+
+var tflopsPattern = regexp.MustCompile(`(?i)([0-9]+(?:\.[0-9]+)?)\s*TFLOPS`)
+
+// Command runs any benchmark tool, e.g. a matrix multiply script, that
+// prints its achieved throughput as "<n> TFLOPS"; the last such figure
+// counts. "{gpu}" and "{seconds}" in the arguments are replaced.
+type Command struct {
+	Args []string // Tool and arguments
+}
+
+// Name returns the tool's base name
+func (c *Command) Name() string {
+	if len(c.Args) == 0 {
+		return "command"
+	}
+	return filepath.Base(c.Args[0])
+}
+
+// Run runs the tool on one GPU
+func (c *Command) Run(ctx context.Context, gpuID string, duration time.Duration) (Throughput, error) {
+	if len(c.Args) == 0 {
+		return Throughput{}, fmt.Errorf("no benchmark command configured")
+	}
+	replacer := strings.NewReplacer("{gpu}", gpuID, "{seconds}", strconv.Itoa(seconds(duration)))
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = replacer.Replace(arg)
+	}
+
+	output, err := execCommand(ctx, "", visibleDevice(gpuID), args[0], args[1:]...)
+	if err != nil {
+		return Throughput{}, fmt.Errorf("%s failed: %w: %s", c.Name(), err, lastLine(output))
+	}
+	matches := tflopsPattern.FindAllSubmatch(output, -1)
+	if len(matches) == 0 {
+		return Throughput{}, fmt.Errorf("%s printed no TFLOPS figure: %s", c.Name(), lastLine(output))
+	}
+	tflops, _ := strconv.ParseFloat(string(matches[len(matches)-1][1]), 64)
+	return Throughput{TFLOPs: tflops}, nil
+}
+
+// seconds rounds a duration up to whole seconds
+func seconds(duration time.Duration) int {
+	return int((duration + time.Second - 1) / time.Second)
+}
+
+// lastLine returns the last non-empty line of tool output
+func lastLine(output []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,81 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubExec replaces execCommand, recording the last invocation
+func stubExec(t *testing.T, output string, err error) *[]string {
+	var invoked []string
+	original := execCommand
+	execCommand = func(ctx context.Context, dir string, env []string, name string, args ...string) ([]byte, error) {
+		invoked = append(append(append([]string{dir}, env...), name), args...)
+		return []byte(output), err
+	}
+	t.Cleanup(func() { execCommand = original })
+	return &invoked
+}
+
+const gpuBurnOutput = `Burning for 60 seconds.
+GPU 0: NVIDIA A100-SXM4-40GB (UUID: GPU-5e2c)
+Initialized device 0 with 40536 MB of memory (40133 MB available, using 36119 MB of it), using FLOATS
+50.0%  proc'd: 1115 (13871 Gflop/s)   errors: 0   temps: 61 C
+100.0%  proc'd: 2230 (14235 Gflop/s)   errors: 0   temps: 64 C
+Tested 1 GPUs:
+	GPU 0: OK
+`
+
+func TestGPUBurnRunner(t *testing.T) {
+	invoked := stubExec(t, gpuBurnOutput, nil)
+
+	runner := &GPUBurn{Path: "/opt/gpu-burn/gpu_burn", TensorCores: true}
+	throughput, err := runner.Run(context.Background(), "3", 59500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if throughput.TFLOPs != 14.235 || throughput.Errors != 0 {
+		t.Errorf("Expected the last progress line, got %+v", throughput)
+	}
+	want := []string{"/opt/gpu-burn", "CUDA_VISIBLE_DEVICES=3", "/opt/gpu-burn/gpu_burn", "-tc", "60"}
+	if !reflect.DeepEqual(*invoked, want) {
+		t.Errorf("Expected %v, got %v", want, *invoked)
+	}
+
+	stubExec(t, strings.Replace(gpuBurnOutput, "errors: 0   temps: 64", "errors: 12   temps: 64", 1), errors.New("exit status 1"))
+	throughput, err = runner.Run(context.Background(), "3", time.Minute)
+	if err != nil || throughput.Errors != 12 {
+		t.Errorf("Expected computation errors reported, got %+v, %v", throughput, err)
+	}
+
+	stubExec(t, "Couldn't init a GPU test: cudaErrorNoDevice", errors.New("exit status 1"))
+	if _, err := runner.Run(context.Background(), "3", time.Minute); err == nil || !strings.Contains(err.Error(), "cudaErrorNoDevice") {
+		t.Errorf("Expected the tool's failure reported, got %v", err)
+	}
+}
+
+func TestCommandRunner(t *testing.T) {
+	invoked := stubExec(t, "warmup done\nmatmul fp16: 231.7 TFLOPS\n", nil)
+
+	runner := &Command{Args: []string{"python3", "matmul.py", "--device", "cuda:{gpu}", "--seconds", "{seconds}"}}
+	if runner.Name() != "python3" {
+		t.Errorf("Expected the runner named after its tool, got %s", runner.Name())
+	}
+	throughput, err := runner.Run(context.Background(), "1", 30*time.Second)
+	if err != nil || throughput.TFLOPs != 231.7 {
+		t.Errorf("Expected 231.7 TFLOPs, got %+v, %v", throughput, err)
+	}
+	want := []string{"", "CUDA_VISIBLE_DEVICES=1", "python3", "matmul.py", "--device", "cuda:1", "--seconds", "30"}
+	if !reflect.DeepEqual(*invoked, want) {
+		t.Errorf("Expected %v, got %v", want, *invoked)
+	}
+
+	stubExec(t, "done\n", nil)
+	if _, err := runner.Run(context.Background(), "1", time.Second); err == nil {
+		t.Error("Expected output without a TFLOPS figure to fail")
+	}
+}
@@ -0,0 +1,255 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+)
+
+// GPUSummary is a GPU as listed by the dashboard
+type GPUSummary struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Status      string            `json:"status"`
+	Utilization float64           `json:"utilization"`
+	Temperature float64           `json:"temperature"`
+	MemoryTotal uint64            `json:"memory_total"` // MB
+	MemoryUsed  uint64            `json:"memory_used"`  // MB
+	PowerDraw   float64           `json:"power_draw"`
+	LastUpdated time.Time         `json:"last_updated"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// GPUHistoryPoint is one sample of a GPU's history
+type GPUHistoryPoint struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Utilization float64   `json:"utilization"`
+	Temperature float64   `json:"temperature"`
+	MemoryUsed  uint64    `json:"memory_used"` // MB
+}
+
+// WorkloadFilter narrows a workload listing; empty fields match everything
+type WorkloadFilter struct {
+	Status   gpu.WorkloadStatus
+	Pool     string
+	Selector string // Label selector, e.g. team=ml,tier!=batch
+}
+
+// Metrics returns the current dashboard metrics
+func (c *Client) Metrics(ctx context.Context) (*observability.DashboardMetrics, error) {
+	var metrics observability.DashboardMetrics
+	if err := c.do(ctx, http.MethodGet, "/api/v1/metrics", nil, nil, &metrics); err != nil {
+		return nil, err
+	}
+	return &metrics, nil
+}
+
+// SystemStats returns cluster-wide GPU statistics
+func (c *Client) SystemStats(ctx context.Context) (*observability.SystemStats, error) {
+	var stats observability.SystemStats
+	if err := c.do(ctx, http.MethodGet, "/api/v1/system/stats", nil, nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GPUMetrics returns the latest metrics of a GPU
+func (c *Client) GPUMetrics(ctx context.Context, gpuID string) (*gpu.GPUMetrics, error) {
+	var metrics gpu.GPUMetrics
+	if err := c.do(ctx, http.MethodGet, "/api/v1/gpu/"+url.PathEscape(gpuID)+"/metrics", nil, nil, &metrics); err != nil {
+		return nil, err
+	}
+	return &metrics, nil
+}
+
+// GPUHistory returns a GPU's samples over the last hours
+func (c *Client) GPUHistory(ctx context.Context, gpuID string, hours int) ([]GPUHistoryPoint, error) {
+	query := url.Values{}
+	if hours > 0 {
+		query.Set("hours", strconv.Itoa(hours))
+	}
+	var response struct {
+		History []GPUHistoryPoint `json:"history"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/gpu/"+url.PathEscape(gpuID)+"/history", query, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.History, nil
+}
+
+// GPUs lists GPUs whose labels match selector, or all GPUs when it is empty
+func (c *Client) GPUs(ctx context.Context, selector string) ([]GPUSummary, error) {
+	query := url.Values{}
+	if selector != "" {
+		query.Set("selector", selector)
+	}
+	var response struct {
+		GPUs []GPUSummary `json:"gpus"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/gpus", query, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.GPUs, nil
+}
+
+// Workloads lists scheduled workloads
+func (c *Client) Workloads(ctx context.Context, filter WorkloadFilter) ([]gpu.WorkloadInfo, error) {
+	query := url.Values{}
+	if filter.Status != "" {
+		query.Set("status", string(filter.Status))
+	}
+	if filter.Pool != "" {
+		query.Set("pool", filter.Pool)
+	}
+	if filter.Selector != "" {
+		query.Set("selector", filter.Selector)
+	}
+	var response struct {
+		Workloads []gpu.WorkloadInfo `json:"workloads"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/workloads", query, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Workloads, nil
+}
+
+// Pools returns the status of the scheduler's GPU pools
+func (c *Client) Pools(ctx context.Context) ([]gpu.PoolStatus, error) {
+	var response struct {
+		Pools []gpu.PoolStatus `json:"pools"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/pools", nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Pools, nil
+}
+
+// Capacity returns each pool's demand against its GPUs, for provisioners
+// deciding whether to add or remove nodes
+func (c *Client) Capacity(ctx context.Context) ([]gpu.PoolCapacity, error) {
+	var response struct {
+		Pools []gpu.PoolCapacity `json:"pools"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/capacity", nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Pools, nil
+}
+
+// PushMetrics uploads metrics collected on a node, as its agent does. The
+// batch is stamped with the local time of each attempt, from which the
+// server measures the node's clock skew.
+func (c *Client) PushMetrics(ctx context.Context, batch observability.AgentBatch) (*observability.AgentIngestResult, error) {
+	var result observability.AgentIngestResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/agents/metrics", nil, stampedBatch(batch), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// stampedBatch encodes as an agent batch sent at the time it is encoded
+type stampedBatch observability.AgentBatch
+
+func (b stampedBatch) MarshalJSON() ([]byte, error) {
+	batch := observability.AgentBatch(b)
+	batch.SentAt = time.Now()
+	return json.Marshal(batch)
+}
+
+// AgentClocks returns each node agent's clock skew as last measured
+func (c *Client) AgentClocks(ctx context.Context) ([]observability.NodeClock, error) {
+	var response struct {
+		Nodes []observability.NodeClock `json:"nodes"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/agents/clocks", nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Nodes, nil
+}
+
+// Alerts returns the active alerts
+func (c *Client) Alerts(ctx context.Context) ([]observability.Alert, error) {
+	var alerts []observability.Alert
+	if err := c.do(ctx, http.MethodGet, "/api/v1/alerts", nil, nil, &alerts); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// ResolveAlert marks an alert resolved
+func (c *Client) ResolveAlert(ctx context.Context, alertID string) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/alerts/"+url.PathEscape(alertID)+"/resolve", nil, nil, nil)
+}
+
+// Incidents lists incidents with status open, resolved or all; open when empty
+func (c *Client) Incidents(ctx context.Context, status string) ([]observability.Incident, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	var response struct {
+		Incidents []observability.Incident `json:"incidents"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/incidents", query, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Incidents, nil
+}
+
+// Costs returns the cost summary of the current period
+func (c *Client) Costs(ctx context.Context) (*observability.CostSummary, error) {
+	var costs observability.CostSummary
+	if err := c.do(ctx, http.MethodGet, "/api/v1/costs", nil, nil, &costs); err != nil {
+		return nil, err
+	}
+	return &costs, nil
+}
+
+// CostWhatIf prices the last hours of GPU usage under each scenario; the
+// dashboard defaults to a week when hours is 0
+func (c *Client) CostWhatIf(ctx context.Context, hours int, scenarios []observability.WhatIfScenario) (*observability.WhatIfReport, error) {
+	request := map[string]interface{}{
+		"hours":     hours,
+		"scenarios": scenarios,
+	}
+	var report observability.WhatIfReport
+	if err := c.do(ctx, http.MethodPost, "/api/v1/costs/whatif", nil, request, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Annotate marks a deployment, incident or maintenance window on the
+// dashboard's charts; the server stamps annotations without a time with now
+func (c *Client) Annotate(ctx context.Context, annotation observability.Annotation) (*observability.Annotation, error) {
+	var created observability.Annotation
+	if err := c.do(ctx, http.MethodPost, "/api/v1/annotations", nil, annotation, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// Annotations lists the annotations between start and end carrying every
+// one of tags
+func (c *Client) Annotations(ctx context.Context, start, end time.Time, tags []string) ([]observability.Annotation, error) {
+	query := url.Values{}
+	query.Set("start", start.Format(time.RFC3339Nano))
+	query.Set("end", end.Format(time.RFC3339Nano))
+	if len(tags) > 0 {
+		query.Set("tags", strings.Join(tags, ","))
+	}
+	var response struct {
+		Annotations []observability.Annotation `json:"annotations"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/annotations", query, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Annotations, nil
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+)
+
+func TestTypedMethodsSendQueriesAndDecode(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/workloads":
+			if r.URL.Query().Get("status") != "running" || r.URL.Query().Get("selector") != "team=ml" {
+				t.Errorf("Expected the filter as query parameters, got %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"workloads": [{"id": "w-1", "name": "train", "status": "running"}], "count": 1}`))
+		case "/api/v1/gpus":
+			w.Write([]byte(`{"gpus": [{"id": "gpu-0", "utilization": 80, "labels": {"zone": "a"}}], "total": 1}`))
+		case "/api/v1/capacity":
+			w.Write([]byte(`{"pools": [{"pool": "batch", "gpus": 4, "pending": 6, "projected_wait": 1800000000000}]}`))
+		case "/api/v1/costs/whatif":
+			var request struct {
+				Hours     int                            `json:"hours"`
+				Scenarios []observability.WhatIfScenario `json:"scenarios"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Hours != 24 || request.Scenarios[0].Name != "spot" {
+				t.Errorf("Expected the scenarios in the body, got %+v (%v)", request, err)
+			}
+			w.Write([]byte(`{"currency": "USD", "baseline": {"cost": 10}, "scenarios": [{"name": "spot", "cost": 6, "delta": -4}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	ctx := context.Background()
+
+	workloads, err := client.Workloads(ctx, WorkloadFilter{Status: "running", Selector: "team=ml"})
+	if err != nil || len(workloads) != 1 || workloads[0].ID != "w-1" {
+		t.Errorf("Expected one workload, got %+v (%v)", workloads, err)
+	}
+
+	gpus, err := client.GPUs(ctx, "")
+	if err != nil || len(gpus) != 1 || gpus[0].Utilization != 80 || gpus[0].Labels["zone"] != "a" {
+		t.Errorf("Expected one GPU, got %+v (%v)", gpus, err)
+	}
+
+	capacity, err := client.Capacity(ctx)
+	if err != nil || len(capacity) != 1 || capacity[0].Pending != 6 || capacity[0].ProjectedWait != 30*time.Minute {
+		t.Errorf("Expected the batch pool's capacity, got %+v (%v)", capacity, err)
+	}
+
+	report, err := client.CostWhatIf(ctx, 24, []observability.WhatIfScenario{{Name: "spot", SpotFraction: 0.5, SpotDiscount: 0.6}})
+	if err != nil || report.Baseline.Cost != 10 || report.Scenarios[0].Delta != -4 {
+		t.Errorf("Expected the what-if report, got %+v (%v)", report, err)
+	}
+}
+
+func TestPushMetricsStampsEachAttempt(t *testing.T) {
+	var sent []time.Time
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var batch observability.AgentBatch
+		json.NewDecoder(r.Body).Decode(&batch)
+		sent = append(sent, batch.SentAt)
+		if len(sent) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(observability.AgentIngestResult{Accepted: len(batch.Metrics)})
+	})
+
+	result, err := client.PushMetrics(context.Background(), observability.AgentBatch{Node: "node-a", Metrics: []gpu.GPUMetrics{{GPUID: "0"}}})
+	if err != nil || result.Accepted != 1 {
+		t.Fatalf("Expected the sample accepted, got %v, %v", result, err)
+	}
+	if len(sent) != 2 || !sent[1].After(sent[0]) {
+		t.Errorf("Expected the retry stamped later than the first attempt, got %v", sent)
+	}
+}
+
+func TestAnnotateAndListAnnotations(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			var annotation observability.Annotation
+			json.NewDecoder(r.Body).Decode(&annotation)
+			annotation.ID = "annotation-1"
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(annotation)
+		default:
+			if r.URL.Query().Get("tags") != "deploy,chat" || r.URL.Query().Get("start") == "" {
+				t.Errorf("Expected the range and tags as query parameters, got %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"annotations": [{"id": "annotation-1", "text": "model v2 deployed"}], "count": 1}`))
+		}
+	})
+	ctx := context.Background()
+
+	created, err := client.Annotate(ctx, observability.Annotation{Text: "model v2 deployed", Tags: []string{"deploy"}})
+	if err != nil || created.ID != "annotation-1" || created.Tags[0] != "deploy" {
+		t.Errorf("Expected the created annotation, got %+v (%v)", created, err)
+	}
+
+	now := time.Now()
+	annotations, err := client.Annotations(ctx, now.Add(-time.Hour), now, []string{"deploy", "chat"})
+	if err != nil || len(annotations) != 1 || annotations[0].Text != "model v2 deployed" {
+		t.Errorf("Expected one annotation, got %+v (%v)", annotations, err)
+	}
+}
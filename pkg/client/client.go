@@ -0,0 +1,366 @@
+// Package client is a Go client for the AgentaFlow dashboard API, with typed
+// methods, bearer authentication, retries and WebSocket streaming helpers
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+)
+
+// Config holds client configuration
+type Config struct {
+	BaseURL      string        // Dashboard address, e.g. http://localhost:8080
+	Token        string        // API key or control token, sent as a bearer token
+	Timeout      time.Duration // Per-attempt timeout
+	MaxRetries   int           // Retries of failed attempts; 0 disables retrying
+	RetryBackoff time.Duration // Wait before the first retry, doubled for each one after
+	HTTPClient   *http.Client  // Optional; a client with Timeout is created when nil
+	UserAgent    string        // Optional, names the caller in dashboard logs
+	Compression  string        // Optional Content-Encoding for request bodies: gzip, snappy, or one registered with RegisterCompression
+
+	// Further dashboard addresses, e.g. a warm standby's. Requests move to
+	// the next one when the current address cannot be reached, and to the
+	// primary a standby names when it turns a request away.
+	Endpoints []string
+}
+
+// Headers a server of a warm standby pair sets on API responses
+const (
+	epochHeader   = "X-AgentaFlow-Epoch"   // Lease epoch of the answering server
+	primaryHeader = "X-AgentaFlow-Primary" // Address of the primary, set when a standby turns a request away
+)
+
+// minCompressSize is the smallest request body worth compressing
+const minCompressSize = 1024
+
+// Compressor creates a compressing writer for a Content-Encoding
+type Compressor func(w io.Writer) (io.WriteCloser, error)
+
+var (
+	compressors = map[string]Compressor{
+		"gzip": func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+		// The framed snappy stream format, cheaper on agent CPU than gzip
+		"snappy": func(w io.Writer) (io.WriteCloser, error) {
+			return snappy.NewBufferedWriter(w), nil
+		},
+	}
+	compressorsMu sync.RWMutex
+)
+
+// RegisterCompression adds a Content-Encoding for request bodies, such as
+// "zstd" backed by a compression library. gzip and snappy are built in on
+// both sides; for others the dashboard must register a matching decoder
+// with observability.RegisterDecoding.
+func RegisterCompression(name string, compressor Compressor) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+	compressors[name] = compressor
+}
+
+// DefaultConfig returns default client configuration for a dashboard address
+func DefaultConfig(baseURL string) Config {
+	return Config{
+		BaseURL:      baseURL,
+		Timeout:      30 * time.Second,
+		MaxRetries:   3,
+		RetryBackoff: 500 * time.Millisecond,
+	}
+}
+
+// Client calls the dashboard API
+type Client struct {
+	config Config
+	http   *http.Client
+
+	endpoints []*url.URL // BaseURL, then the further endpoints
+	current   int        // Index of the endpoint requests go to
+	epoch     uint64     // Highest lease epoch seen in a response
+	mu        sync.Mutex
+}
+
+// New creates a new dashboard API client
+func New(config Config) (*Client, error) {
+	var endpoints []*url.URL
+	for _, address := range append([]string{config.BaseURL}, config.Endpoints...) {
+		endpoint, err := url.Parse(strings.TrimSuffix(address, "/"))
+		if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid base URL %q: expected e.g. http://localhost:8080", address)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	if config.MaxRetries < 0 {
+		return nil, fmt.Errorf("max retries must not be negative")
+	}
+	if config.Compression != "" {
+		compressorsMu.RLock()
+		_, ok := compressors[config.Compression]
+		compressorsMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("unknown compression %q", config.Compression)
+		}
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: config.Timeout}
+	}
+	return &Client{config: config, http: httpClient, endpoints: endpoints}, nil
+}
+
+// Endpoint returns the dashboard address requests currently go to
+func (c *Client) Endpoint() string {
+	endpoint := c.endpoint()
+	return endpoint.String()
+}
+
+// endpoint returns a copy of the address requests currently go to
+func (c *Client) endpoint() url.URL {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return *c.endpoints[c.current]
+}
+
+// failover moves requests from an unreachable endpoint to the next one,
+// unless another request already moved them. It reports whether there is
+// another endpoint to try.
+func (c *Client) failover(from url.URL) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.endpoints) < 2 {
+		return false
+	}
+	if c.endpoints[c.current].String() == from.String() {
+		c.current = (c.current + 1) % len(c.endpoints)
+	}
+	return true
+}
+
+// redirect moves requests to the primary a standby named, reporting whether
+// they moved
+func (c *Client) redirect(primary string) bool {
+	target, err := url.Parse(strings.TrimSuffix(primary, "/"))
+	if primary == "" || err != nil || target.Scheme == "" || target.Host == "" {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, endpoint := range c.endpoints {
+		if endpoint.String() == target.String() {
+			moved := i != c.current
+			c.current = i
+			return moved
+		}
+	}
+	c.endpoints = append(c.endpoints, target)
+	c.current = len(c.endpoints) - 1
+	return true
+}
+
+// observeEpoch records the lease epoch a response carries, reporting whether
+// it comes from a primary deposed since a newer one answered
+func (c *Client) observeEpoch(resp *http.Response) bool {
+	epoch, err := strconv.ParseUint(resp.Header.Get(epochHeader), 10, 64)
+	if err != nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if epoch < c.epoch {
+		return true
+	}
+	c.epoch = epoch
+	return false
+}
+
+// compress encodes a request body with the configured compression,
+// returning the Content-Encoding used; small bodies are sent as they are
+func (c *Client) compress(payload []byte) ([]byte, string, error) {
+	if c.config.Compression == "" || len(payload) < minCompressSize {
+		return payload, "", nil
+	}
+	compressorsMu.RLock()
+	compressor := compressors[c.config.Compression]
+	compressorsMu.RUnlock()
+
+	var buf bytes.Buffer
+	writer, err := compressor(&buf)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := writer.Write(payload); err != nil {
+		return nil, "", err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), c.config.Compression, nil
+}
+
+// connectFailed reports whether a request failed before reaching a server
+func connectFailed(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// APIError is a non-2xx response from the dashboard
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s returned %d: %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 response
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// retryable reports whether a response status is worth retrying. Requests
+// that may have changed state are only retried when the dashboard turned
+// them away before handling them.
+func retryable(method string, statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return method != http.MethodPost
+	}
+	return false
+}
+
+// retryDelay returns how long to wait before retry attempt, honouring a
+// Retry-After header in seconds
+func (c *Client) retryDelay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return c.config.RetryBackoff << uint(attempt)
+}
+
+// do sends an API request and decodes the JSON response into out, if set.
+// Failed attempts are retried with backoff up to MaxRetries times. The body
+// is encoded for each attempt, so bodies stamped as they are encoded carry
+// the time of the attempt.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	for attempt := 0; ; attempt++ {
+		var payload []byte
+		var contentEncoding string
+		if body != nil {
+			data, err := json.Marshal(body)
+			if err != nil {
+				return fmt.Errorf("failed to encode request: %w", err)
+			}
+			if payload, contentEncoding, err = c.compress(data); err != nil {
+				return fmt.Errorf("failed to compress request: %w", err)
+			}
+		}
+
+		// Paths arrive escaped; keep their escapes rather than escaping them again
+		base := c.endpoint()
+		endpoint := base
+		rawPath := endpoint.EscapedPath() + path
+		unescaped, err := url.PathUnescape(rawPath)
+		if err != nil {
+			return fmt.Errorf("invalid request path %s: %w", path, err)
+		}
+		endpoint.Path, endpoint.RawPath = unescaped, rawPath
+		endpoint.RawQuery = query.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, method, endpoint.String(), bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if contentEncoding != "" {
+			req.Header.Set("Content-Encoding", contentEncoding)
+		}
+		if c.config.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.config.Token)
+		}
+		if c.config.UserAgent != "" {
+			req.Header.Set("User-Agent", c.config.UserAgent)
+		}
+
+		resp, err := c.http.Do(req)
+		stale := err == nil && c.observeEpoch(resp)
+		succeeded := err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300
+		if succeeded && !stale {
+			defer resp.Body.Close()
+			if out == nil {
+				return nil
+			}
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return fmt.Errorf("failed to decode %s response: %w", path, err)
+			}
+			return nil
+		}
+
+		var failure error
+		var retry, moved bool
+		switch {
+		case err != nil:
+			failure = fmt.Errorf("%s %s failed: %w", method, path, err)
+			retry = method != http.MethodPost
+			// Nothing reached a server that could not be dialed, so even a
+			// POST moves on to the next endpoint
+			if connectFailed(err) && c.failover(base) {
+				retry, moved = true, true
+			}
+		case succeeded:
+			// A deposed primary answered; its response may predate the failover
+			resp.Body.Close()
+			failure = fmt.Errorf("%s %s answered by a deposed primary at epoch %s", method, path, resp.Header.Get(epochHeader))
+			retry = method != http.MethodPost && c.failover(base)
+			moved = retry
+		default:
+			message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			resp.Body.Close()
+			failure = &APIError{Method: method, Path: path, StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+			retry = retryable(method, resp.StatusCode)
+			if resp.StatusCode == http.StatusServiceUnavailable {
+				moved = c.redirect(resp.Header.Get(primaryHeader))
+			}
+		}
+		if !retry || attempt >= c.config.MaxRetries || ctx.Err() != nil {
+			return failure
+		}
+
+		delay := c.retryDelay(attempt, resp)
+		if moved {
+			delay = 0
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return failure
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	config := DefaultConfig(server.URL)
+	config.Token = "secret"
+	config.RetryBackoff = time.Millisecond
+	client, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return client
+}
+
+func TestNewRejectsInvalidBaseURL(t *testing.T) {
+	for _, baseURL := range []string{"", "localhost:8080", "://"} {
+		if _, err := New(DefaultConfig(baseURL)); err == nil {
+			t.Errorf("Expected %q rejected", baseURL)
+		}
+	}
+}
+
+func TestRetriesOverloadedRequestsWithBearerToken(t *testing.T) {
+	var attempts int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Expected the bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"total_gpus": 4, "average_utilization": 62.5}`))
+	})
+
+	stats, err := client.SystemStats(context.Background())
+	if err != nil {
+		t.Fatalf("Expected the third attempt to succeed: %v", err)
+	}
+	if stats.TotalGPUs != 4 || stats.AverageUtil != 62.5 || attempts != 3 {
+		t.Errorf("Expected decoded stats after 3 attempts, got %+v after %d", stats, attempts)
+	}
+}
+
+func TestClientErrorsAreNotRetried(t *testing.T) {
+	var attempts int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		http.Error(w, "GPU not found", http.StatusNotFound)
+	})
+
+	_, err := client.GPUMetrics(context.Background(), "gpu-9")
+	if !IsNotFound(err) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+	if apiErr := err.(*APIError); apiErr.Message != "GPU not found" || apiErr.Path != "/api/v1/gpu/gpu-9/metrics" {
+		t.Errorf("Expected the response message and path, got %+v", apiErr)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected a single attempt, got %d", attempts)
+	}
+}
+
+func TestPostsAreNotRetriedAfterGatewayErrors(t *testing.T) {
+	var attempts int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	if err := client.ResolveAlert(context.Background(), "alert-1"); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected a POST that may have been handled sent once, got %d", attempts)
+	}
+}
+
+func TestCompressesLargeRequestBodies(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := io.Reader(r.Body)
+		switch r.Header.Get("Content-Encoding") {
+		case "gzip":
+			reader, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Fatalf("Expected a gzip body: %v", err)
+			}
+			body = reader
+		case "snappy":
+			body = snappy.NewReader(r.Body)
+		}
+		data, _ := io.ReadAll(body)
+		received = append(received, r.Header.Get("Content-Encoding")+":"+strconv.Itoa(len(data)))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig(server.URL)
+	config.Compression = "gzip"
+	client, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.do(context.Background(), http.MethodPost, "/large", nil, strings.Repeat("x", 2000), nil)
+	client.do(context.Background(), http.MethodPost, "/small", nil, "x", nil)
+	if len(received) != 2 || received[0] != "gzip:2002" || received[1] != ":3" {
+		t.Errorf("Expected only the large body compressed, got %v", received)
+	}
+
+	config.Compression = "snappy"
+	received = nil
+	snappyClient, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create snappy client: %v", err)
+	}
+	snappyClient.do(context.Background(), http.MethodPost, "/large", nil, strings.Repeat("x", 2000), nil)
+	if len(received) != 1 || received[0] != "snappy:2002" {
+		t.Errorf("Expected the large body sent as snappy, got %v", received)
+	}
+
+	config.Compression = "lz4"
+	if _, err := New(config); err == nil {
+		t.Error("Expected an unregistered compression rejected")
+	}
+}
+
+func TestFailsOverToThePrimary(t *testing.T) {
+	var resolved int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&resolved, 1)
+		w.Header().Set("X-AgentaFlow-Epoch", "2")
+	}))
+	defer primary.Close()
+	standby := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-AgentaFlow-Epoch", "2")
+		w.Header().Set("X-AgentaFlow-Primary", primary.URL)
+		http.Error(w, "standby is standby, not primary", http.StatusServiceUnavailable)
+	}))
+	defer standby.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	config := DefaultConfig(down.URL)
+	config.Endpoints = []string{standby.URL}
+	config.RetryBackoff = time.Millisecond
+	client, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	// The POST never reached the server that was down, so it follows the standby to the primary
+	if err := client.ResolveAlert(context.Background(), "alert-1"); err != nil {
+		t.Fatalf("Expected the primary to resolve the alert: %v", err)
+	}
+	if resolved != 1 || client.Endpoint() != primary.URL {
+		t.Errorf("Expected one request to the primary and later ones sent there, got %d to %s", resolved, client.Endpoint())
+	}
+}
+
+func TestDiscardsResponsesFromADeposedPrimary(t *testing.T) {
+	current := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-AgentaFlow-Epoch", "2")
+		w.Write([]byte(`{"total_gpus": 4}`))
+	}))
+	deposed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-AgentaFlow-Epoch", "1")
+		w.Write([]byte(`{"total_gpus": 2}`))
+	}))
+	defer deposed.Close()
+
+	config := DefaultConfig(current.URL)
+	config.Endpoints = []string{deposed.URL}
+	config.RetryBackoff = time.Millisecond
+	client, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, err := client.SystemStats(context.Background()); err != nil {
+		t.Fatalf("Expected the current primary to answer: %v", err)
+	}
+
+	current.Close()
+	if _, err := client.SystemStats(context.Background()); err == nil || !strings.Contains(err.Error(), "deposed primary at epoch 1") {
+		t.Errorf("Expected the deposed primary's answer discarded, got %v", err)
+	}
+}
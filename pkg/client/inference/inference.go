@@ -0,0 +1,125 @@
+// Package inference is a client for the serving gRPC API that adds bearer
+// authentication and retries to grpcapi.Client
+package inference
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/airgap"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving/grpcapi"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving/inferencepb"
+)
+
+// Config holds inference client configuration
+type Config struct {
+	Address      string        // host:port of the gRPC server
+	Token        string        // Bearer token or API key accepted by the server
+	TLS          bool          // Dial with TLS instead of plaintext
+	MaxRetries   int           // Retries of unavailable or rate limited calls
+	RetryBackoff time.Duration // Wait before the first retry, doubled for each one after
+}
+
+// DefaultConfig returns default inference client configuration for an address
+func DefaultConfig(address string) Config {
+	return Config{
+		Address:      address,
+		MaxRetries:   3,
+		RetryBackoff: 200 * time.Millisecond,
+	}
+}
+
+// Client runs inference requests against the serving gRPC API
+type Client struct {
+	config Config
+	conn   *grpc.ClientConn
+	api    *grpcapi.Client
+}
+
+// Dial connects to the serving gRPC API
+func Dial(ctx context.Context, config Config, opts ...grpc.DialOption) (*Client, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("address is required")
+	}
+	if config.TLS {
+		opts = append([]grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))}, opts...)
+	} else {
+		opts = append([]grpc.DialOption{grpc.WithInsecure()}, opts...)
+	}
+	// Dial through the air gap guard when one is installed
+	opts = append([]grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+		return airgap.DialContext(ctx, "tcp", address)
+	})}, opts...)
+
+	conn, err := grpc.DialContext(ctx, config.Address, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", config.Address, err)
+	}
+	return &Client{config: config, conn: conn, api: grpcapi.NewClient(conn)}, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Infer runs a single inference request
+func (c *Client) Infer(ctx context.Context, req *inferencepb.InferRequest) (*inferencepb.InferResponse, error) {
+	var resp *inferencepb.InferResponse
+	err := c.retry(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = c.api.Infer(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+// InferBatch submits a batch and collects every streamed response. A batch
+// is only retried when the call failed before any response arrived.
+func (c *Client) InferBatch(ctx context.Context, req *inferencepb.BatchInferRequest) ([]*inferencepb.InferResponse, error) {
+	var responses []*inferencepb.InferResponse
+	err := c.retry(ctx, func(ctx context.Context) error {
+		var err error
+		responses, err = c.api.InferBatch(ctx, req)
+		if err != nil && len(responses) > 0 {
+			return status.Errorf(codes.Aborted, "batch interrupted after %d responses: %v", len(responses), err)
+		}
+		return err
+	})
+	return responses, err
+}
+
+// retry runs call with the bearer token attached, retrying calls the server
+// turned away as unavailable or rate limited
+func (c *Client) retry(ctx context.Context, call func(ctx context.Context) error) error {
+	if c.config.Token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.config.Token)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := call(ctx)
+		switch status.Code(err) {
+		case codes.Unavailable, codes.ResourceExhausted:
+		default:
+			return err
+		}
+		if attempt >= c.config.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-time.After(c.config.RetryBackoff << uint(attempt)):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package inference
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving/grpcapi"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving/inferencepb"
+)
+
+func TestInferSendsTokenAndRetriesRateLimits(t *testing.T) {
+	manager := serving.NewServingManager(nil, time.Minute)
+	manager.RegisterModel(&serving.Model{ID: "m", Name: "Model"})
+
+	serverConfig := grpcapi.DefaultConfig()
+	serverConfig.EnableTracing = false
+	serverConfig.Authenticator = grpcapi.StaticTokenAuthenticator("secret")
+	serverConfig.RateLimit = 20
+	serverConfig.RateBurst = 1
+	server, err := grpcapi.NewServer(manager, serverConfig)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	config := DefaultConfig("bufnet")
+	config.Token = "secret"
+	config.RetryBackoff = 50 * time.Millisecond
+	client, err := Dial(context.Background(), config, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}))
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	// The second call exceeds the burst and succeeds once the limiter refills
+	for i := 0; i < 2; i++ {
+		resp, err := client.Infer(context.Background(), &inferencepb.InferRequest{RequestId: "r", ModelId: "m", Input: []byte("x")})
+		if err != nil || resp.Error != "" {
+			t.Fatalf("Expected call %d to succeed, got %v", i, err)
+		}
+	}
+}
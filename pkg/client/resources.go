@@ -0,0 +1,241 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+)
+
+// resourcePath returns the resources API path of a kind, or of one resource
+// when name is set
+func resourcePath(kind, name string) string {
+	path := "/api/v1/resources/" + kind
+	if name != "" {
+		path += "/" + url.PathEscape(name)
+	}
+	return path
+}
+
+// listResources decodes every resource of a kind into out, a slice pointer
+func (c *Client) listResources(ctx context.Context, kind string, out interface{}) error {
+	response := struct {
+		Resources interface{} `json:"resources"`
+	}{Resources: out}
+	return c.do(ctx, http.MethodGet, resourcePath(kind, ""), nil, nil, &response)
+}
+
+// AlertRules lists the per-model alert threshold profiles
+func (c *Client) AlertRules(ctx context.Context) ([]observability.GPUThresholdProfile, error) {
+	var rules []observability.GPUThresholdProfile
+	if err := c.listResources(ctx, observability.ResourceAlertRules, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// AlertRule returns an alert threshold profile
+func (c *Client) AlertRule(ctx context.Context, name string) (*observability.GPUThresholdProfile, error) {
+	var rule observability.GPUThresholdProfile
+	if err := c.do(ctx, http.MethodGet, resourcePath(observability.ResourceAlertRules, name), nil, nil, &rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// PutAlertRule creates or replaces an alert threshold profile. Replaced
+// profiles keep their place in match order; new ones are matched last.
+func (c *Client) PutAlertRule(ctx context.Context, rule observability.GPUThresholdProfile) (*observability.GPUThresholdProfile, error) {
+	var stored observability.GPUThresholdProfile
+	if err := c.do(ctx, http.MethodPut, resourcePath(observability.ResourceAlertRules, rule.Name), nil, rule, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// DeleteAlertRule deletes an alert threshold profile
+func (c *Client) DeleteAlertRule(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, resourcePath(observability.ResourceAlertRules, name), nil, nil, nil)
+}
+
+// PoolConfigs lists the configuration of the scheduler's pools
+func (c *Client) PoolConfigs(ctx context.Context) ([]observability.PoolResource, error) {
+	var pools []observability.PoolResource
+	if err := c.listResources(ctx, observability.ResourcePools, &pools); err != nil {
+		return nil, err
+	}
+	return pools, nil
+}
+
+// PoolConfig returns the configuration of a pool
+func (c *Client) PoolConfig(ctx context.Context, name string) (*observability.PoolResource, error) {
+	var pool observability.PoolResource
+	if err := c.do(ctx, http.MethodGet, resourcePath(observability.ResourcePools, name), nil, nil, &pool); err != nil {
+		return nil, err
+	}
+	return &pool, nil
+}
+
+// PutPool creates or replaces a pool
+func (c *Client) PutPool(ctx context.Context, pool observability.PoolResource) (*observability.PoolResource, error) {
+	var stored observability.PoolResource
+	if err := c.do(ctx, http.MethodPut, resourcePath(observability.ResourcePools, pool.Name), nil, pool, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// DeletePool deletes a pool; its workloads are scheduled without pool limits
+func (c *Client) DeletePool(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, resourcePath(observability.ResourcePools, name), nil, nil, nil)
+}
+
+// Budgets lists team budgets with this month's spend
+func (c *Client) Budgets(ctx context.Context) ([]observability.BudgetStatus, error) {
+	var budgets []observability.BudgetStatus
+	if err := c.listResources(ctx, observability.ResourceBudgets, &budgets); err != nil {
+		return nil, err
+	}
+	return budgets, nil
+}
+
+// Budget returns a team budget with this month's spend
+func (c *Client) Budget(ctx context.Context, name string) (*observability.BudgetStatus, error) {
+	var budget observability.BudgetStatus
+	if err := c.do(ctx, http.MethodGet, resourcePath(observability.ResourceBudgets, name), nil, nil, &budget); err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// PutBudget creates or replaces a team budget
+func (c *Client) PutBudget(ctx context.Context, budget observability.Budget) (*observability.BudgetStatus, error) {
+	var stored observability.BudgetStatus
+	if err := c.do(ctx, http.MethodPut, resourcePath(observability.ResourceBudgets, budget.Name), nil, budget, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// DeleteBudget deletes a team budget
+func (c *Client) DeleteBudget(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, resourcePath(observability.ResourceBudgets, name), nil, nil, nil)
+}
+
+// Models lists the models registered for serving
+func (c *Client) Models(ctx context.Context) ([]observability.ModelRegistration, error) {
+	var models []observability.ModelRegistration
+	if err := c.listResources(ctx, observability.ResourceModels, &models); err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// Model returns a model registered for serving
+func (c *Client) Model(ctx context.Context, id string) (*observability.ModelRegistration, error) {
+	var model observability.ModelRegistration
+	if err := c.do(ctx, http.MethodGet, resourcePath(observability.ResourceModels, id), nil, nil, &model); err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+// RegisterModel registers a model for serving, replacing one with the same ID
+func (c *Client) RegisterModel(ctx context.Context, model observability.ModelRegistration) (*observability.ModelRegistration, error) {
+	var stored observability.ModelRegistration
+	if err := c.do(ctx, http.MethodPut, resourcePath(observability.ResourceModels, model.ID), nil, model, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// UnregisterModel removes a model from serving
+func (c *Client) UnregisterModel(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, resourcePath(observability.ResourceModels, id), nil, nil, nil)
+}
+
+// Templates lists the recurring workload templates
+func (c *Client) Templates(ctx context.Context) ([]observability.WorkloadTemplate, error) {
+	var templates []observability.WorkloadTemplate
+	if err := c.listResources(ctx, observability.ResourceTemplates, &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// Template returns a recurring workload template
+func (c *Client) Template(ctx context.Context, name string) (*observability.WorkloadTemplate, error) {
+	var template observability.WorkloadTemplate
+	if err := c.do(ctx, http.MethodGet, resourcePath(observability.ResourceTemplates, name), nil, nil, &template); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// PutTemplate creates or replaces a recurring workload template; a replaced
+// template keeps its run history
+func (c *Client) PutTemplate(ctx context.Context, template observability.WorkloadTemplate) (*observability.WorkloadTemplate, error) {
+	var stored observability.WorkloadTemplate
+	if err := c.do(ctx, http.MethodPut, resourcePath(observability.ResourceTemplates, template.Name), nil, template, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// DeleteTemplate stops a recurring workload; runs already submitted continue
+func (c *Client) DeleteTemplate(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, resourcePath(observability.ResourceTemplates, name), nil, nil, nil)
+}
+
+// APIKeys lists every API key, including revoked ones, without secrets
+func (c *Client) APIKeys(ctx context.Context) ([]apikeys.Key, error) {
+	var response struct {
+		Keys []apikeys.Key `json:"keys"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/apikeys", nil, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Keys, nil
+}
+
+// APIKey returns an API key without its secret
+func (c *Client) APIKey(ctx context.Context, id string) (*apikeys.Key, error) {
+	var key apikeys.Key
+	if err := c.do(ctx, http.MethodGet, "/api/v1/apikeys/"+url.PathEscape(id), nil, nil, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// CreateAPIKey creates an API key and returns it with its secret, which the
+// dashboard shows only once
+func (c *Client) CreateAPIKey(ctx context.Context, request apikeys.KeyRequest) (*apikeys.Key, string, error) {
+	var response struct {
+		Key    apikeys.Key `json:"key"`
+		Secret string      `json:"secret"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/apikeys", nil, request, &response); err != nil {
+		return nil, "", err
+	}
+	return &response.Key, response.Secret, nil
+}
+
+// UpdateAPIKey replaces an API key's name, scopes, tenant and expiry
+func (c *Client) UpdateAPIKey(ctx context.Context, id string, request apikeys.KeyRequest) (*apikeys.Key, error) {
+	var key apikeys.Key
+	if err := c.do(ctx, http.MethodPut, "/api/v1/apikeys/"+url.PathEscape(id), nil, request, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// RevokeAPIKey revokes an API key; it stays listed for audit
+func (c *Client) RevokeAPIKey(ctx context.Context, id string) (*apikeys.Key, error) {
+	var key apikeys.Key
+	if err := c.do(ctx, http.MethodDelete, "/api/v1/apikeys/"+url.PathEscape(id), nil, nil, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+)
+
+func TestResourceMethodsUseResourcePaths(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "GET /api/v1/resources/alert-rules":
+			w.Write([]byte(`{"kind": "alert-rules", "resources": [{"name": "h100", "pattern": "(?i)h100"}]}`))
+		case "PUT /api/v1/resources/budgets/search%2Fprod":
+			var budget observability.Budget
+			if err := json.NewDecoder(r.Body).Decode(&budget); err != nil || budget.Team != "search" || budget.MonthlyLimit != 500 {
+				t.Errorf("Expected the budget in the body, got %+v (%v)", budget, err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"name": "search/prod", "team": "search", "monthly_limit": 500, "spent": 450, "alerting": true}`))
+		case "DELETE /api/v1/resources/models/chat-v1":
+			http.Error(w, "resource not found: chat-v1", http.StatusNotFound)
+		case "POST /api/v1/apikeys":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"key": {"id": "k-1", "name": "ci", "scopes": ["submit-workloads"]}, "secret": "afk_abc"}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.EscapedPath())
+			http.NotFound(w, r)
+		}
+	})
+	ctx := context.Background()
+
+	rules, err := client.AlertRules(ctx)
+	if err != nil || len(rules) != 1 || rules[0].Pattern != "(?i)h100" {
+		t.Errorf("Expected one alert rule, got %+v (%v)", rules, err)
+	}
+
+	budget, err := client.PutBudget(ctx, observability.Budget{Name: "search/prod", Team: "search", MonthlyLimit: 500})
+	if err != nil || budget.Spent != 450 || !budget.Alerting {
+		t.Errorf("Expected the budget status, got %+v (%v)", budget, err)
+	}
+
+	if err := client.UnregisterModel(ctx, "chat-v1"); !IsNotFound(err) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+
+	key, secret, err := client.CreateAPIKey(ctx, apikeys.KeyRequest{Name: "ci", Scopes: []apikeys.Scope{apikeys.ScopeSubmitWorkloads}})
+	if err != nil || key.ID != "k-1" || secret != "afk_abc" {
+		t.Errorf("Expected the key and its secret, got %+v %q (%v)", key, secret, err)
+	}
+}
@@ -0,0 +1,203 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+)
+
+// MetricsUpdate is a metrics broadcast with any delta already applied, so
+// every update carries the full state of every GPU
+type MetricsUpdate struct {
+	Seq     uint64
+	Metrics observability.DashboardMetrics // GPUMetrics holds gpu.GPUMetrics values
+	GPUs    map[string]gpu.GPUMetrics
+}
+
+// StreamHandlers receive WebSocket events; nil handlers skip their events
+type StreamHandlers struct {
+	Metrics  func(MetricsUpdate)
+	Alert    func(observability.Alert)
+	Incident func(change string, incident observability.Incident)
+}
+
+// streamMessage is a WebSocket message as sent by the dashboard
+type streamMessage struct {
+	Type    string          `json:"type"`
+	Seq     uint64          `json:"seq"`
+	BaseSeq uint64          `json:"base_seq"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// errStreamGap means a delta did not apply to the last update received
+var errStreamGap = fmt.Errorf("metrics delta does not follow the last update")
+
+// metricsState rebuilds full metrics from keyframes and deltas
+type metricsState struct {
+	seq  uint64
+	gpus map[string]map[string]interface{}
+}
+
+// apply merges a metrics_update or metrics_delta message into the state
+func (s *metricsState) apply(message streamMessage) (MetricsUpdate, error) {
+	var data struct {
+		observability.DashboardMetrics
+		GPUMetrics  map[string]map[string]interface{} `json:"gpu_metrics"`
+		RemovedGPUs []string                          `json:"removed_gpus"`
+	}
+	if err := json.Unmarshal(message.Data, &data); err != nil {
+		return MetricsUpdate{}, fmt.Errorf("failed to decode %s: %w", message.Type, err)
+	}
+
+	if message.Type == "metrics_update" {
+		s.gpus = data.GPUMetrics
+	} else {
+		if s.gpus == nil || message.BaseSeq != s.seq {
+			return MetricsUpdate{}, errStreamGap
+		}
+		for gpuID, changed := range data.GPUMetrics {
+			if s.gpus[gpuID] == nil {
+				s.gpus[gpuID] = make(map[string]interface{})
+			}
+			for field, value := range changed {
+				s.gpus[gpuID][field] = value
+			}
+		}
+		for _, gpuID := range data.RemovedGPUs {
+			delete(s.gpus, gpuID)
+		}
+	}
+	s.seq = message.Seq
+
+	update := MetricsUpdate{Seq: message.Seq, Metrics: data.DashboardMetrics, GPUs: make(map[string]gpu.GPUMetrics, len(s.gpus))}
+	update.Metrics.GPUMetrics = make(map[string]interface{}, len(s.gpus))
+	for gpuID, fields := range s.gpus {
+		encoded, err := json.Marshal(fields)
+		if err != nil {
+			return MetricsUpdate{}, err
+		}
+		var metrics gpu.GPUMetrics
+		if err := json.Unmarshal(encoded, &metrics); err != nil {
+			return MetricsUpdate{}, fmt.Errorf("failed to decode metrics of %s: %w", gpuID, err)
+		}
+		update.GPUs[gpuID] = metrics
+		update.Metrics.GPUMetrics[gpuID] = metrics
+	}
+	return update, nil
+}
+
+// Stream delivers dashboard WebSocket events to handlers until ctx is done,
+// reconnecting with backoff when the connection drops. Metrics arrive as
+// deltas on the wire and are merged before delivery.
+func (c *Client) Stream(ctx context.Context, handlers StreamHandlers) error {
+	for attempt := 0; ; attempt++ {
+		started := time.Now()
+		err := c.streamOnce(ctx, handlers)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// A connection that stayed up a while resets the backoff
+		if time.Since(started) > time.Minute {
+			attempt = 0
+		}
+		delay := c.config.RetryBackoff << uint(attempt)
+		if delay <= 0 || delay > 30*time.Second {
+			delay = 30 * time.Second
+		}
+		if err == errStreamGap {
+			delay = 0
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// streamOnce reads events from a single WebSocket connection
+func (c *Client) streamOnce(ctx context.Context, handlers StreamHandlers) error {
+	base := c.endpoint()
+	endpoint := base
+	switch endpoint.Scheme {
+	case "https":
+		endpoint.Scheme = "wss"
+	default:
+		endpoint.Scheme = "ws"
+	}
+	endpoint.Path += "/ws"
+	endpoint.RawQuery = url.Values{"delta": {"1"}}.Encode()
+
+	header := http.Header{}
+	if c.config.Token != "" {
+		header.Set("Authorization", "Bearer "+c.config.Token)
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, endpoint.String(), header)
+	if err != nil {
+		if connectFailed(err) {
+			c.failover(base)
+		}
+		return fmt.Errorf("failed to connect to %s: %w", endpoint.Redacted(), err)
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	var state metricsState
+	for {
+		var message streamMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			return err
+		}
+
+		switch message.Type {
+		case "metrics_update", "metrics_delta":
+			if handlers.Metrics == nil {
+				continue
+			}
+			update, err := state.apply(message)
+			if err != nil {
+				return err
+			}
+			handlers.Metrics(update)
+		case "alert":
+			if handlers.Alert == nil {
+				continue
+			}
+			var alert observability.Alert
+			if err := json.Unmarshal(message.Data, &alert); err != nil {
+				return fmt.Errorf("failed to decode alert: %w", err)
+			}
+			handlers.Alert(alert)
+		case "incident":
+			if handlers.Incident == nil {
+				continue
+			}
+			var data struct {
+				Change   string                 `json:"change"`
+				Incident observability.Incident `json:"incident"`
+			}
+			if err := json.Unmarshal(message.Data, &data); err != nil {
+				return fmt.Errorf("failed to decode incident: %w", err)
+			}
+			handlers.Incident(data.Change, data.Incident)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+)
+
+func TestStreamMergesDeltasAndResyncsOnGaps(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	var connections int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("delta") != "1" || r.URL.Query().Get("token") != "" || r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Expected a delta stream with the token in a header, got %s", r.URL.RawQuery)
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		keyframe := `{"type": "metrics_update", "seq": 1, "data": {"gpu_metrics": {"gpu-0": {"gpu_id": "gpu-0", "utilization_gpu": 50, "temperature": 60}, "gpu-1": {"gpu_id": "gpu-1"}}}}`
+		messages := []string{
+			keyframe,
+			`{"type": "metrics_delta", "seq": 2, "base_seq": 1, "data": {"gpu_metrics": {"gpu-0": {"utilization_gpu": 90}}, "removed_gpus": ["gpu-1"]}}`,
+			`{"type": "alert", "data": {"id": "a-1", "level": "critical"}}`,
+			// Skips seq 3, so the client reconnects for a keyframe
+			`{"type": "metrics_delta", "seq": 4, "base_seq": 3, "data": {"gpu_metrics": {}}}`,
+		}
+		if atomic.AddInt32(&connections, 1) > 1 {
+			messages = []string{keyframe}
+		}
+		for _, message := range messages {
+			conn.WriteMessage(websocket.TextMessage, []byte(message))
+		}
+		conn.ReadMessage()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var updates []MetricsUpdate
+	var alerts []observability.Alert
+	err := client.Stream(ctx, StreamHandlers{
+		Metrics: func(update MetricsUpdate) {
+			updates = append(updates, update)
+			if len(updates) == 3 {
+				cancel()
+			}
+		},
+		Alert: func(alert observability.Alert) { alerts = append(alerts, alert) },
+	})
+	if err != context.Canceled {
+		t.Fatalf("Expected the stream to end with the context, got %v", err)
+	}
+
+	if len(updates) != 3 {
+		t.Fatalf("Expected keyframe, delta and resync keyframe, got %d updates", len(updates))
+	}
+	merged := updates[1]
+	if merged.Seq != 2 || len(merged.GPUs) != 1 || merged.GPUs["gpu-0"].UtilizationGPU != 90 || merged.GPUs["gpu-0"].Temperature != 60 {
+		t.Errorf("Expected the delta merged onto the keyframe, got %+v", merged)
+	}
+	if len(alerts) != 1 || alerts[0].ID != "a-1" {
+		t.Errorf("Expected the alert delivered, got %+v", alerts)
+	}
+	if connections != 2 || updates[2].Seq != 1 {
+		t.Errorf("Expected a reconnect for a keyframe after the gap, got %d connections", connections)
+	}
+}
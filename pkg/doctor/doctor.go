@@ -0,0 +1,399 @@
+// Package doctor runs preflight checks of the environment AgentaFlow runs in
+// and reports the results in a form suitable for humans and CI pipelines
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/airgap"
+)
+
+// Check statuses
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// CheckResult is the outcome of one preflight check
+type CheckResult struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Message    string  `json:"message"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// Report is the outcome of a doctor run. Passed is false if any check failed.
+type Report struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Passed    bool           `json:"passed"`
+	Summary   map[string]int `json:"summary"`
+	Results   []CheckResult  `json:"results"`
+}
+
+// Permission is a Kubernetes verb on a resource the scheduler needs
+type Permission struct {
+	Verb     string `json:"verb"`
+	Resource string `json:"resource"`
+}
+
+// Config controls which checks run and their thresholds
+type Config struct {
+	NvidiaSMIPath     string        // nvidia-smi binary used to query the driver
+	RequireGPU        bool          // Missing GPUs fail instead of warn
+	MinDriverVersion  string        // Oldest supported NVIDIA driver
+	ClockReferenceURL string        // HTTP server whose Date header is the time reference ("" skips)
+	MaxClockSkew      time.Duration // Allowed difference from the reference clock
+	Ports             []int         // Ports that must be free to bind
+	CheckKubernetes   bool          // Verify RBAC permissions with kubectl
+	KubectlPath       string        // kubectl binary used for RBAC checks
+	Namespace         string        // Namespace workloads are scheduled into
+	Permissions       []Permission  // RBAC permissions the scheduler needs
+	StatePath         string        // Snapshot file whose directory must be writable ("" skips)
+	AirGapped         bool          // The site has no route out; endpoints must stay inside AllowedNetworks
+	AllowedNetworks   []string      // CIDR ranges an air-gapped site may reach (empty allows loopback, private and link-local)
+	Endpoints         []string      // URLs AgentaFlow connects to, e.g. tracing collectors and webhooks
+	Timeout           time.Duration // Per-check timeout for external commands and requests
+}
+
+// DefaultConfig returns the checks needed by the dashboard and scheduler
+func DefaultConfig() Config {
+	return Config{
+		NvidiaSMIPath:    "nvidia-smi",
+		RequireGPU:       true,
+		MinDriverVersion: "470.0",
+		MaxClockSkew:     5 * time.Second,
+		Ports:            []int{9000, 8080},
+		KubectlPath:      "kubectl",
+		Namespace:        "agentaflow",
+		Permissions: []Permission{
+			{Verb: "list", Resource: "nodes"},
+			{Verb: "watch", Resource: "nodes"},
+			{Verb: "patch", Resource: "nodes"},
+			{Verb: "list", Resource: "pods"},
+			{Verb: "watch", Resource: "pods"},
+			{Verb: "create", Resource: "pods"},
+			{Verb: "delete", Resource: "pods"},
+			{Verb: "update", Resource: "pods/status"},
+		},
+		Timeout: 10 * time.Second,
+	}
+}
+
+// runCommand executes an external command; replaced in tests
+var runCommand = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).Output()
+}
+
+// check is a single named preflight check
+type check struct {
+	name string
+	run  func(ctx context.Context, config Config) (string, string)
+}
+
+// checks lists the preflight checks in the order they run
+var checks = []check{
+	{"config", checkConfig},
+	{"nvidia_smi", checkNvidiaSMI},
+	{"driver_version", checkDriverVersion},
+	{"clock_skew", checkClockSkew},
+	{"ports", checkPorts},
+	{"kubernetes_rbac", checkRBAC},
+	{"air_gap", checkAirGap},
+}
+
+// Run executes every check and returns the report
+func Run(ctx context.Context, config Config) Report {
+	report := Report{
+		Timestamp: time.Now(),
+		Passed:    true,
+		Summary:   map[string]int{StatusPass: 0, StatusWarn: 0, StatusFail: 0, StatusSkip: 0},
+		Results:   make([]CheckResult, 0, len(checks)),
+	}
+
+	for _, c := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, config.Timeout)
+		start := time.Now()
+		status, message := c.run(checkCtx, config)
+		cancel()
+
+		report.Results = append(report.Results, CheckResult{
+			Name:       c.name,
+			Status:     status,
+			Message:    message,
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+		})
+		report.Summary[status]++
+		if status == StatusFail {
+			report.Passed = false
+		}
+	}
+
+	return report
+}
+
+// checkConfig validates the doctor configuration and the state directory
+func checkConfig(ctx context.Context, config Config) (string, string) {
+	var problems []string
+
+	if config.Timeout <= 0 {
+		problems = append(problems, "timeout must be positive")
+	}
+	if _, err := parseVersion(config.MinDriverVersion); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid minimum driver version: %v", err))
+	}
+	seen := make(map[int]bool)
+	for _, port := range config.Ports {
+		if port <= 0 || port > 65535 {
+			problems = append(problems, fmt.Sprintf("port %d out of range", port))
+		}
+		if seen[port] {
+			problems = append(problems, fmt.Sprintf("port %d listed twice", port))
+		}
+		seen[port] = true
+	}
+
+	if config.StatePath != "" {
+		dir := filepath.Dir(config.StatePath)
+		probe, err := os.CreateTemp(dir, ".agentaflow-doctor-*")
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("state directory %s is not writable: %v", dir, err))
+		} else {
+			probe.Close()
+			os.Remove(probe.Name())
+		}
+	}
+
+	if len(problems) > 0 {
+		return StatusFail, strings.Join(problems, "; ")
+	}
+	return StatusPass, "configuration is valid"
+}
+
+// gpuMissingStatus is the status for missing GPU tooling
+func gpuMissingStatus(config Config) string {
+	if config.RequireGPU {
+		return StatusFail
+	}
+	return StatusWarn
+}
+
+// checkNvidiaSMI verifies nvidia-smi (and so NVML) can enumerate GPUs
+func checkNvidiaSMI(ctx context.Context, config Config) (string, string) {
+	output, err := runCommand(ctx, config.NvidiaSMIPath, "--query-gpu=name", "--format=csv,noheader")
+	if err != nil {
+		return gpuMissingStatus(config), fmt.Sprintf("nvidia-smi unavailable: %v", err)
+	}
+
+	names := nonEmptyLines(output)
+	if len(names) == 0 {
+		return gpuMissingStatus(config), "nvidia-smi found no GPUs"
+	}
+	return StatusPass, fmt.Sprintf("%d GPU(s): %s", len(names), strings.Join(names, ", "))
+}
+
+// checkDriverVersion verifies the NVIDIA driver meets the minimum version
+func checkDriverVersion(ctx context.Context, config Config) (string, string) {
+	output, err := runCommand(ctx, config.NvidiaSMIPath, "--query-gpu=driver_version", "--format=csv,noheader")
+	if err != nil {
+		return StatusSkip, "driver version unavailable without nvidia-smi"
+	}
+
+	lines := nonEmptyLines(output)
+	if len(lines) == 0 {
+		return StatusSkip, "no GPUs reported a driver version"
+	}
+
+	installed, err := parseVersion(lines[0])
+	if err != nil {
+		return StatusFail, fmt.Sprintf("unrecognized driver version %q", lines[0])
+	}
+	minimum, err := parseVersion(config.MinDriverVersion)
+	if err != nil {
+		return StatusSkip, "minimum driver version is invalid"
+	}
+
+	if compareVersions(installed, minimum) < 0 {
+		return StatusFail, fmt.Sprintf("driver %s is older than the required %s", lines[0], config.MinDriverVersion)
+	}
+	return StatusPass, fmt.Sprintf("driver %s", lines[0])
+}
+
+// checkClockSkew compares the local clock with a reference server's Date header
+func checkClockSkew(ctx context.Context, config Config) (string, string) {
+	if config.ClockReferenceURL == "" {
+		return StatusSkip, "no clock reference configured"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, config.ClockReferenceURL, nil)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("invalid clock reference: %v", err)
+	}
+
+	sent := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return StatusWarn, fmt.Sprintf("clock reference unreachable: %v", err)
+	}
+	resp.Body.Close()
+	received := time.Now()
+
+	reference, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return StatusWarn, "clock reference returned no Date header"
+	}
+
+	// Compare against the midpoint of the request to cancel out latency; the
+	// Date header only has second resolution
+	local := sent.Add(received.Sub(sent) / 2)
+	skew := local.Sub(reference)
+	if skew < 0 {
+		skew = -skew
+	}
+
+	if skew > config.MaxClockSkew {
+		return StatusFail, fmt.Sprintf("clock is %s off the reference (max %s)", skew.Round(time.Millisecond), config.MaxClockSkew)
+	}
+	return StatusPass, fmt.Sprintf("clock within %s of the reference", skew.Round(time.Millisecond))
+}
+
+// checkPorts verifies the configured ports can be bound
+func checkPorts(ctx context.Context, config Config) (string, string) {
+	if len(config.Ports) == 0 {
+		return StatusSkip, "no ports configured"
+	}
+
+	var busy []string
+	for _, port := range config.Ports {
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+		if err != nil {
+			busy = append(busy, strconv.Itoa(port))
+			continue
+		}
+		listener.Close()
+	}
+
+	if len(busy) > 0 {
+		return StatusFail, fmt.Sprintf("ports in use: %s", strings.Join(busy, ", "))
+	}
+	return StatusPass, fmt.Sprintf("%d port(s) available", len(config.Ports))
+}
+
+// checkRBAC verifies the current Kubernetes identity has the scheduler's permissions
+func checkRBAC(ctx context.Context, config Config) (string, string) {
+	if !config.CheckKubernetes {
+		return StatusSkip, "Kubernetes checks disabled"
+	}
+
+	var denied []string
+	for _, permission := range config.Permissions {
+		args := []string{"auth", "can-i", permission.Verb, permission.Resource}
+		if permission.Resource != "nodes" && config.Namespace != "" {
+			args = append(args, "--namespace", config.Namespace)
+		}
+
+		// can-i exits non-zero when denied, so judge by the answer it prints
+		output, err := runCommand(ctx, config.KubectlPath, args...)
+		answer := strings.TrimSpace(string(output))
+		if answer == "yes" {
+			continue
+		}
+		if answer == "" && err != nil {
+			return StatusFail, fmt.Sprintf("kubectl auth can-i failed: %v", err)
+		}
+		denied = append(denied, permission.Verb+" "+permission.Resource)
+	}
+
+	if len(denied) > 0 {
+		return StatusFail, fmt.Sprintf("missing permissions: %s", strings.Join(denied, ", "))
+	}
+	return StatusPass, fmt.Sprintf("%d permission(s) granted", len(config.Permissions))
+}
+
+// checkAirGap verifies every endpoint AgentaFlow would connect to, and the
+// clock reference, stays inside the allowed networks
+func checkAirGap(ctx context.Context, config Config) (string, string) {
+	if !config.AirGapped {
+		return StatusSkip, "not air-gapped"
+	}
+	guard, err := airgap.NewGuard(config.AllowedNetworks)
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+
+	endpoints := config.Endpoints
+	if config.ClockReferenceURL != "" {
+		endpoints = append([]string{config.ClockReferenceURL}, endpoints...)
+	}
+	var outbound []string
+	for _, endpoint := range endpoints {
+		if err := guard.CheckURL(ctx, endpoint); err != nil {
+			outbound = append(outbound, err.Error())
+		}
+	}
+
+	if len(outbound) > 0 {
+		return StatusFail, fmt.Sprintf("outbound endpoints: %s", strings.Join(outbound, "; "))
+	}
+	return StatusPass, fmt.Sprintf("%d endpoint(s) inside the allowed networks", len(endpoints))
+}
+
+// parseVersion splits a dotted version into its numeric components
+func parseVersion(version string) ([]int, error) {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return nil, fmt.Errorf("empty version")
+	}
+
+	parts := strings.Split(version, ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version component %q", part)
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+// compareVersions returns -1, 0 or 1 as a is older than, equal to or newer than b
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// nonEmptyLines returns the trimmed, non-empty lines of command output
+func nonEmptyLines(output []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
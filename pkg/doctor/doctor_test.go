@@ -0,0 +1,188 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubCommands replaces runCommand with canned output keyed by the joined arguments
+func stubCommands(t *testing.T, outputs map[string]string) {
+	original := runCommand
+	runCommand = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		output, ok := outputs[name+" "+strings.Join(args, " ")]
+		if !ok {
+			return nil, errors.New("executable file not found")
+		}
+		if output == "no" {
+			return []byte("no\n"), errors.New("exit status 1")
+		}
+		return []byte(output), nil
+	}
+	t.Cleanup(func() { runCommand = original })
+}
+
+// resultFor finds a check's result in a report
+func resultFor(t *testing.T, report Report, name string) CheckResult {
+	t.Helper()
+	for _, result := range report.Results {
+		if result.Name == name {
+			return result
+		}
+	}
+	t.Fatalf("No result for check %s", name)
+	return CheckResult{}
+}
+
+func TestDoctorPassesHealthyEnvironment(t *testing.T) {
+	stubCommands(t, map[string]string{
+		"nvidia-smi --query-gpu=name --format=csv,noheader":           "NVIDIA A100\nNVIDIA A100\n",
+		"nvidia-smi --query-gpu=driver_version --format=csv,noheader": "535.104.05\n535.104.05\n",
+		"kubectl auth can-i list nodes":                               "yes\n",
+		"kubectl auth can-i create pods --namespace agentaflow":       "yes\n",
+	})
+
+	config := DefaultConfig()
+	config.Ports = nil
+	config.CheckKubernetes = true
+	config.Permissions = []Permission{{Verb: "list", Resource: "nodes"}, {Verb: "create", Resource: "pods"}}
+	config.StatePath = t.TempDir() + "/state.json"
+
+	report := Run(context.Background(), config)
+	if !report.Passed {
+		t.Fatalf("Expected report to pass, got %+v", report.Results)
+	}
+	if result := resultFor(t, report, "nvidia_smi"); !strings.Contains(result.Message, "2 GPU(s)") {
+		t.Errorf("Expected GPUs listed, got %q", result.Message)
+	}
+	if report.Summary[StatusSkip] != 3 {
+		t.Errorf("Expected clock, port and air gap checks skipped, got %v", report.Summary)
+	}
+}
+
+func TestDoctorReportsFailures(t *testing.T) {
+	stubCommands(t, map[string]string{
+		"nvidia-smi --query-gpu=name --format=csv,noheader":           "Tesla T4\n",
+		"nvidia-smi --query-gpu=driver_version --format=csv,noheader": "450.80.02\n",
+		"kubectl auth can-i list nodes":                               "yes\n",
+		"kubectl auth can-i delete pods --namespace agentaflow":       "no",
+	})
+
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	config := DefaultConfig()
+	config.Ports = []int{listener.Addr().(*net.TCPAddr).Port}
+	config.CheckKubernetes = true
+	config.Permissions = []Permission{{Verb: "list", Resource: "nodes"}, {Verb: "delete", Resource: "pods"}}
+
+	report := Run(context.Background(), config)
+	if report.Passed {
+		t.Fatal("Expected report to fail")
+	}
+	for _, name := range []string{"driver_version", "ports", "kubernetes_rbac"} {
+		if result := resultFor(t, report, name); result.Status != StatusFail {
+			t.Errorf("Expected %s to fail, got %+v", name, result)
+		}
+	}
+	if message := resultFor(t, report, "kubernetes_rbac").Message; message != "missing permissions: delete pods" {
+		t.Errorf("Expected denied permission listed, got %q", message)
+	}
+}
+
+func TestDoctorWithoutGPUs(t *testing.T) {
+	stubCommands(t, map[string]string{})
+
+	config := DefaultConfig()
+	config.Ports = nil
+	config.RequireGPU = false
+
+	report := Run(context.Background(), config)
+	if !report.Passed {
+		t.Errorf("Expected missing GPUs to only warn when not required, got %+v", report.Results)
+	}
+	if result := resultFor(t, report, "nvidia_smi"); result.Status != StatusWarn {
+		t.Errorf("Expected warning, got %+v", result)
+	}
+
+	config.RequireGPU = true
+	if Run(context.Background(), config).Passed {
+		t.Error("Expected missing GPUs to fail when required")
+	}
+}
+
+func TestDoctorClockSkewAndConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.ClockReferenceURL = server.URL
+	status, message := checkClockSkew(context.Background(), config)
+	if status != StatusFail {
+		t.Errorf("Expected a minute of skew to fail, got %s: %s", status, message)
+	}
+
+	config.MaxClockSkew = 2 * time.Minute
+	if status, _ := checkClockSkew(context.Background(), config); status != StatusPass {
+		t.Errorf("Expected skew within the limit to pass, got %s", status)
+	}
+
+	config.Ports = []int{9000, 9000, 70000}
+	config.MinDriverVersion = "latest"
+	config.StatePath = "/nonexistent/dir/state.json"
+	status, message = checkConfig(context.Background(), config)
+	if status != StatusFail || !strings.Contains(message, "listed twice") || !strings.Contains(message, "out of range") ||
+		!strings.Contains(message, "driver version") || !strings.Contains(message, "not writable") {
+		t.Errorf("Expected every config problem reported, got %s: %s", status, message)
+	}
+}
+
+func TestDoctorAirGap(t *testing.T) {
+	config := DefaultConfig()
+	config.AirGapped = true
+	config.ClockReferenceURL = "http://127.0.0.1:8080"
+	config.Endpoints = []string{"http://10.0.4.2:4318/v1/traces", "https://203.0.113.7/hooks/capacity"}
+
+	status, message := checkAirGap(context.Background(), config)
+	if status != StatusFail || !strings.Contains(message, "203.0.113.7") || strings.Contains(message, "10.0.4.2") {
+		t.Errorf("Expected only the public endpoint reported, got %s: %s", status, message)
+	}
+
+	config.AllowedNetworks = []string{"127.0.0.0/8", "10.0.0.0/8", "203.0.113.0/24"}
+	if status, message := checkAirGap(context.Background(), config); status != StatusPass {
+		t.Errorf("Expected endpoints inside the allowed networks to pass, got %s: %s", status, message)
+	}
+
+	config.AllowedNetworks = []string{"10.0.0.0"}
+	if status, _ := checkAirGap(context.Background(), config); status != StatusFail {
+		t.Errorf("Expected an invalid network to fail, got %s", status)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"535.104.05", "470.0", 1},
+		{"470", "470.0", 0},
+		{"470.57.02", "470.82", -1},
+	}
+	for _, c := range cases {
+		a, _ := parseVersion(c.a)
+		b, _ := parseVersion(c.b)
+		if got := compareVersions(a, b); got != c.want {
+			t.Errorf("compareVersions(%s, %s) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
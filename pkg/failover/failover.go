@@ -0,0 +1,303 @@
+// Package failover elects which of an active and a warm standby AgentaFlow
+// server is primary. The primary holds a lease on shared storage and renews
+// it; a standby takes the lease over once it stops changing. Every takeover
+// raises the lease's epoch, a fencing token the servers attach to their
+// responses so clients can tell a deposed primary from the current one.
+package failover
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Lease records which server is primary
+type Lease struct {
+	Holder   string `json:"holder"`  // Node ID of the primary; empty before the first election
+	Address  string `json:"address"` // Where clients and the standby reach the primary
+	Epoch    uint64 `json:"epoch"`   // Raised by every takeover
+	Renewals uint64 `json:"renewals"`
+}
+
+// LeaseStore keeps the lease where every server can reach it
+type LeaseStore interface {
+	// Load returns the current lease, or a zero lease when none was taken
+	Load() (Lease, error)
+
+	// Swap replaces the lease with next if it still equals current, and
+	// reports whether it did
+	Swap(current, next Lease) (bool, error)
+}
+
+// Server roles
+const (
+	RoleStandby = "standby" // Replicates the primary and waits for its lease to lapse
+	RolePrimary = "primary"
+	RoleFenced  = "fenced" // Lost the lease; stays out of service until restarted
+)
+
+// Config configures an elector
+type Config struct {
+	NodeID  string `yaml:"node_id" json:"node_id"` // Unique per server; the hostname when empty
+	Address string `yaml:"address" json:"address"` // URL clients and the standby reach this server at
+
+	// A standby takes over once the lease has not been renewed for
+	// LeaseDuration. A primary that cannot renew for LeaseDuration minus
+	// RenewInterval fences itself first, so two primaries never overlap.
+	LeaseDuration time.Duration `yaml:"lease_duration" json:"lease_duration"`
+	RenewInterval time.Duration `yaml:"renew_interval" json:"renew_interval"`
+}
+
+// DefaultConfig returns a 15 second lease renewed every 3 seconds
+func DefaultConfig() Config {
+	return Config{
+		LeaseDuration: 15 * time.Second,
+		RenewInterval: 3 * time.Second,
+	}
+}
+
+// Status describes an elector's view of the election
+type Status struct {
+	NodeID    string    `json:"node_id"`
+	Role      string    `json:"role"`
+	Epoch     uint64    `json:"epoch"`             // Of the lease this server last saw
+	Primary   string    `json:"primary,omitempty"` // Address of the current primary
+	Holder    string    `json:"holder,omitempty"`
+	Since     time.Time `json:"since"` // When the role was taken
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Elector runs the election for one server
+type Elector struct {
+	config Config
+	store  LeaseStore
+
+	role       string
+	since      time.Time
+	lease      Lease     // Last lease seen
+	seenAt     time.Time // When the last lease seen changed
+	renewedAt  time.Time // Last successful renewal while primary
+	lastErr    error
+	onPromote  []func(epoch uint64)
+	onFence    []func(epoch uint64, reason string)
+	stopCh     chan struct{}
+	doneCh     chan struct{}
+	mu         sync.RWMutex
+	callbackMu sync.Mutex // Serializes role change callbacks
+}
+
+// NewElector creates an elector that starts as standby
+func NewElector(config Config, store LeaseStore) (*Elector, error) {
+	if store == nil {
+		return nil, fmt.Errorf("lease store is required")
+	}
+	defaults := DefaultConfig()
+	if config.NodeID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("node ID is required: %w", err)
+		}
+		config.NodeID = hostname
+	}
+	if config.LeaseDuration <= 0 {
+		config.LeaseDuration = defaults.LeaseDuration
+	}
+	if config.RenewInterval <= 0 {
+		config.RenewInterval = defaults.RenewInterval
+	}
+	if config.RenewInterval*2 > config.LeaseDuration {
+		return nil, fmt.Errorf("renew interval %s must be at most half the lease duration %s", config.RenewInterval, config.LeaseDuration)
+	}
+	return &Elector{config: config, store: store, role: RoleStandby, since: time.Now()}, nil
+}
+
+// OnPromote registers a handler called when this server becomes primary,
+// e.g. to recover replicated state and start serving
+func (e *Elector) OnPromote(handler func(epoch uint64)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onPromote = append(e.onPromote, handler)
+}
+
+// OnFence registers a handler called when this server loses the lease while
+// primary, e.g. to stop writing state
+func (e *Elector) OnFence(handler func(epoch uint64, reason string)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onFence = append(e.onFence, handler)
+}
+
+// Tick runs one election step at now: a primary renews its lease, and a
+// standby takes over a lease that lapsed or that it held before a restart
+func (e *Elector) Tick(now time.Time) error {
+	e.callbackMu.Lock()
+	defer e.callbackMu.Unlock()
+
+	e.mu.RLock()
+	role, epoch := e.role, e.lease.Epoch
+	e.mu.RUnlock()
+
+	switch role {
+	case RolePrimary:
+		return e.renew(now, epoch)
+	case RoleStandby:
+		return e.watch(now)
+	}
+	return nil
+}
+
+// renew extends the lease, fencing this server if it was taken or cannot be
+// renewed in time
+func (e *Elector) renew(now time.Time, epoch uint64) error {
+	lease, err := e.store.Load()
+	if err == nil && (lease.Holder != e.config.NodeID || lease.Epoch != epoch) {
+		e.fence(lease, fmt.Sprintf("lease taken by %s at epoch %d", lease.Holder, lease.Epoch))
+		return nil
+	}
+	if err == nil {
+		next := lease
+		next.Renewals++
+		var swapped bool
+		if swapped, err = e.store.Swap(lease, next); err == nil && !swapped {
+			err = fmt.Errorf("lease changed during renewal")
+		}
+		if err == nil {
+			e.mu.Lock()
+			e.lease, e.seenAt, e.renewedAt, e.lastErr = next, now, now, nil
+			e.mu.Unlock()
+			return nil
+		}
+	}
+
+	e.mu.Lock()
+	e.lastErr = err
+	renewedAt, current := e.renewedAt, e.lease
+	e.mu.Unlock()
+	if now.Sub(renewedAt) >= e.config.LeaseDuration-e.config.RenewInterval {
+		e.fence(current, fmt.Sprintf("lease not renewed since %s: %v", renewedAt.Format(time.RFC3339), err))
+	}
+	return err
+}
+
+// watch tracks the primary's lease and takes it over once it lapses
+func (e *Elector) watch(now time.Time) error {
+	lease, err := e.store.Load()
+	e.mu.Lock()
+	e.lastErr = err
+	if err != nil {
+		e.mu.Unlock()
+		return err
+	}
+	if lease != e.lease || e.seenAt.IsZero() {
+		e.lease, e.seenAt = lease, now
+	}
+	seenAt := e.seenAt
+	e.mu.Unlock()
+
+	// The lease is taken over when unheld, held by this server before a
+	// restart, or left unrenewed for a whole lease duration
+	if lease.Holder != "" && lease.Holder != e.config.NodeID && now.Sub(seenAt) < e.config.LeaseDuration {
+		return nil
+	}
+	next := Lease{Holder: e.config.NodeID, Address: e.config.Address, Epoch: lease.Epoch + 1}
+	swapped, err := e.store.Swap(lease, next)
+	if err != nil || !swapped {
+		e.mu.Lock()
+		e.lastErr = err
+		e.mu.Unlock()
+		return err
+	}
+
+	e.mu.Lock()
+	e.role, e.since = RolePrimary, now
+	e.lease, e.seenAt, e.renewedAt = next, now, now
+	handlers := e.onPromote
+	e.mu.Unlock()
+
+	log.Printf("failover: %s promoted to primary at epoch %d (previous holder %q)", e.config.NodeID, next.Epoch, lease.Holder)
+	for _, handler := range handlers {
+		handler(next.Epoch)
+	}
+	return nil
+}
+
+// fence takes this server out of service after it lost the lease
+func (e *Elector) fence(lease Lease, reason string) {
+	e.mu.Lock()
+	e.role, e.since = RoleFenced, time.Now()
+	e.lease = lease
+	handlers := e.onFence
+	e.mu.Unlock()
+
+	log.Printf("failover: %s fenced: %s", e.config.NodeID, reason)
+	for _, handler := range handlers {
+		handler(lease.Epoch, reason)
+	}
+}
+
+// Status returns this server's role and the lease it last saw
+func (e *Elector) Status() Status {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	status := Status{
+		NodeID:  e.config.NodeID,
+		Role:    e.role,
+		Epoch:   e.lease.Epoch,
+		Primary: e.lease.Address,
+		Holder:  e.lease.Holder,
+		Since:   e.since,
+	}
+	if e.lastErr != nil {
+		status.LastError = e.lastErr.Error()
+	}
+	return status
+}
+
+// Start runs election steps every renew interval
+func (e *Elector) Start() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.stopCh != nil {
+		return
+	}
+	e.stopCh = make(chan struct{})
+	e.doneCh = make(chan struct{})
+	go e.run(e.stopCh, e.doneCh)
+}
+
+// Stop halts the election steps; a primary keeps its lease until it lapses
+func (e *Elector) Stop() {
+	e.mu.Lock()
+	stopCh, doneCh := e.stopCh, e.doneCh
+	e.stopCh, e.doneCh = nil, nil
+	e.mu.Unlock()
+
+	if stopCh == nil {
+		return
+	}
+	close(stopCh)
+	<-doneCh
+}
+
+// run steps the election until stopped
+func (e *Elector) run(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(e.config.RenewInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.Tick(time.Now()); err != nil {
+			log.Printf("failover: election step failed: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-stopCh:
+			return
+		}
+	}
+}
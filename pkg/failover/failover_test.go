@@ -0,0 +1,127 @@
+package failover
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// flakyStore fails every call while down
+type flakyStore struct {
+	LeaseStore
+	down bool
+}
+
+func (s *flakyStore) Load() (Lease, error) {
+	if s.down {
+		return Lease{}, errors.New("storage unreachable")
+	}
+	return s.LeaseStore.Load()
+}
+
+func (s *flakyStore) Swap(current, next Lease) (bool, error) {
+	if s.down {
+		return false, errors.New("storage unreachable")
+	}
+	return s.LeaseStore.Swap(current, next)
+}
+
+func newTestElector(t *testing.T, id string, store LeaseStore) *Elector {
+	elector, err := NewElector(Config{
+		NodeID:        id,
+		Address:       "http://" + id + ":8080",
+		LeaseDuration: 10 * time.Second,
+		RenewInterval: 2 * time.Second,
+	}, store)
+	if err != nil {
+		t.Fatalf("Failed to create elector: %v", err)
+	}
+	return elector
+}
+
+func TestElectorFailover(t *testing.T) {
+	store := NewFileLeaseStore(filepath.Join(t.TempDir(), "lease.json"))
+	active := newTestElector(t, "active", store)
+	standby := newTestElector(t, "standby", store)
+
+	var promoted, fenced []uint64
+	standby.OnPromote(func(epoch uint64) { promoted = append(promoted, epoch) })
+	active.OnFence(func(epoch uint64, reason string) { fenced = append(fenced, epoch) })
+
+	start := time.Now()
+	active.Tick(start)
+	if status := active.Status(); status.Role != RolePrimary || status.Epoch != 1 {
+		t.Fatalf("Expected the first server to take an unheld lease, got %+v", status)
+	}
+
+	// The standby waits while the primary renews
+	for i := 0; i < 10; i++ {
+		now := start.Add(time.Duration(i) * 2 * time.Second)
+		active.Tick(now)
+		standby.Tick(now)
+	}
+	if status := standby.Status(); status.Role != RoleStandby || status.Primary != "http://active:8080" {
+		t.Fatalf("Expected the standby to follow the primary, got %+v", status)
+	}
+
+	// The primary stops renewing; the standby takes over a lease duration later
+	stalled := start.Add(18 * time.Second)
+	standby.Tick(stalled.Add(9 * time.Second))
+	if standby.Status().Role != RoleStandby {
+		t.Fatal("Expected no takeover before the lease lapses")
+	}
+	standby.Tick(stalled.Add(10 * time.Second))
+	if status := standby.Status(); status.Role != RolePrimary || status.Epoch != 2 {
+		t.Fatalf("Expected the standby promoted at epoch 2, got %+v", status)
+	}
+	if len(promoted) != 1 || promoted[0] != 2 {
+		t.Errorf("Expected one promotion at epoch 2, got %v", promoted)
+	}
+
+	// The old primary finds the lease taken and fences itself
+	active.Tick(stalled.Add(11 * time.Second))
+	if status := active.Status(); status.Role != RoleFenced || status.Primary != "http://standby:8080" {
+		t.Errorf("Expected the old primary fenced and pointing at the new one, got %+v", status)
+	}
+	if len(fenced) != 1 || fenced[0] != 2 {
+		t.Errorf("Expected one fence at epoch 2, got %v", fenced)
+	}
+}
+
+func TestElectorFencesWhenRenewalFails(t *testing.T) {
+	store := &flakyStore{LeaseStore: NewFileLeaseStore(filepath.Join(t.TempDir(), "lease.json"))}
+	elector := newTestElector(t, "active", store)
+
+	start := time.Now()
+	elector.Tick(start)
+	store.down = true
+
+	if err := elector.Tick(start.Add(4 * time.Second)); err == nil {
+		t.Error("Expected the renewal error")
+	}
+	if elector.Status().Role != RolePrimary {
+		t.Error("Expected the primary to ride out a short outage")
+	}
+
+	// Fencing happens a renew interval before a standby could take over
+	elector.Tick(start.Add(8 * time.Second))
+	if status := elector.Status(); status.Role != RoleFenced {
+		t.Errorf("Expected the primary fenced once its lease may lapse, got %+v", status)
+	}
+}
+
+func TestElectorReclaimsOwnLease(t *testing.T) {
+	store := NewFileLeaseStore(filepath.Join(t.TempDir(), "lease.json"))
+	store.Swap(Lease{}, Lease{Holder: "active", Epoch: 4})
+
+	elector := newTestElector(t, "active", store)
+	elector.Tick(time.Now())
+	if status := elector.Status(); status.Role != RolePrimary || status.Epoch != 5 {
+		t.Errorf("Expected a restarted primary to reclaim its lease at a new epoch, got %+v", status)
+	}
+
+	if _, err := NewElector(Config{NodeID: "x", LeaseDuration: time.Second, RenewInterval: time.Second}, store); err == nil {
+		t.Error("Expected a renew interval over half the lease to be rejected")
+	}
+}
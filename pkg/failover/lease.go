@@ -0,0 +1,102 @@
+package failover
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileLeaseStore keeps the lease in a JSON file on storage both servers
+// mount, e.g. an NFS share or a shared volume. A lock file beside it makes
+// Swap atomic between the servers.
+type FileLeaseStore struct {
+	Path string
+
+	// StaleLock is how old a lock file must be before it is assumed left by
+	// a crashed server and removed; 10 seconds when zero
+	StaleLock time.Duration
+}
+
+// NewFileLeaseStore creates a lease store at path
+func NewFileLeaseStore(path string) *FileLeaseStore {
+	return &FileLeaseStore{Path: path}
+}
+
+// Load reads the lease, returning a zero lease when the file does not exist
+func (s *FileLeaseStore) Load() (Lease, error) {
+	var lease Lease
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return lease, nil
+	}
+	if err != nil {
+		return lease, fmt.Errorf("failed to read lease: %w", err)
+	}
+	if err := json.Unmarshal(data, &lease); err != nil {
+		return lease, fmt.Errorf("failed to decode lease %s: %w", s.Path, err)
+	}
+	return lease, nil
+}
+
+// Swap writes next if the lease on disk still equals current
+func (s *FileLeaseStore) Swap(current, next Lease) (bool, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	lease, err := s.Load()
+	if err != nil {
+		return false, err
+	}
+	if lease != current {
+		return false, nil
+	}
+
+	data, err := json.Marshal(next)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode lease: %w", err)
+	}
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return false, fmt.Errorf("failed to write lease: %w", err)
+	}
+	if err := os.Rename(tmp, s.Path); err != nil {
+		os.Remove(tmp)
+		return false, fmt.Errorf("failed to replace lease: %w", err)
+	}
+	return true, nil
+}
+
+// lock creates the lock file, removing one a crashed server left behind
+func (s *FileLeaseStore) lock() (func(), error) {
+	stale := s.StaleLock
+	if stale <= 0 {
+		stale = 10 * time.Second
+	}
+	path := s.Path + ".lock"
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lease directory: %w", err)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock lease: %w", err)
+		}
+		info, statErr := os.Stat(path)
+		if statErr != nil || time.Since(info.ModTime()) < stale {
+			break
+		}
+		os.Remove(path)
+	}
+	return nil, fmt.Errorf("lease %s is locked by another server", s.Path)
+}
@@ -0,0 +1,49 @@
+package failover
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileLeaseStoreSwap(t *testing.T) {
+	store := NewFileLeaseStore(filepath.Join(t.TempDir(), "lease.json"))
+
+	lease, err := store.Load()
+	if err != nil || lease != (Lease{}) {
+		t.Fatalf("Expected a zero lease before the first swap, got %+v, %v", lease, err)
+	}
+
+	first := Lease{Holder: "a", Address: "http://a:8080", Epoch: 1}
+	if swapped, err := store.Swap(Lease{}, first); !swapped || err != nil {
+		t.Fatalf("Expected the first swap to succeed, got %v, %v", swapped, err)
+	}
+	if swapped, err := store.Swap(Lease{}, Lease{Holder: "b", Epoch: 1}); swapped || err != nil {
+		t.Errorf("Expected a swap from a stale lease to fail, got %v, %v", swapped, err)
+	}
+	if lease, _ := store.Load(); lease != first {
+		t.Errorf("Expected %+v, got %+v", first, lease)
+	}
+}
+
+func TestFileLeaseStoreLock(t *testing.T) {
+	store := NewFileLeaseStore(filepath.Join(t.TempDir(), "lease.json"))
+	lockPath := store.Path + ".lock"
+	if err := os.WriteFile(lockPath, nil, 0644); err != nil {
+		t.Fatalf("Failed to create lock: %v", err)
+	}
+
+	if _, err := store.Swap(Lease{}, Lease{Holder: "a", Epoch: 1}); err == nil {
+		t.Error("Expected a held lock to block the swap")
+	}
+
+	old := time.Now().Add(-time.Minute)
+	os.Chtimes(lockPath, old, old)
+	if swapped, err := store.Swap(Lease{}, Lease{Holder: "a", Epoch: 1}); !swapped || err != nil {
+		t.Errorf("Expected a stale lock to be removed, got %v, %v", swapped, err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Error("Expected the lock to be released")
+	}
+}
@@ -0,0 +1,527 @@
+// Package gitops reconciles the live AgentaFlow configuration with
+// declarative config kept in a Git repository or a Kubernetes ConfigMap.
+// The controller reads alert rules, budgets, pools and workload templates
+// from YAML or JSON files, compares them with what the dashboard reports,
+// and applies the differences through the resources API.
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/secrets"
+)
+
+// Resource kinds a desired state holds
+const (
+	KindAlertRule = "alert_rule"
+	KindBudget    = "budget"
+	KindPool      = "pool"
+	KindTemplate  = "template"
+)
+
+// DesiredState is the declarative config of a source. Every file of a source
+// holds part of it and resource names must be unique across files.
+type DesiredState struct {
+	AlertRules []observability.GPUThresholdProfile `yaml:"alert_rules" json:"alert_rules,omitempty"`
+	Budgets    []observability.Budget              `yaml:"budgets" json:"budgets,omitempty"`
+	Pools      []observability.PoolResource        `yaml:"pools" json:"pools,omitempty"`
+	Templates  []observability.WorkloadTemplate    `yaml:"templates" json:"templates,omitempty"`
+}
+
+// Validate checks each resource has a name and valid settings
+func (s DesiredState) Validate() error {
+	var errs observability.ConfigErrors
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, observability.ConfigError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for i, rule := range s.AlertRules {
+		field := fmt.Sprintf("alert_rules[%d]", i)
+		if rule.Name == "" {
+			add(field+".name", "must not be empty")
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			add(field+".pattern", "invalid regular expression: %v", err)
+		}
+	}
+	for i, budget := range s.Budgets {
+		if err := budget.Validate(); err != nil {
+			add(fmt.Sprintf("budgets[%d]", i), "%v", strings.ReplaceAll(err.Error(), "\n", "; "))
+		}
+	}
+	pools := make(map[string]gpu.PoolConfig, len(s.Pools))
+	for i, pool := range s.Pools {
+		if pool.Name == "" {
+			add(fmt.Sprintf("pools[%d].name", i), "must not be empty")
+		}
+		pools[pool.Name] = pool.PoolConfig
+	}
+	if err := (gpu.SchedulingPolicy{Pools: pools}).Validate(); err != nil {
+		add("pools", "%v", err)
+	}
+	for i, template := range s.Templates {
+		field := fmt.Sprintf("templates[%d]", i)
+		if template.Name == "" {
+			add(field+".name", "must not be empty")
+		}
+		if template.MemoryMB == 0 {
+			add(field+".memory_mb", "must be greater than 0")
+		}
+		if _, err := gpu.ParseCronSchedule(template.Recurrence.Schedule); err != nil {
+			add(field+".recurrence.schedule", "%v", err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// ParseDesiredState decodes and merges the YAML and JSON files of a source,
+// keyed by path. Files with other extensions are ignored.
+func ParseDesiredState(files map[string][]byte) (DesiredState, error) {
+	return ParseDesiredStateWithSecrets(context.Background(), files, nil)
+}
+
+// ParseDesiredStateWithSecrets parses a source like ParseDesiredState and
+// resolves the secret references of each file with resolver
+func ParseDesiredStateWithSecrets(ctx context.Context, files map[string][]byte, resolver *secrets.Resolver) (DesiredState, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var state DesiredState
+	defined := make(map[string]string) // kind/name to the file defining it
+	for _, name := range names {
+		var format string
+		switch strings.ToLower(path.Ext(name)) {
+		case ".yaml", ".yml":
+			format = observability.ConfigFormatYAML
+		case ".json":
+			format = observability.ConfigFormatJSON
+		default:
+			continue
+		}
+
+		var part DesiredState
+		if err := observability.DecodeConfigWithSecrets(ctx, files[name], format, name, &part, resolver); err != nil {
+			return DesiredState{}, err
+		}
+		for _, resource := range part.resources() {
+			key := resource.Kind + "/" + resource.Name
+			if first, exists := defined[key]; exists {
+				return DesiredState{}, fmt.Errorf("%s: %s %s is already defined in %s", name, resource.Kind, resource.Name, first)
+			}
+			defined[key] = name
+		}
+		state.AlertRules = append(state.AlertRules, part.AlertRules...)
+		state.Budgets = append(state.Budgets, part.Budgets...)
+		state.Pools = append(state.Pools, part.Pools...)
+		state.Templates = append(state.Templates, part.Templates...)
+	}
+	return state, nil
+}
+
+// resourceRef names a resource of a desired state
+type resourceRef struct {
+	Kind string
+	Name string
+}
+
+// resources lists every resource the state defines
+func (s DesiredState) resources() []resourceRef {
+	var refs []resourceRef
+	for _, rule := range s.AlertRules {
+		refs = append(refs, resourceRef{KindAlertRule, rule.Name})
+	}
+	for _, budget := range s.Budgets {
+		refs = append(refs, resourceRef{KindBudget, budget.Name})
+	}
+	for _, pool := range s.Pools {
+		refs = append(refs, resourceRef{KindPool, pool.Name})
+	}
+	for _, template := range s.Templates {
+		refs = append(refs, resourceRef{KindTemplate, template.Name})
+	}
+	return refs
+}
+
+// Live reads and changes the running configuration. *client.Client
+// implements it over the dashboard's resources API.
+type Live interface {
+	AlertRules(ctx context.Context) ([]observability.GPUThresholdProfile, error)
+	PutAlertRule(ctx context.Context, rule observability.GPUThresholdProfile) (*observability.GPUThresholdProfile, error)
+	DeleteAlertRule(ctx context.Context, name string) error
+
+	Budgets(ctx context.Context) ([]observability.BudgetStatus, error)
+	PutBudget(ctx context.Context, budget observability.Budget) (*observability.BudgetStatus, error)
+	DeleteBudget(ctx context.Context, name string) error
+
+	PoolConfigs(ctx context.Context) ([]observability.PoolResource, error)
+	PutPool(ctx context.Context, pool observability.PoolResource) (*observability.PoolResource, error)
+	DeletePool(ctx context.Context, name string) error
+
+	Templates(ctx context.Context) ([]observability.WorkloadTemplate, error)
+	PutTemplate(ctx context.Context, template observability.WorkloadTemplate) (*observability.WorkloadTemplate, error)
+	DeleteTemplate(ctx context.Context, name string) error
+}
+
+// Change actions
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Change is a difference between the desired and live configuration
+type Change struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Action  string `json:"action"`
+	Applied bool   `json:"applied"`
+	Failed  bool   `json:"failed,omitempty"` // Applying the change returned an error
+	Reason  string `json:"reason,omitempty"` // Why the change was not applied
+}
+
+// Config configures the controller
+type Config struct {
+	Interval time.Duration `yaml:"interval" json:"interval"` // Between syncs; DefaultInterval when zero
+	// Delete live resources the desired state leaves out. Without it they
+	// are reported as drift and left alone.
+	Prune  bool `yaml:"prune" json:"prune"`
+	DryRun bool `yaml:"dry_run" json:"dry_run"` // Report drift without changing anything
+
+	// Resolves secret references in the source's files; optional
+	Secrets *secrets.Resolver `yaml:"-" json:"-"`
+}
+
+// DefaultInterval is how often the controller syncs by default
+const DefaultInterval = time.Minute
+
+// Status reports the controller's last sync
+type Status struct {
+	Source      string     `json:"source"`
+	Revision    string     `json:"revision,omitempty"` // Commit or content digest last read
+	Synced      bool       `json:"synced"`             // The live configuration matched the revision after the sync
+	LastSync    time.Time  `json:"last_sync"`
+	LastSuccess *time.Time `json:"last_success,omitempty"` // Last sync that fetched, parsed and applied without errors
+	Resources   int        `json:"resources"`              // Resources the revision defines
+	Drift       []Change   `json:"drift"`                  // Differences found, with whether each was applied
+	Error       string     `json:"error,omitempty"`
+}
+
+// Controller keeps the live configuration in line with a source
+type Controller struct {
+	source Source
+	live   Live
+	config Config
+	now    func() time.Time
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// NewController creates a controller reconciling live with source
+func NewController(source Source, live Live, config Config) *Controller {
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	return &Controller{
+		source: source,
+		live:   live,
+		config: config,
+		now:    time.Now,
+		status: Status{Source: source.String(), Drift: []Change{}},
+	}
+}
+
+// Run syncs every interval until ctx is done
+func (c *Controller) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+	for {
+		status := c.Sync(ctx)
+		if status.Error != "" {
+			log.Printf("gitops: sync of %s failed: %s", status.Source, status.Error)
+		} else if len(status.Drift) > 0 {
+			log.Printf("gitops: %d changes at revision %s, synced: %v", len(status.Drift), status.Revision, status.Synced)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync fetches the source, compares it with the live configuration and
+// applies the differences, returning the resulting status
+func (c *Controller) Sync(ctx context.Context) Status {
+	c.mu.RLock()
+	status := c.status
+	c.mu.RUnlock()
+	status.LastSync = c.now()
+	status.Drift = []Change{}
+	status.Synced = false
+	status.Error = ""
+
+	snapshot, err := c.source.Fetch(ctx)
+	if err == nil {
+		status.Revision = snapshot.Revision
+		var state DesiredState
+		if state, err = ParseDesiredStateWithSecrets(ctx, snapshot.Files, c.config.Secrets); err == nil {
+			status.Resources = len(state.resources())
+			status.Drift, err = c.reconcile(ctx, state)
+		}
+	}
+
+	if err == nil {
+		failed := 0
+		status.Synced = true
+		for _, change := range status.Drift {
+			if !change.Applied {
+				status.Synced = false
+			}
+			if change.Failed {
+				failed++
+			}
+		}
+		if failed > 0 {
+			err = fmt.Errorf("%d of %d changes failed to apply", failed, len(status.Drift))
+		}
+	}
+	if err != nil {
+		status.Error = err.Error()
+	} else {
+		success := status.LastSync
+		status.LastSuccess = &success
+	}
+
+	c.mu.Lock()
+	c.status = status
+	c.mu.Unlock()
+	return status
+}
+
+// Status returns the result of the last sync
+func (c *Controller) Status() Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	status := c.status
+	status.Drift = append([]Change{}, c.status.Drift...)
+	return status
+}
+
+// Handler serves the status as JSON at /status and answers /healthz with
+// 503 while the last sync failed
+func (c *Controller) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(c.Status())
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if status := c.Status(); status.Error != "" {
+			http.Error(w, status.Error, http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+	return mux
+}
+
+// kindSync reconciles one kind of resource
+type kindSync struct {
+	kind    string
+	desired map[string]interface{}
+	live    map[string]interface{}
+	equal   func(desired, live interface{}) bool
+	put     func(ctx context.Context, desired interface{}) error
+	remove  func(ctx context.Context, name string) error
+}
+
+// reconcile applies the differences between state and the live
+// configuration, kind by kind, and returns them
+func (c *Controller) reconcile(ctx context.Context, state DesiredState) ([]Change, error) {
+	kinds, err := c.kinds(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []Change{}
+	for _, kind := range kinds {
+		for _, name := range sortedNames(kind.desired, kind.live) {
+			desired, wanted := kind.desired[name]
+			live, exists := kind.live[name]
+			change := Change{Kind: kind.kind, Name: name}
+			switch {
+			case wanted && !exists:
+				change.Action = ActionCreate
+			case wanted && !kind.equal(desired, live):
+				change.Action = ActionUpdate
+			case !wanted:
+				change.Action = ActionDelete
+			default:
+				continue
+			}
+
+			var err error
+			switch {
+			case c.config.DryRun:
+				change.Reason = "dry run"
+			case change.Action == ActionDelete && !c.config.Prune:
+				change.Reason = "not in the desired state; pruning is off"
+			case change.Action == ActionDelete:
+				err = kind.remove(ctx, name)
+			default:
+				err = kind.put(ctx, desired)
+			}
+			if err != nil {
+				change.Failed = true
+				change.Reason = err.Error()
+			} else if change.Reason == "" {
+				change.Applied = true
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// kinds reads the live configuration of every kind
+func (c *Controller) kinds(ctx context.Context, state DesiredState) ([]kindSync, error) {
+	rules, err := c.live.AlertRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert rules: %w", err)
+	}
+	budgets, err := c.live.Budgets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budgets: %w", err)
+	}
+	pools, err := c.live.PoolConfigs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pools: %w", err)
+	}
+	templates, err := c.live.Templates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates: %w", err)
+	}
+
+	ruleSync := kindSync{
+		kind: KindAlertRule, desired: map[string]interface{}{}, live: map[string]interface{}{},
+		equal: func(desired, live interface{}) bool {
+			d, l := desired.(observability.GPUThresholdProfile), live.(observability.GPUThresholdProfile)
+			return d.Pattern == l.Pattern && d.Thresholds == l.Thresholds
+		},
+		put: func(ctx context.Context, desired interface{}) error {
+			_, err := c.live.PutAlertRule(ctx, desired.(observability.GPUThresholdProfile))
+			return err
+		},
+		remove: c.live.DeleteAlertRule,
+	}
+	for _, rule := range state.AlertRules {
+		ruleSync.desired[rule.Name] = rule
+	}
+	for _, rule := range rules {
+		ruleSync.live[rule.Name] = rule
+	}
+
+	budgetSync := kindSync{
+		kind: KindBudget, desired: map[string]interface{}{}, live: map[string]interface{}{},
+		equal: func(desired, live interface{}) bool { return desired == live },
+		put: func(ctx context.Context, desired interface{}) error {
+			_, err := c.live.PutBudget(ctx, desired.(observability.Budget))
+			return err
+		},
+		remove: c.live.DeleteBudget,
+	}
+	for _, budget := range state.Budgets {
+		budgetSync.desired[budget.Name] = budget
+	}
+	for _, budget := range budgets {
+		budgetSync.live[budget.Name] = budget.Budget
+	}
+
+	poolSync := kindSync{
+		kind: KindPool, desired: map[string]interface{}{}, live: map[string]interface{}{},
+		equal: func(desired, live interface{}) bool { return desired == live },
+		put: func(ctx context.Context, desired interface{}) error {
+			_, err := c.live.PutPool(ctx, desired.(observability.PoolResource))
+			return err
+		},
+		remove: c.live.DeletePool,
+	}
+	for _, pool := range state.Pools {
+		poolSync.desired[pool.Name] = pool
+	}
+	for _, pool := range pools {
+		poolSync.live[pool.Name] = pool
+	}
+
+	templateSync := kindSync{
+		kind: KindTemplate, desired: map[string]interface{}{}, live: map[string]interface{}{},
+		equal: func(desired, live interface{}) bool {
+			return reflect.DeepEqual(normalizeTemplate(desired.(observability.WorkloadTemplate)), normalizeTemplate(live.(observability.WorkloadTemplate)))
+		},
+		put: func(ctx context.Context, desired interface{}) error {
+			_, err := c.live.PutTemplate(ctx, desired.(observability.WorkloadTemplate))
+			return err
+		},
+		remove: c.live.DeleteTemplate,
+	}
+	for _, template := range state.Templates {
+		templateSync.desired[template.Name] = template
+	}
+	for _, template := range templates {
+		templateSync.live[template.Name] = template
+	}
+
+	return []kindSync{ruleSync, budgetSync, poolSync, templateSync}, nil
+}
+
+// normalizeTemplate applies the defaults the recurrence manager fills in, so
+// a template left at them does not count as drift
+func normalizeTemplate(template observability.WorkloadTemplate) observability.WorkloadTemplate {
+	if template.Recurrence.MaxConcurrency == 0 {
+		template.Recurrence.MaxConcurrency = 1
+	}
+	if template.Recurrence.CatchUp == "" {
+		template.Recurrence.CatchUp = gpu.CatchUpLatest
+	}
+	if len(template.Labels) == 0 {
+		template.Labels = nil
+	}
+	return template
+}
+
+// sortedNames returns the names in either map, sorted
+func sortedNames(desired, live map[string]interface{}) []string {
+	names := make([]string, 0, len(desired)+len(live))
+	for name := range desired {
+		names = append(names, name)
+	}
+	for name := range live {
+		if _, exists := desired[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,286 @@
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+)
+
+const testConfig = `
+alert_rules:
+  - name: h100
+    pattern: "(?i)h100"
+    thresholds:
+      high_temperature: 80
+      for: 5m
+budgets:
+  - name: research
+    team: research
+    monthly_limit: 5000
+pools:
+  - name: batch
+    max_gpus: 4
+templates:
+  - name: nightly
+    tenant: research
+    memory_mb: 8192
+    estimated_time: 2h
+    recurrence:
+      schedule: "0 2 * * *"
+`
+
+// fakeLive keeps the live configuration in maps
+type fakeLive struct {
+	rules     map[string]observability.GPUThresholdProfile
+	budgets   map[string]observability.Budget
+	pools     map[string]observability.PoolResource
+	templates map[string]observability.WorkloadTemplate
+	failPut   bool
+}
+
+func newFakeLive() *fakeLive {
+	return &fakeLive{
+		rules:     map[string]observability.GPUThresholdProfile{},
+		budgets:   map[string]observability.Budget{},
+		pools:     map[string]observability.PoolResource{},
+		templates: map[string]observability.WorkloadTemplate{},
+	}
+}
+
+func (f *fakeLive) AlertRules(ctx context.Context) ([]observability.GPUThresholdProfile, error) {
+	var rules []observability.GPUThresholdProfile
+	for _, rule := range f.rules {
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (f *fakeLive) PutAlertRule(ctx context.Context, rule observability.GPUThresholdProfile) (*observability.GPUThresholdProfile, error) {
+	if f.failPut {
+		return nil, errors.New("unavailable")
+	}
+	f.rules[rule.Name] = rule
+	return &rule, nil
+}
+
+func (f *fakeLive) DeleteAlertRule(ctx context.Context, name string) error {
+	delete(f.rules, name)
+	return nil
+}
+
+func (f *fakeLive) Budgets(ctx context.Context) ([]observability.BudgetStatus, error) {
+	var budgets []observability.BudgetStatus
+	for _, budget := range f.budgets {
+		budgets = append(budgets, observability.BudgetStatus{Budget: budget, Spent: 12})
+	}
+	return budgets, nil
+}
+
+func (f *fakeLive) PutBudget(ctx context.Context, budget observability.Budget) (*observability.BudgetStatus, error) {
+	if f.failPut {
+		return nil, errors.New("unavailable")
+	}
+	f.budgets[budget.Name] = budget
+	return &observability.BudgetStatus{Budget: budget}, nil
+}
+
+func (f *fakeLive) DeleteBudget(ctx context.Context, name string) error {
+	delete(f.budgets, name)
+	return nil
+}
+
+func (f *fakeLive) PoolConfigs(ctx context.Context) ([]observability.PoolResource, error) {
+	var pools []observability.PoolResource
+	for _, pool := range f.pools {
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
+func (f *fakeLive) PutPool(ctx context.Context, pool observability.PoolResource) (*observability.PoolResource, error) {
+	if f.failPut {
+		return nil, errors.New("unavailable")
+	}
+	f.pools[pool.Name] = pool
+	return &pool, nil
+}
+
+func (f *fakeLive) DeletePool(ctx context.Context, name string) error {
+	delete(f.pools, name)
+	return nil
+}
+
+func (f *fakeLive) Templates(ctx context.Context) ([]observability.WorkloadTemplate, error) {
+	var templates []observability.WorkloadTemplate
+	for _, template := range f.templates {
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+func (f *fakeLive) PutTemplate(ctx context.Context, template observability.WorkloadTemplate) (*observability.WorkloadTemplate, error) {
+	if f.failPut {
+		return nil, errors.New("unavailable")
+	}
+	// Like the recurrence manager, fill in the defaults
+	template.Recurrence.MaxConcurrency = 1
+	template.Recurrence.CatchUp = gpu.CatchUpLatest
+	f.templates[template.Name] = template
+	return &template, nil
+}
+
+func (f *fakeLive) DeleteTemplate(ctx context.Context, name string) error {
+	delete(f.templates, name)
+	return nil
+}
+
+// staticSource serves fixed files
+type staticSource struct {
+	revision string
+	files    map[string][]byte
+}
+
+func (s *staticSource) Fetch(ctx context.Context) (Snapshot, error) {
+	return Snapshot{Revision: s.revision, Files: s.files}, nil
+}
+
+func (s *staticSource) String() string {
+	return "static"
+}
+
+func TestParseDesiredState(t *testing.T) {
+	state, err := ParseDesiredState(map[string][]byte{
+		"config.yaml": []byte(testConfig),
+		"extra.json":  []byte(`{"budgets": [{"name": "platform", "team": "platform", "monthly_limit": 100}]}`),
+		"README.md":   []byte("# ignored"),
+	})
+	if err != nil {
+		t.Fatalf("Expected config to parse, got %v", err)
+	}
+	if len(state.AlertRules) != 1 || state.AlertRules[0].Thresholds.For != 5*time.Minute {
+		t.Errorf("Expected one alert rule holding for 5m, got %+v", state.AlertRules)
+	}
+	if len(state.Budgets) != 2 {
+		t.Errorf("Expected budgets from both files, got %+v", state.Budgets)
+	}
+	if len(state.Pools) != 1 || state.Pools[0].MaxGPUs != 4 {
+		t.Errorf("Expected pool batch with 4 GPUs, got %+v", state.Pools)
+	}
+	if len(state.Templates) != 1 || state.Templates[0].EstimatedTime != 2*time.Hour {
+		t.Errorf("Expected template estimated at 2h, got %+v", state.Templates)
+	}
+
+	_, err = ParseDesiredState(map[string][]byte{
+		"a.yaml": []byte("pools:\n  - name: batch\n"),
+		"b.yaml": []byte("pools:\n  - name: batch\n"),
+	})
+	if err == nil || !strings.Contains(err.Error(), "already defined in a.yaml") {
+		t.Errorf("Expected duplicate pool error, got %v", err)
+	}
+
+	_, err = ParseDesiredState(map[string][]byte{
+		"bad.yaml": []byte("templates:\n  - name: nightly\n    memory_mb: 1\n    recurrence:\n      schedule: never\n"),
+	})
+	if err == nil || !strings.Contains(err.Error(), "bad.yaml") {
+		t.Errorf("Expected invalid schedule error naming the file, got %v", err)
+	}
+
+	_, err = ParseDesiredState(map[string][]byte{"typo.yaml": []byte("budget:\n  - name: x\n")})
+	if err == nil {
+		t.Error("Expected unknown field to be rejected")
+	}
+}
+
+func TestControllerSync(t *testing.T) {
+	live := newFakeLive()
+	live.pools["legacy"] = observability.PoolResource{Name: "legacy"}
+	live.budgets["research"] = observability.Budget{Name: "research", Team: "research", MonthlyLimit: 1000}
+	source := &staticSource{revision: "abc123", files: map[string][]byte{"config.yaml": []byte(testConfig)}}
+
+	// Dry run reports drift without changing anything
+	controller := NewController(source, live, Config{DryRun: true})
+	status := controller.Sync(context.Background())
+	if status.Error != "" || status.Synced {
+		t.Fatalf("Expected unsynced dry run without error, got %+v", status)
+	}
+	if len(status.Drift) != 5 {
+		t.Errorf("Expected 5 changes, got %+v", status.Drift)
+	}
+	if live.budgets["research"].MonthlyLimit != 1000 {
+		t.Error("Expected dry run to leave the budget alone")
+	}
+
+	// Without pruning, the extra pool is reported but kept
+	controller = NewController(source, live, Config{})
+	status = controller.Sync(context.Background())
+	if status.Synced || status.Error != "" || status.Revision != "abc123" || status.Resources != 4 {
+		t.Errorf("Expected unsynced status at abc123 with 4 resources, got %+v", status)
+	}
+	if live.budgets["research"].MonthlyLimit != 5000 {
+		t.Errorf("Expected budget to be updated, got %+v", live.budgets["research"])
+	}
+	if _, exists := live.pools["legacy"]; !exists {
+		t.Error("Expected legacy pool to be kept without pruning")
+	}
+	for _, change := range status.Drift {
+		if change.Name == "legacy" && (change.Action != ActionDelete || change.Applied) {
+			t.Errorf("Expected unapplied delete of legacy, got %+v", change)
+		}
+	}
+
+	// With pruning the live system converges, and the next sync finds no drift
+	controller = NewController(source, live, Config{Prune: true})
+	controller.Sync(context.Background())
+	status = controller.Sync(context.Background())
+	if !status.Synced || len(status.Drift) != 0 || status.LastSuccess == nil {
+		t.Errorf("Expected synced status without drift, got %+v", status)
+	}
+	if _, exists := live.pools["legacy"]; exists {
+		t.Error("Expected legacy pool to be pruned")
+	}
+
+	// Drift made outside the controller is detected and reverted
+	live.pools["batch"] = observability.PoolResource{Name: "batch", PoolConfig: gpu.PoolConfig{MaxGPUs: 8}}
+	live.failPut = true
+	status = controller.Sync(context.Background())
+	if status.Synced || status.Error == "" || len(status.Drift) != 1 || !status.Drift[0].Failed {
+		t.Errorf("Expected failed update of batch, got %+v", status)
+	}
+	live.failPut = false
+	controller.Sync(context.Background())
+	if live.pools["batch"].MaxGPUs != 4 {
+		t.Errorf("Expected batch pool to be reverted to 4 GPUs, got %+v", live.pools["batch"])
+	}
+}
+
+func TestControllerHandler(t *testing.T) {
+	source := &staticSource{revision: "abc123", files: map[string][]byte{"bad.yaml": []byte("pools: [")}}
+	controller := NewController(source, newFakeLive(), Config{})
+	controller.Sync(context.Background())
+
+	recorder := httptest.NewRecorder()
+	controller.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 after a failed sync, got %d", recorder.Code)
+	}
+
+	source.files = map[string][]byte{"config.yaml": []byte(testConfig)}
+	controller.Sync(context.Background())
+	recorder = httptest.NewRecorder()
+	controller.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var status Status
+	if err := json.NewDecoder(recorder.Body).Decode(&status); err != nil {
+		t.Fatalf("Expected status JSON, got %v", err)
+	}
+	if !status.Synced || status.Source != "static" || len(status.Drift) != 4 {
+		t.Errorf("Expected synced status with 4 applied changes, got %+v", status)
+	}
+}
@@ -0,0 +1,172 @@
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Snapshot is the content of a source at one revision
+type Snapshot struct {
+	Revision string
+	Files    map[string][]byte // Keyed by slash-separated path relative to the source
+}
+
+// Source provides the desired state
+type Source interface {
+	Fetch(ctx context.Context) (Snapshot, error)
+	String() string
+}
+
+// GitSource reads the desired state from a branch of a Git repository. It
+// keeps a shallow clone in Dir and runs the git binary to update it.
+type GitSource struct {
+	Repo   string // URL or path of the repository
+	Branch string // "main" when empty
+	Path   string // Directory within the repository holding the config; the root when empty
+	Dir    string // Where to keep the clone
+}
+
+// String names the repository, branch and path
+func (s *GitSource) String() string {
+	name := s.Repo + "@" + s.branch()
+	if s.Path != "" {
+		name += ":" + s.Path
+	}
+	return name
+}
+
+func (s *GitSource) branch() string {
+	if s.Branch == "" {
+		return "main"
+	}
+	return s.Branch
+}
+
+// Fetch clones the branch on first use, then updates it to the remote head
+func (s *GitSource) Fetch(ctx context.Context) (Snapshot, error) {
+	if s.Dir == "" {
+		return Snapshot{}, fmt.Errorf("git source %s has no checkout directory", s.Repo)
+	}
+	if _, err := os.Stat(filepath.Join(s.Dir, ".git")); os.IsNotExist(err) {
+		if _, err := s.git(ctx, "", "clone", "--quiet", "--depth", "1", "--branch", s.branch(), s.Repo, s.Dir); err != nil {
+			return Snapshot{}, err
+		}
+	} else {
+		if _, err := s.git(ctx, s.Dir, "fetch", "--quiet", "--depth", "1", "origin", s.branch()); err != nil {
+			return Snapshot{}, err
+		}
+		if _, err := s.git(ctx, s.Dir, "reset", "--quiet", "--hard", "FETCH_HEAD"); err != nil {
+			return Snapshot{}, err
+		}
+	}
+
+	revision, err := s.git(ctx, s.Dir, "rev-parse", "HEAD")
+	if err != nil {
+		return Snapshot{}, err
+	}
+	files, err := readConfigDir(filepath.Join(s.Dir, filepath.FromSlash(s.Path)))
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{Revision: strings.TrimSpace(revision), Files: files}, nil
+}
+
+// git runs a git command in dir and returns its output
+func (s *GitSource) git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// DirSource reads the desired state from a directory, such as a ConfigMap
+// mounted into the controller's pod. Kubernetes updates the mount in place
+// when the ConfigMap changes.
+type DirSource struct {
+	Dir string
+}
+
+// String names the directory
+func (s *DirSource) String() string {
+	return s.Dir
+}
+
+// Fetch reads the directory. The revision is a digest of its content, so it
+// changes only when the config does.
+func (s *DirSource) Fetch(ctx context.Context) (Snapshot, error) {
+	files, err := readConfigDir(s.Dir)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	digest := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(digest, "%s\x00%d\x00", name, len(files[name]))
+		digest.Write(files[name])
+	}
+	return Snapshot{Revision: hex.EncodeToString(digest.Sum(nil))[:12], Files: files}, nil
+}
+
+// readConfigDir reads the YAML and JSON files under dir. Hidden entries are
+// skipped, which leaves out .git and the ..data links of ConfigMap mounts;
+// other symlinks are followed.
+func readConfigDir(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	var walk func(rel string) error
+	walk = func(rel string) error {
+		entries, err := os.ReadDir(filepath.Join(dir, rel))
+		if err != nil {
+			return fmt.Errorf("failed to read config directory: %w", err)
+		}
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			name := filepath.Join(rel, entry.Name())
+			info, err := os.Stat(filepath.Join(dir, name))
+			if err != nil {
+				return fmt.Errorf("failed to read config: %w", err)
+			}
+			if info.IsDir() {
+				if err := walk(name); err != nil {
+					return err
+				}
+				continue
+			}
+			switch strings.ToLower(filepath.Ext(name)) {
+			case ".yaml", ".yml", ".json":
+			default:
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return fmt.Errorf("failed to read config: %w", err)
+			}
+			files[filepath.ToSlash(name)] = data
+		}
+		return nil
+	}
+	if err := walk(""); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
@@ -0,0 +1,81 @@
+package gitops
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirSource(t *testing.T) {
+	dir := t.TempDir()
+	// Lay the directory out like a ConfigMap mount
+	data := filepath.Join(dir, "..2024_01_01")
+	os.Mkdir(data, 0755)
+	os.WriteFile(filepath.Join(data, "config.yaml"), []byte(testConfig), 0644)
+	os.Symlink("..2024_01_01", filepath.Join(dir, "..data"))
+	os.Symlink(filepath.Join("..data", "config.yaml"), filepath.Join(dir, "config.yaml"))
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644)
+
+	source := &DirSource{Dir: dir}
+	snapshot, err := source.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Expected directory to be read, got %v", err)
+	}
+	if len(snapshot.Files) != 1 || snapshot.Files["config.yaml"] == nil {
+		t.Errorf("Expected only config.yaml, got %v", snapshot.Files)
+	}
+
+	again, _ := source.Fetch(context.Background())
+	if again.Revision != snapshot.Revision {
+		t.Errorf("Expected unchanged revision %s, got %s", snapshot.Revision, again.Revision)
+	}
+	os.WriteFile(filepath.Join(data, "config.yaml"), []byte("pools: []\n"), 0644)
+	changed, _ := source.Fetch(context.Background())
+	if changed.Revision == snapshot.Revision {
+		t.Error("Expected revision to change with the content")
+	}
+}
+
+func TestGitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+		return string(out)
+	}
+	git("init", "--quiet", "--initial-branch", "main")
+	os.MkdirAll(filepath.Join(repo, "clusters", "prod"), 0755)
+	os.WriteFile(filepath.Join(repo, "clusters", "prod", "config.yaml"), []byte(testConfig), 0644)
+	os.WriteFile(filepath.Join(repo, "other.yaml"), []byte("pools: []\n"), 0644)
+	git("add", "-A")
+	git("commit", "--quiet", "-m", "initial")
+
+	source := &GitSource{Repo: repo, Path: "clusters", Dir: filepath.Join(t.TempDir(), "checkout")}
+	snapshot, err := source.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Expected clone to succeed, got %v", err)
+	}
+	if len(snapshot.Files) != 1 || snapshot.Files["prod/config.yaml"] == nil {
+		t.Errorf("Expected only prod/config.yaml, got %v", snapshot.Files)
+	}
+
+	os.WriteFile(filepath.Join(repo, "clusters", "prod", "config.yaml"), []byte("pools: []\n"), 0644)
+	git("commit", "--quiet", "-am", "clear")
+	head := git("rev-parse", "HEAD")
+	updated, err := source.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Expected fetch to succeed, got %v", err)
+	}
+	if updated.Revision+"\n" != head || string(updated.Files["prod/config.yaml"]) != "pools: []\n" {
+		t.Errorf("Expected checkout at %s, got %s with %q", head, updated.Revision, updated.Files["prod/config.yaml"])
+	}
+}
@@ -0,0 +1,109 @@
+package gpu
+
+import "time"
+
+// AdaptiveIntervalConfig makes the collector poll slowly while its GPUs are
+// idle and quickly while they are busy or an alert condition is pending
+type AdaptiveIntervalConfig struct {
+	Enabled        bool          `yaml:"enabled" json:"enabled"`
+	IdleInterval   time.Duration `yaml:"idle_interval" json:"idle_interval"`
+	ActiveInterval time.Duration `yaml:"active_interval" json:"active_interval"`
+
+	// Any GPU at or above UtilizationThreshold percent makes the collector
+	// active, and it stays active for ActiveHold after the last activity
+	UtilizationThreshold float64       `yaml:"utilization_threshold" json:"utilization_threshold"`
+	ActiveHold           time.Duration `yaml:"active_hold" json:"active_hold"`
+}
+
+// DefaultAdaptiveIntervalConfig returns adaptive collection settings for
+// mostly idle clusters
+func DefaultAdaptiveIntervalConfig() AdaptiveIntervalConfig {
+	return AdaptiveIntervalConfig{
+		Enabled:              true,
+		IdleInterval:         30 * time.Second,
+		ActiveInterval:       time.Second,
+		UtilizationThreshold: 10,
+		ActiveHold:           2 * time.Minute,
+	}
+}
+
+// SetAdaptiveInterval replaces the fixed collection interval with idle and
+// active intervals; zero fields use the defaults
+func (mc *MetricsCollector) SetAdaptiveInterval(config AdaptiveIntervalConfig) {
+	defaults := DefaultAdaptiveIntervalConfig()
+	if config.IdleInterval <= 0 {
+		config.IdleInterval = defaults.IdleInterval
+	}
+	if config.ActiveInterval <= 0 {
+		config.ActiveInterval = defaults.ActiveInterval
+	}
+	if config.UtilizationThreshold <= 0 {
+		config.UtilizationThreshold = defaults.UtilizationThreshold
+	}
+	if config.ActiveHold <= 0 {
+		config.ActiveHold = defaults.ActiveHold
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.adaptive = config
+}
+
+// Boost switches an adaptive collector to its active interval for at least
+// hold, or ActiveHold when hold is zero, collecting immediately when it was
+// idle. It is used while alert conditions are pending or firing.
+func (mc *MetricsCollector) Boost(hold time.Duration) {
+	now := time.Now()
+	mc.mu.Lock()
+	if !mc.adaptive.Enabled {
+		mc.mu.Unlock()
+		return
+	}
+	if hold <= 0 {
+		hold = mc.adaptive.ActiveHold
+	}
+	wasIdle := !now.Before(mc.activeUntil)
+	if until := now.Add(hold); until.After(mc.activeUntil) {
+		mc.activeUntil = until
+	}
+	mc.mu.Unlock()
+
+	if wasIdle {
+		select {
+		case mc.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// observeActivity keeps an adaptive collector active while any GPU is busy
+func (mc *MetricsCollector) observeActivity(all map[string]GPUMetrics, now time.Time) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if !mc.adaptive.Enabled {
+		return
+	}
+	for _, metrics := range all {
+		if metrics.UtilizationGPU >= mc.adaptive.UtilizationThreshold {
+			mc.activeUntil = now.Add(mc.adaptive.ActiveHold)
+			return
+		}
+	}
+}
+
+// nextInterval chooses the wait before the next collection
+func (mc *MetricsCollector) nextInterval(now time.Time) time.Duration {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	interval := mc.collectInterval
+	if mc.adaptive.Enabled {
+		interval = mc.adaptive.IdleInterval
+		if now.Before(mc.activeUntil) {
+			interval = mc.adaptive.ActiveInterval
+		}
+	}
+	mc.currentInterval = interval
+	return interval
+}
@@ -0,0 +1,71 @@
+package gpu
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveIntervalFollowsActivity(t *testing.T) {
+	collector := NewMetricsCollector(5 * time.Second)
+	now := time.Now()
+	if got := collector.nextInterval(now); got != 5*time.Second {
+		t.Fatalf("fixed interval = %v", got)
+	}
+
+	collector.SetAdaptiveInterval(AdaptiveIntervalConfig{Enabled: true, IdleInterval: 30 * time.Second, ActiveInterval: time.Second, ActiveHold: time.Minute})
+	if got := collector.nextInterval(now); got != 30*time.Second {
+		t.Errorf("idle interval = %v, want 30s", got)
+	}
+
+	collector.observeActivity(map[string]GPUMetrics{"0": {UtilizationGPU: 2}, "1": {UtilizationGPU: 3}}, now)
+	if got := collector.nextInterval(now); got != 30*time.Second {
+		t.Errorf("GPUs below the threshold should stay idle, got %v", got)
+	}
+
+	collector.observeActivity(map[string]GPUMetrics{"0": {UtilizationGPU: 2}, "1": {UtilizationGPU: 75}}, now)
+	if got := collector.nextInterval(now.Add(30 * time.Second)); got != time.Second {
+		t.Errorf("busy GPU should speed collection up, got %v", got)
+	}
+	if got := collector.CycleInterval(); got != time.Second {
+		t.Errorf("watchdog cycle interval = %v, want the active interval", got)
+	}
+	if got := collector.nextInterval(now.Add(2 * time.Minute)); got != 30*time.Second {
+		t.Errorf("collector should slow down after the hold, got %v", got)
+	}
+}
+
+func TestBoostWakesIdleCollector(t *testing.T) {
+	collector := NewMetricsCollector(time.Second)
+	collector.SetAdaptiveInterval(AdaptiveIntervalConfig{Enabled: true, IdleInterval: time.Hour, ActiveInterval: time.Hour})
+	collector.SetJitter(0)
+	collections := make(chan struct{}, 10)
+	runner := NewCommandRunner(CommandRunnerConfig{CacheTTL: -1})
+	runner.exec = func(ctx context.Context, spec CommandSpec) ([]byte, error) {
+		if strings.HasPrefix(spec.Args[0], "--query-gpu") {
+			collections <- struct{}{}
+		}
+		return nil, nil
+	}
+	collector.SetCommandRunner(runner)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go collector.collectLoop(ctx)
+
+	collector.Boost(0)
+	select {
+	case <-collections:
+	case <-time.After(time.Second):
+		t.Fatal("boost did not wake the idle collector")
+	}
+
+	// Already active: further boosts only extend the hold
+	collector.Boost(0)
+	select {
+	case <-collections:
+		t.Error("boosting an active collector collected again")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,46 @@
+package gpu
+
+import "time"
+
+// AgingConfig controls how waiting raises a queued workload's effective priority
+type AgingConfig struct {
+	Enabled bool
+
+	// Every full Interval a workload waits adds Step to its effective
+	// priority, up to MaxBoost. The submitted priority is never changed.
+	Interval time.Duration
+	Step     int
+	MaxBoost int
+}
+
+// DefaultAgingConfig returns gradual aging settings (disabled by default)
+func DefaultAgingConfig() AgingConfig {
+	return AgingConfig{
+		Enabled:  false,
+		Interval: 5 * time.Minute,
+		Step:     1,
+		MaxBoost: 5,
+	}
+}
+
+// EffectivePriority returns the priority the scheduler uses for a workload,
+// including any aging earned while queued
+func (s *Scheduler) EffectivePriority(workload *Workload) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.effectivePriority(workload, time.Now())
+}
+
+// effectivePriority adds aging to a pending workload's priority; callers must hold the lock
+func (s *Scheduler) effectivePriority(workload *Workload, now time.Time) int {
+	config := s.config.Aging
+	if !config.Enabled || config.Interval <= 0 || config.Step <= 0 || workload.Status != WorkloadPending {
+		return workload.Priority
+	}
+
+	boost := int(now.Sub(workload.queuedSince())/config.Interval) * config.Step
+	if config.MaxBoost > 0 && boost > config.MaxBoost {
+		boost = config.MaxBoost
+	}
+	return workload.Priority + boost
+}
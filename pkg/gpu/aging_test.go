@@ -0,0 +1,51 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPriorityAgingPreventsStarvation(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		config := DefaultSchedulerConfig()
+		config.Aging = AgingConfig{Enabled: enabled, Interval: 5 * time.Minute, Step: 1, MaxBoost: 3}
+		scheduler := NewSchedulerWithConfig(StrategyPriority, config)
+		scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+
+		old := &Workload{ID: "old", Priority: 1, MemoryRequired: 8000}
+		fresh := &Workload{ID: "fresh", Priority: 3, MemoryRequired: 8000}
+		scheduler.SubmitWorkload(old)
+		scheduler.SubmitWorkload(fresh)
+		old.QueuedAt = time.Now().Add(-22 * time.Minute)
+
+		expected := 1
+		if enabled {
+			expected = 4 // four intervals waited, capped at a boost of 3
+		}
+		if effective := scheduler.EffectivePriority(old); effective != expected {
+			t.Errorf("aging=%v: expected effective priority %d, got %d", enabled, expected, effective)
+		}
+
+		listed := scheduler.ListWorkloads()
+		if len(listed) != 2 || listed[0].EffectivePriority < listed[1].EffectivePriority {
+			t.Fatalf("aging=%v: expected workloads ordered by effective priority, got %+v", enabled, listed)
+		}
+
+		scheduler.Schedule()
+		winner := "fresh"
+		if enabled {
+			winner = "old"
+		}
+		if scheduler.GetGPUStatus()[0].CurrentWorkload.ID != winner {
+			t.Errorf("aging=%v: expected %s to be scheduled first", enabled, winner)
+		}
+		if old.Priority != 1 {
+			t.Errorf("aging=%v: expected the submitted priority unchanged, got %d", enabled, old.Priority)
+		}
+
+		listed = scheduler.ListWorkloads()
+		if listed[0].Status != WorkloadPending || listed[1].Status != WorkloadRunning || listed[1].AssignedGPU != "gpu-0" {
+			t.Errorf("aging=%v: expected pending then running workloads, got %+v", enabled, listed)
+		}
+	}
+}
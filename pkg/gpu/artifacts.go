@@ -0,0 +1,160 @@
+package gpu
+
+import (
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// Artifact is an output a workload produced, such as a model checkpoint
+type Artifact struct {
+	Name         string    `json:"name"`
+	URI          string    `json:"uri"` // Path or URI, e.g. s3://models/run-42/model.safetensors
+	SizeBytes    int64     `json:"size_bytes,omitempty"`
+	Checksum     string    `json:"checksum,omitempty"` // "<algorithm>:<hex digest>", e.g. "sha256:9f86d0..."
+	ModelID      string    `json:"model_id,omitempty"` // Serving registry model built from the artifact
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// maxFinishedWorkloads bounds the finished workloads whose artifacts are kept
+const maxFinishedWorkloads = 10000
+
+// checksumLengths are the hex digest lengths of supported checksum algorithms
+var checksumLengths = map[string]int{
+	"md5":    32,
+	"sha1":   40,
+	"sha256": 64,
+	"sha512": 128,
+}
+
+// Validate checks an artifact's URI, size and checksum
+func (a Artifact) Validate() error {
+	if a.URI == "" {
+		return fmt.Errorf("artifact URI cannot be empty")
+	}
+	if a.SizeBytes < 0 {
+		return fmt.Errorf("artifact %s has a negative size", a.URI)
+	}
+	if a.Checksum != "" {
+		parts := strings.SplitN(a.Checksum, ":", 2)
+		length, supported := checksumLengths[strings.ToLower(parts[0])]
+		if len(parts) != 2 || !supported {
+			return fmt.Errorf("artifact %s checksum must be md5, sha1, sha256 or sha512 as <algorithm>:<hex>", a.URI)
+		}
+		if _, err := hex.DecodeString(parts[1]); err != nil || len(parts[1]) != length {
+			return fmt.Errorf("artifact %s has an invalid %s digest", a.URI, parts[0])
+		}
+	}
+	return nil
+}
+
+// prepareArtifacts validates artifacts and fills in names and registration times
+func prepareArtifacts(artifacts []Artifact, now time.Time) ([]Artifact, error) {
+	prepared := make([]Artifact, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		if err := artifact.Validate(); err != nil {
+			return nil, err
+		}
+		if artifact.Name == "" {
+			artifact.Name = path.Base(artifact.URI)
+		}
+		artifact.Checksum = strings.ToLower(artifact.Checksum)
+		artifact.RegisteredAt = now
+		prepared = append(prepared, artifact)
+	}
+	return prepared, nil
+}
+
+// RegisterArtifacts records outputs of a queued, running or finished
+// workload. Finished workloads keep their artifacts after leaving the
+// scheduler, so results can be uploaded after the job exits.
+func (s *Scheduler) RegisterArtifacts(workloadID string, artifacts []Artifact) error {
+	prepared, err := prepareArtifacts(artifacts, time.Now())
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if workload := s.findWorkload(workloadID); workload != nil {
+		workload.Artifacts = append(workload.Artifacts, prepared...)
+		s.logWorkload(walArtifacts, workload, s.isColocated(workload))
+		return nil
+	}
+	if finished, exists := s.finished[workloadID]; exists {
+		finished.artifacts = append(finished.artifacts, prepared...)
+		return nil
+	}
+	return fmt.Errorf("workload %s not found", workloadID)
+}
+
+// CompleteWorkloadWithArtifacts marks a workload as completed with the
+// outputs it produced
+func (s *Scheduler) CompleteWorkloadWithArtifacts(workloadID string, artifacts []Artifact) error {
+	prepared, err := prepareArtifacts(artifacts, time.Now())
+	if err != nil {
+		return err
+	}
+	return s.finishWorkload(workloadID, WorkloadCompleted, prepared)
+}
+
+// GetArtifacts returns the artifacts of a known workload, oldest first
+func (s *Scheduler) GetArtifacts(workloadID string) ([]Artifact, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if workload := s.findWorkload(workloadID); workload != nil {
+		return append([]Artifact{}, workload.Artifacts...), true
+	}
+	if finished, exists := s.finished[workloadID]; exists {
+		return append([]Artifact{}, finished.artifacts...), true
+	}
+	return nil, false
+}
+
+// findWorkload returns a queued or placed workload; callers must hold the lock
+func (s *Scheduler) findWorkload(workloadID string) *Workload {
+	for _, workload := range s.workloadQueue {
+		if workload.ID == workloadID {
+			return workload
+		}
+	}
+	for _, gpu := range s.gpus {
+		for _, workload := range []*Workload{gpu.CurrentWorkload, gpu.ColocatedWorkload} {
+			if workload != nil && workload.ID == workloadID {
+				return workload
+			}
+		}
+	}
+	return nil
+}
+
+// isColocated reports whether a workload shares a GPU with inference; callers must hold the lock
+func (s *Scheduler) isColocated(workload *Workload) bool {
+	gpu, exists := s.gpus[workload.AssignedGPU]
+	return exists && gpu.ColocatedWorkload == workload
+}
+
+// finishedWorkload is what the scheduler keeps of a workload after it leaves
+type finishedWorkload struct {
+	tenant    string
+	artifacts []Artifact
+}
+
+// retainFinished keeps a finished workload's tenant and artifacts; callers must hold the lock
+func (s *Scheduler) retainFinished(workload *Workload) {
+	if _, exists := s.finished[workload.ID]; !exists {
+		s.finishedOrder = append(s.finishedOrder, workload.ID)
+	}
+	s.finished[workload.ID] = &finishedWorkload{
+		tenant:    workload.Tenant,
+		artifacts: append([]Artifact{}, workload.Artifacts...),
+	}
+	for len(s.finishedOrder) > maxFinishedWorkloads {
+		delete(s.finished, s.finishedOrder[0])
+		s.finishedOrder = s.finishedOrder[1:]
+	}
+}
@@ -0,0 +1,73 @@
+package gpu
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestArtifactsFollowWorkloadsThroughCompletion(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16384, Available: true})
+	scheduler.SubmitWorkload(&Workload{ID: "train-1", MemoryRequired: 8192})
+	scheduler.Schedule()
+
+	checkpoint := Artifact{URI: "s3://runs/train-1/checkpoint-1000.pt", SizeBytes: 1 << 30}
+	if err := scheduler.RegisterArtifacts("train-1", []Artifact{checkpoint}); err != nil {
+		t.Fatalf("RegisterArtifacts: %v", err)
+	}
+	if listed := scheduler.ListWorkloads(); len(listed) != 1 || len(listed[0].Artifacts) != 1 || listed[0].Artifacts[0].Name != "checkpoint-1000.pt" {
+		t.Fatalf("listed = %+v, want the running workload with its checkpoint", listed)
+	}
+
+	model := Artifact{
+		Name:     "model",
+		URI:      "s3://runs/train-1/model.safetensors",
+		Checksum: "SHA256:" + strings.Repeat("ab", 32),
+		ModelID:  "llama-ft-v3",
+	}
+	if err := scheduler.CompleteWorkloadWithArtifacts("train-1", []Artifact{model}); err != nil {
+		t.Fatalf("CompleteWorkloadWithArtifacts: %v", err)
+	}
+	artifacts, exists := scheduler.GetArtifacts("train-1")
+	if !exists || len(artifacts) != 2 || artifacts[1].Checksum != "sha256:"+strings.Repeat("ab", 32) || artifacts[1].RegisteredAt.IsZero() {
+		t.Fatalf("artifacts = %+v, want both outputs after completion", artifacts)
+	}
+
+	// Results uploaded after the job exits are still accepted
+	if err := scheduler.RegisterArtifacts("train-1", []Artifact{{URI: "/mnt/results/eval.json"}}); err != nil {
+		t.Fatalf("RegisterArtifacts after completion: %v", err)
+	}
+	if artifacts, _ := scheduler.GetArtifacts("train-1"); len(artifacts) != 3 {
+		t.Errorf("artifacts = %+v, want 3", artifacts)
+	}
+	if err := scheduler.RegisterArtifacts("unknown", []Artifact{{URI: "/tmp/x"}}); err == nil {
+		t.Error("registered artifacts for an unknown workload")
+	}
+	if _, exists := scheduler.GetArtifacts("unknown"); exists {
+		t.Error("unknown workload has artifacts")
+	}
+}
+
+func TestArtifactValidation(t *testing.T) {
+	invalid := []Artifact{
+		{},
+		{URI: "/out/model.bin", SizeBytes: -1},
+		{URI: "/out/model.bin", Checksum: "crc32:deadbeef"},
+		{URI: "/out/model.bin", Checksum: "sha256:abc"},
+		{URI: "/out/model.bin", Checksum: "sha1:" + strings.Repeat("zz", 20)},
+	}
+	for _, artifact := range invalid {
+		if err := artifact.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded", artifact)
+		}
+	}
+	if err := (Artifact{URI: "/out/model.bin", Checksum: "md5:" + strings.Repeat("0f", 16)}).Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	scheduler.SubmitWorkload(&Workload{ID: "w1", MemoryRequired: 1024})
+	if err := scheduler.RegisterArtifacts("w1", invalid[1:2]); err == nil {
+		t.Error("registered an invalid artifact")
+	}
+}
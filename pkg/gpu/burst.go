@@ -0,0 +1,85 @@
+package gpu
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// BurstConfig controls the high-frequency samples captured around an alert
+type BurstConfig struct {
+	Samples  int           `yaml:"samples" json:"samples"`
+	Interval time.Duration `yaml:"interval" json:"interval"` // Sub-second where nvidia-smi keeps up
+}
+
+// DefaultBurstConfig returns a five-second burst at four samples per second
+func DefaultBurstConfig() BurstConfig {
+	return BurstConfig{
+		Samples:  20,
+		Interval: 250 * time.Millisecond,
+	}
+}
+
+// BurstCapture is a short run of high-frequency samples from one GPU
+type BurstCapture struct {
+	GPUID     string       `json:"gpu_id"`
+	Interval  string       `json:"interval"`
+	Samples   []GPUMetrics `json:"samples"`
+	Failed    int          `json:"failed"` // Samples that could not be collected
+	StartedAt time.Time    `json:"started_at"`
+	EndedAt   time.Time    `json:"ended_at"`
+}
+
+// CaptureBurst samples one GPU at the burst interval, bypassing the query
+// cache. Samples slower than the interval are taken back to back. It fails
+// only when no sample could be collected.
+func (mc *MetricsCollector) CaptureBurst(ctx context.Context, gpuID string, config BurstConfig) (BurstCapture, error) {
+	defaults := DefaultBurstConfig()
+	if config.Samples <= 0 {
+		config.Samples = defaults.Samples
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+
+	capture := BurstCapture{
+		GPUID:     gpuID,
+		Interval:  config.Interval.String(),
+		Samples:   make([]GPUMetrics, 0, config.Samples),
+		StartedAt: time.Now(),
+	}
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+
+	var lastErr error
+samples:
+	for i := 0; i < config.Samples; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				break samples
+			case <-ticker.C:
+			}
+		}
+		metrics, err := mc.collectGPUMetrics(ctx, gpuID)
+		if err != nil {
+			mc.recordCollectionError(err)
+			capture.Failed++
+			lastErr = err
+			continue
+		}
+		mc.mu.RLock()
+		metrics.NodeID = mc.nodeID
+		mc.mu.RUnlock()
+		capture.Samples = append(capture.Samples, metrics)
+	}
+	capture.EndedAt = time.Now()
+
+	if len(capture.Samples) == 0 {
+		if lastErr == nil {
+			lastErr = ctx.Err()
+		}
+		return capture, fmt.Errorf("burst capture for GPU %s collected no samples: %w", gpuID, lastErr)
+	}
+	return capture, nil
+}
@@ -0,0 +1,57 @@
+package gpu
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCaptureBurstTakesFreshSamples(t *testing.T) {
+	collector := NewMetricsCollector(time.Minute)
+	collector.SetNodeID("node-a")
+	runner := NewCommandRunner(CommandRunnerConfig{CacheTTL: time.Hour, Retries: -1})
+	calls := 0
+	runner.exec = func(ctx context.Context, spec CommandSpec) ([]byte, error) {
+		calls++
+		if calls == 3 {
+			return nil, fmt.Errorf("exit status 15")
+		}
+		return []byte(fmt.Sprintf("0, NVIDIA A100, %d, 20, 40960, 8192, 32768, 60, 250.5, 400, 30, 1410, 1215, 0, 0, 0, 0\n", calls)), nil
+	}
+	collector.SetCommandRunner(runner)
+
+	burst, err := collector.CaptureBurst(context.Background(), "0", BurstConfig{Samples: 5, Interval: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("CaptureBurst failed: %v", err)
+	}
+	if calls != 5 {
+		t.Errorf("burst ran nvidia-smi %d times, want every sample uncached", calls)
+	}
+	if len(burst.Samples) != 4 || burst.Failed != 1 || burst.Interval != "5ms" {
+		t.Fatalf("burst = %d samples, %d failed, interval %s", len(burst.Samples), burst.Failed, burst.Interval)
+	}
+	if burst.Samples[3].UtilizationGPU != 5 || burst.Samples[0].NodeID != "node-a" || burst.Samples[0].GPUID != "0" {
+		t.Errorf("unexpected samples %+v", burst.Samples)
+	}
+	if errs := collector.GetCollectionErrors(); errs[CollectionErrorExecFailed] != 1 {
+		t.Errorf("collection errors = %v", errs)
+	}
+}
+
+func TestCaptureBurstFailsWithoutSamples(t *testing.T) {
+	collector := NewMetricsCollector(time.Minute)
+	runner := NewCommandRunner(CommandRunnerConfig{Retries: -1})
+	runner.exec = func(ctx context.Context, spec CommandSpec) ([]byte, error) {
+		return nil, fmt.Errorf("exit status 15")
+	}
+	collector.SetCommandRunner(runner)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := collector.CaptureBurst(ctx, "0", BurstConfig{Samples: 3, Interval: time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "no samples") {
+		t.Errorf("expected a failed burst, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package gpu
+
+import (
+	"sort"
+	"time"
+)
+
+// PoolCapacity compares a pool's demand with its GPUs, for external
+// provisioners deciding whether to add or remove nodes
+type PoolCapacity struct {
+	Pool            string        `json:"pool"`
+	GPUs            int           `json:"gpus"`
+	IdleGPUs        int           `json:"idle_gpus"` // Available GPUs without a workload
+	Pending         int           `json:"pending"`   // Queued workloads not held for a start window or cheaper power
+	PendingMemoryMB uint64        `json:"pending_memory_mb"`
+	Unplaceable     int           `json:"unplaceable"` // Pending workloads needing more memory than any of the pool's GPUs has
+	OldestWait      time.Duration `json:"oldest_wait"`
+	// Until the last placeable pending workload could start, if running and
+	// pending workloads take their estimated time. Workloads without an
+	// estimate count as finishing at once, so this is a lower bound.
+	ProjectedWait time.Duration `json:"projected_wait"`
+	Timestamp     time.Time     `json:"timestamp"`
+}
+
+// GetCapacity returns the capacity of every configured pool and every pool
+// with GPUs or pending workloads, sorted by name
+func (s *Scheduler) GetCapacity() []PoolCapacity {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	pools := make(map[string]*PoolCapacity)
+	pool := func(name string) *PoolCapacity {
+		capacity, exists := pools[name]
+		if !exists {
+			capacity = &PoolCapacity{Pool: name, Timestamp: now}
+			pools[name] = capacity
+		}
+		return capacity
+	}
+	for name := range s.config.Pools {
+		pool(name)
+	}
+
+	// When each available GPU frees up, from its running workload's estimate
+	ids := make([]string, 0, len(s.gpus))
+	for id := range s.gpus {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	freeAt := make(map[string][]time.Duration)
+	memory := make(map[string][]uint64)
+	for _, id := range ids {
+		gpu := s.gpus[id]
+		capacity := pool(gpu.Pool)
+		capacity.GPUs++
+		if !gpu.Available {
+			continue
+		}
+		var remaining time.Duration
+		if workload := gpu.CurrentWorkload; workload == nil {
+			capacity.IdleGPUs++
+		} else if workload.StartedAt != nil {
+			if remaining = workload.StartedAt.Add(workload.EstimatedTime).Sub(now); remaining < 0 {
+				remaining = 0
+			}
+		}
+		freeAt[gpu.Pool] = append(freeAt[gpu.Pool], remaining)
+		memory[gpu.Pool] = append(memory[gpu.Pool], gpu.MemoryTotal)
+	}
+
+	for _, workload := range s.workloadQueue {
+		if s.held(workload, now) {
+			continue
+		}
+		capacity := pool(workload.Pool)
+		capacity.Pending++
+		capacity.PendingMemoryMB += workload.MemoryRequired
+		if waited := now.Sub(workload.queuedSince()); waited > capacity.OldestWait {
+			capacity.OldestWait = waited
+		}
+
+		// Start the workload on the GPU that frees up first among those large enough
+		best := -1
+		for i, free := range freeAt[workload.Pool] {
+			if memory[workload.Pool][i] >= workload.MemoryRequired && (best < 0 || free < freeAt[workload.Pool][best]) {
+				best = i
+			}
+		}
+		if best < 0 {
+			capacity.Unplaceable++
+			continue
+		}
+		start := freeAt[workload.Pool][best]
+		if start > capacity.ProjectedWait {
+			capacity.ProjectedWait = start
+		}
+		freeAt[workload.Pool][best] = start + workload.EstimatedTime
+	}
+
+	capacities := make([]PoolCapacity, 0, len(pools))
+	for _, capacity := range pools {
+		capacities = append(capacities, *capacity)
+	}
+	sort.Slice(capacities, func(i, j int) bool {
+		return capacities[i].Pool < capacities[j].Pool
+	})
+	return capacities
+}
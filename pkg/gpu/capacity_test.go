@@ -0,0 +1,46 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetCapacityProjectsQueueWait(t *testing.T) {
+	config := DefaultSchedulerConfig()
+	config.Pools["batch"] = PoolConfig{}
+	config.Pools["empty"] = PoolConfig{}
+	scheduler := NewSchedulerWithConfig(StrategyLeastUtilized, config)
+	scheduler.RegisterGPU(&GPU{ID: "batch-0", Pool: "batch", MemoryTotal: 16000, Available: true})
+	scheduler.RegisterGPU(&GPU{ID: "batch-1", Pool: "batch", MemoryTotal: 16000, Available: true})
+	scheduler.RegisterGPU(&GPU{ID: "batch-2", Pool: "batch", MemoryTotal: 16000, Available: false})
+	scheduler.RegisterGPU(&GPU{ID: "shared-0", MemoryTotal: 16000, Available: true})
+
+	// Fill both available batch GPUs, then queue three more hour-long jobs
+	for _, id := range []string{"run-1", "run-2", "wait-1", "wait-2", "wait-3"} {
+		scheduler.SubmitWorkload(&Workload{ID: id, Pool: "batch", MemoryRequired: 8000, EstimatedTime: time.Hour})
+	}
+	scheduler.SubmitWorkload(&Workload{ID: "huge", Pool: "batch", MemoryRequired: 40000})
+	scheduler.Schedule()
+
+	capacities := scheduler.GetCapacity()
+	if len(capacities) != 3 {
+		t.Fatalf("Expected default, batch and empty pools, got %+v", capacities)
+	}
+	shared, batch, empty := capacities[0], capacities[1], capacities[2]
+	if shared.Pool != "" || shared.IdleGPUs != 1 || shared.Pending != 0 {
+		t.Errorf("Expected one idle GPU in the default pool, got %+v", shared)
+	}
+	if batch.GPUs != 3 || batch.IdleGPUs != 0 || batch.Pending != 4 || batch.Unplaceable != 1 {
+		t.Errorf("Expected 3 busy or unavailable GPUs, 4 pending and 1 unplaceable, got %+v", batch)
+	}
+	if batch.PendingMemoryMB != 64000 {
+		t.Errorf("Expected 64000 MB pending, got %d", batch.PendingMemoryMB)
+	}
+	// The third waiting job starts after a running job and a waiting job finish
+	if batch.ProjectedWait <= time.Hour+59*time.Minute || batch.ProjectedWait > 2*time.Hour {
+		t.Errorf("Expected a projected wait of about 2h, got %v", batch.ProjectedWait)
+	}
+	if empty.GPUs != 0 || empty.Pending != 0 {
+		t.Errorf("Expected the configured empty pool listed without GPUs, got %+v", empty)
+	}
+}
@@ -0,0 +1,245 @@
+package gpu
+
+import (
+	"fmt"
+	"time"
+)
+
+// ColocationConfig controls when training jobs may share GPUs with inference
+type ColocationConfig struct {
+	Enabled bool
+
+	// Placement: only training jobs at or below MaxPriority are co-located, and only
+	// on inference GPUs below MaxUtilization whose memory use after placement stays
+	// at or below MaxMemoryPercent
+	MaxPriority      int
+	MaxUtilization   float64
+	MaxMemoryPercent float64
+
+	// Runtime: co-located jobs pause at PauseUtilization, resume at or below
+	// ResumeUtilization, and are preempted back to the queue after PreemptAfter paused
+	PauseUtilization  float64
+	ResumeUtilization float64
+	PreemptAfter      time.Duration
+}
+
+// DefaultColocationConfig returns conservative co-location thresholds (disabled by default)
+func DefaultColocationConfig() ColocationConfig {
+	return ColocationConfig{
+		Enabled:           false,
+		MaxPriority:       0,
+		MaxUtilization:    50.0,
+		MaxMemoryPercent:  80.0,
+		PauseUtilization:  75.0,
+		ResumeUtilization: 40.0,
+		PreemptAfter:      5 * time.Minute,
+	}
+}
+
+// ColocationEvent records a change to a co-located training job
+type ColocationEvent struct {
+	Type        string // colocated, paused, resumed, preempted, promoted
+	GPUID       string
+	WorkloadID  string
+	Utilization float64
+	Timestamp   time.Time
+}
+
+// OnColocationEvent registers a handler for co-location changes, e.g. to
+// signal the training process to pause or checkpoint
+func (s *Scheduler) OnColocationEvent(handler func(ColocationEvent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.colocationHandlers = append(s.colocationHandlers, handler)
+}
+
+// UpdateGPUUtilization records the observed utilization of a GPU and
+// pauses, resumes or preempts its co-located training job accordingly
+func (s *Scheduler) UpdateGPUUtilization(gpuID string, utilization float64) error {
+	s.mu.Lock()
+	gpu, exists := s.gpus[gpuID]
+	if !exists {
+		s.mu.Unlock()
+		return fmt.Errorf("GPU %s not found", gpuID)
+	}
+	gpu.Utilization = utilization
+	// Utilization is only attributable to a workload running alone
+	if gpu.CurrentWorkload != nil && gpu.ColocatedWorkload == nil {
+		s.observeUsage(gpu.CurrentWorkload.ID, utilization, 0)
+	}
+	events := s.reconcileGPU(gpu, time.Now())
+	s.recordColocationDecisions(events)
+	handlers := s.colocationHandlers
+	s.mu.Unlock()
+
+	notifyColocationHandlers(handlers, events)
+	return nil
+}
+
+// ReconcileColocation re-evaluates every co-located training job against
+// current GPU utilization and memory
+func (s *Scheduler) ReconcileColocation() []ColocationEvent {
+	s.mu.Lock()
+	now := time.Now()
+	events := make([]ColocationEvent, 0)
+	for _, gpu := range s.gpus {
+		events = append(events, s.reconcileGPU(gpu, now)...)
+	}
+	s.recordColocationDecisions(events)
+	handlers := s.colocationHandlers
+	s.mu.Unlock()
+
+	notifyColocationHandlers(handlers, events)
+	return events
+}
+
+// scheduleColocated places queued low-priority training jobs on inference GPUs with headroom
+func (s *Scheduler) scheduleColocated() []ColocationEvent {
+	events := make([]ColocationEvent, 0)
+	remaining := make([]*Workload, 0, len(s.workloadQueue))
+
+	for _, workload := range s.workloadQueue {
+		if !s.colocatable(workload) {
+			remaining = append(remaining, workload)
+			continue
+		}
+
+		gpu := s.findColocationGPU(workload)
+		if gpu == nil {
+			remaining = append(remaining, workload)
+			continue
+		}
+
+		now := time.Now()
+		workload.Status = WorkloadRunning
+		workload.AssignedGPU = gpu.ID
+		workload.StartedAt = &now
+		workload.PausedAt = nil
+		s.recordStart(workload, now)
+		gpu.ColocatedWorkload = workload
+		gpu.MemoryUsed += workload.MemoryRequired
+		s.chargePool(workload, false)
+		s.logWorkload(walColocated, workload, true)
+		reason := fmt.Sprintf("co-located with inference at %.0f%% utilization", gpu.Utilization)
+		s.emitLifecycle(LifecycleScheduled, gpu, workload, true, reason)
+		s.emitLifecycle(LifecycleStarted, gpu, workload, true, "")
+
+		events = append(events, ColocationEvent{
+			Type:        "colocated",
+			GPUID:       gpu.ID,
+			WorkloadID:  workload.ID,
+			Utilization: gpu.Utilization,
+			Timestamp:   now,
+		})
+	}
+
+	s.workloadQueue = remaining
+	return events
+}
+
+// colocatable reports whether a workload may share a GPU with inference
+func (s *Scheduler) colocatable(workload *Workload) bool {
+	config := s.config.Colocation
+	return config.Enabled &&
+		workload.Class == WorkloadClassTraining &&
+		workload.Priority <= config.MaxPriority
+}
+
+// findColocationGPU finds the least utilized inference GPU with headroom for the workload
+func (s *Scheduler) findColocationGPU(workload *Workload) *GPU {
+	config := s.config.Colocation
+	var bestGPU *GPU
+
+	for _, gpu := range s.gpus {
+		if !gpu.Available || gpu.ColocatedWorkload != nil {
+			continue
+		}
+		if gpu.CurrentWorkload == nil || gpu.CurrentWorkload.Class != WorkloadClassInference {
+			continue
+		}
+		if gpu.Pool != workload.Pool || gpu.Utilization >= config.MaxUtilization ||
+			!MatchesSelector(gpu.Labels, workload.Selector) || !schedulable(gpu, workload) ||
+			!s.withinQuota(workload, false) {
+			continue
+		}
+
+		memoryAfter := gpu.MemoryUsed + workload.MemoryRequired
+		if memoryAfter > gpu.MemoryTotal ||
+			float64(memoryAfter)/float64(gpu.MemoryTotal)*100 > config.MaxMemoryPercent {
+			continue
+		}
+
+		if bestGPU == nil || gpu.Utilization < bestGPU.Utilization {
+			bestGPU = gpu
+		}
+	}
+
+	return bestGPU
+}
+
+// reconcileGPU applies pause/resume/preempt thresholds to a GPU's co-located job
+func (s *Scheduler) reconcileGPU(gpu *GPU, now time.Time) []ColocationEvent {
+	workload := gpu.ColocatedWorkload
+	if workload == nil {
+		return nil
+	}
+
+	config := s.config.Colocation
+	event := ColocationEvent{GPUID: gpu.ID, WorkloadID: workload.ID, Utilization: gpu.Utilization, Timestamp: now}
+
+	switch workload.Status {
+	case WorkloadRunning:
+		if gpu.Utilization >= config.PauseUtilization {
+			workload.Status = WorkloadPaused
+			workload.PausedAt = &now
+			event.Type = "paused"
+		}
+	case WorkloadPaused:
+		if gpu.Utilization <= config.ResumeUtilization {
+			workload.Status = WorkloadRunning
+			workload.PausedAt = nil
+			event.Type = "resumed"
+		} else if config.PreemptAfter > 0 && workload.PausedAt != nil && now.Sub(*workload.PausedAt) >= config.PreemptAfter {
+			s.preemptColocated(gpu, fmt.Sprintf("paused for %s at %.0f%% utilization", config.PreemptAfter, gpu.Utilization))
+			event.Type = "preempted"
+		}
+	}
+
+	switch event.Type {
+	case "":
+		return nil
+	case "paused":
+		s.logWorkload(walPaused, workload, true)
+	case "resumed":
+		s.logWorkload(walResumed, workload, true)
+	}
+	return []ColocationEvent{event}
+}
+
+// preemptColocated evicts a GPU's co-located job for reason and returns it to the queue
+func (s *Scheduler) preemptColocated(gpu *GPU, reason string) {
+	workload := gpu.ColocatedWorkload
+	gpu.ColocatedWorkload = nil
+	releaseMemory(gpu, workload.MemoryRequired)
+	s.recordUsage(gpu, workload, time.Now(), WorkloadPending)
+
+	workload.Status = WorkloadPending
+	workload.AssignedGPU = ""
+	workload.QueuedAt = time.Now()
+	workload.StartedAt = nil
+	workload.PausedAt = nil
+	workload.Preemptions++
+	s.workloadQueue = append(s.workloadQueue, workload)
+	s.logWorkload(walPreempted, workload, false)
+	s.emitLifecycle(LifecyclePreempted, gpu, workload, true, reason)
+	s.wake()
+}
+
+// notifyColocationHandlers delivers events to registered handlers
+func notifyColocationHandlers(handlers []func(ColocationEvent), events []ColocationEvent) {
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+}
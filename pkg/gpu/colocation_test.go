@@ -0,0 +1,137 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+func newColocationScheduler(t *testing.T) *Scheduler {
+	config := DefaultSchedulerConfig()
+	config.Colocation.Enabled = true
+	config.Colocation.PreemptAfter = time.Hour
+	scheduler := NewSchedulerWithConfig(StrategyLeastUtilized, config)
+
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 40960, Available: true})
+	scheduler.SubmitWorkload(&Workload{ID: "serve", Class: WorkloadClassInference, Priority: 10, MemoryRequired: 16384})
+	if err := scheduler.Schedule(); err != nil {
+		t.Fatalf("Failed to schedule inference: %v", err)
+	}
+	return scheduler
+}
+
+func TestColocationPlacement(t *testing.T) {
+	scheduler := newColocationScheduler(t)
+	scheduler.UpdateGPUUtilization("gpu-0", 30)
+
+	events := make([]ColocationEvent, 0)
+	scheduler.OnColocationEvent(func(e ColocationEvent) {
+		events = append(events, e)
+	})
+
+	// Too much memory: 16384+20480 exceeds 80% of 40960
+	big := &Workload{ID: "train-big", Class: WorkloadClassTraining, MemoryRequired: 20480}
+	// Priority above the co-location limit
+	important := &Workload{ID: "train-important", Class: WorkloadClassTraining, Priority: 5, MemoryRequired: 4096}
+	small := &Workload{ID: "train-small", Class: WorkloadClassTraining, MemoryRequired: 8192}
+	for _, w := range []*Workload{big, important, small} {
+		scheduler.SubmitWorkload(w)
+	}
+	scheduler.Schedule()
+
+	gpu := scheduler.GetGPUStatus()[0]
+	if gpu.ColocatedWorkload != small || small.Status != WorkloadRunning {
+		t.Fatalf("Expected small training job to be co-located, got %+v", gpu.ColocatedWorkload)
+	}
+	if big.Status != WorkloadPending || important.Status != WorkloadPending {
+		t.Error("Ineligible training jobs should stay queued")
+	}
+	if gpu.MemoryUsed != 16384+8192 {
+		t.Errorf("Expected co-located memory to be reserved, got %d", gpu.MemoryUsed)
+	}
+	if len(events) != 1 || events[0].Type != "colocated" {
+		t.Errorf("Expected colocated event, got %+v", events)
+	}
+
+	// No co-location on a busy inference GPU
+	scheduler.CompleteWorkload("train-small")
+	scheduler.UpdateGPUUtilization("gpu-0", 60)
+	scheduler.SubmitWorkload(&Workload{ID: "train-later", Class: WorkloadClassTraining, MemoryRequired: 1024})
+	scheduler.Schedule()
+	if gpu.ColocatedWorkload != nil {
+		t.Error("Training should not be co-located above the utilization threshold")
+	}
+}
+
+func TestColocationPauseResumeAndPreempt(t *testing.T) {
+	scheduler := newColocationScheduler(t)
+	train := &Workload{ID: "train", Class: WorkloadClassTraining, MemoryRequired: 4096}
+	scheduler.SubmitWorkload(train)
+	scheduler.Schedule()
+
+	types := make([]string, 0)
+	scheduler.OnColocationEvent(func(e ColocationEvent) {
+		types = append(types, e.Type)
+	})
+
+	scheduler.UpdateGPUUtilization("gpu-0", 90)
+	if train.Status != WorkloadPaused {
+		t.Fatalf("Expected training to pause when inference load rises, got %s", train.Status)
+	}
+
+	// Between resume and pause thresholds the job stays paused
+	scheduler.UpdateGPUUtilization("gpu-0", 60)
+	if train.Status != WorkloadPaused {
+		t.Error("Expected hysteresis between pause and resume thresholds")
+	}
+
+	scheduler.UpdateGPUUtilization("gpu-0", 20)
+	if train.Status != WorkloadRunning {
+		t.Fatalf("Expected training to resume, got %s", train.Status)
+	}
+
+	scheduler.UpdateGPUUtilization("gpu-0", 90)
+	paused := time.Now().Add(-2 * time.Hour)
+	train.PausedAt = &paused
+	scheduler.ReconcileColocation()
+	if train.Status != WorkloadPending || train.Preemptions != 1 {
+		t.Errorf("Expected long-paused training to be preempted, got %s", train.Status)
+	}
+	if scheduler.GetGPUStatus()[0].MemoryUsed != 16384 {
+		t.Error("Expected preemption to free co-located memory")
+	}
+
+	expected := []string{"paused", "resumed", "paused", "preempted"}
+	if len(types) != len(expected) {
+		t.Fatalf("Expected events %v, got %v", expected, types)
+	}
+	for i := range expected {
+		if types[i] != expected[i] {
+			t.Errorf("Expected events %v, got %v", expected, types)
+			break
+		}
+	}
+}
+
+func TestColocatedWorkloadPromotedWhenInferenceCompletes(t *testing.T) {
+	scheduler := newColocationScheduler(t)
+	train := &Workload{ID: "train", Class: WorkloadClassTraining, MemoryRequired: 4096}
+	scheduler.SubmitWorkload(train)
+	scheduler.Schedule()
+
+	if err := scheduler.CompleteWorkload("serve"); err != nil {
+		t.Fatalf("Failed to complete inference: %v", err)
+	}
+
+	gpu := scheduler.GetGPUStatus()[0]
+	if gpu.CurrentWorkload != train || gpu.ColocatedWorkload != nil {
+		t.Error("Expected co-located training job to take over the GPU")
+	}
+	if gpu.MemoryUsed != 4096 {
+		t.Errorf("Expected only training memory in use, got %d", gpu.MemoryUsed)
+	}
+
+	metrics := scheduler.GetUtilizationMetrics()
+	if metrics["colocated_workloads"].(int) != 0 {
+		t.Error("Expected no co-located workloads after promotion")
+	}
+}
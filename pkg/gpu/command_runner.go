@@ -0,0 +1,269 @@
+package gpu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCommandTimeout marks a command killed after its per-attempt timeout
+var ErrCommandTimeout = errors.New("command timed out")
+
+// CommandRunnerConfig bounds how external commands such as nvidia-smi run
+type CommandRunnerConfig struct {
+	Timeout       time.Duration `yaml:"timeout" json:"timeout"`               // Per attempt
+	MaxConcurrent int           `yaml:"max_concurrent" json:"max_concurrent"` // Commands running at once
+
+	// Failed attempts are retried up to Retries times, waiting Backoff and
+	// doubling up to MaxBackoff. Missing binaries and canceled contexts are not retried.
+	Retries    int           `yaml:"retries" json:"retries"`
+	Backoff    time.Duration `yaml:"backoff" json:"backoff"`
+	MaxBackoff time.Duration `yaml:"max_backoff" json:"max_backoff"`
+
+	// Successful output of cacheable commands is reused for CacheTTL, and
+	// identical cacheable commands running at once share one invocation.
+	// Keep it below the collection interval.
+	CacheTTL time.Duration `yaml:"cache_ttl" json:"cache_ttl"`
+}
+
+// DefaultCommandRunnerConfig returns the default command limits
+func DefaultCommandRunnerConfig() CommandRunnerConfig {
+	return CommandRunnerConfig{
+		Timeout:       10 * time.Second,
+		MaxConcurrent: 4,
+		Retries:       2,
+		Backoff:       200 * time.Millisecond,
+		MaxBackoff:    2 * time.Second,
+		CacheTTL:      time.Second,
+	}
+}
+
+// CommandSpec describes a command to run
+type CommandSpec struct {
+	Name     string
+	Args     []string
+	Env      []string // Inherits the process environment when empty
+	Combined bool     // Return stderr along with stdout
+	Stdin    string   // Written to the command's standard input, e.g. MPS control commands
+
+	// Cacheable marks read-only queries whose output may be shared
+	Cacheable bool
+}
+
+// key identifies identical commands
+func (spec CommandSpec) key() string {
+	return strings.Join([]string{spec.Name, strings.Join(spec.Args, "\x00"), strings.Join(spec.Env, "\x00"), fmt.Sprint(spec.Combined), spec.Stdin}, "\x01")
+}
+
+// cachedOutput is the result of a cacheable command, complete once done is closed
+type cachedOutput struct {
+	done     chan struct{}
+	output   []byte
+	err      error
+	finished time.Time
+}
+
+// CommandRunner runs external commands with a timeout per attempt, a bound
+// on concurrent commands and retry with backoff, so one hung nvidia-smi
+// cannot stall every caller
+type CommandRunner struct {
+	config CommandRunnerConfig
+	sem    chan struct{}
+	exec   func(ctx context.Context, spec CommandSpec) ([]byte, error) // Replaced in tests
+	cache  map[string]*cachedOutput
+
+	runs      int64
+	retries   int64
+	timeouts  int64
+	failures  int64
+	cacheHits int64
+	mu        sync.Mutex
+}
+
+// NewCommandRunner creates a command runner; zero config fields use the defaults
+func NewCommandRunner(config CommandRunnerConfig) *CommandRunner {
+	defaults := DefaultCommandRunnerConfig()
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = defaults.MaxConcurrent
+	}
+	if config.Retries < 0 {
+		config.Retries = 0
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = defaults.Backoff
+	}
+	if config.MaxBackoff < config.Backoff {
+		config.MaxBackoff = config.Backoff
+	}
+	if config.CacheTTL < 0 {
+		config.CacheTTL = 0
+	}
+	return &CommandRunner{
+		config: config,
+		sem:    make(chan struct{}, config.MaxConcurrent),
+		exec:   execCommand,
+		cache:  make(map[string]*cachedOutput),
+	}
+}
+
+// defaultCommandRunner is shared so the concurrency bound covers the whole process
+var defaultCommandRunner = NewCommandRunner(DefaultCommandRunnerConfig())
+
+// DefaultCommandRunner returns the process-wide command runner used by the
+// GPU collectors and the Kubernetes GPU monitor
+func DefaultCommandRunner() *CommandRunner {
+	return defaultCommandRunner
+}
+
+// execCommand runs a command until it exits or ctx is done
+func execCommand(ctx context.Context, spec CommandSpec) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, spec.Name, spec.Args...)
+	if len(spec.Env) > 0 {
+		cmd.Env = spec.Env
+	}
+	if spec.Stdin != "" {
+		cmd.Stdin = strings.NewReader(spec.Stdin)
+	}
+	if spec.Combined {
+		return cmd.CombinedOutput()
+	}
+	return cmd.Output()
+}
+
+// Output runs a command with the process environment and returns its stdout
+func (r *CommandRunner) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return r.Run(ctx, CommandSpec{Name: name, Args: args})
+}
+
+// Run runs a command, retrying failed attempts. The error of the last
+// attempt is returned, wrapping ErrCommandTimeout when it timed out.
+// Cacheable commands may return a recent or in-flight identical command's output.
+func (r *CommandRunner) Run(ctx context.Context, spec CommandSpec) ([]byte, error) {
+	if !spec.Cacheable || r.config.CacheTTL == 0 {
+		return r.run(ctx, spec)
+	}
+
+	key := spec.key()
+	r.mu.Lock()
+	if entry, exists := r.cache[key]; exists {
+		select {
+		case <-entry.done:
+			if time.Since(entry.finished) < r.config.CacheTTL {
+				r.cacheHits++
+				r.mu.Unlock()
+				return entry.output, nil
+			}
+		default:
+			// An identical command is running; wait for its output
+			r.cacheHits++
+			r.mu.Unlock()
+			select {
+			case <-entry.done:
+				return entry.output, entry.err
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+	entry := &cachedOutput{done: make(chan struct{})}
+	r.cache[key] = entry
+	r.mu.Unlock()
+
+	entry.output, entry.err = r.run(ctx, spec)
+	entry.finished = time.Now()
+	close(entry.done)
+
+	if entry.err != nil {
+		// Failures are shared with waiting callers but not cached
+		r.mu.Lock()
+		if r.cache[key] == entry {
+			delete(r.cache, key)
+		}
+		r.mu.Unlock()
+	}
+	return entry.output, entry.err
+}
+
+// run runs a command with retries, bypassing the cache
+func (r *CommandRunner) run(ctx context.Context, spec CommandSpec) ([]byte, error) {
+	backoff := r.config.Backoff
+	for attempt := 0; ; attempt++ {
+		output, err := r.attempt(ctx, spec)
+		if err == nil || attempt >= r.config.Retries || !retryable(ctx, err) {
+			if err != nil {
+				r.count(&r.failures)
+			}
+			return output, err
+		}
+
+		r.count(&r.retries)
+		select {
+		case <-ctx.Done():
+			return output, err
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > r.config.MaxBackoff {
+			backoff = r.config.MaxBackoff
+		}
+	}
+}
+
+// attempt runs a command once, waiting for a concurrency slot first
+func (r *CommandRunner) attempt(ctx context.Context, spec CommandSpec) ([]byte, error) {
+	select {
+	case r.sem <- struct{}{}:
+		defer func() { <-r.sem }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	r.count(&r.runs)
+
+	attemptCtx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	defer cancel()
+
+	output, err := r.exec(attemptCtx, spec)
+	if err != nil && ctx.Err() == nil && attemptCtx.Err() == context.DeadlineExceeded {
+		r.count(&r.timeouts)
+		return output, fmt.Errorf("%s: %w after %v", spec.Name, ErrCommandTimeout, r.config.Timeout)
+	}
+	return output, err
+}
+
+// retryable reports whether a failed attempt may succeed when repeated
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return !errors.Is(err, exec.ErrNotFound) && !errors.Is(err, context.Canceled)
+}
+
+// count increments one of the runner's counters
+func (r *CommandRunner) count(counter *int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*counter++
+}
+
+// GetStats returns command runner statistics
+func (r *CommandRunner) GetStats() map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return map[string]interface{}{
+		"timeout":        r.config.Timeout.String(),
+		"max_concurrent": r.config.MaxConcurrent,
+		"running":        len(r.sem),
+		"attempts":       r.runs,
+		"retries":        r.retries,
+		"timeouts":       r.timeouts,
+		"failures":       r.failures,
+		"cache_hits":     r.cacheHits,
+	}
+}
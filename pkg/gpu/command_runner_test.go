@@ -0,0 +1,177 @@
+package gpu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCommandRunnerRetriesWithBackoff(t *testing.T) {
+	runner := NewCommandRunner(CommandRunnerConfig{Retries: 2, Backoff: time.Millisecond})
+	attempts := 0
+	runner.exec = func(ctx context.Context, spec CommandSpec) ([]byte, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, fmt.Errorf("exit status 15")
+		}
+		return []byte("0\n"), nil
+	}
+
+	output, err := runner.Output(context.Background(), "nvidia-smi", "--query-gpu=index")
+	if err != nil || string(output) != "0\n" {
+		t.Fatalf("Output = %q, %v", output, err)
+	}
+	stats := runner.GetStats()
+	if stats["attempts"] != int64(3) || stats["retries"] != int64(2) || stats["failures"] != int64(0) {
+		t.Errorf("stats = %v", stats)
+	}
+}
+
+func TestCommandRunnerDoesNotRetryMissingBinary(t *testing.T) {
+	runner := NewCommandRunner(CommandRunnerConfig{Retries: 3, Backoff: time.Millisecond})
+	attempts := 0
+	runner.exec = func(ctx context.Context, spec CommandSpec) ([]byte, error) {
+		attempts++
+		return nil, &exec.Error{Name: spec.Name, Err: exec.ErrNotFound}
+	}
+
+	_, err := runner.Output(context.Background(), "nvidia-smi")
+	if attempts != 1 || collectionErrorReason(err) != CollectionErrorNotFound {
+		t.Errorf("attempts = %d, reason = %s", attempts, collectionErrorReason(err))
+	}
+}
+
+func TestCommandRunnerTimesOutHungCommand(t *testing.T) {
+	runner := NewCommandRunner(CommandRunnerConfig{Timeout: 20 * time.Millisecond, Retries: 1, Backoff: time.Millisecond})
+	runner.exec = func(ctx context.Context, spec CommandSpec) ([]byte, error) {
+		<-ctx.Done()
+		return nil, errors.New("signal: killed")
+	}
+
+	start := time.Now()
+	_, err := runner.Output(context.Background(), "nvidia-smi")
+	if !errors.Is(err, ErrCommandTimeout) || collectionErrorReason(err) != CollectionErrorTimeout {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("hung command held the caller for %v", elapsed)
+	}
+	if stats := runner.GetStats(); stats["timeouts"] != int64(2) {
+		t.Errorf("timeouts = %v, want both attempts", stats["timeouts"])
+	}
+}
+
+func TestCommandRunnerBoundsConcurrency(t *testing.T) {
+	runner := NewCommandRunner(CommandRunnerConfig{MaxConcurrent: 2})
+	var mu sync.Mutex
+	running, peak := 0, 0
+	runner.exec = func(ctx context.Context, spec CommandSpec) ([]byte, error) {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runner.Output(context.Background(), "nvidia-smi")
+		}()
+	}
+	wg.Wait()
+	if peak != 2 {
+		t.Errorf("peak concurrency = %d, want 2", peak)
+	}
+}
+
+func TestCommandRunnerStopsOnCancel(t *testing.T) {
+	runner := NewCommandRunner(CommandRunnerConfig{Retries: 5, Backoff: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	runner.exec = func(ctx context.Context, spec CommandSpec) ([]byte, error) {
+		cancel()
+		return nil, fmt.Errorf("exit status 1")
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := runner.Output(ctx, "nvidia-smi")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected the failed attempt's error")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("canceled run kept retrying")
+	}
+}
+
+func TestCommandRunnerCachesIdenticalQueries(t *testing.T) {
+	runner := NewCommandRunner(CommandRunnerConfig{CacheTTL: 50 * time.Millisecond, Retries: -1})
+	var mu sync.Mutex
+	runs := 0
+	fail := false
+	runner.exec = func(ctx context.Context, spec CommandSpec) ([]byte, error) {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		if fail {
+			return nil, fmt.Errorf("exit status 1")
+		}
+		return []byte(spec.Args[0]), nil
+	}
+	query := CommandSpec{Name: "nvidia-smi", Args: []string{"--query-gpu=index"}, Cacheable: true}
+
+	// Identical queries running at once share one invocation
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if output, err := runner.Run(context.Background(), query); err != nil || string(output) != "--query-gpu=index" {
+				t.Errorf("Run = %q, %v", output, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if runs != 1 {
+		t.Fatalf("concurrent identical queries ran %d times", runs)
+	}
+
+	// A recent result is reused; other queries and uncacheable commands are not
+	runner.Run(context.Background(), query)
+	runner.Run(context.Background(), CommandSpec{Name: "nvidia-smi", Args: []string{"--query-gpu=name"}, Cacheable: true})
+	runner.Run(context.Background(), CommandSpec{Name: "nvidia-smi", Args: []string{"--query-gpu=index"}})
+	if runs != 3 {
+		t.Errorf("runs = %d, want the cached query reused", runs)
+	}
+
+	// Expired results are refreshed and failures are not cached
+	time.Sleep(60 * time.Millisecond)
+	fail = true
+	if _, err := runner.Run(context.Background(), query); err == nil {
+		t.Fatal("expected the refreshed query to fail")
+	}
+	fail = false
+	if _, err := runner.Run(context.Background(), query); err != nil {
+		t.Errorf("failure was cached: %v", err)
+	}
+	if stats := runner.GetStats(); stats["cache_hits"] != int64(5) {
+		t.Errorf("cache hits = %v", stats["cache_hits"])
+	}
+}
@@ -0,0 +1,43 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	after := time.Date(2026, 3, 13, 22, 47, 30, 0, time.UTC) // A Friday
+	tests := []struct {
+		expression string
+		want       time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 13, 22, 48, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 13, 23, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2026, 3, 14, 2, 30, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, 3, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Either restricted day field matches
+		{"0 0 1 * 6", time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		schedule, err := ParseCronSchedule(tt.expression)
+		if err != nil {
+			t.Fatalf("ParseCronSchedule(%q): %v", tt.expression, err)
+		}
+		if got := schedule.Next(after); !got.Equal(tt.want) {
+			t.Errorf("%q: Next = %v, want %v", tt.expression, got, tt.want)
+		}
+	}
+}
+
+func TestParseCronScheduleRejectsInvalidExpressions(t *testing.T) {
+	for _, expression := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@sometimes"} {
+		if _, err := ParseCronSchedule(expression); err == nil {
+			t.Errorf("ParseCronSchedule(%q) succeeded", expression)
+		}
+	}
+}
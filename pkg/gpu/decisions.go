@@ -0,0 +1,54 @@
+package gpu
+
+import "time"
+
+// maxSchedulingDecisions bounds the scheduler's decision log
+const maxSchedulingDecisions = 1000
+
+// SchedulingDecision records a placement made by the scheduler
+type SchedulingDecision struct {
+	WorkloadID string             `json:"workload_id"`
+	GPUID      string             `json:"gpu_id"`
+	Strategy   SchedulingStrategy `json:"strategy"`
+	Action     string             `json:"action"` // assigned, evicted, or a colocation event type
+	Reason     string             `json:"reason"`
+	Timestamp  time.Time          `json:"timestamp"`
+}
+
+// GetDecisions returns scheduling decisions made at or after since, oldest first
+func (s *Scheduler) GetDecisions(since time.Time) []SchedulingDecision {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	decisions := make([]SchedulingDecision, 0)
+	for _, decision := range s.decisions {
+		if !decision.Timestamp.Before(since) {
+			decisions = append(decisions, decision)
+		}
+	}
+	return decisions
+}
+
+// recordDecision appends to the decision log; callers must hold the lock
+func (s *Scheduler) recordDecision(decision SchedulingDecision) {
+	if decision.Strategy == "" {
+		decision.Strategy = s.strategy
+	}
+	s.decisions = append(s.decisions, decision)
+	if len(s.decisions) > maxSchedulingDecisions {
+		s.decisions = s.decisions[len(s.decisions)-maxSchedulingDecisions:]
+	}
+}
+
+// recordColocationDecisions logs colocation events as decisions; callers must hold the lock
+func (s *Scheduler) recordColocationDecisions(events []ColocationEvent) {
+	for _, event := range events {
+		s.recordDecision(SchedulingDecision{
+			WorkloadID: event.WorkloadID,
+			GPUID:      event.GPUID,
+			Action:     event.Type,
+			Reason:     "colocation",
+			Timestamp:  event.Timestamp,
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedulerRecordsDecisions(t *testing.T) {
+	scheduler := NewScheduler(StrategyBestFit)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+	scheduler.SubmitWorkload(&Workload{ID: "job-1", MemoryRequired: 8000})
+	scheduler.SubmitWorkload(&Workload{ID: "too-big", MemoryRequired: 32000})
+
+	before := time.Now()
+	if err := scheduler.Schedule(); err != nil {
+		t.Fatalf("Failed to schedule: %v", err)
+	}
+
+	decisions := scheduler.GetDecisions(before)
+	if len(decisions) != 1 {
+		t.Fatalf("Expected 1 decision, got %+v", decisions)
+	}
+	decision := decisions[0]
+	if decision.WorkloadID != "job-1" || decision.GPUID != "gpu-0" || decision.Action != "assigned" || decision.Strategy != StrategyBestFit {
+		t.Errorf("Unexpected decision %+v", decision)
+	}
+	if decision.Reason != "8000 MB required, 16000 MB free" {
+		t.Errorf("Unexpected reason %q", decision.Reason)
+	}
+
+	if later := scheduler.GetDecisions(time.Now().Add(time.Second)); len(later) != 0 {
+		t.Errorf("Expected no decisions after now, got %d", len(later))
+	}
+}
@@ -0,0 +1,71 @@
+package gpu
+
+import (
+	"math"
+	"sort"
+)
+
+// Distribution summarizes how a per-GPU value is spread across the cluster.
+// Averages hide skew; percentiles and the Gini coefficient expose it.
+type Distribution struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+	Gini  float64 `json:"gini"` // 0 when evenly spread, approaching 1 when concentrated on few GPUs
+}
+
+// NewDistribution computes the distribution of per-GPU values
+func NewDistribution(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, value := range sorted {
+		sum += value
+	}
+
+	return Distribution{
+		Count: len(sorted),
+		Mean:  sum / float64(len(sorted)),
+		Min:   sorted[0],
+		Max:   sorted[len(sorted)-1],
+		P50:   percentile(sorted, 0.50),
+		P90:   percentile(sorted, 0.90),
+		P99:   percentile(sorted, 0.99),
+		Gini:  gini(sorted, sum),
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	index := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index]
+}
+
+// gini returns the Gini coefficient of sorted non-negative values
+func gini(sorted []float64, sum float64) float64 {
+	n := float64(len(sorted))
+	if sum <= 0 || n < 2 {
+		return 0
+	}
+
+	weighted := 0.0
+	for i, value := range sorted {
+		weighted += float64(i+1) * value
+	}
+	return 2*weighted/(n*sum) - (n+1)/n
+}
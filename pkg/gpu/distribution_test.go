@@ -0,0 +1,35 @@
+package gpu
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewDistribution(t *testing.T) {
+	even := NewDistribution([]float64{50, 50, 50, 50})
+	if even.Gini != 0 || even.P50 != 50 || even.P99 != 50 {
+		t.Errorf("Expected an even spread, got %+v", even)
+	}
+
+	// One busy GPU and nine idle ones average 10% but are badly skewed
+	skewed := NewDistribution([]float64{100, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+	if skewed.Mean != 10 || skewed.P50 != 0 || skewed.P90 != 0 || skewed.P99 != 100 || skewed.Max != 100 {
+		t.Errorf("Unexpected skewed distribution %+v", skewed)
+	}
+	if math.Abs(skewed.Gini-0.9) > 1e-9 {
+		t.Errorf("Expected Gini 0.9, got %f", skewed.Gini)
+	}
+
+	values := []float64{30, 10, 20}
+	spread := NewDistribution(values)
+	if spread.Min != 10 || spread.P50 != 20 || spread.Count != 3 {
+		t.Errorf("Unexpected distribution %+v", spread)
+	}
+	if values[0] != 30 {
+		t.Error("Expected the input slice to be left unsorted")
+	}
+
+	if empty := NewDistribution(nil); empty != (Distribution{}) {
+		t.Errorf("Expected a zero distribution, got %+v", empty)
+	}
+}
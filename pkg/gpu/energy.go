@@ -0,0 +1,197 @@
+package gpu
+
+// EnergyConfig tunes the power model behind the energy-aware strategy
+type EnergyConfig struct {
+	// Power drawn by a busy GPU whose power limit is not yet known
+	DefaultGPUWatts float64 `yaml:"default_gpu_watts" json:"default_gpu_watts"`
+	// Host power saved while a node has no busy GPUs and can sit in a low-power state
+	NodeWakeWatts float64 `yaml:"node_wake_watts" json:"node_wake_watts"`
+}
+
+// DefaultEnergyConfig returns a power model for typical datacenter GPUs
+func DefaultEnergyConfig() EnergyConfig {
+	return EnergyConfig{
+		DefaultGPUWatts: 300,
+		NodeWakeWatts:   200,
+	}
+}
+
+// EnergyReport compares the estimated power of energy-aware placements with
+// what the least-utilized strategy would have chosen for the same workloads
+type EnergyReport struct {
+	Placements             int     `json:"placements"`
+	EnergyAwareWatts       float64 `json:"energy_aware_watts"`
+	LeastUtilizedWatts     float64 `json:"least_utilized_watts"`
+	SavedWatts             float64 `json:"saved_watts"`
+	SavedPercent           float64 `json:"saved_percent"`
+	EnergyAwareWattHours   float64 `json:"energy_aware_watt_hours"` // For workloads with an estimated duration
+	LeastUtilizedWattHours float64 `json:"least_utilized_watt_hours"`
+	EnergyAwareNodeWakes   int     `json:"energy_aware_node_wakes"`
+	LeastUtilizedNodeWakes int     `json:"least_utilized_node_wakes"`
+}
+
+// ObserveMetrics updates a registered GPU's utilization, power, temperature,
+// encoder and decoder load and node from collected metrics; metrics for unknown GPUs are ignored.
+// Register it with a collector to feed energy- and temperature-aware placement.
+func (s *Scheduler) ObserveMetrics(metrics GPUMetrics) {
+	s.mu.Lock()
+	gpu, exists := s.gpus[metrics.GPUID]
+	if !exists {
+		s.mu.Unlock()
+		return
+	}
+	gpu.PowerUsage = metrics.PowerDraw
+	gpu.PowerLimit = metrics.PowerLimit
+	gpu.Temperature = metrics.Temperature
+	gpu.ClockGraphics = metrics.ClockGraphics
+	gpu.ClockMemory = metrics.ClockMemory
+	gpu.EncoderSessions = metrics.EncoderSessions
+	gpu.DecoderSessions = metrics.DecoderSessions
+	gpu.EncoderUtilization = metrics.EncoderUtilization
+	gpu.DecoderUtilization = metrics.DecoderUtilization
+	gpu.LastMetricsUpdate = metrics.Timestamp
+	s.observeTemperature(gpu, metrics.Temperature)
+	if metrics.NodeID != "" {
+		gpu.Node = metrics.NodeID
+	}
+	s.mu.Unlock()
+
+	s.UpdateGPUUtilization(metrics.GPUID, metrics.UtilizationGPU)
+}
+
+// gpuNode returns the node a GPU is installed in
+func gpuNode(gpu *GPU) string {
+	if gpu.Node == "" {
+		return DefaultNodeID
+	}
+	return gpu.Node
+}
+
+// busyNodes counts the busy GPUs on each node; callers must hold the lock
+func (s *Scheduler) busyNodes() map[string]int {
+	busy := make(map[string]int)
+	for _, gpu := range s.gpus {
+		if gpu.CurrentWorkload != nil {
+			busy[gpuNode(gpu)]++
+		}
+	}
+	return busy
+}
+
+// expectedUtilization is the share of a GPU a workload is expected to use,
+// from its learned profile or 100% when nothing is known; callers must hold the lock
+func (s *Scheduler) expectedUtilization(workload *Workload) float64 {
+	profile, exists := s.profiles[workload.Name]
+	if workload.Name == "" || !exists || profile.Runs < s.config.Profiles.MinRuns || profile.UtilizationRuns == 0 {
+		return 100
+	}
+	return profile.AvgUtilization
+}
+
+// marginalWatts estimates the extra power drawn by running a workload on an
+// idle GPU: the GPU rising from its idle draw towards its limit, plus waking
+// the GPU's node when nothing else runs there. Callers must hold the lock.
+func (s *Scheduler) marginalWatts(gpu *GPU, workload *Workload, busy map[string]int) (float64, bool) {
+	limit := gpu.PowerLimit
+	if limit <= 0 {
+		limit = s.config.Energy.DefaultGPUWatts
+	}
+	watts := (limit - gpu.PowerUsage) * s.expectedUtilization(workload) / 100
+	if watts < 0 {
+		watts = 0
+	}
+	wakes := busy[gpuNode(gpu)] == 0
+	if wakes {
+		watts += s.config.Energy.NodeWakeWatts
+	}
+	return watts, wakes
+}
+
+// findEnergyAwareGPU finds the GPU that adds the least power, preferring
+// nodes that are already busy so idle nodes can stay in low-power states;
+// callers must hold the lock
+func (s *Scheduler) findEnergyAwareGPU(workload *Workload, busy map[string]int) *GPU {
+	var bestGPU *GPU
+	bestWatts := 0.0
+	bestAvoided := false
+
+	for _, gpu := range s.gpus {
+		if !s.canAssign(gpu, workload) {
+			continue
+		}
+		watts, _ := s.marginalWatts(gpu, workload, busy)
+		avoided := s.avoids(gpu, workload)
+		if bestGPU == nil || (bestAvoided && !avoided) {
+			bestGPU, bestWatts, bestAvoided = gpu, watts, avoided
+			continue
+		}
+		if avoided != bestAvoided {
+			continue
+		}
+		// Among equal power, pack the busiest node first, then keep placements deterministic
+		if watts < bestWatts || (watts == bestWatts && (busy[gpuNode(gpu)] > busy[gpuNode(bestGPU)] ||
+			(busy[gpuNode(gpu)] == busy[gpuNode(bestGPU)] && gpu.ID < bestGPU.ID))) {
+			bestGPU, bestWatts = gpu, watts
+		}
+	}
+
+	return bestGPU
+}
+
+// scheduleEnergyAware places each workload where it adds the least power and
+// records how that compares with a least-utilized placement
+func (s *Scheduler) scheduleEnergyAware() error {
+	remaining := make([]*Workload, 0)
+
+	for i, workload := range s.workloadQueue {
+		if s.idleGPUs == 0 {
+			remaining = append(remaining, s.workloadQueue[i:]...)
+			break
+		}
+		busy := s.busyNodes()
+		gpu := s.findEnergyAwareGPU(workload, busy)
+		if gpu == nil {
+			remaining = append(remaining, workload)
+			continue
+		}
+		s.compareEnergy(gpu, s.findLeastUtilizedGPU(workload), workload, busy)
+		s.assignWorkload(gpu, workload)
+	}
+
+	s.workloadQueue = remaining
+	return nil
+}
+
+// compareEnergy adds a placement to the energy report; callers must hold the lock
+func (s *Scheduler) compareEnergy(chosen, leastUtilized *GPU, workload *Workload, busy map[string]int) {
+	watts, wakes := s.marginalWatts(chosen, workload, busy)
+	baseline, baselineWakes := s.marginalWatts(leastUtilized, workload, busy)
+	hours := workload.EstimatedTime.Hours()
+
+	report := &s.energyReport
+	report.Placements++
+	report.EnergyAwareWatts += watts
+	report.LeastUtilizedWatts += baseline
+	report.EnergyAwareWattHours += watts * hours
+	report.LeastUtilizedWattHours += baseline * hours
+	if wakes {
+		report.EnergyAwareNodeWakes++
+	}
+	if baselineWakes {
+		report.LeastUtilizedNodeWakes++
+	}
+}
+
+// GetEnergyReport returns the estimated power of every energy-aware
+// placement so far against the least-utilized strategy's choices
+func (s *Scheduler) GetEnergyReport() EnergyReport {
+	s.mu.RLock()
+	report := s.energyReport
+	s.mu.RUnlock()
+
+	report.SavedWatts = report.LeastUtilizedWatts - report.EnergyAwareWatts
+	if report.LeastUtilizedWatts > 0 {
+		report.SavedPercent = report.SavedWatts / report.LeastUtilizedWatts * 100
+	}
+	return report
+}
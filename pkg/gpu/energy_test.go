@@ -0,0 +1,70 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEnergyAwareConsolidatesOntoBusyNodes(t *testing.T) {
+	scheduler := NewScheduler(StrategyEnergyAware)
+	for _, gpu := range []*GPU{
+		{ID: "a0", Node: "node-a", MemoryTotal: 16384, Available: true, PowerLimit: 300, PowerUsage: 60},
+		{ID: "a1", Node: "node-a", MemoryTotal: 16384, Available: true, PowerLimit: 300, PowerUsage: 60, Utilization: 10},
+		{ID: "b0", Node: "node-b", MemoryTotal: 16384, Available: true, PowerLimit: 300, PowerUsage: 60},
+		{ID: "b1", Node: "node-b", MemoryTotal: 16384, Available: true, PowerLimit: 300, PowerUsage: 60},
+	} {
+		if err := scheduler.RegisterGPU(gpu); err != nil {
+			t.Fatalf("RegisterGPU failed: %v", err)
+		}
+	}
+	scheduler.gpus["a0"].CurrentWorkload = &Workload{ID: "running", Status: WorkloadRunning, AssignedGPU: "a0"}
+
+	scheduler.SubmitWorkload(&Workload{ID: "job", MemoryRequired: 1024, EstimatedTime: 2 * time.Hour})
+	if err := scheduler.Schedule(); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+	if scheduler.gpus["a1"].CurrentWorkload == nil {
+		t.Fatal("Expected the workload on the already busy node")
+	}
+
+	report := scheduler.GetEnergyReport()
+	if report.Placements != 1 || report.EnergyAwareWatts != 240 || report.LeastUtilizedWatts != 440 {
+		t.Errorf("Expected 240 W against 440 W for one placement, got %+v", report)
+	}
+	if report.EnergyAwareNodeWakes != 0 || report.LeastUtilizedNodeWakes != 1 {
+		t.Errorf("Expected least-utilized to wake node-b, got %+v", report)
+	}
+	if report.EnergyAwareWattHours != 480 || report.SavedWatts != 200 {
+		t.Errorf("Expected 480 Wh and 200 W saved, got %+v", report)
+	}
+}
+
+func TestEnergyAwarePrefersEfficientGPUs(t *testing.T) {
+	scheduler := NewScheduler(StrategyEnergyAware)
+	scheduler.RegisterGPU(&GPU{ID: "big", MemoryTotal: 81920, Available: true, PowerLimit: 700, PowerUsage: 90})
+	scheduler.RegisterGPU(&GPU{ID: "small", MemoryTotal: 24576, Available: true, PowerLimit: 250, PowerUsage: 30})
+
+	scheduler.SubmitWorkload(&Workload{ID: "fits-both", MemoryRequired: 8192})
+	scheduler.SubmitWorkload(&Workload{ID: "needs-big", MemoryRequired: 40960})
+	scheduler.Schedule()
+
+	if current := scheduler.gpus["small"].CurrentWorkload; current == nil || current.ID != "fits-both" {
+		t.Errorf("Expected the lower-power GPU to run fits-both, got %+v", current)
+	}
+	if current := scheduler.gpus["big"].CurrentWorkload; current == nil || current.ID != "needs-big" {
+		t.Errorf("Expected the large GPU to run needs-big, got %+v", current)
+	}
+}
+
+func TestObserveMetricsUpdatesPowerAndNode(t *testing.T) {
+	scheduler := NewScheduler(StrategyEnergyAware)
+	scheduler.RegisterGPU(&GPU{ID: "0", MemoryTotal: 16384, Available: true})
+
+	scheduler.ObserveMetrics(GPUMetrics{GPUID: "0", NodeID: "host-1", PowerDraw: 75, PowerLimit: 300, UtilizationGPU: 12, Temperature: 41})
+	scheduler.ObserveMetrics(GPUMetrics{GPUID: "unknown", PowerDraw: 75})
+
+	gpu := scheduler.gpus["0"]
+	if gpu.Node != "host-1" || gpu.PowerUsage != 75 || gpu.PowerLimit != 300 || gpu.Utilization != 12 || gpu.Temperature != 41 {
+		t.Errorf("Expected metrics applied to the GPU, got %+v", gpu)
+	}
+}
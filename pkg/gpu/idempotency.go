@@ -0,0 +1,113 @@
+package gpu
+
+import (
+	"fmt"
+	"time"
+)
+
+// IdempotencyConfig controls how long submissions are remembered by
+// idempotency key so client retries do not create duplicate workloads
+type IdempotencyConfig struct {
+	Window        time.Duration            `yaml:"window" json:"window"`
+	ClientWindows map[string]time.Duration `yaml:"client_windows" json:"client_windows"` // Per-client overrides of Window
+}
+
+// DefaultIdempotencyConfig returns a deduplication window covering typical client retry policies
+func DefaultIdempotencyConfig() IdempotencyConfig {
+	return IdempotencyConfig{
+		Window:        10 * time.Minute,
+		ClientWindows: make(map[string]time.Duration),
+	}
+}
+
+// submission is a workload remembered by its client's idempotency key
+type submission struct {
+	key      string
+	workload *Workload
+	expires  time.Time
+}
+
+// submissionKey scopes an idempotency key to the client that sent it
+func submissionKey(workload *Workload) string {
+	return workload.ClientID + "\x00" + workload.IdempotencyKey
+}
+
+// dedupWindow returns how long a client's submissions are remembered
+func (c IdempotencyConfig) dedupWindow(clientID string) time.Duration {
+	if window, exists := c.ClientWindows[clientID]; exists {
+		return window
+	}
+	return c.Window
+}
+
+// sameRequest reports whether a retry describes the workload originally submitted
+func sameRequest(original, retry *Workload) bool {
+	return original.Name == retry.Name &&
+		original.Priority == retry.Priority &&
+		original.Class == retry.Class &&
+		original.MemoryRequired == retry.MemoryRequired &&
+		original.EstimatedTime == retry.EstimatedTime
+}
+
+// deduplicate reports whether a workload retries a submission still inside
+// its client's window. A retry is updated to describe the original workload.
+// Callers must hold the lock.
+func (s *Scheduler) deduplicate(workload *Workload, now time.Time) (bool, error) {
+	if workload.IdempotencyKey == "" {
+		return false, nil
+	}
+	s.expireSubmissions(now)
+
+	entry, exists := s.submissions[submissionKey(workload)]
+	if !exists || now.After(entry.expires) {
+		return false, nil
+	}
+	if !sameRequest(entry.workload, workload) {
+		return false, fmt.Errorf("idempotency key %s was already used for a different workload", workload.IdempotencyKey)
+	}
+
+	workload.ID = entry.workload.ID
+	workload.Status = entry.workload.Status
+	workload.AssignedGPU = entry.workload.AssignedGPU
+	workload.SubmittedAt = entry.workload.SubmittedAt
+	s.deduplicated++
+	return true, nil
+}
+
+// rememberSubmission records a keyed workload for its client's window; callers must hold the lock
+func (s *Scheduler) rememberSubmission(workload *Workload) {
+	if workload.IdempotencyKey == "" {
+		return
+	}
+	window := s.config.Idempotency.dedupWindow(workload.ClientID)
+	if window <= 0 {
+		return
+	}
+
+	entry := &submission{
+		key:      submissionKey(workload),
+		workload: workload,
+		expires:  workload.SubmittedAt.Add(window),
+	}
+	s.submissions[entry.key] = entry
+	s.submissionOrder = append(s.submissionOrder, entry)
+}
+
+// expireSubmissions forgets submissions in the order they were made. An
+// entry with a short window may outlive its expiry behind a longer one, so
+// lookups check expiry too. Callers must hold the lock.
+func (s *Scheduler) expireSubmissions(now time.Time) {
+	expired := 0
+	for _, entry := range s.submissionOrder {
+		if !now.After(entry.expires) {
+			break
+		}
+		if s.submissions[entry.key] == entry {
+			delete(s.submissions, entry.key)
+		}
+		expired++
+	}
+	if expired > 0 {
+		s.submissionOrder = append(s.submissionOrder[:0], s.submissionOrder[expired:]...)
+	}
+}
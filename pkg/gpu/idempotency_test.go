@@ -0,0 +1,77 @@
+package gpu
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIdempotentSubmissionDeduplicatesRetries(t *testing.T) {
+	config := DefaultSchedulerConfig()
+	config.Idempotency.ClientWindows["batch"] = 0 // never deduplicated
+	scheduler := NewSchedulerWithConfig(StrategyLeastUtilized, config)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+
+	original := &Workload{ID: "train-1", ClientID: "web", IdempotencyKey: "req-1", MemoryRequired: 8000}
+	if err := scheduler.SubmitWorkload(original); err != nil {
+		t.Fatalf("SubmitWorkload failed: %v", err)
+	}
+	scheduler.Schedule()
+
+	// The client timed out and retried with a freshly generated workload ID
+	retry := &Workload{ID: "train-2", ClientID: "web", IdempotencyKey: "req-1", MemoryRequired: 8000}
+	if err := scheduler.SubmitWorkload(retry); err != nil {
+		t.Fatalf("Expected the retry accepted, got %v", err)
+	}
+	if retry.ID != "train-1" || retry.Status != WorkloadRunning || retry.AssignedGPU != "gpu-0" {
+		t.Errorf("Expected the retry to describe the original workload, got %+v", retry)
+	}
+	if queued := len(scheduler.workloadQueue); queued != 0 {
+		t.Errorf("Expected no duplicate queued, got %d", queued)
+	}
+
+	conflicting := &Workload{ID: "train-3", ClientID: "web", IdempotencyKey: "req-1", MemoryRequired: 12000}
+	if err := scheduler.SubmitWorkload(conflicting); err == nil {
+		t.Error("Expected a reused key with a different workload to be rejected")
+	}
+
+	// Keys are scoped to their client
+	other := &Workload{ID: "train-4", ClientID: "cli", IdempotencyKey: "req-1", MemoryRequired: 8000}
+	scheduler.SubmitWorkload(other)
+	unwindowed := &Workload{ID: "batch-1", ClientID: "batch", IdempotencyKey: "req-1", MemoryRequired: 8000}
+	scheduler.SubmitWorkload(unwindowed)
+	scheduler.SubmitWorkload(&Workload{ID: "batch-2", ClientID: "batch", IdempotencyKey: "req-1", MemoryRequired: 8000})
+	if queued := len(scheduler.workloadQueue); queued != 3 {
+		t.Errorf("Expected 3 distinct submissions queued, got %d", queued)
+	}
+
+	// Once the window passes the key can be used again
+	scheduler.mu.Lock()
+	for _, entry := range scheduler.submissionOrder {
+		entry.expires = time.Now().Add(-time.Second)
+	}
+	scheduler.mu.Unlock()
+	scheduler.SubmitWorkload(&Workload{ID: "train-5", ClientID: "web", IdempotencyKey: "req-1", MemoryRequired: 8000})
+	if queued := len(scheduler.workloadQueue); queued != 4 {
+		t.Errorf("Expected the key reusable after its window, got %d queued", queued)
+	}
+	if len(scheduler.submissions) != 1 {
+		t.Errorf("Expected expired submissions forgotten, got %d remembered", len(scheduler.submissions))
+	}
+	if deduplicated := scheduler.GetUtilizationMetrics()["deduplicated_submissions"]; deduplicated != 1 {
+		t.Errorf("Expected 1 deduplicated submission, got %v", deduplicated)
+	}
+}
+
+func TestIdempotencyKeysSurviveRestart(t *testing.T) {
+	config := WALConfig{Path: filepath.Join(t.TempDir(), "scheduler.wal")}
+	scheduler, _ := newWALScheduler(t, config)
+	scheduler.SubmitWorkload(&Workload{ID: "job", ClientID: "web", IdempotencyKey: "req-1", MemoryRequired: 8000})
+
+	restarted, _ := newWALScheduler(t, config)
+	retry := &Workload{ID: "job-retry", ClientID: "web", IdempotencyKey: "req-1", MemoryRequired: 8000}
+	restarted.SubmitWorkload(retry)
+	if retry.ID != "job" || len(restarted.workloadQueue) != 1 {
+		t.Errorf("Expected the retry deduplicated after recovery, got %s with %d queued", retry.ID, len(restarted.workloadQueue))
+	}
+}
@@ -0,0 +1,34 @@
+package gpu
+
+import "sort"
+
+// maxIngestedHistory bounds the metrics kept per ingested GPU, as for collected ones
+const maxIngestedHistory = 1000
+
+// Ingest stores metrics collected elsewhere, such as by a node agent, as if
+// this collector had collected them, and calls the callbacks. A server
+// without GPUs of its own keeps its agents' metrics in a collector it never
+// starts. Late metrics are placed in timestamp order.
+func (mc *MetricsCollector) Ingest(metrics GPUMetrics) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	history, exists := mc.metrics[metrics.GPUID]
+	if !exists {
+		mc.gpuIDs = append(mc.gpuIDs, metrics.GPUID)
+	}
+	at := sort.Search(len(history), func(i int) bool {
+		return history[i].Timestamp.After(metrics.Timestamp)
+	})
+	history = append(history, GPUMetrics{})
+	copy(history[at+1:], history[at:])
+	history[at] = metrics
+	if len(history) > maxIngestedHistory {
+		history = history[len(history)-maxIngestedHistory:]
+	}
+	mc.metrics[metrics.GPUID] = history
+
+	for _, callback := range mc.callbacks {
+		go callback(metrics)
+	}
+}
@@ -0,0 +1,35 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIngestKeepsMetricsInTimestampOrder(t *testing.T) {
+	collector := NewMetricsCollector(time.Second)
+	received := make(chan GPUMetrics, 3)
+	collector.RegisterCallback(func(metrics GPUMetrics) { received <- metrics })
+
+	now := time.Now()
+	collector.Ingest(GPUMetrics{GPUID: "node-a:0", UtilizationGPU: 50, Timestamp: now})
+	collector.Ingest(GPUMetrics{GPUID: "node-a:0", UtilizationGPU: 10, Timestamp: now.Add(-time.Minute)})
+	collector.Ingest(GPUMetrics{GPUID: "node-b:0", UtilizationGPU: 90, Timestamp: now})
+
+	if latest := collector.GetLatestMetrics()["node-a:0"]; latest.UtilizationGPU != 50 {
+		t.Errorf("Expected the late sample kept behind the newest, got %v", latest.UtilizationGPU)
+	}
+	history := collector.GetMetricsHistory("node-a:0", now.Add(-time.Hour))
+	if len(history) != 2 || history[0].UtilizationGPU != 10 {
+		t.Errorf("Expected history in timestamp order, got %+v", history)
+	}
+	if overview := collector.GetSystemOverview(); overview["total_gpus"] != 2 {
+		t.Errorf("Expected both ingested GPUs counted, got %v", overview["total_gpus"])
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case <-received:
+		case <-time.After(time.Second):
+			t.Fatal("Expected a callback for every ingested sample")
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package gpu
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// DefaultJitter is the fraction by which collection intervals vary
+const DefaultJitter = 0.1
+
+// Jitter spreads periodic work so that many nodes started together do not
+// run nvidia-smi in lockstep
+type Jitter struct {
+	fraction float64
+	rng      *rand.Rand
+	mu       sync.Mutex
+}
+
+// NewJitter creates a jitter source seeded by a node name, varying intervals
+// by up to fraction in either direction; fractions are capped at 0.5
+func NewJitter(node string, fraction float64) *Jitter {
+	if fraction < 0 {
+		fraction = 0
+	}
+	if fraction > 0.5 {
+		fraction = 0.5
+	}
+	h := fnv.New64a()
+	h.Write([]byte(node))
+	seed := int64(h.Sum64()) ^ time.Now().UnixNano()
+	return &Jitter{fraction: fraction, rng: rand.New(rand.NewSource(seed))}
+}
+
+// Next returns interval varied by the jitter fraction
+func (j *Jitter) Next(interval time.Duration) time.Duration {
+	if j == nil || j.fraction == 0 {
+		return interval
+	}
+	j.mu.Lock()
+	offset := j.fraction * (2*j.rng.Float64() - 1)
+	j.mu.Unlock()
+	return interval + time.Duration(offset*float64(interval))
+}
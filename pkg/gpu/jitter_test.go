@@ -0,0 +1,28 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJitterStaysWithinFraction(t *testing.T) {
+	jitter := NewJitter("node-a", 0.1)
+	varied := false
+	for i := 0; i < 200; i++ {
+		next := jitter.Next(10 * time.Second)
+		if next < 9*time.Second || next > 11*time.Second {
+			t.Fatalf("interval %v outside 10s +/- 10%%", next)
+		}
+		varied = varied || next != 10*time.Second
+	}
+	if !varied {
+		t.Error("intervals were never jittered")
+	}
+
+	if next := NewJitter("node-a", 0).Next(time.Second); next != time.Second {
+		t.Errorf("zero jitter changed the interval to %v", next)
+	}
+	if next := NewJitter("node-a", 5).Next(time.Second); next < 500*time.Millisecond || next > 1500*time.Millisecond {
+		t.Errorf("fraction should be capped at 0.5, got %v", next)
+	}
+}
@@ -0,0 +1,92 @@
+package gpu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MatchesSelector reports whether labels carry every key/value pair in the
+// selector. An empty selector matches everything.
+func MatchesSelector(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if actual, exists := labels[key]; !exists || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseSelector parses a selector written as comma-separated key=value
+// pairs, e.g. "nvlink=true,arch=hopper"
+func ParseSelector(text string) (map[string]string, error) {
+	selector := make(map[string]string)
+	for _, term := range strings.Split(text, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		parts := strings.SplitN(term, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || key == "" {
+			return nil, fmt.Errorf("invalid selector term %q, expected key=value", term)
+		}
+		selector[key] = strings.TrimSpace(parts[1])
+	}
+	return selector, nil
+}
+
+// FormatSelector writes a selector in the form ParseSelector reads, with keys sorted
+func FormatSelector(selector map[string]string) string {
+	keys := make([]string, 0, len(selector))
+	for key := range selector {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	terms := make([]string, len(keys))
+	for i, key := range keys {
+		terms[i] = key + "=" + selector[key]
+	}
+	return strings.Join(terms, ",")
+}
+
+// SetGPULabels replaces a GPU's labels. Queued workloads are re-examined on
+// the next pass since their selectors may now match.
+func (s *Scheduler) SetGPULabels(gpuID string, labels map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	gpu, exists := s.gpus[gpuID]
+	if !exists {
+		return fmt.Errorf("GPU %s not found", gpuID)
+	}
+	gpu.Labels = copyLabels(labels)
+	s.examined = 0
+	s.wake()
+	return nil
+}
+
+// GPULabels returns a copy of a GPU's labels, or nil for an unknown GPU
+func (s *Scheduler) GPULabels(gpuID string) map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	gpu, exists := s.gpus[gpuID]
+	if !exists {
+		return nil
+	}
+	return copyLabels(gpu.Labels)
+}
+
+// copyLabels returns a copy of a label set, or nil when it is empty
+func copyLabels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(labels))
+	for key, value := range labels {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,57 @@
+package gpu
+
+import "testing"
+
+func TestSelectorsRestrictPlacement(t *testing.T) {
+	for _, strategy := range allStrategies {
+		scheduler := NewScheduler(strategy)
+		scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 80000, Available: true, Labels: map[string]string{"arch": "ampere"}})
+		scheduler.RegisterGPU(&GPU{ID: "gpu-1", MemoryTotal: 80000, Available: true, Labels: map[string]string{"arch": "ampere", "zone": "us-west-2a"}})
+
+		hopper := &Workload{ID: "hopper", MemoryRequired: 8000, Selector: map[string]string{"arch": "hopper", "nvlink": "true"}}
+		zoned := &Workload{ID: "zoned", MemoryRequired: 8000, Selector: map[string]string{"zone": "us-west-2a"}}
+		scheduler.SubmitWorkload(hopper)
+		scheduler.SubmitWorkload(zoned)
+		scheduler.Schedule()
+
+		if zoned.AssignedGPU != "gpu-1" {
+			t.Errorf("%s: expected the zoned workload on gpu-1, got %q", strategy, zoned.AssignedGPU)
+		}
+		if hopper.Status != WorkloadPending {
+			t.Errorf("%s: expected the hopper workload to wait for a matching GPU, got %s on %q", strategy, hopper.Status, hopper.AssignedGPU)
+		}
+
+		// Relabelling makes the waiting workload placeable without freeing capacity
+		if err := scheduler.SetGPULabels("gpu-0", map[string]string{"arch": "hopper", "nvlink": "true"}); err != nil {
+			t.Fatalf("SetGPULabels failed: %v", err)
+		}
+		scheduler.Schedule()
+		if hopper.AssignedGPU != "gpu-0" {
+			t.Errorf("%s: expected the hopper workload on relabelled gpu-0, got %q", strategy, hopper.AssignedGPU)
+		}
+	}
+}
+
+func TestParseSelector(t *testing.T) {
+	selector, err := ParseSelector(" arch=hopper, nvlink=true ,,zone=")
+	if err != nil {
+		t.Fatalf("ParseSelector failed: %v", err)
+	}
+	if len(selector) != 3 || selector["arch"] != "hopper" || selector["zone"] != "" {
+		t.Errorf("Unexpected selector %v", selector)
+	}
+	if formatted := FormatSelector(selector); formatted != "arch=hopper,nvlink=true,zone=" {
+		t.Errorf("Unexpected formatted selector %q", formatted)
+	}
+	if !MatchesSelector(map[string]string{"arch": "hopper", "nvlink": "true", "zone": ""}, selector) {
+		t.Error("Expected labels carrying every pair to match")
+	}
+	if MatchesSelector(map[string]string{"arch": "hopper", "nvlink": "true"}, selector) {
+		t.Error("Expected a missing key not to match")
+	}
+	for _, invalid := range []string{"arch", "=hopper"} {
+		if _, err := ParseSelector(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
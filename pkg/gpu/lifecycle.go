@@ -0,0 +1,124 @@
+package gpu
+
+import "time"
+
+// Workload lifecycle event types
+const (
+	LifecycleQueued    = "queued"
+	LifecycleScheduled = "scheduled"
+	LifecycleStarted   = "started"
+	LifecyclePreempted = "preempted"
+	LifecycleCompleted = "completed"
+	LifecycleFailed    = "failed"
+)
+
+// LifecycleEvent reports a workload changing state with where it was placed,
+// so CI systems and bots can react without polling
+type LifecycleEvent struct {
+	Type        string             `json:"type"`
+	WorkloadID  string             `json:"workload_id"`
+	Name        string             `json:"name"`
+	Tenant      string             `json:"tenant"`
+	Status      WorkloadStatus     `json:"status"`
+	Class       WorkloadClass      `json:"class,omitempty"`
+	Priority    int                `json:"priority"`
+	Pool        string             `json:"pool,omitempty"`
+	Labels      map[string]string  `json:"labels,omitempty"`
+	GPUID       string             `json:"gpu_id,omitempty"` // The GPU placed on, or left when preempted or finished
+	GPUName     string             `json:"gpu_name,omitempty"`
+	Node        string             `json:"node,omitempty"`
+	Colocated   bool               `json:"colocated,omitempty"` // Sharing the GPU with an inference workload
+	Strategy    SchedulingStrategy `json:"strategy,omitempty"`
+	Reason      string             `json:"reason,omitempty"`
+	Preemptions int                `json:"preemptions,omitempty"`
+	SubmittedAt time.Time          `json:"submitted_at"`
+	StartedAt   *time.Time         `json:"started_at,omitempty"`
+	Artifacts   []Artifact         `json:"artifacts,omitempty"` // Outputs registered by the time it finished
+	Usage       *UsageSummary      `json:"usage,omitempty"`     // Set on completed and failed events with samples
+	Timestamp   time.Time          `json:"timestamp"`
+}
+
+// UsageSummary is the GPU utilization observed while a workload ran
+type UsageSummary struct {
+	AvgUtilization float64 `json:"avg_utilization"`
+	PeakMemory     uint64  `json:"peak_memory_mb,omitempty"` // 0 when per-process memory was not observed
+	Samples        int     `json:"samples"`
+}
+
+// lifecycleBuffer bounds the events waiting for delivery; later events are
+// dropped while handlers fall this far behind
+const lifecycleBuffer = 1024
+
+// OnLifecycleEvent registers a handler for workload state changes. Handlers
+// are called in order on a single goroutine, never with the scheduler locked.
+func (s *Scheduler) OnLifecycleEvent(handler func(LifecycleEvent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lifecycleHandlers = append(s.lifecycleHandlers, handler)
+	if s.lifecycleCh == nil {
+		s.lifecycleCh = make(chan LifecycleEvent, lifecycleBuffer)
+		go s.deliverLifecycleEvents(s.lifecycleCh)
+	}
+}
+
+// deliverLifecycleEvents calls the handlers for each queued event
+func (s *Scheduler) deliverLifecycleEvents(events chan LifecycleEvent) {
+	for event := range events {
+		s.mu.RLock()
+		handlers := s.lifecycleHandlers
+		s.mu.RUnlock()
+
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+}
+
+// emitLifecycle queues a lifecycle event for a workload, placed on or leaving
+// gpu when set; callers must hold the lock
+func (s *Scheduler) emitLifecycle(eventType string, gpu *GPU, workload *Workload, colocated bool, reason string) {
+	if s.lifecycleCh == nil {
+		return
+	}
+
+	event := LifecycleEvent{
+		Type:        eventType,
+		WorkloadID:  workload.ID,
+		Name:        workload.Name,
+		Tenant:      workload.Tenant,
+		Status:      workload.Status,
+		Class:       workload.Class,
+		Priority:    workload.Priority,
+		Pool:        workload.Pool,
+		Labels:      copyLabels(workload.Labels),
+		Colocated:   colocated,
+		Reason:      reason,
+		Preemptions: workload.Preemptions,
+		SubmittedAt: workload.SubmittedAt,
+		StartedAt:   workload.StartedAt,
+		Timestamp:   time.Now(),
+	}
+	if eventType == LifecycleCompleted || eventType == LifecycleFailed {
+		event.Artifacts = append([]Artifact(nil), workload.Artifacts...)
+		if usage := s.usage[workload.ID]; usage != nil && usage.samples > 0 {
+			event.Usage = &UsageSummary{
+				AvgUtilization: usage.utilizationSum / float64(usage.samples),
+				PeakMemory:     usage.peakMemory,
+				Samples:        usage.samples,
+			}
+		}
+	}
+	if gpu != nil {
+		event.GPUID = gpu.ID
+		event.GPUName = gpu.Name
+		event.Node = gpu.Node
+		event.Strategy = s.poolStrategy(workload.Pool)
+	}
+
+	select {
+	case s.lifecycleCh <- event:
+	default:
+		s.droppedLifecycle++
+	}
+}
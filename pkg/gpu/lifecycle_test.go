@@ -0,0 +1,84 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+// collectLifecycle returns a function waiting for the next n lifecycle events
+func collectLifecycle(t *testing.T, scheduler *Scheduler) func(n int) []LifecycleEvent {
+	events := make(chan LifecycleEvent, 100)
+	scheduler.OnLifecycleEvent(func(event LifecycleEvent) { events <- event })
+	return func(n int) []LifecycleEvent {
+		var received []LifecycleEvent
+		for len(received) < n {
+			select {
+			case event := <-events:
+				received = append(received, event)
+			case <-time.After(time.Second):
+				t.Fatalf("received %d of %d lifecycle events: %+v", len(received), n, received)
+			}
+		}
+		return received
+	}
+}
+
+func TestLifecycleEventsCarryPlacementContext(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", Name: "NVIDIA A100", Node: "node-a", MemoryTotal: 40960, Available: true})
+	next := collectLifecycle(t, scheduler)
+
+	scheduler.SubmitWorkload(&Workload{ID: "w1", Name: "train", MemoryRequired: 8192, Labels: map[string]string{"ci": "build-42"}})
+	scheduler.Schedule()
+	scheduler.ObserveWorkload("w1", 60, 6144)
+	scheduler.ObserveWorkload("w1", 90, 7168)
+	scheduler.CompleteWorkload("w1")
+
+	events := next(4)
+	want := []string{LifecycleQueued, LifecycleScheduled, LifecycleStarted, LifecycleCompleted}
+	for i, event := range events {
+		if event.Type != want[i] || event.WorkloadID != "w1" || event.Labels["ci"] != "build-42" {
+			t.Errorf("event %d = %+v, want %s", i, event, want[i])
+		}
+	}
+	if events[0].GPUID != "" || events[0].Status != WorkloadPending {
+		t.Errorf("queued event = %+v", events[0])
+	}
+	scheduled := events[1]
+	if scheduled.GPUID != "gpu-0" || scheduled.GPUName != "NVIDIA A100" || scheduled.Node != "node-a" ||
+		scheduled.Strategy != StrategyLeastUtilized || scheduled.Reason == "" || scheduled.Status != WorkloadRunning {
+		t.Errorf("scheduled event = %+v", scheduled)
+	}
+	if completed := events[3]; completed.GPUID != "gpu-0" || completed.Status != WorkloadCompleted || completed.StartedAt == nil ||
+		completed.Usage == nil || completed.Usage.AvgUtilization != 75 || completed.Usage.PeakMemory != 7168 {
+		t.Errorf("completed event = %+v", completed)
+	}
+}
+
+func TestLifecycleEventsReportPreemptionAndFailure(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16384, Available: true})
+	scheduler.RegisterGPU(&GPU{ID: "gpu-1", MemoryTotal: 16384, Available: true})
+	next := collectLifecycle(t, scheduler)
+
+	scheduler.SubmitWorkload(&Workload{ID: "w1", MemoryRequired: 8192})
+	scheduler.Schedule()
+	placed := next(3)[2].GPUID
+
+	scheduler.TaintGPU(placed, Taint{Key: "maintenance", Effect: TaintNoExecute})
+	preempted := next(1)[0]
+	if preempted.Type != LifecyclePreempted || preempted.GPUID != placed || preempted.Reason != noExecuteReason ||
+		preempted.Status != WorkloadPending || preempted.Preemptions != 1 {
+		t.Errorf("preempted event = %+v", preempted)
+	}
+
+	scheduler.Schedule()
+	started := next(2)[1]
+	if started.Type != LifecycleStarted || started.GPUID == placed {
+		t.Errorf("restarted event = %+v, want the untainted GPU", started)
+	}
+	scheduler.FailWorkload("w1")
+	if failed := next(1)[0]; failed.Type != LifecycleFailed || failed.Status != WorkloadFailed {
+		t.Errorf("failed event = %+v", failed)
+	}
+}
@@ -0,0 +1,240 @@
+package gpu
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryLeakConfig controls when a process's GPU memory growth is reported as
+// a possible leak
+type MemoryLeakConfig struct {
+	// Memory must have grown for at least MinAge, judged over the samples
+	// within Window
+	MinAge time.Duration `yaml:"min_age" json:"min_age"`
+	Window time.Duration `yaml:"window" json:"window"`
+
+	// Memory must grow by MinGrowthMB without ever shrinking, while GPU
+	// utilization moves by at most MaxUtilizationChange percentage points
+	MinGrowthMB          uint64  `yaml:"min_growth_mb" json:"min_growth_mb"`
+	MaxUtilizationChange float64 `yaml:"max_utilization_change" json:"max_utilization_change"`
+}
+
+// DefaultMemoryLeakConfig returns leak detection settings for long-running jobs
+func DefaultMemoryLeakConfig() MemoryLeakConfig {
+	return MemoryLeakConfig{
+		MinAge:               2 * time.Hour,
+		Window:               6 * time.Hour,
+		MinGrowthMB:          2048,
+		MaxUtilizationChange: 10,
+	}
+}
+
+// MemoryLeakSuspect is a process whose GPU memory keeps growing while its
+// GPU's utilization does not
+type MemoryLeakSuspect struct {
+	GPUID             string        `json:"gpu_id"`
+	PID               int           `json:"pid"`
+	ProcessName       string        `json:"process_name"`
+	Workload          string        `json:"workload,omitempty"` // Owning workload, when resolvable
+	StartMemoryMB     uint64        `json:"start_memory_mb"`
+	MemoryMB          uint64        `json:"memory_mb"`
+	GrowthMB          uint64        `json:"growth_mb"`
+	UtilizationChange float64       `json:"utilization_change"`
+	Observed          time.Duration `json:"observed"`
+	DetectedAt        time.Time     `json:"detected_at"`
+}
+
+// WorkloadResolver names the workload owning a process on a GPU, or returns
+// "" when unknown
+type WorkloadResolver func(gpuID string, pid int) string
+
+// SchedulerWorkloadResolver resolves processes to the workloads the scheduler
+// placed on their GPU, which requires collector and scheduler GPU IDs to match
+func SchedulerWorkloadResolver(s *Scheduler) WorkloadResolver {
+	return func(gpuID string, pid int) string {
+		var names []string
+		for _, workload := range s.ListWorkloads() {
+			if workload.AssignedGPU == gpuID && workload.Status == WorkloadRunning {
+				names = append(names, fmt.Sprintf("%s (%s)", workload.Name, workload.ID))
+			}
+		}
+		sort.Strings(names)
+		return strings.Join(names, ", ")
+	}
+}
+
+// memorySample is one downsampled observation of a process
+type memorySample struct {
+	at          time.Time
+	memoryMB    uint64
+	utilization float64
+}
+
+// processHistory is the memory history of one process on one GPU
+type processHistory struct {
+	name     string
+	samples  []memorySample // Non-decreasing memory since the last shrink
+	reported bool
+}
+
+// MemoryLeakDetector tracks the GPU memory of long-lived processes and
+// reports monotonic growth that is not explained by changing utilization
+type MemoryLeakDetector struct {
+	config    MemoryLeakConfig
+	resolver  WorkloadResolver
+	histories map[string]*processHistory // "<gpu>/<pid>"
+	listeners []func(MemoryLeakSuspect)
+	suspects  int
+	mu        sync.Mutex
+}
+
+// NewMemoryLeakDetector creates a detector; zero config fields use the defaults
+func NewMemoryLeakDetector(config MemoryLeakConfig) *MemoryLeakDetector {
+	defaults := DefaultMemoryLeakConfig()
+	if config.MinAge <= 0 {
+		config.MinAge = defaults.MinAge
+	}
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.Window < config.MinAge {
+		config.Window = config.MinAge
+	}
+	if config.MinGrowthMB == 0 {
+		config.MinGrowthMB = defaults.MinGrowthMB
+	}
+	if config.MaxUtilizationChange <= 0 {
+		config.MaxUtilizationChange = defaults.MaxUtilizationChange
+	}
+	return &MemoryLeakDetector{
+		config:    config,
+		histories: make(map[string]*processHistory),
+	}
+}
+
+// SetWorkloadResolver names the workloads owning suspect processes
+func (d *MemoryLeakDetector) SetWorkloadResolver(resolver WorkloadResolver) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resolver = resolver
+}
+
+// OnSuspect registers a listener for possible leaks, e.g. one recording an
+// event in the monitoring service
+func (d *MemoryLeakDetector) OnSuspect(listener func(MemoryLeakSuspect)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.listeners = append(d.listeners, listener)
+}
+
+// Observe records the processes currently on a GPU along with its
+// utilization and returns newly suspected leaks. Each process is reported
+// once until its memory shrinks again; processes that exited are forgotten.
+func (d *MemoryLeakDetector) Observe(gpuID string, processes []GPUProcess, utilization float64, at time.Time) []MemoryLeakSuspect {
+	d.mu.Lock()
+	// Keep about a hundred samples per window however often collection runs
+	spacing := d.config.Window / 100
+	seen := make(map[string]bool, len(processes))
+	var suspects []MemoryLeakSuspect
+
+	for _, process := range processes {
+		key := fmt.Sprintf("%s/%d", gpuID, process.PID)
+		seen[key] = true
+		history, exists := d.histories[key]
+		if !exists || history.name != process.ProcessName {
+			// A reused PID is a new process
+			history = &processHistory{name: process.ProcessName}
+			d.histories[key] = history
+		}
+
+		sample := memorySample{at: at, memoryMB: process.MemoryUsed, utilization: utilization}
+		if n := len(history.samples); n > 0 && process.MemoryUsed < history.samples[n-1].memoryMB {
+			// Shrinking memory is not a leak; judge growth from here
+			history.samples = []memorySample{sample}
+			history.reported = false
+			continue
+		}
+		if n := len(history.samples); n == 0 || at.Sub(history.samples[n-1].at) >= spacing {
+			history.samples = append(history.samples, sample)
+		}
+		cutoff := at.Add(-d.config.Window)
+		drop := 0
+		for drop < len(history.samples)-1 && history.samples[drop].at.Before(cutoff) {
+			drop++
+		}
+		history.samples = history.samples[drop:]
+
+		if suspect, leaking := d.evaluate(gpuID, process, history, sample); leaking {
+			history.reported = true
+			d.suspects++
+			suspects = append(suspects, suspect)
+		}
+	}
+
+	prefix := gpuID + "/"
+	for key := range d.histories {
+		if strings.HasPrefix(key, prefix) && !seen[key] {
+			delete(d.histories, key)
+		}
+	}
+	resolver := d.resolver
+	listeners := append([]func(MemoryLeakSuspect){}, d.listeners...)
+	d.mu.Unlock()
+
+	for i := range suspects {
+		if resolver != nil {
+			suspects[i].Workload = resolver(gpuID, suspects[i].PID)
+		}
+		for _, listener := range listeners {
+			listener(suspects[i])
+		}
+	}
+	return suspects
+}
+
+// evaluate checks a process's history against the leak heuristic; callers must hold the lock
+func (d *MemoryLeakDetector) evaluate(gpuID string, process GPUProcess, history *processHistory, latest memorySample) (MemoryLeakSuspect, bool) {
+	if history.reported || len(history.samples) < 2 {
+		return MemoryLeakSuspect{}, false
+	}
+	first := history.samples[0]
+	if latest.at.Sub(first.at) < d.config.MinAge {
+		return MemoryLeakSuspect{}, false
+	}
+	if latest.memoryMB < first.memoryMB+d.config.MinGrowthMB {
+		return MemoryLeakSuspect{}, false
+	}
+	change := math.Abs(latest.utilization - first.utilization)
+	if change > d.config.MaxUtilizationChange {
+		return MemoryLeakSuspect{}, false
+	}
+
+	return MemoryLeakSuspect{
+		GPUID:             gpuID,
+		PID:               process.PID,
+		ProcessName:       process.ProcessName,
+		StartMemoryMB:     first.memoryMB,
+		MemoryMB:          latest.memoryMB,
+		GrowthMB:          latest.memoryMB - first.memoryMB,
+		UtilizationChange: change,
+		Observed:          latest.at.Sub(first.at),
+		DetectedAt:        latest.at,
+	}, true
+}
+
+// GetStats returns leak detection statistics
+func (d *MemoryLeakDetector) GetStats() map[string]interface{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return map[string]interface{}{
+		"tracked_processes": len(d.histories),
+		"suspects":          d.suspects,
+		"window":            d.config.Window.String(),
+		"min_growth_mb":     d.config.MinGrowthMB,
+	}
+}
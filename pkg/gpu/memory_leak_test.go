@@ -0,0 +1,76 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryLeakDetectorFlagsSteadyGrowth(t *testing.T) {
+	detector := NewMemoryLeakDetector(MemoryLeakConfig{MinAge: time.Hour, Window: 3 * time.Hour, MinGrowthMB: 1000, MaxUtilizationChange: 10})
+	detector.SetWorkloadResolver(func(gpuID string, pid int) string { return "trainer (wl-1)" })
+	var heard []MemoryLeakSuspect
+	detector.OnSuspect(func(suspect MemoryLeakSuspect) { heard = append(heard, suspect) })
+
+	start := time.Now()
+	var suspects []MemoryLeakSuspect
+	// The leaking process grows 50 MB per 5 minutes at steady utilization,
+	// while the healthy one grows along with its utilization
+	for i := 0; i <= 36; i++ {
+		at := start.Add(time.Duration(i) * 5 * time.Minute)
+		processes := []GPUProcess{
+			{PID: 100, ProcessName: "python", MemoryUsed: uint64(4000 + 50*i)},
+			{PID: 200, ProcessName: "serve", MemoryUsed: 8000},
+		}
+		suspects = append(suspects, detector.Observe("0", processes, 60, at)...)
+	}
+
+	if len(suspects) != 1 || len(heard) != 1 {
+		t.Fatalf("expected one suspect reported once, got %+v", suspects)
+	}
+	suspect := suspects[0]
+	if suspect.PID != 100 || suspect.Workload != "trainer (wl-1)" || suspect.GrowthMB < 1000 || suspect.Observed < 100*time.Minute {
+		t.Errorf("unexpected suspect %+v", suspect)
+	}
+}
+
+func TestMemoryLeakDetectorIgnoresExplainedGrowth(t *testing.T) {
+	detector := NewMemoryLeakDetector(MemoryLeakConfig{MinAge: time.Hour, Window: 3 * time.Hour, MinGrowthMB: 1000, MaxUtilizationChange: 10})
+	start := time.Now()
+
+	for i := 0; i <= 36; i++ {
+		at := start.Add(time.Duration(i) * 5 * time.Minute)
+		// Memory grows as the job ramps up utilization
+		grows := []GPUProcess{{PID: 100, ProcessName: "python", MemoryUsed: uint64(4000 + 50*i)}}
+		if suspects := detector.Observe("0", grows, float64(20+2*i), at); len(suspects) > 0 {
+			t.Fatalf("growth with rising utilization flagged: %+v", suspects)
+		}
+		// Memory that is released periodically never grows monotonically
+		sawtooth := []GPUProcess{{PID: 300, ProcessName: "cache", MemoryUsed: uint64(4000 + 100*(i%12))}}
+		if suspects := detector.Observe("1", sawtooth, 50, at); len(suspects) > 0 {
+			t.Fatalf("sawtooth memory flagged: %+v", suspects)
+		}
+	}
+
+	// Exited processes are forgotten
+	detector.Observe("0", nil, 0, start.Add(4*time.Hour))
+	if tracked := detector.GetStats()["tracked_processes"]; tracked != 1 {
+		t.Errorf("tracked processes = %v, want only GPU 1's", tracked)
+	}
+}
+
+func TestSchedulerWorkloadResolver(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 40000, Available: true})
+	scheduler.SubmitWorkload(&Workload{ID: "wl-1", Name: "trainer", MemoryRequired: 1000})
+	if err := scheduler.Schedule(); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+
+	resolve := SchedulerWorkloadResolver(scheduler)
+	if owner := resolve("gpu-0", 100); owner != "trainer (wl-1)" {
+		t.Errorf("owner = %q", owner)
+	}
+	if owner := resolve("gpu-1", 100); owner != "" {
+		t.Errorf("unknown GPU owner = %q", owner)
+	}
+}
@@ -27,6 +27,11 @@ type MetricsAggregationService struct {
 	cancel          context.CancelFunc
 	running         bool
 	lastAggregation time.Time
+
+	// Cycle tracking for the watchdog, separate from mu so a cycle stuck
+	// holding mu can still be detected
+	lastCycle time.Time
+	cycleMu   sync.Mutex
 }
 
 // NewMetricsAggregationService creates a new metrics aggregation service
@@ -57,7 +62,8 @@ func (mas *MetricsAggregationService) Start() error {
 	}
 
 	mas.running = true
-	go mas.aggregationLoop()
+	mas.markCycle()
+	go mas.aggregationLoop(mas.ctx)
 
 	return nil
 }
@@ -74,20 +80,59 @@ func (mas *MetricsAggregationService) Stop() {
 }
 
 // aggregationLoop performs periodic metrics aggregation
-func (mas *MetricsAggregationService) aggregationLoop() {
+func (mas *MetricsAggregationService) aggregationLoop(ctx context.Context) {
 	ticker := time.NewTicker(mas.aggregationInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-mas.ctx.Done():
+		case <-ctx.Done():
 			return
 		case <-ticker.C:
 			mas.performAggregation()
+			if ctx.Err() != nil {
+				return
+			}
+			mas.markCycle()
 		}
 	}
 }
 
+// markCycle records that an aggregation cycle completed
+func (mas *MetricsAggregationService) markCycle() {
+	mas.cycleMu.Lock()
+	defer mas.cycleMu.Unlock()
+	mas.lastCycle = time.Now()
+}
+
+// LastCycle returns when the aggregation loop last completed a cycle
+func (mas *MetricsAggregationService) LastCycle() time.Time {
+	mas.cycleMu.Lock()
+	defer mas.cycleMu.Unlock()
+	return mas.lastCycle
+}
+
+// CycleInterval returns the aggregation interval
+func (mas *MetricsAggregationService) CycleInterval() time.Duration {
+	return mas.aggregationInterval
+}
+
+// Restart abandons the current aggregation cycle and starts a fresh loop
+// with a new context
+func (mas *MetricsAggregationService) Restart() error {
+	mas.mu.Lock()
+	defer mas.mu.Unlock()
+
+	if !mas.running {
+		return fmt.Errorf("metrics aggregation service is not running")
+	}
+	mas.cancel()
+	mas.ctx, mas.cancel = context.WithCancel(context.Background())
+	mas.markCycle()
+	go mas.aggregationLoop(mas.ctx)
+	return nil
+}
+
 // performAggregation aggregates metrics for all GPUs
 func (mas *MetricsAggregationService) performAggregation() {
 	now := time.Now()
@@ -240,9 +285,15 @@ func (mas *MetricsAggregationService) updateClusterMetrics(latestMetrics map[str
 	totalProcesses := 0
 	activeGPUs := 0
 	healthyGPUs := 0
+	utilizations := make([]float64, 0, len(latestMetrics))
+	memoryUsage := make([]float64, 0, len(latestMetrics))
 
 	// Aggregate metrics across all GPUs
 	for gpuID, metrics := range latestMetrics {
+		utilizations = append(utilizations, metrics.UtilizationGPU)
+		if metrics.MemoryTotal > 0 {
+			memoryUsage = append(memoryUsage, float64(metrics.MemoryUsed)/float64(metrics.MemoryTotal)*100)
+		}
 		totalMemoryMB += metrics.MemoryTotal
 		usedMemoryMB += metrics.MemoryUsed
 		totalUtilization += metrics.UtilizationGPU
@@ -283,12 +334,20 @@ func (mas *MetricsAggregationService) updateClusterMetrics(latestMetrics map[str
 	clusterMetrics.UsedMemoryMB = usedMemoryMB
 	clusterMetrics.TotalPowerDraw = totalPowerDraw
 	clusterMetrics.TotalProcesses = totalProcesses
+	clusterMetrics.Utilization = NewDistribution(utilizations)
+	clusterMetrics.Memory = NewDistribution(memoryUsage)
+	clusterMetrics.Nodes = AggregateNodes(latestMetrics, now)
 
 	mas.clusterMetrics = clusterMetrics
 }
 
 // calculateSimpleHealthStatus creates a basic health status for a GPU
 func (mas *MetricsAggregationService) calculateSimpleHealthStatus(metrics GPUMetrics) GPUHealthStatus {
+	return simpleHealthStatus(metrics)
+}
+
+// simpleHealthStatus checks a GPU's temperature and memory against fixed thresholds
+func simpleHealthStatus(metrics GPUMetrics) GPUHealthStatus {
 	status := GPUHealthStatus{
 		GPUID:           metrics.GPUID,
 		Timestamp:       metrics.Timestamp,
@@ -229,6 +229,12 @@ func TestClusterMetricsCalculation(t *testing.T) {
 		t.Errorf("Expected total processes %d, got %d",
 			expectedTotalProcesses, clusterMetrics.TotalProcesses)
 	}
+
+	// Metrics without a node are grouped under the default node
+	nodes := aggregationService.GetNodeStats()
+	if len(nodes) != 1 || nodes[0].NodeID != DefaultNodeID || nodes[0].TotalPowerDraw != expectedTotalPower {
+		t.Errorf("Expected one default node drawing %.1f W, got %+v", expectedTotalPower, nodes)
+	}
 }
 
 func TestEfficiencyReport(t *testing.T) {
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strconv"
@@ -15,6 +16,7 @@ import (
 // GPUMetrics represents detailed metrics for a single GPU
 type GPUMetrics struct {
 	GPUID              string    `json:"gpu_id"`
+	NodeID             string    `json:"node_id,omitempty"` // Host the GPU is attached to
 	Name               string    `json:"name"`
 	UtilizationGPU     float64   `json:"utilization_gpu"`     // GPU utilization percentage
 	UtilizationMemory  float64   `json:"utilization_memory"`  // Memory utilization percentage
@@ -28,9 +30,26 @@ type GPUMetrics struct {
 	ClockGraphics      uint64    `json:"clock_graphics"`      // Graphics clock in MHz
 	ClockMemory        uint64    `json:"clock_memory"`        // Memory clock in MHz
 	ProcessCount       int       `json:"process_count"`       // Number of running processes
+	EncoderSessions    int       `json:"encoder_sessions"`    // Open NVENC sessions
+	DecoderSessions    int       `json:"decoder_sessions"`    // Open NVDEC sessions
 	EncoderUtilization float64   `json:"encoder_utilization"` // Encoder utilization percentage
 	DecoderUtilization float64   `json:"decoder_utilization"` // Decoder utilization percentage
 	Timestamp          time.Time `json:"timestamp"`
+
+	// MPSShared marks GPUs shared through CUDA MPS, whose per-process
+	// utilization is merged into the MPS server
+	MPSShared  bool        `json:"mps_shared"`
+	MPSClients []MPSClient `json:"mps_clients,omitempty"`
+
+	// Virtualization, and the --query-gpu fields the GPU did not report,
+	// which are left at zero
+	VirtualizationMode string   `json:"virtualization_mode,omitempty"`
+	VGPUProfile        string   `json:"vgpu_profile,omitempty"`
+	Unavailable        []string `json:"unavailable,omitempty"`
+
+	// Set on metrics uploaded by a node agent whose clock was off: the
+	// agent's offset from the server clock, already removed from Timestamp
+	ClockSkew time.Duration `json:"clock_skew,omitempty"`
 }
 
 // GPUProcess represents a process running on the GPU
@@ -44,6 +63,7 @@ type GPUProcess struct {
 // MetricsCollector collects real-time GPU metrics
 type MetricsCollector struct {
 	gpuIDs          []string
+	nodeID          string
 	collectInterval time.Duration
 	metrics         map[string][]GPUMetrics // GPU ID -> historical metrics
 	processes       map[string][]GPUProcess // GPU ID -> running processes
@@ -52,41 +72,129 @@ type MetricsCollector struct {
 	cancel          context.CancelFunc
 	running         bool
 	callbacks       []func(GPUMetrics)
+
+	// nvidia-smi failures by reason, for self-monitoring
+	collectionErrors map[string]int64
+	lastCycle        time.Time // End of the last collection cycle, or the loop's start
+	runner           *CommandRunner
+	jitter           *Jitter // Varies collection intervals per node
+
+	// Adaptive collection; the fixed collectInterval is used while disabled
+	adaptive        AdaptiveIntervalConfig
+	activeUntil     time.Time
+	currentInterval time.Duration // Wait before the scheduled collection
+	wake            chan struct{} // Collects immediately when an idle collector is boosted
+
+	leakDetector *MemoryLeakDetector // Optional
+	orphans      *OrphanDetector     // Optional
+	mps          MPSConfig
+
+	virtualization map[string]string // Virtualization mode by GPU, discovered at start
+}
+
+// nvidia-smi failure reasons
+const (
+	CollectionErrorNotFound    = "not_found"    // nvidia-smi is not installed
+	CollectionErrorExitStatus  = "exit_status"  // nvidia-smi ran and failed
+	CollectionErrorExecFailed  = "exec_failed"  // nvidia-smi could not be started
+	CollectionErrorParseFailed = "parse_failed" // nvidia-smi output was not understood
+	CollectionErrorTimeout     = "timeout"      // nvidia-smi was killed after hanging
+)
+
+// errUnexpectedOutput marks nvidia-smi output that could not be parsed
+var errUnexpectedOutput = errors.New("unexpected nvidia-smi output format")
+
+// collectionErrorReason classifies an nvidia-smi failure
+func collectionErrorReason(err error) string {
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(err, errUnexpectedOutput):
+		return CollectionErrorParseFailed
+	case errors.Is(err, ErrCommandTimeout):
+		return CollectionErrorTimeout
+	case errors.Is(err, exec.ErrNotFound):
+		return CollectionErrorNotFound
+	case errors.As(err, &exitErr):
+		return CollectionErrorExitStatus
+	default:
+		return CollectionErrorExecFailed
+	}
 }
 
 // NewMetricsCollector creates a new GPU metrics collector
 func NewMetricsCollector(collectInterval time.Duration) *MetricsCollector {
 	ctx, cancel := context.WithCancel(context.Background())
+	nodeID := localNodeID()
 	return &MetricsCollector{
 		collectInterval: collectInterval,
+		nodeID:          nodeID,
 		metrics:         make(map[string][]GPUMetrics),
 		processes:       make(map[string][]GPUProcess),
 		ctx:             ctx,
 		cancel:          cancel,
 		callbacks:       make([]func(GPUMetrics), 0),
+
+		collectionErrors: make(map[string]int64),
+		runner:           defaultCommandRunner,
+		jitter:           NewJitter(nodeID, DefaultJitter),
+		wake:             make(chan struct{}, 1),
+		mps:              DefaultMPSConfig(),
 	}
 }
 
-// Start begins collecting GPU metrics
-func (mc *MetricsCollector) Start() error {
+// SetJitter varies collection intervals by up to fraction in either
+// direction, 0.1 by default; zero collects at exactly the interval
+func (mc *MetricsCollector) SetJitter(fraction float64) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.jitter = NewJitter(mc.nodeID, fraction)
+}
+
+// SetCommandRunner replaces the shared runner that limits nvidia-smi invocations
+func (mc *MetricsCollector) SetCommandRunner(runner *CommandRunner) {
 	mc.mu.Lock()
 	defer mc.mu.Unlock()
+	mc.runner = runner
+}
 
-	if mc.running {
+// nvidiaSMI runs nvidia-smi through the collector's command runner. Read-only
+// queries may be answered from the runner's short-lived cache unless fresh is set.
+func (mc *MetricsCollector) nvidiaSMI(ctx context.Context, fresh bool, args ...string) ([]byte, error) {
+	mc.mu.RLock()
+	runner := mc.runner
+	mc.mu.RUnlock()
+	return runner.Run(ctx, CommandSpec{Name: "nvidia-smi", Args: args, Cacheable: !fresh})
+}
+
+// Start begins collecting GPU metrics
+func (mc *MetricsCollector) Start() error {
+	mc.mu.RLock()
+	running := mc.running
+	mc.mu.RUnlock()
+	if running {
 		return fmt.Errorf("metrics collector is already running")
 	}
 
-	// Discover available GPUs
+	// Discover available GPUs; nvidia-smi runs without the lock held
 	gpus, err := mc.discoverGPUs()
 	if err != nil {
+		mc.recordCollectionError(err)
 		return fmt.Errorf("failed to discover GPUs: %w", err)
 	}
+	virtualization := mc.discoverVirtualization(context.Background())
 
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if mc.running {
+		return fmt.Errorf("metrics collector is already running")
+	}
 	mc.gpuIDs = gpus
+	mc.virtualization = virtualization
 	mc.running = true
+	mc.lastCycle = time.Now()
 
 	// Start collection goroutine
-	go mc.collectLoop()
+	go mc.collectLoop(mc.ctx)
 
 	return nil
 }
@@ -102,6 +210,61 @@ func (mc *MetricsCollector) Stop() {
 	}
 }
 
+// LastCycle returns when the collection loop last completed a cycle
+func (mc *MetricsCollector) LastCycle() time.Time {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	return mc.lastCycle
+}
+
+// CycleInterval returns the wait before the scheduled collection, which
+// varies with activity when the interval is adaptive
+func (mc *MetricsCollector) CycleInterval() time.Duration {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	if mc.currentInterval > 0 {
+		return mc.currentInterval
+	}
+	return mc.collectInterval
+}
+
+// Restart abandons the current collection cycle, killing any nvidia-smi it is
+// waiting on, and starts a fresh loop with a new context
+func (mc *MetricsCollector) Restart() error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if !mc.running {
+		return fmt.Errorf("metrics collector is not running")
+	}
+	mc.cancel()
+	mc.ctx, mc.cancel = context.WithCancel(context.Background())
+	mc.lastCycle = time.Now()
+	go mc.collectLoop(mc.ctx)
+	return nil
+}
+
+// SetMemoryLeakDetector feeds every GPU's processes to a leak detector
+func (mc *MetricsCollector) SetMemoryLeakDetector(detector *MemoryLeakDetector) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.leakDetector = detector
+}
+
+// SetOrphanDetector feeds every GPU's processes to an orphan detector
+func (mc *MetricsCollector) SetOrphanDetector(detector *OrphanDetector) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.orphans = detector
+}
+
+// SetNodeID overrides the node reported with collected metrics, which defaults to the hostname
+func (mc *MetricsCollector) SetNodeID(nodeID string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.nodeID = nodeID
+}
+
 // RegisterCallback registers a callback function to be called when new metrics are collected
 func (mc *MetricsCollector) RegisterCallback(callback func(GPUMetrics)) {
 	mc.mu.Lock()
@@ -159,70 +322,145 @@ func (mc *MetricsCollector) GetRunningProcesses() map[string][]GPUProcess {
 }
 
 // collectLoop is the main collection loop
-func (mc *MetricsCollector) collectLoop() {
-	ticker := time.NewTicker(mc.collectInterval)
-	defer ticker.Stop()
+func (mc *MetricsCollector) collectLoop(ctx context.Context) {
+	mc.mu.RLock()
+	jitter := mc.jitter
+	mc.mu.RUnlock()
+
+	timer := time.NewTimer(jitter.Next(mc.nextInterval(time.Now())))
+	defer timer.Stop()
 
 	for {
 		select {
-		case <-mc.ctx.Done():
+		case <-ctx.Done():
+			return
+		case <-mc.wake:
+			if !timer.Stop() {
+				<-timer.C
+			}
+		case <-timer.C:
+		}
+
+		mc.collectMetrics(ctx)
+		if ctx.Err() != nil {
+			// Restarted while collecting; the new loop reports cycles
 			return
-		case <-ticker.C:
-			mc.collectMetrics()
 		}
+		now := time.Now()
+		mc.mu.Lock()
+		mc.lastCycle = now
+		mc.mu.Unlock()
+		timer.Reset(jitter.Next(mc.nextInterval(now)))
 	}
 }
 
-// collectMetrics collects metrics for all GPUs
-func (mc *MetricsCollector) collectMetrics() {
+// collectMetrics collects metrics for all GPUs concurrently, so a hung
+// nvidia-smi for one GPU does not delay the others
+func (mc *MetricsCollector) collectMetrics(ctx context.Context) {
+	// One nvidia-smi invocation reports every GPU
+	all, err := mc.collectAllGPUMetrics(ctx)
+	if err != nil {
+		mc.recordCollectionError(err)
+		return
+	}
+	mc.observeActivity(all, time.Now())
+
+	var wg sync.WaitGroup
 	for _, gpuID := range mc.gpuIDs {
-		metrics, err := mc.collectGPUMetrics(gpuID)
-		if err != nil {
-			// Log error but continue collecting other GPUs
+		metrics, exists := all[gpuID]
+		if !exists {
+			// The GPU's row was missing or unparseable
+			mc.recordCollectionError(errUnexpectedOutput)
 			continue
 		}
+		wg.Add(1)
+		go func(gpuID string, metrics GPUMetrics) {
+			defer wg.Done()
+			mc.collectGPU(ctx, gpuID, metrics)
+		}(gpuID, metrics)
+	}
+	wg.Wait()
+}
 
-		processes, err := mc.collectGPUProcesses(gpuID)
+// collectGPU collects one GPU's processes and stores them with its metrics
+func (mc *MetricsCollector) collectGPU(ctx context.Context, gpuID string, metrics GPUMetrics) {
+	mc.labelVirtualization(&metrics)
+	processes, err := mc.collectGPUProcesses(ctx, gpuID)
+	if err != nil {
+		// Processes collection is optional, continue anyway
+		mc.recordCollectionError(err)
+		processes = []GPUProcess{}
+	} else {
+		shared, clients, err := mc.collectMPSClients(ctx, processes)
 		if err != nil {
-			// Processes collection is optional, continue anyway
-			processes = []GPUProcess{}
+			mc.recordCollectionError(err)
 		}
+		metrics.MPSShared, metrics.MPSClients = shared, clients
 
-		mc.mu.Lock()
-
-		// Store metrics (keep last 1000 entries per GPU)
-		if _, exists := mc.metrics[gpuID]; !exists {
-			mc.metrics[gpuID] = make([]GPUMetrics, 0)
-		}
-		mc.metrics[gpuID] = append(mc.metrics[gpuID], metrics)
-		if len(mc.metrics[gpuID]) > 1000 {
-			mc.metrics[gpuID] = mc.metrics[gpuID][len(mc.metrics[gpuID])-1000:]
-		}
-		// Store metrics (keep last MaxMetricsHistory entries per GPU)
-		const MaxMetricsHistory = 1000
-		if _, exists := mc.metrics[gpuID]; !exists {
-			mc.metrics[gpuID] = make([]GPUMetrics, 0)
+		mc.mu.RLock()
+		detector, orphans := mc.leakDetector, mc.orphans
+		mc.mu.RUnlock()
+		if detector != nil {
+			detector.Observe(gpuID, processes, metrics.UtilizationGPU, metrics.Timestamp)
 		}
-		mc.metrics[gpuID] = append(mc.metrics[gpuID], metrics)
-		if len(mc.metrics[gpuID]) > MaxMetricsHistory {
-			mc.metrics[gpuID] = mc.metrics[gpuID][len(mc.metrics[gpuID])-MaxMetricsHistory:]
+		if orphans != nil {
+			orphans.Observe(gpuID, processes, metrics.Timestamp)
 		}
-		// Store processes
-		mc.processes[gpuID] = processes
+	}
 
-		// Call callbacks
-		for _, callback := range mc.callbacks {
-			go callback(metrics)
-		}
+	mc.mu.Lock()
+	metrics.NodeID = mc.nodeID
 
-		mc.mu.Unlock()
+	// Store metrics (keep last 1000 entries per GPU)
+	if _, exists := mc.metrics[gpuID]; !exists {
+		mc.metrics[gpuID] = make([]GPUMetrics, 0)
+	}
+	mc.metrics[gpuID] = append(mc.metrics[gpuID], metrics)
+	if len(mc.metrics[gpuID]) > 1000 {
+		mc.metrics[gpuID] = mc.metrics[gpuID][len(mc.metrics[gpuID])-1000:]
+	}
+	// Store metrics (keep last MaxMetricsHistory entries per GPU)
+	const MaxMetricsHistory = 1000
+	if _, exists := mc.metrics[gpuID]; !exists {
+		mc.metrics[gpuID] = make([]GPUMetrics, 0)
+	}
+	mc.metrics[gpuID] = append(mc.metrics[gpuID], metrics)
+	if len(mc.metrics[gpuID]) > MaxMetricsHistory {
+		mc.metrics[gpuID] = mc.metrics[gpuID][len(mc.metrics[gpuID])-MaxMetricsHistory:]
+	}
+	// Store processes
+	mc.processes[gpuID] = processes
+
+	// Call callbacks
+	for _, callback := range mc.callbacks {
+		go callback(metrics)
 	}
+
+	mc.mu.Unlock()
+}
+
+// recordCollectionError counts an nvidia-smi failure by reason
+func (mc *MetricsCollector) recordCollectionError(err error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.collectionErrors[collectionErrorReason(err)]++
+}
+
+// GetCollectionErrors returns the total nvidia-smi failures by reason
+func (mc *MetricsCollector) GetCollectionErrors() map[string]int64 {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	errs := make(map[string]int64, len(mc.collectionErrors))
+	for reason, count := range mc.collectionErrors {
+		errs[reason] = count
+	}
+	return errs
 }
 
 // discoverGPUs discovers available NVIDIA GPUs
 func (mc *MetricsCollector) discoverGPUs() ([]string, error) {
-	cmd := exec.Command("nvidia-smi", "--query-gpu=index", "--format=csv,noheader,nounits")
-	output, err := cmd.Output()
+	output, err := mc.nvidiaSMI(context.Background(), false, "--query-gpu=index", "--format=csv,noheader,nounits")
 	if err != nil {
 		return nil, fmt.Errorf("nvidia-smi not available or no GPUs found: %w", err)
 	}
@@ -243,30 +481,64 @@ func (mc *MetricsCollector) discoverGPUs() ([]string, error) {
 	return gpuIDs, nil
 }
 
-// collectGPUMetrics collects detailed metrics for a specific GPU
-func (mc *MetricsCollector) collectGPUMetrics(gpuID string) (GPUMetrics, error) {
-	// Use nvidia-smi to collect comprehensive metrics
-	cmd := exec.Command("nvidia-smi",
+// gpuMetricsQuery is the nvidia-smi --query-gpu field list, led by the GPU index
+const gpuMetricsQuery = "--query-gpu=index,name,utilization.gpu,utilization.memory,memory.total,memory.used,memory.free,temperature.gpu,power.draw,power.limit,fan.speed,clocks.current.graphics,clocks.current.memory,encoder.stats.sessionCount,decoder.stats.sessionCount,utilization.encoder,utilization.decoder"
+
+// collectAllGPUMetrics collects detailed metrics for every GPU with a single
+// nvidia-smi invocation, keyed by GPU index. Rows that cannot be parsed are
+// counted and skipped.
+func (mc *MetricsCollector) collectAllGPUMetrics(ctx context.Context) (map[string]GPUMetrics, error) {
+	output, err := mc.nvidiaSMI(ctx, false, gpuMetricsQuery, "--format=csv,noheader,nounits")
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect GPU metrics: %w", err)
+	}
+
+	now := time.Now()
+	all := make(map[string]GPUMetrics)
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		metrics, err := parseGPUMetricsRow(line, now)
+		if err != nil {
+			mc.recordCollectionError(err)
+			continue
+		}
+		all[metrics.GPUID] = metrics
+	}
+	return all, nil
+}
+
+// collectGPUMetrics collects a fresh sample of detailed metrics for a specific GPU
+func (mc *MetricsCollector) collectGPUMetrics(ctx context.Context, gpuID string) (GPUMetrics, error) {
+	output, err := mc.nvidiaSMI(ctx, true,
 		fmt.Sprintf("--id=%s", gpuID),
-		"--query-gpu=name,utilization.gpu,utilization.memory,memory.total,memory.used,memory.free,temperature.gpu,power.draw,power.limit,fan.speed,clocks.current.graphics,clocks.current.memory,encoder.stats.sessionCount,decoder.stats.sessionCount",
+		gpuMetricsQuery,
 		"--format=csv,noheader,nounits")
-
-	output, err := cmd.Output()
 	if err != nil {
 		return GPUMetrics{}, fmt.Errorf("failed to collect GPU metrics: %w", err)
 	}
+	return parseGPUMetricsRow(strings.TrimSpace(string(output)), time.Now())
+}
 
-	line := strings.TrimSpace(string(output))
-	fields := strings.Split(line, ", ")
-
-	if len(fields) < 14 {
-		return GPUMetrics{}, fmt.Errorf("unexpected nvidia-smi output format")
+// parseGPUMetricsRow parses one row of gpuMetricsQuery output
+func parseGPUMetricsRow(line string, now time.Time) (GPUMetrics, error) {
+	row := strings.Split(line, ", ")
+	if len(row) < 17 {
+		return GPUMetrics{}, errUnexpectedOutput
 	}
+	gpuID := strings.TrimSpace(row[0])
+	if gpuID == "" {
+		return GPUMetrics{}, errUnexpectedOutput
+	}
+	fields := row[1:]
 
 	// Parse metrics
 	metrics := GPUMetrics{
 		GPUID:     gpuID,
-		Timestamp: time.Now(),
+		Timestamp: now,
 	}
 
 	// Parse each field with error handling
@@ -317,24 +589,31 @@ func (mc *MetricsCollector) collectGPUMetrics(gpuID string) (GPUMetrics, error)
 	}
 
 	if val, err := parseFloat(fields[12]); err == nil {
-		metrics.EncoderUtilization = val
+		metrics.EncoderSessions = int(val)
 	}
 
 	if val, err := parseFloat(fields[13]); err == nil {
+		metrics.DecoderSessions = int(val)
+	}
+
+	if val, err := parseFloat(fields[14]); err == nil {
+		metrics.EncoderUtilization = val
+	}
+
+	if val, err := parseFloat(fields[15]); err == nil {
 		metrics.DecoderUtilization = val
 	}
+	metrics.Unavailable = unavailableFields(fields)
 
 	return metrics, nil
 }
 
 // collectGPUProcesses collects information about processes running on a GPU
-func (mc *MetricsCollector) collectGPUProcesses(gpuID string) ([]GPUProcess, error) {
-	cmd := exec.Command("nvidia-smi",
+func (mc *MetricsCollector) collectGPUProcesses(ctx context.Context, gpuID string) ([]GPUProcess, error) {
+	output, err := mc.nvidiaSMI(ctx, false,
 		fmt.Sprintf("--id=%s", gpuID),
 		"--query-compute-apps=pid,name,used_memory",
 		"--format=csv,noheader,nounits")
-
-	output, err := cmd.Output()
 	if err != nil {
 		return []GPUProcess{}, fmt.Errorf("failed to collect GPU processes: %w", err)
 	}
@@ -370,12 +649,10 @@ func (mc *MetricsCollector) collectGPUProcesses(gpuID string) ([]GPUProcess, err
 	}
 
 	// Also collect graphics processes
-	cmd = exec.Command("nvidia-smi",
+	output, err = mc.nvidiaSMI(ctx, false,
 		fmt.Sprintf("--id=%s", gpuID),
 		"--query-graphics-apps=pid,name,used_memory",
 		"--format=csv,noheader,nounits")
-
-	output, err = cmd.Output()
 	if err == nil {
 		scanner = bufio.NewScanner(strings.NewReader(string(output)))
 		for scanner.Scan() {
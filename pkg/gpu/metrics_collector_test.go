@@ -1,6 +1,10 @@
 package gpu
 
 import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
 	"testing"
 	"time"
 )
@@ -346,6 +350,103 @@ func containsInMiddle(s, substr string) bool {
 	return false
 }
 
+// fakeNvidiaSMI answers metrics queries for gpus GPUs, pausing spawnCost per
+// invocation to model process startup, and reports no processes
+func fakeNvidiaSMI(gpus int, spawnCost time.Duration) func(ctx context.Context, spec CommandSpec) ([]byte, error) {
+	return func(ctx context.Context, spec CommandSpec) ([]byte, error) {
+		time.Sleep(spawnCost)
+		args := strings.Join(spec.Args, " ")
+		if strings.Contains(args, "-apps=") {
+			return nil, nil
+		}
+		var rows []string
+		for i := 0; i < gpus; i++ {
+			if strings.Contains(args, "--id=") && !strings.Contains(args, fmt.Sprintf("--id=%d ", i)) {
+				continue
+			}
+			rows = append(rows, fmt.Sprintf("%d, NVIDIA A100, %d, 20, 40960, 8192, 32768, 60, 250.5, 400, 30, 1410, 1215, 0, 0, 0, 0", i, 10*i))
+		}
+		return []byte(strings.Join(rows, "\n") + "\n"), nil
+	}
+}
+
+func TestCollectMetricsSingleInvocation(t *testing.T) {
+	collector := NewMetricsCollector(time.Second)
+	runner := NewCommandRunner(CommandRunnerConfig{})
+	invocations := 0
+	fake := fakeNvidiaSMI(4, 0)
+	runner.exec = func(ctx context.Context, spec CommandSpec) ([]byte, error) {
+		if strings.HasPrefix(spec.Args[0], "--query-gpu") {
+			invocations++
+		}
+		return fake(ctx, spec)
+	}
+	collector.SetCommandRunner(runner)
+	collector.gpuIDs = []string{"0", "1", "2", "3"}
+
+	collector.collectMetrics(context.Background())
+
+	if invocations != 1 {
+		t.Errorf("metrics query ran %d times, want once for all GPUs", invocations)
+	}
+	latest := collector.GetLatestMetrics()
+	if len(latest) != 4 || latest["3"].UtilizationGPU != 30 || latest["2"].Name != "NVIDIA A100" {
+		t.Errorf("latest metrics = %+v", latest)
+	}
+}
+
+func TestCollectMetricsSkipsBadRows(t *testing.T) {
+	collector := NewMetricsCollector(time.Second)
+	runner := NewCommandRunner(CommandRunnerConfig{})
+	runner.exec = func(ctx context.Context, spec CommandSpec) ([]byte, error) {
+		if strings.HasPrefix(spec.Args[0], "--query-gpu") {
+			return []byte("0, NVIDIA A100, 50, 20, 40960, 8192, 32768, 60, 250.5, 400, 30, 1410, 1215, 0, 0, 0, 0\n1, [GPU is lost]\n"), nil
+		}
+		return nil, nil
+	}
+	collector.SetCommandRunner(runner)
+	collector.gpuIDs = []string{"0", "1"}
+
+	collector.collectMetrics(context.Background())
+
+	if latest := collector.GetLatestMetrics(); len(latest) != 1 || latest["0"].UtilizationGPU != 50 {
+		t.Errorf("latest metrics = %+v", latest)
+	}
+	// The bad row and the GPU missing from the results
+	if errs := collector.GetCollectionErrors(); errs[CollectionErrorParseFailed] != 2 {
+		t.Errorf("collection errors = %v", errs)
+	}
+}
+
+// benchmarkCollection measures one collection cycle's metrics queries on an
+// 8-GPU node where each nvidia-smi spawn costs a millisecond
+func benchmarkCollection(b *testing.B, batched bool) {
+	collector := NewMetricsCollector(time.Second)
+	runner := NewCommandRunner(CommandRunnerConfig{MaxConcurrent: 1})
+	runner.exec = fakeNvidiaSMI(8, time.Millisecond)
+	collector.SetCommandRunner(runner)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if batched {
+			if all, err := collector.collectAllGPUMetrics(ctx); err != nil || len(all) != 8 {
+				b.Fatalf("collected %d GPUs: %v", len(all), err)
+			}
+			continue
+		}
+		for gpu := 0; gpu < 8; gpu++ {
+			if _, err := collector.collectGPUMetrics(ctx, fmt.Sprint(gpu)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkCollectMetricsPerGPU(b *testing.B) { benchmarkCollection(b, false) }
+
+func BenchmarkCollectMetricsBatched(b *testing.B) { benchmarkCollection(b, true) }
+
 // Benchmark tests for performance
 func BenchmarkMetricsCollection(b *testing.B) {
 	collector := NewMetricsCollector(1 * time.Second)
@@ -423,3 +524,26 @@ func BenchmarkEfficiencyMetrics(b *testing.B) {
 		collector.GetGPUEfficiencyMetrics(testGPUID, 2*time.Hour)
 	}
 }
+
+func TestCollectionErrorReasons(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 3").Run()
+	cases := map[string]error{
+		CollectionErrorNotFound:    fmt.Errorf("nvidia-smi not available: %w", exec.ErrNotFound),
+		CollectionErrorExitStatus:  fmt.Errorf("failed to collect GPU metrics: %w", exitErr),
+		CollectionErrorParseFailed: errUnexpectedOutput,
+		CollectionErrorExecFailed:  fmt.Errorf("fork failed"),
+	}
+	for expected, err := range cases {
+		if reason := collectionErrorReason(err); reason != expected {
+			t.Errorf("Expected %s for %v, got %s", expected, err, reason)
+		}
+	}
+
+	collector := NewMetricsCollector(time.Second)
+	collector.recordCollectionError(errUnexpectedOutput)
+	collector.recordCollectionError(errUnexpectedOutput)
+	errs := collector.GetCollectionErrors()
+	if errs[CollectionErrorParseFailed] != 2 || len(errs) != 1 {
+		t.Errorf("Expected two parse failures, got %v", errs)
+	}
+}
@@ -31,6 +31,7 @@ type MockMetricsCollector struct {
 // MockGPUConfig defines parameters for simulating individual GPU behavior
 type MockGPUConfig struct {
 	Name            string
+	NodeID          string
 	MemoryTotal     uint64  // in MB
 	BaseUtilization float64 // baseline utilization %
 	UtilVariance    float64 // utilization variance
@@ -60,6 +61,9 @@ type WorkloadPattern struct {
 	PowerIncrease  float64       // Additional power draw during load
 }
 
+// mockGPUsPerNode is how many simulated GPUs share a simulated node
+const mockGPUsPerNode = 4
+
 // NewMockMetricsCollector creates a new mock metrics collector for demo purposes
 func NewMockMetricsCollector(collectInterval time.Duration, numGPUs int) *MockMetricsCollector {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -94,6 +98,7 @@ func NewMockMetricsCollector(collectInterval time.Duration, numGPUs int) *MockMe
 
 		config := MockGPUConfig{
 			Name:             gpuNames[nameIdx],
+			NodeID:           fmt.Sprintf("mock-node-%d", i/mockGPUsPerNode),
 			MemoryTotal:      memoryConfigs[memIdx],
 			BaseUtilization:  float64(10 + rand.Intn(20)),    // 10-30% base
 			UtilVariance:     float64(5 + rand.Intn(15)),     // 5-20% variance
@@ -381,6 +386,7 @@ func (mc *MockMetricsCollector) generateGPUMetrics(gpuID string, config MockGPUC
 
 	return GPUMetrics{
 		GPUID:              gpuID,
+		NodeID:             config.NodeID,
 		Name:               config.Name,
 		UtilizationGPU:     utilization,
 		UtilizationMemory:  memoryUtilization,
@@ -394,6 +400,8 @@ func (mc *MockMetricsCollector) generateGPUMetrics(gpuID string, config MockGPUC
 		ClockGraphics:      graphicsClock,
 		ClockMemory:        memoryClock,
 		ProcessCount:       len(mc.processes[gpuID]),
+		EncoderSessions:    int(utilization / 25),
+		DecoderSessions:    int(utilization / 30),
 		EncoderUtilization: utilization * 0.3, // Encoder typically lower
 		DecoderUtilization: utilization * 0.2, // Decoder typically lower
 		Timestamp:          timestamp,
@@ -0,0 +1,127 @@
+package gpu
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MPSConfig controls how GPUs shared through CUDA MPS (Multi-Process Service)
+// are detected and accounted
+type MPSConfig struct {
+	Enabled       bool   `yaml:"enabled" json:"enabled"`
+	ControlPath   string `yaml:"control_path" json:"control_path"`     // nvidia-cuda-mps-control binary
+	PipeDirectory string `yaml:"pipe_directory" json:"pipe_directory"` // CUDA_MPS_PIPE_DIRECTORY of the MPS daemon, when not the default
+}
+
+// DefaultMPSConfig returns MPS detection settings for the default MPS daemon
+func DefaultMPSConfig() MPSConfig {
+	return MPSConfig{
+		Enabled:     true,
+		ControlPath: "nvidia-cuda-mps-control",
+	}
+}
+
+// mpsServerName is the process name of the MPS server holding a GPU's context
+const mpsServerName = "nvidia-cuda-mps-server"
+
+// MPS process types, matching nvidia-smi's process table
+const (
+	ProcessTypeMPSServer = "M"
+	ProcessTypeMPSClient = "M+C"
+)
+
+// MPSClient is a process running its CUDA work through an MPS server. The GPU
+// time of MPS clients is merged into the server, so nvidia-smi cannot
+// attribute utilization to them; ActiveThreadPercentage is the share of the
+// GPU's threads each client of the server may use.
+type MPSClient struct {
+	PID                    int     `json:"pid"`
+	ServerPID              int     `json:"server_pid"`
+	ProcessName            string  `json:"process_name,omitempty"`
+	MemoryUsed             uint64  `json:"memory_used"` // MB, when the driver reports client memory
+	ActiveThreadPercentage float64 `json:"active_thread_percentage"`
+}
+
+// SetMPSConfig replaces the MPS detection settings; a zero ControlPath uses
+// the default
+func (mc *MetricsCollector) SetMPSConfig(config MPSConfig) {
+	if config.ControlPath == "" {
+		config.ControlPath = DefaultMPSConfig().ControlPath
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.mps = config
+}
+
+// mpsControl runs one MPS control command, which the control daemon reads
+// from standard input
+func (mc *MetricsCollector) mpsControl(ctx context.Context, command string) ([]byte, error) {
+	mc.mu.RLock()
+	runner, config := mc.runner, mc.mps
+	mc.mu.RUnlock()
+
+	spec := CommandSpec{Name: config.ControlPath, Stdin: command + "\n", Cacheable: true}
+	if config.PipeDirectory != "" {
+		spec.Env = append(os.Environ(), "CUDA_MPS_PIPE_DIRECTORY="+config.PipeDirectory)
+	}
+	return runner.Run(ctx, spec)
+}
+
+// collectMPSClients detects MPS servers among a GPU's processes and lists
+// their clients, marking the processes' types. It returns whether the GPU is
+// shared through MPS.
+func (mc *MetricsCollector) collectMPSClients(ctx context.Context, processes []GPUProcess) (bool, []MPSClient, error) {
+	mc.mu.RLock()
+	enabled := mc.mps.Enabled
+	mc.mu.RUnlock()
+	if !enabled {
+		return false, nil, nil
+	}
+
+	byPID := make(map[int]int, len(processes))
+	var servers []int
+	for i, process := range processes {
+		byPID[process.PID] = i
+		if strings.HasSuffix(process.ProcessName, mpsServerName) {
+			servers = append(servers, process.PID)
+		}
+	}
+	if len(servers) == 0 {
+		return false, nil, nil
+	}
+
+	var clients []MPSClient
+	for _, server := range servers {
+		processes[byPID[server]].Type = ProcessTypeMPSServer
+
+		output, err := mc.mpsControl(ctx, fmt.Sprintf("get_client_list %d", server))
+		if err != nil {
+			return true, clients, fmt.Errorf("failed to list MPS clients of server %d: %w", server, err)
+		}
+		percentage := 100.0
+		if output, err := mc.mpsControl(ctx, fmt.Sprintf("get_active_thread_percentage %d", server)); err == nil {
+			if value, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64); err == nil {
+				percentage = value
+			}
+		}
+
+		for _, field := range strings.Fields(string(output)) {
+			pid, err := strconv.Atoi(field)
+			if err != nil {
+				continue
+			}
+			client := MPSClient{PID: pid, ServerPID: server, ActiveThreadPercentage: percentage}
+			if i, exists := byPID[pid]; exists {
+				processes[i].Type = ProcessTypeMPSClient
+				client.ProcessName = processes[i].ProcessName
+				client.MemoryUsed = processes[i].MemoryUsed
+			}
+			clients = append(clients, client)
+		}
+	}
+	return true, clients, nil
+}
@@ -0,0 +1,64 @@
+package gpu
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollectorAccountsMPSClients(t *testing.T) {
+	collector := NewMetricsCollector(time.Second)
+	runner := NewCommandRunner(CommandRunnerConfig{})
+	fake := fakeNvidiaSMI(2, 0)
+	var commands []string
+	runner.exec = func(ctx context.Context, spec CommandSpec) ([]byte, error) {
+		switch {
+		case spec.Name == "nvidia-cuda-mps-control":
+			commands = append(commands, strings.TrimSpace(spec.Stdin))
+			if strings.HasPrefix(spec.Stdin, "get_client_list 500") {
+				return []byte("601\n602\n"), nil
+			}
+			return []byte("50.0\n"), nil
+		case strings.Contains(strings.Join(spec.Args, " "), "--id=0 --query-compute-apps"):
+			return []byte("500, nvidia-cuda-mps-server, 300\n601, python, 2048\n"), nil
+		}
+		return fake(ctx, spec)
+	}
+	collector.SetCommandRunner(runner)
+	collector.gpuIDs = []string{"0", "1"}
+
+	collector.collectMetrics(context.Background())
+
+	latest := collector.GetLatestMetrics()
+	if latest["1"].MPSShared || len(commands) != 2 {
+		t.Fatalf("GPU without an MPS server marked shared, control commands = %v", commands)
+	}
+	shared := latest["0"]
+	if !shared.MPSShared || len(shared.MPSClients) != 2 {
+		t.Fatalf("MPS metrics = %+v", shared)
+	}
+	client := shared.MPSClients[0]
+	if client.PID != 601 || client.ServerPID != 500 || client.ProcessName != "python" || client.MemoryUsed != 2048 || client.ActiveThreadPercentage != 50 {
+		t.Errorf("client = %+v", client)
+	}
+	if other := shared.MPSClients[1]; other.PID != 602 || other.MemoryUsed != 0 {
+		t.Errorf("client without driver memory = %+v", other)
+	}
+
+	processes := collector.GetRunningProcesses()["0"]
+	if processes[0].Type != ProcessTypeMPSServer || processes[1].Type != ProcessTypeMPSClient {
+		t.Errorf("process types = %+v", processes)
+	}
+}
+
+func TestCollectorSkipsMPSWhenDisabled(t *testing.T) {
+	collector := NewMetricsCollector(time.Second)
+	collector.SetMPSConfig(MPSConfig{Enabled: false})
+	processes := []GPUProcess{{PID: 500, ProcessName: "/usr/bin/nvidia-cuda-mps-server"}}
+
+	shared, clients, err := collector.collectMPSClients(context.Background(), processes)
+	if shared || clients != nil || err != nil || processes[0].Type != "" {
+		t.Errorf("collectMPSClients = %v, %v, %v", shared, clients, err)
+	}
+}
@@ -0,0 +1,118 @@
+package gpu
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// DefaultNodeID groups metrics that carry no node identity
+const DefaultNodeID = "local"
+
+// NodeStats aggregates the latest metrics of the GPUs on one node
+type NodeStats struct {
+	NodeID             string    `json:"node_id"`
+	Status             string    `json:"status"` // Worst GPU health: healthy, warning, critical
+	GPUIDs             []string  `json:"gpu_ids"`
+	TotalGPUs          int       `json:"total_gpus"`
+	ActiveGPUs         int       `json:"active_gpus"`
+	HealthyGPUs        int       `json:"healthy_gpus"`
+	AverageUtilization float64   `json:"average_utilization"`
+	AverageTemperature float64   `json:"average_temperature"`
+	MaxTemperature     float64   `json:"max_temperature"`
+	TotalPowerDraw     float64   `json:"total_power_draw"`
+	TotalPowerLimit    float64   `json:"total_power_limit"`
+	TotalMemoryMB      uint64    `json:"total_memory_mb"`
+	UsedMemoryMB       uint64    `json:"used_memory_mb"`
+	TotalProcesses     int       `json:"total_processes"`
+	Issues             []string  `json:"issues"` // Health issues prefixed with the GPU ID
+	Timestamp          time.Time `json:"timestamp"`
+}
+
+// localNodeID names the host a collector runs on
+func localNodeID() string {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return DefaultNodeID
+}
+
+// metricsNodeID returns the node a GPU's metrics came from
+func metricsNodeID(metrics GPUMetrics) string {
+	if metrics.NodeID == "" {
+		return DefaultNodeID
+	}
+	return metrics.NodeID
+}
+
+// healthRank orders health statuses from best to worst
+var healthRank = map[string]int{"healthy": 0, "warning": 1, "critical": 2}
+
+// AggregateNodes groups the latest GPU metrics by node
+func AggregateNodes(latestMetrics map[string]GPUMetrics, now time.Time) map[string]NodeStats {
+	nodes := make(map[string]*NodeStats)
+	for gpuID, metrics := range latestMetrics {
+		nodeID := metricsNodeID(metrics)
+		node, exists := nodes[nodeID]
+		if !exists {
+			node = &NodeStats{NodeID: nodeID, Status: "healthy", Issues: make([]string, 0), Timestamp: now}
+			nodes[nodeID] = node
+		}
+
+		node.GPUIDs = append(node.GPUIDs, gpuID)
+		node.TotalGPUs++
+		node.AverageUtilization += metrics.UtilizationGPU
+		node.AverageTemperature += metrics.Temperature
+		if metrics.Temperature > node.MaxTemperature {
+			node.MaxTemperature = metrics.Temperature
+		}
+		node.TotalPowerDraw += metrics.PowerDraw
+		node.TotalPowerLimit += metrics.PowerLimit
+		node.TotalMemoryMB += metrics.MemoryTotal
+		node.UsedMemoryMB += metrics.MemoryUsed
+		node.TotalProcesses += metrics.ProcessCount
+		if metrics.UtilizationGPU > 5.0 {
+			node.ActiveGPUs++
+		}
+
+		health := simpleHealthStatus(metrics)
+		if health.Status == "healthy" {
+			node.HealthyGPUs++
+		}
+		if healthRank[health.Status] > healthRank[node.Status] {
+			node.Status = health.Status
+		}
+		for _, issue := range health.Issues {
+			node.Issues = append(node.Issues, fmt.Sprintf("%s: %s", gpuID, issue))
+		}
+	}
+
+	result := make(map[string]NodeStats, len(nodes))
+	for nodeID, node := range nodes {
+		node.AverageUtilization /= float64(node.TotalGPUs)
+		node.AverageTemperature /= float64(node.TotalGPUs)
+		sort.Strings(node.GPUIDs)
+		sort.Strings(node.Issues)
+		result[nodeID] = *node
+	}
+	return result
+}
+
+// GetNodeStats returns per-node statistics from the last aggregation, sorted by node ID
+func (mas *MetricsAggregationService) GetNodeStats() []NodeStats {
+	mas.mu.RLock()
+	defer mas.mu.RUnlock()
+
+	if mas.clusterMetrics == nil {
+		return []NodeStats{}
+	}
+	nodes := make([]NodeStats, 0, len(mas.clusterMetrics.Nodes))
+	for _, node := range mas.clusterMetrics.Nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].NodeID < nodes[j].NodeID
+	})
+	return nodes
+}
@@ -0,0 +1,50 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAggregateNodesGroupsGPUsByNode(t *testing.T) {
+	now := time.Now()
+	latest := map[string]GPUMetrics{
+		"gpu-0": {GPUID: "gpu-0", NodeID: "node-a", UtilizationGPU: 80, Temperature: 60, PowerDraw: 300, PowerLimit: 400, MemoryTotal: 1000, MemoryUsed: 500, ProcessCount: 2},
+		"gpu-1": {GPUID: "gpu-1", NodeID: "node-a", UtilizationGPU: 0, Temperature: 90, PowerDraw: 100, PowerLimit: 400, MemoryTotal: 1000, MemoryUsed: 100},
+		"gpu-2": {GPUID: "gpu-2", NodeID: "node-b", UtilizationGPU: 50, Temperature: 40, PowerDraw: 200, PowerLimit: 300, MemoryTotal: 2000, MemoryUsed: 1700},
+		"gpu-3": {GPUID: "gpu-3", UtilizationGPU: 10, Temperature: 40, MemoryTotal: 1000},
+	}
+
+	nodes := AggregateNodes(latest, now)
+	if len(nodes) != 3 {
+		t.Fatalf("Expected node-a, node-b and the default node, got %v", nodes)
+	}
+
+	a := nodes["node-a"]
+	if a.TotalGPUs != 2 || a.ActiveGPUs != 1 || a.HealthyGPUs != 1 || a.Status != "critical" {
+		t.Errorf("Unexpected node-a counts %+v", a)
+	}
+	if a.AverageUtilization != 40 || a.MaxTemperature != 90 || a.TotalPowerDraw != 400 || a.TotalPowerLimit != 800 {
+		t.Errorf("Unexpected node-a aggregates %+v", a)
+	}
+	if a.UsedMemoryMB != 600 || a.TotalProcesses != 2 || len(a.GPUIDs) != 2 || a.GPUIDs[0] != "gpu-0" {
+		t.Errorf("Unexpected node-a totals %+v", a)
+	}
+	if len(a.Issues) != 1 || a.Issues[0] != "gpu-1: Temperature too high: 90.0°C" {
+		t.Errorf("Expected the overheating GPU reported, got %v", a.Issues)
+	}
+
+	if b := nodes["node-b"]; b.Status != "warning" || b.HealthyGPUs != 0 {
+		t.Errorf("Expected node-b warning on memory, got %+v", b)
+	}
+	if local := nodes[DefaultNodeID]; local.TotalGPUs != 1 || local.Status != "healthy" {
+		t.Errorf("Expected GPUs without a node grouped under %s, got %+v", DefaultNodeID, local)
+	}
+}
+
+func TestMockCollectorSpreadsGPUsAcrossNodes(t *testing.T) {
+	collector := NewMockMetricsCollector(time.Second, 6)
+	if collector.gpuConfigs["gpu-3"].NodeID != "mock-node-0" || collector.gpuConfigs["gpu-4"].NodeID != "mock-node-1" {
+		t.Errorf("Expected four mock GPUs per node, got %s and %s",
+			collector.gpuConfigs["gpu-3"].NodeID, collector.gpuConfigs["gpu-4"].NodeID)
+	}
+}
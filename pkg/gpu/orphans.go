@@ -0,0 +1,333 @@
+package gpu
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RemediationTerminateOrphan is the remediation sending SIGTERM to an orphaned
+// GPU process; its target is OrphanTarget(gpuID, pid)
+const RemediationTerminateOrphan = "terminate_orphan"
+
+// OrphanConfig controls when a process holding GPU memory without an owning
+// workload is reported as an orphan, and whether it may be cleaned up
+type OrphanConfig struct {
+	// A process must hold at least MinMemoryMB without an owner for
+	// GracePeriod, so jobs that are still starting are not reported
+	GracePeriod time.Duration `yaml:"grace_period" json:"grace_period"`
+	MinMemoryMB uint64        `yaml:"min_memory_mb" json:"min_memory_mb"`
+
+	// CleanupEnabled allows operators to terminate orphans through the
+	// RemediationTerminateOrphan remediation
+	CleanupEnabled bool `yaml:"cleanup_enabled" json:"cleanup_enabled"`
+}
+
+// DefaultOrphanConfig returns orphan detection settings with cleanup disabled
+func DefaultOrphanConfig() OrphanConfig {
+	return OrphanConfig{
+		GracePeriod: 5 * time.Minute,
+		MinMemoryMB: 1,
+	}
+}
+
+// OrphanProcess is a process holding GPU memory that no known workload or pod owns
+type OrphanProcess struct {
+	GPUID       string    `json:"gpu_id"`
+	PID         int       `json:"pid"`
+	ProcessName string    `json:"process_name"`
+	MemoryUsed  uint64    `json:"memory_used"` // MB
+	FirstSeen   time.Time `json:"first_seen"`  // First seen without an owner
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+// OrphanTarget names an orphaned process as the target of a remediation
+func OrphanTarget(gpuID string, pid int) string {
+	return fmt.Sprintf("%s/%d", gpuID, pid)
+}
+
+// processIdentity tells a process apart from a later one reusing its PID
+type processIdentity struct {
+	name  string // Command name, as in /proc/<pid>/stat
+	start uint64 // Start time in clock ticks after boot
+}
+
+// readProcessIdentity reads the name and start time of a process from /proc
+func readProcessIdentity(pid int) (processIdentity, error) {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return processIdentity{}, err
+	}
+	// The name is in parentheses and may contain spaces and parentheses itself
+	open, end := strings.IndexByte(string(stat), '('), strings.LastIndexByte(string(stat), ')')
+	if open < 0 || end < open {
+		return processIdentity{}, fmt.Errorf("malformed /proc/%d/stat", pid)
+	}
+	fields := strings.Fields(string(stat[end+1:]))
+	// Fields from the state on; the start time is the 22nd field of the line
+	if len(fields) < 20 {
+		return processIdentity{}, fmt.Errorf("malformed /proc/%d/stat", pid)
+	}
+	start, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return processIdentity{}, fmt.Errorf("malformed /proc/%d/stat: %v", pid, err)
+	}
+	return processIdentity{name: string(stat[open+1 : end]), start: start}, nil
+}
+
+// sameProcessName reports whether nvidia-smi's process name, often a path,
+// names the command in /proc, which the kernel truncates to 15 bytes
+func sameProcessName(observed, command string) bool {
+	name := filepath.Base(observed)
+	if len(name) > 15 {
+		name = name[:15]
+	}
+	return name == command
+}
+
+// OwnerResolvers combines resolvers for different sources of workloads, e.g.
+// the scheduler and Kubernetes pods, naming the first owner found
+func OwnerResolvers(resolvers ...WorkloadResolver) WorkloadResolver {
+	return func(gpuID string, pid int) string {
+		for _, resolver := range resolvers {
+			if owner := resolver(gpuID, pid); owner != "" {
+				return owner
+			}
+		}
+		return ""
+	}
+}
+
+// orphanCandidate is a process seen without an owner
+type orphanCandidate struct {
+	process  OrphanProcess
+	identity processIdentity // Zero when /proc could not be read
+	reported bool
+}
+
+// OrphanDetector finds processes that hold GPU memory after the workload
+// that started them is gone, e.g. a worker left behind by a failed job
+type OrphanDetector struct {
+	config     OrphanConfig
+	resolver   WorkloadResolver
+	kill       func(pid int) error                    // Replaced in tests
+	identify   func(pid int) (processIdentity, error) // Replaced in tests
+	candidates map[string]*orphanCandidate            // OrphanTarget(gpu, pid)
+	listeners  []func(OrphanProcess)
+	detected   int
+	cleaned    int
+	mu         sync.Mutex
+}
+
+// NewOrphanDetector creates a detector judging ownership with resolver; zero
+// config fields use the defaults
+func NewOrphanDetector(config OrphanConfig, resolver WorkloadResolver) *OrphanDetector {
+	defaults := DefaultOrphanConfig()
+	if config.GracePeriod <= 0 {
+		config.GracePeriod = defaults.GracePeriod
+	}
+	if config.MinMemoryMB == 0 {
+		config.MinMemoryMB = defaults.MinMemoryMB
+	}
+	return &OrphanDetector{
+		config:     config,
+		resolver:   resolver,
+		kill:       terminateProcess,
+		identify:   readProcessIdentity,
+		candidates: make(map[string]*orphanCandidate),
+	}
+}
+
+// terminateProcess asks a process to exit
+func terminateProcess(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Signal(syscall.SIGTERM)
+}
+
+// OnOrphan registers a listener for newly detected orphans
+func (d *OrphanDetector) OnOrphan(listener func(OrphanProcess)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.listeners = append(d.listeners, listener)
+}
+
+// SetResolver replaces the resolver judging ownership, e.g. once a scheduler
+// is available. Without a resolver no process is reported.
+func (d *OrphanDetector) SetResolver(resolver WorkloadResolver) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resolver = resolver
+}
+
+// RegisterRemediations registers the RemediationTerminateOrphan remediation,
+// through which operators clean up orphans
+func (d *OrphanDetector) RegisterRemediations(remediator *Remediator) {
+	remediator.Register(RemediationTerminateOrphan, d.terminate)
+}
+
+// Observe records the processes currently on a GPU and returns processes that
+// became orphans. Processes that exited or gained an owner are forgotten.
+func (d *OrphanDetector) Observe(gpuID string, processes []GPUProcess, at time.Time) []OrphanProcess {
+	d.mu.Lock()
+	resolver := d.resolver
+	d.mu.Unlock()
+
+	// Resolve owners and read identities before locking, resolvers may query
+	// the scheduler
+	owners := make([]string, len(processes))
+	identities := make([]processIdentity, len(processes))
+	for i, process := range processes {
+		if resolver == nil || process.MemoryUsed < d.config.MinMemoryMB {
+			continue
+		}
+		if owners[i] = resolver(gpuID, process.PID); owners[i] == "" {
+			identities[i], _ = d.identify(process.PID)
+		}
+	}
+
+	d.mu.Lock()
+	seen := make(map[string]bool, len(processes))
+	var orphans []OrphanProcess
+
+	for i, process := range processes {
+		if owners[i] != "" || resolver == nil || process.MemoryUsed < d.config.MinMemoryMB {
+			continue
+		}
+		key := OrphanTarget(gpuID, process.PID)
+		seen[key] = true
+		candidate, exists := d.candidates[key]
+		reused := exists && candidate.identity.start != 0 && identities[i].start != 0 && candidate.identity.start != identities[i].start
+		if !exists || reused || candidate.process.ProcessName != process.ProcessName {
+			// A reused PID is a new process
+			candidate = &orphanCandidate{process: OrphanProcess{
+				GPUID:       gpuID,
+				PID:         process.PID,
+				ProcessName: process.ProcessName,
+				FirstSeen:   at,
+			}, identity: identities[i]}
+			d.candidates[key] = candidate
+		} else if candidate.identity.start == 0 {
+			candidate.identity = identities[i]
+		}
+		candidate.process.MemoryUsed = process.MemoryUsed
+		candidate.process.LastSeen = at
+
+		if !candidate.reported && at.Sub(candidate.process.FirstSeen) >= d.config.GracePeriod {
+			candidate.reported = true
+			d.detected++
+			orphans = append(orphans, candidate.process)
+		}
+	}
+
+	prefix := gpuID + "/"
+	for key := range d.candidates {
+		if strings.HasPrefix(key, prefix) && !seen[key] {
+			delete(d.candidates, key)
+		}
+	}
+	listeners := append([]func(OrphanProcess){}, d.listeners...)
+	d.mu.Unlock()
+
+	for _, orphan := range orphans {
+		for _, listener := range listeners {
+			listener(orphan)
+		}
+	}
+	return orphans
+}
+
+// Orphans returns the current orphans ordered by GPU and PID
+func (d *OrphanDetector) Orphans() []OrphanProcess {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	orphans := make([]OrphanProcess, 0)
+	for _, candidate := range d.candidates {
+		if candidate.reported {
+			orphans = append(orphans, candidate.process)
+		}
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		if orphans[i].GPUID != orphans[j].GPUID {
+			return orphans[i].GPUID < orphans[j].GPUID
+		}
+		return orphans[i].PID < orphans[j].PID
+	})
+	return orphans
+}
+
+// terminate handles RemediationTerminateOrphan for target. It refuses when
+// cleanup is disabled, the process is not a current orphan, it has gained an
+// owner, or its PID now belongs to another process.
+func (d *OrphanDetector) terminate(target string) (map[string]interface{}, error) {
+	if !d.config.CleanupEnabled {
+		return nil, fmt.Errorf("orphan cleanup is disabled")
+	}
+
+	d.mu.Lock()
+	candidate, exists := d.candidates[target]
+	if !exists || !candidate.reported {
+		d.mu.Unlock()
+		return nil, fmt.Errorf("%s is not a known orphan", target)
+	}
+	process, observed, resolver := candidate.process, candidate.identity, d.resolver
+	d.mu.Unlock()
+
+	details := map[string]interface{}{
+		"gpu_id":       process.GPUID,
+		"pid":          process.PID,
+		"process_name": process.ProcessName,
+		"memory_mb":    process.MemoryUsed,
+	}
+	if owner := resolver(process.GPUID, process.PID); owner != "" {
+		return details, fmt.Errorf("process %d on GPU %s is now owned by %s", process.PID, process.GPUID, owner)
+	}
+
+	// The orphan may have exited and its PID been given to another process
+	// since it was observed
+	current, err := d.identify(process.PID)
+	if err != nil {
+		return details, fmt.Errorf("cannot verify process %d: %v", process.PID, err)
+	}
+	if observed.start == 0 || current.start != observed.start || !sameProcessName(process.ProcessName, current.name) {
+		return details, fmt.Errorf("process %d is no longer the observed %s", process.PID, process.ProcessName)
+	}
+	if err := d.kill(process.PID); err != nil {
+		return details, err
+	}
+
+	d.mu.Lock()
+	d.cleaned++
+	d.mu.Unlock()
+	return details, nil
+}
+
+// GetStats returns orphan detection statistics
+func (d *OrphanDetector) GetStats() map[string]interface{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	orphans := 0
+	for _, candidate := range d.candidates {
+		if candidate.reported {
+			orphans++
+		}
+	}
+	return map[string]interface{}{
+		"candidates":      len(d.candidates),
+		"orphans":         orphans,
+		"detected":        d.detected,
+		"cleaned":         d.cleaned,
+		"grace_period":    d.config.GracePeriod.String(),
+		"cleanup_enabled": d.config.CleanupEnabled,
+	}
+}
@@ -0,0 +1,173 @@
+package gpu
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestOrphanDetectorReportsUnownedProcessesAfterGracePeriod(t *testing.T) {
+	owned := map[int]string{100: "train (w1)"}
+	detector := NewOrphanDetector(OrphanConfig{GracePeriod: 5 * time.Minute}, func(gpuID string, pid int) string {
+		return owned[pid]
+	})
+	var reported []OrphanProcess
+	detector.OnOrphan(func(orphan OrphanProcess) { reported = append(reported, orphan) })
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	processes := []GPUProcess{
+		{PID: 100, ProcessName: "python", MemoryUsed: 8000},
+		{PID: 200, ProcessName: "python", MemoryUsed: 4000},
+		{PID: 300, ProcessName: "idle", MemoryUsed: 0},
+	}
+	if orphans := detector.Observe("0", processes, start); len(orphans) != 0 {
+		t.Fatalf("reported %+v before the grace period", orphans)
+	}
+	orphans := detector.Observe("0", processes, start.Add(5*time.Minute))
+	if len(orphans) != 1 || orphans[0].PID != 200 || orphans[0].MemoryUsed != 4000 || !orphans[0].FirstSeen.Equal(start) {
+		t.Fatalf("orphans = %+v, want the unowned process holding memory", orphans)
+	}
+	if more := detector.Observe("0", processes, start.Add(6*time.Minute)); len(more) != 0 || len(reported) != 1 {
+		t.Errorf("orphan reported again: %+v", more)
+	}
+	if listed := detector.Orphans(); len(listed) != 1 || listed[0].PID != 200 {
+		t.Errorf("Orphans = %+v", listed)
+	}
+
+	// A process that gains an owner or exits is forgotten
+	owned[200] = "train (w2)"
+	detector.Observe("0", processes, start.Add(7*time.Minute))
+	if listed := detector.Orphans(); len(listed) != 0 {
+		t.Errorf("owned process still listed: %+v", listed)
+	}
+	if stats := detector.GetStats(); stats["detected"] != 1 || stats["orphans"] != 0 {
+		t.Errorf("stats = %v", stats)
+	}
+}
+
+func TestOrphanDetectorCleanup(t *testing.T) {
+	owner := ""
+	detector := NewOrphanDetector(OrphanConfig{GracePeriod: time.Minute}, func(gpuID string, pid int) string {
+		return owner
+	})
+	var killed []int
+	detector.kill = func(pid int) error {
+		killed = append(killed, pid)
+		return nil
+	}
+	identity := processIdentity{name: "worker", start: 1000}
+	detector.identify = func(pid int) (processIdentity, error) { return identity, nil }
+
+	remediator := NewRemediator()
+	detector.RegisterRemediations(remediator)
+	var audited []Remediation
+	remediator.OnRemediation(func(remediation Remediation) { audited = append(audited, remediation) })
+	cleanup := func(pid int) (Remediation, error) {
+		return remediator.Remediate("alice", RemediationTerminateOrphan, OrphanTarget("1", pid))
+	}
+
+	start := time.Now()
+	processes := []GPUProcess{{PID: 42, ProcessName: "/usr/bin/worker", MemoryUsed: 2000}}
+	detector.Observe("1", processes, start)
+	detector.Observe("1", processes, start.Add(time.Minute))
+
+	if _, err := cleanup(42); err == nil {
+		t.Fatal("cleanup ran while disabled")
+	}
+	detector.config.CleanupEnabled = true
+	if _, err := cleanup(43); err == nil {
+		t.Error("cleaned up a process that is not an orphan")
+	}
+
+	owner = "train (w3)"
+	if _, err := cleanup(42); err == nil || len(killed) != 0 {
+		t.Errorf("killed a process that gained an owner: %v", err)
+	}
+	owner = ""
+	remediation, err := cleanup(42)
+	if err != nil || len(killed) != 1 || killed[0] != 42 || remediation.Actor != "alice" || remediation.Details["process_name"] != "/usr/bin/worker" {
+		t.Fatalf("Remediate = %+v, %v", remediation, err)
+	}
+	if len(audited) != 4 || audited[0].Error == "" || audited[2].Error == "" || audited[3].Error != "" {
+		t.Errorf("audited = %+v, want the refused and the successful attempts", audited)
+	}
+	if stats := detector.GetStats(); stats["cleaned"] != 1 {
+		t.Errorf("stats = %v", stats)
+	}
+}
+
+func TestOrphanCleanupRefusesReusedPIDs(t *testing.T) {
+	detector := NewOrphanDetector(OrphanConfig{GracePeriod: time.Minute, CleanupEnabled: true}, func(gpuID string, pid int) string {
+		return ""
+	})
+	var killed []int
+	detector.kill = func(pid int) error {
+		killed = append(killed, pid)
+		return nil
+	}
+	identity := processIdentity{name: "python3", start: 1000}
+	detector.identify = func(pid int) (processIdentity, error) { return identity, nil }
+	remediator := NewRemediator()
+	detector.RegisterRemediations(remediator)
+
+	start := time.Now()
+	processes := []GPUProcess{{PID: 7, ProcessName: "python3", MemoryUsed: 2000}}
+	detector.Observe("0", processes, start)
+	detector.Observe("0", processes, start.Add(time.Minute))
+
+	// The orphan exited and an unrelated process was given its PID
+	identity = processIdentity{name: "sshd", start: 5000}
+	if _, err := remediator.Remediate("alice", RemediationTerminateOrphan, OrphanTarget("0", 7)); err == nil {
+		t.Error("terminated a process reusing the orphan's PID")
+	}
+	// Same name, started later
+	identity = processIdentity{name: "python3", start: 5000}
+	if _, err := remediator.Remediate("alice", RemediationTerminateOrphan, OrphanTarget("0", 7)); err == nil {
+		t.Error("terminated a later process with the orphan's PID and name")
+	}
+	if len(killed) != 0 {
+		t.Errorf("killed = %v", killed)
+	}
+
+	// A reused PID seen by the next observation starts a new grace period
+	detector.Observe("0", processes, start.Add(2*time.Minute))
+	if listed := detector.Orphans(); len(listed) != 0 {
+		t.Errorf("Orphans = %+v, want the new process in its grace period", listed)
+	}
+}
+
+func TestReadProcessIdentity(t *testing.T) {
+	self, err := readProcessIdentity(os.Getpid())
+	if err != nil {
+		t.Skipf("/proc unavailable: %v", err)
+	}
+	if self.start == 0 || !sameProcessName(os.Args[0], self.name) {
+		t.Errorf("identity = %+v for %s", self, os.Args[0])
+	}
+	again, _ := readProcessIdentity(os.Getpid())
+	if again != self {
+		t.Errorf("identity changed from %+v to %+v", self, again)
+	}
+}
+
+func TestTerminateProcess(t *testing.T) {
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("sleep unavailable: %v", err)
+	}
+	if err := terminateProcess(cmd.Process.Pid); err != nil {
+		t.Fatalf("terminateProcess: %v", err)
+	}
+	done := make(chan error)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected the process to be terminated by a signal")
+		}
+	case <-time.After(5 * time.Second):
+		cmd.Process.Kill()
+		t.Fatalf("process %d survived SIGTERM", cmd.Process.Pid)
+	}
+}
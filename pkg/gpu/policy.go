@@ -0,0 +1,102 @@
+package gpu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchedulingPolicy is the part of the scheduler configuration that can be
+// replaced while running, for example by a configuration bundle
+type SchedulingPolicy struct {
+	Strategy        SchedulingStrategy    `yaml:"strategy" json:"strategy"`
+	UtilizationGoal float64               `yaml:"utilization_goal" json:"utilization_goal"` // Percent
+	Pools           map[string]PoolConfig `yaml:"pools" json:"pools,omitempty"`
+}
+
+// knownStrategies lists the strategies the scheduler can run
+var knownStrategies = []SchedulingStrategy{
+	StrategyRoundRobin, StrategyLeastUtilized, StrategyBestFit, StrategyPriority, StrategyEnergyAware,
+}
+
+// Validate reports an unknown strategy or an out-of-range utilization goal
+func (p SchedulingPolicy) Validate() error {
+	var problems []string
+	if !isKnownStrategy(p.Strategy) {
+		problems = append(problems, fmt.Sprintf("unknown strategy %q", p.Strategy))
+	}
+	if p.UtilizationGoal < 0 || p.UtilizationGoal > 100 {
+		problems = append(problems, fmt.Sprintf("utilization goal must be between 0 and 100, got %g", p.UtilizationGoal))
+	}
+	names := make([]string, 0, len(p.Pools))
+	for name := range p.Pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pool := p.Pools[name]
+		if pool.Strategy != "" && !isKnownStrategy(pool.Strategy) {
+			problems = append(problems, fmt.Sprintf("pool %s has unknown strategy %q", name, pool.Strategy))
+		}
+		if pool.MaxGPUs < 0 {
+			problems = append(problems, fmt.Sprintf("pool %s max GPUs must not be negative", name))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid scheduling policy: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// isKnownStrategy reports whether a strategy is empty or one the scheduler runs
+func isKnownStrategy(strategy SchedulingStrategy) bool {
+	if strategy == "" {
+		return true
+	}
+	for _, known := range knownStrategies {
+		if strategy == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy returns the scheduler's current strategy, utilization goal and pools
+func (s *Scheduler) Policy() SchedulingPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pools := make(map[string]PoolConfig, len(s.config.Pools))
+	for name, pool := range s.config.Pools {
+		pools[name] = pool
+	}
+	return SchedulingPolicy{Strategy: s.strategy, UtilizationGoal: s.config.UtilizationGoal, Pools: pools}
+}
+
+// ApplyPolicy replaces the strategy, utilization goal and pools used by
+// later scheduling passes. An empty strategy or zero goal keeps the current
+// one; pools are replaced as a whole. Running workloads are not moved.
+func (s *Scheduler) ApplyPolicy(policy SchedulingPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Copy so a config shared with the caller is left untouched
+	config := *s.config
+	config.Pools = make(map[string]PoolConfig, len(policy.Pools))
+	for name, pool := range policy.Pools {
+		config.Pools[name] = pool
+	}
+	if policy.UtilizationGoal != 0 {
+		config.UtilizationGoal = policy.UtilizationGoal
+	}
+	s.config = &config
+	if policy.Strategy != "" {
+		s.strategy = policy.Strategy
+	}
+	s.wake()
+	return nil
+}
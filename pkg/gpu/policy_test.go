@@ -0,0 +1,51 @@
+package gpu
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyPolicyReplacesStrategyAndPools(t *testing.T) {
+	config := DefaultSchedulerConfig()
+	config.Pools["ci"] = PoolConfig{MaxGPUs: 1}
+	scheduler := NewSchedulerWithConfig(StrategyLeastUtilized, config)
+
+	err := scheduler.ApplyPolicy(SchedulingPolicy{
+		Strategy: StrategyBestFit,
+		Pools:    map[string]PoolConfig{"research": {Strategy: StrategyPriority, MaxGPUs: 4}},
+	})
+	if err != nil {
+		t.Fatalf("ApplyPolicy: %v", err)
+	}
+
+	policy := scheduler.Policy()
+	if policy.Strategy != StrategyBestFit || policy.UtilizationGoal != 80 {
+		t.Errorf("Expected best fit with the goal kept, got %+v", policy)
+	}
+	if _, exists := policy.Pools["ci"]; exists || policy.Pools["research"].MaxGPUs != 4 {
+		t.Errorf("Expected pools replaced as a whole, got %+v", policy.Pools)
+	}
+	if scheduler.poolStrategy("research") != StrategyPriority {
+		t.Errorf("Expected the research pool's strategy used, got %s", scheduler.poolStrategy("research"))
+	}
+	if _, exists := config.Pools["research"]; exists {
+		t.Error("Expected the caller's config left untouched")
+	}
+}
+
+func TestApplyPolicyRejectsInvalidPolicy(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+
+	err := scheduler.ApplyPolicy(SchedulingPolicy{
+		Strategy:        "fastest",
+		UtilizationGoal: 120,
+		Pools:           map[string]PoolConfig{"ci": {Strategy: "random"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), `unknown strategy "fastest"`) ||
+		!strings.Contains(err.Error(), "between 0 and 100") || !strings.Contains(err.Error(), `pool ci has unknown strategy "random"`) {
+		t.Errorf("Expected every problem reported, got %v", err)
+	}
+	if scheduler.Policy().Strategy != StrategyLeastUtilized {
+		t.Error("Expected the policy unchanged after a rejected update")
+	}
+}
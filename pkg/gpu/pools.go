@@ -0,0 +1,173 @@
+package gpu
+
+import (
+	"sort"
+)
+
+// PoolConfig configures a named partition of GPUs such as prod-inference,
+// research or ci. Workloads only run on GPUs in their own pool; GPUs and
+// workloads without a pool form the default pool.
+type PoolConfig struct {
+	Strategy    SchedulingStrategy `yaml:"strategy" json:"strategy,omitempty"`           // Scheduler strategy when empty
+	MaxGPUs     int                `yaml:"max_gpus" json:"max_gpus,omitempty"`           // Most GPUs the pool's workloads may occupy, 0 for no limit
+	MaxMemoryMB uint64             `yaml:"max_memory_mb" json:"max_memory_mb,omitempty"` // Most memory the pool's workloads may hold, 0 for no limit
+}
+
+// PoolStatus summarizes a pool for dashboards
+type PoolStatus struct {
+	Name          string             `json:"name"`
+	Strategy      SchedulingStrategy `json:"strategy"`
+	GPUs          int                `json:"gpus"`
+	BusyGPUs      int                `json:"busy_gpus"`
+	MemoryTotalMB uint64             `json:"memory_total_mb"`
+	MemoryUsedMB  uint64             `json:"memory_used_mb"`
+	Running       int                `json:"running"`
+	Pending       int                `json:"pending"`
+	MaxGPUs       int                `json:"max_gpus,omitempty"`
+	MaxMemoryMB   uint64             `json:"max_memory_mb,omitempty"`
+}
+
+// poolUsage is what a pool's workloads hold during a scheduling pass
+type poolUsage struct {
+	gpus   int
+	memory uint64
+}
+
+// poolStrategy returns the strategy used for a pool's workloads
+func (s *Scheduler) poolStrategy(pool string) SchedulingStrategy {
+	if config, exists := s.config.Pools[pool]; exists && config.Strategy != "" {
+		return config.Strategy
+	}
+	return s.strategy
+}
+
+// measurePools records what each pool holds at the start of a pass; callers must hold the lock
+func (s *Scheduler) measurePools() {
+	s.poolUsage = make(map[string]*poolUsage)
+	for _, gpu := range s.gpus {
+		for _, workload := range []*Workload{gpu.CurrentWorkload, gpu.ColocatedWorkload} {
+			if workload != nil {
+				s.chargePool(workload, workload == gpu.CurrentWorkload)
+			}
+		}
+	}
+}
+
+// chargePool adds a placed workload to its pool's usage for the pass; callers must hold the lock
+func (s *Scheduler) chargePool(workload *Workload, ownsGPU bool) {
+	if s.poolUsage == nil {
+		return
+	}
+	usage, exists := s.poolUsage[workload.Pool]
+	if !exists {
+		usage = &poolUsage{}
+		s.poolUsage[workload.Pool] = usage
+	}
+	if ownsGPU {
+		usage.gpus++
+	}
+	usage.memory += workload.MemoryRequired
+}
+
+// withinQuota reports whether placing a workload keeps its pool within
+// quota; ownsGPU is false for co-located placements. Callers must hold the lock.
+func (s *Scheduler) withinQuota(workload *Workload, ownsGPU bool) bool {
+	config, exists := s.config.Pools[workload.Pool]
+	if !exists {
+		return true
+	}
+	usage := s.poolUsage[workload.Pool]
+	if usage == nil {
+		usage = &poolUsage{}
+	}
+	if ownsGPU && config.MaxGPUs > 0 && usage.gpus >= config.MaxGPUs {
+		return false
+	}
+	return config.MaxMemoryMB == 0 || usage.memory+workload.MemoryRequired <= config.MaxMemoryMB
+}
+
+// schedulePools runs each pool's strategy over its own queued workloads,
+// keeping the queue order of workloads left waiting; callers must hold the lock
+func (s *Scheduler) schedulePools() error {
+	pools := make([]string, 0, 1)
+	queues := make(map[string][]*Workload)
+	for _, workload := range s.workloadQueue {
+		if _, exists := queues[workload.Pool]; !exists {
+			pools = append(pools, workload.Pool)
+		}
+		queues[workload.Pool] = append(queues[workload.Pool], workload)
+	}
+	if len(pools) == 1 {
+		return s.runStrategy(s.poolStrategy(pools[0]))
+	}
+
+	position := make(map[*Workload]int, len(s.workloadQueue))
+	for i, workload := range s.workloadQueue {
+		position[workload] = i
+	}
+	remaining := make([]*Workload, 0, len(s.workloadQueue))
+	for _, pool := range pools {
+		s.workloadQueue = queues[pool]
+		if err := s.runStrategy(s.poolStrategy(pool)); err != nil {
+			return err
+		}
+		remaining = append(remaining, s.workloadQueue...)
+	}
+	sort.SliceStable(remaining, func(i, j int) bool {
+		return position[remaining[i]] < position[remaining[j]]
+	})
+	s.workloadQueue = remaining
+	return nil
+}
+
+// GetPools returns the status of every configured pool and every pool in use
+func (s *Scheduler) GetPools() []PoolStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pools := make(map[string]*PoolStatus)
+	pool := func(name string) *PoolStatus {
+		status, exists := pools[name]
+		if !exists {
+			config := s.config.Pools[name]
+			status = &PoolStatus{
+				Name:        name,
+				Strategy:    s.poolStrategy(name),
+				MaxGPUs:     config.MaxGPUs,
+				MaxMemoryMB: config.MaxMemoryMB,
+			}
+			pools[name] = status
+		}
+		return status
+	}
+
+	for name := range s.config.Pools {
+		pool(name)
+	}
+	for _, gpu := range s.gpus {
+		status := pool(gpu.Pool)
+		status.GPUs++
+		status.MemoryTotalMB += gpu.MemoryTotal
+		status.MemoryUsedMB += gpu.MemoryUsed
+		if gpu.CurrentWorkload != nil {
+			status.BusyGPUs++
+		}
+		for _, workload := range []*Workload{gpu.CurrentWorkload, gpu.ColocatedWorkload} {
+			if workload != nil {
+				pool(workload.Pool).Running++
+			}
+		}
+	}
+	for _, workload := range s.workloadQueue {
+		pool(workload.Pool).Pending++
+	}
+
+	statuses := make([]PoolStatus, 0, len(pools))
+	for _, status := range pools {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
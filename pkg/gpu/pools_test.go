@@ -0,0 +1,96 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPoolsPartitionGPUsWithTheirOwnStrategies(t *testing.T) {
+	config := DefaultSchedulerConfig()
+	config.Pools["prod-inference"] = PoolConfig{Strategy: StrategyBestFit}
+	config.Pools["ci"] = PoolConfig{MaxGPUs: 1}
+	scheduler := NewSchedulerWithConfig(StrategyLeastUtilized, config)
+	scheduler.RegisterGPU(&GPU{ID: "prod-0", Pool: "prod-inference", MemoryTotal: 80000, Available: true})
+	scheduler.RegisterGPU(&GPU{ID: "prod-1", Pool: "prod-inference", MemoryTotal: 24000, Available: true, Utilization: 90})
+	scheduler.RegisterGPU(&GPU{ID: "ci-0", Pool: "ci", MemoryTotal: 16000, Available: true})
+	scheduler.RegisterGPU(&GPU{ID: "ci-1", Pool: "ci", MemoryTotal: 16000, Available: true})
+	scheduler.RegisterGPU(&GPU{ID: "shared-0", MemoryTotal: 16000, Available: true})
+
+	serve := &Workload{ID: "serve", Pool: "prod-inference", MemoryRequired: 16000}
+	test1 := &Workload{ID: "test-1", Pool: "ci", MemoryRequired: 4000}
+	test2 := &Workload{ID: "test-2", Pool: "ci", MemoryRequired: 4000}
+	adhoc := &Workload{ID: "adhoc", MemoryRequired: 4000}
+	stray := &Workload{ID: "stray", Pool: "research", MemoryRequired: 4000}
+	for _, workload := range []*Workload{test1, serve, test2, stray, adhoc} {
+		scheduler.SubmitWorkload(workload)
+	}
+	scheduler.Schedule()
+
+	// Best fit picks the tighter GPU even though least utilized would not
+	if serve.AssignedGPU != "prod-1" {
+		t.Errorf("Expected the prod workload best-fit onto prod-1, got %q", serve.AssignedGPU)
+	}
+	if test1.Pool != "ci" || (test1.AssignedGPU != "ci-0" && test1.AssignedGPU != "ci-1") {
+		t.Errorf("Expected test-1 on a ci GPU, got %q", test1.AssignedGPU)
+	}
+	if test2.Status != WorkloadPending {
+		t.Errorf("Expected test-2 held back by the ci quota, got it on %q", test2.AssignedGPU)
+	}
+	if adhoc.AssignedGPU != "shared-0" {
+		t.Errorf("Expected the unpooled workload on the default pool, got %q", adhoc.AssignedGPU)
+	}
+	if stray.Status != WorkloadPending {
+		t.Errorf("Expected a workload for a pool without GPUs to wait, got it on %q", stray.AssignedGPU)
+	}
+	if queued := scheduler.workloadQueue; len(queued) != 2 || queued[0] != test2 || queued[1] != stray {
+		t.Errorf("Expected waiting workloads to keep their queue order, got %v", queued)
+	}
+
+	decisions := scheduler.GetDecisions(time.Time{})
+	for _, decision := range decisions {
+		if decision.WorkloadID == "serve" && decision.Strategy != StrategyBestFit {
+			t.Errorf("Expected the prod decision made by best fit, got %s", decision.Strategy)
+		}
+	}
+
+	scheduler.CompleteWorkload("test-1")
+	scheduler.Schedule()
+	if test2.Status != WorkloadRunning {
+		t.Errorf("Expected test-2 placed once the ci pool was under quota, got %s", test2.Status)
+	}
+
+	pools := make(map[string]PoolStatus)
+	for _, pool := range scheduler.GetPools() {
+		pools[pool.Name] = pool
+	}
+	if len(pools) != 4 {
+		t.Fatalf("Expected default, ci, prod-inference and research pools, got %+v", pools)
+	}
+	if ci := pools["ci"]; ci.GPUs != 2 || ci.BusyGPUs != 1 || ci.Running != 1 || ci.MaxGPUs != 1 || ci.Strategy != StrategyLeastUtilized {
+		t.Errorf("Unexpected ci pool status %+v", ci)
+	}
+	if research := pools["research"]; research.GPUs != 0 || research.Pending != 1 {
+		t.Errorf("Unexpected research pool status %+v", research)
+	}
+	if prod := pools["prod-inference"]; prod.MemoryTotalMB != 104000 || prod.MemoryUsedMB != 16000 {
+		t.Errorf("Unexpected prod pool status %+v", prod)
+	}
+}
+
+func TestPoolMemoryQuotaLimitsColocation(t *testing.T) {
+	config := DefaultSchedulerConfig()
+	config.Colocation.Enabled = true
+	config.Pools["research"] = PoolConfig{MaxMemoryMB: 10000}
+	scheduler := NewSchedulerWithConfig(StrategyLeastUtilized, config)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", Pool: "research", MemoryTotal: 32000, Available: true})
+
+	scheduler.SubmitWorkload(&Workload{ID: "serve", Pool: "research", Class: WorkloadClassInference, MemoryRequired: 8000})
+	scheduler.Schedule()
+	train := &Workload{ID: "train", Pool: "research", Class: WorkloadClassTraining, MemoryRequired: 4000}
+	scheduler.SubmitWorkload(train)
+	scheduler.Schedule()
+
+	if train.Status != WorkloadPending {
+		t.Errorf("Expected co-location refused over the memory quota, got %s", train.Status)
+	}
+}
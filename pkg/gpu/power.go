@@ -0,0 +1,379 @@
+package gpu
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PowerController applies power limits and application clocks to GPUs
+type PowerController interface {
+	SetPowerLimit(gpuID string, watts float64) error
+	SetApplicationClocks(gpuID string, memoryMHz, graphicsMHz uint64) error
+	ResetApplicationClocks(gpuID string) error
+}
+
+// NvidiaSMIPowerController applies settings with nvidia-smi, which must run as root
+type NvidiaSMIPowerController struct {
+	Timeout time.Duration
+}
+
+// runNvidiaSMI executes nvidia-smi through the shared command runner; replaced in tests
+var runNvidiaSMI = func(ctx context.Context, args ...string) ([]byte, error) {
+	return defaultCommandRunner.Run(ctx, CommandSpec{Name: "nvidia-smi", Args: args, Combined: true})
+}
+
+// run executes nvidia-smi against one GPU, including its output in errors
+func (c NvidiaSMIPowerController) run(gpuID string, args ...string) error {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	output, err := runNvidiaSMI(ctx, append([]string{"-i", gpuID}, args...)...)
+	if err != nil {
+		return fmt.Errorf("nvidia-smi %s failed: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// SetPowerLimit sets a GPU's power limit in watts
+func (c NvidiaSMIPowerController) SetPowerLimit(gpuID string, watts float64) error {
+	return c.run(gpuID, "-pl", fmt.Sprintf("%.0f", watts))
+}
+
+// SetApplicationClocks sets a GPU's memory and graphics application clocks
+func (c NvidiaSMIPowerController) SetApplicationClocks(gpuID string, memoryMHz, graphicsMHz uint64) error {
+	return c.run(gpuID, "-ac", fmt.Sprintf("%d,%d", memoryMHz, graphicsMHz))
+}
+
+// ResetApplicationClocks restores a GPU's default application clocks
+func (c NvidiaSMIPowerController) ResetApplicationClocks(gpuID string) error {
+	return c.run(gpuID, "-rac")
+}
+
+// PowerConfig holds guardrails for power and clock changes
+type PowerConfig struct {
+	MinPowerWatts       float64          `yaml:"min_power_watts" json:"min_power_watts"`
+	MaxPowerWatts       float64          `yaml:"max_power_watts" json:"max_power_watts"` // 0 allows up to the GPU's default limit
+	MaxGraphicsClockMHz uint64           `yaml:"max_graphics_clock_mhz" json:"max_graphics_clock_mhz"`
+	MaxMemoryClockMHz   uint64           `yaml:"max_memory_clock_mhz" json:"max_memory_clock_mhz"`
+	MinChangeInterval   time.Duration    `yaml:"min_change_interval" json:"min_change_interval"` // Between operator changes to one GPU
+	AuditSize           int              `yaml:"audit_size" json:"audit_size"`
+	Saver               PowerSaverConfig `yaml:"saver" json:"saver"`
+}
+
+// PowerSaverConfig caps the power limit of idle GPUs
+type PowerSaverConfig struct {
+	Enabled         bool          `yaml:"enabled" json:"enabled"`
+	IdleUtilization float64       `yaml:"idle_utilization" json:"idle_utilization"` // Utilization below this counts as idle
+	IdleAfter       time.Duration `yaml:"idle_after" json:"idle_after"`
+	CapWatts        float64       `yaml:"cap_watts" json:"cap_watts"`
+}
+
+// DefaultPowerConfig returns conservative guardrails with the power saver disabled
+func DefaultPowerConfig() PowerConfig {
+	return PowerConfig{
+		MinPowerWatts:     100,
+		MinChangeInterval: 10 * time.Second,
+		AuditSize:         1000,
+		Saver: PowerSaverConfig{
+			Enabled:         false,
+			IdleUtilization: 5,
+			IdleAfter:       10 * time.Minute,
+			CapWatts:        100,
+		},
+	}
+}
+
+// PowerAuditEntry records one attempted power or clock change
+type PowerAuditEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Actor     string    `json:"actor"`
+	GPUID     string    `json:"gpu_id"`
+	Action    string    `json:"action"` // power_limit, application_clocks, reset_clocks, saver_cap, saver_restore
+	Value     string    `json:"value"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// powerSaverActor is the audit actor for power saver changes
+const powerSaverActor = "power-saver"
+
+// gpuPowerState tracks what the manager knows about a GPU's power settings
+type gpuPowerState struct {
+	defaultLimit float64 // Limit observed before any change
+	restoreLimit float64 // Limit the power saver restores
+	idleSince    time.Time
+	capped       bool
+	lastChange   time.Time
+}
+
+// PowerManager applies guarded power and clock changes, audits them, and
+// runs the power saver policy
+type PowerManager struct {
+	controller    PowerController
+	config        PowerConfig
+	gpus          map[string]*gpuPowerState
+	audit         []PowerAuditEntry
+	auditHandlers []func(PowerAuditEntry)
+	mu            sync.Mutex
+}
+
+// NewPowerManager creates a power manager applying changes through controller
+func NewPowerManager(controller PowerController, config PowerConfig) *PowerManager {
+	return &PowerManager{
+		controller: controller,
+		config:     config,
+		gpus:       make(map[string]*gpuPowerState),
+		audit:      make([]PowerAuditEntry, 0),
+	}
+}
+
+// OnAudit registers a handler called for every audited change
+func (pm *PowerManager) OnAudit(handler func(PowerAuditEntry)) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.auditHandlers = append(pm.auditHandlers, handler)
+}
+
+// state returns a GPU's power state, creating it; callers must hold the lock
+func (pm *PowerManager) state(gpuID string) *gpuPowerState {
+	state, exists := pm.gpus[gpuID]
+	if !exists {
+		state = &gpuPowerState{}
+		pm.gpus[gpuID] = state
+	}
+	return state
+}
+
+// SetPowerLimit sets a GPU's power limit on behalf of actor. The limit must
+// lie within the guardrails and not exceed the GPU's default limit.
+func (pm *PowerManager) SetPowerLimit(actor, gpuID string, watts float64) error {
+	pm.mu.Lock()
+	state := pm.state(gpuID)
+	err := pm.checkPowerLimit(state, watts, time.Now())
+	if err == nil {
+		err = pm.controller.SetPowerLimit(gpuID, watts)
+	}
+	if err == nil {
+		state.restoreLimit = watts
+		state.capped = false
+		state.lastChange = time.Now()
+	}
+	entry, handlers := pm.record(actor, gpuID, "power_limit", fmt.Sprintf("%.0f W", watts), err)
+	pm.mu.Unlock()
+
+	notifyAuditHandlers(handlers, entry)
+	return err
+}
+
+// checkPowerLimit applies the guardrails to an operator power limit; callers must hold the lock
+func (pm *PowerManager) checkPowerLimit(state *gpuPowerState, watts float64, now time.Time) error {
+	if watts < pm.config.MinPowerWatts {
+		return fmt.Errorf("power limit %.0f W is below the %.0f W minimum", watts, pm.config.MinPowerWatts)
+	}
+	if pm.config.MaxPowerWatts > 0 && watts > pm.config.MaxPowerWatts {
+		return fmt.Errorf("power limit %.0f W is above the %.0f W maximum", watts, pm.config.MaxPowerWatts)
+	}
+	if state.defaultLimit > 0 && watts > state.defaultLimit {
+		return fmt.Errorf("power limit %.0f W is above the GPU's default %.0f W", watts, state.defaultLimit)
+	}
+	return pm.checkRate(state, now)
+}
+
+// checkRate rejects operator changes made too soon after the last one; callers must hold the lock
+func (pm *PowerManager) checkRate(state *gpuPowerState, now time.Time) error {
+	if !state.lastChange.IsZero() && now.Sub(state.lastChange) < pm.config.MinChangeInterval {
+		return fmt.Errorf("GPU settings were changed %s ago, wait %s between changes",
+			now.Sub(state.lastChange).Round(time.Second), pm.config.MinChangeInterval)
+	}
+	return nil
+}
+
+// SetApplicationClocks sets a GPU's application clocks on behalf of actor
+func (pm *PowerManager) SetApplicationClocks(actor, gpuID string, memoryMHz, graphicsMHz uint64) error {
+	pm.mu.Lock()
+	state := pm.state(gpuID)
+	var err error
+	switch {
+	case memoryMHz == 0 || graphicsMHz == 0:
+		err = fmt.Errorf("memory and graphics clocks are required")
+	case pm.config.MaxMemoryClockMHz > 0 && memoryMHz > pm.config.MaxMemoryClockMHz:
+		err = fmt.Errorf("memory clock %d MHz is above the %d MHz maximum", memoryMHz, pm.config.MaxMemoryClockMHz)
+	case pm.config.MaxGraphicsClockMHz > 0 && graphicsMHz > pm.config.MaxGraphicsClockMHz:
+		err = fmt.Errorf("graphics clock %d MHz is above the %d MHz maximum", graphicsMHz, pm.config.MaxGraphicsClockMHz)
+	default:
+		err = pm.checkRate(state, time.Now())
+	}
+	if err == nil {
+		err = pm.controller.SetApplicationClocks(gpuID, memoryMHz, graphicsMHz)
+	}
+	if err == nil {
+		state.lastChange = time.Now()
+	}
+	entry, handlers := pm.record(actor, gpuID, "application_clocks", fmt.Sprintf("%d,%d MHz", memoryMHz, graphicsMHz), err)
+	pm.mu.Unlock()
+
+	notifyAuditHandlers(handlers, entry)
+	return err
+}
+
+// ResetApplicationClocks restores a GPU's default clocks on behalf of actor.
+// Resets are always allowed, even within the change interval.
+func (pm *PowerManager) ResetApplicationClocks(actor, gpuID string) error {
+	pm.mu.Lock()
+	err := pm.controller.ResetApplicationClocks(gpuID)
+	if err == nil {
+		pm.state(gpuID).lastChange = time.Now()
+	}
+	entry, handlers := pm.record(actor, gpuID, "reset_clocks", "default", err)
+	pm.mu.Unlock()
+
+	notifyAuditHandlers(handlers, entry)
+	return err
+}
+
+// Observe learns a GPU's default power limit and applies the power saver:
+// GPUs idle for IdleAfter are capped, and restored once they are busy again.
+// Register it as a metrics collector callback.
+func (pm *PowerManager) Observe(metrics GPUMetrics) {
+	pm.mu.Lock()
+	state := pm.state(metrics.GPUID)
+	if state.defaultLimit == 0 && !state.capped && metrics.PowerLimit > 0 {
+		state.defaultLimit = metrics.PowerLimit
+	}
+
+	saver := pm.config.Saver
+	if !saver.Enabled {
+		pm.mu.Unlock()
+		return
+	}
+
+	var entry PowerAuditEntry
+	var handlers []func(PowerAuditEntry)
+	if metrics.UtilizationGPU >= saver.IdleUtilization {
+		state.idleSince = time.Time{}
+		if state.capped {
+			entry, handlers = pm.restore(metrics.GPUID, state)
+		}
+	} else if state.idleSince.IsZero() {
+		state.idleSince = metrics.Timestamp
+	} else if !state.capped && metrics.Timestamp.Sub(state.idleSince) >= saver.IdleAfter && state.limit() > saver.CapWatts {
+		err := pm.controller.SetPowerLimit(metrics.GPUID, saver.CapWatts)
+		if err == nil {
+			if state.restoreLimit == 0 {
+				state.restoreLimit = state.defaultLimit
+			}
+			state.capped = true
+		}
+		entry, handlers = pm.record(powerSaverActor, metrics.GPUID, "saver_cap", fmt.Sprintf("%.0f W", saver.CapWatts), err)
+	}
+	pm.mu.Unlock()
+
+	if entry.Action != "" {
+		notifyAuditHandlers(handlers, entry)
+	}
+}
+
+// limit returns the power limit in effect before the saver caps it
+func (state *gpuPowerState) limit() float64 {
+	if state.restoreLimit > 0 {
+		return state.restoreLimit
+	}
+	return state.defaultLimit
+}
+
+// HandleQueueEvent restores a capped GPU's power limit as soon as a workload
+// is placed on it. Register it with Scheduler.OnQueueEvent.
+func (pm *PowerManager) HandleQueueEvent(event QueueEvent) {
+	if event.Type != "started" || event.GPUID == "" {
+		return
+	}
+
+	pm.mu.Lock()
+	state, exists := pm.gpus[event.GPUID]
+	if !exists || !state.capped {
+		pm.mu.Unlock()
+		return
+	}
+	state.idleSince = time.Time{}
+	entry, handlers := pm.restore(event.GPUID, state)
+	pm.mu.Unlock()
+
+	notifyAuditHandlers(handlers, entry)
+}
+
+// restore lifts the power saver cap from a GPU; callers must hold the lock
+func (pm *PowerManager) restore(gpuID string, state *gpuPowerState) (PowerAuditEntry, []func(PowerAuditEntry)) {
+	err := pm.controller.SetPowerLimit(gpuID, state.limit())
+	if err == nil {
+		state.capped = false
+	}
+	return pm.record(powerSaverActor, gpuID, "saver_restore", fmt.Sprintf("%.0f W", state.limit()), err)
+}
+
+// record appends an audit entry and returns the handlers to notify; callers must hold the lock
+func (pm *PowerManager) record(actor, gpuID, action, value string, err error) (PowerAuditEntry, []func(PowerAuditEntry)) {
+	entry := PowerAuditEntry{
+		Timestamp: time.Now(),
+		Actor:     actor,
+		GPUID:     gpuID,
+		Action:    action,
+		Value:     value,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	pm.audit = append(pm.audit, entry)
+	if pm.config.AuditSize > 0 && len(pm.audit) > pm.config.AuditSize {
+		pm.audit = pm.audit[len(pm.audit)-pm.config.AuditSize:]
+	}
+	return entry, pm.auditHandlers
+}
+
+// AuditLog returns audited changes since a time, oldest first
+func (pm *PowerManager) AuditLog(since time.Time) []PowerAuditEntry {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	entries := make([]PowerAuditEntry, 0)
+	for _, entry := range pm.audit {
+		if entry.Timestamp.After(since) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// GetStats returns power manager statistics
+func (pm *PowerManager) GetStats() map[string]interface{} {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	capped := make([]string, 0)
+	for gpuID, state := range pm.gpus {
+		if state.capped {
+			capped = append(capped, gpuID)
+		}
+	}
+	sort.Strings(capped)
+	return map[string]interface{}{
+		"tracked_gpus":  len(pm.gpus),
+		"capped_gpus":   capped,
+		"saver_enabled": pm.config.Saver.Enabled,
+		"audit_entries": len(pm.audit),
+	}
+}
+
+// notifyAuditHandlers delivers an audit entry to registered handlers
+func notifyAuditHandlers(handlers []func(PowerAuditEntry), entry PowerAuditEntry) {
+	for _, handler := range handlers {
+		handler(entry)
+	}
+}
@@ -0,0 +1,160 @@
+package gpu
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakePowerController records applied settings
+type fakePowerController struct {
+	limits map[string]float64
+	clocks map[string]string
+	fail   bool
+}
+
+func newFakePowerController() *fakePowerController {
+	return &fakePowerController{limits: make(map[string]float64), clocks: make(map[string]string)}
+}
+
+func (c *fakePowerController) SetPowerLimit(gpuID string, watts float64) error {
+	if c.fail {
+		return fmt.Errorf("insufficient permissions")
+	}
+	c.limits[gpuID] = watts
+	return nil
+}
+
+func (c *fakePowerController) SetApplicationClocks(gpuID string, memoryMHz, graphicsMHz uint64) error {
+	c.clocks[gpuID] = fmt.Sprintf("%d,%d", memoryMHz, graphicsMHz)
+	return nil
+}
+
+func (c *fakePowerController) ResetApplicationClocks(gpuID string) error {
+	delete(c.clocks, gpuID)
+	return nil
+}
+
+func TestPowerLimitGuardrailsAndAudit(t *testing.T) {
+	controller := newFakePowerController()
+	config := DefaultPowerConfig()
+	config.MaxGraphicsClockMHz = 1800
+	manager := NewPowerManager(controller, config)
+	manager.Observe(GPUMetrics{GPUID: "0", PowerLimit: 300, UtilizationGPU: 50, Timestamp: time.Now()})
+
+	var audited []PowerAuditEntry
+	manager.OnAudit(func(entry PowerAuditEntry) { audited = append(audited, entry) })
+
+	if err := manager.SetPowerLimit("alice", "0", 50); err == nil {
+		t.Error("Expected a limit below the minimum to be rejected")
+	}
+	if err := manager.SetPowerLimit("alice", "0", 350); err == nil || !strings.Contains(err.Error(), "default") {
+		t.Errorf("Expected a limit above the GPU's default to be rejected, got %v", err)
+	}
+	if err := manager.SetPowerLimit("alice", "0", 250); err != nil {
+		t.Fatalf("SetPowerLimit failed: %v", err)
+	}
+	if controller.limits["0"] != 250 {
+		t.Errorf("Expected 250 W applied, got %v", controller.limits["0"])
+	}
+	if err := manager.SetPowerLimit("bob", "0", 200); err == nil {
+		t.Error("Expected a second change within the interval to be rejected")
+	}
+	if err := manager.SetApplicationClocks("bob", "1", 9000, 2000); err == nil {
+		t.Error("Expected clocks above the maximum to be rejected")
+	}
+	if err := manager.SetApplicationClocks("bob", "1", 9000, 1500); err != nil || controller.clocks["1"] != "9000,1500" {
+		t.Errorf("Expected clocks applied, got %v (%s)", err, controller.clocks["1"])
+	}
+	if err := manager.ResetApplicationClocks("bob", "1"); err != nil || controller.clocks["1"] != "" {
+		t.Errorf("Expected clocks reset, got %v", err)
+	}
+
+	log := manager.AuditLog(time.Time{})
+	if len(log) != 7 || len(audited) != 7 {
+		t.Fatalf("Expected every attempt audited, got %d entries and %d notifications", len(log), len(audited))
+	}
+	if log[2].Actor != "alice" || log[2].Action != "power_limit" || log[2].Value != "250 W" || log[2].Error != "" {
+		t.Errorf("Unexpected audit entry %+v", log[2])
+	}
+	if log[3].Error == "" {
+		t.Errorf("Expected the rejected change audited with its error, got %+v", log[3])
+	}
+}
+
+func TestPowerSaverCapsIdleGPUsAndRestoresOnWorkload(t *testing.T) {
+	controller := newFakePowerController()
+	config := DefaultPowerConfig()
+	config.Saver.Enabled = true
+	config.Saver.IdleAfter = 10 * time.Minute
+	config.Saver.CapWatts = 120
+	manager := NewPowerManager(controller, config)
+
+	start := time.Now()
+	observe := func(gpuID string, utilization float64, at time.Duration) {
+		manager.Observe(GPUMetrics{GPUID: gpuID, PowerLimit: 300, UtilizationGPU: utilization, Timestamp: start.Add(at)})
+	}
+	observe("0", 0, 0)
+	observe("1", 0, 0)
+	observe("0", 1, 5*time.Minute)
+	if _, capped := controller.limits["0"]; capped {
+		t.Fatal("Expected no cap before the idle period")
+	}
+	observe("0", 1, 11*time.Minute)
+	observe("1", 60, 11*time.Minute)
+	observe("1", 0, 12*time.Minute)
+	if controller.limits["0"] != 120 {
+		t.Errorf("Expected the idle GPU capped at 120 W, got %v", controller.limits["0"])
+	}
+	if _, capped := controller.limits["1"]; capped {
+		t.Error("Expected a GPU that became busy to stay uncapped")
+	}
+	if stats := manager.GetStats(); len(stats["capped_gpus"].([]string)) != 1 {
+		t.Errorf("Expected one capped GPU, got %v", stats["capped_gpus"])
+	}
+
+	manager.HandleQueueEvent(QueueEvent{Type: "started", WorkloadID: "train", GPUID: "0"})
+	if controller.limits["0"] != 300 {
+		t.Errorf("Expected the default limit restored when a workload started, got %v", controller.limits["0"])
+	}
+	log := manager.AuditLog(time.Time{})
+	if last := log[len(log)-1]; last.Actor != powerSaverActor || last.Action != "saver_restore" {
+		t.Errorf("Expected the restore audited, got %+v", last)
+	}
+
+	// Utilization rising also restores the limit
+	observe("0", 0, 30*time.Minute)
+	observe("0", 0, 41*time.Minute)
+	observe("0", 90, 42*time.Minute)
+	if controller.limits["0"] != 300 {
+		t.Errorf("Expected the limit restored once the GPU was busy, got %v", controller.limits["0"])
+	}
+}
+
+func TestNvidiaSMIPowerControllerCommands(t *testing.T) {
+	var calls []string
+	original := runNvidiaSMI
+	defer func() { runNvidiaSMI = original }()
+	runNvidiaSMI = func(ctx context.Context, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		if args[2] == "-rac" {
+			return []byte("Insufficient Permissions"), fmt.Errorf("exit status 4")
+		}
+		return nil, nil
+	}
+
+	controller := NvidiaSMIPowerController{}
+	controller.SetPowerLimit("1", 250)
+	controller.SetApplicationClocks("1", 9501, 1755)
+	err := controller.ResetApplicationClocks("1")
+
+	expected := []string{"-i 1 -pl 250", "-i 1 -ac 9501,1755", "-i 1 -rac"}
+	if strings.Join(calls, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %v, got %v", expected, calls)
+	}
+	if err == nil || !strings.Contains(err.Error(), "Insufficient Permissions") {
+		t.Errorf("Expected nvidia-smi output in the error, got %v", err)
+	}
+}
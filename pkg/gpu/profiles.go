@@ -0,0 +1,217 @@
+package gpu
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ProfileClass is the behaviour of a recurring workload learned from its past runs
+type ProfileClass string
+
+const (
+	ProfileTraining       ProfileClass = "training"
+	ProfileBatchInference ProfileClass = "batch_inference"
+	ProfileInteractive    ProfileClass = "interactive"
+)
+
+// ProfileConfig controls how workload profiles are learned and classified
+type ProfileConfig struct {
+	Enabled bool
+
+	// A profile is applied to new submissions once MinRuns runs have completed.
+	// Smoothing is the weight of the newest run in the moving averages.
+	MinRuns   int
+	Smoothing float64
+
+	// Workloads averaging below InteractiveMaxUtilization are interactive; long
+	// runs holding at least TrainingMinMemoryPercent of the GPU are training;
+	// everything else is batch inference
+	InteractiveMaxUtilization float64
+	TrainingMinDuration       time.Duration
+	TrainingMinMemoryPercent  float64
+}
+
+// DefaultProfileConfig returns default profile learning settings
+func DefaultProfileConfig() ProfileConfig {
+	return ProfileConfig{
+		Enabled:                   true,
+		MinRuns:                   2,
+		Smoothing:                 0.3,
+		InteractiveMaxUtilization: 30.0,
+		TrainingMinDuration:       30 * time.Minute,
+		TrainingMinMemoryPercent:  40.0,
+	}
+}
+
+// WorkloadProfile is what the scheduler has learned about workloads sharing a name
+type WorkloadProfile struct {
+	Name            string        `json:"name"`
+	Class           ProfileClass  `json:"class"`
+	Runs            int           `json:"runs"`
+	AvgDuration     time.Duration `json:"avg_duration"`
+	AvgUtilization  float64       `json:"avg_utilization"`
+	UtilizationRuns int           `json:"utilization_runs"` // Runs with utilization samples
+	MemoryPercent   float64       `json:"memory_percent"`
+	PeakMemory      uint64        `json:"peak_memory_mb"`
+	LastRun         time.Time     `json:"last_run"`
+}
+
+// workloadUsage accumulates observations of a running workload
+type workloadUsage struct {
+	utilizationSum float64
+	samples        int
+	peakMemory     uint64
+}
+
+// GetProfile returns the learned profile for a workload name
+func (s *Scheduler) GetProfile(name string) (WorkloadProfile, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	profile, exists := s.profiles[name]
+	if !exists {
+		return WorkloadProfile{}, false
+	}
+	return *profile, true
+}
+
+// GetProfiles returns all learned profiles sorted by name
+func (s *Scheduler) GetProfiles() []WorkloadProfile {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	profiles := make([]WorkloadProfile, 0, len(s.profiles))
+	for _, profile := range s.profiles {
+		profiles = append(profiles, *profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles
+}
+
+// ObserveWorkload records the utilization and memory of a running workload,
+// e.g. from per-process metrics. A memoryUsed of 0 means unknown.
+func (s *Scheduler) ObserveWorkload(workloadID string, utilization float64, memoryUsed uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, gpu := range s.gpus {
+		for _, workload := range []*Workload{gpu.CurrentWorkload, gpu.ColocatedWorkload} {
+			if workload != nil && workload.ID == workloadID {
+				s.observeUsage(workloadID, utilization, memoryUsed)
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("workload %s is not running", workloadID)
+}
+
+// observeUsage accumulates a usage sample; callers must hold the lock
+func (s *Scheduler) observeUsage(workloadID string, utilization float64, memoryUsed uint64) {
+	usage, exists := s.usage[workloadID]
+	if !exists {
+		usage = &workloadUsage{}
+		s.usage[workloadID] = usage
+	}
+	usage.utilizationSum += utilization
+	usage.samples++
+	if memoryUsed > usage.peakMemory {
+		usage.peakMemory = memoryUsed
+	}
+}
+
+// applyProfile fills in what the workload's learned profile knows; callers must hold the lock
+func (s *Scheduler) applyProfile(workload *Workload) {
+	config := s.config.Profiles
+	profile, exists := s.profiles[workload.Name]
+	if !config.Enabled || workload.Name == "" || !exists || profile.Runs < config.MinRuns {
+		return
+	}
+
+	workload.Profile = profile.Class
+	if workload.EstimatedTime == 0 {
+		workload.EstimatedTime = profile.AvgDuration
+	}
+	if workload.Class == "" {
+		workload.Class = profile.Class.workloadClass()
+	}
+	// Reserve what earlier runs actually used so the job is not placed where it would run out of memory
+	if profile.PeakMemory > workload.MemoryRequired {
+		workload.MemoryRequired = profile.PeakMemory
+	}
+}
+
+// learnProfile folds a finished run into its workload's profile; callers must hold the lock
+func (s *Scheduler) learnProfile(gpu *GPU, workload *Workload, completedAt time.Time) {
+	usage := s.usage[workload.ID]
+	delete(s.usage, workload.ID)
+
+	config := s.config.Profiles
+	if !config.Enabled || workload.Name == "" || workload.StartedAt == nil {
+		return
+	}
+
+	duration := completedAt.Sub(*workload.StartedAt)
+	memory := workload.MemoryRequired
+	if usage != nil && usage.peakMemory > 0 {
+		memory = usage.peakMemory
+	}
+	memoryPercent := 0.0
+	if gpu.MemoryTotal > 0 {
+		memoryPercent = float64(memory) / float64(gpu.MemoryTotal) * 100
+	}
+
+	profile, exists := s.profiles[workload.Name]
+	if !exists {
+		profile = &WorkloadProfile{Name: workload.Name}
+		s.profiles[workload.Name] = profile
+	}
+	profile.Runs++
+
+	weight := config.Smoothing
+	if weight <= 0 || profile.Runs == 1 {
+		weight = 1 / float64(profile.Runs)
+	}
+	profile.AvgDuration = time.Duration(smooth(float64(profile.AvgDuration), float64(duration), weight))
+	profile.MemoryPercent = smooth(profile.MemoryPercent, memoryPercent, weight)
+	if memory > profile.PeakMemory {
+		profile.PeakMemory = memory
+	}
+	if usage != nil && usage.samples > 0 {
+		profile.UtilizationRuns++
+		utilizationWeight := weight
+		if profile.UtilizationRuns == 1 {
+			utilizationWeight = 1
+		}
+		profile.AvgUtilization = smooth(profile.AvgUtilization, usage.utilizationSum/float64(usage.samples), utilizationWeight)
+	}
+	profile.LastRun = completedAt
+	profile.Class = config.classify(profile)
+}
+
+// classify derives a profile's class from its averaged signature
+func (c ProfileConfig) classify(profile *WorkloadProfile) ProfileClass {
+	switch {
+	case profile.UtilizationRuns > 0 && profile.AvgUtilization < c.InteractiveMaxUtilization:
+		return ProfileInteractive
+	case profile.AvgDuration >= c.TrainingMinDuration && profile.MemoryPercent >= c.TrainingMinMemoryPercent:
+		return ProfileTraining
+	default:
+		return ProfileBatchInference
+	}
+}
+
+// workloadClass maps a learned profile onto the scheduler's latency classes
+func (p ProfileClass) workloadClass() WorkloadClass {
+	if p == ProfileTraining {
+		return WorkloadClassTraining
+	}
+	return WorkloadClassInference
+}
+
+// smooth blends a new observation into a moving average
+func smooth(average, value, weight float64) float64 {
+	return average + weight*(value-average)
+}
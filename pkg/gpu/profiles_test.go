@@ -0,0 +1,126 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+// runProfiled schedules, observes and completes a named workload that ran for duration
+func runProfiled(t *testing.T, scheduler *Scheduler, workload *Workload, duration time.Duration, utilization float64, memoryUsed uint64) {
+	t.Helper()
+	if err := scheduler.SubmitWorkload(workload); err != nil {
+		t.Fatalf("Failed to submit %s: %v", workload.ID, err)
+	}
+	if err := scheduler.Schedule(); err != nil {
+		t.Fatalf("Failed to schedule: %v", err)
+	}
+	if workload.StartedAt == nil {
+		t.Fatalf("Workload %s was not scheduled", workload.ID)
+	}
+	started := time.Now().Add(-duration)
+	workload.StartedAt = &started
+
+	scheduler.UpdateGPUUtilization(workload.AssignedGPU, utilization)
+	if err := scheduler.ObserveWorkload(workload.ID, utilization, memoryUsed); err != nil {
+		t.Fatalf("Failed to observe %s: %v", workload.ID, err)
+	}
+	if err := scheduler.CompleteWorkload(workload.ID); err != nil {
+		t.Fatalf("Failed to complete %s: %v", workload.ID, err)
+	}
+}
+
+func TestSchedulerLearnsWorkloadProfiles(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+
+	runProfiled(t, scheduler, &Workload{ID: "train-1", Name: "resnet", MemoryRequired: 8000}, time.Hour, 90, 10000)
+	if profile, _ := scheduler.GetProfile("resnet"); profile.Runs != 1 || profile.Class != ProfileTraining {
+		t.Fatalf("Expected a training profile after one run, got %+v", profile)
+	}
+
+	// A profile is not applied before MinRuns
+	early := &Workload{ID: "train-2", Name: "resnet", MemoryRequired: 8000}
+	scheduler.SubmitWorkload(early)
+	if early.Profile != "" || early.MemoryRequired != 8000 {
+		t.Errorf("Expected no profile applied after one run, got %+v", early)
+	}
+	scheduler.Schedule()
+	early.StartedAt = timePtr(time.Now().Add(-time.Hour))
+	scheduler.ObserveWorkload(early.ID, 80, 12000)
+	scheduler.CompleteWorkload(early.ID)
+
+	profile, exists := scheduler.GetProfile("resnet")
+	if !exists || profile.Runs != 2 || profile.PeakMemory != 12000 {
+		t.Fatalf("Unexpected profile %+v", profile)
+	}
+
+	next := &Workload{ID: "train-3", Name: "resnet", MemoryRequired: 8000}
+	scheduler.SubmitWorkload(next)
+	if next.Profile != ProfileTraining || next.Class != WorkloadClassTraining {
+		t.Errorf("Expected the training profile applied, got %+v", next)
+	}
+	if next.MemoryRequired != 12000 {
+		t.Errorf("Expected the learned peak memory reserved, got %d MB", next.MemoryRequired)
+	}
+	if next.EstimatedTime < 59*time.Minute || next.EstimatedTime > 61*time.Minute {
+		t.Errorf("Expected an estimated time of about an hour, got %v", next.EstimatedTime)
+	}
+
+	scheduler.Schedule()
+	decisions := scheduler.GetDecisions(time.Time{})
+	if reason := decisions[len(decisions)-1].Reason; reason != "12000 MB required, 16000 MB free, training profile" {
+		t.Errorf("Unexpected decision reason %q", reason)
+	}
+
+	// An explicit estimate is kept
+	explicit := &Workload{ID: "train-4", Name: "resnet", MemoryRequired: 8000, EstimatedTime: time.Minute}
+	scheduler.SubmitWorkload(explicit)
+	if explicit.EstimatedTime != time.Minute {
+		t.Errorf("Expected the submitted estimate kept, got %v", explicit.EstimatedTime)
+	}
+}
+
+func TestWorkloadProfileClassification(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+
+	runProfiled(t, scheduler, &Workload{ID: "nb-1", Name: "notebook", MemoryRequired: 4000}, 2*time.Hour, 8, 0)
+	runProfiled(t, scheduler, &Workload{ID: "batch-1", Name: "embed", MemoryRequired: 2000}, time.Hour, 85, 0)
+	runProfiled(t, scheduler, &Workload{ID: "batch-2", Name: "score", MemoryRequired: 12000}, 5*time.Minute, 95, 0)
+	runProfiled(t, scheduler, &Workload{ID: "anon", MemoryRequired: 1000}, time.Minute, 50, 0)
+
+	expected := map[string]ProfileClass{
+		"notebook": ProfileInteractive,
+		"embed":    ProfileBatchInference, // long, but too little memory for training
+		"score":    ProfileBatchInference, // large, but short
+	}
+	profiles := scheduler.GetProfiles()
+	if len(profiles) != len(expected) {
+		t.Fatalf("Expected %d profiles, got %+v", len(expected), profiles)
+	}
+	for _, profile := range profiles {
+		if profile.Class != expected[profile.Name] {
+			t.Errorf("Expected %s to be %s, got %s", profile.Name, expected[profile.Name], profile.Class)
+		}
+	}
+
+	if err := scheduler.ObserveWorkload("missing", 50, 0); err == nil {
+		t.Error("Expected observing a workload that is not running to fail")
+	}
+}
+
+func TestSnapshotKeepsWorkloadProfiles(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+	runProfiled(t, scheduler, &Workload{ID: "nb-1", Name: "notebook", MemoryRequired: 4000}, time.Hour, 5, 0)
+
+	restored := NewScheduler(StrategyLeastUtilized)
+	restored.Restore(scheduler.Snapshot())
+	if profile, exists := restored.GetProfile("notebook"); !exists || profile.Class != ProfileInteractive {
+		t.Errorf("Expected the profile restored, got %+v", profile)
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
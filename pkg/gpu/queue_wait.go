@@ -0,0 +1,167 @@
+package gpu
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// QueueConfig sets the wait-time objective for queued workloads
+type QueueConfig struct {
+	// Workloads at or below StarvationMaxPriority that have waited longer than
+	// MaxWait are reported as starving. A positive StarvationBoost raises a
+	// starving workload's priority once so the priority strategy picks it sooner.
+	MaxWait               time.Duration
+	StarvationMaxPriority int
+	StarvationBoost       int
+}
+
+// DefaultQueueConfig returns a 30 minute wait objective for low-priority work
+func DefaultQueueConfig() QueueConfig {
+	return QueueConfig{
+		MaxWait:               30 * time.Minute,
+		StarvationMaxPriority: 1,
+		StarvationBoost:       0,
+	}
+}
+
+// QueueEvent reports a workload leaving the queue or starving in it
+type QueueEvent struct {
+	Type       string // started or starved
+	WorkloadID string
+	Name       string
+	GPUID      string // GPU a started workload was placed on
+	Priority   int    // Priority when queued, before any starvation boost
+	Waited     time.Duration
+	Timestamp  time.Time
+}
+
+// QueueWait describes how long a pending workload has waited
+type QueueWait struct {
+	WorkloadID        string        `json:"workload_id"`
+	Name              string        `json:"name"`
+	Priority          int           `json:"priority"`
+	EffectivePriority int           `json:"effective_priority"` // Priority plus queue aging
+	Waited            time.Duration `json:"waited"`
+	Starving          bool          `json:"starving"`
+}
+
+// OnQueueEvent registers a handler for workloads starting or starving,
+// e.g. to export wait-time histograms or raise alerts
+func (s *Scheduler) OnQueueEvent(handler func(QueueEvent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queueHandlers = append(s.queueHandlers, handler)
+}
+
+// GetQueueWaits returns pending workloads, longest waiting first
+func (s *Scheduler) GetQueueWaits() []QueueWait {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	waits := make([]QueueWait, 0, len(s.workloadQueue))
+	for _, workload := range s.workloadQueue {
+		waits = append(waits, QueueWait{
+			WorkloadID:        workload.ID,
+			Name:              workload.Name,
+			Priority:          workload.Priority,
+			EffectivePriority: s.effectivePriority(workload, now),
+			Waited:            now.Sub(workload.queuedSince()),
+			Starving:          workload.StarvedAt != nil,
+		})
+	}
+	sort.SliceStable(waits, func(i, j int) bool {
+		return waits[i].Waited > waits[j].Waited
+	})
+	return waits
+}
+
+// CheckStarvation reports queued workloads that have exceeded the wait
+// objective. Schedule checks automatically; call this when scheduling is idle.
+func (s *Scheduler) CheckStarvation() []QueueEvent {
+	s.mu.Lock()
+	events := s.detectStarvation(time.Now())
+	handlers := s.queueHandlers
+	s.mu.Unlock()
+
+	notifyQueueHandlers(handlers, events)
+	return events
+}
+
+// detectStarvation marks newly starving workloads; callers must hold the lock
+func (s *Scheduler) detectStarvation(now time.Time) []QueueEvent {
+	config := s.config.Queue
+	if config.MaxWait <= 0 {
+		return nil
+	}
+
+	var events []QueueEvent
+	for _, workload := range s.workloadQueue {
+		// Workloads held for a start window or cheaper power are waiting by choice
+		if workload.StarvedAt != nil || workload.Priority > config.StarvationMaxPriority || s.held(workload, now) {
+			continue
+		}
+		waited := now.Sub(workload.queuedSince())
+		if waited <= config.MaxWait {
+			continue
+		}
+
+		starvedAt := now
+		workload.StarvedAt = &starvedAt
+		events = append(events, QueueEvent{
+			Type:       "starved",
+			WorkloadID: workload.ID,
+			Name:       workload.Name,
+			Priority:   workload.Priority,
+			Waited:     waited,
+			Timestamp:  now,
+		})
+
+		if config.StarvationBoost > 0 {
+			workload.Priority += config.StarvationBoost
+			s.recordDecision(SchedulingDecision{
+				WorkloadID: workload.ID,
+				Action:     "boosted",
+				Reason:     fmt.Sprintf("waited %s, priority raised to %d", waited.Round(time.Second), workload.Priority),
+				Timestamp:  now,
+			})
+		}
+		s.logWorkload(walStarved, workload, false)
+	}
+	return events
+}
+
+// recordStart queues a started event for a workload leaving the queue; callers must hold the lock
+func (s *Scheduler) recordStart(workload *Workload, now time.Time) {
+	priority := workload.Priority
+	if workload.StarvedAt != nil {
+		priority -= s.config.Queue.StarvationBoost
+	}
+	s.queueEvents = append(s.queueEvents, QueueEvent{
+		Type:       "started",
+		WorkloadID: workload.ID,
+		Name:       workload.Name,
+		GPUID:      workload.AssignedGPU,
+		Priority:   priority,
+		Waited:     now.Sub(workload.queuedSince()),
+		Timestamp:  now,
+	})
+}
+
+// queuedSince returns when the workload last entered the queue
+func (w *Workload) queuedSince() time.Time {
+	if w.QueuedAt.IsZero() {
+		return w.SubmittedAt
+	}
+	return w.QueuedAt
+}
+
+// notifyQueueHandlers delivers events to registered handlers
+func notifyQueueHandlers(handlers []func(QueueEvent), events []QueueEvent) {
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedulerDetectsStarvation(t *testing.T) {
+	config := DefaultSchedulerConfig()
+	config.Queue.StarvationBoost = 5
+	scheduler := NewSchedulerWithConfig(StrategyPriority, config)
+
+	var events []QueueEvent
+	scheduler.OnQueueEvent(func(event QueueEvent) {
+		events = append(events, event)
+	})
+
+	low := &Workload{ID: "low", Name: "backfill", Priority: 1, MemoryRequired: 8000}
+	high := &Workload{ID: "high", Priority: 3, MemoryRequired: 8000}
+	fresh := &Workload{ID: "fresh", Priority: 0, MemoryRequired: 8000}
+	for _, workload := range []*Workload{low, high, fresh} {
+		scheduler.SubmitWorkload(workload)
+	}
+	low.QueuedAt = time.Now().Add(-time.Hour)
+	high.QueuedAt = time.Now().Add(-time.Hour)
+
+	waits := scheduler.GetQueueWaits()
+	if len(waits) != 3 || waits[2].WorkloadID != "fresh" || waits[0].Waited < time.Hour {
+		t.Fatalf("Expected waits ordered longest first, got %+v", waits)
+	}
+
+	// Only low-priority work starves, and only once
+	if starved := scheduler.CheckStarvation(); len(starved) != 1 || starved[0].WorkloadID != "low" {
+		t.Fatalf("Expected only the low-priority workload to starve, got %+v", starved)
+	}
+	if starved := scheduler.CheckStarvation(); len(starved) != 0 {
+		t.Errorf("Expected starvation to be reported once, got %+v", starved)
+	}
+	if low.Priority != 6 || low.StarvedAt == nil {
+		t.Errorf("Expected the starving workload boosted to priority 6, got %+v", low)
+	}
+
+	// The boosted workload now wins the only GPU
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+	events = nil
+	if err := scheduler.Schedule(); err != nil {
+		t.Fatalf("Failed to schedule: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != "started" || events[0].WorkloadID != "low" {
+		t.Fatalf("Expected one started event for the starving workload, got %+v", events)
+	}
+	if events[0].Priority != 1 || events[0].Waited < time.Hour {
+		t.Errorf("Expected the original priority and an hour's wait, got %+v", events[0])
+	}
+
+	decisions := scheduler.GetDecisions(time.Time{})
+	if decisions[0].Action != "boosted" || decisions[0].WorkloadID != "low" {
+		t.Errorf("Expected the boost in the decision log, got %+v", decisions[0])
+	}
+}
@@ -0,0 +1,445 @@
+package gpu
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RecurrenceLabel names the recurring workload a run was submitted for, so
+// usage records and chargeback can attribute the run's GPU time
+const RecurrenceLabel = "agentaflow.io/recurrence"
+
+// CatchUpPolicy decides what happens to runs that came due while recurring
+// workloads were not checked, e.g. while the process was down
+type CatchUpPolicy string
+
+const (
+	CatchUpLatest CatchUpPolicy = "latest" // Run once for all missed times
+	CatchUpAll    CatchUpPolicy = "all"    // Run every missed time
+	CatchUpSkip   CatchUpPolicy = "skip"   // Run nothing for missed times
+)
+
+// RunSkipped is the status of a scheduled run that was not submitted
+const RunSkipped WorkloadStatus = "skipped"
+
+// Recurrence submits a workload on a cron schedule
+type Recurrence struct {
+	Schedule       string        `yaml:"schedule" json:"schedule"`               // Cron expression, e.g. "0 2 * * *"
+	MaxConcurrency int           `yaml:"max_concurrency" json:"max_concurrency"` // Unfinished runs allowed at once; 1 when zero
+	CatchUp        CatchUpPolicy `yaml:"catch_up" json:"catch_up"`               // CatchUpLatest when empty
+}
+
+// RecurringWorkload is a workload template submitted on a recurrence. Each
+// run copies the template's requirements under a new ID.
+type RecurringWorkload struct {
+	Name       string
+	Template   Workload
+	Recurrence Recurrence
+}
+
+// RecurringRun is one scheduled run of a recurring workload
+type RecurringRun struct {
+	Recurrence  string         `json:"recurrence"`
+	WorkloadID  string         `json:"workload_id"`
+	ScheduledAt time.Time      `json:"scheduled_at"`
+	SubmittedAt *time.Time     `json:"submitted_at,omitempty"`
+	Status      WorkloadStatus `json:"status"`
+	Reason      string         `json:"reason,omitempty"` // Why the run was skipped or not submitted
+}
+
+// finished reports whether a run no longer holds a concurrency slot
+func (r RecurringRun) finished() bool {
+	switch r.Status {
+	case WorkloadPending, WorkloadRunning, WorkloadPaused:
+		return false
+	}
+	return true
+}
+
+// RecurringStatus describes a recurring workload and its recent runs
+type RecurringStatus struct {
+	Name       string         `json:"name"`
+	Workload   string         `json:"workload"`
+	Tenant     string         `json:"tenant"`
+	Recurrence Recurrence     `json:"recurrence"`
+	NextRun    time.Time      `json:"next_run"`
+	Active     int            `json:"active"`
+	Runs       []RecurringRun `json:"runs"` // Oldest first
+}
+
+// maxRecurringRuns bounds the run history kept per recurring workload
+const maxRecurringRuns = 100
+
+// maxCatchUpRuns bounds the missed runs submitted at once under CatchUpAll
+const maxCatchUpRuns = 100
+
+// recurrenceGrace is how late a run may be noticed before it counts as missed
+const recurrenceGrace = time.Minute
+
+// recurrenceCheckInterval is how often Start checks for due runs
+const recurrenceCheckInterval = 30 * time.Second
+
+// recurringEntry is a registered recurring workload and its state
+type recurringEntry struct {
+	workload RecurringWorkload
+	schedule *CronSchedule
+	next     time.Time
+	runs     []RecurringRun
+}
+
+// RecurrenceManager submits recurring workloads to a scheduler when their
+// schedules come due and keeps each one's run history
+type RecurrenceManager struct {
+	scheduler *Scheduler
+	entries   map[string]*recurringEntry
+	listeners []func(RecurringRun)
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	mu        sync.Mutex
+}
+
+// NewRecurrenceManager creates a manager submitting to scheduler
+func NewRecurrenceManager(scheduler *Scheduler) *RecurrenceManager {
+	return &RecurrenceManager{
+		scheduler: scheduler,
+		entries:   make(map[string]*recurringEntry),
+	}
+}
+
+// OnRun registers a listener for runs as they are submitted or skipped
+func (m *RecurrenceManager) OnRun(listener func(RecurringRun)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, listener)
+}
+
+// Add registers a recurring workload, replacing one with the same name but
+// keeping its run history. The first run is the schedule's next time after now.
+func (m *RecurrenceManager) Add(workload RecurringWorkload) error {
+	if workload.Name == "" {
+		return fmt.Errorf("recurring workload name cannot be empty")
+	}
+	if workload.Template.MemoryRequired == 0 {
+		return fmt.Errorf("workload memory requirement must be greater than 0")
+	}
+	schedule, err := ParseCronSchedule(workload.Recurrence.Schedule)
+	if err != nil {
+		return err
+	}
+	switch {
+	case workload.Recurrence.MaxConcurrency < 0:
+		return fmt.Errorf("max concurrency cannot be negative")
+	case workload.Recurrence.MaxConcurrency == 0:
+		workload.Recurrence.MaxConcurrency = 1
+	}
+	switch workload.Recurrence.CatchUp {
+	case "":
+		workload.Recurrence.CatchUp = CatchUpLatest
+	case CatchUpLatest, CatchUpAll, CatchUpSkip:
+	default:
+		return fmt.Errorf("unknown catch-up policy %q", workload.Recurrence.CatchUp)
+	}
+	for _, window := range workload.Template.Windows {
+		if err := window.Validate(); err != nil {
+			return fmt.Errorf("invalid start window: %v", err)
+		}
+	}
+
+	next := schedule.Next(time.Now())
+	if next.IsZero() {
+		return fmt.Errorf("schedule %q never runs", workload.Recurrence.Schedule)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry := &recurringEntry{workload: workload, schedule: schedule, next: next}
+	if existing, exists := m.entries[workload.Name]; exists {
+		entry.runs = existing.runs
+	}
+	m.entries[workload.Name] = entry
+	return nil
+}
+
+// Remove stops a recurring workload; runs already submitted continue
+func (m *RecurrenceManager) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.entries[name]; !exists {
+		return fmt.Errorf("recurring workload %s not found", name)
+	}
+	delete(m.entries, name)
+	return nil
+}
+
+// Get returns a registered recurring workload
+func (m *RecurrenceManager) Get(name string) (RecurringWorkload, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, exists := m.entries[name]
+	if !exists {
+		return RecurringWorkload{}, false
+	}
+	return entry.workload, true
+}
+
+// Workloads returns every registered recurring workload, ordered by name
+func (m *RecurrenceManager) Workloads() []RecurringWorkload {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	workloads := make([]RecurringWorkload, 0, len(m.entries))
+	for _, name := range m.names() {
+		workloads = append(workloads, m.entries[name].workload)
+	}
+	return workloads
+}
+
+// Tick submits the runs due at now and returns them, including skipped ones.
+// Missed runs are handled by each workload's catch-up policy, and runs beyond
+// the concurrency limit are skipped.
+func (m *RecurrenceManager) Tick(now time.Time) []RecurringRun {
+	m.mu.Lock()
+	m.refresh()
+
+	var runs []RecurringRun
+	for _, name := range m.names() {
+		entry := m.entries[name]
+		due := entry.due(now)
+		if len(due) == 0 {
+			continue
+		}
+
+		active := entry.active()
+		for i, at := range due {
+			run := RecurringRun{
+				Recurrence:  name,
+				WorkloadID:  fmt.Sprintf("%s-%s", name, at.UTC().Format("20060102-1504")),
+				ScheduledAt: at,
+				Status:      RunSkipped,
+			}
+			late := now.Sub(at) > recurrenceGrace
+			switch {
+			case entry.workload.Recurrence.CatchUp == CatchUpLatest && i < len(due)-1:
+				run.Reason = "superseded by a later run"
+			case entry.workload.Recurrence.CatchUp == CatchUpSkip && late:
+				run.Reason = "missed"
+			case active >= entry.workload.Recurrence.MaxConcurrency:
+				run.Reason = fmt.Sprintf("max concurrency %d reached", entry.workload.Recurrence.MaxConcurrency)
+			default:
+				if err := m.scheduler.SubmitWorkload(entry.newRun(run.WorkloadID)); err != nil {
+					run.Status = WorkloadFailed
+					run.Reason = err.Error()
+				} else {
+					submitted := time.Now()
+					run.SubmittedAt = &submitted
+					run.Status = WorkloadPending
+					active++
+				}
+			}
+			entry.record(run)
+			runs = append(runs, run)
+		}
+	}
+	listeners := append([]func(RecurringRun){}, m.listeners...)
+	m.mu.Unlock()
+
+	for _, run := range runs {
+		for _, listener := range listeners {
+			listener(run)
+		}
+	}
+	return runs
+}
+
+// Status returns every recurring workload by name with its run history
+func (m *RecurrenceManager) Status() []RecurringStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refresh()
+
+	statuses := make([]RecurringStatus, 0, len(m.entries))
+	for _, name := range m.names() {
+		entry := m.entries[name]
+		statuses = append(statuses, RecurringStatus{
+			Name:       name,
+			Workload:   entry.workloadName(),
+			Tenant:     entry.tenant(),
+			Recurrence: entry.workload.Recurrence,
+			NextRun:    entry.next,
+			Active:     entry.active(),
+			Runs:       append([]RecurringRun{}, entry.runs...),
+		})
+	}
+	return statuses
+}
+
+// Start checks for due runs in the background until Stop
+func (m *RecurrenceManager) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stopCh != nil {
+		return
+	}
+	m.stopCh = make(chan struct{})
+	m.doneCh = make(chan struct{})
+	go m.run(m.stopCh, m.doneCh)
+}
+
+// Stop halts background submission
+func (m *RecurrenceManager) Stop() {
+	m.mu.Lock()
+	stopCh, doneCh := m.stopCh, m.doneCh
+	m.stopCh, m.doneCh = nil, nil
+	m.mu.Unlock()
+
+	if stopCh == nil {
+		return
+	}
+	close(stopCh)
+	<-doneCh
+}
+
+// run ticks until stopped
+func (m *RecurrenceManager) run(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(recurrenceCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case now := <-ticker.C:
+			m.Tick(now)
+		}
+	}
+}
+
+// refresh updates unfinished runs from the scheduler: listed workloads are
+// still queued or running, and usage records hold the outcome of finished
+// ones; callers must hold the lock
+func (m *RecurrenceManager) refresh() {
+	var since time.Time
+	unfinished := false
+	for _, entry := range m.entries {
+		for _, run := range entry.runs {
+			if !run.finished() && (!unfinished || run.SubmittedAt.Before(since)) {
+				since = *run.SubmittedAt
+				unfinished = true
+			}
+		}
+	}
+	if !unfinished {
+		return
+	}
+
+	statuses := make(map[string]WorkloadStatus)
+	for _, record := range m.scheduler.GetUsageRecords(since) {
+		if record.Status == WorkloadCompleted || record.Status == WorkloadFailed {
+			statuses[record.WorkloadID] = record.Status
+		}
+	}
+	for _, workload := range m.scheduler.ListWorkloads() {
+		statuses[workload.ID] = workload.Status
+	}
+	for _, entry := range m.entries {
+		for i, run := range entry.runs {
+			if status, exists := statuses[run.WorkloadID]; exists && !run.finished() {
+				entry.runs[i].Status = status
+			}
+		}
+	}
+}
+
+// names returns the registered names in order; callers must hold the lock
+func (m *RecurrenceManager) names() []string {
+	names := make([]string, 0, len(m.entries))
+	for name := range m.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// due returns the scheduled times up to now that have not run and advances
+// the next run past now
+func (e *recurringEntry) due(now time.Time) []time.Time {
+	var due []time.Time
+	for !e.next.IsZero() && !e.next.After(now) {
+		if len(due) == maxCatchUpRuns {
+			// Keep the most recent times after a long outage
+			due = due[1:]
+		}
+		due = append(due, e.next)
+		e.next = e.schedule.Next(e.next)
+	}
+	return due
+}
+
+// active counts runs that are queued or running
+func (e *recurringEntry) active() int {
+	active := 0
+	for _, run := range e.runs {
+		if !run.finished() {
+			active++
+		}
+	}
+	return active
+}
+
+// record adds a run to the history
+func (e *recurringEntry) record(run RecurringRun) {
+	e.runs = append(e.runs, run)
+	if len(e.runs) > maxRecurringRuns {
+		e.runs = e.runs[len(e.runs)-maxRecurringRuns:]
+	}
+}
+
+// workloadName is the name runs are submitted under; runs share it so
+// profiles learned from earlier runs apply to later ones
+func (e *recurringEntry) workloadName() string {
+	if e.workload.Template.Name != "" {
+		return e.workload.Template.Name
+	}
+	return e.workload.Name
+}
+
+// tenant is the tenant runs are submitted for
+func (e *recurringEntry) tenant() string {
+	if e.workload.Template.Tenant != "" {
+		return e.workload.Template.Tenant
+	}
+	return DefaultTenant
+}
+
+// newRun copies the template's requirements into a new workload
+func (e *recurringEntry) newRun(id string) *Workload {
+	template := e.workload.Template
+	labels := copyLabels(template.Labels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[RecurrenceLabel] = e.workload.Name
+
+	return &Workload{
+		ID:              id,
+		Name:            e.workloadName(),
+		Priority:        template.Priority,
+		Class:           template.Class,
+		ClientID:        template.ClientID,
+		Tenant:          template.Tenant,
+		Labels:          labels,
+		Selector:        copyLabels(template.Selector),
+		Tolerations:     append([]Toleration(nil), template.Tolerations...),
+		Pool:            template.Pool,
+		Deferrable:      template.Deferrable,
+		Windows:         append([]TimeWindow(nil), template.Windows...),
+		MemoryRequired:  template.MemoryRequired,
+		EstimatedTime:   template.EstimatedTime,
+		EncoderSessions: template.EncoderSessions,
+		DecoderSessions: template.DecoderSessions,
+	}
+}
@@ -0,0 +1,129 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecurrenceManagerSubmitsRunsWithinConcurrency(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16384, Available: true})
+	manager := NewRecurrenceManager(scheduler)
+	var notified []RecurringRun
+	manager.OnRun(func(run RecurringRun) { notified = append(notified, run) })
+
+	err := manager.Add(RecurringWorkload{
+		Name:       "nightly-etl",
+		Template:   Workload{Name: "etl", MemoryRequired: 8000, Labels: map[string]string{"team": "data"}},
+		Recurrence: Recurrence{Schedule: "@hourly"},
+	})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	first := manager.Status()[0].NextRun
+
+	if runs := manager.Tick(first.Add(-time.Second)); len(runs) != 0 {
+		t.Fatalf("runs before the schedule: %+v", runs)
+	}
+	runs := manager.Tick(first)
+	if len(runs) != 1 || runs[0].Status != WorkloadPending || runs[0].SubmittedAt == nil {
+		t.Fatalf("runs = %+v, want one submitted run", runs)
+	}
+	scheduler.Schedule()
+	workloads := scheduler.ListWorkloads()
+	if len(workloads) != 1 || workloads[0].ID != runs[0].WorkloadID || workloads[0].Name != "etl" ||
+		workloads[0].Labels[RecurrenceLabel] != "nightly-etl" || workloads[0].Labels["team"] != "data" {
+		t.Fatalf("workloads = %+v", workloads)
+	}
+
+	// The first run is still going, so the next one is skipped
+	if runs := manager.Tick(first.Add(time.Hour)); len(runs) != 1 || runs[0].Status != RunSkipped {
+		t.Fatalf("runs = %+v, want a skipped run", runs)
+	}
+
+	if err := scheduler.CompleteWorkload(runs[0].WorkloadID); err != nil {
+		t.Fatalf("CompleteWorkload: %v", err)
+	}
+	if runs := manager.Tick(first.Add(2 * time.Hour)); len(runs) != 1 || runs[0].Status != WorkloadPending {
+		t.Fatalf("runs = %+v, want a run once the first completed", runs)
+	}
+
+	status := manager.Status()[0]
+	if status.Active != 1 || len(status.Runs) != 3 || !status.NextRun.Equal(first.Add(3*time.Hour)) {
+		t.Fatalf("status = %+v", status)
+	}
+	if status.Runs[0].Status != WorkloadCompleted || status.Runs[1].Status != RunSkipped {
+		t.Errorf("history = %+v", status.Runs)
+	}
+	if len(notified) != 3 {
+		t.Errorf("notified %d runs, want 3", len(notified))
+	}
+}
+
+func TestRecurrenceManagerCatchUpPolicies(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	manager := NewRecurrenceManager(scheduler)
+	for _, policy := range []CatchUpPolicy{CatchUpLatest, CatchUpSkip, CatchUpAll} {
+		err := manager.Add(RecurringWorkload{
+			Name:       string(policy),
+			Template:   Workload{MemoryRequired: 1000},
+			Recurrence: Recurrence{Schedule: "@hourly", MaxConcurrency: 5, CatchUp: policy},
+		})
+		if err != nil {
+			t.Fatalf("Add(%s): %v", policy, err)
+		}
+	}
+
+	// Three runs came due while nothing was checking
+	first := manager.Status()[0].NextRun
+	submitted := make(map[string]int)
+	for _, run := range manager.Tick(first.Add(150 * time.Minute)) {
+		if run.Status == WorkloadPending {
+			submitted[run.Recurrence]++
+		}
+	}
+	if submitted["latest"] != 1 || submitted["skip"] != 0 || submitted["all"] != 3 {
+		t.Errorf("submitted = %v, want latest 1, skip 0, all 3", submitted)
+	}
+	for _, status := range manager.Status() {
+		if len(status.Runs) != 3 {
+			t.Errorf("%s recorded %d runs, want 3", status.Name, len(status.Runs))
+		}
+	}
+}
+
+func TestRecurrenceManagerValidation(t *testing.T) {
+	manager := NewRecurrenceManager(NewScheduler(StrategyLeastUtilized))
+	invalid := []RecurringWorkload{
+		{Template: Workload{MemoryRequired: 1000}, Recurrence: Recurrence{Schedule: "@daily"}},
+		{Name: "a", Recurrence: Recurrence{Schedule: "@daily"}},
+		{Name: "a", Template: Workload{MemoryRequired: 1000}, Recurrence: Recurrence{Schedule: "daily"}},
+		{Name: "a", Template: Workload{MemoryRequired: 1000}, Recurrence: Recurrence{Schedule: "@daily", MaxConcurrency: -1}},
+		{Name: "a", Template: Workload{MemoryRequired: 1000}, Recurrence: Recurrence{Schedule: "@daily", CatchUp: "sometimes"}},
+		{Name: "a", Template: Workload{MemoryRequired: 1000}, Recurrence: Recurrence{Schedule: "0 0 30 2 *"}},
+	}
+	for _, workload := range invalid {
+		if err := manager.Add(workload); err == nil {
+			t.Errorf("Add(%+v) succeeded", workload)
+		}
+	}
+
+	if err := manager.Add(RecurringWorkload{Name: "a", Template: Workload{MemoryRequired: 1000}, Recurrence: Recurrence{Schedule: "@daily"}}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if recurrence := manager.Status()[0].Recurrence; recurrence.MaxConcurrency != 1 || recurrence.CatchUp != CatchUpLatest {
+		t.Errorf("defaults not applied: %+v", recurrence)
+	}
+	if workload, exists := manager.Get("a"); !exists || workload.Template.MemoryRequired != 1000 || workload.Recurrence.MaxConcurrency != 1 {
+		t.Errorf("Get = %+v, %v", workload, exists)
+	}
+	if workloads := manager.Workloads(); len(workloads) != 1 || workloads[0].Name != "a" {
+		t.Errorf("Workloads = %+v", workloads)
+	}
+	if err := manager.Remove("a"); err != nil || len(manager.Status()) != 0 {
+		t.Errorf("Remove: %v", err)
+	}
+	if err := manager.Remove("a"); err == nil {
+		t.Error("removed an unknown recurring workload")
+	}
+}
@@ -0,0 +1,132 @@
+package gpu
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrUnknownRemediation is returned for a remediation kind without a handler
+var ErrUnknownRemediation = errors.New("unknown remediation")
+
+// maxRemediationHistory caps the remediations kept, dropping the oldest
+const maxRemediationHistory = 100
+
+// Remediation records one attempt to fix a detected problem, e.g. terminating
+// an orphaned GPU process
+type Remediation struct {
+	Kind      string                 `json:"kind"`
+	Target    string                 `json:"target"`
+	Actor     string                 `json:"actor"`
+	Timestamp time.Time              `json:"timestamp"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// RemediationHandler carries out one kind of remediation on a target and
+// returns details worth auditing, also when it fails
+type RemediationHandler func(target string) (map[string]interface{}, error)
+
+// Remediator runs remediations on behalf of operators through handlers that
+// detectors register, and records every attempt for audit
+type Remediator struct {
+	handlers  map[string]RemediationHandler
+	listeners []func(Remediation)
+	history   []Remediation
+	succeeded int
+	failed    int
+	mu        sync.Mutex
+}
+
+// NewRemediator creates a remediator without handlers
+func NewRemediator() *Remediator {
+	return &Remediator{handlers: make(map[string]RemediationHandler)}
+}
+
+// Register sets the handler of a remediation kind, replacing an earlier one
+func (r *Remediator) Register(kind string, handler RemediationHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[kind] = handler
+}
+
+// OnRemediation registers a listener called for every attempt, e.g. one
+// writing an audit event
+func (r *Remediator) OnRemediation(listener func(Remediation)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, listener)
+}
+
+// Kinds returns the registered remediation kinds in order
+func (r *Remediator) Kinds() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.kindsLocked()
+}
+
+// kindsLocked returns the registered kinds in order; r.mu must be held
+func (r *Remediator) kindsLocked() []string {
+	kinds := make([]string, 0, len(r.handlers))
+	for kind := range r.handlers {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Remediate runs a remediation on target on behalf of actor. Attempts that
+// reach a handler are recorded whether or not they succeed.
+func (r *Remediator) Remediate(actor, kind, target string) (Remediation, error) {
+	r.mu.Lock()
+	handler, exists := r.handlers[kind]
+	r.mu.Unlock()
+	if !exists {
+		return Remediation{}, fmt.Errorf("%w: %s", ErrUnknownRemediation, kind)
+	}
+
+	remediation := Remediation{Kind: kind, Target: target, Actor: actor, Timestamp: time.Now()}
+	details, err := handler(target)
+	remediation.Details = details
+	if err != nil {
+		remediation.Error = err.Error()
+	}
+
+	r.mu.Lock()
+	if err == nil {
+		r.succeeded++
+	} else {
+		r.failed++
+	}
+	r.history = append(r.history, remediation)
+	if len(r.history) > maxRemediationHistory {
+		r.history = r.history[len(r.history)-maxRemediationHistory:]
+	}
+	listeners := append([]func(Remediation){}, r.listeners...)
+	r.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(remediation)
+	}
+	return remediation, err
+}
+
+// History returns the recorded remediations, oldest first
+func (r *Remediator) History() []Remediation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Remediation{}, r.history...)
+}
+
+// GetStats returns remediation statistics
+func (r *Remediator) GetStats() map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return map[string]interface{}{
+		"kinds":     r.kindsLocked(),
+		"succeeded": r.succeeded,
+		"failed":    r.failed,
+	}
+}
@@ -0,0 +1,38 @@
+package gpu
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRemediatorRecordsAttempts(t *testing.T) {
+	remediator := NewRemediator()
+	remediator.Register("restart", func(target string) (map[string]interface{}, error) {
+		if target == "broken" {
+			return map[string]interface{}{"attempts": 3}, fmt.Errorf("%s did not restart", target)
+		}
+		return nil, nil
+	})
+	var heard []Remediation
+	remediator.OnRemediation(func(remediation Remediation) { heard = append(heard, remediation) })
+
+	if _, err := remediator.Remediate("alice", "reboot", "node-1"); !errors.Is(err, ErrUnknownRemediation) {
+		t.Errorf("Expected ErrUnknownRemediation, got %v", err)
+	}
+	if _, err := remediator.Remediate("alice", "restart", "worker"); err != nil {
+		t.Errorf("Remediate failed: %v", err)
+	}
+	failed, err := remediator.Remediate("bob", "restart", "broken")
+	if err == nil || failed.Error == "" || failed.Details["attempts"] != 3 {
+		t.Errorf("Expected a recorded failure, got %+v, %v", failed, err)
+	}
+
+	history := remediator.History()
+	if len(history) != 2 || len(heard) != 2 || history[0].Target != "worker" || history[1].Actor != "bob" {
+		t.Errorf("history = %+v, want both attempts that reached a handler", history)
+	}
+	if stats := remediator.GetStats(); stats["succeeded"] != 1 || stats["failed"] != 1 {
+		t.Errorf("stats = %v", stats)
+	}
+}
@@ -0,0 +1,146 @@
+package gpu
+
+import "time"
+
+// scheduleCandidates splits the queue into workloads worth placing this pass
+// and workloads that cannot fit anywhere yet; callers must hold the lock.
+//
+// A workload that found no GPU in an earlier pass can only fit once some idle
+// GPU has more assignable memory or video sessions than it had then, so while
+// capacity has not grown only workloads queued since the last pass are examined.
+func (s *Scheduler) scheduleCandidates() (candidates, deferred []*Workload) {
+	grown := false
+	maxFree := uint64(0)
+	s.idleGPUs = 0
+	for id, gpu := range s.gpus {
+		free := assignableMemory(gpu)
+		if free > s.capacity[id] || s.openableSessions(gpu) > s.videoCapacity[id] {
+			grown = true
+		}
+		if free > maxFree {
+			maxFree = free
+		}
+		if free > 0 {
+			s.idleGPUs++
+		}
+	}
+
+	s.measurePools()
+
+	// Held workloads may be released by the clock alone, so re-examine everything
+	start := s.examined
+	if grown || start > len(s.workloadQueue) || s.heldWorkloads > 0 {
+		start = 0
+	}
+
+	now := time.Now()
+	s.heldWorkloads = 0
+	deferred = make([]*Workload, 0, len(s.workloadQueue))
+	deferred = append(deferred, s.workloadQueue[:start]...)
+	candidates = make([]*Workload, 0, len(s.workloadQueue)-start)
+	for _, workload := range s.workloadQueue[start:] {
+		if s.held(workload, now) {
+			s.heldWorkloads++
+			deferred = append(deferred, workload)
+		} else if workload.MemoryRequired > maxFree {
+			deferred = append(deferred, workload)
+		} else {
+			candidates = append(candidates, workload)
+		}
+	}
+	return candidates, deferred
+}
+
+// finishPass remembers the capacity the queue was examined against; callers must hold the lock
+func (s *Scheduler) finishPass(examined int, duration time.Duration) {
+	for id, gpu := range s.gpus {
+		s.capacity[id] = assignableMemory(gpu)
+		s.videoCapacity[id] = s.openableSessions(gpu)
+	}
+	for id := range s.capacity {
+		if _, exists := s.gpus[id]; !exists {
+			delete(s.capacity, id)
+			delete(s.videoCapacity, id)
+		}
+	}
+	s.examined = len(s.workloadQueue)
+	s.poolUsage = nil
+	s.compactWALIfDue()
+	s.lastPassExamined = examined
+	s.lastPassDuration = duration
+}
+
+// assignableMemory returns the memory a new workload could use on a GPU, or 0
+// when the GPU is unavailable or busy
+func assignableMemory(gpu *GPU) uint64 {
+	if !gpu.Available || gpu.CurrentWorkload != nil {
+		return 0
+	}
+	return freeMemory(gpu)
+}
+
+// Start schedules in the background whenever workloads are submitted or GPU
+// capacity is freed, so callers no longer need to call Schedule
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopCh != nil {
+		return
+	}
+	s.stopCh = make(chan struct{})
+	s.doneCh = make(chan struct{})
+	go s.run(s.stopCh, s.doneCh)
+	s.wake()
+}
+
+// Stop halts background scheduling
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	stopCh, doneCh := s.stopCh, s.doneCh
+	s.stopCh, s.doneCh = nil, nil
+	s.mu.Unlock()
+
+	if stopCh == nil {
+		return
+	}
+	close(stopCh)
+	<-doneCh
+}
+
+// heldRecheckInterval is how often held workloads are re-examined in the background
+const heldRecheckInterval = time.Minute
+
+// run performs a scheduling pass for every batch of wake-ups, and
+// periodically while workloads are held for a start time, until stopped
+func (s *Scheduler) run(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(heldRecheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.wakeCh:
+			s.Schedule()
+		case <-ticker.C:
+			s.mu.RLock()
+			held := s.heldWorkloads
+			s.mu.RUnlock()
+			if held > 0 {
+				s.Schedule()
+			}
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// wake requests a background pass; wake-ups arriving before the pass runs
+// are coalesced. Callers must hold the lock.
+func (s *Scheduler) wake() {
+	select {
+	case s.wakeCh <- struct{}{}:
+	default:
+	}
+}
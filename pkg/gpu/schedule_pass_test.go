@@ -0,0 +1,126 @@
+package gpu
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestScheduleExaminesOnlyNewWorkloadsUntilCapacityFrees(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+	scheduler.RegisterGPU(&GPU{ID: "gpu-1", MemoryTotal: 16000, Available: true})
+	scheduler.SubmitWorkload(&Workload{ID: "a", MemoryRequired: 12000})
+	scheduler.SubmitWorkload(&Workload{ID: "b", MemoryRequired: 12000})
+	for i := 0; i < 50; i++ {
+		scheduler.SubmitWorkload(&Workload{ID: fmt.Sprintf("queued-%d", i), MemoryRequired: 8000})
+	}
+	scheduler.Schedule()
+
+	examined := func() int {
+		return scheduler.GetUtilizationMetrics()["last_pass_examined"].(int)
+	}
+	if examined() != 52 {
+		t.Fatalf("Expected the first pass to examine every workload, got %d", examined())
+	}
+
+	scheduler.SubmitWorkload(&Workload{ID: "late", MemoryRequired: 1000})
+	scheduler.Schedule()
+	if examined() != 0 {
+		t.Errorf("Expected no workloads examined while every GPU is busy, got %d", examined())
+	}
+
+	scheduler.CompleteWorkload("a")
+	scheduler.Schedule()
+	if examined() != 51 {
+		t.Errorf("Expected a full pass once capacity freed, got %d examined", examined())
+	}
+	if queued := len(scheduler.workloadQueue); queued != 50 {
+		t.Errorf("Expected one queued workload placed, got %d still queued", queued)
+	}
+	decisions := scheduler.GetDecisions(time.Time{})
+	if placed := decisions[len(decisions)-1]; placed.WorkloadID != "queued-0" {
+		t.Errorf("Expected the oldest queued workload placed on the freed GPU, got %s", placed.WorkloadID)
+	}
+}
+
+func TestBackgroundSchedulingReactsToEvents(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	first := &Workload{ID: "first", MemoryRequired: 8000}
+	second := &Workload{ID: "second", MemoryRequired: 8000}
+	scheduler.SubmitWorkload(first)
+	scheduler.SubmitWorkload(second)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+
+	waitForStatus := func(workload *Workload, status WorkloadStatus) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			scheduler.mu.RLock()
+			current := workload.Status
+			scheduler.mu.RUnlock()
+			if current == status {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Workload %s never became %s", workload.ID, status)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	waitForStatus(first, WorkloadRunning)
+	if err := scheduler.CompleteWorkload("first"); err != nil {
+		t.Fatalf("CompleteWorkload failed: %v", err)
+	}
+	waitForStatus(second, WorkloadRunning)
+
+	scheduler.Stop()
+	scheduler.Stop()
+}
+
+// newSaturatedScheduler returns a scheduler with every GPU busy and queued workloads waiting
+func newSaturatedScheduler(strategy SchedulingStrategy, gpus, queued int) *Scheduler {
+	scheduler := NewScheduler(strategy)
+	for i := 0; i < gpus; i++ {
+		scheduler.RegisterGPU(&GPU{ID: fmt.Sprintf("gpu-%d", i), MemoryTotal: 81920, Available: true, Utilization: float64(i % 100)})
+		scheduler.SubmitWorkload(&Workload{ID: fmt.Sprintf("running-%d", i), MemoryRequired: 40960})
+	}
+	scheduler.Schedule()
+	for i := 0; i < queued; i++ {
+		scheduler.SubmitWorkload(&Workload{ID: fmt.Sprintf("queued-%d", i), Priority: i % 5, MemoryRequired: uint64(8192 + i%8*8192)})
+	}
+	scheduler.Schedule()
+	return scheduler
+}
+
+// BenchmarkScheduleDecision measures one placement decision with 10k queued
+// workloads: a GPU frees up, a workload is submitted and the scheduler places
+// the next workload
+func BenchmarkScheduleDecision(b *testing.B) {
+	for _, strategy := range allStrategies {
+		b.Run(string(strategy), func(b *testing.B) {
+			scheduler := newSaturatedScheduler(strategy, 64, 10000)
+			latencies := make([]time.Duration, 0, b.N)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				gpu := scheduler.gpus[fmt.Sprintf("gpu-%d", i%64)]
+				scheduler.CompleteWorkload(gpu.CurrentWorkload.ID)
+				scheduler.SubmitWorkload(&Workload{ID: fmt.Sprintf("new-%d", i), Priority: i % 5, MemoryRequired: 16384})
+
+				started := time.Now()
+				scheduler.Schedule()
+				latencies = append(latencies, time.Since(started))
+			}
+			b.StopTimer()
+
+			sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+			b.ReportMetric(float64(latencies[len(latencies)/2].Microseconds()), "p50-µs")
+		})
+	}
+}
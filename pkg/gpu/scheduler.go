@@ -10,12 +10,32 @@ import (
 // SchedulerConfig holds configuration for the GPU scheduler
 type SchedulerConfig struct {
 	UtilizationGoal float64
+	Colocation      ColocationConfig
+	Profiles        ProfileConfig
+	Queue           QueueConfig
+	Aging           AgingConfig
+	Idempotency     IdempotencyConfig
+	Pools           map[string]PoolConfig
+	Energy          EnergyConfig
+	Thermal         ThermalConfig
+	Tariff          TariffConfig
+	Video           VideoConfig
 }
 
 // DefaultSchedulerConfig returns default configuration
 func DefaultSchedulerConfig() *SchedulerConfig {
 	return &SchedulerConfig{
 		UtilizationGoal: 80.0,
+		Colocation:      DefaultColocationConfig(),
+		Profiles:        DefaultProfileConfig(),
+		Queue:           DefaultQueueConfig(),
+		Aging:           DefaultAgingConfig(),
+		Idempotency:     DefaultIdempotencyConfig(),
+		Pools:           make(map[string]PoolConfig),
+		Energy:          DefaultEnergyConfig(),
+		Thermal:         DefaultThermalConfig(),
+		Tariff:          DefaultTariffConfig(),
+		Video:           DefaultVideoConfig(),
 	}
 }
 
@@ -25,7 +45,40 @@ type Scheduler struct {
 	workloadQueue []*Workload
 	strategy      SchedulingStrategy
 	config        *SchedulerConfig
-	mu            sync.RWMutex
+
+	colocationHandlers []func(ColocationEvent)
+	queueHandlers      []func(QueueEvent)
+	queueEvents        []QueueEvent
+	decisions          []SchedulingDecision
+	capacity           map[string]uint64     // Assignable memory per GPU after the last pass
+	videoCapacity      map[string]int        // Openable encoder and decoder sessions per GPU after the last pass
+	examined           int                   // Queue prefix that did not fit in the last pass
+	idleGPUs           int                   // Assignable GPUs left in the current pass
+	poolUsage          map[string]*poolUsage // What each pool holds during the current pass
+	lastPassExamined   int
+	lastPassDuration   time.Duration
+	wal                *writeAheadLog
+	walErr             error
+	wakeCh             chan struct{}
+	stopCh             chan struct{}
+	doneCh             chan struct{}
+	profiles           map[string]*WorkloadProfile
+	usage              map[string]*workloadUsage
+	submissions        map[string]*submission // Keyed by client and idempotency key
+	submissionOrder    []*submission
+	deduplicated       int
+	energyReport       EnergyReport       // Energy-aware placements against least-utilized
+	temperatures       map[string]float64 // Smoothed temperature per GPU
+	throttleSamples    int                // Metrics taken at the slowdown temperature
+	heldWorkloads      int                // Queued workloads waiting for a start window or cheaper power
+	tariffReport       TariffReport
+	usageRecords       []UsageRecord // GPU time of workloads that left their GPU
+	lifecycleHandlers  []func(LifecycleEvent)
+	lifecycleCh        chan LifecycleEvent          // Created with the first lifecycle handler
+	droppedLifecycle   int                          // Lifecycle events dropped while handlers were behind
+	finished           map[string]*finishedWorkload // Workloads that left the scheduler, for artifacts
+	finishedOrder      []string
+	mu                 sync.RWMutex
 }
 
 // NewScheduler creates a new GPU scheduler with default config
@@ -43,6 +96,15 @@ func NewSchedulerWithConfig(strategy SchedulingStrategy, config *SchedulerConfig
 		workloadQueue: make([]*Workload, 0),
 		strategy:      strategy,
 		config:        config,
+		capacity:      make(map[string]uint64),
+		videoCapacity: make(map[string]int),
+		wakeCh:        make(chan struct{}, 1),
+		profiles:      make(map[string]*WorkloadProfile),
+		usage:         make(map[string]*workloadUsage),
+		submissions:   make(map[string]*submission),
+		temperatures:  make(map[string]float64),
+
+		finished: make(map[string]*finishedWorkload),
 	}
 }
 
@@ -61,10 +123,14 @@ func (s *Scheduler) RegisterGPU(gpu *GPU) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.gpus[gpu.ID] = gpu
+	s.wake()
 	return nil
 }
 
-// SubmitWorkload adds a new workload to the queue
+// SubmitWorkload adds a new workload to the queue. A workload carrying an
+// idempotency key already submitted by the same client within the
+// deduplication window is not queued again; it is updated to describe the
+// original workload instead.
 func (s *Scheduler) SubmitWorkload(workload *Workload) error {
 	if workload == nil {
 		return fmt.Errorf("workload cannot be nil")
@@ -75,27 +141,79 @@ func (s *Scheduler) SubmitWorkload(workload *Workload) error {
 	if workload.MemoryRequired == 0 {
 		return fmt.Errorf("workload memory requirement must be greater than 0")
 	}
+	for _, window := range workload.Windows {
+		if err := window.Validate(); err != nil {
+			return fmt.Errorf("invalid start window: %v", err)
+		}
+	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	now := time.Now()
+	duplicate, err := s.deduplicate(workload, now)
+	if err != nil || duplicate {
+		return err
+	}
+
+	s.applyProfile(workload)
+	if workload.Tenant == "" {
+		workload.Tenant = DefaultTenant
+	}
 	workload.Status = WorkloadPending
-	workload.SubmittedAt = time.Now()
+	workload.SubmittedAt = now
+	workload.QueuedAt = workload.SubmittedAt
 	s.workloadQueue = append(s.workloadQueue, workload)
+	s.rememberSubmission(workload)
+	s.logWorkload(walSubmitted, workload, false)
+	s.emitLifecycle(LifecycleQueued, nil, workload, false, "")
+	s.compactWALIfDue()
+	s.wake()
 
 	return nil
 }
 
-// Schedule assigns workloads to GPUs based on the scheduling strategy
+// Schedule assigns workloads to GPUs based on the scheduling strategy. Only
+// workloads queued since the last pass are considered unless GPU capacity
+// has been freed since then.
 func (s *Scheduler) Schedule() error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 
 	if len(s.workloadQueue) == 0 {
+		s.mu.Unlock()
 		return nil
 	}
 
-	switch s.strategy {
+	started := time.Now()
+	candidates, deferred := s.scheduleCandidates()
+	s.workloadQueue = candidates
+
+	err := s.schedulePools()
+	s.workloadQueue = append(deferred, s.workloadQueue...)
+
+	// Training jobs that found no free GPU may share one running inference
+	var events []ColocationEvent
+	if err == nil && s.config.Colocation.Enabled {
+		events = s.scheduleColocated()
+	}
+	s.recordColocationDecisions(events)
+	handlers := s.colocationHandlers
+	s.finishPass(len(candidates), time.Since(started))
+
+	// Report workloads that started, then those still waiting past the objective
+	queueEvents := append(s.queueEvents, s.detectStarvation(time.Now())...)
+	s.queueEvents = nil
+	queueHandlers := s.queueHandlers
+	s.mu.Unlock()
+
+	notifyColocationHandlers(handlers, events)
+	notifyQueueHandlers(queueHandlers, queueEvents)
+	return err
+}
+
+// runStrategy places the queued workloads with a strategy; callers must hold the lock
+func (s *Scheduler) runStrategy(strategy SchedulingStrategy) error {
+	switch strategy {
 	case StrategyLeastUtilized:
 		return s.scheduleLeastUtilized()
 	case StrategyBestFit:
@@ -104,6 +222,8 @@ func (s *Scheduler) Schedule() error {
 		return s.schedulePriority()
 	case StrategyRoundRobin:
 		return s.scheduleRoundRobin()
+	case StrategyEnergyAware:
+		return s.scheduleEnergyAware()
 	default:
 		return s.scheduleLeastUtilized()
 	}
@@ -113,8 +233,12 @@ func (s *Scheduler) Schedule() error {
 func (s *Scheduler) scheduleLeastUtilized() error {
 	remaining := make([]*Workload, 0)
 
-	for _, workload := range s.workloadQueue {
-		gpu := s.findLeastUtilizedGPU(workload.MemoryRequired)
+	for i, workload := range s.workloadQueue {
+		if s.idleGPUs == 0 {
+			remaining = append(remaining, s.workloadQueue[i:]...)
+			break
+		}
+		gpu := s.findLeastUtilizedGPU(workload)
 		if gpu != nil {
 			s.assignWorkload(gpu, workload)
 		} else {
@@ -130,8 +254,12 @@ func (s *Scheduler) scheduleLeastUtilized() error {
 func (s *Scheduler) scheduleBestFit() error {
 	remaining := make([]*Workload, 0)
 
-	for _, workload := range s.workloadQueue {
-		gpu := s.findBestFitGPU(workload.MemoryRequired)
+	for i, workload := range s.workloadQueue {
+		if s.idleGPUs == 0 {
+			remaining = append(remaining, s.workloadQueue[i:]...)
+			break
+		}
+		gpu := s.findBestFitGPU(workload)
 		if gpu != nil {
 			s.assignWorkload(gpu, workload)
 		} else {
@@ -145,10 +273,22 @@ func (s *Scheduler) scheduleBestFit() error {
 
 // schedulePriority schedules based on workload priority
 func (s *Scheduler) schedulePriority() error {
-	// Sort by priority (higher priority first) using efficient sort
-	sort.Slice(s.workloadQueue, func(i, j int) bool {
-		return s.workloadQueue[i].Priority > s.workloadQueue[j].Priority
+	// Sort by effective priority (higher first), keeping submission order among equals
+	type ranked struct {
+		workload *Workload
+		priority int
+	}
+	now := time.Now()
+	queue := make([]ranked, len(s.workloadQueue))
+	for i, workload := range s.workloadQueue {
+		queue[i] = ranked{workload, s.effectivePriority(workload, now)}
+	}
+	sort.SliceStable(queue, func(i, j int) bool {
+		return queue[i].priority > queue[j].priority
 	})
+	for i := range queue {
+		s.workloadQueue[i] = queue[i].workload
+	}
 
 	return s.scheduleLeastUtilized()
 }
@@ -169,7 +309,11 @@ func (s *Scheduler) scheduleRoundRobin() error {
 	remaining := make([]*Workload, 0)
 	gpuIndex := 0
 
-	for _, workload := range s.workloadQueue {
+	for i, workload := range s.workloadQueue {
+		if s.idleGPUs == 0 {
+			remaining = append(remaining, s.workloadQueue[i:]...)
+			break
+		}
 		assigned := false
 		for i := 0; i < len(gpuList); i++ {
 			gpu := gpuList[gpuIndex]
@@ -192,15 +336,22 @@ func (s *Scheduler) scheduleRoundRobin() error {
 }
 
 // findLeastUtilizedGPU finds the GPU with lowest utilization
-func (s *Scheduler) findLeastUtilizedGPU(memoryRequired uint64) *GPU {
+func (s *Scheduler) findLeastUtilizedGPU(workload *Workload) *GPU {
 	var bestGPU *GPU
 	minUtilization := 101.0
 
+	bestAvoided := false
+
 	for _, gpu := range s.gpus {
-		if s.canAssign(gpu, &Workload{MemoryRequired: memoryRequired}) {
-			if gpu.Utilization < minUtilization {
-				minUtilization = gpu.Utilization
+		if s.canAssign(gpu, workload) {
+			// Avoided GPUs (PreferNoSchedule taints, hot spots) only win when nothing else fits
+			avoided := s.avoids(gpu, workload)
+			load := placementLoad(gpu, workload) + s.thermalPenalty(gpu, workload)
+			if bestGPU == nil || (bestAvoided && !avoided) ||
+				(avoided == bestAvoided && load < minUtilization) {
+				minUtilization = load
 				bestGPU = gpu
+				bestAvoided = avoided
 			}
 		}
 	}
@@ -209,15 +360,23 @@ func (s *Scheduler) findLeastUtilizedGPU(memoryRequired uint64) *GPU {
 }
 
 // findBestFitGPU finds the GPU with just enough free memory
-func (s *Scheduler) findBestFitGPU(memoryRequired uint64) *GPU {
+func (s *Scheduler) findBestFitGPU(workload *Workload) *GPU {
 	var bestGPU *GPU
 	minFreeMemory := uint64(^uint64(0))
 
+	bestAvoided := false
+
 	for _, gpu := range s.gpus {
-		freeMemory := gpu.MemoryTotal - gpu.MemoryUsed
-		if freeMemory >= memoryRequired && freeMemory < minFreeMemory {
-			minFreeMemory = freeMemory
+		if !s.canAssign(gpu, workload) {
+			continue
+		}
+		free := freeMemory(gpu)
+		avoided := s.avoids(gpu, workload)
+		if bestGPU == nil || (bestAvoided && !avoided) ||
+			(avoided == bestAvoided && free < minFreeMemory) {
+			minFreeMemory = free
 			bestGPU = gpu
+			bestAvoided = avoided
 		}
 	}
 
@@ -230,8 +389,29 @@ func (s *Scheduler) canAssign(gpu *GPU, workload *Workload) bool {
 		return false
 	}
 
-	freeMemory := gpu.MemoryTotal - gpu.MemoryUsed
-	return freeMemory >= workload.MemoryRequired
+	return gpu.Pool == workload.Pool &&
+		freeMemory(gpu) >= workload.MemoryRequired &&
+		MatchesSelector(gpu.Labels, workload.Selector) &&
+		schedulable(gpu, workload) &&
+		s.videoFits(gpu, workload) &&
+		s.withinQuota(workload, true)
+}
+
+// freeMemory returns a GPU's unallocated memory, or 0 when it is over-reported
+func freeMemory(gpu *GPU) uint64 {
+	if gpu.MemoryUsed >= gpu.MemoryTotal {
+		return 0
+	}
+	return gpu.MemoryTotal - gpu.MemoryUsed
+}
+
+// releaseMemory returns a workload's memory to a GPU without underflowing
+func releaseMemory(gpu *GPU, memory uint64) {
+	if memory > gpu.MemoryUsed {
+		gpu.MemoryUsed = 0
+		return
+	}
+	gpu.MemoryUsed -= memory
 }
 
 // assignWorkload assigns a workload to a GPU
@@ -241,8 +421,34 @@ func (s *Scheduler) assignWorkload(gpu *GPU, workload *Workload) {
 	workload.AssignedGPU = gpu.ID
 	workload.StartedAt = &now
 
+	reason := fmt.Sprintf("%d MB required, %d MB free", workload.MemoryRequired, freeMemory(gpu))
+	if workload.Profile != "" {
+		reason += fmt.Sprintf(", %s profile", workload.Profile)
+	}
+	if len(workload.Selector) > 0 {
+		reason += fmt.Sprintf(", selector %s", FormatSelector(workload.Selector))
+	}
+	reason += videoReason(gpu, workload)
+	if s.thermallyAware(workload) && s.recentTemperature(gpu) > 0 {
+		reason += fmt.Sprintf(", %.0f°C thermal headroom", s.thermalHeadroom(gpu))
+	}
+	s.recordDecision(SchedulingDecision{
+		WorkloadID: workload.ID,
+		GPUID:      gpu.ID,
+		Strategy:   s.poolStrategy(workload.Pool),
+		Action:     "assigned",
+		Reason:     reason,
+		Timestamp:  now,
+	})
+
+	s.recordStart(workload, now)
 	gpu.CurrentWorkload = workload
 	gpu.MemoryUsed += workload.MemoryRequired
+	s.chargePool(workload, true)
+	s.idleGPUs--
+	s.logWorkload(walAssigned, workload, false)
+	s.emitLifecycle(LifecycleScheduled, gpu, workload, false, reason)
+	s.emitLifecycle(LifecycleStarted, gpu, workload, false, "")
 }
 
 // GetUtilizationMetrics returns overall GPU utilization statistics
@@ -255,11 +461,19 @@ func (s *Scheduler) GetUtilizationMetrics() map[string]interface{} {
 	totalUtilization := 0.0
 	totalMemoryUsed := uint64(0)
 	totalMemoryAvailable := uint64(0)
+	colocatedWorkloads := 0
+	pausedWorkloads := 0
 
 	for _, gpu := range s.gpus {
 		if gpu.CurrentWorkload != nil {
 			activeGPUs++
 		}
+		if gpu.ColocatedWorkload != nil {
+			colocatedWorkloads++
+			if gpu.ColocatedWorkload.Status == WorkloadPaused {
+				pausedWorkloads++
+			}
+		}
 		totalUtilization += gpu.Utilization
 		totalMemoryUsed += gpu.MemoryUsed
 		totalMemoryAvailable += gpu.MemoryTotal
@@ -276,36 +490,192 @@ func (s *Scheduler) GetUtilizationMetrics() map[string]interface{} {
 	}
 
 	return map[string]interface{}{
-		"total_gpus":          totalGPUs,
-		"active_gpus":         activeGPUs,
-		"average_utilization": avgUtilization,
-		"memory_used_mb":      totalMemoryUsed,
-		"memory_available_mb": totalMemoryAvailable,
-		"memory_utilization":  memoryUtilization,
-		"pending_workloads":   len(s.workloadQueue),
-		"utilization_goal":    s.config.UtilizationGoal,
+		"total_gpus":               totalGPUs,
+		"active_gpus":              activeGPUs,
+		"average_utilization":      avgUtilization,
+		"memory_used_mb":           totalMemoryUsed,
+		"memory_available_mb":      totalMemoryAvailable,
+		"memory_utilization":       memoryUtilization,
+		"pending_workloads":        len(s.workloadQueue),
+		"utilization_goal":         s.config.UtilizationGoal,
+		"colocated_workloads":      colocatedWorkloads,
+		"paused_workloads":         pausedWorkloads,
+		"last_pass_examined":       s.lastPassExamined,
+		"last_pass_ms":             float64(s.lastPassDuration) / float64(time.Millisecond),
+		"deduplicated_submissions": s.deduplicated,
+		"hot_gpus":                 s.hotGPUs(),
+		"thermal_throttle_samples": s.throttleSamples,
+		"held_workloads":           s.heldWorkloads,
+		"dropped_lifecycle_events": s.droppedLifecycle,
 	}
 }
 
 // CompleteWorkload marks a workload as completed and frees GPU resources
 func (s *Scheduler) CompleteWorkload(workloadID string) error {
+	return s.finishWorkload(workloadID, WorkloadCompleted, nil)
+}
+
+// FailWorkload marks a running workload as failed and frees GPU resources.
+// Failed runs are not learned into workload profiles.
+func (s *Scheduler) FailWorkload(workloadID string) error {
+	return s.finishWorkload(workloadID, WorkloadFailed, nil)
+}
+
+// finishWorkload ends a running workload with the given status and outputs
+func (s *Scheduler) finishWorkload(workloadID string, status WorkloadStatus, artifacts []Artifact) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 
 	for _, gpu := range s.gpus {
+		if gpu.ColocatedWorkload != nil && gpu.ColocatedWorkload.ID == workloadID {
+			now := time.Now()
+			gpu.ColocatedWorkload.Artifacts = append(gpu.ColocatedWorkload.Artifacts, artifacts...)
+			s.endWorkload(gpu, gpu.ColocatedWorkload, status, now)
+			gpu.ColocatedWorkload = nil
+			s.compactWALIfDue()
+			s.wake()
+			s.mu.Unlock()
+			return nil
+		}
+
 		if gpu.CurrentWorkload != nil && gpu.CurrentWorkload.ID == workloadID {
 			now := time.Now()
-			gpu.CurrentWorkload.CompletedAt = &now
-			gpu.CurrentWorkload.Status = WorkloadCompleted
-			gpu.MemoryUsed -= gpu.CurrentWorkload.MemoryRequired
+			gpu.CurrentWorkload.Artifacts = append(gpu.CurrentWorkload.Artifacts, artifacts...)
+			s.endWorkload(gpu, gpu.CurrentWorkload, status, now)
 			gpu.CurrentWorkload = nil
+
+			// A co-located training job takes over the GPU once inference finishes
+			var events []ColocationEvent
+			if promoted := gpu.ColocatedWorkload; promoted != nil {
+				promoted.Status = WorkloadRunning
+				promoted.PausedAt = nil
+				gpu.CurrentWorkload = promoted
+				gpu.ColocatedWorkload = nil
+				s.logWorkload(walPromoted, promoted, false)
+				events = append(events, ColocationEvent{
+					Type:        "promoted",
+					GPUID:       gpu.ID,
+					WorkloadID:  promoted.ID,
+					Utilization: gpu.Utilization,
+					Timestamp:   now,
+				})
+			}
+			s.compactWALIfDue()
+			handlers := s.colocationHandlers
+			s.wake()
+			s.mu.Unlock()
+
+			notifyColocationHandlers(handlers, events)
 			return nil
 		}
 	}
 
+	s.mu.Unlock()
 	return fmt.Errorf("workload %s not found", workloadID)
 }
 
+// endWorkload records a workload's final status and releases its memory; callers must hold the lock
+func (s *Scheduler) endWorkload(gpu *GPU, workload *Workload, status WorkloadStatus, now time.Time) {
+	workload.CompletedAt = &now
+	workload.Status = status
+	releaseMemory(gpu, workload.MemoryRequired)
+	op := walCompleted
+	if status == WorkloadFailed {
+		op = walFailed
+	}
+	s.logWorkload(op, workload, false)
+	lifecycle := LifecycleCompleted
+	if status == WorkloadFailed {
+		lifecycle = LifecycleFailed
+	}
+	s.emitLifecycle(lifecycle, gpu, workload, gpu.ColocatedWorkload == workload, "")
+	s.recordTariffSavings(gpu, workload, now)
+	s.recordUsage(gpu, workload, now, status)
+	s.retainFinished(workload)
+	if status == WorkloadCompleted {
+		s.learnProfile(gpu, workload, now)
+	} else {
+		delete(s.usage, workload.ID)
+	}
+}
+
+// WorkloadInfo is a workload as shown in workload listings
+type WorkloadInfo struct {
+	ID                string            `json:"id"`
+	Name              string            `json:"name"`
+	Tenant            string            `json:"tenant"`
+	Status            WorkloadStatus    `json:"status"`
+	Class             WorkloadClass     `json:"class,omitempty"`
+	Priority          int               `json:"priority"`
+	EffectivePriority int               `json:"effective_priority"` // Priority plus queue aging
+	MemoryRequired    uint64            `json:"memory_required_mb"`
+	EncoderSessions   int               `json:"encoder_sessions,omitempty"`
+	DecoderSessions   int               `json:"decoder_sessions,omitempty"`
+	AssignedGPU       string            `json:"assigned_gpu,omitempty"`
+	SubmittedAt       time.Time         `json:"submitted_at"`
+	StartedAt         *time.Time        `json:"started_at,omitempty"`
+	WaitSeconds       float64           `json:"wait_seconds,omitempty"` // Time queued, for pending workloads
+	Pool              string            `json:"pool,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Selector          map[string]string `json:"selector,omitempty"`
+	Artifacts         []Artifact        `json:"artifacts,omitempty"`
+}
+
+// ListWorkloads returns pending workloads in the order the priority strategy
+// would consider them, followed by running and paused workloads by GPU
+func (s *Scheduler) ListWorkloads() []WorkloadInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	workloads := make([]WorkloadInfo, 0, len(s.workloadQueue)+len(s.gpus))
+	for _, workload := range s.workloadQueue {
+		info := newWorkloadInfo(workload, s.effectivePriority(workload, now))
+		info.WaitSeconds = now.Sub(workload.queuedSince()).Seconds()
+		workloads = append(workloads, info)
+	}
+	sort.SliceStable(workloads, func(i, j int) bool {
+		return workloads[i].EffectivePriority > workloads[j].EffectivePriority
+	})
+
+	gpuIDs := make([]string, 0, len(s.gpus))
+	for id := range s.gpus {
+		gpuIDs = append(gpuIDs, id)
+	}
+	sort.Strings(gpuIDs)
+	for _, id := range gpuIDs {
+		gpu := s.gpus[id]
+		for _, workload := range []*Workload{gpu.CurrentWorkload, gpu.ColocatedWorkload} {
+			if workload != nil {
+				workloads = append(workloads, newWorkloadInfo(workload, workload.Priority))
+			}
+		}
+	}
+	return workloads
+}
+
+// newWorkloadInfo copies the listed fields of a workload
+func newWorkloadInfo(workload *Workload, effectivePriority int) WorkloadInfo {
+	return WorkloadInfo{
+		ID:                workload.ID,
+		Name:              workload.Name,
+		Tenant:            workload.Tenant,
+		Status:            workload.Status,
+		Class:             workload.Class,
+		Priority:          workload.Priority,
+		EffectivePriority: effectivePriority,
+		MemoryRequired:    workload.MemoryRequired,
+		EncoderSessions:   workload.EncoderSessions,
+		DecoderSessions:   workload.DecoderSessions,
+		AssignedGPU:       workload.AssignedGPU,
+		Pool:              workload.Pool,
+		SubmittedAt:       workload.SubmittedAt,
+		StartedAt:         workload.StartedAt,
+		Labels:            copyLabels(workload.Labels),
+		Selector:          copyLabels(workload.Selector),
+		Artifacts:         append([]Artifact(nil), workload.Artifacts...),
+	}
+}
+
 // GetGPUStatus returns the current status of all GPUs
 func (s *Scheduler) GetGPUStatus() []*GPU {
 	s.mu.RLock()
@@ -0,0 +1,373 @@
+package gpu
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+var allStrategies = []SchedulingStrategy{StrategyLeastUtilized, StrategyBestFit, StrategyPriority, StrategyRoundRobin, StrategyEnergyAware}
+
+// schedulerModel tracks every workload submitted to a scheduler under test
+type schedulerModel struct {
+	scheduler *Scheduler
+	workloads map[string]*Workload
+	finished  map[string]WorkloadStatus
+	nextID    int
+}
+
+func newSchedulerModel(scheduler *Scheduler) *schedulerModel {
+	return &schedulerModel{
+		scheduler: scheduler,
+		workloads: make(map[string]*Workload),
+		finished:  make(map[string]WorkloadStatus),
+	}
+}
+
+// running returns the IDs of workloads currently placed on a GPU
+func (m *schedulerModel) running() []string {
+	ids := make([]string, 0)
+	for _, gpu := range m.scheduler.gpus {
+		for _, workload := range []*Workload{gpu.CurrentWorkload, gpu.ColocatedWorkload} {
+			if workload != nil {
+				ids = append(ids, workload.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// choices supplies the random decisions of a run: a seeded *rand.Rand, or the
+// fuzzer's bytes
+type choices interface {
+	Intn(n int) int
+	Float64() float64
+}
+
+// byteChoices reads decisions from fuzz input, choosing 0 once it runs out
+type byteChoices struct {
+	data []byte
+}
+
+func (c *byteChoices) next() byte {
+	if len(c.data) == 0 {
+		return 0
+	}
+	b := c.data[0]
+	c.data = c.data[1:]
+	return b
+}
+
+func (c *byteChoices) Intn(n int) int { return int(c.next()) % n }
+
+func (c *byteChoices) Float64() float64 { return float64(c.next()) / 255 }
+
+// newModelScheduler builds a scheduler with 1-6 GPUs of random size, an eighth of them offline
+func newModelScheduler(strategy SchedulingStrategy, colocation bool, rng choices) *schedulerModel {
+	config := DefaultSchedulerConfig()
+	config.Colocation.Enabled = colocation
+	config.Colocation.MaxPriority = 2
+	scheduler := NewSchedulerWithConfig(strategy, config)
+
+	for i := 0; i < 1+rng.Intn(6); i++ {
+		scheduler.RegisterGPU(&GPU{
+			ID:          fmt.Sprintf("gpu-%d", i),
+			MemoryTotal: uint64(8+rng.Intn(73)) * 1024,
+			Available:   rng.Intn(8) != 0,
+		})
+	}
+	return newSchedulerModel(scheduler)
+}
+
+// step applies one random operation to the scheduler
+func (m *schedulerModel) step(t *testing.T, rng choices) string {
+	switch op := rng.Intn(10); {
+	case op < 4:
+		m.nextID++
+		workload := &Workload{
+			ID:             fmt.Sprintf("w-%d", m.nextID),
+			Priority:       rng.Intn(5),
+			MemoryRequired: uint64(1+rng.Intn(48)) * 1024,
+		}
+		if rng.Intn(2) == 0 {
+			workload.Class = WorkloadClassInference
+		} else {
+			workload.Class = WorkloadClassTraining
+		}
+		if err := m.scheduler.SubmitWorkload(workload); err != nil {
+			t.Fatalf("Submit %s failed: %v", workload.ID, err)
+		}
+		m.workloads[workload.ID] = workload
+		return "submit " + workload.ID
+	case op < 7:
+		if err := m.scheduler.Schedule(); err != nil {
+			t.Fatalf("Schedule failed: %v", err)
+		}
+		m.checkWorkConserving(t)
+		return "schedule"
+	case op < 9:
+		running := m.running()
+		if len(running) == 0 {
+			return "noop"
+		}
+		id := running[rng.Intn(len(running))]
+		status, finish := WorkloadCompleted, m.scheduler.CompleteWorkload
+		if rng.Intn(3) == 0 {
+			status, finish = WorkloadFailed, m.scheduler.FailWorkload
+		}
+		if err := finish(id); err != nil {
+			t.Fatalf("Finishing %s failed: %v", id, err)
+		}
+		m.finished[id] = status
+		return fmt.Sprintf("%s %s", status, id)
+	default:
+		gpuIDs := make([]string, 0, len(m.scheduler.gpus))
+		for id := range m.scheduler.gpus {
+			gpuIDs = append(gpuIDs, id)
+		}
+		id := gpuIDs[rng.Intn(len(gpuIDs))]
+		m.scheduler.UpdateGPUUtilization(id, rng.Float64()*100)
+		return "utilization " + id
+	}
+}
+
+// checkInvariants verifies memory accounting and that every workload is in exactly one place
+func (m *schedulerModel) checkInvariants(t *testing.T, history []string) {
+	t.Helper()
+	placed := make(map[string]string)
+
+	for _, gpu := range m.scheduler.gpus {
+		var allocated uint64
+		for _, workload := range []*Workload{gpu.CurrentWorkload, gpu.ColocatedWorkload} {
+			if workload == nil {
+				continue
+			}
+			if other, exists := placed[workload.ID]; exists {
+				t.Fatalf("Workload %s placed on both %s and %s after %v", workload.ID, other, gpu.ID, history)
+			}
+			placed[workload.ID] = gpu.ID
+			allocated += workload.MemoryRequired
+
+			if workload.AssignedGPU != gpu.ID {
+				t.Fatalf("Workload %s on %s reports GPU %q after %v", workload.ID, gpu.ID, workload.AssignedGPU, history)
+			}
+			if workload.Status != WorkloadRunning && workload.Status != WorkloadPaused {
+				t.Fatalf("Placed workload %s has status %s after %v", workload.ID, workload.Status, history)
+			}
+		}
+		if gpu.ColocatedWorkload != nil && gpu.CurrentWorkload == nil {
+			t.Fatalf("GPU %s has a co-located workload but no primary after %v", gpu.ID, history)
+		}
+		if gpu.MemoryUsed != allocated {
+			t.Fatalf("GPU %s reports %d MB used but %d MB is allocated after %v", gpu.ID, gpu.MemoryUsed, allocated, history)
+		}
+		if gpu.MemoryUsed > gpu.MemoryTotal {
+			t.Fatalf("GPU %s over-committed: %d/%d MB after %v", gpu.ID, gpu.MemoryUsed, gpu.MemoryTotal, history)
+		}
+	}
+
+	queued := make(map[string]bool)
+	for _, workload := range m.scheduler.workloadQueue {
+		if queued[workload.ID] {
+			t.Fatalf("Workload %s queued twice after %v", workload.ID, history)
+		}
+		queued[workload.ID] = true
+		if _, exists := placed[workload.ID]; exists {
+			t.Fatalf("Workload %s both queued and placed after %v", workload.ID, history)
+		}
+		if workload.Status != WorkloadPending {
+			t.Fatalf("Queued workload %s has status %s after %v", workload.ID, workload.Status, history)
+		}
+	}
+
+	for id, workload := range m.workloads {
+		_, isPlaced := placed[id]
+		status, isFinished := m.finished[id]
+		switch {
+		case isFinished:
+			if isPlaced || queued[id] || workload.Status != status || workload.CompletedAt == nil {
+				t.Fatalf("Finished workload %s still tracked or has status %s after %v", id, workload.Status, history)
+			}
+		case !isPlaced && !queued[id]:
+			t.Fatalf("Workload %s was lost after %v", id, history)
+		}
+	}
+}
+
+// checkWorkConserving verifies that after scheduling no queued workload fits an idle GPU
+func (m *schedulerModel) checkWorkConserving(t *testing.T) {
+	t.Helper()
+	for _, workload := range m.scheduler.workloadQueue {
+		for _, gpu := range m.scheduler.gpus {
+			if m.scheduler.canAssign(gpu, workload) {
+				t.Fatalf("Workload %s (%d MB) left queued although %s is idle with %d MB free",
+					workload.ID, workload.MemoryRequired, gpu.ID, freeMemory(gpu))
+			}
+		}
+	}
+}
+
+func TestSchedulerInvariantsUnderRandomInterleavings(t *testing.T) {
+	seeds := 100
+	if testing.Short() {
+		seeds = 10
+	}
+
+	for _, strategy := range allStrategies {
+		for _, colocation := range []bool{false, true} {
+			for seed := int64(1); seed <= int64(seeds); seed++ {
+				rng := rand.New(rand.NewSource(seed))
+				model := newModelScheduler(strategy, colocation, rng)
+				history := make([]string, 0, 200)
+				for i := 0; i < 200; i++ {
+					history = append(history, model.step(t, rng))
+					model.checkInvariants(t, history)
+				}
+			}
+		}
+	}
+}
+
+// FuzzSchedulerInterleavings replays submit, schedule, complete, fail and
+// utilization sequences decoded from the input: the first byte picks the
+// strategy and co-location, the next the GPUs, and the rest the operations
+func FuzzSchedulerInterleavings(f *testing.F) {
+	f.Add([]byte{0})
+	f.Add([]byte{0x81, 2, 40, 1, 60, 1, 0, 10, 4, 5, 7, 0, 4, 1, 7, 1, 2, 8, 9, 3})
+	f.Add([]byte{3, 5, 72, 3, 10, 0, 30, 1, 0, 0, 3, 1, 2, 0, 5, 4, 7, 0, 8, 2, 0, 9, 1, 200})
+	f.Add([]byte{9, 0, 0, 0, 0, 2, 47, 0, 0, 2, 47, 1, 4, 7, 0, 4, 8, 0, 4})
+	f.Add([]byte{0x84, 1, 0, 7, 0, 3, 3, 1, 3, 3, 1, 5, 6, 8, 1, 3, 8, 0, 0, 9, 0, 255})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		rng := &byteChoices{data: data}
+		setup := rng.next()
+		strategy := allStrategies[int(setup)%len(allStrategies)]
+		model := newModelScheduler(strategy, setup&0x80 != 0, rng)
+
+		history := make([]string, 0, 64)
+		for len(rng.data) > 0 && len(history) < 500 {
+			history = append(history, model.step(t, rng))
+			model.checkInvariants(t, history)
+		}
+	})
+}
+
+func TestStrategiesPlaceOnlyOnFittingIdleGPUs(t *testing.T) {
+	for _, strategy := range allStrategies {
+		scheduler := NewScheduler(strategy)
+		scheduler.RegisterGPU(&GPU{ID: "offline", MemoryTotal: 80000, Available: false})
+		scheduler.RegisterGPU(&GPU{ID: "small", MemoryTotal: 8000, Available: true})
+		scheduler.RegisterGPU(&GPU{
+			ID:              "busy",
+			MemoryTotal:     80000,
+			MemoryUsed:      1000,
+			Available:       true,
+			CurrentWorkload: &Workload{ID: "existing", MemoryRequired: 1000, Status: WorkloadRunning, AssignedGPU: "busy"},
+		})
+
+		scheduler.SubmitWorkload(&Workload{ID: "large", MemoryRequired: 16000})
+		if err := scheduler.Schedule(); err != nil {
+			t.Fatalf("%s: Schedule failed: %v", strategy, err)
+		}
+		for _, gpu := range scheduler.gpus {
+			if gpu.CurrentWorkload != nil && gpu.CurrentWorkload.ID == "large" {
+				t.Errorf("%s: large workload placed on %s, which is offline, busy or too small", strategy, gpu.ID)
+			}
+		}
+		if len(scheduler.workloadQueue) != 1 {
+			t.Errorf("%s: expected the large workload to stay queued, got %d queued", strategy, len(scheduler.workloadQueue))
+		}
+	}
+}
+
+func TestBestFitPrefersTightestIdleGPU(t *testing.T) {
+	scheduler := NewScheduler(StrategyBestFit)
+	scheduler.RegisterGPU(&GPU{ID: "roomy", MemoryTotal: 80000, Available: true})
+	scheduler.RegisterGPU(&GPU{ID: "tight", MemoryTotal: 24000, Available: true})
+	scheduler.RegisterGPU(&GPU{ID: "tighter-but-busy", MemoryTotal: 20000, Available: true})
+	scheduler.gpus["tighter-but-busy"].CurrentWorkload = &Workload{ID: "existing", Status: WorkloadRunning, AssignedGPU: "tighter-but-busy"}
+
+	first := &Workload{ID: "first", MemoryRequired: 16000}
+	second := &Workload{ID: "second", MemoryRequired: 16000}
+	scheduler.SubmitWorkload(first)
+	scheduler.SubmitWorkload(second)
+	scheduler.Schedule()
+
+	if first.AssignedGPU != "tight" || second.AssignedGPU != "roomy" {
+		t.Errorf("Expected first on tight and second on roomy, got %q and %q", first.AssignedGPU, second.AssignedGPU)
+	}
+	if scheduler.gpus["tighter-but-busy"].CurrentWorkload.ID != "existing" {
+		t.Error("Best fit must not replace a running workload")
+	}
+}
+
+func TestRoundRobinSpreadsAcrossGPUs(t *testing.T) {
+	scheduler := NewScheduler(StrategyRoundRobin)
+	for i := 0; i < 4; i++ {
+		scheduler.RegisterGPU(&GPU{ID: fmt.Sprintf("gpu-%d", i), MemoryTotal: 16000, Available: true})
+	}
+	for i := 0; i < 6; i++ {
+		scheduler.SubmitWorkload(&Workload{ID: fmt.Sprintf("w-%d", i), MemoryRequired: 4000})
+	}
+	scheduler.Schedule()
+
+	used := make(map[string]bool)
+	for _, gpu := range scheduler.gpus {
+		if gpu.CurrentWorkload != nil {
+			used[gpu.ID] = true
+		}
+	}
+	if len(used) != 4 || len(scheduler.workloadQueue) != 2 {
+		t.Errorf("Expected all 4 GPUs used and 2 workloads queued, got %d used and %d queued", len(used), len(scheduler.workloadQueue))
+	}
+}
+
+func TestPriorityStrategyPicksHighestFittingPriority(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	for trial := 0; trial < 200; trial++ {
+		scheduler := NewScheduler(StrategyPriority)
+		scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+
+		best := -1
+		for i := 0; i < 1+rng.Intn(8); i++ {
+			workload := &Workload{ID: fmt.Sprintf("w-%d", i), Priority: rng.Intn(10), MemoryRequired: uint64(1+rng.Intn(24)) * 1000}
+			scheduler.SubmitWorkload(workload)
+			if workload.MemoryRequired <= 16000 && workload.Priority > best {
+				best = workload.Priority
+			}
+		}
+		scheduler.Schedule()
+
+		current := scheduler.gpus["gpu-0"].CurrentWorkload
+		if best < 0 {
+			if current != nil {
+				t.Fatalf("Trial %d: nothing fits but %s was placed", trial, current.ID)
+			}
+			continue
+		}
+		if current == nil || current.Priority != best {
+			t.Fatalf("Trial %d: expected a priority %d workload placed, got %+v", trial, best, current)
+		}
+	}
+}
+
+func TestFailWorkloadFreesGPUWithoutLearning(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+	workload := &Workload{ID: "crashy", Name: "crashy", MemoryRequired: 8000}
+	scheduler.SubmitWorkload(workload)
+	scheduler.Schedule()
+
+	if err := scheduler.FailWorkload("crashy"); err != nil {
+		t.Fatalf("FailWorkload failed: %v", err)
+	}
+	if workload.Status != WorkloadFailed || scheduler.gpus["gpu-0"].MemoryUsed != 0 {
+		t.Errorf("Expected a failed workload and freed memory, got %s with %d MB used", workload.Status, scheduler.gpus["gpu-0"].MemoryUsed)
+	}
+	if _, exists := scheduler.GetProfile("crashy"); exists {
+		t.Error("Expected failed runs not to be learned")
+	}
+	if err := scheduler.FailWorkload("crashy"); err == nil {
+		t.Error("Expected failing a finished workload to return an error")
+	}
+}
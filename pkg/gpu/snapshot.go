@@ -0,0 +1,96 @@
+package gpu
+
+// SchedulerSnapshot is a serializable copy of the scheduler's state
+type SchedulerSnapshot struct {
+	Strategy SchedulingStrategy `json:"strategy"`
+	GPUs     []GPU              `json:"gpus"`
+	Queue    []Workload         `json:"queue"`
+	Profiles []WorkloadProfile  `json:"profiles,omitempty"`
+}
+
+// Snapshot returns a copy of the registered GPUs (with their running
+// workloads), the queued workloads and the learned workload profiles
+func (s *Scheduler) Snapshot() SchedulerSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot := SchedulerSnapshot{
+		Strategy: s.strategy,
+		GPUs:     make([]GPU, 0, len(s.gpus)),
+		Queue:    make([]Workload, 0, len(s.workloadQueue)),
+		Profiles: make([]WorkloadProfile, 0, len(s.profiles)),
+	}
+
+	for _, gpu := range s.gpus {
+		copied := *gpu
+		copied.MetricsHistory = append([]GPUMetrics(nil), gpu.MetricsHistory...)
+		copied.CurrentWorkload = copyWorkload(gpu.CurrentWorkload)
+		copied.ColocatedWorkload = copyWorkload(gpu.ColocatedWorkload)
+		snapshot.GPUs = append(snapshot.GPUs, copied)
+	}
+
+	for _, workload := range s.workloadQueue {
+		snapshot.Queue = append(snapshot.Queue, *workload)
+	}
+
+	for _, profile := range s.profiles {
+		snapshot.Profiles = append(snapshot.Profiles, *profile)
+	}
+
+	return snapshot
+}
+
+// Restore replaces the scheduler's GPUs and queue with a snapshot's contents.
+// Learned profiles are replaced only when the snapshot carries any. The
+// scheduling strategy and configuration are left unchanged.
+func (s *Scheduler) Restore(snapshot SchedulerSnapshot) {
+	gpus := make(map[string]*GPU, len(snapshot.GPUs))
+	for i := range snapshot.GPUs {
+		gpu := snapshot.GPUs[i]
+		gpus[gpu.ID] = &gpu
+	}
+
+	queue := make([]*Workload, 0, len(snapshot.Queue))
+	for i := range snapshot.Queue {
+		workload := snapshot.Queue[i]
+		queue = append(queue, &workload)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gpus = gpus
+	s.workloadQueue = queue
+	s.examined = 0
+	s.submissions = make(map[string]*submission)
+	s.submissionOrder = nil
+	for _, gpu := range gpus {
+		for _, workload := range []*Workload{gpu.CurrentWorkload, gpu.ColocatedWorkload} {
+			if workload != nil {
+				s.rememberSubmission(workload)
+			}
+		}
+	}
+	for _, workload := range queue {
+		s.rememberSubmission(workload)
+	}
+	if s.wal != nil {
+		s.walErr = s.compactWAL()
+	}
+	if len(snapshot.Profiles) > 0 {
+		profiles := make(map[string]*WorkloadProfile, len(snapshot.Profiles))
+		for i := range snapshot.Profiles {
+			profile := snapshot.Profiles[i]
+			profiles[profile.Name] = &profile
+		}
+		s.profiles = profiles
+	}
+}
+
+// copyWorkload returns a copy of a workload, or nil
+func copyWorkload(workload *Workload) *Workload {
+	if workload == nil {
+		return nil
+	}
+	copied := *workload
+	return &copied
+}
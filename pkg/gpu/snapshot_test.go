@@ -0,0 +1,54 @@
+package gpu
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSchedulerSnapshotRestore(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+	scheduler.RegisterGPU(&GPU{ID: "gpu-1", MemoryTotal: 16000, Available: true})
+	scheduler.SubmitWorkload(&Workload{ID: "running", MemoryRequired: 8000})
+	if err := scheduler.Schedule(); err != nil {
+		t.Fatalf("Failed to schedule: %v", err)
+	}
+	scheduler.SubmitWorkload(&Workload{ID: "queued", MemoryRequired: 32000})
+
+	data, err := json.Marshal(scheduler.Snapshot())
+	if err != nil {
+		t.Fatalf("Failed to encode snapshot: %v", err)
+	}
+	var snapshot SchedulerSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatalf("Failed to decode snapshot: %v", err)
+	}
+
+	restored := NewScheduler(StrategyLeastUtilized)
+	restored.Restore(snapshot)
+
+	status := restored.GetGPUStatus()
+	if len(status) != 2 {
+		t.Fatalf("Expected 2 GPUs restored, got %d", len(status))
+	}
+	running := 0
+	for _, gpu := range status {
+		if gpu.CurrentWorkload != nil && gpu.CurrentWorkload.ID == "running" {
+			running++
+		}
+	}
+	if running != 1 {
+		t.Errorf("Expected the running workload to stay assigned, found it on %d GPUs", running)
+	}
+
+	metrics := restored.GetUtilizationMetrics()
+	if metrics["pending_workloads"] != 1 {
+		t.Errorf("Expected 1 queued workload restored, got %v", metrics["pending_workloads"])
+	}
+
+	// The snapshot must not alias the live scheduler's state
+	scheduler.SubmitWorkload(&Workload{ID: "later", MemoryRequired: 1000})
+	if len(snapshot.Queue) != 1 {
+		t.Errorf("Expected snapshot to be independent of the scheduler, got %d queued", len(snapshot.Queue))
+	}
+}
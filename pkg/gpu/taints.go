@@ -0,0 +1,205 @@
+package gpu
+
+import (
+	"fmt"
+	"time"
+)
+
+// TaintEffect describes what a taint does to workloads that do not tolerate it
+type TaintEffect string
+
+const (
+	// TaintNoSchedule keeps new workloads off the GPU
+	TaintNoSchedule TaintEffect = "NoSchedule"
+	// TaintPreferNoSchedule places workloads elsewhere when another GPU fits
+	TaintPreferNoSchedule TaintEffect = "PreferNoSchedule"
+	// TaintNoExecute keeps new workloads off and evicts running ones back to the queue
+	TaintNoExecute TaintEffect = "NoExecute"
+)
+
+// Taint repels workloads from a GPU, e.g. maintenance or flaky-ecc
+type Taint struct {
+	Key    string      `yaml:"key" json:"key"`
+	Value  string      `yaml:"value" json:"value,omitempty"`
+	Effect TaintEffect `yaml:"effect" json:"effect"`
+}
+
+// TolerationOperator selects how a toleration matches a taint's value
+type TolerationOperator string
+
+const (
+	TolerationEqual  TolerationOperator = "Equal"
+	TolerationExists TolerationOperator = "Exists"
+)
+
+// Toleration lets a workload run on GPUs carrying matching taints. An empty
+// Key with the Exists operator tolerates every taint, and an empty Effect
+// matches every effect.
+type Toleration struct {
+	Key      string             `yaml:"key" json:"key,omitempty"`
+	Operator TolerationOperator `yaml:"operator" json:"operator,omitempty"` // Equal when empty
+	Value    string             `yaml:"value" json:"value,omitempty"`
+	Effect   TaintEffect        `yaml:"effect" json:"effect,omitempty"`
+}
+
+// Tolerates reports whether the toleration matches a taint
+func (t Toleration) Tolerates(taint Taint) bool {
+	if t.Effect != "" && t.Effect != taint.Effect {
+		return false
+	}
+	if t.Operator == TolerationExists {
+		return t.Key == "" || t.Key == taint.Key
+	}
+	return t.Key == taint.Key && t.Value == taint.Value
+}
+
+// toleratesTaints reports whether a workload tolerates every taint on a GPU
+// with one of the given effects
+func toleratesTaints(gpu *GPU, workload *Workload, effects ...TaintEffect) bool {
+	for _, taint := range gpu.Taints {
+		if !hasEffect(taint.Effect, effects) {
+			continue
+		}
+		tolerated := false
+		for _, toleration := range workload.Tolerations {
+			if toleration.Tolerates(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// hasEffect reports whether effect is one of effects
+func hasEffect(effect TaintEffect, effects []TaintEffect) bool {
+	for _, candidate := range effects {
+		if effect == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// schedulable reports whether a GPU's taints allow placing a workload on it
+func schedulable(gpu *GPU, workload *Workload) bool {
+	return toleratesTaints(gpu, workload, TaintNoSchedule, TaintNoExecute)
+}
+
+// avoids reports whether a workload should go elsewhere when another GPU fits
+func avoids(gpu *GPU, workload *Workload) bool {
+	return !toleratesTaints(gpu, workload, TaintPreferNoSchedule)
+}
+
+// TaintGPU adds a taint to a GPU, replacing any taint with the same key and
+// effect. A NoExecute taint evicts running workloads that do not tolerate it
+// back to the queue.
+func (s *Scheduler) TaintGPU(gpuID string, taint Taint) error {
+	if taint.Key == "" {
+		return fmt.Errorf("taint key cannot be empty")
+	}
+	switch taint.Effect {
+	case TaintNoSchedule, TaintPreferNoSchedule, TaintNoExecute:
+	default:
+		return fmt.Errorf("unknown taint effect %q", taint.Effect)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	gpu, exists := s.gpus[gpuID]
+	if !exists {
+		return fmt.Errorf("GPU %s not found", gpuID)
+	}
+
+	taints := make([]Taint, 0, len(gpu.Taints)+1)
+	for _, existing := range gpu.Taints {
+		if existing.Key != taint.Key || existing.Effect != taint.Effect {
+			taints = append(taints, existing)
+		}
+	}
+	gpu.Taints = append(taints, taint)
+
+	if taint.Effect == TaintNoExecute {
+		s.evictIntolerant(gpu)
+	}
+	return nil
+}
+
+// UntaintGPU removes every taint with the given key from a GPU
+func (s *Scheduler) UntaintGPU(gpuID, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	gpu, exists := s.gpus[gpuID]
+	if !exists {
+		return fmt.Errorf("GPU %s not found", gpuID)
+	}
+
+	taints := make([]Taint, 0, len(gpu.Taints))
+	for _, existing := range gpu.Taints {
+		if existing.Key != key {
+			taints = append(taints, existing)
+		}
+	}
+	if len(taints) == len(gpu.Taints) {
+		return nil
+	}
+	gpu.Taints = taints
+	s.examined = 0
+	s.wake()
+	return nil
+}
+
+// evictIntolerant returns workloads that do not tolerate a GPU's NoExecute
+// taints to the queue. A tolerant co-located job takes over the GPU when the
+// primary workload is evicted. Callers must hold the lock.
+func (s *Scheduler) evictIntolerant(gpu *GPU) {
+	if colocated := gpu.ColocatedWorkload; colocated != nil && !toleratesTaints(gpu, colocated, TaintNoExecute) {
+		s.recordEviction(gpu, colocated)
+		s.preemptColocated(gpu, noExecuteReason)
+	}
+
+	current := gpu.CurrentWorkload
+	if current == nil || toleratesTaints(gpu, current, TaintNoExecute) {
+		return
+	}
+	s.recordEviction(gpu, current)
+	s.recordUsage(gpu, current, time.Now(), WorkloadPending)
+	gpu.CurrentWorkload = nil
+	releaseMemory(gpu, current.MemoryRequired)
+	current.Status = WorkloadPending
+	current.AssignedGPU = ""
+	current.QueuedAt = time.Now()
+	current.StartedAt = nil
+	current.Preemptions++
+	s.workloadQueue = append(s.workloadQueue, current)
+	s.logWorkload(walPreempted, current, false)
+	s.emitLifecycle(LifecyclePreempted, gpu, current, false, noExecuteReason)
+
+	if promoted := gpu.ColocatedWorkload; promoted != nil {
+		promoted.Status = WorkloadRunning
+		promoted.PausedAt = nil
+		gpu.CurrentWorkload = promoted
+		gpu.ColocatedWorkload = nil
+		s.logWorkload(walPromoted, promoted, false)
+	}
+	s.wake()
+}
+
+// noExecuteReason explains evictions for NoExecute taints
+const noExecuteReason = "GPU has a NoExecute taint the workload does not tolerate"
+
+// recordEviction records a workload leaving a GPU because of a NoExecute taint; callers must hold the lock
+func (s *Scheduler) recordEviction(gpu *GPU, workload *Workload) {
+	s.recordDecision(SchedulingDecision{
+		WorkloadID: workload.ID,
+		GPUID:      gpu.ID,
+		Action:     "evicted",
+		Reason:     noExecuteReason,
+		Timestamp:  time.Now(),
+	})
+}
@@ -0,0 +1,119 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaintsRepelWorkloadsWithoutTolerations(t *testing.T) {
+	for _, strategy := range allStrategies {
+		scheduler := NewScheduler(strategy)
+		scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true,
+			Taints: []Taint{{Key: "maintenance", Effect: TaintNoSchedule}}})
+		scheduler.RegisterGPU(&GPU{ID: "gpu-1", MemoryTotal: 16000, Available: true,
+			Taints: []Taint{{Key: "flaky-ecc", Value: "true", Effect: TaintNoSchedule}}})
+
+		plain := &Workload{ID: "plain", MemoryRequired: 8000}
+		tolerant := &Workload{ID: "tolerant", MemoryRequired: 8000,
+			Tolerations: []Toleration{{Key: "flaky-ecc", Value: "true"}}}
+		scheduler.SubmitWorkload(plain)
+		scheduler.SubmitWorkload(tolerant)
+		scheduler.Schedule()
+
+		if plain.Status != WorkloadPending {
+			t.Errorf("%s: expected the workload without tolerations to wait, got it on %q", strategy, plain.AssignedGPU)
+		}
+		if tolerant.AssignedGPU != "gpu-1" {
+			t.Errorf("%s: expected the tolerant workload on gpu-1, got %q", strategy, tolerant.AssignedGPU)
+		}
+
+		if err := scheduler.UntaintGPU("gpu-0", "maintenance"); err != nil {
+			t.Fatalf("UntaintGPU failed: %v", err)
+		}
+		scheduler.Schedule()
+		if plain.AssignedGPU != "gpu-0" {
+			t.Errorf("%s: expected the workload placed once maintenance ended, got %q", strategy, plain.AssignedGPU)
+		}
+	}
+}
+
+func TestPreferNoScheduleTaintIsAvoidedWhenPossible(t *testing.T) {
+	for _, strategy := range []SchedulingStrategy{StrategyLeastUtilized, StrategyBestFit, StrategyPriority} {
+		scheduler := NewScheduler(strategy)
+		// The tainted GPU is both the least utilized and the tightest fit
+		scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 8000, Available: true, Utilization: 5,
+			Taints: []Taint{{Key: "flaky-ecc", Effect: TaintPreferNoSchedule}}})
+		scheduler.RegisterGPU(&GPU{ID: "gpu-1", MemoryTotal: 16000, Available: true, Utilization: 50})
+
+		first := &Workload{ID: "first", MemoryRequired: 8000}
+		second := &Workload{ID: "second", MemoryRequired: 8000}
+		scheduler.SubmitWorkload(first)
+		scheduler.SubmitWorkload(second)
+		scheduler.Schedule()
+
+		if first.AssignedGPU != "gpu-1" || second.AssignedGPU != "gpu-0" {
+			t.Errorf("%s: expected the tainted GPU used only once the other was busy, got %q and %q",
+				strategy, first.AssignedGPU, second.AssignedGPU)
+		}
+	}
+}
+
+func TestNoExecuteTaintEvictsIntolerantWorkloads(t *testing.T) {
+	config := DefaultSchedulerConfig()
+	config.Colocation.Enabled = true
+	scheduler := NewSchedulerWithConfig(StrategyLeastUtilized, config)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+
+	serve := &Workload{ID: "serve", Class: WorkloadClassInference, MemoryRequired: 8000}
+	train := &Workload{ID: "train", Class: WorkloadClassTraining, MemoryRequired: 4000,
+		Tolerations: []Toleration{{Key: "maintenance", Operator: TolerationExists}}}
+	scheduler.SubmitWorkload(serve)
+	scheduler.Schedule()
+	scheduler.SubmitWorkload(train)
+	scheduler.Schedule()
+	if train.AssignedGPU != "gpu-0" {
+		t.Fatalf("Expected the training job co-located, got %q", train.AssignedGPU)
+	}
+
+	if err := scheduler.TaintGPU("gpu-0", Taint{Key: "maintenance", Effect: TaintNoExecute}); err != nil {
+		t.Fatalf("TaintGPU failed: %v", err)
+	}
+	if serve.Status != WorkloadPending || serve.Preemptions != 1 {
+		t.Errorf("Expected the intolerant workload evicted to the queue, got %s", serve.Status)
+	}
+	gpu := scheduler.gpus["gpu-0"]
+	if gpu.CurrentWorkload != train || gpu.ColocatedWorkload != nil || gpu.MemoryUsed != 4000 {
+		t.Errorf("Expected the tolerant training job to take over the GPU, got %+v", gpu)
+	}
+
+	decisions := scheduler.GetDecisions(time.Time{})
+	if last := decisions[len(decisions)-1]; last.Action != "evicted" || last.WorkloadID != "serve" {
+		t.Errorf("Expected the eviction recorded, got %+v", last)
+	}
+
+	for _, invalid := range []Taint{{Effect: TaintNoSchedule}, {Key: "x", Effect: "Sometimes"}} {
+		if err := scheduler.TaintGPU("gpu-0", invalid); err == nil {
+			t.Errorf("Expected %+v to be rejected", invalid)
+		}
+	}
+}
+
+func TestTolerationMatching(t *testing.T) {
+	taint := Taint{Key: "flaky-ecc", Value: "true", Effect: TaintNoSchedule}
+	cases := []struct {
+		toleration Toleration
+		expected   bool
+	}{
+		{Toleration{Key: "flaky-ecc", Value: "true"}, true},
+		{Toleration{Key: "flaky-ecc", Value: "false"}, false},
+		{Toleration{Key: "flaky-ecc", Operator: TolerationExists}, true},
+		{Toleration{Operator: TolerationExists}, true},
+		{Toleration{Key: "flaky-ecc", Value: "true", Effect: TaintNoExecute}, false},
+		{Toleration{Key: "maintenance", Operator: TolerationExists}, false},
+	}
+	for _, c := range cases {
+		if got := c.toleration.Tolerates(taint); got != c.expected {
+			t.Errorf("%+v: expected %v, got %v", c.toleration, c.expected, got)
+		}
+	}
+}
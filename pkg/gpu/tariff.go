@@ -0,0 +1,220 @@
+package gpu
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeWindow is a daily clock-time range such as 22:00-06:00. End is
+// exclusive; an End before Start wraps past midnight and equal times cover
+// the whole day.
+type TimeWindow struct {
+	Start string `yaml:"start" json:"start"` // HH:MM
+	End   string `yaml:"end" json:"end"`     // HH:MM
+}
+
+// TariffPeriod prices electricity during a daily time window
+type TariffPeriod struct {
+	TimeWindow  `yaml:",inline"`
+	PricePerKWh float64 `yaml:"price_per_kwh" json:"price_per_kwh"`
+}
+
+// TariffConfig describes electricity prices and how long deferrable
+// workloads may wait for cheaper power
+type TariffConfig struct {
+	Enabled      bool           `yaml:"enabled" json:"enabled"`
+	Currency     string         `yaml:"currency" json:"currency"`
+	DefaultPrice float64        `yaml:"default_price" json:"default_price"` // Per kWh outside every period
+	Periods      []TariffPeriod `yaml:"periods" json:"periods"`             // First matching period wins
+	MaxDeferral  time.Duration  `yaml:"max_deferral" json:"max_deferral"`   // Longest a deferrable workload waits after submission
+}
+
+// DefaultTariffConfig returns a flat tariff with deferral disabled
+func DefaultTariffConfig() TariffConfig {
+	return TariffConfig{
+		Enabled:      false,
+		Currency:     "USD",
+		DefaultPrice: 0.15,
+		MaxDeferral:  12 * time.Hour,
+	}
+}
+
+// TariffReport shows what deferring workloads to cheaper electricity saved,
+// pricing each finished deferrable workload's energy at its actual start and
+// at its submission time
+type TariffReport struct {
+	Currency       string        `json:"currency"`
+	Workloads      int           `json:"workloads"`
+	Deferred       int           `json:"deferred"` // Workloads that waited for cheaper power or a window
+	EnergyKWh      float64       `json:"energy_kwh"`
+	ImmediateCost  float64       `json:"immediate_cost"` // Had each workload started when submitted
+	ActualCost     float64       `json:"actual_cost"`
+	Savings        float64       `json:"savings"`
+	SavingsPercent float64       `json:"savings_percent"`
+	AverageDelay   time.Duration `json:"average_delay"`
+	totalDelay     time.Duration
+}
+
+// parseClock returns the minutes after midnight of an HH:MM time
+func parseClock(clock string) (int, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid clock time %q, expected HH:MM", clock)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// Validate reports a window with malformed clock times
+func (w TimeWindow) Validate() error {
+	if _, err := parseClock(w.Start); err != nil {
+		return err
+	}
+	_, err := parseClock(w.End)
+	return err
+}
+
+// Contains reports whether a time's clock time falls in the window; malformed windows contain nothing
+func (w TimeWindow) Contains(t time.Time) bool {
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	switch {
+	case start == end:
+		return true
+	case start < end:
+		return minute >= start && minute < end
+	default:
+		return minute >= start || minute < end
+	}
+}
+
+// Price returns the electricity price per kWh at a time
+func (c TariffConfig) Price(t time.Time) float64 {
+	for _, period := range c.Periods {
+		if period.Contains(t) {
+			return period.PricePerKWh
+		}
+	}
+	return c.DefaultPrice
+}
+
+// nextBoundaries returns the next time after t that each period starts or ends
+func (c TariffConfig) nextBoundaries(t time.Time) []time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	boundaries := make([]time.Time, 0, 2*len(c.Periods))
+	for _, period := range c.Periods {
+		for _, clock := range []string{period.Start, period.End} {
+			minute, err := parseClock(clock)
+			if err != nil {
+				continue
+			}
+			boundary := midnight.Add(time.Duration(minute) * time.Minute)
+			if !boundary.After(t) {
+				boundary = boundary.Add(24 * time.Hour)
+			}
+			boundaries = append(boundaries, boundary)
+		}
+	}
+	return boundaries
+}
+
+// cheaperBefore reports whether electricity gets cheaper than it is at now before the deadline
+func (c TariffConfig) cheaperBefore(now, deadline time.Time) bool {
+	current := c.Price(now)
+	for _, boundary := range c.nextBoundaries(now) {
+		if boundary.Before(deadline) && c.Price(boundary) < current {
+			return true
+		}
+	}
+	return false
+}
+
+// Cost prices the energy of drawing watts for a duration from start
+func (c TariffConfig) Cost(start time.Time, duration time.Duration, watts float64) float64 {
+	cost := 0.0
+	end := start.Add(duration)
+	for at := start; at.Before(end); {
+		next := end
+		for _, boundary := range c.nextBoundaries(at) {
+			if boundary.Before(next) {
+				next = boundary
+			}
+		}
+		cost += c.Price(at) * watts * next.Sub(at).Hours() / 1000
+		at = next
+	}
+	return cost
+}
+
+// held reports whether a queued workload must wait: outside all of its start
+// windows, or deferrable while cheaper electricity arrives within its
+// deferral limit. Callers must hold the lock.
+func (s *Scheduler) held(workload *Workload, now time.Time) bool {
+	if len(workload.Windows) > 0 {
+		open := false
+		for _, window := range workload.Windows {
+			if window.Contains(now) {
+				open = true
+				break
+			}
+		}
+		if !open {
+			return true
+		}
+	}
+
+	tariff := s.config.Tariff
+	if !tariff.Enabled || !workload.Deferrable {
+		return false
+	}
+	return tariff.cheaperBefore(now, workload.SubmittedAt.Add(tariff.MaxDeferral))
+}
+
+// recordTariffSavings prices a finished deferrable workload's energy at its
+// start and at its submission; callers must hold the lock
+func (s *Scheduler) recordTariffSavings(gpu *GPU, workload *Workload, completedAt time.Time) {
+	tariff := s.config.Tariff
+	if !tariff.Enabled || workload.StartedAt == nil || (!workload.Deferrable && len(workload.Windows) == 0) {
+		return
+	}
+
+	watts := gpu.PowerLimit
+	if watts <= 0 {
+		watts = s.config.Energy.DefaultGPUWatts
+	}
+	duration := completedAt.Sub(*workload.StartedAt)
+	delay := workload.StartedAt.Sub(workload.SubmittedAt)
+
+	report := &s.tariffReport
+	report.Workloads++
+	if delay >= time.Minute {
+		report.Deferred++
+	}
+	report.totalDelay += delay
+	report.EnergyKWh += watts * duration.Hours() / 1000
+	report.ImmediateCost += tariff.Cost(workload.SubmittedAt, duration, watts)
+	report.ActualCost += tariff.Cost(*workload.StartedAt, duration, watts)
+}
+
+// GetTariffReport returns the realized savings of deferring workloads to cheaper electricity
+func (s *Scheduler) GetTariffReport() TariffReport {
+	s.mu.RLock()
+	report := s.tariffReport
+	report.Currency = s.config.Tariff.Currency
+	s.mu.RUnlock()
+
+	report.Savings = report.ImmediateCost - report.ActualCost
+	if report.ImmediateCost > 0 {
+		report.SavingsPercent = report.Savings / report.ImmediateCost * 100
+	}
+	if report.Workloads > 0 {
+		report.AverageDelay = report.totalDelay / time.Duration(report.Workloads)
+	}
+	return report
+}
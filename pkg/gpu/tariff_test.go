@@ -0,0 +1,138 @@
+package gpu
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// offPeakTariff charges 0.30 from 07:00 to 23:00 and 0.10 overnight
+func offPeakTariff() TariffConfig {
+	tariff := DefaultTariffConfig()
+	tariff.Enabled = true
+	tariff.DefaultPrice = 0.10
+	tariff.Periods = []TariffPeriod{{TimeWindow: TimeWindow{Start: "07:00", End: "23:00"}, PricePerKWh: 0.30}}
+	return tariff
+}
+
+func at(hour, minute int) time.Time {
+	return time.Date(2024, 3, 4, hour, minute, 0, 0, time.UTC)
+}
+
+func TestTimeWindowContains(t *testing.T) {
+	overnight := TimeWindow{Start: "22:00", End: "06:00"}
+	daytime := TimeWindow{Start: "09:00", End: "17:30"}
+
+	for _, tc := range []struct {
+		window   TimeWindow
+		time     time.Time
+		contains bool
+	}{
+		{overnight, at(23, 0), true},
+		{overnight, at(5, 59), true},
+		{overnight, at(6, 0), false},
+		{overnight, at(12, 0), false},
+		{daytime, at(9, 0), true},
+		{daytime, at(17, 30), false},
+		{TimeWindow{Start: "00:00", End: "00:00"}, at(3, 0), true},
+		{TimeWindow{Start: "25:00", End: "06:00"}, at(3, 0), false},
+	} {
+		if got := tc.window.Contains(tc.time); got != tc.contains {
+			t.Errorf("%v contains %s: expected %v, got %v", tc.window, tc.time.Format("15:04"), tc.contains, got)
+		}
+	}
+}
+
+func TestTariffPriceAndCost(t *testing.T) {
+	tariff := offPeakTariff()
+	if price := tariff.Price(at(12, 0)); price != 0.30 {
+		t.Errorf("Expected the peak price at noon, got %v", price)
+	}
+	if price := tariff.Price(at(2, 0)); price != 0.10 {
+		t.Errorf("Expected the default price overnight, got %v", price)
+	}
+
+	// 1 kW from 22:00 to 01:00: one peak hour and two off-peak hours
+	if cost := tariff.Cost(at(22, 0), 3*time.Hour, 1000); math.Abs(cost-0.50) > 1e-9 {
+		t.Errorf("Expected 0.50, got %v", cost)
+	}
+	if !tariff.cheaperBefore(at(20, 0), at(20, 0).Add(4*time.Hour)) {
+		t.Error("Expected off-peak power within four hours of 20:00")
+	}
+	if tariff.cheaperBefore(at(20, 0), at(20, 0).Add(2*time.Hour)) {
+		t.Error("Expected no cheaper power within two hours of 20:00")
+	}
+	if tariff.cheaperBefore(at(2, 0), at(2, 0).Add(12*time.Hour)) {
+		t.Error("Expected off-peak workloads to run immediately")
+	}
+}
+
+func TestDeferrableWorkloadsWaitForCheaperPower(t *testing.T) {
+	now := time.Now()
+	clock := func(offset time.Duration) string {
+		return now.Add(offset).Format("15:04")
+	}
+
+	config := DefaultSchedulerConfig()
+	config.Tariff.Enabled = true
+	config.Tariff.DefaultPrice = 0.10
+	config.Tariff.Periods = []TariffPeriod{
+		{TimeWindow: TimeWindow{Start: clock(-time.Hour), End: clock(time.Hour)}, PricePerKWh: 0.40},
+	}
+	config.Tariff.MaxDeferral = 3 * time.Hour
+	scheduler := NewSchedulerWithConfig(StrategyLeastUtilized, config)
+	scheduler.RegisterGPU(&GPU{ID: "0", MemoryTotal: 16384, Available: true})
+	scheduler.RegisterGPU(&GPU{ID: "1", MemoryTotal: 16384, Available: true})
+
+	scheduler.SubmitWorkload(&Workload{ID: "batch", MemoryRequired: 1024, Deferrable: true})
+	scheduler.SubmitWorkload(&Workload{ID: "closed", MemoryRequired: 1024,
+		Windows: []TimeWindow{{Start: clock(2 * time.Hour), End: clock(3 * time.Hour)}}})
+	scheduler.SubmitWorkload(&Workload{ID: "urgent", MemoryRequired: 1024})
+	scheduler.Schedule()
+
+	waits := scheduler.GetQueueWaits()
+	if len(waits) != 2 {
+		t.Fatalf("Expected the deferrable and windowed workloads held, got %+v", waits)
+	}
+	if held := scheduler.GetUtilizationMetrics()["held_workloads"]; held != 2 {
+		t.Errorf("Expected 2 held workloads, got %v", held)
+	}
+
+	// Once the deferral limit has passed the workload runs at the current price
+	scheduler.mu.Lock()
+	scheduler.config.Tariff.MaxDeferral = 30 * time.Minute
+	scheduler.mu.Unlock()
+	scheduler.Schedule()
+	if waits := scheduler.GetQueueWaits(); len(waits) != 1 || waits[0].WorkloadID != "closed" {
+		t.Errorf("Expected only the windowed workload left waiting, got %+v", waits)
+	}
+}
+
+func TestInvalidStartWindowRejected(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	err := scheduler.SubmitWorkload(&Workload{ID: "bad", MemoryRequired: 1024, Windows: []TimeWindow{{Start: "9am", End: "17:00"}}})
+	if err == nil {
+		t.Error("Expected a malformed start window to be rejected")
+	}
+}
+
+func TestTariffReportRealizedSavings(t *testing.T) {
+	config := DefaultSchedulerConfig()
+	config.Tariff = offPeakTariff()
+	scheduler := NewSchedulerWithConfig(StrategyLeastUtilized, config)
+	gpu := &GPU{ID: "0", MemoryTotal: 16384, PowerLimit: 500}
+
+	started := at(23, 0)
+	workload := &Workload{ID: "batch", Deferrable: true, SubmittedAt: at(19, 0), StartedAt: &started}
+	scheduler.recordTariffSavings(gpu, workload, at(23, 0).Add(2*time.Hour))
+	scheduler.recordTariffSavings(gpu, &Workload{ID: "interactive", SubmittedAt: at(12, 0), StartedAt: &started}, at(23, 30))
+
+	report := scheduler.GetTariffReport()
+	// 1 kWh at 0.30 had it started at 19:00, at 0.10 from 23:00
+	if report.Workloads != 1 || report.Deferred != 1 || report.AverageDelay != 4*time.Hour {
+		t.Errorf("Expected one deferred workload delayed 4h, got %+v", report)
+	}
+	if math.Abs(report.EnergyKWh-1) > 1e-9 || math.Abs(report.Savings-0.20) > 1e-9 || math.Abs(report.SavingsPercent-66.666) > 0.01 {
+		t.Errorf("Expected 0.20 saved on 1 kWh, got %+v", report)
+	}
+}
@@ -0,0 +1,37 @@
+package gpu
+
+import "sort"
+
+// DefaultTenant owns workloads submitted without a tenant
+const DefaultTenant = "default"
+
+// WorkloadTenant returns the tenant of a queued, placed or finished workload
+func (s *Scheduler) WorkloadTenant(workloadID string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if workload := s.findWorkload(workloadID); workload != nil {
+		return workload.Tenant, true
+	}
+	if finished, exists := s.finished[workloadID]; exists {
+		return finished.tenant, true
+	}
+	return "", false
+}
+
+// GPUTenants returns the tenants of the workloads placed on each GPU, sorted
+func (s *Scheduler) GPUTenants() map[string][]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenants := make(map[string][]string)
+	for id, gpu := range s.gpus {
+		for _, workload := range []*Workload{gpu.CurrentWorkload, gpu.ColocatedWorkload} {
+			if workload != nil && (len(tenants[id]) == 0 || tenants[id][0] != workload.Tenant) {
+				tenants[id] = append(tenants[id], workload.Tenant)
+			}
+		}
+		sort.Strings(tenants[id])
+	}
+	return tenants
+}
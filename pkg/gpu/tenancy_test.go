@@ -0,0 +1,50 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorkloadsCarryTheirTenant(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16384, Available: true})
+	scheduler.RegisterGPU(&GPU{ID: "gpu-1", MemoryTotal: 16384, Available: true})
+	next := collectLifecycle(t, scheduler)
+
+	scheduler.SubmitWorkload(&Workload{ID: "w1", Tenant: "vision", MemoryRequired: 8192})
+	scheduler.SubmitWorkload(&Workload{ID: "w2", MemoryRequired: 8192})
+	scheduler.Schedule()
+
+	if queued := next(1)[0]; queued.Tenant != "vision" {
+		t.Errorf("queued event tenant = %q", queued.Tenant)
+	}
+	tenants := make(map[string]string)
+	for _, workload := range scheduler.ListWorkloads() {
+		tenants[workload.ID] = workload.Tenant
+	}
+	if tenants["w1"] != "vision" || tenants["w2"] != DefaultTenant {
+		t.Errorf("tenants = %v, want w2 in the default tenant", tenants)
+	}
+	placed := make(map[string]bool)
+	for _, gpuTenants := range scheduler.GPUTenants() {
+		for _, tenant := range gpuTenants {
+			placed[tenant] = true
+		}
+	}
+	if len(placed) != 2 || !placed["vision"] || !placed[DefaultTenant] {
+		t.Errorf("GPU tenants = %v", scheduler.GPUTenants())
+	}
+
+	scheduler.CompleteWorkload("w1")
+	if tenant, exists := scheduler.WorkloadTenant("w1"); !exists || tenant != "vision" {
+		t.Errorf("finished workload tenant = %q, %v", tenant, exists)
+	}
+	for _, record := range scheduler.GetUsageRecords(time.Time{}) {
+		if record.WorkloadID == "w1" && record.Tenant != "vision" {
+			t.Errorf("usage record tenant = %q", record.Tenant)
+		}
+	}
+	if _, exists := scheduler.WorkloadTenant("unknown"); exists {
+		t.Error("unknown workload has a tenant")
+	}
+}
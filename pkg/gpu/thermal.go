@@ -0,0 +1,108 @@
+package gpu
+
+import "time"
+
+// ThermalConfig controls how GPU temperature steers sustained workloads away
+// from hot spots before their clocks throttle
+type ThermalConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// GPUs throttle their clocks at SlowdownTemperature. Sustained workloads
+	// only use GPUs with less than MinHeadroom degrees below it when no cooler
+	// GPU fits, and least-utilized placement adds PenaltyPerDegree utilization
+	// points for each degree of headroom below twice MinHeadroom.
+	SlowdownTemperature float64 `yaml:"slowdown_temperature" json:"slowdown_temperature"`
+	MinHeadroom         float64 `yaml:"min_headroom" json:"min_headroom"`
+	PenaltyPerDegree    float64 `yaml:"penalty_per_degree" json:"penalty_per_degree"`
+
+	// Weight of the newest sample in each GPU's recent temperature
+	Smoothing float64 `yaml:"smoothing" json:"smoothing"`
+
+	// Training workloads and workloads estimated to run at least this long are sustained
+	SustainedAfter time.Duration `yaml:"sustained_after" json:"sustained_after"`
+}
+
+// DefaultThermalConfig returns thermal placement settings for datacenter GPUs
+func DefaultThermalConfig() ThermalConfig {
+	return ThermalConfig{
+		Enabled:             true,
+		SlowdownTemperature: 83,
+		MinHeadroom:         8,
+		PenaltyPerDegree:    2,
+		Smoothing:           0.3,
+		SustainedAfter:      30 * time.Minute,
+	}
+}
+
+// observeTemperature folds a sample into a GPU's recent temperature and
+// counts samples taken at the slowdown temperature; callers must hold the lock
+func (s *Scheduler) observeTemperature(gpu *GPU, temperature float64) {
+	recent, exists := s.temperatures[gpu.ID]
+	if !exists {
+		recent = temperature
+	}
+	s.temperatures[gpu.ID] = recent + s.config.Thermal.Smoothing*(temperature-recent)
+	if temperature >= s.config.Thermal.SlowdownTemperature {
+		s.throttleSamples++
+	}
+}
+
+// recentTemperature returns a GPU's smoothed temperature, or its last
+// reported one before any metrics were observed; callers must hold the lock
+func (s *Scheduler) recentTemperature(gpu *GPU) float64 {
+	if recent, exists := s.temperatures[gpu.ID]; exists {
+		return recent
+	}
+	return gpu.Temperature
+}
+
+// thermalHeadroom returns the degrees a GPU can warm before throttling; callers must hold the lock
+func (s *Scheduler) thermalHeadroom(gpu *GPU) float64 {
+	return s.config.Thermal.SlowdownTemperature - s.recentTemperature(gpu)
+}
+
+// sustained reports whether a workload runs long enough to heat its GPU
+func (s *Scheduler) sustained(workload *Workload) bool {
+	return workload.Class == WorkloadClassTraining || workload.Profile == ProfileTraining ||
+		(s.config.Thermal.SustainedAfter > 0 && workload.EstimatedTime >= s.config.Thermal.SustainedAfter)
+}
+
+// thermallyAware reports whether temperature should steer a workload's placement
+func (s *Scheduler) thermallyAware(workload *Workload) bool {
+	return s.config.Thermal.Enabled && s.sustained(workload)
+}
+
+// avoids reports whether a workload should go elsewhere when another GPU
+// fits, because of an untolerated PreferNoSchedule taint or because a
+// sustained workload would push a hot GPU into throttling; callers must hold the lock
+func (s *Scheduler) avoids(gpu *GPU, workload *Workload) bool {
+	if avoids(gpu, workload) {
+		return true
+	}
+	return s.thermallyAware(workload) && s.thermalHeadroom(gpu) < s.config.Thermal.MinHeadroom
+}
+
+// thermalPenalty returns the utilization points added to a warm GPU's
+// least-utilized score; callers must hold the lock
+func (s *Scheduler) thermalPenalty(gpu *GPU, workload *Workload) float64 {
+	if !s.thermallyAware(workload) {
+		return 0
+	}
+	shortfall := 2*s.config.Thermal.MinHeadroom - s.thermalHeadroom(gpu)
+	if shortfall <= 0 {
+		return 0
+	}
+	return shortfall * s.config.Thermal.PenaltyPerDegree
+}
+
+// hotGPUs counts GPUs whose recent temperature is within MinHeadroom of
+// throttling; callers must hold the lock
+func (s *Scheduler) hotGPUs() int {
+	hot := 0
+	for _, gpu := range s.gpus {
+		if s.recentTemperature(gpu) > 0 && s.thermalHeadroom(gpu) < s.config.Thermal.MinHeadroom {
+			hot++
+		}
+	}
+	return hot
+}
@@ -0,0 +1,90 @@
+package gpu
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func newThermalScheduler(t *testing.T, strategy SchedulingStrategy) *Scheduler {
+	scheduler := NewScheduler(strategy)
+	for _, id := range []string{"hot", "warm", "cool"} {
+		if err := scheduler.RegisterGPU(&GPU{ID: id, MemoryTotal: 16384, Available: true}); err != nil {
+			t.Fatalf("RegisterGPU failed: %v", err)
+		}
+	}
+	for _, sample := range []struct {
+		id          string
+		temperature float64
+		utilization float64
+	}{
+		{"hot", 80, 0}, {"warm", 70, 5}, {"cool", 45, 20},
+	} {
+		scheduler.ObserveMetrics(GPUMetrics{GPUID: sample.id, Temperature: sample.temperature, UtilizationGPU: sample.utilization})
+	}
+	return scheduler
+}
+
+func TestSustainedWorkloadsAvoidHotGPUs(t *testing.T) {
+	for _, strategy := range []SchedulingStrategy{StrategyLeastUtilized, StrategyBestFit, StrategyEnergyAware} {
+		scheduler := newThermalScheduler(t, strategy)
+		scheduler.gpus["cool"].MemoryUsed = 8192 // Best fit would otherwise prefer it anyway
+		scheduler.gpus["warm"].MemoryUsed = 12288
+
+		scheduler.SubmitWorkload(&Workload{ID: "train", Class: WorkloadClassTraining, MemoryRequired: 2048})
+		scheduler.Schedule()
+
+		if gpu := scheduler.gpus["hot"]; gpu.CurrentWorkload != nil {
+			t.Errorf("%s: expected the training job to avoid the hot GPU", strategy)
+		}
+	}
+}
+
+func TestThermalPenaltyPrefersCoolerGPUs(t *testing.T) {
+	scheduler := newThermalScheduler(t, StrategyLeastUtilized)
+
+	// warm scores 5% + (16-13)*2 = 11 points, below cool's 20%
+	scheduler.SubmitWorkload(&Workload{ID: "short", MemoryRequired: 1024})
+	scheduler.SubmitWorkload(&Workload{ID: "long", MemoryRequired: 1024, EstimatedTime: time.Hour})
+	scheduler.Schedule()
+
+	if current := scheduler.gpus["hot"].CurrentWorkload; current == nil || current.ID != "short" {
+		t.Errorf("Expected short jobs to ignore temperature, got %+v", current)
+	}
+	if current := scheduler.gpus["warm"].CurrentWorkload; current == nil || current.ID != "long" {
+		t.Errorf("Expected the long job on the warm GPU, got %+v", current)
+	}
+
+	decisions := scheduler.GetDecisions(time.Time{})
+	if !strings.Contains(decisions[len(decisions)-1].Reason, "13°C thermal headroom") {
+		t.Errorf("Expected the headroom in the decision reason, got %q", decisions[len(decisions)-1].Reason)
+	}
+}
+
+func TestHotGPUsStillRunWorkWhenNothingElseFits(t *testing.T) {
+	scheduler := newThermalScheduler(t, StrategyLeastUtilized)
+	scheduler.gpus["warm"].Available = false
+	scheduler.gpus["cool"].Available = false
+
+	scheduler.SubmitWorkload(&Workload{ID: "train", Class: WorkloadClassTraining, MemoryRequired: 1024})
+	scheduler.Schedule()
+
+	if scheduler.gpus["hot"].CurrentWorkload == nil {
+		t.Error("Expected the hot GPU to be used as a last resort")
+	}
+}
+
+func TestThermalMetrics(t *testing.T) {
+	scheduler := newThermalScheduler(t, StrategyLeastUtilized)
+	scheduler.ObserveMetrics(GPUMetrics{GPUID: "hot", Temperature: 88})
+
+	metrics := scheduler.GetUtilizationMetrics()
+	if metrics["hot_gpus"] != 1 || metrics["thermal_throttle_samples"] != 1 {
+		t.Errorf("Expected one hot GPU and one throttle sample, got %v and %v",
+			metrics["hot_gpus"], metrics["thermal_throttle_samples"])
+	}
+	// Smoothing keeps a single spike from dominating: 80 + 0.3*(88-80)
+	if recent := scheduler.recentTemperature(scheduler.gpus["hot"]); recent < 82.39 || recent > 82.41 {
+		t.Errorf("Expected a smoothed temperature of 82.4, got %v", recent)
+	}
+}
@@ -12,8 +12,15 @@ type GPU struct {
 	Temperature     float64
 	PowerUsage      float64
 	Available       bool
+	Labels          map[string]string // e.g. arch=hopper, nvlink=true, zone=us-west-2a
+	Taints          []Taint           // e.g. maintenance, flaky-ecc
+	Pool            string            // Named partition such as prod-inference; empty for the default pool
+	Node            string            // Host the GPU is installed in; GPUs without one share a node
 	CurrentWorkload *Workload
 
+	// Low-priority training job sharing the GPU with an inference workload
+	ColocatedWorkload *Workload
+
 	// Real-time metrics integration
 	LastMetricsUpdate time.Time
 	MetricsHistory    []GPUMetrics
@@ -22,6 +29,15 @@ type GPU struct {
 	FanSpeed          float64
 	ClockGraphics     uint64
 	ClockMemory       uint64
+
+	// NVENC/NVDEC capacity. Limits are concurrent sessions, 0 for unlimited
+	// and negative when the GPU has no such engine (e.g. NVENC on A100).
+	EncoderSessionLimit int
+	DecoderSessionLimit int
+	EncoderSessions     int // Open sessions from the latest metrics
+	DecoderSessions     int
+	EncoderUtilization  float64
+	DecoderUtilization  float64
 }
 
 // Workload represents a task that requires GPU resources
@@ -29,21 +45,55 @@ type Workload struct {
 	ID             string
 	Name           string
 	Priority       int
+	Class          WorkloadClass
+	Profile        ProfileClass // Learned from earlier runs with the same Name
+	ClientID       string
+	Tenant         string // Team or namespace owning the workload; DefaultTenant when empty
+	IdempotencyKey string // Retries with the same key from the same client are deduplicated
+	Labels         map[string]string
+	Selector       map[string]string // Labels a GPU must carry to run the workload
+	Tolerations    []Toleration
+	Pool           string       // Only GPUs in the same pool run the workload
+	Deferrable     bool         // May wait up to Tariff.MaxDeferral for cheaper electricity
+	Windows        []TimeWindow // Daily times the workload may start in; any time when empty
 	MemoryRequired uint64
 	EstimatedTime  time.Duration
 	Status         WorkloadStatus
 	AssignedGPU    string
 	SubmittedAt    time.Time
+	QueuedAt       time.Time  // Last entered the queue, after submission or preemption
+	StarvedAt      *time.Time // Exceeded the queue wait objective
 	StartedAt      *time.Time
 	CompletedAt    *time.Time
+
+	// Co-location state for training jobs sharing an inference GPU
+	PausedAt    *time.Time
+	Preemptions int
+
+	// NVENC/NVDEC sessions a video workload opens; video workloads are
+	// placed by encoder and decoder capacity rather than compute utilization
+	EncoderSessions int
+	DecoderSessions int
+
+	// Outputs registered by the workload, such as trained models
+	Artifacts []Artifact
 }
 
+// WorkloadClass describes the latency sensitivity of a workload
+type WorkloadClass string
+
+const (
+	WorkloadClassInference WorkloadClass = "inference"
+	WorkloadClassTraining  WorkloadClass = "training"
+)
+
 // WorkloadStatus represents the current state of a workload
 type WorkloadStatus string
 
 const (
 	WorkloadPending   WorkloadStatus = "pending"
 	WorkloadRunning   WorkloadStatus = "running"
+	WorkloadPaused    WorkloadStatus = "paused"
 	WorkloadCompleted WorkloadStatus = "completed"
 	WorkloadFailed    WorkloadStatus = "failed"
 )
@@ -56,6 +106,7 @@ const (
 	StrategyLeastUtilized SchedulingStrategy = "least_utilized"
 	StrategyBestFit       SchedulingStrategy = "best_fit"
 	StrategyPriority      SchedulingStrategy = "priority"
+	StrategyEnergyAware   SchedulingStrategy = "energy_aware"
 )
 
 // GPUStats represents aggregated statistics for a GPU over time
@@ -102,6 +153,7 @@ type GPUAlert struct {
 	Threshold    float64   `json:"threshold"`
 	Timestamp    time.Time `json:"timestamp"`
 	Acknowledged bool      `json:"acknowledged"`
+	Profile      string    `json:"profile,omitempty"` // Threshold profile the alert was evaluated against
 }
 
 // ClusterMetrics represents metrics for the entire GPU cluster
@@ -112,11 +164,14 @@ type ClusterMetrics struct {
 	TotalMemoryMB      uint64                     `json:"total_memory_mb"`
 	UsedMemoryMB       uint64                     `json:"used_memory_mb"`
 	AverageUtilization float64                    `json:"average_utilization"`
+	Utilization        Distribution               `json:"utilization_distribution"` // GPU utilization percent across GPUs
+	Memory             Distribution               `json:"memory_distribution"`      // Memory used percent across GPUs
 	AverageTemperature float64                    `json:"average_temperature"`
 	TotalPowerDraw     float64                    `json:"total_power_draw"`
 	TotalProcesses     int                        `json:"total_processes"`
 	HealthyGPUs        int                        `json:"healthy_gpus"`
 	GPUStats           map[string]GPUStats        `json:"gpu_stats"`
 	GPUHealth          map[string]GPUHealthStatus `json:"gpu_health"`
+	Nodes              map[string]NodeStats       `json:"nodes"`
 	Timestamp          time.Time                  `json:"timestamp"`
 }
@@ -0,0 +1,98 @@
+package gpu
+
+import (
+	"sort"
+	"time"
+)
+
+// maxUsageRecords bounds the scheduler's usage ledger
+const maxUsageRecords = 50000
+
+// UsageRecord is one stretch of GPU time held by a workload, ending when it
+// completed, failed or was evicted. Chargeback and showback price these.
+type UsageRecord struct {
+	WorkloadID string            `json:"workload_id"`
+	Name       string            `json:"name,omitempty"`
+	Tenant     string            `json:"tenant,omitempty"`
+	Pool       string            `json:"pool,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	GPUID      string            `json:"gpu_id"`
+	GPUName    string            `json:"gpu_name,omitempty"`
+	Shared     bool              `json:"shared,omitempty"` // Co-located with another workload on the GPU
+	Start      time.Time         `json:"start"`
+	End        time.Time         `json:"end"`
+	Status     WorkloadStatus    `json:"status"` // Running for usage still accruing
+}
+
+// Hours returns the GPU hours a record covers between from and to
+func (r UsageRecord) Hours(from, to time.Time) float64 {
+	start, end := r.Start, r.End
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start).Hours()
+}
+
+// newUsageRecord records a workload's time on a GPU up to end
+func newUsageRecord(gpu *GPU, workload *Workload, end time.Time, status WorkloadStatus) UsageRecord {
+	return UsageRecord{
+		WorkloadID: workload.ID,
+		Name:       workload.Name,
+		Tenant:     workload.Tenant,
+		Pool:       workload.Pool,
+		Labels:     workload.Labels,
+		GPUID:      gpu.ID,
+		GPUName:    gpu.Name,
+		Shared:     gpu.ColocatedWorkload != nil,
+		Start:      *workload.StartedAt,
+		End:        end,
+		Status:     status,
+	}
+}
+
+// recordUsage adds a workload leaving its GPU to the usage ledger; callers must hold the lock
+func (s *Scheduler) recordUsage(gpu *GPU, workload *Workload, end time.Time, status WorkloadStatus) {
+	if workload.StartedAt == nil {
+		return
+	}
+	s.usageRecords = append(s.usageRecords, newUsageRecord(gpu, workload, end, status))
+	if len(s.usageRecords) > maxUsageRecords {
+		s.usageRecords = s.usageRecords[len(s.usageRecords)-maxUsageRecords:]
+	}
+}
+
+// GetUsageRecords returns GPU time held at or after since: finished stretches
+// oldest first, then running workloads accrued up to now
+func (s *Scheduler) GetUsageRecords(since time.Time) []UsageRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	records := make([]UsageRecord, 0)
+	for _, record := range s.usageRecords {
+		if record.End.After(since) {
+			records = append(records, record)
+		}
+	}
+	running := make([]UsageRecord, 0)
+	for _, gpu := range s.gpus {
+		for _, workload := range []*Workload{gpu.CurrentWorkload, gpu.ColocatedWorkload} {
+			if workload != nil && workload.StartedAt != nil {
+				running = append(running, newUsageRecord(gpu, workload, now, WorkloadRunning))
+			}
+		}
+	}
+	sort.Slice(running, func(i, j int) bool {
+		if running[i].GPUID != running[j].GPUID {
+			return running[i].GPUID < running[j].GPUID
+		}
+		return running[i].WorkloadID < running[j].WorkloadID
+	})
+	return append(records, running...)
+}
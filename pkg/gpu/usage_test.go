@@ -0,0 +1,66 @@
+package gpu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUsageRecordHours(t *testing.T) {
+	record := UsageRecord{Start: at(10, 0), End: at(14, 0)}
+
+	if hours := record.Hours(at(0, 0), at(23, 0)); hours != 4 {
+		t.Errorf("Expected 4 hours, got %.2f", hours)
+	}
+	if hours := record.Hours(at(12, 0), at(23, 0)); hours != 2 {
+		t.Errorf("Expected 2 hours after noon, got %.2f", hours)
+	}
+	if hours := record.Hours(at(15, 0), at(23, 0)); hours != 0 {
+		t.Errorf("Expected no hours outside the record, got %.2f", hours)
+	}
+}
+
+func TestGetUsageRecords(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", Name: "NVIDIA A100", MemoryTotal: 40960, Available: true})
+	scheduler.RegisterGPU(&GPU{ID: "gpu-1", Name: "NVIDIA A100", MemoryTotal: 40960, Available: true})
+
+	labels := map[string]string{"team": "search"}
+	scheduler.SubmitWorkload(&Workload{ID: "done", Labels: labels, MemoryRequired: 1024})
+	scheduler.SubmitWorkload(&Workload{ID: "running", Labels: labels, MemoryRequired: 1024})
+	scheduler.Schedule()
+	before := time.Now()
+	if err := scheduler.CompleteWorkload("done"); err != nil {
+		t.Fatalf("CompleteWorkload failed: %v", err)
+	}
+
+	records := scheduler.GetUsageRecords(before.Add(-time.Hour))
+	if len(records) != 2 {
+		t.Fatalf("Expected a finished and a running record, got %+v", records)
+	}
+	if records[0].WorkloadID != "done" || records[0].Status != WorkloadCompleted || records[0].GPUName != "NVIDIA A100" {
+		t.Errorf("Unexpected finished record %+v", records[0])
+	}
+	if records[1].WorkloadID != "running" || records[1].Status != WorkloadRunning || records[1].Labels["team"] != "search" {
+		t.Errorf("Unexpected running record %+v", records[1])
+	}
+
+	if records := scheduler.GetUsageRecords(time.Now().Add(time.Hour)); len(records) != 1 || records[0].WorkloadID != "running" {
+		t.Errorf("Expected only the running workload after since, got %+v", records)
+	}
+}
+
+func TestEvictionRecordsUsage(t *testing.T) {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 40960, Available: true})
+	scheduler.SubmitWorkload(&Workload{ID: "job", MemoryRequired: 1024})
+	scheduler.Schedule()
+
+	if err := scheduler.TaintGPU("gpu-0", Taint{Key: "maintenance", Effect: TaintNoExecute}); err != nil {
+		t.Fatalf("TaintGPU failed: %v", err)
+	}
+
+	records := scheduler.GetUsageRecords(time.Time{})
+	if len(records) != 1 || records[0].WorkloadID != "job" || records[0].Status != WorkloadPending {
+		t.Errorf("Expected the evicted run in the ledger, got %+v", records)
+	}
+}
@@ -0,0 +1,73 @@
+package gpu
+
+import (
+	"bufio"
+	"context"
+	"strings"
+)
+
+// Virtualization modes reported by nvidia-smi
+const (
+	VirtualizationNone        = "None"
+	VirtualizationPassThrough = "Pass-Through"
+	VirtualizationVGPU        = "VGPU" // A vGPU (GRID) guest
+)
+
+// gpuMetricsFields names the fields of gpuMetricsQuery after the index
+var gpuMetricsFields = strings.Split(strings.TrimPrefix(gpuMetricsQuery, "--query-gpu=index,"), ",")
+
+// Reports returns whether the GPU reported a --query-gpu field such as
+// "power.draw"; vGPU guests typically lack power, clocks and fan speed
+func (m GPUMetrics) Reports(field string) bool {
+	for _, unavailable := range m.Unavailable {
+		if unavailable == field {
+			return false
+		}
+	}
+	return true
+}
+
+// unavailableFields lists the fields of a metrics row that nvidia-smi did
+// not report, e.g. "[N/A]" or "[Not Supported]"
+func unavailableFields(fields []string) []string {
+	var unavailable []string
+	for i, name := range gpuMetricsFields {
+		if i < len(fields) && strings.HasPrefix(strings.TrimSpace(fields[i]), "[") {
+			unavailable = append(unavailable, name)
+		}
+	}
+	return unavailable
+}
+
+// discoverVirtualization returns each GPU's virtualization mode. Drivers
+// without the field report nothing, and the GPUs are treated as physical.
+func (mc *MetricsCollector) discoverVirtualization(ctx context.Context) map[string]string {
+	modes := make(map[string]string)
+	output, err := mc.nvidiaSMI(ctx, false, "--query-gpu=index,virtualization_mode", "--format=csv,noheader,nounits")
+	if err != nil {
+		return modes
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ", ")
+		if len(fields) == 2 {
+			modes[strings.TrimSpace(fields[0])] = strings.TrimSpace(fields[1])
+		}
+	}
+	return modes
+}
+
+// labelVirtualization records a GPU's virtualization mode and, in vGPU
+// guests, its vGPU profile, which nvidia-smi reports as the GPU name
+// (e.g. "GRID T4-4Q")
+func (mc *MetricsCollector) labelVirtualization(metrics *GPUMetrics) {
+	mc.mu.RLock()
+	mode := mc.virtualization[metrics.GPUID]
+	mc.mu.RUnlock()
+
+	metrics.VirtualizationMode = mode
+	if mode == VirtualizationVGPU {
+		metrics.VGPUProfile = metrics.Name
+	}
+}
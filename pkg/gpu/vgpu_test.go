@@ -0,0 +1,59 @@
+package gpu
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeVGPUGuest answers nvidia-smi like a vGPU guest without power, clock or fan readings
+func fakeVGPUGuest(ctx context.Context, spec CommandSpec) ([]byte, error) {
+	args := strings.Join(spec.Args, " ")
+	switch {
+	case strings.Contains(args, "virtualization_mode"):
+		return []byte("0, VGPU\n"), nil
+	case strings.HasPrefix(args, "--query-gpu=index "):
+		return []byte("0\n"), nil
+	case strings.Contains(args, "-apps="):
+		return nil, nil
+	}
+	return []byte("0, GRID T4-4Q, 35, 10, 4096, 1024, 3072, [N/A], [N/A], [N/A], [N/A], [N/A], [N/A], 0, 0, 0, 0\n"), nil
+}
+
+func TestCollectorDegradesInVGPUGuest(t *testing.T) {
+	collector := NewMetricsCollector(time.Hour)
+	collector.SetAdaptiveInterval(AdaptiveIntervalConfig{Enabled: false})
+	runner := NewCommandRunner(CommandRunnerConfig{})
+	runner.exec = fakeVGPUGuest
+	collector.SetCommandRunner(runner)
+
+	if err := collector.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer collector.Stop()
+	collector.collectMetrics(context.Background())
+
+	metrics := collector.GetLatestMetrics()["0"]
+	if metrics.VirtualizationMode != VirtualizationVGPU || metrics.VGPUProfile != "GRID T4-4Q" {
+		t.Errorf("virtualization = %q, profile = %q", metrics.VirtualizationMode, metrics.VGPUProfile)
+	}
+	if metrics.UtilizationGPU != 35 || metrics.MemoryUsed != 1024 {
+		t.Errorf("available metrics were not collected: %+v", metrics)
+	}
+	for _, field := range []string{"temperature.gpu", "power.draw", "power.limit", "fan.speed", "clocks.current.graphics", "clocks.current.memory"} {
+		if metrics.Reports(field) {
+			t.Errorf("%s reported as available", field)
+		}
+	}
+	if !metrics.Reports("utilization.gpu") || !metrics.Reports("encoder.stats.sessionCount") {
+		t.Errorf("unavailable = %v", metrics.Unavailable)
+	}
+}
+
+func TestPhysicalGPUReportsAllFields(t *testing.T) {
+	metrics, err := parseGPUMetricsRow("0, NVIDIA A100, 10, 20, 40960, 8192, 32768, 60, 250.5, 400, 30, 1410, 1215, 0, 0, 0, 0", time.Now())
+	if err != nil || len(metrics.Unavailable) != 0 || metrics.VGPUProfile != "" {
+		t.Errorf("metrics = %+v, %v", metrics, err)
+	}
+}
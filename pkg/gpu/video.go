@@ -0,0 +1,116 @@
+package gpu
+
+import "fmt"
+
+// VideoConfig controls how video workloads are placed by NVENC/NVDEC capacity
+type VideoConfig struct {
+	// GPUs whose encoder or decoder is at least this busy take no more
+	// workloads needing that engine
+	MaxEncoderUtilization float64 `yaml:"max_encoder_utilization" json:"max_encoder_utilization"`
+	MaxDecoderUtilization float64 `yaml:"max_decoder_utilization" json:"max_decoder_utilization"`
+}
+
+// DefaultVideoConfig returns video placement settings
+func DefaultVideoConfig() VideoConfig {
+	return VideoConfig{
+		MaxEncoderUtilization: 90,
+		MaxDecoderUtilization: 90,
+	}
+}
+
+// videoWorkload reports whether a workload needs encoder or decoder sessions
+func videoWorkload(workload *Workload) bool {
+	return workload.EncoderSessions > 0 || workload.DecoderSessions > 0
+}
+
+// freeSessions returns the sessions an engine can still open, -1 when
+// unlimited; a negative limit means the GPU has no such engine
+func freeSessions(limit, open int) int {
+	switch {
+	case limit == 0:
+		return -1
+	case limit < 0 || open >= limit:
+		return 0
+	}
+	return limit - open
+}
+
+// sessionsFit reports whether an engine can open needed more sessions
+func sessionsFit(needed, limit, open int, utilization, maxUtilization float64) bool {
+	if needed <= 0 {
+		return true
+	}
+	free := freeSessions(limit, open)
+	return (free < 0 || free >= needed) && utilization < maxUtilization
+}
+
+// videoFits reports whether a GPU has the encoder and decoder sessions a
+// workload needs; callers must hold the lock
+func (s *Scheduler) videoFits(gpu *GPU, workload *Workload) bool {
+	config := s.config.Video
+	return sessionsFit(workload.EncoderSessions, gpu.EncoderSessionLimit, gpu.EncoderSessions, gpu.EncoderUtilization, config.MaxEncoderUtilization) &&
+		sessionsFit(workload.DecoderSessions, gpu.DecoderSessionLimit, gpu.DecoderSessions, gpu.DecoderUtilization, config.MaxDecoderUtilization)
+}
+
+// unlimitedSessions stands in for an engine without a session limit
+const unlimitedSessions = 1 << 16
+
+// openableSessions returns the sessions an idle GPU could still open on engines
+// below their utilization cap. It grows as sessions close, so deferred video
+// workloads are examined again; callers must hold the lock.
+func (s *Scheduler) openableSessions(gpu *GPU) int {
+	if assignableMemory(gpu) == 0 {
+		return 0
+	}
+	engine := func(limit, open int, utilization, maxUtilization float64) int {
+		free := freeSessions(limit, open)
+		switch {
+		case utilization >= maxUtilization:
+			return 0
+		case free < 0:
+			return unlimitedSessions
+		}
+		return free
+	}
+	config := s.config.Video
+	return engine(gpu.EncoderSessionLimit, gpu.EncoderSessions, gpu.EncoderUtilization, config.MaxEncoderUtilization) +
+		engine(gpu.DecoderSessionLimit, gpu.DecoderSessions, gpu.DecoderUtilization, config.MaxDecoderUtilization)
+}
+
+// placementLoad is the utilization least-utilized placement compares: the
+// busiest engine a video workload needs, or compute utilization otherwise
+func placementLoad(gpu *GPU, workload *Workload) float64 {
+	if !videoWorkload(workload) {
+		return gpu.Utilization
+	}
+	load := 0.0
+	if workload.EncoderSessions > 0 {
+		load = gpu.EncoderUtilization
+	}
+	if workload.DecoderSessions > 0 && gpu.DecoderUtilization > load {
+		load = gpu.DecoderUtilization
+	}
+	return load
+}
+
+// videoReason describes the sessions a video workload found on its GPU
+func videoReason(gpu *GPU, workload *Workload) string {
+	reason := ""
+	if workload.EncoderSessions > 0 {
+		reason += fmt.Sprintf(", %d encoder sessions required, %s free", workload.EncoderSessions,
+			formatSessions(freeSessions(gpu.EncoderSessionLimit, gpu.EncoderSessions)))
+	}
+	if workload.DecoderSessions > 0 {
+		reason += fmt.Sprintf(", %d decoder sessions required, %s free", workload.DecoderSessions,
+			formatSessions(freeSessions(gpu.DecoderSessionLimit, gpu.DecoderSessions)))
+	}
+	return reason
+}
+
+// formatSessions formats a free session count
+func formatSessions(free int) string {
+	if free < 0 {
+		return "unlimited"
+	}
+	return fmt.Sprint(free)
+}
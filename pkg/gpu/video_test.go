@@ -0,0 +1,89 @@
+package gpu
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func newVideoScheduler(t *testing.T) *Scheduler {
+	scheduler := NewScheduler(StrategyLeastUtilized)
+	for _, gpu := range []*GPU{
+		{ID: "a100", MemoryTotal: 40960, Available: true, EncoderSessionLimit: -1},
+		{ID: "t4-busy", MemoryTotal: 16384, Available: true, EncoderSessionLimit: 8},
+		{ID: "t4-idle", MemoryTotal: 16384, Available: true, EncoderSessionLimit: 8},
+	} {
+		if err := scheduler.RegisterGPU(gpu); err != nil {
+			t.Fatalf("RegisterGPU failed: %v", err)
+		}
+	}
+	for _, sample := range []GPUMetrics{
+		{GPUID: "a100", UtilizationGPU: 0},
+		{GPUID: "t4-busy", UtilizationGPU: 5, EncoderSessions: 6, EncoderUtilization: 70},
+		{GPUID: "t4-idle", UtilizationGPU: 60, EncoderSessions: 1, EncoderUtilization: 10},
+	} {
+		scheduler.ObserveMetrics(sample)
+	}
+	return scheduler
+}
+
+func TestVideoWorkloadsPlacedByEncoderCapacity(t *testing.T) {
+	scheduler := newVideoScheduler(t)
+
+	// Compute utilization alone would pick the A100, which has no encoder,
+	// then the T4 with the busier encoder
+	scheduler.SubmitWorkload(&Workload{ID: "transcode", MemoryRequired: 2048, EncoderSessions: 2})
+	scheduler.Schedule()
+
+	if current := scheduler.gpus["t4-idle"].CurrentWorkload; current == nil || current.ID != "transcode" {
+		t.Fatalf("Expected the transcode on the T4 with the idle encoder, got %+v", current)
+	}
+	decisions := scheduler.GetDecisions(time.Time{})
+	if reason := decisions[len(decisions)-1].Reason; !strings.Contains(reason, "2 encoder sessions required, 7 free") {
+		t.Errorf("Expected encoder sessions in the decision reason, got %q", reason)
+	}
+
+	// Compute workloads still go to the least utilized GPU
+	scheduler.SubmitWorkload(&Workload{ID: "train", MemoryRequired: 2048})
+	scheduler.Schedule()
+	if current := scheduler.gpus["a100"].CurrentWorkload; current == nil || current.ID != "train" {
+		t.Errorf("Expected the compute job on the A100, got %+v", current)
+	}
+}
+
+func TestVideoWorkloadsWaitForSessions(t *testing.T) {
+	scheduler := newVideoScheduler(t)
+	scheduler.gpus["t4-idle"].Available = false
+
+	// t4-busy has 2 of 8 sessions free
+	scheduler.SubmitWorkload(&Workload{ID: "ladder", MemoryRequired: 2048, EncoderSessions: 3})
+	scheduler.Schedule()
+	if current := scheduler.gpus["t4-busy"].CurrentWorkload; current != nil {
+		t.Fatalf("Expected the workload to wait for encoder sessions, got it on t4-busy")
+	}
+
+	scheduler.ObserveMetrics(GPUMetrics{GPUID: "t4-busy", EncoderSessions: 4, EncoderUtilization: 40})
+	scheduler.Schedule()
+	if current := scheduler.gpus["t4-busy"].CurrentWorkload; current == nil || current.ID != "ladder" {
+		t.Errorf("Expected the workload placed once sessions freed, got %+v", current)
+	}
+}
+
+func TestSessionsFit(t *testing.T) {
+	for _, tc := range []struct {
+		needed, limit, open int
+		utilization         float64
+		want                bool
+	}{
+		{0, -1, 0, 0, true},   // Not needed
+		{1, -1, 0, 0, false},  // No engine
+		{4, 0, 30, 50, true},  // Unlimited sessions
+		{4, 0, 30, 95, false}, // Engine saturated
+		{2, 3, 1, 10, true},
+		{3, 3, 1, 10, false},
+	} {
+		if got := sessionsFit(tc.needed, tc.limit, tc.open, tc.utilization, 90); got != tc.want {
+			t.Errorf("sessionsFit(%d, %d, %d, %.0f) = %v, want %v", tc.needed, tc.limit, tc.open, tc.utilization, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package gpu
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// WAL operations, one per workload state change
+const (
+	walSubmitted  = "submitted"
+	walAssigned   = "assigned"
+	walColocated  = "colocated"
+	walPaused     = "paused"
+	walResumed    = "resumed"
+	walPreempted  = "preempted"
+	walPromoted   = "promoted"
+	walStarved    = "starved"
+	walCompleted  = "completed"
+	walFailed     = "failed"
+	walArtifacts  = "artifacts"
+	walCheckpoint = "checkpoint"
+)
+
+// WALConfig configures the scheduler's write-ahead log
+type WALConfig struct {
+	Path         string `yaml:"path" json:"path"`
+	SyncWrites   bool   `yaml:"sync_writes" json:"sync_writes"`     // fsync every record so it survives power loss, not just a crash
+	CompactEvery int    `yaml:"compact_every" json:"compact_every"` // Rewrite the log as a checkpoint after this many records
+}
+
+// DefaultWALConfig returns a durable write-ahead log configuration
+func DefaultWALConfig() WALConfig {
+	return WALConfig{
+		Path:         "agentaflow-scheduler.wal",
+		SyncWrites:   true,
+		CompactEvery: 10000,
+	}
+}
+
+// WALRecord is one workload state change. Each record carries the whole
+// workload, so replay only needs the latest record per workload.
+type WALRecord struct {
+	Seq       uint64    `json:"seq"`
+	Op        string    `json:"op"`
+	Workload  Workload  `json:"workload"`
+	Colocated bool      `json:"colocated,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// writeAheadLog appends JSON-encoded records, one per line
+type writeAheadLog struct {
+	config  WALConfig
+	file    *os.File
+	seq     uint64
+	records int // Records appended since the last checkpoint
+
+	// Records since the last checkpoint, up to maxWALTail, for standbys
+	// replicating the log
+	tail          []WALRecord
+	checkpointSeq uint64 // Last sequence number of the last checkpoint
+}
+
+// maxWALTail bounds the records kept in memory for replication; standbys
+// further behind receive a fresh checkpoint instead
+const maxWALTail = 10000
+
+// EnableWAL restores workloads recorded in the log at config.Path and logs
+// every later workload change there. GPUs must be registered first; recovered
+// workloads whose GPU is missing or taken are queued again. It returns the
+// number of workloads recovered.
+func (s *Scheduler) EnableWAL(config WALConfig) (int, error) {
+	if config.Path == "" {
+		return 0, fmt.Errorf("WAL path is required")
+	}
+
+	records, err := readWAL(config.Path)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.wal != nil {
+		return 0, fmt.Errorf("WAL already enabled at %s", s.wal.config.Path)
+	}
+	recovered := s.replayWAL(records)
+
+	s.wal = &writeAheadLog{config: config}
+	if len(records) > 0 {
+		s.wal.seq = records[len(records)-1].Seq
+	}
+	// Start from a checkpoint of the recovered state, dropping finished workloads and any torn final record
+	if err := s.compactWAL(); err != nil {
+		s.wal = nil
+		return 0, err
+	}
+	s.wake()
+	return recovered, nil
+}
+
+// CloseWAL stops logging workload changes
+func (s *Scheduler) CloseWAL() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.wal == nil {
+		return nil
+	}
+	err := s.wal.file.Close()
+	s.wal = nil
+	return err
+}
+
+// CheckWAL reports the error from the most recent WAL write, if it failed
+func (s *Scheduler) CheckWAL() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.walErr != nil {
+		return fmt.Errorf("scheduler WAL write failed: %v", s.walErr)
+	}
+	return nil
+}
+
+// logWorkload appends a workload change to the WAL, if enabled; callers must hold the lock
+func (s *Scheduler) logWorkload(op string, workload *Workload, colocated bool) {
+	if s.wal == nil {
+		return
+	}
+
+	s.wal.seq++
+	record := WALRecord{Seq: s.wal.seq, Op: op, Workload: *workload, Colocated: colocated, Timestamp: time.Now()}
+	s.walErr = s.wal.append(record)
+	s.wal.tail = append(s.wal.tail, record)
+	if len(s.wal.tail) > maxWALTail {
+		s.wal.tail = s.wal.tail[len(s.wal.tail)-maxWALTail:]
+	}
+}
+
+// compactWALIfDue checkpoints the log once CompactEvery records follow the last checkpoint.
+// Callers must hold the lock and must not be mid-pass, when the queue is split.
+func (s *Scheduler) compactWALIfDue() {
+	if s.wal == nil || s.wal.config.CompactEvery <= 0 || s.wal.records < s.wal.config.CompactEvery {
+		return
+	}
+	s.walErr = s.compactWAL()
+}
+
+// checkpointRecords describes every queued or placed workload, numbering
+// the records with seq; callers must hold the lock
+func (s *Scheduler) checkpointRecords(seq func() uint64) []WALRecord {
+	var records []WALRecord
+	now := time.Now()
+	for _, gpu := range s.gpus {
+		if gpu.CurrentWorkload != nil {
+			records = append(records, WALRecord{Seq: seq(), Op: walCheckpoint, Workload: *gpu.CurrentWorkload, Timestamp: now})
+		}
+		if gpu.ColocatedWorkload != nil {
+			records = append(records, WALRecord{Seq: seq(), Op: walCheckpoint, Workload: *gpu.ColocatedWorkload, Colocated: true, Timestamp: now})
+		}
+	}
+	for _, workload := range s.workloadQueue {
+		records = append(records, WALRecord{Seq: seq(), Op: walCheckpoint, Workload: *workload, Timestamp: now})
+	}
+	return records
+}
+
+// compactWAL atomically replaces the log with one checkpoint record per
+// queued or placed workload; callers must hold the lock
+func (s *Scheduler) compactWAL() error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	records := s.checkpointRecords(func() uint64 {
+		s.wal.seq++
+		return s.wal.seq
+	})
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	path := s.wal.config.Path
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create WAL checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write WAL checkpoint: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync WAL checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close WAL checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace WAL: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open WAL: %w", err)
+	}
+	if s.wal.file != nil {
+		s.wal.file.Close()
+	}
+	s.wal.file = file
+	s.wal.records = 0
+	s.wal.tail = nil
+	s.wal.checkpointSeq = s.wal.seq
+	return nil
+}
+
+// WALRecordsSince returns the WAL records after sequence number since, for
+// a standby keeping a copy of the log, and the sequence number it is now at.
+// When the records since are no longer kept, reset is set and the records
+// are a checkpoint of the current state that replaces the copy.
+func (s *Scheduler) WALRecordsSince(since uint64) (records []WALRecord, seq uint64, reset bool, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.wal == nil {
+		return nil, 0, false, fmt.Errorf("WAL not enabled")
+	}
+	seq = s.wal.seq
+	tail := s.wal.tail
+	if since >= s.wal.checkpointSeq && since <= seq && (len(tail) == 0 || since+1 >= tail[0].Seq) {
+		for _, record := range tail {
+			if record.Seq > since {
+				records = append(records, record)
+			}
+		}
+		return records, seq, false, nil
+	}
+	return s.checkpointRecords(func() uint64 { return seq }), seq, true, nil
+}
+
+// WriteWALRecords appends replicated records to the log at path, or replaces
+// it with them when reset is set. A scheduler enabling the WAL at path
+// recovers the replicated workloads.
+func WriteWALRecords(path string, records []WALRecord, reset bool) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	if !reset {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open WAL: %w", err)
+		}
+		if _, err := file.Write(buf.Bytes()); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to append to WAL: %w", err)
+		}
+		if err := file.Sync(); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to sync WAL: %w", err)
+		}
+		return file.Close()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create WAL: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write WAL: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync WAL: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close WAL: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace WAL: %w", err)
+	}
+	return nil
+}
+
+// append writes a record, syncing it to disk when configured
+func (w *writeAheadLog) append(record WALRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := w.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	w.records++
+	if w.config.SyncWrites {
+		return w.file.Sync()
+	}
+	return nil
+}
+
+// readWAL decodes every complete record in the log. A final line left
+// half-written by a crash is ignored; corruption anywhere else is an error.
+func readWAL(path string) ([]WALRecord, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open WAL: %w", err)
+	}
+	defer file.Close()
+
+	records := make([]WALRecord, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var pending error
+	line := 0
+	for scanner.Scan() {
+		line++
+		if pending != nil {
+			return nil, pending
+		}
+		var record WALRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			pending = fmt.Errorf("corrupt WAL record at line %d: %v", line, err)
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read WAL: %w", err)
+	}
+	return records, nil
+}
+
+// replayWAL rebuilds the queue and placements from the latest record of each
+// workload; callers must hold the lock
+func (s *Scheduler) replayWAL(records []WALRecord) int {
+	latest := make(map[string]WALRecord)
+	for _, record := range records {
+		latest[record.Workload.ID] = record
+	}
+
+	live := make([]WALRecord, 0, len(latest))
+	for _, record := range latest {
+		if record.Op != walCompleted && record.Op != walFailed {
+			live = append(live, record)
+		}
+	}
+	// Primary workloads are placed before co-located ones, and the queue keeps its original order
+	sort.Slice(live, func(i, j int) bool {
+		if live[i].Colocated != live[j].Colocated {
+			return !live[i].Colocated
+		}
+		if !live[i].Workload.queuedSince().Equal(live[j].Workload.queuedSince()) {
+			return live[i].Workload.queuedSince().Before(live[j].Workload.queuedSince())
+		}
+		return live[i].Seq < live[j].Seq
+	})
+
+	for _, record := range live {
+		workload := record.Workload
+		gpu := s.gpus[workload.AssignedGPU]
+		placed := false
+		if workload.Status != WorkloadPending && gpu != nil {
+			if !record.Colocated && gpu.CurrentWorkload == nil {
+				gpu.CurrentWorkload = &workload
+				placed = true
+			} else if record.Colocated && gpu.CurrentWorkload != nil && gpu.ColocatedWorkload == nil {
+				gpu.ColocatedWorkload = &workload
+				placed = true
+			}
+		}
+
+		s.rememberSubmission(&workload)
+		if placed {
+			gpu.MemoryUsed += workload.MemoryRequired
+			continue
+		}
+		workload.Status = WorkloadPending
+		workload.AssignedGPU = ""
+		workload.StartedAt = nil
+		workload.PausedAt = nil
+		s.workloadQueue = append(s.workloadQueue, &workload)
+	}
+
+	s.examined = 0
+	return len(live)
+}
@@ -0,0 +1,185 @@
+package gpu
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newWALScheduler returns a scheduler with two GPUs logging to path
+func newWALScheduler(t *testing.T, config WALConfig) (*Scheduler, int) {
+	t.Helper()
+	schedulerConfig := DefaultSchedulerConfig()
+	schedulerConfig.Colocation.Enabled = true
+	scheduler := NewSchedulerWithConfig(StrategyLeastUtilized, schedulerConfig)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+	scheduler.RegisterGPU(&GPU{ID: "gpu-1", MemoryTotal: 16000, Available: true})
+
+	recovered, err := scheduler.EnableWAL(config)
+	if err != nil {
+		t.Fatalf("EnableWAL failed: %v", err)
+	}
+	return scheduler, recovered
+}
+
+// placement summarizes where each workload is
+func placement(scheduler *Scheduler) map[string]string {
+	scheduler.mu.RLock()
+	defer scheduler.mu.RUnlock()
+
+	placed := make(map[string]string)
+	for _, gpu := range scheduler.gpus {
+		if gpu.CurrentWorkload != nil {
+			placed[gpu.CurrentWorkload.ID] = gpu.ID + " " + string(gpu.CurrentWorkload.Status)
+		}
+		if gpu.ColocatedWorkload != nil {
+			placed[gpu.ColocatedWorkload.ID] = gpu.ID + " colocated " + string(gpu.ColocatedWorkload.Status)
+		}
+	}
+	for i, workload := range scheduler.workloadQueue {
+		placed[workload.ID] = "queued " + string(rune('0'+i))
+	}
+	return placed
+}
+
+func TestWALRecoversQueueAndPlacementsAfterCrash(t *testing.T) {
+	config := WALConfig{Path: filepath.Join(t.TempDir(), "scheduler.wal")}
+	scheduler, recovered := newWALScheduler(t, config)
+	if recovered != 0 {
+		t.Fatalf("Expected nothing to recover from a new log, got %d", recovered)
+	}
+
+	scheduler.SubmitWorkload(&Workload{ID: "serve", Class: WorkloadClassInference, MemoryRequired: 8000})
+	scheduler.SubmitWorkload(&Workload{ID: "done", MemoryRequired: 8000})
+	scheduler.Schedule()
+	scheduler.SubmitWorkload(&Workload{ID: "train", Class: WorkloadClassTraining, MemoryRequired: 4000})
+	scheduler.SubmitWorkload(&Workload{ID: "waiting-1", MemoryRequired: 12000})
+	scheduler.SubmitWorkload(&Workload{ID: "waiting-2", MemoryRequired: 12000})
+	scheduler.Schedule()
+	scheduler.CompleteWorkload("done")
+	scheduler.FailWorkload("missing")
+	scheduler.Schedule()
+	scheduler.UpdateGPUUtilization(scheduler.gpus["gpu-0"].ID, 90)
+	scheduler.UpdateGPUUtilization(scheduler.gpus["gpu-1"].ID, 90)
+
+	before := placement(scheduler)
+	if err := scheduler.CheckWAL(); err != nil {
+		t.Fatalf("Unexpected WAL error: %v", err)
+	}
+
+	// Simulate a crash: the old scheduler is abandoned without closing the log
+	restarted, recovered := newWALScheduler(t, config)
+	if recovered != 4 {
+		t.Errorf("Expected 4 live workloads recovered, got %d", recovered)
+	}
+	after := placement(restarted)
+	if len(after) != len(before) {
+		t.Fatalf("Expected %v, got %v", before, after)
+	}
+	for id, where := range before {
+		if after[id] != where {
+			t.Errorf("Expected %s at %q after recovery, got %q", id, where, after[id])
+		}
+	}
+	if used := restarted.gpus["gpu-0"].MemoryUsed + restarted.gpus["gpu-1"].MemoryUsed; used != 24000 {
+		t.Errorf("Expected 24000 MB allocated after recovery, got %d", used)
+	}
+}
+
+func TestWALRequeuesWorkloadsWhoseGPUIsGone(t *testing.T) {
+	config := WALConfig{Path: filepath.Join(t.TempDir(), "scheduler.wal")}
+	scheduler, _ := newWALScheduler(t, config)
+	scheduler.RegisterGPU(&GPU{ID: "gpu-2", MemoryTotal: 80000, Available: true})
+	scheduler.SubmitWorkload(&Workload{ID: "big", MemoryRequired: 40000})
+	scheduler.Schedule()
+	scheduler.CloseWAL()
+
+	restarted, _ := newWALScheduler(t, config)
+	if where := placement(restarted)["big"]; where != "queued 0" {
+		t.Errorf("Expected the workload queued again without its GPU, got %q", where)
+	}
+}
+
+func TestWALToleratesTornFinalRecordAndCompacts(t *testing.T) {
+	config := WALConfig{Path: filepath.Join(t.TempDir(), "scheduler.wal"), CompactEvery: 10}
+	scheduler, _ := newWALScheduler(t, config)
+	for i := 0; i < 20; i++ {
+		id := string(rune('a' + i))
+		scheduler.SubmitWorkload(&Workload{ID: id, MemoryRequired: 100000})
+	}
+	for i := 0; i < 10; i++ {
+		scheduler.SubmitWorkload(&Workload{ID: "small", MemoryRequired: 1000})
+		scheduler.Schedule()
+		scheduler.CompleteWorkload("small")
+	}
+
+	// 50 records were written, but only the 20 queued workloads are live
+	data, _ := os.ReadFile(config.Path)
+	if lines := bytes.Count(data, []byte("\n")); lines > 20+config.CompactEvery {
+		t.Errorf("Expected the log compacted to at most %d records, got %d", 20+config.CompactEvery, lines)
+	}
+
+	file, _ := os.OpenFile(config.Path, os.O_WRONLY|os.O_APPEND, 0o644)
+	file.WriteString(`{"seq":999,"op":"submitted","workload":{"ID":"torn"`)
+	file.Close()
+
+	restarted, recovered := newWALScheduler(t, config)
+	if recovered != 20 || len(restarted.workloadQueue) != 20 {
+		t.Errorf("Expected the 20 queued workloads recovered, got %d", recovered)
+	}
+	if restarted.workloadQueue[0].ID != "a" || restarted.workloadQueue[19].ID != "t" {
+		t.Errorf("Expected queue order preserved, got %s..%s", restarted.workloadQueue[0].ID, restarted.workloadQueue[19].ID)
+	}
+
+	os.WriteFile(config.Path, []byte("not json\n{}\n"), 0o644)
+	if _, err := NewScheduler(StrategyBestFit).EnableWAL(config); err == nil {
+		t.Error("Expected corruption before the final record to be rejected")
+	}
+}
+
+func TestWALReplicatesToStandbyCopy(t *testing.T) {
+	dir := t.TempDir()
+	primary, _ := newWALScheduler(t, WALConfig{Path: filepath.Join(dir, "primary.wal"), CompactEvery: 5})
+	standbyPath := filepath.Join(dir, "standby.wal")
+
+	primary.SubmitWorkload(&Workload{ID: "serve", MemoryRequired: 8000})
+	primary.SubmitWorkload(&Workload{ID: "train", MemoryRequired: 8000})
+	primary.Schedule()
+
+	records, seq, reset, err := primary.WALRecordsSince(0)
+	if err != nil || reset || len(records) != 4 || seq != 4 {
+		t.Fatalf("Expected 4 incremental records, got %d at %d (reset %v, %v)", len(records), seq, reset, err)
+	}
+	if err := WriteWALRecords(standbyPath, records, reset); err != nil {
+		t.Fatalf("WriteWALRecords failed: %v", err)
+	}
+
+	// Compaction drops the records the standby has not seen yet, so it gets a checkpoint
+	primary.SubmitWorkload(&Workload{ID: "waiting", MemoryRequired: 12000})
+	primary.SubmitWorkload(&Workload{ID: "done", MemoryRequired: 1000})
+	primary.CompleteWorkload("serve")
+	primary.SubmitWorkload(&Workload{ID: "compacting", MemoryRequired: 12000})
+	records, seq, reset, err = primary.WALRecordsSince(seq)
+	if err != nil || !reset {
+		t.Fatalf("Expected a checkpoint after compaction, got reset %v (%v)", reset, err)
+	}
+	if err := WriteWALRecords(standbyPath, records, reset); err != nil {
+		t.Fatalf("WriteWALRecords failed: %v", err)
+	}
+	if records, _, _, _ := primary.WALRecordsSince(seq); len(records) != 0 {
+		t.Errorf("Expected nothing new, got %d records", len(records))
+	}
+
+	standby, recovered := newWALScheduler(t, WALConfig{Path: standbyPath})
+	if want := placement(primary); recovered != len(want) {
+		t.Errorf("Expected %d workloads recovered from the standby copy, got %d", len(want), recovered)
+	}
+	if _, placed := placement(standby)["serve"]; placed {
+		t.Error("Expected the completed workload left out of the standby copy")
+	}
+
+	if _, _, _, err := NewScheduler(StrategyBestFit).WALRecordsSince(0); err == nil {
+		t.Error("Expected an error without a WAL")
+	}
+}
@@ -0,0 +1,229 @@
+package gpu
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Watchdog event kinds
+const (
+	WatchdogStalled       = "stalled"
+	WatchdogRestarted     = "restarted"
+	WatchdogRestartFailed = "restart_failed"
+	WatchdogRecovered     = "recovered"
+)
+
+// WatchedLoop is a periodic loop the watchdog can supervise, such as
+// MetricsCollector or MetricsAggregationService
+type WatchedLoop interface {
+	LastCycle() time.Time
+	CycleInterval() time.Duration
+	Restart() error
+}
+
+// WatchdogConfig controls how long a loop may go without completing a cycle
+type WatchdogConfig struct {
+	CheckInterval time.Duration `yaml:"check_interval" json:"check_interval"`
+
+	// A loop is stalled when its last cycle completed more than StallFactor
+	// cycle intervals ago, e.g. because nvidia-smi hung
+	StallFactor float64 `yaml:"stall_factor" json:"stall_factor"`
+
+	// Restart stalled loops with a fresh context, abandoning the hung cycle
+	AutoRestart bool `yaml:"auto_restart" json:"auto_restart"`
+}
+
+// DefaultWatchdogConfig returns the default watchdog configuration
+func DefaultWatchdogConfig() WatchdogConfig {
+	return WatchdogConfig{
+		CheckInterval: 10 * time.Second,
+		StallFactor:   3,
+		AutoRestart:   true,
+	}
+}
+
+// WatchdogEvent reports a stalled loop or what the watchdog did about it
+type WatchdogEvent struct {
+	Loop      string        `json:"loop"`
+	Kind      string        `json:"kind"`
+	LastCycle time.Time     `json:"last_cycle"`
+	Stalled   time.Duration `json:"stalled"` // Time since the last completed cycle
+	Error     string        `json:"error,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// watchedLoop is a supervised loop and its stall state
+type watchedLoop struct {
+	loop     WatchedLoop
+	stalled  bool
+	stalls   int
+	restarts int
+}
+
+// Watchdog detects collection and aggregation loops that stopped completing
+// cycles and optionally restarts them
+type Watchdog struct {
+	config    WatchdogConfig
+	loops     map[string]*watchedLoop
+	listeners []func(WatchdogEvent)
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+	mu     sync.Mutex
+}
+
+// NewWatchdog creates a watchdog; zero config fields use the defaults
+func NewWatchdog(config WatchdogConfig) *Watchdog {
+	defaults := DefaultWatchdogConfig()
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = defaults.CheckInterval
+	}
+	if config.StallFactor <= 0 {
+		config.StallFactor = defaults.StallFactor
+	}
+	return &Watchdog{
+		config: config,
+		loops:  make(map[string]*watchedLoop),
+	}
+}
+
+// Watch supervises a loop under a name
+func (w *Watchdog) Watch(name string, loop WatchedLoop) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.loops[name] = &watchedLoop{loop: loop}
+}
+
+// OnEvent registers a listener for watchdog events, e.g. one recording a
+// critical event in the monitoring service
+func (w *Watchdog) OnEvent(listener func(WatchdogEvent)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.listeners = append(w.listeners, listener)
+}
+
+// Check inspects every loop once, restarting stalled loops when configured,
+// and returns the resulting events. A stalled loop is reported once until it
+// completes a cycle again.
+func (w *Watchdog) Check(now time.Time) []WatchdogEvent {
+	w.mu.Lock()
+	names := make([]string, 0, len(w.loops))
+	for name := range w.loops {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var events []WatchdogEvent
+	for _, name := range names {
+		watched := w.loops[name]
+		lastCycle := watched.loop.LastCycle()
+		since := now.Sub(lastCycle)
+		limit := time.Duration(w.config.StallFactor * float64(watched.loop.CycleInterval()))
+
+		if since <= limit {
+			if watched.stalled {
+				watched.stalled = false
+				events = append(events, WatchdogEvent{Loop: name, Kind: WatchdogRecovered, LastCycle: lastCycle, Timestamp: now})
+			}
+			continue
+		}
+		if watched.stalled {
+			continue
+		}
+		watched.stalled = true
+		watched.stalls++
+		events = append(events, WatchdogEvent{Loop: name, Kind: WatchdogStalled, LastCycle: lastCycle, Stalled: since, Timestamp: now})
+
+		if !w.config.AutoRestart {
+			continue
+		}
+		if err := watched.loop.Restart(); err != nil {
+			events = append(events, WatchdogEvent{Loop: name, Kind: WatchdogRestartFailed, LastCycle: lastCycle, Stalled: since, Error: err.Error(), Timestamp: now})
+			continue
+		}
+		watched.restarts++
+		events = append(events, WatchdogEvent{Loop: name, Kind: WatchdogRestarted, LastCycle: lastCycle, Stalled: since, Timestamp: now})
+	}
+	listeners := append([]func(WatchdogEvent){}, w.listeners...)
+	w.mu.Unlock()
+
+	for _, event := range events {
+		if event.Kind == WatchdogStalled {
+			log.Printf("Watchdog: %s loop has not completed a cycle in %v", event.Loop, event.Stalled.Round(time.Second))
+		}
+		for _, listener := range listeners {
+			listener(event)
+		}
+	}
+	return events
+}
+
+// Start checks the loops every CheckInterval until stopped
+func (w *Watchdog) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stopCh != nil {
+		return
+	}
+	w.stopCh = make(chan struct{})
+	w.doneCh = make(chan struct{})
+	go w.run(w.stopCh, w.doneCh)
+}
+
+// Stop halts the watchdog
+func (w *Watchdog) Stop() {
+	w.mu.Lock()
+	stopCh, doneCh := w.stopCh, w.doneCh
+	w.stopCh, w.doneCh = nil, nil
+	w.mu.Unlock()
+
+	if stopCh == nil {
+		return
+	}
+	close(stopCh)
+	<-doneCh
+}
+
+// run checks until stopped
+func (w *Watchdog) run(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(w.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			w.Check(now)
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// GetStats returns watchdog statistics per loop
+func (w *Watchdog) GetStats() map[string]interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	loops := make(map[string]interface{}, len(w.loops))
+	for name, watched := range w.loops {
+		loops[name] = map[string]interface{}{
+			"stalled":    watched.stalled,
+			"stalls":     watched.stalls,
+			"restarts":   watched.restarts,
+			"last_cycle": watched.loop.LastCycle(),
+			"interval":   watched.loop.CycleInterval().String(),
+		}
+	}
+	return map[string]interface{}{
+		"running":      w.stopCh != nil,
+		"stall_factor": fmt.Sprintf("%gx", w.config.StallFactor),
+		"auto_restart": w.config.AutoRestart,
+		"loops":        loops,
+	}
+}
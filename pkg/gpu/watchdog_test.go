@@ -0,0 +1,132 @@
+package gpu
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// fakeLoop is a loop whose cycles the test controls
+type fakeLoop struct {
+	last       time.Time
+	interval   time.Duration
+	restarts   int
+	restartErr error
+}
+
+func (f *fakeLoop) LastCycle() time.Time         { return f.last }
+func (f *fakeLoop) CycleInterval() time.Duration { return f.interval }
+
+func (f *fakeLoop) Restart() error {
+	if f.restartErr != nil {
+		return f.restartErr
+	}
+	f.restarts++
+	f.last = time.Now()
+	return nil
+}
+
+func eventKinds(events []WatchdogEvent) []string {
+	kinds := make([]string, len(events))
+	for i, event := range events {
+		kinds[i] = event.Loop + ":" + event.Kind
+	}
+	return kinds
+}
+
+func TestWatchdogRestartsStalledLoop(t *testing.T) {
+	now := time.Now()
+	loop := &fakeLoop{last: now.Add(-10 * time.Second), interval: 5 * time.Second}
+
+	wd := NewWatchdog(WatchdogConfig{StallFactor: 3, AutoRestart: true})
+	wd.Watch("collector", loop)
+	var heard []WatchdogEvent
+	wd.OnEvent(func(event WatchdogEvent) { heard = append(heard, event) })
+
+	if events := wd.Check(now); len(events) != 0 {
+		t.Fatalf("loop within 3x its interval reported %v", eventKinds(events))
+	}
+
+	loop.last = now.Add(-20 * time.Second)
+	events := wd.Check(now)
+	if got := fmt.Sprint(eventKinds(events)); got != "[collector:stalled collector:restarted]" {
+		t.Fatalf("stalled loop events = %s", got)
+	}
+	if events[0].Stalled != 20*time.Second {
+		t.Errorf("stalled for %v, want 20s", events[0].Stalled)
+	}
+	if loop.restarts != 1 || len(heard) != 2 {
+		t.Errorf("restarts = %d, listener heard %d events", loop.restarts, len(heard))
+	}
+
+	// The restarted loop completes cycles again
+	if got := fmt.Sprint(eventKinds(wd.Check(time.Now()))); got != "[collector:recovered]" {
+		t.Errorf("events after restart = %s", got)
+	}
+}
+
+func TestWatchdogReportsStallOnce(t *testing.T) {
+	now := time.Now()
+	loop := &fakeLoop{last: now.Add(-time.Minute), interval: time.Second, restartErr: fmt.Errorf("not running")}
+
+	wd := NewWatchdog(WatchdogConfig{AutoRestart: true})
+	wd.Watch("aggregation", loop)
+
+	events := wd.Check(now)
+	if got := fmt.Sprint(eventKinds(events)); got != "[aggregation:stalled aggregation:restart_failed]" {
+		t.Fatalf("events = %s", got)
+	}
+	if events[1].Error != "not running" {
+		t.Errorf("restart error = %q", events[1].Error)
+	}
+	if events := wd.Check(now.Add(time.Second)); len(events) != 0 {
+		t.Errorf("still-stalled loop reported again: %v", eventKinds(events))
+	}
+
+	stats := wd.GetStats()["loops"].(map[string]interface{})["aggregation"].(map[string]interface{})
+	if stats["stalls"] != 1 || stats["restarts"] != 0 || stats["stalled"] != true {
+		t.Errorf("stats = %v", stats)
+	}
+}
+
+func TestWatchdogWithoutAutoRestart(t *testing.T) {
+	now := time.Now()
+	loop := &fakeLoop{last: now.Add(-time.Hour), interval: time.Second}
+
+	wd := NewWatchdog(WatchdogConfig{})
+	wd.Watch("collector", loop)
+
+	if got := fmt.Sprint(eventKinds(wd.Check(now))); got != "[collector:stalled]" {
+		t.Errorf("events = %s", got)
+	}
+	if loop.restarts != 0 {
+		t.Errorf("loop restarted without AutoRestart")
+	}
+}
+
+func TestMetricsCollectorRestart(t *testing.T) {
+	mc := NewMetricsCollector(time.Hour)
+	var _ WatchedLoop = mc
+	var _ WatchedLoop = NewMetricsAggregationService(mc, time.Hour, time.Hour)
+
+	if err := mc.Restart(); err == nil {
+		t.Error("restarting a stopped collector should fail")
+	}
+
+	mc.mu.Lock()
+	mc.running = true
+	mc.lastCycle = time.Now().Add(-time.Hour)
+	mc.mu.Unlock()
+	old := mc.ctx
+
+	if err := mc.Restart(); err != nil {
+		t.Fatalf("Restart failed: %v", err)
+	}
+	if old.Err() == nil {
+		t.Error("restart should cancel the stalled cycle's context")
+	}
+	if time.Since(mc.LastCycle()) > time.Minute {
+		t.Error("restart should reset the last cycle")
+	}
+	mc.Stop()
+}
@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
@@ -9,9 +10,6 @@ import (
 
 	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
 	"gopkg.in/yaml.v2"
-	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // GPUSchedulerCLI provides command-line interface for the Kubernetes GPU scheduler
@@ -61,6 +59,12 @@ func (cli *GPUSchedulerCLI) ExecuteCommand(args []string) error {
 		return cli.watchStatus()
 	case "health":
 		return cli.showHealthStatus()
+	case "templates":
+		return cli.listTemplates()
+	case "generate-template":
+		return cli.generateTemplate(args[1:])
+	case "submit-template":
+		return cli.submitTemplate(args[1:])
 	case "help":
 		return cli.showHelp()
 	default:
@@ -82,8 +86,19 @@ COMMANDS:
   strategy [name]      Show or set scheduling strategy
   watch                Watch status updates in real-time
   health               Show GPU health status
+  templates            List workload template presets
+  generate-template [preset] [-o file]
+                       Write a preset's workload YAML (default: training)
+  submit-template <preset>
+                       Submit a preset directly without a YAML file
   help                 Show this help message
 
+TEMPLATE FLAGS:
+  -name <name>         Workload name
+  -gpus <n>            Number of GPUs
+  -memory <mb>         GPU memory per GPU in MB
+  -priority <0-10>     Workload priority
+
 SCHEDULING STRATEGIES:
   least_utilized       Schedule on least utilized GPUs (default)
   best_fit            Schedule on GPU with just enough free memory
@@ -95,6 +110,8 @@ EXAMPLES:
   agentaflow-k8s submit workload.yaml
   agentaflow-k8s strategy least_utilized
   agentaflow-k8s complete training-job-1
+  agentaflow-k8s generate-template llm-finetune-70b -gpus 4 -o finetune.yaml
+  agentaflow-k8s submit-template whisper-batch -priority 4
 `
 	fmt.Print(help)
 	return nil
@@ -230,6 +247,9 @@ func (cli *GPUSchedulerCLI) submitWorkload(filename string) error {
 	if workload.Spec.GPURequirements.GPUCount == 0 {
 		workload.Spec.GPURequirements.GPUCount = 1
 	}
+	if err := ValidateGPUWorkload(&workload); err != nil {
+		return fmt.Errorf("invalid workload: %v", err)
+	}
 
 	err = cli.scheduler.SubmitGPUWorkload(&workload)
 	if err != nil {
@@ -373,50 +393,16 @@ func (cli *GPUSchedulerCLI) showHealthStatus() error {
 	return nil
 }
 
-// GenerateWorkloadTemplate generates a sample workload YAML file
+// GenerateWorkloadTemplate writes the default preset's workload YAML to a file
 func (cli *GPUSchedulerCLI) GenerateWorkloadTemplate(filename string) error {
-	template := &GPUWorkload{
-		TypeMeta: metav1.TypeMeta{
-			APIVersion: "agentaflow.io/v1",
-			Kind:       "GPUWorkload",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "example-training-job",
-		},
-		Spec: GPUWorkloadSpec{
-			Priority:          1,
-			GPUMemoryRequired: 8192, // 8GB
-			EstimatedDuration: &metav1.Duration{Duration: 2 * time.Hour},
-			GPURequirements: GPURequirements{
-				MinGPUMemory:    8192,
-				GPUCount:        1,
-				ExclusiveAccess: true,
-			},
-			SchedulingStrategy: "least_utilized",
-			PodTemplate: v1.PodTemplateSpec{
-				Spec: v1.PodSpec{
-					Containers: []v1.Container{
-						{
-							Name:  "training-container",
-							Image: "tensorflow/tensorflow:latest-gpu",
-							Command: []string{
-								"python",
-								"/app/train.py",
-							},
-							Resources: v1.ResourceRequirements{
-								Requests: v1.ResourceList{
-									"nvidia.com/gpu": *resource.NewQuantity(1, resource.DecimalSI),
-								},
-								Limits: v1.ResourceList{
-									"nvidia.com/gpu": *resource.NewQuantity(1, resource.DecimalSI),
-								},
-							},
-						},
-					},
-					RestartPolicy: v1.RestartPolicyNever,
-				},
-			},
-		},
+	return cli.writeWorkloadTemplate(DefaultPreset, TemplateParams{}, filename)
+}
+
+// writeWorkloadTemplate renders a preset and writes its YAML to a file
+func (cli *GPUSchedulerCLI) writeWorkloadTemplate(preset string, params TemplateParams, filename string) error {
+	template, err := RenderWorkloadTemplate(preset, params)
+	if err != nil {
+		return err
 	}
 
 	data, err := yaml.Marshal(template)
@@ -432,3 +418,97 @@ func (cli *GPUSchedulerCLI) GenerateWorkloadTemplate(filename string) error {
 	fmt.Printf("Workload template written to %s\n", filename)
 	return nil
 }
+
+// listTemplates displays the workload preset library
+func (cli *GPUSchedulerCLI) listTemplates() error {
+	fmt.Println("=== Workload Templates ===")
+	fmt.Printf("%-20s %-5s %-10s %-9s %s\n", "PRESET", "GPUS", "MEMORY", "PRIORITY", "DESCRIPTION")
+	for _, preset := range WorkloadPresets() {
+		fmt.Printf("%-20s %-5d %-10s %-9d %s\n", preset.Name, preset.GPUCount,
+			fmt.Sprintf("%d MB", preset.MemoryMB), preset.Priority, preset.Description)
+	}
+	return nil
+}
+
+// generateTemplate handles "generate-template [preset] [flags]". A first
+// argument that is not a preset is taken as the output file, as before
+// presets existed.
+func (cli *GPUSchedulerCLI) generateTemplate(args []string) error {
+	preset := DefaultPreset
+	filename := "workload-template.yaml"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		if _, exists := workloadPresets[args[0]]; exists {
+			preset = args[0]
+		} else {
+			filename = args[0]
+		}
+		args = args[1:]
+	}
+
+	flags, params := templateFlags("generate-template")
+	output := flags.String("o", filename, "output file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	return cli.writeWorkloadTemplate(preset, params.resolve(flags), *output)
+}
+
+// submitTemplate renders a preset and submits it without an intermediate file
+func (cli *GPUSchedulerCLI) submitTemplate(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("submit-template command requires a preset name")
+	}
+
+	flags, params := templateFlags("submit-template")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	workload, err := RenderWorkloadTemplate(args[0], params.resolve(flags))
+	if err != nil {
+		return err
+	}
+
+	err = cli.scheduler.SubmitGPUWorkload(workload)
+	if err != nil {
+		return fmt.Errorf("failed to submit workload: %v", err)
+	}
+
+	fmt.Printf("Workload '%s' submitted successfully from preset %s\n", workload.Name, args[0])
+	return nil
+}
+
+// templateFlagValues holds the parameter flags shared by template commands
+type templateFlagValues struct {
+	name     *string
+	gpus     *int
+	memory   *int64
+	priority *int
+}
+
+// templateFlags defines the parameter flags of a template command
+func templateFlags(command string) (*flag.FlagSet, templateFlagValues) {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	return flags, templateFlagValues{
+		name:     flags.String("name", "", "workload name"),
+		gpus:     flags.Int("gpus", 0, "number of GPUs"),
+		memory:   flags.Int64("memory", 0, "GPU memory per GPU in MB"),
+		priority: flags.Int("priority", 0, "workload priority (0-10)"),
+	}
+}
+
+// resolve converts parsed flags into template parameters; priority only
+// overrides the preset when given, since 0 is a valid priority
+func (values templateFlagValues) resolve(flags *flag.FlagSet) TemplateParams {
+	params := TemplateParams{
+		Name:     *values.name,
+		GPUCount: int32(*values.gpus),
+		MemoryMB: *values.memory,
+	}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "priority" {
+			priority := int32(*values.priority)
+			params.Priority = &priority
+		}
+	})
+	return params
+}
@@ -9,8 +9,11 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
@@ -39,6 +42,11 @@ type GPUMonitor struct {
 	namespace string
 	stopCh    chan struct{}
 	logger    *log.Logger
+	runner    *gpu.CommandRunner // Limits nvidia-smi invocations
+
+	// Kubernetes API failures by reason, for self-monitoring
+	apiErrors map[string]int64
+	mu        sync.Mutex
 }
 
 // NewGPUMonitor creates a new GPU monitor for a node
@@ -52,9 +60,36 @@ func NewGPUMonitor(clientset kubernetes.Interface, nodeName, namespace string) *
 		namespace: namespace,
 		stopCh:    make(chan struct{}),
 		logger:    logger,
+		runner:    gpu.DefaultCommandRunner(),
+		apiErrors: make(map[string]int64),
 	}
 }
 
+// SetCommandRunner replaces the shared runner that limits nvidia-smi invocations
+func (gm *GPUMonitor) SetCommandRunner(runner *gpu.CommandRunner) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	gm.runner = runner
+}
+
+// nvidiaSMI runs nvidia-smi at a validated path with a fixed environment to
+// prevent injection. Repeated status polls share the runner's cached output.
+func (gm *GPUMonitor) nvidiaSMI(path string, args ...string) ([]byte, error) {
+	gm.mu.Lock()
+	runner := gm.runner
+	gm.mu.Unlock()
+
+	return runner.Run(context.Background(), gpu.CommandSpec{
+		Name: path,
+		Args: args,
+		Env: []string{
+			"PATH=/usr/bin:/bin:/usr/local/bin",
+			"LC_ALL=C",
+		},
+		Cacheable: true,
+	})
+}
+
 // Start begins monitoring GPU resources on this node
 func (gm *GPUMonitor) Start(ctx context.Context) error {
 	gm.logger.Printf("INFO: Starting GPU monitor for node %s", gm.nodeName)
@@ -80,6 +115,31 @@ func (gm *GPUMonitor) Stop() {
 	close(gm.stopCh)
 }
 
+// recordAPIError counts a Kubernetes API failure by its status reason, such
+// as Forbidden, Conflict or Timeout
+func (gm *GPUMonitor) recordAPIError(err error) {
+	reason := string(apierrors.ReasonForError(err))
+	if reason == "" {
+		reason = "Unknown"
+	}
+
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	gm.apiErrors[reason]++
+}
+
+// GetAPIErrors returns the total Kubernetes API failures by reason
+func (gm *GPUMonitor) GetAPIErrors() map[string]int64 {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	errs := make(map[string]int64, len(gm.apiErrors))
+	for reason, count := range gm.apiErrors {
+		errs[reason] = count
+	}
+	return errs
+}
+
 // initializeNode discovers and registers GPU devices on this node
 func (gm *GPUMonitor) initializeNode() error {
 	gpuDevices, err := gm.discoverGPUDevices()
@@ -112,17 +172,9 @@ func (gm *GPUMonitor) discoverGPUDevices() ([]GPUDevice, error) {
 	}
 
 	// Query GPU information using nvidia-smi with validated path
-	cmd := exec.Command(nvidiaSmiPath,
+	output, err := gm.nvidiaSMI(nvidiaSmiPath,
 		"--query-gpu=index,name,memory.total,pci.bus_id,driver_version",
 		"--format=csv,noheader,nounits")
-
-	// Set environment variables to prevent injection
-	cmd.Env = []string{
-		"PATH=/usr/bin:/bin:/usr/local/bin",
-		"LC_ALL=C",
-	}
-
-	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("nvidia-smi command failed: %v", err)
 	}
@@ -170,6 +222,7 @@ func (gm *GPUMonitor) parseNvidiaSmiOutput(output string) ([]GPUDevice, error) {
 func (gm *GPUMonitor) updateNodeAnnotations(devices []GPUDevice) error {
 	node, err := gm.clientset.CoreV1().Nodes().Get(context.TODO(), gm.nodeName, metav1.GetOptions{})
 	if err != nil {
+		gm.recordAPIError(err)
 		return fmt.Errorf("failed to get node: %v", err)
 	}
 
@@ -197,16 +250,20 @@ func (gm *GPUMonitor) updateNodeAnnotations(devices []GPUDevice) error {
 	// Update the node
 	_, err = gm.clientset.CoreV1().Nodes().Update(context.TODO(), node, metav1.UpdateOptions{})
 	if err != nil {
+		gm.recordAPIError(err)
 		return fmt.Errorf("failed to update node: %v", err)
 	}
 
 	return nil
 }
 
-// monitoringLoop continuously monitors GPU status
+// monitoringLoop continuously monitors GPU status, jittering the interval so
+// that node monitors started together do not poll in lockstep
 func (gm *GPUMonitor) monitoringLoop(ctx context.Context) {
-	ticker := time.NewTicker(15 * time.Second)
-	defer ticker.Stop()
+	const interval = 15 * time.Second
+	jitter := gpu.NewJitter(gm.nodeName, gpu.DefaultJitter)
+	timer := time.NewTimer(jitter.Next(interval))
+	defer timer.Stop()
 
 	for {
 		select {
@@ -214,8 +271,9 @@ func (gm *GPUMonitor) monitoringLoop(ctx context.Context) {
 			return
 		case <-gm.stopCh:
 			return
-		case <-ticker.C:
+		case <-timer.C:
 			gm.updateGPUStatus()
+			timer.Reset(jitter.Next(interval))
 		}
 	}
 }
@@ -243,17 +301,9 @@ func (gm *GPUMonitor) getGPUStatuses() ([]GPUStatus, error) {
 	}
 
 	// Query current GPU status
-	cmd := exec.Command(nvidiaSmiPath,
+	output, err := gm.nvidiaSMI(nvidiaSmiPath,
 		"--query-gpu=index,utilization.gpu,memory.used,memory.total,temperature.gpu,power.draw",
 		"--format=csv,noheader,nounits")
-
-	// Set secure environment
-	cmd.Env = []string{
-		"PATH=/usr/bin:/bin:/usr/local/bin",
-		"LC_ALL=C",
-	}
-
-	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("nvidia-smi status query failed: %v", err)
 	}
@@ -312,6 +362,7 @@ func (gm *GPUMonitor) findWorkloadUsingGPU(gpuID string) string {
 		LabelSelector: "agentaflow.gpu/managed=true",
 	})
 	if err != nil {
+		gm.recordAPIError(err)
 		return ""
 	}
 
@@ -330,6 +381,7 @@ func (gm *GPUMonitor) findWorkloadUsingGPU(gpuID string) string {
 func (gm *GPUMonitor) updateNodeStatus(statuses []GPUStatus) error {
 	node, err := gm.clientset.CoreV1().Nodes().Get(context.TODO(), gm.nodeName, metav1.GetOptions{})
 	if err != nil {
+		gm.recordAPIError(err)
 		return fmt.Errorf("failed to get node: %v", err)
 	}
 
@@ -387,6 +439,7 @@ func (gm *GPUMonitor) updateNodeStatus(statuses []GPUStatus) error {
 	// Update the node
 	_, err = gm.clientset.CoreV1().Nodes().Update(context.TODO(), node, metav1.UpdateOptions{})
 	if err != nil {
+		gm.recordAPIError(err)
 		return fmt.Errorf("failed to update node: %v", err)
 	}
 
@@ -202,8 +202,12 @@ func (ks *KubernetesGPUScheduler) SubmitGPUWorkload(workload *GPUWorkload) error
 	internalWorkload := &gpu.Workload{
 		ID:             workload.ObjectMeta.Name,
 		Name:           workload.ObjectMeta.Name,
+		Tenant:         workload.ObjectMeta.Namespace, // Namespaces are tenants
 		Priority:       int(workload.Spec.Priority),
 		MemoryRequired: uint64(workload.Spec.GPUMemoryRequired),
+
+		EncoderSessions: int(workload.Spec.GPURequirements.EncoderSessions),
+		DecoderSessions: int(workload.Spec.GPURequirements.DecoderSessions),
 	}
 
 	if workload.Spec.EstimatedDuration != nil {
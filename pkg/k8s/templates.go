@@ -0,0 +1,209 @@
+package k8s
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultPreset is the preset used when no preset is named
+const DefaultPreset = "training"
+
+// WorkloadPreset is a named GPUWorkload template for a common job type
+type WorkloadPreset struct {
+	Name              string
+	Description       string
+	WorkloadName      string // Default metadata name
+	Image             string
+	Command           []string
+	GPUCount          int32
+	MemoryMB          int64 // Per GPU
+	Priority          int32
+	EstimatedDuration time.Duration // Zero for services without an end
+	Strategy          string
+	ExclusiveAccess   bool
+}
+
+// TemplateParams overrides a preset's defaults; zero fields and a nil
+// Priority keep them
+type TemplateParams struct {
+	Name     string
+	GPUCount int32
+	MemoryMB int64
+	Priority *int32
+}
+
+// workloadPresets is the built-in template library
+var workloadPresets = map[string]WorkloadPreset{
+	"training": {
+		Name:              "training",
+		Description:       "Single-GPU training job",
+		WorkloadName:      "example-training-job",
+		Image:             "tensorflow/tensorflow:latest-gpu",
+		Command:           []string{"python", "/app/train.py"},
+		GPUCount:          1,
+		MemoryMB:          8192,
+		Priority:          1,
+		EstimatedDuration: 2 * time.Hour,
+		Strategy:          "least_utilized",
+		ExclusiveAccess:   true,
+	},
+	"llm-finetune-70b": {
+		Name:              "llm-finetune-70b",
+		Description:       "70B-parameter LLM fine-tuning across 8 80GB GPUs",
+		WorkloadName:      "llm-finetune-70b",
+		Image:             "nvcr.io/nvidia/pytorch:24.01-py3",
+		Command:           []string{"torchrun", "--nproc_per_node=8", "/app/finetune.py"},
+		GPUCount:          8,
+		MemoryMB:          81920,
+		Priority:          5,
+		EstimatedDuration: 24 * time.Hour,
+		Strategy:          "best_fit",
+		ExclusiveAccess:   true,
+	},
+	"sd-inference": {
+		Name:            "sd-inference",
+		Description:     "Stable Diffusion inference server",
+		WorkloadName:    "sd-inference",
+		Image:           "pytorch/pytorch:latest",
+		Command:         []string{"python", "/app/serve.py"},
+		GPUCount:        1,
+		MemoryMB:        16384,
+		Priority:        7,
+		Strategy:        "least_utilized",
+		ExclusiveAccess: false,
+	},
+	"whisper-batch": {
+		Name:              "whisper-batch",
+		Description:       "Batch speech-to-text transcription with Whisper",
+		WorkloadName:      "whisper-batch",
+		Image:             "pytorch/pytorch:latest",
+		Command:           []string{"python", "/app/transcribe.py", "--batch"},
+		GPUCount:          1,
+		MemoryMB:          10240,
+		Priority:          2,
+		EstimatedDuration: 4 * time.Hour,
+		Strategy:          "best_fit",
+		ExclusiveAccess:   false,
+	},
+}
+
+// WorkloadPresets returns the template library ordered by name
+func WorkloadPresets() []WorkloadPreset {
+	presets := make([]WorkloadPreset, 0, len(workloadPresets))
+	for _, preset := range workloadPresets {
+		presets = append(presets, preset)
+	}
+	sort.Slice(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+	return presets
+}
+
+// RenderWorkloadTemplate builds a validated workload from a preset and parameters
+func RenderWorkloadTemplate(presetName string, params TemplateParams) (*GPUWorkload, error) {
+	preset, exists := workloadPresets[presetName]
+	if !exists {
+		return nil, fmt.Errorf("unknown workload preset %q", presetName)
+	}
+	if params.Name != "" {
+		preset.WorkloadName = params.Name
+	}
+	if params.GPUCount != 0 {
+		preset.GPUCount = params.GPUCount
+	}
+	if params.MemoryMB != 0 {
+		preset.MemoryMB = params.MemoryMB
+	}
+	if params.Priority != nil {
+		preset.Priority = *params.Priority
+	}
+
+	gpus := *resource.NewQuantity(int64(preset.GPUCount), resource.DecimalSI)
+	workload := &GPUWorkload{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "agentaflow.io/v1",
+			Kind:       "GPUWorkload",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   preset.WorkloadName,
+			Labels: map[string]string{"agentaflow.io/preset": preset.Name},
+		},
+		Spec: GPUWorkloadSpec{
+			Priority:          preset.Priority,
+			GPUMemoryRequired: preset.MemoryMB,
+			GPURequirements: GPURequirements{
+				MinGPUMemory:    preset.MemoryMB,
+				GPUCount:        preset.GPUCount,
+				ExclusiveAccess: preset.ExclusiveAccess,
+			},
+			SchedulingStrategy: preset.Strategy,
+			PodTemplate: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:    preset.Name + "-container",
+							Image:   preset.Image,
+							Command: append([]string{}, preset.Command...),
+							Resources: v1.ResourceRequirements{
+								Requests: v1.ResourceList{"nvidia.com/gpu": gpus},
+								Limits:   v1.ResourceList{"nvidia.com/gpu": gpus},
+							},
+						},
+					},
+					RestartPolicy: v1.RestartPolicyNever,
+				},
+			},
+		},
+	}
+	if preset.EstimatedDuration > 0 {
+		workload.Spec.EstimatedDuration = &metav1.Duration{Duration: preset.EstimatedDuration}
+	}
+
+	if err := ValidateGPUWorkload(workload); err != nil {
+		return nil, fmt.Errorf("invalid %s workload: %w", presetName, err)
+	}
+	return workload, nil
+}
+
+// workloadNamePattern matches Kubernetes object names (RFC 1123 labels)
+var workloadNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// ValidateGPUWorkload checks a workload before it is written or submitted
+func ValidateGPUWorkload(workload *GPUWorkload) error {
+	if len(workload.Name) > 63 || !workloadNamePattern.MatchString(workload.Name) {
+		return fmt.Errorf("name %q must be at most 63 lowercase letters, digits or '-'", workload.Name)
+	}
+	spec := workload.Spec
+	if spec.Priority < 0 || spec.Priority > 10 {
+		return fmt.Errorf("priority %d must be between 0 and 10", spec.Priority)
+	}
+	if spec.GPURequirements.GPUCount < 1 {
+		return fmt.Errorf("GPU count %d must be at least 1", spec.GPURequirements.GPUCount)
+	}
+	if spec.GPUMemoryRequired <= 0 {
+		return fmt.Errorf("GPU memory %d MB must be positive", spec.GPUMemoryRequired)
+	}
+	if spec.GPURequirements.MinGPUMemory > spec.GPUMemoryRequired {
+		return fmt.Errorf("minimum GPU memory %d MB exceeds the %d MB required", spec.GPURequirements.MinGPUMemory, spec.GPUMemoryRequired)
+	}
+	switch gpu.SchedulingStrategy(spec.SchedulingStrategy) {
+	case "", gpu.StrategyLeastUtilized, gpu.StrategyBestFit, gpu.StrategyPriority, gpu.StrategyRoundRobin, gpu.StrategyEnergyAware:
+	default:
+		return fmt.Errorf("unknown scheduling strategy %q", spec.SchedulingStrategy)
+	}
+	containers := spec.PodTemplate.Spec.Containers
+	if len(containers) == 0 {
+		return fmt.Errorf("pod template has no containers")
+	}
+	for _, container := range containers {
+		if container.Image == "" {
+			return fmt.Errorf("container %q has no image", container.Name)
+		}
+	}
+	return nil
+}
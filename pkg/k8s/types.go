@@ -51,6 +51,11 @@ type GPURequirements struct {
 
 	// Exclusive access requirement
 	ExclusiveAccess bool `json:"exclusiveAccess,omitempty"`
+
+	// NVENC/NVDEC sessions for video workloads, which are placed by
+	// available encoder and decoder sessions
+	EncoderSessions int32 `json:"encoderSessions,omitempty"`
+	DecoderSessions int32 `json:"decoderSessions,omitempty"`
 }
 
 // GPUWorkloadStatus defines the observed state of GPUWorkload
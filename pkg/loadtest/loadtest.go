@@ -0,0 +1,482 @@
+// Package loadtest replays recorded or synthetic inference traffic against the serving
+// stack and reports latency, throughput and GPU utilization correlation
+package loadtest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving"
+)
+
+// Target executes a single inference request; ServingManager.SubmitInferenceRequest satisfies it
+type Target func(req *serving.InferenceRequest) (*serving.InferenceResponse, error)
+
+// UtilizationSampler returns the current average GPU utilization percentage
+type UtilizationSampler func() (float64, bool)
+
+// TraceRecord is a single request of a recorded trace
+type TraceRecord struct {
+	Offset    time.Duration `json:"-"`
+	OffsetMs  float64       `json:"offset_ms"`
+	ModelID   string        `json:"model_id"`
+	Input     string        `json:"input"`
+	SessionID string        `json:"session_id,omitempty"`
+}
+
+// SyntheticConfig describes generated traffic when no trace is replayed
+type SyntheticConfig struct {
+	Models       []string // Models to send requests to, chosen uniformly
+	MinInputSize int      // Minimum input size in bytes
+	MaxInputSize int      // Maximum input size in bytes
+	Poisson      bool     // Exponential inter-arrival times instead of constant pacing
+	Seed         int64
+}
+
+// Config controls a load test run
+type Config struct {
+	QPS         float64       // Target requests per second; 0 replays trace offsets as recorded
+	Duration    time.Duration // Maximum run time
+	Concurrency int           // Maximum in-flight requests
+	Trace       []TraceRecord // Replayed when non-empty, otherwise Synthetic traffic is generated
+	Synthetic   SyntheticConfig
+	Target      Target
+	Sampler     UtilizationSampler // Optional GPU utilization source
+}
+
+// DefaultConfig returns a default load test configuration
+func DefaultConfig() Config {
+	return Config{
+		QPS:         50,
+		Duration:    30 * time.Second,
+		Concurrency: 64,
+		Synthetic: SyntheticConfig{
+			MinInputSize: 64,
+			MaxInputSize: 1024,
+			Seed:         1,
+		},
+	}
+}
+
+// SecondStats summarises one second of the run
+type SecondStats struct {
+	Second         int     `json:"second"`
+	Completed      int     `json:"completed"`
+	Errors         int     `json:"errors"`
+	MeanLatencyMs  float64 `json:"mean_latency_ms"`
+	GPUUtilization float64 `json:"gpu_utilization,omitempty"`
+}
+
+// Report is the result of a load test run
+type Report struct {
+	Requests       int           `json:"requests"`
+	Succeeded      int           `json:"succeeded"`
+	Failed         int           `json:"failed"`
+	CacheHits      int           `json:"cache_hits"`
+	Duration       time.Duration `json:"duration"`
+	ThroughputRPS  float64       `json:"throughput_rps"`
+	LatencyMeanMs  float64       `json:"latency_mean_ms"`
+	LatencyP50Ms   float64       `json:"latency_p50_ms"`
+	LatencyP90Ms   float64       `json:"latency_p90_ms"`
+	LatencyP95Ms   float64       `json:"latency_p95_ms"`
+	LatencyP99Ms   float64       `json:"latency_p99_ms"`
+	LatencyMaxMs   float64       `json:"latency_max_ms"`
+	AvgUtilization float64       `json:"avg_gpu_utilization"`
+	// Pearson correlation of per-second throughput and latency with GPU utilization
+	ThroughputUtilizationCorr float64       `json:"throughput_utilization_correlation"`
+	LatencyUtilizationCorr    float64       `json:"latency_utilization_correlation"`
+	Timeline                  []SecondStats `json:"timeline"`
+}
+
+// LoadTrace reads a JSON-lines trace file
+func LoadTrace(path string) ([]TraceRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace: %w", err)
+	}
+	defer file.Close()
+
+	records := make([]TraceRecord, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record TraceRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("invalid trace record on line %d: %w", line, err)
+		}
+		if record.ModelID == "" {
+			return nil, fmt.Errorf("trace record on line %d has no model_id", line)
+		}
+		record.Offset = time.Duration(record.OffsetMs * float64(time.Millisecond))
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read trace: %w", err)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Offset < records[j].Offset
+	})
+	return records, nil
+}
+
+// TraceFromRequestLog builds a replayable trace from sampled serving request logs
+func TraceFromRequestLog(entries []serving.RequestLogEntry) []TraceRecord {
+	records := make([]TraceRecord, 0, len(entries))
+	if len(entries) == 0 {
+		return records
+	}
+
+	start := entries[0].Timestamp
+	for _, entry := range entries {
+		if entry.Timestamp.Before(start) {
+			start = entry.Timestamp
+		}
+	}
+	for _, entry := range entries {
+		offset := entry.Timestamp.Sub(start)
+		records = append(records, TraceRecord{
+			Offset:   offset,
+			OffsetMs: float64(offset.Microseconds()) / 1000,
+			ModelID:  entry.ModelID,
+			Input:    entry.Input,
+		})
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Offset < records[j].Offset
+	})
+	return records
+}
+
+// CollectorSampler averages the latest GPU utilization reported by a metrics collector
+func CollectorSampler(collector gpu.MetricsCollectorInterface) UtilizationSampler {
+	return func() (float64, bool) {
+		metrics := collector.GetLatestMetrics()
+		if len(metrics) == 0 {
+			return 0, false
+		}
+		total := 0.0
+		for _, m := range metrics {
+			total += m.UtilizationGPU
+		}
+		return total / float64(len(metrics)), true
+	}
+}
+
+// result is the outcome of a single request
+type result struct {
+	second   int
+	latency  time.Duration
+	err      error
+	cacheHit bool
+}
+
+// Run executes a load test until the trace is exhausted, the duration elapses or ctx is cancelled
+func Run(ctx context.Context, config Config) (*Report, error) {
+	if config.Target == nil {
+		return nil, fmt.Errorf("load test target cannot be nil")
+	}
+	if config.Duration <= 0 {
+		return nil, fmt.Errorf("duration must be positive")
+	}
+	if len(config.Trace) == 0 {
+		if config.QPS <= 0 {
+			return nil, fmt.Errorf("QPS must be positive for synthetic traffic")
+		}
+		if len(config.Synthetic.Models) == 0 {
+			return nil, fmt.Errorf("synthetic traffic requires at least one model")
+		}
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 64
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.Duration)
+	defer cancel()
+
+	start := time.Now()
+	results := make(chan result, config.Concurrency)
+	semaphore := make(chan struct{}, config.Concurrency)
+
+	// Sample GPU utilization once per second
+	utilization := make(map[int]float64)
+	var utilMu sync.Mutex
+	samplerDone := make(chan struct{})
+	go func() {
+		defer close(samplerDone)
+		if config.Sampler == nil {
+			return
+		}
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if value, ok := config.Sampler(); ok {
+					utilMu.Lock()
+					utilization[int(now.Sub(start)/time.Second)-1] = value
+					utilMu.Unlock()
+				}
+			}
+		}
+	}()
+
+	// Collect results while requests are being sent
+	collected := make([]result, 0)
+	collectDone := make(chan struct{})
+	go func() {
+		defer close(collectDone)
+		for r := range results {
+			collected = append(collected, r)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	next := newScheduler(config)
+	for i := 0; ; i++ {
+		req, scheduledOffset, ok := next(i)
+		if !ok {
+			break
+		}
+
+		// Wait for the scheduled send time
+		if wait := time.Until(start.Add(scheduledOffset)); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+			case <-timer.C:
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(req *serving.InferenceRequest, scheduled time.Time) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			resp, err := config.Target(req)
+			// Latency is measured from the scheduled send time to avoid coordinated omission
+			r := result{second: int(scheduled.Sub(start) / time.Second), latency: time.Since(scheduled), err: err}
+			if err == nil && resp != nil {
+				r.cacheHit = resp.CacheHit
+			}
+			results <- r
+		}(req, start.Add(scheduledOffset))
+	}
+
+	wg.Wait()
+	elapsed := time.Since(start)
+	cancel()
+	close(results)
+	<-collectDone
+	<-samplerDone
+
+	return buildReport(collected, utilization, elapsed), nil
+}
+
+// newScheduler returns a generator of requests and their send offsets
+func newScheduler(config Config) func(i int) (*serving.InferenceRequest, time.Duration, bool) {
+	if len(config.Trace) > 0 {
+		return func(i int) (*serving.InferenceRequest, time.Duration, bool) {
+			if i >= len(config.Trace) {
+				return nil, 0, false
+			}
+			record := config.Trace[i]
+			offset := record.Offset
+			if config.QPS > 0 {
+				offset = time.Duration(float64(i) / config.QPS * float64(time.Second))
+			}
+			return &serving.InferenceRequest{
+				ID:        fmt.Sprintf("loadtest-%d", i),
+				ModelID:   record.ModelID,
+				Input:     []byte(record.Input),
+				SessionID: record.SessionID,
+			}, offset, true
+		}
+	}
+
+	synthetic := config.Synthetic
+	rng := rand.New(rand.NewSource(synthetic.Seed))
+	minSize, maxSize := synthetic.MinInputSize, synthetic.MaxInputSize
+	if minSize <= 0 {
+		minSize = 1
+	}
+	if maxSize < minSize {
+		maxSize = minSize
+	}
+	interval := float64(time.Second) / config.QPS
+	var offset time.Duration
+
+	return func(i int) (*serving.InferenceRequest, time.Duration, bool) {
+		if i > 0 {
+			if synthetic.Poisson {
+				offset += time.Duration(rng.ExpFloat64() * interval)
+			} else {
+				offset = time.Duration(float64(i) * interval)
+			}
+		}
+		if offset >= config.Duration {
+			return nil, 0, false
+		}
+
+		input := make([]byte, minSize+rng.Intn(maxSize-minSize+1))
+		for j := range input {
+			input[j] = byte('a' + rng.Intn(26))
+		}
+		return &serving.InferenceRequest{
+			ID:      fmt.Sprintf("loadtest-%d", i),
+			ModelID: synthetic.Models[rng.Intn(len(synthetic.Models))],
+			Input:   input,
+		}, offset, true
+	}
+}
+
+// buildReport aggregates request results into a report
+func buildReport(results []result, utilization map[int]float64, elapsed time.Duration) *Report {
+	report := &Report{Requests: len(results), Duration: elapsed}
+
+	latencies := make([]float64, 0, len(results))
+	seconds := make(map[int]*SecondStats)
+	latencySums := make(map[int]float64)
+	for _, r := range results {
+		stats, exists := seconds[r.second]
+		if !exists {
+			stats = &SecondStats{Second: r.second}
+			seconds[r.second] = stats
+		}
+
+		if r.err != nil {
+			report.Failed++
+			stats.Errors++
+			continue
+		}
+
+		ms := float64(r.latency.Microseconds()) / 1000
+		report.Succeeded++
+		if r.cacheHit {
+			report.CacheHits++
+		}
+		latencies = append(latencies, ms)
+		stats.Completed++
+		latencySums[r.second] += ms
+	}
+
+	if elapsed > 0 {
+		report.ThroughputRPS = float64(report.Succeeded) / elapsed.Seconds()
+	}
+
+	if len(latencies) > 0 {
+		sort.Float64s(latencies)
+		total := 0.0
+		for _, l := range latencies {
+			total += l
+		}
+		report.LatencyMeanMs = total / float64(len(latencies))
+		report.LatencyP50Ms = percentile(latencies, 0.50)
+		report.LatencyP90Ms = percentile(latencies, 0.90)
+		report.LatencyP95Ms = percentile(latencies, 0.95)
+		report.LatencyP99Ms = percentile(latencies, 0.99)
+		report.LatencyMaxMs = latencies[len(latencies)-1]
+	}
+
+	keys := make([]int, 0, len(seconds))
+	for second := range seconds {
+		keys = append(keys, second)
+	}
+	sort.Ints(keys)
+
+	var throughputs, meanLatencies, utils []float64
+	utilTotal := 0.0
+	for _, second := range keys {
+		stats := seconds[second]
+		if stats.Completed > 0 {
+			stats.MeanLatencyMs = latencySums[second] / float64(stats.Completed)
+		}
+		if value, exists := utilization[second]; exists {
+			stats.GPUUtilization = value
+			throughputs = append(throughputs, float64(stats.Completed))
+			meanLatencies = append(meanLatencies, stats.MeanLatencyMs)
+			utils = append(utils, value)
+			utilTotal += value
+		}
+		report.Timeline = append(report.Timeline, *stats)
+	}
+
+	if len(utils) > 0 {
+		report.AvgUtilization = utilTotal / float64(len(utils))
+		report.ThroughputUtilizationCorr = pearson(throughputs, utils)
+		report.LatencyUtilizationCorr = pearson(meanLatencies, utils)
+	}
+
+	return report
+}
+
+// percentile returns the nearest-rank percentile of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index]
+}
+
+// pearson returns the correlation coefficient of two equally sized series, 0 if undefined
+func pearson(x, y []float64) float64 {
+	n := len(x)
+	if n < 2 || n != len(y) {
+		return 0
+	}
+
+	var sumX, sumY float64
+	for i := 0; i < n; i++ {
+		sumX += x[i]
+		sumY += y[i]
+	}
+	meanX, meanY := sumX/float64(n), sumY/float64(n)
+
+	var cov, varX, varY float64
+	for i := 0; i < n; i++ {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varX*varY)
+}
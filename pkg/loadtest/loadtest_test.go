@@ -0,0 +1,95 @@
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving"
+)
+
+func TestRunSyntheticTraffic(t *testing.T) {
+	var calls int32
+	config := DefaultConfig()
+	config.QPS = 200
+	config.Duration = 200 * time.Millisecond
+	config.Synthetic.Models = []string{"m"}
+	config.Target = func(req *serving.InferenceRequest) (*serving.InferenceResponse, error) {
+		if atomic.AddInt32(&calls, 1)%10 == 0 {
+			return nil, errors.New("backend error")
+		}
+		time.Sleep(time.Millisecond)
+		return &serving.InferenceResponse{RequestID: req.ID}, nil
+	}
+
+	report, err := Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if report.Requests < 20 || report.Requests > 45 {
+		t.Errorf("Expected about 40 requests at 200 QPS for 200ms, got %d", report.Requests)
+	}
+	if report.Failed == 0 || report.Succeeded+report.Failed != report.Requests {
+		t.Errorf("Unexpected success/failure counts: %+v", report)
+	}
+	if report.LatencyP50Ms < 1 || report.LatencyP99Ms < report.LatencyP50Ms {
+		t.Errorf("Unexpected latency percentiles: p50=%f p99=%f", report.LatencyP50Ms, report.LatencyP99Ms)
+	}
+}
+
+func TestLoadTraceAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.jsonl")
+	content := `{"offset_ms": 20, "model_id": "b", "input": "second"}
+{"offset_ms": 0, "model_id": "a", "input": "first"}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write trace: %v", err)
+	}
+
+	trace, err := LoadTrace(path)
+	if err != nil {
+		t.Fatalf("LoadTrace failed: %v", err)
+	}
+	if len(trace) != 2 || trace[0].ModelID != "a" || trace[1].Offset != 20*time.Millisecond {
+		t.Fatalf("Unexpected trace: %+v", trace)
+	}
+
+	manager := serving.NewServingManager(nil, time.Minute)
+	config := DefaultConfig()
+	config.QPS = 0
+	config.Trace = trace
+	config.Target = manager.SubmitInferenceRequest
+
+	report, err := Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if report.Succeeded != 2 {
+		t.Errorf("Expected both trace records to be replayed, got %+v", report)
+	}
+	if report.Duration < 20*time.Millisecond {
+		t.Errorf("Expected replay to honour trace offsets, took %v", report.Duration)
+	}
+
+	if _, err := Run(context.Background(), Config{Duration: time.Second}); err == nil {
+		t.Error("Expected error without a target")
+	}
+}
+
+func TestPearsonCorrelation(t *testing.T) {
+	if c := pearson([]float64{1, 2, 3}, []float64{2, 4, 6}); math.Abs(c-1) > 1e-9 {
+		t.Errorf("Expected perfect correlation, got %f", c)
+	}
+	if c := pearson([]float64{1, 2, 3}, []float64{3, 2, 1}); math.Abs(c+1) > 1e-9 {
+		t.Errorf("Expected perfect negative correlation, got %f", c)
+	}
+	if c := pearson([]float64{1, 1}, []float64{1, 2}); c != 0 {
+		t.Errorf("Expected 0 for constant series, got %f", c)
+	}
+}
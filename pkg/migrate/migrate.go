@@ -0,0 +1,306 @@
+// Package migrate upgrades the schema of SQL-backed stores. Migrations are
+// numbered SQL files in the golang-migrate layout (0001_name.up.sql),
+// embedded in the binary and applied in order with the applied versions
+// recorded in a schema_migrations table. Replicas starting together take
+// turns: postgres upgrades hold an advisory lock, sqlite upgrades run in one
+// BEGIN IMMEDIATE transaction.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var embedded embed.FS
+
+// Dialects, which differ in query placeholders
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+// table records applied migrations
+const table = "schema_migrations"
+
+// advisoryLockKey identifies the postgres advisory lock held while migrating
+const advisoryLockKey int64 = 0x6d696772617465
+
+// querier runs statements on a database, connection or transaction
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Migration is one schema change
+type Migration struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	SQL     string `json:"sql"`
+}
+
+// AppliedMigration is a migration recorded in the database
+type AppliedMigration struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Parse reads NNNN_name.up.sql files from a directory of fsys, ordered by
+// version. Down migrations are ignored; versions must be unique.
+func Parse(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s does not match NNNN_name.up.sql", entry.Name())
+		}
+		if match[3] == "down" {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		if version == 0 {
+			return nil, fmt.Errorf("migration file %s: versions start at 1", entry.Name())
+		}
+		if existing, exists := byVersion[version]; exists {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", existing.Name, match[2], version)
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		byVersion[version] = Migration{Version: version, Name: match[2], SQL: string(data)}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Builtin returns the migrations embedded in the binary
+func Builtin() []Migration {
+	migrations, err := Parse(embedded, "migrations")
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded migrations: %v", err))
+	}
+	return migrations
+}
+
+// Migrator applies migrations to a database
+type Migrator struct {
+	db         *sql.DB
+	dialect    string
+	migrations []Migration
+	now        func() time.Time
+}
+
+// New creates a migrator for a postgres or sqlite database
+func New(db *sql.DB, dialect string, migrations []Migration) (*Migrator, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database is required")
+	}
+	if dialect != DialectPostgres && dialect != DialectSQLite {
+		return nil, fmt.Errorf("unsupported dialect %q (expected %s or %s)", dialect, DialectPostgres, DialectSQLite)
+	}
+	return &Migrator{db: db, dialect: dialect, migrations: migrations, now: time.Now}, nil
+}
+
+// Upgrade applies every pending builtin migration; stores call it when they
+// open their database so upgrades happen on startup
+func Upgrade(ctx context.Context, db *sql.DB, dialect string) ([]Migration, error) {
+	migrator, err := New(db, dialect, Builtin())
+	if err != nil {
+		return nil, err
+	}
+	return migrator.Up(ctx)
+}
+
+// ensureTable creates the migration table if it does not exist
+func (m *Migrator) ensureTable(ctx context.Context, q querier) error {
+	_, err := q.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+table+
+		" (version BIGINT PRIMARY KEY, name TEXT NOT NULL, applied_at TIMESTAMP NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", table, err)
+	}
+	return nil
+}
+
+// Applied returns the migrations recorded in the database, oldest first
+func (m *Migrator) Applied(ctx context.Context) ([]AppliedMigration, error) {
+	return m.applied(ctx, m.db)
+}
+
+// applied reads the migration table through q
+func (m *Migrator) applied(ctx context.Context, q querier) ([]AppliedMigration, error) {
+	if err := m.ensureTable(ctx, q); err != nil {
+		return nil, err
+	}
+	rows, err := q.QueryContext(ctx, "SELECT version, name, applied_at FROM "+table+" ORDER BY version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var applied []AppliedMigration
+	for rows.Next() {
+		var migration AppliedMigration
+		if err := rows.Scan(&migration.Version, &migration.Name, &migration.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied = append(applied, migration)
+	}
+	return applied, rows.Err()
+}
+
+// Pending returns the migrations not yet applied, in order. It fails when
+// the database has migrations this binary does not know, which means it was
+// upgraded by a newer release.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	return m.pending(ctx, m.db)
+}
+
+// pending compares the migration table read through q with the migrations
+func (m *Migrator) pending(ctx context.Context, q querier) ([]Migration, error) {
+	applied, err := m.applied(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[int]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+	}
+	done := make(map[int]bool, len(applied))
+	for _, migration := range applied {
+		if !known[migration.Version] {
+			return nil, fmt.Errorf("database has migration %d (%s) unknown to this binary; it was upgraded by a newer release", migration.Version, migration.Name)
+		}
+		done[migration.Version] = true
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if !done[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies the pending migrations in order and returns those applied. On
+// postgres each runs in a transaction with its record and the run stops at
+// the first failure; on sqlite they are applied together or not at all.
+// Pending migrations are read under the lock, so those a peer applied while
+// this one waited are skipped rather than failing.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if m.dialect == DialectSQLite {
+		return m.upSQLite(ctx, conn)
+	}
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", advisoryLockKey); err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", table, err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", advisoryLockKey)
+
+	pending, err := m.pending(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	var applied []Migration
+	for _, migration := range pending {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return applied, err
+		}
+		if err := m.apply(ctx, tx, migration); err != nil {
+			tx.Rollback()
+			return applied, err
+		}
+		if err := tx.Commit(); err != nil {
+			return applied, fmt.Errorf("failed to commit migration %d: %w", migration.Version, err)
+		}
+		applied = append(applied, migration)
+	}
+	return applied, nil
+}
+
+// upSQLite applies the pending migrations in one BEGIN IMMEDIATE transaction,
+// which takes the database write lock before the migration table is read
+func (m *Migrator) upSQLite(ctx context.Context, conn *sql.Conn) ([]Migration, error) {
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", table, err)
+	}
+	pending, err := m.pending(ctx, conn)
+	for i := 0; err == nil && i < len(pending); i++ {
+		err = m.apply(ctx, conn, pending[i])
+	}
+	if err == nil {
+		if _, err = conn.ExecContext(ctx, "COMMIT"); err != nil {
+			err = fmt.Errorf("failed to commit migrations: %w", err)
+		}
+	}
+	if err != nil {
+		conn.ExecContext(context.Background(), "ROLLBACK")
+		return nil, err
+	}
+	return pending, nil
+}
+
+// apply runs one migration and records it through q, which is inside the
+// caller's transaction
+func (m *Migrator) apply(ctx context.Context, q querier, migration Migration) error {
+	if _, err := q.ExecContext(ctx, migration.SQL); err != nil {
+		return fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+	}
+	insert := "INSERT INTO " + table + " (version, name, applied_at) VALUES (" + m.placeholders(3) + ")"
+	if _, err := q.ExecContext(ctx, insert, migration.Version, migration.Name, m.now().UTC()); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+	}
+	return nil
+}
+
+// placeholders returns n query parameters in the dialect's style
+func (m *Migrator) placeholders(n int) string {
+	return Placeholders(m.dialect, n)
+}
+
+// Placeholders returns n comma-separated query parameters in a dialect's
+// style, for stores writing queries against the migrated schema
+func Placeholders(dialect string, n int) string {
+	params := make([]string, n)
+	for i := range params {
+		if dialect == DialectPostgres {
+			params[i] = "$" + strconv.Itoa(i+1)
+		} else {
+			params[i] = "?"
+		}
+	}
+	return strings.Join(params, ", ")
+}
@@ -0,0 +1,59 @@
+//go:build cgo
+// +build cgo
+
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestConcurrentUpgradeOnSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agentaflow.db")
+
+	// Replicas sharing a database file upgrade it together on startup
+	var wg sync.WaitGroup
+	results := make(chan int, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			db, err := sql.Open("sqlite3", path)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer db.Close()
+			applied, err := Upgrade(context.Background(), db, DialectSQLite)
+			if err != nil {
+				t.Errorf("Upgrade: %v", err)
+			}
+			results <- len(applied)
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	total := 0
+	for count := range results {
+		total += count
+	}
+	if total != len(Builtin()) {
+		t.Errorf("Expected each migration applied once across replicas, got %d", total)
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	migrator, _ := New(db, DialectSQLite, Builtin())
+	if pending, err := migrator.Pending(context.Background()); err != nil || len(pending) != 0 {
+		t.Errorf("Pending after upgrade = %+v, %v", pending, err)
+	}
+}
@@ -0,0 +1,291 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// fakeDB is an in-memory database understanding only the migration table
+// statements; other statements are recorded, and fail if they contain FAIL
+type fakeDB struct {
+	mu         sync.Mutex
+	rows       [][]driver.Value // schema_migrations rows
+	statements []string         // Committed statements
+	advisory   sync.Mutex       // Held between pg_advisory_lock and pg_advisory_unlock
+}
+
+var (
+	fakeDBs   = map[string]*fakeDB{}
+	fakeDBsMu sync.Mutex
+)
+
+func init() {
+	sql.Register("migratefake", fakeDriver{})
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeDBsMu.Lock()
+	defer fakeDBsMu.Unlock()
+	if fakeDBs[name] == nil {
+		fakeDBs[name] = &fakeDB{}
+	}
+	return &fakeConn{db: fakeDBs[name]}, nil
+}
+
+type fakeConn struct {
+	db      *fakeDB
+	pending []func() // Changes of the open transaction
+	inTx    bool
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepare not supported")
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.inTx, c.pending = true, nil
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.db.mu.Lock()
+	for _, change := range c.pending {
+		change()
+	}
+	c.db.mu.Unlock()
+	c.inTx, c.pending = false, nil
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.inTx, c.pending = false, nil
+	return nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	switch {
+	case strings.Contains(query, "pg_advisory_lock"):
+		c.db.advisory.Lock()
+		return driver.RowsAffected(0), nil
+	case strings.Contains(query, "pg_advisory_unlock"):
+		c.db.advisory.Unlock()
+		return driver.RowsAffected(0), nil
+	}
+	if strings.Contains(query, "FAIL") {
+		return nil, fmt.Errorf("syntax error")
+	}
+	change := func() { c.db.statements = append(c.db.statements, query) }
+	if strings.HasPrefix(query, "INSERT INTO schema_migrations") {
+		if !strings.Contains(query, "$1, $2, $3") {
+			return nil, fmt.Errorf("expected postgres placeholders in %q", query)
+		}
+		row := []driver.Value{args[0].Value, args[1].Value, args[2].Value}
+		change = func() { c.db.rows = append(c.db.rows, row) }
+	}
+	if c.inTx {
+		c.pending = append(c.pending, change)
+	} else {
+		c.db.mu.Lock()
+		change()
+		c.db.mu.Unlock()
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	return &fakeRows{rows: append([][]driver.Value(nil), c.db.rows...)}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"version", "name", "applied_at"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// openFake opens a fresh fake database
+func openFake(t *testing.T) (*sql.DB, *fakeDB) {
+	t.Helper()
+	db, err := sql.Open("migratefake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	conn, _ := fakeDriver{}.Open(t.Name())
+	return db, conn.(*fakeConn).db
+}
+
+func testMigrations(t *testing.T, files map[string]string) []Migration {
+	t.Helper()
+	fsys := fstest.MapFS{}
+	for name, content := range files {
+		fsys["m/"+name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	migrations, err := Parse(fsys, "m")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return migrations
+}
+
+func TestBuiltinMigrationsParse(t *testing.T) {
+	migrations := Builtin()
+	if len(migrations) == 0 {
+		t.Fatal("Expected embedded migrations")
+	}
+	for i, migration := range migrations {
+		if migration.Version != i+1 || strings.TrimSpace(migration.SQL) == "" {
+			t.Errorf("Expected consecutive non-empty migrations, got %d %s at %d", migration.Version, migration.Name, i)
+		}
+	}
+}
+
+func TestParseOrdersAndRejectsBadFiles(t *testing.T) {
+	migrations := testMigrations(t, map[string]string{
+		"0002_add_index.up.sql":    "CREATE INDEX b",
+		"0001_create.up.sql":       "CREATE TABLE a",
+		"0001_create.down.sql":     "DROP TABLE a",
+		"0010_later_change.up.sql": "ALTER TABLE a",
+	})
+	if len(migrations) != 3 || migrations[0].Name != "create" || migrations[2].Version != 10 {
+		t.Errorf("Unexpected migrations: %+v", migrations)
+	}
+
+	for name, files := range map[string]fstest.MapFS{
+		"duplicate": {"m/0001_a.up.sql": {}, "m/01_b.up.sql": {}},
+		"misnamed":  {"m/create.sql": {}},
+		"zero":      {"m/0000_a.up.sql": {}},
+	} {
+		if _, err := Parse(files, "m"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestUpAppliesPendingOnce(t *testing.T) {
+	db, fake := openFake(t)
+	migrations := testMigrations(t, map[string]string{
+		"0001_create.up.sql": "CREATE TABLE a",
+		"0002_index.up.sql":  "CREATE INDEX b",
+	})
+	migrator, err := New(db, DialectPostgres, migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator.now = func() time.Time { return time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC) }
+
+	pending, err := migrator.Pending(context.Background())
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("Pending = %+v, %v", pending, err)
+	}
+	applied, err := migrator.Up(context.Background())
+	if err != nil || len(applied) != 2 {
+		t.Fatalf("Up = %+v, %v", applied, err)
+	}
+	if applied, _ := migrator.Up(context.Background()); len(applied) != 0 {
+		t.Errorf("Expected nothing pending on the second run, got %+v", applied)
+	}
+
+	recorded, err := migrator.Applied(context.Background())
+	if err != nil || len(recorded) != 2 || recorded[1].Name != "index" || !recorded[1].AppliedAt.Equal(migrator.now()) {
+		t.Errorf("Applied = %+v, %v", recorded, err)
+	}
+	if got := strings.Join(fake.statements, "; "); !strings.Contains(got, "CREATE TABLE a; CREATE INDEX b") {
+		t.Errorf("Expected migrations run in order, got %s", got)
+	}
+}
+
+func TestUpStopsAtFailureAndRefusesNewerDatabase(t *testing.T) {
+	db, fake := openFake(t)
+	migrations := testMigrations(t, map[string]string{
+		"0001_create.up.sql": "CREATE TABLE a",
+		"0002_broken.up.sql": "FAIL",
+		"0003_index.up.sql":  "CREATE INDEX b",
+	})
+	migrator, _ := New(db, DialectPostgres, migrations)
+	applied, err := migrator.Up(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "migration 2 (broken) failed") || len(applied) != 1 {
+		t.Errorf("Expected the run stopped at migration 2, got %+v, %v", applied, err)
+	}
+	if len(fake.rows) != 1 {
+		t.Errorf("Expected only migration 1 recorded, got %v", fake.rows)
+	}
+
+	older, _ := New(db, DialectPostgres, nil)
+	if _, err := older.Pending(context.Background()); err == nil || !strings.Contains(err.Error(), "newer release") {
+		t.Errorf("Expected a database ahead of the binary refused, got %v", err)
+	}
+}
+
+func TestConcurrentUpAppliesEachMigrationOnce(t *testing.T) {
+	_, fake := openFake(t)
+	migrations := testMigrations(t, map[string]string{
+		"0001_create.up.sql": "CREATE TABLE a",
+		"0002_index.up.sql":  "CREATE INDEX b",
+	})
+
+	// Replicas starting together, each with its own connection pool
+	var wg sync.WaitGroup
+	results := make(chan int, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			db, err := sql.Open("migratefake", t.Name())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer db.Close()
+			migrator, _ := New(db, DialectPostgres, migrations)
+			applied, err := migrator.Up(context.Background())
+			if err != nil {
+				t.Errorf("Up: %v", err)
+			}
+			results <- len(applied)
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	total := 0
+	for count := range results {
+		total += count
+	}
+	if total != 2 || len(fake.rows) != 2 {
+		t.Errorf("Expected each migration applied once across replicas, got %d applied and %d recorded", total, len(fake.rows))
+	}
+}
+
+func TestPlaceholdersPerDialect(t *testing.T) {
+	db, _ := openFake(t)
+	if _, err := New(db, "mysql", nil); err == nil {
+		t.Error("Expected an unsupported dialect rejected")
+	}
+	sqlite, _ := New(db, DialectSQLite, nil)
+	postgres, _ := New(db, DialectPostgres, nil)
+	if sqlite.placeholders(3) != "?, ?, ?" || postgres.placeholders(2) != "$1, $2" {
+		t.Errorf("Unexpected placeholders %q and %q", sqlite.placeholders(3), postgres.placeholders(2))
+	}
+}
@@ -0,0 +1,351 @@
+package observability
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// AgentBatch is an upload from a node agent: the metrics it collected, and
+// when it sent them by its own clock
+type AgentBatch struct {
+	Node    string           `json:"node"`
+	SentAt  time.Time        `json:"sent_at"`
+	Metrics []gpu.GPUMetrics `json:"metrics"`
+
+	// An agent numbers its batches from 1 within a session, a new one each
+	// time it starts, so a batch resent after a lost response is stored once
+	Session string `json:"session,omitempty"`
+	Seq     uint64 `json:"seq,omitempty"`
+
+	// Batches the agent spooled while the server was unreachable and has
+	// still to send after this one, and samples it dropped from a full spool
+	Backlog int           `json:"backlog,omitempty"`
+	Dropped []SampleRange `json:"dropped,omitempty"`
+}
+
+// SampleRange is a span of samples an agent dropped
+type SampleRange struct {
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Samples int       `json:"samples"`
+}
+
+// Statuses of a DataGap
+const (
+	GapMissing     = "missing"     // The node has stopped reporting
+	GapBackfilling = "backfilling" // The node is back and sending what it spooled
+	GapBackfilled  = "backfilled"  // The period was filled in late
+	GapLost        = "lost"        // The node dropped the period's samples
+)
+
+// DataGap is a period of a node's data that is missing, or that arrived
+// late and may have been incomplete when dashboards showed it
+type DataGap struct {
+	Node    string     `json:"node"`
+	From    time.Time  `json:"from"`
+	To      *time.Time `json:"to,omitempty"` // Unset while the node is still missing
+	Status  string     `json:"status"`
+	Samples int        `json:"samples,omitempty"` // Lost samples
+}
+
+// maxDataGaps caps the gaps kept, dropping the oldest
+const maxDataGaps = 500
+
+// AgentIngestResult answers an upload
+type AgentIngestResult struct {
+	Accepted   int           `json:"accepted"`
+	ServerTime time.Time     `json:"server_time"`
+	ClockSkew  time.Duration `json:"clock_skew"` // Agent clock minus server clock, measured on receipt
+	Corrected  bool          `json:"corrected"`  // Timestamps were shifted by ClockSkew
+	Duplicate  bool          `json:"duplicate"`  // The batch was stored before and was ignored
+}
+
+// AgentIngestConfig configures how agent uploads are accepted
+type AgentIngestConfig struct {
+	// Agent clocks further than this from the server's have their
+	// timestamps moved onto the server clock; the measurement includes
+	// network delay, so keep it well above upload latency
+	MaxClockSkew time.Duration `yaml:"max_clock_skew" json:"max_clock_skew"`
+
+	// Skew lasting this long raises a warning event
+	SkewAlertAfter time.Duration `yaml:"skew_alert_after" json:"skew_alert_after"`
+
+	// A node silent this long has a gap in its data, shown in dashboards;
+	// keep it above the agents' upload interval
+	OutageAfter time.Duration `yaml:"outage_after" json:"outage_after"`
+}
+
+// DefaultAgentIngestConfig corrects clocks more than 2 seconds off, warns
+// once a node's clock has been off for 5 minutes and marks a gap in the
+// data of a node silent for a minute
+func DefaultAgentIngestConfig() AgentIngestConfig {
+	return AgentIngestConfig{
+		MaxClockSkew:   2 * time.Second,
+		SkewAlertAfter: 5 * time.Minute,
+		OutageAfter:    time.Minute,
+	}
+}
+
+// NodeClock is what the server knows of one agent's clock
+type NodeClock struct {
+	Node        string        `json:"node"`
+	Skew        time.Duration `json:"skew"` // At the last upload
+	Skewed      bool          `json:"skewed"`
+	SkewedSince *time.Time    `json:"skewed_since,omitempty"`
+	Alerting    bool          `json:"alerting"` // Skew has persisted past SkewAlertAfter
+	LastSeen    time.Time     `json:"last_seen"`
+	Uploads     int           `json:"uploads"`
+
+	session string // Of the agent's last batch
+	seq     uint64 // Highest batch stored from session
+}
+
+// AgentIngest accepts metrics uploaded by node agents into a collector,
+// correcting timestamps from agents whose clocks are off so they cannot
+// corrupt trends, costs and alert ordering
+type AgentIngest struct {
+	config     AgentIngestConfig
+	collector  *gpu.MetricsCollector
+	monitoring *MonitoringService
+	exporter   *PrometheusExporter
+
+	nodes       map[string]*NodeClock
+	gaps        []*DataGap
+	backfilling map[string]*DataGap // Open gap of each node sending its spool
+	accepted    int
+	duplicates  int
+	mu          sync.RWMutex
+}
+
+// NewAgentIngest creates an ingest feeding collector; the monitoring service
+// and exporter may be nil
+func NewAgentIngest(config AgentIngestConfig, collector *gpu.MetricsCollector, monitoring *MonitoringService, exporter *PrometheusExporter) (*AgentIngest, error) {
+	if collector == nil {
+		return nil, fmt.Errorf("metrics collector is required")
+	}
+	defaults := DefaultAgentIngestConfig()
+	if config.MaxClockSkew <= 0 {
+		config.MaxClockSkew = defaults.MaxClockSkew
+	}
+	if config.SkewAlertAfter <= 0 {
+		config.SkewAlertAfter = defaults.SkewAlertAfter
+	}
+	if config.OutageAfter <= 0 {
+		config.OutageAfter = defaults.OutageAfter
+	}
+	return &AgentIngest{
+		config:      config,
+		collector:   collector,
+		monitoring:  monitoring,
+		exporter:    exporter,
+		nodes:       make(map[string]*NodeClock),
+		backfilling: make(map[string]*DataGap),
+	}, nil
+}
+
+// Ingest stores a batch received at received. GPU IDs are qualified with the
+// node, e.g. node-a:0, since every node numbers its GPUs from 0. A batch
+// already stored is acknowledged without being stored again.
+func (ai *AgentIngest) Ingest(batch AgentBatch, received time.Time) (AgentIngestResult, error) {
+	if strings.TrimSpace(batch.Node) == "" {
+		return AgentIngestResult{}, fmt.Errorf("node is required")
+	}
+	result := AgentIngestResult{ServerTime: received}
+	if !batch.SentAt.IsZero() {
+		result.ClockSkew = batch.SentAt.Sub(received)
+	}
+	result.Corrected = result.ClockSkew > ai.config.MaxClockSkew || result.ClockSkew < -ai.config.MaxClockSkew
+	ai.observeGaps(batch, received)
+	ai.observeClock(batch.Node, result.ClockSkew, result.Corrected, received)
+	if !ai.claim(batch) {
+		result.Duplicate = true
+		return result, nil
+	}
+
+	prefix := batch.Node + ":"
+	for _, metrics := range batch.Metrics {
+		if metrics.GPUID == "" {
+			continue
+		}
+		if !strings.HasPrefix(metrics.GPUID, prefix) {
+			metrics.GPUID = prefix + metrics.GPUID
+		}
+		if metrics.NodeID == "" {
+			metrics.NodeID = batch.Node
+		}
+		if result.Corrected {
+			metrics.Timestamp = metrics.Timestamp.Add(-result.ClockSkew)
+			metrics.ClockSkew = result.ClockSkew
+		}
+		// Nothing was measured after it arrived
+		if metrics.Timestamp.IsZero() || metrics.Timestamp.After(received) {
+			metrics.Timestamp = received
+		}
+		ai.collector.Ingest(metrics)
+		result.Accepted++
+	}
+
+	ai.mu.Lock()
+	ai.accepted += result.Accepted
+	ai.mu.Unlock()
+	return result, nil
+}
+
+// observeGaps opens a gap for a node back after an outage, closing it once
+// the node has sent its backlog, and records the samples it dropped. It
+// runs before the node's LastSeen is updated.
+func (ai *AgentIngest) observeGaps(batch AgentBatch, received time.Time) {
+	ai.mu.Lock()
+	defer ai.mu.Unlock()
+
+	if clock, seen := ai.nodes[batch.Node]; seen && ai.backfilling[batch.Node] == nil && received.Sub(clock.LastSeen) > ai.config.OutageAfter {
+		back := received
+		gap := &DataGap{Node: batch.Node, From: clock.LastSeen, To: &back, Status: GapBackfilling}
+		ai.addGap(gap)
+		ai.backfilling[batch.Node] = gap
+	}
+	if gap := ai.backfilling[batch.Node]; gap != nil && batch.Backlog == 0 {
+		gap.Status = GapBackfilled
+		delete(ai.backfilling, batch.Node)
+	}
+	for _, dropped := range batch.Dropped {
+		to := dropped.To
+		ai.addGap(&DataGap{Node: batch.Node, From: dropped.From, To: &to, Status: GapLost, Samples: dropped.Samples})
+	}
+}
+
+// addGap keeps a gap, dropping the oldest beyond maxDataGaps
+func (ai *AgentIngest) addGap(gap *DataGap) {
+	ai.gaps = append(ai.gaps, gap)
+	if len(ai.gaps) > maxDataGaps {
+		ai.gaps = ai.gaps[len(ai.gaps)-maxDataGaps:]
+	}
+}
+
+// Gaps returns the gaps in node data ending after since, oldest first,
+// including nodes missing now
+func (ai *AgentIngest) Gaps(since, now time.Time) []DataGap {
+	ai.mu.RLock()
+	defer ai.mu.RUnlock()
+
+	gaps := make([]DataGap, 0)
+	for _, gap := range ai.gaps {
+		if gap.To.After(since) {
+			gaps = append(gaps, *gap)
+		}
+	}
+	for _, clock := range ai.nodes {
+		if now.Sub(clock.LastSeen) > ai.config.OutageAfter {
+			gaps = append(gaps, DataGap{Node: clock.Node, From: clock.LastSeen, Status: GapMissing})
+		}
+	}
+	sort.SliceStable(gaps, func(i, j int) bool { return gaps[i].From.Before(gaps[j].From) })
+	return gaps
+}
+
+// claim records a batch's sequence number, reporting whether the batch is
+// new. Batches without one are always new.
+func (ai *AgentIngest) claim(batch AgentBatch) bool {
+	if batch.Seq == 0 {
+		return true
+	}
+
+	ai.mu.Lock()
+	defer ai.mu.Unlock()
+	clock := ai.nodes[batch.Node]
+	if clock.session == batch.Session && batch.Seq <= clock.seq {
+		ai.duplicates++
+		return false
+	}
+	clock.session, clock.seq = batch.Session, batch.Seq
+	return true
+}
+
+// observeClock tracks a node's skew, raising an event once it persists and
+// another once it clears
+func (ai *AgentIngest) observeClock(node string, skew time.Duration, skewed bool, now time.Time) {
+	ai.mu.Lock()
+	clock, exists := ai.nodes[node]
+	if !exists {
+		clock = &NodeClock{Node: node}
+		ai.nodes[node] = clock
+	}
+	clock.Skew, clock.Skewed, clock.LastSeen = skew, skewed, now
+	clock.Uploads++
+
+	var event *Event
+	switch {
+	case skewed && clock.SkewedSince == nil:
+		since := now
+		clock.SkewedSince = &since
+	case skewed && !clock.Alerting && now.Sub(*clock.SkewedSince) >= ai.config.SkewAlertAfter:
+		clock.Alerting = true
+		event = &Event{
+			Type:     "agent_clock_skew",
+			Severity: "warning",
+			Message:  fmt.Sprintf("Clock on node %s has been %s off the server's since %s; its timestamps are being corrected", node, skew.Round(time.Millisecond), clock.SkewedSince.Format(time.RFC3339)),
+		}
+	case !skewed && clock.Alerting:
+		event = &Event{
+			Type:     "agent_clock_skew_resolved",
+			Severity: "info",
+			Message:  fmt.Sprintf("Clock on node %s is back within %s of the server's", node, ai.config.MaxClockSkew),
+		}
+		fallthrough
+	case !skewed:
+		clock.SkewedSince, clock.Alerting = nil, false
+	}
+	ai.mu.Unlock()
+
+	if ai.exporter != nil {
+		ai.exporter.SetGauge("agent_clock_skew_seconds", skew.Seconds(), map[string]string{"node": node})
+	}
+	if event != nil && ai.monitoring != nil {
+		event.Source = "agent_ingest"
+		event.Metadata = map[string]interface{}{
+			"node":               node,
+			"clock_skew_seconds": skew.Seconds(),
+		}
+		event.Timestamp = now
+		ai.monitoring.RecordEvent(*event)
+	}
+}
+
+// Clocks returns every node's clock, sorted by node
+func (ai *AgentIngest) Clocks() []NodeClock {
+	ai.mu.RLock()
+	defer ai.mu.RUnlock()
+
+	clocks := make([]NodeClock, 0, len(ai.nodes))
+	for _, clock := range ai.nodes {
+		clocks = append(clocks, *clock)
+	}
+	sort.Slice(clocks, func(i, j int) bool { return clocks[i].Node < clocks[j].Node })
+	return clocks
+}
+
+// GetStats returns upload counts and the nodes whose clocks are off
+func (ai *AgentIngest) GetStats() map[string]interface{} {
+	ai.mu.RLock()
+	defer ai.mu.RUnlock()
+
+	skewed := make([]string, 0)
+	for node, clock := range ai.nodes {
+		if clock.Skewed {
+			skewed = append(skewed, node)
+		}
+	}
+	sort.Strings(skewed)
+	return map[string]interface{}{
+		"nodes":        len(ai.nodes),
+		"accepted":     ai.accepted,
+		"duplicates":   ai.duplicates,
+		"backfilling":  len(ai.backfilling),
+		"skewed_nodes": skewed,
+	}
+}
@@ -0,0 +1,178 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestAgentIngestCorrectsSkewedClocks(t *testing.T) {
+	collector := gpu.NewMetricsCollector(time.Second)
+	ingest, err := NewAgentIngest(DefaultAgentIngestConfig(), collector, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create ingest: %v", err)
+	}
+
+	// The agent's clock runs a minute fast
+	received := time.Now()
+	agentNow := received.Add(time.Minute)
+	result, err := ingest.Ingest(AgentBatch{
+		Node:   "node-a",
+		SentAt: agentNow,
+		Metrics: []gpu.GPUMetrics{
+			{GPUID: "0", Timestamp: agentNow.Add(-10 * time.Second), UtilizationGPU: 40},
+			{GPUID: "0", Timestamp: agentNow.Add(-5 * time.Second), UtilizationGPU: 60},
+		},
+	}, received)
+	if err != nil {
+		t.Fatalf("Ingest failed: %v", err)
+	}
+	if !result.Corrected || result.ClockSkew != time.Minute || result.Accepted != 2 {
+		t.Errorf("Expected 2 samples corrected by a minute, got %+v", result)
+	}
+
+	history := collector.GetMetricsHistory("node-a:0", time.Time{})
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 samples for node-a:0, got %d", len(history))
+	}
+	if !history[1].Timestamp.Equal(received.Add(-5*time.Second)) || history[1].ClockSkew != time.Minute || history[1].NodeID != "node-a" {
+		t.Errorf("Expected the sample moved onto the server clock, got %+v", history[1])
+	}
+
+	// Skew within tolerance is left alone
+	result, _ = ingest.Ingest(AgentBatch{Node: "node-b", SentAt: received.Add(time.Second), Metrics: []gpu.GPUMetrics{{GPUID: "0", Timestamp: received.Add(-time.Second)}}}, received)
+	if result.Corrected {
+		t.Errorf("Expected a second of skew tolerated, got %+v", result)
+	}
+	if _, err := ingest.Ingest(AgentBatch{SentAt: received}, received); err == nil {
+		t.Error("Expected a batch without a node to be rejected")
+	}
+}
+
+func TestAgentIngestStoresResentBatchesOnce(t *testing.T) {
+	collector := gpu.NewMetricsCollector(time.Second)
+	ingest, _ := NewAgentIngest(DefaultAgentIngestConfig(), collector, nil, nil)
+
+	now := time.Now()
+	batch := AgentBatch{Node: "node-a", SentAt: now, Session: "a1", Seq: 1, Metrics: []gpu.GPUMetrics{{GPUID: "0", Timestamp: now}}}
+	ingest.Ingest(batch, now)
+	if result, _ := ingest.Ingest(batch, now); !result.Duplicate || result.Accepted != 0 {
+		t.Errorf("Expected the resent batch acknowledged as a duplicate, got %+v", result)
+	}
+
+	// A restarted agent numbers its batches from 1 again
+	batch.Session = "b2"
+	if result, _ := ingest.Ingest(batch, now); result.Duplicate {
+		t.Error("Expected a new session's first batch stored")
+	}
+	if history := collector.GetMetricsHistory("node-a:0", time.Time{}); len(history) != 2 {
+		t.Errorf("Expected 2 samples stored, got %d", len(history))
+	}
+}
+
+func TestAgentIngestTracksGaps(t *testing.T) {
+	ingest, _ := NewAgentIngest(AgentIngestConfig{OutageAfter: time.Minute}, gpu.NewMetricsCollector(time.Second), nil, nil)
+
+	start := time.Now()
+	ingest.Ingest(AgentBatch{Node: "node-a"}, start)
+	ingest.Ingest(AgentBatch{Node: "node-b"}, start)
+	if gaps := ingest.Gaps(start.Add(-time.Hour), start.Add(2*time.Minute)); len(gaps) != 2 || gaps[0].Status != GapMissing || gaps[0].To != nil {
+		t.Fatalf("Expected both silent nodes missing, got %+v", gaps)
+	}
+
+	// node-a comes back with a spool of 2 batches, one of which it had to drop
+	back := start.Add(5 * time.Minute)
+	lost := SampleRange{From: start.Add(time.Minute), To: start.Add(2 * time.Minute), Samples: 12}
+	ingest.Ingest(AgentBatch{Node: "node-a", Backlog: 1, Dropped: []SampleRange{lost}}, back)
+	byStatus := func(gaps []DataGap) map[string]DataGap {
+		statuses := make(map[string]DataGap)
+		for _, gap := range gaps {
+			statuses[gap.Status] = gap
+		}
+		return statuses
+	}
+	gaps := byStatus(ingest.Gaps(start, back))
+	if len(gaps) != 3 || !gaps[GapBackfilling].To.Equal(back) || gaps[GapLost].Samples != 12 || gaps[GapMissing].Node != "node-b" {
+		t.Fatalf("Expected node-a backfilling with a lost period and node-b missing, got %+v", gaps)
+	}
+
+	ingest.Ingest(AgentBatch{Node: "node-a"}, back.Add(time.Second))
+	if gaps := byStatus(ingest.Gaps(start, back.Add(time.Second))); gaps[GapBackfilled].Node != "node-a" {
+		t.Errorf("Expected the outage backfilled once the backlog was sent, got %+v", gaps)
+	}
+	if gaps := ingest.Gaps(back.Add(time.Hour), back.Add(time.Hour)); len(gaps) != 2 {
+		t.Errorf("Expected only the nodes missing now after the gaps ended, got %+v", gaps)
+	}
+}
+
+func TestAgentIngestAlertsOnPersistentSkew(t *testing.T) {
+	monitoring := NewMonitoringService(100)
+	ingest, _ := NewAgentIngest(AgentIngestConfig{MaxClockSkew: 2 * time.Second, SkewAlertAfter: time.Minute}, gpu.NewMetricsCollector(time.Second), monitoring, nil)
+
+	start := time.Now()
+	upload := func(at time.Time, skew time.Duration) {
+		ingest.Ingest(AgentBatch{Node: "node-a", SentAt: at.Add(skew)}, at)
+	}
+	events := func(eventType string) int {
+		count := 0
+		for _, event := range monitoring.GetEvents(start.Add(-time.Hour), start.Add(time.Hour), "") {
+			if event.Type == eventType {
+				count++
+			}
+		}
+		return count
+	}
+
+	upload(start, -30*time.Second)
+	upload(start.Add(30*time.Second), -30*time.Second)
+	if events("agent_clock_skew") != 0 {
+		t.Error("Expected no alert before the skew persisted")
+	}
+	upload(start.Add(time.Minute), -30*time.Second)
+	upload(start.Add(90*time.Second), -30*time.Second)
+	if events("agent_clock_skew") != 1 {
+		t.Errorf("Expected one alert once the skew persisted, got %d", events("agent_clock_skew"))
+	}
+	if clocks := ingest.Clocks(); len(clocks) != 1 || !clocks[0].Alerting || clocks[0].Uploads != 4 {
+		t.Errorf("Expected node-a alerting after 4 uploads, got %+v", clocks)
+	}
+
+	upload(start.Add(2*time.Minute), 0)
+	if events("agent_clock_skew_resolved") != 1 {
+		t.Error("Expected the alert resolved once the clock was fixed")
+	}
+	if clocks := ingest.Clocks(); clocks[0].Skewed || clocks[0].SkewedSince != nil {
+		t.Errorf("Expected node-a back in line, got %+v", clocks[0])
+	}
+}
+
+func TestAgentMetricsRequirePushScope(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{Port: 0})
+	store, _ := apikeys.NewStore("")
+	dashboard.SetAPIKeyStore(store)
+	ingest, _ := NewAgentIngest(DefaultAgentIngestConfig(), gpu.NewMetricsCollector(time.Second), nil, nil)
+	dashboard.SetAgentIngest(ingest)
+
+	_, reader, _ := store.Create(apikeys.KeyRequest{Name: "viewer", Scopes: []apikeys.Scope{apikeys.ScopeReadMetrics}})
+	_, pusher, _ := store.Create(apikeys.KeyRequest{Name: "node-a", Scopes: []apikeys.Scope{apikeys.ScopePushMetrics}})
+	body := `{"node": "node-a", "metrics": [{"gpu_id": "0", "utilization_gpu": 50}]}`
+
+	if response := serveAs(dashboard, reader, http.MethodPost, "/api/v1/agents/metrics", body); response.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 without the push-metrics scope, got %d", response.Code)
+	}
+	response := serveAs(dashboard, pusher, http.MethodPost, "/api/v1/agents/metrics", body)
+	var result AgentIngestResult
+	json.Unmarshal(response.Body.Bytes(), &result)
+	if response.Code != http.StatusOK || result.Accepted != 1 {
+		t.Errorf("Expected the sample accepted, got %d: %s", response.Code, response.Body.String())
+	}
+
+	if response := serveAs(dashboard, reader, http.MethodGet, "/api/v1/agents/gaps", ""); response.Code != http.StatusOK || !strings.Contains(response.Body.String(), `"gaps":[]`) {
+		t.Errorf("Expected no gaps listed, got %d: %s", response.Code, response.Body.String())
+	}
+}
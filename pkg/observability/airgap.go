@@ -0,0 +1,192 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/airgap"
+)
+
+// AirGapConfig runs the dashboard for an edge or air-gapped site, with
+// nothing fetched from or sent outside the local network
+type AirGapConfig struct {
+	// Serves the dashboard page's front-end libraries from the binary,
+	// which must be built with -tags airgap, and refuses outbound
+	// connections from HTTP clients without their own transport, the
+	// inference gRPC client, the trace exporters, NATS, the Redis broadcast
+	// bus and SMTP
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// CIDR ranges connections may go to; empty allows loopback, private and
+	// link-local addresses
+	AllowedNetworks []string `yaml:"allowed_networks" json:"allowed_networks"`
+}
+
+// validate checks the air gap settings, including that the binary bundles
+// the dashboard page's libraries
+func (c AirGapConfig) validate(errs *ConfigErrors) {
+	for i, cidr := range c.AllowedNetworks {
+		if _, _, err := net.ParseCIDR(strings.TrimSpace(cidr)); err != nil {
+			errs.add(fmt.Sprintf("air_gap.allowed_networks[%d]", i), "must be a CIDR range, got %q", cidr)
+		}
+	}
+	if !c.Enabled {
+		return
+	}
+	if bundledAssets == nil {
+		errs.add("air_gap.enabled", "requires a binary built with -tags airgap")
+	} else if missing := missingAssets(); len(missing) > 0 {
+		errs.add("air_gap.enabled", "binary does not bundle %s; run make airgap-assets before building", strings.Join(missing, ", "))
+	}
+}
+
+// Where the dashboard page loads its front-end libraries from, and where an
+// air-gapped dashboard serves them instead
+const (
+	cdnPrefix  = "https://cdn.jsdelivr.net/npm/"
+	vendorPath = "/assets/vendor/"
+)
+
+var (
+	cdnAssetPattern = regexp.MustCompile(regexp.QuoteMeta(cdnPrefix) + `[^"']+`)
+	webFontsPattern = regexp.MustCompile(`\s*<link href="https://fonts\.googleapis\.com/[^"]*" rel="stylesheet">`)
+)
+
+// offlineHTML points a page at the bundled libraries and drops web fonts,
+// leaving the system font the page falls back to
+func offlineHTML(html string) string {
+	html = webFontsPattern.ReplaceAllString(html, "")
+	return strings.ReplaceAll(html, cdnPrefix, vendorPath)
+}
+
+// missingAssets lists the libraries the dashboard page loads that the
+// binary does not bundle; all of them without -tags airgap
+func missingAssets() []string {
+	var missing []string
+	for _, asset := range cdnAssetPattern.FindAllString(getDashboardHTML(WebDashboardConfig{}, DefaultDashboardLocale), -1) {
+		path := strings.TrimPrefix(asset, cdnPrefix)
+		if bundledAssets == nil {
+			missing = append(missing, path)
+			continue
+		}
+		if _, err := fs.Stat(bundledAssets, path); err != nil {
+			missing = append(missing, path)
+		}
+	}
+	return missing
+}
+
+// outboundEndpoint is the host:port address of a configured broker or mail
+// server and the component connecting to it
+type outboundEndpoint struct {
+	component string
+	address   string
+}
+
+// outboundEndpoints are checked by the air_gap health check to stay inside
+// the allowed networks
+var (
+	outboundEndpoints   = make(map[outboundEndpoint]bool)
+	outboundEndpointsMu sync.Mutex
+)
+
+// registerOutboundEndpoint records an address a component connects to
+func registerOutboundEndpoint(component, address string) {
+	outboundEndpointsMu.Lock()
+	defer outboundEndpointsMu.Unlock()
+	outboundEndpoints[outboundEndpoint{component: component, address: address}] = true
+}
+
+// checkOutboundEndpoints returns an error naming every registered endpoint
+// the guard would refuse
+func checkOutboundEndpoints(guard *airgap.Guard) error {
+	outboundEndpointsMu.Lock()
+	endpoints := make([]outboundEndpoint, 0, len(outboundEndpoints))
+	for endpoint := range outboundEndpoints {
+		endpoints = append(endpoints, endpoint)
+	}
+	outboundEndpointsMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var refused []string
+	for _, endpoint := range endpoints {
+		host, _, err := net.SplitHostPort(endpoint.address)
+		if err != nil {
+			host = endpoint.address
+		}
+		if err := guard.Check(ctx, host); err != nil {
+			refused = append(refused, fmt.Sprintf("%s at %s: %v", endpoint.component, endpoint.address, err))
+		}
+	}
+	if len(refused) > 0 {
+		sort.Strings(refused)
+		return fmt.Errorf("configured endpoints outside the allowed networks: %s", strings.Join(refused, "; "))
+	}
+	return nil
+}
+
+// enableAirGap installs a guard refusing outbound connections, recording
+// each attempt as a warning event
+func (wd *WebDashboard) enableAirGap(config AirGapConfig) {
+	guard, err := airgap.NewGuard(config.AllowedNetworks)
+	if err != nil {
+		log.Printf("Air gap misconfigured, allowing only loopback, private and link-local networks: %v", err)
+		guard, _ = airgap.NewGuard(nil)
+	}
+	guard.OnViolation(func(violation airgap.Violation) {
+		log.Printf("Air gap: blocked outbound connection to %s: %s", violation.Address, violation.Reason)
+		if wd.prometheusExporter != nil {
+			wd.prometheusExporter.IncCounter("airgap_blocked_connections_total", 1, nil)
+		}
+		if wd.monitoringService != nil {
+			wd.monitoringService.RecordEvent(Event{
+				Type:      "outbound_connection_blocked",
+				Severity:  "warning",
+				Message:   fmt.Sprintf("Blocked an outbound connection to %s in air-gapped mode", violation.Address),
+				Source:    "air_gap",
+				Metadata:  map[string]interface{}{"address": violation.Address, "reason": violation.Reason},
+				Timestamp: violation.Timestamp,
+			})
+		}
+	})
+	guard.Install()
+	wd.airGap = guard
+
+	wd.RegisterHealthCheck("air_gap", func() error {
+		if missing := missingAssets(); len(missing) > 0 {
+			return fmt.Errorf("%d dashboard assets not bundled; build with -tags airgap after make airgap-assets", len(missing))
+		}
+		return checkOutboundEndpoints(guard)
+	})
+}
+
+// handleAirGapStatus reports bundled assets and blocked connections
+func (wd *WebDashboard) handleAirGapStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if wd.airGap == nil {
+		http.Error(w, "air gap not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	missing := missingAssets()
+	if missing == nil {
+		missing = []string{}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"guard":          wd.airGap.GetStats(),
+		"missing_assets": missing,
+		"violations":     wd.airGap.Violations(),
+		"timestamp":      time.Now(),
+	})
+}
@@ -0,0 +1,151 @@
+package observability
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/airgap"
+)
+
+// bundleAssets stands in for an air-gapped build's embedded libraries
+func bundleAssets(t *testing.T, paths []string) {
+	original := bundledAssets
+	assets := fstest.MapFS{}
+	for _, path := range paths {
+		assets[path] = &fstest.MapFile{Data: []byte("/* " + path + " */")}
+	}
+	bundledAssets = assets
+	t.Cleanup(func() { bundledAssets = original })
+}
+
+func TestAirGappedDashboardServesBundledAssets(t *testing.T) {
+	bundleAssets(t, missingAssets())
+
+	config := WebDashboardConfig{Port: 0, AirGap: AirGapConfig{Enabled: true}}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected bundled assets to validate, got %v", err)
+	}
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, NewPrometheusExporter(nil, DefaultPrometheusConfig()), config)
+	t.Cleanup(dashboard.airGap.Uninstall)
+
+	page := serveDashboard(dashboard, http.MethodGet, "/", "").Body.String()
+	if strings.Contains(page, "https://") {
+		t.Error("Expected the page to load nothing from outside the dashboard")
+	}
+	if !strings.Contains(page, vendorPath+"chart.js@3.9.1/dist/chart.min.js") {
+		t.Error("Expected the page to load the bundled chart.js")
+	}
+
+	response := serveDashboard(dashboard, http.MethodGet, vendorPath+"chart.js@3.9.1/dist/chart.min.js", "")
+	if response.Code != http.StatusOK || !strings.Contains(response.Body.String(), "chart.min.js") {
+		t.Errorf("Expected the bundled asset served, got %d", response.Code)
+	}
+
+	for _, path := range []string{"/health", "/api/v1/system/status", "/api/v1/metrics"} {
+		serveDashboard(dashboard, http.MethodGet, path, "")
+	}
+
+	response = serveDashboard(dashboard, http.MethodGet, "/api/v1/system/airgap", "")
+	var status struct {
+		Guard         map[string]interface{} `json:"guard"`
+		MissingAssets []string               `json:"missing_assets"`
+		Violations    []interface{}          `json:"violations"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode air gap status: %v", err)
+	}
+	if status.Guard["installed"] != true {
+		t.Errorf("Expected the guard installed, got %v", status.Guard)
+	}
+	if len(status.MissingAssets) != 0 || len(status.Violations) != 0 {
+		t.Errorf("Expected no missing assets or outbound connections, got %v and %v", status.MissingAssets, status.Violations)
+	}
+}
+
+func TestAirGapGuardRecordsOutboundConnections(t *testing.T) {
+	bundleAssets(t, missingAssets())
+
+	monitoring := NewMonitoringService(100)
+	dashboard := NewWebDashboard(monitoring, nil, nil, WebDashboardConfig{Port: 0, AirGap: AirGapConfig{Enabled: true}})
+	t.Cleanup(dashboard.airGap.Uninstall)
+
+	if _, err := http.Get("http://203.0.113.7/pricing"); err == nil {
+		t.Fatal("Expected the outbound request refused")
+	}
+	if len(dashboard.airGap.Violations()) != 1 {
+		t.Errorf("Expected one violation, got %v", dashboard.airGap.Violations())
+	}
+	events := monitoring.GetEvents(time.Now().Add(-time.Minute), time.Now().Add(time.Minute), "warning")
+	if len(events) != 1 || events[0].Type != "outbound_connection_blocked" {
+		t.Errorf("Expected a blocked connection event, got %v", events)
+	}
+}
+
+func TestAirGapGuardCoversBrokersAndMail(t *testing.T) {
+	bundleAssets(t, missingAssets())
+	defer func(saved map[outboundEndpoint]bool) { outboundEndpoints = saved }(outboundEndpoints)
+	outboundEndpoints = make(map[outboundEndpoint]bool)
+
+	// Stands in for brokers and a mail server on a network the site may not reach
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	address := listener.Addr().String()
+
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{Port: 0, AirGap: AirGapConfig{Enabled: true, AllowedNetworks: []string{"10.0.0.0/8"}}})
+	t.Cleanup(dashboard.airGap.Uninstall)
+
+	publisher, err := NewNATSPublisher(NATSConfig{URL: "nats://" + address})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := publisher.Publish("capacity", []byte("{}")); !errors.Is(err, airgap.ErrOutbound) {
+		t.Errorf("Expected NATS refused, got %v", err)
+	}
+	if err := NewRedisBroadcastBus(BroadcastConfig{RedisAddr: address}).Publish([]byte("{}")); !errors.Is(err, airgap.ErrOutbound) {
+		t.Errorf("Expected Redis refused, got %v", err)
+	}
+	store, _ := NewNotificationPreferenceStore("")
+	notifier, err := NewUserNotifier(UserNotifierConfig{SMTPAddr: address, From: "agentaflow@example.com"}, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.sendMail(address, nil, "agentaflow@example.com", []string{"oncall@example.com"}, []byte("Subject: test\r\n\r\n")); !errors.Is(err, airgap.ErrOutbound) {
+		t.Errorf("Expected SMTP refused, got %v", err)
+	}
+	if violations := dashboard.airGap.Violations(); len(violations) != 3 {
+		t.Errorf("Expected three violations, got %v", violations)
+	}
+
+	components, failing := dashboard.CheckHealth()
+	message := components["air_gap"].Message
+	if len(failing) == 0 || !strings.Contains(message, "nats at "+address) || !strings.Contains(message, "redis broadcast at "+address) || !strings.Contains(message, "smtp at "+address) {
+		t.Errorf("Expected the health check to name the configured endpoints, got %q", message)
+	}
+}
+
+func TestAirGapConfigValidation(t *testing.T) {
+	original := bundledAssets
+	bundledAssets = nil
+	t.Cleanup(func() { bundledAssets = original })
+
+	config := WebDashboardConfig{AirGap: AirGapConfig{Enabled: true, AllowedNetworks: []string{"10.0.0.0"}}}
+	err := config.Validate()
+	if err == nil || !strings.Contains(err.Error(), "-tags airgap") || !strings.Contains(err.Error(), "allowed_networks[0]") {
+		t.Errorf("Expected an unbundled build and invalid network rejected, got %v", err)
+	}
+
+	bundleAssets(t, []string{"chart.js@3.9.1/dist/chart.min.js"})
+	config.AirGap.AllowedNetworks = nil
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "bootstrap@5.3.0") {
+		t.Errorf("Expected missing assets listed, got %v", err)
+	}
+}
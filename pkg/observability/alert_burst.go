@@ -0,0 +1,72 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// burstCapturer is a collector that can sample one GPU at high frequency,
+// such as gpu.MetricsCollector
+type burstCapturer interface {
+	CaptureBurst(ctx context.Context, gpuID string, config gpu.BurstConfig) (gpu.BurstCapture, error)
+}
+
+// EnableBurstCapture captures a burst of high-frequency samples from a GPU
+// whenever one of its alerts fires, attaching it to the alert's incident when
+// alerts are grouped. It has no effect unless the collector supports bursts.
+func (gmi *GPUMetricsIntegration) EnableBurstCapture(config gpu.BurstConfig) {
+	gmi.mu.Lock()
+	defer gmi.mu.Unlock()
+	gmi.burstConfig = &config
+	if gmi.bursting == nil {
+		gmi.bursting = make(map[string]bool)
+	}
+}
+
+// startBurst captures a burst for a fired alert unless one is already running
+// for the GPU; callers must hold the lock
+func (gmi *GPUMetricsIntegration) startBurst(gpuID string, alert gpu.GPUAlert, incidentID string) {
+	capturer, ok := gmi.metricsCollector.(burstCapturer)
+	if gmi.burstConfig == nil || !ok || gmi.bursting[gpuID] {
+		return
+	}
+	gmi.bursting[gpuID] = true
+	config := *gmi.burstConfig
+	grouper := gmi.alertGrouper
+
+	go func() {
+		defer func() {
+			gmi.mu.Lock()
+			delete(gmi.bursting, gpuID)
+			gmi.mu.Unlock()
+		}()
+
+		burst, err := capturer.CaptureBurst(context.Background(), gpuID, config)
+		if err != nil {
+			log.Printf("Burst capture for %s alert on GPU %s failed: %v", alert.Type, gpuID, err)
+			return
+		}
+		attached := grouper != nil && incidentID != "" && grouper.AttachBurst(incidentID, burst)
+		if gmi.monitoringService != nil {
+			gmi.monitoringService.RecordEvent(Event{
+				Type:     "gpu_alert_burst",
+				Severity: "info",
+				Message:  fmt.Sprintf("Captured %d samples from GPU %s after %s alert", len(burst.Samples), gpuID, alert.Type),
+				Source:   "gpu_metrics_integration",
+				Metadata: map[string]interface{}{
+					"gpu_id":           gpuID,
+					"alert_type":       alert.Type,
+					"incident_id":      incidentID,
+					"attached":         attached,
+					"samples":          len(burst.Samples),
+					"failed":           burst.Failed,
+					"interval":         burst.Interval,
+					"duration_seconds": burst.EndedAt.Sub(burst.StartedAt).Seconds(),
+				},
+			})
+		}
+	}()
+}
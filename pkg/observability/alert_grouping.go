@@ -0,0 +1,512 @@
+package observability
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// Alert grouping dimensions
+const (
+	GroupByNode = "node"
+	GroupByType = "type"
+)
+
+// Incident status values
+const (
+	IncidentOpen     = "open"
+	IncidentResolved = "resolved"
+)
+
+// Incident changes reported to listeners
+const (
+	IncidentOpened    = "opened"
+	IncidentEscalated = "escalated"
+	IncidentClosed    = "resolved"
+)
+
+// AlertGroupingConfig controls how related alerts are collapsed into incidents
+type AlertGroupingConfig struct {
+	GroupBy      []string      `yaml:"group_by" json:"group_by"`           // Dimensions alerts must share to be grouped
+	Window       time.Duration `yaml:"window" json:"window"`               // Idle time after which an incident without firing alerts resolves
+	MaxChildren  int           `yaml:"max_children" json:"max_children"`   // Child alerts kept per incident
+	MaxResolved  int           `yaml:"max_resolved" json:"max_resolved"`   // Resolved incidents kept for the dashboard
+	NodeFallback string        `yaml:"node_fallback" json:"node_fallback"` // Node for GPU IDs without a "node/" prefix
+}
+
+// DefaultAlertGroupingConfig returns a configuration grouping alerts by node and type
+func DefaultAlertGroupingConfig() AlertGroupingConfig {
+	return AlertGroupingConfig{
+		GroupBy:     []string{GroupByNode, GroupByType},
+		Window:      5 * time.Minute,
+		MaxChildren: 100,
+		MaxResolved: 50,
+	}
+}
+
+// Validate checks the grouping configuration
+func (c AlertGroupingConfig) Validate() error {
+	var errs ConfigErrors
+	for _, dimension := range c.GroupBy {
+		if dimension != GroupByNode && dimension != GroupByType {
+			errs.add("group_by", "unknown dimension %q (must be %q or %q)", dimension, GroupByNode, GroupByType)
+		}
+	}
+	if c.Window < 0 {
+		errs.add("window", "must not be negative")
+	}
+	if c.MaxChildren < 0 {
+		errs.add("max_children", "must not be negative")
+	}
+	if c.MaxResolved < 0 {
+		errs.add("max_resolved", "must not be negative")
+	}
+	return errs.err()
+}
+
+// IncidentAlert is a child alert of an incident
+type IncidentAlert struct {
+	GPUID      string       `json:"gpu_id"`
+	GPUName    string       `json:"gpu_name"`
+	Alert      gpu.GPUAlert `json:"alert"`
+	Firing     bool         `json:"firing"`
+	ResolvedAt *time.Time   `json:"resolved_at,omitempty"`
+}
+
+// Incident collapses related alerts that share the grouping dimensions
+type Incident struct {
+	ID         string            `json:"id"`
+	Key        string            `json:"key"`
+	Labels     map[string]string `json:"labels"`
+	Title      string            `json:"title"`
+	Severity   string            `json:"severity"`
+	Status     string            `json:"status"`
+	Alerts     []IncidentAlert   `json:"alerts"`
+	AlertCount int               `json:"alert_count"` // Alerts received, including any dropped past MaxChildren
+	GPUCount   int               `json:"gpu_count"`
+	StartedAt  time.Time         `json:"started_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	ResolvedAt *time.Time        `json:"resolved_at,omitempty"`
+	AckedBy    string            `json:"acked_by,omitempty"` // External tool that acknowledged the incident
+
+	// High-frequency samples captured when its alerts fired, for post-hoc analysis
+	Bursts []gpu.BurstCapture `json:"bursts,omitempty"`
+}
+
+// maxIncidentBursts bounds the burst captures kept per incident
+const maxIncidentBursts = 10
+
+// IncidentListener is notified when an incident opens, escalates or resolves
+type IncidentListener func(incident Incident, change string)
+
+// AlertGrouper groups per-GPU alerts into incidents so that an alert storm
+// results in one notification rather than one per GPU
+type AlertGrouper struct {
+	config     AlertGroupingConfig
+	open       map[string]*Incident // Open incidents by key
+	resolved   []*Incident          // Most recent last
+	listeners  []IncidentListener
+	nextID     int
+	received   int
+	notified   int
+	suppressed int
+	mu         sync.RWMutex
+}
+
+// NewAlertGrouper creates an alert grouper. GPUs are assigned to nodes by the
+// "node/gpu" ID convention used by the Kubernetes scheduler, falling back to
+// NodeFallback (or the local hostname) for plain GPU IDs.
+func NewAlertGrouper(config AlertGroupingConfig) (*AlertGrouper, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if config.NodeFallback == "" {
+		hostname, err := os.Hostname()
+		if err != nil || hostname == "" {
+			hostname = "local"
+		}
+		config.NodeFallback = hostname
+	}
+
+	return &AlertGrouper{
+		config: config,
+		open:   make(map[string]*Incident),
+	}, nil
+}
+
+// Subscribe registers a listener for incident changes. Listeners are called
+// synchronously, outside the grouper's lock.
+func (g *AlertGrouper) Subscribe(listener IncidentListener) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.listeners = append(g.listeners, listener)
+}
+
+// NodeFor returns the node a GPU belongs to
+func (g *AlertGrouper) NodeFor(gpuID string) string {
+	if i := strings.Index(gpuID, "/"); i > 0 {
+		return gpuID[:i]
+	}
+	return g.config.NodeFallback
+}
+
+// Add records an alert and returns the ID of the incident it was grouped
+// into. Firing alerts keep their incident open until Resolve is called;
+// one-shot alerts only keep it open for the grouping window.
+func (g *AlertGrouper) Add(gpuID, gpuName string, alert gpu.GPUAlert, firing bool) string {
+	now := alert.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	g.mu.Lock()
+	changes := g.sweepLocked(now)
+
+	labels := g.labelsFor(gpuID, alert.Type)
+	key := groupKey(labels)
+	incident, exists := g.open[key]
+	change := ""
+	if !exists {
+		g.nextID++
+		incident = &Incident{
+			ID:        fmt.Sprintf("incident-%d", g.nextID),
+			Key:       key,
+			Labels:    labels,
+			Severity:  alert.Severity,
+			Status:    IncidentOpen,
+			StartedAt: now,
+		}
+		g.open[key] = incident
+		change = IncidentOpened
+	} else if severityRank(alert.Severity) > severityRank(incident.Severity) {
+		incident.Severity = alert.Severity
+		change = IncidentEscalated
+	}
+
+	child := IncidentAlert{GPUID: gpuID, GPUName: gpuName, Alert: alert, Firing: firing}
+	if !firing {
+		resolvedAt := now
+		child.ResolvedAt = &resolvedAt
+	}
+	g.addChild(incident, child)
+	incident.AlertCount++
+	incident.UpdatedAt = now
+	incident.GPUCount = countGPUs(incident.Alerts)
+	incident.Title = incidentTitle(incident)
+
+	g.received++
+	if change != "" {
+		g.notified++
+		changes = append(changes, incidentChange{copyIncident(incident), change})
+	} else {
+		g.suppressed++
+	}
+	id := incident.ID
+	listeners := g.listeners
+	g.mu.Unlock()
+
+	notifyListeners(listeners, changes)
+	return id
+}
+
+// Resolve marks a GPU's firing alert of the given type as resolved. The
+// incident resolves once none of its alerts are firing.
+func (g *AlertGrouper) Resolve(gpuID, alertType string, now time.Time) {
+	g.mu.Lock()
+	var changes []incidentChange
+	key := groupKey(g.labelsFor(gpuID, alertType))
+	if incident, exists := g.open[key]; exists {
+		for i := range incident.Alerts {
+			child := &incident.Alerts[i]
+			if child.Firing && child.GPUID == gpuID && child.Alert.Type == alertType {
+				resolvedAt := now
+				child.Firing = false
+				child.ResolvedAt = &resolvedAt
+			}
+		}
+		if !hasFiring(incident) {
+			changes = append(changes, g.closeLocked(incident, now))
+		}
+	}
+	listeners := g.listeners
+	g.mu.Unlock()
+
+	notifyListeners(listeners, changes)
+}
+
+// Acknowledge closes an open incident that was acknowledged elsewhere, such
+// as in an on-call tool. Its child alerts are marked acknowledged and stop
+// firing; a later alert with the same grouping opens a new incident.
+func (g *AlertGrouper) Acknowledge(incidentID, by string, now time.Time) bool {
+	g.mu.Lock()
+	var changes []incidentChange
+	for _, incident := range g.open {
+		if incident.ID != incidentID {
+			continue
+		}
+		for i := range incident.Alerts {
+			child := &incident.Alerts[i]
+			child.Alert.Acknowledged = true
+			if child.Firing {
+				resolvedAt := now
+				child.Firing = false
+				child.ResolvedAt = &resolvedAt
+			}
+		}
+		incident.AckedBy = by
+		changes = append(changes, g.closeLocked(incident, now))
+		break
+	}
+	listeners := g.listeners
+	g.mu.Unlock()
+
+	notifyListeners(listeners, changes)
+	return len(changes) > 0
+}
+
+// AttachBurst adds a burst capture to an open or recently resolved incident,
+// keeping the most recent captures
+func (g *AlertGrouper) AttachBurst(incidentID string, burst gpu.BurstCapture) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	incident := g.findLocked(incidentID)
+	if incident == nil {
+		return false
+	}
+	incident.Bursts = append(incident.Bursts, burst)
+	if len(incident.Bursts) > maxIncidentBursts {
+		incident.Bursts = incident.Bursts[len(incident.Bursts)-maxIncidentBursts:]
+	}
+	return true
+}
+
+// findLocked returns an open or recently resolved incident by ID; callers must hold the lock
+func (g *AlertGrouper) findLocked(id string) *Incident {
+	for _, incident := range g.open {
+		if incident.ID == id {
+			return incident
+		}
+	}
+	for _, incident := range g.resolved {
+		if incident.ID == id {
+			return incident
+		}
+	}
+	return nil
+}
+
+// Sweep resolves open incidents that have no firing alerts and have been
+// idle for the grouping window
+func (g *AlertGrouper) Sweep(now time.Time) {
+	g.mu.Lock()
+	changes := g.sweepLocked(now)
+	listeners := g.listeners
+	g.mu.Unlock()
+
+	notifyListeners(listeners, changes)
+}
+
+// Incidents returns open incidents, most severe first, followed by recently
+// resolved ones when includeResolved is set
+func (g *AlertGrouper) Incidents(includeResolved bool) []Incident {
+	g.Sweep(time.Now())
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	incidents := make([]Incident, 0, len(g.open))
+	for _, incident := range g.open {
+		incidents = append(incidents, copyIncident(incident))
+	}
+	sort.Slice(incidents, func(i, j int) bool {
+		ri, rj := severityRank(incidents[i].Severity), severityRank(incidents[j].Severity)
+		if ri != rj {
+			return ri > rj
+		}
+		return incidents[i].StartedAt.Before(incidents[j].StartedAt)
+	})
+
+	if includeResolved {
+		for i := len(g.resolved) - 1; i >= 0; i-- {
+			incidents = append(incidents, copyIncident(g.resolved[i]))
+		}
+	}
+	return incidents
+}
+
+// GetIncident returns an open or recently resolved incident by ID
+func (g *AlertGrouper) GetIncident(id string) (Incident, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if incident := g.findLocked(id); incident != nil {
+		return copyIncident(incident), true
+	}
+	return Incident{}, false
+}
+
+// GetStats returns grouping statistics
+func (g *AlertGrouper) GetStats() map[string]interface{} {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return map[string]interface{}{
+		"open_incidents":     len(g.open),
+		"resolved_incidents": len(g.resolved),
+		"alerts_received":    g.received,
+		"notifications_sent": g.notified,
+		"alerts_suppressed":  g.suppressed,
+		"group_by":           g.config.GroupBy,
+		"window_seconds":     g.config.Window.Seconds(),
+	}
+}
+
+// incidentChange is a pending listener notification
+type incidentChange struct {
+	incident Incident
+	change   string
+}
+
+// labelsFor returns the grouping labels for an alert
+func (g *AlertGrouper) labelsFor(gpuID, alertType string) map[string]string {
+	labels := make(map[string]string, len(g.config.GroupBy))
+	for _, dimension := range g.config.GroupBy {
+		switch dimension {
+		case GroupByNode:
+			labels[GroupByNode] = g.NodeFor(gpuID)
+		case GroupByType:
+			labels[GroupByType] = alertType
+		}
+	}
+	return labels
+}
+
+// addChild adds or replaces a child alert, keeping at most MaxChildren
+func (g *AlertGrouper) addChild(incident *Incident, child IncidentAlert) {
+	if child.Firing {
+		// A re-fired (escalated) alert replaces the one already firing
+		for i := range incident.Alerts {
+			existing := incident.Alerts[i]
+			if existing.Firing && existing.GPUID == child.GPUID && existing.Alert.Type == child.Alert.Type {
+				incident.Alerts[i] = child
+				return
+			}
+		}
+	}
+
+	incident.Alerts = append(incident.Alerts, child)
+	if g.config.MaxChildren > 0 && len(incident.Alerts) > g.config.MaxChildren {
+		// Drop the oldest resolved child, or the oldest child if all are firing
+		drop := 0
+		for i, existing := range incident.Alerts {
+			if !existing.Firing {
+				drop = i
+				break
+			}
+		}
+		incident.Alerts = append(incident.Alerts[:drop], incident.Alerts[drop+1:]...)
+	}
+}
+
+// sweepLocked resolves idle incidents without firing alerts. Callers hold g.mu.
+func (g *AlertGrouper) sweepLocked(now time.Time) []incidentChange {
+	var changes []incidentChange
+	for _, incident := range g.open {
+		if !hasFiring(incident) && now.Sub(incident.UpdatedAt) >= g.config.Window {
+			changes = append(changes, g.closeLocked(incident, now))
+		}
+	}
+	return changes
+}
+
+// closeLocked moves an incident to the resolved list. Callers hold g.mu.
+func (g *AlertGrouper) closeLocked(incident *Incident, now time.Time) incidentChange {
+	resolvedAt := now
+	incident.Status = IncidentResolved
+	incident.ResolvedAt = &resolvedAt
+	delete(g.open, incident.Key)
+
+	g.resolved = append(g.resolved, incident)
+	if g.config.MaxResolved > 0 && len(g.resolved) > g.config.MaxResolved {
+		g.resolved = g.resolved[len(g.resolved)-g.config.MaxResolved:]
+	}
+	return incidentChange{copyIncident(incident), IncidentClosed}
+}
+
+// notifyListeners delivers incident changes in order
+func notifyListeners(listeners []IncidentListener, changes []incidentChange) {
+	for _, change := range changes {
+		for _, listener := range listeners {
+			listener(change.incident, change.change)
+		}
+	}
+}
+
+// groupKey builds a stable key from grouping labels
+func groupKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+labels[k])
+	}
+	return strings.Join(parts, ",")
+}
+
+// hasFiring reports whether any child alert is still firing
+func hasFiring(incident *Incident) bool {
+	for _, child := range incident.Alerts {
+		if child.Firing {
+			return true
+		}
+	}
+	return false
+}
+
+// countGPUs returns the number of distinct GPUs among child alerts
+func countGPUs(alerts []IncidentAlert) int {
+	seen := make(map[string]bool)
+	for _, child := range alerts {
+		seen[child.GPUID] = true
+	}
+	return len(seen)
+}
+
+// incidentTitle summarizes an incident, e.g. "temperature alerts on 4 GPUs on node-a"
+func incidentTitle(incident *Incident) string {
+	subject := "alerts"
+	if alertType, ok := incident.Labels[GroupByType]; ok {
+		subject = alertType + " alerts"
+	}
+	gpus := "1 GPU"
+	if incident.GPUCount != 1 {
+		gpus = fmt.Sprintf("%d GPUs", incident.GPUCount)
+	}
+	title := fmt.Sprintf("%s on %s", subject, gpus)
+	if node, ok := incident.Labels[GroupByNode]; ok {
+		title += " on " + node
+	}
+	return strings.ToUpper(title[:1]) + title[1:]
+}
+
+// copyIncident returns a copy safe to hand out of the lock
+func copyIncident(incident *Incident) Incident {
+	copied := *incident
+	copied.Alerts = append([]IncidentAlert(nil), incident.Alerts...)
+	copied.Bursts = append([]gpu.BurstCapture(nil), incident.Bursts...)
+	copied.Labels = make(map[string]string, len(incident.Labels))
+	for k, v := range incident.Labels {
+		copied.Labels[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,229 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestAlertGrouperCollapsesNodeStorm(t *testing.T) {
+	grouper, err := NewAlertGrouper(DefaultAlertGroupingConfig())
+	if err != nil {
+		t.Fatalf("NewAlertGrouper failed: %v", err)
+	}
+
+	var changes []string
+	grouper.Subscribe(func(incident Incident, change string) {
+		changes = append(changes, incident.Key+" "+change)
+	})
+
+	now := time.Now()
+	alert := gpu.GPUAlert{Type: "temperature", Severity: "warning", Timestamp: now}
+	first := grouper.Add("node-a/gpu-0", "A100", alert, true)
+	for _, gpuID := range []string{"node-a/gpu-1", "node-a/gpu-2", "node-a/gpu-3"} {
+		if id := grouper.Add(gpuID, "A100", alert, true); id != first {
+			t.Errorf("Expected %s to join %s, got %s", gpuID, first, id)
+		}
+	}
+	if id := grouper.Add("node-b/gpu-0", "A100", alert, true); id == first {
+		t.Error("Expected a different node to open its own incident")
+	}
+
+	critical := alert
+	critical.Severity = "critical"
+	grouper.Add("node-a/gpu-2", "A100", critical, true)
+
+	expected := []string{
+		"node=node-a,type=temperature opened",
+		"node=node-b,type=temperature opened",
+		"node=node-a,type=temperature escalated",
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected notifications %v, got %v", expected, changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("Notification %d: expected %q, got %q", i, expected[i], changes[i])
+		}
+	}
+
+	incident, ok := grouper.GetIncident(first)
+	if !ok {
+		t.Fatalf("Incident %s not found", first)
+	}
+	if incident.GPUCount != 4 || len(incident.Alerts) != 4 || incident.AlertCount != 5 {
+		t.Errorf("Expected 4 GPUs, 4 children and 5 alerts, got %d, %d and %d", incident.GPUCount, len(incident.Alerts), incident.AlertCount)
+	}
+	if incident.Severity != "critical" || incident.Title != "Temperature alerts on 4 GPUs on node-a" {
+		t.Errorf("Unexpected incident %s: %s", incident.Severity, incident.Title)
+	}
+
+	stats := grouper.GetStats()
+	if stats["notifications_sent"] != 3 || stats["alerts_suppressed"] != 3 {
+		t.Errorf("Unexpected stats: %v", stats)
+	}
+
+	// The incident stays open until every child alert resolves
+	for _, gpuID := range []string{"node-a/gpu-0", "node-a/gpu-1", "node-a/gpu-2"} {
+		grouper.Resolve(gpuID, "temperature", now)
+	}
+	if incident, _ := grouper.GetIncident(first); incident.Status != IncidentOpen {
+		t.Fatal("Expected the incident to stay open while a child is firing")
+	}
+	grouper.Resolve("node-a/gpu-3", "temperature", now)
+	if incident, _ := grouper.GetIncident(first); incident.Status != IncidentResolved || incident.ResolvedAt == nil {
+		t.Errorf("Expected the incident to resolve, got %+v", incident)
+	}
+	if open := grouper.Incidents(false); len(open) != 1 {
+		t.Errorf("Expected only the node-b incident open, got %d", len(open))
+	}
+}
+
+func TestAlertGrouperExpiresOneShotIncidents(t *testing.T) {
+	config := DefaultAlertGroupingConfig()
+	config.GroupBy = []string{GroupByType}
+	config.Window = time.Minute
+	grouper, err := NewAlertGrouper(config)
+	if err != nil {
+		t.Fatalf("NewAlertGrouper failed: %v", err)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	alert := gpu.GPUAlert{Type: "process", Severity: "info", Timestamp: start}
+	first := grouper.Add("gpu-0", "T4", alert, false)
+
+	alert.Timestamp = start.Add(30 * time.Second)
+	if id := grouper.Add("other-node/gpu-1", "T4", alert, false); id != first {
+		t.Error("Expected alerts to group by type only")
+	}
+
+	alert.Timestamp = start.Add(2 * time.Minute)
+	if id := grouper.Add("gpu-0", "T4", alert, false); id == first {
+		t.Error("Expected a new incident after the idle window")
+	}
+	if incident, _ := grouper.GetIncident(first); incident.Status != IncidentResolved {
+		t.Errorf("Expected the idle incident to resolve, got %s", incident.Status)
+	}
+
+	if _, err := NewAlertGrouper(AlertGroupingConfig{GroupBy: []string{"rack"}}); err == nil {
+		t.Error("Expected an unknown grouping dimension to be rejected")
+	}
+}
+
+func TestIntegrationGroupsAlertsIntoIncidents(t *testing.T) {
+	monitor := NewMonitoringService(100)
+	integration := NewGPUMetricsIntegration(monitor, nil)
+	grouper, err := NewAlertGrouper(DefaultAlertGroupingConfig())
+	if err != nil {
+		t.Fatalf("NewAlertGrouper failed: %v", err)
+	}
+	integration.SetAlertGrouper(grouper)
+
+	dashboard := NewWebDashboard(monitor, nil, nil, WebDashboardConfig{Port: 0})
+	dashboard.SetAlertGrouper(grouper)
+
+	start := time.Now().Add(-time.Minute)
+	for _, gpuID := range []string{"node-a/gpu-0", "node-a/gpu-1", "node-a/gpu-2"} {
+		sample := temperatureSample(start, 80)
+		sample.GPUID = gpuID
+		integration.processGPUMetrics(sample)
+	}
+
+	events := monitor.GetEvents(start.Add(-time.Second), time.Now(), "")
+	alertEvents, incidentEvents := 0, 0
+	for _, event := range events {
+		switch event.Type {
+		case "gpu_alert":
+			alertEvents++
+			if event.Metadata["incident_id"] == nil {
+				t.Errorf("Expected alert event to reference its incident: %+v", event.Metadata)
+			}
+		case "gpu_incident":
+			incidentEvents++
+		}
+	}
+	if alertEvents != 3 || incidentEvents != 1 {
+		t.Errorf("Expected 3 alert events and 1 incident event, got %d and %d", alertEvents, incidentEvents)
+	}
+
+	response := serveDashboard(dashboard, http.MethodGet, "/api/v1/incidents", "")
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected 200 from incidents endpoint, got %d", response.Code)
+	}
+	var body struct {
+		Incidents []Incident `json:"incidents"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode incidents: %v", err)
+	}
+	if len(body.Incidents) != 1 || len(body.Incidents[0].Alerts) != 3 {
+		t.Fatalf("Expected one incident with 3 alerts, got %+v", body.Incidents)
+	}
+
+	// Cooling every GPU resolves the incident
+	for _, gpuID := range []string{"node-a/gpu-0", "node-a/gpu-1", "node-a/gpu-2"} {
+		sample := temperatureSample(start.Add(10*time.Second), 60)
+		sample.GPUID = gpuID
+		integration.processGPUMetrics(sample)
+	}
+	response = serveDashboard(dashboard, http.MethodGet, "/api/v1/incidents/"+body.Incidents[0].ID, "")
+	var incident Incident
+	if err := json.NewDecoder(response.Body).Decode(&incident); err != nil {
+		t.Fatalf("Failed to decode incident: %v", err)
+	}
+	if incident.Status != IncidentResolved {
+		t.Errorf("Expected the incident to resolve, got %s", incident.Status)
+	}
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/incidents?status=bogus", ""); response.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid status, got %d", response.Code)
+	}
+}
+
+// burstingCollector returns a fixed burst for any GPU
+type burstingCollector struct {
+	*gpu.MockMetricsCollector
+}
+
+func (c burstingCollector) CaptureBurst(ctx context.Context, gpuID string, config gpu.BurstConfig) (gpu.BurstCapture, error) {
+	samples := make([]gpu.GPUMetrics, config.Samples)
+	for i := range samples {
+		samples[i] = gpu.GPUMetrics{GPUID: gpuID, Temperature: 85}
+	}
+	return gpu.BurstCapture{GPUID: gpuID, Interval: config.Interval.String(), Samples: samples}, nil
+}
+
+func TestAlertBurstAttachedToIncident(t *testing.T) {
+	monitor := NewMonitoringService(100)
+	integration := NewGPUMetricsIntegration(monitor, burstingCollector{gpu.NewMockMetricsCollector(time.Second, 1)})
+	grouper, err := NewAlertGrouper(DefaultAlertGroupingConfig())
+	if err != nil {
+		t.Fatalf("NewAlertGrouper failed: %v", err)
+	}
+	integration.SetAlertGrouper(grouper)
+	integration.EnableBurstCapture(gpu.BurstConfig{Samples: 4, Interval: 100 * time.Millisecond})
+
+	integration.processGPUMetrics(temperatureSample(time.Now(), 80))
+	incidents := grouper.Incidents(false)
+	if len(incidents) != 1 {
+		t.Fatalf("expected one incident, got %d", len(incidents))
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		incident, _ := grouper.GetIncident(incidents[0].ID)
+		if len(incident.Bursts) == 1 {
+			if burst := incident.Bursts[0]; burst.GPUID != "gpu-0" || len(burst.Samples) != 4 || burst.Interval != "100ms" {
+				t.Errorf("unexpected burst %+v", burst)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("burst was not attached to the incident")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
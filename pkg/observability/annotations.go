@@ -0,0 +1,229 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxAnnotations is how many annotations a store keeps when no limit
+// is given; the oldest are dropped first
+const DefaultMaxAnnotations = 10000
+
+// Annotation marks a moment or a period on metric charts, e.g. "model v2
+// deployed" or a driver upgrade window
+type Annotation struct {
+	ID        string     `json:"id"`
+	Time      time.Time  `json:"time"`
+	End       *time.Time `json:"end,omitempty"` // Set for a period rather than a moment
+	Text      string     `json:"text"`
+	Tags      []string   `json:"tags,omitempty"`
+	Author    string     `json:"author,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Validate checks the annotation has text and a time, ends after it starts
+// and has no empty tags
+func (a Annotation) Validate() error {
+	var errs ConfigErrors
+	if strings.TrimSpace(a.Text) == "" {
+		errs.add("text", "must not be empty")
+	}
+	if a.Time.IsZero() {
+		errs.add("time", "must be set")
+	}
+	if a.End != nil && a.End.Before(a.Time) {
+		errs.add("end", "must not be before time")
+	}
+	for i, tag := range a.Tags {
+		if strings.TrimSpace(tag) == "" {
+			errs.add(fmt.Sprintf("tags[%d]", i), "must not be empty")
+		}
+	}
+	return errs.err()
+}
+
+// overlaps reports whether the annotation falls in or spans part of
+// [start, end]
+func (a Annotation) overlaps(start, end time.Time) bool {
+	last := a.Time
+	if a.End != nil {
+		last = *a.End
+	}
+	return !a.Time.After(end) && !last.Before(start)
+}
+
+// hasTags reports whether the annotation carries every tag
+func (a Annotation) hasTags(tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, candidate := range a.Tags {
+			found = found || candidate == tag
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// AnnotationStore keeps annotations server-side, optionally persisted to a
+// JSON file
+type AnnotationStore struct {
+	path        string
+	max         int
+	nextID      int
+	annotations []Annotation // Sorted by time
+	mu          sync.RWMutex
+}
+
+// annotationFile is the persisted form of a store
+type annotationFile struct {
+	NextID      int          `json:"next_id"`
+	Annotations []Annotation `json:"annotations"`
+}
+
+// NewAnnotationStore creates a store keeping up to max annotations, loading
+// those saved at path. An empty path keeps annotations in memory only; max
+// 0 keeps DefaultMaxAnnotations.
+func NewAnnotationStore(path string, max int) (*AnnotationStore, error) {
+	if max <= 0 {
+		max = DefaultMaxAnnotations
+	}
+	store := &AnnotationStore{path: path, max: max, nextID: 1, annotations: make([]Annotation, 0)}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read annotations: %w", err)
+	}
+	var saved annotationFile
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to decode annotations: %w", err)
+	}
+	store.annotations = append(store.annotations, saved.Annotations...)
+	store.sort()
+	if saved.NextID > store.nextID {
+		store.nextID = saved.NextID
+	}
+	return store, nil
+}
+
+// Add validates and stores an annotation, assigning its ID and creation
+// time
+func (s *AnnotationStore) Add(annotation Annotation) (Annotation, error) {
+	if err := annotation.Validate(); err != nil {
+		return Annotation{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	annotation.ID = fmt.Sprintf("annotation-%d", s.nextID)
+	annotation.CreatedAt = time.Now()
+	previous := s.annotations
+	s.annotations = append(append(make([]Annotation, 0, len(previous)+1), previous...), annotation)
+	s.sort()
+	if len(s.annotations) > s.max {
+		s.annotations = s.annotations[len(s.annotations)-s.max:]
+	}
+	s.nextID++
+	if err := s.save(); err != nil {
+		s.annotations = previous
+		s.nextID--
+		return Annotation{}, err
+	}
+	return annotation, nil
+}
+
+// Get returns an annotation by ID
+func (s *AnnotationStore) Get(id string) (Annotation, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, annotation := range s.annotations {
+		if annotation.ID == id {
+			return annotation, true
+		}
+	}
+	return Annotation{}, false
+}
+
+// Delete removes an annotation, returning false when there was none
+func (s *AnnotationStore) Delete(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.annotations
+	kept := make([]Annotation, 0, len(previous))
+	for _, annotation := range previous {
+		if annotation.ID != id {
+			kept = append(kept, annotation)
+		}
+	}
+	if len(kept) == len(previous) {
+		return false, nil
+	}
+	s.annotations = kept
+	if err := s.save(); err != nil {
+		s.annotations = previous
+		return false, err
+	}
+	return true, nil
+}
+
+// Between returns the annotations in or overlapping [start, end] that carry
+// every one of tags, in time order
+func (s *AnnotationStore) Between(start, end time.Time, tags []string) []Annotation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]Annotation, 0)
+	for _, annotation := range s.annotations {
+		if annotation.overlaps(start, end) && annotation.hasTags(tags) {
+			result = append(result, annotation)
+		}
+	}
+	return result
+}
+
+// sort orders the annotations by time; callers must hold the lock
+func (s *AnnotationStore) sort() {
+	sort.SliceStable(s.annotations, func(i, j int) bool {
+		return s.annotations[i].Time.Before(s.annotations[j].Time)
+	})
+}
+
+// save atomically rewrites the annotations file; callers must hold the lock
+func (s *AnnotationStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(annotationFile{NextID: s.nextID, Annotations: s.annotations}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode annotations: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create annotations file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write annotations: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close annotations: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace annotations: %w", err)
+	}
+	return nil
+}
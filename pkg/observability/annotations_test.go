@@ -0,0 +1,78 @@
+package observability
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAnnotationValidate(t *testing.T) {
+	now := time.Now()
+	before := now.Add(-time.Minute)
+	for name, annotation := range map[string]Annotation{
+		"no text":       {Time: now},
+		"no time":       {Text: "deploy"},
+		"ends too soon": {Text: "upgrade", Time: now, End: &before},
+		"empty tag":     {Text: "deploy", Time: now, Tags: []string{"deploy", " "}},
+	} {
+		if err := annotation.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+func TestAnnotationStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "annotations.json")
+	store, err := NewAnnotationStore(path, 3)
+	if err != nil {
+		t.Fatalf("NewAnnotationStore failed: %v", err)
+	}
+
+	now := time.Now()
+	upgradeEnd := now.Add(-2 * time.Hour)
+	store.Add(Annotation{Text: "driver upgrade", Time: now.Add(-3 * time.Hour), End: &upgradeEnd, Tags: []string{"maintenance"}})
+	deploy, err := store.Add(Annotation{Text: "model v2 deployed", Time: now.Add(-time.Hour), Tags: []string{"deploy", "chat"}})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if deploy.ID != "annotation-2" || deploy.CreatedAt.IsZero() {
+		t.Errorf("Expected the second annotation to be numbered and stamped, got %+v", deploy)
+	}
+	if _, err := store.Add(Annotation{Text: ""}); err == nil {
+		t.Error("Expected an invalid annotation to be rejected")
+	}
+
+	// The upgrade window overlaps the range even though it started before it
+	if found := store.Between(now.Add(-150*time.Minute), now, nil); len(found) != 2 || found[0].Text != "driver upgrade" {
+		t.Errorf("Expected both annotations in time order, got %+v", found)
+	}
+	if found := store.Between(now.Add(-90*time.Minute), now, nil); len(found) != 1 {
+		t.Errorf("Expected only the deployment after the upgrade ended, got %+v", found)
+	}
+	if found := store.Between(now.Add(-4*time.Hour), now, []string{"deploy", "chat"}); len(found) != 1 || found[0].ID != deploy.ID {
+		t.Errorf("Expected tags to select the deployment, got %+v", found)
+	}
+
+	// The oldest annotations are dropped beyond the limit
+	store.Add(Annotation{Text: "incident", Time: now.Add(-30 * time.Minute)})
+	store.Add(Annotation{Text: "rollback", Time: now.Add(-10 * time.Minute)})
+	if found := store.Between(now.Add(-4*time.Hour), now, nil); len(found) != 3 || found[0].Text != "model v2 deployed" {
+		t.Errorf("Expected the upgrade to be dropped, got %+v", found)
+	}
+
+	// Annotations and numbering survive a restart
+	reloaded, err := NewAnnotationStore(path, 3)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if deleted, err := reloaded.Delete(deploy.ID); !deleted || err != nil {
+		t.Errorf("Expected the deployment to be deleted, got %v %v", deleted, err)
+	}
+	next, _ := reloaded.Add(Annotation{Text: "model v3 deployed", Time: now})
+	if next.ID != "annotation-5" {
+		t.Errorf("Expected numbering to continue after reload, got %s", next.ID)
+	}
+	if _, exists := reloaded.Get(deploy.ID); exists {
+		t.Error("Expected the deleted annotation to be gone")
+	}
+}
@@ -0,0 +1,15 @@
+//go:build airgap
+// +build airgap
+
+package observability
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed assets/vendor
+var vendoredAssets embed.FS
+
+// bundledAssets serves the dashboard's front-end libraries from the binary
+var bundledAssets, _ = fs.Sub(vendoredAssets, "assets/vendor")
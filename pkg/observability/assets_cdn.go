@@ -0,0 +1,10 @@
+//go:build !airgap
+// +build !airgap
+
+package observability
+
+import "io/fs"
+
+// bundledAssets is nil: the dashboard page loads its front-end libraries
+// from a CDN unless built with -tags airgap
+var bundledAssets fs.FS
@@ -0,0 +1,76 @@
+package observability
+
+import "sort"
+
+// DefaultBudgetAlertPercent is when a budget without AlertAtPercent alerts
+const DefaultBudgetAlertPercent = 80.0
+
+// Budget caps what a team is charged for GPU time in a month
+type Budget struct {
+	Name           string  `yaml:"name" json:"name"`
+	Team           string  `yaml:"team" json:"team"`                                   // Showback team the budget applies to
+	MonthlyLimit   float64 `yaml:"monthly_limit" json:"monthly_limit"`                 // In the pricing currency
+	AlertAtPercent float64 `yaml:"alert_at_percent" json:"alert_at_percent,omitempty"` // Of the limit, DefaultBudgetAlertPercent when zero
+}
+
+// Validate checks the budget's name, team, limit and alert percentage
+func (b Budget) Validate() error {
+	var errs ConfigErrors
+
+	if b.Name == "" {
+		errs.add("name", "must not be empty")
+	}
+	if b.Team == "" {
+		errs.add("team", "must not be empty")
+	}
+	if b.MonthlyLimit <= 0 {
+		errs.add("monthly_limit", "must be positive, got %g", b.MonthlyLimit)
+	}
+	if b.AlertAtPercent < 0 || b.AlertAtPercent > 100 {
+		errs.add("alert_at_percent", "must be between 0 and 100, got %g", b.AlertAtPercent)
+	}
+
+	return errs.err()
+}
+
+// BudgetStatus is a budget with what its team was charged this month
+type BudgetStatus struct {
+	Budget
+	Month       string  `json:"month"` // YYYY-MM
+	Currency    string  `json:"currency"`
+	Spent       float64 `json:"spent"`
+	PercentUsed float64 `json:"percent_used"`
+	Alerting    bool    `json:"alerting"` // Spent reached the alert percentage
+	Exceeded    bool    `json:"exceeded"`
+}
+
+// EvaluateBudgets compares budgets with the charges of a showback report,
+// ordered by budget name
+func EvaluateBudgets(budgets []Budget, report ShowbackReport) []BudgetStatus {
+	charges := make(map[string]float64, len(report.Teams))
+	for _, team := range report.Teams {
+		charges[team.Team] = team.Charges
+	}
+
+	statuses := make([]BudgetStatus, 0, len(budgets))
+	for _, budget := range budgets {
+		alertAt := budget.AlertAtPercent
+		if alertAt == 0 {
+			alertAt = DefaultBudgetAlertPercent
+		}
+		status := BudgetStatus{
+			Budget:   budget,
+			Month:    report.Month,
+			Currency: report.Currency,
+			Spent:    charges[budget.Team],
+		}
+		if budget.MonthlyLimit > 0 {
+			status.PercentUsed = status.Spent / budget.MonthlyLimit * 100
+		}
+		status.Alerting = status.PercentUsed >= alertAt
+		status.Exceeded = status.Spent > budget.MonthlyLimit
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
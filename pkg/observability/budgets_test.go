@@ -0,0 +1,45 @@
+package observability
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBudgetValidate(t *testing.T) {
+	if err := (Budget{Name: "search", Team: "search", MonthlyLimit: 500}).Validate(); err != nil {
+		t.Errorf("Expected a valid budget, got %v", err)
+	}
+	err := Budget{Name: "search", MonthlyLimit: -1, AlertAtPercent: 120}.Validate()
+	if err == nil {
+		t.Fatal("Expected an invalid budget")
+	}
+	for _, field := range []string{"team", "monthly_limit", "alert_at_percent"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("Expected %s to be reported, got %v", field, err)
+		}
+	}
+}
+
+func TestEvaluateBudgets(t *testing.T) {
+	report := ShowbackReport{
+		Month:    "2024-03",
+		Currency: "USD",
+		Teams:    []TeamShowback{{Team: "search", Charges: 450}, {Team: "vision", Charges: 120}},
+	}
+	statuses := EvaluateBudgets([]Budget{
+		{Name: "vision", Team: "vision", MonthlyLimit: 100, AlertAtPercent: 90},
+		{Name: "search", Team: "search", MonthlyLimit: 500},
+		{Name: "nlp", Team: "nlp", MonthlyLimit: 200},
+	}, report)
+
+	if len(statuses) != 3 || statuses[0].Name != "nlp" || statuses[0].Spent != 0 || statuses[0].Alerting {
+		t.Fatalf("Expected budgets ordered by name with nlp unspent, got %+v", statuses)
+	}
+	// 90% of the limit is past the default 80% alert
+	if search := statuses[1]; search.PercentUsed != 90 || !search.Alerting || search.Exceeded || search.Month != "2024-03" {
+		t.Errorf("Expected search alerting under its limit, got %+v", search)
+	}
+	if vision := statuses[2]; !vision.Exceeded || !vision.Alerting {
+		t.Errorf("Expected vision over its limit, got %+v", vision)
+	}
+}
@@ -0,0 +1,384 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// Capacity signal types
+const (
+	CapacityNeeded = "capacity_needed" // A pool's queue is deep or slow enough to add GPUs
+	CapacityExcess = "capacity_excess" // A pool has had idle GPUs and an empty queue long enough to remove some
+)
+
+// DefaultCapacitySubject prefixes the NATS subjects capacity signals are
+// published on, e.g. agentaflow.capacity.needed
+const DefaultCapacitySubject = "agentaflow.capacity"
+
+// CapacityWebhook is an endpoint notified of capacity signals, such as a
+// script calling a cloud API or a Karpenter provisioner adapter
+type CapacityWebhook struct {
+	Name   string   `yaml:"name" json:"name"`
+	URL    string   `yaml:"url" json:"url"`
+	Secret string   `yaml:"secret" json:"secret" secret:"true"` // Signs payloads with HMAC-SHA256 in X-AgentaFlow-Signature
+	Events []string `yaml:"events" json:"events"`               // capacity_needed, capacity_excess; both when empty
+}
+
+// CapacitySignalConfig configures when pools signal that they need more or
+// fewer GPUs, and where the signals go
+type CapacitySignalConfig struct {
+	Interval time.Duration `yaml:"interval" json:"interval"`
+	Pools    []string      `yaml:"pools" json:"pools"` // Pools to watch; all when empty
+
+	// A pool needs capacity once it has NeededPending pending workloads, its
+	// projected wait exceeds NeededWait, or a pending workload fits none of
+	// its GPUs. Zero disables the first two triggers.
+	NeededPending int           `yaml:"needed_pending" json:"needed_pending"`
+	NeededWait    time.Duration `yaml:"needed_wait" json:"needed_wait"`
+
+	// A pool has excess capacity once more than ExcessIdleGPUs GPUs have been
+	// idle with nothing pending for ExcessFor. Zero ExcessFor disables it.
+	ExcessIdleGPUs int           `yaml:"excess_idle_gpus" json:"excess_idle_gpus"`
+	ExcessFor      time.Duration `yaml:"excess_for" json:"excess_for"`
+
+	// Signals repeat at most this often while their condition holds
+	Cooldown time.Duration `yaml:"cooldown" json:"cooldown"`
+
+	Webhooks   []CapacityWebhook `yaml:"webhooks" json:"webhooks"`
+	NATS       NATSConfig        `yaml:"nats" json:"nats"`
+	Timeout    time.Duration     `yaml:"timeout" json:"timeout"`
+	MaxRetries int               `yaml:"max_retries" json:"max_retries"` // Webhook retries after network errors, 429 and 5xx responses
+	RetryDelay time.Duration     `yaml:"retry_delay" json:"retry_delay"` // Doubles after each retry
+}
+
+// DefaultCapacitySignalConfig signals when 10 workloads are pending or the
+// projected wait exceeds 15 minutes, and when GPUs idle for 30 minutes
+func DefaultCapacitySignalConfig() CapacitySignalConfig {
+	return CapacitySignalConfig{
+		Interval:      30 * time.Second,
+		NeededPending: 10,
+		NeededWait:    15 * time.Minute,
+		ExcessFor:     30 * time.Minute,
+		Cooldown:      5 * time.Minute,
+		Timeout:       10 * time.Second,
+		MaxRetries:    3,
+		RetryDelay:    time.Second,
+	}
+}
+
+// CapacityEvent is a capacity signal for one pool
+type CapacityEvent struct {
+	Type string `json:"type"`
+	gpu.PoolCapacity
+	// GPUs to add for capacity_needed, or that could be removed for
+	// capacity_excess
+	SuggestedGPUs int    `json:"suggested_gpus"`
+	Reason        string `json:"reason"`
+}
+
+// capacityState tracks one pool between checks
+type capacityState struct {
+	idleSince  time.Time            // Since when the pool has had excess idle GPUs
+	lastSignal map[string]time.Time // Per signal type, while its condition holds
+}
+
+// CapacitySignals turns scheduler queue depth and idle GPUs into capacity
+// signals that external provisioners consume to add or remove GPU nodes
+type CapacitySignals struct {
+	config    CapacitySignalConfig
+	scheduler *gpu.Scheduler
+	sender    webhookSender
+	nats      *NATSPublisher
+
+	pools     map[string]*capacityState
+	recent    []CapacityEvent
+	delivered int
+	failed    int
+	lastError string
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	mu        sync.Mutex
+}
+
+// maxRecentCapacityEvents bounds the signals kept for the dashboard
+const maxRecentCapacityEvents = 50
+
+// NewCapacitySignals validates the configuration; zero intervals, timeouts
+// and delays use the defaults
+func NewCapacitySignals(config CapacitySignalConfig, scheduler *gpu.Scheduler) (*CapacitySignals, error) {
+	if scheduler == nil {
+		return nil, fmt.Errorf("scheduler is required")
+	}
+	defaults := DefaultCapacitySignalConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = defaults.RetryDelay
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	for i, webhook := range config.Webhooks {
+		parsed, err := url.Parse(webhook.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("capacity webhook %d: invalid URL %q", i, webhook.URL)
+		}
+		for _, event := range webhook.Events {
+			if event != CapacityNeeded && event != CapacityExcess {
+				return nil, fmt.Errorf("capacity webhook %d: unknown event %q", i, event)
+			}
+		}
+	}
+
+	if config.NATS.URL != "" && config.NATS.Subject == "" {
+		config.NATS.Subject = DefaultCapacitySubject
+	}
+
+	signals := &CapacitySignals{
+		config:    config,
+		scheduler: scheduler,
+		sender:    newWebhookSender(config.Timeout, config.MaxRetries, config.RetryDelay),
+		pools:     make(map[string]*capacityState),
+	}
+	if config.NATS.URL != "" {
+		publisher, err := NewNATSPublisher(config.NATS)
+		if err != nil {
+			return nil, err
+		}
+		signals.nats = publisher
+	}
+	return signals, nil
+}
+
+// Check evaluates every watched pool and publishes the signals due
+func (cs *CapacitySignals) Check() []CapacityEvent {
+	events := cs.evaluate(cs.scheduler.GetCapacity(), time.Now())
+	for _, event := range events {
+		cs.publish(event)
+	}
+	return events
+}
+
+// evaluate decides which pools signal at now
+func (cs *CapacitySignals) evaluate(capacities []gpu.PoolCapacity, now time.Time) []CapacityEvent {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	var events []CapacityEvent
+	for _, capacity := range capacities {
+		if !cs.watches(capacity.Pool) {
+			continue
+		}
+		state, exists := cs.pools[capacity.Pool]
+		if !exists {
+			state = &capacityState{lastSignal: make(map[string]time.Time)}
+			cs.pools[capacity.Pool] = state
+		}
+
+		if reason := cs.neededReason(capacity); reason != "" {
+			suggested := capacity.Pending - capacity.IdleGPUs
+			if suggested < 1 {
+				suggested = 1
+			}
+			events = cs.signal(events, state, CapacityNeeded, capacity, suggested, reason, now)
+		} else {
+			delete(state.lastSignal, CapacityNeeded)
+		}
+
+		excess := capacity.IdleGPUs - cs.config.ExcessIdleGPUs
+		if cs.config.ExcessFor <= 0 || capacity.Pending > 0 || excess <= 0 {
+			state.idleSince = time.Time{}
+			delete(state.lastSignal, CapacityExcess)
+			continue
+		}
+		if state.idleSince.IsZero() {
+			state.idleSince = now
+		}
+		if idle := now.Sub(state.idleSince); idle >= cs.config.ExcessFor {
+			reason := fmt.Sprintf("%d GPUs idle with nothing pending for %s", capacity.IdleGPUs, idle.Round(time.Second))
+			events = cs.signal(events, state, CapacityExcess, capacity, excess, reason, now)
+		}
+	}
+
+	cs.recent = append(cs.recent, events...)
+	if len(cs.recent) > maxRecentCapacityEvents {
+		cs.recent = cs.recent[len(cs.recent)-maxRecentCapacityEvents:]
+	}
+	return events
+}
+
+// neededReason explains why a pool needs capacity, or returns ""
+func (cs *CapacitySignals) neededReason(capacity gpu.PoolCapacity) string {
+	switch {
+	case capacity.Unplaceable > 0:
+		return fmt.Sprintf("%d pending workloads fit none of the pool's GPUs", capacity.Unplaceable)
+	case cs.config.NeededPending > 0 && capacity.Pending >= cs.config.NeededPending:
+		return fmt.Sprintf("%d workloads pending", capacity.Pending)
+	case cs.config.NeededWait > 0 && capacity.ProjectedWait > cs.config.NeededWait:
+		return fmt.Sprintf("projected wait %s exceeds %s", capacity.ProjectedWait.Round(time.Second), cs.config.NeededWait)
+	}
+	return ""
+}
+
+// signal appends an event unless one of the same type was sent within the
+// cooldown; callers must hold the lock
+func (cs *CapacitySignals) signal(events []CapacityEvent, state *capacityState, eventType string, capacity gpu.PoolCapacity, suggested int, reason string, now time.Time) []CapacityEvent {
+	if last, sent := state.lastSignal[eventType]; sent && now.Sub(last) < cs.config.Cooldown {
+		return events
+	}
+	state.lastSignal[eventType] = now
+	capacity.Timestamp = now
+	return append(events, CapacityEvent{
+		Type:          eventType,
+		PoolCapacity:  capacity,
+		SuggestedGPUs: suggested,
+		Reason:        reason,
+	})
+}
+
+// watches reports whether a pool is configured to signal
+func (cs *CapacitySignals) watches(pool string) bool {
+	if len(cs.config.Pools) == 0 {
+		return true
+	}
+	for _, name := range cs.config.Pools {
+		if name == pool {
+			return true
+		}
+	}
+	return false
+}
+
+// publish sends an event to the webhooks subscribed to it and to NATS
+func (cs *CapacitySignals) publish(event CapacityEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		cs.record(fmt.Errorf("failed to encode %s event: %v", event.Type, err))
+		return
+	}
+
+	delivery := fmt.Sprintf("%s-%s-%d", event.Pool, event.Type, event.Timestamp.UnixNano())
+	for _, webhook := range cs.config.Webhooks {
+		if !webhook.wants(event.Type) {
+			continue
+		}
+		cs.record(cs.deliver(webhook, event.Type, delivery, payload))
+	}
+	if cs.nats != nil {
+		subject := cs.config.NATS.Subject + ".needed"
+		if event.Type == CapacityExcess {
+			subject = cs.config.NATS.Subject + ".excess"
+		}
+		cs.record(cs.nats.Publish(subject, payload))
+	}
+}
+
+// wants reports whether a webhook subscribes to a signal type
+func (webhook CapacityWebhook) wants(eventType string) bool {
+	if len(webhook.Events) == 0 {
+		return true
+	}
+	for _, event := range webhook.Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// deliver posts a signal to a webhook
+func (cs *CapacitySignals) deliver(webhook CapacityWebhook, eventType, delivery string, payload []byte) error {
+	if err := cs.sender.send(webhook.URL, webhook.Secret, eventType, delivery, payload); err != nil {
+		return fmt.Errorf("capacity webhook %s: %v", webhookName(webhook.Name, webhook.URL), err)
+	}
+	return nil
+}
+
+// record counts a delivery outcome
+func (cs *CapacitySignals) record(err error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if err != nil {
+		cs.failed++
+		cs.lastError = err.Error()
+		return
+	}
+	cs.delivered++
+}
+
+// Recent returns the latest signals, oldest first
+func (cs *CapacitySignals) Recent() []CapacityEvent {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return append([]CapacityEvent{}, cs.recent...)
+}
+
+// Start begins periodic checks
+func (cs *CapacitySignals) Start() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.stopCh != nil {
+		return
+	}
+	cs.stopCh = make(chan struct{})
+	cs.doneCh = make(chan struct{})
+	go cs.run(cs.stopCh, cs.doneCh)
+}
+
+// Stop halts periodic checks and closes the NATS connection
+func (cs *CapacitySignals) Stop() {
+	cs.mu.Lock()
+	stopCh, doneCh := cs.stopCh, cs.doneCh
+	cs.stopCh, cs.doneCh = nil, nil
+	cs.mu.Unlock()
+
+	if stopCh != nil {
+		close(stopCh)
+		<-doneCh
+	}
+	if cs.nats != nil {
+		cs.nats.Close()
+	}
+}
+
+// run checks capacity on every interval until stopped
+func (cs *CapacitySignals) run(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(cs.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			cs.Check()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// GetStats returns delivery counts and the last failure
+func (cs *CapacitySignals) GetStats() map[string]interface{} {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return map[string]interface{}{
+		"interval_seconds": cs.config.Interval.Seconds(),
+		"running":          cs.stopCh != nil,
+		"webhooks":         len(cs.config.Webhooks),
+		"nats":             cs.nats != nil,
+		"delivered":        cs.delivered,
+		"failed":           cs.failed,
+		"last_error":       cs.lastError,
+	}
+}
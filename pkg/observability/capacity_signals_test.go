@@ -0,0 +1,143 @@
+package observability
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestCapacitySignalsNeededAndExcess(t *testing.T) {
+	config := DefaultCapacitySignalConfig()
+	config.NeededPending = 3
+	config.ExcessIdleGPUs = 1
+	config.Pools = []string{"batch"}
+	signals, err := NewCapacitySignals(config, gpu.NewScheduler(gpu.StrategyLeastUtilized))
+	if err != nil {
+		t.Fatalf("Failed to create capacity signals: %v", err)
+	}
+
+	start := time.Now()
+	busy := []gpu.PoolCapacity{
+		{Pool: "batch", GPUs: 2, Pending: 5},
+		{Pool: "ignored", GPUs: 1, Pending: 50},
+	}
+	events := signals.evaluate(busy, start)
+	if len(events) != 1 || events[0].Type != CapacityNeeded || events[0].Pool != "batch" || events[0].SuggestedGPUs != 5 {
+		t.Fatalf("Expected capacity_needed for batch suggesting 5 GPUs, got %+v", events)
+	}
+
+	// The cooldown holds back repeats while the queue stays deep
+	if events := signals.evaluate(busy, start.Add(time.Minute)); len(events) != 0 {
+		t.Errorf("Expected no repeat within the cooldown, got %+v", events)
+	}
+	if events := signals.evaluate(busy, start.Add(6*time.Minute)); len(events) != 1 {
+		t.Errorf("Expected a repeat after the cooldown, got %+v", events)
+	}
+
+	slow := []gpu.PoolCapacity{{Pool: "batch", GPUs: 2, Pending: 1, ProjectedWait: time.Hour}}
+	if events := signals.evaluate(slow, start.Add(7*time.Minute)); len(events) != 0 {
+		t.Errorf("Expected the cooldown to span triggers, got %+v", events)
+	}
+
+	// Idle GPUs signal excess only after ExcessFor, beyond the headroom kept
+	idle := []gpu.PoolCapacity{{Pool: "batch", GPUs: 4, IdleGPUs: 3}}
+	idleAt := start.Add(10 * time.Minute)
+	if events := signals.evaluate(idle, idleAt); len(events) != 0 {
+		t.Errorf("Expected no excess signal before ExcessFor, got %+v", events)
+	}
+	events = signals.evaluate(idle, idleAt.Add(config.ExcessFor))
+	if len(events) != 1 || events[0].Type != CapacityExcess || events[0].SuggestedGPUs != 2 {
+		t.Errorf("Expected capacity_excess suggesting 2 GPUs, got %+v", events)
+	}
+
+	// A pending workload resets the idle timer
+	signals.evaluate([]gpu.PoolCapacity{{Pool: "batch", GPUs: 4, IdleGPUs: 3, Pending: 1}}, idleAt.Add(config.ExcessFor+time.Minute))
+	if events := signals.evaluate(idle, idleAt.Add(config.ExcessFor+2*time.Minute)); len(events) != 0 {
+		t.Errorf("Expected the idle timer to restart, got %+v", events)
+	}
+	if recent := signals.Recent(); len(recent) != 3 {
+		t.Errorf("Expected 3 recent signals, got %d", len(recent))
+	}
+}
+
+func TestCapacitySignalsDeliverToWebhooks(t *testing.T) {
+	var mu sync.Mutex
+	var received []CapacityEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("X-AgentaFlow-Signature") != SignWebhookPayload("s3cret", body) {
+			t.Errorf("bad signature %q", r.Header.Get("X-AgentaFlow-Signature"))
+		}
+		var event CapacityEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+		if r.Header.Get("X-AgentaFlow-Event") != event.Type {
+			t.Errorf("Expected event header %s, got %q", event.Type, r.Header.Get("X-AgentaFlow-Event"))
+		}
+		mu.Lock()
+		received = append(received, event)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	config := gpu.DefaultSchedulerConfig()
+	config.Pools["batch"] = gpu.PoolConfig{}
+	scheduler := gpu.NewSchedulerWithConfig(gpu.StrategyLeastUtilized, config)
+	scheduler.RegisterGPU(&gpu.GPU{ID: "batch-0", Pool: "batch", MemoryTotal: 16000, Available: true})
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "too-big", Pool: "batch", MemoryRequired: 80000})
+
+	signals, err := NewCapacitySignals(CapacitySignalConfig{
+		Webhooks: []CapacityWebhook{
+			{Name: "provisioner", URL: server.URL, Secret: "s3cret", Events: []string{CapacityNeeded}},
+			{Name: "scale-down", URL: server.URL, Events: []string{CapacityExcess}},
+		},
+	}, scheduler)
+	if err != nil {
+		t.Fatalf("Failed to create capacity signals: %v", err)
+	}
+	events := signals.Check()
+	if len(events) != 1 || events[0].Unplaceable != 1 {
+		t.Fatalf("Expected a signal for the workload no GPU fits, got %+v", events)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 || received[0].Type != CapacityNeeded || received[0].PendingMemoryMB != 80000 {
+		t.Errorf("Expected capacity_needed delivered once, got %+v", received)
+	}
+	if stats := signals.GetStats(); stats["delivered"] != 1 || stats["failed"] != 0 {
+		t.Errorf("Unexpected stats %v", stats)
+	}
+
+	if _, err := NewCapacitySignals(CapacitySignalConfig{Webhooks: []CapacityWebhook{{URL: server.URL, Events: []string{"queued"}}}}, scheduler); err == nil {
+		t.Error("Expected unknown capacity event to be rejected")
+	}
+}
+
+func TestCapacityAPI(t *testing.T) {
+	scheduler := gpu.NewScheduler(gpu.StrategyLeastUtilized)
+	scheduler.RegisterGPU(&gpu.GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{Port: 0})
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/capacity", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a scheduler, got %d", response.Code)
+	}
+
+	dashboard.SetScheduler(scheduler)
+	response := serveDashboard(dashboard, http.MethodGet, "/api/v1/capacity", "")
+	var capacity struct {
+		Pools []gpu.PoolCapacity `json:"pools"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &capacity); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(capacity.Pools) != 1 || capacity.Pools[0].IdleGPUs != 1 {
+		t.Errorf("Expected one idle GPU, got %+v", capacity.Pools)
+	}
+}
@@ -0,0 +1,400 @@
+package observability
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving"
+)
+
+// CostImpactMetric is the ImpactMetric.Metric that compares spend rather
+// than a recorded metric
+const CostImpactMetric = "cost"
+
+// costBuckets is how many equal slices each window's spend is split into
+// to give the cost comparison samples
+const costBuckets = 12
+
+// ImpactMetric is a metric compared either side of an annotated change.
+// Regression is the direction that counts as worse: "increase" or
+// "decrease".
+type ImpactMetric struct {
+	Name       string `json:"name"`
+	Metric     string `json:"metric"` // Recorded metric name, or CostImpactMetric
+	Regression string `json:"regression"`
+}
+
+// DefaultImpactMetrics compares inference latency, GPU utilization and
+// spend
+func DefaultImpactMetrics() []ImpactMetric {
+	return []ImpactMetric{
+		{Name: "latency", Metric: "inference_latency_ms", Regression: "increase"},
+		{Name: "utilization", Metric: "gpu_utilization_percent", Regression: "decrease"},
+		{Name: "cost", Metric: CostImpactMetric, Regression: "increase"},
+	}
+}
+
+// ChangeImpactConfig sets the windows compared around each annotation and
+// when a change is a regression
+type ChangeImpactConfig struct {
+	Window           time.Duration  // Compared before and after each change
+	Alpha            float64        // Significance level of Welch's t-test
+	MinChangePercent float64        // Significant changes smaller than this are ignored
+	MinSamples       int            // Needed on both sides before testing
+	Metrics          []ImpactMetric // Defaults to DefaultImpactMetrics
+	Tags             []string       // Only annotations carrying every tag; empty for all
+}
+
+// DefaultChangeImpactConfig compares the hour either side of each change
+// and flags 5% or larger regressions significant at the 5% level
+func DefaultChangeImpactConfig() ChangeImpactConfig {
+	return ChangeImpactConfig{
+		Window:           time.Hour,
+		Alpha:            0.05,
+		MinChangePercent: 5,
+		MinSamples:       5,
+		Metrics:          DefaultImpactMetrics(),
+	}
+}
+
+// Validate checks the window, significance level and metrics
+func (c ChangeImpactConfig) Validate() error {
+	var errs ConfigErrors
+	if c.Window <= 0 {
+		errs.add("window", "must be positive")
+	}
+	if c.Alpha <= 0 || c.Alpha >= 1 {
+		errs.add("alpha", "must be between 0 and 1, got %g", c.Alpha)
+	}
+	if c.MinChangePercent < 0 {
+		errs.add("min_change_percent", "must not be negative")
+	}
+	if c.MinSamples < 2 {
+		errs.add("min_samples", "must be at least 2, got %d", c.MinSamples)
+	}
+	for i, metric := range c.Metrics {
+		if metric.Name == "" || metric.Metric == "" {
+			errs.add(fmt.Sprintf("metrics[%d]", i), "must have a name and a metric")
+		}
+		if metric.Regression != "increase" && metric.Regression != "decrease" {
+			errs.add(fmt.Sprintf("metrics[%d].regression", i), "must be increase or decrease, got %q", metric.Regression)
+		}
+	}
+	return errs.err()
+}
+
+// MetricImpact is how one metric moved across a change. PValue is nil when
+// either side had too few samples to test.
+type MetricImpact struct {
+	Name        string                `json:"name"`
+	Metric      string                `json:"metric"`
+	Before      serving.MetricSummary `json:"before"`
+	After       serving.MetricSummary `json:"after"`
+	Delta       float64               `json:"delta"`
+	Percent     *float64              `json:"percent"`
+	PValue      *float64              `json:"p_value"`
+	Significant bool                  `json:"significant"`
+	Regression  bool                  `json:"regression"`
+}
+
+// ChangeImpact compares the metrics before and after one annotated change.
+// Complete is false while the after window is still open.
+type ChangeImpact struct {
+	Annotation  Annotation     `json:"annotation"`
+	Before      TimeWindow     `json:"before"`
+	After       TimeWindow     `json:"after"`
+	Complete    bool           `json:"complete"`
+	Metrics     []MetricImpact `json:"metrics"`
+	Regressions []string       `json:"regressions"` // Readable descriptions
+}
+
+// impactWindows returns the window before an annotation starts and the
+// window after it ends
+func impactWindows(annotation Annotation, window time.Duration) (before, after TimeWindow) {
+	end := annotation.Time
+	if annotation.End != nil {
+		end = *annotation.End
+	}
+	before = TimeWindow{Start: annotation.Time.Add(-window), End: annotation.Time}
+	after = TimeWindow{Start: end, End: end.Add(window)}
+	return before, after
+}
+
+// AnalyzeChangeImpact compares each annotation's before and after windows,
+// flagging statistically significant moves in the regression direction.
+// Annotations missing config.Tags are skipped.
+func (ms *MonitoringService) AnalyzeChangeImpact(annotations []Annotation, config ChangeImpactConfig) ([]ChangeImpact, error) {
+	if len(config.Metrics) == 0 {
+		config.Metrics = DefaultImpactMetrics()
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	impacts := make([]ChangeImpact, 0, len(annotations))
+	for _, annotation := range annotations {
+		if !annotation.hasTags(config.Tags) {
+			continue
+		}
+		before, after := impactWindows(annotation, config.Window)
+		impact := ChangeImpact{
+			Annotation:  annotation,
+			Before:      before,
+			After:       after,
+			Complete:    !after.End.After(now),
+			Metrics:     make([]MetricImpact, 0, len(config.Metrics)),
+			Regressions: make([]string, 0),
+		}
+		for _, metric := range config.Metrics {
+			result := compareSamples(metric, ms.impactSamples(metric, before), ms.impactSamples(metric, after), config)
+			impact.Metrics = append(impact.Metrics, result)
+			if result.Regression {
+				impact.Regressions = append(impact.Regressions, result.describe())
+			}
+		}
+		impacts = append(impacts, impact)
+	}
+	return impacts, nil
+}
+
+// impactSamples returns a metric's values in a window, or for cost the
+// spend in each of costBuckets equal slices of it
+func (ms *MonitoringService) impactSamples(metric ImpactMetric, window TimeWindow) []float64 {
+	if metric.Metric != CostImpactMetric {
+		metrics := ms.GetMetrics(window.Start, window.End, metric.Metric)
+		values := make([]float64, 0, len(metrics))
+		for _, m := range metrics {
+			values = append(values, m.Value)
+		}
+		return values
+	}
+
+	buckets := make([]float64, costBuckets)
+	width := window.End.Sub(window.Start) / costBuckets
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	for _, cost := range ms.costs {
+		if cost.Timestamp.Before(window.Start) || !cost.Timestamp.Before(window.End) {
+			continue
+		}
+		bucket := costBuckets - 1
+		if width > 0 {
+			if i := int(cost.Timestamp.Sub(window.Start) / width); i < bucket {
+				bucket = i
+			}
+		}
+		buckets[bucket] += cost.Cost
+	}
+	return buckets
+}
+
+// compareSamples tests whether a metric's mean moved significantly between
+// the before and after samples
+func compareSamples(metric ImpactMetric, before, after []float64, config ChangeImpactConfig) MetricImpact {
+	result := MetricImpact{
+		Name:   metric.Name,
+		Metric: metric.Metric,
+		Before: serving.Summarize(before),
+		After:  serving.Summarize(after),
+	}
+	result.Delta = result.After.Mean - result.Before.Mean
+	if result.Before.Mean != 0 {
+		percent := result.Delta / math.Abs(result.Before.Mean) * 100
+		result.Percent = &percent
+	}
+	if result.Before.Count < config.MinSamples || result.After.Count < config.MinSamples {
+		return result
+	}
+
+	pValue := serving.WelchPValue(result.Before, result.After)
+	result.PValue = &pValue
+	large := result.Percent == nil || math.Abs(*result.Percent) >= config.MinChangePercent
+	result.Significant = pValue < config.Alpha && large && result.Delta != 0
+	worse := result.Delta > 0
+	if metric.Regression == "decrease" {
+		worse = result.Delta < 0
+	}
+	result.Regression = result.Significant && worse
+	return result
+}
+
+// describe renders a metric impact for readers, e.g. "latency up 25.0%
+// (120.00 -> 150.00, p=0.001)"
+func (m MetricImpact) describe() string {
+	direction := "up"
+	if m.Delta < 0 {
+		direction = "down"
+	}
+	change := fmt.Sprintf("%.2f", math.Abs(m.Delta))
+	if m.Percent != nil {
+		change = fmt.Sprintf("%.1f%%", math.Abs(*m.Percent))
+	}
+	pValue := math.NaN()
+	if m.PValue != nil {
+		pValue = *m.PValue
+	}
+	return fmt.Sprintf("%s %s %s (%.2f -> %.2f, p=%.3f)", m.Name, direction, change, m.Before.Mean, m.After.Mean, pValue)
+}
+
+// ChangeImpactMonitor periodically analyzes annotated changes once their
+// after window closes and records a warning event for each regression
+type ChangeImpactMonitor struct {
+	interval    time.Duration
+	lookback    time.Duration
+	config      ChangeImpactConfig
+	annotations *AnnotationStore
+	monitoring  *MonitoringService
+
+	analyzed    map[string]ChangeImpact // By annotation ID
+	regressions int
+	stopCh      chan struct{}
+	doneCh      chan struct{}
+	mu          sync.RWMutex
+}
+
+// NewChangeImpactMonitor creates a monitor that checks every interval for
+// changes annotated within lookback whose after window has closed
+func NewChangeImpactMonitor(interval, lookback time.Duration, config ChangeImpactConfig, annotations *AnnotationStore, monitoring *MonitoringService) (*ChangeImpactMonitor, error) {
+	if annotations == nil || monitoring == nil {
+		return nil, fmt.Errorf("annotations and monitoring are required")
+	}
+	if interval <= 0 || lookback <= 0 {
+		return nil, fmt.Errorf("change impact interval and lookback must be positive")
+	}
+	if len(config.Metrics) == 0 {
+		config.Metrics = DefaultImpactMetrics()
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &ChangeImpactMonitor{
+		interval:    interval,
+		lookback:    lookback,
+		config:      config,
+		annotations: annotations,
+		monitoring:  monitoring,
+		analyzed:    make(map[string]ChangeImpact),
+	}, nil
+}
+
+// Check analyzes each change whose after window closed since the last
+// check, returning the newly analyzed impacts
+func (m *ChangeImpactMonitor) Check() []ChangeImpact {
+	now := time.Now()
+	m.mu.RLock()
+	pending := make([]Annotation, 0)
+	for _, annotation := range m.annotations.Between(now.Add(-m.lookback), now, m.config.Tags) {
+		_, after := impactWindows(annotation, m.config.Window)
+		if _, done := m.analyzed[annotation.ID]; !done && !after.End.After(now) {
+			pending = append(pending, annotation)
+		}
+	}
+	m.mu.RUnlock()
+
+	impacts, _ := m.monitoring.AnalyzeChangeImpact(pending, m.config)
+
+	m.mu.Lock()
+	for _, impact := range impacts {
+		m.analyzed[impact.Annotation.ID] = impact
+		if len(impact.Regressions) > 0 {
+			m.regressions++
+		}
+	}
+	for id, impact := range m.analyzed {
+		if impact.After.End.Before(now.Add(-m.lookback)) {
+			delete(m.analyzed, id)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, impact := range impacts {
+		if len(impact.Regressions) == 0 {
+			continue
+		}
+		m.monitoring.RecordEvent(Event{
+			Type:     "change_regression",
+			Severity: "warning",
+			Message:  fmt.Sprintf("Regression after %q: %v", impact.Annotation.Text, impact.Regressions),
+			Source:   "change_impact_monitor",
+			Metadata: map[string]interface{}{
+				"annotation_id": impact.Annotation.ID,
+				"tags":          impact.Annotation.Tags,
+				"regressions":   impact.Regressions,
+			},
+		})
+	}
+	return impacts
+}
+
+// Impacts returns the analyzed changes still within the lookback, by
+// annotation ID
+func (m *ChangeImpactMonitor) Impacts() map[string]ChangeImpact {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	impacts := make(map[string]ChangeImpact, len(m.analyzed))
+	for id, impact := range m.analyzed {
+		impacts[id] = impact
+	}
+	return impacts
+}
+
+// Start begins periodic change analysis
+func (m *ChangeImpactMonitor) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stopCh != nil {
+		return
+	}
+	m.stopCh = make(chan struct{})
+	m.doneCh = make(chan struct{})
+	go m.run(m.stopCh, m.doneCh)
+}
+
+// Stop halts periodic change analysis
+func (m *ChangeImpactMonitor) Stop() {
+	m.mu.Lock()
+	stopCh, doneCh := m.stopCh, m.doneCh
+	m.stopCh, m.doneCh = nil, nil
+	m.mu.Unlock()
+
+	if stopCh == nil {
+		return
+	}
+	close(stopCh)
+	<-doneCh
+}
+
+// run analyzes changes on every interval until stopped
+func (m *ChangeImpactMonitor) run(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.Check()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// GetStats returns how many changes were analyzed and how many regressed
+func (m *ChangeImpactMonitor) GetStats() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return map[string]interface{}{
+		"interval_seconds": m.interval.Seconds(),
+		"running":          m.stopCh != nil,
+		"analyzed":         len(m.analyzed),
+		"regressions":      m.regressions,
+	}
+}
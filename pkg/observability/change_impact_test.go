@@ -0,0 +1,176 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// recordChange records latency and utilization samples either side of an
+// annotated change and returns the annotation
+func recordChange(t *testing.T, monitor *MonitoringService, store *AnnotationStore, tags []string, latencyBefore, latencyAfter, utilizationBefore, utilizationAfter []float64) Annotation {
+	record := func(latency, utilization []float64) {
+		for i := range latency {
+			monitor.RecordMetric(Metric{Name: "inference_latency_ms", Value: latency[i]})
+			monitor.RecordMetric(Metric{Name: "gpu_utilization_percent", Value: utilization[i]})
+		}
+	}
+	record(latencyBefore, utilizationBefore)
+	time.Sleep(2 * time.Millisecond)
+	annotation, err := store.Add(Annotation{Time: time.Now(), Text: "model v2 deployed", Tags: tags})
+	if err != nil {
+		t.Fatalf("Failed to add annotation: %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	record(latencyAfter, utilizationAfter)
+	return annotation
+}
+
+func TestAnalyzeChangeImpactFlagsRegressions(t *testing.T) {
+	monitor := NewMonitoringService(1000)
+	store, _ := NewAnnotationStore("", 0)
+	annotation := recordChange(t, monitor, store, []string{"deploy"},
+		[]float64{100, 102, 98, 101, 99, 100}, []float64{150, 152, 148, 151, 149, 150},
+		[]float64{80, 81, 79, 80, 82, 78}, []float64{81, 79, 80, 82, 78, 80})
+
+	config := DefaultChangeImpactConfig()
+	config.Window = 100 * time.Millisecond
+	impacts, err := monitor.AnalyzeChangeImpact([]Annotation{annotation}, config)
+	if err != nil {
+		t.Fatalf("AnalyzeChangeImpact failed: %v", err)
+	}
+	if len(impacts) != 1 || impacts[0].Complete {
+		t.Fatalf("Expected one change with its after window still open, got %+v", impacts)
+	}
+	metrics := make(map[string]MetricImpact)
+	for _, metric := range impacts[0].Metrics {
+		metrics[metric.Name] = metric
+	}
+	if latency := metrics["latency"]; !latency.Regression || latency.Delta != 50 || *latency.Percent != 50 {
+		t.Errorf("Expected a 50%% latency regression, got %+v", latency)
+	}
+	if utilization := metrics["utilization"]; utilization.Significant || utilization.PValue == nil {
+		t.Errorf("Expected utilization tested but unchanged, got %+v", utilization)
+	}
+	if cost := metrics["cost"]; cost.Before.Count != costBuckets || cost.Regression {
+		t.Errorf("Expected cost compared over %d buckets without a regression, got %+v", costBuckets, cost)
+	}
+	if len(impacts[0].Regressions) != 1 {
+		t.Errorf("Expected only latency to regress, got %v", impacts[0].Regressions)
+	}
+
+	// Too few samples are reported but never tested
+	config.MinSamples = 10
+	impacts, _ = monitor.AnalyzeChangeImpact([]Annotation{annotation}, config)
+	if latency := impacts[0].Metrics[0]; latency.PValue != nil || latency.Regression {
+		t.Errorf("Expected latency untested with too few samples, got %+v", latency)
+	}
+
+	config.Tags = []string{"incident"}
+	if impacts, _ := monitor.AnalyzeChangeImpact([]Annotation{annotation}, config); len(impacts) != 0 {
+		t.Errorf("Expected annotations without the tags skipped, got %+v", impacts)
+	}
+	config.Alpha = 2
+	if _, err := monitor.AnalyzeChangeImpact([]Annotation{annotation}, config); err == nil {
+		t.Error("Expected an invalid alpha to be rejected")
+	}
+}
+
+func TestAnalyzeChangeImpactUtilizationDrop(t *testing.T) {
+	monitor := NewMonitoringService(1000)
+	store, _ := NewAnnotationStore("", 0)
+	same := []float64{100, 102, 98, 101, 99, 100}
+	annotation := recordChange(t, monitor, store, nil, same, same,
+		[]float64{80, 81, 79, 80, 82, 78}, []float64{60, 61, 59, 60, 62, 58})
+
+	config := DefaultChangeImpactConfig()
+	config.Window = 100 * time.Millisecond
+	impacts, _ := monitor.AnalyzeChangeImpact([]Annotation{annotation}, config)
+	if utilization := impacts[0].Metrics[1]; !utilization.Regression || utilization.Delta != -20 {
+		t.Errorf("Expected a utilization drop flagged as a regression, got %+v", utilization)
+	}
+	if latency := impacts[0].Metrics[0]; latency.Significant {
+		t.Errorf("Expected unchanged latency, got %+v", latency)
+	}
+}
+
+func TestChangeImpactMonitorRecordsRegressionEvents(t *testing.T) {
+	monitor := NewMonitoringService(1000)
+	store, _ := NewAnnotationStore("", 0)
+	annotation := recordChange(t, monitor, store, []string{"deploy"},
+		[]float64{100, 102, 98, 101, 99, 100}, []float64{150, 152, 148, 151, 149, 150},
+		[]float64{80, 81, 79, 80, 82, 78}, []float64{80, 81, 79, 80, 82, 78})
+
+	config := DefaultChangeImpactConfig()
+	config.Window = 50 * time.Millisecond
+	impactMonitor, err := NewChangeImpactMonitor(time.Minute, time.Hour, config, store, monitor)
+	if err != nil {
+		t.Fatalf("NewChangeImpactMonitor failed: %v", err)
+	}
+	if impacts := impactMonitor.Check(); len(impacts) != 0 {
+		t.Errorf("Expected the change skipped while its after window is open, got %+v", impacts)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if impacts := impactMonitor.Check(); len(impacts) != 1 || !impacts[0].Complete {
+		t.Fatalf("Expected the change analyzed once its window closed, got %+v", impacts)
+	}
+	if impacts := impactMonitor.Check(); len(impacts) != 0 {
+		t.Errorf("Expected the change analyzed only once, got %+v", impacts)
+	}
+	if _, exists := impactMonitor.Impacts()[annotation.ID]; !exists {
+		t.Errorf("Expected the impact kept, got %+v", impactMonitor.Impacts())
+	}
+
+	events := monitor.GetEvents(annotation.Time, time.Now().Add(time.Second), "warning")
+	if len(events) != 1 || events[0].Type != "change_regression" || events[0].Metadata["annotation_id"] != annotation.ID {
+		t.Errorf("Expected one change_regression event, got %+v", events)
+	}
+	if stats := impactMonitor.GetStats(); stats["regressions"] != 1 || stats["analyzed"] != 1 {
+		t.Errorf("Unexpected stats %v", stats)
+	}
+
+	if _, err := NewChangeImpactMonitor(0, time.Hour, config, store, monitor); err == nil {
+		t.Error("Expected a zero interval to be rejected")
+	}
+}
+
+func TestChangeImpactEndpoint(t *testing.T) {
+	monitor := NewMonitoringService(1000)
+	dashboard := NewWebDashboard(monitor, nil, nil, WebDashboardConfig{Port: 0})
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/annotations/impact", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without an annotation store, got %d", response.Code)
+	}
+
+	store, _ := NewAnnotationStore("", 0)
+	dashboard.SetAnnotations(store)
+	recordChange(t, monitor, store, []string{"deploy"},
+		[]float64{100, 102, 98, 101, 99, 100}, []float64{150, 152, 148, 151, 149, 150},
+		[]float64{80, 81, 79, 80, 82, 78}, []float64{80, 81, 79, 80, 82, 78})
+
+	response := serveDashboard(dashboard, http.MethodGet, "/api/v1/annotations/impact?window=100ms&tags=deploy", "")
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", response.Code, response.Body.String())
+	}
+	var result struct {
+		Impacts     []ChangeImpact `json:"impacts"`
+		Regressions int            `json:"regressions"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to decode impacts: %v", err)
+	}
+	if len(result.Impacts) != 1 || result.Regressions != 1 {
+		t.Errorf("Expected one regressed change, got %+v", result)
+	}
+
+	for _, path := range []string{
+		"/api/v1/annotations/impact?window=soon",
+		"/api/v1/annotations/impact?alpha=high",
+		"/api/v1/annotations/impact?alpha=1.5",
+	} {
+		if response := serveDashboard(dashboard, http.MethodGet, path, ""); response.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", path, response.Code)
+		}
+	}
+}
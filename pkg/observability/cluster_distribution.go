@@ -0,0 +1,56 @@
+package observability
+
+import (
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// clusterQuantiles are the quantiles exported for cluster distributions
+var clusterQuantiles = []struct {
+	label string
+	value func(gpu.Distribution) float64
+}{
+	{"0.5", func(d gpu.Distribution) float64 { return d.P50 }},
+	{"0.9", func(d gpu.Distribution) float64 { return d.P90 }},
+	{"0.99", func(d gpu.Distribution) float64 { return d.P99 }},
+}
+
+// clusterDistributions returns the utilization and memory usage
+// distributions across the latest metrics of each GPU
+func clusterDistributions(latest map[string]gpu.GPUMetrics) (gpu.Distribution, gpu.Distribution) {
+	utilizations := make([]float64, 0, len(latest))
+	memoryUsage := make([]float64, 0, len(latest))
+	for _, metrics := range latest {
+		utilizations = append(utilizations, metrics.UtilizationGPU)
+		if metrics.MemoryTotal > 0 {
+			memoryUsage = append(memoryUsage, float64(metrics.MemoryUsed)/float64(metrics.MemoryTotal)*100)
+		}
+	}
+	return gpu.NewDistribution(utilizations), gpu.NewDistribution(memoryUsage)
+}
+
+// UpdateClusterDistribution exports cluster-wide utilization and memory
+// percentiles and imbalance coefficients
+func (pe *PrometheusExporter) UpdateClusterDistribution(utilization, memory gpu.Distribution) {
+	resources := []struct {
+		name         string
+		distribution gpu.Distribution
+	}{
+		{"utilization", utilization},
+		{"memory", memory},
+	}
+
+	for _, resource := range resources {
+		if resource.distribution.Count == 0 {
+			continue
+		}
+		for _, quantile := range clusterQuantiles {
+			pe.UpdateMetric("cluster_gpu_quantile_percent", quantile.value(resource.distribution),
+				map[string]string{"resource": resource.name, "quantile": quantile.label})
+		}
+		pe.UpdateMetric("cluster_gpu_imbalance_gini", resource.distribution.Gini,
+			map[string]string{"resource": resource.name})
+	}
+	if utilization.Count > 0 {
+		pe.UpdateMetric("cluster_utilization_percent", utilization.Mean, map[string]string{})
+	}
+}
@@ -0,0 +1,51 @@
+package observability
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestClusterDistributionExported(t *testing.T) {
+	monitor := NewMonitoringService(1000)
+	exporter := NewPrometheusExporter(monitor, DefaultPrometheusConfig())
+	exporter.RegisterGPUMetrics()
+	exporter.RegisterSystemMetrics()
+
+	integration := NewGPUMetricsIntegration(monitor, nil)
+	integration.SetPrometheusExporter(exporter)
+
+	dashboard := NewWebDashboard(monitor, nil, nil, WebDashboardConfig{Port: 0})
+	for i, utilization := range []float64{100, 0, 0, 0} {
+		metrics := gpu.GPUMetrics{
+			GPUID:          []string{"gpu-0", "gpu-1", "gpu-2", "gpu-3"}[i],
+			Name:           "A100",
+			UtilizationGPU: utilization,
+			MemoryTotal:    1000,
+			MemoryUsed:     500,
+			Timestamp:      time.Now(),
+		}
+		integration.processGPUMetrics(metrics)
+		dashboard.lastMetrics[metrics.GPUID] = metrics
+	}
+
+	output := exporter.ExportMetrics()
+	for _, want := range []string{
+		`agentaflow_cluster_gpu_quantile_percent{quantile="0.5",resource="utilization"} 0`,
+		`agentaflow_cluster_gpu_quantile_percent{quantile="0.99",resource="utilization"} 100`,
+		`agentaflow_cluster_gpu_imbalance_gini{resource="utilization"} 0.75`,
+		`agentaflow_cluster_gpu_imbalance_gini{resource="memory"} 0`,
+		`agentaflow_cluster_utilization_percent 25`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in exposition:\n%s", want, output)
+		}
+	}
+
+	stats := dashboard.calculateSystemStats()
+	if stats.Utilization.Gini != 0.75 || stats.Utilization.P90 != 100 || stats.Memory.P50 != 50 {
+		t.Errorf("Unexpected dashboard distributions %+v / %+v", stats.Utilization, stats.Memory)
+	}
+}
@@ -0,0 +1,376 @@
+package observability
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/secrets"
+)
+
+// ConfigBundle is configuration rolled out across a fleet as one signed,
+// versioned unit. Sections left out keep the running configuration.
+type ConfigBundle struct {
+	// Increases with every release; lower versions are refused as rollbacks
+	Version     int    `yaml:"version" json:"version"`
+	Description string `yaml:"description" json:"description,omitempty"`
+
+	AlertThresholds   *GPUAlertThresholds    `yaml:"alert_thresholds" json:"alert_thresholds,omitempty"`
+	ThresholdProfiles []GPUThresholdProfile  `yaml:"threshold_profiles" json:"threshold_profiles,omitempty"`
+	Costs             *GPUCostConfiguration  `yaml:"costs" json:"costs,omitempty"`
+	Dashboard         *DashboardLayoutConfig `yaml:"dashboard" json:"dashboard,omitempty"`
+	Scheduling        *gpu.SchedulingPolicy  `yaml:"scheduling" json:"scheduling,omitempty"`
+}
+
+// Validate checks the bundle version and every included section
+func (b ConfigBundle) Validate() error {
+	var errs ConfigErrors
+
+	if b.Version < 1 {
+		errs.add("version", "must be at least 1, got %d", b.Version)
+	}
+	nested := func(prefix string, err error) {
+		if err == nil {
+			return
+		}
+		sectionErrs, ok := err.(ConfigErrors)
+		if !ok {
+			errs.add(prefix, "%v", err)
+			return
+		}
+		for _, sectionErr := range sectionErrs {
+			sectionErr.Field = prefix + "." + sectionErr.Field
+			errs = append(errs, sectionErr)
+		}
+	}
+	if b.Costs != nil {
+		nested("costs", b.Costs.Validate())
+	}
+	if b.Dashboard != nil {
+		nested("dashboard", b.Dashboard.Validate())
+	}
+	if b.Scheduling != nil {
+		nested("scheduling", b.Scheduling.Validate())
+	}
+	for i, profile := range b.ThresholdProfiles {
+		if profile.Name == "" {
+			errs.add(fmt.Sprintf("threshold_profiles[%d].name", i), "must not be empty")
+		}
+	}
+
+	return errs.err()
+}
+
+// BundleConfig configures which configuration bundles are accepted
+type BundleConfig struct {
+	// Base64 Ed25519 public keys; a bundle must be signed by one of them
+	TrustedKeys []string `yaml:"trusted_keys" json:"trusted_keys"`
+
+	// Only this version is accepted when set, including rolling back to it
+	PinnedVersion int `yaml:"pinned_version" json:"pinned_version"`
+
+	// Keeps the highest version applied and its digest across restarts, so a
+	// restarted process still refuses rollbacks; in memory only when empty
+	StateFile string `yaml:"state_file" json:"state_file"`
+}
+
+// bundleState is what a loader keeps in its state file
+type bundleState struct {
+	HighestVersion int           `json:"highest_version"` // Highest version ever applied
+	HighestDigest  string        `json:"highest_digest"`  // Digest of the bundle applied at that version
+	InEffect       *BundleStatus `json:"in_effect,omitempty"`
+}
+
+// BundleStatus describes the configuration bundle in effect
+type BundleStatus struct {
+	Version   int       `json:"version"`
+	Digest    string    `json:"digest"` // SHA-256 of the bundle file
+	KeyID     string    `json:"key_id"` // Fingerprint of the key that signed it
+	Source    string    `json:"source"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// BundleTargets are the components a bundle configures; nil targets are skipped
+type BundleTargets struct {
+	Integration *GPUMetricsIntegration
+	Dashboard   *WebDashboard
+	Scheduler   *gpu.Scheduler
+}
+
+// VerifiedBundle is a bundle whose signature and version were checked
+type VerifiedBundle struct {
+	ConfigBundle
+	Digest string
+	KeyID  string
+	Source string
+}
+
+// BundleLoader verifies signed configuration bundles and applies them,
+// refusing versions older than the one in effect unless pinned
+type BundleLoader struct {
+	keys          []ed25519.PublicKey
+	pinnedVersion int
+	statePath     string
+	secrets       *secrets.Resolver // Optional, resolves secret references in bundles
+	state         bundleState
+	now           func() time.Time
+	mu            sync.Mutex
+}
+
+// NewBundleLoader creates a loader trusting the configured keys
+func NewBundleLoader(config BundleConfig) (*BundleLoader, error) {
+	if len(config.TrustedKeys) == 0 {
+		return nil, fmt.Errorf("at least one trusted key is required")
+	}
+	loader := &BundleLoader{pinnedVersion: config.PinnedVersion, statePath: config.StateFile, now: time.Now}
+	for i, encoded := range config.TrustedKeys {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("trusted key %d is not a base64 Ed25519 public key", i)
+		}
+		loader.keys = append(loader.keys, ed25519.PublicKey(key))
+	}
+
+	if loader.statePath != "" {
+		data, err := os.ReadFile(loader.statePath)
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			return nil, fmt.Errorf("failed to read bundle state: %w", err)
+		default:
+			if err := json.Unmarshal(data, &loader.state); err != nil {
+				return nil, fmt.Errorf("failed to decode bundle state %s: %w", loader.statePath, err)
+			}
+		}
+	}
+	return loader, nil
+}
+
+// saveState writes the loader's state file; l.mu must be held
+func (l *BundleLoader) saveState(state bundleState) error {
+	if l.statePath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bundle state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.statePath), filepath.Base(l.statePath)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create bundle state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write bundle state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close bundle state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), l.statePath); err != nil {
+		return fmt.Errorf("failed to replace bundle state: %w", err)
+	}
+	return nil
+}
+
+// checkVersionLocked refuses a bundle the version rules exclude: another
+// version than the pinned one, a version older than the highest applied
+// unless pinned, or the highest applied version with different contents.
+// l.mu must be held.
+func (l *BundleLoader) checkVersionLocked(version int, digest, source string) error {
+	if l.pinnedVersion != 0 && version != l.pinnedVersion {
+		return fmt.Errorf("%s: version %d does not match pinned version %d", source, version, l.pinnedVersion)
+	}
+	if version == l.state.HighestVersion && digest != l.state.HighestDigest {
+		return fmt.Errorf("%s: version %d was already applied with different contents", source, version)
+	}
+	if l.pinnedVersion == 0 && version < l.state.HighestVersion {
+		return fmt.Errorf("%s: version %d is older than version %d already applied", source, version, l.state.HighestVersion)
+	}
+	return nil
+}
+
+// SetSecretResolver resolves secret references in the bundles verified from
+// now on, like config files loaded with LoadConfigFileWithSecrets
+func (l *BundleLoader) SetSecretResolver(resolver *secrets.Resolver) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.secrets = resolver
+}
+
+// SignConfigBundle returns the base64 Ed25519 signature of a bundle file's
+// contents, written next to it as <bundle>.sig
+func SignConfigBundle(data []byte, key ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, data))
+}
+
+// BundleKeyID returns the short fingerprint reported for a public key
+func BundleKeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Load reads a bundle and its <bundle>.sig signature, verifies it against
+// the trusted keys, decodes it strictly and checks its version
+func (l *BundleLoader) Load(path string) (*VerifiedBundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	signature, err := os.ReadFile(path + ".sig")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle signature: %w", err)
+	}
+	return l.Verify(data, signature, path)
+}
+
+// Verify checks bundle data against its signature and decodes it; source
+// names the bundle in errors and status
+func (l *BundleLoader) Verify(data, signature []byte, source string) (*VerifiedBundle, error) {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: signature is not base64", source)
+	}
+	keyID := ""
+	for _, key := range l.keys {
+		if ed25519.Verify(key, data, decoded) {
+			keyID = BundleKeyID(key)
+			break
+		}
+	}
+	if keyID == "" {
+		return nil, fmt.Errorf("%s: signature does not match any trusted key", source)
+	}
+
+	format := ConfigFormatYAML
+	if strings.ToLower(filepath.Ext(source)) == ".json" {
+		format = ConfigFormatJSON
+	}
+	l.mu.Lock()
+	resolver := l.secrets
+	l.mu.Unlock()
+	var bundle ConfigBundle
+	if err := DecodeConfigWithSecrets(context.Background(), data, format, source, &bundle, resolver); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.checkVersionLocked(bundle.Version, digest, source); err != nil {
+		return nil, err
+	}
+	return &VerifiedBundle{ConfigBundle: bundle, Digest: digest, KeyID: keyID, Source: source}, nil
+}
+
+// Apply configures the targets from a verified bundle and records it as the
+// bundle in effect. Every section is validated and the state file written
+// before any target changes, so a refused bundle leaves everything as it was.
+func (l *BundleLoader) Apply(bundle *VerifiedBundle, targets BundleTargets) error {
+	if err := bundle.validateTargets(targets); err != nil {
+		return fmt.Errorf("%s: %w", bundle.Source, err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.checkVersionLocked(bundle.Version, bundle.Digest, bundle.Source); err != nil {
+		return err
+	}
+	state := l.state
+	if bundle.Version > state.HighestVersion {
+		state.HighestVersion, state.HighestDigest = bundle.Version, bundle.Digest
+	}
+	state.InEffect = &BundleStatus{
+		Version:   bundle.Version,
+		Digest:    bundle.Digest,
+		KeyID:     bundle.KeyID,
+		Source:    bundle.Source,
+		AppliedAt: l.now(),
+	}
+	if err := l.saveState(state); err != nil {
+		return err
+	}
+	l.state = state
+
+	// Validated above, so the setters below do not refuse their section
+	if targets.Integration != nil {
+		if bundle.AlertThresholds != nil {
+			targets.Integration.SetAlertThresholds(*bundle.AlertThresholds)
+		}
+		if bundle.ThresholdProfiles != nil {
+			if err := targets.Integration.SetThresholdProfiles(bundle.ThresholdProfiles); err != nil {
+				return err
+			}
+		}
+		if bundle.Costs != nil {
+			targets.Integration.SetCostConfiguration(*bundle.Costs)
+		}
+	}
+	if targets.Dashboard != nil {
+		if bundle.Costs != nil {
+			targets.Dashboard.SetCostConfiguration(*bundle.Costs)
+		}
+		if bundle.Dashboard != nil {
+			if err := targets.Dashboard.SetDashboardLayout(*bundle.Dashboard); err != nil {
+				return err
+			}
+		}
+	}
+	if targets.Scheduler != nil && bundle.Scheduling != nil {
+		if err := targets.Scheduler.ApplyPolicy(*bundle.Scheduling); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateTargets checks every section of the bundle, including the profile
+// patterns the integration compiles, so Apply can refuse a bundle before
+// changing any target
+func (b *VerifiedBundle) validateTargets(targets BundleTargets) error {
+	if err := b.ConfigBundle.Validate(); err != nil {
+		return err
+	}
+	if targets.Integration != nil && len(b.ThresholdProfiles) > 0 {
+		probe := &GPUMetricsIntegration{}
+		if err := probe.SetThresholdProfiles(b.ThresholdProfiles); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadAndApply loads, verifies and applies a bundle file
+func (l *BundleLoader) LoadAndApply(path string, targets BundleTargets) (*BundleStatus, error) {
+	bundle, err := l.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.Apply(bundle, targets); err != nil {
+		return nil, err
+	}
+	return l.Status(), nil
+}
+
+// Status returns the bundle in effect, or nil before one is applied
+func (l *BundleLoader) Status() *BundleStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.state.InEffect == nil {
+		return nil
+	}
+	status := *l.state.InEffect
+	return &status
+}
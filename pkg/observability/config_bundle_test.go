@@ -0,0 +1,210 @@
+package observability
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// writeBundle writes a bundle file and its signature into dir
+func writeBundle(t *testing.T, dir, name, contents string, key ed25519.PrivateKey) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".sig", []byte(SignConfigBundle([]byte(contents), key)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newTestBundleLoader(t *testing.T, pinned int) (*BundleLoader, ed25519.PrivateKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader, err := NewBundleLoader(BundleConfig{TrustedKeys: []string{base64.StdEncoding.EncodeToString(public)}, PinnedVersion: pinned})
+	if err != nil {
+		t.Fatalf("NewBundleLoader: %v", err)
+	}
+	return loader, private
+}
+
+func TestBundleLoaderAppliesSignedBundle(t *testing.T) {
+	loader, key := newTestBundleLoader(t, 0)
+	dir := t.TempDir()
+	path := writeBundle(t, dir, "fleet.yaml", `version: 3
+description: raise H100 limits
+alert_thresholds:
+  high_temperature: 80
+  critical_temperature: 90
+threshold_profiles:
+  - name: h100
+    pattern: "(?i)h100"
+    thresholds: {high_temperature: 85, critical_temperature: 95}
+costs:
+  currency: EUR
+  cost_per_hour: {a100: 2.5}
+scheduling:
+  strategy: best_fit
+  pools:
+    ci: {max_gpus: 2}
+`, key)
+
+	integration := NewGPUMetricsIntegration(NewMonitoringService(100), nil)
+	scheduler := gpu.NewScheduler(gpu.StrategyLeastUtilized)
+	status, err := loader.LoadAndApply(path, BundleTargets{Integration: integration, Scheduler: scheduler})
+	if err != nil {
+		t.Fatalf("LoadAndApply: %v", err)
+	}
+	if status.Version != 3 || len(status.Digest) != 64 || status.KeyID == "" || status.Source != path {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	if thresholds, profile := integration.GetThresholdsForGPU("NVIDIA H100 80GB"); profile != "h100" || thresholds.HighTemperature != 85 {
+		t.Errorf("Expected the h100 profile applied, got %s %+v", profile, thresholds)
+	}
+	if thresholds, _ := integration.GetThresholdsForGPU("Tesla T4"); thresholds.HighTemperature != 80 {
+		t.Errorf("Expected default thresholds from the bundle, got %+v", thresholds)
+	}
+	if costs := integration.GetCostConfiguration(); costs.Currency != "EUR" || costs.CostPerHour["a100"] != 2.5 {
+		t.Errorf("Expected bundle costs applied, got %+v", costs)
+	}
+	if policy := scheduler.Policy(); policy.Strategy != gpu.StrategyBestFit || policy.Pools["ci"].MaxGPUs != 2 {
+		t.Errorf("Expected bundle scheduling policy applied, got %+v", policy)
+	}
+}
+
+func TestBundleLoaderRejectsTamperedAndUntrustedBundles(t *testing.T) {
+	loader, key := newTestBundleLoader(t, 0)
+	dir := t.TempDir()
+	path := writeBundle(t, dir, "fleet.yaml", "version: 1\n", key)
+
+	os.WriteFile(path, []byte("version: 1\ncosts: {tax_rate: 0.5}\n"), 0644)
+	if _, err := loader.Load(path); err == nil || !strings.Contains(err.Error(), "does not match any trusted key") {
+		t.Errorf("Expected a tampered bundle rejected, got %v", err)
+	}
+
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	untrusted := writeBundle(t, dir, "other.yaml", "version: 1\n", otherKey)
+	if _, err := loader.Load(untrusted); err == nil {
+		t.Error("Expected a bundle signed by an untrusted key rejected")
+	}
+
+	invalid := writeBundle(t, dir, "invalid.yaml", "version: 2\nscheduling: {strategy: fastest}\ncosts: {tax_rat: 1}\n", key)
+	if _, err := loader.Load(invalid); err == nil || !strings.Contains(err.Error(), "tax_rat: unknown field") {
+		t.Errorf("Expected strict decoding errors, got %v", err)
+	}
+
+	if _, err := NewBundleLoader(BundleConfig{TrustedKeys: []string{"not-a-key"}}); err == nil {
+		t.Error("Expected an invalid trusted key rejected")
+	}
+}
+
+func TestBundleLoaderVersionRules(t *testing.T) {
+	loader, key := newTestBundleLoader(t, 0)
+	dir := t.TempDir()
+	integration := NewGPUMetricsIntegration(NewMonitoringService(100), nil)
+
+	v2 := writeBundle(t, dir, "v2.yaml", "version: 2\n", key)
+	if _, err := loader.LoadAndApply(v2, BundleTargets{Integration: integration}); err != nil {
+		t.Fatalf("LoadAndApply v2: %v", err)
+	}
+	v1 := writeBundle(t, dir, "v1.yaml", "version: 1\n", key)
+	if _, err := loader.Load(v1); err == nil || !strings.Contains(err.Error(), "older than version 2") {
+		t.Errorf("Expected a rollback refused, got %v", err)
+	}
+	if _, err := loader.Load(v2); err != nil {
+		t.Errorf("Expected the applied bundle accepted again, got %v", err)
+	}
+	other := writeBundle(t, dir, "other-v2.yaml", "version: 2\ncosts: {currency: EUR}\n", key)
+	if _, err := loader.Load(other); err == nil || !strings.Contains(err.Error(), "already applied with different contents") {
+		t.Errorf("Expected a different bundle with the same version refused, got %v", err)
+	}
+
+	pinned, key := newTestBundleLoader(t, 1)
+	v1 = writeBundle(t, dir, "pinned-v1.yaml", "version: 1\n", key)
+	v2 = writeBundle(t, dir, "pinned-v2.yaml", "version: 2\n", key)
+	if _, err := pinned.Load(v2); err == nil || !strings.Contains(err.Error(), "pinned version 1") {
+		t.Errorf("Expected an unpinned version refused, got %v", err)
+	}
+	if _, err := pinned.Load(v1); err != nil {
+		t.Errorf("Expected the pinned version accepted, got %v", err)
+	}
+}
+
+func TestBundleLoaderPersistsAppliedVersion(t *testing.T) {
+	public, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	config := BundleConfig{
+		TrustedKeys: []string{base64.StdEncoding.EncodeToString(public)},
+		StateFile:   filepath.Join(dir, "bundle-state.json"),
+	}
+	loader, err := NewBundleLoader(config)
+	if err != nil {
+		t.Fatalf("NewBundleLoader: %v", err)
+	}
+	v2 := writeBundle(t, dir, "v2.yaml", "version: 2\n", key)
+	if _, err := loader.LoadAndApply(v2, BundleTargets{}); err != nil {
+		t.Fatalf("LoadAndApply v2: %v", err)
+	}
+
+	// A restarted process must still refuse the rollback
+	restarted, err := NewBundleLoader(config)
+	if err != nil {
+		t.Fatalf("NewBundleLoader after restart: %v", err)
+	}
+	if status := restarted.Status(); status == nil || status.Version != 2 || status.Source != v2 {
+		t.Errorf("Expected the applied bundle restored, got %+v", status)
+	}
+	v1 := writeBundle(t, dir, "v1.yaml", "version: 1\n", key)
+	if _, err := restarted.Load(v1); err == nil || !strings.Contains(err.Error(), "older than version 2") {
+		t.Errorf("Expected a rollback refused after restart, got %v", err)
+	}
+	other := writeBundle(t, dir, "other-v2.yaml", "version: 2\ncosts: {currency: EUR}\n", key)
+	if _, err := restarted.Load(other); err == nil {
+		t.Error("Expected a different bundle with the same version refused after restart")
+	}
+
+	os.WriteFile(config.StateFile, []byte("{"), 0644)
+	if _, err := NewBundleLoader(config); err == nil {
+		t.Error("Expected a corrupt state file rejected")
+	}
+}
+
+func TestBundleLoaderApplyChangesNothingOnInvalidSection(t *testing.T) {
+	loader, _ := newTestBundleLoader(t, 0)
+	integration := NewGPUMetricsIntegration(NewMonitoringService(100), nil)
+	scheduler := gpu.NewScheduler(gpu.StrategyLeastUtilized)
+	before := integration.GetCostConfiguration()
+
+	bundle := &VerifiedBundle{
+		ConfigBundle: ConfigBundle{
+			Version:    1,
+			Costs:      &GPUCostConfiguration{Currency: "EUR"},
+			Scheduling: &gpu.SchedulingPolicy{Strategy: "fastest"},
+		},
+		Digest: "d1",
+		Source: "invalid.yaml",
+	}
+	if err := loader.Apply(bundle, BundleTargets{Integration: integration, Scheduler: scheduler}); err == nil {
+		t.Fatal("Expected an invalid scheduling section refused")
+	}
+	if costs := integration.GetCostConfiguration(); costs.Currency != before.Currency {
+		t.Errorf("Expected costs left unchanged, got %+v", costs)
+	}
+	if loader.Status() != nil {
+		t.Errorf("Expected no bundle in effect, got %+v", loader.Status())
+	}
+}
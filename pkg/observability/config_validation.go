@@ -0,0 +1,504 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/secrets"
+)
+
+// Config file formats
+const (
+	ConfigFormatYAML = "yaml"
+	ConfigFormatJSON = "json"
+)
+
+// ConfigError describes one problem in a configuration file
+type ConfigError struct {
+	Source  string `json:"source,omitempty"`
+	Line    int    `json:"line,omitempty"` // 0 when the location is unknown
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error formats the problem as source:line: field: message
+func (e ConfigError) Error() string {
+	var location []string
+	if e.Source != "" {
+		location = append(location, e.Source)
+	}
+	if e.Line > 0 {
+		location = append(location, strconv.Itoa(e.Line))
+	}
+
+	message := e.Message
+	if e.Field != "" {
+		message = e.Field + ": " + message
+	}
+	if len(location) == 0 {
+		return message
+	}
+	return strings.Join(location, ":") + ": " + message
+}
+
+// ConfigErrors collects every problem found in a configuration file
+type ConfigErrors []ConfigError
+
+// Error lists each problem on its own line
+func (errs ConfigErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// add records a problem with a field
+func (errs *ConfigErrors) add(field, format string, args ...interface{}) {
+	*errs = append(*errs, ConfigError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// err returns the collected problems, or nil if there are none
+func (errs ConfigErrors) err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// configValidator is implemented by config structs that check their values
+type configValidator interface {
+	Validate() error
+}
+
+// LoadConfigFile strictly decodes a YAML or JSON config file (chosen by
+// extension) into target, which should already hold the defaults. Unknown
+// fields, type mismatches and invalid values are all reported with line
+// numbers instead of being silently ignored.
+func LoadConfigFile(path string, target interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var format string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		format = ConfigFormatYAML
+	case ".json":
+		format = ConfigFormatJSON
+	default:
+		return fmt.Errorf("unsupported config file extension %q (use .yaml, .yml or .json)", filepath.Ext(path))
+	}
+
+	return DecodeConfig(data, format, path, target)
+}
+
+// LoadConfigFileWithSecrets loads a config file like LoadConfigFile, then
+// replaces secret references such as env:OPSGENIE_API_KEY or
+// vault:agentaflow/notifiers#opsgenie in fields tagged `secret` with the
+// values they name, so credentials stay out of the file itself.
+func LoadConfigFileWithSecrets(ctx context.Context, path string, target interface{}, resolver *secrets.Resolver) error {
+	if err := LoadConfigFile(path, target); err != nil {
+		return err
+	}
+	return resolveConfigSecrets(ctx, path, target, resolver)
+}
+
+// DecodeConfigWithSecrets decodes config data like DecodeConfig, then resolves
+// secret references like LoadConfigFileWithSecrets. Every loader of config
+// from outside the binary, such as bundles and GitOps sources, goes through
+// it; a nil resolver leaves references unresolved.
+func DecodeConfigWithSecrets(ctx context.Context, data []byte, format, source string, target interface{}, resolver *secrets.Resolver) error {
+	if err := DecodeConfig(data, format, source, target); err != nil {
+		return err
+	}
+	return resolveConfigSecrets(ctx, source, target, resolver)
+}
+
+// resolveConfigSecrets resolves the secret fields of decoded config, naming
+// source in errors
+func resolveConfigSecrets(ctx context.Context, source string, target interface{}, resolver *secrets.Resolver) error {
+	if resolver == nil {
+		return nil
+	}
+	if err := resolver.ResolveFields(ctx, target); err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+	return nil
+}
+
+// DecodeConfig strictly decodes YAML or JSON config data into target and
+// validates the result. source names the data in error messages.
+func DecodeConfig(data []byte, format, source string, target interface{}) error {
+	var errs ConfigErrors
+	switch format {
+	case ConfigFormatYAML:
+		errs = decodeYAML(data, target)
+	case ConfigFormatJSON:
+		errs = decodeJSON(data, target)
+	default:
+		return fmt.Errorf("unsupported config format %q", format)
+	}
+
+	if len(errs) == 0 {
+		if validator, ok := target.(configValidator); ok {
+			if err := validator.Validate(); err != nil {
+				var validationErrs ConfigErrors
+				if !errors.As(err, &validationErrs) {
+					validationErrs = ConfigErrors{{Message: err.Error()}}
+				}
+				for _, e := range validationErrs {
+					e.Line = uniqueKeyLine(data, e.Field)
+					errs = append(errs, e)
+				}
+			}
+		}
+	}
+
+	for i := range errs {
+		errs[i].Source = source
+	}
+	return errs.err()
+}
+
+var (
+	yamlLinePattern         = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	yamlUnknownFieldPattern = regexp.MustCompile(`^field (\S+) not found in type (\S+)$`)
+	jsonUnknownFieldPattern = regexp.MustCompile(`^json: unknown field "(.*)"$`)
+)
+
+// decodeYAML decodes YAML rejecting unknown fields
+func decodeYAML(data []byte, target interface{}) ConfigErrors {
+	err := yaml.UnmarshalStrict(data, target)
+	if err == nil {
+		return nil
+	}
+
+	var messages []string
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		messages = typeErr.Errors
+	} else {
+		messages = []string{err.Error()}
+	}
+
+	fields := knownFields(reflect.TypeOf(target))
+	var errs ConfigErrors
+	for _, message := range messages {
+		configErr := ConfigError{Message: strings.TrimPrefix(message, "yaml: ")}
+		if match := yamlLinePattern.FindStringSubmatch(message); match != nil {
+			configErr.Line, _ = strconv.Atoi(match[1])
+			configErr.Message = match[2]
+		}
+		if match := yamlUnknownFieldPattern.FindStringSubmatch(configErr.Message); match != nil {
+			configErr.Field = match[1]
+			configErr.Message = unknownFieldMessage(match[1], fields[match[2]])
+		}
+		errs = append(errs, configErr)
+	}
+	return errs
+}
+
+// decodeJSON decodes JSON rejecting unknown fields and trailing data
+func decodeJSON(data []byte, target interface{}) ConfigErrors {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(target)
+	if err == nil {
+		if decoder.More() {
+			return ConfigErrors{{Line: lineAt(data, int(decoder.InputOffset())), Message: "unexpected data after the config object"}}
+		}
+		return nil
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return ConfigErrors{{Line: lineAt(data, int(syntaxErr.Offset)), Message: syntaxErr.Error()}}
+	case errors.As(err, &typeErr):
+		return ConfigErrors{{
+			Line:    lineAt(data, int(typeErr.Offset)),
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("cannot use %s as %s", typeErr.Value, typeErr.Type),
+		}}
+	}
+
+	if match := jsonUnknownFieldPattern.FindStringSubmatch(err.Error()); match != nil {
+		// JSON errors carry no type, so suggest from every field of the config
+		var candidates []string
+		for _, names := range knownFields(reflect.TypeOf(target)) {
+			candidates = append(candidates, names...)
+		}
+		return ConfigErrors{{
+			Line:    uniqueKeyLine(data, match[1]),
+			Field:   match[1],
+			Message: unknownFieldMessage(match[1], candidates),
+		}}
+	}
+	return ConfigErrors{{Message: err.Error()}}
+}
+
+// unknownFieldMessage reports an unknown field, suggesting the closest known one
+func unknownFieldMessage(field string, candidates []string) string {
+	best, bestDistance := "", len(field)/2+1
+	for _, candidate := range candidates {
+		if distance := editDistance(field, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	if best == "" {
+		return "unknown field"
+	}
+	return fmt.Sprintf("unknown field (did you mean %q?)", best)
+}
+
+// knownFields maps each struct type reachable from t (as named in YAML
+// errors, e.g. observability.PrometheusConfig) to its config field names
+func knownFields(t reflect.Type) map[string][]string {
+	fields := make(map[string][]string)
+	var walk func(reflect.Type)
+	walk = func(t reflect.Type) {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || fields[t.String()] != nil {
+			return
+		}
+
+		names := make([]string, 0, t.NumField())
+		fields[t.String()] = names
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			if name != "-" {
+				names = append(names, name)
+			}
+			walk(field.Type)
+		}
+		sort.Strings(names)
+		fields[t.String()] = names
+	}
+	walk(t)
+	return fields
+}
+
+// lineAt returns the 1-based line containing a byte offset
+func lineAt(data []byte, offset int) int {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// uniqueKeyLine returns the line where a key (the last segment of a field
+// path) is defined, or 0 if it does not appear exactly once
+func uniqueKeyLine(data []byte, field string) int {
+	if field == "" {
+		return 0
+	}
+	if i := strings.LastIndexAny(field, ".]"); i >= 0 {
+		field = field[i+1:]
+	}
+
+	pattern := regexp.MustCompile(`(^|[\s{,])"?` + regexp.QuoteMeta(field) + `"?\s*:`)
+	matches := pattern.FindAllSubmatchIndex(data, 2)
+	if len(matches) != 1 {
+		return 0
+	}
+	// The key starts after the delimiter captured by the first group
+	return lineAt(data, matches[0][3])
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// minInt returns the smaller of two ints
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Validate checks the exporter configuration values
+func (c PrometheusConfig) Validate() error {
+	var errs ConfigErrors
+
+	if c.MetricsPrefix == "" {
+		errs.add("metrics_prefix", "must not be empty")
+	} else if sanitizeLabelName(c.MetricsPrefix) != c.MetricsPrefix {
+		errs.add("metrics_prefix", "%q is not a valid metric name prefix", c.MetricsPrefix)
+	}
+
+	groups := []string{"cost_metrics", "gpu_metrics", "scheduling_metrics", "serving_metrics", "system_metrics"}
+	for group := range c.EnabledMetrics {
+		known := false
+		for _, g := range groups {
+			known = known || g == group
+		}
+		if !known {
+			errs.add("enabled_metrics", "unknown metric group %q (expected one of %s)", group, strings.Join(groups, ", "))
+		}
+	}
+
+	for name := range c.MetricLabels {
+		if sanitizeLabelName(name) != name {
+			errs.add("metric_labels", "%q is not a valid label name", name)
+		}
+	}
+
+	if c.StaleSeriesTTL < 0 {
+		errs.add("stale_series_ttl", "must not be negative")
+	}
+	if c.StaleSeriesAction != "" && c.StaleSeriesAction != StaleSeriesDrop && c.StaleSeriesAction != StaleSeriesZero {
+		errs.add("stale_series_action", "must be %q or %q, got %q", StaleSeriesDrop, StaleSeriesZero, c.StaleSeriesAction)
+	}
+	if c.HistogramWindow < 0 {
+		errs.add("histogram_window", "must not be negative")
+	}
+	if c.HistogramMaxSamples < 0 {
+		errs.add("histogram_max_samples", "must not be negative")
+	}
+	for name, buckets := range c.HistogramBuckets {
+		if len(buckets) == 0 {
+			errs.add("histogram_buckets."+name, "must list at least one upper bound")
+			continue
+		}
+		for i, bound := range buckets {
+			if math.IsNaN(bound) || math.IsInf(bound, 0) || i > 0 && bound <= buckets[i-1] {
+				errs.add("histogram_buckets."+name, "upper bounds must be finite and strictly increasing")
+				break
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+// Validate checks the dashboard configuration values
+func (c WebDashboardConfig) Validate() error {
+	var errs ConfigErrors
+
+	if c.Port < 0 || c.Port > 65535 {
+		errs.add("port", "must be between 0 and 65535, got %d", c.Port)
+	}
+	if c.Theme != "" && c.Theme != "light" && c.Theme != "dark" {
+		errs.add("theme", "must be \"light\" or \"dark\", got %q", c.Theme)
+	}
+	if c.RefreshInterval < 0 {
+		errs.add("refresh_interval", "must not be negative")
+	}
+	if c.Health.CollectorMaxAge < 0 {
+		errs.add("health.collector_max_age", "must not be negative")
+	}
+	if c.Health.BroadcastMaxAge < 0 {
+		errs.add("health.broadcast_max_age", "must not be negative")
+	}
+	if c.Health.PrometheusMaxAge < 0 {
+		errs.add("health.prometheus_max_age", "must not be negative")
+	}
+	if len(c.Layout.Default.Panels) > 0 {
+		if err := c.Layout.Validate(); err != nil {
+			for _, layoutErr := range err.(ConfigErrors) {
+				layoutErr.Field = "layout." + layoutErr.Field
+				errs = append(errs, layoutErr)
+			}
+		}
+	}
+	if c.WebSocketKeyframeInterval < 0 {
+		errs.add("websocket_keyframe_interval", "must not be negative")
+	}
+	c.HTTP.validate("http", &errs)
+	if c.ResponseCache.TTL < 0 {
+		errs.add("response_cache.ttl", "must not be negative")
+	}
+	if c.ResponseCache.MaxConcurrent < 0 {
+		errs.add("response_cache.max_concurrent", "must not be negative")
+	}
+	if c.ResponseCache.MaxEntries < 0 {
+		errs.add("response_cache.max_entries", "must not be negative")
+	}
+	c.Tenancy.validate(&errs)
+	c.Replication.validate(&errs)
+	c.AirGap.validate(&errs)
+
+	return errs.err()
+}
+
+// Validate checks the cost configuration values
+func (c GPUCostConfiguration) Validate() error {
+	var errs ConfigErrors
+
+	checkRate := func(field string, value float64) {
+		if value < 0 || value > 1 {
+			errs.add(field, "must be between 0 and 1, got %g", value)
+		}
+	}
+	checkCosts := func(field string, costs map[string]float64) {
+		for gpuType, cost := range costs {
+			if cost < 0 {
+				errs.add(field, "cost for %q must not be negative", gpuType)
+			}
+		}
+	}
+
+	checkCosts("cost_per_hour", c.CostPerHour)
+	checkCosts("custom_pricing", c.CustomPricing)
+	checkCosts("reserved_instance_cost", c.ReservedInstanceCost)
+	checkRate("min_utilization_factor", c.MinUtilizationFactor)
+	checkRate("idle_cost_reduction", c.IdleCostReduction)
+	checkRate("tax_rate", c.TaxRate)
+	checkRate("spot_instance_discount", c.SpotInstanceDiscount)
+
+	for i, discount := range c.VolumeDiscounts {
+		if discount.MinHours < 0 {
+			errs.add(fmt.Sprintf("volume_discounts[%d].min_hours", i), "must not be negative")
+		}
+		checkRate(fmt.Sprintf("volume_discounts[%d].discount_rate", i), discount.DiscountRate)
+	}
+
+	return errs.err()
+}
@@ -0,0 +1,153 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/secrets"
+)
+
+func TestDecodeConfigYAMLUnknownFields(t *testing.T) {
+	data := []byte(`metrics_prefix: agentaflow
+stale_series_ttl: 10m
+metric_prefix: typo
+histogram_max_sample: 10
+`)
+
+	config := DefaultPrometheusConfig()
+	err := DecodeConfig(data, ConfigFormatYAML, "prometheus.yaml", &config)
+
+	var errs ConfigErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("Expected 2 config errors, got %v", err)
+	}
+	if errs[0].Line != 3 || errs[0].Field != "metric_prefix" || !strings.Contains(errs[0].Message, `did you mean "metrics_prefix"`) {
+		t.Errorf("Expected line-numbered unknown field with suggestion, got %+v", errs[0])
+	}
+	if got := errs[1].Error(); got != `prometheus.yaml:4: histogram_max_sample: unknown field (did you mean "histogram_max_samples"?)` {
+		t.Errorf("Unexpected error message: %s", got)
+	}
+}
+
+func TestDecodeConfigYAMLValid(t *testing.T) {
+	data := []byte(`port: 9100
+theme: dark
+health:
+  collector_max_age: 45s
+`)
+
+	config := WebDashboardConfig{Port: 9000, Title: "kept"}
+	if err := DecodeConfig(data, ConfigFormatYAML, "dashboard.yaml", &config); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+	if config.Port != 9100 || config.Title != "kept" || config.Health.CollectorMaxAge != 45*time.Second {
+		t.Errorf("Expected fields decoded over defaults, got %+v", config)
+	}
+}
+
+func TestDecodeConfigJSONErrors(t *testing.T) {
+	config := DefaultGPUCostConfiguration()
+
+	unknown := []byte("{\n  \"currency\": \"EUR\",\n  \"tax_rat\": 0.2\n}")
+	err := DecodeConfig(unknown, ConfigFormatJSON, "costs.json", &config)
+	if err == nil || err.Error() != `costs.json:3: tax_rat: unknown field (did you mean "tax_rate"?)` {
+		t.Errorf("Expected unknown JSON field with line number, got %v", err)
+	}
+
+	mistyped := []byte("{\n  \"currency\": \"EUR\",\n  \"tax_rate\": \"high\"\n}")
+	err = DecodeConfig(mistyped, ConfigFormatJSON, "costs.json", &config)
+	if err == nil || !strings.HasPrefix(err.Error(), "costs.json:3: tax_rate: cannot use string") {
+		t.Errorf("Expected type error with line number, got %v", err)
+	}
+
+	broken := []byte("{\n  \"currency\": \"EUR\"\n  \"tax_rate\": 0.2\n}")
+	if err := DecodeConfig(broken, ConfigFormatJSON, "costs.json", &config); err == nil || !strings.HasPrefix(err.Error(), "costs.json:3:") {
+		t.Errorf("Expected syntax error with line number, got %v", err)
+	}
+}
+
+func TestDecodeConfigValidatesValues(t *testing.T) {
+	data := []byte(`tax_rate: 1.5
+volume_discounts:
+  - min_hours: 100
+    discount_rate: 0.1
+`)
+
+	config := DefaultGPUCostConfiguration()
+	err := DecodeConfig(data, ConfigFormatYAML, "costs.yaml", &config)
+	if err == nil || err.Error() != "costs.yaml:1: tax_rate: must be between 0 and 1, got 1.5" {
+		t.Errorf("Expected value validation with line number, got %v", err)
+	}
+
+	prometheus := DefaultPrometheusConfig()
+	err = DecodeConfig([]byte("enabled_metrics:\n  gpu_metrcs: true\nstale_series_action: delete\n"), ConfigFormatYAML, "", &prometheus)
+	var errs ConfigErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("Expected metric group and action errors, got %v", err)
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "prometheus.json")
+	if err := os.WriteFile(path, []byte(`{"metrics_prefix": "custom"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := DefaultPrometheusConfig()
+	if err := LoadConfigFile(path, &config); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.MetricsPrefix != "custom" || config.HistogramMaxSamples != 1024 {
+		t.Errorf("Expected file values over defaults, got %+v", config)
+	}
+
+	if err := LoadConfigFile(filepath.Join(dir, "config.toml"), &config); err == nil {
+		t.Error("Expected error for an unsupported extension")
+	}
+}
+
+func TestLoadConfigFileWithSecrets(t *testing.T) {
+	t.Setenv("AGENTAFLOW_TEST_OPSGENIE_KEY", "og-key")
+	path := filepath.Join(t.TempDir(), "opsgenie.yaml")
+	os.WriteFile(path, []byte("api_key: env:AGENTAFLOW_TEST_OPSGENIE_KEY\nteam: sre\n"), 0600)
+
+	var config OpsgenieConfig
+	resolver := secrets.NewResolver(secrets.DefaultConfig())
+	if err := LoadConfigFileWithSecrets(context.Background(), path, &config, resolver); err != nil {
+		t.Fatalf("LoadConfigFileWithSecrets: %v", err)
+	}
+	if config.APIKey != "og-key" || config.Team != "sre" {
+		t.Errorf("Expected the API key resolved from the environment, got %+v", config)
+	}
+
+	os.WriteFile(path, []byte("api_key: env:AGENTAFLOW_TEST_MISSING\n"), 0600)
+	err := LoadConfigFileWithSecrets(context.Background(), path, &OpsgenieConfig{}, resolver)
+	if err == nil || !strings.Contains(err.Error(), "opsgenie.yaml: api_key: failed to resolve secret") {
+		t.Errorf("Expected the unresolved field named, got %v", err)
+	}
+}
+
+func TestDecodeConfigWithSecrets(t *testing.T) {
+	t.Setenv("AGENTAFLOW_TEST_PING_URL", "https://hc-ping.com/0b1c2d3e")
+	data := []byte("ping_url: env:AGENTAFLOW_TEST_PING_URL\nmethod: POST\n")
+
+	var config DeadMansSwitchConfig
+	if err := DecodeConfigWithSecrets(context.Background(), data, ConfigFormatYAML, "bundle.yaml", &config, secrets.NewResolver(secrets.DefaultConfig())); err != nil {
+		t.Fatalf("DecodeConfigWithSecrets: %v", err)
+	}
+	if config.PingURL != "https://hc-ping.com/0b1c2d3e" || config.Method != "POST" {
+		t.Errorf("Expected the ping URL resolved, got %+v", config)
+	}
+
+	// Without a resolver references are left as written
+	config = DeadMansSwitchConfig{}
+	if err := DecodeConfigWithSecrets(context.Background(), data, ConfigFormatYAML, "bundle.yaml", &config, nil); err != nil || config.PingURL != "env:AGENTAFLOW_TEST_PING_URL" {
+		t.Errorf("Expected the reference kept without a resolver, got %+v, %v", config, err)
+	}
+}
@@ -0,0 +1,93 @@
+package observability
+
+import (
+	"fmt"
+	"time"
+)
+
+// Calendar periods costs are bucketed into
+const (
+	CostPeriodDay   = "day"
+	CostPeriodMonth = "month"
+)
+
+// CostBucket is the spend over one calendar day or month
+type CostBucket struct {
+	Period   string    `json:"period"` // 2006-01-02 for days, 2006-01 for months
+	Label    string    `json:"label"`  // Localized, e.g. 16.10.2026 or Oktober 2026
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Cost     float64   `json:"cost"`
+	GPUHours float64   `json:"gpu_hours"`
+	Tokens   int64     `json:"tokens"`
+
+	FormattedCost string `json:"formatted_cost,omitempty"` // Cost in the report locale and currency
+}
+
+// periodStart returns the start of the day or month containing t in t's
+// time zone
+func periodStart(t time.Time, period string) time.Time {
+	if period == CostPeriodMonth {
+		return MonthStart(t)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// nextPeriod returns the start of the day or month after the one starting
+// at start; days are calendar days, so 23 or 25 hours across DST changes
+func nextPeriod(start time.Time, period string) time.Time {
+	if period == CostPeriodMonth {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// GetCostBuckets totals a tenant's costs between start and end by calendar
+// day or month in the locale's time zone. Every period overlapping the
+// range is returned, including those without costs. An empty tenant
+// totals every cost entry.
+func (ms *MonitoringService) GetCostBuckets(start, end time.Time, tenant, period string, locale ReportLocale) ([]CostBucket, error) {
+	if period != CostPeriodDay && period != CostPeriodMonth {
+		return nil, fmt.Errorf("period must be %q or %q, got %q", CostPeriodDay, CostPeriodMonth, period)
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("end must be after start")
+	}
+
+	location := locale.Location()
+	buckets := make([]CostBucket, 0)
+	index := make(map[string]int)
+	for bucketStart := periodStart(start.In(location), period); bucketStart.Before(end); bucketStart = nextPeriod(bucketStart, period) {
+		bucket := CostBucket{Start: bucketStart, End: nextPeriod(bucketStart, period)}
+		if period == CostPeriodMonth {
+			bucket.Period = bucketStart.Format("2006-01")
+			bucket.Label = locale.FormatMonth(bucketStart)
+		} else {
+			bucket.Period = bucketStart.Format("2006-01-02")
+			bucket.Label = locale.FormatDate(bucketStart)
+		}
+		index[bucket.Period] = len(buckets)
+		buckets = append(buckets, bucket)
+	}
+
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	for _, cost := range ms.costs {
+		if tenant != "" && cost.Tenant != tenant {
+			continue
+		}
+		if !cost.Timestamp.After(start) || !cost.Timestamp.Before(end) {
+			continue
+		}
+		key := periodStart(cost.Timestamp.In(location), period).Format("2006-01-02")
+		if period == CostPeriodMonth {
+			key = key[:7]
+		}
+		if i, exists := index[key]; exists {
+			buckets[i].Cost += cost.Cost
+			buckets[i].GPUHours += cost.GPUHours
+			buckets[i].Tokens += cost.TokensUsed
+		}
+	}
+	return buckets, nil
+}
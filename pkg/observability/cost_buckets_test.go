@@ -0,0 +1,97 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGetCostBucketsUsesTheReportTimeZone(t *testing.T) {
+	ms := NewMonitoringService(100)
+	ms.RecordCost(CostEntry{ID: "c1", Tenant: "vision", Cost: 12, GPUHours: 3, TokensUsed: 100})
+	ms.RecordCost(CostEntry{ID: "c2", Tenant: "nlp", Cost: 3})
+
+	now := time.Now()
+	for _, zone := range []string{"Pacific/Kiritimati", "Pacific/Pago_Pago"} {
+		locale := ReportLocale{TimeZone: zone, Locale: "de-DE"}
+		buckets, err := ms.GetCostBuckets(now.Add(-48*time.Hour), now.Add(time.Minute), "vision", CostPeriodDay, locale)
+		if err != nil {
+			t.Fatalf("GetCostBuckets failed: %v", err)
+		}
+		if len(buckets) < 3 {
+			t.Fatalf("%s: expected every day of the range, got %+v", zone, buckets)
+		}
+		today := buckets[len(buckets)-1]
+		if today.Period != now.In(locale.Location()).Format("2006-01-02") || today.Cost != 12 || today.GPUHours != 3 || today.Tokens != 100 {
+			t.Errorf("%s: expected vision's cost on the local day, got %+v", zone, today)
+		}
+		if today.Label != locale.FormatDate(now) || today.Start.Location().String() != zone {
+			t.Errorf("%s: expected a localized day, got %+v", zone, today)
+		}
+	}
+
+	months, _ := ms.GetCostBuckets(now.Add(-time.Hour), now.Add(time.Minute), "", CostPeriodMonth, ReportLocale{Locale: "en-US"})
+	if len(months) == 0 || months[len(months)-1].Cost != 15 || months[len(months)-1].Period != now.Format("2006-01") {
+		t.Errorf("Expected every tenant's cost in this month, got %+v", months)
+	}
+
+	// Calendar days across a DST change are 23 hours long
+	newYork := ReportLocale{TimeZone: "America/New_York"}
+	start := time.Date(2026, time.March, 7, 12, 0, 0, 0, newYork.Location())
+	buckets, _ := ms.GetCostBuckets(start, start.Add(48*time.Hour), "", CostPeriodDay, newYork)
+	if len(buckets) != 3 || buckets[1].Period != "2026-03-08" || buckets[1].End.Sub(buckets[1].Start) != 23*time.Hour {
+		t.Errorf("Expected a 23 hour 8 March, got %+v", buckets)
+	}
+
+	if _, err := ms.GetCostBuckets(now.Add(-time.Hour), now, "", "week", newYork); err == nil {
+		t.Error("Expected an unknown period to be rejected")
+	}
+}
+
+func TestCostBreakdownEndpoint(t *testing.T) {
+	monitor := NewMonitoringService(100)
+	monitor.RecordCost(CostEntry{ID: "c1", Tenant: "vision", Cost: 1234.5})
+	monitor.RecordCost(CostEntry{ID: "c2", Tenant: "nlp", Cost: 3})
+	dashboard := NewWebDashboard(monitor, nil, nil, WebDashboardConfig{
+		Port:          0,
+		ControlTokens: map[string]string{"vision-token": "ana", "admin-token": "ops"},
+		Tenancy: TenancyConfig{
+			Tenants: map[string]string{"ana": "vision"},
+			Admins:  []string{"ops"},
+			Locales: map[string]ReportLocale{"vision": {TimeZone: "Europe/Berlin", Locale: "de-DE"}},
+		},
+	})
+	dashboard.SetCostConfiguration(GPUCostConfiguration{Currency: "EUR"})
+
+	var breakdown struct {
+		Period         string       `json:"period"`
+		TimeZone       string       `json:"time_zone"`
+		Buckets        []CostBucket `json:"buckets"`
+		Total          float64      `json:"total"`
+		FormattedTotal string       `json:"formatted_total"`
+	}
+	response := serveAs(dashboard, "vision-token", http.MethodGet, "/api/v1/costs/breakdown", "")
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", response.Code, response.Body.String())
+	}
+	json.Unmarshal(response.Body.Bytes(), &breakdown)
+	if breakdown.TimeZone != "Europe/Berlin" || len(breakdown.Buckets) != 30 || breakdown.FormattedTotal != "1.234,50\u00a0€" {
+		t.Errorf("Expected 30 Berlin days of vision's costs in de-DE, got %+v", breakdown)
+	}
+
+	json.Unmarshal(serveAs(dashboard, "admin-token", http.MethodGet, "/api/v1/costs/breakdown?period=month&tz=Asia/Tokyo&locale=ja-JP", "").Body.Bytes(), &breakdown)
+	if breakdown.TimeZone != "Asia/Tokyo" || len(breakdown.Buckets) != 12 || breakdown.Total != 1237.5 || breakdown.FormattedTotal != "€1,237.50" {
+		t.Errorf("Expected 12 Tokyo months of every tenant's costs, got %+v", breakdown)
+	}
+
+	for _, path := range []string{
+		"/api/v1/costs/breakdown?period=week",
+		"/api/v1/costs/breakdown?tz=Mars/Olympus",
+		"/api/v1/costs/breakdown?locale=tlh",
+	} {
+		if response := serveAs(dashboard, "admin-token", http.MethodGet, path, ""); response.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", path, response.Code)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package observability
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CostOptimizerConfig tunes the cluster cost action plan
+type CostOptimizerConfig struct {
+	// GPUs averaging below ConsolidateBelow percent utilization are packed
+	// onto as few GPUs as fit within TargetUtilization
+	ConsolidateBelow  float64 `yaml:"consolidate_below" json:"consolidate_below"`
+	TargetUtilization float64 `yaml:"target_utilization" json:"target_utilization"`
+
+	// GPUs averaging below DownsizeBelow percent utilization whose peak
+	// memory fits within DownsizeMemoryPercent of a cheaper type are downsized
+	DownsizeBelow         float64                   `yaml:"downsize_below" json:"downsize_below"`
+	DownsizeMemoryPercent float64                   `yaml:"downsize_memory_percent" json:"downsize_memory_percent"`
+	DownsizeTargets       map[string]DownsizeTarget `yaml:"downsize_targets" json:"downsize_targets"` // Keyed by GPU type
+
+	// Discount assumed for spot capacity when the pricing config has none
+	SpotDiscount float64 `yaml:"spot_discount" json:"spot_discount"`
+
+	// Savings and the cost forecast are projected over this many hours
+	HorizonHours float64 `yaml:"horizon_hours" json:"horizon_hours"`
+
+	// Utilization and peak memory come from this much metrics history
+	Lookback time.Duration `yaml:"lookback" json:"lookback"`
+}
+
+// DownsizeTarget is a cheaper GPU type a workload could move to
+type DownsizeTarget struct {
+	Type     string `yaml:"type" json:"type"`
+	MemoryMB uint64 `yaml:"memory_mb" json:"memory_mb"`
+}
+
+// DefaultCostOptimizerConfig returns a 30 day plan over the last day of metrics
+func DefaultCostOptimizerConfig() CostOptimizerConfig {
+	return CostOptimizerConfig{
+		ConsolidateBelow:      30,
+		TargetUtilization:     75,
+		DownsizeBelow:         40,
+		DownsizeMemoryPercent: 80,
+		DownsizeTargets: map[string]DownsizeTarget{
+			"h100": {Type: "a100", MemoryMB: 40960},
+			"a100": {Type: "a10", MemoryMB: 24576},
+			"v100": {Type: "t4", MemoryMB: 16384},
+		},
+		SpotDiscount: 0.6,
+		HorizonHours: 720,
+		Lookback:     24 * time.Hour,
+	}
+}
+
+// GPUCostProfile is what the optimizer knows about one GPU
+type GPUCostProfile struct {
+	GPUID              string  `json:"gpu_id"`
+	GPUType            string  `json:"gpu_type"`
+	CostPerHour        float64 `json:"cost_per_hour"`
+	AverageUtilization float64 `json:"average_utilization"`
+	PeakMemoryMB       uint64  `json:"peak_memory_mb"`
+	MemoryTotalMB      uint64  `json:"memory_total_mb"`
+	Interruptible      bool    `json:"interruptible"` // Runs only work that tolerates spot interruptions
+}
+
+// CostAction is one step of a cost action plan
+type CostAction struct {
+	Type             string   `json:"type"` // consolidate, downsize or spot
+	Title            string   `json:"title"`
+	Description      string   `json:"description"`
+	GPUIDs           []string `json:"gpu_ids"`
+	SavingsPerHour   float64  `json:"savings_per_hour"`
+	EstimatedSavings float64  `json:"estimated_savings"` // Over the plan's horizon
+	Risk             string   `json:"risk"`              // low, medium or high
+}
+
+// CostActionPlan lists cost-saving actions, most worthwhile first
+type CostActionPlan struct {
+	Actions       []CostAction `json:"actions"`
+	Currency      string       `json:"currency"`
+	HorizonHours  float64      `json:"horizon_hours"`
+	ForecastCost  float64      `json:"forecast_cost"`  // Current run rate over the horizon
+	OptimizedCost float64      `json:"optimized_cost"` // After every action
+	TotalSavings  float64      `json:"total_savings"`
+	GeneratedAt   time.Time    `json:"generated_at"`
+}
+
+// riskWeight discounts savings by how likely an action is to hurt workloads
+var riskWeight = map[string]float64{"low": 1.0, "medium": 0.75, "high": 0.5}
+
+// BuildCostActionPlan turns per-GPU utilization and pricing into ordered
+// actions: consolidating lightly used GPUs, downsizing oversized ones and
+// moving interruptible work to spot capacity. Each GPU's savings are counted
+// once, by the first action that applies to it.
+func BuildCostActionPlan(profiles []GPUCostProfile, pricing GPUCostConfiguration, config CostOptimizerConfig, now time.Time) CostActionPlan {
+	plan := CostActionPlan{
+		Actions:      make([]CostAction, 0),
+		Currency:     pricing.Currency,
+		HorizonHours: config.HorizonHours,
+		GeneratedAt:  now,
+	}
+	runRate := 0.0
+	for _, profile := range profiles {
+		runRate += profile.CostPerHour
+	}
+	plan.ForecastCost = runRate * config.HorizonHours
+
+	freed := make(map[string]bool)
+	if action, ok := planConsolidation(profiles, config, freed); ok {
+		plan.Actions = append(plan.Actions, action)
+	}
+
+	// Remaining hourly cost of each GPU after earlier actions
+	remaining := make(map[string]float64, len(profiles))
+	for _, profile := range profiles {
+		if !freed[profile.GPUID] {
+			remaining[profile.GPUID] = profile.CostPerHour
+		}
+	}
+	plan.Actions = append(plan.Actions, planDownsizing(profiles, pricing, config, remaining)...)
+	if action, ok := planSpot(profiles, pricing, config, remaining); ok {
+		plan.Actions = append(plan.Actions, action)
+	}
+
+	for i := range plan.Actions {
+		plan.Actions[i].EstimatedSavings = plan.Actions[i].SavingsPerHour * config.HorizonHours
+		plan.TotalSavings += plan.Actions[i].EstimatedSavings
+	}
+	sort.SliceStable(plan.Actions, func(i, j int) bool {
+		a, b := plan.Actions[i], plan.Actions[j]
+		return a.EstimatedSavings*riskWeight[a.Risk] > b.EstimatedSavings*riskWeight[b.Risk]
+	})
+	plan.OptimizedCost = plan.ForecastCost - plan.TotalSavings
+	return plan
+}
+
+// packedGPU is a GPU receiving consolidated work
+type packedGPU struct {
+	profile     GPUCostProfile
+	utilization float64
+	memory      uint64
+}
+
+// planConsolidation packs lightly used GPUs onto the busiest of them, first
+// fit by utilization and peak memory, and marks the GPUs left empty as freed
+func planConsolidation(profiles []GPUCostProfile, config CostOptimizerConfig, freed map[string]bool) (CostAction, bool) {
+	candidates := make([]GPUCostProfile, 0)
+	for _, profile := range profiles {
+		if profile.AverageUtilization < config.ConsolidateBelow {
+			candidates = append(candidates, profile)
+		}
+	}
+	if len(candidates) < 2 {
+		return CostAction{}, false
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].AverageUtilization != candidates[j].AverageUtilization {
+			return candidates[i].AverageUtilization > candidates[j].AverageUtilization
+		}
+		return candidates[i].GPUID < candidates[j].GPUID
+	})
+
+	receivers := make([]*packedGPU, 0)
+	moved := make([]GPUCostProfile, 0)
+	for _, candidate := range candidates {
+		placed := false
+		for _, receiver := range receivers {
+			if receiver.utilization+candidate.AverageUtilization <= config.TargetUtilization &&
+				receiver.memory+candidate.PeakMemoryMB <= receiver.profile.MemoryTotalMB {
+				receiver.utilization += candidate.AverageUtilization
+				receiver.memory += candidate.PeakMemoryMB
+				placed = true
+				break
+			}
+		}
+		if placed {
+			moved = append(moved, candidate)
+		} else {
+			receivers = append(receivers, &packedGPU{candidate, candidate.AverageUtilization, candidate.PeakMemoryMB})
+		}
+	}
+	if len(moved) == 0 {
+		return CostAction{}, false
+	}
+
+	action := CostAction{Type: "consolidate", Risk: "low", GPUIDs: make([]string, 0, len(moved))}
+	busiest := 0.0
+	for _, receiver := range receivers {
+		busiest = math.Max(busiest, receiver.utilization)
+	}
+	// Receivers running close to the target leave little room for spikes
+	if busiest > config.TargetUtilization*0.8 {
+		action.Risk = "medium"
+	}
+	for _, profile := range moved {
+		freed[profile.GPUID] = true
+		action.GPUIDs = append(action.GPUIDs, profile.GPUID)
+		action.SavingsPerHour += profile.CostPerHour
+	}
+	sort.Strings(action.GPUIDs)
+	action.Title = fmt.Sprintf("Consolidate workloads from %d GPUs onto %d", len(moved), len(receivers))
+	action.Description = fmt.Sprintf("Move the work on %s (each below %.0f%% utilization) onto the remaining %d GPUs, "+
+		"keeping them below %.0f%% utilization, then release the freed GPUs",
+		strings.Join(action.GPUIDs, ", "), config.ConsolidateBelow, len(receivers), config.TargetUtilization)
+	return action, true
+}
+
+// planDownsizing moves lightly used GPUs whose peak memory fits a cheaper
+// type onto that type, one action per type change
+func planDownsizing(profiles []GPUCostProfile, pricing GPUCostConfiguration, config CostOptimizerConfig, remaining map[string]float64) []CostAction {
+	byChange := make(map[string]*CostAction)
+	changes := make([]string, 0)
+	for _, profile := range profiles {
+		cost, kept := remaining[profile.GPUID]
+		target, exists := config.DownsizeTargets[profile.GPUType]
+		if !kept || !exists || profile.AverageUtilization >= config.DownsizeBelow {
+			continue
+		}
+		if float64(profile.PeakMemoryMB) > float64(target.MemoryMB)*config.DownsizeMemoryPercent/100 {
+			continue
+		}
+		targetCost := pricing.hourlyCost(target.Type)
+		if targetCost >= cost {
+			continue
+		}
+
+		change := profile.GPUType + "->" + target.Type
+		action, exists := byChange[change]
+		if !exists {
+			action = &CostAction{Type: "downsize", Risk: "medium"}
+			byChange[change] = action
+			changes = append(changes, change)
+		}
+		action.GPUIDs = append(action.GPUIDs, profile.GPUID)
+		action.SavingsPerHour += cost - targetCost
+		remaining[profile.GPUID] = targetCost
+	}
+
+	sort.Strings(changes)
+	actions := make([]CostAction, 0, len(changes))
+	for _, change := range changes {
+		action := byChange[change]
+		types := strings.SplitN(change, "->", 2)
+		sort.Strings(action.GPUIDs)
+		action.Title = fmt.Sprintf("Downsize %d %s instances to %s", len(action.GPUIDs), types[0], types[1])
+		action.Description = fmt.Sprintf("%s peak below %.0f%% of a %s's memory at under %.0f%% utilization",
+			strings.Join(action.GPUIDs, ", "), config.DownsizeMemoryPercent, types[1], config.DownsizeBelow)
+		actions = append(actions, *action)
+	}
+	return actions
+}
+
+// planSpot moves GPUs running only interruptible work to spot capacity
+func planSpot(profiles []GPUCostProfile, pricing GPUCostConfiguration, config CostOptimizerConfig, remaining map[string]float64) (CostAction, bool) {
+	discount := pricing.SpotInstanceDiscount
+	if discount <= 0 {
+		discount = config.SpotDiscount
+	}
+	action := CostAction{Type: "spot", Risk: "medium", GPUIDs: make([]string, 0)}
+	for _, profile := range profiles {
+		cost, kept := remaining[profile.GPUID]
+		if !kept || !profile.Interruptible {
+			continue
+		}
+		action.GPUIDs = append(action.GPUIDs, profile.GPUID)
+		action.SavingsPerHour += cost * discount
+		// Busy interruptible jobs lose more work to each reclaim
+		if profile.AverageUtilization > 80 {
+			action.Risk = "high"
+		}
+	}
+	if len(action.GPUIDs) == 0 || discount <= 0 {
+		return CostAction{}, false
+	}
+	sort.Strings(action.GPUIDs)
+	action.Title = fmt.Sprintf("Switch %d GPUs running interruptible work to spot", len(action.GPUIDs))
+	action.Description = fmt.Sprintf("%s only run training or batch work; spot capacity at a %.0f%% discount needs checkpointing to survive reclaims",
+		strings.Join(action.GPUIDs, ", "), discount*100)
+	return action, true
+}
@@ -0,0 +1,105 @@
+package observability
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestBuildCostActionPlan(t *testing.T) {
+	pricing := DefaultGPUCostConfiguration()
+	pricing.CostPerHour["a10"] = 1.0
+	config := DefaultCostOptimizerConfig()
+
+	profiles := []GPUCostProfile{
+		// Three lightly used T4s fit on one: two are freed
+		{GPUID: "t4-0", GPUType: "t4", CostPerHour: 0.5, AverageUtilization: 20, PeakMemoryMB: 2048, MemoryTotalMB: 16384},
+		{GPUID: "t4-1", GPUType: "t4", CostPerHour: 0.5, AverageUtilization: 10, PeakMemoryMB: 2048, MemoryTotalMB: 16384},
+		{GPUID: "t4-2", GPUType: "t4", CostPerHour: 0.5, AverageUtilization: 5, PeakMemoryMB: 1024, MemoryTotalMB: 16384},
+		// An A100 peaking at 12 GB fits an A10
+		{GPUID: "a100-0", GPUType: "a100", CostPerHour: 3.0, AverageUtilization: 35, PeakMemoryMB: 12288, MemoryTotalMB: 40960},
+		// A busy A100 running training moves to spot
+		{GPUID: "a100-1", GPUType: "a100", CostPerHour: 3.0, AverageUtilization: 70, PeakMemoryMB: 38000, MemoryTotalMB: 40960, Interruptible: true},
+	}
+	plan := BuildCostActionPlan(profiles, pricing, config, time.Now())
+
+	if len(plan.Actions) != 3 {
+		t.Fatalf("Expected consolidate, downsize and spot actions, got %+v", plan.Actions)
+	}
+	byType := make(map[string]CostAction)
+	for _, action := range plan.Actions {
+		byType[action.Type] = action
+	}
+
+	consolidate := byType["consolidate"]
+	if len(consolidate.GPUIDs) != 2 || consolidate.GPUIDs[0] != "t4-1" || consolidate.GPUIDs[1] != "t4-2" {
+		t.Errorf("Expected the two least used T4s freed, got %v", consolidate.GPUIDs)
+	}
+	if consolidate.Risk != "low" || math.Abs(consolidate.EstimatedSavings-720) > 1e-9 {
+		t.Errorf("Expected 1.00/h over 720h at low risk, got %+v", consolidate)
+	}
+
+	downsize := byType["downsize"]
+	if len(downsize.GPUIDs) != 1 || downsize.GPUIDs[0] != "a100-0" || math.Abs(downsize.SavingsPerHour-2.0) > 1e-9 {
+		t.Errorf("Expected a100-0 downsized to an A10 saving 2.00/h, got %+v", downsize)
+	}
+
+	spot := byType["spot"]
+	if len(spot.GPUIDs) != 1 || spot.GPUIDs[0] != "a100-1" || math.Abs(spot.SavingsPerHour-1.8) > 1e-9 {
+		t.Errorf("Expected a100-1 on spot saving 1.80/h, got %+v", spot)
+	}
+
+	// Ordered by risk-weighted savings: downsize (1440*0.75), spot (1296*0.75), consolidate (720)
+	if plan.Actions[0].Type != "downsize" || plan.Actions[1].Type != "spot" || plan.Actions[2].Type != "consolidate" {
+		t.Errorf("Unexpected action order %s, %s, %s", plan.Actions[0].Type, plan.Actions[1].Type, plan.Actions[2].Type)
+	}
+	if math.Abs(plan.ForecastCost-7.5*720) > 1e-9 || math.Abs(plan.OptimizedCost-(plan.ForecastCost-plan.TotalSavings)) > 1e-9 {
+		t.Errorf("Unexpected forecast %+v", plan)
+	}
+}
+
+func TestCostActionPlanCountsEachGPUOnce(t *testing.T) {
+	config := DefaultCostOptimizerConfig()
+	profiles := []GPUCostProfile{
+		{GPUID: "0", GPUType: "a100", CostPerHour: 3.0, AverageUtilization: 10, PeakMemoryMB: 1024, MemoryTotalMB: 40960, Interruptible: true},
+		{GPUID: "1", GPUType: "a100", CostPerHour: 3.0, AverageUtilization: 10, PeakMemoryMB: 1024, MemoryTotalMB: 40960, Interruptible: true},
+	}
+	plan := BuildCostActionPlan(profiles, DefaultGPUCostConfiguration(), config, time.Now())
+
+	// GPU 1 is freed; GPU 0 is downsized to an A10 (priced at the generic 1.50) and put on spot
+	if math.Abs(plan.TotalSavings-(3.0+1.5+1.5*0.6)*720) > 1e-6 {
+		t.Errorf("Expected savings counted once per GPU, got %.2f from %+v", plan.TotalSavings, plan.Actions)
+	}
+	if plan.TotalSavings > plan.ForecastCost {
+		t.Errorf("Savings exceed the forecast: %+v", plan)
+	}
+}
+
+func TestCostPlanEndpoint(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{Port: 0})
+	scheduler := gpu.NewScheduler(gpu.StrategyLeastUtilized)
+	scheduler.RegisterGPU(&gpu.GPU{ID: "gpu-0", MemoryTotal: 40960, Available: true})
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "train", Class: gpu.WorkloadClassTraining, MemoryRequired: 1024})
+	scheduler.Schedule()
+	dashboard.SetScheduler(scheduler)
+	dashboard.lastMetrics["gpu-0"] = gpu.GPUMetrics{GPUID: "gpu-0", Name: "NVIDIA A100", UtilizationGPU: 90, MemoryUsed: 30000, MemoryTotal: 40960}
+
+	response := serveDashboard(dashboard, http.MethodGet, "/api/v1/costs/plan", "")
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", response.Code)
+	}
+	var plan CostActionPlan
+	if err := json.Unmarshal(response.Body.Bytes(), &plan); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(plan.Actions) != 1 || plan.Actions[0].Type != "spot" || plan.Actions[0].Risk != "high" {
+		t.Errorf("Expected a high-risk spot action for the busy training GPU, got %+v", plan.Actions)
+	}
+	if plan.Currency != "USD" || plan.ForecastCost != DefaultCostA100*720 {
+		t.Errorf("Unexpected plan totals %+v", plan)
+	}
+}
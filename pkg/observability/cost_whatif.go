@@ -0,0 +1,168 @@
+package observability
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// WhatIfScenario describes alternative cost assumptions to replay history under
+type WhatIfScenario struct {
+	Name string `yaml:"name" json:"name"`
+
+	// Hourly prices by GPU type replacing the current ones, e.g. another region's
+	CostPerHour map[string]float64 `yaml:"cost_per_hour" json:"cost_per_hour,omitempty"`
+
+	// Shares of GPU hours run on spot capacity and covered by reserved
+	// commitments, with their discounts off the on-demand price
+	SpotFraction     float64 `yaml:"spot_fraction" json:"spot_fraction,omitempty"`
+	SpotDiscount     float64 `yaml:"spot_discount" json:"spot_discount,omitempty"`
+	ReservedFraction float64 `yaml:"reserved_fraction" json:"reserved_fraction,omitempty"`
+	ReservedDiscount float64 `yaml:"reserved_discount" json:"reserved_discount,omitempty"`
+
+	// Average utilization the fleet is consolidated to; GPU hours shrink in
+	// proportion when history ran below it. 0 keeps the historical fleet.
+	TargetUtilization float64 `yaml:"target_utilization" json:"target_utilization,omitempty"`
+}
+
+// Validate reports fractions and discounts outside 0-1 and mixes above 100%
+func (s WhatIfScenario) Validate() error {
+	fractions := []struct {
+		name  string
+		value float64
+	}{
+		{"spot_fraction", s.SpotFraction},
+		{"spot_discount", s.SpotDiscount},
+		{"reserved_fraction", s.ReservedFraction},
+		{"reserved_discount", s.ReservedDiscount},
+	}
+	for _, fraction := range fractions {
+		if fraction.value < 0 || fraction.value > 1 {
+			return fmt.Errorf("scenario %q: %s must be between 0 and 1", s.Name, fraction.name)
+		}
+	}
+	if s.SpotFraction+s.ReservedFraction > 1 {
+		return fmt.Errorf("scenario %q: spot and reserved fractions exceed 100%%", s.Name)
+	}
+	if s.TargetUtilization < 0 || s.TargetUtilization > 100 {
+		return fmt.Errorf("scenario %q: target_utilization must be between 0 and 100", s.Name)
+	}
+	for gpuType, cost := range s.CostPerHour {
+		if cost < 0 {
+			return fmt.Errorf("scenario %q: negative cost for %s", s.Name, gpuType)
+		}
+	}
+	return nil
+}
+
+// WhatIfResult is the cost of the history under one set of assumptions
+type WhatIfResult struct {
+	Name         string  `json:"name"`
+	Cost         float64 `json:"cost"`
+	GPUHours     float64 `json:"gpu_hours"`
+	Delta        float64 `json:"delta"` // Against the baseline; negative saves money
+	DeltaPercent float64 `json:"delta_percent"`
+}
+
+// WhatIfReport compares scenarios with the history priced as configured today
+type WhatIfReport struct {
+	Start     time.Time      `json:"start"`
+	End       time.Time      `json:"end"`
+	Currency  string         `json:"currency"`
+	Baseline  WhatIfResult   `json:"baseline"`
+	Scenarios []WhatIfResult `json:"scenarios"`
+}
+
+// SimulateCosts recomputes the cost of per-GPU metrics history under each
+// scenario. The baseline prices the same history with the current pricing
+// so results differ only by the scenario's assumptions.
+func SimulateCosts(history map[string][]gpu.GPUMetrics, pricing GPUCostConfiguration, scenarios []WhatIfScenario) WhatIfReport {
+	report := WhatIfReport{
+		Currency:  pricing.Currency,
+		Scenarios: make([]WhatIfResult, 0, len(scenarios)),
+	}
+
+	utilizationSum, samples := 0.0, 0
+	for _, series := range history {
+		for _, metrics := range series {
+			if report.Start.IsZero() || metrics.Timestamp.Before(report.Start) {
+				report.Start = metrics.Timestamp
+			}
+			if metrics.Timestamp.After(report.End) {
+				report.End = metrics.Timestamp
+			}
+			utilizationSum += metrics.UtilizationGPU
+			samples++
+		}
+	}
+	averageUtilization := 0.0
+	if samples > 0 {
+		averageUtilization = utilizationSum / float64(samples)
+	}
+
+	report.Baseline = replayCosts(history, pricing)
+	report.Baseline.Name = "baseline"
+
+	for _, scenario := range scenarios {
+		scenarioPricing := pricing
+		if len(scenario.CostPerHour) > 0 {
+			// Custom prices take precedence over reserved and standard ones
+			scenarioPricing.CustomPricing = make(map[string]float64, len(pricing.CustomPricing)+len(scenario.CostPerHour))
+			for gpuType, cost := range pricing.CustomPricing {
+				scenarioPricing.CustomPricing[gpuType] = cost
+			}
+			for gpuType, cost := range scenario.CostPerHour {
+				scenarioPricing.CustomPricing[gpuType] = cost
+			}
+		}
+		// The scenario's spot mix replaces the configured blanket spot discount
+		scenarioPricing.SpotInstanceDiscount = 0
+
+		result := replayCosts(history, scenarioPricing)
+		result.Name = scenario.Name
+
+		mix := 1 - scenario.SpotFraction*scenario.SpotDiscount - scenario.ReservedFraction*scenario.ReservedDiscount
+		scale := mix
+		if scenario.TargetUtilization > 0 && averageUtilization < scenario.TargetUtilization {
+			consolidation := averageUtilization / scenario.TargetUtilization
+			scale *= consolidation
+			result.GPUHours *= consolidation
+			// Fewer, busier GPUs cost more per hour under utilization-based pricing
+			if pricing.UseUtilizationFactor {
+				if before := utilizationFactorAt(pricing, averageUtilization); before > 0 {
+					scale *= utilizationFactorAt(pricing, scenario.TargetUtilization) / before
+				}
+			}
+		}
+		result.Cost *= scale
+		result.Delta = result.Cost - report.Baseline.Cost
+		if report.Baseline.Cost > 0 {
+			result.DeltaPercent = result.Delta / report.Baseline.Cost * 100
+		}
+		report.Scenarios = append(report.Scenarios, result)
+	}
+	return report
+}
+
+// replayCosts prices every interval between consecutive samples of each GPU
+func replayCosts(history map[string][]gpu.GPUMetrics, pricing GPUCostConfiguration) WhatIfResult {
+	result := WhatIfResult{}
+	for _, series := range history {
+		for i := 1; i < len(series); i++ {
+			hours := series[i].Timestamp.Sub(series[i-1].Timestamp).Hours()
+			if hours <= 0 {
+				continue
+			}
+			result.GPUHours += hours
+			result.Cost += pricing.intervalCost(series[i], series[i-1])
+		}
+	}
+	return result
+}
+
+// utilizationFactorAt returns the pricing's utilization factor for a steady utilization
+func utilizationFactorAt(pricing GPUCostConfiguration, utilization float64) float64 {
+	sample := gpu.GPUMetrics{UtilizationGPU: utilization}
+	return pricing.utilizationFactor(sample, sample)
+}
@@ -0,0 +1,164 @@
+package observability
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// historyCollector serves fixed metrics history to the dashboard
+type historyCollector struct {
+	gpu.MetricsCollectorInterface
+	history map[string][]gpu.GPUMetrics
+}
+
+func (c *historyCollector) GetLatestMetrics() map[string]gpu.GPUMetrics {
+	latest := make(map[string]gpu.GPUMetrics)
+	for gpuID, series := range c.history {
+		latest[gpuID] = series[len(series)-1]
+	}
+	return latest
+}
+
+func (c *historyCollector) GetMetricsHistory(gpuID string, since time.Time) []gpu.GPUMetrics {
+	return c.history[gpuID]
+}
+
+// steadyHistory returns hourly samples of a GPU at a constant utilization
+func steadyHistory(gpuID, name string, utilization float64, hours int, start time.Time) []gpu.GPUMetrics {
+	series := make([]gpu.GPUMetrics, 0, hours+1)
+	for i := 0; i <= hours; i++ {
+		series = append(series, gpu.GPUMetrics{
+			GPUID:          gpuID,
+			Name:           name,
+			UtilizationGPU: utilization,
+			Timestamp:      start.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	return series
+}
+
+func TestSimulateCosts(t *testing.T) {
+	pricing := DefaultGPUCostConfiguration()
+	pricing.UseUtilizationFactor = false
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	history := map[string][]gpu.GPUMetrics{
+		"gpu-0": steadyHistory("gpu-0", "NVIDIA A100", 25, 10, start),
+		"gpu-1": steadyHistory("gpu-1", "Tesla T4", 25, 10, start),
+	}
+
+	report := SimulateCosts(history, pricing, []WhatIfScenario{
+		{Name: "region", CostPerHour: map[string]float64{"a100": 2.0}},
+		{Name: "spot", SpotFraction: 0.5, SpotDiscount: 0.6},
+		{Name: "consolidate", TargetUtilization: 50},
+	})
+
+	baseline := 10*DefaultCostA100 + 10*DefaultCostT4
+	if math.Abs(report.Baseline.Cost-baseline) > 1e-9 || report.Baseline.GPUHours != 20 {
+		t.Fatalf("Expected a %.2f baseline over 20 GPU hours, got %+v", baseline, report.Baseline)
+	}
+	if !report.Start.Equal(start) || !report.End.Equal(start.Add(10*time.Hour)) {
+		t.Errorf("Unexpected report range %v to %v", report.Start, report.End)
+	}
+	if len(report.Scenarios) != 3 {
+		t.Fatalf("Expected 3 scenario results, got %d", len(report.Scenarios))
+	}
+
+	region := report.Scenarios[0]
+	if want := 20 + 10*DefaultCostT4; math.Abs(region.Cost-want) > 1e-9 || math.Abs(region.Delta-(want-baseline)) > 1e-9 {
+		t.Errorf("Expected region pricing to cost %.2f, got %+v", want, region)
+	}
+
+	// Half the hours at a 60% discount cost 70% of on-demand
+	spot := report.Scenarios[1]
+	if math.Abs(spot.Cost-0.7*baseline) > 1e-9 || math.Abs(spot.DeltaPercent+30) > 1e-9 {
+		t.Errorf("Expected the spot mix to save 30%%, got %+v", spot)
+	}
+
+	// Running at 50% instead of 25% needs half the GPU hours
+	consolidate := report.Scenarios[2]
+	if math.Abs(consolidate.Cost-0.5*baseline) > 1e-9 || consolidate.GPUHours != 10 {
+		t.Errorf("Expected consolidation to halve cost and GPU hours, got %+v", consolidate)
+	}
+}
+
+func TestSimulateCostsUtilizationPricing(t *testing.T) {
+	pricing := DefaultGPUCostConfiguration()
+	history := map[string][]gpu.GPUMetrics{
+		"gpu-0": steadyHistory("gpu-0", "NVIDIA A100", 20, 4, time.Now()),
+	}
+
+	report := SimulateCosts(history, pricing, []WhatIfScenario{{Name: "same"}, {Name: "consolidate", TargetUtilization: 80}})
+
+	if math.Abs(report.Scenarios[0].Cost-report.Baseline.Cost) > 1e-9 {
+		t.Errorf("Expected an empty scenario to match the baseline, got %+v", report.Scenarios[0])
+	}
+	// A quarter of the hours, each priced at 80% rather than 20% utilization
+	want := report.Baseline.Cost / 4 * utilizationFactorAt(pricing, 80) / utilizationFactorAt(pricing, 20)
+	if math.Abs(report.Scenarios[1].Cost-want) > 1e-9 {
+		t.Errorf("Expected consolidated cost %.4f, got %.4f", want, report.Scenarios[1].Cost)
+	}
+}
+
+func TestWhatIfScenarioValidate(t *testing.T) {
+	invalid := []WhatIfScenario{
+		{Name: "fraction", SpotFraction: 1.5},
+		{Name: "discount", ReservedDiscount: -0.1},
+		{Name: "mix", SpotFraction: 0.6, ReservedFraction: 0.6},
+		{Name: "target", TargetUtilization: 120},
+		{Name: "price", CostPerHour: map[string]float64{"a100": -1}},
+	}
+	for _, scenario := range invalid {
+		if err := scenario.Validate(); err == nil {
+			t.Errorf("Expected scenario %q to be invalid", scenario.Name)
+		}
+	}
+	valid := WhatIfScenario{Name: "ok", SpotFraction: 0.4, SpotDiscount: 0.7, ReservedFraction: 0.6, ReservedDiscount: 0.3, TargetUtilization: 70}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected a valid scenario, got %v", err)
+	}
+}
+
+func TestCostWhatIfEndpoint(t *testing.T) {
+	collector := &historyCollector{history: map[string][]gpu.GPUMetrics{
+		"gpu-0": steadyHistory("gpu-0", "NVIDIA A100", 50, 2, time.Now().Add(-2*time.Hour)),
+	}}
+	dashboard := NewWebDashboard(NewMonitoringService(100), collector, nil, WebDashboardConfig{Port: 0})
+
+	post := func(body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		dashboard.server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/costs/whatif", strings.NewReader(body)))
+		return recorder
+	}
+
+	response := post(`{"hours": 24, "scenarios": [{"name": "reserved", "reserved_fraction": 1, "reserved_discount": 0.4}]}`)
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", response.Code, response.Body.String())
+	}
+	var report WhatIfReport
+	if err := json.Unmarshal(response.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if report.Baseline.GPUHours != 2 || len(report.Scenarios) != 1 || math.Abs(report.Scenarios[0].DeltaPercent+40) > 1e-9 {
+		t.Errorf("Expected full reservation to save 40%% of 2 GPU hours, got %+v", report)
+	}
+
+	for _, body := range []string{`{`, `{"scenarios": []}`, `{"scenarios": [{"name": "bad", "spot_fraction": 2}]}`} {
+		if code := post(body).Code; code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", body, code)
+		}
+	}
+
+	unconfigured := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{Port: 0})
+	recorder := httptest.NewRecorder()
+	unconfigured.server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/costs/whatif", strings.NewReader(`{}`)))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a metrics collector, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,176 @@
+package observability
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// DefaultDashboardLocale is the dashboard's language when the browser asks
+// for none of the catalog's; its messages fill gaps in the others
+const DefaultDashboardLocale = "en"
+
+// dashboardLocaleCookie remembers the language picked on the dashboard page
+const dashboardLocaleCookie = "agentaflow_lang"
+
+//go:embed i18n/*.json
+var dashboardCatalogFiles embed.FS
+
+// dashboardCatalog holds the dashboard's UI strings by locale, loaded from
+// i18n/<locale>.json
+var dashboardCatalog = loadDashboardCatalog()
+
+// loadDashboardCatalog reads every embedded message file
+func loadDashboardCatalog() map[string]map[string]string {
+	files, err := dashboardCatalogFiles.ReadDir("i18n")
+	if err != nil {
+		panic(fmt.Sprintf("failed to read dashboard messages: %v", err))
+	}
+	catalog := make(map[string]map[string]string, len(files))
+	for _, file := range files {
+		data, err := dashboardCatalogFiles.ReadFile(path.Join("i18n", file.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("failed to read dashboard messages %s: %v", file.Name(), err))
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("failed to decode dashboard messages %s: %v", file.Name(), err))
+		}
+		catalog[strings.TrimSuffix(file.Name(), ".json")] = messages
+	}
+	return catalog
+}
+
+// DashboardLocales lists the languages the dashboard is translated into
+func DashboardLocales() []string {
+	locales := make([]string, 0, len(dashboardCatalog))
+	for locale := range dashboardCatalog {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// DashboardMessages returns a locale's UI strings, with messages it has not
+// translated yet in DefaultDashboardLocale
+func DashboardMessages(locale string) (map[string]string, bool) {
+	translated, exists := dashboardCatalog[locale]
+	if !exists {
+		return nil, false
+	}
+	messages := make(map[string]string, len(dashboardCatalog[DefaultDashboardLocale]))
+	for key, message := range dashboardCatalog[DefaultDashboardLocale] {
+		messages[key] = message
+	}
+	for key, message := range translated {
+		messages[key] = message
+	}
+	return messages, true
+}
+
+// matchDashboardLocale returns the catalog locale for a language tag such
+// as de-AT, matching on the language alone
+func matchDashboardLocale(tag string) (string, bool) {
+	language := strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	_, exists := dashboardCatalog[language]
+	return language, exists
+}
+
+// NegotiateDashboardLocale picks the dashboard language from an explicit
+// choice, else the most preferred catalog language in an Accept-Language
+// header, else DefaultDashboardLocale
+func NegotiateDashboardLocale(requested, acceptLanguage string) string {
+	if locale, exists := matchDashboardLocale(requested); exists {
+		return locale
+	}
+
+	type preference struct {
+		tag     string
+		quality float64
+	}
+	var preferences []preference
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			if value := strings.TrimSpace(param); strings.HasPrefix(value, "q=") {
+				if q, err := strconv.ParseFloat(value[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			preferences = append(preferences, preference{tag: tag, quality: quality})
+		}
+	}
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+	for _, preference := range preferences {
+		if locale, exists := matchDashboardLocale(preference.tag); exists {
+			return locale
+		}
+	}
+	return DefaultDashboardLocale
+}
+
+// requestDashboardLocale negotiates the language of a request from ?lang,
+// the language cookie set by the dashboard page, then Accept-Language
+func requestDashboardLocale(r *http.Request) string {
+	requested := r.URL.Query().Get("lang")
+	if requested == "" {
+		if cookie, err := r.Cookie(dashboardLocaleCookie); err == nil {
+			requested = cookie.Value
+		}
+	}
+	return NegotiateDashboardLocale(requested, r.Header.Get("Accept-Language"))
+}
+
+// handleDashboardLocales reports the negotiated dashboard language and every
+// language the dashboard is translated into, named in that language
+func (wd *WebDashboard) handleDashboardLocales(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Vary", "Accept-Language, Cookie")
+
+	supported := make([]map[string]string, 0, len(dashboardCatalog))
+	for _, locale := range DashboardLocales() {
+		messages, _ := DashboardMessages(locale)
+		supported = append(supported, map[string]string{"locale": locale, "name": messages["language.name"]})
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"locale":    requestDashboardLocale(r),
+		"default":   DefaultDashboardLocale,
+		"supported": supported,
+	})
+}
+
+// handleDashboardMessages serves the message catalog of the locale in the
+// path
+func (wd *WebDashboard) handleDashboardMessages(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	locale := mux.Vars(r)["locale"]
+	messages, exists := DashboardMessages(locale)
+	if !exists {
+		http.Error(w, fmt.Sprintf("locale %s not supported, must be one of %s", locale, strings.Join(DashboardLocales(), ", ")), http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"locale":   locale,
+		"messages": messages,
+	})
+}
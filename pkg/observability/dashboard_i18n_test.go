@@ -0,0 +1,125 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestDashboardCatalogsAreComplete(t *testing.T) {
+	if locales := DashboardLocales(); strings.Join(locales, ",") != "de,en,ja" {
+		t.Fatalf("Expected German, English and Japanese, got %v", locales)
+	}
+
+	placeholder := regexp.MustCompile(`\{[a-z_]+\}`)
+	placeholders := func(message string) string {
+		found := placeholder.FindAllString(message, -1)
+		sort.Strings(found)
+		return strings.Join(found, "")
+	}
+	english := dashboardCatalog[DefaultDashboardLocale]
+	for locale, messages := range dashboardCatalog {
+		for key, message := range english {
+			translated, exists := messages[key]
+			if !exists {
+				t.Errorf("%s: missing %s", locale, key)
+				continue
+			}
+			if placeholders(translated) != placeholders(message) {
+				t.Errorf("%s: %s has placeholders %q, want %q", locale, key, placeholders(translated), placeholders(message))
+			}
+		}
+		for key := range messages {
+			if _, exists := english[key]; !exists {
+				t.Errorf("%s: %s is not in the %s catalog", locale, key, DefaultDashboardLocale)
+			}
+		}
+	}
+
+	// Every string the page looks up is in the catalog
+	html := getDashboardHTML(WebDashboardConfig{}, DefaultDashboardLocale)
+	lookup := regexp.MustCompile(`data-i18n(?:-label)?="([a-z0-9_.]+)"|\bt\('([a-z0-9_.]+)'`)
+	matches := lookup.FindAllStringSubmatch(html, -1)
+	if len(matches) < 40 {
+		t.Errorf("Expected the page's strings to be looked up, found %d", len(matches))
+	}
+	for _, match := range matches {
+		key := match[1] + match[2]
+		if _, exists := english[key]; !exists {
+			t.Errorf("The page looks up %s, which is not in the catalog", key)
+		}
+	}
+}
+
+func TestNegotiateDashboardLocale(t *testing.T) {
+	cases := []struct {
+		requested, acceptLanguage, want string
+	}{
+		{"", "", "en"},
+		{"", "ja-JP,ja;q=0.9,en;q=0.8", "ja"},
+		{"", "fr-FR, de-AT;q=0.7, en;q=0.5", "de"},
+		{"", "en;q=0.3, de;q=0.9", "de"},
+		{"", "de;q=0, ja;q=0.1", "ja"},
+		{"", "fr, *", "en"},
+		{"ja", "de", "ja"},
+		{"DE_de", "ja", "de"},
+		{"fr", "ja", "ja"},
+	}
+	for _, c := range cases {
+		if got := NegotiateDashboardLocale(c.requested, c.acceptLanguage); got != c.want {
+			t.Errorf("NegotiateDashboardLocale(%q, %q) = %q, want %q", c.requested, c.acceptLanguage, got, c.want)
+		}
+	}
+}
+
+func TestDashboardI18nEndpoints(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{Port: 0, Title: "GPUs"})
+	serve := func(path, acceptLanguage string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Language", acceptLanguage)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		recorder := httptest.NewRecorder()
+		dashboard.server.Handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	var negotiated struct {
+		Locale    string              `json:"locale"`
+		Supported []map[string]string `json:"supported"`
+	}
+	json.Unmarshal(serve("/api/v1/i18n/locales", "ja-JP,ja;q=0.9", nil).Body.Bytes(), &negotiated)
+	if negotiated.Locale != "ja" || len(negotiated.Supported) != 3 || negotiated.Supported[2]["name"] != "日本語" {
+		t.Errorf("Expected Japanese negotiated among 3 languages, got %+v", negotiated)
+	}
+
+	var catalog struct {
+		Locale   string            `json:"locale"`
+		Messages map[string]string `json:"messages"`
+	}
+	json.Unmarshal(serve("/api/v1/i18n/messages/de", "", nil).Body.Bytes(), &catalog)
+	if catalog.Locale != "de" || catalog.Messages["panel.alerts"] != "Aktive Warnungen" {
+		t.Errorf("Expected the German catalog, got %+v", catalog)
+	}
+	if response := serve("/api/v1/i18n/messages/fr", "", nil); response.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an untranslated locale, got %d", response.Code)
+	}
+
+	page := serve("/", "de-DE,de;q=0.9", nil).Body.String()
+	if !strings.Contains(page, `<html lang="de">`) || !strings.Contains(page, `"panel.alerts":"Aktive Warnungen"`) {
+		t.Error("Expected the dashboard page in German")
+	}
+	page = serve("/", "de-DE", &http.Cookie{Name: dashboardLocaleCookie, Value: "ja"}).Body.String()
+	if !strings.Contains(page, `<html lang="ja">`) {
+		t.Error("Expected the language picked on the page to override Accept-Language")
+	}
+	page = serve("/?lang=en", "de-DE", &http.Cookie{Name: dashboardLocaleCookie, Value: "ja"}).Body.String()
+	if !strings.Contains(page, `<html lang="en">`) || strings.Contains(page, "{{.Messages}}") {
+		t.Error("Expected ?lang to override the cookie")
+	}
+}
@@ -0,0 +1,209 @@
+package observability
+
+import (
+	"fmt"
+)
+
+// Built-in dashboard panels
+const (
+	PanelSystemMetrics    = "system_metrics"
+	PanelGPUGrid          = "gpu_grid"
+	PanelPerformanceChart = "performance_chart"
+	PanelCostChart        = "cost_chart"
+	PanelAlerts           = "alerts"
+	PanelPeriodComparison = "period_comparison"
+)
+
+// builtinPanels lists every panel the dashboard page can render
+var builtinPanels = []string{PanelSystemMetrics, PanelGPUGrid, PanelPerformanceChart, PanelCostChart, PanelAlerts, PanelPeriodComparison}
+
+// DashboardPanel places a panel on the dashboard page
+type DashboardPanel struct {
+	ID    string `yaml:"id" json:"id"`
+	Title string `yaml:"title" json:"title,omitempty"` // Replaces the panel's heading when set
+	Width int    `yaml:"width" json:"width"`           // Bootstrap grid columns, 1-12
+}
+
+// Custom panel visualizations
+const (
+	VisualizationLine = "line"
+	VisualizationBar  = "bar"
+	VisualizationStat = "stat"
+)
+
+// CustomPanel renders a user-defined metric query next to the built-in panels
+type CustomPanel struct {
+	ID            string      `yaml:"id" json:"id"`
+	Title         string      `yaml:"title" json:"title"`
+	Visualization string      `yaml:"visualization" json:"visualization"` // line, bar or stat
+	Unit          string      `yaml:"unit" json:"unit,omitempty"`
+	Query         MetricQuery `yaml:"query" json:"query"`
+}
+
+// Validate checks the panel's ID, visualization and query
+func (p CustomPanel) Validate() error {
+	if p.ID == "" {
+		return fmt.Errorf("id must not be empty")
+	}
+	for _, id := range builtinPanels {
+		if id == p.ID {
+			return fmt.Errorf("id %q is a built-in panel", p.ID)
+		}
+	}
+	switch p.Visualization {
+	case VisualizationLine, VisualizationBar, VisualizationStat:
+	default:
+		return fmt.Errorf("visualization must be %s, %s or %s, got %q", VisualizationLine, VisualizationBar, VisualizationStat, p.Visualization)
+	}
+	if err := p.Query.Validate(); err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+	return nil
+}
+
+// DashboardThresholds sets when GPU cards turn warning or critical
+type DashboardThresholds struct {
+	TemperatureWarning  float64 `yaml:"temperature_warning" json:"temperature_warning"`
+	TemperatureCritical float64 `yaml:"temperature_critical" json:"temperature_critical"`
+	UtilizationWarning  float64 `yaml:"utilization_warning" json:"utilization_warning"`
+	UtilizationCritical float64 `yaml:"utilization_critical" json:"utilization_critical"`
+}
+
+// DashboardLayout is the panels, thresholds and refresh rate of one dashboard
+// page. Panels are shown in order; built-in panels not listed are hidden and
+// custom panels not listed follow the listed ones.
+type DashboardLayout struct {
+	Title           string              `yaml:"title" json:"title,omitempty"`
+	RefreshInterval int                 `yaml:"refresh_interval" json:"refresh_interval"` // Milliseconds
+	Panels          []DashboardPanel    `yaml:"panels" json:"panels"`
+	Thresholds      DashboardThresholds `yaml:"thresholds" json:"thresholds"`
+}
+
+// DashboardLayoutConfig is a version-controlled dashboard definition: a
+// default layout plus overrides rendered for each GPU pool. Zero values in
+// a pool layout inherit from the default.
+type DashboardLayoutConfig struct {
+	Default DashboardLayout            `yaml:"default" json:"default"`
+	Pools   map[string]DashboardLayout `yaml:"pools" json:"pools,omitempty"`
+
+	// Panels defined by metric queries, available to every layout
+	CustomPanels []CustomPanel `yaml:"custom_panels" json:"custom_panels,omitempty"`
+}
+
+// DefaultDashboardLayoutConfig returns the stock dashboard layout
+func DefaultDashboardLayoutConfig() DashboardLayoutConfig {
+	return DashboardLayoutConfig{
+		Default: DashboardLayout{
+			RefreshInterval: 3000,
+			Panels: []DashboardPanel{
+				{ID: PanelSystemMetrics, Width: 12},
+				{ID: PanelGPUGrid, Width: 12},
+				{ID: PanelPerformanceChart, Width: 8},
+				{ID: PanelCostChart, Width: 4},
+				{ID: PanelAlerts, Width: 12},
+				{ID: PanelPeriodComparison, Width: 12},
+			},
+			Thresholds: DashboardThresholds{
+				TemperatureWarning:  75,
+				TemperatureCritical: 85,
+				UtilizationWarning:  80,
+				UtilizationCritical: 95,
+			},
+		},
+		Pools: make(map[string]DashboardLayout),
+	}
+}
+
+// ForPool returns the layout rendered for a pool, or the default layout
+// when the pool has no override
+func (c DashboardLayoutConfig) ForPool(pool string) DashboardLayout {
+	layout, exists := c.Pools[pool]
+	if !exists {
+		return c.Default
+	}
+	if layout.Title == "" {
+		layout.Title = c.Default.Title
+	}
+	if layout.RefreshInterval == 0 {
+		layout.RefreshInterval = c.Default.RefreshInterval
+	}
+	if len(layout.Panels) == 0 {
+		layout.Panels = c.Default.Panels
+	}
+	if layout.Thresholds == (DashboardThresholds{}) {
+		layout.Thresholds = c.Default.Thresholds
+	}
+	return layout
+}
+
+// Validate checks every layout's panels, thresholds and refresh interval
+func (c DashboardLayoutConfig) Validate() error {
+	var errs ConfigErrors
+
+	panels := make(map[string]bool)
+	for _, id := range builtinPanels {
+		panels[id] = true
+	}
+	for i, panel := range c.CustomPanels {
+		field := fmt.Sprintf("custom_panels[%d]", i)
+		if err := panel.Validate(); err != nil {
+			errs.add(field, "%v", err)
+		} else if panels[panel.ID] {
+			errs.add(field+".id", "panel %q is defined twice", panel.ID)
+		}
+		panels[panel.ID] = true
+	}
+
+	c.Default.validate("default", true, panels, &errs)
+	for pool, layout := range c.Pools {
+		layout.validate(fmt.Sprintf("pools.%s", pool), false, panels, &errs)
+	}
+	return errs.err()
+}
+
+// CustomPanel returns a custom panel by ID
+func (c DashboardLayoutConfig) CustomPanel(id string) (CustomPanel, bool) {
+	for _, panel := range c.CustomPanels {
+		if panel.ID == id {
+			return panel, true
+		}
+	}
+	return CustomPanel{}, false
+}
+
+// validate records a layout's problems under prefix; inherited zero values
+// are only allowed in pool layouts and panels must be built-in or custom
+func (l DashboardLayout) validate(prefix string, required bool, panels map[string]bool, errs *ConfigErrors) {
+	if l.RefreshInterval < 0 || (required && l.RefreshInterval == 0) {
+		errs.add(prefix+".refresh_interval", "must be positive, got %d", l.RefreshInterval)
+	}
+	if required && len(l.Panels) == 0 {
+		errs.add(prefix+".panels", "must list at least one panel")
+	}
+
+	seen := make(map[string]bool)
+	for i, panel := range l.Panels {
+		field := fmt.Sprintf("%s.panels[%d]", prefix, i)
+		if !panels[panel.ID] {
+			errs.add(field+".id", "unknown panel %q (expected a custom panel or one of %v)", panel.ID, builtinPanels)
+		}
+		if seen[panel.ID] {
+			errs.add(field+".id", "panel %q is listed twice", panel.ID)
+		}
+		seen[panel.ID] = true
+		if panel.Width < 1 || panel.Width > 12 {
+			errs.add(field+".width", "must be between 1 and 12, got %d", panel.Width)
+		}
+	}
+
+	thresholds := l.Thresholds
+	if !required && thresholds == (DashboardThresholds{}) {
+		return
+	}
+	if thresholds.TemperatureWarning <= 0 || thresholds.TemperatureWarning >= thresholds.TemperatureCritical {
+		errs.add(prefix+".thresholds.temperature_warning", "must be positive and below temperature_critical")
+	}
+	if thresholds.UtilizationWarning <= 0 || thresholds.UtilizationWarning >= thresholds.UtilizationCritical || thresholds.UtilizationCritical > 100 {
+		errs.add(prefix+".thresholds.utilization_warning", "must be positive and below utilization_critical, at most 100")
+	}
+}
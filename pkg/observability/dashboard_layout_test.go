@@ -0,0 +1,131 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+const layoutYAML = `
+default:
+  title: GPU Fleet
+  refresh_interval: 5000
+  panels:
+    - {id: gpu_grid, width: 12}
+    - {id: alerts, width: 6, title: Pages}
+  thresholds:
+    temperature_warning: 70
+    temperature_critical: 80
+    utilization_warning: 85
+    utilization_critical: 98
+pools:
+  research:
+    panels:
+      - {id: performance_chart, width: 12}
+`
+
+func TestDashboardLayoutForPool(t *testing.T) {
+	config := DefaultDashboardLayoutConfig()
+	if err := DecodeConfig([]byte(layoutYAML), ConfigFormatYAML, "layout.yaml", &config); err != nil {
+		t.Fatalf("DecodeConfig failed: %v", err)
+	}
+
+	research := config.ForPool("research")
+	if len(research.Panels) != 1 || research.Panels[0].ID != PanelPerformanceChart {
+		t.Errorf("Expected the research pool's own panels, got %+v", research.Panels)
+	}
+	if research.RefreshInterval != 5000 || research.Title != "GPU Fleet" || research.Thresholds.TemperatureCritical != 80 {
+		t.Errorf("Expected research to inherit the default's settings, got %+v", research)
+	}
+
+	other := config.ForPool("prod-inference")
+	if len(other.Panels) != 2 || other.Panels[1].Title != "Pages" {
+		t.Errorf("Expected pools without overrides to get the default layout, got %+v", other)
+	}
+}
+
+func TestDashboardLayoutValidate(t *testing.T) {
+	config := DefaultDashboardLayoutConfig()
+	config.Default.Panels = append(config.Default.Panels, DashboardPanel{ID: PanelAlerts, Width: 12}, DashboardPanel{ID: "sparkline", Width: 13})
+	config.Default.Thresholds.UtilizationWarning = 99
+	config.Pools["ci"] = DashboardLayout{RefreshInterval: -1}
+
+	err := config.Validate()
+	if err == nil {
+		t.Fatal("Expected an invalid layout")
+	}
+	errs := err.(ConfigErrors)
+	fields := make(map[string]bool)
+	for _, e := range errs {
+		fields[e.Field] = true
+	}
+	for _, field := range []string{
+		"default.panels[6].id", "default.panels[7].id", "default.panels[7].width",
+		"default.thresholds.utilization_warning", "pools.ci.refresh_interval",
+	} {
+		if !fields[field] {
+			t.Errorf("Expected a problem with %s, got %v", field, errs)
+		}
+	}
+
+	if err := DefaultDashboardLayoutConfig().Validate(); err != nil {
+		t.Errorf("Expected the default layout to be valid, got %v", err)
+	}
+}
+
+func TestDashboardLayoutEndpoints(t *testing.T) {
+	config := DefaultDashboardLayoutConfig()
+	config.Pools["research"] = DashboardLayout{Panels: []DashboardPanel{{ID: PanelGPUGrid, Width: 12}}}
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{Port: 0, Layout: config})
+
+	response := serveDashboard(dashboard, http.MethodGet, "/api/v1/layout/render?pool=research", "")
+	var layout DashboardLayout
+	if err := json.Unmarshal(response.Body.Bytes(), &layout); err != nil {
+		t.Fatalf("Failed to decode layout: %v", err)
+	}
+	if len(layout.Panels) != 1 || layout.RefreshInterval != 3000 {
+		t.Errorf("Unexpected research layout %+v", layout)
+	}
+
+	response = serveDashboard(dashboard, http.MethodGet, "/api/v1/layout?format=yaml", "")
+	if response.Code != http.StatusOK || response.Header().Get("Content-Type") != "application/x-yaml" {
+		t.Fatalf("Expected a YAML export, got %d %s", response.Code, response.Header().Get("Content-Type"))
+	}
+	var exported DashboardLayoutConfig
+	if err := yaml.Unmarshal(response.Body.Bytes(), &exported); err != nil {
+		t.Fatalf("Failed to decode YAML export: %v", err)
+	}
+	if len(exported.Default.Panels) != 6 || len(exported.Pools["research"].Panels) != 1 {
+		t.Errorf("Expected the export to round-trip, got %+v", exported)
+	}
+
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/layout?format=xml", ""); response.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown format, got %d", response.Code)
+	}
+
+	invalid := DefaultDashboardLayoutConfig()
+	invalid.Default.Panels = nil
+	if err := dashboard.SetDashboardLayout(invalid); err == nil {
+		t.Error("Expected SetDashboardLayout to reject a layout without panels")
+	}
+
+	page := serveDashboard(dashboard, http.MethodGet, "/", "").Body.String()
+	for _, panel := range builtinPanels {
+		if !strings.Contains(page, `data-panel="`+panel+`"`) {
+			t.Errorf("Expected the page to render panel %s", panel)
+		}
+	}
+}
+
+func TestExampleDashboardLayoutLoads(t *testing.T) {
+	config := DefaultDashboardLayoutConfig()
+	if err := LoadConfigFile("../../examples/demo/web-dashboard/dashboard-layout.yaml", &config); err != nil {
+		t.Fatalf("Example layout is invalid: %v", err)
+	}
+	if config.ForPool("prod-inference").RefreshInterval != 1000 {
+		t.Errorf("Expected prod-inference to refresh every second, got %+v", config.ForPool("prod-inference"))
+	}
+}
@@ -1,14 +1,16 @@
 package observability
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 )
 
-// getDashboardHTML returns the complete HTML for the web dashboard
-func getDashboardHTML(config WebDashboardConfig) string {
+// getDashboardHTML returns the complete HTML for the web dashboard, with UI
+// strings in locale
+func getDashboardHTML(config WebDashboardConfig, locale string) string {
 	tmpl := `<!DOCTYPE html>
-<html lang="en">
+<html lang="{{.Lang}}">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
@@ -263,6 +265,15 @@ func getDashboardHTML(config WebDashboardConfig) string {
         .status-warning { background: var(--accent-yellow); color: #333; }
         .status-critical { background: var(--accent-red); color: white; }
 
+        .mps-badge {
+            margin-left: 0.4rem;
+            padding: 0.1rem 0.4rem;
+            border-radius: 4px;
+            font-size: 0.7rem;
+            font-weight: 600;
+            background: rgba(106, 135, 219, 0.3);
+        }
+
         .chart-container {
             background: linear-gradient(135deg, #2c3e50 0%, #34495e 50%, #2c3e50 100%);
             border: 1px solid rgba(106, 135, 219, 0.3);
@@ -451,7 +462,8 @@ func getDashboardHTML(config WebDashboardConfig) string {
                 <i class="bi bi-gpu-card me-2"></i>
                 {{.Title}}
             </a>
-            <div class="navbar-text">
+            <div class="navbar-text d-flex align-items-center">
+                <select id="language-select" class="form-select form-select-sm me-3" aria-label="Language" data-i18n-label="language.label"></select>
                 <span class="status-indicator status-healthy"></span>
                 <span id="current-time"></span>
             </div>
@@ -461,29 +473,48 @@ func getDashboardHTML(config WebDashboardConfig) string {
     <!-- Connection Status -->
     <div id="connection-status" class="connection-status disconnected">
         <div class="loading"></div>
-        <span>Connecting...</span>
+        <span data-i18n="status.connecting">Connecting...</span>
     </div>
 
     <!-- Main Container -->
     <div class="main-container">
+        <!-- Shown while AgentaFlow's own monitoring pipeline is failing -->
+        <div id="pipeline-banner" class="alert alert-warning d-none" role="alert">
+            <i class="fas fa-exclamation-triangle me-2"></i>
+            <strong data-i18n="banner.pipeline_degraded">Monitoring pipeline degraded:</strong>
+            <span id="pipeline-banner-sources"></span>. <span data-i18n="banner.pipeline_stale">Dashboard data may be stale or incomplete.</span>
+        </div>
+
+        <!-- Shown while node data has gaps from agents that lost the server -->
+        <div id="gaps-banner" class="alert alert-info d-none" role="alert">
+            <i class="fas fa-history me-2"></i>
+            <strong data-i18n="banner.gaps">Gaps in node data:</strong>
+            <span id="gaps-banner-periods"></span>
+        </div>
+
+        <!-- Panels are ordered, sized and hidden by the dashboard layout -->
+        <div class="row" id="dashboard-panels">
         <!-- System Overview Metrics -->
-        <div class="metrics-grid" id="system-metrics">
-            <!-- Metrics cards will be populated by JavaScript -->
+        <div class="col-12" data-panel="system_metrics">
+            <div class="metrics-grid" id="system-metrics">
+                <!-- Metrics cards will be populated by JavaScript -->
+            </div>
         </div>
 
         <!-- GPU Status Grid -->
-        <div class="gpu-grid" id="gpu-grid">
-            <!-- GPU cards will be populated by JavaScript -->
+        <div class="col-12" data-panel="gpu_grid">
+            <div class="gpu-grid" id="gpu-grid">
+                <!-- GPU cards will be populated by JavaScript -->
+            </div>
         </div>
 
         <!-- Performance Charts -->
-        <div class="row">
-            <div class="col-lg-8">
+            <div class="col-lg-8" data-panel="performance_chart">
                 <div class="chart-container">
                     <div class="chart-header">
                         <h3 class="chart-title">
                             <i class="bi bi-graph-up me-2"></i>
-                            GPU Performance
+                            <span class="panel-title" data-i18n="panel.performance_chart">GPU Performance</span>
                         </h3>
                         <div class="btn-group btn-group-sm" role="group">
                             <button type="button" class="btn btn-outline-secondary active" data-timerange="1h">1H</button>
@@ -496,12 +527,12 @@ func getDashboardHTML(config WebDashboardConfig) string {
                     </div>
                 </div>
             </div>
-            <div class="col-lg-4">
+            <div class="col-lg-4" data-panel="cost_chart">
                 <div class="chart-container">
                     <div class="chart-header">
                         <h3 class="chart-title">
                             <i class="bi bi-currency-dollar me-2"></i>
-                            Cost Analytics
+                            <span class="panel-title" data-i18n="panel.cost_chart">Cost Analytics</span>
                         </h3>
                     </div>
                     <div class="chart-canvas">
@@ -509,33 +540,93 @@ func getDashboardHTML(config WebDashboardConfig) string {
                     </div>
                 </div>
             </div>
-        </div>
 
         <!-- Alerts -->
+        <div class="col-12" data-panel="alerts">
         <div class="alerts-container">
             <div class="chart-header">
                 <h3 class="chart-title">
                     <i class="bi bi-exclamation-triangle me-2"></i>
-                    Active Alerts
+                    <span class="panel-title" data-i18n="panel.alerts">Active Alerts</span>
                     <span id="alert-count" class="badge bg-warning ms-2">0</span>
                 </h3>
-                <button class="btn btn-sm btn-outline-secondary" onclick="clearAllAlerts()">
+                <button class="btn btn-sm btn-outline-secondary" onclick="clearAllAlerts()" data-i18n="alerts.clear_all">
                     Clear All
                 </button>
             </div>
+            <div id="incidents-list"></div>
             <div id="alerts-list">
                 <div class="text-muted text-center py-3">
                     <i class="bi bi-check-circle-fill me-2"></i>
-                    No active alerts
+                    <span data-i18n="alerts.none">No active alerts</span>
+                </div>
+            </div>
+        </div>
+        </div>
+
+        <!-- Period Comparison -->
+        <div class="col-12" data-panel="period_comparison">
+            <div class="chart-container">
+                <div class="chart-header">
+                    <h3 class="chart-title">
+                        <i class="bi bi-arrow-left-right me-2"></i>
+                        <span class="panel-title" data-i18n="panel.period_comparison">Period Comparison</span>
+                    </h3>
+                    <div class="btn-group btn-group-sm" role="group">
+                        <button type="button" class="btn btn-outline-secondary" data-compare="24" data-i18n="compare.day">Day</button>
+                        <button type="button" class="btn btn-outline-secondary active" data-compare="168" data-i18n="compare.week">Week</button>
+                        <button type="button" class="btn btn-outline-secondary" data-compare="720" data-i18n="compare.30_days">30 Days</button>
+                    </div>
                 </div>
+                <div id="comparison-table" class="text-muted text-center py-3" data-i18n="compare.loading">Loading...</div>
             </div>
         </div>
+        </div>
     </div>
 
     <!-- Bootstrap JS -->
     <script src="https://cdn.jsdelivr.net/npm/bootstrap@5.3.0/dist/js/bootstrap.bundle.min.js"></script>
 
     <script>
+        // UI strings in the negotiated language, from the backend's message catalog
+        const messages = {{.Messages}};
+        const dashboardLocales = {{.Locales}};
+
+        // Translate a message, filling {name} placeholders from vars
+        function t(key, vars) {
+            let text = messages[key] || key;
+            Object.keys(vars || {}).forEach(name => {
+                text = text.split('{' + name + '}').join(vars[name]);
+            });
+            return text;
+        }
+
+        // Translate every element marked with data-i18n, and offer the other
+        // languages; picking one is remembered in a cookie
+        function translatePage() {
+            document.querySelectorAll('[data-i18n]').forEach(el => {
+                el.textContent = t(el.dataset.i18n);
+            });
+            document.querySelectorAll('[data-i18n-label]').forEach(el => {
+                el.setAttribute('aria-label', t(el.dataset.i18nLabel));
+            });
+            const select = document.getElementById('language-select');
+            dashboardLocales.forEach(locale => {
+                const option = document.createElement('option');
+                option.value = locale.locale;
+                option.textContent = locale.name;
+                option.selected = locale.locale === document.documentElement.lang;
+                select.appendChild(option);
+            });
+            select.addEventListener('change', function() {
+                document.cookie = 'agentaflow_lang=' + encodeURIComponent(this.value) + '; path=/; max-age=31536000; samesite=lax';
+                const page = new URL(window.location.href);
+                page.searchParams.delete('lang');
+                window.location.href = page.toString();
+            });
+        }
+        translatePage();
+
         // Dashboard state
         let wsConnection = null;
         let performanceChart = null;
@@ -544,6 +635,10 @@ func getDashboardHTML(config WebDashboardConfig) string {
         let metricsData = {};
         let reconnectAttempts = 0;
         const maxReconnectAttempts = 5;
+        let layout = {
+            refresh_interval: {{.RefreshInterval}},
+            thresholds: { temperature_warning: 75, temperature_critical: 85, utilization_warning: 80, utilization_critical: 95 }
+        };
         
         // Initialize dashboard
         document.addEventListener('DOMContentLoaded', function() {
@@ -570,8 +665,18 @@ func getDashboardHTML(config WebDashboardConfig) string {
         function connectWebSocket() {
             const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
             const wsUrl = protocol + '//' + window.location.host + '/ws';
+            // A control token lets the server apply the user's notification
+            // preferences. It is offered as a subprotocol rather than in the URL
+            // and kept for the browser session only.
+            const protocols = ['agentaflow.v1'];
+            const token = sessionStorage.getItem('agentaflowToken');
+            if (token) {
+                const encoded = btoa(unescape(encodeURIComponent(token)))
+                    .replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '');
+                protocols.push('agentaflow.token.' + encoded);
+            }
             
-            wsConnection = new WebSocket(wsUrl);
+            wsConnection = new WebSocket(wsUrl, protocols);
             
             wsConnection.onopen = function(event) {
                 console.log('WebSocket connected');
@@ -619,7 +724,10 @@ func getDashboardHTML(config WebDashboardConfig) string {
                     updateDashboard(message.data);
                     break;
                 case 'alert':
-                    addAlert(message.data);
+                    addAlert(message.data, message.notify !== false);
+                    break;
+                case 'incident':
+                    updateIncident(message.data.incident);
                     break;
                 case 'notification':
                     showNotification(message.data);
@@ -637,10 +745,10 @@ func getDashboardHTML(config WebDashboardConfig) string {
             const statusEl = document.getElementById('connection-status');
             if (connected) {
                 statusEl.className = 'connection-status connected';
-                statusEl.innerHTML = '<i class="bi bi-wifi text-success"></i><span>Connected</span>';
+                statusEl.innerHTML = '<i class="bi bi-wifi text-success"></i><span>' + t('status.connected') + '</span>';
             } else {
                 statusEl.className = 'connection-status disconnected';
-                statusEl.innerHTML = '<div class="loading"></div><span>Reconnecting...</span>';
+                statusEl.innerHTML = '<div class="loading"></div><span>' + t('status.reconnecting') + '</span>';
             }
         }
 
@@ -661,15 +769,217 @@ func getDashboardHTML(config WebDashboardConfig) string {
             }
         }
 
+        // Apply the dashboard layout for the page's ?pool, or the pool of its
+        // saved ?view, before refreshing
+        async function loadLayout() {
+            try {
+                const page = new URLSearchParams(window.location.search);
+                const params = new URLSearchParams();
+                ['pool', 'view'].forEach(key => {
+                    if (page.has(key)) params.set(key, page.get(key));
+                });
+                const response = await fetch('/api/v1/layout/render?' + params.toString());
+                layout = await response.json();
+            } catch (error) {
+                console.error('Error fetching dashboard layout, using defaults:', error);
+                return;
+            }
+
+            const container = document.getElementById('dashboard-panels');
+            await loadCustomPanels(container);
+            const listed = {};
+            (layout.panels || []).forEach(panel => {
+                const el = container.querySelector('[data-panel="' + panel.id + '"]');
+                if (!el) return;
+                listed[panel.id] = true;
+                el.className = panel.width >= 12 ? 'col-12' : 'col-lg-' + panel.width;
+                el.style.display = '';
+                if (panel.title) {
+                    const title = el.querySelector('.panel-title');
+                    if (title) title.textContent = panel.title;
+                }
+                container.appendChild(el);
+            });
+            container.querySelectorAll('[data-panel]').forEach(el => {
+                if (listed[el.dataset.panel]) return;
+                if (el.dataset.custom) {
+                    container.appendChild(el);
+                } else {
+                    el.style.display = 'none';
+                }
+            });
+            if (layout.title) {
+                document.title = layout.title;
+            }
+        }
+
+        // Create an element for every custom panel, rendered from its metric query
+        let customPanels = [];
+        const customCharts = {};
+        async function loadCustomPanels(container) {
+            try {
+                const response = await fetch('/api/v1/panels');
+                customPanels = await response.json();
+            } catch (error) {
+                console.error('Error fetching custom panels:', error);
+                return;
+            }
+            customPanels.forEach(panel => {
+                const el = document.createElement('div');
+                el.className = 'col-lg-6';
+                el.dataset.panel = panel.id;
+                el.dataset.custom = 'true';
+                el.innerHTML = '<div class="chart-container"><div class="chart-header"><h3 class="chart-title">' +
+                    '<i class="bi bi-bar-chart me-2"></i><span class="panel-title"></span></h3></div>' +
+                    (panel.visualization === 'stat'
+                        ? '<div class="panel-stat display-5 fw-semibold text-center py-4">-</div>'
+                        : '<div class="chart-canvas"><canvas></canvas></div>') +
+                    '</div>';
+                el.querySelector('.panel-title').textContent = panel.title || panel.id;
+                container.appendChild(el);
+            });
+        }
+
+        // Refresh every custom panel from its query
+        // Show the degraded banner with the failing pipeline sources
+        async function checkPipeline() {
+            try {
+                const response = await fetch('/api/v1/system/pipeline');
+                if (!response.ok) return;
+                const status = await response.json();
+                const failing = status.sources.filter(source => source.degraded).map(source => {
+                    const reasons = Object.keys(source.errors).join(', ');
+                    return source.source + (reasons ? ' (' + reasons + ')' : '');
+                });
+                document.getElementById('pipeline-banner-sources').textContent = failing.join('; ');
+                document.getElementById('pipeline-banner').classList.toggle('d-none', !status.degraded);
+            } catch (error) {
+                console.error('Error checking monitoring pipeline:', error);
+            }
+        }
+
+        // Show the periods of node data missing, lost or filled in late
+        async function checkDataGaps() {
+            try {
+                const response = await fetch('/api/v1/agents/gaps?hours=24');
+                if (!response.ok) return;
+                const result = await response.json();
+                const time = value => new Date(value).toLocaleTimeString();
+                const periods = result.gaps.map(gap => {
+                    const until = gap.to ? time(gap.to) : 'now';
+                    const lost = gap.samples ? ', ' + gap.samples + ' samples' : '';
+                    return gap.node + ' ' + time(gap.from) + ' to ' + until + ' (' + gap.status + lost + ')';
+                });
+                document.getElementById('gaps-banner-periods').textContent = periods.join('; ');
+                document.getElementById('gaps-banner').classList.toggle('d-none', periods.length === 0);
+            } catch (error) {
+                console.error('Error checking data gaps:', error);
+            }
+        }
+
+        function refreshCustomPanels() {
+            customPanels.forEach(async panel => {
+                const el = document.querySelector('[data-panel="' + panel.id + '"]');
+                if (!el || el.style.display === 'none') return;
+                try {
+                    const response = await fetch('/api/v1/panels/' + encodeURIComponent(panel.id) + '/data');
+                    renderCustomPanel(el, panel, await response.json());
+                } catch (error) {
+                    console.error('Error fetching panel ' + panel.id + ':', error);
+                }
+            });
+        }
+
+        // Chart.js plugin drawing the annotations set on chart.$annotations
+        // as vertical markers, shading the span of annotated periods
+        const annotationMarkers = {
+            id: 'annotationMarkers',
+            afterDatasetsDraw(chart) {
+                const x = chart.scales.x;
+                const area = chart.chartArea;
+                const ctx = chart.ctx;
+                (chart.$annotations || []).forEach(annotation => {
+                    const start = x.getPixelForValue(new Date(annotation.time).getTime());
+                    if (start < area.left || start > area.right) return;
+                    ctx.save();
+                    if (annotation.end) {
+                        const end = Math.min(x.getPixelForValue(new Date(annotation.end).getTime()), area.right);
+                        ctx.fillStyle = 'rgba(114, 46, 209, 0.08)';
+                        ctx.fillRect(start, area.top, end - start, area.bottom - area.top);
+                    }
+                    ctx.strokeStyle = '#722ed1';
+                    ctx.setLineDash([4, 4]);
+                    ctx.beginPath();
+                    ctx.moveTo(start, area.top);
+                    ctx.lineTo(start, area.bottom);
+                    ctx.stroke();
+                    ctx.fillStyle = '#722ed1';
+                    ctx.font = '11px sans-serif';
+                    ctx.fillText(annotation.text, start + 4, area.top + 12);
+                    ctx.restore();
+                });
+            }
+        };
+
+        // Draw a query result as a stat or a line or bar chart per series
+        function renderCustomPanel(el, panel, result) {
+            const unit = panel.unit ? ' ' + panel.unit : '';
+            if (panel.visualization === 'stat') {
+                const points = result.series && result.series.length ? result.series[0].points : [];
+                el.querySelector('.panel-stat').textContent = points.length
+                    ? points[points.length - 1].value.toFixed(2) + unit : '-';
+                return;
+            }
+            if (typeof Chart === 'undefined') return;
+            const colors = ['#1890ff', '#52c41a', '#faad14', '#ff4d4f', '#722ed1'];
+            const datasets = (result.series || []).map((series, i) => ({
+                label: (series.group || panel.query.metric) + unit,
+                data: series.points.map(point => ({ x: new Date(point.timestamp), y: point.value })),
+                borderColor: colors[i % colors.length],
+                backgroundColor: colors[i % colors.length],
+                tension: 0.3
+            }));
+            if (customCharts[panel.id]) {
+                customCharts[panel.id].data.datasets = datasets;
+                customCharts[panel.id].$annotations = result.annotations;
+                customCharts[panel.id].update('none');
+                return;
+            }
+            customCharts[panel.id] = new Chart(el.querySelector('canvas'), {
+                type: panel.visualization,
+                data: { datasets: datasets },
+                options: {
+                    responsive: true,
+                    maintainAspectRatio: false,
+                    scales: { x: { type: 'time' } }
+                },
+                plugins: [annotationMarkers]
+            });
+            customCharts[panel.id].$annotations = result.annotations;
+            customCharts[panel.id].update('none');
+        }
+
         // Start periodic data refresh
-        function startDataRefresh() {
+        async function startDataRefresh() {
+            await loadLayout();
+
             // Fetch data immediately
             fetchMetrics();
+            refreshCustomPanels();
+            checkPipeline();
+            checkDataGaps();
+            loadComparison();
             
-            // Refresh every 3 seconds regardless of WebSocket status for demo purposes
+            // Refresh at the layout's interval regardless of WebSocket status
             setInterval(() => {
                 fetchMetrics();
-            }, 3000);
+                refreshCustomPanels();
+                checkPipeline();
+                checkDataGaps();
+            }, layout.refresh_interval || 3000);
+
+            // Comparisons span days, so refresh them once a minute
+            setInterval(loadComparison, 60000);
             
             // Also try WebSocket connection every 5 seconds if not connected
             setInterval(() => {
@@ -686,6 +996,7 @@ func getDashboardHTML(config WebDashboardConfig) string {
             updateGPUCards(data.gpu_metrics);
             updateCharts(data);
             updateAlerts(data.alerts);
+            updateIncidents(data.incidents);
         }
 
         // Update system metrics cards
@@ -694,46 +1005,61 @@ func getDashboardHTML(config WebDashboardConfig) string {
             
             const metrics = [
                 {
-                    title: 'Total GPUs',
+                    title: t('metric.total_gpus'),
                     value: systemStats.total_gpus || 0,
                     change: null,
                     icon: 'bi-gpu-card',
                     color: 'var(--accent-blue)'
                 },
                 {
-                    title: 'Active GPUs',
+                    title: t('metric.active_gpus'),
                     value: systemStats.active_gpus || 0,
                     change: null,
                     icon: 'bi-power',
                     color: 'var(--accent-green)'
                 },
                 {
-                    title: 'Avg Utilization',
+                    title: t('metric.avg_utilization'),
                     value: (systemStats.average_utilization || 0).toFixed(1) + '%',
                     change: '+2.3%',
                     icon: 'bi-speedometer2',
                     color: 'var(--accent-purple)'
                 },
                 {
-                    title: 'Efficiency Score',
+                    title: t('metric.efficiency_score'),
                     value: (systemStats.efficiency_score || 0).toFixed(0) + '/100',
                     change: '+5.7%',
                     icon: 'bi-star-fill',
                     color: 'var(--accent-yellow)'
                 },
                 {
-                    title: 'Total Power',
+                    title: t('metric.total_power'),
                     value: (systemStats.total_power_watts || 0).toFixed(0) + 'W',
                     change: '-1.2%',
                     icon: 'bi-lightning-fill',
                     color: 'var(--accent-red)'
                 },
                 {
-                    title: 'Memory Usage',
+                    title: t('metric.memory_usage'),
                     value: ((systemStats.used_memory_gb || 0) / (systemStats.total_memory_gb || 1) * 100).toFixed(1) + '%',
                     change: '+0.8%',
                     icon: 'bi-memory',
                     color: 'var(--accent-blue)'
+                },
+                {
+                    title: t('metric.utilization_percentiles'),
+                    value: ((systemStats.utilization_distribution || {}).p50 || 0).toFixed(0) + '% / ' +
+                        ((systemStats.utilization_distribution || {}).p90 || 0).toFixed(0) + '%',
+                    change: null,
+                    icon: 'bi-bar-chart-line',
+                    color: 'var(--accent-purple)'
+                },
+                {
+                    title: t('metric.load_imbalance'),
+                    value: ((systemStats.utilization_distribution || {}).gini || 0).toFixed(2),
+                    change: null,
+                    icon: 'bi-distribute-vertical',
+                    color: 'var(--accent-yellow)'
                 }
             ];
 
@@ -768,7 +1094,7 @@ func getDashboardHTML(config WebDashboardConfig) string {
             const gpuContainer = document.getElementById('gpu-grid');
             
             if (!gpuMetrics || Object.keys(gpuMetrics).length === 0) {
-                gpuContainer.innerHTML = '<div class="col-12 text-center text-muted">No GPU data available</div>';
+                gpuContainer.innerHTML = '<div class="col-12 text-center text-muted">' + t('gpu.none') + '</div>';
                 return;
             }
 
@@ -822,6 +1148,9 @@ func getDashboardHTML(config WebDashboardConfig) string {
             if (utilBar) utilBar.style.width = utilization + '%';
             if (memBar) memBar.style.width = memoryPercent + '%';
             
+            const mpsElement = cardElement.querySelector('.gpu-mps');
+            if (mpsElement) mpsElement.innerHTML = mpsAnnotation(metrics);
+
             // Update status badge
             const status = getGPUStatus(temperature, utilization);
             const statusBadge = cardElement.querySelector('.status-badge');
@@ -831,6 +1160,15 @@ func getDashboardHTML(config WebDashboardConfig) string {
             }
         }
 
+        // MPS-shared GPUs merge client utilization into the MPS server, so
+        // per-process figures are annotated rather than trusted
+        function mpsAnnotation(metrics) {
+            if (!metrics.mps_shared) return '';
+            const clients = (metrics.mps_clients || []).length;
+            return '<span class="mps-badge" title="' + t('gpu.mps_shared') + '">MPS · ' +
+                t(clients === 1 ? 'gpu.mps_client' : 'gpu.mps_clients', { count: clients }) + '</span>';
+        }
+
         // Create GPU card HTML
         function createGPUCard(gpuId, metrics) {
             const utilization = metrics.utilization_gpu || 0;
@@ -846,13 +1184,14 @@ func getDashboardHTML(config WebDashboardConfig) string {
                         '<div>' +
                             '<div class="gpu-name">' + (metrics.name || gpuId) + '</div>' +
                             '<small class="text-muted">' + gpuId + '</small>' +
+                            '<span class="gpu-mps">' + mpsAnnotation(metrics) + '</span>' +
                         '</div>' +
                         '<span class="status-badge status-' + status.class + '">' + status.text + '</span>' +
                     '</div>' +
                     
                     '<div class="progress-group">' +
                         '<div class="progress-label">' +
-                            '<span>Utilization</span>' +
+                            '<span>' + t('gpu.utilization') + '</span>' +
                             '<span class="gpu-util">' + utilization.toFixed(1) + '%</span>' +
                         '</div>' +
                         '<div class="progress">' +
@@ -862,7 +1201,7 @@ func getDashboardHTML(config WebDashboardConfig) string {
                     
                     '<div class="progress-group">' +
                         '<div class="progress-label">' +
-                            '<span>Memory</span>' +
+                            '<span>' + t('gpu.memory') + '</span>' +
                             '<span class="gpu-memory">' + (memoryUsed / 1024).toFixed(1) + 'GB / ' + (memoryTotal / 1024).toFixed(1) + 'GB</span>' +
                         '</div>' +
                         '<div class="progress">' +
@@ -872,7 +1211,7 @@ func getDashboardHTML(config WebDashboardConfig) string {
                     
                     '<div class="progress-group">' +
                         '<div class="progress-label">' +
-                            '<span>Temperature</span>' +
+                            '<span>' + t('gpu.temperature') + '</span>' +
                             '<span class="gpu-temp">' + temperature.toFixed(1) + '°C</span>' +
                         '</div>' +
                         '<div class="progress">' +
@@ -884,8 +1223,9 @@ func getDashboardHTML(config WebDashboardConfig) string {
 
         // Get GPU status based on metrics
         function getGPUStatus(temperature, utilization) {
-            if (temperature > 85 || utilization > 95) return 'critical';
-            if (temperature > 75 || utilization > 80) return 'warning';
+            const thresholds = layout.thresholds;
+            if (temperature > thresholds.temperature_critical || utilization > thresholds.utilization_critical) return 'critical';
+            if (temperature > thresholds.temperature_warning || utilization > thresholds.utilization_warning) return 'warning';
             if (utilization > 5) return 'active';
             return 'idle';
         }
@@ -937,14 +1277,14 @@ func getDashboardHTML(config WebDashboardConfig) string {
                 data: {
                     labels: initialLabels,
                     datasets: [{
-                        label: 'GPU Utilization %',
+                        label: t('chart.gpu_utilization'),
                         data: initialUtilData,
                         borderColor: '#1890ff',
                         backgroundColor: 'rgba(24, 144, 255, 0.1)',
                         tension: 0.4,
                         fill: true
                     }, {
-                        label: 'Temperature °C',
+                        label: t('chart.temperature'),
                         data: initialTempData,
                         borderColor: '#ff4d4f',
                         backgroundColor: 'rgba(255, 77, 79, 0.1)',
@@ -995,7 +1335,7 @@ func getDashboardHTML(config WebDashboardConfig) string {
                             },
                             title: { 
                                 display: true, 
-                                text: 'Utilization %', 
+                                text: t('chart.utilization'), 
                                 color: '#c5ccd6',
                                 font: { size: 12, weight: '500' }
                             }
@@ -1011,7 +1351,7 @@ func getDashboardHTML(config WebDashboardConfig) string {
                             },
                             title: { 
                                 display: true, 
-                                text: 'Temperature °C', 
+                                text: t('chart.temperature'), 
                                 color: '#c5ccd6',
                                 font: { size: 12, weight: '500' }
                             }
@@ -1025,7 +1365,7 @@ func getDashboardHTML(config WebDashboardConfig) string {
             costChart = new Chart(costCtx, {
                 type: 'doughnut',
                 data: {
-                    labels: ['GPU Hours', 'Storage', 'Network', 'Other'],
+                    labels: [t('cost.gpu_hours'), t('cost.storage'), t('cost.network'), t('cost.other')],
                     datasets: [{
                         data: [65, 20, 10, 5],
                         backgroundColor: [
@@ -1107,7 +1447,7 @@ func getDashboardHTML(config WebDashboardConfig) string {
                 alertsList.innerHTML = ` + "`" + `
                     <div class="text-muted text-center py-3">
                         <i class="bi bi-check-circle-fill me-2"></i>
-                        No active alerts
+                        ${t('alerts.none')}
                     </div>
                 ` + "`" + `;
                 alertCount.textContent = '0';
@@ -1119,6 +1459,31 @@ func getDashboardHTML(config WebDashboardConfig) string {
             }
         }
 
+        // Update grouped incidents; each incident collapses related alerts
+        function updateIncidents(incidents) {
+            const incidentsList = document.getElementById('incidents-list');
+            metricsData.incidents = incidents || [];
+            incidentsList.innerHTML = metricsData.incidents.map(incident => ` + "`" + `
+                <div class="alert-item alert-${incident.severity} fade-in">
+                    <div class="alert-icon">
+                        <i class="bi ${getAlertIcon(incident.severity)}"></i>
+                    </div>
+                    <div class="alert-content">
+                        <div class="alert-title">${incident.title}</div>
+                        <div class="alert-message">${t('alerts.grouped', { count: incident.alert_count, id: incident.id })}</div>
+                    </div>
+                    <div class="alert-time">${getTimeAgo(new Date(incident.started_at))}</div>
+                </div>
+            ` + "`" + `).join('');
+        }
+
+        // Apply an incident change pushed over the WebSocket
+        function updateIncident(incident) {
+            const incidents = (metricsData.incidents || []).filter(i => i.id !== incident.id);
+            if (incident.status === 'open') incidents.unshift(incident);
+            updateIncidents(incidents);
+        }
+
         // Create alert item HTML
         function createAlertItem(alert) {
             const timeAgo = getTimeAgo(new Date(alert.timestamp));
@@ -1130,7 +1495,7 @@ func getDashboardHTML(config WebDashboardConfig) string {
                     </div>
                     <div class="alert-content">
                         <div class="alert-title">${alert.message}</div>
-                        <div class="alert-message">Source: ${alert.source}</div>
+                        <div class="alert-message">${t('alerts.source', { source: alert.source })}</div>
                     </div>
                     <div class="alert-time">${timeAgo}</div>
                 </div>
@@ -1151,28 +1516,28 @@ func getDashboardHTML(config WebDashboardConfig) string {
         function getTimeAgo(date) {
             const seconds = Math.floor((new Date() - date) / 1000);
             
-            if (seconds < 60) return seconds + 's ago';
+            if (seconds < 60) return t('time.seconds_ago', { n: seconds });
             
             const minutes = Math.floor(seconds / 60);
-            if (minutes < 60) return minutes + 'm ago';
+            if (minutes < 60) return t('time.minutes_ago', { n: minutes });
             
             const hours = Math.floor(minutes / 60);
-            if (hours < 24) return hours + 'h ago';
+            if (hours < 24) return t('time.hours_ago', { n: hours });
             
             const days = Math.floor(hours / 24);
-            return days + 'd ago';
+            return t('time.days_ago', { n: days });
         }
 
         // Add new alert
-        function addAlert(alert) {
+        function addAlert(alert, notify) {
             // Add to current alerts and refresh display
             if (!metricsData.alerts) metricsData.alerts = [];
             metricsData.alerts.unshift(alert);
             updateAlerts(metricsData.alerts);
             
-            // Show browser notification if supported
-            if (Notification.permission === 'granted') {
-                new Notification('AgentaFlow Alert', {
+            // Show browser notification if supported and wanted
+            if (notify && Notification.permission === 'granted') {
+                new Notification(t('alerts.notification_title'), {
                     body: alert.message,
                     icon: '/favicon.ico'
                 });
@@ -1188,7 +1553,7 @@ func getDashboardHTML(config WebDashboardConfig) string {
             toast.innerHTML = ` + "`" + `
                 <div class="toast-header">
                     <strong class="me-auto">${notification.title}</strong>
-                    <small class="text-muted">now</small>
+                    <small class="text-muted">${t('time.now')}</small>
                     <button type="button" class="btn-close" data-bs-dismiss="toast"></button>
                 </div>
                 <div class="toast-body">${notification.message}</div>
@@ -1202,6 +1567,48 @@ func getDashboardHTML(config WebDashboardConfig) string {
             }, 5000);
         }
 
+        // Compare the last ?hours against the period of the same length before
+        let comparisonHours = 168;
+        async function loadComparison() {
+            const el = document.getElementById('comparison-table');
+            const end = new Date();
+            const start = new Date(end.getTime() - comparisonHours * 3600 * 1000);
+            try {
+                const response = await fetch('/api/v1/performance/compare?current_start=' +
+                    encodeURIComponent(start.toISOString()) + '&current_end=' + encodeURIComponent(end.toISOString()));
+                if (!response.ok) {
+                    el.textContent = t('compare.unavailable', { status: response.status });
+                    return;
+                }
+                const comparison = await response.json();
+                const rows = comparison.changes.map(change => {
+                    const percent = change.percent === null ? t('compare.new') : (change.percent >= 0 ? '+' : '') + change.percent.toFixed(1) + '%';
+                    return '<tr class="' + (change.significant ? 'table-warning' : '') + '">' +
+                        '<td>' + change.metric + '</td>' +
+                        '<td class="text-end">' + change.baseline.toFixed(2) + '</td>' +
+                        '<td class="text-end">' + change.current.toFixed(2) + '</td>' +
+                        '<td class="text-end">' + (change.delta >= 0 ? '+' : '') + change.delta.toFixed(2) + '</td>' +
+                        '<td class="text-end">' + percent + '</td></tr>';
+                });
+                el.className = '';
+                el.innerHTML = '<table class="table table-sm mb-0"><thead><tr><th>' + t('compare.metric') + '</th>' +
+                    '<th class="text-end">' + t('compare.previous') + '</th><th class="text-end">' + t('compare.current') + '</th>' +
+                    '<th class="text-end">' + t('compare.change') + '</th><th class="text-end">%</th></tr></thead><tbody>' +
+                    rows.join('') + '</tbody></table>';
+            } catch (error) {
+                console.error('Error comparing periods:', error);
+            }
+        }
+
+        document.querySelectorAll('[data-compare]').forEach(button => {
+            button.addEventListener('click', function() {
+                document.querySelectorAll('[data-compare]').forEach(b => b.classList.remove('active'));
+                this.classList.add('active');
+                comparisonHours = parseInt(this.dataset.compare, 10);
+                loadComparison();
+            });
+        });
+
         // Clear all alerts
         function clearAllAlerts() {
             if (metricsData.alerts) {
@@ -1247,10 +1654,26 @@ func getDashboardHTML(config WebDashboardConfig) string {
 </body>
 </html>`
 
+	messages, exists := DashboardMessages(locale)
+	if !exists {
+		locale = DefaultDashboardLocale
+		messages, _ = DashboardMessages(locale)
+	}
+	messagesJSON, _ := json.Marshal(messages)
+	locales := make([]map[string]string, 0)
+	for _, supported := range DashboardLocales() {
+		translated, _ := DashboardMessages(supported)
+		locales = append(locales, map[string]string{"locale": supported, "name": translated["language.name"]})
+	}
+	localesJSON, _ := json.Marshal(locales)
+
 	// Replace template variables
 	html := strings.ReplaceAll(tmpl, "{{.Title}}", config.Title)
 	html = strings.ReplaceAll(html, "{{.RefreshInterval}}", fmt.Sprintf("%d", config.RefreshInterval))
 	html = strings.ReplaceAll(html, "{{.Theme}}", config.Theme)
+	html = strings.ReplaceAll(html, "{{.Lang}}", locale)
+	html = strings.ReplaceAll(html, "{{.Messages}}", string(messagesJSON))
+	html = strings.ReplaceAll(html, "{{.Locales}}", string(localesJSON))
 
 	return html
 }
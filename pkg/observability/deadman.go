@@ -0,0 +1,217 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DeadMansSwitchConfig configures the heartbeat AgentaFlow sends so that an
+// external monitor alerts when it stops arriving
+type DeadMansSwitchConfig struct {
+	Interval time.Duration `yaml:"interval" json:"interval"`
+	Timeout  time.Duration `yaml:"timeout" json:"timeout"` // Per-ping timeout
+
+	// PingURL is requested on every healthy heartbeat, e.g. a healthchecks.io
+	// check URL or an Opsgenie heartbeat ping endpoint. Only the heartbeat
+	// metric is updated when empty.
+	PingURL string `yaml:"ping_url" json:"ping_url" secret:"true"`
+	// FailURL is requested instead while the health check fails, e.g. the
+	// check URL with /fail appended. Without it, unhealthy heartbeats are not sent.
+	FailURL string            `yaml:"fail_url" json:"fail_url" secret:"true"`
+	Method  string            `yaml:"method" json:"method"`   // GET when empty
+	Headers map[string]string `yaml:"headers" json:"headers"` // e.g. Authorization: GenieKey <key>
+}
+
+// DefaultDeadMansSwitchConfig returns the default heartbeat configuration
+func DefaultDeadMansSwitchConfig() DeadMansSwitchConfig {
+	return DeadMansSwitchConfig{
+		Interval: time.Minute,
+		Timeout:  10 * time.Second,
+		Method:   http.MethodGet,
+	}
+}
+
+// DeadMansSwitch periodically sets the heartbeat metric and pings an external
+// monitor, which raises an alert once the pings stop
+type DeadMansSwitch struct {
+	config   DeadMansSwitchConfig
+	exporter *PrometheusExporter
+	check    HealthCheck
+	client   *http.Client
+
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	beats    int
+	pings    int
+	failures int
+	lastBeat time.Time
+	lastErr  error
+	mu       sync.RWMutex
+}
+
+// NewDeadMansSwitch creates a heartbeat reporting to the exporter's
+// heartbeat metric, the configured ping URL, or both
+func NewDeadMansSwitch(config DeadMansSwitchConfig, exporter *PrometheusExporter) (*DeadMansSwitch, error) {
+	if exporter == nil && config.PingURL == "" {
+		return nil, fmt.Errorf("dead man's switch needs a Prometheus exporter or a ping URL")
+	}
+	defaults := DefaultDeadMansSwitchConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.Method == "" {
+		config.Method = defaults.Method
+	}
+
+	return &DeadMansSwitch{
+		config:   config,
+		exporter: exporter,
+		client:   &http.Client{},
+	}, nil
+}
+
+// SetHealthCheck makes heartbeats report failure while check fails, e.g. a
+// check that fails when the dashboard reports failing components
+func (d *DeadMansSwitch) SetHealthCheck(check HealthCheck) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.check = check
+}
+
+// Beat sets the heartbeat metric and pings the monitor once
+func (d *DeadMansSwitch) Beat(ctx context.Context) error {
+	now := time.Now()
+	d.mu.Lock()
+	check := d.check
+	d.beats++
+	d.lastBeat = now
+	d.mu.Unlock()
+
+	var err error
+	if d.exporter != nil {
+		err = d.exporter.SetGauge("heartbeat", float64(now.Unix()), nil)
+	}
+
+	url := d.config.PingURL
+	if check != nil {
+		if checkErr := check(); checkErr != nil {
+			log.Printf("Heartbeat health check failed: %v", checkErr)
+			url = d.config.FailURL
+		}
+	}
+	if url != "" {
+		if pingErr := d.ping(ctx, url); pingErr != nil {
+			err = pingErr
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err != nil {
+		d.failures++
+		d.lastErr = err
+	} else if url != "" {
+		d.pings++
+	}
+	return err
+}
+
+// ping requests a monitor URL
+func (d *DeadMansSwitch) ping(ctx context.Context, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, d.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, d.config.Method, url, nil)
+	if err != nil {
+		return fmt.Errorf("heartbeat ping failed: %w", err)
+	}
+	for name, value := range d.config.Headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("heartbeat ping failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("heartbeat ping returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Start sends a heartbeat immediately and then every Interval
+func (d *DeadMansSwitch) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopCh != nil {
+		return
+	}
+	d.stopCh = make(chan struct{})
+	d.doneCh = make(chan struct{})
+	go d.run(d.stopCh, d.doneCh)
+}
+
+// Stop halts heartbeats, so the external monitor alerts after its grace period
+func (d *DeadMansSwitch) Stop() {
+	d.mu.Lock()
+	stopCh, doneCh := d.stopCh, d.doneCh
+	d.stopCh, d.doneCh = nil, nil
+	d.mu.Unlock()
+
+	if stopCh == nil {
+		return
+	}
+	close(stopCh)
+	<-doneCh
+}
+
+// run beats until stopped
+func (d *DeadMansSwitch) run(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(d.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.Beat(context.Background()); err != nil {
+			log.Printf("Heartbeat failed: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// GetStats returns heartbeat statistics
+func (d *DeadMansSwitch) GetStats() map[string]interface{} {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	stats := map[string]interface{}{
+		"running":        d.stopCh != nil,
+		"interval":       d.config.Interval.String(),
+		"beats":          d.beats,
+		"pings_sent":     d.pings,
+		"beats_failed":   d.failures,
+		"ping_url_set":   d.config.PingURL != "",
+		"fail_url_set":   d.config.FailURL != "",
+		"last_heartbeat": d.lastBeat,
+	}
+	if d.lastErr != nil {
+		stats["last_error"] = d.lastErr.Error()
+	}
+	return stats
+}
@@ -0,0 +1,97 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDeadMansSwitchBeat(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	exporter := NewPrometheusExporter(NewMonitoringService(100), DefaultPrometheusConfig())
+	exporter.RegisterSystemMetrics()
+	heartbeat, err := NewDeadMansSwitch(DeadMansSwitchConfig{
+		PingURL: server.URL + "/ping/abc",
+		FailURL: server.URL + "/ping/abc/fail",
+		Headers: map[string]string{"Authorization": "GenieKey k"},
+	}, exporter)
+	if err != nil {
+		t.Fatalf("NewDeadMansSwitch failed: %v", err)
+	}
+
+	if err := heartbeat.Beat(context.Background()); err != nil {
+		t.Fatalf("Beat failed: %v", err)
+	}
+	beat := heartbeat.GetStats()["last_heartbeat"].(time.Time)
+	if exported := exporter.ExportMetrics(); !strings.Contains(exported, fmt.Sprintf("agentaflow_heartbeat %d\n", beat.Unix())) {
+		t.Errorf("Expected the heartbeat metric set to the beat time, got:\n%s", exported)
+	}
+
+	healthy := false
+	heartbeat.SetHealthCheck(func() error {
+		if !healthy {
+			return fmt.Errorf("collector stalled")
+		}
+		return nil
+	})
+	heartbeat.Beat(context.Background())
+
+	mu.Lock()
+	expected := []string{"GET /ping/abc GenieKey k", "GET /ping/abc/fail GenieKey k"}
+	if strings.Join(requests, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, requests)
+	}
+	mu.Unlock()
+	if stats := heartbeat.GetStats(); stats["beats"] != 2 || stats["pings_sent"] != 2 {
+		t.Errorf("Unexpected stats: %v", stats)
+	}
+}
+
+func TestDeadMansSwitchSkipsUnhealthyPingsWithoutFailURL(t *testing.T) {
+	pings := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pings++
+	}))
+	defer server.Close()
+
+	heartbeat, _ := NewDeadMansSwitch(DeadMansSwitchConfig{PingURL: server.URL}, nil)
+	heartbeat.SetHealthCheck(func() error { return fmt.Errorf("down") })
+	if err := heartbeat.Beat(context.Background()); err != nil {
+		t.Fatalf("Beat failed: %v", err)
+	}
+	if pings != 0 {
+		t.Errorf("Expected no ping while unhealthy, got %d", pings)
+	}
+
+	if _, err := NewDeadMansSwitch(DeadMansSwitchConfig{}, nil); err == nil {
+		t.Error("Expected an error without an exporter or ping URL")
+	}
+}
+
+func TestDeadMansSwitchReportsPingErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	heartbeat, _ := NewDeadMansSwitch(DeadMansSwitchConfig{PingURL: server.URL}, nil)
+	if err := heartbeat.Beat(context.Background()); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a 404 error, got %v", err)
+	}
+	if stats := heartbeat.GetStats(); stats["beats_failed"] != 1 || stats["last_error"] == nil {
+		t.Errorf("Expected the failure recorded, got %v", stats)
+	}
+}
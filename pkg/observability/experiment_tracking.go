@@ -0,0 +1,367 @@
+package observability
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// Workload labels naming the experiment run to report to
+const (
+	MLflowRunLabel = "agentaflow.io/mlflow-run-id"
+	WandbRunLabel  = "agentaflow.io/wandb-run" // "<entity>/<project>/<run id>", or the run ID with WandbConfig defaults
+)
+
+// MLflowConfig is an MLflow tracking server
+type MLflowConfig struct {
+	TrackingURI string `yaml:"tracking_uri" json:"tracking_uri"` // e.g. http://mlflow:5000
+	Token       string `yaml:"token" json:"token" secret:"true"` // Sent as a bearer token when set
+}
+
+// WandbConfig is a Weights & Biases server
+type WandbConfig struct {
+	BaseURL string `yaml:"base_url" json:"base_url"`
+	APIKey  string `yaml:"api_key" json:"api_key" secret:"true"`
+	Entity  string `yaml:"entity" json:"entity"`   // Used when run labels hold only a run ID
+	Project string `yaml:"project" json:"project"` // Used when run labels hold only a run ID
+}
+
+// ExperimentTrackingConfig configures pushing workload runtime, utilization
+// and cost into experiment tracking runs
+type ExperimentTrackingConfig struct {
+	MLflow  MLflowConfig         `yaml:"mlflow" json:"mlflow"`
+	Wandb   WandbConfig          `yaml:"wandb" json:"wandb"`
+	Pricing GPUCostConfiguration `yaml:"pricing" json:"pricing"`
+	Timeout time.Duration        `yaml:"timeout" json:"timeout"`
+}
+
+// DefaultExperimentTrackingConfig returns settings with no tracking server configured
+func DefaultExperimentTrackingConfig() ExperimentTrackingConfig {
+	return ExperimentTrackingConfig{
+		Wandb:   WandbConfig{BaseURL: "https://api.wandb.ai"},
+		Pricing: DefaultGPUCostConfiguration(),
+		Timeout: 10 * time.Second,
+	}
+}
+
+// RunMetrics is what a finished workload reports to its experiment run
+type RunMetrics struct {
+	WorkloadID     string  `json:"workload_id"`
+	Status         string  `json:"status"`
+	GPUID          string  `json:"gpu_id,omitempty"`
+	GPUName        string  `json:"gpu_name,omitempty"`
+	Node           string  `json:"node,omitempty"`
+	RuntimeSeconds float64 `json:"runtime_seconds"`
+	GPUHours       float64 `json:"gpu_hours"`
+	AvgUtilization float64 `json:"avg_utilization,omitempty"`
+	PeakMemory     uint64  `json:"peak_memory_mb,omitempty"`
+	Cost           float64 `json:"cost"`
+	Currency       string  `json:"currency"`
+	Preemptions    int     `json:"preemptions,omitempty"`
+}
+
+// metrics returns the numeric values under their run metric keys
+func (m RunMetrics) metrics() map[string]float64 {
+	values := map[string]float64{
+		"agentaflow/runtime_seconds": m.RuntimeSeconds,
+		"agentaflow/gpu_hours":       m.GPUHours,
+		"agentaflow/cost":            m.Cost,
+		"agentaflow/preemptions":     float64(m.Preemptions),
+	}
+	if m.AvgUtilization > 0 {
+		values["agentaflow/avg_gpu_utilization"] = m.AvgUtilization
+	}
+	if m.PeakMemory > 0 {
+		values["agentaflow/peak_gpu_memory_mb"] = float64(m.PeakMemory)
+	}
+	return values
+}
+
+// tags returns the descriptive values under their run tag keys
+func (m RunMetrics) tags() map[string]string {
+	return map[string]string{
+		"agentaflow.workload_id": m.WorkloadID,
+		"agentaflow.status":      m.Status,
+		"agentaflow.gpu":         m.GPUName,
+		"agentaflow.node":        m.Node,
+		"agentaflow.currency":    m.Currency,
+	}
+}
+
+// ExperimentTracker reports finished workloads to the MLflow or Weights &
+// Biases runs named in their labels, so ML engineers see infrastructure
+// efficiency next to their training curves
+type ExperimentTracker struct {
+	config    ExperimentTrackingConfig
+	client    *http.Client
+	reported  int
+	failed    int
+	lastError string
+	mu        sync.Mutex
+}
+
+// NewExperimentTracker creates a tracker; zero settings use the defaults
+func NewExperimentTracker(config ExperimentTrackingConfig) (*ExperimentTracker, error) {
+	defaults := DefaultExperimentTrackingConfig()
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.Wandb.BaseURL == "" {
+		config.Wandb.BaseURL = defaults.Wandb.BaseURL
+	}
+	if config.Pricing.CostPerHour == nil {
+		config.Pricing = defaults.Pricing
+	}
+	if config.MLflow.TrackingURI == "" && config.Wandb.APIKey == "" {
+		return nil, fmt.Errorf("experiment tracking needs an MLflow tracking URI or a W&B API key")
+	}
+	config.MLflow.TrackingURI = strings.TrimRight(config.MLflow.TrackingURI, "/")
+	config.Wandb.BaseURL = strings.TrimRight(config.Wandb.BaseURL, "/")
+
+	return &ExperimentTracker{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// Handle reports completed and failed workloads labelled with a run; register
+// it with Scheduler.OnLifecycleEvent
+func (et *ExperimentTracker) Handle(event gpu.LifecycleEvent) {
+	if event.Type != gpu.LifecycleCompleted && event.Type != gpu.LifecycleFailed {
+		return
+	}
+	mlflowRun, wandbRun := event.Labels[MLflowRunLabel], event.Labels[WandbRunLabel]
+	if mlflowRun == "" && wandbRun == "" {
+		return
+	}
+
+	metrics := et.RunMetrics(event)
+	if mlflowRun != "" {
+		et.record(et.logMLflow(mlflowRun, metrics, event.Timestamp))
+	}
+	if wandbRun != "" {
+		et.record(et.logWandb(wandbRun, metrics))
+	}
+}
+
+// RunMetrics summarizes a finished workload's runtime, utilization and cost
+func (et *ExperimentTracker) RunMetrics(event gpu.LifecycleEvent) RunMetrics {
+	metrics := RunMetrics{
+		WorkloadID:  event.WorkloadID,
+		Status:      string(event.Status),
+		GPUID:       event.GPUID,
+		GPUName:     event.GPUName,
+		Node:        event.Node,
+		Currency:    et.config.Pricing.Currency,
+		Preemptions: event.Preemptions,
+	}
+	if event.Usage != nil {
+		metrics.AvgUtilization = event.Usage.AvgUtilization
+		metrics.PeakMemory = event.Usage.PeakMemory
+	}
+	if event.StartedAt != nil && event.Timestamp.After(*event.StartedAt) {
+		record := gpu.UsageRecord{
+			GPUName: event.GPUName,
+			Shared:  event.Colocated,
+			Start:   *event.StartedAt,
+			End:     event.Timestamp,
+		}
+		metrics.RuntimeSeconds = event.Timestamp.Sub(*event.StartedAt).Seconds()
+		metrics.GPUHours = record.Hours(record.Start, record.End)
+		metrics.Cost = et.config.Pricing.usageCost(record, metrics.GPUHours)
+	}
+	return metrics
+}
+
+// logMLflow logs metrics and tags to an MLflow run with runs/log-batch
+func (et *ExperimentTracker) logMLflow(runID string, metrics RunMetrics, at time.Time) error {
+	if et.config.MLflow.TrackingURI == "" {
+		return fmt.Errorf("workload %s names MLflow run %s but no tracking URI is configured", metrics.WorkloadID, runID)
+	}
+
+	type keyValue struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	type metric struct {
+		Key       string  `json:"key"`
+		Value     float64 `json:"value"`
+		Timestamp int64   `json:"timestamp"`
+		Step      int64   `json:"step"`
+	}
+	batch := struct {
+		RunID   string     `json:"run_id"`
+		Metrics []metric   `json:"metrics"`
+		Tags    []keyValue `json:"tags"`
+	}{RunID: runID}
+	timestamp := at.UnixNano() / int64(time.Millisecond)
+	for key, value := range metrics.metrics() {
+		batch.Metrics = append(batch.Metrics, metric{Key: key, Value: value, Timestamp: timestamp})
+	}
+	for key, value := range metrics.tags() {
+		if value != "" {
+			batch.Tags = append(batch.Tags, keyValue{Key: key, Value: value})
+		}
+	}
+	sort.Slice(batch.Metrics, func(i, j int) bool { return batch.Metrics[i].Key < batch.Metrics[j].Key })
+	sort.Slice(batch.Tags, func(i, j int) bool { return batch.Tags[i].Key < batch.Tags[j].Key })
+
+	headers := map[string]string{}
+	if et.config.MLflow.Token != "" {
+		headers["Authorization"] = "Bearer " + et.config.MLflow.Token
+	}
+	_, err := et.post(et.config.MLflow.TrackingURI+"/api/2.0/mlflow/runs/log-batch", headers, batch)
+	if err != nil {
+		return fmt.Errorf("MLflow run %s: %v", runID, err)
+	}
+	return nil
+}
+
+// logWandb merges metrics into a W&B run's summary through the GraphQL API
+func (et *ExperimentTracker) logWandb(run string, metrics RunMetrics) error {
+	wandb := et.config.Wandb
+	if wandb.APIKey == "" {
+		return fmt.Errorf("workload %s names W&B run %s but no API key is configured", metrics.WorkloadID, run)
+	}
+	entity, project, name := wandb.Entity, wandb.Project, run
+	if parts := strings.Split(run, "/"); len(parts) == 3 {
+		entity, project, name = parts[0], parts[1], parts[2]
+	}
+	if entity == "" || project == "" {
+		return fmt.Errorf("W&B run %s needs an entity and project", run)
+	}
+
+	headers := map[string]string{"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte("api:"+wandb.APIKey))}
+	endpoint := wandb.BaseURL + "/graphql"
+
+	// upsertBucket replaces the summary, so merge into what the run already logged
+	var found struct {
+		Data struct {
+			Project *struct {
+				Run *struct {
+					ID             string `json:"id"`
+					SummaryMetrics string `json:"summaryMetrics"`
+				} `json:"run"`
+			} `json:"project"`
+		} `json:"data"`
+	}
+	response, err := et.post(endpoint, headers, map[string]interface{}{
+		"query":     `query Run($entity: String!, $project: String!, $name: String!) { project(name: $project, entityName: $entity) { run(name: $name) { id summaryMetrics } } }`,
+		"variables": map[string]string{"entity": entity, "project": project, "name": name},
+	})
+	if err == nil {
+		err = json.Unmarshal(response, &found)
+	}
+	if err != nil {
+		return fmt.Errorf("W&B run %s: %v", run, err)
+	}
+	if found.Data.Project == nil || found.Data.Project.Run == nil {
+		return fmt.Errorf("W&B run %s not found", run)
+	}
+
+	summary := make(map[string]interface{})
+	if found.Data.Project.Run.SummaryMetrics != "" {
+		if err := json.Unmarshal([]byte(found.Data.Project.Run.SummaryMetrics), &summary); err != nil {
+			return fmt.Errorf("W&B run %s has an invalid summary: %v", run, err)
+		}
+	}
+	for key, value := range metrics.metrics() {
+		summary[key] = value
+	}
+	for key, value := range metrics.tags() {
+		if value != "" {
+			summary[key] = value
+		}
+	}
+	encoded, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("W&B run %s: %v", run, err)
+	}
+
+	_, err = et.post(endpoint, headers, map[string]interface{}{
+		"query":     `mutation UpsertBucket($id: String!, $summaryMetrics: JSONString) { upsertBucket(input: {id: $id, summaryMetrics: $summaryMetrics}) { bucket { id } } }`,
+		"variables": map[string]string{"id": found.Data.Project.Run.ID, "summaryMetrics": string(encoded)},
+	})
+	if err != nil {
+		return fmt.Errorf("W&B run %s: %v", run, err)
+	}
+	return nil
+}
+
+// post sends a JSON request and returns the response body
+func (et *ExperimentTracker) post(url string, headers map[string]string, body interface{}) ([]byte, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := et.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message := string(data)
+		if len(message) > 512 {
+			message = message[:512]
+		}
+		return nil, fmt.Errorf("returned %d: %s", resp.StatusCode, strings.TrimSpace(message))
+	}
+	// GraphQL reports failures in a 200 response
+	var graphQL struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(data, &graphQL) == nil && len(graphQL.Errors) > 0 {
+		return nil, fmt.Errorf("%s", graphQL.Errors[0].Message)
+	}
+	return data, nil
+}
+
+// record counts a report outcome
+func (et *ExperimentTracker) record(err error) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	if err != nil {
+		et.failed++
+		et.lastError = err.Error()
+		return
+	}
+	et.reported++
+}
+
+// GetStats returns report counts and the last failure
+func (et *ExperimentTracker) GetStats() map[string]interface{} {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	return map[string]interface{}{
+		"mlflow":     et.config.MLflow.TrackingURI != "",
+		"wandb":      et.config.Wandb.APIKey != "",
+		"reported":   et.reported,
+		"failed":     et.failed,
+		"last_error": et.lastError,
+	}
+}
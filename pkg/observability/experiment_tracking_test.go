@@ -0,0 +1,147 @@
+package observability
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// finishedEvent is a completed two-hour run on an A100 labelled with runs
+func finishedEvent(labels map[string]string) gpu.LifecycleEvent {
+	finished := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	started := finished.Add(-2 * time.Hour)
+	return gpu.LifecycleEvent{
+		Type:       gpu.LifecycleCompleted,
+		WorkloadID: "finetune-42",
+		Status:     gpu.WorkloadCompleted,
+		Labels:     labels,
+		GPUID:      "gpu-0",
+		GPUName:    "NVIDIA A100",
+		Node:       "node-a",
+		StartedAt:  &started,
+		Usage:      &gpu.UsageSummary{AvgUtilization: 87.5, PeakMemory: 38000, Samples: 120},
+		Timestamp:  finished,
+	}
+}
+
+func TestExperimentTrackerLogsToMLflow(t *testing.T) {
+	var batch struct {
+		RunID   string `json:"run_id"`
+		Metrics []struct {
+			Key       string  `json:"key"`
+			Value     float64 `json:"value"`
+			Timestamp int64   `json:"timestamp"`
+		} `json:"metrics"`
+		Tags []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"tags"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/2.0/mlflow/runs/log-batch" || r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Header)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			t.Errorf("Failed to decode batch: %v", err)
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	tracker, err := NewExperimentTracker(ExperimentTrackingConfig{MLflow: MLflowConfig{TrackingURI: server.URL + "/", Token: "tok"}})
+	if err != nil {
+		t.Fatalf("NewExperimentTracker: %v", err)
+	}
+	tracker.Handle(finishedEvent(map[string]string{MLflowRunLabel: "run-abc"}))
+	// Workloads without a run and unfinished workloads are not reported
+	tracker.Handle(finishedEvent(nil))
+	started := finishedEvent(map[string]string{MLflowRunLabel: "run-abc"})
+	started.Type = gpu.LifecycleStarted
+	tracker.Handle(started)
+
+	if batch.RunID != "run-abc" {
+		t.Fatalf("run_id = %q", batch.RunID)
+	}
+	metrics := make(map[string]float64)
+	for _, metric := range batch.Metrics {
+		metrics[metric.Key] = metric.Value
+		if metric.Timestamp != finishedEvent(nil).Timestamp.Unix()*1000 {
+			t.Errorf("metric %s timestamp = %d", metric.Key, metric.Timestamp)
+		}
+	}
+	if metrics["agentaflow/runtime_seconds"] != 7200 || metrics["agentaflow/gpu_hours"] != 2 ||
+		metrics["agentaflow/avg_gpu_utilization"] != 87.5 || metrics["agentaflow/peak_gpu_memory_mb"] != 38000 ||
+		math.Abs(metrics["agentaflow/cost"]-2*DefaultCostA100) > 1e-9 {
+		t.Errorf("metrics = %v", metrics)
+	}
+	tags := make(map[string]string)
+	for _, tag := range batch.Tags {
+		tags[tag.Key] = tag.Value
+	}
+	if tags["agentaflow.workload_id"] != "finetune-42" || tags["agentaflow.status"] != "completed" || tags["agentaflow.currency"] != "USD" {
+		t.Errorf("tags = %v", tags)
+	}
+	if stats := tracker.GetStats(); stats["reported"] != 1 || stats["failed"] != 0 {
+		t.Errorf("stats = %v", stats)
+	}
+}
+
+func TestExperimentTrackerMergesWandbSummary(t *testing.T) {
+	var summary map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, key, _ := r.BasicAuth()
+		if r.URL.Path != "/graphql" || user != "api" || key != "wandb-key" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Header)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var request struct {
+			Query     string            `json:"query"`
+			Variables map[string]string `json:"variables"`
+		}
+		json.Unmarshal(body, &request)
+
+		switch {
+		case strings.HasPrefix(request.Query, "query Run"):
+			if request.Variables["entity"] != "ml-team" || request.Variables["project"] != "llama" || request.Variables["name"] != "3xk9" {
+				w.Write([]byte(`{"data": {"project": {"run": null}}}`))
+				return
+			}
+			w.Write([]byte(`{"data": {"project": {"run": {"id": "UnVuOjM=", "summaryMetrics": "{\"loss\": 0.42}"}}}}`))
+		case strings.HasPrefix(request.Query, "mutation UpsertBucket"):
+			if request.Variables["id"] != "UnVuOjM=" {
+				t.Errorf("upserted run %q", request.Variables["id"])
+			}
+			json.Unmarshal([]byte(request.Variables["summaryMetrics"]), &summary)
+			w.Write([]byte(`{"data": {"upsertBucket": {"bucket": {"id": "UnVuOjM="}}}}`))
+		default:
+			t.Errorf("unexpected query %s", request.Query)
+		}
+	}))
+	defer server.Close()
+
+	tracker, err := NewExperimentTracker(ExperimentTrackingConfig{Wandb: WandbConfig{BaseURL: server.URL, APIKey: "wandb-key", Entity: "ml-team", Project: "llama"}})
+	if err != nil {
+		t.Fatalf("NewExperimentTracker: %v", err)
+	}
+	tracker.Handle(finishedEvent(map[string]string{WandbRunLabel: "3xk9"}))
+
+	if summary["loss"] != 0.42 || summary["agentaflow/gpu_hours"] != 2.0 || summary["agentaflow.node"] != "node-a" {
+		t.Errorf("summary = %v, want metrics merged into the existing summary", summary)
+	}
+
+	// Unknown W&B runs and MLflow without a tracking URI are reported as failures
+	tracker.Handle(finishedEvent(map[string]string{MLflowRunLabel: "run-abc", WandbRunLabel: "ml-team/llama/missing"}))
+	if stats := tracker.GetStats(); stats["reported"] != 1 || stats["failed"] != 2 || stats["last_error"] == "" {
+		t.Errorf("stats = %v", stats)
+	}
+	if _, err := NewExperimentTracker(ExperimentTrackingConfig{}); err == nil {
+		t.Error("created a tracker without MLflow or W&B")
+	}
+}
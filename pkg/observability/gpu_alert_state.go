@@ -0,0 +1,271 @@
+package observability
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// thresholdCondition is one threshold check evaluated against a sample
+type thresholdCondition struct {
+	key       string // Identifies the condition per GPU, e.g. "temperature"
+	alertType string
+	value     float64
+	severity  string  // Severity of the breach, or "" when not breached
+	threshold float64 // Threshold that was breached
+	message   string
+	cleared   bool    // Value is back past the clear threshold
+	clearAt   float64 // Threshold the value must pass to resolve
+}
+
+// alertState tracks a threshold condition on one GPU across samples
+type alertState struct {
+	pendingSince time.Time // When the breach was first seen
+	firing       bool
+	alert        gpu.GPUAlert // Most recently fired alert
+}
+
+// severityRank orders alert severities so escalations can be detected
+func severityRank(severity string) int {
+	switch severity {
+	case "critical":
+		return 3
+	case "warning":
+		return 2
+	case "info":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// clearThreshold returns the explicit clear threshold, or the raise threshold
+// when none is configured (no hysteresis)
+func clearThreshold(clear, raise float64) float64 {
+	if clear > 0 {
+		return clear
+	}
+	return raise
+}
+
+// thresholdConditions evaluates every threshold check for a sample
+func thresholdConditions(metrics gpu.GPUMetrics, thresholds GPUAlertThresholds) []thresholdCondition {
+	memoryUsagePercent := float64(metrics.MemoryUsed) / float64(metrics.MemoryTotal) * 100
+	powerUsagePercent := 0.0
+	if metrics.PowerLimit > 0 {
+		powerUsagePercent = metrics.PowerDraw / metrics.PowerLimit * 100
+	}
+
+	// upper builds a condition with warning and critical levels above which the value alerts
+	upper := func(key, label string, value, high, critical, clear float64) thresholdCondition {
+		condition := thresholdCondition{
+			key:       key,
+			alertType: key,
+			value:     value,
+			clearAt:   clearThreshold(clear, high),
+		}
+		condition.cleared = value < condition.clearAt
+		if value >= critical {
+			condition.severity = "critical"
+			condition.threshold = critical
+			condition.message = fmt.Sprintf("GPU %s %s critically high", metrics.GPUID, label)
+		} else if value >= high {
+			condition.severity = "warning"
+			condition.threshold = high
+			condition.message = fmt.Sprintf("GPU %s %s high", metrics.GPUID, label)
+		}
+		return condition
+	}
+
+	conditions := []thresholdCondition{
+		upper("temperature", "temperature", metrics.Temperature,
+			thresholds.HighTemperature, thresholds.CriticalTemperature, thresholds.ClearTemperature),
+		upper("memory", "memory usage", memoryUsagePercent,
+			thresholds.HighMemoryUsage, thresholds.CriticalMemoryUsage, thresholds.ClearMemoryUsage),
+		upper("power", "power usage", powerUsagePercent,
+			thresholds.HighPowerUsage, thresholds.CriticalPowerUsage, thresholds.ClearPowerUsage),
+	}
+
+	highUtilization := thresholdCondition{
+		key:       "utilization_high",
+		alertType: "utilization",
+		value:     metrics.UtilizationGPU,
+		clearAt:   thresholds.HighUtilization,
+		cleared:   metrics.UtilizationGPU < thresholds.HighUtilization,
+	}
+	if !highUtilization.cleared {
+		highUtilization.severity = "info"
+		highUtilization.threshold = thresholds.HighUtilization
+		highUtilization.message = fmt.Sprintf("GPU %s utilization very high", metrics.GPUID)
+	}
+
+	lowUtilization := thresholdCondition{
+		key:       "utilization_low",
+		alertType: "utilization",
+		value:     metrics.UtilizationGPU,
+		clearAt:   thresholds.LowUtilization,
+		cleared:   metrics.UtilizationGPU > thresholds.LowUtilization,
+	}
+	if !lowUtilization.cleared {
+		lowUtilization.severity = "info"
+		lowUtilization.threshold = thresholds.LowUtilization
+		lowUtilization.message = fmt.Sprintf("GPU %s utilization low", metrics.GPUID)
+	}
+
+	return append(conditions, highUtilization, lowUtilization)
+}
+
+// evaluateCondition advances a condition's state and returns an alert when it
+// starts firing or escalates. A breach must hold for the configured duration
+// before firing, and a firing alert resolves only once the value passes the
+// clear threshold, recording a resolution event. Callers must hold the lock.
+func (gmi *GPUMetricsIntegration) evaluateCondition(metrics gpu.GPUMetrics, condition thresholdCondition, forDuration time.Duration, profile string, now time.Time) *gpu.GPUAlert {
+	states := gmi.alertStates[metrics.GPUID]
+	if states == nil {
+		states = make(map[string]*alertState)
+		gmi.alertStates[metrics.GPUID] = states
+	}
+	state := states[condition.key]
+
+	if condition.severity == "" {
+		if state == nil {
+			return nil
+		}
+		if state.firing && condition.cleared {
+			gmi.recordAlertResolved(state.alert, metrics, condition, now)
+			delete(states, condition.key)
+		} else if !state.firing {
+			// The breach ended before it held long enough to fire
+			delete(states, condition.key)
+		}
+		return nil
+	}
+
+	if state == nil {
+		state = &alertState{pendingSince: now}
+		states[condition.key] = state
+	}
+	if state.firing && severityRank(condition.severity) <= severityRank(state.alert.Severity) {
+		return nil
+	}
+	if now.Sub(state.pendingSince) < forDuration {
+		return nil
+	}
+
+	state.firing = true
+	state.alert = gpu.GPUAlert{
+		Type:      condition.alertType,
+		Severity:  condition.severity,
+		Message:   condition.message,
+		Value:     condition.value,
+		Threshold: condition.threshold,
+		Timestamp: now,
+		Profile:   profile,
+	}
+	return &state.alert
+}
+
+// recordAlertResolved records that a firing alert's condition has cleared
+func (gmi *GPUMetricsIntegration) recordAlertResolved(alert gpu.GPUAlert, metrics gpu.GPUMetrics, condition thresholdCondition, now time.Time) {
+	if gmi.alertGrouper != nil {
+		gmi.alertGrouper.Resolve(metrics.GPUID, alert.Type, now)
+	}
+	if gmi.monitoringService == nil {
+		return
+	}
+
+	gmi.monitoringService.RecordEvent(Event{
+		Type:     "gpu_alert_resolved",
+		Severity: "info",
+		Message:  fmt.Sprintf("GPU %s %s alert resolved", metrics.GPUID, alert.Type),
+		Source:   "gpu_metrics_integration",
+		Metadata: map[string]interface{}{
+			"gpu_id":           metrics.GPUID,
+			"gpu_name":         metrics.Name,
+			"alert_type":       alert.Type,
+			"alert_severity":   alert.Severity,
+			"value":            condition.value,
+			"clear_threshold":  condition.clearAt,
+			"profile":          alert.Profile,
+			"firing_since":     alert.Timestamp,
+			"duration_seconds": now.Sub(alert.Timestamp).Seconds(),
+		},
+	})
+}
+
+// GetActiveAlerts returns the threshold alerts currently firing on a GPU
+func (gmi *GPUMetricsIntegration) GetActiveAlerts(gpuID string) []gpu.GPUAlert {
+	gmi.mu.RLock()
+	defer gmi.mu.RUnlock()
+
+	active := make([]gpu.GPUAlert, 0)
+	for _, state := range gmi.alertStates[gpuID] {
+		if state.firing {
+			active = append(active, state.alert)
+		}
+	}
+	return active
+}
+
+// isAlertFiring reports whether an alert type is a firing threshold alert on
+// a GPU, as opposed to a one-shot alert; callers must hold the lock
+func (gmi *GPUMetricsIntegration) isAlertFiring(gpuID, alertType string) bool {
+	for _, state := range gmi.alertStates[gpuID] {
+		if state.firing && state.alert.Type == alertType {
+			return true
+		}
+	}
+	return false
+}
+
+// collectionBooster is a collector that speeds up while alert conditions
+// are active, such as gpu.MetricsCollector with an adaptive interval
+type collectionBooster interface {
+	Boost(hold time.Duration)
+}
+
+// alertConditionActive reports whether any threshold condition on a GPU is
+// pending or firing, which is when it has state; callers must hold the lock
+func (gmi *GPUMetricsIntegration) alertConditionActive(gpuID string) bool {
+	return len(gmi.alertStates[gpuID]) > 0
+}
+
+// SetAlertGrouper groups alerts into incidents. Each alert is still recorded
+// as a "gpu_alert" event tagged with its incident, while "gpu_incident"
+// events are recorded only when an incident opens, escalates or resolves.
+func (gmi *GPUMetricsIntegration) SetAlertGrouper(grouper *AlertGrouper) {
+	if grouper != nil {
+		grouper.Subscribe(gmi.recordIncidentEvent)
+	}
+
+	gmi.mu.Lock()
+	defer gmi.mu.Unlock()
+	gmi.alertGrouper = grouper
+}
+
+// recordIncidentEvent records an incident change with the monitoring service
+func (gmi *GPUMetricsIntegration) recordIncidentEvent(incident Incident, change string) {
+	if gmi.monitoringService == nil {
+		return
+	}
+
+	severity := incident.Severity
+	if change == IncidentClosed {
+		severity = "info"
+	}
+	gmi.monitoringService.RecordEvent(Event{
+		Type:     "gpu_incident",
+		Severity: severity,
+		Message:  fmt.Sprintf("Incident %s %s: %s", incident.ID, change, incident.Title),
+		Source:   "gpu_metrics_integration",
+		Metadata: map[string]interface{}{
+			"incident_id": incident.ID,
+			"change":      change,
+			"labels":      incident.Labels,
+			"alert_count": incident.AlertCount,
+			"gpu_count":   incident.GPUCount,
+			"started_at":  incident.StartedAt,
+		},
+	})
+}
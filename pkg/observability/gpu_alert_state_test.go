@@ -0,0 +1,120 @@
+package observability
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// temperatureSample builds a sample that only varies in temperature
+func temperatureSample(at time.Time, temperature float64) gpu.GPUMetrics {
+	return gpu.GPUMetrics{
+		GPUID:          "gpu-0",
+		Name:           "NVIDIA A100",
+		Temperature:    temperature,
+		UtilizationGPU: 50,
+		MemoryTotal:    40000,
+		MemoryUsed:     1000,
+		Timestamp:      at,
+	}
+}
+
+func TestAlertForDurationAndHysteresis(t *testing.T) {
+	monitor := NewMonitoringService(100)
+	integration := NewGPUMetricsIntegration(monitor, nil)
+	thresholds := DefaultGPUAlertThresholds()
+	thresholds.For = 30 * time.Second
+	integration.SetAlertThresholds(thresholds)
+
+	start := time.Now().Add(-time.Hour)
+	steps := []struct {
+		offset      time.Duration
+		temperature float64
+		alerts      int
+	}{
+		{0, 80, 0},                // breach starts pending
+		{10 * time.Second, 80, 0}, // not held long enough
+		{30 * time.Second, 80, 1}, // held for 30s: warning fires
+		{40 * time.Second, 80, 1}, // still firing, no repeat
+		{50 * time.Second, 90, 2}, // escalates to critical
+		{60 * time.Second, 72, 2}, // inside the hysteresis band, still firing
+		{70 * time.Second, 65, 2}, // below the clear threshold: resolved
+		{80 * time.Second, 80, 2}, // new breach pending again
+	}
+
+	for _, step := range steps {
+		integration.processGPUMetrics(temperatureSample(start.Add(step.offset), step.temperature))
+		if got := len(integration.GetAlertHistory("gpu-0", time.Time{})); got != step.alerts {
+			t.Fatalf("At +%v (%.0fC) expected %d alerts, got %d", step.offset, step.temperature, step.alerts, got)
+		}
+	}
+
+	history := integration.GetAlertHistory("gpu-0", time.Time{})
+	if history[0].Severity != "warning" || history[1].Severity != "critical" {
+		t.Errorf("Expected warning then critical, got %s then %s", history[0].Severity, history[1].Severity)
+	}
+	if active := integration.GetActiveAlerts("gpu-0"); len(active) != 0 {
+		t.Errorf("Expected no active alerts while the new breach is pending, got %+v", active)
+	}
+
+	resolved := 0
+	for _, event := range monitor.GetEvents(time.Time{}, time.Now().Add(time.Minute), "info") {
+		if event.Type == "gpu_alert_resolved" {
+			resolved++
+			if event.Metadata["alert_severity"] != "critical" || event.Metadata["duration_seconds"] != 20.0 {
+				t.Errorf("Unexpected resolution metadata: %+v", event.Metadata)
+			}
+		}
+	}
+	if resolved != 1 {
+		t.Errorf("Expected 1 resolution event, got %d", resolved)
+	}
+}
+
+func TestAlertPendingResetsWhenBreachEnds(t *testing.T) {
+	integration := NewGPUMetricsIntegration(NewMonitoringService(100), nil)
+	thresholds := DefaultGPUAlertThresholds()
+	thresholds.For = 30 * time.Second
+	integration.SetAlertThresholds(thresholds)
+
+	start := time.Now().Add(-time.Hour)
+	integration.processGPUMetrics(temperatureSample(start, 80))
+	integration.processGPUMetrics(temperatureSample(start.Add(20*time.Second), 60))
+	integration.processGPUMetrics(temperatureSample(start.Add(25*time.Second), 80))
+	integration.processGPUMetrics(temperatureSample(start.Add(40*time.Second), 80))
+	if got := len(integration.GetAlertHistory("gpu-0", time.Time{})); got != 0 {
+		t.Fatalf("Expected the interrupted breach not to fire, got %d alerts", got)
+	}
+
+	integration.processGPUMetrics(temperatureSample(start.Add(55*time.Second), 80))
+	if active := integration.GetActiveAlerts("gpu-0"); len(active) != 1 || active[0].Type != "temperature" {
+		t.Errorf("Expected the renewed breach to fire after 30s, got %+v", active)
+	}
+}
+
+// boostingCollector records collection boosts
+type boostingCollector struct {
+	*gpu.MockMetricsCollector
+	boosts int
+}
+
+func (c *boostingCollector) Boost(hold time.Duration) { c.boosts++ }
+
+func TestPendingAlertBoostsCollection(t *testing.T) {
+	collector := &boostingCollector{MockMetricsCollector: gpu.NewMockMetricsCollector(time.Second, 1)}
+	integration := NewGPUMetricsIntegration(NewMonitoringService(100), collector)
+	thresholds := DefaultGPUAlertThresholds()
+	thresholds.For = time.Minute
+	integration.SetAlertThresholds(thresholds)
+
+	start := time.Now().Add(-time.Hour)
+	integration.processGPUMetrics(temperatureSample(start, 60))
+	if collector.boosts != 0 {
+		t.Fatalf("healthy sample boosted collection")
+	}
+	integration.processGPUMetrics(temperatureSample(start.Add(time.Second), 80))
+	if collector.boosts != 1 {
+		t.Errorf("pending breach should boost collection, got %d boosts", collector.boosts)
+	}
+}
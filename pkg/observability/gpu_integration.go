@@ -3,6 +3,7 @@ package observability
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -27,32 +28,32 @@ const (
 // GPUCostConfiguration defines cost settings for GPU types and pricing models
 type GPUCostConfiguration struct {
 	// Cost per hour by GPU type (USD)
-	CostPerHour map[string]float64
+	CostPerHour map[string]float64 `yaml:"cost_per_hour" json:"cost_per_hour"`
 
 	// Pricing model settings
-	UseUtilizationFactor bool    // Whether to adjust cost based on utilization
-	MinUtilizationFactor float64 // Minimum cost factor for idle GPUs
-	IdleCostReduction    float64 // Cost reduction factor for idle time (0.0-1.0)
+	UseUtilizationFactor bool    `yaml:"use_utilization_factor" json:"use_utilization_factor"` // Whether to adjust cost based on utilization
+	MinUtilizationFactor float64 `yaml:"min_utilization_factor" json:"min_utilization_factor"` // Minimum cost factor for idle GPUs
+	IdleCostReduction    float64 `yaml:"idle_cost_reduction" json:"idle_cost_reduction"`       // Cost reduction factor for idle time (0.0-1.0)
 
 	// Cloud provider settings
-	CloudProvider string             // AWS, GCP, Azure, etc.
-	Region        string             // Cloud region for regional pricing
-	CustomPricing map[string]float64 // Custom pricing overrides
+	CloudProvider string             `yaml:"cloud_provider" json:"cloud_provider"` // AWS, GCP, Azure, etc.
+	Region        string             `yaml:"region" json:"region"`                 // Cloud region for regional pricing
+	CustomPricing map[string]float64 `yaml:"custom_pricing" json:"custom_pricing"` // Custom pricing overrides
 
 	// Currency and billing
-	Currency string  // Cost currency (USD, EUR, etc.)
-	TaxRate  float64 // Tax rate to apply (0.0-1.0)
+	Currency string  `yaml:"currency" json:"currency"` // Cost currency (USD, EUR, etc.)
+	TaxRate  float64 `yaml:"tax_rate" json:"tax_rate"` // Tax rate to apply (0.0-1.0)
 
 	// Advanced pricing features
-	SpotInstanceDiscount float64            // Discount for spot instances (0.0-1.0)
-	ReservedInstanceCost map[string]float64 // Reserved instance pricing
-	VolumeDiscounts      []VolumeDiscount   // Volume-based discounts
+	SpotInstanceDiscount float64            `yaml:"spot_instance_discount" json:"spot_instance_discount"` // Discount for spot instances (0.0-1.0)
+	ReservedInstanceCost map[string]float64 `yaml:"reserved_instance_cost" json:"reserved_instance_cost"` // Reserved instance pricing
+	VolumeDiscounts      []VolumeDiscount   `yaml:"volume_discounts" json:"volume_discounts"`             // Volume-based discounts
 }
 
 // VolumeDiscount defines volume-based pricing discounts
 type VolumeDiscount struct {
-	MinHours     float64 // Minimum hours for discount to apply
-	DiscountRate float64 // Discount rate (0.0-1.0)
+	MinHours     float64 `yaml:"min_hours" json:"min_hours"`         // Minimum hours for discount to apply
+	DiscountRate float64 `yaml:"discount_rate" json:"discount_rate"` // Discount rate (0.0-1.0)
 }
 
 // DefaultGPUCostConfiguration returns a default cost configuration
@@ -88,7 +89,8 @@ type GPUMetricsIntegration struct {
 
 	// Configuration
 	alertThresholds   GPUAlertThresholds
-	costConfig        GPUCostConfiguration // Add cost configuration
+	thresholdProfiles []GPUThresholdProfile // Per-model overrides, first match wins
+	costConfig        GPUCostConfiguration  // Add cost configuration
 	metricsEnabled    bool
 	eventsEnabled     bool
 	costsEnabled      bool
@@ -97,18 +99,45 @@ type GPUMetricsIntegration struct {
 	// State tracking
 	lastKnownState map[string]gpu.GPUMetrics
 	alertHistory   map[string][]gpu.GPUAlert
+	alertStates    map[string]map[string]*alertState // Threshold condition state per GPU
+	alertGrouper   *AlertGrouper                     // Optional incident grouping
+	burstConfig    *gpu.BurstConfig                  // Optional burst capture when alerts fire
+	bursting       map[string]bool                   // GPUs with a burst capture running
+
+	hardwareBaselines *HardwareBaselines // Optional, exports throughput against each model's baseline
 }
 
 // GPUAlertThresholds defines thresholds for GPU monitoring alerts
 type GPUAlertThresholds struct {
-	HighTemperature     float64 // Temperature in Celsius
-	CriticalTemperature float64
-	HighMemoryUsage     float64 // Memory usage percentage
-	CriticalMemoryUsage float64
-	HighPowerUsage      float64 // Power usage percentage of limit
-	CriticalPowerUsage  float64
-	LowUtilization      float64 // GPU utilization percentage
-	HighUtilization     float64
+	HighTemperature     float64 `yaml:"high_temperature" json:"high_temperature"` // Temperature in Celsius
+	CriticalTemperature float64 `yaml:"critical_temperature" json:"critical_temperature"`
+	HighMemoryUsage     float64 `yaml:"high_memory_usage" json:"high_memory_usage"` // Memory usage percentage
+	CriticalMemoryUsage float64 `yaml:"critical_memory_usage" json:"critical_memory_usage"`
+	HighPowerUsage      float64 `yaml:"high_power_usage" json:"high_power_usage"` // Power usage percentage of limit
+	CriticalPowerUsage  float64 `yaml:"critical_power_usage" json:"critical_power_usage"`
+	LowUtilization      float64 `yaml:"low_utilization" json:"low_utilization"` // GPU utilization percentage
+	HighUtilization     float64 `yaml:"high_utilization" json:"high_utilization"`
+
+	// Hysteresis: a firing alert resolves only once the value drops below its
+	// clear threshold (0 clears at the high threshold). For is how long a
+	// breach must hold before the alert fires.
+	ClearTemperature float64       `yaml:"clear_temperature" json:"clear_temperature"`
+	ClearMemoryUsage float64       `yaml:"clear_memory_usage" json:"clear_memory_usage"`
+	ClearPowerUsage  float64       `yaml:"clear_power_usage" json:"clear_power_usage"`
+	For              time.Duration `yaml:"for" json:"for"`
+}
+
+// DefaultThresholdProfile names the thresholds used when no profile matches a GPU
+const DefaultThresholdProfile = "default"
+
+// GPUThresholdProfile overrides alert thresholds for GPU models whose name
+// matches a regular expression, e.g. "(?i)h100" or "(?i)tesla t4"
+type GPUThresholdProfile struct {
+	Name       string             `yaml:"name" json:"name"`       // Reported in alert metadata
+	Pattern    string             `yaml:"pattern" json:"pattern"` // Matched against the GPU name
+	Thresholds GPUAlertThresholds `yaml:"thresholds" json:"thresholds"`
+
+	pattern *regexp.Regexp
 }
 
 // DefaultGPUAlertThresholds returns sensible default alert thresholds
@@ -122,6 +151,9 @@ func DefaultGPUAlertThresholds() GPUAlertThresholds {
 		CriticalPowerUsage:  95.0,
 		LowUtilization:      10.0,
 		HighUtilization:     95.0,
+		ClearTemperature:    70.0,
+		ClearMemoryUsage:    75.0,
+		ClearPowerUsage:     75.0,
 	}
 }
 
@@ -140,6 +172,7 @@ func NewGPUMetricsIntegration(
 		costsEnabled:      true,
 		lastKnownState:    make(map[string]gpu.GPUMetrics),
 		alertHistory:      make(map[string][]gpu.GPUAlert),
+		alertStates:       make(map[string]map[string]*alertState),
 	}
 
 	// Register callback with metrics collector
@@ -157,6 +190,55 @@ func (gmi *GPUMetricsIntegration) SetAlertThresholds(thresholds GPUAlertThreshol
 	gmi.alertThresholds = thresholds
 }
 
+// SetThresholdProfiles configures per-model threshold overrides. Profiles are
+// tried in order and GPUs matching none use the default thresholds.
+func (gmi *GPUMetricsIntegration) SetThresholdProfiles(profiles []GPUThresholdProfile) error {
+	compiled := make([]GPUThresholdProfile, len(profiles))
+	for i, profile := range profiles {
+		if profile.Name == "" {
+			return fmt.Errorf("threshold profile %d has no name", i)
+		}
+		pattern, err := regexp.Compile(profile.Pattern)
+		if err != nil {
+			return fmt.Errorf("threshold profile %s has invalid pattern: %w", profile.Name, err)
+		}
+		profile.pattern = pattern
+		compiled[i] = profile
+	}
+
+	gmi.mu.Lock()
+	defer gmi.mu.Unlock()
+	gmi.thresholdProfiles = compiled
+	return nil
+}
+
+// ThresholdProfiles returns the per-model threshold overrides in match order
+func (gmi *GPUMetricsIntegration) ThresholdProfiles() []GPUThresholdProfile {
+	gmi.mu.RLock()
+	defer gmi.mu.RUnlock()
+	profiles := make([]GPUThresholdProfile, len(gmi.thresholdProfiles))
+	copy(profiles, gmi.thresholdProfiles)
+	return profiles
+}
+
+// GetThresholdsForGPU returns the thresholds applied to a GPU model and the
+// name of the profile they come from
+func (gmi *GPUMetricsIntegration) GetThresholdsForGPU(gpuName string) (GPUAlertThresholds, string) {
+	gmi.mu.RLock()
+	defer gmi.mu.RUnlock()
+	return gmi.thresholdsFor(gpuName)
+}
+
+// thresholdsFor resolves the thresholds for a GPU model; callers must hold the lock
+func (gmi *GPUMetricsIntegration) thresholdsFor(gpuName string) (GPUAlertThresholds, string) {
+	for _, profile := range gmi.thresholdProfiles {
+		if profile.pattern.MatchString(gpuName) {
+			return profile.Thresholds, profile.Name
+		}
+	}
+	return gmi.alertThresholds, DefaultThresholdProfile
+}
+
 // EnableMetrics enables/disables metrics recording
 func (gmi *GPUMetricsIntegration) EnableMetrics(enabled bool) {
 	gmi.mu.Lock()
@@ -231,6 +313,14 @@ func (gmi *GPUMetricsIntegration) SetPrometheusExporter(exporter *PrometheusExpo
 	gmi.prometheusEnabled = true
 }
 
+// SetHardwareBaselines exports each busy GPU's estimated throughput as a
+// percentage of its model's baseline
+func (gmi *GPUMetricsIntegration) SetHardwareBaselines(baselines *HardwareBaselines) {
+	gmi.mu.Lock()
+	defer gmi.mu.Unlock()
+	gmi.hardwareBaselines = baselines
+}
+
 // GetPrometheusExporter returns the current Prometheus exporter
 func (gmi *GPUMetricsIntegration) GetPrometheusExporter() *PrometheusExporter {
 	gmi.mu.RLock()
@@ -255,7 +345,12 @@ func (gmi *GPUMetricsIntegration) processGPUMetrics(metrics gpu.GPUMetrics) {
 	if gmi.eventsEnabled {
 		alerts := gmi.checkAlerts(metrics, lastState, hasLastState)
 		for _, alert := range alerts {
-			gmi.recordAlertEvent(alert, metrics)
+			incidentID := ""
+			if gmi.alertGrouper != nil {
+				incidentID = gmi.alertGrouper.Add(gpuID, metrics.Name, alert, gmi.isAlertFiring(gpuID, alert.Type))
+			}
+			gmi.recordAlertEvent(alert, metrics, incidentID)
+			gmi.startBurst(gpuID, alert, incidentID)
 		}
 
 		// Store alerts in history
@@ -269,6 +364,11 @@ func (gmi *GPUMetricsIntegration) processGPUMetrics(metrics gpu.GPUMetrics) {
 		if len(gmi.alertHistory[gpuID]) > 100 {
 			gmi.alertHistory[gpuID] = gmi.alertHistory[gpuID][len(gmi.alertHistory[gpuID])-100:]
 		}
+
+		// Collect at full resolution while an alert condition is pending or firing
+		if booster, ok := gmi.metricsCollector.(collectionBooster); ok && gmi.alertConditionActive(gpuID) {
+			booster.Boost(0)
+		}
 	}
 
 	// Record GPU costs if enabled
@@ -278,6 +378,10 @@ func (gmi *GPUMetricsIntegration) processGPUMetrics(metrics gpu.GPUMetrics) {
 
 	// Update last known state
 	gmi.lastKnownState[gpuID] = metrics
+
+	if gmi.prometheusEnabled && gmi.prometheusExporter != nil {
+		gmi.prometheusExporter.UpdateClusterDistribution(clusterDistributions(gmi.lastKnownState))
+	}
 }
 
 // recordGPUMetrics records GPU metrics with the monitoring service
@@ -286,6 +390,9 @@ func (gmi *GPUMetricsIntegration) recordGPUMetrics(metrics gpu.GPUMetrics) {
 		"gpu_id":   metrics.GPUID,
 		"gpu_name": metrics.Name,
 	}
+	if metrics.VGPUProfile != "" {
+		labels["vgpu_profile"] = metrics.VGPUProfile
+	}
 
 	// GPU utilization metrics
 	gmi.monitoringService.RecordMetric(Metric{
@@ -318,42 +425,86 @@ func (gmi *GPUMetricsIntegration) recordGPUMetrics(metrics gpu.GPUMetrics) {
 	})
 
 	// Temperature metrics
-	gmi.monitoringService.RecordMetric(Metric{
-		Name:   "gpu_temperature_celsius",
-		Type:   MetricGauge,
-		Value:  metrics.Temperature,
-		Labels: labels,
-	})
+	if metrics.Reports("temperature.gpu") {
+		gmi.monitoringService.RecordMetric(Metric{
+			Name:   "gpu_temperature_celsius",
+			Type:   MetricGauge,
+			Value:  metrics.Temperature,
+			Labels: labels,
+		})
+	}
 
 	// Power metrics
-	gmi.monitoringService.RecordMetric(Metric{
-		Name:   "gpu_power_draw_watts",
-		Type:   MetricGauge,
-		Value:  metrics.PowerDraw,
-		Labels: labels,
-	})
+	if metrics.Reports("power.draw") {
+		gmi.monitoringService.RecordMetric(Metric{
+			Name:   "gpu_power_draw_watts",
+			Type:   MetricGauge,
+			Value:  metrics.PowerDraw,
+			Labels: labels,
+		})
+	}
 
-	gmi.monitoringService.RecordMetric(Metric{
-		Name:   "gpu_power_limit_watts",
-		Type:   MetricGauge,
-		Value:  metrics.PowerLimit,
-		Labels: labels,
-	})
+	if metrics.Reports("power.limit") {
+		gmi.monitoringService.RecordMetric(Metric{
+			Name:   "gpu_power_limit_watts",
+			Type:   MetricGauge,
+			Value:  metrics.PowerLimit,
+			Labels: labels,
+		})
+	}
 
 	// Clock metrics
-	gmi.monitoringService.RecordMetric(Metric{
-		Name:   "gpu_clock_graphics_mhz",
-		Type:   MetricGauge,
-		Value:  float64(metrics.ClockGraphics),
-		Labels: labels,
-	})
+	if metrics.Reports("clocks.current.graphics") {
+		gmi.monitoringService.RecordMetric(Metric{
+			Name:   "gpu_clock_graphics_mhz",
+			Type:   MetricGauge,
+			Value:  float64(metrics.ClockGraphics),
+			Labels: labels,
+		})
+	}
 
-	gmi.monitoringService.RecordMetric(Metric{
-		Name:   "gpu_clock_memory_mhz",
-		Type:   MetricGauge,
-		Value:  float64(metrics.ClockMemory),
-		Labels: labels,
-	})
+	if metrics.Reports("clocks.current.memory") {
+		gmi.monitoringService.RecordMetric(Metric{
+			Name:   "gpu_clock_memory_mhz",
+			Type:   MetricGauge,
+			Value:  float64(metrics.ClockMemory),
+			Labels: labels,
+		})
+	}
+
+	// Video engine metrics, which video workloads are scheduled by
+	if metrics.Reports("encoder.stats.sessionCount") {
+		gmi.monitoringService.RecordMetric(Metric{
+			Name:   "gpu_encoder_sessions",
+			Type:   MetricGauge,
+			Value:  float64(metrics.EncoderSessions),
+			Labels: labels,
+		})
+	}
+	if metrics.Reports("decoder.stats.sessionCount") {
+		gmi.monitoringService.RecordMetric(Metric{
+			Name:   "gpu_decoder_sessions",
+			Type:   MetricGauge,
+			Value:  float64(metrics.DecoderSessions),
+			Labels: labels,
+		})
+	}
+	if metrics.Reports("utilization.encoder") {
+		gmi.monitoringService.RecordMetric(Metric{
+			Name:   "gpu_encoder_utilization_percent",
+			Type:   MetricGauge,
+			Value:  metrics.EncoderUtilization,
+			Labels: labels,
+		})
+	}
+	if metrics.Reports("utilization.decoder") {
+		gmi.monitoringService.RecordMetric(Metric{
+			Name:   "gpu_decoder_utilization_percent",
+			Type:   MetricGauge,
+			Value:  metrics.DecoderUtilization,
+			Labels: labels,
+		})
+	}
 
 	// Process metrics
 	gmi.monitoringService.RecordMetric(Metric{
@@ -362,6 +513,14 @@ func (gmi *GPUMetricsIntegration) recordGPUMetrics(metrics gpu.GPUMetrics) {
 		Value:  float64(metrics.ProcessCount),
 		Labels: labels,
 	})
+	if metrics.MPSShared {
+		gmi.monitoringService.RecordMetric(Metric{
+			Name:   "gpu_mps_clients",
+			Type:   MetricGauge,
+			Value:  float64(len(metrics.MPSClients)),
+			Labels: labels,
+		})
+	}
 
 	// Efficiency metrics
 	powerEfficiency := 0.0
@@ -390,20 +549,58 @@ func (gmi *GPUMetricsIntegration) exportGPUMetricsToPrometheus(metrics gpu.GPUMe
 		"gpu_name": metrics.Name,
 		"node":     nodeName,
 	}
+	if metrics.VGPUProfile != "" {
+		labels["vgpu_profile"] = metrics.VGPUProfile
+	}
 
 	// Core GPU metrics
 	gmi.prometheusExporter.UpdateMetric("gpu_utilization_percent", metrics.UtilizationGPU, labels)
 	gmi.prometheusExporter.UpdateMetric("gpu_memory_utilization_percent", metrics.UtilizationMemory, labels)
 	gmi.prometheusExporter.UpdateMetric("gpu_memory_used_bytes", float64(metrics.MemoryUsed)*1024*1024, labels) // Convert MB to bytes
 	gmi.prometheusExporter.UpdateMetric("gpu_memory_total_bytes", float64(metrics.MemoryTotal)*1024*1024, labels)
-	gmi.prometheusExporter.UpdateMetric("gpu_temperature_celsius", metrics.Temperature, labels)
-	gmi.prometheusExporter.UpdateMetric("gpu_power_draw_watts", metrics.PowerDraw, labels)
-	gmi.prometheusExporter.UpdateMetric("gpu_power_limit_watts", metrics.PowerLimit, labels)
-	gmi.prometheusExporter.UpdateMetric("gpu_fan_speed_percent", metrics.FanSpeed, labels)
-	gmi.prometheusExporter.UpdateMetric("gpu_clock_graphics_mhz", float64(metrics.ClockGraphics), labels)
-	gmi.prometheusExporter.UpdateMetric("gpu_clock_memory_mhz", float64(metrics.ClockMemory), labels)
+	if metrics.Reports("temperature.gpu") {
+		gmi.prometheusExporter.UpdateMetric("gpu_temperature_celsius", metrics.Temperature, labels)
+	}
+	if metrics.Reports("power.draw") {
+		gmi.prometheusExporter.UpdateMetric("gpu_power_draw_watts", metrics.PowerDraw, labels)
+	}
+	if metrics.Reports("power.limit") {
+		gmi.prometheusExporter.UpdateMetric("gpu_power_limit_watts", metrics.PowerLimit, labels)
+	}
+	if metrics.Reports("fan.speed") {
+		gmi.prometheusExporter.UpdateMetric("gpu_fan_speed_percent", metrics.FanSpeed, labels)
+	}
+	if metrics.Reports("clocks.current.graphics") {
+		gmi.prometheusExporter.UpdateMetric("gpu_clock_graphics_mhz", float64(metrics.ClockGraphics), labels)
+	}
+	if metrics.Reports("clocks.current.memory") {
+		gmi.prometheusExporter.UpdateMetric("gpu_clock_memory_mhz", float64(metrics.ClockMemory), labels)
+	}
 	gmi.prometheusExporter.UpdateMetric("gpu_process_count", float64(metrics.ProcessCount), labels)
+	if metrics.Reports("encoder.stats.sessionCount") {
+		gmi.prometheusExporter.UpdateMetric("gpu_encoder_sessions", float64(metrics.EncoderSessions), labels)
+	}
+	if metrics.Reports("decoder.stats.sessionCount") {
+		gmi.prometheusExporter.UpdateMetric("gpu_decoder_sessions", float64(metrics.DecoderSessions), labels)
+	}
+	if metrics.Reports("utilization.encoder") {
+		gmi.prometheusExporter.UpdateMetric("gpu_encoder_utilization_percent", metrics.EncoderUtilization, labels)
+	}
+	if metrics.Reports("utilization.decoder") {
+		gmi.prometheusExporter.UpdateMetric("gpu_decoder_utilization_percent", metrics.DecoderUtilization, labels)
+	}
 	gmi.prometheusExporter.UpdateMetric("gpu_efficiency_score", powerEfficiency, labels)
+	if gmi.hardwareBaselines != nil {
+		if perf := gmi.hardwareBaselines.Estimate(metrics); perf.Judged {
+			gmi.prometheusExporter.UpdateMetric("gpu_expected_throughput_percent", perf.PercentOfExpected, labels)
+		}
+	}
+
+	lastSeen := metrics.Timestamp
+	if lastSeen.IsZero() {
+		lastSeen = time.Now()
+	}
+	gmi.prometheusExporter.UpdateMetric("gpu_last_seen_timestamp", float64(lastSeen.Unix()), labels)
 
 	// Calculate idle time percentage (simplified)
 	idleTimePercent := 100.0 - metrics.UtilizationGPU
@@ -421,13 +618,15 @@ func (gmi *GPUMetricsIntegration) exportGPUMetricsToPrometheus(metrics gpu.GPUMe
 
 // calculateHealthStatusNumeric returns health status as numeric value
 func (gmi *GPUMetricsIntegration) calculateHealthStatusNumeric(metrics gpu.GPUMetrics) int {
+	thresholds, _ := gmi.thresholdsFor(metrics.Name)
+
 	// Check for critical conditions
-	if metrics.Temperature >= gmi.alertThresholds.CriticalTemperature {
+	if metrics.Temperature >= thresholds.CriticalTemperature {
 		return 0 // Unhealthy
 	}
 
 	memoryUsagePercent := float64(metrics.MemoryUsed) / float64(metrics.MemoryTotal) * 100
-	if memoryUsagePercent >= gmi.alertThresholds.CriticalMemoryUsage {
+	if memoryUsagePercent >= thresholds.CriticalMemoryUsage {
 		return 0 // Unhealthy
 	}
 
@@ -435,14 +634,14 @@ func (gmi *GPUMetricsIntegration) calculateHealthStatusNumeric(metrics gpu.GPUMe
 	if metrics.PowerLimit > 0 {
 		powerUsagePercent = metrics.PowerDraw / metrics.PowerLimit * 100
 	}
-	if powerUsagePercent >= gmi.alertThresholds.CriticalPowerUsage {
+	if powerUsagePercent >= thresholds.CriticalPowerUsage {
 		return 0 // Unhealthy
 	}
 
 	// Check for warning conditions
-	if metrics.Temperature >= gmi.alertThresholds.HighTemperature ||
-		memoryUsagePercent >= gmi.alertThresholds.HighMemoryUsage ||
-		powerUsagePercent >= gmi.alertThresholds.HighPowerUsage {
+	if metrics.Temperature >= thresholds.HighTemperature ||
+		memoryUsagePercent >= thresholds.HighMemoryUsage ||
+		powerUsagePercent >= thresholds.HighPowerUsage {
 		return 1 // Warning
 	}
 
@@ -465,96 +664,19 @@ func (gmi *GPUMetricsIntegration) getHealthStatusString(status int) string {
 
 // checkAlerts checks for alert conditions in GPU metrics
 func (gmi *GPUMetricsIntegration) checkAlerts(metrics gpu.GPUMetrics, lastState gpu.GPUMetrics, hasLastState bool) []gpu.GPUAlert {
+	thresholds, profile := gmi.thresholdsFor(metrics.Name)
 	var alerts []gpu.GPUAlert
 
-	// Temperature alerts
-	if metrics.Temperature >= gmi.alertThresholds.CriticalTemperature {
-		alerts = append(alerts, gpu.GPUAlert{
-			Type:      "temperature",
-			Severity:  "critical",
-			Message:   fmt.Sprintf("GPU %s temperature critically high", metrics.GPUID),
-			Value:     metrics.Temperature,
-			Threshold: gmi.alertThresholds.CriticalTemperature,
-			Timestamp: metrics.Timestamp,
-		})
-	} else if metrics.Temperature >= gmi.alertThresholds.HighTemperature {
-		alerts = append(alerts, gpu.GPUAlert{
-			Type:      "temperature",
-			Severity:  "warning",
-			Message:   fmt.Sprintf("GPU %s temperature high", metrics.GPUID),
-			Value:     metrics.Temperature,
-			Threshold: gmi.alertThresholds.HighTemperature,
-			Timestamp: metrics.Timestamp,
-		})
-	}
-
-	// Memory alerts
-	memoryUsagePercent := float64(metrics.MemoryUsed) / float64(metrics.MemoryTotal) * 100
-	if memoryUsagePercent >= gmi.alertThresholds.CriticalMemoryUsage {
-		alerts = append(alerts, gpu.GPUAlert{
-			Type:      "memory",
-			Severity:  "critical",
-			Message:   fmt.Sprintf("GPU %s memory usage critically high", metrics.GPUID),
-			Value:     memoryUsagePercent,
-			Threshold: gmi.alertThresholds.CriticalMemoryUsage,
-			Timestamp: metrics.Timestamp,
-		})
-	} else if memoryUsagePercent >= gmi.alertThresholds.HighMemoryUsage {
-		alerts = append(alerts, gpu.GPUAlert{
-			Type:      "memory",
-			Severity:  "warning",
-			Message:   fmt.Sprintf("GPU %s memory usage high", metrics.GPUID),
-			Value:     memoryUsagePercent,
-			Threshold: gmi.alertThresholds.HighMemoryUsage,
-			Timestamp: metrics.Timestamp,
-		})
+	// Threshold alerts fire once a breach has held for the configured duration
+	// and stay firing until the value passes the clear threshold
+	now := metrics.Timestamp
+	if now.IsZero() {
+		now = time.Now()
 	}
-
-	// Power alerts
-	powerUsagePercent := 0.0
-	if metrics.PowerLimit > 0 {
-		powerUsagePercent = metrics.PowerDraw / metrics.PowerLimit * 100
-	}
-
-	if powerUsagePercent >= gmi.alertThresholds.CriticalPowerUsage {
-		alerts = append(alerts, gpu.GPUAlert{
-			Type:      "power",
-			Severity:  "critical",
-			Message:   fmt.Sprintf("GPU %s power usage critically high", metrics.GPUID),
-			Value:     powerUsagePercent,
-			Threshold: gmi.alertThresholds.CriticalPowerUsage,
-			Timestamp: metrics.Timestamp,
-		})
-	} else if powerUsagePercent >= gmi.alertThresholds.HighPowerUsage {
-		alerts = append(alerts, gpu.GPUAlert{
-			Type:      "power",
-			Severity:  "warning",
-			Message:   fmt.Sprintf("GPU %s power usage high", metrics.GPUID),
-			Value:     powerUsagePercent,
-			Threshold: gmi.alertThresholds.HighPowerUsage,
-			Timestamp: metrics.Timestamp,
-		})
-	}
-
-	// Utilization alerts
-	if metrics.UtilizationGPU >= gmi.alertThresholds.HighUtilization {
-		alerts = append(alerts, gpu.GPUAlert{
-			Type:      "utilization",
-			Severity:  "info",
-			Message:   fmt.Sprintf("GPU %s utilization very high", metrics.GPUID),
-			Value:     metrics.UtilizationGPU,
-			Threshold: gmi.alertThresholds.HighUtilization,
-			Timestamp: metrics.Timestamp,
-		})
-	} else if metrics.UtilizationGPU <= gmi.alertThresholds.LowUtilization {
-		alerts = append(alerts, gpu.GPUAlert{
-			Type:      "utilization",
-			Severity:  "info",
-			Message:   fmt.Sprintf("GPU %s utilization low", metrics.GPUID),
-			Value:     metrics.UtilizationGPU,
-			Threshold: gmi.alertThresholds.LowUtilization,
-			Timestamp: metrics.Timestamp,
-		})
+	for _, condition := range thresholdConditions(metrics, thresholds) {
+		if alert := gmi.evaluateCondition(metrics, condition, thresholds.For, profile, now); alert != nil {
+			alerts = append(alerts, *alert)
+		}
 	}
 
 	// State change alerts (if we have previous state)
@@ -585,11 +707,16 @@ func (gmi *GPUMetricsIntegration) checkAlerts(metrics gpu.GPUMetrics, lastState
 		}
 	}
 
+	// Record which threshold profile the alerts were evaluated against
+	for i := range alerts {
+		alerts[i].Profile = profile
+	}
+
 	return alerts
 }
 
 // recordAlertEvent records GPU alerts as events in the monitoring service
-func (gmi *GPUMetricsIntegration) recordAlertEvent(alert gpu.GPUAlert, metrics gpu.GPUMetrics) {
+func (gmi *GPUMetricsIntegration) recordAlertEvent(alert gpu.GPUAlert, metrics gpu.GPUMetrics, incidentID string) {
 	event := Event{
 		Type:     "gpu_alert",
 		Severity: alert.Severity,
@@ -601,8 +728,12 @@ func (gmi *GPUMetricsIntegration) recordAlertEvent(alert gpu.GPUAlert, metrics g
 			"alert_type": alert.Type,
 			"value":      alert.Value,
 			"threshold":  alert.Threshold,
+			"profile":    alert.Profile,
 		},
 	}
+	if incidentID != "" {
+		event.Metadata["incident_id"] = incidentID
+	}
 
 	gmi.monitoringService.RecordEvent(event)
 }
@@ -620,34 +751,13 @@ func (gmi *GPUMetricsIntegration) recordGPUCosts(metrics gpu.GPUMetrics, lastSta
 		return // Invalid duration
 	}
 
-	// Get cost per hour for this GPU type using the new configuration system
-	costPerHour := gmi.getGPUCostPerHour(metrics.Name)
-
-	// Calculate utilization factor if enabled
-	utilizationFactor := 1.0
-	if gmi.costConfig.UseUtilizationFactor {
-		utilizationFactor = gmi.calculateUtilizationFactor(metrics, lastState)
-	}
-
-	// Apply spot instance discount if configured
-	spotDiscount := 1.0 - gmi.costConfig.SpotInstanceDiscount
-
-	// Calculate base cost
-	baseCost := costPerHour * hours * utilizationFactor * spotDiscount
-
-	// Apply volume discounts if any
-	finalCost := gmi.applyVolumeDiscounts(baseCost, hours)
-
-	// Apply tax if configured
-	if gmi.costConfig.TaxRate > 0 {
-		finalCost *= (1.0 + gmi.costConfig.TaxRate)
-	}
+	finalCost := gmi.costConfig.intervalCost(metrics, lastState)
 
 	// Record cost entry
 	costEntry := CostEntry{
 		ID:        fmt.Sprintf("gpu-%s-%d", metrics.GPUID, time.Now().Unix()),
 		Operation: "gpu_compute",
-		ModelID:   fmt.Sprintf("gpu_%s", gmi.normalizeGPUType(metrics.Name)),
+		ModelID:   fmt.Sprintf("gpu_%s", normalizeGPUType(metrics.Name)),
 		Duration:  duration,
 		GPUHours:  hours,
 		Cost:      finalCost,
@@ -675,6 +785,8 @@ func (gmi *GPUMetricsIntegration) GetGPUHealth() map[string]gpu.GPUHealthStatus
 
 // calculateHealthStatus calculates health status for a GPU
 func (gmi *GPUMetricsIntegration) calculateHealthStatus(gpuID string, metrics gpu.GPUMetrics) gpu.GPUHealthStatus {
+	thresholds, _ := gmi.thresholdsFor(metrics.Name)
+
 	status := gpu.GPUHealthStatus{
 		GPUID:           gpuID,
 		Status:          "healthy",
@@ -684,12 +796,12 @@ func (gmi *GPUMetricsIntegration) calculateHealthStatus(gpuID string, metrics gp
 	}
 
 	// Check temperature status
-	if metrics.Temperature >= gmi.alertThresholds.CriticalTemperature {
+	if metrics.Temperature >= thresholds.CriticalTemperature {
 		status.TemperatureStatus = "critical"
 		status.Status = "critical"
 		status.Issues = append(status.Issues, fmt.Sprintf("Temperature critically high: %.1f°C", metrics.Temperature))
 		status.Recommendations = append(status.Recommendations, "Check cooling system and reduce workload")
-	} else if metrics.Temperature >= gmi.alertThresholds.HighTemperature {
+	} else if metrics.Temperature >= thresholds.HighTemperature {
 		status.TemperatureStatus = "warning"
 		if status.Status == "healthy" {
 			status.Status = "warning"
@@ -702,12 +814,12 @@ func (gmi *GPUMetricsIntegration) calculateHealthStatus(gpuID string, metrics gp
 
 	// Check memory status
 	memoryUsagePercent := float64(metrics.MemoryUsed) / float64(metrics.MemoryTotal) * 100
-	if memoryUsagePercent >= gmi.alertThresholds.CriticalMemoryUsage {
+	if memoryUsagePercent >= thresholds.CriticalMemoryUsage {
 		status.MemoryStatus = "critical"
 		status.Status = "critical"
 		status.Issues = append(status.Issues, fmt.Sprintf("Memory usage critically high: %.1f%%", memoryUsagePercent))
 		status.Recommendations = append(status.Recommendations, "Reduce memory usage or scale workloads")
-	} else if memoryUsagePercent >= gmi.alertThresholds.HighMemoryUsage {
+	} else if memoryUsagePercent >= thresholds.HighMemoryUsage {
 		status.MemoryStatus = "warning"
 		if status.Status == "healthy" {
 			status.Status = "warning"
@@ -724,11 +836,11 @@ func (gmi *GPUMetricsIntegration) calculateHealthStatus(gpuID string, metrics gp
 		powerUsagePercent = metrics.PowerDraw / metrics.PowerLimit * 100
 	}
 
-	if powerUsagePercent >= gmi.alertThresholds.CriticalPowerUsage {
+	if powerUsagePercent >= thresholds.CriticalPowerUsage {
 		status.PowerStatus = "critical"
 		status.Status = "critical"
 		status.Issues = append(status.Issues, fmt.Sprintf("Power usage critically high: %.1f%%", powerUsagePercent))
-	} else if powerUsagePercent >= gmi.alertThresholds.HighPowerUsage {
+	} else if powerUsagePercent >= thresholds.HighPowerUsage {
 		status.PowerStatus = "warning"
 		if status.Status == "healthy" {
 			status.Status = "warning"
@@ -739,11 +851,11 @@ func (gmi *GPUMetricsIntegration) calculateHealthStatus(gpuID string, metrics gp
 	}
 
 	// Check utilization status
-	if metrics.UtilizationGPU <= gmi.alertThresholds.LowUtilization {
+	if metrics.UtilizationGPU <= thresholds.LowUtilization {
 		status.UtilizationStatus = "underutilized"
 		status.Issues = append(status.Issues, fmt.Sprintf("Low utilization: %.1f%%", metrics.UtilizationGPU))
 		status.Recommendations = append(status.Recommendations, "Consider consolidating workloads or scaling down")
-	} else if metrics.UtilizationGPU >= gmi.alertThresholds.HighUtilization {
+	} else if metrics.UtilizationGPU >= thresholds.HighUtilization {
 		status.UtilizationStatus = "high"
 		status.Issues = append(status.Issues, fmt.Sprintf("High utilization: %.1f%%", metrics.UtilizationGPU))
 		status.Recommendations = append(status.Recommendations, "Monitor for performance bottlenecks")
@@ -788,27 +900,25 @@ func (gmi *GPUMetricsIntegration) GetAlertHistory(gpuID string, since time.Time)
 	return result
 }
 
-// getGPUCostPerHour returns the cost per hour for a given GPU type
-func (gmi *GPUMetricsIntegration) getGPUCostPerHour(gpuName string) float64 {
-	gpuType := gmi.normalizeGPUType(gpuName)
-
+// hourlyCost returns the cost per hour of a normalized GPU type
+func (c GPUCostConfiguration) hourlyCost(gpuType string) float64 {
 	// Check custom pricing first
-	if cost, exists := gmi.costConfig.CustomPricing[gpuType]; exists {
+	if cost, exists := c.CustomPricing[gpuType]; exists {
 		return cost
 	}
 
 	// Check reserved instance pricing
-	if cost, exists := gmi.costConfig.ReservedInstanceCost[gpuType]; exists {
+	if cost, exists := c.ReservedInstanceCost[gpuType]; exists {
 		return cost
 	}
 
 	// Check standard pricing
-	if cost, exists := gmi.costConfig.CostPerHour[gpuType]; exists {
+	if cost, exists := c.CostPerHour[gpuType]; exists {
 		return cost
 	}
 
 	// Return generic cost as fallback
-	if cost, exists := gmi.costConfig.CostPerHour["generic"]; exists {
+	if cost, exists := c.CostPerHour["generic"]; exists {
 		return cost
 	}
 
@@ -816,7 +926,7 @@ func (gmi *GPUMetricsIntegration) getGPUCostPerHour(gpuName string) float64 {
 }
 
 // normalizeGPUType extracts and normalizes GPU type from GPU name
-func (gmi *GPUMetricsIntegration) normalizeGPUType(gpuName string) string {
+func normalizeGPUType(gpuName string) string {
 	lowerName := strings.ToLower(gpuName)
 
 	switch {
@@ -839,24 +949,53 @@ func (gmi *GPUMetricsIntegration) normalizeGPUType(gpuName string) string {
 	}
 }
 
-// calculateUtilizationFactor calculates cost adjustment based on GPU utilization
-func (gmi *GPUMetricsIntegration) calculateUtilizationFactor(current, previous gpu.GPUMetrics) float64 {
+// intervalCost prices a GPU's time between two metrics samples
+func (c GPUCostConfiguration) intervalCost(metrics, lastState gpu.GPUMetrics) float64 {
+	hours := metrics.Timestamp.Sub(lastState.Timestamp).Hours()
+
+	// Get cost per hour for this GPU type using the new configuration system
+	costPerHour := c.hourlyCost(normalizeGPUType(metrics.Name))
+
+	// Calculate utilization factor if enabled
+	utilizationFactor := 1.0
+	if c.UseUtilizationFactor {
+		utilizationFactor = c.utilizationFactor(metrics, lastState)
+	}
+
+	// Apply spot instance discount if configured
+	spotDiscount := 1.0 - c.SpotInstanceDiscount
+
+	// Calculate base cost
+	baseCost := costPerHour * hours * utilizationFactor * spotDiscount
+
+	// Apply volume discounts if any
+	finalCost := c.applyVolumeDiscounts(baseCost, hours)
+
+	// Apply tax if configured
+	if c.TaxRate > 0 {
+		finalCost *= (1.0 + c.TaxRate)
+	}
+	return finalCost
+}
+
+// utilizationFactor calculates cost adjustment based on GPU utilization
+func (c GPUCostConfiguration) utilizationFactor(current, previous gpu.GPUMetrics) float64 {
 	// Average utilization over the measurement period
 	avgUtilization := (current.UtilizationGPU + previous.UtilizationGPU) / 2.0 / 100.0
 
 	// Apply idle cost reduction for underutilized GPUs
 	if avgUtilization < 0.1 { // Less than 10% utilization
-		return gmi.costConfig.MinUtilizationFactor +
-			(gmi.costConfig.IdleCostReduction * avgUtilization)
+		return c.MinUtilizationFactor +
+			(c.IdleCostReduction * avgUtilization)
 	}
 
 	// Linear factor based on utilization
-	factor := gmi.costConfig.MinUtilizationFactor +
-		(avgUtilization * (MaxUtilizationFactor - gmi.costConfig.MinUtilizationFactor))
+	factor := c.MinUtilizationFactor +
+		(avgUtilization * (MaxUtilizationFactor - c.MinUtilizationFactor))
 
 	// Ensure factor is within bounds
-	if factor < gmi.costConfig.MinUtilizationFactor {
-		return gmi.costConfig.MinUtilizationFactor
+	if factor < c.MinUtilizationFactor {
+		return c.MinUtilizationFactor
 	}
 	if factor > MaxUtilizationFactor {
 		return MaxUtilizationFactor
@@ -866,14 +1005,14 @@ func (gmi *GPUMetricsIntegration) calculateUtilizationFactor(current, previous g
 }
 
 // applyVolumeDiscounts applies volume-based discounts to the cost
-func (gmi *GPUMetricsIntegration) applyVolumeDiscounts(cost, hours float64) float64 {
-	if len(gmi.costConfig.VolumeDiscounts) == 0 {
+func (c GPUCostConfiguration) applyVolumeDiscounts(cost, hours float64) float64 {
+	if len(c.VolumeDiscounts) == 0 {
 		return cost
 	}
 
 	// Find the highest applicable discount
 	var bestDiscount float64
-	for _, discount := range gmi.costConfig.VolumeDiscounts {
+	for _, discount := range c.VolumeDiscounts {
 		if hours >= discount.MinHours && discount.DiscountRate > bestDiscount {
 			bestDiscount = discount.DiscountRate
 		}
@@ -0,0 +1,82 @@
+package observability
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestThresholdProfilesByGPUModel(t *testing.T) {
+	monitor := NewMonitoringService(100)
+	integration := NewGPUMetricsIntegration(monitor, nil)
+
+	hopper := DefaultGPUAlertThresholds()
+	hopper.HighTemperature = 85
+	hopper.CriticalTemperature = 92
+	err := integration.SetThresholdProfiles([]GPUThresholdProfile{
+		{Name: "hopper", Pattern: "(?i)h100", Thresholds: hopper},
+	})
+	if err != nil {
+		t.Fatalf("Failed to set profiles: %v", err)
+	}
+
+	if _, profile := integration.GetThresholdsForGPU("NVIDIA H100 80GB HBM3"); profile != "hopper" {
+		t.Errorf("Expected H100 to match the hopper profile, got %s", profile)
+	}
+	if _, profile := integration.GetThresholdsForGPU("Tesla T4"); profile != DefaultThresholdProfile {
+		t.Errorf("Expected T4 to fall back to the default profile, got %s", profile)
+	}
+
+	now := time.Now()
+	for _, name := range []string{"Tesla T4", "NVIDIA H100 80GB HBM3"} {
+		integration.processGPUMetrics(gpu.GPUMetrics{
+			GPUID:          name,
+			Name:           name,
+			Temperature:    80,
+			UtilizationGPU: 50,
+			MemoryTotal:    16000,
+			MemoryUsed:     1000,
+			Timestamp:      now,
+		})
+	}
+
+	t4Alerts := integration.GetAlertHistory("Tesla T4", time.Time{})
+	if len(t4Alerts) != 1 || t4Alerts[0].Type != "temperature" || t4Alerts[0].Profile != DefaultThresholdProfile {
+		t.Errorf("Expected a default-profile temperature alert for the T4 at 80C, got %+v", t4Alerts)
+	}
+	if alerts := integration.GetAlertHistory("NVIDIA H100 80GB HBM3", time.Time{}); len(alerts) != 0 {
+		t.Errorf("Expected no alerts for the H100 at 80C, got %+v", alerts)
+	}
+
+	events := monitor.GetEvents(now.Add(-time.Minute), time.Now().Add(time.Minute), "warning")
+	if len(events) != 1 || events[0].Metadata["profile"] != DefaultThresholdProfile {
+		t.Errorf("Expected alert event metadata to include the profile, got %+v", events)
+	}
+
+	if err := integration.SetThresholdProfiles([]GPUThresholdProfile{{Name: "bad", Pattern: "("}}); err == nil {
+		t.Error("Expected error for an invalid pattern")
+	}
+}
+
+func TestVGPUMetricsSkipUnreportedFields(t *testing.T) {
+	monitor := NewMonitoringService(1000)
+	integration := NewGPUMetricsIntegration(monitor, nil)
+	integration.processGPUMetrics(gpu.GPUMetrics{
+		GPUID:          "0",
+		Name:           "GRID T4-4Q",
+		UtilizationGPU: 35,
+		VGPUProfile:    "GRID T4-4Q",
+		Unavailable:    []string{"temperature.gpu", "power.draw", "power.limit"},
+		Timestamp:      time.Now(),
+	})
+
+	start, end := time.Now().Add(-time.Minute), time.Now().Add(time.Minute)
+	if power := monitor.GetMetrics(start, end, "gpu_power_draw_watts"); len(power) != 0 {
+		t.Errorf("recorded unreported power draw: %+v", power)
+	}
+	utilization := monitor.GetMetrics(start, end, "gpu_utilization_percent")
+	if len(utilization) != 1 || utilization[0].Labels["vgpu_profile"] != "GRID T4-4Q" {
+		t.Errorf("Expected utilization labelled with the vGPU profile, got %+v", utilization)
+	}
+}
@@ -0,0 +1,40 @@
+package observability
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// RecordMemoryLeakSuspect records a "possible GPU memory leak" warning
+// naming the process and its owning workload; register it with
+// MemoryLeakDetector.OnSuspect
+func (ms *MonitoringService) RecordMemoryLeakSuspect(suspect gpu.MemoryLeakSuspect) {
+	owner := ""
+	if suspect.Workload != "" {
+		owner = " of workload " + suspect.Workload
+	}
+	metadata := map[string]interface{}{
+		"gpu_id":             suspect.GPUID,
+		"pid":                suspect.PID,
+		"process_name":       suspect.ProcessName,
+		"start_memory_mb":    suspect.StartMemoryMB,
+		"memory_mb":          suspect.MemoryMB,
+		"growth_mb":          suspect.GrowthMB,
+		"utilization_change": suspect.UtilizationChange,
+		"observed_hours":     suspect.Observed.Hours(),
+	}
+	if suspect.Workload != "" {
+		metadata["workload"] = suspect.Workload
+	}
+
+	ms.RecordEvent(Event{
+		Type:     "gpu_memory_leak",
+		Severity: "warning",
+		Message: fmt.Sprintf("Possible GPU memory leak: process %s (PID %d)%s on GPU %s grew from %d MB to %d MB over %s without a utilization change",
+			suspect.ProcessName, suspect.PID, owner, suspect.GPUID, suspect.StartMemoryMB, suspect.MemoryMB, suspect.Observed.Round(time.Minute)),
+		Source:   "gpu_memory_leak_detector",
+		Metadata: metadata,
+	})
+}
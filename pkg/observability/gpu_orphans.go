@@ -0,0 +1,57 @@
+package observability
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// RecordOrphanProcess records an "orphaned GPU process" warning; register it
+// with OrphanDetector.OnOrphan
+func (ms *MonitoringService) RecordOrphanProcess(orphan gpu.OrphanProcess) {
+	ms.RecordEvent(Event{
+		Type:     "gpu_orphan_process",
+		Severity: "warning",
+		Message: fmt.Sprintf("Orphaned GPU process: %s (PID %d) on GPU %s holds %d MB without an owning workload since %s",
+			orphan.ProcessName, orphan.PID, orphan.GPUID, orphan.MemoryUsed, orphan.FirstSeen.Format(time.RFC3339)),
+		Source: "gpu_orphan_detector",
+		Metadata: map[string]interface{}{
+			"gpu_id":       orphan.GPUID,
+			"pid":          orphan.PID,
+			"process_name": orphan.ProcessName,
+			"memory_mb":    orphan.MemoryUsed,
+		},
+	})
+}
+
+// RecordRemediation records who ran a remediation, such as terminating an
+// orphaned GPU process, and whether it worked; register it with
+// Remediator.OnRemediation
+func (ms *MonitoringService) RecordRemediation(remediation gpu.Remediation) {
+	severity := "info"
+	message := fmt.Sprintf("%s ran %s on %s", remediation.Actor, remediation.Kind, remediation.Target)
+	if remediation.Error != "" {
+		severity = "warning"
+		message = fmt.Sprintf("%s failed to run %s on %s: %s", remediation.Actor, remediation.Kind, remediation.Target, remediation.Error)
+	}
+
+	metadata := map[string]interface{}{
+		"actor":  remediation.Actor,
+		"kind":   remediation.Kind,
+		"target": remediation.Target,
+		"error":  remediation.Error,
+	}
+	for key, value := range remediation.Details {
+		if _, exists := metadata[key]; !exists {
+			metadata[key] = value
+		}
+	}
+	ms.RecordEvent(Event{
+		Type:     "remediation",
+		Severity: severity,
+		Message:  message,
+		Source:   "remediator",
+		Metadata: metadata,
+	})
+}
@@ -0,0 +1,104 @@
+package observability
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// RecurringRunCost is a recurring workload run with the GPU time it used
+type RecurringRunCost struct {
+	gpu.RecurringRun
+	GPUHours float64 `json:"gpu_hours"`
+	Cost     float64 `json:"cost"`
+}
+
+// RecurringReport is a recurring workload with its priced run history
+type RecurringReport struct {
+	Name       string             `json:"name"`
+	Workload   string             `json:"workload"`
+	Recurrence gpu.Recurrence     `json:"recurrence"`
+	NextRun    time.Time          `json:"next_run"`
+	Active     int                `json:"active"`
+	Runs       []RecurringRunCost `json:"runs"`
+	GPUHours   float64            `json:"gpu_hours"`
+	Cost       float64            `json:"cost"`
+	Currency   string             `json:"currency"`
+}
+
+// BuildRecurringReports attributes the GPU time of usage records to the
+// recurring workload runs that used it and prices it at provider cost
+func BuildRecurringReports(statuses []gpu.RecurringStatus, records []gpu.UsageRecord, pricing GPUCostConfiguration) []RecurringReport {
+	type usage struct{ hours, cost float64 }
+	byWorkload := make(map[string]*usage)
+	for _, record := range records {
+		if record.Labels[gpu.RecurrenceLabel] == "" {
+			continue
+		}
+		hours := record.Hours(record.Start, record.End)
+		total, exists := byWorkload[record.WorkloadID]
+		if !exists {
+			total = &usage{}
+			byWorkload[record.WorkloadID] = total
+		}
+		total.hours += hours
+		total.cost += pricing.usageCost(record, hours)
+	}
+
+	reports := make([]RecurringReport, 0, len(statuses))
+	for _, status := range statuses {
+		report := RecurringReport{
+			Name:       status.Name,
+			Workload:   status.Workload,
+			Recurrence: status.Recurrence,
+			NextRun:    status.NextRun,
+			Active:     status.Active,
+			Runs:       make([]RecurringRunCost, 0, len(status.Runs)),
+			Currency:   pricing.Currency,
+		}
+		for _, run := range status.Runs {
+			priced := RecurringRunCost{RecurringRun: run}
+			if total, exists := byWorkload[run.WorkloadID]; exists {
+				priced.GPUHours = total.hours
+				priced.Cost = total.cost
+			}
+			report.GPUHours += priced.GPUHours
+			report.Cost += priced.Cost
+			report.Runs = append(report.Runs, priced)
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// RecordRecurringRun records a recurring workload run being submitted,
+// skipped or refused; register it with RecurrenceManager.OnRun
+func (ms *MonitoringService) RecordRecurringRun(run gpu.RecurringRun) {
+	severity := "info"
+	message := fmt.Sprintf("Submitted %s run %s scheduled for %s",
+		run.Recurrence, run.WorkloadID, run.ScheduledAt.Format(time.RFC3339))
+	switch run.Status {
+	case gpu.RunSkipped:
+		message = fmt.Sprintf("Skipped %s run scheduled for %s: %s",
+			run.Recurrence, run.ScheduledAt.Format(time.RFC3339), run.Reason)
+	case gpu.WorkloadFailed:
+		severity = "warning"
+		message = fmt.Sprintf("Failed to submit %s run scheduled for %s: %s",
+			run.Recurrence, run.ScheduledAt.Format(time.RFC3339), run.Reason)
+	}
+
+	ms.RecordEvent(Event{
+		Type:     "recurring_workload_run",
+		Severity: severity,
+		Message:  message,
+		Source:   "recurrence_manager",
+		Metadata: map[string]interface{}{
+			"recurrence":   run.Recurrence,
+			"workload_id":  run.WorkloadID,
+			"scheduled_at": run.ScheduledAt,
+			"status":       string(run.Status),
+			"reason":       run.Reason,
+		},
+	})
+}
@@ -0,0 +1,348 @@
+package observability
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/benchmark"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// Where a hardware baseline comes from
+const (
+	BaselineSourceSpec      = "spec"
+	BaselineSourceBenchmark = "benchmark"
+)
+
+// HardwareBaseline is the performance expected of a GPU model at full load,
+// for GPUs whose name matches a regular expression
+type HardwareBaseline struct {
+	Name       string  `yaml:"name" json:"name"`
+	Pattern    string  `yaml:"pattern" json:"pattern"`         // Matched against the GPU name
+	TFLOPs     float64 `yaml:"tflops" json:"tflops"`           // FP32 throughput at full load
+	PowerWatts float64 `yaml:"power_watts" json:"power_watts"` // Power draw at full load
+	ClockMHz   float64 `yaml:"clock_mhz" json:"clock_mhz"`     // Graphics clock at full load
+	Source     string  `yaml:"source" json:"source"`
+	GPUs       int     `yaml:"-" json:"gpus,omitempty"` // GPUs benchmarked, for benchmark baselines
+
+	pattern *regexp.Regexp
+	runner  string    // Benchmark runner the baseline comes from
+	updated time.Time // When the benchmark profile was last updated
+}
+
+// PublishedBaselines returns FP32 peak throughput, board power and boost
+// clock from vendor datasheets for common GPU models. More specific patterns
+// come first.
+func PublishedBaselines() []HardwareBaseline {
+	spec := func(name, pattern string, tflops, watts, clock float64) HardwareBaseline {
+		return HardwareBaseline{Name: name, Pattern: pattern, TFLOPs: tflops, PowerWatts: watts, ClockMHz: clock, Source: BaselineSourceSpec}
+	}
+	return []HardwareBaseline{
+		spec("H100 SXM", `(?i)h100.*(sxm|hbm3)`, 66.9, 700, 1980),
+		spec("H100 PCIe", `(?i)h100`, 51.2, 350, 1755),
+		spec("A100 SXM", `(?i)a100.*sxm`, 19.5, 400, 1410),
+		spec("A100 PCIe", `(?i)a100`, 19.5, 300, 1410),
+		spec("L40S", `(?i)l40s`, 91.6, 350, 2520),
+		spec("L4", `(?i)\bl4\b`, 30.3, 72, 2040),
+		spec("A10", `(?i)\ba10\b`, 31.2, 150, 1695),
+		spec("T4", `(?i)\bt4\b`, 8.1, 70, 1590),
+		spec("V100 SXM2", `(?i)v100.*sxm`, 15.7, 300, 1530),
+		spec("V100 PCIe", `(?i)v100`, 14.0, 250, 1380),
+		spec("RTX 6000 Ada", `(?i)rtx 6000 ada`, 91.1, 300, 2505),
+		spec("Quadro RTX 6000", `(?i)rtx 6000`, 16.3, 260, 1770),
+		spec("RTX 4090", `(?i)rtx 4090`, 82.6, 450, 2520),
+		spec("RTX 4080", `(?i)rtx 4080`, 48.7, 320, 2505),
+		spec("RTX 4070 Ti", `(?i)rtx 4070 ti`, 40.1, 285, 2610),
+		spec("RTX 3090", `(?i)rtx 3090`, 35.6, 350, 1695),
+		spec("RTX 3080", `(?i)rtx 3080`, 29.8, 320, 1710),
+	}
+}
+
+// PerformanceConfig controls how GPUs are judged against their baselines
+type PerformanceConfig struct {
+	// GPUs less busy than this (percent) are not judged, since an idle GPU
+	// lowers its clocks
+	MinUtilization float64 `yaml:"min_utilization" json:"min_utilization"`
+
+	// A GPU below UnderperformingPercent of its expected throughput, or more
+	// than PeerGapPercent points below the median of busy GPUs of the same
+	// model, is underperforming
+	UnderperformingPercent float64 `yaml:"underperforming_percent" json:"underperforming_percent"`
+	PeerGapPercent         float64 `yaml:"peer_gap_percent" json:"peer_gap_percent"`
+}
+
+// DefaultPerformanceConfig returns default performance judging settings
+func DefaultPerformanceConfig() PerformanceConfig {
+	return PerformanceConfig{
+		MinUtilization:         50,
+		UnderperformingPercent: 85,
+		PeerGapPercent:         10,
+	}
+}
+
+// GPUPerformance is a GPU's throughput as a share of what its model is
+// expected to achieve
+type GPUPerformance struct {
+	GPUID          string `json:"gpu_id"`
+	Node           string `json:"node,omitempty"`
+	Model          string `json:"model"`
+	Baseline       string `json:"baseline,omitempty"` // Name of the matched baseline
+	BaselineSource string `json:"baseline_source,omitempty"`
+
+	Utilization     float64 `json:"utilization"`
+	ExpectedTFLOPs  float64 `json:"expected_tflops"`  // At the current utilization
+	EstimatedTFLOPs float64 `json:"estimated_tflops"` // Scaled by the current clock
+	// Estimated throughput as a share of expected, and TFLOPs per watt as a
+	// share of the baseline's
+	PercentOfExpected      float64 `json:"percent_of_expected"`
+	PowerEfficiencyPercent float64 `json:"power_efficiency_percent"`
+	PeerMedianPercent      float64 `json:"peer_median_percent,omitempty"`
+	BenchmarkPercent       float64 `json:"benchmark_percent,omitempty"` // The GPU's last benchmark against its baseline
+
+	Judged          bool   `json:"judged"` // Busy, with a baseline and a clock reading
+	Underperforming bool   `json:"underperforming"`
+	Reason          string `json:"reason,omitempty"`
+}
+
+// HardwareBaselines holds the expected performance of each GPU model and
+// compares GPUs against it and against their peers
+type HardwareBaselines struct {
+	config      PerformanceConfig
+	configured  []HardwareBaseline
+	benchmarked map[string]HardwareBaseline // From benchmark profiles by lowercase model; matched first
+	results     map[string]benchmark.Result // Latest benchmark result by node and GPU ID
+	hostname    string                      // Node of GPUs collected locally
+	mu          sync.RWMutex
+}
+
+// NewHardwareBaselines creates baselines from configured ones, tried in
+// order, followed by PublishedBaselines
+func NewHardwareBaselines(config PerformanceConfig, baselines []HardwareBaseline) (*HardwareBaselines, error) {
+	defaults := DefaultPerformanceConfig()
+	if config.MinUtilization <= 0 {
+		config.MinUtilization = defaults.MinUtilization
+	}
+	if config.UnderperformingPercent <= 0 {
+		config.UnderperformingPercent = defaults.UnderperformingPercent
+	}
+	if config.PeerGapPercent <= 0 {
+		config.PeerGapPercent = defaults.PeerGapPercent
+	}
+
+	var compiled []HardwareBaseline
+	for i, baseline := range append(append([]HardwareBaseline(nil), baselines...), PublishedBaselines()...) {
+		if baseline.Name == "" {
+			return nil, fmt.Errorf("hardware baseline %d has no name", i)
+		}
+		if baseline.TFLOPs <= 0 {
+			return nil, fmt.Errorf("hardware baseline %s needs positive tflops", baseline.Name)
+		}
+		pattern, err := regexp.Compile(baseline.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("hardware baseline %s has invalid pattern: %w", baseline.Name, err)
+		}
+		baseline.pattern = pattern
+		if baseline.Source == "" {
+			baseline.Source = BaselineSourceSpec
+		}
+		compiled = append(compiled, baseline)
+	}
+
+	hostname, _ := os.Hostname()
+	return &HardwareBaselines{
+		config:      config,
+		configured:  compiled,
+		benchmarked: make(map[string]HardwareBaseline),
+		results:     make(map[string]benchmark.Result),
+		hostname:    hostname,
+	}, nil
+}
+
+// AddProfiles takes baselines from benchmark profiles, which match their
+// model's exact name ahead of configured and published baselines. A model
+// benchmarked by several runners keeps the most recently updated profile.
+func (hb *HardwareBaselines) AddProfiles(profiles []benchmark.Profile) {
+	hb.mu.Lock()
+	defer hb.mu.Unlock()
+	for _, profile := range profiles {
+		if profile.TFLOPs <= 0 {
+			continue
+		}
+		for _, result := range profile.Results {
+			key := result.Node + "/" + result.GPUID
+			if previous, exists := hb.results[key]; !exists || result.StartedAt.After(previous.StartedAt) {
+				hb.results[key] = result
+			}
+		}
+
+		model := strings.ToLower(profile.Model)
+		if previous, exists := hb.benchmarked[model]; exists && !profile.UpdatedAt.After(previous.updated) {
+			continue
+		}
+		pattern := "(?i)^" + regexp.QuoteMeta(profile.Model) + "$"
+		hb.benchmarked[model] = HardwareBaseline{
+			Name:       profile.Model,
+			Pattern:    pattern,
+			TFLOPs:     profile.TFLOPs,
+			PowerWatts: profile.AvgPowerWatts,
+			ClockMHz:   profile.AvgClockMHz,
+			Source:     BaselineSourceBenchmark,
+			GPUs:       profile.GPUs,
+			pattern:    regexp.MustCompile(pattern),
+			runner:     profile.Runner,
+			updated:    profile.UpdatedAt,
+		}
+	}
+}
+
+// LoadProfiles takes baselines from a benchmark profiles file, such as the
+// one agentaflow benchmark gpu writes
+func (hb *HardwareBaselines) LoadProfiles(path string) error {
+	store, err := benchmark.OpenProfiles(path)
+	if err != nil {
+		return err
+	}
+	hb.AddProfiles(store.Profiles())
+	return nil
+}
+
+// Baselines returns every baseline in match order
+func (hb *HardwareBaselines) Baselines() []HardwareBaseline {
+	hb.mu.RLock()
+	defer hb.mu.RUnlock()
+
+	baselines := make([]HardwareBaseline, 0, len(hb.benchmarked)+len(hb.configured))
+	for _, baseline := range hb.benchmarked {
+		baselines = append(baselines, baseline)
+	}
+	sort.Slice(baselines, func(i, j int) bool { return baselines[i].Name < baselines[j].Name })
+	return append(baselines, hb.configured...)
+}
+
+// Lookup returns the baseline for a GPU model
+func (hb *HardwareBaselines) Lookup(model string) (HardwareBaseline, bool) {
+	hb.mu.RLock()
+	defer hb.mu.RUnlock()
+	return hb.lookupLocked(model)
+}
+
+func (hb *HardwareBaselines) lookupLocked(model string) (HardwareBaseline, bool) {
+	if baseline, exists := hb.benchmarked[strings.ToLower(model)]; exists {
+		return baseline, true
+	}
+	for _, baseline := range hb.configured {
+		if baseline.pattern.MatchString(model) {
+			return baseline, true
+		}
+	}
+	return HardwareBaseline{}, false
+}
+
+// Estimate judges one GPU against its baseline. Utilization measures the
+// time a GPU is busy and the graphics clock the work done while busy, so a
+// busy GPU's throughput is its baseline scaled by both.
+func (hb *HardwareBaselines) Estimate(metrics gpu.GPUMetrics) GPUPerformance {
+	hb.mu.RLock()
+	defer hb.mu.RUnlock()
+
+	perf := GPUPerformance{
+		GPUID:       metrics.GPUID,
+		Node:        metrics.NodeID,
+		Model:       metrics.Name,
+		Utilization: metrics.UtilizationGPU,
+	}
+	baseline, ok := hb.lookupLocked(metrics.Name)
+	if !ok {
+		perf.Reason = "no baseline for this model"
+		return perf
+	}
+	perf.Baseline, perf.BaselineSource = baseline.Name, baseline.Source
+	perf.ExpectedTFLOPs = baseline.TFLOPs * metrics.UtilizationGPU / 100
+
+	// Only a benchmark by the same runner is comparable; runners differ in
+	// what they count
+	if result, ok := hb.benchmarkResultLocked(metrics); ok && baseline.runner != "" && result.Runner == baseline.runner {
+		perf.BenchmarkPercent = result.TFLOPs / baseline.TFLOPs * 100
+	}
+
+	switch {
+	case metrics.UtilizationGPU < hb.config.MinUtilization:
+		perf.Reason = fmt.Sprintf("below %.0f%% utilization", hb.config.MinUtilization)
+	case baseline.ClockMHz <= 0 || !metrics.Reports("clocks.current.graphics") || metrics.ClockGraphics == 0:
+		perf.Reason = "no clock reading to compare"
+	default:
+		perf.Judged = true
+		perf.PercentOfExpected = float64(metrics.ClockGraphics) / baseline.ClockMHz * 100
+		perf.EstimatedTFLOPs = perf.ExpectedTFLOPs * perf.PercentOfExpected / 100
+		if metrics.PowerDraw > 0 && baseline.PowerWatts > 0 {
+			perf.PowerEfficiencyPercent = (perf.EstimatedTFLOPs / metrics.PowerDraw) / (baseline.TFLOPs / baseline.PowerWatts) * 100
+		}
+	}
+
+	switch {
+	case perf.Judged && perf.PercentOfExpected < hb.config.UnderperformingPercent:
+		perf.Underperforming = true
+		perf.Reason = fmt.Sprintf("running at %.0f%% of expected throughput", perf.PercentOfExpected)
+	case perf.BenchmarkPercent > 0 && perf.BenchmarkPercent < hb.config.UnderperformingPercent:
+		perf.Underperforming = true
+		perf.Reason = fmt.Sprintf("benchmarked at %.0f%% of its baseline", perf.BenchmarkPercent)
+	}
+	return perf
+}
+
+// benchmarkResultLocked finds a GPU's latest benchmark result. Local GPUs
+// carry no node; uploaded ones are prefixed with it, e.g. gpu-node-1:0.
+func (hb *HardwareBaselines) benchmarkResultLocked(metrics gpu.GPUMetrics) (benchmark.Result, bool) {
+	node := metrics.NodeID
+	if node == "" {
+		node = hb.hostname
+	}
+	result, ok := hb.results[node+"/"+strings.TrimPrefix(metrics.GPUID, node+":")]
+	return result, ok
+}
+
+// Compare judges GPUs against their baselines and against busy GPUs of the
+// same model, so one falling behind its peers stands out once at least
+// three of them are busy. Results are sorted by GPU ID.
+func (hb *HardwareBaselines) Compare(metrics map[string]gpu.GPUMetrics) []GPUPerformance {
+	performances := make([]GPUPerformance, 0, len(metrics))
+	peers := make(map[string][]float64) // Judged percentages by model
+	for _, m := range metrics {
+		perf := hb.Estimate(m)
+		if perf.Judged {
+			peers[perf.Model] = append(peers[perf.Model], perf.PercentOfExpected)
+		}
+		performances = append(performances, perf)
+	}
+
+	for i := range performances {
+		perf := &performances[i]
+		if !perf.Judged || len(peers[perf.Model]) < 3 {
+			continue
+		}
+		perf.PeerMedianPercent = median(peers[perf.Model])
+		if !perf.Underperforming && perf.PercentOfExpected < perf.PeerMedianPercent-hb.config.PeerGapPercent {
+			perf.Underperforming = true
+			perf.Reason = fmt.Sprintf("running at %.0f%% of expected throughput, %.0f points below its peers", perf.PercentOfExpected, perf.PeerMedianPercent-perf.PercentOfExpected)
+		}
+	}
+
+	sort.Slice(performances, func(i, j int) bool { return performances[i].GPUID < performances[j].GPUID })
+	return performances
+}
+
+// median returns the middle of values
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
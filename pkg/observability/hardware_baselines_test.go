@@ -0,0 +1,179 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/benchmark"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// busyA100 is a busy A100 at a graphics clock
+func busyA100(gpuID string, clock uint64) gpu.GPUMetrics {
+	return gpu.GPUMetrics{
+		GPUID:          gpuID,
+		NodeID:         "gpu-node-1",
+		Name:           "NVIDIA A100-SXM4-40GB",
+		UtilizationGPU: 100,
+		ClockGraphics:  clock,
+		PowerDraw:      400,
+	}
+}
+
+func TestHardwareBaselinesLookup(t *testing.T) {
+	baselines, err := NewHardwareBaselines(DefaultPerformanceConfig(), []HardwareBaseline{
+		{Name: "A100 lab", Pattern: "(?i)a100.*lab", TFLOPs: 18, ClockMHz: 1300},
+	})
+	if err != nil {
+		t.Fatalf("NewHardwareBaselines failed: %v", err)
+	}
+
+	cases := map[string]string{
+		"NVIDIA A100-SXM4-40GB": "A100 SXM",
+		"NVIDIA A100 80GB PCIe": "A100 PCIe",
+		"NVIDIA A100 lab unit":  "A100 lab",
+		"NVIDIA H100 80GB HBM3": "H100 SXM",
+		"NVIDIA L4":             "L4",
+		"NVIDIA L40S":           "L40S",
+		"NVIDIA A10":            "A10",
+		"Tesla T4":              "T4",
+	}
+	for model, want := range cases {
+		if baseline, ok := baselines.Lookup(model); !ok || baseline.Name != want {
+			t.Errorf("Expected %s to match %s, got %+v", model, want, baseline)
+		}
+	}
+	if _, ok := baselines.Lookup("Matrox G200"); ok {
+		t.Error("Expected no baseline for an unknown model")
+	}
+
+	if _, err := NewHardwareBaselines(DefaultPerformanceConfig(), []HardwareBaseline{{Name: "bad", Pattern: "(", TFLOPs: 1}}); err == nil {
+		t.Error("Expected an invalid pattern rejected")
+	}
+}
+
+func TestHardwareBaselinesFlagUnderperformingGPUs(t *testing.T) {
+	baselines, err := NewHardwareBaselines(DefaultPerformanceConfig(), nil)
+	if err != nil {
+		t.Fatalf("NewHardwareBaselines failed: %v", err)
+	}
+
+	idle := busyA100("gpu-node-1:4", 210)
+	idle.UtilizationGPU = 5
+	performances := baselines.Compare(map[string]gpu.GPUMetrics{
+		"gpu-node-1:0": busyA100("gpu-node-1:0", 1410),
+		"gpu-node-1:1": busyA100("gpu-node-1:1", 1395),
+		"gpu-node-1:2": busyA100("gpu-node-1:2", 1400),
+		"gpu-node-1:3": busyA100("gpu-node-1:3", 1240),
+		"gpu-node-1:4": idle,
+	})
+
+	byID := make(map[string]GPUPerformance)
+	for _, perf := range performances {
+		byID[perf.GPUID] = perf
+	}
+	healthy := byID["gpu-node-1:0"]
+	if !healthy.Judged || healthy.PercentOfExpected != 100 || healthy.EstimatedTFLOPs != 19.5 || healthy.PowerEfficiencyPercent != 100 {
+		t.Errorf("Expected a GPU at its boost clock at 100%%, got %+v", healthy)
+	}
+	if healthy.Underperforming {
+		t.Errorf("Expected the healthy GPU not flagged, got %+v", healthy)
+	}
+
+	// 88% of expected clears the threshold but trails its peers
+	slow := byID["gpu-node-1:3"]
+	if !slow.Underperforming || !strings.Contains(slow.Reason, "below its peers") || slow.PeerMedianPercent < 98 {
+		t.Errorf("Expected the slow A100 to stand out against its peers, got %+v", slow)
+	}
+	if perf := byID["gpu-node-1:4"]; perf.Judged || perf.Underperforming {
+		t.Errorf("Expected the idle GPU not judged, got %+v", perf)
+	}
+}
+
+func TestHardwareBaselinesFromBenchmarks(t *testing.T) {
+	baselines, err := NewHardwareBaselines(DefaultPerformanceConfig(), nil)
+	if err != nil {
+		t.Fatalf("NewHardwareBaselines failed: %v", err)
+	}
+
+	result := func(gpuID string, tflops float64) benchmark.Result {
+		return benchmark.Result{Node: "gpu-node-1", GPUID: gpuID, Model: "NVIDIA A100-SXM4-40GB", Runner: "gpu-burn", TFLOPs: tflops, Passed: true}
+	}
+	baselines.AddProfiles([]benchmark.Profile{{
+		Model:         "NVIDIA A100-SXM4-40GB",
+		Runner:        "gpu-burn",
+		GPUs:          3,
+		TFLOPs:        16,
+		AvgPowerWatts: 390,
+		AvgClockMHz:   1380,
+		UpdatedAt:     time.Now(),
+		Results:       []benchmark.Result{result("0", 16.5), result("1", 16.4), result("2", 12.8)},
+	}})
+
+	baseline, ok := baselines.Lookup("nvidia a100-sxm4-40gb")
+	if !ok || baseline.Source != BaselineSourceBenchmark || baseline.TFLOPs != 16 {
+		t.Fatalf("Expected the benchmark baseline ahead of the published one, got %+v", baseline)
+	}
+
+	// An idle GPU whose benchmark fell short is still flagged
+	idle := busyA100("gpu-node-1:2", 1380)
+	idle.UtilizationGPU = 0
+	perf := baselines.Estimate(idle)
+	if perf.BenchmarkPercent != 80 || !perf.Underperforming || !strings.Contains(perf.Reason, "benchmarked at 80%") {
+		t.Errorf("Expected the GPU's benchmark at 80%% of its peers flagged, got %+v", perf)
+	}
+	if perf := baselines.Estimate(busyA100("gpu-node-1:0", 1380)); perf.Underperforming || perf.PercentOfExpected != 100 {
+		t.Errorf("Expected a GPU at the benchmarked clock at 100%%, got %+v", perf)
+	}
+}
+
+func TestHardwareBaselinesEndpoint(t *testing.T) {
+	collector := &historyCollector{history: map[string][]gpu.GPUMetrics{
+		"0": {busyA100("0", 1410)},
+		"1": {busyA100("1", 1000)},
+	}}
+	dashboard := NewWebDashboard(NewMonitoringService(100), collector, nil, WebDashboardConfig{Port: 0})
+
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/performance/baselines", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without baselines, got %d", response.Code)
+	}
+
+	baselines, _ := NewHardwareBaselines(DefaultPerformanceConfig(), nil)
+	dashboard.SetHardwareBaselines(baselines)
+
+	var body struct {
+		Baselines       []HardwareBaseline `json:"baselines"`
+		GPUs            []GPUPerformance   `json:"gpus"`
+		Underperforming int                `json:"underperforming"`
+	}
+	response := serveDashboard(dashboard, http.MethodGet, "/api/v1/performance/baselines", "")
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode baselines: %v", err)
+	}
+	if len(body.Baselines) == 0 || len(body.GPUs) != 2 || body.Underperforming != 1 || !body.GPUs[1].Underperforming {
+		t.Errorf("Expected GPU 1 flagged at 71%% of expected, got %+v", body)
+	}
+
+	var efficiency struct {
+		Expected        map[string]float64       `json:"expected_throughput_percent"`
+		Recommendations []map[string]interface{} `json:"recommendations"`
+	}
+	if err := json.NewDecoder(serveDashboard(dashboard, http.MethodGet, "/api/v1/performance/efficiency", "").Body).Decode(&efficiency); err != nil {
+		t.Fatalf("Failed to decode efficiency: %v", err)
+	}
+	if efficiency.Expected["0"] != 100 {
+		t.Errorf("Expected GPU 0 at 100%% of expected throughput, got %v", efficiency.Expected)
+	}
+	found := false
+	for _, recommendation := range efficiency.Recommendations {
+		if recommendation["type"] == "performance" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a recommendation for the underperforming GPU, got %v", efficiency.Recommendations)
+	}
+}
@@ -0,0 +1,134 @@
+package observability
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// maxHeatmapBuckets bounds the number of time columns in a heatmap
+const maxHeatmapBuckets = 1000
+
+// heatmapMetrics maps heatmap metric names to recorded GPU metrics
+var heatmapMetrics = map[string]string{
+	"utilization": "gpu_utilization_percent",
+	"memory":      "gpu_memory_utilization_percent",
+	"temperature": "gpu_temperature_celsius",
+	"power":       "gpu_power_draw_watts",
+}
+
+// HeatmapQuery selects the metric, time range and resolution of a heatmap
+type HeatmapQuery struct {
+	Metric             string        // utilization, memory, temperature or power
+	Start              time.Time     // Start of the first bucket
+	End                time.Time     // End of the last bucket
+	Resolution         time.Duration // Bucket width
+	UnderutilizedBelow float64       // Average utilization below which a GPU is flagged, 0 to disable
+}
+
+// HeatmapRow holds one GPU's bucket averages; buckets without samples are null
+type HeatmapRow struct {
+	GPUID         string     `json:"gpu_id"`
+	GPUName       string     `json:"gpu_name"`
+	Values        []*float64 `json:"values"`
+	Average       float64    `json:"average"`
+	Samples       int        `json:"samples"`
+	Underutilized bool       `json:"underutilized"`
+}
+
+// Heatmap is a GPU×time matrix of bucket averages
+type Heatmap struct {
+	Metric            string       `json:"metric"`
+	Start             time.Time    `json:"start"`
+	End               time.Time    `json:"end"`
+	ResolutionSeconds float64      `json:"resolution_seconds"`
+	Timestamps        []time.Time  `json:"timestamps"` // Start of each bucket
+	Rows              []HeatmapRow `json:"rows"`
+	Underutilized     []string     `json:"underutilized"` // GPUs averaging below the threshold
+	Annotations       []Annotation `json:"annotations,omitempty"`
+}
+
+// BuildHeatmap averages a GPU metric into fixed-width time buckets per GPU
+func (ms *MonitoringService) BuildHeatmap(query HeatmapQuery) (*Heatmap, error) {
+	if query.Metric == "" {
+		query.Metric = "utilization"
+	}
+	metricName, ok := heatmapMetrics[query.Metric]
+	if !ok {
+		return nil, fmt.Errorf("unsupported heatmap metric %q (must be utilization, memory, temperature or power)", query.Metric)
+	}
+	if query.Resolution < time.Second {
+		return nil, fmt.Errorf("heatmap resolution must be at least 1s")
+	}
+	if !query.End.After(query.Start) {
+		return nil, fmt.Errorf("heatmap end must be after start")
+	}
+	buckets := int((query.End.Sub(query.Start) + query.Resolution - 1) / query.Resolution)
+	if buckets > maxHeatmapBuckets {
+		return nil, fmt.Errorf("heatmap would have %d buckets, the maximum is %d; use a coarser resolution", buckets, maxHeatmapBuckets)
+	}
+
+	heatmap := &Heatmap{
+		Metric:            query.Metric,
+		Start:             query.Start,
+		End:               query.End,
+		ResolutionSeconds: query.Resolution.Seconds(),
+		Timestamps:        make([]time.Time, buckets),
+		Rows:              make([]HeatmapRow, 0),
+		Underutilized:     make([]string, 0),
+	}
+	for i := range heatmap.Timestamps {
+		heatmap.Timestamps[i] = query.Start.Add(time.Duration(i) * query.Resolution)
+	}
+
+	type accumulator struct {
+		name   string
+		sums   []float64
+		counts []int
+	}
+	byGPU := make(map[string]*accumulator)
+	for _, metric := range ms.GetMetrics(query.Start.Add(-time.Nanosecond), query.End, metricName) {
+		gpuID := metric.Labels["gpu_id"]
+		if gpuID == "" {
+			continue
+		}
+		acc, exists := byGPU[gpuID]
+		if !exists {
+			acc = &accumulator{sums: make([]float64, buckets), counts: make([]int, buckets)}
+			byGPU[gpuID] = acc
+		}
+		acc.name = metric.Labels["gpu_name"]
+		bucket := int(metric.Timestamp.Sub(query.Start) / query.Resolution)
+		acc.sums[bucket] += metric.Value
+		acc.counts[bucket]++
+	}
+
+	gpuIDs := make([]string, 0, len(byGPU))
+	for gpuID := range byGPU {
+		gpuIDs = append(gpuIDs, gpuID)
+	}
+	sort.Strings(gpuIDs)
+
+	for _, gpuID := range gpuIDs {
+		acc := byGPU[gpuID]
+		row := HeatmapRow{GPUID: gpuID, GPUName: acc.name, Values: make([]*float64, buckets)}
+		var total float64
+		for i := range acc.sums {
+			if acc.counts[i] == 0 {
+				continue
+			}
+			average := acc.sums[i] / float64(acc.counts[i])
+			row.Values[i] = &average
+			total += acc.sums[i]
+			row.Samples += acc.counts[i]
+		}
+		row.Average = total / float64(row.Samples)
+		if query.Metric == "utilization" && query.UnderutilizedBelow > 0 && row.Average < query.UnderutilizedBelow {
+			row.Underutilized = true
+			heatmap.Underutilized = append(heatmap.Underutilized, gpuID)
+		}
+		heatmap.Rows = append(heatmap.Rows, row)
+	}
+
+	return heatmap, nil
+}
@@ -0,0 +1,94 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBuildHeatmapBucketsPerGPU(t *testing.T) {
+	monitor := NewMonitoringService(1000)
+	start := time.Now().Add(-time.Second)
+	end := start.Add(2 * time.Second)
+	for _, sample := range []struct {
+		gpuID string
+		value float64
+	}{{"gpu-0", 80}, {"gpu-0", 90}, {"gpu-1", 4}, {"gpu-1", 6}} {
+		monitor.RecordMetric(Metric{
+			Name:   "gpu_utilization_percent",
+			Value:  sample.value,
+			Labels: map[string]string{"gpu_id": sample.gpuID, "gpu_name": "A100"},
+		})
+	}
+	monitor.RecordMetric(Metric{Name: "gpu_temperature_celsius", Value: 70, Labels: map[string]string{"gpu_id": "gpu-2"}})
+
+	heatmap, err := monitor.BuildHeatmap(HeatmapQuery{Start: start, End: end, Resolution: time.Second, UnderutilizedBelow: 10})
+	if err != nil {
+		t.Fatalf("BuildHeatmap failed: %v", err)
+	}
+	if len(heatmap.Timestamps) != 2 || len(heatmap.Rows) != 2 {
+		t.Fatalf("Expected 2 buckets and 2 GPUs, got %d and %d", len(heatmap.Timestamps), len(heatmap.Rows))
+	}
+
+	row := heatmap.Rows[0]
+	if row.GPUID != "gpu-0" || row.Average != 85 || row.Samples != 2 || row.Underutilized {
+		t.Errorf("Unexpected row %+v", row)
+	}
+	filled := 0
+	for _, value := range row.Values {
+		if value != nil {
+			filled++
+		}
+	}
+	if filled == 0 || filled > 2 {
+		t.Errorf("Expected samples in 1 or 2 buckets, got %d", filled)
+	}
+	if len(heatmap.Underutilized) != 1 || heatmap.Underutilized[0] != "gpu-1" {
+		t.Errorf("Expected gpu-1 flagged as underutilized, got %v", heatmap.Underutilized)
+	}
+
+	temperature, err := monitor.BuildHeatmap(HeatmapQuery{Metric: "temperature", Start: start, End: end, Resolution: time.Second, UnderutilizedBelow: 100})
+	if err != nil {
+		t.Fatalf("BuildHeatmap failed: %v", err)
+	}
+	if len(temperature.Rows) != 1 || len(temperature.Underutilized) != 0 {
+		t.Errorf("Expected one temperature row and no utilization flags, got %+v", temperature)
+	}
+
+	invalid := []HeatmapQuery{
+		{Metric: "fan", Start: start, End: end, Resolution: time.Second},
+		{Start: start, End: end, Resolution: time.Millisecond},
+		{Start: end, End: start, Resolution: time.Second},
+		{Start: end.Add(-24 * time.Hour), End: end, Resolution: time.Second},
+	}
+	for _, query := range invalid {
+		if _, err := monitor.BuildHeatmap(query); err == nil {
+			t.Errorf("Expected query %+v to be rejected", query)
+		}
+	}
+}
+
+func TestHeatmapEndpoint(t *testing.T) {
+	monitor := NewMonitoringService(100)
+	monitor.RecordMetric(Metric{Name: "gpu_utilization_percent", Value: 50, Labels: map[string]string{"gpu_id": "gpu-0"}})
+	dashboard := NewWebDashboard(monitor, nil, nil, WebDashboardConfig{Port: 0})
+
+	response := serveDashboard(dashboard, http.MethodGet, "/api/v1/gpus/heatmap?hours=1&resolution=1m", "")
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", response.Code, response.Body.String())
+	}
+	var heatmap Heatmap
+	if err := json.NewDecoder(response.Body).Decode(&heatmap); err != nil {
+		t.Fatalf("Failed to decode heatmap: %v", err)
+	}
+	if len(heatmap.Timestamps) != 60 || len(heatmap.Rows) != 1 || heatmap.Rows[0].Values[59] == nil {
+		t.Errorf("Expected 60 buckets with the sample in the last, got %d buckets and %+v", len(heatmap.Timestamps), heatmap.Rows)
+	}
+
+	for _, path := range []string{"/api/v1/gpus/heatmap?resolution=soon", "/api/v1/gpus/heatmap?metric=fan"} {
+		if response := serveDashboard(dashboard, http.MethodGet, path, ""); response.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", path, response.Code)
+		}
+	}
+}
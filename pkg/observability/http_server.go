@@ -0,0 +1,407 @@
+package observability
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/golang/snappy"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// HTTPServerConfig configures transport features shared by the dashboard,
+// status page and metrics servers
+type HTTPServerConfig struct {
+	// Responses are compressed when the client accepts it unless disabled
+	DisableCompression bool `yaml:"disable_compression" json:"disable_compression"`
+
+	// Smaller responses are sent uncompressed; zero uses 1024 bytes
+	MinCompressSize int `yaml:"min_compress_size" json:"min_compress_size"`
+
+	// Serves HTTPS, negotiating HTTP/2 with ALPN, when both are set
+	TLSCertFile string `yaml:"tls_cert_file" json:"tls_cert_file"`
+	TLSKeyFile  string `yaml:"tls_key_file" json:"tls_key_file"`
+
+	// HTTP/2 is served over TLS and as cleartext h2c unless disabled
+	DisableHTTP2 bool `yaml:"disable_http2" json:"disable_http2"`
+}
+
+// defaultMinCompressSize is the smallest response worth compressing
+const defaultMinCompressSize = 1024
+
+// Encoder creates a compressing writer for a Content-Encoding
+type Encoder func(w io.Writer) (io.WriteCloser, error)
+
+// encoding is a registered Content-Encoding and its server preference
+type encoding struct {
+	name       string
+	preference int
+	encoder    Encoder
+}
+
+var (
+	encodings = []encoding{
+		{name: "gzip", preference: 10, encoder: newGzipWriter},
+		{name: "br", preference: 20, encoder: newBrotliWriter},
+	}
+	encodingsMu sync.RWMutex
+)
+
+// gzipWriters reuses gzip state, which is expensive to allocate
+var gzipWriters = sync.Pool{New: func() interface{} {
+	writer, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+	return writer
+}}
+
+// pooledGzipWriter returns its gzip writer to the pool when closed
+type pooledGzipWriter struct {
+	*gzip.Writer
+}
+
+func (p pooledGzipWriter) Close() error {
+	err := p.Writer.Close()
+	gzipWriters.Put(p.Writer)
+	return err
+}
+
+func newGzipWriter(w io.Writer) (io.WriteCloser, error) {
+	writer := gzipWriters.Get().(*gzip.Writer)
+	writer.Reset(w)
+	return pooledGzipWriter{writer}, nil
+}
+
+// brotliLevel trades ratio for speed on responses compressed as they are
+// served; at 4 brotli is about as fast as gzip and still smaller
+const brotliLevel = 4
+
+// brotliWriters reuses brotli state like gzipWriters
+var brotliWriters = sync.Pool{New: func() interface{} {
+	return brotli.NewWriterLevel(nil, brotliLevel)
+}}
+
+// pooledBrotliWriter returns its brotli writer to the pool when closed
+type pooledBrotliWriter struct {
+	*brotli.Writer
+}
+
+func (p pooledBrotliWriter) Close() error {
+	err := p.Writer.Close()
+	brotliWriters.Put(p.Writer)
+	return err
+}
+
+func newBrotliWriter(w io.Writer) (io.WriteCloser, error) {
+	writer := brotliWriters.Get().(*brotli.Writer)
+	writer.Reset(w)
+	return pooledBrotliWriter{writer}, nil
+}
+
+// RegisterEncoding adds a Content-Encoding, such as "zstd" backed by a
+// compression library, or replaces a built-in one; higher preferences win
+// when a client accepts several equally. gzip is built in with preference
+// 10 and brotli ("br") with 20.
+func RegisterEncoding(name string, preference int, encoder Encoder) {
+	encodingsMu.Lock()
+	defer encodingsMu.Unlock()
+	for i := range encodings {
+		if encodings[i].name == name {
+			encodings[i] = encoding{name: name, preference: preference, encoder: encoder}
+			return
+		}
+	}
+	encodings = append(encodings, encoding{name: name, preference: preference, encoder: encoder})
+}
+
+// Decoder reads a request body sent with a Content-Encoding
+type Decoder func(r io.Reader) (io.ReadCloser, error)
+
+var (
+	decoders   = map[string]Decoder{"gzip": newGzipReader, "snappy": newSnappyReader}
+	decodersMu sync.RWMutex
+)
+
+func newGzipReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// newSnappyReader reads the framed snappy stream format agents upload with
+func newSnappyReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(snappy.NewReader(r)), nil
+}
+
+// RegisterDecoding accepts request bodies in a Content-Encoding, such as
+// "zstd" backed by a compression library. gzip and snappy are built in.
+func RegisterDecoding(name string, decoder Decoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[strings.ToLower(name)] = decoder
+}
+
+// DecompressionMiddleware decodes request bodies sent with a registered
+// Content-Encoding, answering 415 for any other
+func DecompressionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+		if name == "" || name == "identity" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		decodersMu.RLock()
+		decoder, ok := decoders[name]
+		decodersMu.RUnlock()
+		if !ok {
+			http.Error(w, fmt.Sprintf("unsupported Content-Encoding %q", name), http.StatusUnsupportedMediaType)
+			return
+		}
+		decoded, err := decoder(r.Body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to decode %s body: %v", name, err), http.StatusBadRequest)
+			return
+		}
+		defer decoded.Close()
+
+		r.Body = decoded
+		r.Header.Del("Content-Encoding")
+		r.ContentLength = -1
+		next.ServeHTTP(w, r)
+	})
+}
+
+// negotiateEncoding picks the encoding for an Accept-Encoding header by the
+// client's q-values, then the server's preference; ok is false for none
+func negotiateEncoding(header string) (encoding, bool) {
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		accepted[name] = quality
+	}
+
+	encodingsMu.RLock()
+	candidates := append([]encoding(nil), encodings...)
+	encodingsMu.RUnlock()
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].preference > candidates[j].preference })
+
+	var best encoding
+	bestQuality := 0.0
+	for _, candidate := range candidates {
+		quality, listed := accepted[candidate.name]
+		if !listed {
+			quality, listed = accepted["*"]
+		}
+		if listed && quality > bestQuality {
+			best, bestQuality = candidate, quality
+		}
+	}
+	return best, bestQuality > 0
+}
+
+// CompressionMiddleware compresses responses of at least minSize bytes with
+// the best encoding the client accepts. WebSocket upgrades, responses that
+// are already encoded and compressed media types pass through unchanged.
+func CompressionMiddleware(next http.Handler, minSize int) http.Handler {
+	if minSize <= 0 {
+		minSize = defaultMinCompressSize
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		chosen, ok := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if !ok || r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		writer := &compressingWriter{ResponseWriter: w, encoding: chosen, minSize: minSize}
+		defer writer.finish()
+		next.ServeHTTP(writer, r)
+	})
+}
+
+// compressingWriter buffers the start of a response until it knows whether
+// compressing it is worthwhile
+type compressingWriter struct {
+	http.ResponseWriter
+	encoding   encoding
+	minSize    int
+	status     int
+	buffer     []byte
+	compressor io.WriteCloser
+	decided    bool
+}
+
+func (c *compressingWriter) WriteHeader(status int) {
+	if c.status == 0 {
+		c.status = status
+	}
+}
+
+func (c *compressingWriter) Write(data []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	if c.decided {
+		if c.compressor != nil {
+			return c.compressor.Write(data)
+		}
+		return c.ResponseWriter.Write(data)
+	}
+
+	c.buffer = append(c.buffer, data...)
+	if len(c.buffer) >= c.minSize {
+		if err := c.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// decide sends the headers, compressing when large enough and suitable, and
+// flushes the buffered start of the body
+func (c *compressingWriter) decide(largeEnough bool) error {
+	c.decided = true
+	header := c.Header()
+	if largeEnough && c.compressible() {
+		compressor, err := c.encoding.encoder(c.ResponseWriter)
+		if err == nil {
+			c.compressor = compressor
+			header.Set("Content-Encoding", c.encoding.name)
+			header.Del("Content-Length")
+			// The representation differs from the uncompressed one
+			if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+				header.Set("ETag", "W/"+etag)
+			}
+		}
+	}
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	c.ResponseWriter.WriteHeader(c.status)
+
+	buffered := c.buffer
+	c.buffer = nil
+	if len(buffered) == 0 {
+		return nil
+	}
+	var err error
+	if c.compressor != nil {
+		_, err = c.compressor.Write(buffered)
+	} else {
+		_, err = c.ResponseWriter.Write(buffered)
+	}
+	return err
+}
+
+// compressible reports whether the response may be compressed
+func (c *compressingWriter) compressible() bool {
+	if c.status == http.StatusNoContent || c.status == http.StatusNotModified || c.status < 200 {
+		return false
+	}
+	header := c.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(c.buffer)
+	}
+	for _, prefix := range []string{"image/", "video/", "audio/", "application/gzip", "application/zip", "application/octet-stream"} {
+		if strings.HasPrefix(contentType, prefix) && contentType != "image/svg+xml" {
+			return false
+		}
+	}
+	return true
+}
+
+// finish sends a response smaller than minSize and closes the compressor
+func (c *compressingWriter) finish() {
+	if !c.decided {
+		if c.status == 0 && len(c.buffer) == 0 {
+			return
+		}
+		c.decide(false)
+	}
+	if c.compressor != nil {
+		c.compressor.Close()
+	}
+}
+
+// Flush sends buffered data, compressing it if the response is compressed
+func (c *compressingWriter) Flush() {
+	if !c.decided {
+		c.decide(len(c.buffer) >= c.minSize)
+	}
+	if flusher, ok := c.compressor.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hands over the connection of a response that was not started
+func (c *compressingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := c.ResponseWriter.(http.Hijacker)
+	if !ok || c.decided {
+		return nil, nil, fmt.Errorf("connection cannot be hijacked")
+	}
+	c.decided = true
+	return hijacker.Hijack()
+}
+
+// newHTTPServer wraps a handler with request decoding, and compression and
+// cleartext HTTP/2 as configured
+func newHTTPServer(server *http.Server, config HTTPServerConfig) *http.Server {
+	handler := DecompressionMiddleware(server.Handler)
+	if !config.DisableCompression {
+		handler = CompressionMiddleware(handler, config.MinCompressSize)
+	}
+	if config.DisableHTTP2 {
+		// A non-nil empty map turns off HTTP/2 over TLS
+		server.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	} else if config.TLSCertFile == "" {
+		handler = h2c.NewHandler(handler, &http2.Server{})
+	}
+	server.Handler = handler
+	return server
+}
+
+// listenAndServe serves HTTPS when a certificate is configured
+func listenAndServe(server *http.Server, config HTTPServerConfig) error {
+	if config.TLSCertFile != "" && config.TLSKeyFile != "" {
+		return server.ListenAndServeTLS(config.TLSCertFile, config.TLSKeyFile)
+	}
+	return server.ListenAndServe()
+}
+
+// validate adds server errors under a field prefix
+func (c HTTPServerConfig) validate(prefix string, errs *ConfigErrors) {
+	if c.MinCompressSize < 0 {
+		errs.add(prefix+".min_compress_size", "must not be negative")
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs.add(prefix+".tls_cert_file", "must be set together with tls_key_file")
+	}
+}
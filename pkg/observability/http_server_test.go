@@ -0,0 +1,164 @@
+package observability
+
+import (
+	"compress/gzip"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/golang/snappy"
+	"golang.org/x/net/http2"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	for header, expected := range map[string]string{
+		"gzip, deflate, br":    "br",
+		"gzip;q=1.0, br;q=0.5": "gzip",
+		"gzip":                 "gzip",
+		"*":                    "br",
+		"br;q=0, gzip;q=0":     "",
+		"identity":             "",
+		"":                     "",
+	} {
+		chosen, ok := negotiateEncoding(header)
+		if !ok {
+			chosen.name = ""
+		}
+		if chosen.name != expected {
+			t.Errorf("%q: expected %q, got %q", header, expected, chosen.name)
+		}
+	}
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	large := strings.Repeat(`{"gpu":"gpu-0","utilization":87.5},`, 100)
+	handler := CompressionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/large":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("ETag", `"abc"`)
+			io.WriteString(w, large)
+		case "/small":
+			io.WriteString(w, "ok")
+		case "/png":
+			w.Header().Set("Content-Type", "image/png")
+			io.WriteString(w, large)
+		}
+	}), 0)
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest("GET", path, nil)
+		request.Header.Set("Accept-Encoding", acceptEncoding)
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		return response
+	}
+
+	compressed := get("/large", "gzip")
+	if compressed.Header().Get("Content-Encoding") != "gzip" || compressed.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("Expected a gzip response varying on Accept-Encoding, got %v", compressed.Header())
+	}
+	if compressed.Header().Get("ETag") != `W/"abc"` {
+		t.Errorf("Expected the ETag weakened, got %q", compressed.Header().Get("ETag"))
+	}
+	reader, err := gzip.NewReader(compressed.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(reader)
+	if string(body) != large || compressed.Body.Len() >= len(large) {
+		t.Errorf("Expected the body compressed losslessly, got %d bytes for %d", compressed.Body.Len(), len(large))
+	}
+
+	// Browsers accept both and get brotli
+	for i := 0; i < 2; i++ {
+		brotlied := get("/large", "gzip, deflate, br")
+		if brotlied.Header().Get("Content-Encoding") != "br" {
+			t.Fatalf("Expected a brotli response, got %v", brotlied.Header())
+		}
+		body, err := io.ReadAll(brotli.NewReader(brotlied.Body))
+		if err != nil || string(body) != large || brotlied.Body.Len() >= len(large) {
+			t.Errorf("Expected the body compressed losslessly with brotli, got %d bytes for %d, %v", brotlied.Body.Len(), len(large), err)
+		}
+	}
+
+	for name, response := range map[string]*httptest.ResponseRecorder{
+		"small":        get("/small", "gzip"),
+		"png":          get("/png", "gzip"),
+		"not accepted": get("/large", ""),
+	} {
+		if response.Header().Get("Content-Encoding") != "" {
+			t.Errorf("%s: expected an uncompressed response, got %v", name, response.Header())
+		}
+	}
+	if small := get("/small", "gzip"); small.Body.String() != "ok" {
+		t.Errorf("Expected the small body sent as is, got %q", small.Body.String())
+	}
+}
+
+func TestDecompressionMiddleware(t *testing.T) {
+	handler := DecompressionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	}))
+	post := func(contentEncoding string, body io.Reader) *httptest.ResponseRecorder {
+		request := httptest.NewRequest("POST", "/api/v1/agents/metrics", body)
+		request.Header.Set("Content-Encoding", contentEncoding)
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		return response
+	}
+
+	var compressed strings.Builder
+	writer := gzip.NewWriter(&compressed)
+	io.WriteString(writer, `{"node":"node-a"}`)
+	writer.Close()
+	if response := post("gzip", strings.NewReader(compressed.String())); response.Body.String() != `{"node":"node-a"}` {
+		t.Errorf("Expected the gzip body decoded, got %q", response.Body.String())
+	}
+	var framed strings.Builder
+	snappyWriter := snappy.NewBufferedWriter(&framed)
+	io.WriteString(snappyWriter, `{"node":"node-b"}`)
+	snappyWriter.Close()
+	if response := post("snappy", strings.NewReader(framed.String())); response.Body.String() != `{"node":"node-b"}` {
+		t.Errorf("Expected the snappy body decoded, got %q", response.Body.String())
+	}
+	if response := post("", strings.NewReader("plain")); response.Body.String() != "plain" {
+		t.Errorf("Expected an unencoded body passed through, got %q", response.Body.String())
+	}
+	if response := post("gzip", strings.NewReader("plain")); response.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a corrupt gzip body, got %d", response.Code)
+	}
+	if response := post("zstd", strings.NewReader("plain")); response.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected 415 for an unregistered encoding, got %d", response.Code)
+	}
+}
+
+func TestDashboardServesCleartextHTTP2(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{})
+	server := httptest.NewServer(dashboard.server.Handler)
+	defer server.Close()
+
+	// Cleartext HTTP/2 with prior knowledge, as h2c clients connect
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	response, err := client.Get(server.URL + "/api/v1/system/overview")
+	if err != nil {
+		t.Fatalf("HTTP/2 request failed: %v", err)
+	}
+	defer response.Body.Close()
+	if response.ProtoMajor != 2 || response.StatusCode != http.StatusOK {
+		t.Errorf("Expected an HTTP/2 200 response, got %s %d", response.Proto, response.StatusCode)
+	}
+
+	if err := (WebDashboardConfig{HTTP: HTTPServerConfig{TLSCertFile: "cert.pem"}}).Validate(); err == nil || !strings.Contains(err.Error(), "tls_key_file") {
+		t.Errorf("Expected a certificate without a key rejected, got %v", err)
+	}
+}
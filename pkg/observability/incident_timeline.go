@@ -0,0 +1,362 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// Timeline export formats
+const (
+	TimelineFormatJSON     = "json"
+	TimelineFormatMarkdown = "markdown"
+)
+
+// Timeline entry kinds
+const (
+	TimelineAlert    = "alert"
+	TimelineResolved = "resolved"
+	TimelineEvent    = "event"
+	TimelineMetrics  = "metrics"
+	TimelineDecision = "decision"
+)
+
+// TimelineConfig controls how much context surrounds an incident's timeline
+type TimelineConfig struct {
+	Lead time.Duration `yaml:"lead" json:"lead"` // Context included before the incident started
+	Tail time.Duration `yaml:"tail" json:"tail"` // Context included after it resolved
+}
+
+// DefaultTimelineConfig returns the default timeline configuration
+func DefaultTimelineConfig() TimelineConfig {
+	return TimelineConfig{
+		Lead: 5 * time.Minute,
+		Tail: 5 * time.Minute,
+	}
+}
+
+// TimelineEntry is one item on an incident timeline
+type TimelineEntry struct {
+	Timestamp time.Time              `json:"timestamp"`
+	Kind      string                 `json:"kind"`
+	Source    string                 `json:"source"`
+	Severity  string                 `json:"severity,omitempty"`
+	GPUID     string                 `json:"gpu_id,omitempty"`
+	Summary   string                 `json:"summary"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// IncidentTimeline combines an incident's alerts with related events, metric
+// snapshots and scheduler decisions, ordered by time
+type IncidentTimeline struct {
+	Incident    Incident        `json:"incident"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Start       time.Time       `json:"start"`
+	End         time.Time       `json:"end"`
+	Entries     []TimelineEntry `json:"entries"`
+}
+
+// IncidentTimelineBuilder assembles incident timelines from the alert
+// grouper, monitoring service and (optionally) the scheduler
+type IncidentTimelineBuilder struct {
+	config     TimelineConfig
+	monitoring *MonitoringService
+	grouper    *AlertGrouper
+	scheduler  *gpu.Scheduler
+}
+
+// NewIncidentTimelineBuilder creates a timeline builder; scheduler may be nil
+func NewIncidentTimelineBuilder(config TimelineConfig, monitoring *MonitoringService, grouper *AlertGrouper, scheduler *gpu.Scheduler) *IncidentTimelineBuilder {
+	return &IncidentTimelineBuilder{
+		config:     config,
+		monitoring: monitoring,
+		grouper:    grouper,
+		scheduler:  scheduler,
+	}
+}
+
+// Build returns the timeline of an open or recently resolved incident
+func (b *IncidentTimelineBuilder) Build(incidentID string) (*IncidentTimeline, error) {
+	if b.grouper == nil {
+		return nil, fmt.Errorf("alert grouping not configured")
+	}
+	incident, exists := b.grouper.GetIncident(incidentID)
+	if !exists {
+		return nil, fmt.Errorf("incident %s not found", incidentID)
+	}
+
+	now := time.Now()
+	end := now
+	if incident.ResolvedAt != nil {
+		end = incident.ResolvedAt.Add(b.config.Tail)
+		if end.After(now) {
+			end = now
+		}
+	}
+	timeline := &IncidentTimeline{
+		Incident:    incident,
+		GeneratedAt: now,
+		Start:       incident.StartedAt.Add(-b.config.Lead),
+		End:         end,
+		Entries:     make([]TimelineEntry, 0),
+	}
+
+	gpuIDs := make(map[string]bool)
+	for _, child := range incident.Alerts {
+		gpuIDs[child.GPUID] = true
+		timeline.Entries = append(timeline.Entries, alertEntries(child)...)
+	}
+
+	timeline.Entries = append(timeline.Entries, b.eventEntries(incident.ID, gpuIDs, timeline.Start, timeline.End)...)
+	timeline.Entries = append(timeline.Entries, b.metricEntries(incident, gpuIDs, timeline.Start, timeline.End)...)
+	timeline.Entries = append(timeline.Entries, b.decisionEntries(gpuIDs, timeline.Start, timeline.End)...)
+
+	sort.SliceStable(timeline.Entries, func(i, j int) bool {
+		return timeline.Entries[i].Timestamp.Before(timeline.Entries[j].Timestamp)
+	})
+	return timeline, nil
+}
+
+// alertEntries returns the firing and resolution entries of a child alert
+func alertEntries(child IncidentAlert) []TimelineEntry {
+	entries := []TimelineEntry{{
+		Timestamp: child.Alert.Timestamp,
+		Kind:      TimelineAlert,
+		Source:    "gpu_alert",
+		Severity:  child.Alert.Severity,
+		GPUID:     child.GPUID,
+		Summary:   child.Alert.Message,
+		Details: map[string]interface{}{
+			"alert_type": child.Alert.Type,
+			"value":      child.Alert.Value,
+			"threshold":  child.Alert.Threshold,
+		},
+	}}
+
+	// One-shot alerts are resolved as soon as they are recorded
+	if child.ResolvedAt != nil && child.ResolvedAt.After(child.Alert.Timestamp) {
+		entries = append(entries, TimelineEntry{
+			Timestamp: *child.ResolvedAt,
+			Kind:      TimelineResolved,
+			Source:    "gpu_alert",
+			Severity:  "info",
+			GPUID:     child.GPUID,
+			Summary:   fmt.Sprintf("GPU %s %s alert resolved", child.GPUID, child.Alert.Type),
+		})
+	}
+	return entries
+}
+
+// eventEntries returns events that reference the incident or its GPUs, plus
+// warning and critical events without a GPU. Alert events are skipped since
+// the incident's child alerts already cover them.
+func (b *IncidentTimelineBuilder) eventEntries(incidentID string, gpuIDs map[string]bool, start, end time.Time) []TimelineEntry {
+	entries := make([]TimelineEntry, 0)
+	if b.monitoring == nil {
+		return entries
+	}
+
+	for _, event := range b.monitoring.GetEvents(start, end, "") {
+		if event.Type == "gpu_alert" || event.Type == "gpu_alert_resolved" {
+			continue
+		}
+		gpuID, _ := event.Metadata["gpu_id"].(string)
+		related := event.Metadata["incident_id"] == incidentID || gpuIDs[gpuID]
+		if !related && !(gpuID == "" && severityRank(event.Severity) >= severityRank("warning")) {
+			continue
+		}
+
+		entries = append(entries, TimelineEntry{
+			Timestamp: event.Timestamp,
+			Kind:      TimelineEvent,
+			Source:    event.Source,
+			Severity:  event.Severity,
+			GPUID:     gpuID,
+			Summary:   event.Message,
+			Details:   map[string]interface{}{"type": event.Type},
+		})
+	}
+	return entries
+}
+
+// metricEntries snapshots each affected GPU's metrics as the incident started
+// and at the end of the timeline
+func (b *IncidentTimelineBuilder) metricEntries(incident Incident, gpuIDs map[string]bool, start, end time.Time) []TimelineEntry {
+	entries := make([]TimelineEntry, 0)
+	if b.monitoring == nil {
+		return entries
+	}
+
+	metrics := b.monitoring.GetMetrics(start, end.Add(time.Nanosecond), "")
+	ids := make([]string, 0, len(gpuIDs))
+	for gpuID := range gpuIDs {
+		ids = append(ids, gpuID)
+	}
+	sort.Strings(ids)
+
+	for _, gpuID := range ids {
+		points := []struct {
+			label string
+			at    time.Time
+		}{
+			{"incident start", incident.StartedAt},
+			{"incident end", end},
+		}
+		for _, point := range points {
+			values, at := metricSnapshot(metrics, gpuID, point.at)
+			if len(values) == 0 {
+				continue
+			}
+			details := make(map[string]interface{}, len(values))
+			for name, value := range values {
+				details[name] = value
+			}
+			entries = append(entries, TimelineEntry{
+				Timestamp: at,
+				Kind:      TimelineMetrics,
+				Source:    "monitoring",
+				GPUID:     gpuID,
+				Summary:   fmt.Sprintf("GPU %s metrics at %s", gpuID, point.label),
+				Details:   details,
+			})
+		}
+	}
+	return entries
+}
+
+// metricSnapshot returns the latest value of each of a GPU's metrics at or
+// before a time, falling back to the earliest sample after it
+func metricSnapshot(metrics []Metric, gpuID string, at time.Time) (map[string]float64, time.Time) {
+	values := make(map[string]float64)
+	var latest time.Time
+	for _, metric := range metrics {
+		if metric.Labels["gpu_id"] != gpuID || metric.Timestamp.After(at) {
+			continue
+		}
+		values[metric.Name] = metric.Value
+		if metric.Timestamp.After(latest) {
+			latest = metric.Timestamp
+		}
+	}
+	if len(values) > 0 {
+		return values, latest
+	}
+
+	var first time.Time
+	for _, metric := range metrics {
+		if metric.Labels["gpu_id"] != gpuID {
+			continue
+		}
+		if first.IsZero() {
+			first = metric.Timestamp
+		}
+		if metric.Timestamp.Equal(first) {
+			values[metric.Name] = metric.Value
+		}
+	}
+	return values, first
+}
+
+// decisionEntries returns scheduler decisions on the affected GPUs
+func (b *IncidentTimelineBuilder) decisionEntries(gpuIDs map[string]bool, start, end time.Time) []TimelineEntry {
+	entries := make([]TimelineEntry, 0)
+	if b.scheduler == nil {
+		return entries
+	}
+
+	for _, decision := range b.scheduler.GetDecisions(start) {
+		if decision.Timestamp.After(end) || !gpuIDs[decision.GPUID] {
+			continue
+		}
+		entries = append(entries, TimelineEntry{
+			Timestamp: decision.Timestamp,
+			Kind:      TimelineDecision,
+			Source:    "gpu_scheduler",
+			GPUID:     decision.GPUID,
+			Summary:   fmt.Sprintf("Workload %s %s on GPU %s (%s)", decision.WorkloadID, decision.Action, decision.GPUID, decision.Reason),
+			Details: map[string]interface{}{
+				"workload_id": decision.WorkloadID,
+				"strategy":    string(decision.Strategy),
+			},
+		})
+	}
+	return entries
+}
+
+// Export renders the timeline as JSON or as a Markdown postmortem draft
+func (t *IncidentTimeline) Export(format string) ([]byte, error) {
+	switch format {
+	case TimelineFormatJSON:
+		return json.MarshalIndent(t, "", "  ")
+	case TimelineFormatMarkdown:
+		return []byte(t.Markdown()), nil
+	default:
+		return nil, fmt.Errorf("unsupported timeline format %q (must be %q or %q)", format, TimelineFormatJSON, TimelineFormatMarkdown)
+	}
+}
+
+// Markdown renders the timeline as a postmortem draft
+func (t *IncidentTimeline) Markdown() string {
+	incident := t.Incident
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Incident %s: %s\n\n", incident.ID, incident.Title)
+	fmt.Fprintf(&b, "- **Severity:** %s\n", incident.Severity)
+	fmt.Fprintf(&b, "- **Status:** %s\n", incident.Status)
+	fmt.Fprintf(&b, "- **Started:** %s\n", incident.StartedAt.UTC().Format(time.RFC3339))
+	if incident.ResolvedAt != nil {
+		fmt.Fprintf(&b, "- **Resolved:** %s\n", incident.ResolvedAt.UTC().Format(time.RFC3339))
+		fmt.Fprintf(&b, "- **Duration:** %s\n", incident.ResolvedAt.Sub(incident.StartedAt).Round(time.Second))
+	}
+	fmt.Fprintf(&b, "- **Alerts:** %d across %d GPUs\n", incident.AlertCount, incident.GPUCount)
+	if len(incident.Labels) > 0 {
+		fmt.Fprintf(&b, "- **Grouped by:** %s\n", strings.ReplaceAll(incident.Key, ",", ", "))
+	}
+
+	b.WriteString("\n## Timeline\n\n")
+	b.WriteString("| Time (UTC) | Kind | Source | Severity | GPU | Summary |\n")
+	b.WriteString("|---|---|---|---|---|---|\n")
+	for _, entry := range t.Entries {
+		summary := entry.Summary
+		if entry.Kind == TimelineMetrics {
+			summary += ": " + formatMetricDetails(entry.Details)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+			entry.Timestamp.UTC().Format("2006-01-02 15:04:05"),
+			entry.Kind,
+			markdownCell(entry.Source),
+			entry.Severity,
+			markdownCell(entry.GPUID),
+			markdownCell(summary))
+	}
+
+	b.WriteString("\n## Impact\n\n_TBD_\n")
+	b.WriteString("\n## Root Cause\n\n_TBD_\n")
+	b.WriteString("\n## Action Items\n\n- [ ] _TBD_\n")
+	return b.String()
+}
+
+// formatMetricDetails renders metric values sorted by name
+func formatMetricDetails(details map[string]interface{}) string {
+	names := make([]string, 0, len(details))
+	for name := range details {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%.1f", name, details[name]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// markdownCell escapes text for a Markdown table cell
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(text, "\n", " ")
+}
@@ -0,0 +1,100 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestIncidentTimelineCombinesSources(t *testing.T) {
+	monitor := NewMonitoringService(1000)
+	integration := NewGPUMetricsIntegration(monitor, nil)
+	grouper, err := NewAlertGrouper(DefaultAlertGroupingConfig())
+	if err != nil {
+		t.Fatalf("NewAlertGrouper failed: %v", err)
+	}
+	integration.SetAlertGrouper(grouper)
+
+	scheduler := gpu.NewScheduler(gpu.StrategyLeastUtilized)
+	scheduler.RegisterGPU(&gpu.GPU{ID: "node-a/gpu-0", MemoryTotal: 40000, Available: true})
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "train-1", MemoryRequired: 20000})
+	if err := scheduler.Schedule(); err != nil {
+		t.Fatalf("Failed to schedule: %v", err)
+	}
+
+	sample := temperatureSample(time.Now(), 90)
+	sample.GPUID = "node-a/gpu-0"
+	integration.processGPUMetrics(sample)
+	monitor.RecordEvent(Event{Type: "node_maintenance", Severity: "warning", Message: "Fan | failure reported", Source: "operator", Timestamp: time.Now()})
+	monitor.RecordEvent(Event{Type: "unrelated", Severity: "info", Message: "noise", Source: "operator", Timestamp: time.Now()})
+
+	sample.Temperature = 60
+	sample.Timestamp = time.Now()
+	integration.processGPUMetrics(sample)
+
+	incidents := grouper.Incidents(true)
+	if len(incidents) != 1 || incidents[0].Status != IncidentResolved {
+		t.Fatalf("Expected one resolved incident, got %+v", incidents)
+	}
+
+	builder := NewIncidentTimelineBuilder(DefaultTimelineConfig(), monitor, grouper, scheduler)
+	timeline, err := builder.Build(incidents[0].ID)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	kinds := make(map[string]int)
+	for i, entry := range timeline.Entries {
+		kinds[entry.Kind]++
+		if i > 0 && entry.Timestamp.Before(timeline.Entries[i-1].Timestamp) {
+			t.Errorf("Entries out of order at %d", i)
+		}
+		if entry.Summary == "noise" {
+			t.Error("Expected unrelated info events to be excluded")
+		}
+	}
+	for _, kind := range []string{TimelineAlert, TimelineResolved, TimelineEvent, TimelineMetrics, TimelineDecision} {
+		if kinds[kind] == 0 {
+			t.Errorf("Expected a %s entry, got %v", kind, kinds)
+		}
+	}
+
+	markdown, err := timeline.Export(TimelineFormatMarkdown)
+	if err != nil {
+		t.Fatalf("Markdown export failed: %v", err)
+	}
+	for _, want := range []string{"# Incident " + incidents[0].ID, "- **Status:** resolved", "Fan \\| failure reported", "train-1", "## Root Cause"} {
+		if !strings.Contains(string(markdown), want) {
+			t.Errorf("Expected Markdown to contain %q:\n%s", want, markdown)
+		}
+	}
+
+	data, err := timeline.Export(TimelineFormatJSON)
+	if err != nil {
+		t.Fatalf("JSON export failed: %v", err)
+	}
+	var decoded IncidentTimeline
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded.Entries) != len(timeline.Entries) {
+		t.Errorf("JSON export did not round-trip: %v", err)
+	}
+	if _, err := timeline.Export("pdf"); err == nil {
+		t.Error("Expected an unsupported format to be rejected")
+	}
+	if _, err := builder.Build("incident-missing"); err == nil {
+		t.Error("Expected an unknown incident to be rejected")
+	}
+
+	dashboard := NewWebDashboard(monitor, nil, nil, WebDashboardConfig{Port: 0})
+	dashboard.SetAlertGrouper(grouper)
+	response := serveDashboard(dashboard, http.MethodGet, "/api/v1/incidents/"+incidents[0].ID+"/timeline?format=markdown", "")
+	if response.Code != http.StatusOK || !strings.HasPrefix(response.Header().Get("Content-Type"), "text/markdown") {
+		t.Errorf("Expected a Markdown download, got %d %s", response.Code, response.Header().Get("Content-Type"))
+	}
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/incidents/incident-missing/timeline", ""); response.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown incident, got %d", response.Code)
+	}
+}
@@ -0,0 +1,278 @@
+package observability
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// WebhookConfig is an endpoint notified of workload lifecycle events
+type WebhookConfig struct {
+	Name     string            `yaml:"name" json:"name"`
+	URL      string            `yaml:"url" json:"url"`
+	Secret   string            `yaml:"secret" json:"secret" secret:"true"` // Signs payloads with HMAC-SHA256 in X-AgentaFlow-Signature
+	Events   []string          `yaml:"events" json:"events"`               // Lifecycle event types to send; all when empty
+	Selector map[string]string `yaml:"selector" json:"selector"`           // Workload labels required, e.g. the CI pipeline's
+}
+
+// LifecycleWebhookConfig configures lifecycle webhook delivery
+type LifecycleWebhookConfig struct {
+	Webhooks   []WebhookConfig `yaml:"webhooks" json:"webhooks"`
+	Timeout    time.Duration   `yaml:"timeout" json:"timeout"`
+	MaxRetries int             `yaml:"max_retries" json:"max_retries"` // Retries after network errors, 429 and 5xx responses
+	RetryDelay time.Duration   `yaml:"retry_delay" json:"retry_delay"` // Doubles after each retry
+}
+
+// DefaultLifecycleWebhookConfig returns delivery settings without webhooks
+func DefaultLifecycleWebhookConfig() LifecycleWebhookConfig {
+	return LifecycleWebhookConfig{
+		Timeout:    10 * time.Second,
+		MaxRetries: 3,
+		RetryDelay: time.Second,
+	}
+}
+
+// lifecycleEventTypes are the event types webhooks may subscribe to
+var lifecycleEventTypes = map[string]bool{
+	gpu.LifecycleQueued:    true,
+	gpu.LifecycleScheduled: true,
+	gpu.LifecycleStarted:   true,
+	gpu.LifecyclePreempted: true,
+	gpu.LifecycleCompleted: true,
+	gpu.LifecycleFailed:    true,
+}
+
+// LifecycleWebhooks posts workload lifecycle events to webhooks, e.g. CI
+// systems waiting for a job to finish or chat bots announcing failures
+type LifecycleWebhooks struct {
+	config    LifecycleWebhookConfig
+	sender    webhookSender
+	delivered int
+	failed    int
+	lastError string
+	mu        sync.Mutex
+}
+
+// NewLifecycleWebhooks validates the webhooks; zero delivery settings use the defaults
+func NewLifecycleWebhooks(config LifecycleWebhookConfig) (*LifecycleWebhooks, error) {
+	defaults := DefaultLifecycleWebhookConfig()
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = defaults.RetryDelay
+	}
+	for i, webhook := range config.Webhooks {
+		parsed, err := url.Parse(webhook.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("webhook %d: invalid URL %q", i, webhook.URL)
+		}
+		for _, event := range webhook.Events {
+			if !lifecycleEventTypes[event] {
+				return nil, fmt.Errorf("webhook %d: unknown lifecycle event %q", i, event)
+			}
+		}
+	}
+
+	return &LifecycleWebhooks{
+		config: config,
+		sender: newWebhookSender(config.Timeout, config.MaxRetries, config.RetryDelay),
+	}, nil
+}
+
+// Handle delivers an event to every webhook subscribed to it; register it
+// with Scheduler.OnLifecycleEvent
+func (lw *LifecycleWebhooks) Handle(event gpu.LifecycleEvent) {
+	var payload []byte
+	for _, webhook := range lw.config.Webhooks {
+		if !webhook.wants(event) {
+			continue
+		}
+		if payload == nil {
+			data, err := json.Marshal(event)
+			if err != nil {
+				lw.record(fmt.Errorf("failed to encode %s event: %v", event.Type, err))
+				return
+			}
+			payload = data
+		}
+		lw.record(lw.deliver(webhook, event, payload))
+	}
+}
+
+// wants reports whether a webhook subscribes to an event
+func (webhook WebhookConfig) wants(event gpu.LifecycleEvent) bool {
+	if len(webhook.Events) > 0 {
+		subscribed := false
+		for _, eventType := range webhook.Events {
+			subscribed = subscribed || eventType == event.Type
+		}
+		if !subscribed {
+			return false
+		}
+	}
+	return gpu.MatchesSelector(event.Labels, webhook.Selector)
+}
+
+// deliver posts an event to a webhook
+func (lw *LifecycleWebhooks) deliver(webhook WebhookConfig, event gpu.LifecycleEvent, payload []byte) error {
+	delivery := fmt.Sprintf("%s-%s-%d", event.WorkloadID, event.Type, event.Timestamp.UnixNano())
+	if err := lw.sender.send(webhook.URL, webhook.Secret, event.Type, delivery, payload); err != nil {
+		return fmt.Errorf("webhook %s: %v", webhookName(webhook.Name, webhook.URL), err)
+	}
+	return nil
+}
+
+// webhookSender delivers signed payloads for lifecycle and capacity
+// webhooks, retrying network errors, 429 and 5xx responses with a delay that
+// doubles after each retry
+type webhookSender struct {
+	client     *http.Client
+	maxRetries int
+	retryDelay time.Duration
+	sleep      func(time.Duration) // Replaced in tests
+}
+
+// newWebhookSender returns a sender with a per-attempt timeout
+func newWebhookSender(timeout time.Duration, maxRetries int, retryDelay time.Duration) webhookSender {
+	return webhookSender{
+		client:     &http.Client{Timeout: timeout},
+		maxRetries: maxRetries,
+		retryDelay: retryDelay,
+		sleep:      time.Sleep,
+	}
+}
+
+// send posts a payload until it is accepted, fails permanently or runs out
+// of retries
+func (s webhookSender) send(url, secret, eventType, delivery string, payload []byte) error {
+	delay := s.retryDelay
+	var err error
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			s.sleep(delay)
+			delay *= 2
+		}
+
+		var retry bool
+		retry, err = postWebhook(s.client, url, secret, eventType, delivery, payload)
+		if err == nil || !retry {
+			break
+		}
+	}
+	return err
+}
+
+// webhookName names a webhook in errors, by its URL when it has no name
+func webhookName(name, url string) string {
+	if name == "" {
+		return url
+	}
+	return name
+}
+
+// postWebhook posts a signed JSON payload and reports whether a failure is
+// worth retrying
+func postWebhook(client *http.Client, url, secret, eventType, delivery string, payload []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-AgentaFlow-Event", eventType)
+	req.Header.Set("X-AgentaFlow-Delivery", delivery)
+	if secret != "" {
+		req.Header.Set("X-AgentaFlow-Signature", SignWebhookPayload(secret, payload))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("returned %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return false, nil
+}
+
+// SignWebhookPayload returns the X-AgentaFlow-Signature value receivers
+// compare against to verify a payload came from AgentaFlow
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// record counts a delivery outcome
+func (lw *LifecycleWebhooks) record(err error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if err != nil {
+		lw.failed++
+		lw.lastError = err.Error()
+		return
+	}
+	lw.delivered++
+}
+
+// GetStats returns delivery counts and the last failure
+func (lw *LifecycleWebhooks) GetStats() map[string]interface{} {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	return map[string]interface{}{
+		"webhooks":   len(lw.config.Webhooks),
+		"delivered":  lw.delivered,
+		"failed":     lw.failed,
+		"last_error": lw.lastError,
+	}
+}
+
+// RecordLifecycleEvent puts a workload lifecycle event on the event stream;
+// register it with Scheduler.OnLifecycleEvent
+func (ms *MonitoringService) RecordLifecycleEvent(event gpu.LifecycleEvent) {
+	severity := "info"
+	if event.Type == gpu.LifecycleFailed {
+		severity = "warning"
+	}
+	message := fmt.Sprintf("Workload %s %s", event.WorkloadID, event.Type)
+	if event.GPUID != "" {
+		message += " on GPU " + event.GPUID
+	}
+	if event.Reason != "" {
+		message += ": " + event.Reason
+	}
+
+	ms.RecordEvent(Event{
+		Type:     "workload_lifecycle",
+		Severity: severity,
+		Message:  message,
+		Source:   "gpu_scheduler",
+		Metadata: map[string]interface{}{
+			"lifecycle":   event.Type,
+			"workload_id": event.WorkloadID,
+			"name":        event.Name,
+			"gpu_id":      event.GPUID,
+			"node":        event.Node,
+			"pool":        event.Pool,
+			"reason":      event.Reason,
+		},
+	})
+}
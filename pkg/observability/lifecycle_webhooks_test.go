@@ -0,0 +1,140 @@
+package observability
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestLifecycleWebhooksDeliverSignedEvents(t *testing.T) {
+	var mu sync.Mutex
+	var received []gpu.LifecycleEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("X-AgentaFlow-Signature") != SignWebhookPayload("s3cret", body) {
+			t.Errorf("bad signature %q", r.Header.Get("X-AgentaFlow-Signature"))
+		}
+		var event gpu.LifecycleEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+		if r.Header.Get("X-AgentaFlow-Event") != event.Type || r.Header.Get("X-AgentaFlow-Delivery") == "" {
+			t.Errorf("headers = %v", r.Header)
+		}
+		mu.Lock()
+		received = append(received, event)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	webhooks, err := NewLifecycleWebhooks(LifecycleWebhookConfig{Webhooks: []WebhookConfig{{
+		Name:     "ci",
+		URL:      server.URL,
+		Secret:   "s3cret",
+		Events:   []string{gpu.LifecycleStarted, gpu.LifecycleCompleted},
+		Selector: map[string]string{"ci": "true"},
+	}}})
+	if err != nil {
+		t.Fatalf("NewLifecycleWebhooks: %v", err)
+	}
+
+	scheduler := gpu.NewScheduler(gpu.StrategyLeastUtilized)
+	scheduler.RegisterGPU(&gpu.GPU{ID: "gpu-0", Node: "node-a", MemoryTotal: 16384, Available: true})
+	done := make(chan struct{}, 10)
+	scheduler.OnLifecycleEvent(webhooks.Handle)
+	scheduler.OnLifecycleEvent(func(gpu.LifecycleEvent) { done <- struct{}{} })
+
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "build-1", MemoryRequired: 4096, Labels: map[string]string{"ci": "true"}})
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "adhoc", MemoryRequired: 4096})
+	scheduler.Schedule()
+	scheduler.CompleteWorkload("build-1")
+	// Both queued, then the CI workload takes the only GPU and completes
+	for i := 0; i < 5; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("only %d lifecycle events delivered", i)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 2 || received[0].Type != gpu.LifecycleStarted || received[1].Type != gpu.LifecycleCompleted {
+		t.Fatalf("received %+v, want started and completed for the CI workload", received)
+	}
+	if received[0].WorkloadID != "build-1" || received[0].GPUID != "gpu-0" || received[0].Node != "node-a" {
+		t.Errorf("started event = %+v", received[0])
+	}
+	if stats := webhooks.GetStats(); stats["delivered"] != 2 || stats["failed"] != 0 {
+		t.Errorf("stats = %v", stats)
+	}
+}
+
+func TestLifecycleWebhooksRetryTransientFailures(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(status)
+		}
+	}))
+	defer server.Close()
+
+	webhooks, err := NewLifecycleWebhooks(LifecycleWebhookConfig{
+		Webhooks:   []WebhookConfig{{URL: server.URL}},
+		MaxRetries: 3,
+	})
+	if err != nil {
+		t.Fatalf("NewLifecycleWebhooks: %v", err)
+	}
+	var delays []time.Duration
+	webhooks.sender.sleep = func(d time.Duration) { delays = append(delays, d) }
+
+	event := gpu.LifecycleEvent{Type: gpu.LifecycleFailed, WorkloadID: "w1", Timestamp: time.Now()}
+	webhooks.Handle(event)
+	if attempts != 3 || len(delays) != 2 || delays[1] != 2*delays[0] {
+		t.Errorf("attempts = %d, delays = %v, want 3 attempts with doubling delays", attempts, delays)
+	}
+
+	// Client errors are not retried
+	attempts, status = 0, http.StatusBadRequest
+	webhooks.Handle(event)
+	if attempts != 1 {
+		t.Errorf("attempts = %d after a 400, want 1", attempts)
+	}
+	if stats := webhooks.GetStats(); stats["delivered"] != 1 || stats["failed"] != 1 || stats["last_error"] == "" {
+		t.Errorf("stats = %v", stats)
+	}
+}
+
+func TestNewLifecycleWebhooksValidation(t *testing.T) {
+	for _, webhook := range []WebhookConfig{
+		{URL: "ftp://example.com/hook"},
+		{URL: "not a url"},
+		{URL: "https://example.com/hook", Events: []string{"exploded"}},
+	} {
+		if _, err := NewLifecycleWebhooks(LifecycleWebhookConfig{Webhooks: []WebhookConfig{webhook}}); err == nil {
+			t.Errorf("accepted %+v", webhook)
+		}
+	}
+}
+
+func TestRecordLifecycleEvent(t *testing.T) {
+	ms := NewMonitoringService(100)
+	ms.RecordLifecycleEvent(gpu.LifecycleEvent{Type: gpu.LifecycleFailed, WorkloadID: "w1", GPUID: "gpu-0"})
+
+	events := ms.GetEvents(time.Now().Add(-time.Minute), time.Now().Add(time.Minute), "warning")
+	if len(events) != 1 || events[0].Type != "workload_lifecycle" || events[0].Metadata["lifecycle"] != gpu.LifecycleFailed {
+		t.Errorf("events = %+v", events)
+	}
+}
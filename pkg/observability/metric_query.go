@@ -0,0 +1,180 @@
+package observability
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Metric query aggregations
+const (
+	AggregationAvg   = "avg"
+	AggregationSum   = "sum"
+	AggregationMin   = "min"
+	AggregationMax   = "max"
+	AggregationCount = "count"
+	AggregationLast  = "last"
+	AggregationP95   = "p95"
+)
+
+var aggregations = []string{AggregationAvg, AggregationSum, AggregationMin, AggregationMax, AggregationCount, AggregationLast, AggregationP95}
+
+// MetricQuery selects recorded metrics by name and labels and aggregates them
+// over a trailing window, optionally per step and per value of a label
+type MetricQuery struct {
+	Metric        string            `yaml:"metric" json:"metric"`
+	Labels        map[string]string `yaml:"labels" json:"labels,omitempty"` // Exact matches
+	Aggregation   string            `yaml:"aggregation" json:"aggregation"`
+	WindowSeconds int               `yaml:"window_seconds" json:"window_seconds"`
+	StepSeconds   int               `yaml:"step_seconds" json:"step_seconds,omitempty"` // 0 for a single value per series
+	GroupBy       string            `yaml:"group_by" json:"group_by,omitempty"`         // Label splitting the result into series
+}
+
+// maxQueryPoints bounds the points of each series a query returns
+const maxQueryPoints = 1000
+
+// Validate checks the query's metric, aggregation, window and step
+func (q MetricQuery) Validate() error {
+	if q.Metric == "" {
+		return fmt.Errorf("metric must not be empty")
+	}
+	known := false
+	for _, aggregation := range aggregations {
+		known = known || aggregation == q.Aggregation
+	}
+	if !known {
+		return fmt.Errorf("unknown aggregation %q (expected one of %v)", q.Aggregation, aggregations)
+	}
+	if q.WindowSeconds <= 0 {
+		return fmt.Errorf("window_seconds must be positive")
+	}
+	if q.StepSeconds < 0 || q.StepSeconds > q.WindowSeconds {
+		return fmt.Errorf("step_seconds must be between 0 and window_seconds")
+	}
+	if q.StepSeconds > 0 && q.WindowSeconds/q.StepSeconds > maxQueryPoints {
+		return fmt.Errorf("window_seconds / step_seconds must not exceed %d points", maxQueryPoints)
+	}
+	return nil
+}
+
+// QueryPoint is an aggregated value at the end of a step
+type QueryPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// QuerySeries is the result for one value of the query's GroupBy label
+type QuerySeries struct {
+	Group  string       `json:"group,omitempty"`
+	Points []QueryPoint `json:"points"`
+}
+
+// QueryResult is the outcome of a metric query
+type QueryResult struct {
+	Query  MetricQuery   `json:"query"`
+	Start  time.Time     `json:"start"`
+	End    time.Time     `json:"end"`
+	Series []QuerySeries `json:"series"`
+
+	// Annotations in the window, when the dashboard has an annotation store
+	Annotations []Annotation `json:"annotations,omitempty"`
+}
+
+// QueryMetrics runs a query over the metrics recorded in the window ending at
+// now. Steps without samples are left out of their series.
+func (ms *MonitoringService) QueryMetrics(query MetricQuery, now time.Time) (QueryResult, error) {
+	if err := query.Validate(); err != nil {
+		return QueryResult{}, err
+	}
+	window := time.Duration(query.WindowSeconds) * time.Second
+	result := QueryResult{Query: query, Start: now.Add(-window), End: now, Series: make([]QuerySeries, 0)}
+
+	step := window
+	if query.StepSeconds > 0 {
+		step = time.Duration(query.StepSeconds) * time.Second
+	}
+	steps := int((window + step - 1) / step)
+
+	// Samples per group and step, in recording order
+	buckets := make(map[string][][]float64)
+	ms.mu.RLock()
+	for _, metric := range ms.metrics {
+		if metric.Name != query.Metric || !metric.Timestamp.After(result.Start) || metric.Timestamp.After(now) {
+			continue
+		}
+		matches := true
+		for key, value := range query.Labels {
+			matches = matches && metric.Labels[key] == value
+		}
+		if !matches {
+			continue
+		}
+		group := ""
+		if query.GroupBy != "" {
+			group = metric.Labels[query.GroupBy]
+		}
+		if buckets[group] == nil {
+			buckets[group] = make([][]float64, steps)
+		}
+		// Steps end at now, so the newest step is the last
+		index := steps - 1 - int(now.Sub(metric.Timestamp)/step)
+		if index < 0 {
+			continue
+		}
+		buckets[group][index] = append(buckets[group][index], metric.Value)
+	}
+	ms.mu.RUnlock()
+
+	groups := make([]string, 0, len(buckets))
+	for group := range buckets {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		series := QuerySeries{Group: group, Points: make([]QueryPoint, 0, steps)}
+		for i, values := range buckets[group] {
+			if len(values) == 0 {
+				continue
+			}
+			series.Points = append(series.Points, QueryPoint{
+				Timestamp: now.Add(-time.Duration(steps-1-i) * step),
+				Value:     aggregate(query.Aggregation, values),
+			})
+		}
+		result.Series = append(result.Series, series)
+	}
+	return result, nil
+}
+
+// aggregate reduces samples, oldest first, to one value
+func aggregate(aggregation string, values []float64) float64 {
+	switch aggregation {
+	case AggregationCount:
+		return float64(len(values))
+	case AggregationLast:
+		return values[len(values)-1]
+	case AggregationP95:
+		sorted := append([]float64(nil), values...)
+		sort.Float64s(sorted)
+		return sorted[int(math.Ceil(0.95*float64(len(sorted))))-1]
+	}
+
+	result := values[0]
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+		if aggregation == AggregationMin {
+			result = math.Min(result, value)
+		} else if aggregation == AggregationMax {
+			result = math.Max(result, value)
+		}
+	}
+	switch aggregation {
+	case AggregationSum:
+		return sum
+	case AggregationAvg:
+		return sum / float64(len(values))
+	}
+	return result
+}
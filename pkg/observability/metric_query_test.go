@@ -0,0 +1,77 @@
+package observability
+
+import (
+	"testing"
+	"time"
+)
+
+// recordAt stores a metric sample with an explicit timestamp
+func recordAt(ms *MonitoringService, name string, value float64, labels map[string]string, at time.Time) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.metrics = append(ms.metrics, Metric{Name: name, Type: MetricGauge, Value: value, Labels: labels, Timestamp: at})
+}
+
+func TestQueryMetrics(t *testing.T) {
+	ms := NewMonitoringService(100)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	gpu0 := map[string]string{"gpu_id": "gpu-0", "node": "a"}
+	gpu1 := map[string]string{"gpu_id": "gpu-1", "node": "a"}
+	recordAt(ms, "gpu_utilization_percent", 10, gpu0, now.Add(-90*time.Second))
+	recordAt(ms, "gpu_utilization_percent", 30, gpu0, now.Add(-30*time.Second))
+	recordAt(ms, "gpu_utilization_percent", 50, gpu0, now.Add(-10*time.Second))
+	recordAt(ms, "gpu_utilization_percent", 80, gpu1, now.Add(-20*time.Second))
+	recordAt(ms, "gpu_utilization_percent", 99, gpu1, now.Add(-10*time.Minute)) // Outside the window
+	recordAt(ms, "gpu_temperature_celsius", 70, gpu0, now.Add(-10*time.Second))
+
+	result, err := ms.QueryMetrics(MetricQuery{Metric: "gpu_utilization_percent", Aggregation: AggregationAvg, WindowSeconds: 120}, now)
+	if err != nil {
+		t.Fatalf("QueryMetrics failed: %v", err)
+	}
+	if len(result.Series) != 1 || len(result.Series[0].Points) != 1 || result.Series[0].Points[0].Value != 42.5 {
+		t.Errorf("Expected a single average of 42.5, got %+v", result.Series)
+	}
+
+	result, _ = ms.QueryMetrics(MetricQuery{Metric: "gpu_utilization_percent", Aggregation: AggregationMax, WindowSeconds: 120, StepSeconds: 60, GroupBy: "gpu_id"}, now)
+	if len(result.Series) != 2 || result.Series[0].Group != "gpu-0" || result.Series[1].Group != "gpu-1" {
+		t.Fatalf("Expected a series per GPU, got %+v", result.Series)
+	}
+	points := result.Series[0].Points
+	if len(points) != 2 || points[0].Value != 10 || points[1].Value != 50 || !points[1].Timestamp.Equal(now) {
+		t.Errorf("Expected per-minute maxima of 10 and 50 for gpu-0, got %+v", points)
+	}
+	if points := result.Series[1].Points; len(points) != 1 || points[0].Value != 80 {
+		t.Errorf("Expected gpu-1's empty first minute to be skipped, got %+v", points)
+	}
+
+	result, _ = ms.QueryMetrics(MetricQuery{Metric: "gpu_utilization_percent", Labels: map[string]string{"gpu_id": "gpu-0"}, Aggregation: AggregationCount, WindowSeconds: 60}, now)
+	if result.Series[0].Points[0].Value != 2 {
+		t.Errorf("Expected 2 gpu-0 samples in the last minute, got %+v", result.Series)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	values := []float64{4, 1, 3, 2}
+	for aggregation, want := range map[string]float64{
+		AggregationAvg: 2.5, AggregationSum: 10, AggregationMin: 1, AggregationMax: 4,
+		AggregationCount: 4, AggregationLast: 2, AggregationP95: 4,
+	} {
+		if got := aggregate(aggregation, values); got != want {
+			t.Errorf("%s: expected %g, got %g", aggregation, want, got)
+		}
+	}
+}
+
+func TestMetricQueryValidate(t *testing.T) {
+	for _, query := range []MetricQuery{
+		{Aggregation: AggregationAvg, WindowSeconds: 60},
+		{Metric: "m", Aggregation: "median", WindowSeconds: 60},
+		{Metric: "m", Aggregation: AggregationAvg},
+		{Metric: "m", Aggregation: AggregationAvg, WindowSeconds: 60, StepSeconds: 120},
+		{Metric: "m", Aggregation: AggregationAvg, WindowSeconds: 86400, StepSeconds: 1},
+	} {
+		if err := query.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", query)
+		}
+	}
+}
@@ -39,6 +39,7 @@ type CostEntry struct {
 	ID         string
 	Operation  string // "inference" or "training"
 	ModelID    string
+	Tenant     string // Team or namespace charged; empty for shared costs
 	Duration   time.Duration
 	TokensUsed int64
 	GPUHours   float64
@@ -54,6 +55,10 @@ type MonitoringService struct {
 	costs          []CostEntry
 	mu             sync.RWMutex
 	maxHistorySize int
+
+	// Per-signal retention and eviction accounting
+	retention RetentionConfig
+	evictions map[string]evictionStats
 }
 
 // NewMonitoringService creates a new monitoring service
@@ -67,6 +72,8 @@ func NewMonitoringService(maxHistorySize int) *MonitoringService {
 		events:         make([]Event, 0),
 		costs:          make([]CostEntry, 0),
 		maxHistorySize: maxHistorySize,
+		retention:      DefaultRetentionConfig(maxHistorySize),
+		evictions:      make(map[string]evictionStats),
 	}
 }
 
@@ -78,10 +85,9 @@ func (ms *MonitoringService) RecordMetric(metric Metric) {
 	metric.Timestamp = time.Now()
 	ms.metrics = append(ms.metrics, metric)
 
-	// Trim old metrics if we exceed max size
-	if len(ms.metrics) > ms.maxHistorySize {
-		ms.metrics = ms.metrics[len(ms.metrics)-ms.maxHistorySize:]
-	}
+	// Evict metrics outside the retention policy
+	drop := ms.evictionIndex(SignalMetrics, len(ms.metrics), func(i int) time.Time { return ms.metrics[i].Timestamp }, ms.retention.Metrics, metric.Timestamp)
+	ms.metrics = ms.metrics[drop:]
 }
 
 // RecordEvent records a new event
@@ -92,10 +98,9 @@ func (ms *MonitoringService) RecordEvent(event Event) {
 	event.Timestamp = time.Now()
 	ms.events = append(ms.events, event)
 
-	// Trim old events if we exceed max size
-	if len(ms.events) > ms.maxHistorySize {
-		ms.events = ms.events[len(ms.events)-ms.maxHistorySize:]
-	}
+	// Evict events outside the retention policy
+	drop := ms.evictionIndex(SignalEvents, len(ms.events), func(i int) time.Time { return ms.events[i].Timestamp }, ms.retention.Events, event.Timestamp)
+	ms.events = ms.events[drop:]
 }
 
 // RecordCost records a cost entry
@@ -106,10 +111,9 @@ func (ms *MonitoringService) RecordCost(cost CostEntry) {
 	cost.Timestamp = time.Now()
 	ms.costs = append(ms.costs, cost)
 
-	// Trim old cost entries if we exceed max size
-	if len(ms.costs) > ms.maxHistorySize {
-		ms.costs = ms.costs[len(ms.costs)-ms.maxHistorySize:]
-	}
+	// Evict cost entries outside the retention policy
+	drop := ms.evictionIndex(SignalCosts, len(ms.costs), func(i int) time.Time { return ms.costs[i].Timestamp }, ms.retention.Costs, cost.Timestamp)
+	ms.costs = ms.costs[drop:]
 }
 
 // GetMetrics returns metrics within a time range
@@ -148,6 +152,12 @@ func (ms *MonitoringService) GetEvents(start, end time.Time, severity string) []
 
 // GetCostSummary calculates cost summary for a time period
 func (ms *MonitoringService) GetCostSummary(start, end time.Time) map[string]interface{} {
+	return ms.GetTenantCostSummary(start, end, "")
+}
+
+// GetTenantCostSummary calculates a tenant's cost summary for a time period;
+// an empty tenant summarizes every cost entry
+func (ms *MonitoringService) GetTenantCostSummary(start, end time.Time, tenant string) map[string]interface{} {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
@@ -159,6 +169,9 @@ func (ms *MonitoringService) GetCostSummary(start, end time.Time) map[string]int
 	operationCounts := make(map[string]int)
 
 	for _, cost := range ms.costs {
+		if tenant != "" && cost.Tenant != tenant {
+			continue
+		}
 		if cost.Timestamp.After(start) && cost.Timestamp.Before(end) {
 			totalCost += cost.Cost
 			totalTokens += cost.TokensUsed
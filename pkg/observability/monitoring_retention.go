@@ -0,0 +1,173 @@
+package observability
+
+import (
+	"fmt"
+	"time"
+)
+
+// Signal types held by the MonitoringService
+const (
+	SignalMetrics = "metrics"
+	SignalEvents  = "events"
+	SignalCosts   = "costs"
+)
+
+// RetentionPolicy bounds the history kept for one signal type
+type RetentionPolicy struct {
+	MaxEntries int           `json:"max_entries"` // Oldest entries are evicted beyond this count
+	MaxAge     time.Duration `json:"max_age"`     // Entries older than this are evicted (0 keeps them)
+}
+
+// RetentionConfig configures retention per signal type
+type RetentionConfig struct {
+	Metrics RetentionPolicy `json:"metrics"`
+	Events  RetentionPolicy `json:"events"`
+	Costs   RetentionPolicy `json:"costs"`
+}
+
+// DefaultRetentionConfig keeps up to maxEntries of each signal type with no age limit
+func DefaultRetentionConfig(maxEntries int) RetentionConfig {
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	policy := RetentionPolicy{MaxEntries: maxEntries}
+	return RetentionConfig{Metrics: policy, Events: policy, Costs: policy}
+}
+
+// evictionStats counts entries evicted from one signal buffer
+type evictionStats struct {
+	byCount int64
+	byAge   int64
+}
+
+// NewMonitoringServiceWithRetention creates a monitoring service with per-signal retention
+func NewMonitoringServiceWithRetention(config RetentionConfig) (*MonitoringService, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	ms := NewMonitoringService(config.Metrics.MaxEntries)
+	ms.retention = config
+	return ms, nil
+}
+
+// validate checks every policy has a positive entry limit and non-negative age
+func (c RetentionConfig) validate() error {
+	policies := map[string]RetentionPolicy{SignalMetrics: c.Metrics, SignalEvents: c.Events, SignalCosts: c.Costs}
+	for signal, policy := range policies {
+		if policy.MaxEntries <= 0 {
+			return fmt.Errorf("%s retention max entries must be positive", signal)
+		}
+		if policy.MaxAge < 0 {
+			return fmt.Errorf("%s retention max age cannot be negative", signal)
+		}
+	}
+	return nil
+}
+
+// SetRetention changes retention and immediately evicts entries outside the new limits
+func (ms *MonitoringService) SetRetention(config RetentionConfig) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.retention = config
+	ms.maxHistorySize = config.Metrics.MaxEntries
+	ms.enforceRetention(time.Now())
+	return nil
+}
+
+// GetRetention returns the current retention configuration
+func (ms *MonitoringService) GetRetention() RetentionConfig {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return ms.retention
+}
+
+// EnforceRetention evicts entries that have aged out and returns how many were removed
+func (ms *MonitoringService) EnforceRetention() int {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.enforceRetention(time.Now())
+}
+
+// enforceRetention trims every buffer; callers must hold the lock
+func (ms *MonitoringService) enforceRetention(now time.Time) int {
+	drop := ms.evictionIndex(SignalMetrics, len(ms.metrics), func(i int) time.Time { return ms.metrics[i].Timestamp }, ms.retention.Metrics, now)
+	ms.metrics = ms.metrics[drop:]
+	removed := drop
+
+	drop = ms.evictionIndex(SignalEvents, len(ms.events), func(i int) time.Time { return ms.events[i].Timestamp }, ms.retention.Events, now)
+	ms.events = ms.events[drop:]
+	removed += drop
+
+	drop = ms.evictionIndex(SignalCosts, len(ms.costs), func(i int) time.Time { return ms.costs[i].Timestamp }, ms.retention.Costs, now)
+	ms.costs = ms.costs[drop:]
+	removed += drop
+
+	return removed
+}
+
+// evictionIndex returns how many of the oldest entries of a chronological buffer
+// fall outside the policy and records why they were evicted
+func (ms *MonitoringService) evictionIndex(signal string, size int, timestampAt func(int) time.Time, policy RetentionPolicy, now time.Time) int {
+	drop := 0
+	if policy.MaxAge > 0 {
+		cutoff := now.Add(-policy.MaxAge)
+		for drop < size && timestampAt(drop).Before(cutoff) {
+			drop++
+		}
+	}
+	byAge := drop
+
+	if size-drop > policy.MaxEntries {
+		drop = size - policy.MaxEntries
+	}
+
+	stats := ms.evictions[signal]
+	stats.byAge += int64(byAge)
+	stats.byCount += int64(drop - byAge)
+	ms.evictions[signal] = stats
+	return drop
+}
+
+// GetBufferOccupancy returns current size, capacity and eviction counts per signal type
+func (ms *MonitoringService) GetBufferOccupancy() map[string]interface{} {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	describe := func(signal string, size int, oldest time.Time, policy RetentionPolicy) map[string]interface{} {
+		occupancy := map[string]interface{}{
+			"entries":           size,
+			"max_entries":       policy.MaxEntries,
+			"occupancy_percent": float64(size) / float64(policy.MaxEntries) * 100,
+			"max_age_seconds":   policy.MaxAge.Seconds(),
+			"evicted_by_count":  ms.evictions[signal].byCount,
+			"evicted_by_age":    ms.evictions[signal].byAge,
+		}
+		if size > 0 {
+			occupancy["oldest_entry"] = oldest
+		}
+		return occupancy
+	}
+
+	var oldestMetric, oldestEvent, oldestCost time.Time
+	if len(ms.metrics) > 0 {
+		oldestMetric = ms.metrics[0].Timestamp
+	}
+	if len(ms.events) > 0 {
+		oldestEvent = ms.events[0].Timestamp
+	}
+	if len(ms.costs) > 0 {
+		oldestCost = ms.costs[0].Timestamp
+	}
+
+	return map[string]interface{}{
+		SignalMetrics: describe(SignalMetrics, len(ms.metrics), oldestMetric, ms.retention.Metrics),
+		SignalEvents:  describe(SignalEvents, len(ms.events), oldestEvent, ms.retention.Events),
+		SignalCosts:   describe(SignalCosts, len(ms.costs), oldestCost, ms.retention.Costs),
+	}
+}
@@ -0,0 +1,96 @@
+package observability
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetentionByCount(t *testing.T) {
+	config := DefaultRetentionConfig(100)
+	config.Events.MaxEntries = 3
+	monitor, err := NewMonitoringServiceWithRetention(config)
+	if err != nil {
+		t.Fatalf("Failed to create monitoring service: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		monitor.RecordEvent(Event{ID: string(rune('a' + i)), Type: "test"})
+		monitor.RecordMetric(Metric{Name: "m", Value: float64(i)})
+	}
+
+	occupancy := monitor.GetBufferOccupancy()
+	events := occupancy[SignalEvents].(map[string]interface{})
+	if events["entries"].(int) != 3 || events["evicted_by_count"].(int64) != 2 {
+		t.Errorf("Expected 3 events kept and 2 evicted, got %+v", events)
+	}
+	if events["occupancy_percent"].(float64) != 100 {
+		t.Errorf("Expected full event buffer, got %v", events["occupancy_percent"])
+	}
+
+	metrics := occupancy[SignalMetrics].(map[string]interface{})
+	if metrics["entries"].(int) != 5 || metrics["evicted_by_count"].(int64) != 0 {
+		t.Errorf("Expected metrics to be retained independently, got %+v", metrics)
+	}
+
+	// The oldest events are the ones evicted
+	now := time.Now()
+	kept := monitor.GetEvents(now.Add(-time.Minute), now.Add(time.Minute), "")
+	if kept[0].ID != "c" {
+		t.Errorf("Expected oldest events to be evicted first, first kept is %s", kept[0].ID)
+	}
+}
+
+func TestRetentionByAge(t *testing.T) {
+	monitor := NewMonitoringService(100)
+	for i := 0; i < 4; i++ {
+		monitor.RecordCost(CostEntry{Operation: "inference", Cost: 1})
+	}
+
+	// Backdate half the entries beyond the age limit
+	monitor.mu.Lock()
+	monitor.costs[0].Timestamp = time.Now().Add(-2 * time.Hour)
+	monitor.costs[1].Timestamp = time.Now().Add(-2 * time.Hour)
+	monitor.mu.Unlock()
+
+	config := DefaultRetentionConfig(100)
+	config.Costs.MaxAge = time.Hour
+	if err := monitor.SetRetention(config); err != nil {
+		t.Fatalf("Failed to set retention: %v", err)
+	}
+
+	costs := monitor.GetBufferOccupancy()[SignalCosts].(map[string]interface{})
+	if costs["entries"].(int) != 2 || costs["evicted_by_age"].(int64) != 2 {
+		t.Errorf("Expected 2 aged-out costs evicted, got %+v", costs)
+	}
+
+	if err := monitor.SetRetention(RetentionConfig{}); err == nil {
+		t.Error("Expected error for retention without entry limits")
+	}
+}
+
+func TestBufferMetricsExported(t *testing.T) {
+	monitor, _ := NewMonitoringServiceWithRetention(RetentionConfig{
+		Metrics: RetentionPolicy{MaxEntries: 2},
+		Events:  RetentionPolicy{MaxEntries: 10},
+		Costs:   RetentionPolicy{MaxEntries: 10},
+	})
+	for i := 0; i < 5; i++ {
+		monitor.RecordMetric(Metric{Name: "unregistered", Value: 1})
+	}
+
+	exporter := NewPrometheusExporter(monitor, DefaultPrometheusConfig())
+	exporter.RegisterSystemMetrics()
+	exporter.SyncFromMonitoringService()
+	exporter.SyncFromMonitoringService()
+
+	output := exporter.ExportMetrics()
+	families := parseExposition(t, output)
+	if entries := families["agentaflow_monitoring_buffer_entries"].GetMetric(); len(entries) != 3 {
+		t.Errorf("Expected buffer entries for each signal, got %v", entries)
+	}
+	expected := `agentaflow_monitoring_buffer_evictions_total{reason="count",signal="metrics"} 3` + "\n"
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected eviction counter to mirror the service total, got:\n%s", output)
+	}
+}
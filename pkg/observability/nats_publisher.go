@@ -0,0 +1,157 @@
+package observability
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/airgap"
+)
+
+// NATSConfig selects a NATS server to publish events to
+type NATSConfig struct {
+	URL         string        `yaml:"url" json:"url"` // e.g. nats://nats:4222; publishing is off when empty
+	Subject     string        `yaml:"subject" json:"subject"`
+	Token       string        `yaml:"token" json:"-" secret:"true"`
+	User        string        `yaml:"user" json:"user"`
+	Password    string        `yaml:"password" json:"-" secret:"true"`
+	DialTimeout time.Duration `yaml:"dial_timeout" json:"dial_timeout"`
+}
+
+// NATSPublisher publishes messages to a NATS server, speaking the client
+// protocol directly so no client library is needed. Each publish is
+// confirmed with a PING so errors the server reports are returned.
+type NATSPublisher struct {
+	config NATSConfig
+	addr   string
+	conn   net.Conn
+	reader *bufio.Reader
+	mu     sync.Mutex
+}
+
+// NewNATSPublisher creates a publisher for a nats:// URL; the connection is
+// opened on first use and reopened after failures
+func NewNATSPublisher(config NATSConfig) (*NATSPublisher, error) {
+	parsed, err := url.Parse(config.URL)
+	if err != nil || parsed.Scheme != "nats" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid NATS URL %q", config.URL)
+	}
+	if parsed.User != nil && config.User == "" {
+		config.User = parsed.User.Username()
+		config.Password, _ = parsed.User.Password()
+	}
+	addr := parsed.Host
+	if parsed.Port() == "" {
+		addr = net.JoinHostPort(parsed.Hostname(), "4222")
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 5 * time.Second
+	}
+	registerOutboundEndpoint("nats", addr)
+	return &NATSPublisher{config: config, addr: addr}, nil
+}
+
+// dial connects through the air gap guard, reads the server's INFO and
+// sends CONNECT
+func (p *NATSPublisher) dial() (net.Conn, *bufio.Reader, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.DialTimeout)
+	defer cancel()
+	conn, err := airgap.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(p.config.DialTimeout))
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return nil, nil, fmt.Errorf("unexpected NATS greeting %q: %v", strings.TrimSpace(line), err)
+	}
+
+	connect := map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "agentaflow",
+		"lang":     "go",
+	}
+	if p.config.Token != "" {
+		connect["auth_token"] = p.config.Token
+	}
+	if p.config.User != "" {
+		connect["user"] = p.config.User
+		connect["pass"] = p.config.Password
+	}
+	options, _ := json.Marshal(connect)
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\n", options); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, reader, nil
+}
+
+// Publish sends a message to a subject, reconnecting once if the connection
+// was lost
+func (p *NATSPublisher) Publish(subject string, payload []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if p.conn == nil {
+			if p.conn, p.reader, err = p.dial(); err != nil {
+				return err
+			}
+		}
+		p.conn.SetDeadline(time.Now().Add(p.config.DialTimeout))
+		if _, err = fmt.Fprintf(p.conn, "PUB %s %d\r\n%s\r\nPING\r\n", subject, len(payload), payload); err == nil {
+			var rejected error
+			if rejected, err = p.awaitPong(); err == nil {
+				return rejected
+			}
+		}
+		p.conn.Close()
+		p.conn, p.reader = nil, nil
+	}
+	return fmt.Errorf("failed to publish to NATS: %w", err)
+}
+
+// awaitPong reads until the server answers the PING, answering the server's
+// own PINGs. An -ERR the server sent first is returned as rejected; err
+// reports a broken connection.
+func (p *NATSPublisher) awaitPong() (rejected error, err error) {
+	for {
+		line, err := p.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return rejected, nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			rejected = fmt.Errorf("NATS rejected the publish: %s", strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")), "'"))
+		}
+	}
+}
+
+// Close closes the connection
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn, p.reader = nil, nil
+	return err
+}
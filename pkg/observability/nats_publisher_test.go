@@ -0,0 +1,75 @@
+package observability
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeNATSServer accepts one connection and records the published messages
+func fakeNATSServer(t *testing.T, reject string) (string, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch fields := strings.Fields(line); fields[0] {
+			case "CONNECT":
+				messages <- strings.TrimSpace(line)
+			case "PUB":
+				payload, _ := reader.ReadString('\n')
+				if fields[1] == reject {
+					conn.Write([]byte("-ERR 'Permissions Violation for Publish'\r\n"))
+					continue
+				}
+				messages <- fields[1] + " " + strings.TrimSpace(payload)
+			case "PING":
+				conn.Write([]byte("PING\r\nPONG\r\n"))
+			}
+		}
+	}()
+	return "nats://" + listener.Addr().String(), messages
+}
+
+func TestNATSPublisher(t *testing.T) {
+	url, messages := fakeNATSServer(t, "forbidden")
+	publisher, err := NewNATSPublisher(NATSConfig{URL: url, Token: "t0ken"})
+	if err != nil {
+		t.Fatalf("Failed to create publisher: %v", err)
+	}
+	defer publisher.Close()
+
+	if err := publisher.Publish("agentaflow.capacity.needed", []byte(`{"pool":"batch"}`)); err != nil {
+		t.Fatalf("Expected publish to succeed, got %v", err)
+	}
+	if connect := <-messages; !strings.Contains(connect, `"auth_token":"t0ken"`) {
+		t.Errorf("Expected CONNECT with the token, got %s", connect)
+	}
+	if message := <-messages; message != `agentaflow.capacity.needed {"pool":"batch"}` {
+		t.Errorf("Unexpected message %q", message)
+	}
+
+	if err := publisher.Publish("forbidden", []byte("x")); err == nil || !strings.Contains(err.Error(), "Permissions Violation") {
+		t.Errorf("Expected the server's error, got %v", err)
+	}
+
+	if _, err := NewNATSPublisher(NATSConfig{URL: "http://nats:4222"}); err == nil {
+		t.Error("Expected non-NATS URL to be rejected")
+	}
+}
@@ -0,0 +1,53 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestNodesAPI(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{Port: 0})
+	for _, metrics := range []gpu.GPUMetrics{
+		{GPUID: "gpu-0", NodeID: "node-b", UtilizationGPU: 90, PowerDraw: 300, MemoryTotal: 1000, Timestamp: time.Now()},
+		{GPUID: "gpu-1", NodeID: "node-b", UtilizationGPU: 10, PowerDraw: 100, MemoryTotal: 1000, Timestamp: time.Now()},
+		{GPUID: "gpu-2", NodeID: "node-a", UtilizationGPU: 50, PowerDraw: 200, MemoryTotal: 1000, Timestamp: time.Now()},
+	} {
+		dashboard.lastMetrics[metrics.GPUID] = metrics
+	}
+
+	response := serveDashboard(dashboard, http.MethodGet, "/api/v1/nodes", "")
+	var list struct {
+		Nodes []gpu.NodeStats `json:"nodes"`
+		Total int             `json:"total"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if list.Total != 2 || list.Nodes[0].NodeID != "node-a" || list.Nodes[1].TotalPowerDraw != 400 {
+		t.Errorf("Unexpected nodes %+v", list.Nodes)
+	}
+
+	var node gpu.NodeStats
+	response = serveDashboard(dashboard, http.MethodGet, "/api/v1/nodes/node-b", "")
+	if err := json.Unmarshal(response.Body.Bytes(), &node); err != nil || node.AverageUtilization != 50 {
+		t.Errorf("Unexpected node-b %s", response.Body.String())
+	}
+
+	var gpus struct {
+		GPUs []gpu.GPUMetrics `json:"gpus"`
+	}
+	response = serveDashboard(dashboard, http.MethodGet, "/api/v1/nodes/node-b/gpus", "")
+	if err := json.Unmarshal(response.Body.Bytes(), &gpus); err != nil || len(gpus.GPUs) != 2 || gpus.GPUs[0].GPUID != "gpu-0" {
+		t.Errorf("Unexpected node-b GPUs %s", response.Body.String())
+	}
+
+	for _, path := range []string{"/api/v1/nodes/missing", "/api/v1/nodes/missing/gpus"} {
+		if response := serveDashboard(dashboard, http.MethodGet, path, ""); response.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", path, response.Code)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Channels a user can be notified on
+const (
+	ChannelBrowser = "browser"
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+)
+
+// notificationSeverities are the alert and incident severities users choose channels for
+var notificationSeverities = []string{"info", "warning", "error", "critical"}
+
+// NotificationPreferences chooses where a user is notified for each severity.
+// Severities without channels notify the user nowhere.
+type NotificationPreferences struct {
+	User       string              `yaml:"user" json:"user"`
+	Email      string              `yaml:"email" json:"email,omitempty"`
+	WebhookURL string              `yaml:"webhook_url" json:"webhook_url,omitempty"`
+	Severities map[string][]string `yaml:"severities" json:"severities"` // Severity to channels
+}
+
+// DefaultNotificationPreferences shows every alert as a browser notification,
+// as the dashboard does for users without preferences
+func DefaultNotificationPreferences(user string) NotificationPreferences {
+	severities := make(map[string][]string)
+	for _, severity := range notificationSeverities {
+		severities[severity] = []string{ChannelBrowser}
+	}
+	return NotificationPreferences{User: user, Severities: severities}
+}
+
+// Validate checks the severities and channels, and that email and webhook
+// channels have somewhere to deliver to
+func (p NotificationPreferences) Validate() error {
+	var errs ConfigErrors
+	if p.User == "" {
+		errs.add("user", "must not be empty")
+	}
+	if p.Email != "" {
+		if _, err := mail.ParseAddress(p.Email); err != nil {
+			errs.add("email", "invalid address %q", p.Email)
+		}
+	}
+	if p.WebhookURL != "" {
+		if u, err := url.Parse(p.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.add("webhook_url", "must be an http or https URL")
+		}
+	}
+
+	severities := make([]string, 0, len(p.Severities))
+	for severity := range p.Severities {
+		severities = append(severities, severity)
+	}
+	sort.Strings(severities)
+	for _, severity := range severities {
+		channels := p.Severities[severity]
+		field := fmt.Sprintf("severities.%s", severity)
+		known := false
+		for _, candidate := range notificationSeverities {
+			known = known || candidate == severity
+		}
+		if !known {
+			errs.add(field, "unknown severity (expected one of %v)", notificationSeverities)
+		}
+		for _, channel := range channels {
+			switch channel {
+			case ChannelBrowser:
+			case ChannelEmail:
+				if p.Email == "" {
+					errs.add(field, "email channel requires an email address")
+				}
+			case ChannelWebhook:
+				if p.WebhookURL == "" {
+					errs.add(field, "webhook channel requires a webhook_url")
+				}
+			default:
+				errs.add(field, "unknown channel %q (expected %s, %s or %s)", channel, ChannelBrowser, ChannelEmail, ChannelWebhook)
+			}
+		}
+	}
+	return errs.err()
+}
+
+// Wants reports whether the user is notified on a channel for a severity
+func (p NotificationPreferences) Wants(severity, channel string) bool {
+	for _, candidate := range p.Severities[severity] {
+		if candidate == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationPreferenceStore keeps every user's notification preferences
+// server-side, optionally persisted to a JSON file
+type NotificationPreferenceStore struct {
+	path        string
+	preferences map[string]NotificationPreferences
+	mu          sync.RWMutex
+}
+
+// NewNotificationPreferenceStore creates a store, loading preferences saved
+// at path. An empty path keeps preferences in memory only.
+func NewNotificationPreferenceStore(path string) (*NotificationPreferenceStore, error) {
+	store := &NotificationPreferenceStore{
+		path:        path,
+		preferences: make(map[string]NotificationPreferences),
+	}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification preferences: %w", err)
+	}
+	var saved []NotificationPreferences
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to decode notification preferences: %w", err)
+	}
+	for _, preferences := range saved {
+		store.preferences[preferences.User] = preferences
+	}
+	return store, nil
+}
+
+// Get returns a user's preferences, or the defaults when the user has none
+func (s *NotificationPreferenceStore) Get(user string) NotificationPreferences {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if preferences, exists := s.preferences[user]; exists {
+		return preferences
+	}
+	return DefaultNotificationPreferences(user)
+}
+
+// Set validates and saves a user's preferences
+func (s *NotificationPreferenceStore) Set(preferences NotificationPreferences) error {
+	if err := preferences.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, existed := s.preferences[preferences.User]
+	s.preferences[preferences.User] = preferences
+	if err := s.save(); err != nil {
+		if existed {
+			s.preferences[preferences.User] = previous
+		} else {
+			delete(s.preferences, preferences.User)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete resets a user to the default preferences, returning false when the
+// user had none saved
+func (s *NotificationPreferenceStore) Delete(user string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, exists := s.preferences[user]
+	if !exists {
+		return false, nil
+	}
+	delete(s.preferences, user)
+	if err := s.save(); err != nil {
+		s.preferences[user] = previous
+		return false, err
+	}
+	return true, nil
+}
+
+// List returns the saved preferences of every user, sorted by user
+func (s *NotificationPreferenceStore) List() []NotificationPreferences {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sorted()
+}
+
+// sorted copies the saved preferences in user order; callers must hold the lock
+func (s *NotificationPreferenceStore) sorted() []NotificationPreferences {
+	list := make([]NotificationPreferences, 0, len(s.preferences))
+	for _, preferences := range s.preferences {
+		list = append(list, preferences)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].User < list[j].User
+	})
+	return list
+}
+
+// save atomically rewrites the preferences file; callers must hold the lock
+func (s *NotificationPreferenceStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.sorted(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode notification preferences: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create notification preferences file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write notification preferences: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close notification preferences: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace notification preferences: %w", err)
+	}
+	return nil
+}
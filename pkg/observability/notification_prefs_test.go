@@ -0,0 +1,72 @@
+package observability
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestNotificationPreferencesValidate(t *testing.T) {
+	valid := NotificationPreferences{
+		User:       "alice",
+		Email:      "alice@example.com",
+		WebhookURL: "https://hooks.example.com/alice",
+		Severities: map[string][]string{"critical": {ChannelBrowser, ChannelEmail, ChannelWebhook}, "info": {}},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected valid preferences, got %v", err)
+	}
+	if !valid.Wants("critical", ChannelEmail) || valid.Wants("info", ChannelBrowser) || valid.Wants("warning", ChannelBrowser) {
+		t.Error("Expected only the listed severities and channels to be wanted")
+	}
+
+	for name, preferences := range map[string]NotificationPreferences{
+		"unknown severity": {User: "alice", Severities: map[string][]string{"fatal": {ChannelBrowser}}},
+		"unknown channel":  {User: "alice", Severities: map[string][]string{"critical": {"sms"}}},
+		"no email address": {User: "alice", Severities: map[string][]string{"critical": {ChannelEmail}}},
+		"no webhook url":   {User: "alice", Severities: map[string][]string{"critical": {ChannelWebhook}}},
+		"bad webhook url":  {User: "alice", WebhookURL: "ftp://example.com"},
+		"bad email":        {User: "alice", Email: "not-an-address"},
+	} {
+		if err := preferences.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+func TestNotificationPreferenceStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notification-preferences.json")
+	store, err := NewNotificationPreferenceStore(path)
+	if err != nil {
+		t.Fatalf("NewNotificationPreferenceStore failed: %v", err)
+	}
+	if !store.Get("bob").Wants("info", ChannelBrowser) {
+		t.Error("Expected users without preferences to get browser notifications")
+	}
+
+	preferences := NotificationPreferences{User: "alice", Severities: map[string][]string{"critical": {ChannelBrowser}}}
+	if err := store.Set(preferences); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := store.Set(NotificationPreferences{User: "bob", Severities: map[string][]string{"critical": {ChannelEmail}}}); err == nil {
+		t.Error("Expected invalid preferences to be rejected")
+	}
+
+	// Preferences survive a restart
+	reloaded, err := NewNotificationPreferenceStore(path)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if list := reloaded.List(); len(list) != 1 || list[0].User != "alice" || reloaded.Get("alice").Wants("warning", ChannelBrowser) {
+		t.Errorf("Expected alice's saved preferences after reload, got %+v", list)
+	}
+
+	if deleted, err := reloaded.Delete("alice"); err != nil || !deleted {
+		t.Fatalf("Expected alice's preferences deleted, got %v, %v", deleted, err)
+	}
+	if deleted, _ := reloaded.Delete("alice"); deleted {
+		t.Error("Expected nothing left to delete")
+	}
+	if !reloaded.Get("alice").Wants("warning", ChannelBrowser) {
+		t.Error("Expected alice back on the defaults")
+	}
+}
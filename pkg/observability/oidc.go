@@ -0,0 +1,542 @@
+package observability
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
+)
+
+// errNoDashboardRole rejects users whose groups do not map to one role set
+// and tenant
+var errNoDashboardRole = errors.New("no dashboard role")
+
+// oidcLoginTimeout is how long a user has to finish logging in at the provider
+const oidcLoginTimeout = 10 * time.Minute
+
+// maxPendingOIDCLogins caps the logins started but not finished, which anyone
+// can start; beyond it the oldest is dropped
+const maxPendingOIDCLogins = 10000
+
+// oidcPruneInterval is how often expired logins and sessions are dropped
+const oidcPruneInterval = time.Minute
+
+// OIDCConfig enables OpenID Connect login (Okta, Azure AD, Google and other
+// providers) for the dashboard. Users get the roles and tenant their groups
+// map to; machines keep using API keys. Pending logins and sessions are kept
+// in each replica's memory, so users log in again after a restart, and
+// replicas behind a load balancer need sticky sessions: a callback or request
+// reaching another replica is refused.
+type OIDCConfig struct {
+	Issuer       string `yaml:"issuer" json:"issuer"` // Login is off when empty
+	ClientID     string `yaml:"client_id" json:"client_id"`
+	ClientSecret string `yaml:"client_secret" json:"-" secret:"true"`
+
+	// The dashboard's /auth/callback URL as registered with the provider
+	RedirectURL string `yaml:"redirect_url" json:"redirect_url"`
+
+	// Requested scopes; empty uses openid, profile and email
+	Scopes []string `yaml:"scopes" json:"scopes"`
+
+	// ID token claim listing the user's groups; empty uses "groups". A string
+	// claim such as Google's "hd" counts as one group.
+	GroupsClaim string `yaml:"groups_claim" json:"groups_claim"`
+
+	// Role granted to members of each group: read-metrics, submit-workloads
+	// or admin. Users in no mapped group cannot log in.
+	GroupRoles map[string]apikeys.Scope `yaml:"group_roles" json:"group_roles"`
+
+	// Tenant of members of each group while tenancy is enabled
+	GroupTenants map[string]string `yaml:"group_tenants" json:"group_tenants"`
+
+	// How long a login lasts; zero uses 8 hours
+	SessionTTL time.Duration `yaml:"session_ttl" json:"session_ttl"`
+}
+
+// Enabled reports whether OIDC login is configured
+func (c OIDCConfig) Enabled() bool {
+	return c.Issuer != ""
+}
+
+// oidcDiscovery is the part of the provider's discovery document used here
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcSession is a logged-in dashboard user
+type oidcSession struct {
+	Subject   string          `json:"subject"`
+	Email     string          `json:"email,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Groups    []string        `json:"groups"`
+	Scopes    []apikeys.Scope `json:"scopes"`
+	Tenant    string          `json:"tenant,omitempty"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// actor is the name audit logs record for the user
+func (s *oidcSession) actor() string {
+	if s.Email != "" {
+		return "oidc:" + s.Email
+	}
+	return "oidc:" + s.Subject
+}
+
+// hasAdmin reports whether the user's groups grant the admin role
+func (s *oidcSession) hasAdmin() bool {
+	for _, scope := range s.Scopes {
+		if scope == apikeys.ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// pendingLogin is a login started at the provider but not yet finished
+type pendingLogin struct {
+	nonce    string
+	verifier string // PKCE code verifier
+	next     string // Dashboard path to return to
+	expires  time.Time
+}
+
+// oidcProvider runs the authorization code flow with PKCE, verifies RS256
+// ID tokens against the provider's published keys and keeps login sessions
+type oidcProvider struct {
+	config    OIDCConfig
+	client    *http.Client
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey // By key ID
+	logins    map[string]pendingLogin   // By state
+	sessions  map[string]*oidcSession   // By session ID
+	now       func() time.Time
+	mu        sync.Mutex
+}
+
+// newOIDCProvider validates the configuration and fills defaults. The
+// provider is discovered on the first login, so startup does not need it.
+func newOIDCProvider(config OIDCConfig) (*oidcProvider, error) {
+	if config.ClientID == "" || config.RedirectURL == "" {
+		return nil, fmt.Errorf("OIDC login needs a client ID and redirect URL")
+	}
+	for group, role := range config.GroupRoles {
+		switch role {
+		case apikeys.ScopeReadMetrics, apikeys.ScopeSubmitWorkloads, apikeys.ScopeAdmin:
+		default:
+			return nil, fmt.Errorf("group %s maps to unknown role %q", group, role)
+		}
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "profile", "email"}
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	if config.SessionTTL == 0 {
+		config.SessionTTL = 8 * time.Hour
+	}
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+
+	return &oidcProvider{
+		config:   config,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		keys:     make(map[string]*rsa.PublicKey),
+		logins:   make(map[string]pendingLogin),
+		sessions: make(map[string]*oidcSession),
+		now:      time.Now,
+	}, nil
+}
+
+// discover fetches and caches the provider's discovery document
+func (p *oidcProvider) discover(ctx context.Context) (oidcDiscovery, error) {
+	p.mu.Lock()
+	cached := p.discovery
+	p.mu.Unlock()
+	if cached != nil {
+		return *cached, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := p.getJSON(ctx, p.config.Issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return oidcDiscovery{}, fmt.Errorf("failed to discover OIDC provider: %w", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != p.config.Issuer {
+		return oidcDiscovery{}, fmt.Errorf("OIDC provider reports issuer %s, expected %s", discovery.Issuer, p.config.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return oidcDiscovery{}, fmt.Errorf("OIDC discovery document is missing endpoints")
+	}
+
+	p.mu.Lock()
+	p.discovery = &discovery
+	p.mu.Unlock()
+	return discovery, nil
+}
+
+// loginURL starts a login that returns to next and gives the provider URL
+// to send the browser to
+func (p *oidcProvider) loginURL(ctx context.Context, next string) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	state, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	if len(p.logins) >= maxPendingOIDCLogins {
+		p.dropOldestLogin()
+	}
+	p.logins[state] = pendingLogin{nonce: nonce, verifier: verifier, next: next, expires: p.now().Add(oidcLoginTimeout)}
+	p.mu.Unlock()
+
+	challenge := sha256.Sum256([]byte(verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// finishLogin exchanges the authorization code for an ID token and starts a
+// session, returning its ID and the path the login started from
+func (p *oidcProvider) finishLogin(ctx context.Context, state, code string) (string, *oidcSession, string, error) {
+	p.mu.Lock()
+	login, exists := p.logins[state]
+	delete(p.logins, state)
+	p.mu.Unlock()
+	if !exists || p.now().After(login.expires) {
+		return "", nil, "", fmt.Errorf("unknown or expired login")
+	}
+
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", nil, "", err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {login.verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", nil, "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", nil, "", fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, "", fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return "", nil, "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	claims, err := p.verifyIDToken(ctx, discovery, tokens.IDToken, login.nonce)
+	if err != nil {
+		return "", nil, "", err
+	}
+	session, err := p.newSession(claims)
+	if err != nil {
+		return "", nil, "", err
+	}
+	id, err := randomToken()
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sessions[id] = session
+	return id, session, login.next, nil
+}
+
+// dropOldestLogin forgets the pending login closest to expiring; p.mu must be held
+func (p *oidcProvider) dropOldestLogin() {
+	oldest := ""
+	for state, login := range p.logins {
+		if oldest == "" || login.expires.Before(p.logins[oldest].expires) {
+			oldest = state
+		}
+	}
+	delete(p.logins, oldest)
+}
+
+// prune drops expired pending logins and sessions
+func (p *oidcProvider) prune() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	for state, login := range p.logins {
+		if now.After(login.expires) {
+			delete(p.logins, state)
+		}
+	}
+	for id, session := range p.sessions {
+		if now.After(session.ExpiresAt) {
+			delete(p.sessions, id)
+		}
+	}
+}
+
+// runPruning prunes expired logins and sessions every interval until ctx is done
+func (p *oidcProvider) runPruning(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.prune()
+		}
+	}
+}
+
+// verifyIDToken checks an ID token's RS256 signature, issuer, audience,
+// expiry and nonce and returns its claims
+func (p *oidcProvider) verifyIDToken(ctx context.Context, discovery oidcDiscovery, token, nonce string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed ID token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed ID token header: %w", err)
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("unsupported ID token algorithm %q", header.Alg)
+	}
+	key, err := p.signingKey(ctx, discovery, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed ID token signature: %w", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, fmt.Errorf("invalid ID token signature")
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed ID token claims: %w", err)
+	}
+	if issuer, _ := claims["iss"].(string); strings.TrimSuffix(issuer, "/") != p.config.Issuer {
+		return nil, fmt.Errorf("ID token issued by %q", issuer)
+	}
+	if !audienceIncludes(claims["aud"], p.config.ClientID) {
+		return nil, fmt.Errorf("ID token not issued for this dashboard")
+	}
+	if expiry, _ := claims["exp"].(float64); !p.now().Before(time.Unix(int64(expiry), 0)) {
+		return nil, fmt.Errorf("ID token expired")
+	}
+	if claimed, _ := claims["nonce"].(string); claimed != nonce {
+		return nil, fmt.Errorf("ID token nonce mismatch")
+	}
+	return claims, nil
+}
+
+// signingKey returns the provider key with an ID, refetching the key set
+// when the provider has rotated to a key not seen yet
+func (p *oidcProvider) signingKey(ctx context.Context, discovery oidcDiscovery, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	key, exists := p.keys[kid]
+	p.mu.Unlock()
+	if exists {
+		return key, nil
+	}
+
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := p.getJSON(ctx, discovery.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC signing keys: %w", err)
+	}
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+	if key, exists := keys[kid]; exists {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown ID token signing key %q", kid)
+}
+
+// newSession maps an ID token's groups to roles and a tenant
+func (p *oidcProvider) newSession(claims map[string]interface{}) (*oidcSession, error) {
+	session := &oidcSession{ExpiresAt: p.now().Add(p.config.SessionTTL)}
+	session.Subject, _ = claims["sub"].(string)
+	session.Email, _ = claims["email"].(string)
+	session.Name, _ = claims["name"].(string)
+	switch groups := claims[p.config.GroupsClaim].(type) {
+	case string:
+		session.Groups = []string{groups}
+	case []interface{}:
+		for _, group := range groups {
+			if name, ok := group.(string); ok {
+				session.Groups = append(session.Groups, name)
+			}
+		}
+	}
+	sort.Strings(session.Groups)
+
+	granted := make(map[apikeys.Scope]bool)
+	for _, group := range session.Groups {
+		if role, mapped := p.config.GroupRoles[group]; mapped && !granted[role] {
+			granted[role] = true
+			session.Scopes = append(session.Scopes, role)
+		}
+		if tenant, mapped := p.config.GroupTenants[group]; mapped {
+			if session.Tenant != "" && session.Tenant != tenant {
+				return nil, fmt.Errorf("%w: %s belongs to tenants %s and %s", errNoDashboardRole, session.actor(), session.Tenant, tenant)
+			}
+			session.Tenant = tenant
+		}
+	}
+	if len(session.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: none of %s's groups is mapped", errNoDashboardRole, session.actor())
+	}
+	return session, nil
+}
+
+// session returns an unexpired session by ID
+func (p *oidcProvider) session(id string) (*oidcSession, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	session, exists := p.sessions[id]
+	if !exists {
+		return nil, false
+	}
+	if p.now().After(session.ExpiresAt) {
+		delete(p.sessions, id)
+		return nil, false
+	}
+	return session, true
+}
+
+// logout ends a session
+func (p *oidcProvider) logout(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.sessions, id)
+}
+
+// getJSON fetches a provider document
+func (p *oidcProvider) getJSON(ctx context.Context, endpoint string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// decodeSegment decodes a base64url JSON segment of a JWT
+func decodeSegment(segment string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// audienceIncludes reports whether an aud claim, a string or a list,
+// names the client
+func audienceIncludes(aud interface{}, clientID string) bool {
+	switch audience := aud.(type) {
+	case string:
+		return audience == clientID
+	case []interface{}:
+		for _, entry := range audience {
+			if entry == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// randomToken returns 32 random bytes as hex, for states, nonces and sessions
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,274 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// onCallEntityPrefix namespaces incident IDs in external on-call tools
+const onCallEntityPrefix = "agentaflow-"
+
+// Notifier delivers incident changes to an external on-call tool
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, incident Incident, change string) error
+}
+
+// AckSource is implemented by notifiers that can report which incidents have
+// been acknowledged in the external tool
+type AckSource interface {
+	AcknowledgedIncidents(ctx context.Context) ([]string, error)
+}
+
+// OnCallConfig controls incident delivery to on-call tools
+type OnCallConfig struct {
+	QueueSize    int           `yaml:"queue_size" json:"queue_size"`       // Incident changes buffered for delivery
+	SyncInterval time.Duration `yaml:"sync_interval" json:"sync_interval"` // How often acknowledgements are pulled back
+	Timeout      time.Duration `yaml:"timeout" json:"timeout"`             // Per-request timeout
+}
+
+// DefaultOnCallConfig returns the default on-call configuration
+func DefaultOnCallConfig() OnCallConfig {
+	return OnCallConfig{
+		QueueSize:    100,
+		SyncInterval: time.Minute,
+		Timeout:      10 * time.Second,
+	}
+}
+
+// OnCallDispatcher forwards incident changes from an alert grouper to on-call
+// notifiers and closes incidents that are acknowledged in those tools
+type OnCallDispatcher struct {
+	config    OnCallConfig
+	grouper   *AlertGrouper
+	notifiers []Notifier
+	queue     chan incidentChange
+
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+	sent    int
+	failed  int
+	dropped int
+	acked   int
+	lastErr error
+	errs    map[string]int64 // Failures by notifier and operation
+	mu      sync.RWMutex
+}
+
+// NewOnCallDispatcher creates a dispatcher subscribed to the grouper's
+// incident changes. Changes are queued until Start is called.
+func NewOnCallDispatcher(config OnCallConfig, grouper *AlertGrouper, notifiers ...Notifier) *OnCallDispatcher {
+	defaults := DefaultOnCallConfig()
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaults.QueueSize
+	}
+	if config.SyncInterval <= 0 {
+		config.SyncInterval = defaults.SyncInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+
+	d := &OnCallDispatcher{
+		config:    config,
+		grouper:   grouper,
+		notifiers: notifiers,
+		queue:     make(chan incidentChange, config.QueueSize),
+		errs:      make(map[string]int64),
+	}
+	grouper.Subscribe(d.enqueue)
+	return d
+}
+
+// enqueue queues an incident change without blocking alert processing
+func (d *OnCallDispatcher) enqueue(incident Incident, change string) {
+	select {
+	case d.queue <- incidentChange{incident, change}:
+	default:
+		d.mu.Lock()
+		d.dropped++
+		d.mu.Unlock()
+		log.Printf("On-call queue full, dropped %s change for %s", change, incident.ID)
+	}
+}
+
+// Start begins delivering incident changes and syncing acknowledgements
+func (d *OnCallDispatcher) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopCh != nil {
+		return
+	}
+	d.stopCh = make(chan struct{})
+	d.doneCh = make(chan struct{})
+	go d.run(d.stopCh, d.doneCh)
+}
+
+// Stop halts delivery after flushing queued changes
+func (d *OnCallDispatcher) Stop() {
+	d.mu.Lock()
+	stopCh, doneCh := d.stopCh, d.doneCh
+	d.stopCh, d.doneCh = nil, nil
+	d.mu.Unlock()
+
+	if stopCh == nil {
+		return
+	}
+	close(stopCh)
+	<-doneCh
+}
+
+// run delivers queued changes and syncs acknowledgements until stopped
+func (d *OnCallDispatcher) run(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(d.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case change := <-d.queue:
+			d.deliver(change)
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), d.config.Timeout)
+			d.SyncAcknowledgements(ctx)
+			cancel()
+		case <-stopCh:
+			for {
+				select {
+				case change := <-d.queue:
+					d.deliver(change)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver sends one incident change to every notifier
+func (d *OnCallDispatcher) deliver(change incidentChange) {
+	for _, notifier := range d.notifiers {
+		// Don't echo an acknowledgement back to the tool it came from
+		if change.change == IncidentClosed && change.incident.AckedBy == notifier.Name() {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), d.config.Timeout)
+		err := notifier.Notify(ctx, change.incident, change.change)
+		cancel()
+
+		d.mu.Lock()
+		if err != nil {
+			d.failed++
+			d.errs[notifier.Name()+"_delivery"]++
+			d.lastErr = fmt.Errorf("%s: %w", notifier.Name(), err)
+		} else {
+			d.sent++
+		}
+		d.mu.Unlock()
+		if err != nil {
+			log.Printf("Failed to notify %s of incident %s: %v", notifier.Name(), change.incident.ID, err)
+		}
+	}
+}
+
+// SyncAcknowledgements closes incidents acknowledged in external tools and
+// returns how many were closed
+func (d *OnCallDispatcher) SyncAcknowledgements(ctx context.Context) int {
+	closed := 0
+	for _, notifier := range d.notifiers {
+		source, ok := notifier.(AckSource)
+		if !ok {
+			continue
+		}
+		ids, err := source.AcknowledgedIncidents(ctx)
+		if err != nil {
+			d.mu.Lock()
+			d.errs[notifier.Name()+"_ack_sync"]++
+			d.lastErr = fmt.Errorf("%s: %w", notifier.Name(), err)
+			d.mu.Unlock()
+			continue
+		}
+		for _, id := range ids {
+			if d.grouper.Acknowledge(id, notifier.Name(), time.Now()) {
+				closed++
+			}
+		}
+	}
+
+	d.mu.Lock()
+	d.acked += closed
+	d.mu.Unlock()
+	return closed
+}
+
+// GetErrors returns the total failures by notifier and operation, such as
+// opsgenie_delivery or victorops_ack_sync
+func (d *OnCallDispatcher) GetErrors() map[string]int64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	errs := make(map[string]int64, len(d.errs))
+	for reason, count := range d.errs {
+		errs[reason] = count
+	}
+	return errs
+}
+
+// GetStats returns delivery statistics
+func (d *OnCallDispatcher) GetStats() map[string]interface{} {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	names := make([]string, 0, len(d.notifiers))
+	for _, notifier := range d.notifiers {
+		names = append(names, notifier.Name())
+	}
+	stats := map[string]interface{}{
+		"notifiers":           names,
+		"running":             d.stopCh != nil,
+		"queued":              len(d.queue),
+		"notifications_sent":  d.sent,
+		"notifications_error": d.failed,
+		"changes_dropped":     d.dropped,
+		"acks_synced":         d.acked,
+	}
+	if d.lastErr != nil {
+		stats["last_error"] = d.lastErr.Error()
+	}
+	return stats
+}
+
+// onCallEntityID returns the ID an incident is known by in external tools
+func onCallEntityID(incident Incident) string {
+	return onCallEntityPrefix + incident.ID
+}
+
+// incidentIDFromEntity reverses onCallEntityID, returning false for entities
+// created by other sources
+func incidentIDFromEntity(entityID string) (string, bool) {
+	if !strings.HasPrefix(entityID, onCallEntityPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(entityID, onCallEntityPrefix), true
+}
+
+// incidentDescription lists an incident's child alerts for on-call tools
+func incidentDescription(incident Incident) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d alerts across %d GPUs since %s\n", incident.AlertCount, incident.GPUCount, incident.StartedAt.UTC().Format(time.RFC3339))
+	for _, child := range incident.Alerts {
+		state := "resolved"
+		if child.Firing {
+			state = "firing"
+		}
+		fmt.Fprintf(&b, "- %s [%s, %s]: %s\n", child.GPUID, child.Alert.Severity, state, child.Alert.Message)
+	}
+	return b.String()
+}
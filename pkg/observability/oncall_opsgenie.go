@@ -0,0 +1,163 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// OpsgenieConfig configures the Opsgenie notifier
+type OpsgenieConfig struct {
+	APIKey string   `yaml:"api_key" json:"api_key" secret:"true"` // API integration key
+	APIURL string   `yaml:"api_url" json:"api_url"`               // https://api.eu.opsgenie.com for EU accounts
+	Team   string   `yaml:"team" json:"team"`                     // Optional responder team
+	Tags   []string `yaml:"tags" json:"tags"`
+}
+
+// opsgenieTag marks alerts created by AgentaFlow so acknowledgements can be found
+const opsgenieTag = "agentaflow"
+
+// OpsgenieNotifier creates, escalates and closes Opsgenie alerts for incidents
+type OpsgenieNotifier struct {
+	config OpsgenieConfig
+	client *http.Client
+}
+
+// NewOpsgenieNotifier creates an Opsgenie notifier
+func NewOpsgenieNotifier(config OpsgenieConfig) (*OpsgenieNotifier, error) {
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("opsgenie API key is required")
+	}
+	if config.APIURL == "" {
+		config.APIURL = "https://api.opsgenie.com"
+	}
+	config.APIURL = strings.TrimSuffix(config.APIURL, "/")
+
+	return &OpsgenieNotifier{config: config, client: &http.Client{}}, nil
+}
+
+// Name returns the notifier name
+func (n *OpsgenieNotifier) Name() string {
+	return "opsgenie"
+}
+
+// Notify creates the alert when an incident opens, raises its priority when
+// it escalates and closes it when it resolves
+func (n *OpsgenieNotifier) Notify(ctx context.Context, incident Incident, change string) error {
+	alias := url.PathEscape(onCallEntityID(incident))
+
+	switch change {
+	case IncidentOpened:
+		message := incident.Title
+		if len(message) > 130 {
+			message = message[:130]
+		}
+		details := map[string]string{
+			"incident_id": incident.ID,
+			"alert_count": fmt.Sprintf("%d", incident.AlertCount),
+			"gpu_count":   fmt.Sprintf("%d", incident.GPUCount),
+		}
+		for k, v := range incident.Labels {
+			details[k] = v
+		}
+		body := map[string]interface{}{
+			"message":     message,
+			"alias":       onCallEntityID(incident),
+			"description": incidentDescription(incident),
+			"priority":    opsgeniePriority(incident.Severity),
+			"tags":        append([]string{opsgenieTag}, n.config.Tags...),
+			"details":     details,
+			"source":      "agentaflow",
+		}
+		if n.config.Team != "" {
+			body["responders"] = []map[string]string{{"type": "team", "name": n.config.Team}}
+		}
+		return n.do(ctx, http.MethodPost, "/v2/alerts", body, nil)
+	case IncidentEscalated:
+		body := map[string]string{"priority": opsgeniePriority(incident.Severity)}
+		return n.do(ctx, http.MethodPut, "/v2/alerts/"+alias+"/priority?identifierType=alias", body, nil)
+	case IncidentClosed:
+		body := map[string]string{"source": "agentaflow", "note": "Resolved in AgentaFlow"}
+		return n.do(ctx, http.MethodPost, "/v2/alerts/"+alias+"/close?identifierType=alias", body, nil)
+	default:
+		return nil
+	}
+}
+
+// AcknowledgedIncidents returns incidents whose Opsgenie alerts are open but acknowledged
+func (n *OpsgenieNotifier) AcknowledgedIncidents(ctx context.Context) ([]string, error) {
+	query := url.Values{
+		"query": {"status:open AND acknowledged:true AND tag:" + opsgenieTag},
+		"limit": {"100"},
+	}
+	var response struct {
+		Data []struct {
+			Alias        string `json:"alias"`
+			Acknowledged bool   `json:"acknowledged"`
+		} `json:"data"`
+	}
+	if err := n.do(ctx, http.MethodGet, "/v2/alerts?"+query.Encode(), nil, &response); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(response.Data))
+	for _, alert := range response.Data {
+		if id, ok := incidentIDFromEntity(alert.Alias); ok && alert.Acknowledged {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// do sends an Opsgenie API request and decodes the response into out, if set
+func (n *OpsgenieNotifier) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, n.config.APIURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "GenieKey "+n.config.APIKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("opsgenie %s %s returned %d: %s", method, strings.SplitN(path, "?", 2)[0], resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// opsgeniePriority maps incident severity to an Opsgenie priority
+func opsgeniePriority(severity string) string {
+	switch severity {
+	case "critical":
+		return "P1"
+	case "warning":
+		return "P3"
+	default:
+		return "P5"
+	}
+}
@@ -0,0 +1,216 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// recordedRequest is a request captured by a fake on-call API
+type recordedRequest struct {
+	method string
+	path   string
+	header http.Header
+	body   map[string]interface{}
+}
+
+// fakeOnCallAPI records requests and answers GETs with a canned body
+type fakeOnCallAPI struct {
+	*httptest.Server
+	getBody  string
+	requests []recordedRequest
+	mu       sync.Mutex
+}
+
+func newFakeOnCallAPI(getBody string) *fakeOnCallAPI {
+	api := &fakeOnCallAPI{getBody: getBody}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := recordedRequest{method: r.Method, path: r.URL.RequestURI(), header: r.Header}
+		json.NewDecoder(r.Body).Decode(&request.body)
+
+		api.mu.Lock()
+		api.requests = append(api.requests, request)
+		api.mu.Unlock()
+
+		if r.Method == http.MethodGet {
+			w.Write([]byte(api.getBody))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	return api
+}
+
+func (api *fakeOnCallAPI) recorded() []recordedRequest {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return append([]recordedRequest(nil), api.requests...)
+}
+
+func TestOpsgenieNotifierLifecycle(t *testing.T) {
+	api := newFakeOnCallAPI(`{"data":[{"alias":"agentaflow-incident-1","acknowledged":true},{"alias":"other-tool","acknowledged":true}]}`)
+	defer api.Close()
+
+	notifier, err := NewOpsgenieNotifier(OpsgenieConfig{APIKey: "key", APIURL: api.URL, Team: "gpu-oncall"})
+	if err != nil {
+		t.Fatalf("NewOpsgenieNotifier failed: %v", err)
+	}
+	incident := Incident{ID: "incident-1", Title: "Temperature alerts on 2 GPUs on node-a", Severity: "warning", Labels: map[string]string{"node": "node-a"}}
+
+	ctx := context.Background()
+	for _, change := range []string{IncidentOpened, IncidentEscalated, IncidentClosed} {
+		if change == IncidentEscalated {
+			incident.Severity = "critical"
+		}
+		if err := notifier.Notify(ctx, incident, change); err != nil {
+			t.Fatalf("Notify %s failed: %v", change, err)
+		}
+	}
+
+	requests := api.recorded()
+	if len(requests) != 3 {
+		t.Fatalf("Expected 3 requests, got %d", len(requests))
+	}
+	create := requests[0]
+	if create.method != http.MethodPost || create.path != "/v2/alerts" || create.header.Get("Authorization") != "GenieKey key" {
+		t.Errorf("Unexpected create request %s %s", create.method, create.path)
+	}
+	if create.body["alias"] != "agentaflow-incident-1" || create.body["priority"] != "P3" {
+		t.Errorf("Unexpected create body %v", create.body)
+	}
+	if requests[1].path != "/v2/alerts/agentaflow-incident-1/priority?identifierType=alias" || requests[1].body["priority"] != "P1" {
+		t.Errorf("Unexpected escalation %s %v", requests[1].path, requests[1].body)
+	}
+	if requests[2].path != "/v2/alerts/agentaflow-incident-1/close?identifierType=alias" {
+		t.Errorf("Unexpected close path %s", requests[2].path)
+	}
+
+	acked, err := notifier.AcknowledgedIncidents(ctx)
+	if err != nil {
+		t.Fatalf("AcknowledgedIncidents failed: %v", err)
+	}
+	if len(acked) != 1 || acked[0] != "incident-1" {
+		t.Errorf("Expected only incident-1 acknowledged, got %v", acked)
+	}
+	if query := api.recorded()[3].path; !strings.Contains(query, "acknowledged%3Atrue") {
+		t.Errorf("Expected the query to filter acknowledged alerts, got %s", query)
+	}
+
+	if _, err := NewOpsgenieNotifier(OpsgenieConfig{}); err == nil {
+		t.Error("Expected a missing API key to be rejected")
+	}
+}
+
+func TestVictorOpsNotifierLifecycle(t *testing.T) {
+	api := newFakeOnCallAPI(`{"incidents":[{"entityId":"agentaflow-incident-2","currentPhase":"ACKED"},{"entityId":"agentaflow-incident-3","currentPhase":"UNACKED"}]}`)
+	defer api.Close()
+
+	notifier, err := NewVictorOpsNotifier(VictorOpsConfig{
+		RESTEndpointURL: api.URL + "/integrations/generic/20131114/alert/secret",
+		RoutingKey:      "gpu",
+		APIID:           "id",
+		APIKey:          "key",
+		APIURL:          api.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewVictorOpsNotifier failed: %v", err)
+	}
+
+	incident := Incident{ID: "incident-2", Title: "Memory alerts on 1 GPU on node-b", Severity: "critical"}
+	ctx := context.Background()
+	if err := notifier.Notify(ctx, incident, IncidentOpened); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if err := notifier.Notify(ctx, incident, IncidentClosed); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	requests := api.recorded()
+	if requests[0].path != "/integrations/generic/20131114/alert/secret/gpu" {
+		t.Errorf("Unexpected REST path %s", requests[0].path)
+	}
+	if requests[0].body["message_type"] != "CRITICAL" || requests[1].body["message_type"] != "RECOVERY" {
+		t.Errorf("Expected CRITICAL then RECOVERY, got %v then %v", requests[0].body["message_type"], requests[1].body["message_type"])
+	}
+	if requests[0].body["entity_id"] != "agentaflow-incident-2" {
+		t.Errorf("Unexpected entity ID %v", requests[0].body["entity_id"])
+	}
+
+	acked, err := notifier.AcknowledgedIncidents(ctx)
+	if err != nil {
+		t.Fatalf("AcknowledgedIncidents failed: %v", err)
+	}
+	if len(acked) != 1 || acked[0] != "incident-2" {
+		t.Errorf("Expected only incident-2 acknowledged, got %v", acked)
+	}
+	if header := api.recorded()[2].header; header.Get("X-VO-Api-Id") != "id" || header.Get("X-VO-Api-Key") != "key" {
+		t.Errorf("Expected API credentials on the incidents request, got %v", header)
+	}
+}
+
+// stubNotifier records changes and reports a fixed set of acknowledgements
+type stubNotifier struct {
+	acked   []string
+	changes []string
+	mu      sync.Mutex
+}
+
+func (n *stubNotifier) Name() string { return "stub" }
+
+func (n *stubNotifier) Notify(ctx context.Context, incident Incident, change string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.changes = append(n.changes, incident.ID+" "+change)
+	return nil
+}
+
+func (n *stubNotifier) AcknowledgedIncidents(ctx context.Context) ([]string, error) {
+	return n.acked, nil
+}
+
+func (n *stubNotifier) recorded() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.changes...)
+}
+
+func TestOnCallDispatcherSyncsAcknowledgements(t *testing.T) {
+	grouper, err := NewAlertGrouper(DefaultAlertGroupingConfig())
+	if err != nil {
+		t.Fatalf("NewAlertGrouper failed: %v", err)
+	}
+	notifier := &stubNotifier{}
+	dispatcher := NewOnCallDispatcher(OnCallConfig{SyncInterval: time.Hour}, grouper, notifier)
+	dispatcher.Start()
+
+	alert := gpu.GPUAlert{Type: "temperature", Severity: "critical", Timestamp: time.Now()}
+	id := grouper.Add("node-a/gpu-0", "A100", alert, true)
+	grouper.Add("node-a/gpu-1", "A100", alert, true)
+	waitFor(t, func() bool { return len(notifier.recorded()) == 1 })
+
+	notifier.acked = []string{id, "incident-unknown"}
+	if closed := dispatcher.SyncAcknowledgements(context.Background()); closed != 1 {
+		t.Fatalf("Expected 1 incident closed, got %d", closed)
+	}
+
+	incident, _ := grouper.GetIncident(id)
+	if incident.Status != IncidentResolved || incident.AckedBy != "stub" || !incident.Alerts[0].Alert.Acknowledged {
+		t.Errorf("Expected the incident closed by the acknowledgement, got %+v", incident)
+	}
+
+	dispatcher.Stop()
+	if changes := notifier.recorded(); len(changes) != 1 {
+		t.Errorf("Expected the acknowledgement not to be echoed back, got %v", changes)
+	}
+	stats := dispatcher.GetStats()
+	if stats["notifications_sent"] != 1 || stats["acks_synced"] != 1 {
+		t.Errorf("Unexpected stats %v", stats)
+	}
+}
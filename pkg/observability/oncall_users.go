@@ -0,0 +1,265 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/airgap"
+)
+
+// smtpTimeout bounds connecting to the mail server and sending one email
+const smtpTimeout = 30 * time.Second
+
+// UserNotifierConfig configures email delivery for user notification preferences
+type UserNotifierConfig struct {
+	SMTPAddr string `yaml:"smtp_addr" json:"smtp_addr"` // host:port; email is not sent when empty
+	From     string `yaml:"from" json:"from"`
+	Username string `yaml:"username" json:"username"` // PLAIN auth, optional
+	Password string `yaml:"password" json:"-" secret:"true"`
+
+	// WebhookHosts, when set, are the only hosts user webhooks may call. Other
+	// webhooks may only reach public addresses, so users cannot make the
+	// server call into its own network.
+	WebhookHosts   []string      `yaml:"webhook_hosts" json:"webhook_hosts,omitempty"`
+	WebhookTimeout time.Duration `yaml:"webhook_timeout" json:"webhook_timeout"` // Defaults to 10s
+}
+
+// ErrWebhookHostNotAllowed is returned for a user webhook the notifier refuses to call
+var ErrWebhookHostNotAllowed = errors.New("webhook host not allowed")
+
+// UserNotifier emails and calls the webhooks of users whose notification
+// preferences ask for an incident's severity
+type UserNotifier struct {
+	config   UserNotifierConfig
+	store    *NotificationPreferenceStore
+	client   *http.Client
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewUserNotifier creates a notifier delivering to the users of a preference store
+func NewUserNotifier(config UserNotifierConfig, store *NotificationPreferenceStore) (*UserNotifier, error) {
+	if store == nil {
+		return nil, fmt.Errorf("notification preference store is required")
+	}
+	if config.SMTPAddr != "" && config.From == "" {
+		return nil, fmt.Errorf("from address is required to send email")
+	}
+	if config.WebhookTimeout <= 0 {
+		config.WebhookTimeout = 10 * time.Second
+	}
+
+	if config.SMTPAddr != "" {
+		registerOutboundEndpoint("smtp", config.SMTPAddr)
+	}
+
+	n := &UserNotifier{config: config, store: store, sendMail: sendMail}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = n.dialWebhook
+	n.client = &http.Client{
+		Timeout:   config.WebhookTimeout,
+		Transport: transport,
+		// A redirect could lead a webhook past the host allowlist
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	return n, nil
+}
+
+// allowsWebhookHost reports whether host is on the webhook allowlist
+func (n *UserNotifier) allowsWebhookHost(host string) bool {
+	for _, allowed := range n.config.WebhookHosts {
+		if strings.EqualFold(host, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// dialWebhook resolves the host of a webhook once and dials the address it
+// checked, so the name cannot resolve elsewhere between check and dial. The
+// dial goes through the air gap guard when one is installed.
+func (n *UserNotifier) dialWebhook(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	listed := n.allowsWebhookHost(host)
+	if len(n.config.WebhookHosts) > 0 && !listed {
+		return nil, fmt.Errorf("%w: %s is not in webhook_hosts", ErrWebhookHostNotAllowed, host)
+	}
+
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("%s does not resolve", host)
+	}
+	if !listed {
+		for _, ip := range ips {
+			if internalIP(ip) {
+				return nil, fmt.Errorf("%w: %s resolves to internal address %s", ErrWebhookHostNotAllowed, host, ip)
+			}
+		}
+	}
+
+	return airgap.DialContext(ctx, network, net.JoinHostPort(ips[0].String(), port))
+}
+
+// internalIP reports whether ip is a loopback, private, link-local,
+// multicast or unspecified address
+func internalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
+
+// Name returns the notifier name
+func (n *UserNotifier) Name() string {
+	return "users"
+}
+
+// Notify delivers the incident change to every user who wants its severity
+// by email or webhook. Failed deliveries do not stop the remaining users.
+func (n *UserNotifier) Notify(ctx context.Context, incident Incident, change string) error {
+	var failures []string
+	for _, preferences := range n.store.List() {
+		if preferences.Wants(incident.Severity, ChannelEmail) && n.config.SMTPAddr != "" {
+			if err := n.email(preferences.Email, incident, change); err != nil {
+				failures = append(failures, fmt.Sprintf("email to %s: %v", preferences.User, err))
+			}
+		}
+		if preferences.Wants(incident.Severity, ChannelWebhook) {
+			if err := n.webhook(ctx, preferences, incident, change); err != nil {
+				failures = append(failures, fmt.Sprintf("webhook for %s: %v", preferences.User, err))
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("user notifications failed: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// email sends an incident change to one address
+func (n *UserNotifier) email(to string, incident Incident, change string) error {
+	var auth smtp.Auth
+	if n.config.Username != "" {
+		host := n.config.SMTPAddr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", n.config.Username, n.config.Password, host)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: [AgentaFlow %s] %s: %s\r\n", strings.ToUpper(incident.Severity), change, incident.Title)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(incidentDescription(incident), "\n", "\r\n"))
+
+	return n.sendMail(n.config.SMTPAddr, auth, n.config.From, []string{to}, msg.Bytes())
+}
+
+// sendMail sends like smtp.SendMail, but dials through the air gap guard so
+// a mail server outside the allowed networks is refused and recorded
+func sendMail(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), smtpTimeout)
+	defer cancel()
+	conn, err := airgap.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(msg); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// webhook posts an incident change to a user's webhook
+func (n *UserNotifier) webhook(ctx context.Context, preferences NotificationPreferences, incident Incident, change string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"user":     preferences.User,
+		"change":   change,
+		"incident": incident,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, preferences.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("returned %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUserNotifier(t *testing.T) {
+	var hooks []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		hooks = append(hooks, body)
+	}))
+	defer server.Close()
+
+	store, _ := NewNotificationPreferenceStore("")
+	store.Set(NotificationPreferences{
+		User:       "alice",
+		Email:      "alice@example.com",
+		Severities: map[string][]string{"critical": {ChannelEmail}, "warning": {ChannelBrowser}},
+	})
+	store.Set(NotificationPreferences{
+		User:       "bob",
+		WebhookURL: server.URL,
+		Severities: map[string][]string{"critical": {ChannelWebhook}, "warning": {ChannelWebhook}},
+	})
+
+	notifier, err := NewUserNotifier(UserNotifierConfig{
+		SMTPAddr:     "smtp.example.com:587",
+		From:         "agentaflow@example.com",
+		WebhookHosts: []string{"127.0.0.1"},
+	}, store)
+	if err != nil {
+		t.Fatalf("NewUserNotifier failed: %v", err)
+	}
+	var mails []string
+	notifier.sendMail = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		mails = append(mails, to[0]+"\n"+string(msg))
+		return nil
+	}
+
+	critical := Incident{ID: "inc-1", Title: "GPU temperature critical", Severity: "critical"}
+	if err := notifier.Notify(context.Background(), critical, IncidentOpened); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if len(mails) != 1 || !strings.HasPrefix(mails[0], "alice@example.com\n") || !strings.Contains(mails[0], "Subject: [AgentaFlow CRITICAL] opened: GPU temperature critical") {
+		t.Errorf("Expected one critical email to alice, got %q", mails)
+	}
+	if len(hooks) != 1 || hooks[0]["user"] != "bob" || hooks[0]["change"] != IncidentOpened {
+		t.Errorf("Expected one webhook call for bob, got %+v", hooks)
+	}
+
+	// Alice only wants warnings in the browser
+	if err := notifier.Notify(context.Background(), Incident{ID: "inc-2", Severity: "warning"}, IncidentOpened); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if len(mails) != 1 || len(hooks) != 2 {
+		t.Errorf("Expected only bob's webhook for a warning, got %d emails and %d webhooks", len(mails), len(hooks))
+	}
+
+	if _, err := NewUserNotifier(UserNotifierConfig{SMTPAddr: "smtp.example.com:587"}, store); err == nil {
+		t.Error("Expected an error for email without a from address")
+	}
+}
+
+func TestUserNotifierRefusesInternalWebhooks(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	store, _ := NewNotificationPreferenceStore("")
+	store.Set(NotificationPreferences{
+		User:       "mallory",
+		WebhookURL: server.URL,
+		Severities: map[string][]string{"critical": {ChannelWebhook}},
+	})
+	critical := Incident{ID: "inc-1", Severity: "critical"}
+
+	// Without an allowlist, only public addresses are called
+	notifier, _ := NewUserNotifier(UserNotifierConfig{}, store)
+	if err := notifier.Notify(context.Background(), critical, IncidentOpened); err == nil || !strings.Contains(err.Error(), ErrWebhookHostNotAllowed.Error()) {
+		t.Errorf("Expected a loopback webhook to be refused, got %v", err)
+	}
+
+	// With an allowlist, only listed hosts are called
+	notifier, _ = NewUserNotifier(UserNotifierConfig{WebhookHosts: []string{"hooks.example.com"}}, store)
+	if err := notifier.Notify(context.Background(), critical, IncidentOpened); err == nil || !strings.Contains(err.Error(), ErrWebhookHostNotAllowed.Error()) {
+		t.Errorf("Expected an unlisted webhook host to be refused, got %v", err)
+	}
+	if called {
+		t.Error("Expected no refused webhook to be called")
+	}
+	if notifier.client.Timeout != 10*time.Second {
+		t.Errorf("Expected a default webhook timeout of 10s, got %v", notifier.client.Timeout)
+	}
+}
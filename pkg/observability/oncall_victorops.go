@@ -0,0 +1,150 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// VictorOpsConfig configures the Splunk On-Call (VictorOps) notifier. The
+// REST endpoint sends alerts; the optional API ID and key enable pulling
+// acknowledgements back from the public API.
+type VictorOpsConfig struct {
+	RESTEndpointURL string `yaml:"rest_endpoint_url" json:"rest_endpoint_url" secret:"true"` // REST integration URL including its key
+	RoutingKey      string `yaml:"routing_key" json:"routing_key"`
+	APIID           string `yaml:"api_id" json:"api_id"`
+	APIKey          string `yaml:"api_key" json:"api_key" secret:"true"`
+	APIURL          string `yaml:"api_url" json:"api_url"`
+}
+
+// VictorOpsNotifier sends incidents to Splunk On-Call
+type VictorOpsNotifier struct {
+	config VictorOpsConfig
+	client *http.Client
+}
+
+// NewVictorOpsNotifier creates a Splunk On-Call notifier
+func NewVictorOpsNotifier(config VictorOpsConfig) (*VictorOpsNotifier, error) {
+	if config.RESTEndpointURL == "" {
+		return nil, fmt.Errorf("splunk on-call REST endpoint URL is required")
+	}
+	if config.RoutingKey == "" {
+		return nil, fmt.Errorf("splunk on-call routing key is required")
+	}
+	if config.APIURL == "" {
+		config.APIURL = "https://api.victorops.com"
+	}
+	config.RESTEndpointURL = strings.TrimSuffix(config.RESTEndpointURL, "/")
+	config.APIURL = strings.TrimSuffix(config.APIURL, "/")
+
+	return &VictorOpsNotifier{config: config, client: &http.Client{}}, nil
+}
+
+// Name returns the notifier name
+func (n *VictorOpsNotifier) Name() string {
+	return "victorops"
+}
+
+// Notify sends the incident with a message type matching its severity, or a
+// recovery once it resolves
+func (n *VictorOpsNotifier) Notify(ctx context.Context, incident Incident, change string) error {
+	messageType := victorOpsMessageType(incident.Severity)
+	if change == IncidentClosed {
+		messageType = "RECOVERY"
+	}
+
+	body := map[string]interface{}{
+		"message_type":        messageType,
+		"entity_id":           onCallEntityID(incident),
+		"entity_display_name": incident.Title,
+		"state_message":       incidentDescription(incident),
+		"state_start_time":    incident.StartedAt.Unix(),
+		"monitoring_tool":     "agentaflow",
+	}
+	for k, v := range incident.Labels {
+		body["agentaflow_"+k] = v
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.RESTEndpointURL+"/"+n.config.RoutingKey, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return n.send(req, nil)
+}
+
+// AcknowledgedIncidents returns incidents acknowledged in Splunk On-Call.
+// Without API credentials acknowledgements are not synced.
+func (n *VictorOpsNotifier) AcknowledgedIncidents(ctx context.Context) ([]string, error) {
+	if n.config.APIID == "" || n.config.APIKey == "" {
+		return nil, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.config.APIURL+"/api-public/v1/incidents", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-VO-Api-Id", n.config.APIID)
+	req.Header.Set("X-VO-Api-Key", n.config.APIKey)
+
+	var response struct {
+		Incidents []struct {
+			EntityID     string `json:"entityId"`
+			CurrentPhase string `json:"currentPhase"`
+		} `json:"incidents"`
+	}
+	if err := n.send(req, &response); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0)
+	for _, incident := range response.Incidents {
+		if id, ok := incidentIDFromEntity(incident.EntityID); ok && incident.CurrentPhase == "ACKED" {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// send performs a request and decodes the response into out, if set
+func (n *VictorOpsNotifier) send(req *http.Request, out interface{}) error {
+	// The REST endpoint path embeds the integration key, so keep URLs out of errors
+	resp, err := n.client.Do(req)
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("splunk on-call %s failed: %w", req.Method, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("splunk on-call %s returned %d: %s", req.Method, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// victorOpsMessageType maps incident severity to a Splunk On-Call message type
+func victorOpsMessageType(severity string) string {
+	switch severity {
+	case "critical":
+		return "CRITICAL"
+	case "warning":
+		return "WARNING"
+	default:
+		return "INFO"
+	}
+}
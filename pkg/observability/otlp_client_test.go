@@ -0,0 +1,65 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/airgap"
+)
+
+func TestOTLPClientUploadsResourceSpans(t *testing.T) {
+	var received []*tracepb.ResourceSpans
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/x-protobuf" {
+			t.Errorf("Unexpected upload %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		for len(body) > 0 {
+			number, wireType, n := protowire.ConsumeTag(body)
+			if n < 0 || number != 1 || wireType != protowire.BytesType {
+				t.Fatalf("Unexpected field %d of type %d", number, wireType)
+			}
+			body = body[n:]
+			encoded, n := protowire.ConsumeBytes(body)
+			if n < 0 {
+				t.Fatal("Truncated resource_spans")
+			}
+			body = body[n:]
+			spans := &tracepb.ResourceSpans{}
+			if err := proto.Unmarshal(encoded, spans); err != nil {
+				t.Fatal(err)
+			}
+			received = append(received, spans)
+		}
+	}))
+	defer server.Close()
+
+	client := newOTLPHTTPClient(strings.TrimPrefix(server.URL, "http://"))
+	spans := []*tracepb.ResourceSpans{{SchemaUrl: "a"}, {SchemaUrl: "b"}}
+	if err := client.UploadTraces(context.Background(), spans); err != nil {
+		t.Fatalf("UploadTraces: %v", err)
+	}
+	if len(received) != 2 || received[0].SchemaUrl != "a" || received[1].SchemaUrl != "b" {
+		t.Errorf("Collector received %v", received)
+	}
+
+	// An installed guard refuses a collector outside the allowed networks
+	guard, err := airgap.NewGuard([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	guard.Install()
+	defer guard.Uninstall()
+	if err := newOTLPHTTPClient(server.URL+"/v1/traces").UploadTraces(context.Background(), spans); !errors.Is(err, airgap.ErrOutbound) {
+		t.Errorf("Expected the upload refused, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package observability
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// Pipeline sources registered by NewPipelineMonitor and the usual integrations
+const (
+	PipelineSourcePrometheusSync = "prometheus_sync"
+	PipelineSourceNvidiaSMI      = "nvidia_smi"
+	PipelineSourceKubernetes     = "kubernetes_api"
+	PipelineSourceNotifiers      = "notifiers"
+)
+
+// PipelineErrorCounter returns a component's total failures by reason, such as
+// MetricsCollector.GetCollectionErrors or OnCallDispatcher.GetErrors
+type PipelineErrorCounter func() map[string]int64
+
+// PipelineSourceStatus is the failure history of one pipeline source
+type PipelineSourceStatus struct {
+	Source      string           `json:"source"`
+	Errors      map[string]int64 `json:"errors"` // Totals by reason
+	LastFailure *time.Time       `json:"last_failure,omitempty"`
+	Degraded    bool             `json:"degraded"`
+}
+
+// PipelineStatus reports whether AgentaFlow's own monitoring is healthy
+type PipelineStatus struct {
+	Degraded bool                   `json:"degraded"`
+	Window   string                 `json:"window"`
+	Sources  []PipelineSourceStatus `json:"sources"`
+}
+
+// PipelineMonitor tracks failures of the monitoring pipeline itself: exporter
+// syncs, nvidia-smi invocations, Kubernetes API calls and notifier delivery.
+// A source is degraded while its failures grew within the degraded window.
+type PipelineMonitor struct {
+	exporter    *PrometheusExporter
+	window      time.Duration
+	sources     map[string]PipelineErrorCounter
+	totals      map[string]map[string]int64
+	lastFailure map[string]time.Time
+	mu          sync.RWMutex
+}
+
+// NewPipelineMonitor creates a monitor that mirrors failures into the
+// exporter's pipeline_errors_total counter and tracks the exporter's own syncs.
+// A zero window uses five minutes.
+func NewPipelineMonitor(exporter *PrometheusExporter, window time.Duration) *PipelineMonitor {
+	if window <= 0 {
+		window = 5 * time.Minute
+	}
+	pm := &PipelineMonitor{
+		exporter:    exporter,
+		window:      window,
+		sources:     make(map[string]PipelineErrorCounter),
+		totals:      make(map[string]map[string]int64),
+		lastFailure: make(map[string]time.Time),
+	}
+	if exporter != nil {
+		pm.AddSource(PipelineSourcePrometheusSync, exporter.GetSyncErrors)
+	}
+	return pm
+}
+
+// AddSource tracks a component's failure counters under a source name
+func (pm *PipelineMonitor) AddSource(source string, counter PipelineErrorCounter) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.sources[source] = counter
+	if pm.totals[source] == nil {
+		pm.totals[source] = make(map[string]int64)
+	}
+}
+
+// Check reads every source's counters, records when they last grew and
+// updates the exporter's self-monitoring metrics
+func (pm *PipelineMonitor) Check(now time.Time) PipelineStatus {
+	pm.mu.RLock()
+	sources := make(map[string]PipelineErrorCounter, len(pm.sources))
+	for source, counter := range pm.sources {
+		sources[source] = counter
+	}
+	pm.mu.RUnlock()
+
+	counts := make(map[string]map[string]int64, len(sources))
+	for source, counter := range sources {
+		counts[source] = counter()
+	}
+
+	status := PipelineStatus{Window: pm.window.String(), Sources: make([]PipelineSourceStatus, 0, len(counts))}
+	pm.mu.Lock()
+	for source, errs := range counts {
+		for reason, total := range errs {
+			if total > pm.totals[source][reason] {
+				pm.lastFailure[source] = now
+			}
+			pm.totals[source][reason] = total
+		}
+
+		sourceStatus := PipelineSourceStatus{Source: source, Errors: errs}
+		if last, failed := pm.lastFailure[source]; failed {
+			sourceStatus.LastFailure = &last
+			sourceStatus.Degraded = now.Sub(last) < pm.window
+		}
+		status.Degraded = status.Degraded || sourceStatus.Degraded
+		status.Sources = append(status.Sources, sourceStatus)
+	}
+	pm.mu.Unlock()
+	sort.Slice(status.Sources, func(i, j int) bool {
+		return status.Sources[i].Source < status.Sources[j].Source
+	})
+
+	if pm.exporter != nil {
+		for _, sourceStatus := range status.Sources {
+			for reason, total := range sourceStatus.Errors {
+				pm.exporter.setCounterTotal("pipeline_errors_total", float64(total),
+					map[string]string{"source": sourceStatus.Source, "reason": reason})
+			}
+		}
+		degraded := 0.0
+		if status.Degraded {
+			degraded = 1
+		}
+		pm.exporter.SetGauge("pipeline_degraded", degraded, nil)
+	}
+	return status
+}
+
+// RecordWatchdogEvent records a GPU watchdog event. Stalled loops and failed
+// restarts are critical; register it with Watchdog.OnEvent.
+func (ms *MonitoringService) RecordWatchdogEvent(event gpu.WatchdogEvent) {
+	severity := "critical"
+	message := fmt.Sprintf("%s loop has not completed a cycle in %v", event.Loop, event.Stalled.Round(time.Second))
+	switch event.Kind {
+	case gpu.WatchdogRestarted:
+		severity = "warning"
+		message = fmt.Sprintf("%s loop restarted by the watchdog", event.Loop)
+	case gpu.WatchdogRestartFailed:
+		message = fmt.Sprintf("%s loop could not be restarted: %s", event.Loop, event.Error)
+	case gpu.WatchdogRecovered:
+		severity = "info"
+		message = fmt.Sprintf("%s loop is completing cycles again", event.Loop)
+	}
+
+	metadata := map[string]interface{}{
+		"loop":       event.Loop,
+		"kind":       event.Kind,
+		"last_cycle": event.LastCycle,
+	}
+	if event.Stalled > 0 {
+		metadata["stalled_seconds"] = event.Stalled.Seconds()
+	}
+	if event.Error != "" {
+		metadata["error"] = event.Error
+	}
+	ms.RecordEvent(Event{
+		Type:     "watchdog",
+		Severity: severity,
+		Message:  message,
+		Source:   "gpu_watchdog",
+		Metadata: metadata,
+	})
+}
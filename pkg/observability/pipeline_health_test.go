@@ -0,0 +1,127 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// failingNotifier fails every delivery and acknowledgement sync
+type failingNotifier struct{}
+
+func (failingNotifier) Name() string { return "pager" }
+
+func (failingNotifier) Notify(ctx context.Context, incident Incident, change string) error {
+	return fmt.Errorf("connection refused")
+}
+
+func (failingNotifier) AcknowledgedIncidents(ctx context.Context) ([]string, error) {
+	return nil, fmt.Errorf("unauthorized")
+}
+
+func TestPipelineMonitor(t *testing.T) {
+	exporter := NewPrometheusExporter(nil, DefaultPrometheusConfig())
+	exporter.RegisterSystemMetrics()
+	monitor := NewPipelineMonitor(exporter, time.Minute)
+
+	smi := map[string]int64{}
+	monitor.AddSource(PipelineSourceNvidiaSMI, func() map[string]int64 { return smi })
+
+	now := time.Now()
+	if status := monitor.Check(now); status.Degraded || len(status.Sources) != 2 {
+		t.Fatalf("Expected a healthy pipeline with two sources, got %+v", status)
+	}
+
+	// The exporter counts its own failed syncs
+	exporter.SyncFromMonitoringService()
+	smi["exit_status"] = 3
+	status := monitor.Check(now)
+	if !status.Degraded || !status.Sources[0].Degraded || !status.Sources[1].Degraded {
+		t.Fatalf("Expected both sources degraded, got %+v", status)
+	}
+	if status.Sources[1].Source != PipelineSourcePrometheusSync || status.Sources[1].Errors[SyncErrorNoMonitoringService] != 1 {
+		t.Errorf("Expected the failed sync counted, got %+v", status.Sources[1])
+	}
+	exported := exporter.ExportMetrics()
+	for _, sample := range []string{
+		`agentaflow_pipeline_errors_total{reason="exit_status",source="nvidia_smi"} 3`,
+		`agentaflow_pipeline_degraded 1`,
+	} {
+		if !strings.Contains(exported, sample) {
+			t.Errorf("Expected %s in:\n%s", sample, exported)
+		}
+	}
+
+	// Sources recover once their counters stop growing for the window
+	if status := monitor.Check(now.Add(2 * time.Minute)); status.Degraded {
+		t.Errorf("Expected the pipeline to recover, got %+v", status)
+	}
+	if !strings.Contains(exporter.ExportMetrics(), "agentaflow_pipeline_degraded 0") {
+		t.Error("Expected the degraded gauge cleared")
+	}
+}
+
+func TestOnCallDispatcherCountsErrors(t *testing.T) {
+	grouper, _ := NewAlertGrouper(DefaultAlertGroupingConfig())
+	dispatcher := NewOnCallDispatcher(DefaultOnCallConfig(), grouper, failingNotifier{})
+	dispatcher.deliver(incidentChange{Incident{ID: "inc-1"}, IncidentOpened})
+	dispatcher.SyncAcknowledgements(context.Background())
+
+	errs := dispatcher.GetErrors()
+	if errs["pager_delivery"] != 1 || errs["pager_ack_sync"] != 1 {
+		t.Errorf("Expected delivery and sync failures counted, got %v", errs)
+	}
+}
+
+func TestPipelineStatusEndpoint(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{Port: 0})
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/system/pipeline", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a pipeline monitor, got %d", response.Code)
+	}
+
+	monitor := NewPipelineMonitor(nil, time.Minute)
+	monitor.AddSource(PipelineSourceKubernetes, func() map[string]int64 { return map[string]int64{"Forbidden": 1} })
+	dashboard.SetPipelineMonitor(monitor)
+
+	var status PipelineStatus
+	json.Unmarshal(serveDashboard(dashboard, http.MethodGet, "/api/v1/system/pipeline", "").Body.Bytes(), &status)
+	if !status.Degraded || len(status.Sources) != 1 || status.Sources[0].Errors["Forbidden"] != 1 {
+		t.Errorf("Expected the Kubernetes failure reported, got %+v", status)
+	}
+}
+
+func TestRecordWatchdogEvent(t *testing.T) {
+	ms := NewMonitoringService(100)
+	now := time.Now()
+	ms.RecordWatchdogEvent(gpu.WatchdogEvent{Loop: "collector", Kind: gpu.WatchdogStalled, Stalled: 45 * time.Second, Timestamp: now})
+	ms.RecordWatchdogEvent(gpu.WatchdogEvent{Loop: "collector", Kind: gpu.WatchdogRestarted, Timestamp: now})
+
+	critical := ms.GetEvents(now.Add(-time.Minute), now.Add(time.Minute), "critical")
+	if len(critical) != 1 || critical[0].Type != "watchdog" || critical[0].Message != "collector loop has not completed a cycle in 45s" {
+		t.Fatalf("critical events = %+v", critical)
+	}
+	if warnings := ms.GetEvents(now.Add(-time.Minute), now.Add(time.Minute), "warning"); len(warnings) != 1 {
+		t.Errorf("expected the restart as a warning, got %+v", warnings)
+	}
+}
+
+func TestRecordMemoryLeakSuspect(t *testing.T) {
+	ms := NewMonitoringService(100)
+	ms.RecordMemoryLeakSuspect(gpu.MemoryLeakSuspect{GPUID: "0", PID: 4242, ProcessName: "python", Workload: "trainer (wl-1)",
+		StartMemoryMB: 4000, MemoryMB: 9000, GrowthMB: 5000, Observed: 3 * time.Hour})
+
+	events := ms.GetEvents(time.Now().Add(-time.Minute), time.Now().Add(time.Minute), "warning")
+	if len(events) != 1 || events[0].Type != "gpu_memory_leak" {
+		t.Fatalf("events = %+v", events)
+	}
+	want := "Possible GPU memory leak: process python (PID 4242) of workload trainer (wl-1) on GPU 0 grew from 4000 MB to 9000 MB over 3h0m0s without a utilization change"
+	if events[0].Message != want || events[0].Metadata["pid"] != 4242 {
+		t.Errorf("event = %+v", events[0])
+	}
+}
@@ -2,10 +2,15 @@ package observability
 
 import (
 	"fmt"
+	"math"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving"
 )
 
 // PrometheusExporter exports metrics in Prometheus format
@@ -16,7 +21,7 @@ type PrometheusExporter struct {
 	// Metric registries
 	gaugeMetrics     map[string]float64
 	counterMetrics   map[string]float64
-	histogramMetrics map[string][]float64
+	histogramMetrics map[string]*histogramSeries
 
 	// Metric metadata
 	metricHelp   map[string]string
@@ -26,15 +31,61 @@ type PrometheusExporter struct {
 	// Configuration
 	metricsPrefix  string
 	enabledMetrics map[string]bool
+
+	// Staleness tracking for series whose source (GPU, instance) disappears
+	seriesUpdated     map[string]time.Time
+	staleSeries       map[string]bool
+	staleSeriesTTL    time.Duration
+	staleSeriesAction string
+
+	// Bounds on the raw observations each histogram keeps for windowed quantiles
+	histogramWindow     time.Duration
+	histogramMaxSamples int
+
+	// Bucket upper bounds by histogram, and overrides by metric name
+	histogramBuckets map[string][]float64
+	bucketOverrides  map[string][]float64
+
+	// Time of the last SyncFromMonitoringService, and its failures by reason
+	lastSync   time.Time
+	syncErrors map[string]int64
 }
 
+// SyncFromMonitoringService failure reasons
+const (
+	SyncErrorNoMonitoringService = "no_monitoring_service"
+	SyncErrorInvalidBufferStats  = "invalid_buffer_stats"
+)
+
 // PrometheusConfig configures the Prometheus exporter
 type PrometheusConfig struct {
-	MetricsPrefix  string            `json:"metrics_prefix"`
-	EnabledMetrics map[string]bool   `json:"enabled_metrics"`
-	MetricLabels   map[string]string `json:"metric_labels"`
+	MetricsPrefix  string            `yaml:"metrics_prefix" json:"metrics_prefix"`
+	EnabledMetrics map[string]bool   `yaml:"enabled_metrics" json:"enabled_metrics"`
+	MetricLabels   map[string]string `yaml:"metric_labels" json:"metric_labels"`
+
+	// StaleSeriesTTL is how long a series may go without updates before it is
+	// expired (0 disables expiry). StaleSeriesAction is "drop" to remove expired
+	// series from the exposition or "zero" to keep reporting them as 0.
+	StaleSeriesTTL    time.Duration `yaml:"stale_series_ttl" json:"stale_series_ttl"`
+	StaleSeriesAction string        `yaml:"stale_series_action" json:"stale_series_action"`
+
+	// Histograms export cumulative bucket counts, which take constant memory.
+	// For windowed quantiles each series also keeps at most HistogramMaxSamples
+	// raw observations, and only those within HistogramWindow are used.
+	HistogramWindow     time.Duration `yaml:"histogram_window" json:"histogram_window"`
+	HistogramMaxSamples int           `yaml:"histogram_max_samples" json:"histogram_max_samples"`
+
+	// HistogramBuckets replaces the bucket upper bounds of histograms by
+	// metric name without the prefix, e.g. inference_batch_size
+	HistogramBuckets map[string][]float64 `yaml:"histogram_buckets" json:"histogram_buckets"`
 }
 
+// Stale series actions
+const (
+	StaleSeriesDrop = "drop"
+	StaleSeriesZero = "zero"
+)
+
 // DefaultPrometheusConfig returns default Prometheus configuration
 func DefaultPrometheusConfig() PrometheusConfig {
 	return PrometheusConfig{
@@ -50,6 +101,11 @@ func DefaultPrometheusConfig() PrometheusConfig {
 			"instance": "agentaflow",
 			"version":  "community",
 		},
+		StaleSeriesTTL:    5 * time.Minute,
+		StaleSeriesAction: StaleSeriesDrop,
+
+		HistogramWindow:     10 * time.Minute,
+		HistogramMaxSamples: 1024,
 	}
 }
 
@@ -59,12 +115,22 @@ func NewPrometheusExporter(monitoringService *MonitoringService, config Promethe
 		monitoringService: monitoringService,
 		gaugeMetrics:      make(map[string]float64),
 		counterMetrics:    make(map[string]float64),
-		histogramMetrics:  make(map[string][]float64),
+		histogramMetrics:  make(map[string]*histogramSeries),
 		metricHelp:        make(map[string]string),
 		metricTypes:       make(map[string]string),
 		metricLabels:      make(map[string]map[string]string),
 		metricsPrefix:     config.MetricsPrefix,
 		enabledMetrics:    config.EnabledMetrics,
+		seriesUpdated:     make(map[string]time.Time),
+		staleSeries:       make(map[string]bool),
+		staleSeriesTTL:    config.StaleSeriesTTL,
+		staleSeriesAction: config.StaleSeriesAction,
+		syncErrors:        make(map[string]int64),
+
+		histogramWindow:     config.HistogramWindow,
+		histogramMaxSamples: config.HistogramMaxSamples,
+		histogramBuckets:    make(map[string][]float64),
+		bucketOverrides:     config.HistogramBuckets,
 	}
 }
 
@@ -100,6 +166,16 @@ func (pe *PrometheusExporter) RegisterGPUMetrics() {
 	pe.registerMetric("gpu_clock_memory_mhz", "gauge",
 		"GPU memory clock in MHz", []string{"gpu_id", "gpu_name", "node"})
 
+	// GPU video engine metrics
+	pe.registerMetric("gpu_encoder_sessions", "gauge",
+		"Open NVENC encoder sessions", []string{"gpu_id", "gpu_name", "node"})
+	pe.registerMetric("gpu_decoder_sessions", "gauge",
+		"Open NVDEC decoder sessions", []string{"gpu_id", "gpu_name", "node"})
+	pe.registerMetric("gpu_encoder_utilization_percent", "gauge",
+		"GPU encoder utilization percentage", []string{"gpu_id", "gpu_name", "node"})
+	pe.registerMetric("gpu_decoder_utilization_percent", "gauge",
+		"GPU decoder utilization percentage", []string{"gpu_id", "gpu_name", "node"})
+
 	// GPU efficiency and process metrics
 	pe.registerMetric("gpu_process_count", "gauge",
 		"Number of processes running on GPU", []string{"gpu_id", "gpu_name", "node"})
@@ -111,6 +187,10 @@ func (pe *PrometheusExporter) RegisterGPUMetrics() {
 	// GPU health status
 	pe.registerMetric("gpu_health_status", "gauge",
 		"GPU health status (0=unhealthy, 1=warning, 2=healthy)", []string{"gpu_id", "gpu_name", "node", "status"})
+
+	// GPU presence, kept after other GPU series expire so disappearance can be alerted on
+	pe.registerMetric("gpu_last_seen_timestamp", "gauge",
+		"Unix time the GPU last reported metrics", []string{"gpu_id", "gpu_name", "node"})
 }
 
 // RegisterSchedulingMetrics registers GPU scheduling metrics
@@ -130,18 +210,22 @@ func (pe *PrometheusExporter) RegisterSchedulingMetrics() {
 		"Number of completed workloads", []string{"status", "priority"})
 
 	// Scheduling performance metrics
-	pe.registerMetric("scheduling_duration_seconds", "histogram",
-		"Time taken for scheduling decisions", []string{"strategy"})
+	pe.registerHistogram("scheduling_duration_seconds",
+		"Time taken for scheduling decisions", []string{"strategy"}, schedulingDurationBuckets)
 	pe.registerMetric("scheduling_decisions_total", "counter",
 		"Total scheduling decisions made", []string{"strategy", "outcome"})
 	pe.registerMetric("gpu_allocation_efficiency", "gauge",
 		"GPU allocation efficiency percentage", []string{"strategy"})
 
 	// Queue metrics
-	pe.registerMetric("workload_queue_time_seconds", "histogram",
-		"Time workloads spend in queue", []string{"priority"})
-	pe.registerMetric("workload_execution_time_seconds", "histogram",
-		"Workload execution time", []string{"workload_type", "gpu_type"})
+	pe.registerHistogram("workload_queue_time_seconds",
+		"Time workloads spend in queue", []string{"priority"}, workloadQueueTimeBuckets)
+	pe.registerMetric("workload_queue_oldest_wait_seconds", "gauge",
+		"Longest current wait among pending workloads", []string{"priority"})
+	pe.registerMetric("workload_starvation_total", "counter",
+		"Workloads that exceeded the queue wait objective", []string{"priority"})
+	pe.registerHistogram("workload_execution_time_seconds",
+		"Workload execution time", []string{"workload_type", "gpu_type"}, workloadExecutionTimeBuckets)
 }
 
 // RegisterServingMetrics registers model serving metrics
@@ -153,10 +237,12 @@ func (pe *PrometheusExporter) RegisterServingMetrics() {
 	// Request metrics
 	pe.registerMetric("inference_requests_total", "counter",
 		"Total inference requests", []string{"model_id", "status"})
-	pe.registerMetric("inference_latency_seconds", "histogram",
-		"Inference request latency", []string{"model_id"})
-	pe.registerMetric("inference_batch_size", "histogram",
-		"Inference batch sizes", []string{"model_id"})
+	pe.registerHistogram("inference_latency_seconds",
+		"Inference request latency", []string{"model_id"}, serving.DefaultLatencyBuckets)
+	pe.registerHistogram("inference_batch_size",
+		"Inference batch sizes", []string{"model_id"}, inferenceBatchSizeBuckets)
+	pe.registerHistogram("inference_stage_latency_seconds",
+		"Inference latency by stage (cache_check, queue_wait, batch_wait, backend_execution, end_to_end)", []string{"model_id", "stage"}, serving.DefaultLatencyBuckets)
 
 	// Cache metrics
 	pe.registerMetric("cache_hits_total", "counter",
@@ -215,6 +301,10 @@ func (pe *PrometheusExporter) RegisterSystemMetrics() {
 		"Overall cluster utilization percentage", []string{})
 	pe.registerMetric("cluster_efficiency_score", "gauge",
 		"Overall cluster efficiency score", []string{})
+	pe.registerMetric("cluster_gpu_quantile_percent", "gauge",
+		"Percentile of per-GPU utilization or memory usage across the cluster", []string{"resource", "quantile"})
+	pe.registerMetric("cluster_gpu_imbalance_gini", "gauge",
+		"Gini coefficient of per-GPU utilization or memory usage (0=balanced)", []string{"resource"})
 
 	// Alert metrics
 	pe.registerMetric("alerts_total", "counter",
@@ -227,6 +317,34 @@ func (pe *PrometheusExporter) RegisterSystemMetrics() {
 		"System uptime in seconds", []string{"component"})
 	pe.registerMetric("component_health_status", "gauge",
 		"Component health status (0=down, 1=degraded, 2=healthy)", []string{"component"})
+	pe.registerMetric("heartbeat", "gauge",
+		"Unix time of the last heartbeat; alert when it stops advancing", []string{})
+
+	// Self-monitoring metrics
+	pe.registerMetric("pipeline_errors_total", "counter",
+		"Failures of the monitoring pipeline itself", []string{"source", "reason"})
+	pe.registerMetric("pipeline_degraded", "gauge",
+		"Whether a monitoring pipeline source failed recently (0/1)", []string{})
+	pe.registerMetric("agent_clock_skew_seconds", "gauge",
+		"Node agent clock minus server clock, measured on each upload", []string{"node"})
+	pe.registerMetric("airgap_blocked_connections_total", "counter",
+		"Outbound connections refused in air-gapped mode", []string{})
+	pe.registerMetric("gpu_expected_throughput_percent", "gauge",
+		"Estimated GPU throughput as a percentage of its model's baseline, for busy GPUs", []string{"gpu_id", "gpu_name", "node"})
+
+	// Exporter staleness metrics
+	pe.registerMetric("stale_series", "gauge",
+		"Number of series currently marked stale", []string{})
+	pe.registerMetric("stale_series_expired_total", "counter",
+		"Total series dropped or zeroed after exceeding the staleness TTL", []string{})
+
+	// Monitoring service buffer metrics
+	pe.registerMetric("monitoring_buffer_entries", "gauge",
+		"Entries held in the monitoring service buffer", []string{"signal"})
+	pe.registerMetric("monitoring_buffer_occupancy_percent", "gauge",
+		"Monitoring service buffer occupancy percentage", []string{"signal"})
+	pe.registerMetric("monitoring_buffer_evictions_total", "counter",
+		"Entries evicted from the monitoring service buffer", []string{"signal", "reason"})
 }
 
 // registerMetric registers a metric with metadata
@@ -236,6 +354,12 @@ func (pe *PrometheusExporter) registerMetric(name, metricType, help string, labe
 	pe.metricHelp[fullName] = help
 	pe.metricLabels[fullName] = make(map[string]string)
 
+	// Metrics with labels get series as they are updated; only unlabeled
+	// metrics start with a zero series
+	if len(labels) > 0 {
+		return
+	}
+
 	// Initialize metric based on type
 	switch metricType {
 	case "gauge":
@@ -243,112 +367,367 @@ func (pe *PrometheusExporter) registerMetric(name, metricType, help string, labe
 	case "counter":
 		pe.counterMetrics[fullName] = 0.0
 	case "histogram":
-		pe.histogramMetrics[fullName] = make([]float64, 0)
+		// Histogram series are created on first observation
+	}
+}
+
+// registerHistogram registers a histogram with the bucket upper bounds suited
+// to what it measures, unless the configuration overrides them
+func (pe *PrometheusExporter) registerHistogram(name, help string, labels []string, buckets []float64) {
+	if override, exists := pe.bucketOverrides[name]; exists {
+		buckets = override
 	}
+	pe.registerMetric(name, "histogram", help, labels)
+	pe.histogramBuckets[fmt.Sprintf("%s_%s", pe.metricsPrefix, name)] = buckets
 }
 
-// UpdateMetric updates a specific metric value
+// UpdateMetric updates a metric according to its registered type: gauges are
+// set, counters are incremented and histograms observe the value. Invalid
+// updates are ignored; use IncCounter, SetGauge or ObserveHistogram to get errors.
 func (pe *PrometheusExporter) UpdateMetric(name string, value float64, labels map[string]string) {
 	pe.mu.Lock()
 	defer pe.mu.Unlock()
 
 	fullName := fmt.Sprintf("%s_%s", pe.metricsPrefix, name)
-	metricKey := pe.buildMetricKey(fullName, labels)
+	pe.update(fullName, pe.metricTypes[fullName], value, labels)
+}
 
-	metricType := pe.metricTypes[fullName]
+// IncCounter adds a non-negative delta to a counter
+func (pe *PrometheusExporter) IncCounter(name string, delta float64, labels map[string]string) error {
+	return pe.updateTyped(name, "counter", delta, labels)
+}
+
+// SetGauge sets a gauge to the given value
+func (pe *PrometheusExporter) SetGauge(name string, value float64, labels map[string]string) error {
+	return pe.updateTyped(name, "gauge", value, labels)
+}
+
+// ObserveHistogram records an observation in a histogram
+func (pe *PrometheusExporter) ObserveHistogram(name string, value float64, labels map[string]string) error {
+	return pe.updateTyped(name, "histogram", value, labels)
+}
+
+// ObserveInferenceStages records a request's latency breakdown in the
+// inference_stage_latency_seconds histogram, one observation per stage
+func (pe *PrometheusExporter) ObserveInferenceStages(modelID string, breakdown serving.LatencyBreakdown) error {
+	for _, stage := range serving.AllLatencyStages {
+		labels := map[string]string{"model_id": modelID, "stage": string(stage)}
+		if err := pe.ObserveHistogram("inference_stage_latency_seconds", breakdown.Stage(stage).Seconds(), labels); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateTyped applies an update after checking the metric is registered with the expected type
+func (pe *PrometheusExporter) updateTyped(name, metricType string, value float64, labels map[string]string) error {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	fullName := fmt.Sprintf("%s_%s", pe.metricsPrefix, name)
+	registeredType, exists := pe.metricTypes[fullName]
+	if !exists {
+		return fmt.Errorf("metric %s is not registered", fullName)
+	}
+	if registeredType != metricType {
+		return fmt.Errorf("metric %s is a %s, not a %s", fullName, registeredType, metricType)
+	}
+	return pe.update(fullName, metricType, value, labels)
+}
+
+// update applies a value to a series of the given type; callers must hold the lock
+func (pe *PrometheusExporter) update(fullName, metricType string, value float64, labels map[string]string) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("metric %s value must be finite", fullName)
+	}
+
+	metricKey := pe.buildMetricKey(fullName, labels)
 	switch metricType {
 	case "gauge":
 		pe.gaugeMetrics[metricKey] = value
 	case "counter":
+		if value < 0 {
+			return fmt.Errorf("counter %s cannot decrease (delta %g)", fullName, value)
+		}
 		pe.counterMetrics[metricKey] += value
 	case "histogram":
-		if pe.histogramMetrics[metricKey] == nil {
-			pe.histogramMetrics[metricKey] = make([]float64, 0)
+		series := pe.histogramMetrics[metricKey]
+		if series == nil {
+			series = newHistogramSeries(pe.bucketsFor(fullName), pe.histogramMaxSamples)
+			pe.histogramMetrics[metricKey] = series
+		}
+		series.observe(value, time.Now())
+	default:
+		return fmt.Errorf("metric %s is not registered", fullName)
+	}
+
+	pe.seriesUpdated[metricKey] = time.Now()
+	delete(pe.staleSeries, metricKey)
+	return nil
+}
+
+// ExpireStaleSeries drops or zeroes series that have not been updated within
+// the staleness TTL and returns how many series were expired. Last-seen
+// timestamps are exempt so dashboards can tell when a source went away.
+func (pe *PrometheusExporter) ExpireStaleSeries() int {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	if pe.staleSeriesTTL <= 0 {
+		return 0
+	}
+
+	expired := 0
+	cutoff := time.Now().Add(-pe.staleSeriesTTL)
+	for metricKey, updated := range pe.seriesUpdated {
+		if updated.After(cutoff) || pe.staleSeries[metricKey] {
+			continue
+		}
+
+		name, _ := pe.parseMetricKey(metricKey)
+		if strings.HasSuffix(name, "_last_seen_timestamp") {
+			continue
+		}
+
+		if pe.staleSeriesAction == StaleSeriesZero {
+			if _, isGauge := pe.gaugeMetrics[metricKey]; isGauge {
+				pe.gaugeMetrics[metricKey] = 0
+			}
+			pe.staleSeries[metricKey] = true
+		} else {
+			delete(pe.gaugeMetrics, metricKey)
+			delete(pe.counterMetrics, metricKey)
+			delete(pe.histogramMetrics, metricKey)
+			delete(pe.seriesUpdated, metricKey)
+		}
+		expired++
+	}
+
+	expiredName := fmt.Sprintf("%s_stale_series_expired_total", pe.metricsPrefix)
+	if _, registered := pe.metricTypes[expiredName]; registered {
+		pe.counterMetrics[expiredName] += float64(expired)
+	}
+	staleName := fmt.Sprintf("%s_stale_series", pe.metricsPrefix)
+	if _, registered := pe.metricTypes[staleName]; registered {
+		pe.gaugeMetrics[staleName] = float64(len(pe.staleSeries))
+	}
+
+	return expired
+}
+
+// GetStaleSeries returns the series that have not been updated within the staleness TTL
+func (pe *PrometheusExporter) GetStaleSeries() []string {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	stale := make([]string, 0)
+	if pe.staleSeriesTTL <= 0 {
+		return stale
+	}
+
+	cutoff := time.Now().Add(-pe.staleSeriesTTL)
+	for metricKey, updated := range pe.seriesUpdated {
+		if !updated.After(cutoff) {
+			stale = append(stale, metricKey)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// RemoveSeries removes every series of the named metrics carrying the given
+// label value, e.g. all series of a decommissioned GPU
+func (pe *PrometheusExporter) RemoveSeries(labelName, labelValue string) int {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	// Keys hold escaped, sorted label pairs, so an unescaped quote only
+	// appears around values and the pair cannot match inside another value
+	pair := formatLabelPair(labelName, labelValue)
+	removed := 0
+	for metricKey := range pe.seriesUpdated {
+		_, labels := pe.parseMetricKey(metricKey)
+		wrapped := "," + labels + ","
+		if strings.Contains(wrapped, ","+pair+",") {
+			delete(pe.gaugeMetrics, metricKey)
+			delete(pe.counterMetrics, metricKey)
+			delete(pe.histogramMetrics, metricKey)
+			delete(pe.seriesUpdated, metricKey)
+			delete(pe.staleSeries, metricKey)
+			removed++
 		}
-		pe.histogramMetrics[metricKey] = append(pe.histogramMetrics[metricKey], value)
 	}
+	return removed
 }
 
-// buildMetricKey creates a unique key for metric with labels
+// buildMetricKey creates a unique key for metric with labels. The key is the
+// series in exposition syntax: label names sorted and values escaped.
 func (pe *PrometheusExporter) buildMetricKey(name string, labels map[string]string) string {
 	if len(labels) == 0 {
 		return name
 	}
 
-	var labelPairs []string
-	for key, value := range labels {
-		labelPairs = append(labelPairs, fmt.Sprintf("%s=%s", key, value))
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	labelPairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		labelPairs = append(labelPairs, formatLabelPair(key, labels[key]))
 	}
 
 	return fmt.Sprintf("%s{%s}", name, strings.Join(labelPairs, ","))
 }
 
-// ExportMetrics exports metrics in Prometheus format
+// formatLabelPair formats a label as name="value" with the name sanitized
+// and the value escaped per the Prometheus text format
+func formatLabelPair(name, value string) string {
+	return fmt.Sprintf("%s=\"%s\"", sanitizeLabelName(name), labelValueEscaper.Replace(value))
+}
+
+// labelValueEscaper escapes backslash, double quote and newline in label values
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// helpEscaper escapes backslash and newline in HELP text
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// sanitizeLabelName replaces characters not allowed in label names with underscores
+func sanitizeLabelName(name string) string {
+	if name == "" {
+		return "_"
+	}
+
+	sanitized := []byte(name)
+	for i, c := range sanitized {
+		valid := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')
+		if !valid {
+			sanitized[i] = '_'
+		}
+	}
+	return string(sanitized)
+}
+
+// formatSampleValue formats a sample value without losing precision
+func formatSampleValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+}
+
+// defaultHistogramBuckets are the upper bounds of histograms registered
+// without buckets of their own
+var defaultHistogramBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Bucket upper bounds of the built-in histograms, sized to what each measures
+var (
+	schedulingDurationBuckets    = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+	workloadQueueTimeBuckets     = []float64{1, 5, 15, 30, 60, 300, 900, 1800, 3600, 7200, 21600, 86400}
+	workloadExecutionTimeBuckets = []float64{60, 300, 900, 1800, 3600, 7200, 14400, 28800, 86400, 259200}
+	inferenceBatchSizeBuckets    = []float64{1, 2, 4, 8, 16, 32, 64, 128, 256}
+)
+
+// bucketsFor returns a histogram's bucket upper bounds; callers must hold the lock
+func (pe *PrometheusExporter) bucketsFor(fullName string) []float64 {
+	if buckets, exists := pe.histogramBuckets[fullName]; exists {
+		return buckets
+	}
+	return defaultHistogramBuckets
+}
+
+// ExportMetrics exports metrics in the Prometheus text exposition format.
+// Each metric family is written once with its HELP and TYPE lines followed
+// by its series in sorted order, so output is stable between scrapes.
 func (pe *PrometheusExporter) ExportMetrics() string {
 	pe.mu.RLock()
 	defer pe.mu.RUnlock()
 
-	var output strings.Builder
+	families := make(map[string][]string)
+	familyTypes := make(map[string]string)
+	addSeries := func(metricKey, metricType string) {
+		name, _ := pe.parseMetricKey(metricKey)
+		families[name] = append(families[name], metricKey)
+		familyTypes[name] = metricType
+	}
 
-	// Export gauge metrics
-	for metricKey, value := range pe.gaugeMetrics {
-		name, labels := pe.parseMetricKey(metricKey)
-		if help, exists := pe.metricHelp[name]; exists {
-			output.WriteString(fmt.Sprintf("# HELP %s %s\n", name, help))
-			output.WriteString(fmt.Sprintf("# TYPE %s gauge\n", name))
+	for metricKey := range pe.gaugeMetrics {
+		addSeries(metricKey, "gauge")
+	}
+	for metricKey := range pe.counterMetrics {
+		addSeries(metricKey, "counter")
+	}
+	for metricKey, series := range pe.histogramMetrics {
+		if series.count > 0 {
+			addSeries(metricKey, "histogram")
 		}
+	}
 
-		if labels != "" {
-			output.WriteString(fmt.Sprintf("%s{%s} %.2f\n", name, labels, value))
-		} else {
-			output.WriteString(fmt.Sprintf("%s %.2f\n", name, value))
-		}
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	// Export counter metrics
-	for metricKey, value := range pe.counterMetrics {
-		name, labels := pe.parseMetricKey(metricKey)
+	var output strings.Builder
+	for _, name := range names {
+		metricType := familyTypes[name]
 		if help, exists := pe.metricHelp[name]; exists {
-			output.WriteString(fmt.Sprintf("# HELP %s %s\n", name, help))
-			output.WriteString(fmt.Sprintf("# TYPE %s counter\n", name))
+			output.WriteString(fmt.Sprintf("# HELP %s %s\n", name, helpEscaper.Replace(help)))
 		}
-
-		if labels != "" {
-			output.WriteString(fmt.Sprintf("%s{%s} %.2f\n", name, labels, value))
-		} else {
-			output.WriteString(fmt.Sprintf("%s %.2f\n", name, value))
+		output.WriteString(fmt.Sprintf("# TYPE %s %s\n", name, metricType))
+
+		keys := families[name]
+		sort.Strings(keys)
+		for _, metricKey := range keys {
+			_, labels := pe.parseMetricKey(metricKey)
+			switch metricType {
+			case "gauge":
+				writeSample(&output, name, labels, pe.gaugeMetrics[metricKey])
+			case "counter":
+				writeSample(&output, name, labels, pe.counterMetrics[metricKey])
+			case "histogram":
+				writeHistogram(&output, name, labels, pe.histogramMetrics[metricKey])
+			}
 		}
 	}
 
-	// Export histogram metrics (simplified - just count and sum)
-	for metricKey, values := range pe.histogramMetrics {
-		if len(values) == 0 {
-			continue
-		}
-
-		name, labels := pe.parseMetricKey(metricKey)
-		if help, exists := pe.metricHelp[name]; exists {
-			output.WriteString(fmt.Sprintf("# HELP %s %s\n", name, help))
-			output.WriteString(fmt.Sprintf("# TYPE %s histogram\n", name))
-		}
+	return output.String()
+}
 
-		count := float64(len(values))
-		sum := 0.0
-		for _, v := range values {
-			sum += v
-		}
+// writeSample writes a single sample line
+func writeSample(output *strings.Builder, name, labels string, value float64) {
+	if labels != "" {
+		output.WriteString(fmt.Sprintf("%s{%s} %s\n", name, labels, formatSampleValue(value)))
+	} else {
+		output.WriteString(fmt.Sprintf("%s %s\n", name, formatSampleValue(value)))
+	}
+}
 
-		labelStr := labels
-		if labelStr != "" {
-			output.WriteString(fmt.Sprintf("%s_count{%s} %.0f\n", name, labelStr, count))
-			output.WriteString(fmt.Sprintf("%s_sum{%s} %.2f\n", name, labelStr, sum))
-		} else {
-			output.WriteString(fmt.Sprintf("%s_count %.0f\n", name, count))
-			output.WriteString(fmt.Sprintf("%s_sum %.2f\n", name, sum))
+// writeHistogram writes cumulative buckets, sum and count for a histogram series
+func writeHistogram(output *strings.Builder, name, labels string, series *histogramSeries) {
+	bucketLabels := func(le string) string {
+		if labels == "" {
+			return fmt.Sprintf("le=\"%s\"", le)
 		}
+		return fmt.Sprintf("%s,le=\"%s\"", labels, le)
 	}
 
-	return output.String()
+	cumulative := uint64(0)
+	for i, bound := range series.bounds {
+		cumulative += series.bucketCounts[i]
+		writeSample(output, name+"_bucket", bucketLabels(formatSampleValue(bound)), float64(cumulative))
+	}
+	writeSample(output, name+"_bucket", bucketLabels("+Inf"), float64(series.count))
+	writeSample(output, name+"_sum", labels, series.sum)
+	writeSample(output, name+"_count", labels, float64(series.count))
 }
 
 // parseMetricKey extracts metric name and labels from metric key
@@ -371,7 +750,10 @@ func (pe *PrometheusExporter) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	// Expire series whose source stopped reporting before exporting
+	pe.ExpireStaleSeries()
 
 	// Add timestamp
 	w.Write([]byte(fmt.Sprintf("# Generated at %s\n", time.Now().UTC().Format(time.RFC3339))))
@@ -391,38 +773,123 @@ func (pe *PrometheusExporter) StartMetricsServer(port int) error {
 		w.Write([]byte("OK"))
 	})
 
-	return http.ListenAndServe(addr, nil)
+	server := newHTTPServer(&http.Server{Addr: addr, Handler: http.DefaultServeMux}, HTTPServerConfig{})
+	return server.ListenAndServe()
 }
 
-// SyncFromMonitoringService syncs metrics from the monitoring service
+// SyncFromMonitoringService syncs metrics recorded since the previous sync
+// from the monitoring service. Only new samples and costs are applied so
+// counters are not incremented twice for the same data.
 func (pe *PrometheusExporter) SyncFromMonitoringService() {
 	if pe.monitoringService == nil {
+		pe.recordSyncError(SyncErrorNoMonitoringService)
 		return
 	}
 
-	// Get current metrics from monitoring service
 	now := time.Now()
-	oneHourAgo := now.Add(-1 * time.Hour)
+	pe.mu.Lock()
+	since := pe.lastSync
+	pe.lastSync = now
+	pe.mu.Unlock()
 
-	// Get recent metrics
-	metrics := pe.monitoringService.GetMetrics(oneHourAgo, now, "")
+	if since.IsZero() {
+		since = now.Add(-1 * time.Hour)
+	}
+	// Range queries exclude both ends; step back so samples at exactly the
+	// previous sync time are not skipped
+	since = since.Add(-time.Nanosecond)
+
+	metrics := pe.monitoringService.GetMetrics(since, now, "")
 	for _, metric := range metrics {
 		pe.UpdateMetric(metric.Name, metric.Value, metric.Labels)
 	}
 
-	// Get cost summary
-	costSummary := pe.monitoringService.GetCostSummary(oneHourAgo, now)
+	costSummary := pe.monitoringService.GetCostSummary(since, now)
 	if totalCost, ok := costSummary["total_cost"].(float64); ok {
-		pe.UpdateMetric("cost_total_dollars", totalCost, map[string]string{
+		pe.IncCounter("cost_total_dollars", totalCost, map[string]string{
 			"operation": "all",
 			"currency":  "USD",
 		})
 	}
 
 	if gpuHours, ok := costSummary["total_gpu_hours"].(float64); ok {
-		pe.UpdateMetric("gpu_hours_consumed", gpuHours, map[string]string{
+		pe.IncCounter("gpu_hours_consumed", gpuHours, map[string]string{
 			"gpu_type":      "all",
 			"workload_type": "all",
 		})
 	}
+
+	for signal, info := range pe.monitoringService.GetBufferOccupancy() {
+		occupancy, ok := info.(map[string]interface{})
+		if !ok {
+			pe.recordSyncError(SyncErrorInvalidBufferStats)
+			continue
+		}
+		entries, entriesOK := occupancy["entries"].(int)
+		percent, percentOK := occupancy["occupancy_percent"].(float64)
+		byCount, byCountOK := occupancy["evicted_by_count"].(int64)
+		byAge, byAgeOK := occupancy["evicted_by_age"].(int64)
+		if !entriesOK || !percentOK || !byCountOK || !byAgeOK {
+			pe.recordSyncError(SyncErrorInvalidBufferStats)
+			continue
+		}
+		labels := map[string]string{"signal": signal}
+		pe.SetGauge("monitoring_buffer_entries", float64(entries), labels)
+		pe.SetGauge("monitoring_buffer_occupancy_percent", percent, labels)
+		pe.setCounterTotal("monitoring_buffer_evictions_total", float64(byCount),
+			map[string]string{"signal": signal, "reason": "count"})
+		pe.setCounterTotal("monitoring_buffer_evictions_total", float64(byAge),
+			map[string]string{"signal": signal, "reason": "age"})
+	}
+}
+
+// recordSyncError counts a SyncFromMonitoringService failure by reason
+func (pe *PrometheusExporter) recordSyncError(reason string) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+	pe.syncErrors[reason]++
+}
+
+// GetSyncErrors returns the total SyncFromMonitoringService failures by reason
+func (pe *PrometheusExporter) GetSyncErrors() map[string]int64 {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	errs := make(map[string]int64, len(pe.syncErrors))
+	for reason, count := range pe.syncErrors {
+		errs[reason] = count
+	}
+	return errs
+}
+
+// setCounterTotal mirrors a monotonic total maintained elsewhere into a counter
+func (pe *PrometheusExporter) setCounterTotal(name string, total float64, labels map[string]string) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	fullName := fmt.Sprintf("%s_%s", pe.metricsPrefix, name)
+	if pe.metricTypes[fullName] != "counter" {
+		return
+	}
+	metricKey := pe.buildMetricKey(fullName, labels)
+	if total > pe.counterMetrics[metricKey] {
+		pe.counterMetrics[metricKey] = total
+	}
+	pe.seriesUpdated[metricKey] = time.Now()
+	delete(pe.staleSeries, metricKey)
+}
+
+// LastUpdate returns when the exporter last received data, either from a
+// sync with the monitoring service or a direct series update
+func (pe *PrometheusExporter) LastUpdate() time.Time {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	last := pe.lastSync
+	for _, updated := range pe.seriesUpdated {
+		if updated.After(last) {
+			last = updated
+		}
+	}
+	return last
 }
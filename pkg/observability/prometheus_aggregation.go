@@ -0,0 +1,288 @@
+package observability
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+)
+
+// ProcessLabel is added to gauges published through the aggregation socket so
+// each process reports its own series instead of overwriting the others'
+const ProcessLabel = "process"
+
+// AggregatedUpdate is one metric update sent over the aggregation socket as a
+// line of JSON
+type AggregatedUpdate struct {
+	Process string            `json:"process"`
+	Type    string            `json:"type"` // counter, gauge or histogram
+	Name    string            `json:"name"`
+	Value   float64           `json:"value"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// MetricsAggregator accepts metric updates from local worker processes over a
+// Unix socket and applies them to a single exporter, so processes sharing a
+// node publish through one scrape endpoint. Counter increments and histogram
+// observations are merged across processes; gauges are kept per process and
+// removed when that process disconnects.
+type MetricsAggregator struct {
+	exporter   *PrometheusExporter
+	socketPath string
+
+	listener    net.Listener
+	connections map[net.Conn]bool
+	processes   map[string]int
+	applied     int64
+	rejected    int64
+	wg          sync.WaitGroup
+	mu          sync.RWMutex
+}
+
+// NewMetricsAggregator creates an aggregator that applies updates to the exporter
+func NewMetricsAggregator(exporter *PrometheusExporter, socketPath string) *MetricsAggregator {
+	return &MetricsAggregator{
+		exporter:    exporter,
+		socketPath:  socketPath,
+		connections: make(map[net.Conn]bool),
+		processes:   make(map[string]int),
+	}
+}
+
+// Start listens on the aggregation socket. A socket file left behind by a
+// process that exited is replaced; one that is still accepting connections
+// is an error.
+func (ma *MetricsAggregator) Start() error {
+	if ma.exporter == nil {
+		return fmt.Errorf("aggregator requires an exporter")
+	}
+	if ma.socketPath == "" {
+		return fmt.Errorf("aggregation socket path is required")
+	}
+
+	if conn, err := net.Dial("unix", ma.socketPath); err == nil {
+		conn.Close()
+		return fmt.Errorf("aggregation socket %s is already in use", ma.socketPath)
+	}
+	if err := os.Remove(ma.socketPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", ma.socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on aggregation socket: %w", err)
+	}
+
+	ma.mu.Lock()
+	ma.listener = listener
+	ma.mu.Unlock()
+
+	ma.wg.Add(1)
+	go ma.acceptLoop(listener)
+	return nil
+}
+
+// Stop closes the socket and all process connections
+func (ma *MetricsAggregator) Stop() error {
+	// The listener is closed first so no connection is accepted after the
+	// open ones are closed
+	ma.mu.Lock()
+	listener := ma.listener
+	ma.listener = nil
+	ma.mu.Unlock()
+
+	if listener == nil {
+		return nil
+	}
+	err := listener.Close()
+
+	ma.mu.Lock()
+	for conn := range ma.connections {
+		conn.Close()
+	}
+	ma.mu.Unlock()
+
+	ma.wg.Wait()
+	return err
+}
+
+// acceptLoop accepts process connections until the listener is closed
+func (ma *MetricsAggregator) acceptLoop(listener net.Listener) {
+	defer ma.wg.Done()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		// A connection accepted while stopping is refused rather than left open
+		ma.mu.Lock()
+		if ma.listener != listener {
+			ma.mu.Unlock()
+			conn.Close()
+			return
+		}
+		ma.connections[conn] = true
+		ma.mu.Unlock()
+
+		ma.wg.Add(1)
+		go ma.handleConnection(conn)
+	}
+}
+
+// handleConnection applies updates from one process until it disconnects
+func (ma *MetricsAggregator) handleConnection(conn net.Conn) {
+	defer ma.wg.Done()
+
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var update AggregatedUpdate
+		if err := json.Unmarshal(scanner.Bytes(), &update); err != nil {
+			ma.recordResult(err)
+			continue
+		}
+
+		if update.Process != "" && !seen[update.Process] {
+			seen[update.Process] = true
+			ma.mu.Lock()
+			ma.processes[update.Process]++
+			ma.mu.Unlock()
+		}
+		ma.recordResult(ma.apply(update))
+	}
+
+	conn.Close()
+	ma.mu.Lock()
+	delete(ma.connections, conn)
+	var gone []string
+	for process := range seen {
+		ma.processes[process]--
+		if ma.processes[process] <= 0 {
+			delete(ma.processes, process)
+			gone = append(gone, process)
+		}
+	}
+	ma.mu.Unlock()
+
+	// Gauges describe live state, so drop them with the process that set them
+	for _, process := range gone {
+		ma.exporter.RemoveSeries(ProcessLabel, process)
+	}
+}
+
+// apply forwards an update to the exporter
+func (ma *MetricsAggregator) apply(update AggregatedUpdate) error {
+	switch update.Type {
+	case "counter":
+		return ma.exporter.IncCounter(update.Name, update.Value, update.Labels)
+	case "histogram":
+		return ma.exporter.ObserveHistogram(update.Name, update.Value, update.Labels)
+	case "gauge":
+		labels := update.Labels
+		if update.Process != "" {
+			labels = make(map[string]string, len(update.Labels)+1)
+			for k, v := range update.Labels {
+				labels[k] = v
+			}
+			labels[ProcessLabel] = update.Process
+		}
+		return ma.exporter.SetGauge(update.Name, update.Value, labels)
+	default:
+		return fmt.Errorf("unknown metric type %q", update.Type)
+	}
+}
+
+// recordResult counts applied and rejected updates
+func (ma *MetricsAggregator) recordResult(err error) {
+	ma.mu.Lock()
+	defer ma.mu.Unlock()
+	if err != nil {
+		ma.rejected++
+		return
+	}
+	ma.applied++
+}
+
+// GetStats returns connected processes and update counts
+func (ma *MetricsAggregator) GetStats() map[string]interface{} {
+	ma.mu.RLock()
+	defer ma.mu.RUnlock()
+
+	processes := make([]string, 0, len(ma.processes))
+	for process := range ma.processes {
+		processes = append(processes, process)
+	}
+
+	return map[string]interface{}{
+		"socket_path":      ma.socketPath,
+		"connections":      len(ma.connections),
+		"processes":        processes,
+		"updates_applied":  ma.applied,
+		"updates_rejected": ma.rejected,
+	}
+}
+
+// AggregationClient publishes metric updates to a MetricsAggregator from a
+// worker process
+type AggregationClient struct {
+	process string
+	conn    net.Conn
+	encoder *json.Encoder
+	mu      sync.Mutex
+}
+
+// DialAggregator connects to the aggregation socket, identifying as process
+func DialAggregator(socketPath, process string) (*AggregationClient, error) {
+	if process == "" {
+		return nil, fmt.Errorf("process name is required")
+	}
+
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to aggregation socket: %w", err)
+	}
+
+	return &AggregationClient{
+		process: process,
+		conn:    conn,
+		encoder: json.NewEncoder(conn),
+	}, nil
+}
+
+// IncCounter adds a non-negative delta to a counter shared by all processes
+func (ac *AggregationClient) IncCounter(name string, delta float64, labels map[string]string) error {
+	return ac.send("counter", name, delta, labels)
+}
+
+// SetGauge sets this process's series of a gauge
+func (ac *AggregationClient) SetGauge(name string, value float64, labels map[string]string) error {
+	return ac.send("gauge", name, value, labels)
+}
+
+// ObserveHistogram records an observation in a histogram shared by all processes
+func (ac *AggregationClient) ObserveHistogram(name string, value float64, labels map[string]string) error {
+	return ac.send("histogram", name, value, labels)
+}
+
+// send writes one update to the socket
+func (ac *AggregationClient) send(metricType, name string, value float64, labels map[string]string) error {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	return ac.encoder.Encode(AggregatedUpdate{
+		Process: ac.process,
+		Type:    metricType,
+		Name:    name,
+		Value:   value,
+		Labels:  labels,
+	})
+}
+
+// Close disconnects from the aggregator
+func (ac *AggregationClient) Close() error {
+	return ac.conn.Close()
+}
@@ -0,0 +1,145 @@
+package observability
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitFor polls until condition holds or the timeout elapses
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestMetricsAggregatorMergesProcesses(t *testing.T) {
+	// Unix socket paths are length-limited, so avoid the long test temp dir
+	dir, err := os.MkdirTemp("", "agg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "metrics.sock")
+
+	exporter := NewPrometheusExporter(nil, DefaultPrometheusConfig())
+	exporter.RegisterServingMetrics()
+	exporter.RegisterSystemMetrics()
+
+	aggregator := NewMetricsAggregator(exporter, socketPath)
+	if err := aggregator.Start(); err != nil {
+		t.Fatalf("Failed to start aggregator: %v", err)
+	}
+	defer aggregator.Stop()
+
+	if err := NewMetricsAggregator(exporter, socketPath).Start(); err == nil {
+		t.Error("Expected error when the socket is already in use")
+	}
+
+	workerA, err := DialAggregator(socketPath, "worker-a")
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	workerB, _ := DialAggregator(socketPath, "worker-b")
+	defer workerB.Close()
+
+	labels := map[string]string{"model_id": "m"}
+	for i := 0; i < 3; i++ {
+		workerA.IncCounter("inference_requests_total", 1, labels)
+		workerB.IncCounter("inference_requests_total", 1, labels)
+	}
+	workerA.SetGauge("system_uptime_seconds", 10, map[string]string{"component": "worker"})
+	workerB.SetGauge("system_uptime_seconds", 20, map[string]string{"component": "worker"})
+	workerB.IncCounter("not_registered", 1, nil)
+
+	waitFor(t, func() bool { return aggregator.GetStats()["updates_rejected"].(int64) == 1 })
+
+	output := exporter.ExportMetrics()
+	if !strings.Contains(output, `agentaflow_inference_requests_total{model_id="m"} 6`+"\n") {
+		t.Errorf("Expected counters merged across processes, got:\n%s", output)
+	}
+	if !strings.Contains(output, `agentaflow_system_uptime_seconds{component="worker",process="worker-a"} 10`) ||
+		!strings.Contains(output, `agentaflow_system_uptime_seconds{component="worker",process="worker-b"} 20`) {
+		t.Errorf("Expected a gauge series per process, got:\n%s", output)
+	}
+
+	workerA.Close()
+	waitFor(t, func() bool {
+		return !strings.Contains(exporter.ExportMetrics(), `process="worker-a"`)
+	})
+	if !strings.Contains(exporter.ExportMetrics(), `agentaflow_inference_requests_total{model_id="m"} 6`) {
+		t.Error("Expected counters to survive a process disconnecting")
+	}
+}
+
+func TestMetricsAggregatorReplacesStaleSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "agg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "metrics.sock")
+
+	if err := os.WriteFile(socketPath, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	aggregator := NewMetricsAggregator(NewPrometheusExporter(nil, DefaultPrometheusConfig()), socketPath)
+	if err := aggregator.Start(); err != nil {
+		t.Fatalf("Expected a stale socket file to be replaced, got %v", err)
+	}
+	if err := aggregator.Stop(); err != nil {
+		t.Errorf("Failed to stop aggregator: %v", err)
+	}
+
+	if _, err := DialAggregator(socketPath, ""); err == nil {
+		t.Error("Expected error for an empty process name")
+	}
+}
+
+func TestMetricsAggregatorStopsWhileProcessesConnect(t *testing.T) {
+	dir, err := os.MkdirTemp("", "agg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "metrics.sock")
+
+	for i := 0; i < 20; i++ {
+		aggregator := NewMetricsAggregator(NewPrometheusExporter(nil, DefaultPrometheusConfig()), socketPath)
+		if err := aggregator.Start(); err != nil {
+			t.Fatalf("Failed to start aggregator: %v", err)
+		}
+
+		// Processes keep connecting, and holding their connections open, while the aggregator stops
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for j := 0; j < 20; j++ {
+				if conn, err := net.Dial("unix", socketPath); err == nil {
+					defer conn.Close()
+				}
+			}
+		}()
+
+		stopped := make(chan struct{})
+		go func() {
+			aggregator.Stop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Stop hung on a connection accepted while stopping")
+		}
+		<-done
+	}
+}
@@ -0,0 +1,109 @@
+package observability
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// histogramSeries accumulates observations into fixed buckets for export and
+// keeps a bounded ring of recent raw observations for windowed quantiles, so
+// memory stays constant no matter how many values are observed
+type histogramSeries struct {
+	bounds       []float64 // Ascending bucket upper bounds, without +Inf
+	bucketCounts []uint64  // Per-bucket (non-cumulative) counts for bounds
+	count        uint64
+	sum          float64
+
+	recent []timedObservation
+	next   int
+	full   bool
+}
+
+// timedObservation is a raw histogram observation
+type timedObservation struct {
+	value float64
+	at    time.Time
+}
+
+// newHistogramSeries creates a histogram series with the given buckets,
+// retaining up to maxSamples raw observations
+func newHistogramSeries(bounds []float64, maxSamples int) *histogramSeries {
+	if maxSamples < 0 {
+		maxSamples = 0
+	}
+	return &histogramSeries{
+		bounds:       bounds,
+		bucketCounts: make([]uint64, len(bounds)),
+		recent:       make([]timedObservation, maxSamples),
+	}
+}
+
+// observe records a value
+func (h *histogramSeries) observe(value float64, now time.Time) {
+	h.count++
+	h.sum += value
+
+	index := sort.SearchFloat64s(h.bounds, value)
+	if index < len(h.bucketCounts) {
+		h.bucketCounts[index]++
+	}
+
+	if len(h.recent) == 0 {
+		return
+	}
+	h.recent[h.next] = timedObservation{value: value, at: now}
+	h.next = (h.next + 1) % len(h.recent)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// windowValues returns the retained observations made after since
+func (h *histogramSeries) windowValues(since time.Time) []float64 {
+	retained := h.next
+	if h.full {
+		retained = len(h.recent)
+	}
+
+	values := make([]float64, 0, retained)
+	for i := 0; i < retained; i++ {
+		if observation := h.recent[i]; observation.at.After(since) {
+			values = append(values, observation.value)
+		}
+	}
+	return values
+}
+
+// GetHistogramQuantile returns the q-quantile (0-1) of a histogram series over
+// the configured rolling window, computed from its retained raw observations
+func (pe *PrometheusExporter) GetHistogramQuantile(name string, labels map[string]string, q float64) (float64, error) {
+	if q < 0 || q > 1 {
+		return 0, fmt.Errorf("quantile must be between 0 and 1")
+	}
+
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	fullName := fmt.Sprintf("%s_%s", pe.metricsPrefix, name)
+	if pe.metricTypes[fullName] != "histogram" {
+		return 0, fmt.Errorf("metric %s is not a registered histogram", fullName)
+	}
+
+	series := pe.histogramMetrics[pe.buildMetricKey(fullName, labels)]
+	if series == nil {
+		return 0, fmt.Errorf("no observations for %s", fullName)
+	}
+
+	since := time.Time{}
+	if pe.histogramWindow > 0 {
+		since = time.Now().Add(-pe.histogramWindow)
+	}
+	values := series.windowValues(since)
+	if len(values) == 0 {
+		return 0, fmt.Errorf("no observations for %s within the window", fullName)
+	}
+
+	sort.Float64s(values)
+	return values[int(q*float64(len(values)-1))], nil
+}
@@ -0,0 +1,85 @@
+package observability
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving"
+)
+
+func TestHistogramMemoryIsBounded(t *testing.T) {
+	config := DefaultPrometheusConfig()
+	config.HistogramMaxSamples = 256
+	exporter := NewPrometheusExporter(nil, config)
+	exporter.RegisterServingMetrics()
+	labels := map[string]string{"model_id": "m"}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	const observations = 2000000
+	for i := 0; i < observations; i++ {
+		exporter.ObserveHistogram("inference_latency_seconds", float64(i%1000)/1000, labels)
+	}
+
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+
+	series := exporter.histogramMetrics[exporter.buildMetricKey("agentaflow_inference_latency_seconds", labels)]
+	if len(series.recent) != 256 || len(series.bucketCounts) != len(serving.DefaultLatencyBuckets) {
+		t.Errorf("Expected fixed-size storage, got %d samples and %d buckets", len(series.recent), len(series.bucketCounts))
+	}
+	if series.count != observations {
+		t.Errorf("Expected %d observations counted, got %d", observations, series.count)
+	}
+
+	// Two million float64s would need 16MB; bounded storage retains a few KB
+	if growth := int64(after.HeapAlloc) - int64(before.HeapAlloc); growth > 1<<20 {
+		t.Errorf("Expected bounded memory, heap grew by %d bytes", growth)
+	}
+
+	output := exporter.ExportMetrics()
+	if !strings.Contains(output, `agentaflow_inference_latency_seconds_bucket{model_id="m",le="+Inf"} 2000000`) {
+		t.Error("Expected cumulative +Inf bucket to equal the observation count")
+	}
+	if !strings.Contains(output, `agentaflow_inference_latency_seconds_bucket{model_id="m",le="0.1"} 202000`) {
+		t.Errorf("Expected cumulative bucket counts, got:\n%s", output)
+	}
+}
+
+func TestHistogramRollingWindowQuantile(t *testing.T) {
+	config := DefaultPrometheusConfig()
+	config.HistogramWindow = time.Minute
+	config.HistogramMaxSamples = 100
+	exporter := NewPrometheusExporter(nil, config)
+	exporter.RegisterServingMetrics()
+	labels := map[string]string{"model_id": "m"}
+
+	for i := 0; i < 100; i++ {
+		exporter.ObserveHistogram("inference_latency_seconds", 5, labels)
+	}
+
+	// Age the slow observations out of the window
+	series := exporter.histogramMetrics[exporter.buildMetricKey("agentaflow_inference_latency_seconds", labels)]
+	for i := range series.recent {
+		series.recent[i].at = time.Now().Add(-time.Hour)
+	}
+	for i := 0; i < 10; i++ {
+		exporter.ObserveHistogram("inference_latency_seconds", 0.1, labels)
+	}
+
+	p99, err := exporter.GetHistogramQuantile("inference_latency_seconds", labels, 0.99)
+	if err != nil {
+		t.Fatalf("Failed to get quantile: %v", err)
+	}
+	if p99 != 0.1 {
+		t.Errorf("Expected p99 over the window to ignore aged observations, got %f", p99)
+	}
+
+	if _, err := exporter.GetHistogramQuantile("cluster_utilization_percent", nil, 0.5); err == nil {
+		t.Error("Expected error for a non-histogram metric")
+	}
+}
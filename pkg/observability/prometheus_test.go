@@ -0,0 +1,324 @@
+package observability
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving"
+)
+
+func newStalenessExporter(action string) *PrometheusExporter {
+	config := DefaultPrometheusConfig()
+	config.StaleSeriesTTL = time.Minute
+	config.StaleSeriesAction = action
+	exporter := NewPrometheusExporter(nil, config)
+	exporter.RegisterGPUMetrics()
+	exporter.RegisterSystemMetrics()
+	return exporter
+}
+
+// ageSeries pretends every series matching the label was last updated long ago
+func ageSeries(pe *PrometheusExporter, label string) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+	for key := range pe.seriesUpdated {
+		if strings.Contains(key, label) {
+			pe.seriesUpdated[key] = time.Now().Add(-time.Hour)
+		}
+	}
+}
+
+func TestStaleSeriesDropped(t *testing.T) {
+	exporter := newStalenessExporter(StaleSeriesDrop)
+
+	for _, id := range []string{"gpu-0", "gpu-1"} {
+		labels := map[string]string{"gpu_id": id}
+		exporter.UpdateMetric("gpu_utilization_percent", 75, labels)
+		exporter.UpdateMetric("gpu_last_seen_timestamp", float64(time.Now().Unix()), labels)
+	}
+
+	// gpu-1 disappears
+	ageSeries(exporter, "gpu-1")
+	if stale := exporter.GetStaleSeries(); len(stale) != 2 {
+		t.Errorf("Expected 2 stale gpu-1 series, got %v", stale)
+	}
+
+	if expired := exporter.ExpireStaleSeries(); expired != 1 {
+		t.Errorf("Expected 1 expired series, got %d", expired)
+	}
+
+	output := exporter.ExportMetrics()
+	if strings.Contains(output, "agentaflow_gpu_utilization_percent{gpu_id=\"gpu-1\"}") {
+		t.Error("Expected utilization of the vanished GPU to be dropped")
+	}
+	if !strings.Contains(output, "agentaflow_gpu_utilization_percent{gpu_id=\"gpu-0\"} 75\n") {
+		t.Error("Expected live GPU series to be kept")
+	}
+	if !strings.Contains(output, "agentaflow_gpu_last_seen_timestamp{gpu_id=\"gpu-1\"}") {
+		t.Error("Expected last-seen timestamp to survive expiry")
+	}
+	if !strings.Contains(output, "agentaflow_stale_series_expired_total 1\n") {
+		t.Error("Expected expired series counter to be exported")
+	}
+
+	if removed := exporter.RemoveSeries("gpu_id", "gpu-1"); removed != 1 {
+		t.Errorf("Expected last-seen series to be removed explicitly, got %d", removed)
+	}
+}
+
+func TestStaleSeriesZeroed(t *testing.T) {
+	exporter := newStalenessExporter(StaleSeriesZero)
+	labels := map[string]string{"gpu_id": "gpu-0"}
+	exporter.UpdateMetric("gpu_temperature_celsius", 80, labels)
+
+	ageSeries(exporter, "gpu-0")
+	exporter.ExpireStaleSeries()
+
+	output := exporter.ExportMetrics()
+	if !strings.Contains(output, "agentaflow_gpu_temperature_celsius{gpu_id=\"gpu-0\"} 0\n") {
+		t.Errorf("Expected stale gauge to be zeroed, got:\n%s", output)
+	}
+	if !strings.Contains(output, "agentaflow_stale_series 1\n") {
+		t.Error("Expected zeroed series to be marked stale")
+	}
+
+	// A fresh update clears the stale mark
+	exporter.UpdateMetric("gpu_temperature_celsius", 65, labels)
+	exporter.ExpireStaleSeries()
+	if !strings.Contains(exporter.ExportMetrics(), "agentaflow_stale_series 0\n") {
+		t.Error("Expected stale mark to clear after the series is updated")
+	}
+}
+
+func TestTypedMetricUpdates(t *testing.T) {
+	exporter := NewPrometheusExporter(nil, DefaultPrometheusConfig())
+	exporter.RegisterSchedulingMetrics()
+	exporter.RegisterGPUMetrics()
+
+	labels := map[string]string{"status": "success"}
+	if err := exporter.IncCounter("workloads_completed", 1, labels); err != nil {
+		t.Fatalf("IncCounter failed: %v", err)
+	}
+	if err := exporter.IncCounter("workloads_completed", 2, labels); err != nil {
+		t.Fatalf("IncCounter failed: %v", err)
+	}
+	if err := exporter.IncCounter("workloads_completed", -1, labels); err == nil {
+		t.Error("Expected negative counter delta to be rejected")
+	}
+	if err := exporter.SetGauge("workloads_completed", 10, labels); err == nil {
+		t.Error("Expected SetGauge on a counter to be rejected")
+	}
+	if err := exporter.IncCounter("gpu_temperature_celsius", 1, nil); err == nil {
+		t.Error("Expected IncCounter on a gauge to be rejected")
+	}
+	if err := exporter.ObserveHistogram("not_registered", 1, nil); err == nil {
+		t.Error("Expected update of an unregistered metric to be rejected")
+	}
+	if err := exporter.SetGauge("gpu_temperature_celsius", math.NaN(), nil); err == nil {
+		t.Error("Expected NaN to be rejected")
+	}
+	if err := exporter.ObserveHistogram("scheduling_duration_seconds", 0.25, map[string]string{"strategy": "x"}); err != nil {
+		t.Errorf("ObserveHistogram failed: %v", err)
+	}
+
+	// Legacy UpdateMetric follows the registered type and ignores invalid updates
+	exporter.UpdateMetric("workloads_completed", -5, labels)
+
+	output := exporter.ExportMetrics()
+	if !strings.Contains(output, "agentaflow_workloads_completed{status=\"success\"} 3\n") {
+		t.Errorf("Expected counter to be 3, got:\n%s", output)
+	}
+	if !strings.Contains(output, "agentaflow_scheduling_duration_seconds_count{strategy=\"x\"} 1\n") {
+		t.Error("Expected histogram observation to be exported")
+	}
+}
+
+func TestObserveInferenceStages(t *testing.T) {
+	exporter := NewPrometheusExporter(nil, DefaultPrometheusConfig())
+	if err := exporter.ObserveInferenceStages("gpt", serving.LatencyBreakdown{}); err == nil {
+		t.Error("Expected an error before serving metrics are registered")
+	}
+	exporter.RegisterServingMetrics()
+
+	breakdown := serving.LatencyBreakdown{QueueWait: 20 * time.Millisecond, Execution: 250 * time.Millisecond}
+	if err := exporter.ObserveInferenceStages("gpt", breakdown); err != nil {
+		t.Fatalf("ObserveInferenceStages failed: %v", err)
+	}
+	output := exporter.ExportMetrics()
+	for _, sample := range []string{
+		`agentaflow_inference_stage_latency_seconds_sum{model_id="gpt",stage="queue_wait"} 0.02`,
+		`agentaflow_inference_stage_latency_seconds_sum{model_id="gpt",stage="backend_execution"} 0.25`,
+		`agentaflow_inference_stage_latency_seconds_sum{model_id="gpt",stage="end_to_end"} 0.27`,
+		`agentaflow_inference_stage_latency_seconds_count{model_id="gpt",stage="cache_check"} 1`,
+	} {
+		if !strings.Contains(output, sample+"\n") {
+			t.Errorf("Expected %s in:\n%s", sample, output)
+		}
+	}
+}
+
+func TestSyncFromMonitoringServiceIsIncremental(t *testing.T) {
+	monitor := NewMonitoringService(100)
+	exporter := NewPrometheusExporter(monitor, DefaultPrometheusConfig())
+	exporter.RegisterCostMetrics()
+
+	monitor.RecordCost(CostEntry{Operation: "inference", Cost: 2.5})
+	time.Sleep(time.Millisecond)
+	exporter.SyncFromMonitoringService()
+	exporter.SyncFromMonitoringService()
+
+	output := exporter.ExportMetrics()
+	if strings.Count(output, " 2.5\n") != 1 || strings.Contains(output, " 5\n") {
+		t.Errorf("Expected cost to be counted once, got:\n%s", output)
+	}
+}
+
+// parseExposition parses text exposition with the Prometheus text parser,
+// failing on anything a Prometheus server would reject
+func parseExposition(t *testing.T, text string) map[string]*dto.MetricFamily {
+	t.Helper()
+	families, err := new(expfmt.TextParser).TextToMetricFamilies(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("Exposition does not parse: %v\n%s", err, text)
+	}
+	return families
+}
+
+// metricLabels returns the label values of a parsed series
+func metricLabels(metric *dto.Metric) map[string]string {
+	labels := make(map[string]string, len(metric.GetLabel()))
+	for _, pair := range metric.GetLabel() {
+		labels[pair.GetName()] = pair.GetValue()
+	}
+	return labels
+}
+
+// bucketBounds returns the upper bounds of a parsed histogram series
+func bucketBounds(metric *dto.Metric) []float64 {
+	bounds := make([]float64, 0, len(metric.GetHistogram().GetBucket()))
+	for _, bucket := range metric.GetHistogram().GetBucket() {
+		bounds = append(bounds, bucket.GetUpperBound())
+	}
+	return bounds
+}
+
+func TestExpositionConformance(t *testing.T) {
+	exporter := NewPrometheusExporter(nil, DefaultPrometheusConfig())
+	exporter.RegisterGPUMetrics()
+	exporter.RegisterSchedulingMetrics()
+	exporter.RegisterServingMetrics()
+	exporter.RegisterCostMetrics()
+	exporter.RegisterSystemMetrics()
+
+	tricky := map[string]string{
+		"gpu_id":   "gpu-0",
+		"gpu_name": "NVIDIA A100-SXM4-40GB, \"rev 2\" \\ node\nrack",
+		"node":     "host {a=b}",
+	}
+	for i := 0; i < 3; i++ {
+		exporter.SetGauge("gpu_utilization_percent", 42.5, tricky)
+		exporter.SetGauge("gpu_temperature_celsius", 61, tricky)
+	}
+	exporter.SetGauge("gpu_utilization_percent", 10, map[string]string{"gpu_id": "gpu-1", "gpu_name": "T4", "node": "n1"})
+	exporter.IncCounter("workloads_completed", 1, map[string]string{"status": "success", "priority": "high"})
+	exporter.SetGauge("gpu_last_seen_timestamp", 1760000000, map[string]string{"gpu_id": "gpu-0"})
+	exporter.ObserveHistogram("inference_latency_seconds", 0.03, map[string]string{"model_id": "m"})
+	exporter.ObserveHistogram("inference_latency_seconds", 2, map[string]string{"model_id": "m"})
+	exporter.SetGauge("cluster_utilization_percent", 55, map[string]string{"bad-label name": "x"})
+
+	output := exporter.ExportMetrics()
+	families := parseExposition(t, output)
+
+	// Repeated updates with the same labels must map to one series regardless of map order
+	matching := 0
+	for _, metric := range families["agentaflow_gpu_utilization_percent"].GetMetric() {
+		labels := metricLabels(metric)
+		if labels["gpu_id"] == "gpu-0" {
+			matching++
+			if labels["gpu_name"] != tricky["gpu_name"] || labels["node"] != tricky["node"] {
+				t.Errorf("Label values did not round-trip: %+v", labels)
+			}
+		}
+	}
+	if matching != 1 {
+		t.Errorf("Expected one series for gpu-0, got %d", matching)
+	}
+
+	if !strings.Contains(output, `agentaflow_workloads_completed{priority="high",status="success"} 1`) {
+		t.Error("Expected labels in sorted order")
+	}
+	if value := families["agentaflow_gpu_last_seen_timestamp"].GetMetric()[0].GetGauge().GetValue(); value != 1760000000 {
+		t.Errorf("Expected timestamps to be exported without loss of precision, got %v", value)
+	}
+	if families["agentaflow_workloads_completed"].GetType() != dto.MetricType_COUNTER {
+		t.Error("Expected counters to be typed")
+	}
+
+	latency := families["agentaflow_inference_latency_seconds"]
+	if latency.GetType() != dto.MetricType_HISTOGRAM || len(latency.GetMetric()) != 1 {
+		t.Fatalf("Expected one histogram series, got %v", latency)
+	}
+	histogram := latency.GetMetric()[0].GetHistogram()
+	bounds := bucketBounds(latency.GetMetric()[0])
+	if len(bounds) != len(serving.DefaultLatencyBuckets)+1 || !math.IsInf(bounds[len(bounds)-1], 1) {
+		t.Errorf("Expected the latency buckets ending in +Inf, got %v", bounds)
+	}
+	for _, bucket := range histogram.GetBucket() {
+		if bucket.GetUpperBound() == 0.05 && bucket.GetCumulativeCount() != 1 {
+			t.Errorf("Expected cumulative bucket counts, got %d at 0.05", bucket.GetCumulativeCount())
+		}
+	}
+	if histogram.GetSampleCount() != 2 || histogram.GetSampleSum() != 2.03 {
+		t.Errorf("Expected count and sum of both observations, got %d and %v", histogram.GetSampleCount(), histogram.GetSampleSum())
+	}
+
+	if _, ok := families["agentaflow_cluster_utilization_percent"]; !ok {
+		t.Error("Expected series with sanitized label name to be exported")
+	}
+
+	// Output is deterministic between scrapes
+	if exporter.ExportMetrics() != output {
+		t.Error("Expected stable exposition output")
+	}
+}
+
+func TestHistogramBucketsPerMetric(t *testing.T) {
+	config := DefaultPrometheusConfig()
+	config.HistogramBuckets = map[string][]float64{"scheduling_duration_seconds": {0.01, 0.1, 1}}
+	exporter := NewPrometheusExporter(nil, config)
+	exporter.RegisterSchedulingMetrics()
+	exporter.RegisterServingMetrics()
+
+	exporter.ObserveHistogram("inference_batch_size", 24, map[string]string{"model_id": "m"})
+	exporter.ObserveHistogram("workload_queue_time_seconds", 600, map[string]string{"priority": "high"})
+	exporter.ObserveHistogram("scheduling_duration_seconds", 0.002, map[string]string{"strategy": "binpack"})
+
+	families := parseExposition(t, exporter.ExportMetrics())
+	for name, want := range map[string][]float64{
+		"agentaflow_inference_batch_size":        inferenceBatchSizeBuckets,
+		"agentaflow_workload_queue_time_seconds": workloadQueueTimeBuckets,
+		"agentaflow_scheduling_duration_seconds": {0.01, 0.1, 1},
+	} {
+		bounds := bucketBounds(families[name].GetMetric()[0])
+		if len(bounds) != len(want)+1 {
+			t.Errorf("%s: expected buckets %v and +Inf, got %v", name, want, bounds)
+			continue
+		}
+		for i, bound := range want {
+			if bounds[i] != bound {
+				t.Errorf("%s: expected buckets %v and +Inf, got %v", name, want, bounds)
+				break
+			}
+		}
+	}
+
+	config.HistogramBuckets["inference_batch_size"] = []float64{8, 4}
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "histogram_buckets.inference_batch_size") {
+		t.Errorf("Expected decreasing buckets to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package observability
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// QueueSLOMonitor exports scheduler queue wait times to Prometheus and turns
+// starving workloads into warning events
+type QueueSLOMonitor struct {
+	interval   time.Duration
+	scheduler  *gpu.Scheduler
+	monitoring *MonitoringService
+	exporter   *PrometheusExporter
+
+	priorities map[string]bool // Priorities with exported queue gauges
+	started    int
+	starved    int
+	stopCh     chan struct{}
+	doneCh     chan struct{}
+	mu         sync.RWMutex
+}
+
+// NewQueueSLOMonitor creates a monitor that checks the queue every interval;
+// monitoring and exporter may be nil
+func NewQueueSLOMonitor(interval time.Duration, scheduler *gpu.Scheduler, monitoring *MonitoringService, exporter *PrometheusExporter) (*QueueSLOMonitor, error) {
+	if scheduler == nil {
+		return nil, fmt.Errorf("scheduler is required")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("queue check interval must be positive")
+	}
+
+	monitor := &QueueSLOMonitor{
+		interval:   interval,
+		scheduler:  scheduler,
+		monitoring: monitoring,
+		exporter:   exporter,
+		priorities: make(map[string]bool),
+	}
+	scheduler.OnQueueEvent(monitor.handleQueueEvent)
+	return monitor, nil
+}
+
+// Check detects starving workloads and refreshes the pending queue gauges
+func (m *QueueSLOMonitor) Check() {
+	m.scheduler.CheckStarvation()
+
+	pending := make(map[string]float64)
+	oldest := make(map[string]float64)
+	for _, wait := range m.scheduler.GetQueueWaits() {
+		priority := strconv.Itoa(wait.Priority)
+		pending[priority]++
+		if seconds := wait.Waited.Seconds(); seconds > oldest[priority] {
+			oldest[priority] = seconds
+		}
+	}
+
+	m.mu.Lock()
+	for priority := range pending {
+		m.priorities[priority] = true
+	}
+	priorities := make([]string, 0, len(m.priorities))
+	for priority := range m.priorities {
+		priorities = append(priorities, priority)
+	}
+	m.mu.Unlock()
+
+	if m.exporter == nil {
+		return
+	}
+	// Priorities whose queue drained are reset rather than left at their last value
+	for _, priority := range priorities {
+		labels := map[string]string{"priority": priority}
+		m.exporter.SetGauge("workloads_pending", pending[priority], labels)
+		m.exporter.SetGauge("workload_queue_oldest_wait_seconds", oldest[priority], labels)
+	}
+}
+
+// handleQueueEvent records wait times of started workloads and alerts on starvation
+func (m *QueueSLOMonitor) handleQueueEvent(event gpu.QueueEvent) {
+	labels := map[string]string{"priority": strconv.Itoa(event.Priority)}
+
+	m.mu.Lock()
+	if event.Type == "starved" {
+		m.starved++
+	} else {
+		m.started++
+	}
+	m.mu.Unlock()
+
+	if event.Type != "starved" {
+		if m.exporter != nil {
+			m.exporter.ObserveHistogram("workload_queue_time_seconds", event.Waited.Seconds(), labels)
+		}
+		return
+	}
+
+	if m.exporter != nil {
+		m.exporter.IncCounter("workload_starvation_total", 1, labels)
+	}
+	if m.monitoring != nil {
+		m.monitoring.RecordEvent(Event{
+			Type:     "workload_starvation",
+			Severity: "warning",
+			Message:  fmt.Sprintf("Workload %s (priority %d) has waited %s in the queue", event.WorkloadID, event.Priority, event.Waited.Round(time.Second)),
+			Source:   "queue_slo_monitor",
+			Metadata: map[string]interface{}{
+				"workload_id":    event.WorkloadID,
+				"workload_name":  event.Name,
+				"priority":       event.Priority,
+				"waited_seconds": event.Waited.Seconds(),
+			},
+			Timestamp: event.Timestamp,
+		})
+	}
+}
+
+// Start begins periodic queue checks
+func (m *QueueSLOMonitor) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stopCh != nil {
+		return
+	}
+	m.stopCh = make(chan struct{})
+	m.doneCh = make(chan struct{})
+	go m.run(m.stopCh, m.doneCh)
+}
+
+// Stop halts periodic queue checks
+func (m *QueueSLOMonitor) Stop() {
+	m.mu.Lock()
+	stopCh, doneCh := m.stopCh, m.doneCh
+	m.stopCh, m.doneCh = nil, nil
+	m.mu.Unlock()
+
+	if stopCh == nil {
+		return
+	}
+	close(stopCh)
+	<-doneCh
+}
+
+// run checks the queue on every interval until stopped
+func (m *QueueSLOMonitor) run(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.Check()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// GetStats returns counts of started and starved workloads seen
+func (m *QueueSLOMonitor) GetStats() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return map[string]interface{}{
+		"interval_seconds":  m.interval.Seconds(),
+		"running":           m.stopCh != nil,
+		"workloads_started": m.started,
+		"workloads_starved": m.starved,
+	}
+}
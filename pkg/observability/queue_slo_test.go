@@ -0,0 +1,141 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestQueueSLOMonitorExportsWaitsAndStarvation(t *testing.T) {
+	scheduler := gpu.NewScheduler(gpu.StrategyPriority)
+	monitoring := NewMonitoringService(100)
+	exporter := NewPrometheusExporter(nil, DefaultPrometheusConfig())
+	exporter.RegisterSchedulingMetrics()
+
+	monitor, err := NewQueueSLOMonitor(time.Minute, scheduler, monitoring, exporter)
+	if err != nil {
+		t.Fatalf("NewQueueSLOMonitor failed: %v", err)
+	}
+
+	workload := &gpu.Workload{ID: "backfill-1", Priority: 0, MemoryRequired: 4000}
+	scheduler.SubmitWorkload(workload)
+	workload.QueuedAt = time.Now().Add(-2 * time.Hour)
+	monitor.Check()
+
+	events := monitoring.GetEvents(time.Now().Add(-time.Minute), time.Now().Add(time.Minute), "warning")
+	if len(events) != 1 || events[0].Type != "workload_starvation" || events[0].Metadata["workload_id"] != "backfill-1" {
+		t.Fatalf("Expected a starvation event, got %+v", events)
+	}
+	output := exporter.ExportMetrics()
+	for _, expected := range []string{
+		"agentaflow_workloads_pending{priority=\"0\"} 1\n",
+		"agentaflow_workload_starvation_total{priority=\"0\"} 1\n",
+		"agentaflow_workload_queue_oldest_wait_seconds{priority=\"0\"} 72",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, output)
+		}
+	}
+
+	scheduler.RegisterGPU(&gpu.GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+	scheduler.Schedule()
+	monitor.Check()
+
+	output = exporter.ExportMetrics()
+	if !strings.Contains(output, "agentaflow_workload_queue_time_seconds_count{priority=\"0\"} 1\n") {
+		t.Errorf("Expected the queue wait observed, got:\n%s", output)
+	}
+	if !strings.Contains(output, "agentaflow_workloads_pending{priority=\"0\"} 0\n") {
+		t.Errorf("Expected the drained priority reset to 0, got:\n%s", output)
+	}
+	if stats := monitor.GetStats(); stats["workloads_started"] != 1 || stats["workloads_starved"] != 1 {
+		t.Errorf("Unexpected stats %v", stats)
+	}
+
+	if _, err := NewQueueSLOMonitor(0, scheduler, nil, nil); err == nil {
+		t.Error("Expected a zero interval to be rejected")
+	}
+}
+
+func TestWorkloadListAPI(t *testing.T) {
+	config := gpu.DefaultSchedulerConfig()
+	config.Aging.Enabled = true
+	scheduler := gpu.NewSchedulerWithConfig(gpu.StrategyPriority, config)
+	scheduler.RegisterGPU(&gpu.GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "running", Priority: 2, MemoryRequired: 8000})
+	scheduler.Schedule()
+
+	waiting := &gpu.Workload{ID: "waiting", Priority: 1, MemoryRequired: 4000, Labels: map[string]string{"team": "search"}}
+	scheduler.SubmitWorkload(waiting)
+	waiting.QueuedAt = time.Now().Add(-11 * time.Minute)
+
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{Port: 0})
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/workloads", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a scheduler, got %d", response.Code)
+	}
+	dashboard.SetScheduler(scheduler)
+
+	response := serveDashboard(dashboard, http.MethodGet, "/api/v1/workloads?status=pending", "")
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", response.Code, response.Body.String())
+	}
+	var body struct {
+		Workloads []gpu.WorkloadInfo `json:"workloads"`
+		Count     int                `json:"count"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Count != 1 || body.Workloads[0].ID != "waiting" || body.Workloads[0].EffectivePriority != 3 {
+		t.Errorf("Expected the waiting workload aged to priority 3, got %+v", body.Workloads)
+	}
+
+	response = serveDashboard(dashboard, http.MethodGet, "/api/v1/workloads", "")
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil || body.Count != 2 {
+		t.Errorf("Expected both workloads listed, got %s", response.Body.String())
+	}
+
+	response = serveDashboard(dashboard, http.MethodGet, "/api/v1/workloads?selector=team=search", "")
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil || body.Count != 1 || body.Workloads[0].ID != "waiting" {
+		t.Errorf("Expected only the labelled workload listed, got %s", response.Body.String())
+	}
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/workloads?selector=team", ""); response.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a malformed selector, got %d", response.Code)
+	}
+}
+
+func TestPoolsAPI(t *testing.T) {
+	config := gpu.DefaultSchedulerConfig()
+	config.Pools["ci"] = gpu.PoolConfig{MaxGPUs: 2}
+	scheduler := gpu.NewSchedulerWithConfig(gpu.StrategyLeastUtilized, config)
+	scheduler.RegisterGPU(&gpu.GPU{ID: "ci-0", Pool: "ci", MemoryTotal: 16000, Available: true})
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "test", Pool: "ci", MemoryRequired: 4000})
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "adhoc", MemoryRequired: 4000})
+	scheduler.Schedule()
+
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{Port: 0})
+	dashboard.SetScheduler(scheduler)
+
+	response := serveDashboard(dashboard, http.MethodGet, "/api/v1/pools", "")
+	var pools struct {
+		Pools []gpu.PoolStatus `json:"pools"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &pools); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(pools.Pools) != 2 || pools.Pools[1].Name != "ci" || pools.Pools[1].Running != 1 || pools.Pools[0].Pending != 1 {
+		t.Errorf("Unexpected pools %+v", pools.Pools)
+	}
+
+	response = serveDashboard(dashboard, http.MethodGet, "/api/v1/workloads?pool=", "")
+	var workloads struct {
+		Workloads []gpu.WorkloadInfo `json:"workloads"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &workloads); err != nil || len(workloads.Workloads) != 1 || workloads.Workloads[0].ID != "adhoc" {
+		t.Errorf("Expected only the default pool's workload, got %s", response.Body.String())
+	}
+}
@@ -0,0 +1,204 @@
+package observability
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	// Time zones resolve on hosts and containers without a zoneinfo database
+	_ "time/tzdata"
+)
+
+// DefaultReportLocale formats reports for tenants without a locale
+const DefaultReportLocale = "en-US"
+
+// localeFormat is how a locale writes numbers, amounts and dates
+type localeFormat struct {
+	group       string // Thousands separator; spaces are non-breaking
+	decimal     string
+	symbolAfter bool // Amounts read "1.234,50 €" rather than "€1,234.50"
+	date        string
+	months      [12]string
+	month       string // Pattern for the month and year, with %s and %d
+}
+
+var englishMonths = [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+
+// localeFormats are the locales reports can be rendered in
+var localeFormats = map[string]localeFormat{
+	"en-US": {group: ",", decimal: ".", date: "Jan 2, 2006", months: englishMonths, month: "%s %d"},
+	"en-GB": {group: ",", decimal: ".", date: "2 Jan 2006", months: englishMonths, month: "%s %d"},
+	"de-DE": {group: ".", decimal: ",", symbolAfter: true, date: "02.01.2006", month: "%s %d",
+		months: [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"}},
+	"fr-FR": {group: "\u202f", decimal: ",", symbolAfter: true, date: "02/01/2006", month: "%s %d",
+		months: [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"}},
+	"ja-JP": {group: ",", decimal: ".", date: "2006/01/02", month: "%[2]d年%[1]s",
+		months: [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"}},
+}
+
+// currencySymbols are written in place of the ISO code; other currencies
+// are written with their code
+var currencySymbols = map[string]string{"USD": "$", "EUR": "€", "GBP": "£", "JPY": "¥", "CNY": "¥", "INR": "₹", "KRW": "₩"}
+
+// zeroDecimalCurrencies have no minor unit
+var zeroDecimalCurrencies = map[string]bool{"JPY": true, "KRW": true}
+
+// ReportLocale is the time zone that costs are bucketed into days and
+// months in, and the locale reports format numbers, amounts and dates with
+type ReportLocale struct {
+	TimeZone string `yaml:"time_zone" json:"time_zone"` // IANA name, e.g. Europe/Berlin; empty for the server's zone
+	Locale   string `yaml:"locale" json:"locale"`       // e.g. de-DE; empty for DefaultReportLocale
+}
+
+// Validate checks the time zone exists and the locale is supported
+func (l ReportLocale) Validate() error {
+	var errs ConfigErrors
+	l.validate("", &errs)
+	return errs.err()
+}
+
+// validate adds the locale's errors under prefix
+func (l ReportLocale) validate(prefix string, errs *ConfigErrors) {
+	if l.TimeZone != "" {
+		if _, err := time.LoadLocation(l.TimeZone); err != nil {
+			errs.add(prefix+"time_zone", "unknown time zone %q", l.TimeZone)
+		}
+	}
+	if _, supported := localeFormats[l.Locale]; l.Locale != "" && !supported {
+		errs.add(prefix+"locale", "must be one of %s, got %q", strings.Join(supportedLocales(), ", "), l.Locale)
+	}
+}
+
+// supportedLocales lists the locales reports can be rendered in
+func supportedLocales() []string {
+	locales := make([]string, 0, len(localeFormats))
+	for locale := range localeFormats {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Location returns the report time zone, the server's when unset or unknown
+func (l ReportLocale) Location() *time.Location {
+	if l.TimeZone == "" {
+		return time.Local
+	}
+	location, err := time.LoadLocation(l.TimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return location
+}
+
+// ZoneName returns the IANA name of the report time zone
+func (l ReportLocale) ZoneName() string {
+	return l.Location().String()
+}
+
+// format returns how the locale writes values, en-US when unsupported
+func (l ReportLocale) format() localeFormat {
+	if format, supported := localeFormats[l.Locale]; supported {
+		return format
+	}
+	return localeFormats[DefaultReportLocale]
+}
+
+// FormatNumber writes a value with the locale's separators, e.g. 1.234,5
+// in de-DE
+func (l ReportLocale) FormatNumber(value float64, decimals int) string {
+	format := l.format()
+	digits := strconv.FormatFloat(math.Abs(value), 'f', decimals, 64)
+	whole, fraction := digits, ""
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		whole, fraction = digits[:i], digits[i+1:]
+	}
+
+	var b strings.Builder
+	if value < 0 && strings.Trim(digits, "0.") != "" {
+		b.WriteString("-")
+	}
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(format.group)
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteString(format.decimal)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// FormatCurrency writes an amount in a currency the locale's way, e.g.
+// $1,234.50 in en-US or 1.234,50 € in de-DE
+func (l ReportLocale) FormatCurrency(amount float64, currency string) string {
+	currency = strings.ToUpper(currency)
+	decimals := 2
+	if zeroDecimalCurrencies[currency] {
+		decimals = 0
+	}
+	number := l.FormatNumber(amount, decimals)
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+
+	symbol, known := currencySymbols[currency]
+	if !known {
+		symbol = currency
+	}
+	switch {
+	case l.format().symbolAfter:
+		return sign + number + "\u00a0" + symbol
+	case known:
+		return sign + symbol + number
+	default:
+		return sign + symbol + "\u00a0" + number
+	}
+}
+
+// FormatDate writes a date in the report time zone the locale's way
+func (l ReportLocale) FormatDate(t time.Time) string {
+	return t.In(l.Location()).Format(l.format().date)
+}
+
+// FormatMonth names a month in the report time zone, e.g. Oktober 2026
+func (l ReportLocale) FormatMonth(t time.Time) string {
+	format := l.format()
+	t = t.In(l.Location())
+	return fmt.Sprintf(format.month, format.months[t.Month()-1], t.Year())
+}
+
+// reportLocale returns the locale of the request's reports: ?tz and
+// ?locale, else the caller's tenant's configured locale, else the server's
+// time zone in DefaultReportLocale
+func (wd *WebDashboard) reportLocale(r *http.Request) (ReportLocale, error) {
+	wd.mu.RLock()
+	locales := wd.tenancy.Locales
+	wd.mu.RUnlock()
+
+	locale := ReportLocale{}
+	if scope, scoped := r.Context().Value(tenantScopeKey{}).(tenantScope); scoped && scope.tenant != "" {
+		locale = locales[scope.tenant]
+	}
+	query := r.URL.Query()
+	if tz := query.Get("tz"); tz != "" {
+		locale.TimeZone = tz
+	}
+	if name := query.Get("locale"); name != "" {
+		locale.Locale = name
+	}
+	if err := locale.Validate(); err != nil {
+		return ReportLocale{}, err
+	}
+	if locale.Locale == "" {
+		locale.Locale = DefaultReportLocale
+	}
+	return locale, nil
+}
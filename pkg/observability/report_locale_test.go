@@ -0,0 +1,87 @@
+package observability
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportLocaleFormatting(t *testing.T) {
+	day := time.Date(2026, time.October, 6, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		locale   string
+		number   string
+		usd      string
+		eur      string
+		jpy      string
+		date     string
+		month    string
+		negative string
+	}{
+		{"en-US", "1,234,567.89", "$1,234.50", "€1,234.50", "¥1,235", "Oct 6, 2026", "October 2026", "-$12.00"},
+		{"en-GB", "1,234,567.89", "$1,234.50", "€1,234.50", "¥1,235", "6 Oct 2026", "October 2026", "-$12.00"},
+		{"de-DE", "1.234.567,89", "1.234,50\u00a0$", "1.234,50\u00a0€", "1.235\u00a0¥", "06.10.2026", "Oktober 2026", "-12,00\u00a0$"},
+		{"fr-FR", "1\u202f234\u202f567,89", "1\u202f234,50\u00a0$", "1\u202f234,50\u00a0€", "1\u202f235\u00a0¥", "06/10/2026", "octobre 2026", "-12,00\u00a0$"},
+		{"ja-JP", "1,234,567.89", "$1,234.50", "€1,234.50", "¥1,235", "2026/10/06", "2026年10月", "-$12.00"},
+	}
+	for _, c := range cases {
+		locale := ReportLocale{TimeZone: "UTC", Locale: c.locale}
+		if got := locale.FormatNumber(1234567.891, 2); got != c.number {
+			t.Errorf("%s: FormatNumber = %q, want %q", c.locale, got, c.number)
+		}
+		if got := locale.FormatCurrency(1234.5, "usd"); got != c.usd {
+			t.Errorf("%s: USD = %q, want %q", c.locale, got, c.usd)
+		}
+		if got := locale.FormatCurrency(1234.5, "EUR"); got != c.eur {
+			t.Errorf("%s: EUR = %q, want %q", c.locale, got, c.eur)
+		}
+		if got := locale.FormatCurrency(1234.6, "JPY"); got != c.jpy {
+			t.Errorf("%s: JPY = %q, want %q", c.locale, got, c.jpy)
+		}
+		if got := locale.FormatCurrency(-12, "USD"); got != c.negative {
+			t.Errorf("%s: negative = %q, want %q", c.locale, got, c.negative)
+		}
+		if got := locale.FormatDate(day); got != c.date {
+			t.Errorf("%s: FormatDate = %q, want %q", c.locale, got, c.date)
+		}
+		if got := locale.FormatMonth(day); got != c.month {
+			t.Errorf("%s: FormatMonth = %q, want %q", c.locale, got, c.month)
+		}
+	}
+
+	locale := ReportLocale{Locale: "en-US"}
+	if got := locale.FormatCurrency(99, "CHF"); got != "CHF\u00a099.00" {
+		t.Errorf("Expected unknown currencies written with their code, got %q", got)
+	}
+	if got := locale.FormatNumber(-0.001, 2); got != "0.00" {
+		t.Errorf("Expected no sign on a value rounding to zero, got %q", got)
+	}
+
+	// Dates are taken in the report time zone
+	tokyo := ReportLocale{TimeZone: "Asia/Tokyo", Locale: "ja-JP"}
+	if got := tokyo.FormatDate(time.Date(2026, time.October, 6, 20, 0, 0, 0, time.UTC)); got != "2026/10/07" {
+		t.Errorf("Expected the next day in Tokyo, got %q", got)
+	}
+	if (ReportLocale{}).Location() != time.Local {
+		t.Error("Expected the server's time zone without one configured")
+	}
+}
+
+func TestReportLocaleValidation(t *testing.T) {
+	if err := (ReportLocale{TimeZone: "Europe/Berlin", Locale: "de-DE"}).Validate(); err != nil {
+		t.Errorf("Expected a valid locale, got %v", err)
+	}
+	err := (ReportLocale{TimeZone: "Mars/Olympus", Locale: "tlh"}).Validate()
+	if err == nil || len(err.(ConfigErrors)) != 2 {
+		t.Fatalf("Expected time zone and locale errors, got %v", err)
+	}
+
+	config := WebDashboardConfig{Tenancy: TenancyConfig{
+		Tenants: map[string]string{"ana": "vision"},
+		Locales: map[string]ReportLocale{"vision": {TimeZone: "Nowhere/City"}},
+	}}
+	err = config.Validate()
+	if err == nil || !strings.Contains(err.Error(), "tenancy.locales.vision.time_zone") {
+		t.Errorf("Expected the tenant's time zone rejected, got %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// viewNamePattern restricts view names to what reads well in a URL
+var viewNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// SavedView is a named filter set, applied to any API request or dashboard
+// page with ?view=<name> so dashboards and reports share consistent views
+type SavedView struct {
+	Name        string            `yaml:"name" json:"name"`
+	Description string            `yaml:"description" json:"description,omitempty"`
+	Pool        string            `yaml:"pool" json:"pool,omitempty"`
+	Selector    string            `yaml:"selector" json:"selector,omitempty"` // GPU and workload labels, e.g. gpu_type=h100
+	Range       string            `yaml:"range" json:"range,omitempty"`       // Whole hours looking back, e.g. 6h
+	Params      map[string]string `yaml:"params" json:"params,omitempty"`     // Other query parameters
+	Owner       string            `yaml:"owner" json:"owner,omitempty"`
+	CreatedAt   time.Time         `yaml:"created_at" json:"created_at"`
+	UpdatedAt   time.Time         `yaml:"updated_at" json:"updated_at"`
+}
+
+// Validate checks the view's name, selector and range
+func (v SavedView) Validate() error {
+	var errs ConfigErrors
+	if !viewNamePattern.MatchString(v.Name) {
+		errs.add("name", "must be lowercase letters, digits, - and _, starting with a letter or digit, got %q", v.Name)
+	}
+	if _, err := gpu.ParseSelector(v.Selector); err != nil {
+		errs.add("selector", "%v", err)
+	}
+	if v.Range != "" {
+		duration, err := time.ParseDuration(v.Range)
+		if err != nil || duration < time.Hour || duration%time.Hour != 0 {
+			errs.add("range", "must be a whole number of hours, e.g. 6h, got %q", v.Range)
+		}
+	}
+	for _, key := range []string{"view", "pool", "selector", "hours"} {
+		if _, exists := v.Params[key]; exists {
+			errs.add("params."+key, "must not be set in params")
+		}
+	}
+	return errs.err()
+}
+
+// Query returns the view's filters as query parameters: pool, selector,
+// hours for the range, then any other params
+func (v SavedView) Query() url.Values {
+	query := url.Values{}
+	for key, value := range v.Params {
+		query.Set(key, value)
+	}
+	if v.Pool != "" {
+		query.Set("pool", v.Pool)
+	}
+	if v.Selector != "" {
+		query.Set("selector", v.Selector)
+	}
+	if duration, err := time.ParseDuration(v.Range); err == nil {
+		query.Set("hours", strconv.Itoa(int(duration/time.Hour)))
+	}
+	return query
+}
+
+// SavedViewStore keeps saved views server-side, optionally persisted to a
+// JSON file
+type SavedViewStore struct {
+	path  string
+	views map[string]SavedView
+	mu    sync.RWMutex
+}
+
+// NewSavedViewStore creates a store, loading views saved at path. An empty
+// path keeps views in memory only.
+func NewSavedViewStore(path string) (*SavedViewStore, error) {
+	store := &SavedViewStore{
+		path:  path,
+		views: make(map[string]SavedView),
+	}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saved views: %w", err)
+	}
+	var saved []SavedView
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to decode saved views: %w", err)
+	}
+	for _, view := range saved {
+		store.views[view.Name] = view
+	}
+	return store, nil
+}
+
+// Get returns a saved view
+func (s *SavedViewStore) Get(name string) (SavedView, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	view, exists := s.views[name]
+	return view, exists
+}
+
+// Save validates and stores a view, replacing any view of the same name.
+// A replaced view keeps its creation time.
+func (s *SavedViewStore) Save(view SavedView) (SavedView, error) {
+	if err := view.Validate(); err != nil {
+		return SavedView{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, existed := s.views[view.Name]
+	view.UpdatedAt = time.Now()
+	view.CreatedAt = view.UpdatedAt
+	if existed {
+		view.CreatedAt = previous.CreatedAt
+	}
+	s.views[view.Name] = view
+	if err := s.save(); err != nil {
+		if existed {
+			s.views[view.Name] = previous
+		} else {
+			delete(s.views, view.Name)
+		}
+		return SavedView{}, err
+	}
+	return view, nil
+}
+
+// Delete removes a view, returning false when there was none
+func (s *SavedViewStore) Delete(name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, exists := s.views[name]
+	if !exists {
+		return false, nil
+	}
+	delete(s.views, name)
+	if err := s.save(); err != nil {
+		s.views[name] = previous
+		return false, err
+	}
+	return true, nil
+}
+
+// List returns every saved view, sorted by name
+func (s *SavedViewStore) List() []SavedView {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sorted()
+}
+
+// sorted copies the views in name order; callers must hold the lock
+func (s *SavedViewStore) sorted() []SavedView {
+	list := make([]SavedView, 0, len(s.views))
+	for _, view := range s.views {
+		list = append(list, view)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// save atomically rewrites the views file; callers must hold the lock
+func (s *SavedViewStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.sorted(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode saved views: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create saved views file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write saved views: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close saved views: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace saved views: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package observability
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSavedViewValidate(t *testing.T) {
+	valid := SavedView{Name: "research-h100", Pool: "research", Selector: "gpu_type=h100", Range: "6h", Params: map[string]string{"metric": "power"}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected a valid view, got %v", err)
+	}
+	query := valid.Query()
+	if query.Get("pool") != "research" || query.Get("selector") != "gpu_type=h100" || query.Get("hours") != "6" || query.Get("metric") != "power" {
+		t.Errorf("Unexpected view query %v", query)
+	}
+
+	for name, view := range map[string]SavedView{
+		"empty name":       {},
+		"uppercase name":   {Name: "Research"},
+		"bad selector":     {Name: "research", Selector: "gpu_type"},
+		"partial hours":    {Name: "research", Range: "90m"},
+		"bad range":        {Name: "research", Range: "yesterday"},
+		"shadowing params": {Name: "research", Params: map[string]string{"pool": "training"}},
+	} {
+		if err := view.Validate(); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+func TestSavedViewStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "views.json")
+	store, err := NewSavedViewStore(path)
+	if err != nil {
+		t.Fatalf("NewSavedViewStore failed: %v", err)
+	}
+
+	created, err := store.Save(SavedView{Name: "research", Pool: "research", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if created.CreatedAt.IsZero() || !created.CreatedAt.Equal(created.UpdatedAt) {
+		t.Errorf("Expected a new view to be stamped, got %+v", created)
+	}
+	updated, err := store.Save(SavedView{Name: "research", Pool: "research", Range: "24h", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if !updated.CreatedAt.Equal(created.CreatedAt) || updated.Range != "24h" {
+		t.Errorf("Expected the replaced view to keep its creation time, got %+v", updated)
+	}
+	if _, err := store.Save(SavedView{Name: "Bad Name"}); err == nil {
+		t.Error("Expected an invalid view to be rejected")
+	}
+	store.Save(SavedView{Name: "training", Pool: "training"})
+
+	// Views survive a restart
+	reloaded, err := NewSavedViewStore(path)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if list := reloaded.List(); len(list) != 2 || list[0].Name != "research" || list[0].Range != "24h" {
+		t.Errorf("Expected both views after reload, got %+v", list)
+	}
+
+	if deleted, err := reloaded.Delete("training"); !deleted || err != nil {
+		t.Errorf("Expected training to be deleted, got %v %v", deleted, err)
+	}
+	if deleted, _ := reloaded.Delete("training"); deleted {
+		t.Error("Expected deleting a missing view to report false")
+	}
+	if _, exists := reloaded.Get("training"); exists {
+		t.Error("Expected training to be gone")
+	}
+}
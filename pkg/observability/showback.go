@@ -0,0 +1,234 @@
+package observability
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// DefaultPoolName keys the price card of GPUs and workloads without a pool
+const DefaultPoolName = "default"
+
+// PriceCard is what an internal GPU marketplace charges consuming teams for a pool
+type PriceCard struct {
+	PerGPUHour float64            `yaml:"per_gpu_hour" json:"per_gpu_hour"`
+	ByGPUType  map[string]float64 `yaml:"by_gpu_type" json:"by_gpu_type,omitempty"` // Overrides PerGPUHour for a GPU type such as a100
+	// Share of the price charged while a workload is co-located with another
+	SharedFactor float64 `yaml:"shared_factor" json:"shared_factor,omitempty"`
+}
+
+// ShowbackConfig maps workloads to teams and pools to price cards
+type ShowbackConfig struct {
+	TeamLabel  string               `yaml:"team_label" json:"team_label"`   // Workload label naming the consuming team
+	PriceCards map[string]PriceCard `yaml:"price_cards" json:"price_cards"` // Keyed by pool, DefaultPoolName for no pool
+}
+
+// DefaultShowbackConfig returns a config without price cards, charging
+// every pool at provider cost
+func DefaultShowbackConfig() ShowbackConfig {
+	return ShowbackConfig{
+		TeamLabel:  "team",
+		PriceCards: make(map[string]PriceCard),
+	}
+}
+
+// charge returns the price of a GPU hour in a pool and whether a card priced it
+func (c ShowbackConfig) charge(pool, gpuType string, shared bool) (float64, bool) {
+	card, exists := c.PriceCards[pool]
+	if !exists {
+		return 0, false
+	}
+	price := card.PerGPUHour
+	if typed, exists := card.ByGPUType[gpuType]; exists {
+		price = typed
+	}
+	if shared && card.SharedFactor > 0 {
+		price *= card.SharedFactor
+	}
+	return price, true
+}
+
+// ShowbackLine is a team's usage of one pool
+type ShowbackLine struct {
+	Pool         string  `json:"pool"`
+	GPUHours     float64 `json:"gpu_hours"`
+	Charges      float64 `json:"charges"`
+	ProviderCost float64 `json:"provider_cost"`
+}
+
+// TeamShowback is what a team was charged for a month against what its GPU
+// time cost the provider. A negative margin is a subsidy from the platform.
+type TeamShowback struct {
+	Team          string         `json:"team"`
+	GPUHours      float64        `json:"gpu_hours"`
+	Charges       float64        `json:"charges"`
+	ProviderCost  float64        `json:"provider_cost"`
+	Margin        float64        `json:"margin"`
+	MarginPercent float64        `json:"margin_percent"` // Of charges
+	Subsidy       float64        `json:"subsidy"`
+	Workloads     int            `json:"workloads"`
+	Pools         []ShowbackLine `json:"pools"`
+}
+
+// ShowbackReport is the monthly marketplace statement for every team
+type ShowbackReport struct {
+	Month        string         `json:"month"` // YYYY-MM
+	Start        time.Time      `json:"start"`
+	End          time.Time      `json:"end"`
+	TimeZone     string         `json:"time_zone"` // Month boundaries are midnight here
+	Currency     string         `json:"currency"`
+	Teams        []TeamShowback `json:"teams"`
+	Charges      float64        `json:"charges"`
+	ProviderCost float64        `json:"provider_cost"`
+	Margin       float64        `json:"margin"`
+	Subsidy      float64        `json:"subsidy"`
+	Unpriced     []string       `json:"unpriced_pools,omitempty"` // Charged at provider cost
+}
+
+// MonthStart returns midnight on the first of a time's month
+func MonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// BuildShowbackReport prices the GPU time of usage records falling in the
+// month containing month with the pool price cards and the provider pricing.
+// Workloads without the team label are reported under "unassigned".
+func BuildShowbackReport(records []gpu.UsageRecord, pricing GPUCostConfiguration, config ShowbackConfig, month time.Time) ShowbackReport {
+	start := MonthStart(month)
+	end := start.AddDate(0, 1, 0)
+	report := ShowbackReport{
+		Month:    start.Format("2006-01"),
+		Start:    start,
+		End:      end,
+		TimeZone: start.Location().String(),
+		Currency: pricing.Currency,
+		Teams:    make([]TeamShowback, 0),
+	}
+
+	teams := make(map[string]*TeamShowback)
+	lines := make(map[string]map[string]*ShowbackLine)
+	workloads := make(map[string]map[string]bool)
+	unpriced := make(map[string]bool)
+	for _, record := range records {
+		hours := record.Hours(start, end)
+		if hours <= 0 {
+			continue
+		}
+		team := record.Labels[config.TeamLabel]
+		if team == "" {
+			team = "unassigned"
+		}
+		pool := record.Pool
+		if pool == "" {
+			pool = DefaultPoolName
+		}
+
+		gpuType := normalizeGPUType(record.GPUName)
+		cost := pricing.usageCost(record, hours)
+		charges := cost
+		if price, priced := config.charge(pool, gpuType, record.Shared); priced {
+			charges = price * hours
+		} else {
+			unpriced[pool] = true
+		}
+
+		summary, exists := teams[team]
+		if !exists {
+			summary = &TeamShowback{Team: team}
+			teams[team] = summary
+			lines[team] = make(map[string]*ShowbackLine)
+			workloads[team] = make(map[string]bool)
+		}
+		line, exists := lines[team][pool]
+		if !exists {
+			line = &ShowbackLine{Pool: pool}
+			lines[team][pool] = line
+		}
+		line.GPUHours += hours
+		line.Charges += charges
+		line.ProviderCost += cost
+		workloads[team][record.WorkloadID] = true
+	}
+
+	for team, summary := range teams {
+		for _, line := range lines[team] {
+			summary.GPUHours += line.GPUHours
+			summary.Charges += line.Charges
+			summary.ProviderCost += line.ProviderCost
+			summary.Pools = append(summary.Pools, *line)
+		}
+		sort.Slice(summary.Pools, func(i, j int) bool {
+			return summary.Pools[i].Pool < summary.Pools[j].Pool
+		})
+		summary.Workloads = len(workloads[team])
+		summary.Margin = summary.Charges - summary.ProviderCost
+		if summary.Charges > 0 {
+			summary.MarginPercent = summary.Margin / summary.Charges * 100
+		}
+		if summary.Margin < 0 {
+			summary.Subsidy = -summary.Margin
+		}
+
+		report.Charges += summary.Charges
+		report.ProviderCost += summary.ProviderCost
+		report.Subsidy += summary.Subsidy
+		report.Teams = append(report.Teams, *summary)
+	}
+	report.Margin = report.Charges - report.ProviderCost
+	sort.Slice(report.Teams, func(i, j int) bool {
+		return report.Teams[i].Team < report.Teams[j].Team
+	})
+	for pool := range unpriced {
+		report.Unpriced = append(report.Unpriced, pool)
+	}
+	sort.Strings(report.Unpriced)
+	return report
+}
+
+// Markdown renders the report as a statement with amounts, hours and dates
+// written the locale's way
+func (r ShowbackReport) Markdown(locale ReportLocale) string {
+	var b strings.Builder
+	money := func(amount float64) string {
+		return locale.FormatCurrency(amount, r.Currency)
+	}
+
+	fmt.Fprintf(&b, "# GPU Showback: %s\n\n", locale.FormatMonth(r.Start))
+	fmt.Fprintf(&b, "- **Period:** %s to %s (%s)\n", locale.FormatDate(r.Start), locale.FormatDate(r.End.Add(-time.Nanosecond)), locale.ZoneName())
+	fmt.Fprintf(&b, "- **Charges:** %s\n", money(r.Charges))
+	fmt.Fprintf(&b, "- **Provider cost:** %s\n", money(r.ProviderCost))
+	fmt.Fprintf(&b, "- **Margin:** %s\n", money(r.Margin))
+	if r.Subsidy > 0 {
+		fmt.Fprintf(&b, "- **Subsidy:** %s\n", money(r.Subsidy))
+	}
+	if len(r.Unpriced) > 0 {
+		fmt.Fprintf(&b, "- **Charged at provider cost:** %s\n", strings.Join(r.Unpriced, ", "))
+	}
+
+	b.WriteString("\n## Teams\n\n")
+	b.WriteString("| Team | Workloads | GPU hours | Charges | Provider cost | Margin |\n")
+	b.WriteString("|---|---:|---:|---:|---:|---:|\n")
+	for _, team := range r.Teams {
+		fmt.Fprintf(&b, "| %s | %d | %s | %s | %s | %s |\n",
+			markdownCell(team.Team),
+			team.Workloads,
+			locale.FormatNumber(team.GPUHours, 1),
+			money(team.Charges),
+			money(team.ProviderCost),
+			money(team.Margin))
+	}
+	return b.String()
+}
+
+// usageCost is the provider cost of hours of a usage record
+func (c GPUCostConfiguration) usageCost(record gpu.UsageRecord, hours float64) float64 {
+	cost := c.hourlyCost(normalizeGPUType(record.GPUName)) * (1 - c.SpotInstanceDiscount) * hours
+	if record.Shared {
+		// Co-located workloads split the GPU's cost
+		cost /= 2
+	}
+	return cost
+}
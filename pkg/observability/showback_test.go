@@ -0,0 +1,129 @@
+package observability
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestBuildShowbackReport(t *testing.T) {
+	pricing := DefaultGPUCostConfiguration()
+	config := DefaultShowbackConfig()
+	config.PriceCards["prod-inference"] = PriceCard{PerGPUHour: 4.0, ByGPUType: map[string]float64{"t4": 0.8}}
+	config.PriceCards["research"] = PriceCard{PerGPUHour: 2.0, SharedFactor: 0.5}
+
+	march := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	search := map[string]string{"team": "search"}
+	vision := map[string]string{"team": "vision"}
+	records := []gpu.UsageRecord{
+		// 10 A100 hours at 4.00 against 3.06
+		{WorkloadID: "serve", Pool: "prod-inference", Labels: search, GPUName: "NVIDIA A100", Start: march, End: march.Add(10 * time.Hour)},
+		// 10 T4 hours at the 0.80 type price
+		{WorkloadID: "embed", Pool: "prod-inference", Labels: search, GPUName: "Tesla T4", Start: march, End: march.Add(10 * time.Hour)},
+		// Started in February: only the 4 March hours count, shared at half price and cost
+		{WorkloadID: "train", Pool: "research", Labels: vision, GPUName: "NVIDIA A100", Shared: true,
+			Start: march.Add(-6 * time.Hour), End: march.Add(4 * time.Hour)},
+		// No price card and no team: charged at cost to unassigned
+		{WorkloadID: "adhoc", GPUName: "NVIDIA A100", Start: march, End: march.Add(2 * time.Hour)},
+		// April usage is left out
+		{WorkloadID: "later", Pool: "research", Labels: vision, GPUName: "NVIDIA A100", Start: march.AddDate(0, 1, 0), End: march.AddDate(0, 1, 1)},
+	}
+
+	report := BuildShowbackReport(records, pricing, config, march.Add(15*24*time.Hour))
+	if report.Month != "2024-03" || !report.Start.Equal(march) || len(report.Teams) != 3 {
+		t.Fatalf("Expected March statements for 3 teams, got %+v", report)
+	}
+	teams := make(map[string]TeamShowback)
+	for _, team := range report.Teams {
+		teams[team.Team] = team
+	}
+
+	searchTeam := teams["search"]
+	charges, cost := 40+8.0, 10*DefaultCostA100+10*DefaultCostT4
+	if math.Abs(searchTeam.Charges-charges) > 1e-9 || math.Abs(searchTeam.ProviderCost-cost) > 1e-9 {
+		t.Errorf("Expected search charged %.2f against %.2f, got %+v", charges, cost, searchTeam)
+	}
+	if searchTeam.GPUHours != 20 || searchTeam.Workloads != 2 || len(searchTeam.Pools) != 1 || searchTeam.Subsidy != 0 {
+		t.Errorf("Unexpected search statement %+v", searchTeam)
+	}
+
+	// 4 shared hours at 1.00 against half of 4 * 3.06: subsidized
+	visionTeam := teams["vision"]
+	if math.Abs(visionTeam.Charges-4) > 1e-9 || math.Abs(visionTeam.ProviderCost-2*DefaultCostA100) > 1e-9 {
+		t.Errorf("Unexpected vision charges %+v", visionTeam)
+	}
+	if math.Abs(visionTeam.Subsidy-(2*DefaultCostA100-4)) > 1e-9 || visionTeam.Margin >= 0 {
+		t.Errorf("Expected vision to be subsidized, got %+v", visionTeam)
+	}
+
+	unassigned := teams["unassigned"]
+	if unassigned.Margin != 0 || unassigned.Pools[0].Pool != DefaultPoolName {
+		t.Errorf("Expected unpriced usage charged at cost in the default pool, got %+v", unassigned)
+	}
+	if len(report.Unpriced) != 1 || report.Unpriced[0] != DefaultPoolName {
+		t.Errorf("Expected the default pool reported as unpriced, got %v", report.Unpriced)
+	}
+	if math.Abs(report.Margin-(report.Charges-report.ProviderCost)) > 1e-9 || math.Abs(report.Subsidy-visionTeam.Subsidy) > 1e-9 {
+		t.Errorf("Unexpected report totals %+v", report)
+	}
+	if report.TimeZone != "UTC" {
+		t.Errorf("Expected the month's time zone reported, got %q", report.TimeZone)
+	}
+
+	markdown := report.Markdown(ReportLocale{TimeZone: "UTC", Locale: "de-DE"})
+	for _, want := range []string{"# GPU Showback: März 2024", "01.03.2024 to 31.03.2024 (UTC)", "| search | 2 | 20,0 | 48,00\u00a0$ |"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Expected %q in the statement:\n%s", want, markdown)
+		}
+	}
+}
+
+func TestShowbackEndpoint(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{
+		Port:     0,
+		Showback: ShowbackConfig{TeamLabel: "owner", PriceCards: map[string]PriceCard{DefaultPoolName: {PerGPUHour: 5}}},
+	})
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/costs/showback", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a scheduler, got %d", response.Code)
+	}
+
+	scheduler := gpu.NewScheduler(gpu.StrategyLeastUtilized)
+	scheduler.RegisterGPU(&gpu.GPU{ID: "gpu-0", Name: "NVIDIA A100", MemoryTotal: 40960, Available: true})
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "job", Labels: map[string]string{"owner": "ads"}, MemoryRequired: 1024})
+	scheduler.Schedule()
+	dashboard.SetScheduler(scheduler)
+
+	response := serveDashboard(dashboard, http.MethodGet, "/api/v1/costs/showback", "")
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", response.Code)
+	}
+	var report ShowbackReport
+	if err := json.Unmarshal(response.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if report.Month != time.Now().Format("2006-01") || len(report.Teams) != 1 || report.Teams[0].Team != "ads" {
+		t.Errorf("Expected this month's statement for ads, got %+v", report)
+	}
+
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/costs/showback?month=March", ""); response.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a malformed month, got %d", response.Code)
+	}
+
+	response = serveDashboard(dashboard, http.MethodGet, "/api/v1/costs/showback?format=markdown&locale=en-GB&tz=Pacific/Auckland", "")
+	if response.Code != http.StatusOK || response.Header().Get("Content-Type") != "text/markdown; charset=utf-8" {
+		t.Fatalf("Expected a Markdown statement, got %d %q", response.Code, response.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(response.Body.String(), "(Pacific/Auckland)") || !strings.Contains(response.Body.String(), "| ads |") {
+		t.Errorf("Expected an Auckland statement for ads, got:\n%s", response.Body.String())
+	}
+	for _, path := range []string{"/api/v1/costs/showback?format=pdf", "/api/v1/costs/showback?tz=Mars/Olympus"} {
+		if response := serveDashboard(dashboard, http.MethodGet, path, ""); response.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", path, response.Code)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// snapshotVersion is the on-disk snapshot format version
+const snapshotVersion = 1
+
+// MonitoringSnapshot is a serializable copy of the monitoring buffers
+type MonitoringSnapshot struct {
+	Metrics []Metric    `json:"metrics"`
+	Events  []Event     `json:"events"`
+	Costs   []CostEntry `json:"costs"`
+}
+
+// StateSnapshot is the operational state persisted to disk
+type StateSnapshot struct {
+	Version      int                       `json:"version"`
+	CreatedAt    time.Time                 `json:"created_at"`
+	Monitoring   *MonitoringSnapshot       `json:"monitoring,omitempty"`
+	Scheduler    *gpu.SchedulerSnapshot    `json:"scheduler,omitempty"`
+	AlertHistory map[string][]gpu.GPUAlert `json:"alert_history,omitempty"`
+}
+
+// SnapshotConfig configures periodic state snapshots
+type SnapshotConfig struct {
+	Path     string        // File the snapshot is written to and restored from
+	Interval time.Duration // How often to snapshot while running
+}
+
+// DefaultSnapshotConfig returns a default snapshot configuration
+func DefaultSnapshotConfig() SnapshotConfig {
+	return SnapshotConfig{
+		Path:     "agentaflow-state.json",
+		Interval: time.Minute,
+	}
+}
+
+// Snapshot returns a copy of the metric, event and cost buffers
+func (ms *MonitoringService) Snapshot() MonitoringSnapshot {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return MonitoringSnapshot{
+		Metrics: append([]Metric(nil), ms.metrics...),
+		Events:  append([]Event(nil), ms.events...),
+		Costs:   append([]CostEntry(nil), ms.costs...),
+	}
+}
+
+// Restore replaces the buffers with a snapshot's contents, applying the
+// current retention configuration to the restored entries
+func (ms *MonitoringService) Restore(snapshot MonitoringSnapshot) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.metrics = append([]Metric(nil), snapshot.Metrics...)
+	ms.events = append([]Event(nil), snapshot.Events...)
+	ms.costs = append([]CostEntry(nil), snapshot.Costs...)
+	ms.enforceRetention(time.Now())
+}
+
+// SnapshotAlertHistory returns a copy of the alert history for every GPU
+func (gmi *GPUMetricsIntegration) SnapshotAlertHistory() map[string][]gpu.GPUAlert {
+	gmi.mu.RLock()
+	defer gmi.mu.RUnlock()
+
+	history := make(map[string][]gpu.GPUAlert, len(gmi.alertHistory))
+	for gpuID, alerts := range gmi.alertHistory {
+		history[gpuID] = append([]gpu.GPUAlert(nil), alerts...)
+	}
+	return history
+}
+
+// RestoreAlertHistory replaces the alert history with a snapshot's contents
+func (gmi *GPUMetricsIntegration) RestoreAlertHistory(history map[string][]gpu.GPUAlert) {
+	restored := make(map[string][]gpu.GPUAlert, len(history))
+	for gpuID, alerts := range history {
+		if len(alerts) > 100 {
+			alerts = alerts[len(alerts)-100:]
+		}
+		restored[gpuID] = append([]gpu.GPUAlert(nil), alerts...)
+	}
+
+	gmi.mu.Lock()
+	defer gmi.mu.Unlock()
+	gmi.alertHistory = restored
+}
+
+// Snapshotter periodically persists monitoring buffers, scheduler state and
+// alert history to disk and restores them on startup
+type Snapshotter struct {
+	config      SnapshotConfig
+	monitoring  *MonitoringService
+	scheduler   *gpu.Scheduler
+	integration *GPUMetricsIntegration
+
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	lastSave time.Time
+	lastErr  error
+	mu       sync.RWMutex
+}
+
+// NewSnapshotter creates a snapshotter; any of the sources may be nil
+func NewSnapshotter(config SnapshotConfig, monitoring *MonitoringService, scheduler *gpu.Scheduler, integration *GPUMetricsIntegration) (*Snapshotter, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("snapshot path is required")
+	}
+	if config.Interval <= 0 {
+		return nil, fmt.Errorf("snapshot interval must be positive")
+	}
+
+	return &Snapshotter{
+		config:      config,
+		monitoring:  monitoring,
+		scheduler:   scheduler,
+		integration: integration,
+	}, nil
+}
+
+// Capture collects the current state from every attached source
+func (s *Snapshotter) Capture() StateSnapshot {
+	snapshot := StateSnapshot{
+		Version:   snapshotVersion,
+		CreatedAt: time.Now(),
+	}
+
+	if s.monitoring != nil {
+		monitoring := s.monitoring.Snapshot()
+		snapshot.Monitoring = &monitoring
+	}
+	if s.scheduler != nil {
+		scheduler := s.scheduler.Snapshot()
+		snapshot.Scheduler = &scheduler
+	}
+	if s.integration != nil {
+		snapshot.AlertHistory = s.integration.SnapshotAlertHistory()
+	}
+
+	return snapshot
+}
+
+// Save writes a snapshot to disk. The file is replaced atomically so a crash
+// mid-write never leaves a truncated snapshot behind.
+func (s *Snapshotter) Save() error {
+	err := s.save(s.Capture())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastErr = err
+	if err == nil {
+		s.lastSave = time.Now()
+	}
+	return err
+}
+
+// save encodes the snapshot to a temporary file and renames it into place
+func (s *Snapshotter) save(snapshot StateSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	dir := filepath.Dir(s.config.Path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.config.Path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.config.Path); err != nil {
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+	return nil
+}
+
+// Restore loads the snapshot from disk into every attached source. It
+// returns false without error when no snapshot exists yet.
+func (s *Snapshotter) Restore() (bool, error) {
+	data, err := os.ReadFile(s.config.Path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snapshot StateSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return false, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	if snapshot.Version != snapshotVersion {
+		return false, fmt.Errorf("unsupported snapshot version %d", snapshot.Version)
+	}
+
+	if s.monitoring != nil && snapshot.Monitoring != nil {
+		s.monitoring.Restore(*snapshot.Monitoring)
+	}
+	if s.scheduler != nil && snapshot.Scheduler != nil {
+		s.scheduler.Restore(*snapshot.Scheduler)
+	}
+	if s.integration != nil && snapshot.AlertHistory != nil {
+		s.integration.RestoreAlertHistory(snapshot.AlertHistory)
+	}
+
+	return true, nil
+}
+
+// Start begins periodic snapshotting
+func (s *Snapshotter) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopCh != nil {
+		return
+	}
+	s.stopCh = make(chan struct{})
+	s.doneCh = make(chan struct{})
+	go s.run(s.stopCh, s.doneCh)
+}
+
+// Stop halts periodic snapshotting and writes a final snapshot
+func (s *Snapshotter) Stop() error {
+	s.mu.Lock()
+	stopCh, doneCh := s.stopCh, s.doneCh
+	s.stopCh, s.doneCh = nil, nil
+	s.mu.Unlock()
+
+	if stopCh == nil {
+		return nil
+	}
+	close(stopCh)
+	<-doneCh
+	return s.Save()
+}
+
+// run snapshots on every interval until stopped
+func (s *Snapshotter) run(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Save()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// GetStatus returns when the last snapshot was written and any save error
+func (s *Snapshotter) GetStatus() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := map[string]interface{}{
+		"path":             s.config.Path,
+		"interval_seconds": s.config.Interval.Seconds(),
+		"running":          s.stopCh != nil,
+	}
+	if !s.lastSave.IsZero() {
+		status["last_snapshot"] = s.lastSave
+	}
+	if s.lastErr != nil {
+		status["last_error"] = s.lastErr.Error()
+	}
+	return status
+}
+
+// Check reports the error from the most recent snapshot, if it failed
+func (s *Snapshotter) Check() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.lastErr != nil {
+		return fmt.Errorf("last snapshot failed: %v", s.lastErr)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package observability
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestSnapshotterRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	config := SnapshotConfig{Path: path, Interval: time.Minute}
+
+	monitor := NewMonitoringService(100)
+	monitor.RecordMetric(Metric{Name: "gpu_utilization", Value: 80, Labels: map[string]string{"gpu_id": "gpu-0"}})
+	monitor.RecordEvent(Event{ID: "evt-1", Type: "gpu_alert", Severity: "warning"})
+	monitor.RecordCost(CostEntry{Operation: "inference", Cost: 1.5})
+
+	scheduler := gpu.NewScheduler(gpu.StrategyLeastUtilized)
+	scheduler.RegisterGPU(&gpu.GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "job-1", MemoryRequired: 64000})
+
+	integration := NewGPUMetricsIntegration(monitor, nil)
+	integration.RestoreAlertHistory(map[string][]gpu.GPUAlert{
+		"gpu-0": {{Type: "temperature", Timestamp: time.Now()}},
+	})
+
+	snapshotter, err := NewSnapshotter(config, monitor, scheduler, integration)
+	if err != nil {
+		t.Fatalf("Failed to create snapshotter: %v", err)
+	}
+	if err := snapshotter.Save(); err != nil {
+		t.Fatalf("Failed to save snapshot: %v", err)
+	}
+
+	// Simulate a restart with empty state
+	restoredMonitor := NewMonitoringService(100)
+	restoredScheduler := gpu.NewScheduler(gpu.StrategyLeastUtilized)
+	restoredIntegration := NewGPUMetricsIntegration(restoredMonitor, nil)
+	restorer, _ := NewSnapshotter(config, restoredMonitor, restoredScheduler, restoredIntegration)
+
+	restored, err := restorer.Restore()
+	if err != nil || !restored {
+		t.Fatalf("Expected snapshot to be restored, got %v, %v", restored, err)
+	}
+
+	occupancy := restoredMonitor.GetBufferOccupancy()
+	for _, signal := range []string{SignalMetrics, SignalEvents, SignalCosts} {
+		if entries := occupancy[signal].(map[string]interface{})["entries"].(int); entries != 1 {
+			t.Errorf("Expected 1 %s entry restored, got %d", signal, entries)
+		}
+	}
+	if len(restoredScheduler.GetGPUStatus()) != 1 {
+		t.Error("Expected scheduler GPUs to be restored")
+	}
+	if restoredScheduler.GetUtilizationMetrics()["pending_workloads"] != 1 {
+		t.Error("Expected queued workloads to be restored")
+	}
+	if alerts := restoredIntegration.GetAlertHistory("gpu-0", time.Time{}); len(alerts) != 1 {
+		t.Errorf("Expected alert history to be restored, got %d alerts", len(alerts))
+	}
+}
+
+func TestSnapshotterMissingAndCorruptFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	snapshotter, _ := NewSnapshotter(SnapshotConfig{Path: path, Interval: time.Minute}, NewMonitoringService(10), nil, nil)
+
+	restored, err := snapshotter.Restore()
+	if err != nil || restored {
+		t.Errorf("Expected a missing snapshot to be skipped, got %v, %v", restored, err)
+	}
+
+	if err := os.WriteFile(path, []byte("{truncated"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := snapshotter.Restore(); err == nil {
+		t.Error("Expected error for a corrupt snapshot")
+	}
+
+	if _, err := NewSnapshotter(SnapshotConfig{Interval: time.Minute}, nil, nil, nil); err == nil {
+		t.Error("Expected error for a missing path")
+	}
+}
+
+func TestSnapshotterStopWritesFinalSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	monitor := NewMonitoringService(10)
+	snapshotter, _ := NewSnapshotter(SnapshotConfig{Path: path, Interval: time.Hour}, monitor, nil, nil)
+
+	snapshotter.Start()
+	monitor.RecordEvent(Event{ID: "evt-1", Type: "test"})
+	if err := snapshotter.Stop(); err != nil {
+		t.Fatalf("Failed to stop snapshotter: %v", err)
+	}
+
+	restoredMonitor := NewMonitoringService(10)
+	restorer, _ := NewSnapshotter(SnapshotConfig{Path: path, Interval: time.Hour}, restoredMonitor, nil, nil)
+	if _, err := restorer.Restore(); err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if len(restoredMonitor.GetEvents(time.Time{}, time.Now().Add(time.Minute), "")) != 1 {
+		t.Error("Expected final snapshot to include events recorded before stop")
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected temporary files to be cleaned up, found %d entries", len(entries))
+	}
+}
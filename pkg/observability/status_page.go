@@ -0,0 +1,393 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Overall statuses shown on the status page
+const (
+	StatusOperational = "operational"
+	StatusDegraded    = "degraded"
+	StatusMajorOutage = "major_outage"
+)
+
+// StatusPageConfig configures the public status page
+type StatusPageConfig struct {
+	Port               int              `yaml:"port" json:"port"`
+	Title              string           `yaml:"title" json:"title"`
+	CheckInterval      time.Duration    `yaml:"check_interval" json:"check_interval"`           // How often serving endpoints are probed
+	AvailabilityWindow time.Duration    `yaml:"availability_window" json:"availability_window"` // Period endpoint availability is reported over
+	HTTP               HTTPServerConfig `yaml:"http" json:"http"`                               // Compression, TLS and HTTP/2
+}
+
+// DefaultStatusPageConfig returns the default status page configuration
+func DefaultStatusPageConfig() StatusPageConfig {
+	return StatusPageConfig{
+		Port:               9100,
+		Title:              "GPU Platform Status",
+		CheckInterval:      30 * time.Second,
+		AvailabilityWindow: 24 * time.Hour,
+	}
+}
+
+// ComponentStatus is the public status of a cluster component
+type ComponentStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// EndpointStatus is the current state and recent availability of a serving endpoint
+type EndpointStatus struct {
+	Name         string     `json:"name"`
+	Up           bool       `json:"up"`
+	Availability float64    `json:"availability_percent"` // Of probes in the availability window
+	LastChecked  *time.Time `json:"last_checked,omitempty"`
+}
+
+// PublicIncident is an open incident without its per-GPU alerts and labels
+type PublicIncident struct {
+	Title     string    `json:"title"`
+	Severity  string    `json:"severity"`
+	Status    string    `json:"status"`
+	GPUCount  int       `json:"gpu_count"`
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StatusSummary is everything the status page shows
+type StatusSummary struct {
+	Title      string            `json:"title"`
+	Status     string            `json:"status"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	TotalGPUs  int               `json:"total_gpus"`
+	ActiveGPUs int               `json:"active_gpus"`
+	Components []ComponentStatus `json:"components"`
+	Endpoints  []EndpointStatus  `json:"endpoints"`
+	Incidents  []PublicIncident  `json:"incidents"`
+}
+
+// endpointProbe is the result of one endpoint check
+type endpointProbe struct {
+	at time.Time
+	up bool
+}
+
+// StatusPage serves a read-only summary of cluster health, serving endpoint
+// availability and open incidents on its own port, so it can be shared with
+// internal customers without exposing the dashboard and its APIs
+type StatusPage struct {
+	config    StatusPageConfig
+	dashboard *WebDashboard
+	endpoints map[string]HealthCheck
+	probes    map[string][]endpointProbe
+	server    *http.Server
+
+	stopCh chan struct{}
+	mu     sync.RWMutex
+}
+
+// NewStatusPage creates a status page summarizing a dashboard's components and incidents
+func NewStatusPage(config StatusPageConfig, dashboard *WebDashboard) *StatusPage {
+	defaults := DefaultStatusPageConfig()
+	if config.Title == "" {
+		config.Title = defaults.Title
+	}
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = defaults.CheckInterval
+	}
+	if config.AvailabilityWindow <= 0 {
+		config.AvailabilityWindow = defaults.AvailabilityWindow
+	}
+
+	sp := &StatusPage{
+		config:    config,
+		dashboard: dashboard,
+		endpoints: make(map[string]HealthCheck),
+		probes:    make(map[string][]endpointProbe),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", sp.handlePage)
+	mux.HandleFunc("/api/status", sp.handleStatus)
+	sp.server = newHTTPServer(&http.Server{
+		Addr:         fmt.Sprintf(":%d", config.Port),
+		Handler:      mux,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+	}, config.HTTP)
+	return sp
+}
+
+// AddEndpoint adds a serving endpoint whose check is probed every
+// CheckInterval, e.g. a request to a model server's health route
+func (sp *StatusPage) AddEndpoint(name string, check HealthCheck) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.endpoints[name] = check
+}
+
+// Probe checks every endpoint once and drops results older than the availability window
+func (sp *StatusPage) Probe(now time.Time) {
+	sp.mu.RLock()
+	endpoints := make(map[string]HealthCheck, len(sp.endpoints))
+	for name, check := range sp.endpoints {
+		endpoints[name] = check
+	}
+	sp.mu.RUnlock()
+
+	results := make(map[string]bool, len(endpoints))
+	for name, check := range endpoints {
+		results[name] = check() == nil
+	}
+
+	cutoff := now.Add(-sp.config.AvailabilityWindow)
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	for name, up := range results {
+		probes := append(sp.probes[name], endpointProbe{at: now, up: up})
+		first := 0
+		for first < len(probes) && !probes[first].at.After(cutoff) {
+			first++
+		}
+		sp.probes[name] = probes[first:]
+	}
+}
+
+// Summary returns the current status. Endpoints that have not been probed yet
+// are reported as down.
+func (sp *StatusPage) Summary(now time.Time) StatusSummary {
+	summary := StatusSummary{
+		Title:      sp.config.Title,
+		Status:     StatusOperational,
+		UpdatedAt:  now,
+		Components: make([]ComponentStatus, 0),
+		Endpoints:  make([]EndpointStatus, 0),
+		Incidents:  make([]PublicIncident, 0),
+	}
+
+	components, failing := sp.dashboard.CheckHealth()
+	for name, health := range components {
+		if health.Status != ComponentDisabled {
+			summary.Components = append(summary.Components, ComponentStatus{Name: name, Status: health.Status})
+		}
+	}
+	sort.Slice(summary.Components, func(i, j int) bool {
+		return summary.Components[i].Name < summary.Components[j].Name
+	})
+
+	sp.dashboard.mu.RLock()
+	summary.TotalGPUs = len(sp.dashboard.lastMetrics)
+	summary.ActiveGPUs = sp.dashboard.calculateSystemStats().ActiveGPUs
+	incidents := sp.dashboard.getOpenIncidents()
+	sp.dashboard.mu.RUnlock()
+
+	critical := false
+	for _, incident := range incidents {
+		critical = critical || incident.Severity == "critical"
+		summary.Incidents = append(summary.Incidents, PublicIncident{
+			Title:     incident.Title,
+			Severity:  incident.Severity,
+			Status:    incident.Status,
+			GPUCount:  incident.GPUCount,
+			StartedAt: incident.StartedAt,
+			UpdatedAt: incident.UpdatedAt,
+		})
+	}
+
+	sp.mu.RLock()
+	for name := range sp.endpoints {
+		status := EndpointStatus{Name: name}
+		probes := sp.probes[name]
+		if len(probes) > 0 {
+			up := 0
+			for _, probe := range probes {
+				if probe.up {
+					up++
+				}
+			}
+			last := probes[len(probes)-1]
+			status.Up = last.up
+			status.Availability = float64(up) / float64(len(probes)) * 100
+			status.LastChecked = &last.at
+		}
+		summary.Endpoints = append(summary.Endpoints, status)
+	}
+	sp.mu.RUnlock()
+	sort.Slice(summary.Endpoints, func(i, j int) bool {
+		return summary.Endpoints[i].Name < summary.Endpoints[j].Name
+	})
+
+	down := 0
+	for _, endpoint := range summary.Endpoints {
+		if !endpoint.Up {
+			down++
+		}
+	}
+	switch {
+	case critical || (down > 0 && down == len(summary.Endpoints)):
+		summary.Status = StatusMajorOutage
+	case down > 0 || len(failing) > 0 || len(summary.Incidents) > 0:
+		summary.Status = StatusDegraded
+	}
+	return summary
+}
+
+// Handler returns the status page's HTTP handler
+func (sp *StatusPage) Handler() http.Handler {
+	return sp.server.Handler
+}
+
+// Start probes endpoints every CheckInterval and serves the status page
+// until Stop is called
+func (sp *StatusPage) Start() error {
+	sp.mu.Lock()
+	if sp.stopCh != nil {
+		sp.mu.Unlock()
+		return fmt.Errorf("status page already started")
+	}
+	stopCh := make(chan struct{})
+	sp.stopCh = stopCh
+	sp.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(sp.config.CheckInterval)
+		defer ticker.Stop()
+		sp.Probe(time.Now())
+		for {
+			select {
+			case <-ticker.C:
+				sp.Probe(time.Now())
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+
+	log.Printf("Status page starting on :%d", sp.config.Port)
+	if err := listenAndServe(sp.server, sp.config.HTTP); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Stop stops probing and shuts the status page server down
+func (sp *StatusPage) Stop() error {
+	sp.mu.Lock()
+	if sp.stopCh != nil {
+		close(sp.stopCh)
+		sp.stopCh = nil
+	}
+	sp.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return sp.server.Shutdown(ctx)
+}
+
+// handleStatus serves the summary as JSON
+func (sp *StatusPage) handleStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	json.NewEncoder(w).Encode(sp.Summary(time.Now()))
+}
+
+// handlePage renders the summary as HTML
+func (sp *StatusPage) handlePage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := statusPageTemplate.Execute(w, sp.Summary(time.Now())); err != nil {
+		log.Printf("Status page render error: %v", err)
+	}
+}
+
+// statusPageTemplate is the status page HTML; it reloads every minute
+var statusPageTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"label": func(status string) string {
+		switch status {
+		case StatusOperational, ComponentHealthy:
+			return "Operational"
+		case StatusDegraded:
+			return "Degraded Performance"
+		case StatusMajorOutage:
+			return "Major Outage"
+		}
+		return "Outage"
+	},
+	"time": func(t time.Time) string {
+		return t.UTC().Format("2006-01-02 15:04 UTC")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <meta http-equiv="refresh" content="60">
+    <title>{{.Title}}</title>
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; max-width: 760px; margin: 2rem auto; padding: 0 1rem; color: #212529; }
+        .banner { padding: 1rem 1.25rem; border-radius: 6px; color: #fff; font-size: 1.25rem; margin-bottom: 2rem; }
+        .operational, .healthy { background: #198754; }
+        .degraded { background: #fd7e14; }
+        .major_outage, .failing { background: #dc3545; }
+        .pill { color: #fff; padding: 0.1rem 0.6rem; border-radius: 1rem; font-size: 0.85rem; }
+        table { width: 100%; border-collapse: collapse; margin-bottom: 2rem; }
+        td { padding: 0.6rem 0; border-bottom: 1px solid #dee2e6; }
+        td:last-child { text-align: right; }
+        .muted { color: #6c757d; font-size: 0.85rem; }
+    </style>
+</head>
+<body>
+    <h1>{{.Title}}</h1>
+    <div class="banner {{.Status}}">{{label .Status}}</div>
+
+    <h2>Serving Endpoints</h2>
+    <table>
+        {{range .Endpoints}}
+        <tr>
+            <td>{{.Name}}</td>
+            <td><span class="muted">{{printf "%.2f" .Availability}}% available</span>
+                {{if .Up}}<span class="pill healthy">Up</span>{{else}}<span class="pill failing">Down</span>{{end}}</td>
+        </tr>
+        {{else}}
+        <tr><td class="muted">No endpoints monitored</td><td></td></tr>
+        {{end}}
+    </table>
+
+    <h2>Cluster</h2>
+    <table>
+        <tr><td>GPUs active</td><td>{{.ActiveGPUs}} / {{.TotalGPUs}}</td></tr>
+        {{range .Components}}
+        <tr><td>{{.Name}}</td><td><span class="pill {{.Status}}">{{label .Status}}</span></td></tr>
+        {{end}}
+    </table>
+
+    <h2>Active Incidents</h2>
+    {{range .Incidents}}
+    <p><strong>{{.Title}}</strong> <span class="pill {{if eq .Severity "critical"}}failing{{else}}degraded{{end}}">{{.Severity}}</span><br>
+        <span class="muted">{{.GPUCount}} GPUs affected, started {{time .StartedAt}}, updated {{time .UpdatedAt}}</span></p>
+    {{else}}
+    <p class="muted">No active incidents</p>
+    {{end}}
+
+    <p class="muted">Updated {{time .UpdatedAt}}</p>
+</body>
+</html>
+`))
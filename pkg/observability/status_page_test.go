@@ -0,0 +1,98 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestStatusPageSummary(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{Port: 0})
+	page := NewStatusPage(StatusPageConfig{AvailabilityWindow: time.Hour}, dashboard)
+
+	now := time.Now()
+	if summary := page.Summary(now); summary.Status != StatusOperational || summary.Title != "GPU Platform Status" {
+		t.Errorf("Expected an operational page with the default title, got %+v", summary)
+	}
+
+	healthy := true
+	page.AddEndpoint("chat", func() error { return nil })
+	page.AddEndpoint("embeddings", func() error {
+		if !healthy {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	})
+	page.Probe(now.Add(-2 * time.Hour)) // Outside the availability window
+	healthy = false
+	page.Probe(now.Add(-time.Minute))
+	healthy = true
+	page.Probe(now.Add(-30 * time.Second))
+	healthy = false
+	page.Probe(now)
+
+	summary := page.Summary(now)
+	if summary.Status != StatusDegraded || len(summary.Endpoints) != 2 {
+		t.Fatalf("Expected a degraded page with two endpoints, got %+v", summary)
+	}
+	embeddings := summary.Endpoints[1]
+	if embeddings.Name != "embeddings" || embeddings.Up || embeddings.Availability < 33.3 || embeddings.Availability > 33.4 {
+		t.Errorf("Expected embeddings down at 1/3 availability, got %+v", embeddings)
+	}
+	if chat := summary.Endpoints[0]; !chat.Up || chat.Availability != 100 {
+		t.Errorf("Expected chat fully available, got %+v", chat)
+	}
+
+	// A critical incident is a major outage
+	grouper, _ := NewAlertGrouper(DefaultAlertGroupingConfig())
+	dashboard.SetAlertGrouper(grouper)
+	grouper.Add("node-a/gpu-0", "A100", gpu.GPUAlert{Type: "temperature", Severity: "critical", Timestamp: now}, true)
+	summary = page.Summary(now)
+	if summary.Status != StatusMajorOutage || len(summary.Incidents) != 1 || summary.Incidents[0].Severity != "critical" {
+		t.Errorf("Expected a major outage with the incident listed, got %+v", summary)
+	}
+}
+
+func TestStatusPageHandlers(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{Port: 0})
+	page := NewStatusPage(StatusPageConfig{Title: "Acme <GPU> Status"}, dashboard)
+	page.AddEndpoint("chat", func() error { return nil })
+	page.Probe(time.Now())
+
+	recorder := httptest.NewRecorder()
+	page.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/api/status", nil))
+	var summary StatusSummary
+	if err := json.Unmarshal(recorder.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	if summary.Status != StatusOperational || len(summary.Endpoints) != 1 {
+		t.Errorf("Expected one operational endpoint, got %+v", summary)
+	}
+
+	recorder = httptest.NewRecorder()
+	page.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	body := recorder.Body.String()
+	if !strings.Contains(body, "Acme &lt;GPU&gt; Status") || !strings.Contains(body, "100.00% available") {
+		t.Errorf("Expected an escaped title and endpoint availability, got %s", body)
+	}
+
+	// The dashboard's APIs are not reachable through the status page
+	for _, path := range []string{"/api/v1/gpus", "/ws"} {
+		recorder = httptest.NewRecorder()
+		page.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 for %s, got %d", path, recorder.Code)
+		}
+	}
+	recorder = httptest.NewRecorder()
+	page.Handler().ServeHTTP(recorder, httptest.NewRequest("POST", "/api/status", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected the status page to be read-only, got %d", recorder.Code)
+	}
+}
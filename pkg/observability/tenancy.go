@@ -0,0 +1,149 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
+)
+
+// TenancyConfig scopes the dashboard API to teams. Callers authenticate with
+// a ControlTokens bearer token, an API key or an OIDC login and see only
+// their tenant's workloads, usage, alerts and artifacts; admins, admin API
+// keys and admin logins may query across tenants with ?tenant.
+type TenancyConfig struct {
+	// Tenant of each operator named in ControlTokens; tenancy is off when empty
+	Tenants map[string]string `yaml:"tenants" json:"tenants"`
+
+	// Operators who may query any tenant and call cluster-wide endpoints
+	// such as cluster costs, power controls and GPU processes
+	Admins []string `yaml:"admins" json:"admins"`
+
+	// Time zone and locale of each tenant's cost buckets and reports;
+	// tenants without one get the server's time zone in DefaultReportLocale
+	Locales map[string]ReportLocale `yaml:"locales" json:"locales,omitempty"`
+}
+
+// validate checks each tenant's report locale
+func (c TenancyConfig) validate(errs *ConfigErrors) {
+	tenants := make([]string, 0, len(c.Locales))
+	for tenant := range c.Locales {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	for _, tenant := range tenants {
+		c.Locales[tenant].validate(fmt.Sprintf("tenancy.locales.%s.", tenant), errs)
+	}
+}
+
+// Enabled reports whether API requests are scoped to tenants
+func (c TenancyConfig) Enabled() bool {
+	return len(c.Tenants) > 0
+}
+
+// isAdmin reports whether an operator may query across tenants
+func (c TenancyConfig) isAdmin(actor string) bool {
+	for _, admin := range c.Admins {
+		if admin == actor {
+			return true
+		}
+	}
+	return false
+}
+
+// tenantScope is the tenant an API request may see
+type tenantScope struct {
+	tenant string // Empty for every tenant
+	admin  bool
+}
+
+type tenantScopeKey struct{}
+
+// scopeTenant resolves the caller's tenant for every API request while
+// tenancy is enabled
+func (wd *WebDashboard) scopeTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wd.mu.RLock()
+		tenancy := wd.tenancy
+		wd.mu.RUnlock()
+		if !tenancy.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		caller, authenticated := wd.authenticate(r)
+		if !authenticated {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "invalid or missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodGet && !caller.hasScope(apikeys.ScopeReadMetrics) {
+			http.Error(w, fmt.Sprintf("%s lacks the %s scope", caller.actor, apikeys.ScopeReadMetrics), http.StatusForbidden)
+			return
+		}
+
+		scope := tenantScope{admin: caller.admin, tenant: caller.tenant}
+		requested := r.URL.Query().Get("tenant")
+		if scope.admin {
+			scope.tenant = requested
+		} else {
+			if scope.tenant == "" {
+				http.Error(w, fmt.Sprintf("%s has no tenant", caller.actor), http.StatusForbidden)
+				return
+			}
+			if requested != "" && requested != scope.tenant {
+				http.Error(w, "only admins can query other tenants", http.StatusForbidden)
+				return
+			}
+		}
+		ctx := context.WithValue(r.Context(), principalKey{}, caller)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, tenantScopeKey{}, scope)))
+	})
+}
+
+// requireAdmin limits a cluster-wide endpoint to admins while tenancy is enabled
+func (wd *WebDashboard) requireAdmin(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		scope, scoped := r.Context().Value(tenantScopeKey{}).(tenantScope)
+		if scoped && !scope.admin {
+			http.Error(w, "cluster-wide endpoint requires an admin", http.StatusForbidden)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// inTenant reports whether a request may see what a tenant owns
+func inTenant(r *http.Request, tenant string) bool {
+	scope, scoped := r.Context().Value(tenantScopeKey{}).(tenantScope)
+	return !scoped || scope.tenant == "" || scope.tenant == tenant
+}
+
+// tenantAlerts tags alerts with the tenants running workloads on their GPU
+// and keeps those the request may see
+func (wd *WebDashboard) tenantAlerts(r *http.Request, alerts []Alert) []Alert {
+	wd.mu.RLock()
+	scheduler := wd.scheduler
+	wd.mu.RUnlock()
+
+	gpuTenants := make(map[string][]string)
+	if scheduler != nil {
+		gpuTenants = scheduler.GPUTenants()
+	}
+	visible := make([]Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		alert.Tenants = gpuTenants[alert.Source]
+		for _, tenant := range alert.Tenants {
+			if inTenant(r, tenant) {
+				visible = append(visible, alert)
+				break
+			}
+		}
+		if len(alert.Tenants) == 0 && inTenant(r, "") {
+			visible = append(visible, alert)
+		}
+	}
+	return visible
+}
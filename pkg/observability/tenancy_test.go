@@ -0,0 +1,134 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// serveAs sends a request with an operator's bearer token
+func serveAs(wd *WebDashboard, token, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	wd.server.Handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestTenancyScopesAPIsToTheCallersTenant(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{
+		Port:          0,
+		ControlTokens: map[string]string{"vision-token": "ana", "nlp-token": "bo", "admin-token": "ops", "stray-token": "cy"},
+		Tenancy: TenancyConfig{
+			Tenants: map[string]string{"ana": "vision", "bo": "nlp"},
+			Admins:  []string{"ops"},
+		},
+	})
+	scheduler := gpu.NewScheduler(gpu.StrategyLeastUtilized)
+	scheduler.RegisterGPU(&gpu.GPU{ID: "gpu-0", MemoryTotal: 16384, Available: true})
+	scheduler.RegisterGPU(&gpu.GPU{ID: "gpu-1", MemoryTotal: 16384, Available: true})
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "detect", Tenant: "vision", MemoryRequired: 8192})
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "translate", Tenant: "nlp", MemoryRequired: 8192})
+	scheduler.Schedule()
+	scheduler.RegisterArtifacts("translate", []gpu.Artifact{{URI: "s3://nlp/model.bin"}})
+	dashboard.SetScheduler(scheduler)
+
+	workloadIDs := func(token, path string) []string {
+		response := serveAs(dashboard, token, http.MethodGet, path, "")
+		var listed struct {
+			Workloads []gpu.WorkloadInfo `json:"workloads"`
+		}
+		if err := json.Unmarshal(response.Body.Bytes(), &listed); err != nil {
+			t.Fatalf("Failed to decode %s: %v (%d)", path, err, response.Code)
+		}
+		ids := make([]string, 0)
+		for _, workload := range listed.Workloads {
+			ids = append(ids, workload.ID)
+		}
+		return ids
+	}
+	if ids := workloadIDs("vision-token", "/api/v1/workloads"); len(ids) != 1 || ids[0] != "detect" {
+		t.Errorf("vision sees %v, want only its own workload", ids)
+	}
+	if ids := workloadIDs("admin-token", "/api/v1/workloads"); len(ids) != 2 {
+		t.Errorf("admin sees %v, want every tenant", ids)
+	}
+	if ids := workloadIDs("admin-token", "/api/v1/workloads?tenant=nlp"); len(ids) != 1 || ids[0] != "translate" {
+		t.Errorf("admin filtered to nlp sees %v", ids)
+	}
+
+	denied := map[string]struct {
+		token, path string
+		code        int
+	}{
+		"no token":          {"", "/api/v1/workloads", http.StatusUnauthorized},
+		"operator unmapped": {"stray-token", "/api/v1/workloads", http.StatusForbidden},
+		"other tenant":      {"vision-token", "/api/v1/workloads?tenant=nlp", http.StatusForbidden},
+		"cluster costs":     {"vision-token", "/api/v1/costs", http.StatusForbidden},
+		"other artifacts":   {"vision-token", "/api/v1/workloads/translate/artifacts", http.StatusNotFound},
+	}
+	for name, request := range denied {
+		if response := serveAs(dashboard, request.token, http.MethodGet, request.path, ""); response.Code != request.code {
+			t.Errorf("%s: got %d, want %d", name, response.Code, request.code)
+		}
+	}
+	for _, token := range []string{"nlp-token", "admin-token"} {
+		if response := serveAs(dashboard, token, http.MethodGet, "/api/v1/workloads/translate/artifacts", ""); response.Code != http.StatusOK {
+			t.Errorf("%s reading nlp artifacts: got %d", token, response.Code)
+		}
+	}
+	if response := serveAs(dashboard, "admin-token", http.MethodGet, "/api/v1/costs", ""); response.Code != http.StatusOK {
+		t.Errorf("admin reading cluster costs: got %d", response.Code)
+	}
+
+	// Alerts belong to the tenants running on the GPU they fire for
+	hotGPU := ""
+	for _, workload := range scheduler.ListWorkloads() {
+		if workload.ID == "translate" {
+			hotGPU = workload.AssignedGPU
+		}
+	}
+	dashboard.lastMetrics[hotGPU] = gpu.GPUMetrics{GPUID: hotGPU, Temperature: 91}
+	var alerts []Alert
+	json.Unmarshal(serveAs(dashboard, "nlp-token", http.MethodGet, "/api/v1/alerts", "").Body.Bytes(), &alerts)
+	if len(alerts) != 1 || len(alerts[0].Tenants) != 1 || alerts[0].Tenants[0] != "nlp" {
+		t.Errorf("nlp alerts = %+v", alerts)
+	}
+	alerts = nil
+	json.Unmarshal(serveAs(dashboard, "vision-token", http.MethodGet, "/api/v1/alerts", "").Body.Bytes(), &alerts)
+	if len(alerts) != 0 {
+		t.Errorf("vision alerts = %+v, want none", alerts)
+	}
+}
+
+func TestTenancyDisabledLeavesAPIsOpen(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{Port: 0})
+	scheduler := gpu.NewScheduler(gpu.StrategyLeastUtilized)
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "detect", Tenant: "vision", MemoryRequired: 8192})
+	dashboard.SetScheduler(scheduler)
+
+	for _, path := range []string{"/api/v1/workloads", "/api/v1/costs"} {
+		if response := serveDashboard(dashboard, http.MethodGet, path, ""); response.Code != http.StatusOK {
+			t.Errorf("%s: got %d without tenancy", path, response.Code)
+		}
+	}
+}
+
+func TestTenantCostSummary(t *testing.T) {
+	ms := NewMonitoringService(100)
+	ms.RecordCost(CostEntry{ID: "c1", Operation: "training", Tenant: "vision", Cost: 12})
+	ms.RecordCost(CostEntry{ID: "c2", Operation: "inference", Tenant: "nlp", Cost: 3})
+
+	now := time.Now()
+	if summary := ms.GetTenantCostSummary(now.Add(-time.Hour), now.Add(time.Hour), "vision"); summary["total_cost"] != 12.0 {
+		t.Errorf("vision summary = %v", summary)
+	}
+	if summary := ms.GetCostSummary(now.Add(-time.Hour), now.Add(time.Hour)); summary["total_cost"] != 15.0 {
+		t.Errorf("summary = %v", summary)
+	}
+}
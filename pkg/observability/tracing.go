@@ -12,7 +12,6 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/exporters/jaeger"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -59,6 +58,33 @@ func DefaultTracingConfig() *TracingConfig {
 	}
 }
 
+// Validate checks the tracing configuration values
+func (c TracingConfig) Validate() error {
+	var errs ConfigErrors
+
+	if c.ServiceName == "" {
+		errs.add("service_name", "must not be empty")
+	}
+	switch c.ExporterType {
+	case "jaeger":
+		if c.JaegerEndpoint == "" {
+			errs.add("jaeger_endpoint", "is required for the jaeger exporter")
+		}
+	case "otlp":
+		if c.OTLPEndpoint == "" {
+			errs.add("otlp_endpoint", "is required for the otlp exporter")
+		}
+	case "stdout", "none":
+	default:
+		errs.add("exporter_type", "must be one of jaeger, otlp, stdout or none, got %q", c.ExporterType)
+	}
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		errs.add("sample_rate", "must be between 0 and 1, got %g", c.SampleRate)
+	}
+
+	return errs.err()
+}
+
 // TracingService manages OpenTelemetry tracing infrastructure
 type TracingService struct {
 	config   *TracingConfig
@@ -161,22 +187,19 @@ func (ts *TracingService) initialize() error {
 	return nil
 }
 
-// createJaegerExporter creates a Jaeger exporter
+// createJaegerExporter creates a Jaeger exporter that dials through the air
+// gap guard when one is installed
 func (ts *TracingService) createJaegerExporter() (trace.SpanExporter, error) {
 	return jaeger.New(jaeger.WithCollectorEndpoint(
 		jaeger.WithEndpoint(ts.config.JaegerEndpoint),
+		jaeger.WithHTTPClient(newExporterHTTPClient()),
 	))
 }
 
-// createOTLPExporter creates an OTLP HTTP exporter
+// createOTLPExporter creates an OTLP HTTP exporter that dials through the
+// air gap guard when one is installed
 func (ts *TracingService) createOTLPExporter() (trace.SpanExporter, error) {
-	return otlptrace.New(
-		context.Background(),
-		otlptracehttp.NewClient(
-			otlptracehttp.WithEndpoint(ts.config.OTLPEndpoint),
-			otlptracehttp.WithInsecure(),
-		),
-	)
+	return otlptrace.New(context.Background(), newOTLPHTTPClient(ts.config.OTLPEndpoint))
 }
 
 // createStdoutExporter creates a stdout exporter for development
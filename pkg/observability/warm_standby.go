@@ -0,0 +1,352 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/failover"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// WarmStandbyConfig runs the server as one of an active and a warm standby
+// pair. Both servers share a lease file; the one holding it is primary and
+// the other replicates its scheduler WAL and alert history until the lease
+// lapses, then takes over.
+type WarmStandbyConfig struct {
+	NodeID        string        `yaml:"node_id" json:"node_id"`               // Unique per server; the hostname when empty
+	AdvertiseURL  string        `yaml:"advertise_url" json:"advertise_url"`   // Where clients and the other server reach this one
+	LeasePath     string        `yaml:"lease_path" json:"lease_path"`         // On storage both servers mount
+	LeaseDuration time.Duration `yaml:"lease_duration" json:"lease_duration"` // Unrenewed for this long, the lease is taken over
+	RenewInterval time.Duration `yaml:"renew_interval" json:"renew_interval"`
+	SyncInterval  time.Duration `yaml:"sync_interval" json:"sync_interval"` // How often the standby pulls the primary's state
+
+	// The scheduler WAL; the standby keeps its replicated copy here and the
+	// primary logs to it
+	WAL gpu.WALConfig `yaml:"wal" json:"wal"`
+
+	// Control token the standby presents to the primary
+	Token   string        `yaml:"token" json:"-" secret:"true"`
+	Timeout time.Duration `yaml:"timeout" json:"timeout"`
+}
+
+// DefaultWarmStandbyConfig returns a 15 second lease renewed every 3 seconds,
+// with the standby syncing every second
+func DefaultWarmStandbyConfig() WarmStandbyConfig {
+	lease := failover.DefaultConfig()
+	return WarmStandbyConfig{
+		LeaseDuration: lease.LeaseDuration,
+		RenewInterval: lease.RenewInterval,
+		SyncInterval:  time.Second,
+		Timeout:       5 * time.Second,
+	}
+}
+
+// StandbyState is what the primary sends the standby in a sync
+type StandbyState struct {
+	Node         string                    `json:"node"`
+	Epoch        uint64                    `json:"epoch"`
+	Seq          uint64                    `json:"seq"`   // WAL sequence number the records bring the copy to
+	Reset        bool                      `json:"reset"` // Records replace the copy rather than extend it
+	Records      []gpu.WALRecord           `json:"records"`
+	AlertHistory map[string][]gpu.GPUAlert `json:"alert_history,omitempty"`
+}
+
+// WarmStandby elects the primary of a server pair, replicates the primary's
+// state to the standby and recovers it on promotion. A primary that loses the
+// lease is fenced: it stops logging and refuses API requests, and must be
+// restarted to rejoin as standby.
+type WarmStandby struct {
+	config      WarmStandbyConfig
+	elector     *failover.Elector
+	scheduler   *gpu.Scheduler
+	integration *GPUMetricsIntegration
+	client      *http.Client
+
+	// Replication progress while standby
+	epoch        uint64 // Of the primary the copy came from
+	seq          uint64
+	synced       bool
+	alertHistory map[string][]gpu.GPUAlert
+	lastSync     time.Time
+	lastErr      error
+	syncs        int
+	resets       int
+	recovered    int // Workloads recovered on promotion
+
+	syncMu sync.Mutex // Keeps promotion from reading the WAL copy mid-write
+	stopCh chan struct{}
+	doneCh chan struct{}
+	mu     sync.RWMutex
+}
+
+// NewWarmStandby validates the configuration and starts the server as
+// standby; store may be nil to use a lease file at config.LeasePath. The
+// scheduler's WAL must not be enabled: it is enabled on promotion.
+func NewWarmStandby(config WarmStandbyConfig, store failover.LeaseStore, scheduler *gpu.Scheduler, integration *GPUMetricsIntegration) (*WarmStandby, error) {
+	if scheduler == nil {
+		return nil, fmt.Errorf("scheduler is required")
+	}
+	if config.WAL.Path == "" {
+		return nil, fmt.Errorf("WAL path is required")
+	}
+	if config.AdvertiseURL == "" {
+		return nil, fmt.Errorf("advertise URL is required")
+	}
+	if parsed, err := url.Parse(config.AdvertiseURL); err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid advertise URL %q", config.AdvertiseURL)
+	}
+	if store == nil {
+		if config.LeasePath == "" {
+			return nil, fmt.Errorf("lease path is required")
+		}
+		store = failover.NewFileLeaseStore(config.LeasePath)
+	}
+	defaults := DefaultWarmStandbyConfig()
+	if config.SyncInterval <= 0 {
+		config.SyncInterval = defaults.SyncInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+
+	elector, err := failover.NewElector(failover.Config{
+		NodeID:        config.NodeID,
+		Address:       strings.TrimSuffix(config.AdvertiseURL, "/"),
+		LeaseDuration: config.LeaseDuration,
+		RenewInterval: config.RenewInterval,
+	}, store)
+	if err != nil {
+		return nil, err
+	}
+
+	ws := &WarmStandby{
+		config:      config,
+		elector:     elector,
+		scheduler:   scheduler,
+		integration: integration,
+		client:      &http.Client{Timeout: config.Timeout},
+	}
+	elector.OnPromote(ws.promote)
+	elector.OnFence(ws.fence)
+	return ws, nil
+}
+
+// promote recovers the replicated state and starts logging to the WAL
+func (ws *WarmStandby) promote(epoch uint64) {
+	ws.syncMu.Lock()
+	defer ws.syncMu.Unlock()
+
+	recovered, err := ws.scheduler.EnableWAL(ws.config.WAL)
+	if err != nil {
+		log.Printf("warm standby: failed to recover scheduler WAL at epoch %d: %v", epoch, err)
+	}
+
+	ws.mu.Lock()
+	ws.recovered, ws.lastErr = recovered, err
+	history := ws.alertHistory
+	ws.alertHistory = nil
+	ws.mu.Unlock()
+
+	if ws.integration != nil && history != nil {
+		ws.integration.RestoreAlertHistory(history)
+	}
+	log.Printf("warm standby: serving as primary at epoch %d with %d recovered workloads", epoch, recovered)
+}
+
+// fence stops logging once another server holds the lease
+func (ws *WarmStandby) fence(epoch uint64, reason string) {
+	if err := ws.scheduler.CloseWAL(); err != nil {
+		log.Printf("warm standby: failed to close WAL: %v", err)
+	}
+}
+
+// Role returns the server's role: primary, standby or fenced
+func (ws *WarmStandby) Role() string {
+	return ws.elector.Status().Role
+}
+
+// Epoch returns the lease epoch this server last saw
+func (ws *WarmStandby) Epoch() uint64 {
+	return ws.elector.Status().Epoch
+}
+
+// Primary returns the address of the current primary, if known
+func (ws *WarmStandby) Primary() string {
+	return ws.elector.Status().Primary
+}
+
+// State returns the WAL records after since and the alert history, for the
+// standby; only the primary has state to send
+func (ws *WarmStandby) State(since uint64) (StandbyState, error) {
+	status := ws.elector.Status()
+	if status.Role != failover.RolePrimary {
+		return StandbyState{}, fmt.Errorf("%s is %s, not primary", status.NodeID, status.Role)
+	}
+	records, seq, reset, err := ws.scheduler.WALRecordsSince(since)
+	if err != nil {
+		return StandbyState{}, err
+	}
+	state := StandbyState{Node: status.NodeID, Epoch: status.Epoch, Seq: seq, Reset: reset, Records: records}
+	if ws.integration != nil {
+		state.AlertHistory = ws.integration.SnapshotAlertHistory()
+	}
+	return state, nil
+}
+
+// Sync pulls the primary's state into the WAL copy once; it does nothing
+// unless this server is standby and another server is primary
+func (ws *WarmStandby) Sync() error {
+	ws.syncMu.Lock()
+	defer ws.syncMu.Unlock()
+
+	status := ws.elector.Status()
+	if status.Role != failover.RoleStandby || status.Holder == "" || status.Holder == status.NodeID || status.Primary == "" {
+		return nil
+	}
+
+	ws.mu.RLock()
+	since, epoch := ws.seq, ws.epoch
+	ws.mu.RUnlock()
+
+	state, err := ws.fetch(status.Primary, since)
+	if err == nil && state.Epoch < status.Epoch {
+		err = fmt.Errorf("%s answered from epoch %d, behind lease epoch %d", status.Primary, state.Epoch, status.Epoch)
+	}
+	// Sequence numbers restart with each primary's log
+	if err == nil && state.Epoch != epoch && since != 0 {
+		since = 0
+		state, err = ws.fetch(status.Primary, 0)
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if err == nil {
+		// The first sync replaces whatever copy an earlier run left
+		reset := state.Reset || since == 0 || !ws.synced
+		if err = gpu.WriteWALRecords(ws.config.WAL.Path, state.Records, reset); err == nil {
+			if reset {
+				ws.resets++
+			}
+			ws.epoch, ws.seq, ws.synced = state.Epoch, state.Seq, true
+			if state.AlertHistory != nil {
+				ws.alertHistory = state.AlertHistory
+			}
+			ws.lastSync = time.Now()
+			ws.syncs++
+		}
+	}
+	if err != nil {
+		// A failed write may leave a partial copy; start over from a checkpoint
+		ws.seq, ws.synced = 0, false
+	}
+	ws.lastErr = err
+	return err
+}
+
+// fetch requests the primary's state after since
+func (ws *WarmStandby) fetch(primary string, since uint64) (StandbyState, error) {
+	var state StandbyState
+	request, err := http.NewRequest("GET", fmt.Sprintf("%s/api/v1/standby/state?since=%d", primary, since), nil)
+	if err != nil {
+		return state, err
+	}
+	if ws.config.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+ws.config.Token)
+	}
+	response, err := ws.client.Do(request)
+	if err != nil {
+		return state, fmt.Errorf("failed to reach primary %s: %w", primary, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return state, fmt.Errorf("primary %s returned %s", primary, response.Status)
+	}
+	if err := json.NewDecoder(response.Body).Decode(&state); err != nil {
+		return state, fmt.Errorf("failed to decode primary state: %w", err)
+	}
+	return state, nil
+}
+
+// Start runs the election and, while standby, syncs every sync interval
+func (ws *WarmStandby) Start() {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.stopCh != nil {
+		return
+	}
+	ws.elector.Start()
+	ws.stopCh = make(chan struct{})
+	ws.doneCh = make(chan struct{})
+	go ws.run(ws.stopCh, ws.doneCh)
+}
+
+// Stop halts syncing and the election
+func (ws *WarmStandby) Stop() {
+	ws.mu.Lock()
+	stopCh, doneCh := ws.stopCh, ws.doneCh
+	ws.stopCh, ws.doneCh = nil, nil
+	ws.mu.Unlock()
+
+	if stopCh == nil {
+		return
+	}
+	close(stopCh)
+	<-doneCh
+	ws.elector.Stop()
+}
+
+// run syncs until stopped
+func (ws *WarmStandby) run(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(ws.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := ws.Sync(); err != nil {
+				log.Printf("warm standby: sync failed: %v", err)
+			}
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// GetStats returns the server's role and replication progress
+func (ws *WarmStandby) GetStats() map[string]interface{} {
+	status := ws.elector.Status()
+
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	stats := map[string]interface{}{
+		"node_id":   status.NodeID,
+		"role":      status.Role,
+		"epoch":     status.Epoch,
+		"primary":   status.Primary,
+		"since":     status.Since,
+		"seq":       ws.seq,
+		"syncs":     ws.syncs,
+		"resets":    ws.resets,
+		"recovered": ws.recovered,
+	}
+	if !ws.lastSync.IsZero() {
+		stats["last_sync"] = ws.lastSync
+	}
+	if ws.lastErr != nil {
+		stats["last_error"] = ws.lastErr.Error()
+	} else if status.LastError != "" {
+		stats["last_error"] = status.LastError
+	}
+	return stats
+}
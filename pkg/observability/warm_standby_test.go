@@ -0,0 +1,136 @@
+package observability
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/failover"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// standbyPair is one server of a warm standby pair under test
+type standbyPair struct {
+	scheduler   *gpu.Scheduler
+	integration *GPUMetricsIntegration
+	dashboard   *WebDashboard
+	server      *httptest.Server
+	standby     *WarmStandby
+}
+
+func newStandbyServer(t *testing.T, id string, store failover.LeaseStore) *standbyPair {
+	t.Helper()
+	node := &standbyPair{scheduler: gpu.NewScheduler(gpu.StrategyLeastUtilized)}
+	node.scheduler.RegisterGPU(&gpu.GPU{ID: "gpu-0", MemoryTotal: 16000, Available: true})
+	node.scheduler.RegisterGPU(&gpu.GPU{ID: "gpu-1", MemoryTotal: 16000, Available: true})
+	node.integration = NewGPUMetricsIntegration(NewMonitoringService(100), nil)
+	node.dashboard = NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{
+		Port:          0,
+		ControlTokens: map[string]string{"s3cret": "standby"},
+	})
+	node.dashboard.SetScheduler(node.scheduler)
+	node.server = httptest.NewServer(node.dashboard.server.Handler)
+	t.Cleanup(node.server.Close)
+
+	standby, err := NewWarmStandby(WarmStandbyConfig{
+		NodeID:        id,
+		AdvertiseURL:  node.server.URL,
+		LeaseDuration: 10 * time.Second,
+		RenewInterval: 2 * time.Second,
+		WAL:           gpu.WALConfig{Path: filepath.Join(t.TempDir(), id+".wal")},
+		Token:         "s3cret",
+	}, store, node.scheduler, node.integration)
+	if err != nil {
+		t.Fatalf("Failed to create warm standby: %v", err)
+	}
+	node.standby = standby
+	node.dashboard.SetWarmStandby(standby)
+	return node
+}
+
+func TestWarmStandbyFailover(t *testing.T) {
+	store := failover.NewFileLeaseStore(filepath.Join(t.TempDir(), "lease.json"))
+	active := newStandbyServer(t, "active", store)
+	standby := newStandbyServer(t, "standby", store)
+
+	start := time.Now()
+	active.standby.elector.Tick(start)
+	standby.standby.elector.Tick(start)
+	if active.standby.Role() != failover.RolePrimary || standby.standby.Role() != failover.RoleStandby {
+		t.Fatalf("Expected active primary and standby standing by, got %s and %s", active.standby.Role(), standby.standby.Role())
+	}
+
+	active.scheduler.SubmitWorkload(&gpu.Workload{ID: "train", MemoryRequired: 8000})
+	active.scheduler.SubmitWorkload(&gpu.Workload{ID: "serve", MemoryRequired: 8000})
+	active.scheduler.Schedule()
+	active.integration.RestoreAlertHistory(map[string][]gpu.GPUAlert{"gpu-0": {{Type: "temperature", Message: "hot"}}})
+	if err := standby.standby.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	active.scheduler.SubmitWorkload(&gpu.Workload{ID: "queued", MemoryRequired: 12000})
+	if err := standby.standby.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if stats := standby.standby.GetStats(); stats["syncs"] != 2 || stats["resets"] != 1 {
+		t.Errorf("Expected a full sync followed by an incremental one, got %v", stats)
+	}
+
+	// The standby turns API requests away, naming the primary
+	response := serveDashboard(standby.dashboard, http.MethodGet, "/api/v1/workloads", "")
+	if response.Code != http.StatusServiceUnavailable || response.Header().Get("X-AgentaFlow-Primary") != active.server.URL {
+		t.Errorf("Expected 503 pointing at the primary, got %d %v", response.Code, response.Header())
+	}
+	if response := serveDashboard(standby.dashboard, http.MethodGet, "/readyz", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected the standby not ready, got %d", response.Code)
+	}
+	if response := serveDashboard(standby.dashboard, http.MethodGet, "/api/v1/standby/status", ""); response.Code != http.StatusOK {
+		t.Errorf("Expected standby status served, got %d", response.Code)
+	}
+
+	// The primary stops renewing and the standby takes over with its state
+	standby.standby.elector.Tick(start.Add(10 * time.Second))
+	if standby.standby.Role() != failover.RolePrimary || standby.standby.Epoch() != 2 {
+		t.Fatalf("Expected the standby promoted at epoch 2, got %v", standby.standby.GetStats())
+	}
+	workloads := make(map[string]gpu.WorkloadStatus)
+	for _, workload := range standby.scheduler.ListWorkloads() {
+		workloads[workload.ID] = workload.Status
+	}
+	if len(workloads) != 3 || workloads["queued"] != gpu.WorkloadPending {
+		t.Errorf("Expected the three workloads recovered, got %v", workloads)
+	}
+	if history := standby.integration.SnapshotAlertHistory(); len(history["gpu-0"]) != 1 {
+		t.Errorf("Expected the alert history recovered, got %v", history)
+	}
+	response = serveDashboard(standby.dashboard, http.MethodGet, "/api/v1/workloads", "")
+	if response.Code != http.StatusOK || response.Header().Get("X-AgentaFlow-Epoch") != "2" {
+		t.Errorf("Expected the new primary to serve at epoch 2, got %d %v", response.Code, response.Header())
+	}
+
+	// The old primary fences itself and redirects to the new one
+	active.standby.elector.Tick(start.Add(11 * time.Second))
+	response = serveDashboard(active.dashboard, http.MethodGet, "/api/v1/workloads", "")
+	if active.standby.Role() != failover.RoleFenced || response.Header().Get("X-AgentaFlow-Primary") != standby.server.URL {
+		t.Errorf("Expected the old primary fenced and pointing at the new one, got %s %v", active.standby.Role(), response.Header())
+	}
+	if err := active.scheduler.CheckWAL(); err != nil {
+		t.Errorf("Expected the fenced server to stop logging cleanly, got %v", err)
+	}
+}
+
+func TestWarmStandbyStateRequiresToken(t *testing.T) {
+	store := failover.NewFileLeaseStore(filepath.Join(t.TempDir(), "lease.json"))
+	node := newStandbyServer(t, "active", store)
+	node.standby.elector.Tick(time.Now())
+
+	if response := serveDashboard(node.dashboard, http.MethodGet, "/api/v1/standby/state", ""); response.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without the control token, got %d", response.Code)
+	}
+
+	if _, err := NewWarmStandby(WarmStandbyConfig{AdvertiseURL: node.server.URL}, store, node.scheduler, nil); err == nil {
+		t.Error("Expected a missing WAL path to be rejected")
+	}
+}
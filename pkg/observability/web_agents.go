@@ -0,0 +1,86 @@
+package observability
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxAgentBatchBytes caps an upload once decompressed
+const maxAgentBatchBytes = 64 << 20
+
+// SetAgentIngest accepts metrics uploaded by node agents
+func (wd *WebDashboard) SetAgentIngest(ingest *AgentIngest) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.agentIngest = ingest
+}
+
+// handleAgentMetrics stores a node agent's upload and tells the agent how
+// far its clock is from the server's
+func (wd *WebDashboard) handleAgentMetrics(w http.ResponseWriter, r *http.Request) {
+	received := time.Now()
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	ingest := wd.agentIngest
+	wd.mu.RUnlock()
+	if ingest == nil {
+		http.Error(w, "agent ingest not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var batch AgentBatch
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxAgentBatchBytes)).Decode(&batch); err != nil {
+		http.Error(w, "expected a JSON body with node, sent_at and metrics", http.StatusBadRequest)
+		return
+	}
+	result, err := ingest.Ingest(batch, received)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleAgentClocks lists each agent's clock skew
+func (wd *WebDashboard) handleAgentClocks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	ingest := wd.agentIngest
+	wd.mu.RUnlock()
+	if ingest == nil {
+		http.Error(w, "agent ingest not configured", http.StatusServiceUnavailable)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"nodes":       ingest.Clocks(),
+		"server_time": time.Now(),
+	})
+}
+
+// handleAgentGaps lists the periods of node data missing, lost or filled
+// in late within the last ?hours (default 24)
+func (wd *WebDashboard) handleAgentGaps(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	ingest := wd.agentIngest
+	wd.mu.RUnlock()
+	if ingest == nil {
+		http.Error(w, "agent ingest not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	hours := 24
+	if h, err := strconv.Atoi(r.URL.Query().Get("hours")); err == nil && h > 0 {
+		hours = h
+	}
+	now := time.Now()
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"gaps": ingest.Gaps(now.Add(-time.Duration(hours)*time.Hour), now),
+	})
+}
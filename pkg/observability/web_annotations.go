@@ -0,0 +1,198 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
+	"github.com/gorilla/mux"
+)
+
+// SetAnnotations enables the annotations API and returns annotations with
+// metric history so charts can mark deployments and incidents
+func (wd *WebDashboard) SetAnnotations(store *AnnotationStore) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.annotations = store
+}
+
+// getAnnotations returns the annotation store, or reports 503 when none is set
+func (wd *WebDashboard) getAnnotations(w http.ResponseWriter) *AnnotationStore {
+	wd.mu.RLock()
+	store := wd.annotations
+	wd.mu.RUnlock()
+	if store == nil {
+		http.Error(w, "annotations not configured", http.StatusServiceUnavailable)
+	}
+	return store
+}
+
+// annotationsBetween returns the annotations overlapping a chart's time
+// range, or nil without a store
+func (wd *WebDashboard) annotationsBetween(start, end time.Time) []Annotation {
+	wd.mu.RLock()
+	store := wd.annotations
+	wd.mu.RUnlock()
+	if store == nil {
+		return nil
+	}
+	return store.Between(start, end, nil)
+}
+
+// handleListAnnotations lists annotations between ?start and ?end (RFC
+// 3339), or over the last ?hours (default 24), carrying every one of the
+// comma-separated ?tags
+func (wd *WebDashboard) handleListAnnotations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	store := wd.getAnnotations(w)
+	if store == nil {
+		return
+	}
+	query := r.URL.Query()
+	end := time.Now()
+	hours := 24
+	if h, err := strconv.Atoi(query.Get("hours")); err == nil && h > 0 {
+		hours = h
+	}
+	window, err := parseWindow(query, "", TimeWindow{Start: end.Add(-time.Duration(hours) * time.Hour), End: end})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	annotations := store.Between(window.Start, window.End, parseTags(query.Get("tags")))
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"annotations": annotations,
+		"count":       len(annotations),
+		"start":       window.Start,
+		"end":         window.End,
+	})
+}
+
+// handleChangeImpact compares latency, utilization and cost in the window
+// (?window, default 1h) before and after each annotation over the last
+// ?hours (default 24) carrying every one of ?tags, flagging significant
+// regressions at ?alpha (default 0.05)
+func (wd *WebDashboard) handleChangeImpact(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	store := wd.getAnnotations(w)
+	if store == nil {
+		return
+	}
+	query := r.URL.Query()
+	config := DefaultChangeImpactConfig()
+	config.Tags = parseTags(query.Get("tags"))
+	if raw := query.Get("window"); raw != "" {
+		window, err := time.ParseDuration(raw)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid window %q", raw), http.StatusBadRequest)
+			return
+		}
+		config.Window = window
+	}
+	if raw := query.Get("alpha"); raw != "" {
+		alpha, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid alpha %q", raw), http.StatusBadRequest)
+			return
+		}
+		config.Alpha = alpha
+	}
+	end := time.Now()
+	hours := 24
+	if h, err := strconv.Atoi(query.Get("hours")); err == nil && h > 0 {
+		hours = h
+	}
+
+	impacts, err := wd.monitoringService.AnalyzeChangeImpact(store.Between(end.Add(-time.Duration(hours)*time.Hour), end, config.Tags), config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	regressions := 0
+	for _, impact := range impacts {
+		if len(impact.Regressions) > 0 {
+			regressions++
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"impacts":     impacts,
+		"count":       len(impacts),
+		"regressions": regressions,
+		"window":      config.Window.String(),
+	})
+}
+
+// handleCreateAnnotation stores an annotation from the request body,
+// authored by the caller. The time defaults to now.
+func (wd *WebDashboard) handleCreateAnnotation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	store := wd.getAnnotations(w)
+	if store == nil {
+		return
+	}
+	var annotation Annotation
+	if err := json.NewDecoder(r.Body).Decode(&annotation); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if annotation.Time.IsZero() {
+		annotation.Time = time.Now()
+	}
+	annotation.Author = controlActor(r)
+
+	if err := annotation.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	created, err := store.Add(annotation)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// handleDeleteAnnotation removes the annotation named in the path; only its
+// author or an admin may
+func (wd *WebDashboard) handleDeleteAnnotation(w http.ResponseWriter, r *http.Request) {
+	store := wd.getAnnotations(w)
+	if store == nil {
+		return
+	}
+	id := mux.Vars(r)["id"]
+	annotation, exists := store.Get(id)
+	if !exists {
+		http.Error(w, fmt.Sprintf("annotation %s not found", id), http.StatusNotFound)
+		return
+	}
+	caller, _ := wd.authenticate(r)
+	if annotation.Author != "" && annotation.Author != caller.actor && !caller.hasScope(apikeys.ScopeAdmin) {
+		http.Error(w, fmt.Sprintf("annotation %s belongs to %s", id, annotation.Author), http.StatusForbidden)
+		return
+	}
+	if _, err := store.Delete(id); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseTags splits a comma-separated tag list, dropping empty tags
+func parseTags(raw string) []string {
+	var tags []string
+	for _, tag := range strings.Split(raw, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,81 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
+)
+
+func TestAnnotationEndpoints(t *testing.T) {
+	layout := DefaultDashboardLayoutConfig()
+	layout.CustomPanels = []CustomPanel{{ID: "power", Title: "Power", Visualization: VisualizationLine, Query: MetricQuery{Metric: "gpu_power_draw_watts", Aggregation: AggregationAvg, WindowSeconds: 3600}}}
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{
+		Port:          0,
+		ControlTokens: map[string]string{"s3cret": "admin"},
+		Layout:        layout,
+	})
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/annotations", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without an annotation store, got %d", response.Code)
+	}
+
+	store, _ := NewAnnotationStore("", 0)
+	dashboard.SetAnnotations(store)
+	keys, _ := apikeys.NewStore("")
+	dashboard.SetAPIKeyStore(keys)
+	_, ci, _ := keys.Create(apikeys.KeyRequest{Name: "ci", Scopes: []apikeys.Scope{apikeys.ScopeWriteAnnotations}})
+	_, reader, _ := keys.Create(apikeys.KeyRequest{Name: "grafana", Scopes: []apikeys.Scope{apikeys.ScopeReadMetrics}})
+
+	body := `{"text": "model v2 deployed", "tags": ["deploy"]}`
+	if response := serveAs(dashboard, reader, http.MethodPost, "/api/v1/annotations", body); response.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 without the write-annotations scope, got %d", response.Code)
+	}
+	response := serveAs(dashboard, ci, http.MethodPost, "/api/v1/annotations", body)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", response.Code, response.Body.String())
+	}
+	var created Annotation
+	json.Unmarshal(response.Body.Bytes(), &created)
+	if created.Author != "apikey:ci" || time.Since(created.Time) > time.Minute {
+		t.Errorf("Expected an annotation by ci stamped now, got %+v", created)
+	}
+	if response := serveAs(dashboard, ci, http.MethodPost, "/api/v1/annotations", `{"tags": ["deploy"]}`); response.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without text, got %d", response.Code)
+	}
+
+	var listed struct {
+		Annotations []Annotation `json:"annotations"`
+	}
+	json.Unmarshal(serveDashboard(dashboard, http.MethodGet, "/api/v1/annotations?tags=deploy", "").Body.Bytes(), &listed)
+	if len(listed.Annotations) != 1 || listed.Annotations[0].ID != created.ID {
+		t.Errorf("Expected the deployment listed, got %+v", listed)
+	}
+	json.Unmarshal(serveDashboard(dashboard, http.MethodGet, "/api/v1/annotations?tags=incident", "").Body.Bytes(), &listed)
+	if len(listed.Annotations) != 0 {
+		t.Errorf("Expected no incidents, got %+v", listed)
+	}
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/annotations?start=today", ""); response.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid start, got %d", response.Code)
+	}
+
+	// Panel data carries the annotations in its window
+	var result QueryResult
+	json.Unmarshal(serveDashboard(dashboard, http.MethodGet, "/api/v1/panels/power/data", "").Body.Bytes(), &result)
+	if len(result.Annotations) != 1 || result.Annotations[0].Text != "model v2 deployed" {
+		t.Errorf("Expected the panel data to include the deployment, got %+v", result)
+	}
+
+	path := "/api/v1/annotations/" + created.ID
+	if response := serveAs(dashboard, "s3cret", http.MethodDelete, "/api/v1/annotations/missing", ""); response.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown annotation, got %d", response.Code)
+	}
+	if response := serveAs(dashboard, ci, http.MethodDelete, path, ""); response.Code != http.StatusNoContent {
+		t.Errorf("Expected the author to delete the annotation, got %d", response.Code)
+	}
+	if strings.Contains(serveDashboard(dashboard, http.MethodGet, "/api/v1/panels/power/data", "").Body.String(), "annotations") {
+		t.Error("Expected no annotations after deletion")
+	}
+}
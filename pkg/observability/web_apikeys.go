@@ -0,0 +1,153 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
+)
+
+// SetAPIKeyStore lets API keys authenticate API requests and enables the key
+// management endpoints
+func (wd *WebDashboard) SetAPIKeyStore(store *apikeys.Store) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.apiKeys = store
+}
+
+// getAPIKeyStore returns the API key store, or nil when none is set
+func (wd *WebDashboard) getAPIKeyStore() *apikeys.Store {
+	wd.mu.RLock()
+	defer wd.mu.RUnlock()
+	return wd.apiKeys
+}
+
+// apiKeyActor is the name audit logs record for an API key
+func apiKeyActor(key apikeys.Key) string {
+	return "apikey:" + key.Name
+}
+
+// apiKeyStoreOr503 returns the API key store, or reports 503 when none is set
+func (wd *WebDashboard) apiKeyStoreOr503(w http.ResponseWriter) *apikeys.Store {
+	store := wd.getAPIKeyStore()
+	if store == nil {
+		http.Error(w, "API keys not configured", http.StatusServiceUnavailable)
+	}
+	return store
+}
+
+// handleListAPIKeys lists every API key, including revoked ones, without secrets
+func (wd *WebDashboard) handleListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	store := wd.apiKeyStoreOr503(w)
+	if store == nil {
+		return
+	}
+
+	keys := store.List()
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys":  keys,
+		"count": len(keys),
+	})
+}
+
+// handleCreateAPIKey creates a key from {"name", "scopes", "tenant",
+// "expires_at"} and returns its secret, which is shown only once
+func (wd *WebDashboard) handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	store := wd.apiKeyStoreOr503(w)
+	if store == nil {
+		return
+	}
+
+	var request apikeys.KeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := request.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	key, secret, err := store.Create(request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"key":    key,
+		"secret": secret,
+	})
+}
+
+// handleGetAPIKey returns one API key without its secret
+func (wd *WebDashboard) handleGetAPIKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	store := wd.apiKeyStoreOr503(w)
+	if store == nil {
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	key, exists := store.Get(id)
+	if !exists {
+		http.Error(w, "API key not found: "+id, http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(key)
+}
+
+// handleUpdateAPIKey replaces an API key's name, scopes, tenant and expiry
+func (wd *WebDashboard) handleUpdateAPIKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	store := wd.apiKeyStoreOr503(w)
+	if store == nil {
+		return
+	}
+
+	var request apikeys.KeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := request.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if _, exists := store.Get(id); !exists {
+		http.Error(w, "API key not found: "+id, http.StatusNotFound)
+		return
+	}
+	key, err := store.Update(id, request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(key)
+}
+
+// handleRevokeAPIKey revokes an API key; it stays listed for audit
+func (wd *WebDashboard) handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	store := wd.apiKeyStoreOr503(w)
+	if store == nil {
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if _, exists := store.Get(id); !exists {
+		http.Error(w, "API key not found: "+id, http.StatusNotFound)
+		return
+	}
+	key, err := store.Revoke(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(key)
+}
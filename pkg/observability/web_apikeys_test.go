@@ -0,0 +1,121 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestAPIKeyManagementEndpoints(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{
+		Port:          0,
+		ControlTokens: map[string]string{"s3cret": "alice"},
+	})
+	if response := serveAs(dashboard, "s3cret", http.MethodGet, "/api/v1/apikeys", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a key store, got %d", response.Code)
+	}
+	store, _ := apikeys.NewStore("")
+	dashboard.SetAPIKeyStore(store)
+	scheduler := gpu.NewScheduler(gpu.StrategyLeastUtilized)
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "train-1", MemoryRequired: 8192})
+	dashboard.SetScheduler(scheduler)
+
+	create := func(token, body string) (apikeys.Key, string) {
+		response := serveAs(dashboard, token, http.MethodPost, "/api/v1/apikeys", body)
+		if response.Code != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d: %s", response.Code, response.Body.String())
+		}
+		var created struct {
+			Key    apikeys.Key `json:"key"`
+			Secret string      `json:"secret"`
+		}
+		json.Unmarshal(response.Body.Bytes(), &created)
+		return created.Key, created.Secret
+	}
+	admin, adminSecret := create("s3cret", `{"name": "ops-bot", "scopes": ["admin"]}`)
+	// Admin keys manage keys like control tokens do
+	ci, ciSecret := create(adminSecret, `{"name": "ci", "scopes": ["submit-workloads"]}`)
+
+	response := serveAs(dashboard, "s3cret", http.MethodGet, "/api/v1/apikeys", "")
+	if strings.Contains(response.Body.String(), ciSecret) || strings.Contains(response.Body.String(), "hash") {
+		t.Errorf("Listing exposed secrets: %s", response.Body.String())
+	}
+	var listed struct {
+		Keys []apikeys.Key `json:"keys"`
+	}
+	json.Unmarshal(response.Body.Bytes(), &listed)
+	if len(listed.Keys) != 2 || listed.Keys[0].ID != admin.ID || listed.Keys[1].LastUsedAt != nil {
+		t.Errorf("Expected both keys with ci unused, got %+v", listed.Keys)
+	}
+
+	artifacts := `{"artifacts": [{"uri": "s3://runs/train-1/model.bin"}]}`
+	if response := serveAs(dashboard, ciSecret, http.MethodPost, "/api/v1/workloads/train-1/artifacts", artifacts); response.Code != http.StatusCreated {
+		t.Errorf("Expected the submit-workloads key to register artifacts, got %d", response.Code)
+	}
+	if response := serveAs(dashboard, ciSecret, http.MethodGet, "/api/v1/apikeys", ""); response.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for key management without the admin scope, got %d", response.Code)
+	}
+	var used apikeys.Key
+	json.Unmarshal(serveAs(dashboard, "s3cret", http.MethodGet, "/api/v1/apikeys/"+ci.ID, "").Body.Bytes(), &used)
+	if used.LastUsedAt == nil {
+		t.Errorf("Expected the ci key's last use to be tracked, got %+v", used)
+	}
+
+	checks := []struct {
+		method, path, body string
+		code               int
+	}{
+		{http.MethodPost, "/api/v1/apikeys", `{"name": "bad", "scopes": ["root"]}`, http.StatusBadRequest},
+		{http.MethodPut, "/api/v1/apikeys/" + ci.ID, `{"name": "ci-readonly", "scopes": ["read-metrics"]}`, http.StatusOK},
+		{http.MethodPut, "/api/v1/apikeys/missing", `{"name": "x", "scopes": ["admin"]}`, http.StatusNotFound},
+		{http.MethodGet, "/api/v1/apikeys/missing", "", http.StatusNotFound},
+		{http.MethodDelete, "/api/v1/apikeys/missing", "", http.StatusNotFound},
+	}
+	for _, check := range checks {
+		if response := serveAs(dashboard, "s3cret", check.method, check.path, check.body); response.Code != check.code {
+			t.Errorf("%s %s: got %d, want %d", check.method, check.path, response.Code, check.code)
+		}
+	}
+	if response := serveAs(dashboard, ciSecret, http.MethodPost, "/api/v1/workloads/train-1/artifacts", artifacts); response.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 after narrowing the key to read-metrics, got %d", response.Code)
+	}
+
+	if response := serveAs(dashboard, adminSecret, http.MethodDelete, "/api/v1/apikeys/"+admin.ID, ""); response.Code != http.StatusOK {
+		t.Fatalf("Expected the admin key to revoke itself, got %d", response.Code)
+	}
+	if response := serveAs(dashboard, adminSecret, http.MethodGet, "/api/v1/apikeys", ""); response.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a revoked key, got %d", response.Code)
+	}
+}
+
+func TestAPIKeysCarryTheirTenant(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{
+		Port:    0,
+		Tenancy: TenancyConfig{Tenants: map[string]string{"ana": "vision"}},
+	})
+	store, _ := apikeys.NewStore("")
+	dashboard.SetAPIKeyStore(store)
+	scheduler := gpu.NewScheduler(gpu.StrategyLeastUtilized)
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "detect", Tenant: "vision", MemoryRequired: 8192})
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "translate", Tenant: "nlp", MemoryRequired: 8192})
+	dashboard.SetScheduler(scheduler)
+
+	_, nlpReader, _ := store.Create(apikeys.KeyRequest{Name: "nlp-grafana", Scopes: []apikeys.Scope{apikeys.ScopeReadMetrics}, Tenant: "nlp"})
+	_, nlpSubmitter, _ := store.Create(apikeys.KeyRequest{Name: "nlp-ci", Scopes: []apikeys.Scope{apikeys.ScopeSubmitWorkloads}, Tenant: "nlp"})
+
+	response := serveAs(dashboard, nlpReader, http.MethodGet, "/api/v1/workloads", "")
+	var listed struct {
+		Workloads []gpu.WorkloadInfo `json:"workloads"`
+	}
+	json.Unmarshal(response.Body.Bytes(), &listed)
+	if len(listed.Workloads) != 1 || listed.Workloads[0].ID != "translate" {
+		t.Errorf("Expected only nlp workloads, got %d: %s", response.Code, response.Body.String())
+	}
+	if response := serveAs(dashboard, nlpSubmitter, http.MethodGet, "/api/v1/workloads", ""); response.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 reading without the read-metrics scope, got %d", response.Code)
+	}
+}
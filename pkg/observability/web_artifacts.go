@@ -0,0 +1,80 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// handleWorkloadArtifacts lists the outputs a workload registered
+func (wd *WebDashboard) handleWorkloadArtifacts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	scheduler := wd.scheduler
+	wd.mu.RUnlock()
+	if scheduler == nil {
+		http.Error(w, "scheduler not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	artifacts, exists := scheduler.GetArtifacts(id)
+	if tenant, _ := scheduler.WorkloadTenant(id); !exists || !inTenant(r, tenant) {
+		http.Error(w, "workload not found: "+id, http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"workload_id": id,
+		"artifacts":   artifacts,
+		"count":       len(artifacts),
+	})
+}
+
+// handleRegisterArtifacts records outputs of a workload, e.g. uploaded by its
+// job after training finishes
+func (wd *WebDashboard) handleRegisterArtifacts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	scheduler := wd.scheduler
+	wd.mu.RUnlock()
+	if scheduler == nil {
+		http.Error(w, "scheduler not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var request struct {
+		Artifacts []gpu.Artifact `json:"artifacts"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || len(request.Artifacts) == 0 {
+		http.Error(w, "expected a JSON body with artifacts", http.StatusBadRequest)
+		return
+	}
+	for _, artifact := range request.Artifacts {
+		if err := artifact.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	id := mux.Vars(r)["id"]
+	if tenant, exists := scheduler.WorkloadTenant(id); exists && !inTenant(r, tenant) {
+		http.Error(w, "workload not found: "+id, http.StatusNotFound)
+		return
+	}
+	if err := scheduler.RegisterArtifacts(id, request.Artifacts); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	artifacts, _ := scheduler.GetArtifacts(id)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"workload_id": id,
+		"artifacts":   artifacts,
+		"count":       len(artifacts),
+	})
+}
@@ -0,0 +1,65 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestWorkloadArtifactsAPI(t *testing.T) {
+	ms := NewMonitoringService(100)
+	dashboard := NewWebDashboard(ms, nil, nil, WebDashboardConfig{
+		Port:          0,
+		ControlTokens: map[string]string{"s3cret": "ci"},
+	})
+	register := func(id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/workloads/"+id+"/artifacts", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		recorder := httptest.NewRecorder()
+		dashboard.server.Handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/workloads/train-1/artifacts", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a scheduler, got %d", response.Code)
+	}
+
+	scheduler := gpu.NewScheduler(gpu.StrategyLeastUtilized)
+	scheduler.RegisterGPU(&gpu.GPU{ID: "gpu-0", MemoryTotal: 16384, Available: true})
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "train-1", MemoryRequired: 8192})
+	scheduler.Schedule()
+	scheduler.CompleteWorkload("train-1")
+	dashboard.SetScheduler(scheduler)
+
+	body := `{"artifacts": [{"name": "model", "uri": "s3://runs/train-1/model.safetensors", "size_bytes": 4096, "model_id": "llama-ft-v3"}]}`
+	if response := register("train-1", body); response.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", response.Code, response.Body.String())
+	}
+	if response := register("train-1", `{"artifacts": [{"uri": ""}]}`); response.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an artifact without a URI, got %d", response.Code)
+	}
+	if response := register("missing", body); response.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown workload, got %d", response.Code)
+	}
+	if response := serveDashboard(dashboard, http.MethodPost, "/api/v1/workloads/train-1/artifacts", body); response.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a control token, got %d", response.Code)
+	}
+
+	response := serveDashboard(dashboard, http.MethodGet, "/api/v1/workloads/train-1/artifacts", "")
+	var listed struct {
+		Artifacts []gpu.Artifact `json:"artifacts"`
+		Count     int            `json:"count"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if listed.Count != 1 || listed.Artifacts[0].ModelID != "llama-ft-v3" || listed.Artifacts[0].SizeBytes != 4096 {
+		t.Errorf("Expected the registered model artifact, got %+v", listed)
+	}
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/workloads/missing/artifacts", ""); response.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown workload, got %d", response.Code)
+	}
+}
@@ -0,0 +1,56 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// SetHardwareBaselines judges GPUs against their model's expected
+// throughput in the efficiency and baselines endpoints
+func (wd *WebDashboard) SetHardwareBaselines(baselines *HardwareBaselines) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.hardwareBaselines = baselines
+}
+
+// compareHardware judges the GPUs the collector reports, or returns nil
+// without baselines
+func (wd *WebDashboard) compareHardware() []GPUPerformance {
+	wd.mu.RLock()
+	baselines := wd.hardwareBaselines
+	wd.mu.RUnlock()
+
+	if baselines == nil || wd.metricsCollector == nil {
+		return nil
+	}
+	return baselines.Compare(wd.metricsCollector.GetLatestMetrics())
+}
+
+// handleHardwareBaselines lists the baselines and each GPU's throughput as
+// a share of its model's
+func (wd *WebDashboard) handleHardwareBaselines(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	baselines := wd.hardwareBaselines
+	wd.mu.RUnlock()
+	if baselines == nil || wd.metricsCollector == nil {
+		http.Error(w, "hardware baselines not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	gpus := wd.compareHardware()
+	underperforming := 0
+	for _, perf := range gpus {
+		if perf.Underperforming {
+			underperforming++
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"baselines":       baselines.Baselines(),
+		"gpus":            gpus,
+		"underperforming": underperforming,
+		"timestamp":       time.Now(),
+	})
+}
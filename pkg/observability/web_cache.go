@@ -0,0 +1,259 @@
+package observability
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResponseCacheConfig limits how often expensive API endpoints are computed.
+// Responses are cached briefly and tagged with an ETag, and at most
+// MaxConcurrent are computed at once so aggressive pollers cannot starve
+// metric collection.
+type ResponseCacheConfig struct {
+	// How long a response is served without recomputing
+	TTL time.Duration `yaml:"ttl" json:"ttl"`
+
+	// Responses computed at once across endpoints; further requests get a
+	// stale response, or 503 when there is none. Zero means no limit.
+	MaxConcurrent int `yaml:"max_concurrent" json:"max_concurrent"`
+
+	// Cached responses kept; expired ones are evicted first
+	MaxEntries int `yaml:"max_entries" json:"max_entries"`
+}
+
+// DefaultResponseCacheConfig returns the cache used when none is configured
+func DefaultResponseCacheConfig() ResponseCacheConfig {
+	return ResponseCacheConfig{
+		TTL:           2 * time.Second,
+		MaxConcurrent: 8,
+		MaxEntries:    1000,
+	}
+}
+
+// cachedResponse is a computed response and when it goes stale
+type cachedResponse struct {
+	status  int
+	header  http.Header
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+// responseCache caches GET responses per URL and tenant scope, coalescing
+// concurrent misses for the same key into one computation
+type responseCache struct {
+	config   ResponseCacheConfig
+	entries  map[string]*cachedResponse
+	inflight map[string]chan struct{}
+	slots    chan struct{}
+	now      func() time.Time
+	mu       sync.Mutex
+}
+
+// newResponseCache creates a cache for a configuration
+func newResponseCache(config ResponseCacheConfig) *responseCache {
+	cache := &responseCache{
+		config:   config,
+		entries:  make(map[string]*cachedResponse),
+		inflight: make(map[string]chan struct{}),
+		now:      time.Now,
+	}
+	if config.MaxConcurrent > 0 {
+		cache.slots = make(chan struct{}, config.MaxConcurrent)
+	}
+	return cache
+}
+
+// bufferedResponse records a handler's response so it can be cached
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(data []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(data)
+}
+
+// cacheKey identifies a response; responses differ by tenant scope and
+// negotiated encoding
+func cacheKey(r *http.Request) string {
+	key := r.URL.Path + "?" + r.URL.RawQuery
+	if encoding, err := negotiateResponseEncoding(r); err == nil && encoding != EncodingJSON {
+		key += "|encoding=" + encoding
+	}
+	if scope, scoped := r.Context().Value(tenantScopeKey{}).(tenantScope); scoped {
+		key += fmt.Sprintf("|admin=%t|tenant=%s", scope.admin, scope.tenant)
+	}
+	return key
+}
+
+// cached serves a GET handler through the dashboard's response cache
+func (wd *WebDashboard) cached(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		wd.mu.RLock()
+		cache := wd.responseCache
+		wd.mu.RUnlock()
+		if cache == nil || r.Method != http.MethodGet {
+			handler(w, r)
+			return
+		}
+		cache.serve(w, r, handler)
+	}
+}
+
+// serve answers from the cache, computing the response when it is missing
+// or expired and a computation slot is free
+func (c *responseCache) serve(w http.ResponseWriter, r *http.Request, handler http.HandlerFunc) {
+	key := cacheKey(r)
+	for {
+		c.mu.Lock()
+		entry := c.entries[key]
+		if entry != nil && c.now().Before(entry.expires) {
+			c.mu.Unlock()
+			c.write(w, r, entry, "HIT")
+			return
+		}
+		if wait, computing := c.inflight[key]; computing {
+			c.mu.Unlock()
+			select {
+			case <-wait:
+				continue
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if !c.acquire() {
+			c.mu.Unlock()
+			if entry != nil {
+				c.write(w, r, entry, "STALE")
+				return
+			}
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "dashboard is busy, retry shortly", http.StatusServiceUnavailable)
+			return
+		}
+		done := make(chan struct{})
+		c.inflight[key] = done
+		c.mu.Unlock()
+
+		entry = c.compute(r, handler)
+
+		c.mu.Lock()
+		delete(c.inflight, key)
+		if entry.status == http.StatusOK {
+			c.store(key, entry)
+		}
+		c.mu.Unlock()
+		close(done)
+		c.release()
+
+		c.write(w, r, entry, "MISS")
+		return
+	}
+}
+
+// acquire takes a computation slot without waiting
+func (c *responseCache) acquire() bool {
+	if c.slots == nil {
+		return true
+	}
+	select {
+	case c.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release returns a computation slot
+func (c *responseCache) release() {
+	if c.slots != nil {
+		<-c.slots
+	}
+}
+
+// compute runs the handler and records its response
+func (c *responseCache) compute(r *http.Request, handler http.HandlerFunc) *cachedResponse {
+	recorder := &bufferedResponse{header: make(http.Header)}
+	handler(recorder, r)
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+
+	sum := sha256.Sum256(recorder.body.Bytes())
+	return &cachedResponse{
+		status:  recorder.status,
+		header:  recorder.header,
+		body:    recorder.body.Bytes(),
+		etag:    `"` + hex.EncodeToString(sum[:12]) + `"`,
+		expires: c.now().Add(c.config.TTL),
+	}
+}
+
+// store keeps an entry, evicting expired entries and then arbitrary ones
+// once MaxEntries is reached; the caller holds the lock
+func (c *responseCache) store(key string, entry *cachedResponse) {
+	if c.config.MaxEntries > 0 && len(c.entries) >= c.config.MaxEntries {
+		now := c.now()
+		for existing, cached := range c.entries {
+			if !now.Before(cached.expires) {
+				delete(c.entries, existing)
+			}
+		}
+		for existing := range c.entries {
+			if len(c.entries) < c.config.MaxEntries {
+				break
+			}
+			delete(c.entries, existing)
+		}
+	}
+	c.entries[key] = entry
+}
+
+// write sends a cached response, or 304 when the client has it already
+func (c *responseCache) write(w http.ResponseWriter, r *http.Request, entry *cachedResponse, state string) {
+	for name, values := range entry.header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("X-Cache", state)
+	if entry.status == http.StatusOK {
+		w.Header().Set("ETag", entry.etag)
+		w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(c.config.TTL.Seconds())))
+		if etagMatches(r.Header.Get("If-None-Match"), entry.etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.WriteHeader(entry.status)
+	w.Write(entry.body)
+}
+
+// etagMatches reports whether an If-None-Match header lists an ETag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
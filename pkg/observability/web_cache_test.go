@@ -0,0 +1,125 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResponseCacheServesETagsAndExpires(t *testing.T) {
+	cache := newResponseCache(ResponseCacheConfig{TTL: 2 * time.Second})
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	var calls int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"call":%d}`, atomic.AddInt32(&calls, 1))
+	}
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest("GET", "/api/v1/metrics", nil)
+		if ifNoneMatch != "" {
+			request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		response := httptest.NewRecorder()
+		cache.serve(response, request, handler)
+		return response
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || first.Header().Get("X-Cache") != "MISS" {
+		t.Fatalf("Expected a computed response with an ETag, got %d %v", first.Code, first.Header())
+	}
+	if second := get(""); second.Body.String() != `{"call":1}` || second.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("Expected the cached response, got %s %v", second.Body.String(), second.Header())
+	}
+	if notModified := get(etag); notModified.Code != http.StatusNotModified || notModified.Body.Len() != 0 {
+		t.Errorf("Expected 304 for a matching If-None-Match, got %d", notModified.Code)
+	}
+	if content := get(etag).Header().Get("Content-Type"); content != "application/json" {
+		t.Errorf("Expected the handler's headers kept, got %q", content)
+	}
+
+	now = now.Add(3 * time.Second)
+	if refreshed := get(etag); refreshed.Code != http.StatusOK || refreshed.Body.String() != `{"call":2}` {
+		t.Errorf("Expected the expired response recomputed, got %d %s", refreshed.Code, refreshed.Body.String())
+	}
+}
+
+func TestResponseCacheCoalescesAndShedsLoad(t *testing.T) {
+	cache := newResponseCache(ResponseCacheConfig{TTL: time.Minute, MaxConcurrent: 1})
+	release := make(chan struct{})
+	started := make(chan struct{}, 10)
+	var calls int32
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		started <- struct{}{}
+		<-release
+		w.Write([]byte("slow"))
+	}
+
+	// Concurrent requests for the same URL share one computation
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 3)
+	for i := range responses {
+		responses[i] = httptest.NewRecorder()
+		wg.Add(1)
+		go func(response *httptest.ResponseRecorder) {
+			defer wg.Done()
+			cache.serve(response, httptest.NewRequest("GET", "/api/v1/performance", nil), slow)
+		}(responses[i])
+	}
+	<-started
+
+	// The only slot is taken, so another URL is shed
+	busy := httptest.NewRecorder()
+	cache.serve(busy, httptest.NewRequest("GET", "/api/v1/gpus/heatmap", nil), slow)
+	if busy.Code != http.StatusServiceUnavailable || busy.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 503 with Retry-After while busy, got %d", busy.Code)
+	}
+
+	close(release)
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("Expected one computation for concurrent requests, got %d", calls)
+	}
+	for _, response := range responses {
+		if response.Body.String() != "slow" {
+			t.Errorf("Expected every request answered, got %q", response.Body.String())
+		}
+	}
+}
+
+func TestResponseCacheKeysByTenantAndSkipsErrors(t *testing.T) {
+	cache := newResponseCache(DefaultResponseCacheConfig())
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		scope, _ := r.Context().Value(tenantScopeKey{}).(tenantScope)
+		if scope.tenant == "" {
+			http.Error(w, "no tenant", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(scope.tenant))
+	}
+	get := func(tenant string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest("GET", "/api/v1/costs/showback", nil)
+		request = request.WithContext(context.WithValue(request.Context(), tenantScopeKey{}, tenantScope{tenant: tenant}))
+		response := httptest.NewRecorder()
+		cache.serve(response, request, handler)
+		return response
+	}
+
+	get("ml")
+	if other := get("vision"); other.Body.String() != "vision" {
+		t.Errorf("Expected responses kept apart per tenant, got %q", other.Body.String())
+	}
+	get("")
+	if forbidden := get(""); forbidden.Header().Get("X-Cache") != "MISS" || forbidden.Code != http.StatusForbidden {
+		t.Errorf("Expected error responses not cached, got %d %v", forbidden.Code, forbidden.Header())
+	}
+}
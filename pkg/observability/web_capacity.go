@@ -0,0 +1,35 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// SetCapacitySignals adds the capacity signals' recent events to /capacity
+func (wd *WebDashboard) SetCapacitySignals(signals *CapacitySignals) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.capacitySignals = signals
+}
+
+// handleCapacity reports each pool's demand against its GPUs, for
+// provisioners that poll rather than subscribe to capacity signals
+func (wd *WebDashboard) handleCapacity(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	scheduler, signals := wd.scheduler, wd.capacitySignals
+	wd.mu.RUnlock()
+	if scheduler == nil {
+		http.Error(w, "scheduler not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	response := map[string]interface{}{
+		"pools": scheduler.GetCapacity(),
+	}
+	if signals != nil {
+		response["signals"] = signals.Recent()
+	}
+	json.NewEncoder(w).Encode(response)
+}
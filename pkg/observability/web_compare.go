@@ -0,0 +1,80 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// parseWindow reads ?<prefix>_start and ?<prefix>_end, or ?start and ?end
+// without a prefix, as RFC 3339 times, keeping the fallback for any that
+// are absent
+func parseWindow(query url.Values, prefix string, fallback TimeWindow) (TimeWindow, error) {
+	if prefix != "" {
+		prefix += "_"
+	}
+	window := fallback
+	for _, bound := range []struct {
+		name  string
+		field *time.Time
+	}{{prefix + "start", &window.Start}, {prefix + "end", &window.End}} {
+		value := query.Get(bound.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return window, fmt.Errorf("invalid %s, expected RFC 3339: %w", bound.name, err)
+		}
+		*bound.field = parsed
+	}
+	return window, nil
+}
+
+// handleCompareWindows compares utilization, cost and alerts between the
+// ?baseline_start/?baseline_end and ?current_start/?current_end windows,
+// defaulting to last week against this week. ?threshold overrides the
+// percent change counted as significant.
+func (wd *WebDashboard) handleCompareWindows(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if wd.monitoringService == nil {
+		http.Error(w, "monitoring service not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+	_, thisWeek := WeekOverWeek(time.Now())
+	current, err := parseWindow(query, "current", thisWeek)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// The baseline defaults to the period of the same length just before
+	previous := TimeWindow{Start: current.Start.Add(-current.End.Sub(current.Start)), End: current.Start}
+	baseline, err := parseWindow(query, "baseline", previous)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	config := DefaultComparisonConfig()
+	if value := query.Get("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "invalid threshold, expected a positive percentage", http.StatusBadRequest)
+			return
+		}
+		config.SignificantPercent = parsed
+	}
+
+	comparison, err := wd.monitoringService.CompareWindows(baseline, current, config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(comparison)
+}
@@ -2,6 +2,7 @@ package observability
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,7 +12,10 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/airgap"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
 	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving"
 )
 
 // WebDashboard represents the web-based monitoring dashboard
@@ -25,6 +29,10 @@ type WebDashboard struct {
 	// WebSocket management
 	wsConnections  map[*websocket.Conn]bool
 	wsWriteMutexes map[*websocket.Conn]*sync.Mutex
+	wsUsers        map[*websocket.Conn]string // Operators that authenticated their connection
+	wsEncodings    map[*websocket.Conn]string // Connections asking for binary frames
+	wsDeltaClients map[*websocket.Conn]bool   // Connections taking delta updates, true until their first keyframe
+	deltaTracker   *metricsDeltaTracker
 	wsUpgrader     websocket.Upgrader
 	wsMutex        sync.RWMutex
 
@@ -38,6 +46,48 @@ type WebDashboard struct {
 	enableRealTimeUpdates bool
 	theme                 string
 	systemHealth          SystemHealthStatus
+	alertGrouper          *AlertGrouper            // Optional, groups alerts into incidents
+	timelineBuilder       *IncidentTimelineBuilder // Optional, adds scheduler decisions to timelines
+	scheduler             *gpu.Scheduler           // Optional, lists queued and running workloads
+	powerManager          *gpu.PowerManager        // Optional, serves power and clock controls
+	orphanDetector        *gpu.OrphanDetector      // Optional, lists orphaned GPU processes
+	orphansFromConfig     bool                     // The detector judges ownership with the dashboard's scheduler
+	remediator            *gpu.Remediator          // Runs remediations operators request, such as orphan cleanup
+	recurrence            *gpu.RecurrenceManager   // Optional, lists recurring workloads and their runs
+	capacitySignals       *CapacitySignals         // Optional, adds recent capacity signals to /capacity
+	warmStandby           *WarmStandby             // Optional, serves only while primary of a standby pair
+	agentIngest           *AgentIngest             // Optional, accepts metrics uploaded by node agents
+	airGap                *airgap.Guard            // Set in air-gapped mode, refuses outbound connections
+	hardwareBaselines     *HardwareBaselines       // Optional, judges GPUs against their model's expected throughput
+	controlTokens         map[string]string
+	apiKeys               *apikeys.Store // Optional, authenticates API keys and serves key management
+	tenancy               TenancyConfig
+	oidc                  *oidcProvider                // Optional, requires a login or API credential for every request
+	oidcErr               error                        // Invalid OIDC configuration; requests fail closed
+	notificationPrefs     *NotificationPreferenceStore // Optional, filters browser notifications per user
+	savedViews            *SavedViewStore              // Optional, named filter sets applied with ?view
+	annotations           *AnnotationStore             // Optional, chart markers returned with metric history
+	pipelineMonitor       *PipelineMonitor             // Optional, reports monitoring pipeline failures
+	costConfig            GPUCostConfiguration         // Prices the cost action plan
+	gpuIntegration        *GPUMetricsIntegration       // Optional, serves alert rules through the resources API
+	servingManager        *serving.ServingManager      // Optional, serves model registrations through the resources API
+	budgets               map[string]Budget            // Team budgets managed through the resources API
+	resourcesMu           sync.Mutex                   // Serializes changes made through the resources API
+	costOptimizer         CostOptimizerConfig
+	showback              ShowbackConfig
+	layout                DashboardLayoutConfig
+	role                  string          // Replica role, primary unless configured
+	bus                   BroadcastBus    // Optional, carries broadcasts between replicas
+	relayedMetrics        json.RawMessage // Latest metrics update received from the bus
+	responseCache         *responseCache  // Caches and rate limits expensive endpoints
+	httpConfig            HTTPServerConfig
+
+	// Component health checks
+	healthConfig       HealthConfig
+	healthChecks       map[string]HealthCheck
+	broadcastHeartbeat *Heartbeat
+	createdAt          time.Time
+	healthMu           sync.RWMutex
 
 	// Lifecycle management
 	ctx    context.Context
@@ -46,11 +96,54 @@ type WebDashboard struct {
 
 // WebDashboardConfig holds configuration for the web dashboard
 type WebDashboardConfig struct {
-	Port                  int
-	EnableRealTimeUpdates bool
-	Theme                 string // "light" or "dark"
-	Title                 string
-	RefreshInterval       int
+	Port                  int          `yaml:"port" json:"port"`
+	EnableRealTimeUpdates bool         `yaml:"enable_real_time_updates" json:"enable_real_time_updates"`
+	Theme                 string       `yaml:"theme" json:"theme"` // "light" or "dark"
+	Title                 string       `yaml:"title" json:"title"`
+	RefreshInterval       int          `yaml:"refresh_interval" json:"refresh_interval"`
+	Health                HealthConfig `yaml:"health" json:"health"` // Zero value uses DefaultHealthConfig
+
+	// Zero HorizonHours uses DefaultCostOptimizerConfig
+	CostOptimizer CostOptimizerConfig `yaml:"cost_optimizer" json:"cost_optimizer"`
+
+	// Internal marketplace price cards; an empty TeamLabel uses DefaultShowbackConfig
+	Showback ShowbackConfig `yaml:"showback" json:"showback"`
+
+	// Panels, thresholds and refresh rate of the dashboard page, per pool;
+	// no default panels uses DefaultDashboardLayoutConfig
+	Layout DashboardLayoutConfig `yaml:"layout" json:"layout"`
+
+	// Bearer tokens allowed to call control endpoints such as power limits,
+	// mapped to the operator name recorded in audit logs. Control endpoints
+	// are disabled when empty.
+	ControlTokens map[string]string `yaml:"control_tokens" json:"-" secret:"keys"`
+
+	// Scopes API requests to the caller's tenant; off without tenants
+	Tenancy TenancyConfig `yaml:"tenancy" json:"tenancy"`
+
+	// Single sign-on for the dashboard page and API; off without an issuer
+	OIDC OIDCConfig `yaml:"oidc" json:"oidc"`
+
+	// WebSocket clients connecting with ?delta=1 get a full keyframe every
+	// this many metric updates and changed GPU fields in between; zero uses 30
+	WebSocketKeyframeInterval int `yaml:"websocket_keyframe_interval" json:"websocket_keyframe_interval"`
+
+	// Response compression, TLS and HTTP/2
+	HTTP HTTPServerConfig `yaml:"http" json:"http"`
+
+	// Caching and concurrency limits of expensive API endpoints; the zero
+	// value uses DefaultResponseCacheConfig
+	ResponseCache ResponseCacheConfig `yaml:"response_cache" json:"response_cache"`
+
+	// Runs the API as replicas sharing WebSocket broadcasts; without it a
+	// single primary delivers them in process
+	Replication ReplicationConfig `yaml:"replication" json:"replication"`
+
+	// Edge and air-gapped sites; off by default
+	AirGap AirGapConfig `yaml:"air_gap" json:"air_gap"`
+
+	// Processes holding GPU memory without an owning workload; off by default
+	Orphans OrphanDetectionConfig `yaml:"orphans" json:"orphans"`
 }
 
 // SystemHealthStatus represents overall system health
@@ -82,6 +175,39 @@ type AlertInfo struct {
 func NewWebDashboard(monitoringService *MonitoringService, metricsCollector gpu.MetricsCollectorInterface, prometheusExporter *PrometheusExporter, config WebDashboardConfig) *WebDashboard {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	healthConfig := config.Health
+	if healthConfig == (HealthConfig{}) {
+		healthConfig = DefaultHealthConfig()
+	}
+
+	costOptimizer := config.CostOptimizer
+	if costOptimizer.HorizonHours == 0 {
+		costOptimizer = DefaultCostOptimizerConfig()
+	}
+
+	showback := config.Showback
+	if showback.TeamLabel == "" {
+		showback = DefaultShowbackConfig()
+	}
+
+	layout := config.Layout
+	if len(layout.Default.Panels) == 0 {
+		layout = DefaultDashboardLayoutConfig()
+		if config.RefreshInterval > 0 {
+			layout.Default.RefreshInterval = config.RefreshInterval
+		}
+	}
+
+	responseCacheConfig := config.ResponseCache
+	if responseCacheConfig == (ResponseCacheConfig{}) {
+		responseCacheConfig = DefaultResponseCacheConfig()
+	}
+
+	role := config.Replication.Role
+	if role == "" {
+		role = ReplicaRolePrimary
+	}
+
 	wd := &WebDashboard{
 		monitoringService:  monitoringService,
 		metricsCollector:   metricsCollector,
@@ -89,7 +215,11 @@ func NewWebDashboard(monitoringService *MonitoringService, metricsCollector gpu.
 		port:               config.Port,
 		wsConnections:      make(map[*websocket.Conn]bool),
 		wsWriteMutexes:     make(map[*websocket.Conn]*sync.Mutex),
+		wsUsers:            make(map[*websocket.Conn]string),
+		wsDeltaClients:     make(map[*websocket.Conn]bool),
+		deltaTracker:       newMetricsDeltaTracker(config.WebSocketKeyframeInterval),
 		wsUpgrader: websocket.Upgrader{
+			Subprotocols: []string{wsProtocol},
 			CheckOrigin: func(r *http.Request) bool {
 				origin := r.Header.Get("Origin")
 
@@ -130,21 +260,54 @@ func NewWebDashboard(monitoringService *MonitoringService, metricsCollector gpu.
 		lastMetrics:           make(map[string]gpu.GPUMetrics),
 		enableRealTimeUpdates: config.EnableRealTimeUpdates,
 		theme:                 config.Theme,
+		controlTokens:         config.ControlTokens,
+		tenancy:               config.Tenancy,
+		costConfig:            DefaultGPUCostConfiguration(),
+		costOptimizer:         costOptimizer,
+		showback:              showback,
+		layout:                layout,
+		role:                  role,
+		bus:                   newBroadcastBus(config.Replication.Broadcast),
+		responseCache:         newResponseCache(responseCacheConfig),
+		httpConfig:            config.HTTP,
 		systemHealth:          SystemHealthStatus{Status: "healthy", Score: 100},
+		healthConfig:          healthConfig,
+		healthChecks:          make(map[string]HealthCheck),
+		broadcastHeartbeat:    NewHeartbeat(healthConfig.BroadcastMaxAge),
+		createdAt:             time.Now(),
 		ctx:                   ctx,
 		cancel:                cancel,
 	}
 
+	if config.OIDC.Enabled() {
+		wd.oidc, wd.oidcErr = newOIDCProvider(config.OIDC)
+		if wd.oidcErr != nil {
+			log.Printf("OIDC login misconfigured, rejecting requests: %v", wd.oidcErr)
+		}
+	}
+
+	if config.AirGap.Enabled {
+		wd.enableAirGap(config.AirGap)
+	}
+
+	wd.remediator = gpu.NewRemediator()
+	if monitoringService != nil {
+		wd.remediator.OnRemediation(monitoringService.RecordRemediation)
+	}
+	if config.Orphans.Enabled {
+		wd.enableOrphanDetection(config.Orphans.OrphanConfig)
+	}
+
 	// Set up HTTP server
 	router := mux.NewRouter()
 	wd.setupRoutes(router)
 
-	wd.server = &http.Server{
+	wd.server = newHTTPServer(&http.Server{
 		Addr:         fmt.Sprintf(":%d", config.Port),
 		Handler:      router,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
-	}
+	}, config.HTTP)
 
 	return wd
 }
@@ -154,14 +317,26 @@ func (wd *WebDashboard) Start() error {
 	log.Printf("Starting web dashboard on port %d", wd.port)
 	log.Printf("Dashboard will be accessible at: http://localhost:%d", wd.port)
 
-	// Start background metrics collection
-	go wd.startMetricsCollection()
+	// Relay broadcasts published by any replica to this one's clients
+	if bus := wd.broadcastBus(); bus != nil {
+		go wd.relayBroadcasts(bus)
+	}
+
+	if wd.oidc != nil {
+		go wd.oidc.runPruning(wd.ctx, oidcPruneInterval)
+	}
+
+	// Replicas serve the API only; the primary collects and broadcasts
+	if !wd.isReplica() {
+		// Start background metrics collection
+		go wd.startMetricsCollection()
 
-	// Start WebSocket broadcast routine
-	go wd.startWebSocketBroadcast()
+		// Start WebSocket broadcast routine
+		go wd.startWebSocketBroadcast()
+	}
 
 	log.Printf("HTTP server starting on :%d...", wd.port)
-	err := wd.server.ListenAndServe()
+	err := listenAndServe(wd.server, wd.httpConfig)
 	if err != nil && err != http.ErrServerClosed {
 		log.Printf("Error starting web dashboard server: %v", err)
 		return err
@@ -173,6 +348,12 @@ func (wd *WebDashboard) Start() error {
 func (wd *WebDashboard) Stop() error {
 	// Cancel the context to stop background routines
 	wd.cancel()
+	if bus := wd.broadcastBus(); bus != nil {
+		bus.Close()
+	}
+	if wd.airGap != nil {
+		wd.airGap.Uninstall()
+	}
 
 	// Create a timeout context for graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -191,6 +372,7 @@ func (wd *WebDashboard) startMetricsCollection() {
 		select {
 		case <-ticker.C:
 			wd.updateMetrics()
+			wd.checkPipeline()
 		case <-wd.ctx.Done():
 			return
 		}
@@ -247,8 +429,11 @@ func (wd *WebDashboard) startWebSocketBroadcast() {
 	for {
 		select {
 		case <-ticker.C:
-			// Only broadcast if there are connections to avoid race conditions
-			if wd.GetActiveConnections() > 0 {
+			wd.broadcastHeartbeat.Beat()
+
+			// Only broadcast if there are connections to avoid race conditions;
+			// with a bus, other replicas may have connections
+			if wd.GetActiveConnections() > 0 || wd.broadcastBus() != nil {
 				wd.broadcastMetrics()
 			}
 		case <-wd.ctx.Done():
@@ -334,6 +519,97 @@ func (wd *WebDashboard) calculateSystemHealth() SystemHealthStatus {
 	}
 }
 
+// SetAlertGrouper shows the grouper's incidents on the dashboard and pushes
+// incident changes to WebSocket clients
+func (wd *WebDashboard) SetAlertGrouper(grouper *AlertGrouper) {
+	if grouper != nil {
+		grouper.Subscribe(wd.BroadcastIncident)
+	}
+
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.alertGrouper = grouper
+}
+
+// SetIncidentTimelineBuilder overrides the builder used for incident
+// timelines, e.g. to include scheduler decisions
+func (wd *WebDashboard) SetIncidentTimelineBuilder(builder *IncidentTimelineBuilder) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.timelineBuilder = builder
+}
+
+// SetScheduler exposes the scheduler's workloads through the workload list API
+func (wd *WebDashboard) SetScheduler(scheduler *gpu.Scheduler) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.scheduler = scheduler
+	if wd.orphansFromConfig && scheduler != nil {
+		wd.orphanDetector.SetResolver(gpu.SchedulerWorkloadResolver(scheduler))
+	}
+}
+
+// SetCostConfiguration sets the pricing used by the cost action plan,
+// e.g. GPUMetricsIntegration.GetCostConfiguration()
+func (wd *WebDashboard) SetCostConfiguration(config GPUCostConfiguration) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.costConfig = config
+}
+
+// SetDashboardLayout replaces the dashboard layout, e.g. after reloading it from version control
+func (wd *WebDashboard) SetDashboardLayout(layout DashboardLayoutConfig) error {
+	if err := layout.Validate(); err != nil {
+		return fmt.Errorf("invalid dashboard layout: %w", err)
+	}
+
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.layout = layout
+	return nil
+}
+
+// SetNotificationPreferences enables per-user notification preferences.
+// Alerts pushed to WebSocket clients authenticated with a control token
+// only raise browser notifications for the severities their user chose.
+func (wd *WebDashboard) SetNotificationPreferences(store *NotificationPreferenceStore) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.notificationPrefs = store
+}
+
+// SetPipelineMonitor shows a banner on the dashboard while the monitoring
+// pipeline is degraded. The dashboard's collector is tracked as the
+// nvidia-smi source when it counts collection errors.
+func (wd *WebDashboard) SetPipelineMonitor(monitor *PipelineMonitor) {
+	if collector, ok := wd.metricsCollector.(interface {
+		GetCollectionErrors() map[string]int64
+	}); ok && monitor != nil {
+		monitor.AddSource(PipelineSourceNvidiaSMI, collector.GetCollectionErrors)
+	}
+
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.pipelineMonitor = monitor
+}
+
+// checkPipeline refreshes the pipeline monitor's status and metrics
+func (wd *WebDashboard) checkPipeline() {
+	wd.mu.RLock()
+	monitor := wd.pipelineMonitor
+	wd.mu.RUnlock()
+	if monitor != nil {
+		monitor.Check(time.Now())
+	}
+}
+
+// SetPowerManager enables the power limit and application clock control endpoints
+func (wd *WebDashboard) SetPowerManager(powerManager *gpu.PowerManager) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.powerManager = powerManager
+}
+
 func (wd *WebDashboard) getRecentAlerts() []AlertInfo {
 	// In a real implementation, this would fetch from a persistent store
 	// For now, return some example alerts
@@ -371,46 +647,148 @@ func (wd *WebDashboard) setupRoutes(router *mux.Router) {
 
 	// Health check endpoint
 	router.HandleFunc("/health", wd.handleHealth).Methods("GET")
+	router.HandleFunc("/livez", wd.handleLivez).Methods("GET")
+	router.HandleFunc("/readyz", wd.handleReadyz).Methods("GET")
 
 	// WebSocket endpoint for real-time updates
 	router.HandleFunc("/ws", wd.handleWebSocket).Methods("GET")
 
+	// Single sign-on
+	router.HandleFunc("/auth/login", wd.handleLogin).Methods("GET")
+	router.HandleFunc("/auth/callback", wd.handleLoginCallback).Methods("GET")
+	router.HandleFunc("/auth/logout", wd.handleLogout).Methods("POST")
+	router.HandleFunc("/auth/me", wd.handleCurrentUser).Methods("GET")
+
 	// API v1 routes
 	api := router.PathPrefix("/api/v1").Subrouter()
+	api.Use(wd.applySavedView)
+	api.Use(wd.scopeTenant)
+	api.Use(wd.requirePrimary)
 
 	// Metrics endpoints
-	api.HandleFunc("/metrics", wd.handleMetrics).Methods("GET")
+	api.HandleFunc("/metrics", wd.cached(wd.handleMetrics)).Methods("GET")
 	api.HandleFunc("/gpu/{id}/metrics", wd.handleGPUMetrics).Methods("GET")
-	api.HandleFunc("/system/stats", wd.handleSystemStats).Methods("GET")
+	api.HandleFunc("/system/stats", wd.cached(wd.handleSystemStats)).Methods("GET")
 
 	// Cost endpoints
-	api.HandleFunc("/costs", wd.handleCosts).Methods("GET")
-	api.HandleFunc("/costs/summary", wd.handleCostSummary).Methods("GET")
-	api.HandleFunc("/costs/forecast", wd.handleCostForecast).Methods("GET")
+	api.HandleFunc("/costs", wd.requireAdmin(wd.handleCosts)).Methods("GET")
+	api.HandleFunc("/costs/summary", wd.requireAdmin(wd.handleCostSummary)).Methods("GET")
+	api.HandleFunc("/costs/forecast", wd.requireAdmin(wd.cached(wd.handleCostForecast))).Methods("GET")
+	api.HandleFunc("/costs/plan", wd.requireAdmin(wd.cached(wd.handleCostPlan))).Methods("GET")
+	api.HandleFunc("/costs/whatif", wd.requireAdmin(wd.handleCostWhatIf)).Methods("POST")
+	api.HandleFunc("/costs/showback", wd.cached(wd.handleShowback)).Methods("GET")
+	api.HandleFunc("/costs/breakdown", wd.handleCostBreakdown).Methods("GET")
+
+	// Dashboard translations
+	api.HandleFunc("/i18n/locales", wd.handleDashboardLocales).Methods("GET")
+	api.HandleFunc("/i18n/messages/{locale}", wd.handleDashboardMessages).Methods("GET")
+
+	// Dashboards as code
+	api.HandleFunc("/layout", wd.handleLayout).Methods("GET")
+	api.HandleFunc("/layout/render", wd.handleLayoutRender).Methods("GET")
+	api.HandleFunc("/panels", wd.handleListPanels).Methods("GET")
+	api.HandleFunc("/panels", wd.handleCreatePanel).Methods("POST")
+	api.HandleFunc("/panels/{id}", wd.handleUpdatePanel).Methods("PUT")
+	api.HandleFunc("/panels/{id}", wd.handleDeletePanel).Methods("DELETE")
+	api.HandleFunc("/panels/{id}/data", wd.handlePanelData).Methods("GET")
+	api.HandleFunc("/query", wd.handleQuery).Methods("POST")
 
 	// Alert endpoints
 	api.HandleFunc("/alerts", wd.handleAlerts).Methods("GET")
 	api.HandleFunc("/alerts/{id}/resolve", wd.handleResolveAlert).Methods("POST")
-	api.HandleFunc("/alerts/summary", wd.handleAlertSummary).Methods("GET")
+	api.HandleFunc("/alerts/summary", wd.cached(wd.handleAlertSummary)).Methods("GET")
+	api.HandleFunc("/incidents", wd.requireAdmin(wd.handleIncidents)).Methods("GET")
+	api.HandleFunc("/incidents/{id}", wd.requireAdmin(wd.handleIncident)).Methods("GET")
+	api.HandleFunc("/incidents/{id}/timeline", wd.requireAdmin(wd.handleIncidentTimeline)).Methods("GET")
 
 	// Performance endpoints
-	api.HandleFunc("/performance", wd.handlePerformance).Methods("GET")
-	api.HandleFunc("/performance/efficiency", wd.handleEfficiency).Methods("GET")
-	api.HandleFunc("/performance/trends", wd.handleTrends).Methods("GET")
+	api.HandleFunc("/performance", wd.cached(wd.handlePerformance)).Methods("GET")
+	api.HandleFunc("/performance/efficiency", wd.cached(wd.handleEfficiency)).Methods("GET")
+	api.HandleFunc("/performance/baselines", wd.handleHardwareBaselines).Methods("GET")
+	api.HandleFunc("/performance/trends", wd.cached(wd.handleTrends)).Methods("GET")
+	api.HandleFunc("/performance/compare", wd.requireAdmin(wd.handleCompareWindows)).Methods("GET")
 
 	// GPU management endpoints
 	api.HandleFunc("/gpus", wd.handleGPUList).Methods("GET")
-	api.HandleFunc("/gpu/{id}/processes", wd.handleGPUProcesses).Methods("GET")
+	api.HandleFunc("/nodes", wd.handleNodes).Methods("GET")
+	api.HandleFunc("/nodes/{id}", wd.handleNode).Methods("GET")
+	api.HandleFunc("/nodes/{id}/gpus", wd.handleNodeGPUs).Methods("GET")
+	api.HandleFunc("/gpus/heatmap", wd.cached(wd.handleHeatmap)).Methods("GET")
+	api.HandleFunc("/gpus/orphans", wd.requireAdmin(wd.handleOrphans)).Methods("GET")
+	api.HandleFunc("/workloads", wd.handleWorkloads).Methods("GET")
+	api.HandleFunc("/workloads/recurring", wd.handleRecurringWorkloads).Methods("GET")
+	api.HandleFunc("/workloads/{id}/artifacts", wd.handleWorkloadArtifacts).Methods("GET")
+	api.HandleFunc("/workloads/{id}/artifacts", wd.requireScope(apikeys.ScopeSubmitWorkloads, wd.handleRegisterArtifacts)).Methods("POST")
+	api.HandleFunc("/pools", wd.handlePools).Methods("GET")
+	api.HandleFunc("/capacity", wd.handleCapacity).Methods("GET")
+	api.HandleFunc("/agents/metrics", wd.requireScope(apikeys.ScopePushMetrics, wd.handleAgentMetrics)).Methods("POST")
+	api.HandleFunc("/agents/clocks", wd.requireAdmin(wd.handleAgentClocks)).Methods("GET")
+	api.HandleFunc("/agents/gaps", wd.handleAgentGaps).Methods("GET")
+	api.HandleFunc("/energy", wd.requireAdmin(wd.cached(wd.handleEnergyReport))).Methods("GET")
+	api.HandleFunc("/energy/tariff", wd.requireAdmin(wd.cached(wd.handleTariffReport))).Methods("GET")
+	api.HandleFunc("/gpu/{id}/processes", wd.requireAdmin(wd.handleGPUProcesses)).Methods("GET")
 	api.HandleFunc("/gpu/{id}/history", wd.handleGPUHistory).Methods("GET")
+	api.HandleFunc("/gpu/{id}/power", wd.requireAdmin(wd.requireControlToken(wd.handleSetPowerLimit))).Methods("POST")
+	api.HandleFunc("/gpu/{id}/clocks", wd.requireAdmin(wd.requireControlToken(wd.handleSetClocks))).Methods("POST", "DELETE")
+	api.HandleFunc("/power/audit", wd.requireAdmin(wd.requireControlToken(wd.handlePowerAudit))).Methods("GET")
+	api.HandleFunc("/gpu/{id}/processes/{pid}/cleanup", wd.requireAdmin(wd.requireControlToken(wd.handleCleanupOrphan))).Methods("POST")
+
+	// Model serving
+	api.HandleFunc("/embeddings", wd.requireScope(apikeys.ScopeSubmitWorkloads, wd.handleEmbeddings)).Methods("POST")
+
+	// API key management
+	api.HandleFunc("/apikeys", wd.requireAdmin(wd.requireControlToken(wd.handleListAPIKeys))).Methods("GET")
+	api.HandleFunc("/apikeys", wd.requireAdmin(wd.requireControlToken(wd.handleCreateAPIKey))).Methods("POST")
+	api.HandleFunc("/apikeys/{id}", wd.requireAdmin(wd.requireControlToken(wd.handleGetAPIKey))).Methods("GET")
+	api.HandleFunc("/apikeys/{id}", wd.requireAdmin(wd.requireControlToken(wd.handleUpdateAPIKey))).Methods("PUT")
+	api.HandleFunc("/apikeys/{id}", wd.requireAdmin(wd.requireControlToken(wd.handleRevokeAPIKey))).Methods("DELETE")
+
+	// Alert rules, pools, budgets, model registrations and workload templates
+	// as resources, managed as config as code by the Terraform provider and
+	// the GitOps controller
+	api.HandleFunc("/resources/{kind}", wd.requireAdmin(wd.requireControlToken(wd.handleListResources))).Methods("GET")
+	api.HandleFunc("/resources/{kind}/{name}", wd.requireAdmin(wd.requireControlToken(wd.handleGetResource))).Methods("GET")
+	api.HandleFunc("/resources/{kind}/{name}", wd.requireAdmin(wd.requireControlToken(wd.handlePutResource))).Methods("PUT")
+	api.HandleFunc("/resources/{kind}/{name}", wd.requireAdmin(wd.requireControlToken(wd.handleDeleteResource))).Methods("DELETE")
+
+	// Warm standby replication
+	api.HandleFunc("/standby/state", wd.requireAdmin(wd.requireControlToken(wd.handleStandbyState))).Methods("GET")
+	api.HandleFunc("/standby/status", wd.handleStandbyStatus).Methods("GET")
+
+	// Per-user notification preferences
+	api.HandleFunc("/notifications/preferences", wd.requireControlToken(wd.handleGetNotificationPreferences)).Methods("GET")
+	api.HandleFunc("/notifications/preferences", wd.requireControlToken(wd.handleSetNotificationPreferences)).Methods("PUT")
+	api.HandleFunc("/notifications/preferences", wd.requireControlToken(wd.handleResetNotificationPreferences)).Methods("DELETE")
+
+	// Chart annotations
+	api.HandleFunc("/annotations", wd.handleListAnnotations).Methods("GET")
+	api.HandleFunc("/annotations/impact", wd.requireAdmin(wd.handleChangeImpact)).Methods("GET")
+	api.HandleFunc("/annotations", wd.requireScope(apikeys.ScopeWriteAnnotations, wd.handleCreateAnnotation)).Methods("POST")
+	api.HandleFunc("/annotations/{id}", wd.requireScope(apikeys.ScopeWriteAnnotations, wd.handleDeleteAnnotation)).Methods("DELETE")
+
+	// Saved views
+	api.HandleFunc("/views", wd.handleListViews).Methods("GET")
+	api.HandleFunc("/views", wd.requireScope(apikeys.ScopeReadMetrics, wd.handleCreateView)).Methods("POST")
+	api.HandleFunc("/views/{name}", wd.handleGetView).Methods("GET")
+	api.HandleFunc("/views/{name}", wd.requireScope(apikeys.ScopeReadMetrics, wd.handleUpdateView)).Methods("PUT")
+	api.HandleFunc("/views/{name}", wd.requireScope(apikeys.ScopeReadMetrics, wd.handleDeleteView)).Methods("DELETE")
 
 	// System endpoints
-	api.HandleFunc("/system/overview", wd.handleSystemOverview).Methods("GET")
+	api.HandleFunc("/system/overview", wd.cached(wd.handleSystemOverview)).Methods("GET")
 	api.HandleFunc("/system/status", wd.handleSystemStatus).Methods("GET")
+	api.HandleFunc("/system/buffers", wd.handleBufferOccupancy).Methods("GET")
+	api.HandleFunc("/system/pipeline", wd.handlePipelineStatus).Methods("GET")
+	api.HandleFunc("/system/airgap", wd.handleAirGapStatus).Methods("GET")
 
 	// Demo endpoints (for testing/simulation)
 	api.HandleFunc("/demo/trigger/{gpu_id}/{pattern}", wd.handleDemoTrigger).Methods("POST")
 	api.HandleFunc("/demo/simulation/speed", wd.handleSimulationSpeed).Methods("POST", "GET")
 
+	// Front-end libraries bundled for air-gapped sites
+	if wd.airGap != nil && bundledAssets != nil {
+		router.PathPrefix(vendorPath).Handler(http.StripPrefix(vendorPath, http.FileServer(http.FS(bundledAssets))))
+	}
+
 	// Static file serving for dashboard assets
 	staticDir := "/static/"
 	router.PathPrefix(staticDir).Handler(http.StripPrefix(staticDir, http.FileServer(http.Dir("./static/"))))
@@ -420,4 +798,7 @@ func (wd *WebDashboard) setupRoutes(router *mux.Router) {
 
 	// Logging middleware
 	router.Use(wd.loggingMiddleware)
+
+	// Login gate while single sign-on is configured
+	router.Use(wd.requireLogin)
 }
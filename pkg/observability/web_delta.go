@@ -0,0 +1,144 @@
+package observability
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// defaultKeyframeInterval is how many metric broadcasts pass between full
+// keyframes for delta clients when none is configured
+const defaultKeyframeInterval = 30
+
+// DashboardMetricsDelta is a metrics update carrying, per GPU, only the
+// fields changed since the update numbered base_seq. GPUs absent from
+// GPUMetrics are unchanged; RemovedGPUs stopped reporting.
+type DashboardMetricsDelta struct {
+	DashboardMetrics
+	RemovedGPUs []string `json:"removed_gpus,omitempty"`
+}
+
+// metricsDeltaTracker numbers metric broadcasts and diffs each against the
+// previous one
+type metricsDeltaTracker struct {
+	interval      int
+	seq           uint64
+	sinceKeyframe int
+	base          map[string]map[string]interface{} // GPU fields as last broadcast
+	mu            sync.Mutex
+}
+
+// newMetricsDeltaTracker creates a tracker sending a keyframe every interval
+// broadcasts
+func newMetricsDeltaTracker(interval int) *metricsDeltaTracker {
+	if interval <= 0 {
+		interval = defaultKeyframeInterval
+	}
+	return &metricsDeltaTracker{interval: interval}
+}
+
+// gpuFields flattens GPU metrics to their JSON fields for comparison
+func gpuFields(metrics map[string]interface{}) map[string]map[string]interface{} {
+	fields := make(map[string]map[string]interface{}, len(metrics))
+	for gpuID, value := range metrics {
+		data, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		var decoded map[string]interface{}
+		if json.Unmarshal(data, &decoded) == nil {
+			fields[gpuID] = decoded
+		}
+	}
+	return fields
+}
+
+// next records a broadcast and returns its sequence number and its delta
+// from the previous broadcast, or a nil delta when a keyframe is due
+func (t *metricsDeltaTracker) next(metrics DashboardMetrics) (uint64, *DashboardMetricsDelta) {
+	current := gpuFields(metrics.GPUMetrics)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.seq++
+	previous := t.base
+	t.base = current
+
+	t.sinceKeyframe++
+	if previous == nil || t.sinceKeyframe >= t.interval {
+		t.sinceKeyframe = 0
+		return t.seq, nil
+	}
+
+	delta := &DashboardMetricsDelta{DashboardMetrics: metrics}
+	delta.GPUMetrics = make(map[string]interface{})
+	for gpuID, fields := range current {
+		before, existed := previous[gpuID]
+		changed := make(map[string]interface{})
+		for name, value := range fields {
+			if old, present := before[name]; !existed || !present || !reflect.DeepEqual(old, value) {
+				changed[name] = value
+			}
+		}
+		if len(changed) > 0 {
+			delta.GPUMetrics[gpuID] = changed
+		}
+	}
+	for gpuID := range previous {
+		if _, exists := current[gpuID]; !exists {
+			delta.RemovedGPUs = append(delta.RemovedGPUs, gpuID)
+		}
+	}
+	sort.Strings(delta.RemovedGPUs)
+	return t.seq, delta
+}
+
+// metricsMessages builds the full update and, unless a keyframe is due, the
+// delta update of a metrics broadcast
+func (wd *WebDashboard) metricsMessages() (map[string]interface{}, map[string]interface{}) {
+	full := wd.buildMetricsMessage()
+	seq, delta := wd.deltaTracker.next(full["data"].(DashboardMetrics))
+	full["seq"] = seq
+	if delta == nil {
+		return full, nil
+	}
+	return full, map[string]interface{}{
+		"type":     "metrics_delta",
+		"seq":      seq,
+		"base_seq": seq - 1,
+		"data":     delta,
+	}
+}
+
+// deliverMetrics sends a metrics broadcast to this replica's clients: delta
+// clients get the delta, or the full update when they are waiting for a
+// keyframe or none is due, and other clients the full update
+func (wd *WebDashboard) deliverMetrics(full, delta interface{}) {
+	type recipient struct {
+		conn     *websocket.Conn
+		useDelta bool
+	}
+
+	wd.wsMutex.Lock()
+	recipients := make([]recipient, 0, len(wd.wsConnections))
+	for conn := range wd.wsConnections {
+		awaitingKeyframe, deltaClient := wd.wsDeltaClients[conn]
+		useDelta := deltaClient && !awaitingKeyframe && delta != nil
+		if deltaClient && !useDelta {
+			wd.wsDeltaClients[conn] = false
+		}
+		recipients = append(recipients, recipient{conn: conn, useDelta: useDelta})
+	}
+	wd.wsMutex.Unlock()
+
+	for _, r := range recipients {
+		if r.useDelta {
+			wd.sendToConnection(r.conn, delta)
+		} else {
+			wd.sendToConnection(r.conn, full)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package observability
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestMetricsDeltaTrackerDiffsAndKeyframes(t *testing.T) {
+	tracker := newMetricsDeltaTracker(3)
+	snapshot := func(gpus map[string]interface{}) DashboardMetrics {
+		return DashboardMetrics{GPUMetrics: gpus}
+	}
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	seq, delta := tracker.next(snapshot(map[string]interface{}{
+		"gpu-0": gpu.GPUMetrics{GPUID: "gpu-0", UtilizationGPU: 50, Temperature: 60, Timestamp: at},
+		"gpu-1": gpu.GPUMetrics{GPUID: "gpu-1", UtilizationGPU: 10, Temperature: 40, Timestamp: at},
+	}))
+	if seq != 1 || delta != nil {
+		t.Fatalf("Expected the first update to be a keyframe, got %d %+v", seq, delta)
+	}
+
+	seq, delta = tracker.next(snapshot(map[string]interface{}{
+		"gpu-0": gpu.GPUMetrics{GPUID: "gpu-0", UtilizationGPU: 75, Temperature: 60, Timestamp: at},
+		"gpu-2": gpu.GPUMetrics{GPUID: "gpu-2", UtilizationGPU: 5, Timestamp: at},
+	}))
+	if seq != 2 || delta == nil {
+		t.Fatalf("Expected a delta, got %d %+v", seq, delta)
+	}
+	if changed := delta.GPUMetrics["gpu-0"]; !reflect.DeepEqual(changed, map[string]interface{}{"utilization_gpu": 75.0}) {
+		t.Errorf("Expected only gpu-0's changed field, got %v", changed)
+	}
+	if added, ok := delta.GPUMetrics["gpu-2"].(map[string]interface{}); !ok || added["gpu_id"] != "gpu-2" || added["temperature"] != 0.0 {
+		t.Errorf("Expected every field of a new GPU, got %v", delta.GPUMetrics["gpu-2"])
+	}
+	if !reflect.DeepEqual(delta.RemovedGPUs, []string{"gpu-1"}) {
+		t.Errorf("Expected gpu-1 reported removed, got %v", delta.RemovedGPUs)
+	}
+
+	unchanged := snapshot(map[string]interface{}{
+		"gpu-0": gpu.GPUMetrics{GPUID: "gpu-0", UtilizationGPU: 75, Temperature: 60, Timestamp: at},
+		"gpu-2": gpu.GPUMetrics{GPUID: "gpu-2", UtilizationGPU: 5, Timestamp: at},
+	})
+	if _, delta = tracker.next(unchanged); delta == nil || len(delta.GPUMetrics) != 0 {
+		t.Errorf("Expected an empty delta without changes, got %+v", delta)
+	}
+	if seq, delta = tracker.next(unchanged); seq != 4 || delta != nil {
+		t.Errorf("Expected a keyframe after three updates, got %d %+v", seq, delta)
+	}
+}
+
+func TestDeltaClientsGetKeyframeThenDeltas(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{})
+	dashboard.lastMetrics["gpu-0"] = gpu.GPUMetrics{GPUID: "gpu-0", UtilizationGPU: 50}
+	server := httptest.NewServer(dashboard.server.Handler)
+	defer server.Close()
+
+	connect := func(query string) *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws"+query, nil)
+		if err != nil {
+			t.Fatalf("Dial failed: %v", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var initial map[string]interface{}
+		conn.ReadJSON(&initial)
+		return conn
+	}
+	read := func(conn *websocket.Conn) map[string]interface{} {
+		var message map[string]interface{}
+		if err := conn.ReadJSON(&message); err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		return message
+	}
+	deltaClient := connect("?delta=1")
+	defer deltaClient.Close()
+	fullClient := connect("")
+	defer fullClient.Close()
+
+	// The first broadcast is a keyframe, and so are the first ones a new
+	// delta client sees
+	dashboard.broadcastMetrics()
+	if message := read(deltaClient); message["type"] != "metrics_update" || message["seq"] != 1.0 {
+		t.Errorf("Expected a keyframe first, got %v", message)
+	}
+	read(fullClient)
+
+	dashboard.mu.Lock()
+	dashboard.lastMetrics["gpu-0"] = gpu.GPUMetrics{GPUID: "gpu-0", UtilizationGPU: 90}
+	dashboard.mu.Unlock()
+	dashboard.broadcastMetrics()
+
+	message := read(deltaClient)
+	if message["type"] != "metrics_delta" || message["base_seq"] != 1.0 {
+		t.Fatalf("Expected a delta on the keyframe, got %v", message)
+	}
+	gpus := message["data"].(map[string]interface{})["gpu_metrics"].(map[string]interface{})
+	if !reflect.DeepEqual(gpus["gpu-0"], map[string]interface{}{"utilization_gpu": 90.0}) {
+		t.Errorf("Expected only the changed utilization, got %v", gpus)
+	}
+	if message := read(fullClient); message["type"] != "metrics_update" {
+		t.Errorf("Expected clients without ?delta to keep full updates, got %v", message["type"])
+	}
+}
@@ -0,0 +1,67 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving"
+)
+
+// maxEmbeddingRequestBytes bounds the body of an embedding request
+const maxEmbeddingRequestBytes = 8 << 20
+
+// handleEmbeddings computes embeddings of text inputs with a registered model,
+// micro-batched with concurrent requests for the same model
+func (wd *WebDashboard) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	manager := wd.servingManager
+	wd.mu.RUnlock()
+	if manager == nil {
+		http.Error(w, "serving manager not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var request struct {
+		ID      string   `json:"id"`
+		ModelID string   `json:"model_id"`
+		Inputs  []string `json:"inputs"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxEmbeddingRequestBytes)).Decode(&request); err != nil || request.ModelID == "" || len(request.Inputs) == 0 {
+		http.Error(w, "expected a JSON body with model_id and inputs", http.StatusBadRequest)
+		return
+	}
+
+	// Models of other tenants are reported as unknown
+	model, exists := manager.GetModel(request.ModelID)
+	if !exists || !inTenant(r, model.Tenant) {
+		http.Error(w, "model not found: "+request.ModelID, http.StatusNotFound)
+		return
+	}
+
+	if request.ID == "" {
+		request.ID = fmt.Sprintf("emb-%d", time.Now().UnixNano())
+	}
+	inputs := make([][]byte, len(request.Inputs))
+	for i, input := range request.Inputs {
+		inputs[i] = []byte(input)
+	}
+	resp, err := manager.SubmitEmbeddingRequest(&serving.EmbeddingRequest{ID: request.ID, ModelID: request.ModelID, Inputs: inputs})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":             resp.RequestID,
+		"model_id":       request.ModelID,
+		"embeddings":     resp.Embeddings,
+		"latency_ms":     float64(resp.Latency.Microseconds()) / 1000,
+		"queue_wait_ms":  float64(resp.QueueWait.Microseconds()) / 1000,
+		"max_batch_size": resp.MaxBatchSize,
+	})
+}
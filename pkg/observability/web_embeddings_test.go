@@ -0,0 +1,60 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving"
+)
+
+func TestEmbeddingsAPI(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{
+		Port:          0,
+		ControlTokens: map[string]string{"s3cret": "rag"},
+	})
+	embed := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/embeddings", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		dashboard.server.Handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+	body := `{"model_id": "embedder", "inputs": ["first chunk", "second chunk"]}`
+	if response := embed("s3cret", body); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a serving manager, got %d", response.Code)
+	}
+
+	manager := serving.NewServingManager(nil, 0)
+	manager.RegisterModel(&serving.Model{ID: "embedder", Name: "Embedder"})
+	dashboard.SetServingManager(manager)
+
+	response := embed("s3cret", body)
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", response.Code, response.Body.String())
+	}
+	var result struct {
+		ModelID    string      `json:"model_id"`
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	json.Unmarshal(response.Body.Bytes(), &result)
+	if result.ModelID != "embedder" || len(result.Embeddings) != 2 || len(result.Embeddings[0]) == 0 {
+		t.Errorf("Expected an embedding per input, got %+v", result)
+	}
+
+	for _, c := range []struct {
+		token, body string
+		code        int
+	}{
+		{"wrong", body, http.StatusUnauthorized},
+		{"s3cret", `{"model_id": "unregistered", "inputs": ["x"]}`, http.StatusNotFound},
+		{"s3cret", `{"model_id": "embedder", "inputs": []}`, http.StatusBadRequest},
+		{"s3cret", `{"model_id": "embedder", "inputs": [""]}`, http.StatusBadRequest},
+	} {
+		if response := embed(c.token, c.body); response.Code != c.code {
+			t.Errorf("Expected %d for %s, got %d: %s", c.code, c.body, response.Code, response.Body.String())
+		}
+	}
+}
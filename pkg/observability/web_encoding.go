@@ -0,0 +1,633 @@
+package observability
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Response encodings offered by high-volume endpoints. Binary encodings
+// carry the same document as JSON, with the same field names.
+const (
+	EncodingJSON    = "json"
+	EncodingMsgpack = "msgpack"
+	// An agentaflow.dashboard.v1.Value message
+	EncodingProtobuf = "protobuf"
+)
+
+// encodingContentTypes maps each encoding to the media type it is served as
+var encodingContentTypes = map[string]string{
+	EncodingJSON:     "application/json",
+	EncodingMsgpack:  "application/msgpack",
+	EncodingProtobuf: "application/x-protobuf",
+}
+
+// negotiateResponseEncoding picks an encoding from ?format, then the Accept
+// header, defaulting to JSON
+func negotiateResponseEncoding(r *http.Request) (string, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		if _, known := encodingContentTypes[format]; !known {
+			return "", fmt.Errorf("unsupported format %q (expected json, msgpack or protobuf)", format)
+		}
+		return format, nil
+	}
+
+	best, bestQuality := EncodingJSON, 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			fmt.Sscanf(q, "%g", &quality)
+		}
+		encoding := ""
+		switch mediaType {
+		case "application/msgpack", "application/x-msgpack":
+			encoding = EncodingMsgpack
+		case "application/x-protobuf", "application/protobuf":
+			encoding = EncodingProtobuf
+		case "application/json":
+			encoding = EncodingJSON
+		}
+		if encoding != "" && quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+	return best, nil
+}
+
+// writeEncoded writes a response in the encoding the client negotiated
+func writeEncoded(w http.ResponseWriter, r *http.Request, value interface{}) {
+	w.Header().Add("Vary", "Accept")
+	encoding, err := negotiateResponseEncoding(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotAcceptable)
+		return
+	}
+
+	data, err := encodeResponse(encoding, value)
+	if err != nil {
+		http.Error(w, "failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", encodingContentTypes[encoding])
+	w.Write(data)
+}
+
+// encodeResponse encodes a value as JSON, MessagePack or protobuf
+func encodeResponse(encoding string, value interface{}) ([]byte, error) {
+	switch encoding {
+	case EncodingJSON:
+		data, err := json.Marshal(value)
+		return append(data, '\n'), err
+	case EncodingMsgpack:
+		encoder := &msgpackEncoder{}
+		if err := encodeDocument(encoder, reflect.ValueOf(value)); err != nil {
+			return nil, err
+		}
+		return encoder.buf.Bytes(), nil
+	case EncodingProtobuf:
+		encoder := &protobufEncoder{}
+		if err := encodeDocument(encoder, reflect.ValueOf(value)); err != nil {
+			return nil, err
+		}
+		return encoder.buf, nil
+	}
+	return nil, fmt.Errorf("unsupported encoding %q", encoding)
+}
+
+// documentEncoder receives a document as encodeDocument walks it. Lists and
+// objects call back for each item so encoders can frame them as they need
+type documentEncoder interface {
+	null()
+	bool(v bool)
+	int(v int64)
+	uint(v uint64)
+	float(v float64)
+	string(v string)
+	list(n int, item func(i int) error) error
+	object(keys []string, field func(i int) error) error
+}
+
+// textMarshaler is encoding.TextMarshaler, which this package's encoding
+// type shadows
+type textMarshaler interface {
+	MarshalText() ([]byte, error)
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*textMarshaler)(nil)).Elem()
+	jsonNumberType    = reflect.TypeOf(json.Number(""))
+	timeType          = reflect.TypeOf(time.Time{})
+)
+
+// encodeDocument walks a value the way encoding/json does, with the same
+// field names, omitempty and marshalers, keeping integers as integers
+func encodeDocument(e documentEncoder, v reflect.Value) error {
+	if !v.IsValid() {
+		e.null()
+		return nil
+	}
+	switch v.Type() {
+	case timeType:
+		e.string(v.Interface().(time.Time).Format(time.RFC3339Nano))
+		return nil
+	case jsonNumberType:
+		return encodeNumber(e, json.Number(v.String()))
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		e.null()
+		return nil
+	}
+	if marshaler, ok := asMarshaler(v, jsonMarshalerType); ok {
+		return encodeMarshaled(e, marshaler.(json.Marshaler))
+	}
+	if marshaler, ok := asMarshaler(v, textMarshalerType); ok {
+		text, err := marshaler.(textMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		e.string(string(text))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		e.bool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.int(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := v.Uint(); u <= math.MaxInt64 {
+			e.int(int64(u))
+		} else {
+			e.uint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("unsupported float value %v", f)
+		}
+		if v.Kind() == reflect.Float32 {
+			// Widen through the shortest float32 form, as JSON prints it
+			f, _ = strconv.ParseFloat(strconv.FormatFloat(f, 'g', -1, 32), 64)
+		}
+		e.float(f)
+	case reflect.String:
+		e.string(v.String())
+	case reflect.Ptr, reflect.Interface:
+		return encodeDocument(e, v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.null()
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.string(base64.StdEncoding.EncodeToString(v.Bytes()))
+			return nil
+		}
+		return e.list(v.Len(), func(i int) error { return encodeDocument(e, v.Index(i)) })
+	case reflect.Array:
+		return e.list(v.Len(), func(i int) error { return encodeDocument(e, v.Index(i)) })
+	case reflect.Map:
+		if v.IsNil() {
+			e.null()
+			return nil
+		}
+		return encodeMap(e, v)
+	case reflect.Struct:
+		return encodeStruct(e, v)
+	default:
+		return fmt.Errorf("cannot encode %s", v.Type())
+	}
+	return nil
+}
+
+// asMarshaler returns the value as a marshaler interface, taking its address
+// for pointer receivers like encoding/json
+func asMarshaler(v reflect.Value, marshalerType reflect.Type) (interface{}, bool) {
+	if v.Type().Implements(marshalerType) {
+		return v.Interface(), true
+	}
+	if v.Kind() != reflect.Ptr && v.CanAddr() && reflect.PtrTo(v.Type()).Implements(marshalerType) {
+		return v.Addr().Interface(), true
+	}
+	return nil, false
+}
+
+// encodeMarshaled encodes the JSON a custom marshaler produces
+func encodeMarshaled(e documentEncoder, marshaler json.Marshaler) error {
+	data, err := marshaler.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return err
+	}
+	return encodeDocument(e, reflect.ValueOf(document))
+}
+
+// encodeNumber encodes a JSON number as an integer when it is one
+func encodeNumber(e documentEncoder, n json.Number) error {
+	if i, err := n.Int64(); err == nil {
+		e.int(i)
+		return nil
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		e.uint(u)
+		return nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return err
+	}
+	e.float(f)
+	return nil
+}
+
+// encodeMap encodes a map as an object with sorted keys
+func encodeMap(e documentEncoder, v reflect.Value) error {
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{key, iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	keys := make([]string, len(entries))
+	for i := range entries {
+		keys[i] = entries[i].key
+	}
+	return e.object(keys, func(i int) error { return encodeDocument(e, entries[i].value) })
+}
+
+// mapKey formats a map key the way encoding/json does
+func mapKey(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+	if marshaler, ok := key.Interface().(textMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported map key type %s", key.Type())
+}
+
+// encodeStruct encodes a struct's JSON fields as an object
+func encodeStruct(e documentEncoder, v reflect.Value) error {
+	var keys []string
+	var values []reflect.Value
+	for _, field := range structFields(v.Type()) {
+		value, ok := fieldByIndex(v, field.index)
+		if !ok || (field.omitEmpty && isEmptyValue(value)) {
+			continue
+		}
+		keys = append(keys, field.name)
+		values = append(values, value)
+	}
+	return e.object(keys, func(i int) error { return encodeDocument(e, values[i]) })
+}
+
+// documentField is a struct field as encoding/json names it
+type documentField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// documentFields caches the fields of each struct type
+var documentFields sync.Map
+
+// structFields lists a struct's JSON fields, promoting embedded structs'
+// fields unless a shallower field has the same name
+func structFields(t reflect.Type) []documentField {
+	if cached, ok := documentFields.Load(t); ok {
+		return cached.([]documentField)
+	}
+
+	var fields []documentField
+	seen := make(map[string]bool)
+	current := []documentField{{index: nil}}
+	visited := map[reflect.Type]bool{}
+	for len(current) > 0 {
+		var next []documentField
+		depth := make(map[string]bool)
+		for _, parent := range current {
+			structType := t
+			if parent.index != nil {
+				structType = t.FieldByIndex(parent.index).Type
+				if structType.Kind() == reflect.Ptr {
+					structType = structType.Elem()
+				}
+			}
+			if visited[structType] {
+				continue
+			}
+			visited[structType] = true
+
+			for i := 0; i < structType.NumField(); i++ {
+				sf := structType.Field(i)
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, options := tag, ""
+				if comma := strings.Index(tag, ","); comma >= 0 {
+					name, options = tag[:comma], tag[comma+1:]
+				}
+				index := append(append([]int{}, parent.index...), i)
+
+				fieldType := sf.Type
+				if fieldType.Kind() == reflect.Ptr {
+					fieldType = fieldType.Elem()
+				}
+				if sf.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+					next = append(next, documentField{index: index})
+					continue
+				}
+				if sf.PkgPath != "" {
+					continue
+				}
+				if name == "" {
+					name = sf.Name
+				}
+				if seen[name] {
+					continue
+				}
+				depth[name] = true
+				fields = append(fields, documentField{
+					name:      name,
+					index:     index,
+					omitEmpty: strings.Contains(","+options+",", ",omitempty,"),
+				})
+			}
+		}
+		for name := range depth {
+			seen[name] = true
+		}
+		current = next
+	}
+
+	documentFields.Store(t, fields)
+	return fields
+}
+
+// fieldByIndex follows a field path, reporting false through a nil
+// embedded pointer
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, position := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(position)
+	}
+	return v, true
+}
+
+// isEmptyValue reports whether omitempty drops a value
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// msgpackEncoder writes MessagePack with integers in their smallest form
+type msgpackEncoder struct {
+	buf bytes.Buffer
+}
+
+func (e *msgpackEncoder) null() { e.buf.WriteByte(0xc0) }
+
+func (e *msgpackEncoder) bool(v bool) {
+	if v {
+		e.buf.WriteByte(0xc3)
+	} else {
+		e.buf.WriteByte(0xc2)
+	}
+}
+
+func (e *msgpackEncoder) int(v int64) { writeMsgpackInt(&e.buf, v) }
+
+func (e *msgpackEncoder) uint(v uint64) {
+	if v <= math.MaxInt64 {
+		writeMsgpackInt(&e.buf, int64(v))
+		return
+	}
+	e.buf.WriteByte(0xcf)
+	binary.Write(&e.buf, binary.BigEndian, v)
+}
+
+func (e *msgpackEncoder) float(v float64) {
+	e.buf.WriteByte(0xcb)
+	binary.Write(&e.buf, binary.BigEndian, math.Float64bits(v))
+}
+
+func (e *msgpackEncoder) string(v string) {
+	n := len(v)
+	switch {
+	case n < 32:
+		e.buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		e.buf.WriteByte(0xd9)
+		e.buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(0xda)
+		binary.Write(&e.buf, binary.BigEndian, uint16(n))
+	default:
+		e.buf.WriteByte(0xdb)
+		binary.Write(&e.buf, binary.BigEndian, uint32(n))
+	}
+	e.buf.WriteString(v)
+}
+
+func (e *msgpackEncoder) list(n int, item func(i int) error) error {
+	writeMsgpackLength(&e.buf, n, 0x90, 0xdc, 0xdd)
+	for i := 0; i < n; i++ {
+		if err := item(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *msgpackEncoder) object(keys []string, field func(i int) error) error {
+	writeMsgpackLength(&e.buf, len(keys), 0x80, 0xde, 0xdf)
+	for i, key := range keys {
+		e.string(key)
+		if err := field(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMsgpackInt writes an integer in the smallest MessagePack form
+func writeMsgpackInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i < 128:
+		buf.WriteByte(byte(i))
+	case i < 0 && i >= -32:
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		buf.WriteByte(0xd1)
+		binary.Write(buf, binary.BigEndian, int16(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, i)
+	}
+}
+
+// writeMsgpackLength writes an array or map header
+func writeMsgpackLength(buf *bytes.Buffer, n int, fix, len16, len32 byte) {
+	switch {
+	case n < 16:
+		buf.WriteByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(len16)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(len32)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+// Field numbers of agentaflow.dashboard.v1.Value, which follows
+// google.protobuf.Value and adds integer kinds
+const (
+	protoNullValue   protowire.Number = 1
+	protoNumberValue protowire.Number = 2
+	protoStringValue protowire.Number = 3
+	protoBoolValue   protowire.Number = 4
+	protoStructValue protowire.Number = 5
+	protoListValue   protowire.Number = 6
+	protoIntValue    protowire.Number = 7
+	protoUintValue   protowire.Number = 8
+)
+
+// protobufEncoder writes an agentaflow.dashboard.v1.Value message. buf holds
+// the encoding of the value written last
+type protobufEncoder struct {
+	buf []byte
+}
+
+func (e *protobufEncoder) null() {
+	e.buf = protowire.AppendTag(e.buf, protoNullValue, protowire.VarintType)
+	e.buf = protowire.AppendVarint(e.buf, 0)
+}
+
+func (e *protobufEncoder) bool(v bool) {
+	e.buf = protowire.AppendTag(e.buf, protoBoolValue, protowire.VarintType)
+	e.buf = protowire.AppendVarint(e.buf, protowire.EncodeBool(v))
+}
+
+func (e *protobufEncoder) int(v int64) {
+	e.buf = protowire.AppendTag(e.buf, protoIntValue, protowire.VarintType)
+	e.buf = protowire.AppendVarint(e.buf, uint64(v))
+}
+
+func (e *protobufEncoder) uint(v uint64) {
+	e.buf = protowire.AppendTag(e.buf, protoUintValue, protowire.VarintType)
+	e.buf = protowire.AppendVarint(e.buf, v)
+}
+
+func (e *protobufEncoder) float(v float64) {
+	e.buf = protowire.AppendTag(e.buf, protoNumberValue, protowire.Fixed64Type)
+	e.buf = protowire.AppendFixed64(e.buf, math.Float64bits(v))
+}
+
+func (e *protobufEncoder) string(v string) {
+	e.buf = protowire.AppendTag(e.buf, protoStringValue, protowire.BytesType)
+	e.buf = protowire.AppendString(e.buf, v)
+}
+
+// list writes a ListValue, whose repeated values are field 1
+func (e *protobufEncoder) list(n int, item func(i int) error) error {
+	outer := e.buf
+	var list []byte
+	for i := 0; i < n; i++ {
+		e.buf = nil
+		if err := item(i); err != nil {
+			return err
+		}
+		list = protowire.AppendTag(list, 1, protowire.BytesType)
+		list = protowire.AppendBytes(list, e.buf)
+	}
+	e.buf = protowire.AppendTag(outer, protoListValue, protowire.BytesType)
+	e.buf = protowire.AppendBytes(e.buf, list)
+	return nil
+}
+
+// object writes a Struct, whose fields map is field 1 with entries of key 1
+// and value 2
+func (e *protobufEncoder) object(keys []string, field func(i int) error) error {
+	outer := e.buf
+	var fields []byte
+	for i, key := range keys {
+		e.buf = nil
+		if err := field(i); err != nil {
+			return err
+		}
+		entry := protowire.AppendTag(nil, 1, protowire.BytesType)
+		entry = protowire.AppendString(entry, key)
+		entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+		entry = protowire.AppendBytes(entry, e.buf)
+		fields = protowire.AppendTag(fields, 1, protowire.BytesType)
+		fields = protowire.AppendBytes(fields, entry)
+	}
+	e.buf = protowire.AppendTag(outer, protoStructValue, protowire.BytesType)
+	e.buf = protowire.AppendBytes(e.buf, fields)
+	return nil
+}
@@ -0,0 +1,411 @@
+package observability
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// decodeMsgpack reads the MessagePack subset msgpackEncoder writes, with
+// integers as int64 or uint64 and floats as float64
+func decodeMsgpack(r *bytes.Reader) (interface{}, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	readN := func(n int) []byte {
+		data := make([]byte, n)
+		io.ReadFull(r, data)
+		return data
+	}
+	length := func(size int) int {
+		data := readN(size)
+		switch size {
+		case 1:
+			return int(data[0])
+		case 2:
+			return int(binary.BigEndian.Uint16(data))
+		}
+		return int(binary.BigEndian.Uint32(data))
+	}
+
+	switch {
+	case tag <= 0x7f:
+		return int64(tag), nil
+	case tag >= 0xe0:
+		return int64(int8(tag)), nil
+	case tag&0xe0 == 0xa0:
+		return string(readN(int(tag & 0x1f))), nil
+	case tag&0xf0 == 0x90:
+		return decodeMsgpackArray(r, int(tag&0x0f))
+	case tag&0xf0 == 0x80:
+		return decodeMsgpackMap(r, int(tag&0x0f))
+	}
+	switch tag {
+	case 0xc0:
+		return nil, nil
+	case 0xc2, 0xc3:
+		return tag == 0xc3, nil
+	case 0xcb:
+		return math.Float64frombits(binary.BigEndian.Uint64(readN(8))), nil
+	case 0xcf:
+		return binary.BigEndian.Uint64(readN(8)), nil
+	case 0xd0:
+		return int64(int8(readN(1)[0])), nil
+	case 0xd1:
+		return int64(int16(binary.BigEndian.Uint16(readN(2)))), nil
+	case 0xd2:
+		return int64(int32(binary.BigEndian.Uint32(readN(4)))), nil
+	case 0xd3:
+		return int64(binary.BigEndian.Uint64(readN(8))), nil
+	case 0xd9:
+		return string(readN(length(1))), nil
+	case 0xda:
+		return string(readN(length(2))), nil
+	case 0xdb:
+		return string(readN(length(4))), nil
+	case 0xdc:
+		return decodeMsgpackArray(r, length(2))
+	case 0xde:
+		return decodeMsgpackMap(r, length(2))
+	}
+	return nil, fmt.Errorf("unexpected msgpack tag %#x", tag)
+}
+
+func decodeMsgpackArray(r *bytes.Reader, n int) (interface{}, error) {
+	items := make([]interface{}, n)
+	for i := range items {
+		item, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+func decodeMsgpackMap(r *bytes.Reader, n int) (interface{}, error) {
+	entries := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		key, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		if entries[key.(string)], err = decodeMsgpack(r); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// decodeProtobufValue reads an agentaflow.dashboard.v1.Value message, with
+// the same Go types as decodeMsgpack
+func decodeProtobufValue(data []byte) (interface{}, error) {
+	var value interface{}
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+		var raw []byte
+		var varint uint64
+		switch wireType {
+		case protowire.VarintType:
+			varint, n = protowire.ConsumeVarint(data)
+		case protowire.Fixed64Type:
+			varint, n = protowire.ConsumeFixed64(data)
+		case protowire.BytesType:
+			raw, n = protowire.ConsumeBytes(data)
+		default:
+			return nil, fmt.Errorf("unexpected wire type %d", wireType)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		var err error
+		switch number {
+		case protoNullValue:
+			value = nil
+		case protoNumberValue:
+			value = math.Float64frombits(varint)
+		case protoStringValue:
+			value = string(raw)
+		case protoBoolValue:
+			value = varint != 0
+		case protoStructValue:
+			value, err = decodeProtobufStruct(raw)
+		case protoListValue:
+			value, err = decodeProtobufList(raw)
+		case protoIntValue:
+			value = int64(varint)
+		case protoUintValue:
+			value = varint
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+// decodeProtobufEntries reads the length-delimited field 1 entries of a
+// Struct or ListValue
+func decodeProtobufEntries(data []byte, each func(entry []byte) error) error {
+	for len(data) > 0 {
+		_, _, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		entry, m := protowire.ConsumeBytes(data[n:])
+		if m < 0 {
+			return protowire.ParseError(m)
+		}
+		if err := each(entry); err != nil {
+			return err
+		}
+		data = data[n+m:]
+	}
+	return nil
+}
+
+func decodeProtobufList(data []byte) (interface{}, error) {
+	items := []interface{}{}
+	err := decodeProtobufEntries(data, func(entry []byte) error {
+		item, err := decodeProtobufValue(entry)
+		items = append(items, item)
+		return err
+	})
+	return items, err
+}
+
+func decodeProtobufStruct(data []byte) (interface{}, error) {
+	fields := map[string]interface{}{}
+	err := decodeProtobufEntries(data, func(entry []byte) error {
+		var key string
+		var value interface{}
+		err := decodeProtobufEntries(entry, func(part []byte) error {
+			if key == "" {
+				key = string(part)
+				return nil
+			}
+			var err error
+			value, err = decodeProtobufValue(part)
+			return err
+		})
+		fields[key] = value
+		return err
+	})
+	return fields, err
+}
+
+// sameDocument compares a decoded binary document with decoded JSON. JSON
+// prints whole floats as integers, so numbers compare by value, but an
+// integer only matches a float that holds it exactly
+func sameDocument(decoded, expected interface{}) bool {
+	switch e := expected.(type) {
+	case json.Number:
+		switch d := decoded.(type) {
+		case int64:
+			return e.String() == fmt.Sprint(d)
+		case uint64:
+			return e.String() == fmt.Sprint(d)
+		case float64:
+			if i, err := e.Int64(); err == nil {
+				return float64(i) == d && int64(d) == i
+			}
+			f, err := e.Float64()
+			return err == nil && f == d
+		}
+		return false
+	case []interface{}:
+		d, ok := decoded.([]interface{})
+		if !ok || len(d) != len(e) {
+			return false
+		}
+		for i := range e {
+			if !sameDocument(d[i], e[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		d, ok := decoded.(map[string]interface{})
+		if !ok || len(d) != len(e) {
+			return false
+		}
+		for key := range e {
+			if !sameDocument(d[key], e[key]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(decoded, expected)
+}
+
+type encodedReading struct {
+	GPUID   string     `json:"gpu_id"`
+	Serial  uint64     `json:"serial"`
+	Note    string     `json:"note,omitempty"`
+	Skipped string     `json:"-"`
+	Labels  []string   `json:"labels"`
+	Parent  *nodeLabel `json:"parent,omitempty"`
+	nodeLabel
+}
+
+type nodeLabel struct {
+	Node string `json:"node"`
+}
+
+func TestEncodeResponseMatchesJSONDocument(t *testing.T) {
+	value := map[string]interface{}{
+		"gpu_id":  "gpu-0",
+		"count":   3,
+		"big":     70000,
+		"neg":     -200,
+		"ratio":   0.875,
+		"ok":      true,
+		"missing": nil,
+		"long":    strings.Repeat("x", 300),
+		"history": []string{},
+		"series":  []float64{1, 2.5, -3},
+		"scale":   float32(0.1),
+		"at":      time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+		"counter": int64(1<<53 + 1),
+		"max":     uint64(math.MaxUint64),
+		"reading": encodedReading{GPUID: "gpu-1", Serial: 42, Skipped: "x", nodeLabel: nodeLabel{"node-a"}},
+		"byGPU":   map[int]string{2: "b", 1: "a"},
+		"raw":     json.RawMessage(`{"n":12345678901234567}`),
+	}
+	jsonData, _ := encodeResponse(EncodingJSON, value)
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	var expected interface{}
+	decoder.Decode(&expected)
+
+	msgpackData, err := encodeResponse(EncodingMsgpack, value)
+	if err != nil {
+		t.Fatalf("msgpack: %v", err)
+	}
+	decoded, err := decodeMsgpack(bytes.NewReader(msgpackData))
+	if err != nil || !sameDocument(decoded, expected) {
+		t.Errorf("Expected the msgpack document to match JSON, got %v (%v)", decoded, err)
+	}
+	if len(msgpackData) >= len(jsonData) {
+		t.Errorf("Expected msgpack smaller than JSON, got %d and %d bytes", len(msgpackData), len(jsonData))
+	}
+
+	protobufData, err := encodeResponse(EncodingProtobuf, value)
+	if err != nil {
+		t.Fatalf("protobuf: %v", err)
+	}
+	decoded, err = decodeProtobufValue(protobufData)
+	if err != nil || !sameDocument(decoded, expected) {
+		t.Errorf("Expected the protobuf value to match JSON, got %v (%v)", decoded, err)
+	}
+}
+
+func TestEncodeResponseKeepsIntegers(t *testing.T) {
+	value := map[string]interface{}{"counter": int64(1<<53 + 1), "max": uint64(math.MaxUint64), "ratio": 1.0}
+	for _, tc := range []struct {
+		encoding string
+		decode   func([]byte) (interface{}, error)
+	}{
+		{EncodingMsgpack, func(data []byte) (interface{}, error) { return decodeMsgpack(bytes.NewReader(data)) }},
+		{EncodingProtobuf, decodeProtobufValue},
+	} {
+		data, err := encodeResponse(tc.encoding, value)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.encoding, err)
+		}
+		decoded, err := tc.decode(data)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.encoding, err)
+		}
+		document := decoded.(map[string]interface{})
+		if document["counter"] != int64(1<<53+1) || document["max"] != uint64(math.MaxUint64) || document["ratio"] != 1.0 {
+			t.Errorf("%s: expected exact integers and a float ratio, got %#v", tc.encoding, document)
+		}
+	}
+}
+
+func TestNegotiateResponseEncoding(t *testing.T) {
+	for name, tc := range map[string]struct {
+		url, accept, expected string
+	}{
+		"default":         {"/x", "", EncodingJSON},
+		"browser":         {"/x", "text/html,application/xhtml+xml,*/*;q=0.8", EncodingJSON},
+		"msgpack":         {"/x", "application/msgpack", EncodingMsgpack},
+		"x-msgpack":       {"/x", "application/x-msgpack, application/json;q=0.5", EncodingMsgpack},
+		"protobuf":        {"/x", "application/json;q=0.1, application/x-protobuf", EncodingProtobuf},
+		"format override": {"/x?format=msgpack", "application/json", EncodingMsgpack},
+	} {
+		request := httptest.NewRequest("GET", tc.url, nil)
+		request.Header.Set("Accept", tc.accept)
+		if encoding, err := negotiateResponseEncoding(request); err != nil || encoding != tc.expected {
+			t.Errorf("%s: expected %s, got %s (%v)", name, tc.expected, encoding, err)
+		}
+	}
+	if _, err := negotiateResponseEncoding(httptest.NewRequest("GET", "/x?format=xml", nil)); err == nil {
+		t.Error("Expected an unknown format rejected")
+	}
+}
+
+func TestHighVolumeEndpointsNegotiateEncoding(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{})
+	get := func(accept string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest("GET", "/api/v1/gpu/gpu-0/history", nil)
+		request.Header.Set("Accept", accept)
+		response := httptest.NewRecorder()
+		dashboard.server.Handler.ServeHTTP(response, request)
+		return response
+	}
+
+	msgpack := get("application/msgpack")
+	if msgpack.Code != http.StatusOK || msgpack.Header().Get("Content-Type") != "application/msgpack" {
+		t.Fatalf("Expected a msgpack response, got %d %v", msgpack.Code, msgpack.Header())
+	}
+	decoded, err := decodeMsgpack(bytes.NewReader(msgpack.Body.Bytes()))
+	if err != nil || decoded.(map[string]interface{})["gpu_id"] != "gpu-0" {
+		t.Errorf("Expected the history document, got %v (%v)", decoded, err)
+	}
+	if plain := get(""); plain.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected JSON by default, got %v", plain.Header())
+	}
+}
+
+func TestWebSocketMsgpackFrames(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{})
+	server := httptest.NewServer(dashboard.server.Handler)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws?encoding=msgpack", nil)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	messageType, frame, err := conn.ReadMessage()
+	if err != nil || messageType != websocket.BinaryMessage {
+		t.Fatalf("Expected a binary frame, got %d (%v)", messageType, err)
+	}
+	decoded, err := decodeMsgpack(bytes.NewReader(frame))
+	if err != nil || decoded.(map[string]interface{})["type"] != "metrics_update" {
+		t.Errorf("Expected the initial metrics update, got %v (%v)", decoded, err)
+	}
+}
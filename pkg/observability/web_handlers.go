@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
 	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v2"
 )
 
 // DashboardMetrics represents comprehensive dashboard metrics
@@ -19,6 +22,7 @@ type DashboardMetrics struct {
 	SystemStats SystemStats            `json:"system_stats"`
 	CostData    CostSummary            `json:"cost_data"`
 	Alerts      []Alert                `json:"alerts"`
+	Incidents   []Incident             `json:"incidents,omitempty"`
 	Performance PerformanceMetrics     `json:"performance"`
 }
 
@@ -32,6 +36,10 @@ type SystemStats struct {
 	AverageTemp     float64 `json:"average_temperature"`
 	TotalPowerWatts float64 `json:"total_power_watts"`
 	EfficiencyScore float64 `json:"efficiency_score"`
+
+	// Spread across GPUs, since averages hide skew
+	Utilization gpu.Distribution `json:"utilization_distribution"`
+	Memory      gpu.Distribution `json:"memory_distribution"`
 }
 
 // Alert represents an alert condition
@@ -40,6 +48,7 @@ type Alert struct {
 	Level     string    `json:"level"`
 	Message   string    `json:"message"`
 	Source    string    `json:"source"`
+	Tenants   []string  `json:"tenants,omitempty"` // Tenants running workloads on the source GPU
 	Timestamp time.Time `json:"timestamp"`
 }
 
@@ -61,35 +70,65 @@ type OptimizationTip struct {
 	Action  string  `json:"action"`
 }
 
-// handleDashboard serves the main dashboard HTML
+// handleDashboard serves the main dashboard HTML in the negotiated language
 func (wd *WebDashboard) handleDashboard(config WebDashboardConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("Vary", "Accept-Language, Cookie")
 
 		// Get the embedded dashboard HTML template
-		html := getDashboardHTML(config)
+		html := getDashboardHTML(config, requestDashboardLocale(r))
+		if wd.airGap != nil {
+			html = offlineHTML(html)
+		}
 		w.Write([]byte(html))
 	}
 }
 
-// handleHealth provides health check endpoint
+// handleHealth reports the health of every component, returning 503 with the
+// failing components listed when any of them is degraded
 func (wd *WebDashboard) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	components, failing := wd.CheckHealth()
 	health := map[string]interface{}{
-		"status":    "healthy",
-		"timestamp": time.Now(),
-		"version":   "1.0.0",
-		"components": map[string]string{
-			"monitoring_service": "healthy",
-			"metrics_collector":  "healthy",
-			"prometheus":         "healthy",
-		},
+		"status":     "healthy",
+		"timestamp":  time.Now(),
+		"version":    "1.0.0",
+		"components": components,
+	}
+	if len(failing) > 0 {
+		health["status"] = "degraded"
+		health["failing"] = failing
+		w.WriteHeader(http.StatusServiceUnavailable)
 	}
 
 	json.NewEncoder(w).Encode(health)
 }
 
+// handleLivez reports whether the process is running; it does not check
+// dependencies, so a failing component never causes a restart
+func (wd *WebDashboard) handleLivez(w http.ResponseWriter, r *http.Request) {
+	if wd.ctx.Err() != nil {
+		http.Error(w, "stopping", http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ok"))
+}
+
+// handleReadyz reports whether every component is healthy enough to serve traffic
+func (wd *WebDashboard) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	_, failing := wd.CheckHealth()
+	if wd.ctx.Err() != nil {
+		failing = append(failing, "dashboard")
+	}
+	if len(failing) > 0 {
+		http.Error(w, "not ready: "+strings.Join(failing, ", "), http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ok"))
+}
+
 // handleMetrics provides comprehensive metrics data
 func (wd *WebDashboard) handleMetrics(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -108,10 +147,11 @@ func (wd *WebDashboard) handleMetrics(w http.ResponseWriter, r *http.Request) {
 		SystemStats: wd.calculateSystemStats(),
 		CostData:    wd.lastCostData,
 		Alerts:      wd.getActiveAlerts(),
+		Incidents:   wd.getOpenIncidents(),
 		Performance: wd.calculatePerformanceMetrics(),
 	}
 
-	json.NewEncoder(w).Encode(metrics)
+	writeEncoded(w, r, metrics)
 }
 
 // handleGPUMetrics provides metrics for a specific GPU
@@ -142,6 +182,33 @@ func (wd *WebDashboard) handleSystemStats(w http.ResponseWriter, r *http.Request
 	json.NewEncoder(w).Encode(stats)
 }
 
+// handleBufferOccupancy reports monitoring service buffer sizes, limits and evictions
+func (wd *WebDashboard) handleBufferOccupancy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if wd.monitoringService == nil {
+		http.Error(w, "monitoring service not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	json.NewEncoder(w).Encode(wd.monitoringService.GetBufferOccupancy())
+}
+
+// handlePipelineStatus reports failures of the monitoring pipeline by source and reason
+func (wd *WebDashboard) handlePipelineStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	monitor := wd.pipelineMonitor
+	wd.mu.RUnlock()
+	if monitor == nil {
+		http.Error(w, "pipeline monitor not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	json.NewEncoder(w).Encode(monitor.Check(time.Now()))
+}
+
 // handleCosts provides cost information
 func (wd *WebDashboard) handleCosts(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -156,7 +223,7 @@ func (wd *WebDashboard) handleCosts(w http.ResponseWriter, r *http.Request) {
 func (wd *WebDashboard) handleAlerts(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	alerts := wd.getActiveAlerts()
+	alerts := wd.tenantAlerts(r, wd.getActiveAlerts())
 	json.NewEncoder(w).Encode(alerts)
 }
 
@@ -172,6 +239,99 @@ func (wd *WebDashboard) handleResolveAlert(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(map[string]string{"status": "resolved"})
 }
 
+// handleIncidents lists alert incidents; ?status=open (default), resolved or all
+func (wd *WebDashboard) handleIncidents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	grouper := wd.alertGrouper
+	wd.mu.RUnlock()
+	if grouper == nil {
+		http.Error(w, "alert grouping not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = IncidentOpen
+	}
+	if status != IncidentOpen && status != IncidentResolved && status != "all" {
+		http.Error(w, "status must be open, resolved or all", http.StatusBadRequest)
+		return
+	}
+
+	incidents := make([]Incident, 0)
+	for _, incident := range grouper.Incidents(status != IncidentOpen) {
+		if status == "all" || incident.Status == status {
+			incidents = append(incidents, incident)
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"incidents": incidents,
+		"count":     len(incidents),
+		"stats":     grouper.GetStats(),
+	})
+}
+
+// handleIncident returns a single incident with its child alerts
+func (wd *WebDashboard) handleIncident(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	grouper := wd.alertGrouper
+	wd.mu.RUnlock()
+	if grouper == nil {
+		http.Error(w, "alert grouping not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	incident, exists := grouper.GetIncident(mux.Vars(r)["id"])
+	if !exists {
+		http.Error(w, "Incident not found", http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(incident)
+}
+
+// handleIncidentTimeline exports an incident's timeline; ?format=json (default) or markdown
+func (wd *WebDashboard) handleIncidentTimeline(w http.ResponseWriter, r *http.Request) {
+	wd.mu.RLock()
+	builder := wd.timelineBuilder
+	if builder == nil && wd.alertGrouper != nil {
+		builder = NewIncidentTimelineBuilder(DefaultTimelineConfig(), wd.monitoringService, wd.alertGrouper, nil)
+	}
+	wd.mu.RUnlock()
+	if builder == nil {
+		http.Error(w, "alert grouping not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = TimelineFormatJSON
+	}
+
+	timeline, err := builder.Build(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	data, err := timeline.Export(format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if format == TimelineFormatMarkdown {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", timeline.Incident.ID+".md"))
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.Write(data)
+}
+
 // handlePerformance provides performance analytics
 func (wd *WebDashboard) handlePerformance(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -204,6 +364,7 @@ func (wd *WebDashboard) calculateSystemStats() SystemStats {
 
 	avgUtil := totalUtil / float64(totalGPUs)
 	efficiencyScore := calculateEfficiencyScore(avgUtil, totalTemp/float64(totalGPUs))
+	utilization, memory := clusterDistributions(wd.lastMetrics)
 
 	return SystemStats{
 		TotalGPUs:       totalGPUs,
@@ -214,6 +375,8 @@ func (wd *WebDashboard) calculateSystemStats() SystemStats {
 		AverageTemp:     totalTemp / float64(totalGPUs),
 		TotalPowerWatts: totalPower,
 		EfficiencyScore: efficiencyScore,
+		Utilization:     utilization,
+		Memory:          memory,
 	}
 }
 
@@ -239,6 +402,15 @@ func calculateEfficiencyScore(avgUtil, avgTemp float64) float64 {
 }
 
 // getActiveAlerts generates sample alerts based on current metrics
+// getOpenIncidents returns open incidents when alert grouping is configured;
+// callers must hold wd.mu
+func (wd *WebDashboard) getOpenIncidents() []Incident {
+	if wd.alertGrouper == nil {
+		return nil
+	}
+	return wd.alertGrouper.Incidents(false)
+}
+
 func (wd *WebDashboard) getActiveAlerts() []Alert {
 	var alerts []Alert
 
@@ -297,13 +469,14 @@ func (wd *WebDashboard) calculatePerformanceMetrics() PerformanceMetrics {
 		avgUtil /= float64(totalGPUs)
 	}
 
-	if avgUtil < 50 {
+	plan := wd.buildCostPlan()
+	for _, action := range plan.Actions {
 		tips = append(tips, OptimizationTip{
-			Type:    "efficiency",
-			Message: "GPU utilization is below 50%. Consider consolidating workloads.",
-			Impact:  "high",
-			Savings: wd.lastCostData.TotalCost * 0.3,
-			Action:  "Redistribute workloads to fewer GPUs",
+			Type:    action.Type,
+			Message: action.Title,
+			Impact:  savingsImpact(action.EstimatedSavings, plan.ForecastCost),
+			Savings: action.EstimatedSavings,
+			Action:  action.Description,
 		})
 	}
 
@@ -320,12 +493,18 @@ func (wd *WebDashboard) calculatePerformanceMetrics() PerformanceMetrics {
 func (wd *WebDashboard) handleCostSummary(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	locale, err := wd.reportLocale(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	wd.mu.RLock()
 	defer wd.mu.RUnlock()
 
 	summary := map[string]interface{}{
 		"current_period":         wd.lastCostData,
-		"daily_breakdown":        wd.calculateDailyCostBreakdown(),
+		"daily_breakdown":        wd.calculateDailyCostBreakdown(locale.Location()),
 		"cost_by_gpu":            wd.calculateCostByGPU(),
 		"optimization_potential": wd.calculateOptimizationPotential(),
 	}
@@ -348,11 +527,273 @@ func (wd *WebDashboard) handleCostForecast(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(forecast)
 }
 
+// handleCostPlan returns the ordered cost action plan with per-action savings and risk
+func (wd *WebDashboard) handleCostPlan(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	plan := wd.buildCostPlan()
+	wd.mu.RUnlock()
+
+	json.NewEncoder(w).Encode(plan)
+}
+
+// handleCostWhatIf replays the last "hours" of GPU metrics (default 168) under
+// the scenarios in the request body, e.g. {"scenarios": [{"name": "spot",
+// "spot_fraction": 0.5, "spot_discount": 0.6}]}
+func (wd *WebDashboard) handleCostWhatIf(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if wd.metricsCollector == nil {
+		http.Error(w, "metrics collector not configured", http.StatusServiceUnavailable)
+		return
+	}
+	var request struct {
+		Hours     int              `json:"hours"`
+		Scenarios []WhatIfScenario `json:"scenarios"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(request.Scenarios) == 0 {
+		http.Error(w, "at least one scenario is required", http.StatusBadRequest)
+		return
+	}
+	for _, scenario := range request.Scenarios {
+		if err := scenario.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	hours := request.Hours
+	if hours <= 0 {
+		hours = 168
+	}
+
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+	history := make(map[string][]gpu.GPUMetrics)
+	for gpuID := range wd.metricsCollector.GetLatestMetrics() {
+		history[gpuID] = wd.metricsCollector.GetMetricsHistory(gpuID, since)
+	}
+
+	wd.mu.RLock()
+	pricing := wd.costConfig
+	wd.mu.RUnlock()
+
+	json.NewEncoder(w).Encode(SimulateCosts(history, pricing, request.Scenarios))
+}
+
+// handleShowback reports marketplace charges, provider cost and margin per
+// team for ?month=YYYY-MM, defaulting to the current month. Months start at
+// midnight in the report time zone; ?format=markdown renders a statement
+// in the report locale.
+func (wd *WebDashboard) handleShowback(w http.ResponseWriter, r *http.Request) {
+	locale, err := wd.reportLocale(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "markdown" {
+		http.Error(w, "format must be json or markdown", http.StatusBadRequest)
+		return
+	}
+
+	month := time.Now().In(locale.Location())
+	if value := r.URL.Query().Get("month"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01", value, locale.Location())
+		if err != nil {
+			http.Error(w, "invalid month, expected YYYY-MM", http.StatusBadRequest)
+			return
+		}
+		month = parsed
+	}
+
+	wd.mu.RLock()
+	scheduler := wd.scheduler
+	pricing := wd.costConfig
+	config := wd.showback
+	wd.mu.RUnlock()
+	if scheduler == nil {
+		http.Error(w, "scheduler not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	records := make([]gpu.UsageRecord, 0)
+	for _, record := range scheduler.GetUsageRecords(MonthStart(month)) {
+		if inTenant(r, record.Tenant) {
+			records = append(records, record)
+		}
+	}
+	report := BuildShowbackReport(records, pricing, config, month)
+	if format == "markdown" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "showback-"+report.Month+".md"))
+		w.Write([]byte(report.Markdown(locale)))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// handleCostBreakdown totals the caller's tenant's costs by calendar ?period
+// (day, the default, or month) in the report time zone, between ?start and
+// ?end (RFC 3339) or over the last 30 days or 12 months
+func (wd *WebDashboard) handleCostBreakdown(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	locale, err := wd.reportLocale(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	period := query.Get("period")
+	if period == "" {
+		period = CostPeriodDay
+	}
+	end := time.Now().In(locale.Location())
+	start := periodStart(end, CostPeriodDay).AddDate(0, 0, -29)
+	if period == CostPeriodMonth {
+		start = MonthStart(end).AddDate(0, -11, 0)
+	}
+	window, err := parseWindow(query, "", TimeWindow{Start: start, End: end})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tenant := ""
+	if scope, scoped := r.Context().Value(tenantScopeKey{}).(tenantScope); scoped {
+		tenant = scope.tenant
+	}
+	buckets, err := wd.monitoringService.GetCostBuckets(window.Start, window.End, tenant, period, locale)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	wd.mu.RLock()
+	currency := wd.costConfig.Currency
+	wd.mu.RUnlock()
+	var total float64
+	for i := range buckets {
+		buckets[i].FormattedCost = locale.FormatCurrency(buckets[i].Cost, currency)
+		total += buckets[i].Cost
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"period":          period,
+		"time_zone":       locale.ZoneName(),
+		"locale":          locale.Locale,
+		"currency":        currency,
+		"buckets":         buckets,
+		"total":           total,
+		"formatted_total": locale.FormatCurrency(total, currency),
+	})
+}
+
+// handleLayout exports the dashboard layout config as JSON, or as YAML with
+// ?format=yaml for committing to version control
+func (wd *WebDashboard) handleLayout(w http.ResponseWriter, r *http.Request) {
+	wd.mu.RLock()
+	layout := wd.layout
+	wd.mu.RUnlock()
+
+	switch r.URL.Query().Get("format") {
+	case "", ConfigFormatJSON:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(layout)
+	case ConfigFormatYAML:
+		data, err := yaml.Marshal(layout)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-yaml")
+		w.Header().Set("Content-Disposition", `attachment; filename="dashboard-layout.yaml"`)
+		w.Write(data)
+	default:
+		http.Error(w, "format must be json or yaml", http.StatusBadRequest)
+	}
+}
+
+// handleLayoutRender returns the layout the dashboard page renders for ?pool
+func (wd *WebDashboard) handleLayoutRender(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	layout := wd.layout.ForPool(r.URL.Query().Get("pool"))
+	wd.mu.RUnlock()
+
+	json.NewEncoder(w).Encode(layout)
+}
+
+// buildCostPlan profiles every GPU from its recent metrics history and the
+// workloads the scheduler runs on it; callers must hold wd.mu
+func (wd *WebDashboard) buildCostPlan() CostActionPlan {
+	now := time.Now()
+	interruptible := make(map[string]bool)
+	if wd.scheduler != nil {
+		for _, workload := range wd.scheduler.ListWorkloads() {
+			if workload.AssignedGPU == "" {
+				continue
+			}
+			isTraining := workload.Class == gpu.WorkloadClassTraining
+			previous, seen := interruptible[workload.AssignedGPU]
+			interruptible[workload.AssignedGPU] = isTraining && (!seen || previous)
+		}
+	}
+
+	profiles := make([]GPUCostProfile, 0, len(wd.lastMetrics))
+	for gpuID, latest := range wd.lastMetrics {
+		history := []gpu.GPUMetrics{latest}
+		if wd.metricsCollector != nil {
+			if recent := wd.metricsCollector.GetMetricsHistory(gpuID, now.Add(-wd.costOptimizer.Lookback)); len(recent) > 0 {
+				history = recent
+			}
+		}
+
+		profile := GPUCostProfile{
+			GPUID:         gpuID,
+			GPUType:       normalizeGPUType(latest.Name),
+			MemoryTotalMB: latest.MemoryTotal,
+			Interruptible: interruptible[gpuID],
+		}
+		profile.CostPerHour = wd.costConfig.hourlyCost(profile.GPUType)
+		for _, metrics := range history {
+			profile.AverageUtilization += metrics.UtilizationGPU
+			if metrics.MemoryUsed > profile.PeakMemoryMB {
+				profile.PeakMemoryMB = metrics.MemoryUsed
+			}
+		}
+		profile.AverageUtilization /= float64(len(history))
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].GPUID < profiles[j].GPUID
+	})
+
+	return BuildCostActionPlan(profiles, wd.costConfig, wd.costOptimizer, now)
+}
+
+// savingsImpact rates an action by its share of the forecast cost
+func savingsImpact(savings, forecast float64) string {
+	switch {
+	case forecast > 0 && savings >= forecast*0.3:
+		return "high"
+	case forecast > 0 && savings >= forecast*0.1:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
 // handleAlertSummary provides alert summary information
 func (wd *WebDashboard) handleAlertSummary(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	alerts := wd.getActiveAlerts()
+	alerts := wd.tenantAlerts(r, wd.getActiveAlerts())
 
 	summary := map[string]interface{}{
 		"total_alerts":   len(alerts),
@@ -378,6 +819,15 @@ func (wd *WebDashboard) handleEfficiency(w http.ResponseWriter, r *http.Request)
 		"thermal_efficiency": wd.calculateThermalEfficiency(),
 		"recommendations":    wd.generateEfficiencyRecommendations(),
 	}
+	if performances := wd.compareHardware(); performances != nil {
+		expected := make(map[string]float64)
+		for _, perf := range performances {
+			if perf.Judged {
+				expected[perf.GPUID] = perf.PercentOfExpected
+			}
+		}
+		efficiency["expected_throughput_percent"] = expected
+	}
 
 	json.NewEncoder(w).Encode(efficiency)
 }
@@ -398,17 +848,32 @@ func (wd *WebDashboard) handleTrends(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(trends)
 }
 
-// handleGPUList provides list of all available GPUs
+// handleGPUList provides list of all available GPUs, optionally filtered by
+// scheduler labels with ?selector=key=value,...
 func (wd *WebDashboard) handleGPUList(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	selector, err := gpu.ParseSelector(r.URL.Query().Get("selector"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	wd.mu.RLock()
 	defer wd.mu.RUnlock()
 
 	gpus := make([]map[string]interface{}, 0)
 
 	for gpuID, metrics := range wd.lastMetrics {
-		gpu := map[string]interface{}{
+		var labels map[string]string
+		if wd.scheduler != nil {
+			labels = wd.scheduler.GPULabels(gpuID)
+		}
+		if !gpu.MatchesSelector(labels, selector) {
+			continue
+		}
+
+		info := map[string]interface{}{
 			"id":           gpuID,
 			"name":         metrics.Name,
 			"status":       wd.getGPUStatus(metrics),
@@ -418,17 +883,80 @@ func (wd *WebDashboard) handleGPUList(w http.ResponseWriter, r *http.Request) {
 			"memory_used":  metrics.MemoryUsed,
 			"power_draw":   metrics.PowerDraw,
 			"last_updated": metrics.Timestamp,
+			"labels":       labels,
 		}
-		gpus = append(gpus, gpu)
+		gpus = append(gpus, info)
 	}
 
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	writeEncoded(w, r, map[string]interface{}{
 		"gpus":      gpus,
 		"total":     len(gpus),
 		"timestamp": time.Now(),
 	})
 }
 
+// handleNodes lists per-node power, utilization and health, sorted by node ID
+func (wd *WebDashboard) handleNodes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	nodes := gpu.AggregateNodes(wd.lastMetrics, time.Now())
+	wd.mu.RUnlock()
+
+	list := make([]gpu.NodeStats, 0, len(nodes))
+	for _, node := range nodes {
+		list = append(list, node)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].NodeID < list[j].NodeID
+	})
+
+	writeEncoded(w, r, map[string]interface{}{
+		"nodes":     list,
+		"total":     len(list),
+		"timestamp": time.Now(),
+	})
+}
+
+// handleNode returns one node's aggregated stats
+func (wd *WebDashboard) handleNode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	node, exists := gpu.AggregateNodes(wd.lastMetrics, time.Now())[mux.Vars(r)["id"]]
+	wd.mu.RUnlock()
+
+	if !exists {
+		http.Error(w, "node not found", http.StatusNotFound)
+		return
+	}
+	writeEncoded(w, r, node)
+}
+
+// handleNodeGPUs returns the latest metrics of each GPU on a node
+func (wd *WebDashboard) handleNodeGPUs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	nodeID := mux.Vars(r)["id"]
+	wd.mu.RLock()
+	node, exists := gpu.AggregateNodes(wd.lastMetrics, time.Now())[nodeID]
+	gpus := make([]gpu.GPUMetrics, 0, len(node.GPUIDs))
+	for _, gpuID := range node.GPUIDs {
+		gpus = append(gpus, wd.lastMetrics[gpuID])
+	}
+	wd.mu.RUnlock()
+
+	if !exists {
+		http.Error(w, "node not found", http.StatusNotFound)
+		return
+	}
+	writeEncoded(w, r, map[string]interface{}{
+		"node_id": nodeID,
+		"gpus":    gpus,
+		"total":   len(gpus),
+	})
+}
+
 // handleGPUProcesses provides processes running on a specific GPU
 func (wd *WebDashboard) handleGPUProcesses(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -486,12 +1014,159 @@ func (wd *WebDashboard) handleGPUHistory(w http.ResponseWriter, r *http.Request)
 		},
 	}
 
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	response := map[string]interface{}{
 		"gpu_id":  gpuID,
 		"since":   since,
 		"history": history,
 		"count":   len(history),
+	}
+	if annotations := wd.annotationsBetween(since, time.Now()); annotations != nil {
+		response["annotations"] = annotations
+	}
+	writeEncoded(w, r, response)
+}
+
+// handleWorkloads lists scheduler workloads with their effective priority,
+// optionally filtered by ?status, ?pool and by workload labels with ?selector
+func (wd *WebDashboard) handleWorkloads(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	scheduler := wd.scheduler
+	wd.mu.RUnlock()
+	if scheduler == nil {
+		http.Error(w, "scheduler not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	status := gpu.WorkloadStatus(r.URL.Query().Get("status"))
+	pool, filterPool := r.URL.Query()["pool"]
+	selector, err := gpu.ParseSelector(r.URL.Query().Get("selector"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	workloads := make([]gpu.WorkloadInfo, 0)
+	for _, workload := range scheduler.ListWorkloads() {
+		if status != "" && workload.Status != status {
+			continue
+		}
+		if filterPool && workload.Pool != pool[0] {
+			continue
+		}
+		if !inTenant(r, workload.Tenant) {
+			continue
+		}
+		if gpu.MatchesSelector(workload.Labels, selector) {
+			workloads = append(workloads, workload)
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"workloads": workloads,
+		"count":     len(workloads),
+	})
+}
+
+// handlePools summarizes each GPU pool's capacity, usage and quota
+func (wd *WebDashboard) handlePools(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	scheduler := wd.scheduler
+	wd.mu.RUnlock()
+	if scheduler == nil {
+		http.Error(w, "scheduler not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	pools := scheduler.GetPools()
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"pools": pools,
+		"count": len(pools),
+	})
+}
+
+// handleEnergyReport compares energy-aware placements with least-utilized ones
+func (wd *WebDashboard) handleEnergyReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	scheduler := wd.scheduler
+	wd.mu.RUnlock()
+	if scheduler == nil {
+		http.Error(w, "scheduler not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	json.NewEncoder(w).Encode(scheduler.GetEnergyReport())
+}
+
+// handleTariffReport reports the savings of deferring workloads to cheaper electricity
+func (wd *WebDashboard) handleTariffReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	scheduler := wd.scheduler
+	wd.mu.RUnlock()
+	if scheduler == nil {
+		http.Error(w, "scheduler not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	json.NewEncoder(w).Encode(scheduler.GetTariffReport())
+}
+
+// handleHeatmap returns a GPU×time matrix for the last ?hours (default 6) at
+// ?resolution (default 5m) of ?metric (default utilization)
+func (wd *WebDashboard) handleHeatmap(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if wd.monitoringService == nil {
+		http.Error(w, "monitoring service not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+	hours := 6
+	if h, err := strconv.Atoi(query.Get("hours")); err == nil && h > 0 {
+		hours = h
+	}
+	resolution := 5 * time.Minute
+	if value := query.Get("resolution"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			http.Error(w, "invalid resolution: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		resolution = parsed
+	}
+	underutilizedBelow := DefaultGPUAlertThresholds().LowUtilization
+	if value := query.Get("underutilized_below"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			http.Error(w, "invalid underutilized_below: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		underutilizedBelow = parsed
+	}
+
+	end := time.Now()
+	heatmap, err := wd.monitoringService.BuildHeatmap(HeatmapQuery{
+		Metric:             query.Get("metric"),
+		Start:              end.Add(-time.Duration(hours) * time.Hour),
+		End:                end,
+		Resolution:         resolution,
+		UnderutilizedBelow: underutilizedBelow,
 	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	heatmap.Annotations = wd.annotationsBetween(heatmap.Start, heatmap.End)
+	writeEncoded(w, r, heatmap)
 }
 
 // handleSystemOverview provides comprehensive system overview
@@ -587,12 +1262,13 @@ func (wd *WebDashboard) handleSimulationSpeed(w http.ResponseWriter, r *http.Req
 
 // Helper methods for calculations
 
-func (wd *WebDashboard) calculateDailyCostBreakdown() []map[string]interface{} {
-	// Mock daily cost data
+func (wd *WebDashboard) calculateDailyCostBreakdown(location *time.Location) []map[string]interface{} {
+	// Mock daily cost data, dated in the report time zone
+	now := time.Now().In(location)
 	return []map[string]interface{}{
-		{"date": time.Now().AddDate(0, 0, -2).Format("2006-01-02"), "cost": 124.50},
-		{"date": time.Now().AddDate(0, 0, -1).Format("2006-01-02"), "cost": 132.75},
-		{"date": time.Now().Format("2006-01-02"), "cost": 98.25},
+		{"date": now.AddDate(0, 0, -2).Format("2006-01-02"), "cost": 124.50},
+		{"date": now.AddDate(0, 0, -1).Format("2006-01-02"), "cost": 132.75},
+		{"date": now.Format("2006-01-02"), "cost": 98.25},
 	}
 }
 
@@ -686,6 +1362,9 @@ func (wd *WebDashboard) calculateMemoryEfficiency() float64 {
 func (wd *WebDashboard) calculateThermalEfficiency() float64 {
 	totalTemp := 0.0
 	count := float64(len(wd.lastMetrics))
+	if count == 0 {
+		return 0
+	}
 
 	for _, metrics := range wd.lastMetrics {
 		totalTemp += metrics.Temperature
@@ -700,27 +1379,38 @@ func (wd *WebDashboard) calculateThermalEfficiency() float64 {
 func (wd *WebDashboard) generateEfficiencyRecommendations() []map[string]interface{} {
 	recommendations := []map[string]interface{}{}
 
-	avgUtil := 0.0
 	avgTemp := 0.0
 	count := float64(len(wd.lastMetrics))
 
 	for _, metrics := range wd.lastMetrics {
-		avgUtil += metrics.UtilizationGPU
 		avgTemp += metrics.Temperature
 	}
 
 	if count > 0 {
-		avgUtil /= count
 		avgTemp /= count
 	}
 
-	if avgUtil < 50 {
+	plan := wd.buildCostPlan()
+	for _, action := range plan.Actions {
+		recommendations = append(recommendations, map[string]interface{}{
+			"type":        action.Type,
+			"priority":    savingsImpact(action.EstimatedSavings, plan.ForecastCost),
+			"title":       action.Title,
+			"description": action.Description,
+			"impact":      fmt.Sprintf("%.2f %s over %.0f days, %s risk", action.EstimatedSavings, plan.Currency, plan.HorizonHours/24, action.Risk),
+		})
+	}
+
+	for _, perf := range wd.compareHardware() {
+		if !perf.Underperforming {
+			continue
+		}
 		recommendations = append(recommendations, map[string]interface{}{
-			"type":        "utilization",
+			"type":        "performance",
 			"priority":    "high",
-			"title":       "Low GPU Utilization",
-			"description": "Consider consolidating workloads to improve efficiency",
-			"impact":      "30% cost reduction",
+			"title":       fmt.Sprintf("GPU %s Underperforming", perf.GPUID),
+			"description": fmt.Sprintf("%s is %s; check cooling, power limits and clock throttling", perf.Model, perf.Reason),
+			"impact":      fmt.Sprintf("Restores the throughput expected of a %s", perf.Baseline),
 		})
 	}
 
@@ -0,0 +1,182 @@
+package observability
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Component health statuses
+const (
+	ComponentHealthy  = "healthy"
+	ComponentFailing  = "failing"
+	ComponentDisabled = "disabled"
+)
+
+// HealthConfig configures how stale a component may be before it is failing
+type HealthConfig struct {
+	CollectorMaxAge  time.Duration `yaml:"collector_max_age" json:"collector_max_age"`   // Newest GPU metrics must be younger than this
+	BroadcastMaxAge  time.Duration `yaml:"broadcast_max_age" json:"broadcast_max_age"`   // WebSocket broadcast loop must have ticked within this
+	PrometheusMaxAge time.Duration `yaml:"prometheus_max_age" json:"prometheus_max_age"` // Exporter must have been synced or updated within this
+}
+
+// DefaultHealthConfig returns default health check thresholds
+func DefaultHealthConfig() HealthConfig {
+	return HealthConfig{
+		CollectorMaxAge:  30 * time.Second,
+		BroadcastMaxAge:  10 * time.Second,
+		PrometheusMaxAge: 2 * time.Minute,
+	}
+}
+
+// HealthCheck reports a component's health; a non-nil error marks it failing
+type HealthCheck func() error
+
+// ComponentHealth is the result of checking one component
+type ComponentHealth struct {
+	Status      string     `json:"status"`
+	Message     string     `json:"message,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+}
+
+// Heartbeat tracks the liveness of a periodic loop such as a scheduler.
+// The loop calls Beat on every iteration and Check fails once beats stop.
+type Heartbeat struct {
+	maxAge time.Duration
+	last   time.Time
+	mu     sync.RWMutex
+}
+
+// NewHeartbeat creates a heartbeat that fails after maxAge without a beat
+func NewHeartbeat(maxAge time.Duration) *Heartbeat {
+	return &Heartbeat{maxAge: maxAge, last: time.Now()}
+}
+
+// Beat records that the loop is alive
+func (h *Heartbeat) Beat() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.last = time.Now()
+}
+
+// LastBeat returns the time of the most recent beat
+func (h *Heartbeat) LastBeat() time.Time {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.last
+}
+
+// Check fails if the loop has not beaten within maxAge
+func (h *Heartbeat) Check() error {
+	if age := time.Since(h.LastBeat()); age > h.maxAge {
+		return fmt.Errorf("no heartbeat for %s", age.Round(time.Second))
+	}
+	return nil
+}
+
+// RegisterHealthCheck adds a named component check, such as a scheduler loop
+// heartbeat or snapshot storage, to the health and readiness endpoints
+func (wd *WebDashboard) RegisterHealthCheck(name string, check HealthCheck) {
+	wd.healthMu.Lock()
+	defer wd.healthMu.Unlock()
+	wd.healthChecks[name] = check
+}
+
+// CheckHealth checks every component and returns their health along with the
+// sorted names of failing components
+func (wd *WebDashboard) CheckHealth() (map[string]ComponentHealth, []string) {
+	components := map[string]ComponentHealth{
+		"monitoring_service": wd.checkMonitoringService(),
+		"metrics_collector":  wd.checkCollector(),
+		"websocket_hub":      wd.checkWebSocketHub(),
+		"prometheus":         wd.checkPrometheus(),
+	}
+
+	wd.healthMu.RLock()
+	checks := make(map[string]HealthCheck, len(wd.healthChecks))
+	for name, check := range wd.healthChecks {
+		checks[name] = check
+	}
+	wd.healthMu.RUnlock()
+
+	for name, check := range checks {
+		if err := check(); err != nil {
+			components[name] = ComponentHealth{Status: ComponentFailing, Message: err.Error()}
+		} else {
+			components[name] = ComponentHealth{Status: ComponentHealthy}
+		}
+	}
+
+	failing := make([]string, 0)
+	for name, health := range components {
+		if health.Status == ComponentFailing {
+			failing = append(failing, name)
+		}
+	}
+	sort.Strings(failing)
+
+	return components, failing
+}
+
+// checkMonitoringService verifies the monitoring service is attached
+func (wd *WebDashboard) checkMonitoringService() ComponentHealth {
+	if wd.monitoringService == nil {
+		return ComponentHealth{Status: ComponentFailing, Message: "monitoring service not configured"}
+	}
+	return ComponentHealth{Status: ComponentHealthy}
+}
+
+// checkCollector verifies the collector has produced recent GPU metrics
+func (wd *WebDashboard) checkCollector() ComponentHealth {
+	if wd.metricsCollector == nil {
+		return ComponentHealth{Status: ComponentDisabled}
+	}
+
+	var last time.Time
+	for _, metrics := range wd.metricsCollector.GetLatestMetrics() {
+		if metrics.Timestamp.After(last) {
+			last = metrics.Timestamp
+		}
+	}
+	return wd.checkAge(last, wd.healthConfig.CollectorMaxAge, "no GPU metrics collected")
+}
+
+// checkWebSocketHub verifies the broadcast loop is running
+func (wd *WebDashboard) checkWebSocketHub() ComponentHealth {
+	if !wd.enableRealTimeUpdates {
+		return ComponentHealth{Status: ComponentDisabled}
+	}
+	if wd.ctx.Err() != nil {
+		return ComponentHealth{Status: ComponentFailing, Message: "dashboard stopped"}
+	}
+	return wd.checkAge(wd.broadcastHeartbeat.LastBeat(), wd.healthConfig.BroadcastMaxAge, "broadcast loop stalled")
+}
+
+// checkPrometheus verifies the exporter is receiving data
+func (wd *WebDashboard) checkPrometheus() ComponentHealth {
+	if wd.prometheusExporter == nil {
+		return ComponentHealth{Status: ComponentDisabled}
+	}
+	return wd.checkAge(wd.prometheusExporter.LastUpdate(), wd.healthConfig.PrometheusMaxAge, "exporter not updated")
+}
+
+// checkAge fails a component whose last success is older than maxAge. A
+// component that has never succeeded gets maxAge from dashboard startup.
+func (wd *WebDashboard) checkAge(last time.Time, maxAge time.Duration, failure string) ComponentHealth {
+	health := ComponentHealth{Status: ComponentHealthy}
+
+	since := last
+	if since.IsZero() {
+		since = wd.createdAt
+	} else {
+		health.LastSuccess = &last
+	}
+	if maxAge > 0 {
+		if age := time.Since(since); age > maxAge {
+			health.Status = ComponentFailing
+			health.Message = fmt.Sprintf("%s for %s", failure, age.Round(time.Second))
+		}
+	}
+	return health
+}
@@ -0,0 +1,102 @@
+package observability
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// serveDashboard sends a request through the dashboard router
+func serveDashboard(wd *WebDashboard, method, path, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	wd.server.Handler.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return recorder
+}
+
+func TestHealthEndpointsReportFailingComponents(t *testing.T) {
+	exporter := NewPrometheusExporter(nil, DefaultPrometheusConfig())
+	exporter.RegisterSystemMetrics()
+	exporter.SetGauge("cluster_utilization_percent", 50, nil)
+
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, exporter, WebDashboardConfig{Port: 0})
+
+	response := serveDashboard(dashboard, http.MethodGet, "/health", "")
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected healthy dashboard, got %d: %s", response.Code, response.Body.String())
+	}
+
+	scheduler := NewHeartbeat(time.Millisecond)
+	dashboard.RegisterHealthCheck("scheduler", scheduler.Check)
+	dashboard.RegisterHealthCheck("storage", func() error { return errors.New("disk full") })
+	time.Sleep(5 * time.Millisecond)
+
+	response = serveDashboard(dashboard, http.MethodGet, "/health", "")
+	if response.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503 for failing components, got %d", response.Code)
+	}
+
+	var health struct {
+		Status     string                     `json:"status"`
+		Failing    []string                   `json:"failing"`
+		Components map[string]ComponentHealth `json:"components"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&health); err != nil {
+		t.Fatalf("Failed to decode health: %v", err)
+	}
+	if health.Status != "degraded" || strings.Join(health.Failing, ",") != "scheduler,storage" {
+		t.Errorf("Expected scheduler and storage failing, got %s %v", health.Status, health.Failing)
+	}
+	if health.Components["storage"].Message != "disk full" {
+		t.Errorf("Expected failure message, got %+v", health.Components["storage"])
+	}
+	if health.Components["prometheus"].Status != ComponentHealthy || health.Components["prometheus"].LastSuccess == nil {
+		t.Errorf("Expected recently updated exporter to be healthy, got %+v", health.Components["prometheus"])
+	}
+
+	if response := serveDashboard(dashboard, http.MethodGet, "/readyz", ""); response.Code != http.StatusServiceUnavailable ||
+		!strings.Contains(response.Body.String(), "scheduler, storage") {
+		t.Errorf("Expected readyz to fail listing components, got %d: %s", response.Code, response.Body.String())
+	}
+	if response := serveDashboard(dashboard, http.MethodGet, "/livez", ""); response.Code != http.StatusOK {
+		t.Errorf("Expected livez to ignore component health, got %d", response.Code)
+	}
+
+	scheduler.Beat()
+	dashboard.RegisterHealthCheck("storage", func() error { return nil })
+	if response := serveDashboard(dashboard, http.MethodGet, "/readyz", ""); response.Code != http.StatusOK {
+		t.Errorf("Expected readyz to recover, got %d: %s", response.Code, response.Body.String())
+	}
+}
+
+func TestHealthDetectsStaleCollectorAndStoppedDashboard(t *testing.T) {
+	config := WebDashboardConfig{
+		Port:                  0,
+		EnableRealTimeUpdates: true,
+		Health:                HealthConfig{CollectorMaxAge: time.Millisecond, BroadcastMaxAge: time.Minute},
+	}
+	collector := gpu.NewMockMetricsCollector(time.Second, 1)
+	dashboard := NewWebDashboard(NewMonitoringService(100), collector, nil, config)
+	time.Sleep(5 * time.Millisecond)
+
+	components, failing := dashboard.CheckHealth()
+	if strings.Join(failing, ",") != "metrics_collector" {
+		t.Errorf("Expected only the idle collector to fail, got %v (%+v)", failing, components)
+	}
+	if components["prometheus"].Status != ComponentDisabled {
+		t.Errorf("Expected prometheus check disabled without an exporter, got %+v", components["prometheus"])
+	}
+
+	dashboard.Stop()
+	if response := serveDashboard(dashboard, http.MethodGet, "/livez", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected livez to fail once stopped, got %d", response.Code)
+	}
+	if _, failing := dashboard.CheckHealth(); !strings.Contains(strings.Join(failing, ","), "websocket_hub") {
+		t.Errorf("Expected websocket hub failing once stopped, got %v", failing)
+	}
+}
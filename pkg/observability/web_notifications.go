@@ -0,0 +1,71 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// getNotificationPreferences returns the preference store, or reports 503 when none is set
+func (wd *WebDashboard) getNotificationPreferences(w http.ResponseWriter) *NotificationPreferenceStore {
+	wd.mu.RLock()
+	store := wd.notificationPrefs
+	wd.mu.RUnlock()
+	if store == nil {
+		http.Error(w, "notification preferences not configured", http.StatusServiceUnavailable)
+	}
+	return store
+}
+
+// handleGetNotificationPreferences returns the authenticated user's preferences
+func (wd *WebDashboard) handleGetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	store := wd.getNotificationPreferences(w)
+	if store == nil {
+		return
+	}
+	json.NewEncoder(w).Encode(store.Get(controlActor(r)))
+}
+
+// handleSetNotificationPreferences replaces the authenticated user's preferences
+func (wd *WebDashboard) handleSetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	store := wd.getNotificationPreferences(w)
+	if store == nil {
+		return
+	}
+	var preferences NotificationPreferences
+	if err := json.NewDecoder(r.Body).Decode(&preferences); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Users can only change their own preferences
+	preferences.User = controlActor(r)
+
+	if err := preferences.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := store.Set(preferences); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(preferences)
+}
+
+// handleResetNotificationPreferences restores the authenticated user's default preferences
+func (wd *WebDashboard) handleResetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	store := wd.getNotificationPreferences(w)
+	if store == nil {
+		return
+	}
+	user := controlActor(r)
+	if _, err := store.Delete(user); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(store.Get(user))
+}
@@ -0,0 +1,113 @@
+package observability
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestNotificationPreferencesAPI(t *testing.T) {
+	request := func(wd *WebDashboard, method, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/notifications/preferences", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		wd.server.Handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{
+		Port:          0,
+		ControlTokens: map[string]string{"s3cret": "alice"},
+	})
+	if response := request(dashboard, "GET", "s3cret", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a preference store, got %d", response.Code)
+	}
+	store, _ := NewNotificationPreferenceStore("")
+	dashboard.SetNotificationPreferences(store)
+
+	if response := request(dashboard, "GET", "", ""); response.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", response.Code)
+	}
+
+	// The user comes from the token, not the body
+	response := request(dashboard, "PUT", "s3cret", `{"user": "mallory", "severities": {"critical": ["browser"]}}`)
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", response.Code, response.Body.String())
+	}
+	if preferences := store.Get("alice"); !preferences.Wants("critical", ChannelBrowser) || preferences.Wants("warning", ChannelBrowser) {
+		t.Errorf("Expected alice's preferences saved, got %+v", preferences)
+	}
+	if len(store.List()) != 1 {
+		t.Errorf("Expected no preferences saved for mallory, got %+v", store.List())
+	}
+	if response := request(dashboard, "PUT", "s3cret", `{"severities": {"critical": ["email"]}}`); response.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for email without an address, got %d", response.Code)
+	}
+
+	response = request(dashboard, "DELETE", "s3cret", "")
+	var preferences NotificationPreferences
+	json.Unmarshal(response.Body.Bytes(), &preferences)
+	if response.Code != http.StatusOK || !preferences.Wants("warning", ChannelBrowser) {
+		t.Errorf("Expected the defaults after a reset, got %d %+v", response.Code, preferences)
+	}
+}
+
+func TestBroadcastAlertRespectsPreferences(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{
+		Port:          0,
+		ControlTokens: map[string]string{"s3cret": "alice"},
+	})
+	store, _ := NewNotificationPreferenceStore("")
+	store.Set(NotificationPreferences{User: "alice", Severities: map[string][]string{"critical": {ChannelBrowser}}})
+	dashboard.SetNotificationPreferences(store)
+
+	server := httptest.NewServer(dashboard.server.Handler)
+	defer server.Close()
+	connect := func(protocols ...string) *websocket.Conn {
+		dialer := websocket.Dialer{Subprotocols: protocols}
+		conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+		if err != nil {
+			t.Fatalf("Dial failed: %v", err)
+		}
+		if len(protocols) > 0 && conn.Subprotocol() != wsProtocol {
+			t.Fatalf("Expected the %s subprotocol, got %q", wsProtocol, conn.Subprotocol())
+		}
+		// The initial metrics update means the connection is registered
+		var initial map[string]interface{}
+		conn.ReadJSON(&initial)
+		return conn
+	}
+	// The token is offered as a subprotocol, never selected by the server
+	alice := connect(wsProtocol, wsTokenProtocolPrefix+base64.RawURLEncoding.EncodeToString([]byte("s3cret")))
+	defer alice.Close()
+	anonymous := connect()
+	defer anonymous.Close()
+
+	readNotify := func(conn *websocket.Conn) interface{} {
+		var message map[string]interface{}
+		if err := conn.ReadJSON(&message); err != nil || message["type"] != "alert" {
+			t.Fatalf("Expected an alert message, got %v (%v)", message, err)
+		}
+		return message["notify"]
+	}
+
+	dashboard.BroadcastAlert(Alert{ID: "a1", Level: "warning", Message: "GPU 0 is hot"})
+	if notify := readNotify(alice); notify != false {
+		t.Errorf("Expected no browser notification for alice's warning, got %v", notify)
+	}
+	if notify := readNotify(anonymous); notify != true {
+		t.Errorf("Expected anonymous clients to be notified, got %v", notify)
+	}
+
+	dashboard.BroadcastAlert(Alert{ID: "a2", Level: "critical", Message: "GPU 0 is overheating"})
+	if notify := readNotify(alice); notify != true {
+		t.Errorf("Expected a browser notification for alice's critical alert, got %v", notify)
+	}
+}
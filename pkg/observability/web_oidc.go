@@ -0,0 +1,170 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
+)
+
+// sessionCookie holds the login session ID
+const sessionCookie = "agentaflow_session"
+
+// loginExemptPrefixes stay reachable without logging in: the login flow
+// itself, probes and static assets
+var loginExemptPrefixes = []string{"/auth/", "/health", "/livez", "/readyz", "/static/"}
+
+// requireLogin admits only logged-in users and bearer credentials while OIDC
+// login is configured. Browsers are sent to the login page; API clients get 401.
+func (wd *WebDashboard) requireLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wd.oidc == nil && wd.oidcErr == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		for _, prefix := range loginExemptPrefixes {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		if wd.oidcErr != nil {
+			http.Error(w, "OIDC login misconfigured: "+wd.oidcErr.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		caller, authenticated := wd.authenticate(r)
+		if !authenticated {
+			if strings.HasPrefix(r.URL.Path, "/api/") {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "login or bearer token required", http.StatusUnauthorized)
+				return
+			}
+			http.Redirect(w, r, "/auth/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+			return
+		}
+		if r.Method == http.MethodGet && !caller.hasScope(apikeys.ScopeReadMetrics) {
+			http.Error(w, caller.actor+" lacks the "+string(apikeys.ScopeReadMetrics)+" scope", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, caller)))
+	})
+}
+
+// loginSession returns the session named by a request's cookie
+func (wd *WebDashboard) loginSession(r *http.Request) (*oidcSession, bool) {
+	if wd.oidc == nil {
+		return nil, false
+	}
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil, false
+	}
+	return wd.oidc.session(cookie.Value)
+}
+
+// oidcOr503 returns the OIDC provider, or reports 503 when login is not configured
+func (wd *WebDashboard) oidcOr503(w http.ResponseWriter) *oidcProvider {
+	if wd.oidc == nil {
+		http.Error(w, "OIDC login not configured", http.StatusServiceUnavailable)
+	}
+	return wd.oidc
+}
+
+// handleLogin sends the browser to the identity provider, returning to the
+// dashboard path in ?next afterwards
+func (wd *WebDashboard) handleLogin(w http.ResponseWriter, r *http.Request) {
+	provider := wd.oidcOr503(w)
+	if provider == nil {
+		return
+	}
+
+	next := r.URL.Query().Get("next")
+	// Only local paths, so the login cannot redirect to another site
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		next = "/"
+	}
+	target, err := provider.loginURL(r.Context(), next)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// handleLoginCallback finishes a login at the provider's redirect and sets
+// the session cookie
+func (wd *WebDashboard) handleLoginCallback(w http.ResponseWriter, r *http.Request) {
+	provider := wd.oidcOr503(w)
+	if provider == nil {
+		return
+	}
+
+	query := r.URL.Query()
+	if failure := query.Get("error"); failure != "" {
+		http.Error(w, "login failed: "+failure+" "+query.Get("error_description"), http.StatusUnauthorized)
+		return
+	}
+	id, session, next, err := provider.finishLogin(r.Context(), query.Get("state"), query.Get("code"))
+	if errors.Is(err, errNoDashboardRole) {
+		http.Error(w, "login refused: "+err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "login failed: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	cookie := provider.newSessionCookie(id)
+	cookie.Expires = session.ExpiresAt
+	http.SetCookie(w, cookie)
+	http.Redirect(w, r, next, http.StatusFound)
+}
+
+// handleLogout ends the login session and clears its cookie
+func (wd *WebDashboard) handleLogout(w http.ResponseWriter, r *http.Request) {
+	provider := wd.oidcOr503(w)
+	if provider == nil {
+		return
+	}
+
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		provider.logout(cookie.Value)
+	}
+	cookie := provider.newSessionCookie("")
+	cookie.MaxAge = -1
+	http.SetCookie(w, cookie)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// newSessionCookie returns the session cookie with the attributes login and
+// logout share, so logout replaces the cookie login set
+func (p *oidcProvider) newSessionCookie(value string) *http.Cookie {
+	return &http.Cookie{
+		Name:     sessionCookie,
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(p.config.RedirectURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// handleCurrentUser returns the logged-in user with their groups, roles and tenant
+func (wd *WebDashboard) handleCurrentUser(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if wd.oidcOr503(w) == nil {
+		return
+	}
+
+	session, exists := wd.loginSession(r)
+	if !exists {
+		http.Error(w, "not logged in", http.StatusUnauthorized)
+		return
+	}
+	json.NewEncoder(w).Encode(session)
+}
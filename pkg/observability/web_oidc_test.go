@@ -0,0 +1,325 @@
+package observability
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// fakeIdentityProvider issues RS256 ID tokens with the claims a test sets
+type fakeIdentityProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	claims map[string]interface{} // Next ID token's claims; nonce comes from the login
+	nonces map[string]string      // By authorization code
+}
+
+func newFakeIdentityProvider(t *testing.T) *fakeIdentityProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	idp := &fakeIdentityProvider{key: key, nonces: make(map[string]string)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kid": "k1",
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if clientID, secret, _ := r.BasicAuth(); clientID != "dashboard" || secret != "client-secret" || r.Form.Get("code_verifier") == "" {
+			http.Error(w, "bad client", http.StatusUnauthorized)
+			return
+		}
+		claims := map[string]interface{}{"nonce": idp.nonces[r.Form.Get("code")]}
+		for name, value := range idp.claims {
+			claims[name] = value
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idp.sign(t, claims)})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *fakeIdentityProvider) sign(t *testing.T, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("SignPKCS1v15: %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// validClaims are an ID token's claims for a user in groups
+func (idp *fakeIdentityProvider) validClaims(email string, groups ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"iss":    idp.server.URL,
+		"aud":    []interface{}{"dashboard"},
+		"sub":    "user-" + email,
+		"email":  email,
+		"exp":    float64(time.Now().Add(time.Hour).Unix()),
+		"groups": groups,
+	}
+}
+
+// login runs the browser side of a login and returns the callback response
+func (idp *fakeIdentityProvider) login(t *testing.T, wd *WebDashboard, next string) *httptest.ResponseRecorder {
+	response := serveDashboard(wd, http.MethodGet, "/auth/login?next="+url.QueryEscape(next), "")
+	if response.Code != http.StatusFound {
+		t.Fatalf("Expected a redirect to the provider, got %d: %s", response.Code, response.Body.String())
+	}
+	authorize, _ := url.Parse(response.Header().Get("Location"))
+	query := authorize.Query()
+	if !strings.HasPrefix(authorize.String(), idp.server.URL+"/authorize") || query.Get("code_challenge_method") != "S256" || query.Get("client_id") != "dashboard" {
+		t.Fatalf("Unexpected authorization URL %s", authorize)
+	}
+	code := "code-" + query.Get("state")[:8]
+	idp.nonces[code] = query.Get("nonce")
+	return serveDashboard(wd, http.MethodGet, "/auth/callback?state="+query.Get("state")+"&code="+code, "")
+}
+
+// withCookie serves a request carrying the callback's session cookie
+func withCookie(wd *WebDashboard, login *httptest.ResponseRecorder, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for _, cookie := range login.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	recorder := httptest.NewRecorder()
+	wd.server.Handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func newOIDCDashboard(idp *fakeIdentityProvider) *WebDashboard {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{
+		Port:          0,
+		ControlTokens: map[string]string{"s3cret": "ops"},
+		Tenancy:       TenancyConfig{Tenants: map[string]string{"ana": "vision"}, Admins: []string{"ops"}},
+		OIDC: OIDCConfig{
+			Issuer:       idp.server.URL,
+			ClientID:     "dashboard",
+			ClientSecret: "client-secret",
+			RedirectURL:  "https://dash.example.com/auth/callback",
+			GroupRoles: map[string]apikeys.Scope{
+				"ml-viewers": apikeys.ScopeReadMetrics,
+				"ml-eng":     apikeys.ScopeSubmitWorkloads,
+				"sre":        apikeys.ScopeAdmin,
+			},
+			GroupTenants: map[string]string{"ml-viewers": "vision", "ml-eng": "vision", "nlp": "nlp"},
+		},
+	})
+	scheduler := gpu.NewScheduler(gpu.StrategyLeastUtilized)
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "detect", Tenant: "vision", MemoryRequired: 8192})
+	scheduler.SubmitWorkload(&gpu.Workload{ID: "translate", Tenant: "nlp", MemoryRequired: 8192})
+	dashboard.SetScheduler(scheduler)
+	return dashboard
+}
+
+func TestOIDCLoginMapsGroupsToRolesAndTenants(t *testing.T) {
+	idp := newFakeIdentityProvider(t)
+	dashboard := newOIDCDashboard(idp)
+
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/workloads", ""); response.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 before logging in, got %d", response.Code)
+	}
+	if response := serveDashboard(dashboard, http.MethodGet, "/", ""); response.Code != http.StatusFound || response.Header().Get("Location") != "/auth/login?next=%2F" {
+		t.Errorf("Expected the page to redirect to login, got %d %s", response.Code, response.Header().Get("Location"))
+	}
+	if response := serveDashboard(dashboard, http.MethodGet, "/health", ""); response.Code == http.StatusUnauthorized || response.Code == http.StatusFound {
+		t.Errorf("Expected probes to stay open, got %d", response.Code)
+	}
+
+	idp.claims = idp.validClaims("ana@example.com", "ml-viewers", "everyone")
+	login := idp.login(t, dashboard, "/api/v1/workloads")
+	if login.Code != http.StatusFound || login.Header().Get("Location") != "/api/v1/workloads" {
+		t.Fatalf("Expected the callback to return to the start page, got %d: %s", login.Code, login.Body.String())
+	}
+	cookie := login.Result().Cookies()[0]
+	if !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("Session cookie is not locked down: %+v", cookie)
+	}
+
+	var me oidcSession
+	json.Unmarshal(withCookie(dashboard, login, http.MethodGet, "/auth/me", "").Body.Bytes(), &me)
+	if me.Email != "ana@example.com" || me.Tenant != "vision" || len(me.Scopes) != 1 || me.Scopes[0] != apikeys.ScopeReadMetrics {
+		t.Errorf("Unexpected session %+v", me)
+	}
+	response := withCookie(dashboard, login, http.MethodGet, "/api/v1/workloads", "")
+	var listed struct {
+		Workloads []gpu.WorkloadInfo `json:"workloads"`
+	}
+	json.Unmarshal(response.Body.Bytes(), &listed)
+	if len(listed.Workloads) != 1 || listed.Workloads[0].ID != "detect" {
+		t.Errorf("Expected only the vision tenant's workloads, got %d: %s", response.Code, response.Body.String())
+	}
+	artifacts := `{"artifacts": [{"uri": "s3://runs/detect/model.bin"}]}`
+	if response := withCookie(dashboard, login, http.MethodPost, "/api/v1/workloads/detect/artifacts", artifacts); response.Code != http.StatusForbidden {
+		t.Errorf("Expected viewers to be refused artifact uploads, got %d", response.Code)
+	}
+
+	idp.claims = idp.validClaims("bo@example.com", "ml-eng", "ml-viewers")
+	engineer := idp.login(t, dashboard, "/")
+	if response := withCookie(dashboard, engineer, http.MethodPost, "/api/v1/workloads/detect/artifacts", artifacts); response.Code != http.StatusCreated {
+		t.Errorf("Expected engineers to upload artifacts, got %d: %s", response.Code, response.Body.String())
+	}
+	if response := withCookie(dashboard, engineer, http.MethodGet, "/api/v1/costs", ""); response.Code != http.StatusForbidden {
+		t.Errorf("Expected cluster costs to stay admin-only, got %d", response.Code)
+	}
+
+	idp.claims = idp.validClaims("sam@example.com", "sre")
+	sre := idp.login(t, dashboard, "/")
+	if response := withCookie(dashboard, sre, http.MethodGet, "/api/v1/apikeys", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected admins to reach key management, got %d", response.Code)
+	}
+
+	// Machines keep using bearer credentials
+	if response := serveAs(dashboard, "s3cret", http.MethodGet, "/api/v1/workloads", ""); response.Code != http.StatusOK {
+		t.Errorf("Expected control tokens to keep working, got %d", response.Code)
+	}
+
+	logout := withCookie(dashboard, login, http.MethodPost, "/auth/logout", "")
+	if logout.Code != http.StatusSeeOther {
+		t.Fatalf("Expected logout to redirect, got %d", logout.Code)
+	}
+	if cleared := logout.Result().Cookies()[0]; cleared.MaxAge >= 0 || !cleared.HttpOnly || !cleared.Secure || cleared.SameSite != http.SameSiteLaxMode {
+		t.Errorf("Expected logout to clear the cookie with the login's attributes, got %+v", cleared)
+	}
+	if response := withCookie(dashboard, login, http.MethodGet, "/api/v1/workloads", ""); response.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 after logout, got %d", response.Code)
+	}
+}
+
+func TestOIDCLoginRejections(t *testing.T) {
+	idp := newFakeIdentityProvider(t)
+	dashboard := newOIDCDashboard(idp)
+
+	expired := idp.validClaims("ana@example.com", "ml-viewers")
+	expired["exp"] = float64(time.Now().Add(-time.Minute).Unix())
+	otherClient := idp.validClaims("ana@example.com", "ml-viewers")
+	otherClient["aud"] = "another-app"
+	otherIssuer := idp.validClaims("ana@example.com", "ml-viewers")
+	otherIssuer["iss"] = "https://evil.example.com"
+
+	cases := []struct {
+		name   string
+		claims map[string]interface{}
+		code   int
+	}{
+		{"expired", expired, http.StatusUnauthorized},
+		{"wrong audience", otherClient, http.StatusUnauthorized},
+		{"wrong issuer", otherIssuer, http.StatusUnauthorized},
+		{"no mapped group", idp.validClaims("eve@example.com", "everyone"), http.StatusForbidden},
+		{"two tenants", idp.validClaims("max@example.com", "ml-viewers", "nlp"), http.StatusForbidden},
+	}
+	for _, c := range cases {
+		idp.claims = c.claims
+		if response := idp.login(t, dashboard, "/"); response.Code != c.code || len(response.Result().Cookies()) != 0 {
+			t.Errorf("%s: got %d, want %d without a session", c.name, response.Code, c.code)
+		}
+	}
+
+	if response := serveDashboard(dashboard, http.MethodGet, "/auth/callback?state=forged&code=x", ""); response.Code != http.StatusUnauthorized {
+		t.Errorf("Expected an unknown state to be refused, got %d", response.Code)
+	}
+	if response := serveDashboard(dashboard, http.MethodGet, "/auth/login?next=//evil.example.com", ""); response.Code != http.StatusFound {
+		t.Fatalf("Expected a redirect, got %d", response.Code)
+	}
+	provider := dashboard.oidc
+	for _, login := range provider.logins {
+		if login.next != "/" {
+			t.Errorf("Expected an off-site next to be replaced, got %q", login.next)
+		}
+	}
+
+	// A tampered token fails signature verification
+	discovery, _ := provider.discover(httptest.NewRequest(http.MethodGet, "/", nil).Context())
+	token := idp.sign(t, idp.validClaims("ana@example.com", "sre"))
+	parts := strings.Split(token, ".")
+	forged, _ := json.Marshal(idp.validClaims("ana@example.com", "sre", "root"))
+	tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString(forged) + "." + parts[2]
+	if _, err := provider.verifyIDToken(httptest.NewRequest(http.MethodGet, "/", nil).Context(), discovery, tampered, ""); err == nil {
+		t.Error("Expected a tampered ID token to be rejected")
+	}
+
+	misconfigured := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{
+		OIDC: OIDCConfig{Issuer: idp.server.URL, ClientID: "dashboard", RedirectURL: "https://dash/auth/callback", GroupRoles: map[string]apikeys.Scope{"sre": "root"}},
+	})
+	if response := serveDashboard(misconfigured, http.MethodGet, "/api/v1/gpus", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected a misconfigured login to fail closed, got %d", response.Code)
+	}
+}
+
+func TestOIDCPendingLoginsAreBounded(t *testing.T) {
+	idp := newFakeIdentityProvider(t)
+	provider := newOIDCDashboard(idp).oidc
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+
+	// Unauthenticated visitors cannot grow pending logins without bound
+	provider.mu.Lock()
+	for i := 0; i < maxPendingOIDCLogins; i++ {
+		provider.logins[fmt.Sprintf("state-%d", i)] = pendingLogin{expires: now.Add(time.Duration(i) * time.Millisecond)}
+	}
+	provider.sessions["expired"] = &oidcSession{ExpiresAt: now.Add(-time.Second)}
+	provider.sessions["current"] = &oidcSession{ExpiresAt: now.Add(time.Hour)}
+	provider.mu.Unlock()
+	if _, err := provider.loginURL(context.Background(), "/"); err != nil {
+		t.Fatalf("loginURL failed: %v", err)
+	}
+	provider.mu.Lock()
+	_, oldest := provider.logins["state-0"]
+	pending := len(provider.logins)
+	provider.mu.Unlock()
+	if pending != maxPendingOIDCLogins || oldest {
+		t.Errorf("Expected the oldest login dropped at the cap, got %d pending (oldest kept: %v)", pending, oldest)
+	}
+
+	// Pruning drops what expired without waiting for the next login
+	now = now.Add(oidcLoginTimeout + time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go provider.runPruning(ctx, time.Millisecond)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		provider.mu.Lock()
+		logins, sessions := len(provider.logins), len(provider.sessions)
+		provider.mu.Unlock()
+		if logins == 0 && sessions == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected expired logins and sessions pruned, got %d logins and %d sessions", logins, sessions)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
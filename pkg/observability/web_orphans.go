@@ -0,0 +1,105 @@
+package observability
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// OrphanDetectionConfig enables detecting orphaned GPU processes on the
+// dashboard's own metrics collector
+type OrphanDetectionConfig struct {
+	Enabled          bool `yaml:"enabled" json:"enabled"`
+	gpu.OrphanConfig `yaml:",inline"`
+}
+
+// orphanObserver is a collector reporting GPU processes to an orphan detector
+type orphanObserver interface {
+	SetOrphanDetector(detector *gpu.OrphanDetector)
+}
+
+// enableOrphanDetection feeds the processes of the dashboard's collector to
+// an orphan detector. Ownership is judged with the scheduler once one is set,
+// so nothing is reported before.
+func (wd *WebDashboard) enableOrphanDetection(config gpu.OrphanConfig) {
+	collector, ok := wd.metricsCollector.(orphanObserver)
+	if !ok {
+		log.Printf("Orphan detection needs a collector reporting GPU processes, disabled")
+		return
+	}
+	detector := gpu.NewOrphanDetector(config, nil)
+	if wd.monitoringService != nil {
+		detector.OnOrphan(wd.monitoringService.RecordOrphanProcess)
+	}
+	collector.SetOrphanDetector(detector)
+	wd.SetOrphanDetector(detector)
+	wd.orphansFromConfig = true
+}
+
+// SetOrphanDetector enables the orphaned GPU process endpoints and registers
+// the detector's cleanup with the dashboard's remediator
+func (wd *WebDashboard) SetOrphanDetector(detector *gpu.OrphanDetector) {
+	if detector != nil {
+		detector.RegisterRemediations(wd.remediator)
+	}
+
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.orphanDetector = detector
+	wd.orphansFromConfig = false
+}
+
+// getOrphanDetector returns the orphan detector, or reports 503 when none is set
+func (wd *WebDashboard) getOrphanDetector(w http.ResponseWriter) *gpu.OrphanDetector {
+	wd.mu.RLock()
+	detector := wd.orphanDetector
+	wd.mu.RUnlock()
+	if detector == nil {
+		http.Error(w, "orphan detector not configured", http.StatusServiceUnavailable)
+	}
+	return detector
+}
+
+// handleOrphans lists processes holding GPU memory without an owning workload
+func (wd *WebDashboard) handleOrphans(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	detector := wd.getOrphanDetector(w)
+	if detector == nil {
+		return
+	}
+	orphans := detector.Orphans()
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"orphans": orphans,
+		"count":   len(orphans),
+	})
+}
+
+// handleCleanupOrphan terminates an orphaned process on a GPU through the
+// remediator, which records the attempt
+func (wd *WebDashboard) handleCleanupOrphan(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	detector := wd.getOrphanDetector(w)
+	if detector == nil {
+		return
+	}
+	vars := mux.Vars(r)
+	pid, err := strconv.Atoi(vars["pid"])
+	if err != nil || pid <= 0 {
+		http.Error(w, "invalid pid: "+vars["pid"], http.StatusBadRequest)
+		return
+	}
+
+	remediation, err := wd.remediator.Remediate(controlActor(r), gpu.RemediationTerminateOrphan, gpu.OrphanTarget(vars["id"], pid))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	json.NewEncoder(w).Encode(remediation)
+}
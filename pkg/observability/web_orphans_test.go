@@ -0,0 +1,114 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestOrphanAPI(t *testing.T) {
+	ms := NewMonitoringService(100)
+	dashboard := NewWebDashboard(ms, nil, nil, WebDashboardConfig{
+		Port:          0,
+		ControlTokens: map[string]string{"s3cret": "alice"},
+	})
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/gpus/orphans", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without an orphan detector, got %d", response.Code)
+	}
+
+	detector := gpu.NewOrphanDetector(gpu.OrphanConfig{GracePeriod: time.Minute}, func(gpuID string, pid int) string { return "" })
+	detector.OnOrphan(ms.RecordOrphanProcess)
+	dashboard.SetOrphanDetector(detector)
+
+	start := time.Now()
+	processes := []gpu.GPUProcess{{PID: 4242, ProcessName: "python", MemoryUsed: 3000}}
+	detector.Observe("0", processes, start)
+	detector.Observe("0", processes, start.Add(time.Minute))
+
+	response := serveDashboard(dashboard, http.MethodGet, "/api/v1/gpus/orphans", "")
+	var listed struct {
+		Orphans []gpu.OrphanProcess `json:"orphans"`
+		Count   int                 `json:"count"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if listed.Count != 1 || listed.Orphans[0].PID != 4242 || listed.Orphans[0].GPUID != "0" {
+		t.Errorf("Expected the orphaned process, got %+v", listed)
+	}
+
+	cleanup := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(""))
+		req.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		dashboard.server.Handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+	if response := cleanup("/api/v1/gpu/0/processes/4242/cleanup", "wrong"); response.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a wrong token, got %d", response.Code)
+	}
+	if response := cleanup("/api/v1/gpu/0/processes/abc/cleanup", "s3cret"); response.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a bad pid, got %d", response.Code)
+	}
+	if response := cleanup("/api/v1/gpu/0/processes/4242/cleanup", "s3cret"); response.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 while cleanup is disabled, got %d", response.Code)
+	}
+
+	events := ms.GetEvents(start.Add(-time.Minute), time.Now().Add(time.Minute), "warning")
+	found, audited := false, false
+	for _, event := range events {
+		if event.Type == "gpu_orphan_process" && event.Metadata["pid"] == 4242 {
+			found = true
+		}
+		if event.Type == "remediation" && event.Metadata["target"] == "0/4242" && event.Metadata["actor"] == "alice" {
+			audited = true
+		}
+	}
+	if !found || !audited {
+		t.Errorf("Expected gpu_orphan_process and remediation events, got %+v", events)
+	}
+}
+
+func TestOrphanDetectionFromConfig(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), gpu.NewMetricsCollector(time.Second), nil, WebDashboardConfig{
+		Port:    0,
+		Orphans: OrphanDetectionConfig{Enabled: true},
+	})
+	detector := dashboard.orphanDetector
+	if detector == nil {
+		t.Fatal("Expected an orphan detector fed by the collector")
+	}
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/gpus/orphans", ""); response.Code != http.StatusOK {
+		t.Errorf("Expected 200 with orphan detection enabled, got %d", response.Code)
+	}
+	if kinds := dashboard.remediator.Kinds(); len(kinds) != 1 || kinds[0] != gpu.RemediationTerminateOrphan {
+		t.Errorf("Expected orphan cleanup to be a remediation, got %v", kinds)
+	}
+
+	// Nothing is an orphan until a scheduler can tell owned processes apart
+	start := time.Now()
+	processes := []gpu.GPUProcess{{PID: 4242, ProcessName: "python", MemoryUsed: 3000}}
+	detector.Observe("0", processes, start)
+	if orphans := detector.Observe("0", processes, start.Add(time.Hour)); len(orphans) != 0 {
+		t.Errorf("Expected no orphans without a scheduler, got %+v", orphans)
+	}
+	dashboard.SetScheduler(gpu.NewScheduler(gpu.StrategyLeastUtilized))
+	detector.Observe("0", processes, start.Add(time.Hour))
+	if orphans := detector.Observe("0", processes, start.Add(2*time.Hour)); len(orphans) != 1 {
+		t.Errorf("Expected the unowned process once the scheduler is set, got %+v", orphans)
+	}
+
+	// The mock collector reports no real processes, so detection stays off
+	mock := NewWebDashboard(NewMonitoringService(100), gpu.NewMockMetricsCollector(time.Second, 1), nil, WebDashboardConfig{
+		Port:    0,
+		Orphans: OrphanDetectionConfig{Enabled: true},
+	})
+	if mock.orphanDetector != nil {
+		t.Error("Expected no orphan detector for a collector without processes")
+	}
+}
@@ -0,0 +1,177 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// handleListPanels lists the custom panels
+func (wd *WebDashboard) handleListPanels(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	panels := append([]CustomPanel{}, wd.layout.CustomPanels...)
+	wd.mu.RUnlock()
+
+	json.NewEncoder(w).Encode(panels)
+}
+
+// decodePanel reads and validates a custom panel from a request body
+func decodePanel(w http.ResponseWriter, r *http.Request) (CustomPanel, bool) {
+	var panel CustomPanel
+	if err := json.NewDecoder(r.Body).Decode(&panel); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return panel, false
+	}
+	if err := panel.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return panel, false
+	}
+	return panel, true
+}
+
+// updatePanels applies a change to the custom panels, keeping the layout
+// valid; callers must hold wd.mu
+func (wd *WebDashboard) updatePanels(panels []CustomPanel) error {
+	layout := wd.layout
+	layout.CustomPanels = panels
+	if err := layout.Validate(); err != nil {
+		return err
+	}
+	wd.layout = layout
+	return nil
+}
+
+// handleCreatePanel registers a custom panel from the request body
+func (wd *WebDashboard) handleCreatePanel(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	panel, ok := decodePanel(w, r)
+	if !ok {
+		return
+	}
+
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	if _, exists := wd.layout.CustomPanel(panel.ID); exists {
+		http.Error(w, fmt.Sprintf("panel %s already exists", panel.ID), http.StatusConflict)
+		return
+	}
+	panels := append(append([]CustomPanel{}, wd.layout.CustomPanels...), panel)
+	if err := wd.updatePanels(panels); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(panel)
+}
+
+// handleUpdatePanel replaces the custom panel named in the path
+func (wd *WebDashboard) handleUpdatePanel(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	panel, ok := decodePanel(w, r)
+	if !ok {
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if panel.ID != id {
+		http.Error(w, "panel id does not match the path", http.StatusBadRequest)
+		return
+	}
+
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	panels := append([]CustomPanel{}, wd.layout.CustomPanels...)
+	found := false
+	for i := range panels {
+		if panels[i].ID == id {
+			panels[i] = panel
+			found = true
+		}
+	}
+	if !found {
+		http.Error(w, fmt.Sprintf("panel %s not found", id), http.StatusNotFound)
+		return
+	}
+	if err := wd.updatePanels(panels); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(panel)
+}
+
+// handleDeletePanel removes a custom panel no layout places explicitly
+func (wd *WebDashboard) handleDeletePanel(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	panels := make([]CustomPanel, 0, len(wd.layout.CustomPanels))
+	for _, panel := range wd.layout.CustomPanels {
+		if panel.ID != id {
+			panels = append(panels, panel)
+		}
+	}
+	if len(panels) == len(wd.layout.CustomPanels) {
+		http.Error(w, fmt.Sprintf("panel %s not found", id), http.StatusNotFound)
+		return
+	}
+	if err := wd.updatePanels(panels); err != nil {
+		// A layout still lists the panel
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePanelData runs a custom panel's query
+func (wd *WebDashboard) handlePanelData(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id := mux.Vars(r)["id"]
+	wd.mu.RLock()
+	panel, exists := wd.layout.CustomPanel(id)
+	wd.mu.RUnlock()
+	if !exists {
+		http.Error(w, fmt.Sprintf("panel %s not found", id), http.StatusNotFound)
+		return
+	}
+
+	wd.writeQueryResult(w, panel.Query)
+}
+
+// handleQuery runs an ad-hoc metric query from the request body, e.g. to
+// preview a panel before registering it
+func (wd *WebDashboard) handleQuery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var query MetricQuery
+	if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	wd.writeQueryResult(w, query)
+}
+
+// writeQueryResult runs a query over the monitoring service's metrics
+func (wd *WebDashboard) writeQueryResult(w http.ResponseWriter, query MetricQuery) {
+	if wd.monitoringService == nil {
+		http.Error(w, "monitoring service not configured", http.StatusServiceUnavailable)
+		return
+	}
+	result, err := wd.monitoringService.QueryMetrics(query, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result.Annotations = wd.annotationsBetween(result.Start, result.End)
+	json.NewEncoder(w).Encode(result)
+}
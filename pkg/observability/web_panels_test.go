@@ -0,0 +1,79 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCustomPanelsAPI(t *testing.T) {
+	ms := NewMonitoringService(100)
+	dashboard := NewWebDashboard(ms, nil, nil, WebDashboardConfig{Port: 0})
+	ms.RecordMetric(Metric{Name: "queue_depth", Value: 4, Labels: map[string]string{"pool": "research"}})
+	ms.RecordMetric(Metric{Name: "queue_depth", Value: 6, Labels: map[string]string{"pool": "research"}})
+
+	panel := `{"id": "queue", "title": "Queue depth", "visualization": "stat",
+		"query": {"metric": "queue_depth", "labels": {"pool": "research"}, "aggregation": "avg", "window_seconds": 300}}`
+	if response := serveDashboard(dashboard, http.MethodPost, "/api/v1/panels", panel); response.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", response.Code, response.Body.String())
+	}
+	if response := serveDashboard(dashboard, http.MethodPost, "/api/v1/panels", panel); response.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a duplicate panel, got %d", response.Code)
+	}
+	invalid := `{"id": "gpu_grid", "visualization": "line", "query": {"metric": "m", "aggregation": "avg", "window_seconds": 60}}`
+	if response := serveDashboard(dashboard, http.MethodPost, "/api/v1/panels", invalid); response.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a built-in panel ID, got %d", response.Code)
+	}
+
+	response := serveDashboard(dashboard, http.MethodGet, "/api/v1/panels/queue/data", "")
+	var result QueryResult
+	if err := json.Unmarshal(response.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to decode query result: %v", err)
+	}
+	if len(result.Series) != 1 || result.Series[0].Points[0].Value != 5 {
+		t.Errorf("Expected an average queue depth of 5, got %+v", result.Series)
+	}
+
+	updated := strings.Replace(panel, `"avg"`, `"max"`, 1)
+	if response := serveDashboard(dashboard, http.MethodPut, "/api/v1/panels/queue", updated); response.Code != http.StatusOK {
+		t.Fatalf("Expected 200 updating the panel, got %d: %s", response.Code, response.Body.String())
+	}
+	json.Unmarshal(serveDashboard(dashboard, http.MethodGet, "/api/v1/panels/queue/data", "").Body.Bytes(), &result)
+	if result.Series[0].Points[0].Value != 6 {
+		t.Errorf("Expected the updated query's maximum of 6, got %+v", result.Series)
+	}
+
+	// Panels placed by a layout cannot be removed until the layout moves on
+	layout := DefaultDashboardLayoutConfig()
+	layout.CustomPanels = dashboard.layout.CustomPanels
+	layout.Default.Panels = append(layout.Default.Panels, DashboardPanel{ID: "queue", Width: 6})
+	if err := dashboard.SetDashboardLayout(layout); err != nil {
+		t.Fatalf("SetDashboardLayout failed: %v", err)
+	}
+	if response := serveDashboard(dashboard, http.MethodDelete, "/api/v1/panels/queue", ""); response.Code != http.StatusConflict {
+		t.Errorf("Expected 409 deleting a placed panel, got %d", response.Code)
+	}
+	layout.Default.Panels = layout.Default.Panels[:5]
+	dashboard.SetDashboardLayout(layout)
+	if response := serveDashboard(dashboard, http.MethodDelete, "/api/v1/panels/queue", ""); response.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", response.Code)
+	}
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/panels/queue/data", ""); response.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 after deletion, got %d", response.Code)
+	}
+}
+
+func TestAdHocQuery(t *testing.T) {
+	ms := NewMonitoringService(100)
+	dashboard := NewWebDashboard(ms, nil, nil, WebDashboardConfig{Port: 0})
+	ms.RecordMetric(Metric{Name: "gpu_power_watts", Value: 250})
+
+	response := serveDashboard(dashboard, http.MethodPost, "/api/v1/query", `{"metric": "gpu_power_watts", "aggregation": "sum", "window_seconds": 60}`)
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", response.Code)
+	}
+	if response := serveDashboard(dashboard, http.MethodPost, "/api/v1/query", `{"metric": "gpu_power_watts"}`); response.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an incomplete query, got %d", response.Code)
+	}
+}
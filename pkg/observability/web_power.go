@@ -0,0 +1,220 @@
+package observability
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// controlActorKey carries the authenticated operator through a control request
+type controlActorKey struct{}
+
+// requireControlToken rejects requests without a configured bearer token or
+// an admin API key and passes the caller's name to the handler for audit logs
+func (wd *WebDashboard) requireControlToken(handler http.HandlerFunc) http.HandlerFunc {
+	return wd.requireScope(apikeys.ScopeAdmin, handler)
+}
+
+// requireScope accepts control tokens, and API keys and login sessions
+// granting scope, passing the caller's name to the handler for audit logs
+func (wd *WebDashboard) requireScope(scope apikeys.Scope, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(wd.controlTokens) == 0 && wd.getAPIKeyStore() == nil && wd.oidc == nil {
+			http.Error(w, "control API disabled: no control tokens configured", http.StatusForbidden)
+			return
+		}
+
+		caller, authenticated := wd.authenticate(r)
+		if !authenticated {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "invalid or missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		if !caller.hasScope(scope) {
+			http.Error(w, fmt.Sprintf("%s lacks the %s scope", caller.actor, scope), http.StatusForbidden)
+			return
+		}
+		handler(w, r.WithContext(context.WithValue(r.Context(), controlActorKey{}, caller.actor)))
+	}
+}
+
+// bearerToken returns the token in a request's Authorization header
+func bearerToken(r *http.Request) string {
+	return strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+}
+
+// tokenActor returns the operator a control token belongs to, or "" for an
+// empty or unknown token
+func (wd *WebDashboard) tokenActor(token string) string {
+	if token == "" {
+		return ""
+	}
+	actor := ""
+	for candidate, name := range wd.controlTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(candidate)) == 1 {
+			actor = name
+		}
+	}
+	return actor
+}
+
+// principal is an authenticated caller
+type principal struct {
+	actor  string
+	tenant string          // Empty for every tenant
+	admin  bool            // May query across tenants
+	scopes []apikeys.Scope // Nil grants every scope, as control tokens do
+}
+
+// hasScope reports whether the caller may act with scope
+func (p principal) hasScope(scope apikeys.Scope) bool {
+	if p.scopes == nil {
+		return true
+	}
+	for _, granted := range p.scopes {
+		if granted == scope || granted == apikeys.ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// authenticate resolves the caller from a control token or API key bearer
+// token, or from a login session cookie
+func (wd *WebDashboard) authenticate(r *http.Request) (principal, bool) {
+	if caller, resolved := r.Context().Value(principalKey{}).(principal); resolved {
+		return caller, true
+	}
+	wd.mu.RLock()
+	tenancy := wd.tenancy
+	wd.mu.RUnlock()
+
+	token := bearerToken(r)
+	if actor := wd.tokenActor(token); actor != "" {
+		return principal{actor: actor, tenant: tenancy.Tenants[actor], admin: tenancy.isAdmin(actor)}, true
+	}
+	if keys := wd.getAPIKeyStore(); token != "" && keys != nil {
+		if key, err := keys.Authenticate(token); err == nil {
+			return principal{actor: apiKeyActor(key), tenant: key.Tenant, admin: key.HasScope(apikeys.ScopeAdmin), scopes: key.Scopes}, true
+		}
+	}
+	if session, exists := wd.loginSession(r); token == "" && exists {
+		return principal{actor: session.actor(), tenant: session.Tenant, admin: session.hasAdmin(), scopes: session.Scopes}, true
+	}
+	return principal{}, false
+}
+
+// controlActor returns the operator that authenticated a control request
+func controlActor(r *http.Request) string {
+	actor, _ := r.Context().Value(controlActorKey{}).(string)
+	return actor
+}
+
+// getPowerManager returns the power manager, or reports 503 when none is set
+func (wd *WebDashboard) getPowerManager(w http.ResponseWriter) *gpu.PowerManager {
+	wd.mu.RLock()
+	powerManager := wd.powerManager
+	wd.mu.RUnlock()
+	if powerManager == nil {
+		http.Error(w, "power manager not configured", http.StatusServiceUnavailable)
+	}
+	return powerManager
+}
+
+// handleSetPowerLimit sets a GPU's power limit from {"watts": N}
+func (wd *WebDashboard) handleSetPowerLimit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	powerManager := wd.getPowerManager(w)
+	if powerManager == nil {
+		return
+	}
+	var request struct {
+		Watts float64 `json:"watts"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	gpuID := mux.Vars(r)["id"]
+	if err := powerManager.SetPowerLimit(controlActor(r), gpuID, request.Watts); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"gpu_id":      gpuID,
+		"power_limit": request.Watts,
+	})
+}
+
+// handleSetClocks sets a GPU's application clocks from {"memory_mhz": N,
+// "graphics_mhz": N}, or restores the defaults on DELETE
+func (wd *WebDashboard) handleSetClocks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	powerManager := wd.getPowerManager(w)
+	if powerManager == nil {
+		return
+	}
+	gpuID := mux.Vars(r)["id"]
+
+	if r.Method == http.MethodDelete {
+		if err := powerManager.ResetApplicationClocks(controlActor(r), gpuID); err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"gpu_id": gpuID, "clocks": "default"})
+		return
+	}
+
+	var request struct {
+		MemoryMHz   uint64 `json:"memory_mhz"`
+		GraphicsMHz uint64 `json:"graphics_mhz"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := powerManager.SetApplicationClocks(controlActor(r), gpuID, request.MemoryMHz, request.GraphicsMHz); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"gpu_id":       gpuID,
+		"memory_mhz":   request.MemoryMHz,
+		"graphics_mhz": request.GraphicsMHz,
+	})
+}
+
+// handlePowerAudit lists power and clock changes from the last ?hours (default 24)
+func (wd *WebDashboard) handlePowerAudit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	powerManager := wd.getPowerManager(w)
+	if powerManager == nil {
+		return
+	}
+	hours := 24
+	if h, err := strconv.Atoi(r.URL.Query().Get("hours")); err == nil && h > 0 {
+		hours = h
+	}
+
+	entries := powerManager.AuditLog(time.Now().Add(-time.Duration(hours) * time.Hour))
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
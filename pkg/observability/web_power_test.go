@@ -0,0 +1,86 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// recordingPowerController accepts every change
+type recordingPowerController struct {
+	limits map[string]float64
+}
+
+func (c *recordingPowerController) SetPowerLimit(gpuID string, watts float64) error {
+	c.limits[gpuID] = watts
+	return nil
+}
+
+func (c *recordingPowerController) SetApplicationClocks(gpuID string, memoryMHz, graphicsMHz uint64) error {
+	return nil
+}
+
+func (c *recordingPowerController) ResetApplicationClocks(gpuID string) error {
+	return nil
+}
+
+func TestPowerControlAPIRequiresToken(t *testing.T) {
+	controller := &recordingPowerController{limits: make(map[string]float64)}
+	powerManager := gpu.NewPowerManager(controller, gpu.DefaultPowerConfig())
+
+	request := func(wd *WebDashboard, method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		wd.server.Handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	disabled := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{Port: 0})
+	disabled.SetPowerManager(powerManager)
+	if response := request(disabled, "POST", "/api/v1/gpu/0/power", "", `{"watts":200}`); response.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 without configured tokens, got %d", response.Code)
+	}
+
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{
+		Port:          0,
+		ControlTokens: map[string]string{"s3cret": "alice"},
+	})
+	if response := request(dashboard, "POST", "/api/v1/gpu/0/power", "s3cret", `{"watts":200}`); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a power manager, got %d", response.Code)
+	}
+	dashboard.SetPowerManager(powerManager)
+
+	if response := request(dashboard, "POST", "/api/v1/gpu/0/power", "wrong", `{"watts":200}`); response.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a wrong token, got %d", response.Code)
+	}
+	if response := request(dashboard, "POST", "/api/v1/gpu/0/power", "s3cret", `{"watts":20}`); response.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for a limit outside the guardrails, got %d", response.Code)
+	}
+	if response := request(dashboard, "POST", "/api/v1/gpu/0/power", "s3cret", `{"watts":200}`); response.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", response.Code, response.Body.String())
+	}
+	if controller.limits["0"] != 200 {
+		t.Errorf("Expected 200 W applied, got %v", controller.limits["0"])
+	}
+	if response := request(dashboard, "DELETE", "/api/v1/gpu/0/clocks", "s3cret", ""); response.Code != http.StatusOK {
+		t.Errorf("Expected clocks reset, got %d", response.Code)
+	}
+
+	response := request(dashboard, "GET", "/api/v1/power/audit", "s3cret", "")
+	var audit struct {
+		Entries []gpu.PowerAuditEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &audit); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(audit.Entries) != 3 || audit.Entries[1].Actor != "alice" || audit.Entries[1].Value != "200 W" {
+		t.Errorf("Expected audited changes attributed to alice, got %+v", audit.Entries)
+	}
+}
@@ -0,0 +1,54 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+// SetRecurrenceManager enables the recurring workload endpoint
+func (wd *WebDashboard) SetRecurrenceManager(manager *gpu.RecurrenceManager) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.recurrence = manager
+}
+
+// handleRecurringWorkloads lists recurring workloads with their run history
+// and the cost of each run
+func (wd *WebDashboard) handleRecurringWorkloads(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	manager := wd.recurrence
+	scheduler := wd.scheduler
+	pricing := wd.costConfig
+	wd.mu.RUnlock()
+	if manager == nil {
+		http.Error(w, "recurrence manager not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	statuses := make([]gpu.RecurringStatus, 0)
+	for _, status := range manager.Status() {
+		if inTenant(r, status.Tenant) {
+			statuses = append(statuses, status)
+		}
+	}
+	var records []gpu.UsageRecord
+	if scheduler != nil {
+		since := time.Now()
+		for _, status := range statuses {
+			for _, run := range status.Runs {
+				if run.SubmittedAt != nil && run.SubmittedAt.Before(since) {
+					since = *run.SubmittedAt
+				}
+			}
+		}
+		records = scheduler.GetUsageRecords(since)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"recurring": BuildRecurringReports(statuses, records, pricing),
+	})
+}
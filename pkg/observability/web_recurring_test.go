@@ -0,0 +1,104 @@
+package observability
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+)
+
+func TestBuildRecurringReportsPricesEachRun(t *testing.T) {
+	start := time.Date(2026, 5, 4, 2, 0, 0, 0, time.UTC)
+	labels := map[string]string{gpu.RecurrenceLabel: "nightly"}
+	statuses := []gpu.RecurringStatus{{
+		Name: "nightly",
+		Runs: []gpu.RecurringRun{
+			{Recurrence: "nightly", WorkloadID: "nightly-1", Status: gpu.WorkloadCompleted},
+			{Recurrence: "nightly", WorkloadID: "nightly-2", Status: gpu.RunSkipped},
+			{Recurrence: "nightly", WorkloadID: "nightly-3", Status: gpu.WorkloadCompleted},
+		},
+	}}
+	records := []gpu.UsageRecord{
+		{WorkloadID: "nightly-1", Labels: labels, GPUName: "NVIDIA A100", Start: start, End: start.Add(2 * time.Hour)},
+		// Preempted and resumed on another GPU
+		{WorkloadID: "nightly-3", Labels: labels, GPUName: "NVIDIA A100", Start: start, End: start.Add(time.Hour)},
+		{WorkloadID: "nightly-3", Labels: labels, GPUName: "NVIDIA A100", Shared: true, Start: start.Add(time.Hour), End: start.Add(3 * time.Hour)},
+		{WorkloadID: "adhoc", GPUName: "NVIDIA A100", Start: start, End: start.Add(5 * time.Hour)},
+	}
+	pricing := GPUCostConfiguration{Currency: "USD", CostPerHour: map[string]float64{"a100": 3}}
+
+	reports := BuildRecurringReports(statuses, records, pricing)
+	if len(reports) != 1 || len(reports[0].Runs) != 3 {
+		t.Fatalf("reports = %+v", reports)
+	}
+	runs := reports[0].Runs
+	if runs[0].GPUHours != 2 || runs[0].Cost != 6 {
+		t.Errorf("first run = %+v, want 2 hours costing 6", runs[0])
+	}
+	if runs[1].GPUHours != 0 || runs[1].Cost != 0 {
+		t.Errorf("skipped run priced: %+v", runs[1])
+	}
+	// One hour alone plus two shared hours at half price
+	if runs[2].GPUHours != 3 || runs[2].Cost != 6 {
+		t.Errorf("third run = %+v, want 3 hours costing 6", runs[2])
+	}
+	if reports[0].GPUHours != 5 || math.Abs(reports[0].Cost-12) > 1e-9 || reports[0].Currency != "USD" {
+		t.Errorf("totals = %.2f hours, %.2f %s", reports[0].GPUHours, reports[0].Cost, reports[0].Currency)
+	}
+}
+
+func TestRecurringWorkloadsAPI(t *testing.T) {
+	ms := NewMonitoringService(100)
+	dashboard := NewWebDashboard(ms, nil, nil, WebDashboardConfig{Port: 0})
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/workloads/recurring", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a recurrence manager, got %d", response.Code)
+	}
+
+	scheduler := gpu.NewScheduler(gpu.StrategyLeastUtilized)
+	scheduler.RegisterGPU(&gpu.GPU{ID: "gpu-0", Name: "NVIDIA A100", MemoryTotal: 40960, Available: true})
+	manager := gpu.NewRecurrenceManager(scheduler)
+	manager.OnRun(ms.RecordRecurringRun)
+	if err := manager.Add(gpu.RecurringWorkload{
+		Name:       "nightly",
+		Template:   gpu.Workload{MemoryRequired: 8000},
+		Recurrence: gpu.Recurrence{Schedule: "@daily"},
+	}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	dashboard.SetScheduler(scheduler)
+	dashboard.SetRecurrenceManager(manager)
+
+	runs := manager.Tick(manager.Status()[0].NextRun)
+	scheduler.Schedule()
+	time.Sleep(5 * time.Millisecond)
+	scheduler.CompleteWorkload(runs[0].WorkloadID)
+
+	response := serveDashboard(dashboard, http.MethodGet, "/api/v1/workloads/recurring", "")
+	var listed struct {
+		Recurring []RecurringReport `json:"recurring"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(listed.Recurring) != 1 || len(listed.Recurring[0].Runs) != 1 {
+		t.Fatalf("Expected the recurring workload with one run, got %+v", listed)
+	}
+	run := listed.Recurring[0].Runs[0]
+	if run.Status != gpu.WorkloadCompleted || run.GPUHours <= 0 || run.Cost <= 0 {
+		t.Errorf("Expected a completed, priced run, got %+v", run)
+	}
+
+	events := ms.GetEvents(time.Now().Add(-time.Minute), time.Now(), "")
+	found := false
+	for _, event := range events {
+		if event.Type == "recurring_workload_run" && event.Metadata["workload_id"] == runs[0].WorkloadID {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected a recurring run event")
+	}
+}
@@ -0,0 +1,390 @@
+package observability
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/airgap"
+)
+
+// Dashboard replica roles
+const (
+	ReplicaRolePrimary = "primary" // Collects metrics and publishes broadcasts
+	ReplicaRoleReplica = "replica" // Serves the API and relays published broadcasts
+)
+
+// Broadcast backends
+const (
+	BroadcastBackendLocal = "local"
+	BroadcastBackendRedis = "redis"
+)
+
+// DefaultBroadcastChannel is the pub/sub channel used when none is configured
+const DefaultBroadcastChannel = "agentaflow:dashboard"
+
+// ReplicationConfig runs the dashboard as several replicas. One primary
+// collects metrics; replicas serve the API from the shared persistent stores
+// and receive WebSocket broadcasts over a pub/sub channel.
+type ReplicationConfig struct {
+	// "primary" (default) or "replica"
+	Role string `yaml:"role" json:"role"`
+
+	Broadcast BroadcastConfig `yaml:"broadcast" json:"broadcast"`
+}
+
+// BroadcastConfig selects how WebSocket broadcasts reach every replica
+type BroadcastConfig struct {
+	// "local" (default) delivers in process; "redis" uses Redis pub/sub
+	Backend       string        `yaml:"backend" json:"backend"`
+	RedisAddr     string        `yaml:"redis_addr" json:"redis_addr"`
+	RedisPassword string        `yaml:"redis_password" json:"-" secret:"true"`
+	Channel       string        `yaml:"channel" json:"channel"`
+	DialTimeout   time.Duration `yaml:"dial_timeout" json:"dial_timeout"`
+}
+
+// validate adds replication errors under the replication prefix
+func (c ReplicationConfig) validate(errs *ConfigErrors) {
+	if c.Role != "" && c.Role != ReplicaRolePrimary && c.Role != ReplicaRoleReplica {
+		errs.add("replication.role", "must be %q or %q, got %q", ReplicaRolePrimary, ReplicaRoleReplica, c.Role)
+	}
+	switch c.Broadcast.Backend {
+	case "", BroadcastBackendLocal:
+		if c.Role == ReplicaRoleReplica {
+			errs.add("replication.broadcast.backend", "replicas need a shared backend such as %q", BroadcastBackendRedis)
+		}
+	case BroadcastBackendRedis:
+		if c.Broadcast.RedisAddr == "" {
+			errs.add("replication.broadcast.redis_addr", "is required for the redis backend")
+		}
+	default:
+		errs.add("replication.broadcast.backend", "must be %q or %q, got %q", BroadcastBackendLocal, BroadcastBackendRedis, c.Broadcast.Backend)
+	}
+	if c.Broadcast.DialTimeout < 0 {
+		errs.add("replication.broadcast.dial_timeout", "must not be negative")
+	}
+}
+
+// BroadcastBus carries WebSocket broadcasts between dashboard replicas.
+// Implementations for other brokers, such as NATS, are set with
+// SetBroadcastBus.
+type BroadcastBus interface {
+	// Publish sends a payload to every subscribed replica, including this one
+	Publish(payload []byte) error
+
+	// Subscribe calls deliver for each published payload until ctx is done
+	Subscribe(ctx context.Context, deliver func(payload []byte)) error
+
+	Close() error
+}
+
+// newBroadcastBus creates the bus for a configuration; nil means broadcasts
+// are delivered in process
+func newBroadcastBus(config BroadcastConfig) BroadcastBus {
+	if config.Backend != BroadcastBackendRedis {
+		return nil
+	}
+	return NewRedisBroadcastBus(config)
+}
+
+// RedisBroadcastBus publishes broadcasts over Redis pub/sub, speaking RESP
+// directly so no client library is needed
+type RedisBroadcastBus struct {
+	config  BroadcastConfig
+	publish net.Conn
+	reader  *bufio.Reader
+	mu      sync.Mutex
+}
+
+// NewRedisBroadcastBus creates a bus for a Redis server; connections are
+// opened on first use and reopened after failures
+func NewRedisBroadcastBus(config BroadcastConfig) *RedisBroadcastBus {
+	if config.Channel == "" {
+		config.Channel = DefaultBroadcastChannel
+	}
+	if config.DialTimeout == 0 {
+		config.DialTimeout = 5 * time.Second
+	}
+	registerOutboundEndpoint("redis broadcast", config.RedisAddr)
+	return &RedisBroadcastBus{config: config}
+}
+
+// dial connects through the air gap guard and authenticates a Redis
+// connection
+func (b *RedisBroadcastBus) dial() (net.Conn, *bufio.Reader, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.config.DialTimeout)
+	defer cancel()
+	conn, err := airgap.DialContext(ctx, "tcp", b.config.RedisAddr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	reader := bufio.NewReader(conn)
+	if b.config.RedisPassword != "" {
+		if err := writeRESPCommand(conn, "AUTH", b.config.RedisPassword); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+		if _, err := readRESP(reader); err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("redis authentication failed: %w", err)
+		}
+	}
+	return conn, reader, nil
+}
+
+// Publish sends a payload to the channel, reconnecting once if the
+// connection was lost
+func (b *RedisBroadcastBus) Publish(payload []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if b.publish == nil {
+			if b.publish, b.reader, err = b.dial(); err != nil {
+				return err
+			}
+		}
+		b.publish.SetDeadline(time.Now().Add(b.config.DialTimeout))
+		if err = writeRESPCommand(b.publish, "PUBLISH", b.config.Channel, string(payload)); err == nil {
+			if _, err = readRESP(b.reader); err == nil {
+				return nil
+			}
+		}
+		b.publish.Close()
+		b.publish, b.reader = nil, nil
+	}
+	return fmt.Errorf("failed to publish broadcast: %w", err)
+}
+
+// Subscribe receives the channel's messages until ctx is done, resubscribing
+// with backoff when the connection drops
+func (b *RedisBroadcastBus) Subscribe(ctx context.Context, deliver func(payload []byte)) error {
+	backoff := time.Second
+	for {
+		err := b.subscribeOnce(ctx, deliver)
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Printf("Broadcast subscription to %s lost, retrying in %v: %v", b.config.RedisAddr, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// subscribeOnce holds one subscription until it fails or ctx is done
+func (b *RedisBroadcastBus) subscribeOnce(ctx context.Context, deliver func(payload []byte)) error {
+	conn, reader, err := b.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	if err := writeRESPCommand(conn, "SUBSCRIBE", b.config.Channel); err != nil {
+		return err
+	}
+	for {
+		reply, err := readRESP(reader)
+		if err != nil {
+			return err
+		}
+		// Messages arrive as ["message", channel, payload]
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 3 {
+			continue
+		}
+		if kind, _ := parts[0].(string); kind != "message" {
+			continue
+		}
+		if payload, ok := parts[2].(string); ok {
+			deliver([]byte(payload))
+		}
+	}
+}
+
+// Close closes the publishing connection; subscriptions end with their context
+func (b *RedisBroadcastBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.publish == nil {
+		return nil
+	}
+	err := b.publish.Close()
+	b.publish, b.reader = nil, nil
+	return err
+}
+
+// writeRESPCommand writes a command as a RESP array of bulk strings
+func writeRESPCommand(w io.Writer, args ...string) error {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, arg...)
+		buf = append(buf, '\r', '\n')
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// readRESP reads one RESP reply; bulk strings and simple strings become
+// strings, integers int64, arrays []interface{}, and errors an error
+func readRESP(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed redis reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, fmt.Errorf("redis error: %s", body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(body)
+		if err != nil || count < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = readRESP(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unknown redis reply type %q", kind)
+}
+
+// broadcastEnvelope is a broadcast as published on the bus. Alerts travel
+// unrendered because whether to notify depends on each connection's user.
+type broadcastEnvelope struct {
+	Alert   *Alert          `json:"alert,omitempty"`
+	Message json.RawMessage `json:"message,omitempty"`
+	Delta   json.RawMessage `json:"delta,omitempty"` // Delta of a metrics update in Message
+}
+
+// SetBroadcastBus routes WebSocket broadcasts through a bus shared by all
+// replicas; call it before Start
+func (wd *WebDashboard) SetBroadcastBus(bus BroadcastBus) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.bus = bus
+}
+
+// broadcastBus returns the bus, or nil for in-process delivery
+func (wd *WebDashboard) broadcastBus() BroadcastBus {
+	wd.mu.RLock()
+	defer wd.mu.RUnlock()
+	return wd.bus
+}
+
+// isReplica reports whether this dashboard only serves and relays
+func (wd *WebDashboard) isReplica() bool {
+	return wd.role == ReplicaRoleReplica
+}
+
+// publishBroadcast puts a broadcast on the bus, reporting whether it did;
+// the caller delivers locally when it did not
+func (wd *WebDashboard) publishBroadcast(envelope broadcastEnvelope) bool {
+	bus := wd.broadcastBus()
+	if bus == nil {
+		return false
+	}
+	payload, err := json.Marshal(envelope)
+	if err == nil {
+		err = bus.Publish(payload)
+	}
+	if err != nil {
+		log.Printf("Failed to publish broadcast, delivering locally only: %v", err)
+		return false
+	}
+	return true
+}
+
+// relayBroadcasts delivers broadcasts from the bus to this replica's
+// connections until the dashboard stops
+func (wd *WebDashboard) relayBroadcasts(bus BroadcastBus) {
+	err := bus.Subscribe(wd.ctx, func(payload []byte) {
+		var envelope broadcastEnvelope
+		if err := json.Unmarshal(payload, &envelope); err != nil {
+			log.Printf("Ignoring malformed broadcast: %v", err)
+			return
+		}
+		if envelope.Alert != nil {
+			wd.deliverAlert(*envelope.Alert)
+			return
+		}
+		if len(envelope.Message) == 0 {
+			return
+		}
+
+		var header struct {
+			Type string `json:"type"`
+		}
+		json.Unmarshal(envelope.Message, &header)
+		if header.Type == "metrics_update" {
+			// Replicas have no collector; the primary's updates are their
+			// initial data for new connections and show the broadcast is alive
+			wd.mu.Lock()
+			wd.relayedMetrics = envelope.Message
+			wd.mu.Unlock()
+			if wd.isReplica() {
+				wd.broadcastHeartbeat.Beat()
+			}
+			var delta interface{}
+			if len(envelope.Delta) > 0 {
+				delta = envelope.Delta
+			}
+			wd.deliverMetrics(envelope.Message, delta)
+			return
+		}
+		wd.deliverToLocalConnections(envelope.Message)
+	})
+	if err != nil {
+		log.Printf("Broadcast relay stopped: %v", err)
+	}
+}
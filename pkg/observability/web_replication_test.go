@@ -0,0 +1,206 @@
+package observability
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeRedis implements AUTH, SUBSCRIBE and PUBLISH for a single channel
+type fakeRedis struct {
+	listener    net.Listener
+	password    string
+	subscribers []net.Conn
+	mu          sync.Mutex
+}
+
+func startFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeRedis{listener: listener, password: password}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	authenticated := s.password == ""
+	for {
+		reply, err := readRESP(reader)
+		if err != nil {
+			conn.Close()
+			return
+		}
+		args, _ := reply.([]interface{})
+		command, _ := args[0].(string)
+		if !authenticated && command != "AUTH" {
+			conn.Write([]byte("-NOAUTH Authentication required.\r\n"))
+			continue
+		}
+		switch command {
+		case "AUTH":
+			if args[1] != s.password {
+				conn.Write([]byte("-WRONGPASS invalid password\r\n"))
+				continue
+			}
+			authenticated = true
+			conn.Write([]byte("+OK\r\n"))
+		case "SUBSCRIBE":
+			s.mu.Lock()
+			s.subscribers = append(s.subscribers, conn)
+			s.mu.Unlock()
+			writeRESPCommand(conn, "subscribe", args[1].(string), "1")
+		case "PUBLISH":
+			s.mu.Lock()
+			for _, subscriber := range s.subscribers {
+				writeRESPCommand(subscriber, "message", args[1].(string), args[2].(string))
+			}
+			count := len(s.subscribers)
+			s.mu.Unlock()
+			conn.Write([]byte(":" + strconv.Itoa(count) + "\r\n"))
+		}
+	}
+}
+
+func (s *fakeRedis) subscriberCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers)
+}
+
+func TestRedisBroadcastBusPublishesToSubscribers(t *testing.T) {
+	server := startFakeRedis(t, "s3cret")
+	bus := NewRedisBroadcastBus(BroadcastConfig{Backend: BroadcastBackendRedis, RedisAddr: server.listener.Addr().String(), RedisPassword: "s3cret"})
+	defer bus.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	received := make(chan string, 1)
+	done := make(chan struct{})
+	go func() {
+		bus.Subscribe(ctx, func(payload []byte) { received <- string(payload) })
+		close(done)
+	}()
+	for deadline := time.Now().Add(2 * time.Second); server.subscriberCount() == 0; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("Subscriber never connected")
+		}
+	}
+
+	if err := bus.Publish([]byte("hello\r\nworld")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	select {
+	case payload := <-received:
+		if payload != "hello\r\nworld" {
+			t.Errorf("Expected the payload intact, got %q", payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the published payload delivered")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Error("Expected Subscribe to return when its context is done")
+	}
+
+	wrong := NewRedisBroadcastBus(BroadcastConfig{RedisAddr: server.listener.Addr().String(), RedisPassword: "wrong"})
+	if err := wrong.Publish([]byte("x")); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Errorf("Expected a bad password reported, got %v", err)
+	}
+}
+
+func TestReplicaRelaysBroadcastsFromPrimary(t *testing.T) {
+	server := startFakeRedis(t, "")
+	replication := func(role string) ReplicationConfig {
+		return ReplicationConfig{Role: role, Broadcast: BroadcastConfig{Backend: BroadcastBackendRedis, RedisAddr: server.listener.Addr().String()}}
+	}
+	primary := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{Replication: replication(ReplicaRolePrimary)})
+	replica := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{Replication: replication(ReplicaRoleReplica), EnableRealTimeUpdates: true})
+	for _, dashboard := range []*WebDashboard{primary, replica} {
+		go dashboard.relayBroadcasts(dashboard.bus)
+		defer dashboard.cancel()
+	}
+	for deadline := time.Now().Add(2 * time.Second); server.subscriberCount() < 2; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("Dashboards never subscribed")
+		}
+	}
+
+	// The primary's metrics update becomes the replica's initial data
+	primary.broadcastMetrics()
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		replica.mu.RLock()
+		relayed := replica.relayedMetrics
+		replica.mu.RUnlock()
+		if relayed != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the replica to receive the metrics update")
+		}
+	}
+	if replica.checkWebSocketHub().Status != ComponentHealthy {
+		t.Errorf("Expected relayed updates to keep the replica's hub healthy, got %+v", replica.checkWebSocketHub())
+	}
+
+	httpServer := httptest.NewServer(replica.server.Handler)
+	defer httpServer.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var initial map[string]interface{}
+	if err := conn.ReadJSON(&initial); err != nil || initial["type"] != "metrics_update" {
+		t.Fatalf("Expected the relayed metrics as initial data, got %v (%v)", initial, err)
+	}
+
+	primary.BroadcastAlert(Alert{ID: "a1", Level: "critical", Message: "GPU 0 is overheating"})
+	var alert map[string]interface{}
+	if err := conn.ReadJSON(&alert); err != nil || alert["type"] != "alert" || alert["notify"] != true {
+		t.Errorf("Expected the primary's alert on the replica, got %v (%v)", alert, err)
+	}
+}
+
+func TestReplicationConfigValidation(t *testing.T) {
+	for name, tc := range map[string]struct {
+		config ReplicationConfig
+		field  string
+	}{
+		"unknown role":       {ReplicationConfig{Role: "leader"}, "replication.role"},
+		"local replica":      {ReplicationConfig{Role: ReplicaRoleReplica}, "replication.broadcast.backend"},
+		"redis without addr": {ReplicationConfig{Broadcast: BroadcastConfig{Backend: BroadcastBackendRedis}}, "replication.broadcast.redis_addr"},
+		"unknown backend":    {ReplicationConfig{Broadcast: BroadcastConfig{Backend: "kafka"}}, "replication.broadcast.backend"},
+	} {
+		err := WebDashboardConfig{Replication: tc.config}.Validate()
+		if err == nil || !strings.Contains(err.Error(), tc.field) {
+			t.Errorf("%s: expected an error for %s, got %v", name, tc.field, err)
+		}
+	}
+	if err := (WebDashboardConfig{}).Validate(); err != nil {
+		t.Errorf("Expected the default single primary valid, got %v", err)
+	}
+}
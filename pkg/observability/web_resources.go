@@ -0,0 +1,530 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving"
+)
+
+// The kinds of configuration managed through /api/v1/resources, e.g. by the
+// Terraform provider
+const (
+	ResourceAlertRules = "alert-rules"
+	ResourcePools      = "pools"
+	ResourceBudgets    = "budgets"
+	ResourceModels     = "models"
+	ResourceTemplates  = "templates"
+)
+
+// PoolResource is a scheduler pool as managed through the resources API
+type PoolResource struct {
+	Name           string `yaml:"name" json:"name"`
+	gpu.PoolConfig `yaml:",inline"`
+}
+
+// WorkloadTemplate is a recurring workload as managed through the resources
+// API: the requirements each run is submitted with, and its schedule
+type WorkloadTemplate struct {
+	Name          string            `yaml:"name" json:"name"`
+	Workload      string            `yaml:"workload" json:"workload,omitempty"` // Name of each run; the template's when empty
+	Tenant        string            `yaml:"tenant" json:"tenant,omitempty"`
+	Pool          string            `yaml:"pool" json:"pool,omitempty"`
+	Priority      int               `yaml:"priority" json:"priority,omitempty"`
+	Class         gpu.WorkloadClass `yaml:"class" json:"class,omitempty"`
+	Labels        map[string]string `yaml:"labels" json:"labels,omitempty"`
+	MemoryMB      uint64            `yaml:"memory_mb" json:"memory_mb"`
+	EstimatedTime time.Duration     `yaml:"estimated_time" json:"estimated_time,omitempty"`
+	Recurrence    gpu.Recurrence    `yaml:"recurrence" json:"recurrence"`
+}
+
+// newWorkloadTemplate describes a recurring workload
+func newWorkloadTemplate(workload gpu.RecurringWorkload) WorkloadTemplate {
+	return WorkloadTemplate{
+		Name:          workload.Name,
+		Workload:      workload.Template.Name,
+		Tenant:        workload.Template.Tenant,
+		Pool:          workload.Template.Pool,
+		Priority:      workload.Template.Priority,
+		Class:         workload.Template.Class,
+		Labels:        workload.Template.Labels,
+		MemoryMB:      workload.Template.MemoryRequired,
+		EstimatedTime: workload.Template.EstimatedTime,
+		Recurrence:    workload.Recurrence,
+	}
+}
+
+// RecurringWorkload returns the recurring workload a template describes
+func (t WorkloadTemplate) RecurringWorkload() gpu.RecurringWorkload {
+	return gpu.RecurringWorkload{
+		Name: t.Name,
+		Template: gpu.Workload{
+			Name:           t.Workload,
+			Tenant:         t.Tenant,
+			Pool:           t.Pool,
+			Priority:       t.Priority,
+			Class:          t.Class,
+			Labels:         t.Labels,
+			MemoryRequired: t.MemoryMB,
+			EstimatedTime:  t.EstimatedTime,
+		},
+		Recurrence: t.Recurrence,
+	}
+}
+
+// ModelRegistration is a served model as managed through the resources API
+type ModelRegistration struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Version        string    `json:"version,omitempty"`
+	Framework      string    `json:"framework,omitempty"`
+	MemorySize     uint64    `json:"memory_size,omitempty"` // MB
+	Tenant         string    `json:"tenant,omitempty"`
+	SourceWorkload string    `json:"source_workload,omitempty"`
+	SourceArtifact string    `json:"source_artifact,omitempty"`
+	LoadedAt       time.Time `json:"loaded_at"`
+}
+
+// newModelRegistration describes a registered model
+func newModelRegistration(model *serving.Model) ModelRegistration {
+	return ModelRegistration{
+		ID:             model.ID,
+		Name:           model.Name,
+		Version:        model.Version,
+		Framework:      model.Framework,
+		MemorySize:     model.MemorySize,
+		Tenant:         model.Tenant,
+		SourceWorkload: model.SourceWorkload,
+		SourceArtifact: model.SourceArtifact,
+		LoadedAt:       model.LoadedAt,
+	}
+}
+
+// SetGPUIntegration lets the resources API manage alert rules, which are the
+// integration's threshold profiles
+func (wd *WebDashboard) SetGPUIntegration(integration *GPUMetricsIntegration) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.gpuIntegration = integration
+}
+
+// SetServingManager lets the resources API register and remove models
+func (wd *WebDashboard) SetServingManager(manager *serving.ServingManager) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.servingManager = manager
+}
+
+// resourceKind lists, reads, creates or replaces, and deletes one kind of
+// resource by name
+type resourceKind interface {
+	list() interface{}
+	get(name string) (interface{}, bool)
+	// put decodes a resource from a request body, returning it as stored
+	put(name string, body []byte) (interface{}, error)
+	remove(name string) (bool, error)
+}
+
+// resourceKindOrError returns the named kind, or reports 404 for an unknown
+// kind and 503 when the component behind it is not set
+func (wd *WebDashboard) resourceKindOrError(w http.ResponseWriter, r *http.Request) resourceKind {
+	kind := mux.Vars(r)["kind"]
+
+	wd.mu.RLock()
+	integration := wd.gpuIntegration
+	scheduler := wd.scheduler
+	manager := wd.servingManager
+	recurrence := wd.recurrence
+	wd.mu.RUnlock()
+
+	var missing string
+	switch kind {
+	case ResourceAlertRules:
+		if integration != nil {
+			return alertRuleResources{integration}
+		}
+		missing = "GPU integration"
+	case ResourcePools:
+		if scheduler != nil {
+			return poolResources{scheduler}
+		}
+		missing = "scheduler"
+	case ResourceBudgets:
+		return budgetResources{wd}
+	case ResourceModels:
+		if manager != nil {
+			return modelResources{manager}
+		}
+		missing = "serving manager"
+	case ResourceTemplates:
+		if recurrence != nil {
+			return templateResources{recurrence}
+		}
+		missing = "recurrence manager"
+	default:
+		http.Error(w, "unknown resource kind: "+kind, http.StatusNotFound)
+		return nil
+	}
+	http.Error(w, missing+" not configured", http.StatusServiceUnavailable)
+	return nil
+}
+
+// handleListResources lists every resource of a kind, ordered by name
+func (wd *WebDashboard) handleListResources(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	kind := wd.resourceKindOrError(w, r)
+	if kind == nil {
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"kind":      mux.Vars(r)["kind"],
+		"resources": kind.list(),
+	})
+}
+
+// handleGetResource returns one resource
+func (wd *WebDashboard) handleGetResource(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	kind := wd.resourceKindOrError(w, r)
+	if kind == nil {
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+	resource, exists := kind.get(name)
+	if !exists {
+		http.Error(w, "resource not found: "+name, http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(resource)
+}
+
+// handlePutResource creates or replaces a resource, answering 201 when it
+// did not exist
+func (wd *WebDashboard) handlePutResource(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	kind := wd.resourceKindOrError(w, r)
+	if kind == nil {
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "failed to read request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	name := mux.Vars(r)["name"]
+
+	wd.resourcesMu.Lock()
+	_, existed := kind.get(name)
+	resource, err := kind.put(name, body)
+	wd.resourcesMu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !existed {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(resource)
+}
+
+// handleDeleteResource deletes a resource
+func (wd *WebDashboard) handleDeleteResource(w http.ResponseWriter, r *http.Request) {
+	kind := wd.resourceKindOrError(w, r)
+	if kind == nil {
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+	wd.resourcesMu.Lock()
+	removed, err := kind.remove(name)
+	wd.resourcesMu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !removed {
+		http.Error(w, "resource not found: "+name, http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeNamed decodes a resource body whose name, when set, must match the path
+func decodeNamed(body []byte, resource interface{}, name string, bodyName *string) error {
+	if err := json.Unmarshal(body, resource); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	if *bodyName != "" && *bodyName != name {
+		return fmt.Errorf("body names %s but the path names %s", *bodyName, name)
+	}
+	*bodyName = name
+	return nil
+}
+
+// alertRuleResources manages the GPU threshold profiles
+type alertRuleResources struct {
+	integration *GPUMetricsIntegration
+}
+
+func (a alertRuleResources) list() interface{} {
+	profiles := a.integration.ThresholdProfiles()
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles
+}
+
+func (a alertRuleResources) get(name string) (interface{}, bool) {
+	for _, profile := range a.integration.ThresholdProfiles() {
+		if profile.Name == name {
+			return profile, true
+		}
+	}
+	return nil, false
+}
+
+// put replaces a profile in place so match order is kept; new profiles are
+// tried last
+func (a alertRuleResources) put(name string, body []byte) (interface{}, error) {
+	var profile GPUThresholdProfile
+	if err := decodeNamed(body, &profile, name, &profile.Name); err != nil {
+		return nil, err
+	}
+
+	profiles := a.integration.ThresholdProfiles()
+	replaced := false
+	for i := range profiles {
+		if profiles[i].Name == name {
+			profiles[i] = profile
+			replaced = true
+		}
+	}
+	if !replaced {
+		profiles = append(profiles, profile)
+	}
+	if err := a.integration.SetThresholdProfiles(profiles); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (a alertRuleResources) remove(name string) (bool, error) {
+	profiles := a.integration.ThresholdProfiles()
+	kept := make([]GPUThresholdProfile, 0, len(profiles))
+	for _, profile := range profiles {
+		if profile.Name != name {
+			kept = append(kept, profile)
+		}
+	}
+	if len(kept) == len(profiles) {
+		return false, nil
+	}
+	return true, a.integration.SetThresholdProfiles(kept)
+}
+
+// poolResources manages the scheduler's pools
+type poolResources struct {
+	scheduler *gpu.Scheduler
+}
+
+func (p poolResources) list() interface{} {
+	pools := p.scheduler.Policy().Pools
+	resources := make([]PoolResource, 0, len(pools))
+	for name, config := range pools {
+		resources = append(resources, PoolResource{Name: name, PoolConfig: config})
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Name < resources[j].Name })
+	return resources
+}
+
+func (p poolResources) get(name string) (interface{}, bool) {
+	config, exists := p.scheduler.Policy().Pools[name]
+	if !exists {
+		return nil, false
+	}
+	return PoolResource{Name: name, PoolConfig: config}, true
+}
+
+// put leaves the strategy and utilization goal as they are
+func (p poolResources) put(name string, body []byte) (interface{}, error) {
+	var pool PoolResource
+	if err := decodeNamed(body, &pool, name, &pool.Name); err != nil {
+		return nil, err
+	}
+
+	policy := p.scheduler.Policy()
+	policy.Pools[name] = pool.PoolConfig
+	if err := p.scheduler.ApplyPolicy(policy); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
+
+func (p poolResources) remove(name string) (bool, error) {
+	policy := p.scheduler.Policy()
+	if _, exists := policy.Pools[name]; !exists {
+		return false, nil
+	}
+	delete(policy.Pools, name)
+	return true, p.scheduler.ApplyPolicy(policy)
+}
+
+// budgetResources manages the dashboard's team budgets, reporting this
+// month's spend when a scheduler is set
+type budgetResources struct {
+	wd *WebDashboard
+}
+
+func (b budgetResources) list() interface{} {
+	b.wd.mu.RLock()
+	budgets := make([]Budget, 0, len(b.wd.budgets))
+	for _, budget := range b.wd.budgets {
+		budgets = append(budgets, budget)
+	}
+	scheduler := b.wd.scheduler
+	pricing := b.wd.costConfig
+	config := b.wd.showback
+	b.wd.mu.RUnlock()
+
+	month := time.Now()
+	var records []gpu.UsageRecord
+	if scheduler != nil {
+		records = scheduler.GetUsageRecords(MonthStart(month))
+	}
+	return EvaluateBudgets(budgets, BuildShowbackReport(records, pricing, config, month))
+}
+
+func (b budgetResources) get(name string) (interface{}, bool) {
+	for _, status := range b.list().([]BudgetStatus) {
+		if status.Name == name {
+			return status, true
+		}
+	}
+	return nil, false
+}
+
+func (b budgetResources) put(name string, body []byte) (interface{}, error) {
+	var budget Budget
+	if err := decodeNamed(body, &budget, name, &budget.Name); err != nil {
+		return nil, err
+	}
+	if err := budget.Validate(); err != nil {
+		return nil, err
+	}
+
+	b.wd.mu.Lock()
+	if b.wd.budgets == nil {
+		b.wd.budgets = make(map[string]Budget)
+	}
+	b.wd.budgets[name] = budget
+	b.wd.mu.Unlock()
+
+	status, _ := b.get(name)
+	return status, nil
+}
+
+func (b budgetResources) remove(name string) (bool, error) {
+	b.wd.mu.Lock()
+	defer b.wd.mu.Unlock()
+	_, exists := b.wd.budgets[name]
+	delete(b.wd.budgets, name)
+	return exists, nil
+}
+
+// modelResources manages the models registered with the serving manager
+type modelResources struct {
+	manager *serving.ServingManager
+}
+
+func (m modelResources) list() interface{} {
+	models := m.manager.Models()
+	registrations := make([]ModelRegistration, 0, len(models))
+	for _, model := range models {
+		registrations = append(registrations, newModelRegistration(model))
+	}
+	return registrations
+}
+
+func (m modelResources) get(name string) (interface{}, bool) {
+	model, exists := m.manager.GetModel(name)
+	if !exists {
+		return nil, false
+	}
+	return newModelRegistration(model), true
+}
+
+func (m modelResources) put(name string, body []byte) (interface{}, error) {
+	var registration ModelRegistration
+	if err := decodeNamed(body, &registration, name, &registration.ID); err != nil {
+		return nil, err
+	}
+
+	model := &serving.Model{
+		ID:             registration.ID,
+		Name:           registration.Name,
+		Version:        registration.Version,
+		Framework:      registration.Framework,
+		MemorySize:     registration.MemorySize,
+		Tenant:         registration.Tenant,
+		SourceWorkload: registration.SourceWorkload,
+		SourceArtifact: registration.SourceArtifact,
+	}
+	if err := m.manager.RegisterModel(model); err != nil {
+		return nil, err
+	}
+	return newModelRegistration(model), nil
+}
+
+func (m modelResources) remove(name string) (bool, error) {
+	return m.manager.UnregisterModel(name), nil
+}
+
+// templateResources manages recurring workload templates
+type templateResources struct {
+	manager *gpu.RecurrenceManager
+}
+
+func (t templateResources) list() interface{} {
+	workloads := t.manager.Workloads()
+	templates := make([]WorkloadTemplate, 0, len(workloads))
+	for _, workload := range workloads {
+		templates = append(templates, newWorkloadTemplate(workload))
+	}
+	return templates
+}
+
+func (t templateResources) get(name string) (interface{}, bool) {
+	workload, exists := t.manager.Get(name)
+	if !exists {
+		return nil, false
+	}
+	return newWorkloadTemplate(workload), true
+}
+
+// put keeps the run history of a replaced template
+func (t templateResources) put(name string, body []byte) (interface{}, error) {
+	var template WorkloadTemplate
+	if err := decodeNamed(body, &template, name, &template.Name); err != nil {
+		return nil, err
+	}
+	if err := t.manager.Add(template.RecurringWorkload()); err != nil {
+		return nil, err
+	}
+	stored, _ := t.get(name)
+	return stored, nil
+}
+
+func (t templateResources) remove(name string) (bool, error) {
+	if _, exists := t.manager.Get(name); !exists {
+		return false, nil
+	}
+	return true, t.manager.Remove(name)
+}
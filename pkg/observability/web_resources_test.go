@@ -0,0 +1,96 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving"
+)
+
+func TestResourceEndpoints(t *testing.T) {
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{
+		Port:          0,
+		ControlTokens: map[string]string{"s3cret": "alice"},
+	})
+	if response := serveAs(dashboard, "s3cret", http.MethodGet, "/api/v1/resources/models", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a serving manager, got %d", response.Code)
+	}
+	if response := serveAs(dashboard, "s3cret", http.MethodGet, "/api/v1/resources/widgets", ""); response.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown kind, got %d", response.Code)
+	}
+	integration := NewGPUMetricsIntegration(NewMonitoringService(100), nil)
+	integration.SetThresholdProfiles([]GPUThresholdProfile{{Name: "h100", Pattern: "(?i)h100"}})
+	dashboard.SetGPUIntegration(integration)
+	scheduler := gpu.NewScheduler(gpu.StrategyLeastUtilized)
+	dashboard.SetScheduler(scheduler)
+	recurrence := gpu.NewRecurrenceManager(scheduler)
+	dashboard.SetRecurrenceManager(recurrence)
+	manager := serving.NewServingManager(nil, time.Minute)
+	dashboard.SetServingManager(manager)
+
+	checks := []struct {
+		token, method, path, body string
+		code                      int
+	}{
+		{"", http.MethodGet, "/api/v1/resources/pools", "", http.StatusUnauthorized},
+		{"s3cret", http.MethodPut, "/api/v1/resources/alert-rules/t4", `{"pattern": "(?i)t4", "thresholds": {"high_temperature": 70}}`, http.StatusCreated},
+		{"s3cret", http.MethodPut, "/api/v1/resources/alert-rules/h100", `{"pattern": "(?i)h100", "thresholds": {"high_temperature": 80}}`, http.StatusOK},
+		{"s3cret", http.MethodPut, "/api/v1/resources/alert-rules/bad", `{"pattern": "("}`, http.StatusBadRequest},
+		{"s3cret", http.MethodPut, "/api/v1/resources/alert-rules/t4", `{"name": "a10"}`, http.StatusBadRequest},
+		{"s3cret", http.MethodPut, "/api/v1/resources/pools/research", `{"strategy": "best_fit", "max_gpus": 4}`, http.StatusCreated},
+		{"s3cret", http.MethodPut, "/api/v1/resources/pools/batch", `{"max_gpus": -1}`, http.StatusBadRequest},
+		{"s3cret", http.MethodPut, "/api/v1/resources/budgets/search", `{"team": "search", "monthly_limit": 500}`, http.StatusCreated},
+		{"s3cret", http.MethodPut, "/api/v1/resources/budgets/vision", `{"team": "vision"}`, http.StatusBadRequest},
+		{"s3cret", http.MethodPut, "/api/v1/resources/models/chat-v1", `{"name": "llama", "version": "1", "memory_size": 16000}`, http.StatusCreated},
+		{"s3cret", http.MethodDelete, "/api/v1/resources/models/chat-v2", "", http.StatusNotFound},
+		{"s3cret", http.MethodPut, "/api/v1/resources/templates/nightly-etl", `{"workload": "etl", "memory_mb": 8000, "recurrence": {"schedule": "@daily"}}`, http.StatusCreated},
+		{"s3cret", http.MethodPut, "/api/v1/resources/templates/hourly", `{"memory_mb": 8000, "recurrence": {"schedule": "hourly"}}`, http.StatusBadRequest},
+	}
+	for _, check := range checks {
+		if response := serveAs(dashboard, check.token, check.method, check.path, check.body); response.Code != check.code {
+			t.Errorf("%s %s: expected %d, got %d: %s", check.method, check.path, check.code, response.Code, response.Body.String())
+		}
+	}
+
+	// Replacing h100 keeps it ahead of t4
+	profiles := integration.ThresholdProfiles()
+	if len(profiles) != 2 || profiles[0].Name != "h100" || profiles[0].Thresholds.HighTemperature != 80 {
+		t.Errorf("Expected h100 replaced in place and t4 appended, got %+v", profiles)
+	}
+	if pool := dashboard.scheduler.Policy().Pools["research"]; pool.Strategy != gpu.StrategyBestFit || pool.MaxGPUs != 4 {
+		t.Errorf("Expected the research pool applied to the scheduler, got %+v", pool)
+	}
+	if model, exists := manager.GetModel("chat-v1"); !exists || model.Name != "llama" || model.MemorySize != 16000 {
+		t.Errorf("Expected the model registered, got %+v", model)
+	}
+
+	if workload, exists := recurrence.Get("nightly-etl"); !exists || workload.Template.Name != "etl" || workload.Recurrence.MaxConcurrency != 1 {
+		t.Errorf("Expected the template added with defaults, got %+v", workload)
+	}
+
+	var budget BudgetStatus
+	json.Unmarshal(serveAs(dashboard, "s3cret", http.MethodGet, "/api/v1/resources/budgets/search", "").Body.Bytes(), &budget)
+	if budget.Name != "search" || budget.MonthlyLimit != 500 || budget.Month == "" {
+		t.Errorf("Expected the budget with this month's spend, got %+v", budget)
+	}
+
+	var listed struct {
+		Resources []PoolResource `json:"resources"`
+	}
+	json.Unmarshal(serveAs(dashboard, "s3cret", http.MethodGet, "/api/v1/resources/pools", "").Body.Bytes(), &listed)
+	if len(listed.Resources) != 1 || listed.Resources[0].Name != "research" || listed.Resources[0].MaxGPUs != 4 {
+		t.Errorf("Expected the research pool listed, got %+v", listed.Resources)
+	}
+
+	for _, path := range []string{"/api/v1/resources/alert-rules/t4", "/api/v1/resources/pools/research", "/api/v1/resources/budgets/search", "/api/v1/resources/models/chat-v1", "/api/v1/resources/templates/nightly-etl"} {
+		if response := serveAs(dashboard, "s3cret", http.MethodDelete, path, ""); response.Code != http.StatusNoContent {
+			t.Errorf("DELETE %s: expected 204, got %d", path, response.Code)
+		}
+		if response := serveAs(dashboard, "s3cret", http.MethodGet, path, ""); response.Code != http.StatusNotFound {
+			t.Errorf("GET %s: expected 404 after deleting, got %d", path, response.Code)
+		}
+	}
+}
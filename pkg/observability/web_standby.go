@@ -0,0 +1,99 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/failover"
+)
+
+// SetWarmStandby serves the API only while this server is primary of a warm
+// standby pair; readiness fails otherwise so load balancers route to the
+// primary
+func (wd *WebDashboard) SetWarmStandby(standby *WarmStandby) {
+	wd.mu.Lock()
+	wd.warmStandby = standby
+	wd.mu.Unlock()
+
+	wd.RegisterHealthCheck("warm_standby", func() error {
+		status := standby.elector.Status()
+		if status.Role != failover.RolePrimary {
+			return fmt.Errorf("%s at epoch %d, primary is %s", status.Role, status.Epoch, status.Primary)
+		}
+		return nil
+	})
+}
+
+// requirePrimary stamps API responses with the lease epoch and turns API
+// requests away from a standby or fenced server, naming the primary in
+// X-AgentaFlow-Primary so clients can reconnect to it
+func (wd *WebDashboard) requirePrimary(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wd.mu.RLock()
+		standby := wd.warmStandby
+		wd.mu.RUnlock()
+		if standby == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		status := standby.elector.Status()
+		w.Header().Set("X-AgentaFlow-Epoch", strconv.FormatUint(status.Epoch, 10))
+		if status.Role == failover.RolePrimary || strings.HasPrefix(r.URL.Path, "/api/v1/standby/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if status.Primary != "" && status.Holder != status.NodeID {
+			w.Header().Set("X-AgentaFlow-Primary", status.Primary)
+		}
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, fmt.Sprintf("%s is %s, not primary", status.NodeID, status.Role), http.StatusServiceUnavailable)
+	})
+}
+
+// handleStandbyState sends the standby the WAL records after ?since and the
+// alert history
+func (wd *WebDashboard) handleStandbyState(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	standby := wd.warmStandby
+	wd.mu.RUnlock()
+	if standby == nil {
+		http.Error(w, "warm standby not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var since uint64
+	if value := r.URL.Query().Get("since"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid since: "+value, http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+	state, err := standby.State(since)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	json.NewEncoder(w).Encode(state)
+}
+
+// handleStandbyStatus reports this server's role and replication progress
+func (wd *WebDashboard) handleStandbyStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	wd.mu.RLock()
+	standby := wd.warmStandby
+	wd.mu.RUnlock()
+	if standby == nil {
+		http.Error(w, "warm standby not configured", http.StatusServiceUnavailable)
+		return
+	}
+	json.NewEncoder(w).Encode(standby.GetStats())
+}
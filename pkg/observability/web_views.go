@@ -0,0 +1,201 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
+	"github.com/gorilla/mux"
+)
+
+// SetSavedViews enables saved views: named filter sets stored server-side
+// and applied to any API request with ?view=<name>
+func (wd *WebDashboard) SetSavedViews(store *SavedViewStore) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.savedViews = store
+}
+
+// getSavedViews returns the view store, or reports 503 when none is set
+func (wd *WebDashboard) getSavedViews(w http.ResponseWriter) *SavedViewStore {
+	wd.mu.RLock()
+	store := wd.savedViews
+	wd.mu.RUnlock()
+	if store == nil {
+		http.Error(w, "saved views not configured", http.StatusServiceUnavailable)
+	}
+	return store
+}
+
+// applySavedView fills in the filters of the view named by ?view. Parameters
+// given in the request override the view's.
+func (wd *WebDashboard) applySavedView(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("view")
+		if name == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		store := wd.getSavedViews(w)
+		if store == nil {
+			return
+		}
+		view, exists := store.Get(name)
+		if !exists {
+			http.Error(w, fmt.Sprintf("view %s not found", name), http.StatusNotFound)
+			return
+		}
+
+		query := view.Query()
+		for key, values := range r.URL.Query() {
+			if key != "view" {
+				query[key] = values
+			}
+		}
+		filtered := r.Clone(r.Context())
+		filtered.URL.RawQuery = query.Encode()
+		next.ServeHTTP(w, filtered)
+	})
+}
+
+// canEditView reports whether the caller saved the view or is an admin
+func (wd *WebDashboard) canEditView(r *http.Request, view SavedView) bool {
+	caller, _ := wd.authenticate(r)
+	return view.Owner == "" || view.Owner == caller.actor || caller.hasScope(apikeys.ScopeAdmin)
+}
+
+// decodeView reads a view from a request body
+func decodeView(w http.ResponseWriter, r *http.Request) (SavedView, bool) {
+	var view SavedView
+	if err := json.NewDecoder(r.Body).Decode(&view); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return view, false
+	}
+	return view, true
+}
+
+// handleListViews lists the saved views
+func (wd *WebDashboard) handleListViews(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	store := wd.getSavedViews(w)
+	if store == nil {
+		return
+	}
+	json.NewEncoder(w).Encode(store.List())
+}
+
+// handleGetView returns the view named in the path
+func (wd *WebDashboard) handleGetView(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	store := wd.getSavedViews(w)
+	if store == nil {
+		return
+	}
+	name := mux.Vars(r)["name"]
+	view, exists := store.Get(name)
+	if !exists {
+		http.Error(w, fmt.Sprintf("view %s not found", name), http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(view)
+}
+
+// handleCreateView saves a new view owned by the caller
+func (wd *WebDashboard) handleCreateView(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	store := wd.getSavedViews(w)
+	if store == nil {
+		return
+	}
+	view, ok := decodeView(w, r)
+	if !ok {
+		return
+	}
+	if _, exists := store.Get(view.Name); exists {
+		http.Error(w, fmt.Sprintf("view %s already exists", view.Name), http.StatusConflict)
+		return
+	}
+	view.Owner = controlActor(r)
+
+	saved, err := store.Save(view)
+	if err != nil {
+		writeViewError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(saved)
+}
+
+// handleUpdateView replaces the view named in the path; only its owner or
+// an admin may
+func (wd *WebDashboard) handleUpdateView(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	store := wd.getSavedViews(w)
+	if store == nil {
+		return
+	}
+	view, ok := decodeView(w, r)
+	if !ok {
+		return
+	}
+	name := mux.Vars(r)["name"]
+	if view.Name != name {
+		http.Error(w, "view name does not match the path", http.StatusBadRequest)
+		return
+	}
+	previous, exists := store.Get(name)
+	if !exists {
+		http.Error(w, fmt.Sprintf("view %s not found", name), http.StatusNotFound)
+		return
+	}
+	if !wd.canEditView(r, previous) {
+		http.Error(w, fmt.Sprintf("view %s belongs to %s", name, previous.Owner), http.StatusForbidden)
+		return
+	}
+	view.Owner = previous.Owner
+
+	saved, err := store.Save(view)
+	if err != nil {
+		writeViewError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(saved)
+}
+
+// handleDeleteView removes the view named in the path; only its owner or
+// an admin may
+func (wd *WebDashboard) handleDeleteView(w http.ResponseWriter, r *http.Request) {
+	store := wd.getSavedViews(w)
+	if store == nil {
+		return
+	}
+	name := mux.Vars(r)["name"]
+	view, exists := store.Get(name)
+	if !exists {
+		http.Error(w, fmt.Sprintf("view %s not found", name), http.StatusNotFound)
+		return
+	}
+	if !wd.canEditView(r, view) {
+		http.Error(w, fmt.Sprintf("view %s belongs to %s", name, view.Owner), http.StatusForbidden)
+		return
+	}
+	if _, err := store.Delete(name); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeViewError reports an invalid view as 400 and a failed write as 500
+func writeViewError(w http.ResponseWriter, err error) {
+	if _, invalid := err.(ConfigErrors); invalid {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
@@ -0,0 +1,83 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
+)
+
+func TestSavedViewEndpoints(t *testing.T) {
+	layout := DefaultDashboardLayoutConfig()
+	layout.Pools["research"] = DashboardLayout{Panels: []DashboardPanel{{ID: PanelGPUGrid, Width: 12}}}
+	dashboard := NewWebDashboard(NewMonitoringService(100), nil, nil, WebDashboardConfig{
+		Port:          0,
+		ControlTokens: map[string]string{"s3cret": "admin"},
+		Layout:        layout,
+	})
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/views", ""); response.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a view store, got %d", response.Code)
+	}
+
+	views, _ := NewSavedViewStore("")
+	dashboard.SetSavedViews(views)
+	keys, _ := apikeys.NewStore("")
+	dashboard.SetAPIKeyStore(keys)
+	_, alice, _ := keys.Create(apikeys.KeyRequest{Name: "alice", Scopes: []apikeys.Scope{apikeys.ScopeReadMetrics}})
+	_, bob, _ := keys.Create(apikeys.KeyRequest{Name: "bob", Scopes: []apikeys.Scope{apikeys.ScopeReadMetrics}})
+
+	body := `{"name": "research", "pool": "research", "selector": "gpu_type=h100", "range": "6h"}`
+	response := serveAs(dashboard, alice, http.MethodPost, "/api/v1/views", body)
+	if response.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", response.Code, response.Body.String())
+	}
+	var created SavedView
+	json.Unmarshal(response.Body.Bytes(), &created)
+	if created.Owner != "apikey:alice" {
+		t.Errorf("Expected alice to own the view, got %+v", created)
+	}
+	if response := serveAs(dashboard, bob, http.MethodPost, "/api/v1/views", body); response.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a duplicate view, got %d", response.Code)
+	}
+	if response := serveAs(dashboard, alice, http.MethodPost, "/api/v1/views", `{"name": "x", "range": "5m"}`); response.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid view, got %d", response.Code)
+	}
+
+	// ?view fills in the view's filters
+	response = serveDashboard(dashboard, http.MethodGet, "/api/v1/layout/render?view=research", "")
+	var rendered DashboardLayout
+	json.Unmarshal(response.Body.Bytes(), &rendered)
+	if len(rendered.Panels) != 1 {
+		t.Errorf("Expected the research pool's layout, got %+v", rendered)
+	}
+	response = serveDashboard(dashboard, http.MethodGet, "/api/v1/layout/render?view=research&pool=", "")
+	json.Unmarshal(response.Body.Bytes(), &rendered)
+	if len(rendered.Panels) != 6 {
+		t.Errorf("Expected an explicit pool to override the view, got %+v", rendered)
+	}
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/layout/render?view=missing", ""); response.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown view, got %d", response.Code)
+	}
+
+	// Only the owner or an admin may change a view
+	update := `{"name": "research", "pool": "research", "range": "24h"}`
+	if response := serveAs(dashboard, bob, http.MethodPut, "/api/v1/views/research", update); response.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for another user's view, got %d", response.Code)
+	}
+	if response := serveAs(dashboard, alice, http.MethodPut, "/api/v1/views/research", update); response.Code != http.StatusOK {
+		t.Errorf("Expected the owner to update the view, got %d: %s", response.Code, response.Body.String())
+	}
+	if view, _ := views.Get("research"); view.Range != "24h" || view.Owner != "apikey:alice" {
+		t.Errorf("Expected the update to keep the owner, got %+v", view)
+	}
+	if response := serveAs(dashboard, bob, http.MethodDelete, "/api/v1/views/research", ""); response.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 deleting another user's view, got %d", response.Code)
+	}
+	if response := serveAs(dashboard, "s3cret", http.MethodDelete, "/api/v1/views/research", ""); response.Code != http.StatusNoContent {
+		t.Errorf("Expected an admin to delete the view, got %d", response.Code)
+	}
+	if response := serveDashboard(dashboard, http.MethodGet, "/api/v1/views/research", ""); response.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 after deletion, got %d", response.Code)
+	}
+}
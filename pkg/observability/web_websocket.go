@@ -1,15 +1,39 @@
 package observability
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 )
 
+const (
+	// wsProtocol is the subprotocol the dashboard accepts WebSocket connections with
+	wsProtocol = "agentaflow.v1"
+	// wsTokenProtocolPrefix precedes a control token offered as a second
+	// subprotocol, base64url encoded without padding. The server never selects
+	// it, so the token is not echoed back nor kept in URLs and access logs.
+	wsTokenProtocolPrefix = "agentaflow.token."
+)
+
+// wsProtocolToken returns the control token offered as a subprotocol, or ""
+func wsProtocolToken(r *http.Request) string {
+	for _, protocol := range websocket.Subprotocols(r) {
+		if strings.HasPrefix(protocol, wsTokenProtocolPrefix) {
+			token, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(protocol, wsTokenProtocolPrefix))
+			if err == nil {
+				return string(token)
+			}
+		}
+	}
+	return ""
+}
+
 // handleWebSocket handles WebSocket connections for real-time updates
 func (wd *WebDashboard) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	conn, err := wd.wsUpgrader.Upgrade(w, r, nil)
@@ -19,6 +43,18 @@ func (wd *WebDashboard) handleWebSocket(w http.ResponseWriter, r *http.Request)
 	}
 	defer conn.Close()
 
+	// A control token identifying the user for notification preferences comes
+	// as a subprotocol from browsers, which cannot set headers on WebSocket
+	// requests, or as a bearer token from other clients, unless they logged in
+	// with single sign-on
+	user := wd.tokenActor(wsProtocolToken(r))
+	if user == "" {
+		user = wd.tokenActor(strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")))
+	}
+	if session, exists := wd.loginSession(r); user == "" && exists {
+		user = session.actor()
+	}
+
 	// Register connection with write mutex
 	connMutex := &sync.Mutex{}
 	wd.wsMutex.Lock()
@@ -27,6 +63,23 @@ func (wd *WebDashboard) handleWebSocket(w http.ResponseWriter, r *http.Request)
 		wd.wsWriteMutexes = make(map[*websocket.Conn]*sync.Mutex)
 	}
 	wd.wsWriteMutexes[conn] = connMutex
+	if user != "" {
+		wd.wsUsers[conn] = user
+	}
+	// Automation consuming the stream may ask for binary MessagePack frames
+	if r.URL.Query().Get("encoding") == EncodingMsgpack {
+		if wd.wsEncodings == nil {
+			wd.wsEncodings = make(map[*websocket.Conn]string)
+		}
+		wd.wsEncodings[conn] = EncodingMsgpack
+	}
+	// Delta clients get changed GPU fields only, after a first keyframe
+	if delta := r.URL.Query().Get("delta"); delta == "1" || delta == "true" {
+		if wd.wsDeltaClients == nil {
+			wd.wsDeltaClients = make(map[*websocket.Conn]bool)
+		}
+		wd.wsDeltaClients[conn] = true
+	}
 	wd.wsMutex.Unlock()
 
 	log.Printf("New WebSocket connection established from %s", r.RemoteAddr)
@@ -39,6 +92,9 @@ func (wd *WebDashboard) handleWebSocket(w http.ResponseWriter, r *http.Request)
 		wd.wsMutex.Lock()
 		delete(wd.wsConnections, conn)
 		delete(wd.wsWriteMutexes, conn)
+		delete(wd.wsUsers, conn)
+		delete(wd.wsEncodings, conn)
+		delete(wd.wsDeltaClients, conn)
 		wd.wsMutex.Unlock()
 		log.Printf("WebSocket connection closed from %s", r.RemoteAddr)
 	}()
@@ -168,6 +224,7 @@ func (wd *WebDashboard) buildMetricsMessage() map[string]interface{} {
 		SystemStats: wd.calculateSystemStats(),
 		CostData:    wd.lastCostData,
 		Alerts:      wd.getActiveAlerts(),
+		Incidents:   wd.getOpenIncidents(),
 		Performance: wd.calculatePerformanceMetrics(),
 	}
 	wd.mu.RUnlock()
@@ -178,8 +235,19 @@ func (wd *WebDashboard) buildMetricsMessage() map[string]interface{} {
 	}
 }
 
-// broadcastToAllConnections sends a message to all connected WebSocket clients
+// broadcastToAllConnections sends a message to all connected WebSocket
+// clients, on every replica when a broadcast bus is set
 func (wd *WebDashboard) broadcastToAllConnections(message interface{}) {
+	if wd.broadcastBus() != nil {
+		if data, err := json.Marshal(message); err == nil && wd.publishBroadcast(broadcastEnvelope{Message: data}) {
+			return
+		}
+	}
+	wd.deliverToLocalConnections(message)
+}
+
+// deliverToLocalConnections sends a message to this replica's clients
+func (wd *WebDashboard) deliverToLocalConnections(message interface{}) {
 	wd.wsMutex.RLock()
 	connections := make([]*websocket.Conn, 0, len(wd.wsConnections))
 	for conn := range wd.wsConnections {
@@ -192,36 +260,37 @@ func (wd *WebDashboard) broadcastToAllConnections(message interface{}) {
 	}
 }
 
-// broadcastMetrics sends current metrics to all connected WebSocket clients
+// broadcastMetrics sends current metrics to all connected WebSocket
+// clients, as deltas to those that asked for them
 func (wd *WebDashboard) broadcastMetrics() {
-	wd.mu.RLock()
-
-	gpuMetricsInterface := make(map[string]interface{})
-	for k, v := range wd.lastMetrics {
-		gpuMetricsInterface[k] = v
+	full, delta := wd.metricsMessages()
+	var deltaMessage interface{}
+	if delta != nil {
+		deltaMessage = delta
 	}
 
-	metrics := DashboardMetrics{
-		Timestamp:   time.Now(),
-		GPUMetrics:  gpuMetricsInterface,
-		SystemStats: wd.calculateSystemStats(),
-		CostData:    wd.lastCostData,
-		Alerts:      wd.getActiveAlerts(),
-		Performance: wd.calculatePerformanceMetrics(),
-	}
-	wd.mu.RUnlock()
-
-	message := map[string]interface{}{
-		"type": "metrics_update",
-		"data": metrics,
+	if wd.broadcastBus() != nil {
+		var envelope broadcastEnvelope
+		var err error
+		if envelope.Message, err = json.Marshal(full); err == nil && delta != nil {
+			envelope.Delta, err = json.Marshal(delta)
+		}
+		if err == nil && wd.publishBroadcast(envelope) {
+			return
+		}
 	}
-
-	wd.broadcastToAllConnections(message)
+	wd.deliverMetrics(full, deltaMessage)
 }
 
 // sendMetricsToConnection sends current metrics to a specific connection
 func (wd *WebDashboard) sendMetricsToConnection(conn *websocket.Conn) {
 	wd.mu.RLock()
+	if wd.isReplica() && wd.relayedMetrics != nil {
+		relayed := wd.relayedMetrics
+		wd.mu.RUnlock()
+		wd.sendToConnection(conn, relayed)
+		return
+	}
 
 	gpuMetricsInterface := make(map[string]interface{})
 	for k, v := range wd.lastMetrics {
@@ -234,6 +303,7 @@ func (wd *WebDashboard) sendMetricsToConnection(conn *websocket.Conn) {
 		SystemStats: wd.calculateSystemStats(),
 		CostData:    wd.lastCostData,
 		Alerts:      wd.getActiveAlerts(),
+		Incidents:   wd.getOpenIncidents(),
 		Performance: wd.calculatePerformanceMetrics(),
 	}
 	wd.mu.RUnlock()
@@ -255,21 +325,39 @@ func (wd *WebDashboard) sendToConnection(conn *websocket.Conn, message interface
 			wd.wsMutex.Lock()
 			delete(wd.wsConnections, conn)
 			delete(wd.wsWriteMutexes, conn)
+			delete(wd.wsUsers, conn)
+			delete(wd.wsEncodings, conn)
+			delete(wd.wsDeltaClients, conn)
 			wd.wsMutex.Unlock()
 		}
 	}()
 
 	wd.wsMutex.RLock()
 	writeMutex, exists := wd.wsWriteMutexes[conn]
+	encoding := wd.wsEncodings[conn]
 	wd.wsMutex.RUnlock()
 
 	if !exists {
 		return
 	}
 
+	var frame []byte
+	if encoding == EncodingMsgpack {
+		var err error
+		if frame, err = encodeResponse(EncodingMsgpack, message); err != nil {
+			log.Printf("WebSocket encode error: %v", err)
+			return
+		}
+	}
+
 	writeMutex.Lock()
 	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-	err := conn.WriteJSON(message)
+	var err error
+	if frame != nil {
+		err = conn.WriteMessage(websocket.BinaryMessage, frame)
+	} else {
+		err = conn.WriteJSON(message)
+	}
 	writeMutex.Unlock()
 
 	if err != nil {
@@ -278,6 +366,9 @@ func (wd *WebDashboard) sendToConnection(conn *websocket.Conn, message interface
 		wd.wsMutex.Lock()
 		delete(wd.wsConnections, conn)
 		delete(wd.wsWriteMutexes, conn)
+		delete(wd.wsUsers, conn)
+		delete(wd.wsEncodings, conn)
+		delete(wd.wsDeltaClients, conn)
 		wd.wsMutex.Unlock()
 	}
 }
@@ -289,15 +380,54 @@ func (wd *WebDashboard) GetActiveConnections() int {
 	return len(wd.wsConnections)
 }
 
-// BroadcastAlert sends an alert to all connected clients immediately
+// BroadcastAlert sends an alert to all connected clients immediately. Every
+// client shows the alert; "notify" tells it whether to also raise a browser
+// notification under its user's preferences.
 func (wd *WebDashboard) BroadcastAlert(alert Alert) {
+	if wd.publishBroadcast(broadcastEnvelope{Alert: &alert}) {
+		return
+	}
+	wd.deliverAlert(alert)
+}
+
+// deliverAlert sends an alert to this replica's clients
+func (wd *WebDashboard) deliverAlert(alert Alert) {
+	wd.mu.RLock()
+	store := wd.notificationPrefs
+	wd.mu.RUnlock()
+
+	wd.wsMutex.RLock()
+	users := make(map[*websocket.Conn]string, len(wd.wsConnections))
+	for conn := range wd.wsConnections {
+		users[conn] = wd.wsUsers[conn]
+	}
+	wd.wsMutex.RUnlock()
+
+	for conn, user := range users {
+		notify := true
+		if store != nil && user != "" {
+			notify = store.Get(user).Wants(alert.Level, ChannelBrowser)
+		}
+		wd.sendToConnection(conn, map[string]interface{}{
+			"type":   "alert",
+			"data":   alert,
+			"notify": notify,
+		})
+	}
+	log.Printf("Broadcasted alert to %d connections: %s", wd.GetActiveConnections(), alert.Message)
+}
+
+// BroadcastIncident sends an incident change to all connected clients
+func (wd *WebDashboard) BroadcastIncident(incident Incident, change string) {
 	message := map[string]interface{}{
-		"type": "alert",
-		"data": alert,
+		"type": "incident",
+		"data": map[string]interface{}{
+			"change":   change,
+			"incident": incident,
+		},
 	}
 
 	wd.broadcastToAllConnections(message)
-	log.Printf("Broadcasted alert to %d connections: %s", wd.GetActiveConnections(), alert.Message)
 }
 
 // BroadcastSystemUpdate sends a system status update to all connected clients
@@ -0,0 +1,211 @@
+package observability
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// TimeWindow is a time range, e.g. this week
+type TimeWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// WeekOverWeek returns the 7 days up to end and the 7 days before them
+func WeekOverWeek(end time.Time) (baseline, current TimeWindow) {
+	week := 7 * 24 * time.Hour
+	current = TimeWindow{Start: end.Add(-week), End: end}
+	baseline = TimeWindow{Start: current.Start.Add(-week), End: current.Start}
+	return baseline, current
+}
+
+// ComparisonConfig sets when a change between two windows is significant
+type ComparisonConfig struct {
+	SignificantPercent float64 // Relative change in cost and alert counts
+	UtilizationPoints  float64 // Change in average utilization, in percentage points
+	MinAlerts          int     // Alert count changes smaller than this are never significant
+}
+
+// DefaultComparisonConfig flags cost and alert changes of 10% or more and
+// utilization shifts of 5 points or more
+func DefaultComparisonConfig() ComparisonConfig {
+	return ComparisonConfig{
+		SignificantPercent: 10,
+		UtilizationPoints:  5,
+		MinAlerts:          3,
+	}
+}
+
+// WindowSummary aggregates utilization, cost and alerts over one window
+type WindowSummary struct {
+	TimeWindow
+	AvgUtilization     float64        `json:"avg_utilization"`
+	PeakUtilization    float64        `json:"peak_utilization"`
+	UtilizationSamples int            `json:"utilization_samples"`
+	GPUs               int            `json:"gpus"` // GPUs reporting utilization
+	TotalCost          float64        `json:"total_cost"`
+	GPUHours           float64        `json:"gpu_hours"`
+	Alerts             int            `json:"alerts"`
+	AlertsBySeverity   map[string]int `json:"alerts_by_severity"`
+	AlertsByType       map[string]int `json:"alerts_by_type"`
+}
+
+// MetricChange is how one aggregate moved between the windows. Percent is
+// nil when the baseline is zero.
+type MetricChange struct {
+	Metric      string   `json:"metric"`
+	Baseline    float64  `json:"baseline"`
+	Current     float64  `json:"current"`
+	Delta       float64  `json:"delta"`
+	Percent     *float64 `json:"percent"`
+	Significant bool     `json:"significant"`
+	points      bool     // Delta is in percentage points
+}
+
+// WindowComparison compares a current window against a baseline window
+type WindowComparison struct {
+	Baseline           WindowSummary  `json:"baseline"`
+	Current            WindowSummary  `json:"current"`
+	Changes            []MetricChange `json:"changes"`
+	SignificantChanges []string       `json:"significant_changes"` // Readable descriptions
+}
+
+// CompareWindows summarises both windows and the changes between them.
+// Windows of different lengths are compared as totals, not rates.
+func (ms *MonitoringService) CompareWindows(baseline, current TimeWindow, config ComparisonConfig) (*WindowComparison, error) {
+	if !baseline.End.After(baseline.Start) {
+		return nil, fmt.Errorf("baseline window must end after it starts")
+	}
+	if !current.End.After(current.Start) {
+		return nil, fmt.Errorf("current window must end after it starts")
+	}
+
+	comparison := &WindowComparison{
+		Baseline:           ms.summariseWindow(baseline),
+		Current:            ms.summariseWindow(current),
+		SignificantChanges: make([]string, 0),
+	}
+	a, b := comparison.Baseline, comparison.Current
+
+	comparison.Changes = []MetricChange{
+		pointChange("avg_utilization", a.AvgUtilization, b.AvgUtilization, config.UtilizationPoints),
+		pointChange("peak_utilization", a.PeakUtilization, b.PeakUtilization, config.UtilizationPoints),
+		relativeChange("total_cost", a.TotalCost, b.TotalCost, config.SignificantPercent, 0),
+		relativeChange("gpu_hours", a.GPUHours, b.GPUHours, config.SignificantPercent, 0),
+		relativeChange("alerts", float64(a.Alerts), float64(b.Alerts), config.SignificantPercent, config.MinAlerts),
+	}
+	for _, severity := range alertKeys(a.AlertsBySeverity, b.AlertsBySeverity) {
+		comparison.Changes = append(comparison.Changes, relativeChange("alerts_"+severity,
+			float64(a.AlertsBySeverity[severity]), float64(b.AlertsBySeverity[severity]), config.SignificantPercent, config.MinAlerts))
+	}
+
+	for _, change := range comparison.Changes {
+		if change.Significant {
+			comparison.SignificantChanges = append(comparison.SignificantChanges, change.describe())
+		}
+	}
+	return comparison, nil
+}
+
+// summariseWindow aggregates the metrics, costs and alert events in a window
+func (ms *MonitoringService) summariseWindow(window TimeWindow) WindowSummary {
+	summary := WindowSummary{
+		TimeWindow:       window,
+		AlertsBySeverity: make(map[string]int),
+		AlertsByType:     make(map[string]int),
+	}
+
+	var total float64
+	gpus := make(map[string]bool)
+	for _, metric := range ms.GetMetrics(window.Start, window.End, "gpu_utilization_percent") {
+		total += metric.Value
+		summary.UtilizationSamples++
+		if metric.Value > summary.PeakUtilization {
+			summary.PeakUtilization = metric.Value
+		}
+		gpus[metric.Labels["gpu_id"]] = true
+	}
+	if summary.UtilizationSamples > 0 {
+		summary.AvgUtilization = total / float64(summary.UtilizationSamples)
+	}
+	summary.GPUs = len(gpus)
+
+	costs := ms.GetCostSummary(window.Start, window.End)
+	summary.TotalCost, _ = costs["total_cost"].(float64)
+	summary.GPUHours, _ = costs["total_gpu_hours"].(float64)
+
+	for _, event := range ms.GetEvents(window.Start, window.End, "") {
+		if event.Type != "gpu_alert" {
+			continue
+		}
+		summary.Alerts++
+		summary.AlertsBySeverity[event.Severity]++
+		if alertType, ok := event.Metadata["alert_type"].(string); ok {
+			summary.AlertsByType[alertType]++
+		}
+	}
+	return summary
+}
+
+// pointChange compares percentages, significant when they move by at least
+// points
+func pointChange(metric string, baseline, current, points float64) MetricChange {
+	change := newMetricChange(metric, baseline, current)
+	change.points = true
+	change.Significant = points > 0 && math.Abs(change.Delta) >= points
+	return change
+}
+
+// relativeChange compares totals, significant when they move by at least
+// percent and, for counts, by at least minDelta
+func relativeChange(metric string, baseline, current, percent float64, minDelta int) MetricChange {
+	change := newMetricChange(metric, baseline, current)
+	if math.Abs(change.Delta) < math.Max(float64(minDelta), 1e-9) {
+		return change
+	}
+	change.Significant = change.Percent == nil || math.Abs(*change.Percent) >= percent
+	return change
+}
+
+func newMetricChange(metric string, baseline, current float64) MetricChange {
+	change := MetricChange{Metric: metric, Baseline: baseline, Current: current, Delta: current - baseline}
+	if baseline != 0 {
+		percent := change.Delta / baseline * 100
+		change.Percent = &percent
+	}
+	return change
+}
+
+// describe words a change, e.g. "total_cost up 25.0% (200.00 to 250.00)"
+func (c MetricChange) describe() string {
+	direction := "up"
+	if c.Delta < 0 {
+		direction = "down"
+	}
+	if c.points {
+		return fmt.Sprintf("%s %s %.1f points (%.1f%% to %.1f%%)", c.Metric, direction, math.Abs(c.Delta), c.Baseline, c.Current)
+	}
+	if c.Percent == nil {
+		return fmt.Sprintf("%s %s from 0 to %.2f", c.Metric, direction, c.Current)
+	}
+	return fmt.Sprintf("%s %s %.1f%% (%.2f to %.2f)", c.Metric, direction, math.Abs(*c.Percent), c.Baseline, c.Current)
+}
+
+// alertKeys returns the keys of both maps, sorted
+func alertKeys(a, b map[string]int) []string {
+	seen := make(map[string]bool)
+	for key := range a {
+		seen[key] = true
+	}
+	for key := range b {
+		seen[key] = true
+	}
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
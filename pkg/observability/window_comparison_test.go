@@ -0,0 +1,119 @@
+package observability
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// recordWindow records utilization samples, a cost and alerts, and returns
+// the window they fall in
+func recordWindow(monitor *MonitoringService, utilization []float64, cost float64, alerts map[string]int) TimeWindow {
+	start := time.Now()
+	time.Sleep(time.Millisecond)
+	for i, value := range utilization {
+		monitor.RecordMetric(Metric{
+			Name:   "gpu_utilization_percent",
+			Value:  value,
+			Labels: map[string]string{"gpu_id": string(rune('0' + i%2))},
+		})
+	}
+	monitor.RecordCost(CostEntry{Operation: "training", Cost: cost, GPUHours: cost / 2})
+	for severity, count := range alerts {
+		for i := 0; i < count; i++ {
+			monitor.RecordEvent(Event{Type: "gpu_alert", Severity: severity, Metadata: map[string]interface{}{"alert_type": "temperature"}})
+		}
+	}
+	monitor.RecordEvent(Event{Type: "workload_lifecycle", Severity: "info"})
+	time.Sleep(time.Millisecond)
+	return TimeWindow{Start: start, End: time.Now()}
+}
+
+func TestCompareWindowsFlagsSignificantChanges(t *testing.T) {
+	monitor := NewMonitoringService(1000)
+	baseline := recordWindow(monitor, []float64{40, 60}, 200, map[string]int{"warning": 4, "critical": 1})
+	current := recordWindow(monitor, []float64{70, 90}, 210, map[string]int{"warning": 10, "critical": 2})
+
+	comparison, err := monitor.CompareWindows(baseline, current, DefaultComparisonConfig())
+	if err != nil {
+		t.Fatalf("CompareWindows failed: %v", err)
+	}
+	if comparison.Baseline.AvgUtilization != 50 || comparison.Current.PeakUtilization != 90 || comparison.Current.GPUs != 2 {
+		t.Errorf("Unexpected utilization summaries %+v and %+v", comparison.Baseline, comparison.Current)
+	}
+	if comparison.Baseline.Alerts != 5 || comparison.Current.Alerts != 12 || comparison.Current.AlertsByType["temperature"] != 12 {
+		t.Errorf("Expected only GPU alerts counted, got %+v and %+v", comparison.Baseline, comparison.Current)
+	}
+
+	changes := make(map[string]MetricChange)
+	for _, change := range comparison.Changes {
+		changes[change.Metric] = change
+	}
+	if change := changes["avg_utilization"]; change.Delta != 30 || !change.Significant {
+		t.Errorf("Expected a significant 30 point utilization rise, got %+v", change)
+	}
+	if change := changes["total_cost"]; change.Percent == nil || *change.Percent != 5 || change.Significant {
+		t.Errorf("Expected a 5%% cost rise below the threshold, got %+v", change)
+	}
+	if change := changes["alerts_warning"]; change.Delta != 6 || !change.Significant {
+		t.Errorf("Expected a significant rise in warnings, got %+v", change)
+	}
+	if change := changes["alerts_critical"]; change.Significant {
+		t.Errorf("Expected one more critical alert to fall under the minimum, got %+v", change)
+	}
+	if len(comparison.SignificantChanges) != 4 {
+		t.Errorf("Expected utilization, peak, alerts and warnings to change significantly, got %v", comparison.SignificantChanges)
+	}
+
+	if _, err := monitor.CompareWindows(current, TimeWindow{Start: current.End, End: current.Start}, DefaultComparisonConfig()); err == nil {
+		t.Error("Expected an inverted window to be rejected")
+	}
+}
+
+func TestCompareWindowsEndpoint(t *testing.T) {
+	monitor := NewMonitoringService(1000)
+	baseline := recordWindow(monitor, []float64{50}, 100, nil)
+	current := recordWindow(monitor, []float64{50}, 150, nil)
+	dashboard := NewWebDashboard(monitor, nil, nil, WebDashboardConfig{Port: 0})
+
+	query := url.Values{}
+	query.Set("baseline_start", baseline.Start.Format(time.RFC3339Nano))
+	query.Set("baseline_end", baseline.End.Format(time.RFC3339Nano))
+	query.Set("current_start", current.Start.Format(time.RFC3339Nano))
+	query.Set("current_end", current.End.Format(time.RFC3339Nano))
+	response := serveDashboard(dashboard, http.MethodGet, "/api/v1/performance/compare?"+query.Encode(), "")
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", response.Code, response.Body.String())
+	}
+	var comparison WindowComparison
+	if err := json.Unmarshal(response.Body.Bytes(), &comparison); err != nil {
+		t.Fatalf("Failed to decode comparison: %v", err)
+	}
+	if comparison.Baseline.TotalCost != 100 || comparison.Current.TotalCost != 150 {
+		t.Errorf("Expected costs of 100 and 150, got %+v", comparison)
+	}
+	if len(comparison.SignificantChanges) != 2 {
+		t.Errorf("Expected cost and GPU hours to change significantly, got %v", comparison.SignificantChanges)
+	}
+
+	// Defaults to this week against last week
+	response = serveDashboard(dashboard, http.MethodGet, "/api/v1/performance/compare", "")
+	if err := json.Unmarshal(response.Body.Bytes(), &comparison); err != nil {
+		t.Fatalf("Failed to decode comparison: %v", err)
+	}
+	if comparison.Current.TotalCost != 250 || comparison.Current.End.Sub(comparison.Current.Start) != 7*24*time.Hour {
+		t.Errorf("Expected this week's costs, got %+v", comparison.Current)
+	}
+
+	for _, path := range []string{
+		"/api/v1/performance/compare?current_start=yesterday",
+		"/api/v1/performance/compare?threshold=-5",
+		"/api/v1/performance/compare?baseline_start=" + url.QueryEscape(current.End.Format(time.RFC3339Nano)) + "&baseline_end=" + url.QueryEscape(current.Start.Format(time.RFC3339Nano)),
+	} {
+		if response := serveDashboard(dashboard, http.MethodGet, path, ""); response.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", path, response.Code)
+		}
+	}
+}
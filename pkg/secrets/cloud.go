@@ -0,0 +1,338 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSConfig is access to AWS Secrets Manager. Empty fields use the standard
+// AWS_* environment variables.
+type AWSConfig struct {
+	Region          string `yaml:"region" json:"region"`
+	AccessKeyID     string `yaml:"access_key_id" json:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key" json:"-"`
+	SessionToken    string `yaml:"session_token" json:"-"`
+	Endpoint        string `yaml:"endpoint" json:"endpoint"` // VPC endpoint or emulator; empty uses the regional endpoint
+}
+
+// AWSProvider reads AWS Secrets Manager secrets: aws-sm:SECRET-ID#FIELD. With
+// a field, the secret string must be a JSON object.
+type AWSProvider struct {
+	config AWSConfig
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewAWSProvider creates an AWS Secrets Manager provider
+func NewAWSProvider(config AWSConfig, timeout time.Duration) *AWSProvider {
+	return &AWSProvider{config: config, client: &http.Client{Timeout: timeout}, now: time.Now}
+}
+
+// Get reads the current version of a secret
+func (p *AWSProvider) Get(ctx context.Context, name string) (string, error) {
+	region := firstNonEmpty(p.config.Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"))
+	accessKey := firstNonEmpty(p.config.AccessKeyID, os.Getenv("AWS_ACCESS_KEY_ID"))
+	secretKey := firstNonEmpty(p.config.SecretAccessKey, os.Getenv("AWS_SECRET_ACCESS_KEY"))
+	sessionToken := firstNonEmpty(p.config.SessionToken, os.Getenv("AWS_SESSION_TOKEN"))
+	if region == "" || accessKey == "" || secretKey == "" {
+		return "", fmt.Errorf("aws region and credentials are required (set AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY)")
+	}
+	id, field := splitField(name)
+
+	endpoint := firstNonEmpty(p.config.Endpoint, "https://secretsmanager."+region+".amazonaws.com")
+	body, err := json.Marshal(map[string]string{"SecretId": id})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+	signAWSRequest(req, body, region, "secretsmanager", accessKey, secretKey, p.now().UTC())
+
+	var response struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := doJSON(p.client, req, &response); err != nil {
+		return "", err
+	}
+	return pickJSONField(response.SecretString, field)
+}
+
+// signAWSRequest adds a Signature Version 4 Authorization header covering
+// the request's host, X-Amz-* and Content-Type headers and its body
+func signAWSRequest(req *http.Request, body []byte, region, service, accessKey, secretKey string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		lower := strings.ToLower(key)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method, path, req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, hashHex(body),
+	}, "\n")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// GCPConfig is access to Google Cloud Secret Manager
+type GCPConfig struct {
+	// OAuth access token; empty uses GOOGLE_OAUTH_ACCESS_TOKEN, then the
+	// metadata server's service account on GCE and GKE
+	AccessToken string `yaml:"access_token" json:"-"`
+	Endpoint    string `yaml:"endpoint" json:"endpoint"` // Empty uses https://secretmanager.googleapis.com
+}
+
+// GCPProvider reads Secret Manager secrets:
+// gcp-sm:projects/P/secrets/S[/versions/V]#FIELD. The latest version is used
+// when none is named.
+type GCPProvider struct {
+	config      GCPConfig
+	client      *http.Client
+	metadataURL string
+}
+
+// NewGCPProvider creates a Google Cloud Secret Manager provider
+func NewGCPProvider(config GCPConfig, timeout time.Duration) *GCPProvider {
+	return &GCPProvider{
+		config:      config,
+		client:      &http.Client{Timeout: timeout},
+		metadataURL: "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token",
+	}
+}
+
+// Get reads a secret version's payload
+func (p *GCPProvider) Get(ctx context.Context, name string) (string, error) {
+	resource, field := splitField(name)
+	resource = strings.Trim(resource, "/")
+	if !strings.HasPrefix(resource, "projects/") || !strings.Contains(resource, "/secrets/") {
+		return "", fmt.Errorf("gcp secret name must look like projects/PROJECT/secrets/SECRET, got %q", resource)
+	}
+	if !strings.Contains(resource, "/versions/") {
+		resource += "/versions/latest"
+	}
+	token, err := p.token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := firstNonEmpty(p.config.Endpoint, "https://secretmanager.googleapis.com")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint, "/")+"/v1/"+resource+":access", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var response struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := doJSON(p.client, req, &response); err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(response.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("invalid secret payload: %w", err)
+	}
+	return pickJSONField(string(data), field)
+}
+
+// token returns the configured access token or one from the metadata server
+func (p *GCPProvider) token(ctx context.Context) (string, error) {
+	if token := firstNonEmpty(p.config.AccessToken, os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")); token != "" {
+		return token, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.metadataURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var response struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doJSON(p.client, req, &response); err != nil {
+		return "", fmt.Errorf("no gcp access token configured and the metadata server failed: %w", err)
+	}
+	return response.AccessToken, nil
+}
+
+// AzureConfig is access to Azure Key Vault. Without a client secret the
+// VM's or pod's managed identity is used.
+type AzureConfig struct {
+	TenantID     string `yaml:"tenant_id" json:"tenant_id"`       // Empty uses AZURE_TENANT_ID
+	ClientID     string `yaml:"client_id" json:"client_id"`       // Empty uses AZURE_CLIENT_ID
+	ClientSecret string `yaml:"client_secret" json:"-"`           // Empty uses AZURE_CLIENT_SECRET
+	VaultSuffix  string `yaml:"vault_suffix" json:"vault_suffix"` // Empty uses vault.azure.net; sovereign clouds differ
+}
+
+// AzureProvider reads Key Vault secrets: azure-kv:VAULT/SECRET[/VERSION]#FIELD
+type AzureProvider struct {
+	config      AzureConfig
+	client      *http.Client
+	loginURL    string
+	identityURL string
+	vaultURL    func(vault string) string
+}
+
+// NewAzureProvider creates an Azure Key Vault provider
+func NewAzureProvider(config AzureConfig, timeout time.Duration) *AzureProvider {
+	if config.VaultSuffix == "" {
+		config.VaultSuffix = "vault.azure.net"
+	}
+	suffix := config.VaultSuffix
+	return &AzureProvider{
+		config:      config,
+		client:      &http.Client{Timeout: timeout},
+		loginURL:    "https://login.microsoftonline.com",
+		identityURL: "http://169.254.169.254/metadata/identity/oauth2/token",
+		vaultURL:    func(vault string) string { return "https://" + vault + "." + suffix },
+	}
+}
+
+// Get reads a secret's value, the latest version unless one is named
+func (p *AzureProvider) Get(ctx context.Context, name string) (string, error) {
+	path, field := splitField(name)
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("azure secret name must look like VAULT/SECRET or VAULT/SECRET/VERSION, got %q", path)
+	}
+	token, err := p.token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := p.vaultURL(parts[0]) + "/secrets/" + url.PathEscape(parts[1])
+	if len(parts) == 3 {
+		endpoint += "/" + url.PathEscape(parts[2])
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?api-version=7.4", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var response struct {
+		Value string `json:"value"`
+	}
+	if err := doJSON(p.client, req, &response); err != nil {
+		return "", err
+	}
+	return pickJSONField(response.Value, field)
+}
+
+// token gets a Key Vault access token with the client credentials, or from
+// the managed identity endpoint when there is no client secret
+func (p *AzureProvider) token(ctx context.Context) (string, error) {
+	tenantID := firstNonEmpty(p.config.TenantID, os.Getenv("AZURE_TENANT_ID"))
+	clientID := firstNonEmpty(p.config.ClientID, os.Getenv("AZURE_CLIENT_ID"))
+	clientSecret := firstNonEmpty(p.config.ClientSecret, os.Getenv("AZURE_CLIENT_SECRET"))
+	resource := "https://" + p.config.VaultSuffix
+
+	var req *http.Request
+	var err error
+	if clientSecret != "" {
+		if tenantID == "" || clientID == "" {
+			return "", fmt.Errorf("azure tenant and client IDs are required with a client secret")
+		}
+		form := url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {clientID},
+			"client_secret": {clientSecret},
+			"scope":         {resource + "/.default"},
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, p.loginURL+"/"+url.PathEscape(tenantID)+"/oauth2/v2.0/token", strings.NewReader(form.Encode()))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		query := url.Values{"api-version": {"2018-02-01"}, "resource": {resource}}
+		if clientID != "" {
+			query.Set("client_id", clientID)
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, p.identityURL+"?"+query.Encode(), nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Metadata", "true")
+	}
+
+	var response struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doJSON(p.client, req, &response); err != nil {
+		return "", fmt.Errorf("failed to get azure access token: %w", err)
+	}
+	return response.AccessToken, nil
+}
+
+// pickJSONField returns a secret's text, or one field of it when the text
+// is a JSON object
+func pickJSONField(text, field string) (string, error) {
+	if field == "" {
+		return text, nil
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &data); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object, so it has no field %q", field)
+	}
+	return pickField(data, field)
+}
+
+// hashHex returns the hex SHA-256 of data
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data under key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,132 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/data/agentaflow/notifiers" || r.Header.Get("X-Vault-Token") != "root" || r.Header.Get("X-Vault-Namespace") != "ml" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"opsgenie":"og-key","smtp":"pw"}}}`))
+	}))
+	defer server.Close()
+
+	p := NewVaultProvider(VaultConfig{Address: server.URL, Token: "root", Namespace: "ml", Mount: "kv"}, time.Second)
+	if got, err := p.Get(context.Background(), "agentaflow/notifiers#opsgenie"); err != nil || got != "og-key" {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+	if _, err := p.Get(context.Background(), "agentaflow/notifiers"); err == nil || !strings.Contains(err.Error(), "2 fields") {
+		t.Errorf("Expected an error asking for a field, got %v", err)
+	}
+	if _, err := p.Get(context.Background(), "agentaflow/other#x"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Expected the HTTP status in the error, got %v", err)
+	}
+}
+
+func TestAWSProviderSignsRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20261016/eu-west-1/secretsmanager/aws4_request, ") ||
+			!strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target,") ||
+			r.Header.Get("X-Amz-Date") != "20261016T120000Z" {
+			http.Error(w, "bad signature", http.StatusForbidden)
+			return
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		json.NewEncoder(w).Encode(map[string]string{"SecretString": `{"wandb":"wb-key"}`, "Name": body["SecretId"]})
+	}))
+	defer server.Close()
+
+	p := NewAWSProvider(AWSConfig{Region: "eu-west-1", AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session", Endpoint: server.URL}, time.Second)
+	p.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+	if got, err := p.Get(context.Background(), "prod/agentaflow#wandb"); err != nil || got != "wb-key" {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+	if got, err := p.Get(context.Background(), "prod/agentaflow"); err != nil || got != `{"wandb":"wb-key"}` {
+		t.Errorf("Expected the whole secret string without a field, got %q, %v", got, err)
+	}
+}
+
+func TestSignAWSRequestKnownSignature(t *testing.T) {
+	// From the AWS Signature Version 4 test suite (get-vanilla)
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	signAWSRequest(req, nil, "us-east-1", "service", "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %s", got)
+	}
+}
+
+func TestGCPProviderUsesMetadataToken(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(w, "missing header", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"access_token":"ya29.token"}`))
+	})
+	mux.HandleFunc("/v1/projects/ml/secrets/mlflow-token/versions/latest:access", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer ya29.token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"payload": map[string]string{"data": base64.StdEncoding.EncodeToString([]byte("mlflow-secret"))}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "")
+	p := NewGCPProvider(GCPConfig{Endpoint: server.URL}, time.Second)
+	p.metadataURL = server.URL + "/token"
+	if got, err := p.Get(context.Background(), "projects/ml/secrets/mlflow-token"); err != nil || got != "mlflow-secret" {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+	if _, err := p.Get(context.Background(), "mlflow-token"); err == nil {
+		t.Error("Expected an error for a name without a project")
+	}
+}
+
+func TestAzureProviderClientCredentials(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tenant-1/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "client_secret=azure-secret") || !strings.Contains(string(body), "scope=https%3A%2F%2Fvault.azure.net%2F.default") {
+			http.Error(w, "bad credentials", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"access_token":"aad-token"}`))
+	})
+	mux.HandleFunc("/ml-vault/secrets/oidc-client-secret", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer aad-token" || r.URL.Query().Get("api-version") == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"value":"oidc-secret"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p := NewAzureProvider(AzureConfig{TenantID: "tenant-1", ClientID: "app", ClientSecret: "azure-secret"}, time.Second)
+	p.loginURL = server.URL
+	p.vaultURL = func(vault string) string { return server.URL + "/" + vault }
+	if got, err := p.Get(context.Background(), "ml-vault/oidc-client-secret"); err != nil || got != "oidc-secret" {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+	if _, err := p.Get(context.Background(), "ml-vault"); err == nil {
+		t.Error("Expected an error for a name without a secret")
+	}
+}
@@ -0,0 +1,141 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// EnvProvider reads secrets from environment variables: env:NAME
+type EnvProvider struct{}
+
+// Get returns an environment variable, failing when it is unset
+func (EnvProvider) Get(ctx context.Context, name string) (string, error) {
+	value, exists := os.LookupEnv(name)
+	if !exists {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// FileProvider reads secrets from files such as Kubernetes secret volumes or
+// Docker secrets: file:/run/secrets/NAME. One trailing newline is dropped.
+type FileProvider struct{}
+
+// Get returns a file's contents
+func (FileProvider) Get(ctx context.Context, name string) (string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r"), nil
+}
+
+// VaultConfig is a HashiCorp Vault server with a KV version 2 engine
+type VaultConfig struct {
+	Address   string `yaml:"address" json:"address"`     // Empty uses VAULT_ADDR
+	Token     string `yaml:"token" json:"-"`             // Empty uses VAULT_TOKEN
+	Namespace string `yaml:"namespace" json:"namespace"` // Vault Enterprise namespace, optional
+	Mount     string `yaml:"mount" json:"mount"`         // KV engine mount; empty uses "secret"
+}
+
+// VaultProvider reads fields of Vault KV v2 secrets: vault:PATH#FIELD. Without
+// a field, the secret must have exactly one.
+type VaultProvider struct {
+	config VaultConfig
+	client *http.Client
+}
+
+// NewVaultProvider creates a Vault provider
+func NewVaultProvider(config VaultConfig, timeout time.Duration) *VaultProvider {
+	if config.Mount == "" {
+		config.Mount = "secret"
+	}
+	return &VaultProvider{config: config, client: &http.Client{Timeout: timeout}}
+}
+
+// Get reads a field of the latest version of a secret
+func (p *VaultProvider) Get(ctx context.Context, name string) (string, error) {
+	address := firstNonEmpty(p.config.Address, os.Getenv("VAULT_ADDR"))
+	token := firstNonEmpty(p.config.Token, os.Getenv("VAULT_TOKEN"))
+	if address == "" || token == "" {
+		return "", fmt.Errorf("vault address and token are required (set VAULT_ADDR and VAULT_TOKEN)")
+	}
+	path, field := splitField(name)
+
+	endpoint := strings.TrimSuffix(address, "/") + "/v1/" + strings.Trim(p.config.Mount, "/") + "/data/" + strings.TrimPrefix(path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if p.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.config.Namespace)
+	}
+	var response struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := doJSON(p.client, req, &response); err != nil {
+		return "", err
+	}
+	return pickField(response.Data.Data, field)
+}
+
+// splitField splits NAME#FIELD
+func splitField(name string) (string, string) {
+	if i := strings.LastIndex(name, "#"); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return name, ""
+}
+
+// pickField returns one string field of a secret's key-value data; an empty
+// field selects the only one
+func pickField(data map[string]interface{}, field string) (string, error) {
+	if field == "" {
+		if len(data) != 1 {
+			return "", fmt.Errorf("secret has %d fields; name one with #field", len(data))
+		}
+		for only := range data {
+			field = only
+		}
+	}
+	value, exists := data[field]
+	if !exists {
+		return "", fmt.Errorf("secret has no field %q", field)
+	}
+	text, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("secret field %q is not a string", field)
+	}
+	return text, nil
+}
+
+// doJSON sends a request and decodes a successful JSON response
+func doJSON(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %s", req.Method, req.URL.Redacted(), resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,258 @@
+// Package secrets resolves credentials referenced from configuration, so
+// API keys, passwords and tokens can live in the environment, mounted files,
+// Vault or a cloud secret manager instead of plain config files.
+//
+// A reference is "<provider>:<name>", for example env:OPSGENIE_API_KEY,
+// file:/run/secrets/smtp-password, vault:agentaflow/notifiers#opsgenie,
+// aws-sm:prod/agentaflow#wandb, gcp-sm:projects/ml/secrets/mlflow-token or
+// azure-kv:ml-vault/oidc-client-secret. Values without a known provider are
+// used as written.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Provider looks up a secret by the name after its reference's scheme
+type Provider interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// ProviderFunc adapts a function to a Provider
+type ProviderFunc func(ctx context.Context, name string) (string, error)
+
+// Get calls f
+func (f ProviderFunc) Get(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+// Config configures the secret providers
+type Config struct {
+	Vault VaultConfig `yaml:"vault" json:"vault"`
+	AWS   AWSConfig   `yaml:"aws" json:"aws"`
+	GCP   GCPConfig   `yaml:"gcp" json:"gcp"`
+	Azure AzureConfig `yaml:"azure" json:"azure"`
+
+	// How long resolved values are reused; zero disables caching
+	CacheTTL time.Duration `yaml:"cache_ttl" json:"cache_ttl"`
+
+	// Fail when a secret field holds a plain value instead of a reference
+	RejectLiterals bool `yaml:"reject_literals" json:"reject_literals"`
+
+	Timeout time.Duration `yaml:"timeout" json:"timeout"` // Per-request timeout for remote providers
+}
+
+// DefaultConfig returns the default secrets configuration
+func DefaultConfig() Config {
+	return Config{
+		Vault:    VaultConfig{Mount: "secret"},
+		CacheTTL: 5 * time.Minute,
+		Timeout:  10 * time.Second,
+	}
+}
+
+// cachedSecret is a resolved value and when it must be fetched again
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+// Resolver resolves secret references through registered providers
+type Resolver struct {
+	providers      map[string]Provider // By scheme
+	cacheTTL       time.Duration
+	rejectLiterals bool
+	cache          map[string]cachedSecret
+	now            func() time.Time
+	mu             sync.Mutex
+}
+
+// NewResolver creates a resolver with the env, file, vault, aws-sm, gcp-sm
+// and azure-kv providers. Remote providers read their credentials from config
+// or the environment when first used.
+func NewResolver(config Config) *Resolver {
+	defaults := DefaultConfig()
+	if config.Timeout == 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.Vault.Mount == "" {
+		config.Vault.Mount = defaults.Vault.Mount
+	}
+
+	r := &Resolver{
+		providers:      make(map[string]Provider),
+		cacheTTL:       config.CacheTTL,
+		rejectLiterals: config.RejectLiterals,
+		cache:          make(map[string]cachedSecret),
+		now:            time.Now,
+	}
+	r.Register("env", EnvProvider{})
+	r.Register("file", FileProvider{})
+	r.Register("vault", NewVaultProvider(config.Vault, config.Timeout))
+	r.Register("aws-sm", NewAWSProvider(config.AWS, config.Timeout))
+	r.Register("gcp-sm", NewGCPProvider(config.GCP, config.Timeout))
+	r.Register("azure-kv", NewAzureProvider(config.Azure, config.Timeout))
+	return r
+}
+
+// Register adds or replaces the provider for a reference scheme
+func (r *Resolver) Register(scheme string, provider Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[scheme] = provider
+}
+
+// parse splits a reference into its provider and name; ok is false for
+// plain values
+func (r *Resolver) parse(value string) (Provider, string, bool) {
+	i := strings.Index(value, ":")
+	if i <= 0 {
+		return nil, "", false
+	}
+	r.mu.Lock()
+	provider, exists := r.providers[value[:i]]
+	r.mu.Unlock()
+	return provider, value[i+1:], exists
+}
+
+// IsReference reports whether a value names a secret rather than holding one
+func (r *Resolver) IsReference(value string) bool {
+	_, _, ok := r.parse(value)
+	return ok
+}
+
+// Resolve returns the secret a reference names, or a plain value unchanged
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	provider, name, ok := r.parse(value)
+	if !ok {
+		return value, nil
+	}
+
+	r.mu.Lock()
+	cached, exists := r.cache[value]
+	r.mu.Unlock()
+	if exists && r.now().Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	secret, err := provider.Get(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %s: %w", value, err)
+	}
+	if r.cacheTTL > 0 {
+		r.mu.Lock()
+		r.cache[value] = cachedSecret{value: secret, expires: r.now().Add(r.cacheTTL)}
+		r.mu.Unlock()
+	}
+	return secret, nil
+}
+
+// ResolveFields replaces references in the string fields of a config struct
+// tagged `secret:"true"`, walking nested structs, pointers and slices. Maps of
+// strings tagged `secret:"keys"`, such as token-to-operator maps, have their
+// keys resolved instead of their values. Every failure is reported.
+func (r *Resolver) ResolveFields(ctx context.Context, target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("secrets: ResolveFields needs a non-nil pointer, got %T", target)
+	}
+	var problems []string
+	r.walk(ctx, v.Elem(), "", &problems)
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// walk resolves the secret fields reachable from v, named from path
+func (r *Resolver) walk(ctx context.Context, v reflect.Value, path string, problems *[]string) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			r.walk(ctx, v.Elem(), path, problems)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			r.walk(ctx, v.Index(i), fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" {
+				name = strings.ToLower(field.Name)
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			switch tag := field.Tag.Get("secret"); {
+			case tag == "true" && field.Type.Kind() == reflect.String && v.Field(i).CanSet():
+				if resolved, ok := r.resolveField(ctx, name, v.Field(i).String(), problems); ok {
+					v.Field(i).SetString(resolved)
+				}
+			case tag == "true" && isStringMap(field.Type):
+				r.resolveMap(ctx, v.Field(i), name, false, problems)
+			case tag == "keys" && isStringMap(field.Type):
+				r.resolveMap(ctx, v.Field(i), name, true, problems)
+			default:
+				r.walk(ctx, v.Field(i), name, problems)
+			}
+		}
+	}
+}
+
+// resolveMap resolves the values, or the keys, of a map of strings
+func (r *Resolver) resolveMap(ctx context.Context, m reflect.Value, path string, keys bool, problems *[]string) {
+	if m.IsNil() {
+		return
+	}
+	resolved := reflect.MakeMapWithSize(m.Type(), m.Len())
+	iter := m.MapRange()
+	for iter.Next() {
+		key, value := iter.Key().String(), iter.Value().String()
+		if keys {
+			if secret, ok := r.resolveField(ctx, path, key, problems); ok {
+				key = secret
+			}
+		} else if secret, ok := r.resolveField(ctx, path+"."+key, value, problems); ok {
+			value = secret
+		}
+		resolved.SetMapIndex(reflect.ValueOf(key).Convert(m.Type().Key()), reflect.ValueOf(value).Convert(m.Type().Elem()))
+	}
+	if m.CanSet() {
+		m.Set(resolved)
+	}
+}
+
+// resolveField resolves one secret value, recording a problem on failure
+func (r *Resolver) resolveField(ctx context.Context, path, value string, problems *[]string) (string, bool) {
+	if value == "" {
+		return "", false
+	}
+	if !r.IsReference(value) {
+		if r.rejectLiterals {
+			*problems = append(*problems, fmt.Sprintf("%s: plain secret in config; use a reference such as env:NAME", path))
+		}
+		return "", false
+	}
+	resolved, err := r.Resolve(ctx, value)
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s: %v", path, err))
+		return "", false
+	}
+	return resolved, true
+}
+
+// isStringMap reports whether t is a map with string keys and values
+func isStringMap(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String
+}
@@ -0,0 +1,125 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type notifierConfig struct {
+	APIKey   string            `yaml:"api_key" secret:"true"`
+	Team     string            `yaml:"team"`
+	Headers  map[string]string `yaml:"headers" secret:"true"`
+	Tokens   map[string]string `yaml:"tokens" secret:"keys"`
+	Webhooks []struct {
+		URL    string `yaml:"url"`
+		Secret string `yaml:"secret" secret:"true"`
+	} `yaml:"webhooks"`
+	Nested *struct {
+		Password string `yaml:"password" secret:"true"`
+	} `yaml:"nested"`
+}
+
+func TestResolveReferences(t *testing.T) {
+	t.Setenv("AGENTAFLOW_TEST_KEY", "from-env")
+	path := filepath.Join(t.TempDir(), "password")
+	os.WriteFile(path, []byte("from-file\n"), 0600)
+
+	r := NewResolver(DefaultConfig())
+	ctx := context.Background()
+	for value, want := range map[string]string{
+		"env:AGENTAFLOW_TEST_KEY": "from-env",
+		"file:" + path:            "from-file",
+		"plain-value":             "plain-value",
+		"https://example.com/x":   "https://example.com/x",
+	} {
+		got, err := r.Resolve(ctx, value)
+		if err != nil || got != want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if r.IsReference("https://example.com") || !r.IsReference("vault:kv/app") {
+		t.Error("Expected only known schemes to be references")
+	}
+	if _, err := r.Resolve(ctx, "env:AGENTAFLOW_TEST_MISSING"); err == nil || !strings.Contains(err.Error(), "env:AGENTAFLOW_TEST_MISSING") {
+		t.Errorf("Expected an error naming the missing reference, got %v", err)
+	}
+}
+
+func TestResolveCachesUntilTTL(t *testing.T) {
+	calls := 0
+	r := NewResolver(Config{CacheTTL: time.Minute})
+	r.Register("count", ProviderFunc(func(ctx context.Context, name string) (string, error) {
+		calls++
+		return name, nil
+	}))
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	r.Resolve(context.Background(), "count:a")
+	r.Resolve(context.Background(), "count:a")
+	if calls != 1 {
+		t.Errorf("Expected a cached second lookup, got %d calls", calls)
+	}
+	now = now.Add(2 * time.Minute)
+	r.Resolve(context.Background(), "count:a")
+	if calls != 2 {
+		t.Errorf("Expected a lookup after the TTL, got %d calls", calls)
+	}
+}
+
+func TestResolveFields(t *testing.T) {
+	t.Setenv("AGENTAFLOW_TEST_KEY", "key")
+	t.Setenv("AGENTAFLOW_TEST_TOKEN", "token")
+	t.Setenv("AGENTAFLOW_TEST_HOOK", "hook")
+	t.Setenv("AGENTAFLOW_TEST_PASSWORD", "password")
+
+	var config notifierConfig
+	config.APIKey = "env:AGENTAFLOW_TEST_KEY"
+	config.Team = "env:AGENTAFLOW_TEST_KEY" // Not a secret field, left alone
+	config.Headers = map[string]string{"X-Auth": "env:AGENTAFLOW_TEST_TOKEN"}
+	config.Tokens = map[string]string{"env:AGENTAFLOW_TEST_TOKEN": "ops", "literal": "ci"}
+	config.Webhooks = append(config.Webhooks, struct {
+		URL    string `yaml:"url"`
+		Secret string `yaml:"secret" secret:"true"`
+	}{URL: "https://hooks.example.com", Secret: "env:AGENTAFLOW_TEST_HOOK"})
+	config.Nested = &struct {
+		Password string `yaml:"password" secret:"true"`
+	}{Password: "env:AGENTAFLOW_TEST_PASSWORD"}
+
+	if err := NewResolver(DefaultConfig()).ResolveFields(context.Background(), &config); err != nil {
+		t.Fatalf("ResolveFields: %v", err)
+	}
+	if config.APIKey != "key" || config.Team != "env:AGENTAFLOW_TEST_KEY" || config.Headers["X-Auth"] != "token" ||
+		config.Tokens["token"] != "ops" || config.Tokens["literal"] != "ci" ||
+		config.Webhooks[0].Secret != "hook" || config.Nested.Password != "password" {
+		t.Errorf("Unexpected resolved config: %+v %+v", config, *config.Nested)
+	}
+}
+
+func TestResolveFieldsReportsEveryProblem(t *testing.T) {
+	config := notifierConfig{
+		APIKey:  "env:AGENTAFLOW_TEST_MISSING",
+		Headers: map[string]string{"X-Auth": "plain"},
+	}
+	r := NewResolver(Config{RejectLiterals: true})
+	err := r.ResolveFields(context.Background(), &config)
+	if err == nil {
+		t.Fatal("Expected errors")
+	}
+	message := err.Error()
+	if !strings.Contains(message, "api_key: failed to resolve secret env:AGENTAFLOW_TEST_MISSING") ||
+		!strings.Contains(message, "headers.X-Auth: plain secret in config") {
+		t.Errorf("Expected both problems with field paths, got:\n%s", message)
+	}
+	if config.APIKey != "env:AGENTAFLOW_TEST_MISSING" {
+		t.Errorf("Expected unresolved field left as written, got %q", config.APIKey)
+	}
+
+	if err := r.ResolveFields(context.Background(), config); err == nil {
+		t.Error("Expected an error for a non-pointer target")
+	}
+}
@@ -0,0 +1,200 @@
+package serving
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// sessionBinding pins a session to a model instance
+type sessionBinding struct {
+	modelID    string
+	instanceID string
+	lastUsed   time.Time
+}
+
+// RouteRequestWithSession routes a request, keeping a session on the same instance when
+// the router uses RouteSessionAffinity. Other strategies ignore the session ID.
+//
+// A session moves only when its instance becomes unavailable or is no longer
+// registered. A session whose instance is at MaxLoad stays there and queues,
+// unless SetSessionSpillover lets the request run elsewhere.
+func (r *Router) RouteRequestWithSession(modelID, sessionID string) (*ModelInstance, error) {
+	if sessionID == "" || r.strategy != RouteSessionAffinity {
+		return r.RouteRequest(modelID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	instances, exists := r.instances[modelID]
+	if !exists || len(instances) == 0 {
+		return nil, fmt.Errorf("no instances available for model %s", modelID)
+	}
+
+	key := modelID + "/" + sessionID
+	now := time.Now()
+
+	binding, bound := r.sessions[key]
+	var instance *ModelInstance
+	if bound {
+		for _, candidate := range instances {
+			if candidate.ID == binding.instanceID && candidate.Available {
+				instance = candidate
+				break
+			}
+		}
+	}
+
+	if instance == nil {
+		instance = rendezvousPick(instances, key, func(candidate *ModelInstance) bool { return candidate.Available })
+		if instance == nil {
+			return nil, fmt.Errorf("no available instances")
+		}
+		if bound && binding.instanceID != instance.ID {
+			r.sessionRebalances++
+		}
+		binding = &sessionBinding{modelID: modelID, instanceID: instance.ID}
+		r.sessions[key] = binding
+	}
+	binding.lastUsed = now
+
+	if r.sessionSpillover && !instanceEligible(instance) {
+		// Run this request elsewhere but keep the binding, so the session
+		// returns to its instance once it has capacity
+		if spill := rendezvousPick(instances, key, instanceEligible); spill != nil {
+			r.sessionSpillovers++
+			return spill, nil
+		}
+	}
+
+	return instance, nil
+}
+
+// SetSessionSpillover lets requests of a session whose instance is at MaxLoad
+// run on another instance instead of queueing on their own. The session stays
+// bound to its instance. Off by default.
+func (r *Router) SetSessionSpillover(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessionSpillover = enabled
+}
+
+// SetInstanceAvailable marks an instance (un)available; sessions pinned to a failed
+// instance are rebalanced on their next request
+func (r *Router) SetInstanceAvailable(instanceID string, available bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, instances := range r.instances {
+		for _, instance := range instances {
+			if instance.ID == instanceID {
+				instance.Available = available
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("instance %s not found", instanceID)
+}
+
+// SetSessionTTL sets how long an idle session keeps its instance binding
+func (r *Router) SetSessionTTL(ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessionTTL = ttl
+}
+
+// CleanExpiredSessions removes idle session bindings and returns how many were removed
+func (r *Router) CleanExpiredSessions() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	removed := 0
+	cutoff := time.Now().Add(-r.sessionTTL)
+	for key, binding := range r.sessions {
+		if binding.lastUsed.Before(cutoff) {
+			delete(r.sessions, key)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// StartSessionCleanup removes expired session bindings every interval until
+// StopSessionCleanup is called
+func (r *Router) StartSessionCleanup(interval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cleanupStop != nil || interval <= 0 {
+		return
+	}
+	r.cleanupStop = make(chan struct{})
+	r.cleanupDone = make(chan struct{})
+	go r.runSessionCleanup(interval, r.cleanupStop, r.cleanupDone)
+}
+
+// StopSessionCleanup halts the periodic session cleanup
+func (r *Router) StopSessionCleanup() {
+	r.mu.Lock()
+	stop, done := r.cleanupStop, r.cleanupDone
+	r.cleanupStop, r.cleanupDone = nil, nil
+	r.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// runSessionCleanup cleans expired sessions on every tick until stopped
+func (r *Router) runSessionCleanup(interval time.Duration, stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.CleanExpiredSessions()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// instanceEligible reports whether an instance can accept another request
+func instanceEligible(instance *ModelInstance) bool {
+	return instance.Available && instance.CurrentLoad < instance.MaxLoad
+}
+
+// rendezvousPick selects an instance by highest-random-weight hashing among those
+// eligible, so a session maps to the same instance consistently and only sessions on
+// a removed instance move when the instance set changes
+func rendezvousPick(instances []*ModelInstance, key string, eligible func(*ModelInstance) bool) *ModelInstance {
+	var best *ModelInstance
+	var bestScore uint64
+
+	for _, instance := range instances {
+		if !eligible(instance) {
+			continue
+		}
+
+		h := fnv.New64a()
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(instance.ID))
+		score := h.Sum64()
+
+		if best == nil || score > bestScore {
+			best = instance
+			bestScore = score
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,148 @@
+package serving
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newAffinityRouter() *Router {
+	router := NewRouter(RouteSessionAffinity)
+	for i := 1; i <= 3; i++ {
+		router.RegisterInstance(&ModelInstance{
+			ID:        fmt.Sprintf("instance-%d", i),
+			ModelID:   "chat",
+			MaxLoad:   10,
+			Available: true,
+		})
+	}
+	return router
+}
+
+func TestSessionAffinityIsSticky(t *testing.T) {
+	router := newAffinityRouter()
+
+	first, err := router.RouteRequestWithSession("chat", "session-42")
+	if err != nil {
+		t.Fatalf("Routing failed: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		instance, _ := router.RouteRequestWithSession("chat", "session-42")
+		if instance.ID != first.ID {
+			t.Fatalf("Expected session to stay on %s, got %s", first.ID, instance.ID)
+		}
+	}
+
+	// The mapping is consistent across routers with the same instances
+	other := newAffinityRouter()
+	instance, _ := other.RouteRequestWithSession("chat", "session-42")
+	if instance.ID != first.ID {
+		t.Errorf("Expected consistent hashing to pick %s, got %s", first.ID, instance.ID)
+	}
+
+	// Sessions spread across instances
+	used := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		instance, _ := router.RouteRequestWithSession("chat", fmt.Sprintf("s-%d", i))
+		used[instance.ID] = true
+	}
+	if len(used) < 2 {
+		t.Errorf("Expected sessions to spread across instances, used %v", used)
+	}
+}
+
+func TestSessionAffinityRebalancesOnFailure(t *testing.T) {
+	router := newAffinityRouter()
+
+	assignments := make(map[string]string)
+	for i := 0; i < 30; i++ {
+		session := fmt.Sprintf("s-%d", i)
+		instance, _ := router.RouteRequestWithSession("chat", session)
+		assignments[session] = instance.ID
+	}
+
+	if err := router.SetInstanceAvailable("instance-1", false); err != nil {
+		t.Fatalf("Failed to mark instance unavailable: %v", err)
+	}
+
+	moved := 0
+	for session, previous := range assignments {
+		instance, err := router.RouteRequestWithSession("chat", session)
+		if err != nil {
+			t.Fatalf("Routing failed: %v", err)
+		}
+		if instance.ID == "instance-1" {
+			t.Errorf("Session %s routed to failed instance", session)
+		}
+		if previous != "instance-1" && instance.ID != previous {
+			t.Errorf("Session %s moved from healthy instance %s to %s", session, previous, instance.ID)
+		}
+		if instance.ID != previous {
+			moved++
+		}
+	}
+
+	rebalances := router.GetRoutingMetrics()["session_rebalances"].(int64)
+	if rebalances != int64(moved) {
+		t.Errorf("Expected %d rebalances recorded, got %d", moved, rebalances)
+	}
+
+	if removed := router.CleanExpiredSessions(); removed != 0 {
+		t.Errorf("Expected no expired sessions, got %d", removed)
+	}
+}
+
+func TestSessionAffinityQueuesOnSaturatedInstance(t *testing.T) {
+	router := newAffinityRouter()
+	bound, _ := router.RouteRequestWithSession("chat", "session-42")
+	bound.CurrentLoad = bound.MaxLoad
+
+	// A busy instance keeps its session; the request queues there
+	for i := 0; i < 3; i++ {
+		if instance, err := router.RouteRequestWithSession("chat", "session-42"); err != nil || instance.ID != bound.ID {
+			t.Fatalf("Expected the session to stay on saturated %s, got %v (%v)", bound.ID, instance, err)
+		}
+	}
+
+	// With spill-over, the request runs elsewhere but the session stays bound
+	router.SetSessionSpillover(true)
+	spill, _ := router.RouteRequestWithSession("chat", "session-42")
+	if spill.ID == bound.ID {
+		t.Fatalf("Expected the request to spill over from %s", bound.ID)
+	}
+	bound.CurrentLoad = 0
+	if instance, _ := router.RouteRequestWithSession("chat", "session-42"); instance.ID != bound.ID {
+		t.Errorf("Expected the session back on %s once it has capacity, got %s", bound.ID, instance.ID)
+	}
+
+	metrics := router.GetRoutingMetrics()
+	if metrics["session_rebalances"].(int64) != 0 || metrics["session_spillovers"].(int64) != 1 {
+		t.Errorf("Expected no rebalances and one spill-over, got %v", metrics)
+	}
+}
+
+func TestSessionCleanupRunsPeriodically(t *testing.T) {
+	router := newAffinityRouter()
+	router.SetSessionTTL(time.Millisecond)
+	router.RouteRequestWithSession("chat", "session-42")
+
+	router.StartSessionCleanup(5 * time.Millisecond)
+	defer router.StopSessionCleanup()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		router.mu.RLock()
+		remaining := len(router.sessions)
+		router.mu.RUnlock()
+		if remaining == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the idle session to be cleaned up")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	router.StopSessionCleanup()
+	router.StopSessionCleanup()
+}
@@ -0,0 +1,98 @@
+package serving
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrCoalescedLeaderPanicked is returned to requests coalesced onto a leader
+// whose execution panicked
+var ErrCoalescedLeaderPanicked = errors.New("coalesced request panicked")
+
+// inflightCall is a backend execution shared by identical concurrent requests
+type inflightCall struct {
+	done     chan struct{}
+	response *InferenceResponse
+	err      error
+}
+
+// inflightGroup coalesces identical in-flight inference requests
+type inflightGroup struct {
+	calls            map[string]*inflightCall
+	coalesced        int64
+	coalescedByModel map[string]int64
+	mu               sync.Mutex
+}
+
+// newInflightGroup creates a new in-flight request group
+func newInflightGroup() *inflightGroup {
+	return &inflightGroup{
+		calls:            make(map[string]*inflightCall),
+		coalescedByModel: make(map[string]int64),
+	}
+}
+
+// join returns the in-flight call for key and whether the caller is the leader that must execute it
+func (g *inflightGroup) join(key, modelID string) (*inflightCall, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if call, exists := g.calls[key]; exists {
+		g.coalesced++
+		g.coalescedByModel[modelID]++
+		return call, false
+	}
+
+	call := &inflightCall{done: make(chan struct{})}
+	g.calls[key] = call
+	return call, true
+}
+
+// do runs fn as the leader of call and publishes its result to every waiting
+// caller. The call is removed and released even if fn panics: followers get
+// ErrCoalescedLeaderPanicked and the panic continues in the leader
+func (g *inflightGroup) do(key string, call *inflightCall, fn func() (*InferenceResponse, error)) (*InferenceResponse, error) {
+	returned := false
+	defer func() {
+		var recovered interface{}
+		if !returned {
+			recovered = recover()
+			call.response = nil
+			call.err = fmt.Errorf("%w: %v", ErrCoalescedLeaderPanicked, recovered)
+		}
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+
+		// A nil recovery is runtime.Goexit, which carries on unwinding by itself
+		if recovered != nil {
+			panic(recovered)
+		}
+	}()
+
+	response, err := fn()
+	call.response = response
+	call.err = err
+	returned = true
+	return response, err
+}
+
+// getStats returns coalescing statistics
+func (g *inflightGroup) getStats() map[string]interface{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	byModel := make(map[string]int64, len(g.coalescedByModel))
+	for modelID, count := range g.coalescedByModel {
+		byModel[modelID] = count
+	}
+
+	return map[string]interface{}{
+		"inflight_requests":           len(g.calls),
+		"coalesced_requests":          g.coalesced,
+		"coalesced_requests_by_model": byModel,
+	}
+}
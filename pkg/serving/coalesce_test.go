@@ -0,0 +1,159 @@
+package serving
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIdenticalInflightRequestsAreCoalesced(t *testing.T) {
+	manager := NewServingManager(nil, time.Minute)
+	manager.RegisterModel(&Model{ID: "m", Name: "Model"})
+
+	release := make(chan struct{})
+	var executions int32
+	manager.SetInferenceBackend(func(req *InferenceRequest) ([]byte, error) {
+		atomic.AddInt32(&executions, 1)
+		<-release
+		return []byte("result"), nil
+	})
+
+	const callers = 5
+	responses := make([]*InferenceResponse, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := manager.SubmitInferenceRequest(&InferenceRequest{
+				ID:      string(rune('a' + i)),
+				ModelID: "m",
+				Input:   []byte("same prompt"),
+			})
+			if err != nil {
+				t.Errorf("Request failed: %v", err)
+				return
+			}
+			responses[i] = resp
+		}(i)
+	}
+
+	// Wait until every follower has joined the leader before letting the backend finish
+	deadline := time.Now().Add(2 * time.Second)
+	for manager.inflight.getStats()["coalesced_requests"].(int64) < callers-1 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for requests to coalesce")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if executions != 1 {
+		t.Errorf("Expected backend to run once, ran %d times", executions)
+	}
+
+	coalesced := 0
+	for i, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		if string(resp.Output) != "result" {
+			t.Errorf("Expected shared output, got %q", resp.Output)
+		}
+		if resp.RequestID != string(rune('a'+i)) {
+			t.Errorf("Expected response to carry caller's request ID, got %s", resp.RequestID)
+		}
+		if resp.Coalesced {
+			coalesced++
+		}
+	}
+	if coalesced != callers-1 {
+		t.Errorf("Expected %d coalesced responses, got %d", callers-1, coalesced)
+	}
+
+	stats := manager.GetServingMetrics()["coalescing"].(map[string]interface{})
+	if stats["coalesced_requests_by_model"].(map[string]int64)["m"] != callers-1 {
+		t.Errorf("Expected per-model coalescing metric, got %v", stats)
+	}
+	if stats["inflight_requests"].(int) != 0 {
+		t.Errorf("Expected no in-flight requests after completion, got %v", stats["inflight_requests"])
+	}
+}
+
+func TestBackendErrorIsNotCached(t *testing.T) {
+	manager := NewServingManager(nil, time.Minute)
+	manager.RegisterModel(&Model{ID: "m", Name: "Model"})
+
+	fail := true
+	manager.SetInferenceBackend(func(req *InferenceRequest) ([]byte, error) {
+		if fail {
+			return nil, errors.New("backend unavailable")
+		}
+		return []byte("ok"), nil
+	})
+
+	if _, err := manager.SubmitInferenceRequest(&InferenceRequest{ID: "1", ModelID: "m", Input: []byte("x")}); err == nil {
+		t.Fatal("Expected backend error")
+	}
+
+	fail = false
+	resp, err := manager.SubmitInferenceRequest(&InferenceRequest{ID: "2", ModelID: "m", Input: []byte("x")})
+	if err != nil || resp.CacheHit || string(resp.Output) != "ok" {
+		t.Errorf("Expected fresh execution after failure, got %+v, %v", resp, err)
+	}
+}
+
+func TestCoalescedFollowersSurviveALeaderPanic(t *testing.T) {
+	manager := NewServingManager(nil, time.Minute)
+	manager.RegisterModel(&Model{ID: "m", Name: "Model"})
+
+	release := make(chan struct{})
+	manager.SetInferenceBackend(func(req *InferenceRequest) ([]byte, error) {
+		<-release
+		panic("backend crashed")
+	})
+
+	leaderPanic := make(chan interface{}, 1)
+	go func() {
+		defer func() { leaderPanic <- recover() }()
+		manager.SubmitInferenceRequest(&InferenceRequest{ID: "leader", ModelID: "m", Input: []byte("same prompt")})
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for manager.inflight.getStats()["inflight_requests"].(int) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the leader")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	followerErr := make(chan error, 1)
+	go func() {
+		_, err := manager.SubmitInferenceRequest(&InferenceRequest{ID: "follower", ModelID: "m", Input: []byte("same prompt")})
+		followerErr <- err
+	}()
+	for manager.inflight.getStats()["coalesced_requests"].(int64) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the follower to coalesce")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+
+	select {
+	case err := <-followerErr:
+		if !errors.Is(err, ErrCoalescedLeaderPanicked) {
+			t.Errorf("Expected the leader's panic as an error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Follower blocked after the leader panicked")
+	}
+	if r := <-leaderPanic; r != "backend crashed" {
+		t.Errorf("Expected the panic to continue in the leader, got %v", r)
+	}
+	if inflight := manager.inflight.getStats()["inflight_requests"].(int); inflight != 0 {
+		t.Errorf("Expected the in-flight entry removed, %d remain", inflight)
+	}
+}
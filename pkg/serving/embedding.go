@@ -0,0 +1,349 @@
+package serving
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// ErrModelNotRegistered is returned for requests naming a model that is not
+// in the registry
+var ErrModelNotRegistered = errors.New("model not registered")
+
+// EmbeddingBackend computes one embedding vector per input for a model
+type EmbeddingBackend func(modelID string, inputs [][]byte) ([][]float32, error)
+
+// EmbeddingConfig controls micro-batching of embedding requests
+type EmbeddingConfig struct {
+	MaxBatchSize int           // Inputs per backend call; a full batch is dispatched immediately
+	MaxWaitTime  time.Duration // How long the first input of a batch waits for more to arrive
+	MaxInputSize int           // Inputs larger than this many bytes are rejected (0 disables the limit)
+}
+
+// DefaultEmbeddingConfig returns micro-batching defaults tuned for small RAG inputs
+func DefaultEmbeddingConfig() EmbeddingConfig {
+	return EmbeddingConfig{
+		MaxBatchSize: 128,
+		MaxWaitTime:  5 * time.Millisecond,
+		MaxInputSize: 8192,
+	}
+}
+
+// EmbeddingRequest asks for embeddings of one or more inputs
+type EmbeddingRequest struct {
+	ID        string
+	ModelID   string
+	Inputs    [][]byte
+	CreatedAt time.Time
+}
+
+// EmbeddingResponse holds one embedding per request input, in input order
+type EmbeddingResponse struct {
+	RequestID    string
+	Embeddings   [][]float32
+	Latency      time.Duration
+	QueueWait    time.Duration // Longest time an input waited for its micro-batch
+	MaxBatchSize int           // Largest backend batch any input was served in
+	CompletedAt  time.Time
+}
+
+// embeddingItem is a single input waiting in a micro-batch
+type embeddingItem struct {
+	input     []byte
+	enqueued  time.Time
+	done      chan struct{}
+	vector    []float32
+	err       error
+	queueWait time.Duration
+	batchSize int
+}
+
+// embeddingBatch collects inputs for one model until it is full or its timer fires
+type embeddingBatch struct {
+	modelID string
+	items   []*embeddingItem
+	timer   *time.Timer
+}
+
+// embeddingBatcher micro-batches embedding inputs per model
+type embeddingBatcher struct {
+	config  EmbeddingConfig
+	backend EmbeddingBackend
+	pending map[string]*embeddingBatch
+
+	batches        int64
+	inputs         int64
+	fullBatches    int64
+	timeoutBatches int64
+	failedBatches  int64
+	inputsByModel  map[string]int64
+	mu             sync.Mutex
+}
+
+// newEmbeddingBatcher creates a new embedding batcher
+func newEmbeddingBatcher(config EmbeddingConfig) *embeddingBatcher {
+	return &embeddingBatcher{
+		config:        config,
+		pending:       make(map[string]*embeddingBatch),
+		inputsByModel: make(map[string]int64),
+	}
+}
+
+// enqueue adds an input to the model's pending batch, dispatching it when full
+func (b *embeddingBatcher) enqueue(modelID string, input []byte) *embeddingItem {
+	item := &embeddingItem{input: input, enqueued: time.Now(), done: make(chan struct{})}
+
+	b.mu.Lock()
+	batch, exists := b.pending[modelID]
+	if !exists {
+		batch = &embeddingBatch{modelID: modelID}
+		b.pending[modelID] = batch
+		batch.timer = time.AfterFunc(b.config.MaxWaitTime, func() {
+			b.mu.Lock()
+			claimed := b.pending[modelID] == batch
+			if claimed {
+				delete(b.pending, modelID)
+			}
+			b.mu.Unlock()
+
+			if claimed {
+				b.dispatch(batch, false)
+			}
+		})
+	}
+	batch.items = append(batch.items, item)
+	full := len(batch.items) >= b.config.MaxBatchSize
+	if full {
+		delete(b.pending, modelID)
+		batch.timer.Stop()
+	}
+	b.mu.Unlock()
+
+	if full {
+		go b.dispatch(batch, true)
+	}
+	return item
+}
+
+// dispatch runs a batch that has been removed from the pending set on the backend
+func (b *embeddingBatcher) dispatch(batch *embeddingBatch, full bool) {
+	b.mu.Lock()
+	backend := b.backend
+	b.batches++
+	b.inputs += int64(len(batch.items))
+	b.inputsByModel[batch.modelID] += int64(len(batch.items))
+	if full {
+		b.fullBatches++
+	} else {
+		b.timeoutBatches++
+	}
+	b.mu.Unlock()
+
+	dispatchedAt := time.Now()
+	inputs := make([][]byte, len(batch.items))
+	for i, item := range batch.items {
+		inputs[i] = item.input
+	}
+
+	var vectors [][]float32
+	var err error
+	if backend == nil {
+		vectors = simulatedEmbeddings(inputs)
+	} else {
+		vectors, err = backend(batch.modelID, inputs)
+		if err == nil && len(vectors) != len(inputs) {
+			err = fmt.Errorf("embedding backend returned %d vectors for %d inputs", len(vectors), len(inputs))
+		}
+	}
+
+	if err != nil {
+		b.mu.Lock()
+		b.failedBatches++
+		b.mu.Unlock()
+	}
+
+	for i, item := range batch.items {
+		item.queueWait = dispatchedAt.Sub(item.enqueued)
+		item.batchSize = len(batch.items)
+		if err != nil {
+			item.err = err
+		} else {
+			item.vector = vectors[i]
+		}
+		close(item.done)
+	}
+}
+
+// setBackend replaces the embedding backend
+func (b *embeddingBatcher) setBackend(backend EmbeddingBackend) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.backend = backend
+}
+
+// setConfig replaces the batching configuration for batches formed from now on
+func (b *embeddingBatcher) setConfig(config EmbeddingConfig) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config = config
+}
+
+// getConfig returns the current batching configuration
+func (b *embeddingBatcher) getConfig() EmbeddingConfig {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.config
+}
+
+// getStats returns micro-batching statistics
+func (b *embeddingBatcher) getStats() map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	avgBatchSize := 0.0
+	if b.batches > 0 {
+		avgBatchSize = float64(b.inputs) / float64(b.batches)
+	}
+
+	byModel := make(map[string]int64, len(b.inputsByModel))
+	for modelID, count := range b.inputsByModel {
+		byModel[modelID] = count
+	}
+
+	return map[string]interface{}{
+		"batches":          b.batches,
+		"inputs":           b.inputs,
+		"avg_batch_size":   avgBatchSize,
+		"full_batches":     b.fullBatches,
+		"timeout_batches":  b.timeoutBatches,
+		"failed_batches":   b.failedBatches,
+		"pending_models":   len(b.pending),
+		"inputs_by_model":  byModel,
+		"max_batch_size":   b.config.MaxBatchSize,
+		"max_wait_time_ms": b.config.MaxWaitTime.Milliseconds(),
+	}
+}
+
+// simulatedEmbeddings returns small deterministic vectors derived from each input
+func simulatedEmbeddings(inputs [][]byte) [][]float32 {
+	const dimensions = 8
+	vectors := make([][]float32, len(inputs))
+	for i, input := range inputs {
+		vector := make([]float32, dimensions)
+		for d := range vector {
+			h := fnv.New32a()
+			h.Write([]byte{byte(d)})
+			h.Write(input)
+			vector[d] = float32(h.Sum32())/float32(^uint32(0))*2 - 1
+		}
+		vectors[i] = vector
+	}
+	return vectors
+}
+
+// SetEmbeddingBackend sets the function used to compute embeddings; nil restores simulated vectors
+func (sm *ServingManager) SetEmbeddingBackend(backend EmbeddingBackend) {
+	sm.embeddings.setBackend(backend)
+}
+
+// SetEmbeddingConfig changes the embedding micro-batching configuration
+func (sm *ServingManager) SetEmbeddingConfig(config EmbeddingConfig) error {
+	if config.MaxBatchSize <= 0 {
+		return fmt.Errorf("embedding max batch size must be positive")
+	}
+	if config.MaxWaitTime < 0 {
+		return fmt.Errorf("embedding max wait time cannot be negative")
+	}
+	sm.embeddings.setConfig(config)
+	return nil
+}
+
+// SubmitEmbeddingRequest computes embeddings, micro-batching each input with
+// concurrent inputs for the same model so the backend sees large batches
+func (sm *ServingManager) SubmitEmbeddingRequest(req *EmbeddingRequest) (*EmbeddingResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("embedding request cannot be nil")
+	}
+	if req.ID == "" {
+		return nil, fmt.Errorf("request ID cannot be empty")
+	}
+	if req.ModelID == "" {
+		return nil, fmt.Errorf("model ID cannot be empty")
+	}
+	if len(req.Inputs) == 0 {
+		return nil, fmt.Errorf("embedding request must contain at least one input")
+	}
+
+	sm.mu.RLock()
+	_, registered := sm.models[req.ModelID]
+	sm.mu.RUnlock()
+	if !registered {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotRegistered, req.ModelID)
+	}
+
+	config := sm.embeddings.getConfig()
+	for i, input := range req.Inputs {
+		if len(input) == 0 {
+			return nil, fmt.Errorf("embedding input %d is empty", i)
+		}
+		if config.MaxInputSize > 0 && len(input) > config.MaxInputSize {
+			return nil, fmt.Errorf("embedding input %d exceeds %d bytes", i, config.MaxInputSize)
+		}
+	}
+
+	if sm.slaManager.IsShed(req.ModelID) {
+		return nil, fmt.Errorf("traffic for model %s is being shed to protect higher-tier SLAs", req.ModelID)
+	}
+
+	req.CreatedAt = time.Now()
+
+	if lifecycle := sm.GetLifecycleManager(); lifecycle != nil {
+		_, release, err := lifecycle.Acquire(req.ModelID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load model for request %s: %w", req.ID, err)
+		}
+		defer release()
+	}
+
+	items := make([]*embeddingItem, len(req.Inputs))
+	for i, input := range req.Inputs {
+		items[i] = sm.embeddings.enqueue(req.ModelID, input)
+	}
+
+	response := &EmbeddingResponse{
+		RequestID:  req.ID,
+		Embeddings: make([][]float32, len(items)),
+	}
+	for i, item := range items {
+		<-item.done
+		if item.err != nil {
+			sm.slaManager.RecordOutcome(req.ModelID, time.Since(req.CreatedAt), false)
+			return nil, fmt.Errorf("embedding failed for request %s: %w", req.ID, item.err)
+		}
+		response.Embeddings[i] = item.vector
+		if item.queueWait > response.QueueWait {
+			response.QueueWait = item.queueWait
+		}
+		if item.batchSize > response.MaxBatchSize {
+			response.MaxBatchSize = item.batchSize
+		}
+	}
+
+	response.CompletedAt = time.Now()
+	response.Latency = response.CompletedAt.Sub(req.CreatedAt)
+
+	sm.latencyTracker.Observe(req.ModelID, LatencyBreakdown{
+		BatchWait: response.QueueWait,
+		Execution: response.Latency - response.QueueWait,
+	})
+	sm.slaManager.RecordOutcome(req.ModelID, response.Latency, true)
+
+	return response, nil
+}
+
+// GetEmbeddingMetrics returns embedding micro-batching statistics
+func (sm *ServingManager) GetEmbeddingMetrics() map[string]interface{} {
+	return sm.embeddings.getStats()
+}
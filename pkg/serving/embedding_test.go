@@ -0,0 +1,120 @@
+package serving
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEmbeddingMicroBatching(t *testing.T) {
+	manager := NewServingManager(nil, time.Minute)
+	manager.RegisterModel(&Model{ID: "embedder", Name: "Embedder"})
+	if err := manager.SetEmbeddingConfig(EmbeddingConfig{MaxBatchSize: 8, MaxWaitTime: 20 * time.Millisecond}); err != nil {
+		t.Fatalf("Failed to set config: %v", err)
+	}
+
+	var calls int32
+	manager.SetEmbeddingBackend(func(modelID string, inputs [][]byte) ([][]float32, error) {
+		atomic.AddInt32(&calls, 1)
+		vectors := make([][]float32, len(inputs))
+		for i, input := range inputs {
+			vectors[i] = []float32{float32(len(input))}
+		}
+		return vectors, nil
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			input := []byte(fmt.Sprintf("chunk-%d%s", i, make([]byte, i)))
+			resp, err := manager.SubmitEmbeddingRequest(&EmbeddingRequest{
+				ID:      fmt.Sprintf("e-%d", i),
+				ModelID: "embedder",
+				Inputs:  [][]byte{input},
+			})
+			if err != nil {
+				errs <- err
+				return
+			}
+			if resp.Embeddings[0][0] != float32(len(input)) {
+				errs <- fmt.Errorf("request %d got embedding for the wrong input", i)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if c := atomic.LoadInt32(&calls); c > 4 {
+		t.Errorf("Expected 16 concurrent inputs to be micro-batched, got %d backend calls", c)
+	}
+
+	stats := manager.GetEmbeddingMetrics()
+	if stats["inputs"].(int64) != 16 {
+		t.Errorf("Expected 16 inputs, got %v", stats["inputs"])
+	}
+}
+
+func TestEmbeddingRequestWithManyInputs(t *testing.T) {
+	manager := NewServingManager(nil, time.Minute)
+	manager.RegisterModel(&Model{ID: "embedder", Name: "Embedder"})
+	manager.SetEmbeddingConfig(EmbeddingConfig{MaxBatchSize: 4, MaxWaitTime: 50 * time.Millisecond})
+
+	inputs := make([][]byte, 10)
+	for i := range inputs {
+		inputs[i] = []byte(fmt.Sprintf("doc-%d", i))
+	}
+
+	resp, err := manager.SubmitEmbeddingRequest(&EmbeddingRequest{ID: "bulk", ModelID: "embedder", Inputs: inputs})
+	if err != nil {
+		t.Fatalf("Embedding failed: %v", err)
+	}
+	if len(resp.Embeddings) != 10 || resp.MaxBatchSize != 4 {
+		t.Errorf("Expected 10 embeddings from batches of at most 4, got %d (max batch %d)", len(resp.Embeddings), resp.MaxBatchSize)
+	}
+
+	// Simulated embeddings are deterministic per input
+	again, _ := manager.SubmitEmbeddingRequest(&EmbeddingRequest{ID: "again", ModelID: "embedder", Inputs: inputs[:1]})
+	if fmt.Sprint(again.Embeddings[0]) != fmt.Sprint(resp.Embeddings[0]) {
+		t.Error("Expected identical inputs to produce identical simulated embeddings")
+	}
+
+	stats := manager.GetEmbeddingMetrics()
+	if stats["full_batches"].(int64) != 2 || stats["timeout_batches"].(int64) != 2 {
+		t.Errorf("Expected 2 full and 2 timeout batches, got %v", stats)
+	}
+}
+
+func TestEmbeddingValidationAndErrors(t *testing.T) {
+	manager := NewServingManager(nil, time.Minute)
+	manager.RegisterModel(&Model{ID: "embedder", Name: "Embedder"})
+	manager.SetEmbeddingConfig(EmbeddingConfig{MaxBatchSize: 4, MaxWaitTime: time.Millisecond, MaxInputSize: 8})
+
+	if _, err := manager.SubmitEmbeddingRequest(&EmbeddingRequest{ID: "e", ModelID: "embedder"}); err == nil {
+		t.Error("Expected error for request without inputs")
+	}
+	if _, err := manager.SubmitEmbeddingRequest(&EmbeddingRequest{ID: "e", ModelID: "embedder", Inputs: [][]byte{[]byte("too long input")}}); err == nil {
+		t.Error("Expected error for oversized input")
+	}
+	if _, err := manager.SubmitEmbeddingRequest(&EmbeddingRequest{ID: "e", ModelID: "unknown", Inputs: [][]byte{[]byte("x")}}); !errors.Is(err, ErrModelNotRegistered) {
+		t.Errorf("Expected an unregistered model to be rejected, got %v", err)
+	}
+	if err := manager.SetEmbeddingConfig(EmbeddingConfig{}); err == nil {
+		t.Error("Expected error for zero batch size")
+	}
+
+	manager.SetEmbeddingBackend(func(modelID string, inputs [][]byte) ([][]float32, error) {
+		return nil, errors.New("backend down")
+	})
+	if _, err := manager.SubmitEmbeddingRequest(&EmbeddingRequest{ID: "e", ModelID: "embedder", Inputs: [][]byte{[]byte("x")}}); err == nil {
+		t.Error("Expected backend error to be returned")
+	}
+}
@@ -0,0 +1,596 @@
+package serving
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ExperimentStatus is the lifecycle state of an experiment
+type ExperimentStatus string
+
+const (
+	ExperimentDraft     ExperimentStatus = "draft"
+	ExperimentRunning   ExperimentStatus = "running"
+	ExperimentConcluded ExperimentStatus = "concluded"
+)
+
+// Variant is one arm of an experiment
+type Variant struct {
+	Name           string  `json:"name"`
+	ModelID        string  `json:"model_id"`
+	Weight         float64 `json:"weight"`           // Relative share of traffic
+	CostPerRequest float64 `json:"cost_per_request"` // Cost of a request served by the variant when metering is disabled
+}
+
+// Experiment splits traffic for a model between variants
+type Experiment struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	ModelID     string           `json:"model_id"` // Model requested by clients
+	Variants    []Variant        `json:"variants"` // The first variant is the control
+	Status      ExperimentStatus `json:"status"`
+	Winner      string           `json:"winner,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	StartedAt   time.Time        `json:"started_at,omitempty"`
+	ConcludedAt time.Time        `json:"concluded_at,omitempty"`
+}
+
+// ExperimentEvent records an experiment lifecycle change
+type ExperimentEvent struct {
+	ExperimentID string    `json:"experiment_id"`
+	Type         string    `json:"type"` // experiment_created, experiment_started, experiment_concluded
+	Message      string    `json:"message"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// runningStat accumulates count, mean and variance (Welford)
+type runningStat struct {
+	n    int
+	mean float64
+	m2   float64
+}
+
+func (rs *runningStat) add(x float64) {
+	rs.n++
+	delta := x - rs.mean
+	rs.mean += delta / float64(rs.n)
+	rs.m2 += delta * (x - rs.mean)
+}
+
+func (rs *runningStat) variance() float64 {
+	if rs.n < 2 {
+		return 0
+	}
+	return rs.m2 / float64(rs.n-1)
+}
+
+// variantStats holds collected metrics for a variant
+type variantStats struct {
+	requests  int
+	errors    int
+	latencyMs runningStat
+	quality   runningStat
+	cost      runningStat
+}
+
+// MetricSummary summarises one metric of a variant
+type MetricSummary struct {
+	Count  int     `json:"count"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"std_dev"`
+}
+
+// VariantReport summarises a variant's results
+type VariantReport struct {
+	Name      string        `json:"name"`
+	ModelID   string        `json:"model_id"`
+	Requests  int           `json:"requests"`
+	ErrorRate float64       `json:"error_rate"`
+	LatencyMs MetricSummary `json:"latency_ms"`
+	Quality   MetricSummary `json:"quality"`
+	Cost      MetricSummary `json:"cost"`
+}
+
+// Comparison is the significance test of a variant metric against the control
+type Comparison struct {
+	Variant     string  `json:"variant"`
+	Metric      string  `json:"metric"` // latency_ms, quality, cost, error_rate
+	Difference  float64 `json:"difference"`
+	PValue      float64 `json:"p_value"`
+	Significant bool    `json:"significant"`
+}
+
+// ExperimentReport is the statistical report of an experiment
+type ExperimentReport struct {
+	Experiment  Experiment      `json:"experiment"`
+	Variants    []VariantReport `json:"variants"`
+	Comparisons []Comparison    `json:"comparisons"`
+	Alpha       float64         `json:"alpha"`
+}
+
+// ExperimentManager runs A/B experiments across model variants
+type ExperimentManager struct {
+	experiments map[string]*Experiment
+	stats       map[string]map[string]*variantStats // experiment ID -> variant name -> stats
+	events      []ExperimentEvent
+	handlers    []func(ExperimentEvent)
+	alpha       float64
+	mu          sync.RWMutex
+}
+
+// NewExperimentManager creates a new experiment manager
+func NewExperimentManager() *ExperimentManager {
+	return &ExperimentManager{
+		experiments: make(map[string]*Experiment),
+		stats:       make(map[string]map[string]*variantStats),
+		events:      make([]ExperimentEvent, 0),
+		handlers:    make([]func(ExperimentEvent), 0),
+		alpha:       0.05,
+	}
+}
+
+// OnEvent registers a handler called for every experiment event
+func (em *ExperimentManager) OnEvent(handler func(ExperimentEvent)) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	em.handlers = append(em.handlers, handler)
+}
+
+// CreateExperiment registers a new experiment in draft state
+func (em *ExperimentManager) CreateExperiment(exp *Experiment) error {
+	if exp == nil {
+		return fmt.Errorf("experiment cannot be nil")
+	}
+	if exp.ID == "" {
+		return fmt.Errorf("experiment ID cannot be empty")
+	}
+	if exp.ModelID == "" {
+		return fmt.Errorf("model ID cannot be empty")
+	}
+	if len(exp.Variants) < 2 {
+		return fmt.Errorf("experiment needs at least two variants")
+	}
+
+	names := make(map[string]bool)
+	for _, v := range exp.Variants {
+		if v.Name == "" || v.ModelID == "" {
+			return fmt.Errorf("variant name and model ID cannot be empty")
+		}
+		if v.Weight <= 0 {
+			return fmt.Errorf("variant %s weight must be positive", v.Name)
+		}
+		if names[v.Name] {
+			return fmt.Errorf("duplicate variant %s", v.Name)
+		}
+		names[v.Name] = true
+	}
+
+	em.mu.Lock()
+	if _, exists := em.experiments[exp.ID]; exists {
+		em.mu.Unlock()
+		return fmt.Errorf("experiment %s already exists", exp.ID)
+	}
+
+	exp.Status = ExperimentDraft
+	exp.CreatedAt = time.Now()
+	em.experiments[exp.ID] = exp
+	em.stats[exp.ID] = make(map[string]*variantStats)
+	for _, v := range exp.Variants {
+		em.stats[exp.ID][v.Name] = &variantStats{}
+	}
+	event := em.recordEventLocked(exp.ID, "experiment_created", fmt.Sprintf("Experiment %s created for model %s", exp.Name, exp.ModelID))
+	handlers := append([]func(ExperimentEvent){}, em.handlers...)
+	em.mu.Unlock()
+
+	notifyExperimentHandlers(handlers, event)
+	return nil
+}
+
+// StartExperiment begins splitting traffic; only one experiment may run per model
+func (em *ExperimentManager) StartExperiment(id string) error {
+	em.mu.Lock()
+	exp, exists := em.experiments[id]
+	if !exists {
+		em.mu.Unlock()
+		return fmt.Errorf("experiment %s not found", id)
+	}
+	if exp.Status != ExperimentDraft {
+		em.mu.Unlock()
+		return fmt.Errorf("experiment %s is %s, only draft experiments can be started", id, exp.Status)
+	}
+	for _, other := range em.experiments {
+		if other.Status == ExperimentRunning && other.ModelID == exp.ModelID {
+			em.mu.Unlock()
+			return fmt.Errorf("experiment %s is already running for model %s", other.ID, exp.ModelID)
+		}
+	}
+
+	exp.Status = ExperimentRunning
+	exp.StartedAt = time.Now()
+	event := em.recordEventLocked(id, "experiment_started", fmt.Sprintf("Experiment %s started", exp.Name))
+	handlers := append([]func(ExperimentEvent){}, em.handlers...)
+	em.mu.Unlock()
+
+	notifyExperimentHandlers(handlers, event)
+	return nil
+}
+
+// ConcludeExperiment stops the experiment, optionally recording the winning variant
+func (em *ExperimentManager) ConcludeExperiment(id, winner string) error {
+	em.mu.Lock()
+	exp, exists := em.experiments[id]
+	if !exists {
+		em.mu.Unlock()
+		return fmt.Errorf("experiment %s not found", id)
+	}
+	if exp.Status != ExperimentRunning {
+		em.mu.Unlock()
+		return fmt.Errorf("experiment %s is not running", id)
+	}
+	if winner != "" {
+		if _, exists := em.stats[id][winner]; !exists {
+			em.mu.Unlock()
+			return fmt.Errorf("variant %s not found", winner)
+		}
+	}
+
+	exp.Status = ExperimentConcluded
+	exp.ConcludedAt = time.Now()
+	exp.Winner = winner
+	message := fmt.Sprintf("Experiment %s concluded", exp.Name)
+	if winner != "" {
+		message = fmt.Sprintf("Experiment %s concluded, winner: %s", exp.Name, winner)
+	}
+	event := em.recordEventLocked(id, "experiment_concluded", message)
+	handlers := append([]func(ExperimentEvent){}, em.handlers...)
+	em.mu.Unlock()
+
+	notifyExperimentHandlers(handlers, event)
+	return nil
+}
+
+// AssignVariant picks the variant for a request to modelID; the same key (e.g. a session or
+// user ID) always receives the same variant. Returns false when no experiment is running.
+func (em *ExperimentManager) AssignVariant(modelID, key string) (experimentID string, variant Variant, ok bool) {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+
+	for _, exp := range em.experiments {
+		if exp.Status != ExperimentRunning || exp.ModelID != modelID {
+			continue
+		}
+
+		total := 0.0
+		for _, v := range exp.Variants {
+			total += v.Weight
+		}
+
+		h := fnv.New64a()
+		h.Write([]byte(exp.ID))
+		h.Write([]byte{0})
+		h.Write([]byte(key))
+		// Low bits of FNV mix better than high bits for short, similar keys
+		point := float64(h.Sum64()%10000) / 10000 * total
+
+		for _, v := range exp.Variants {
+			if point < v.Weight {
+				return exp.ID, v, true
+			}
+			point -= v.Weight
+		}
+		return exp.ID, exp.Variants[len(exp.Variants)-1], true
+	}
+
+	return "", Variant{}, false
+}
+
+// RecordOutcome records the latency and success of a request served by a variant
+func (em *ExperimentManager) RecordOutcome(experimentID, variant string, latency time.Duration, success bool) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	stats := em.variantStatsLocked(experimentID, variant)
+	if stats == nil {
+		return
+	}
+
+	stats.requests++
+	if !success {
+		stats.errors++
+		return
+	}
+	stats.latencyMs.add(float64(latency.Microseconds()) / 1000)
+}
+
+// RecordQuality records a quality score (e.g. user rating or eval score) for a variant
+func (em *ExperimentManager) RecordQuality(experimentID, variant string, score float64) error {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	stats := em.variantStatsLocked(experimentID, variant)
+	if stats == nil {
+		return fmt.Errorf("variant %s not found in experiment %s", variant, experimentID)
+	}
+	stats.quality.add(score)
+	return nil
+}
+
+// RecordCost records the cost of a request served by a variant
+func (em *ExperimentManager) RecordCost(experimentID, variant string, cost float64) error {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	stats := em.variantStatsLocked(experimentID, variant)
+	if stats == nil {
+		return fmt.Errorf("variant %s not found in experiment %s", variant, experimentID)
+	}
+	stats.cost.add(cost)
+	return nil
+}
+
+// GetExperiment returns a copy of an experiment
+func (em *ExperimentManager) GetExperiment(id string) (Experiment, bool) {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+
+	exp, exists := em.experiments[id]
+	if !exists {
+		return Experiment{}, false
+	}
+	return *exp, true
+}
+
+// ListExperiments returns all experiments sorted by creation time
+func (em *ExperimentManager) ListExperiments() []Experiment {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+
+	result := make([]Experiment, 0, len(em.experiments))
+	for _, exp := range em.experiments {
+		result = append(result, *exp)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// GetEvents returns experiment events since a point in time
+func (em *ExperimentManager) GetEvents(since time.Time) []ExperimentEvent {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+
+	result := make([]ExperimentEvent, 0)
+	for _, event := range em.events {
+		if event.Timestamp.After(since) {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+// GetReport computes per-variant metrics and significance against the control variant
+func (em *ExperimentManager) GetReport(id string) (*ExperimentReport, error) {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+
+	exp, exists := em.experiments[id]
+	if !exists {
+		return nil, fmt.Errorf("experiment %s not found", id)
+	}
+
+	report := &ExperimentReport{Experiment: *exp, Alpha: em.alpha}
+	for _, v := range exp.Variants {
+		stats := em.stats[id][v.Name]
+		errorRate := 0.0
+		if stats.requests > 0 {
+			errorRate = float64(stats.errors) / float64(stats.requests)
+		}
+		report.Variants = append(report.Variants, VariantReport{
+			Name:      v.Name,
+			ModelID:   v.ModelID,
+			Requests:  stats.requests,
+			ErrorRate: errorRate,
+			LatencyMs: summarize(stats.latencyMs),
+			Quality:   summarize(stats.quality),
+			Cost:      summarize(stats.cost),
+		})
+	}
+
+	control := em.stats[id][exp.Variants[0].Name]
+	for _, v := range exp.Variants[1:] {
+		stats := em.stats[id][v.Name]
+		metrics := []struct {
+			name string
+			a, b runningStat
+		}{
+			{"latency_ms", control.latencyMs, stats.latencyMs},
+			{"quality", control.quality, stats.quality},
+			{"cost", control.cost, stats.cost},
+		}
+		for _, m := range metrics {
+			if m.a.n < 2 || m.b.n < 2 {
+				continue
+			}
+			p := welchTTest(m.a, m.b)
+			report.Comparisons = append(report.Comparisons, Comparison{
+				Variant:     v.Name,
+				Metric:      m.name,
+				Difference:  m.b.mean - m.a.mean,
+				PValue:      p,
+				Significant: p < em.alpha,
+			})
+		}
+
+		if control.requests > 0 && stats.requests > 0 {
+			p := twoProportionZTest(control.errors, control.requests, stats.errors, stats.requests)
+			report.Comparisons = append(report.Comparisons, Comparison{
+				Variant:     v.Name,
+				Metric:      "error_rate",
+				Difference:  float64(stats.errors)/float64(stats.requests) - float64(control.errors)/float64(control.requests),
+				PValue:      p,
+				Significant: p < em.alpha,
+			})
+		}
+	}
+
+	return report, nil
+}
+
+// variantStatsLocked returns the stats of a variant in a running experiment; caller must hold the lock
+func (em *ExperimentManager) variantStatsLocked(experimentID, variant string) *variantStats {
+	exp, exists := em.experiments[experimentID]
+	if !exists || exp.Status != ExperimentRunning {
+		return nil
+	}
+	return em.stats[experimentID][variant]
+}
+
+// recordEventLocked appends an event; caller must hold the lock
+func (em *ExperimentManager) recordEventLocked(experimentID, eventType, message string) ExperimentEvent {
+	event := ExperimentEvent{
+		ExperimentID: experimentID,
+		Type:         eventType,
+		Message:      message,
+		Timestamp:    time.Now(),
+	}
+	em.events = append(em.events, event)
+	return event
+}
+
+func notifyExperimentHandlers(handlers []func(ExperimentEvent), event ExperimentEvent) {
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+func summarize(rs runningStat) MetricSummary {
+	return MetricSummary{Count: rs.n, Mean: rs.mean, StdDev: math.Sqrt(rs.variance())}
+}
+
+// Summarize returns the count, mean and standard deviation of values
+func Summarize(values []float64) MetricSummary {
+	var rs runningStat
+	for _, value := range values {
+		rs.add(value)
+	}
+	return summarize(rs)
+}
+
+// WelchPValue returns the two-sided p-value of Welch's t-test for a
+// difference between the means of two samples of at least two values each
+func WelchPValue(a, b MetricSummary) float64 {
+	return welchTTest(a.runningStat(), b.runningStat())
+}
+
+// runningStat restores the running statistics a summary was taken from
+func (s MetricSummary) runningStat() runningStat {
+	return runningStat{n: s.Count, mean: s.Mean, m2: s.StdDev * s.StdDev * float64(s.Count-1)}
+}
+
+// welchTTest returns the two-sided p-value of Welch's t-test for a difference in means
+func welchTTest(a, b runningStat) float64 {
+	va, vb := a.variance()/float64(a.n), b.variance()/float64(b.n)
+	if va+vb == 0 {
+		if a.mean == b.mean {
+			return 1
+		}
+		return 0
+	}
+
+	t := (b.mean - a.mean) / math.Sqrt(va+vb)
+	df := (va + vb) * (va + vb) / (va*va/float64(a.n-1) + vb*vb/float64(b.n-1))
+
+	// Two-sided p-value from the Student t distribution
+	return regularizedIncompleteBeta(df/(df+t*t), df/2, 0.5)
+}
+
+// twoProportionZTest returns the two-sided p-value for a difference in proportions
+func twoProportionZTest(x1, n1, x2, n2 int) float64 {
+	p1, p2 := float64(x1)/float64(n1), float64(x2)/float64(n2)
+	pooled := float64(x1+x2) / float64(n1+n2)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(n1) + 1/float64(n2)))
+	if se == 0 {
+		return 1
+	}
+	z := math.Abs(p2-p1) / se
+	return math.Erfc(z / math.Sqrt2)
+}
+
+// regularizedIncompleteBeta computes I_x(a, b) using a continued fraction expansion
+func regularizedIncompleteBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	lga, _ := math.Lgamma(a)
+	lgb, _ := math.Lgamma(b)
+	lgab, _ := math.Lgamma(a + b)
+	front := math.Exp(lgab - lga - lgb + a*math.Log(x) + b*math.Log(1-x))
+
+	// Use the symmetry relation for faster convergence
+	if x > (a+1)/(a+b+2) {
+		return 1 - front*betaContinuedFraction(1-x, b, a)/b
+	}
+	return front * betaContinuedFraction(x, a, b) / a
+}
+
+// betaContinuedFraction evaluates the continued fraction for the incomplete beta function
+func betaContinuedFraction(x, a, b float64) float64 {
+	const (
+		maxIterations = 200
+		epsilon       = 1e-12
+		tiny          = 1e-300
+	)
+
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	result := d
+
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+
+		// Even step
+		num := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		result *= d * c
+
+		// Odd step
+		num = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		result *= delta
+
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,172 @@
+package serving
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+func newTestExperiment() *Experiment {
+	return &Experiment{
+		ID:      "exp-1",
+		Name:    "Distilled model",
+		ModelID: "chat",
+		Variants: []Variant{
+			{Name: "control", ModelID: "chat-large", Weight: 1},
+			{Name: "candidate", ModelID: "chat-small", Weight: 1, CostPerRequest: 0.001},
+		},
+	}
+}
+
+func TestExperimentLifecycleAndTrafficSplit(t *testing.T) {
+	manager := NewServingManager(nil, time.Minute)
+	experiments := manager.GetExperimentManager()
+
+	events := make([]string, 0)
+	experiments.OnEvent(func(e ExperimentEvent) {
+		events = append(events, e.Type)
+	})
+
+	if err := experiments.CreateExperiment(newTestExperiment()); err != nil {
+		t.Fatalf("Failed to create experiment: %v", err)
+	}
+	if err := experiments.ConcludeExperiment("exp-1", ""); err == nil {
+		t.Error("Draft experiment should not be concludable")
+	}
+	if err := experiments.StartExperiment("exp-1"); err != nil {
+		t.Fatalf("Failed to start experiment: %v", err)
+	}
+
+	served := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		req := &InferenceRequest{ID: fmt.Sprintf("r-%d", i), ModelID: "chat", Input: []byte(fmt.Sprintf("q-%d", i))}
+		resp, err := manager.SubmitInferenceRequest(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if req.ModelID != "chat" || resp.ExperimentID != "exp-1" {
+			t.Fatalf("Expected the caller's request untouched and the experiment on the response, got %s and %+v", req.ModelID, resp)
+		}
+		served[resp.Variant]++
+	}
+	if served["control"] < 60 || served["candidate"] < 60 {
+		t.Errorf("Expected roughly even split, got %v", served)
+	}
+
+	// The same session always gets the same variant
+	_, first, _ := experiments.AssignVariant("chat", "session-1")
+	for i := 0; i < 10; i++ {
+		_, v, _ := experiments.AssignVariant("chat", "session-1")
+		if v.Name != first.Name {
+			t.Fatal("Expected sticky variant assignment for a session")
+		}
+	}
+
+	report, err := experiments.GetReport("exp-1")
+	if err != nil {
+		t.Fatalf("Failed to get report: %v", err)
+	}
+	if report.Variants[0].Requests+report.Variants[1].Requests != 200 {
+		t.Errorf("Expected 200 recorded requests, got %+v", report.Variants)
+	}
+	if report.Variants[1].Cost.Count != report.Variants[1].Requests {
+		t.Errorf("Expected one cost per candidate request, got %+v", report.Variants[1])
+	}
+
+	if err := experiments.ConcludeExperiment("exp-1", "candidate"); err != nil {
+		t.Fatalf("Failed to conclude experiment: %v", err)
+	}
+	if _, _, ok := experiments.AssignVariant("chat", "x"); ok {
+		t.Error("Concluded experiment should not receive traffic")
+	}
+
+	expected := []string{"experiment_created", "experiment_started", "experiment_concluded"}
+	if fmt.Sprint(events) != fmt.Sprint(expected) {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+}
+
+func TestExperimentMetersCostOnce(t *testing.T) {
+	manager := NewServingManager(nil, time.Minute)
+	manager.EnableMetering(DefaultMeteringConfig())
+	experiments := manager.GetExperimentManager()
+	experiments.CreateExperiment(newTestExperiment())
+	experiments.StartExperiment("exp-1")
+
+	resp, err := manager.SubmitInferenceRequest(&InferenceRequest{ID: "r-1", ModelID: "chat", Input: []byte("q")})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if err := experiments.RecordQuality(resp.ExperimentID, resp.Variant, 4.5); err != nil {
+		t.Errorf("Expected the response to identify its variant: %v", err)
+	}
+
+	report, _ := experiments.GetReport("exp-1")
+	for _, v := range report.Variants {
+		if v.Name != resp.Variant {
+			continue
+		}
+		if v.Cost.Count != 1 || math.Abs(v.Cost.Mean-resp.Cost.Total) > 1e-12 {
+			t.Errorf("Expected the metered cost recorded once, got %+v for %+v", v.Cost, resp.Cost)
+		}
+		if v.Quality.Count != 1 {
+			t.Errorf("Expected the quality score attributed to %s, got %+v", v.Name, v.Quality)
+		}
+	}
+}
+
+func TestExperimentSignificance(t *testing.T) {
+	experiments := NewExperimentManager()
+	experiments.CreateExperiment(newTestExperiment())
+	experiments.StartExperiment("exp-1")
+
+	for i := 0; i < 100; i++ {
+		jitter := time.Duration(i%10) * time.Millisecond
+		experiments.RecordOutcome("exp-1", "control", 100*time.Millisecond+jitter, true)
+		experiments.RecordOutcome("exp-1", "candidate", 60*time.Millisecond+jitter, true)
+		experiments.RecordQuality("exp-1", "control", 4.0+float64(i%3)*0.1)
+		experiments.RecordQuality("exp-1", "candidate", 4.0+float64((i+1)%3)*0.1)
+	}
+
+	report, _ := experiments.GetReport("exp-1")
+	results := make(map[string]Comparison)
+	for _, c := range report.Comparisons {
+		results[c.Metric] = c
+	}
+
+	if !results["latency_ms"].Significant || results["latency_ms"].Difference > -39 {
+		t.Errorf("Expected significant latency improvement, got %+v", results["latency_ms"])
+	}
+	if results["quality"].Significant {
+		t.Errorf("Expected no significant quality difference, got %+v", results["quality"])
+	}
+}
+
+func TestStatisticalHelpers(t *testing.T) {
+	// I_0.5(a, a) = 0.5 by symmetry
+	if v := regularizedIncompleteBeta(0.5, 3, 3); math.Abs(v-0.5) > 1e-9 {
+		t.Errorf("Expected 0.5, got %f", v)
+	}
+
+	// t = 2.228 with 10 degrees of freedom is the 5% two-sided critical value
+	df, tStat := 10.0, 2.228
+	if p := regularizedIncompleteBeta(df/(df+tStat*tStat), df/2, 0.5); math.Abs(p-0.05) > 0.001 {
+		t.Errorf("Expected p close to 0.05, got %f", p)
+	}
+
+	if p := twoProportionZTest(10, 100, 10, 100); p != 1 {
+		t.Errorf("Expected p=1 for equal proportions, got %f", p)
+	}
+
+	before := Summarize([]float64{10, 11, 9, 10, 12, 8})
+	if before.Count != 6 || before.Mean != 10 || math.Abs(before.StdDev-math.Sqrt(2)) > 1e-9 {
+		t.Errorf("Unexpected summary %+v", before)
+	}
+	if p := WelchPValue(before, Summarize([]float64{20, 21, 19, 20, 22, 18})); p > 0.001 {
+		t.Errorf("Expected a shift of 10 to be significant, got p=%f", p)
+	}
+	if p := WelchPValue(before, Summarize([]float64{10, 12, 8, 11, 9, 10})); p < 0.9 {
+		t.Errorf("Expected the same distribution not to be significant, got p=%f", p)
+	}
+}
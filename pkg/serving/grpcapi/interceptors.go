@@ -0,0 +1,245 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving"
+)
+
+// Authenticator validates the bearer token of an incoming call
+type Authenticator func(ctx context.Context, token string) error
+
+// StaticTokenAuthenticator accepts any of a fixed set of bearer tokens
+func StaticTokenAuthenticator(tokens ...string) Authenticator {
+	return func(ctx context.Context, token string) error {
+		for _, valid := range tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(valid)) == 1 {
+				return nil
+			}
+		}
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+}
+
+// APIKeyAuthenticator accepts active API keys from store that grant scope
+func APIKeyAuthenticator(store *apikeys.Store, scope apikeys.Scope) Authenticator {
+	return func(ctx context.Context, token string) error {
+		key, err := store.Authenticate(token)
+		if err != nil {
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+		if !key.HasScope(scope) {
+			return status.Errorf(codes.PermissionDenied, "API key %s lacks the %s scope", key.ID, scope)
+		}
+		return nil
+	}
+}
+
+type traceIDKey struct{}
+
+// TraceIDFromContext returns the trace ID of the call, from the active span or x-trace-id metadata
+func TraceIDFromContext(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc.TraceID().String()
+	}
+	if traceID, ok := ctx.Value(traceIDKey{}).(string); ok {
+		return traceID
+	}
+	return ""
+}
+
+// metadataCarrier adapts gRPC metadata for OpenTelemetry propagation
+type metadataCarrier metadata.MD
+
+func (mc metadataCarrier) Get(key string) string {
+	values := metadata.MD(mc).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (mc metadataCarrier) Set(key, value string) {
+	metadata.MD(mc).Set(key, value)
+}
+
+func (mc metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(mc))
+	for key := range mc {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// startSpan extracts the incoming trace context and starts a server span for the method
+func startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	if values := md.Get("x-trace-id"); len(values) > 0 && values[0] != "" {
+		ctx = context.WithValue(ctx, traceIDKey{}, values[0])
+	}
+
+	ctx, span := otel.Tracer("agentaflow-grpc").Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.method", method),
+		),
+	)
+	return ctx, span
+}
+
+// endSpan records the call outcome on the span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+		span.SetAttributes(attribute.String("rpc.grpc.status_code", status.Code(err).String()))
+	} else {
+		span.SetStatus(otelcodes.Ok, "")
+	}
+	span.End()
+}
+
+// TracingUnaryInterceptor creates a span per unary call, continuing any incoming trace
+func TracingUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := startSpan(ctx, info.FullMethod)
+		resp, err := handler(ctx, req)
+		endSpan(span, err)
+		return resp, err
+	}
+}
+
+// TracingStreamInterceptor creates a span per streaming call, continuing any incoming trace
+func TracingStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startSpan(ss.Context(), info.FullMethod)
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		endSpan(span, err)
+		return err
+	}
+}
+
+// contextStream overrides the context of a server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (cs *contextStream) Context() context.Context {
+	return cs.ctx
+}
+
+// callerKey carries the identity of an authenticated caller
+type callerKey struct{}
+
+// authenticate validates the bearer token in the authorization metadata and
+// returns the context with the caller identified by its token
+func authenticate(ctx context.Context, auth Authenticator) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+
+	token := strings.TrimSpace(strings.TrimPrefix(values[0], "Bearer "))
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+
+	if err := auth(ctx, token); err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	// Keyed on a hash so the token itself is not kept around
+	hash := sha256.Sum256([]byte(token))
+	return context.WithValue(ctx, callerKey{}, "token:"+hex.EncodeToString(hash[:8])), nil
+}
+
+// AuthUnaryInterceptor rejects unary calls without a valid bearer token
+func AuthUnaryInterceptor(auth Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, auth)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthStreamInterceptor rejects streaming calls without a valid bearer token
+func AuthStreamInterceptor(auth Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), auth)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// clientKey identifies the caller for rate limiting by its authenticated
+// token, else its verified client certificate, else its address. Metadata
+// the caller sets is never used, or a caller could pick a fresh bucket for
+// every call.
+func clientKey(ctx context.Context) string {
+	if caller, ok := ctx.Value(callerKey{}).(string); ok {
+		return caller
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+	if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		if chains := tlsInfo.State.VerifiedChains; len(chains) > 0 && len(chains[0]) > 0 {
+			return "mtls:" + chains[0][0].Subject.String()
+		}
+	}
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return "peer:" + addr
+}
+
+// RateLimitUnaryInterceptor limits unary calls per client
+func RateLimitUnaryInterceptor(limiter *serving.RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !limiter.Allow(clientKey(ctx)) {
+			return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStreamInterceptor limits streaming calls per client
+func RateLimitStreamInterceptor(limiter *serving.RateLimiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !limiter.Allow(clientKey(ss.Context())) {
+			return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		}
+		return handler(srv, ss)
+	}
+}
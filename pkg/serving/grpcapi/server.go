@@ -0,0 +1,271 @@
+// Package grpcapi exposes the serving manager as a gRPC InferenceService
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving/inferencepb"
+)
+
+// rateLimitCleanupInterval is how often idle rate limit buckets are dropped
+const rateLimitCleanupInterval = time.Minute
+
+// Config holds gRPC server configuration
+type Config struct {
+	Port             int
+	EnableTracing    bool
+	Authenticator    Authenticator // Optional; requests are unauthenticated when nil
+	RateLimit        float64       // Requests per second per caller; 0 disables rate limiting
+	RateBurst        int
+	MaxBatchRequests int
+}
+
+// DefaultConfig returns default gRPC server configuration
+func DefaultConfig() Config {
+	return Config{
+		Port:             9091,
+		EnableTracing:    true,
+		RateLimit:        100,
+		RateBurst:        200,
+		MaxBatchRequests: 256,
+	}
+}
+
+// Server serves inference requests over gRPC
+type Server struct {
+	inferencepb.UnimplementedInferenceServiceServer
+
+	manager    *serving.ServingManager
+	config     Config
+	grpcServer *grpc.Server
+
+	// Rate limit buckets, one per caller, and the cleanup of idle ones
+	limiter     *serving.RateLimiter
+	cleanupOnce sync.Once
+	stopOnce    sync.Once
+	stop        chan struct{}
+}
+
+// NewServer creates a new gRPC inference server
+func NewServer(manager *serving.ServingManager, config Config) (*Server, error) {
+	if manager == nil {
+		return nil, fmt.Errorf("serving manager cannot be nil")
+	}
+	if config.MaxBatchRequests <= 0 {
+		config.MaxBatchRequests = 256
+	}
+
+	unary := make([]grpc.UnaryServerInterceptor, 0)
+	stream := make([]grpc.StreamServerInterceptor, 0)
+
+	if config.EnableTracing {
+		unary = append(unary, TracingUnaryInterceptor())
+		stream = append(stream, TracingStreamInterceptor())
+	}
+	if config.Authenticator != nil {
+		unary = append(unary, AuthUnaryInterceptor(config.Authenticator))
+		stream = append(stream, AuthStreamInterceptor(config.Authenticator))
+	}
+
+	s := &Server{
+		manager: manager,
+		config:  config,
+		stop:    make(chan struct{}),
+	}
+	if config.RateLimit > 0 {
+		limiter, err := serving.NewRateLimiter(config.RateLimit, config.RateBurst)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit: %w", err)
+		}
+		s.limiter = limiter
+		unary = append(unary, RateLimitUnaryInterceptor(limiter))
+		stream = append(stream, RateLimitStreamInterceptor(limiter))
+	}
+
+	s.grpcServer = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
+	inferencepb.RegisterInferenceServiceServer(s.grpcServer, s)
+
+	return s, nil
+}
+
+// Start listens on the configured port and serves until Stop is called
+func (s *Server) Start() error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.config.Port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %d: %w", s.config.Port, err)
+	}
+	return s.Serve(lis)
+}
+
+// Serve serves gRPC requests on an existing listener
+func (s *Server) Serve(lis net.Listener) error {
+	if s.limiter != nil {
+		s.cleanupOnce.Do(func() { go s.cleanupRateLimits() })
+	}
+	return s.grpcServer.Serve(lis)
+}
+
+// Stop gracefully stops the server
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+	s.grpcServer.GracefulStop()
+}
+
+// cleanupRateLimits drops the buckets of callers idle long enough for their
+// bucket to refill, which a new caller would get anyway, until Stop
+func (s *Server) cleanupRateLimits() {
+	refill := time.Duration(float64(s.config.RateBurst) / s.config.RateLimit * float64(time.Second))
+	ticker := time.NewTicker(rateLimitCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.limiter.Cleanup(refill)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Infer runs a single inference request
+func (s *Server) Infer(ctx context.Context, req *inferencepb.InferRequest) (*inferencepb.InferResponse, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.manager.SubmitInferenceRequest(toServingRequest(ctx, req))
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return fromServingResponse(resp), nil
+}
+
+// InferBatch submits every request in the batch and streams each response back
+func (s *Server) InferBatch(req *inferencepb.BatchInferRequest, stream inferencepb.InferenceService_InferBatchServer) error {
+	if len(req.Requests) == 0 {
+		return status.Error(codes.InvalidArgument, "batch cannot be empty")
+	}
+	if len(req.Requests) > s.config.MaxBatchRequests {
+		return status.Errorf(codes.InvalidArgument, "batch exceeds %d requests", s.config.MaxBatchRequests)
+	}
+
+	ctx := stream.Context()
+	for _, r := range req.Requests {
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		var out *inferencepb.InferResponse
+		if err := validateRequest(r); err != nil {
+			out = &inferencepb.InferResponse{RequestId: r.GetRequestId(), Error: status.Convert(err).Message()}
+		} else if resp, err := s.manager.SubmitInferenceRequest(toServingRequest(ctx, r)); err != nil {
+			out = &inferencepb.InferResponse{RequestId: r.RequestId, Error: err.Error()}
+		} else {
+			out = fromServingResponse(resp)
+		}
+
+		if err := stream.Send(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRequest checks required fields before the request reaches the serving manager
+func validateRequest(req *inferencepb.InferRequest) error {
+	if req == nil {
+		return status.Error(codes.InvalidArgument, "inference request cannot be nil")
+	}
+	if req.RequestId == "" {
+		return status.Error(codes.InvalidArgument, "request ID cannot be empty")
+	}
+	if req.ModelId == "" {
+		return status.Error(codes.InvalidArgument, "model ID cannot be empty")
+	}
+	if len(req.Input) == 0 {
+		return status.Error(codes.InvalidArgument, "request input cannot be empty")
+	}
+	return nil
+}
+
+// toServingRequest converts a wire request, inheriting the trace from the call context
+func toServingRequest(ctx context.Context, req *inferencepb.InferRequest) *serving.InferenceRequest {
+	traceID := req.TraceId
+	if traceID == "" {
+		traceID = TraceIDFromContext(ctx)
+	}
+
+	return &serving.InferenceRequest{
+		ID:       req.RequestId,
+		ModelID:  req.ModelId,
+		Input:    req.Input,
+		Priority: int(req.Priority),
+		TraceID:  traceID,
+	}
+}
+
+// fromServingResponse converts a serving response to its wire form
+func fromServingResponse(resp *serving.InferenceResponse) *inferencepb.InferResponse {
+	return &inferencepb.InferResponse{
+		RequestId: resp.RequestID,
+		Output:    resp.Output,
+		LatencyUs: resp.Latency.Microseconds(),
+		CacheHit:  resp.CacheHit,
+		BatchSize: int32(resp.BatchSize),
+		Breakdown: &inferencepb.LatencyBreakdown{
+			CacheCheckUs:       resp.Breakdown.CacheCheck.Microseconds(),
+			QueueWaitUs:        resp.Breakdown.QueueWait.Microseconds(),
+			BatchWaitUs:        resp.Breakdown.BatchWait.Microseconds(),
+			BackendExecutionUs: resp.Breakdown.Execution.Microseconds(),
+		},
+	}
+}
+
+// Client is a Go client for the InferenceService
+type Client struct {
+	api inferencepb.InferenceServiceClient
+}
+
+// NewClient creates a new inference client on an established connection
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{api: inferencepb.NewInferenceServiceClient(conn)}
+}
+
+// Infer runs a single inference request
+func (c *Client) Infer(ctx context.Context, req *inferencepb.InferRequest, opts ...grpc.CallOption) (*inferencepb.InferResponse, error) {
+	return c.api.Infer(ctx, req, opts...)
+}
+
+// InferBatch submits a batch and collects every streamed response
+func (c *Client) InferBatch(ctx context.Context, req *inferencepb.BatchInferRequest, opts ...grpc.CallOption) ([]*inferencepb.InferResponse, error) {
+	stream, err := c.api.InferBatch(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*inferencepb.InferResponse, 0, len(req.Requests))
+	for {
+		out, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return responses, nil
+			}
+			return responses, err
+		}
+		responses = append(responses, out)
+	}
+}
@@ -0,0 +1,160 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/serving/inferencepb"
+)
+
+func startTestServer(t *testing.T, config Config) *Client {
+	manager := serving.NewServingManager(nil, time.Minute)
+	manager.RegisterModel(&serving.Model{ID: "m", Name: "Model"})
+
+	server, err := NewServer(manager, config)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return NewClient(conn)
+}
+
+func TestInferUnaryAndStreaming(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableTracing = false
+	client := startTestServer(t, config)
+	ctx := context.Background()
+
+	resp, err := client.Infer(ctx, &inferencepb.InferRequest{RequestId: "r-1", ModelId: "m", Input: []byte("hi")})
+	if err != nil {
+		t.Fatalf("Infer failed: %v", err)
+	}
+	if resp.RequestId != "r-1" || len(resp.Output) == 0 || resp.Breakdown == nil {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+
+	if _, err := client.Infer(ctx, &inferencepb.InferRequest{RequestId: "r-2", ModelId: "m"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for empty input, got %v", err)
+	}
+
+	responses, err := client.InferBatch(ctx, &inferencepb.BatchInferRequest{Requests: []*inferencepb.InferRequest{
+		{RequestId: "b-1", ModelId: "m", Input: []byte("1")},
+		{RequestId: "b-2", ModelId: "m"},
+	}})
+	if err != nil {
+		t.Fatalf("InferBatch failed: %v", err)
+	}
+	if len(responses) != 2 {
+		t.Fatalf("Expected 2 streamed responses, got %d", len(responses))
+	}
+	if responses[0].Error != "" || responses[1].Error == "" {
+		t.Errorf("Expected only the invalid request to fail: %+v", responses)
+	}
+}
+
+func TestAuthAndRateLimitInterceptors(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableTracing = false
+	config.Authenticator = StaticTokenAuthenticator("secret")
+	config.RateLimit = 1
+	config.RateBurst = 1
+	client := startTestServer(t, config)
+
+	req := &inferencepb.InferRequest{RequestId: "r", ModelId: "m", Input: []byte("x")}
+
+	if _, err := client.Infer(context.Background(), req); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated without token, got %v", err)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	if _, err := client.Infer(ctx, req); err != nil {
+		t.Fatalf("Expected authenticated call to succeed: %v", err)
+	}
+	if _, err := client.Infer(ctx, req); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected ResourceExhausted after burst, got %v", err)
+	}
+
+	// A caller cannot get a fresh bucket by naming itself differently
+	forged := metadata.AppendToOutgoingContext(ctx, "x-client-id", "someone-else")
+	if _, err := client.Infer(forged, req); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected x-client-id to be ignored by the rate limit, got %v", err)
+	}
+}
+
+func TestClientKeyUsesTheAuthenticatedCaller(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 51234}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-client-id", "forged", "authorization", "Bearer secret"))
+
+	if key := clientKey(ctx); key != "peer:10.0.0.7" {
+		t.Errorf("Expected unauthenticated callers keyed on their address, got %q", key)
+	}
+
+	authenticated, err := authenticate(ctx, StaticTokenAuthenticator("secret"))
+	if err != nil {
+		t.Fatalf("authenticate failed: %v", err)
+	}
+	key := clientKey(authenticated)
+	if !strings.HasPrefix(key, "token:") || strings.Contains(key, "secret") {
+		t.Errorf("Expected callers keyed on a hash of their token, got %q", key)
+	}
+
+	other, _ := authenticate(metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer other")), StaticTokenAuthenticator("other"))
+	if clientKey(other) == key {
+		t.Error("Expected different tokens to get different buckets")
+	}
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	store, _ := apikeys.NewStore("")
+	_, inferKey, _ := store.Create(apikeys.KeyRequest{Name: "app", Scopes: []apikeys.Scope{apikeys.ScopeSubmitWorkloads}})
+	_, readKey, _ := store.Create(apikeys.KeyRequest{Name: "grafana", Scopes: []apikeys.Scope{apikeys.ScopeReadMetrics}})
+
+	config := DefaultConfig()
+	config.EnableTracing = false
+	config.Authenticator = APIKeyAuthenticator(store, apikeys.ScopeSubmitWorkloads)
+	client := startTestServer(t, config)
+	req := &inferencepb.InferRequest{RequestId: "r", ModelId: "m", Input: []byte("x")}
+
+	call := func(secret string) error {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+secret)
+		_, err := client.Infer(ctx, req)
+		return err
+	}
+	if err := call(inferKey); err != nil {
+		t.Fatalf("Expected the submit-workloads key to succeed: %v", err)
+	}
+	if err := call(readKey); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected PermissionDenied for a read-metrics key, got %v", err)
+	}
+	if err := call("afk_bogus_key"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated for an unknown key, got %v", err)
+	}
+}
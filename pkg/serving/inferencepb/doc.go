@@ -0,0 +1,6 @@
+// Package inferencepb contains the messages and gRPC stubs of the
+// InferenceService, generated from api/proto/agentaflow/serving/v1/inference.proto
+// with protoc-gen-go and protoc-gen-go-grpc. Run make proto after changing the proto.
+package inferencepb
+
+//go:generate protoc -I ../../../api/proto --go_out=../../.. --go_opt=module=github.com/Finoptimize/agentaflow-sro-community --go-grpc_out=../../.. --go-grpc_opt=module=github.com/Finoptimize/agentaflow-sro-community agentaflow/serving/v1/inference.proto
@@ -0,0 +1,496 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: agentaflow/serving/v1/inference.proto
+
+package inferencepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// InferRequest is a single inference request
+type InferRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestId string `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	ModelId   string `protobuf:"bytes,2,opt,name=model_id,json=modelId,proto3" json:"model_id,omitempty"`
+	Input     []byte `protobuf:"bytes,3,opt,name=input,proto3" json:"input,omitempty"`
+	Priority  int32  `protobuf:"varint,4,opt,name=priority,proto3" json:"priority,omitempty"`
+	// Optional trace the request belongs to; defaults to the incoming trace metadata
+	TraceId string `protobuf:"bytes,5,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+}
+
+func (x *InferRequest) Reset() {
+	*x = InferRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_agentaflow_serving_v1_inference_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InferRequest) ProtoMessage() {}
+
+func (x *InferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentaflow_serving_v1_inference_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InferRequest.ProtoReflect.Descriptor instead.
+func (*InferRequest) Descriptor() ([]byte, []int) {
+	return file_agentaflow_serving_v1_inference_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *InferRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *InferRequest) GetModelId() string {
+	if x != nil {
+		return x.ModelId
+	}
+	return ""
+}
+
+func (x *InferRequest) GetInput() []byte {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+func (x *InferRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *InferRequest) GetTraceId() string {
+	if x != nil {
+		return x.TraceId
+	}
+	return ""
+}
+
+// BatchInferRequest groups several inference requests
+type BatchInferRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Requests []*InferRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+}
+
+func (x *BatchInferRequest) Reset() {
+	*x = BatchInferRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_agentaflow_serving_v1_inference_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchInferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchInferRequest) ProtoMessage() {}
+
+func (x *BatchInferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentaflow_serving_v1_inference_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchInferRequest.ProtoReflect.Descriptor instead.
+func (*BatchInferRequest) Descriptor() ([]byte, []int) {
+	return file_agentaflow_serving_v1_inference_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BatchInferRequest) GetRequests() []*InferRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+// LatencyBreakdown is the per-stage latency of a request in microseconds
+type LatencyBreakdown struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CacheCheckUs       int64 `protobuf:"varint,1,opt,name=cache_check_us,json=cacheCheckUs,proto3" json:"cache_check_us,omitempty"`
+	QueueWaitUs        int64 `protobuf:"varint,2,opt,name=queue_wait_us,json=queueWaitUs,proto3" json:"queue_wait_us,omitempty"`
+	BatchWaitUs        int64 `protobuf:"varint,3,opt,name=batch_wait_us,json=batchWaitUs,proto3" json:"batch_wait_us,omitempty"`
+	BackendExecutionUs int64 `protobuf:"varint,4,opt,name=backend_execution_us,json=backendExecutionUs,proto3" json:"backend_execution_us,omitempty"`
+}
+
+func (x *LatencyBreakdown) Reset() {
+	*x = LatencyBreakdown{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_agentaflow_serving_v1_inference_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LatencyBreakdown) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LatencyBreakdown) ProtoMessage() {}
+
+func (x *LatencyBreakdown) ProtoReflect() protoreflect.Message {
+	mi := &file_agentaflow_serving_v1_inference_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LatencyBreakdown.ProtoReflect.Descriptor instead.
+func (*LatencyBreakdown) Descriptor() ([]byte, []int) {
+	return file_agentaflow_serving_v1_inference_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LatencyBreakdown) GetCacheCheckUs() int64 {
+	if x != nil {
+		return x.CacheCheckUs
+	}
+	return 0
+}
+
+func (x *LatencyBreakdown) GetQueueWaitUs() int64 {
+	if x != nil {
+		return x.QueueWaitUs
+	}
+	return 0
+}
+
+func (x *LatencyBreakdown) GetBatchWaitUs() int64 {
+	if x != nil {
+		return x.BatchWaitUs
+	}
+	return 0
+}
+
+func (x *LatencyBreakdown) GetBackendExecutionUs() int64 {
+	if x != nil {
+		return x.BackendExecutionUs
+	}
+	return 0
+}
+
+// InferResponse is the result of an inference request
+type InferResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestId string            `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Output    []byte            `protobuf:"bytes,2,opt,name=output,proto3" json:"output,omitempty"`
+	LatencyUs int64             `protobuf:"varint,3,opt,name=latency_us,json=latencyUs,proto3" json:"latency_us,omitempty"`
+	CacheHit  bool              `protobuf:"varint,4,opt,name=cache_hit,json=cacheHit,proto3" json:"cache_hit,omitempty"`
+	BatchSize int32             `protobuf:"varint,5,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`
+	Breakdown *LatencyBreakdown `protobuf:"bytes,6,opt,name=breakdown,proto3" json:"breakdown,omitempty"`
+	// Set on streamed batch responses when the individual request failed
+	Error string `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *InferResponse) Reset() {
+	*x = InferResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_agentaflow_serving_v1_inference_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InferResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InferResponse) ProtoMessage() {}
+
+func (x *InferResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentaflow_serving_v1_inference_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InferResponse.ProtoReflect.Descriptor instead.
+func (*InferResponse) Descriptor() ([]byte, []int) {
+	return file_agentaflow_serving_v1_inference_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *InferResponse) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *InferResponse) GetOutput() []byte {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *InferResponse) GetLatencyUs() int64 {
+	if x != nil {
+		return x.LatencyUs
+	}
+	return 0
+}
+
+func (x *InferResponse) GetCacheHit() bool {
+	if x != nil {
+		return x.CacheHit
+	}
+	return false
+}
+
+func (x *InferResponse) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *InferResponse) GetBreakdown() *LatencyBreakdown {
+	if x != nil {
+		return x.Breakdown
+	}
+	return nil
+}
+
+func (x *InferResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_agentaflow_serving_v1_inference_proto protoreflect.FileDescriptor
+
+var file_agentaflow_serving_v1_inference_proto_rawDesc = []byte{
+	0x0a, 0x25, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x61, 0x66, 0x6c, 0x6f, 0x77, 0x2f, 0x73, 0x65, 0x72,
+	0x76, 0x69, 0x6e, 0x67, 0x2f, 0x76, 0x31, 0x2f, 0x69, 0x6e, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x15, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x61, 0x66,
+	0x6c, 0x6f, 0x77, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x22, 0x95,
+	0x01, 0x0a, 0x0c, 0x49, 0x6e, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x19,
+	0x0a, 0x08, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x70,
+	0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x12,
+	0x1a, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x19, 0x0a, 0x08, 0x74,
+	0x72, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74,
+	0x72, 0x61, 0x63, 0x65, 0x49, 0x64, 0x22, 0x54, 0x0a, 0x11, 0x42, 0x61, 0x74, 0x63, 0x68, 0x49,
+	0x6e, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x3f, 0x0a, 0x08, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e,
+	0x61, 0x67, 0x65, 0x6e, 0x74, 0x61, 0x66, 0x6c, 0x6f, 0x77, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x22, 0xb2, 0x01, 0x0a,
+	0x10, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x64, 0x6f, 0x77,
+	0x6e, 0x12, 0x24, 0x0a, 0x0e, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b,
+	0x5f, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x63, 0x61, 0x63, 0x68, 0x65,
+	0x43, 0x68, 0x65, 0x63, 0x6b, 0x55, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x71, 0x75, 0x65, 0x75, 0x65,
+	0x5f, 0x77, 0x61, 0x69, 0x74, 0x5f, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b,
+	0x71, 0x75, 0x65, 0x75, 0x65, 0x57, 0x61, 0x69, 0x74, 0x55, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x62,
+	0x61, 0x74, 0x63, 0x68, 0x5f, 0x77, 0x61, 0x69, 0x74, 0x5f, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0b, 0x62, 0x61, 0x74, 0x63, 0x68, 0x57, 0x61, 0x69, 0x74, 0x55, 0x73, 0x12,
+	0x30, 0x0a, 0x14, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x5f, 0x65, 0x78, 0x65, 0x63, 0x75,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x12, 0x62,
+	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x55,
+	0x73, 0x22, 0xfe, 0x01, 0x0a, 0x0d, 0x49, 0x6e, 0x66, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x61,
+	0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09,
+	0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x55, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x61, 0x63,
+	0x68, 0x65, 0x5f, 0x68, 0x69, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x63, 0x61,
+	0x63, 0x68, 0x65, 0x48, 0x69, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f,
+	0x73, 0x69, 0x7a, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x62, 0x61, 0x74, 0x63,
+	0x68, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x45, 0x0a, 0x09, 0x62, 0x72, 0x65, 0x61, 0x6b, 0x64, 0x6f,
+	0x77, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74,
+	0x61, 0x66, 0x6c, 0x6f, 0x77, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x64, 0x6f, 0x77,
+	0x6e, 0x52, 0x09, 0x62, 0x72, 0x65, 0x61, 0x6b, 0x64, 0x6f, 0x77, 0x6e, 0x12, 0x14, 0x0a, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x32, 0xc6, 0x01, 0x0a, 0x10, 0x49, 0x6e, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x52, 0x0a, 0x05, 0x49, 0x6e, 0x66, 0x65, 0x72,
+	0x12, 0x23, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x61, 0x66, 0x6c, 0x6f, 0x77, 0x2e, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x66, 0x65, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x61, 0x66, 0x6c,
+	0x6f, 0x77, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e,
+	0x66, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5e, 0x0a, 0x0a, 0x49,
+	0x6e, 0x66, 0x65, 0x72, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x28, 0x2e, 0x61, 0x67, 0x65, 0x6e,
+	0x74, 0x61, 0x66, 0x6c, 0x6f, 0x77, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x6e, 0x67, 0x2e, 0x76,
+	0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x49, 0x6e, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x61, 0x66, 0x6c, 0x6f, 0x77,
+	0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x66, 0x65,
+	0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42, 0x49, 0x5a, 0x47, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x46, 0x69, 0x6e, 0x6f, 0x70, 0x74,
+	0x69, 0x6d, 0x69, 0x7a, 0x65, 0x2f, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x61, 0x66, 0x6c, 0x6f, 0x77,
+	0x2d, 0x73, 0x72, 0x6f, 0x2d, 0x63, 0x6f, 0x6d, 0x6d, 0x75, 0x6e, 0x69, 0x74, 0x79, 0x2f, 0x70,
+	0x6b, 0x67, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x6e, 0x67, 0x2f, 0x69, 0x6e, 0x66, 0x65, 0x72,
+	0x65, 0x6e, 0x63, 0x65, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_agentaflow_serving_v1_inference_proto_rawDescOnce sync.Once
+	file_agentaflow_serving_v1_inference_proto_rawDescData = file_agentaflow_serving_v1_inference_proto_rawDesc
+)
+
+func file_agentaflow_serving_v1_inference_proto_rawDescGZIP() []byte {
+	file_agentaflow_serving_v1_inference_proto_rawDescOnce.Do(func() {
+		file_agentaflow_serving_v1_inference_proto_rawDescData = protoimpl.X.CompressGZIP(file_agentaflow_serving_v1_inference_proto_rawDescData)
+	})
+	return file_agentaflow_serving_v1_inference_proto_rawDescData
+}
+
+var file_agentaflow_serving_v1_inference_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_agentaflow_serving_v1_inference_proto_goTypes = []interface{}{
+	(*InferRequest)(nil),      // 0: agentaflow.serving.v1.InferRequest
+	(*BatchInferRequest)(nil), // 1: agentaflow.serving.v1.BatchInferRequest
+	(*LatencyBreakdown)(nil),  // 2: agentaflow.serving.v1.LatencyBreakdown
+	(*InferResponse)(nil),     // 3: agentaflow.serving.v1.InferResponse
+}
+var file_agentaflow_serving_v1_inference_proto_depIdxs = []int32{
+	0, // 0: agentaflow.serving.v1.BatchInferRequest.requests:type_name -> agentaflow.serving.v1.InferRequest
+	2, // 1: agentaflow.serving.v1.InferResponse.breakdown:type_name -> agentaflow.serving.v1.LatencyBreakdown
+	0, // 2: agentaflow.serving.v1.InferenceService.Infer:input_type -> agentaflow.serving.v1.InferRequest
+	1, // 3: agentaflow.serving.v1.InferenceService.InferBatch:input_type -> agentaflow.serving.v1.BatchInferRequest
+	3, // 4: agentaflow.serving.v1.InferenceService.Infer:output_type -> agentaflow.serving.v1.InferResponse
+	3, // 5: agentaflow.serving.v1.InferenceService.InferBatch:output_type -> agentaflow.serving.v1.InferResponse
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_agentaflow_serving_v1_inference_proto_init() }
+func file_agentaflow_serving_v1_inference_proto_init() {
+	if File_agentaflow_serving_v1_inference_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_agentaflow_serving_v1_inference_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InferRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_agentaflow_serving_v1_inference_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchInferRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_agentaflow_serving_v1_inference_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LatencyBreakdown); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_agentaflow_serving_v1_inference_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InferResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_agentaflow_serving_v1_inference_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_agentaflow_serving_v1_inference_proto_goTypes,
+		DependencyIndexes: file_agentaflow_serving_v1_inference_proto_depIdxs,
+		MessageInfos:      file_agentaflow_serving_v1_inference_proto_msgTypes,
+	}.Build()
+	File_agentaflow_serving_v1_inference_proto = out.File
+	file_agentaflow_serving_v1_inference_proto_rawDesc = nil
+	file_agentaflow_serving_v1_inference_proto_goTypes = nil
+	file_agentaflow_serving_v1_inference_proto_depIdxs = nil
+}
@@ -0,0 +1,57 @@
+package inferencepb
+
+import (
+	"bytes"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+func TestInferRequestRoundTrip(t *testing.T) {
+	batch := &BatchInferRequest{Requests: []*InferRequest{
+		{RequestId: "r-1", ModelId: "m", Input: []byte("hello"), Priority: -2, TraceId: "t"},
+		{RequestId: "r-2", ModelId: "m", Input: []byte{0, 1, 2}},
+	}}
+
+	data, err := proto.Marshal(batch)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	decoded := &BatchInferRequest{}
+	if err := proto.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !proto.Equal(batch, decoded) {
+		t.Errorf("Expected %v, got %v", batch, decoded)
+	}
+	if !bytes.Equal(decoded.Requests[1].GetInput(), []byte{0, 1, 2}) {
+		t.Errorf("Binary input not preserved: %v", decoded.Requests[1].GetInput())
+	}
+}
+
+func TestInferResponseWireFormat(t *testing.T) {
+	data, _ := proto.Marshal(&InferResponse{RequestId: "r", LatencyUs: 300, CacheHit: true})
+	// Field 1 (request_id, length-delimited) must be encoded as tag 0x0a
+	if data[0] != 0x0a || data[1] != 1 || data[2] != 'r' {
+		t.Errorf("Unexpected encoding prefix: %x", data[:3])
+	}
+
+	// Unknown fields from newer clients are kept
+	data = append(data, 0x78, 0x01) // field 15, varint 1
+	decoded := &InferResponse{}
+	if err := proto.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.LatencyUs != 300 || !decoded.CacheHit || len(decoded.ProtoReflect().GetUnknown()) != 2 {
+		t.Errorf("Unexpected decoded response: %v", decoded)
+	}
+	if err := proto.Unmarshal([]byte{0x0a, 0x05, 'a'}, decoded); err == nil {
+		t.Error("Expected error for truncated message")
+	}
+
+	// The service is registered for reflection-based tools such as grpcurl
+	if _, err := protoregistry.GlobalFiles.FindDescriptorByName("agentaflow.serving.v1.InferenceService"); err != nil {
+		t.Errorf("Expected the service descriptor to be registered: %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package serving
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// DefaultPrefixBlockSize is the number of prompt bytes per prefix-cache block
+const DefaultPrefixBlockSize = 256
+
+// KVCacheState is the KV cache state reported by a backend instance
+type KVCacheState struct {
+	Occupancy    float64   // Fraction of KV cache in use (0.0-1.0)
+	BlockSize    int       // Prompt bytes per prefix block used for PrefixBlocks
+	PrefixBlocks []string  // Hashes of cached prefix blocks, from PrefixBlockHashes
+	UpdatedAt    time.Time // Set by the router when the report is received
+}
+
+// kvRoutingStats tracks prefix-cache routing effectiveness
+type kvRoutingStats struct {
+	prefixHits         int64
+	prefixMisses       int64
+	matchedPrefixBytes int64
+}
+
+// PrefixBlockHashes returns chained hashes of each full block of the prompt, so a block
+// hash identifies the entire prefix up to and including that block
+func PrefixBlockHashes(prompt []byte, blockSize int) []string {
+	if blockSize <= 0 {
+		blockSize = DefaultPrefixBlockSize
+	}
+
+	hashes := make([]string, 0, len(prompt)/blockSize)
+	var parent []byte
+	for end := blockSize; end <= len(prompt); end += blockSize {
+		h := fnv.New64a()
+		h.Write(parent)
+		h.Write(prompt[end-blockSize : end])
+		parent = h.Sum(nil)
+		hashes = append(hashes, hex.EncodeToString(parent))
+	}
+
+	return hashes
+}
+
+// ReportKVCacheState records the KV cache state reported by an instance
+func (r *Router) ReportKVCacheState(instanceID string, state KVCacheState) error {
+	if state.Occupancy < 0 || state.Occupancy > 1 {
+		return fmt.Errorf("occupancy must be between 0 and 1")
+	}
+	if state.BlockSize <= 0 {
+		state.BlockSize = DefaultPrefixBlockSize
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, instances := range r.instances {
+		for _, instance := range instances {
+			if instance.ID == instanceID {
+				state.UpdatedAt = time.Now()
+				state.PrefixBlocks = append([]string{}, state.PrefixBlocks...)
+				r.kvStates[instanceID] = &state
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("instance %s not found", instanceID)
+}
+
+// GetKVCacheState returns the last reported KV cache state of an instance
+func (r *Router) GetKVCacheState(instanceID string) (KVCacheState, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state, exists := r.kvStates[instanceID]
+	if !exists {
+		return KVCacheState{}, false
+	}
+	return *state, true
+}
+
+// RouteRequestWithPrompt routes a request to the instance holding the longest cached
+// prefix of the prompt when the router uses RouteKVCacheAware
+func (r *Router) RouteRequestWithPrompt(modelID string, prompt []byte) (*ModelInstance, error) {
+	if r.strategy != RouteKVCacheAware {
+		return r.RouteRequest(modelID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	instances, exists := r.instances[modelID]
+	if !exists || len(instances) == 0 {
+		return nil, fmt.Errorf("no instances available for model %s", modelID)
+	}
+
+	hashesBySize := make(map[int][]string)
+
+	var best *ModelInstance
+	bestScore := -1.0
+	bestMatched := 0 // Prompt bytes already held in the chosen instance's prefix cache
+
+	for _, instance := range instances {
+		if !instanceEligible(instance) {
+			continue
+		}
+
+		matched := 0
+		occupancy := 0.0
+		if state, exists := r.kvStates[instance.ID]; exists && time.Since(state.UpdatedAt) <= r.kvStateTTL {
+			hashes, computed := hashesBySize[state.BlockSize]
+			if !computed {
+				hashes = PrefixBlockHashes(prompt, state.BlockSize)
+				hashesBySize[state.BlockSize] = hashes
+			}
+			matched = matchedPrefixBlocks(hashes, state.PrefixBlocks) * state.BlockSize
+			occupancy = state.Occupancy
+		}
+
+		// Prefer reused prefill, then spare KV cache and spare request slots
+		loadFraction := float64(instance.CurrentLoad) / float64(instance.MaxLoad)
+		score := float64(matched) + (1-occupancy)*0.5 + (1-loadFraction)*0.25
+		if occupancy >= r.kvHighWatermark {
+			// Nearly full caches would evict the prefix anyway
+			score = (1 - loadFraction) * 0.25
+		}
+
+		if score > bestScore {
+			best = instance
+			bestScore = score
+			bestMatched = matched
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no available instances")
+	}
+
+	if bestMatched > 0 {
+		r.kvStats.prefixHits++
+		r.kvStats.matchedPrefixBytes += int64(bestMatched)
+	} else {
+		r.kvStats.prefixMisses++
+	}
+
+	return best, nil
+}
+
+// RouteInferenceRequest routes a request using the inputs relevant to the router's strategy
+func (r *Router) RouteInferenceRequest(req *InferenceRequest) (*ModelInstance, error) {
+	if req == nil {
+		return nil, fmt.Errorf("inference request cannot be nil")
+	}
+
+	switch r.strategy {
+	case RouteSessionAffinity:
+		return r.RouteRequestWithSession(req.ModelID, req.SessionID)
+	case RouteKVCacheAware:
+		return r.RouteRequestWithPrompt(req.ModelID, req.Input)
+	default:
+		return r.RouteRequest(req.ModelID)
+	}
+}
+
+// matchedPrefixBlocks counts how many leading prompt blocks are present in the cached set
+func matchedPrefixBlocks(promptHashes, cached []string) int {
+	if len(promptHashes) == 0 || len(cached) == 0 {
+		return 0
+	}
+
+	set := make(map[string]struct{}, len(cached))
+	for _, hash := range cached {
+		set[hash] = struct{}{}
+	}
+
+	matched := 0
+	for _, hash := range promptHashes {
+		if _, exists := set[hash]; !exists {
+			break
+		}
+		matched++
+	}
+	return matched
+}
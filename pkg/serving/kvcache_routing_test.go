@@ -0,0 +1,75 @@
+package serving
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrefixBlockHashesAreChained(t *testing.T) {
+	a := PrefixBlockHashes([]byte("aaaabbbbcc"), 4)
+	if len(a) != 2 {
+		t.Fatalf("Expected 2 full blocks, got %d", len(a))
+	}
+
+	// Same second block under a different first block must hash differently
+	b := PrefixBlockHashes([]byte("xxxxbbbb"), 4)
+	if a[1] == b[1] {
+		t.Error("Expected block hashes to depend on the whole prefix")
+	}
+}
+
+func TestKVCacheAwareRoutingPrefersCachedPrefix(t *testing.T) {
+	router := NewRouter(RouteKVCacheAware)
+	router.RegisterInstance(&ModelInstance{ID: "warm", ModelID: "llm", MaxLoad: 10, CurrentLoad: 5, Available: true})
+	router.RegisterInstance(&ModelInstance{ID: "cold", ModelID: "llm", MaxLoad: 10, Available: true})
+
+	systemPrompt := strings.Repeat("You are a helpful assistant. ", 40)
+	prompt := []byte(systemPrompt + "What is the weather?")
+
+	err := router.ReportKVCacheState("warm", KVCacheState{
+		Occupancy:    0.6,
+		BlockSize:    64,
+		PrefixBlocks: PrefixBlockHashes([]byte(systemPrompt), 64),
+	})
+	if err != nil {
+		t.Fatalf("Failed to report state: %v", err)
+	}
+	router.ReportKVCacheState("cold", KVCacheState{Occupancy: 0.1, BlockSize: 64})
+
+	instance, err := router.RouteInferenceRequest(&InferenceRequest{ModelID: "llm", Input: prompt})
+	if err != nil {
+		t.Fatalf("Routing failed: %v", err)
+	}
+	if instance.ID != "warm" {
+		t.Errorf("Expected instance holding the prefix, got %s", instance.ID)
+	}
+
+	// Unrelated prompts go to the instance with the most spare capacity
+	instance, _ = router.RouteRequestWithPrompt("llm", []byte(strings.Repeat("z", 200)))
+	if instance.ID != "cold" {
+		t.Errorf("Expected least loaded instance for a cache miss, got %s", instance.ID)
+	}
+
+	// A nearly full cache loses its prefix advantage
+	router.ReportKVCacheState("warm", KVCacheState{
+		Occupancy:    0.99,
+		BlockSize:    64,
+		PrefixBlocks: PrefixBlockHashes([]byte(systemPrompt), 64),
+	})
+	instance, _ = router.RouteRequestWithPrompt("llm", prompt)
+	if instance.ID != "cold" {
+		t.Errorf("Expected to avoid nearly full KV cache, got %s", instance.ID)
+	}
+
+	metrics := router.GetRoutingMetrics()
+	if metrics["prefix_cache_hits"].(int64) != 1 || metrics["prefix_cache_misses"].(int64) != 2 {
+		t.Errorf("Unexpected prefix cache metrics: %v", metrics)
+	}
+
+	if err := router.ReportKVCacheState("missing", KVCacheState{}); err == nil {
+		t.Error("Expected error for unknown instance")
+	}
+	if err := router.ReportKVCacheState("warm", KVCacheState{Occupancy: 2}); err == nil {
+		t.Error("Expected error for invalid occupancy")
+	}
+}
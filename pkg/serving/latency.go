@@ -0,0 +1,190 @@
+package serving
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyStage identifies a stage of the inference path
+type LatencyStage string
+
+const (
+	StageCacheCheck LatencyStage = "cache_check"
+	StageQueueWait  LatencyStage = "queue_wait"
+	StageBatchWait  LatencyStage = "batch_wait"
+	StageExecution  LatencyStage = "backend_execution"
+	StageEndToEnd   LatencyStage = "end_to_end"
+)
+
+// AllLatencyStages lists every instrumented stage in path order
+var AllLatencyStages = []LatencyStage{StageCacheCheck, StageQueueWait, StageBatchWait, StageExecution, StageEndToEnd}
+
+// DefaultLatencyBuckets are histogram upper bounds in seconds
+var DefaultLatencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// LatencyBreakdown splits the latency of a single request by stage
+type LatencyBreakdown struct {
+	CacheCheck time.Duration `json:"cache_check"`
+	QueueWait  time.Duration `json:"queue_wait"`
+	BatchWait  time.Duration `json:"batch_wait"`
+	Execution  time.Duration `json:"backend_execution"`
+}
+
+// Total returns the end-to-end latency
+func (lb LatencyBreakdown) Total() time.Duration {
+	return lb.CacheCheck + lb.QueueWait + lb.BatchWait + lb.Execution
+}
+
+// Stage returns the duration of a single stage
+func (lb LatencyBreakdown) Stage(stage LatencyStage) time.Duration {
+	switch stage {
+	case StageCacheCheck:
+		return lb.CacheCheck
+	case StageQueueWait:
+		return lb.QueueWait
+	case StageBatchWait:
+		return lb.BatchWait
+	case StageExecution:
+		return lb.Execution
+	case StageEndToEnd:
+		return lb.Total()
+	}
+	return 0
+}
+
+// StageHistogram is a cumulative latency histogram for one model and stage
+type StageHistogram struct {
+	Buckets []float64 `json:"buckets"` // Upper bounds in seconds
+	Counts  []uint64  `json:"counts"`  // Cumulative count per bucket
+	Count   uint64    `json:"count"`
+	Sum     float64   `json:"sum"` // Seconds
+}
+
+// Quantile estimates a quantile (0.0-1.0) from the bucket counts, in seconds
+func (h StageHistogram) Quantile(q float64) float64 {
+	if h.Count == 0 {
+		return 0
+	}
+
+	rank := q * float64(h.Count)
+	index := sort.Search(len(h.Counts), func(i int) bool {
+		return float64(h.Counts[i]) >= rank
+	})
+	if index >= len(h.Buckets) {
+		return h.Buckets[len(h.Buckets)-1]
+	}
+
+	lower, prevCount := 0.0, uint64(0)
+	if index > 0 {
+		lower = h.Buckets[index-1]
+		prevCount = h.Counts[index-1]
+	}
+	inBucket := h.Counts[index] - prevCount
+	if inBucket == 0 {
+		return h.Buckets[index]
+	}
+	return lower + (h.Buckets[index]-lower)*(rank-float64(prevCount))/float64(inBucket)
+}
+
+// LatencyTracker records per-model, per-stage latency histograms
+type LatencyTracker struct {
+	buckets    []float64
+	histograms map[string]map[LatencyStage]*StageHistogram
+	mu         sync.RWMutex
+}
+
+// NewLatencyTracker creates a new latency tracker; nil buckets use DefaultLatencyBuckets
+func NewLatencyTracker(buckets []float64) *LatencyTracker {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	sorted := append([]float64{}, buckets...)
+	sort.Float64s(sorted)
+
+	return &LatencyTracker{
+		buckets:    sorted,
+		histograms: make(map[string]map[LatencyStage]*StageHistogram),
+	}
+}
+
+// Observe records the stage breakdown of a completed request
+func (lt *LatencyTracker) Observe(modelID string, breakdown LatencyBreakdown) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	stages, exists := lt.histograms[modelID]
+	if !exists {
+		stages = make(map[LatencyStage]*StageHistogram)
+		lt.histograms[modelID] = stages
+	}
+
+	for _, stage := range AllLatencyStages {
+		hist, exists := stages[stage]
+		if !exists {
+			hist = &StageHistogram{
+				Buckets: lt.buckets,
+				Counts:  make([]uint64, len(lt.buckets)),
+			}
+			stages[stage] = hist
+		}
+
+		seconds := breakdown.Stage(stage).Seconds()
+		for i, bound := range lt.buckets {
+			if seconds <= bound {
+				hist.Counts[i]++
+			}
+		}
+		hist.Count++
+		hist.Sum += seconds
+	}
+}
+
+// GetHistograms returns a copy of the stage histograms for a model
+func (lt *LatencyTracker) GetHistograms(modelID string) map[LatencyStage]StageHistogram {
+	lt.mu.RLock()
+	defer lt.mu.RUnlock()
+
+	result := make(map[LatencyStage]StageHistogram)
+	for stage, hist := range lt.histograms[modelID] {
+		result[stage] = StageHistogram{
+			Buckets: hist.Buckets,
+			Counts:  append([]uint64{}, hist.Counts...),
+			Count:   hist.Count,
+			Sum:     hist.Sum,
+		}
+	}
+	return result
+}
+
+// GetModels returns the models with recorded latencies
+func (lt *LatencyTracker) GetModels() []string {
+	lt.mu.RLock()
+	defer lt.mu.RUnlock()
+
+	models := make([]string, 0, len(lt.histograms))
+	for modelID := range lt.histograms {
+		models = append(models, modelID)
+	}
+	sort.Strings(models)
+	return models
+}
+
+// GetBreakdownSummary returns mean and p95 per stage for a model, in milliseconds
+func (lt *LatencyTracker) GetBreakdownSummary(modelID string) map[string]interface{} {
+	histograms := lt.GetHistograms(modelID)
+
+	summary := make(map[string]interface{})
+	for _, stage := range AllLatencyStages {
+		hist, exists := histograms[stage]
+		if !exists || hist.Count == 0 {
+			continue
+		}
+		summary[string(stage)] = map[string]interface{}{
+			"count":   hist.Count,
+			"mean_ms": hist.Sum / float64(hist.Count) * 1000,
+			"p95_ms":  hist.Quantile(0.95) * 1000,
+		}
+	}
+	return summary
+}
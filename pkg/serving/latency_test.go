@@ -0,0 +1,72 @@
+package serving
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyBreakdownPerStage(t *testing.T) {
+	manager := NewServingManager(&BatchConfig{MaxBatchSize: 1, MinBatchSize: 1}, time.Minute)
+	manager.RegisterModel(&Model{ID: "m", Name: "Model"})
+
+	resp, err := manager.SubmitInferenceRequest(&InferenceRequest{ID: "r-1", ModelID: "m", Input: []byte("a")})
+	if err != nil {
+		t.Fatalf("Failed to submit request: %v", err)
+	}
+	if resp.Breakdown.Execution != 50*time.Millisecond {
+		t.Errorf("Expected backend execution of 50ms, got %v", resp.Breakdown.Execution)
+	}
+
+	hit, _ := manager.SubmitInferenceRequest(&InferenceRequest{ID: "r-2", ModelID: "m", Input: []byte("a")})
+	if !hit.CacheHit || hit.Breakdown.Execution != 0 {
+		t.Errorf("Expected cache hit without backend execution, got %+v", hit.Breakdown)
+	}
+
+	histograms := manager.GetLatencyTracker().GetHistograms("m")
+	for _, stage := range AllLatencyStages {
+		if histograms[stage].Count != 2 {
+			t.Errorf("Expected 2 observations for stage %s, got %d", stage, histograms[stage].Count)
+		}
+	}
+
+	if _, exists := manager.GetLatencyBreakdown()["m"]; !exists {
+		t.Error("Expected latency breakdown summary for model m")
+	}
+}
+
+func TestBatchWaitBreakdown(t *testing.T) {
+	created := time.Now()
+	previous := created.Add(20 * time.Millisecond)
+	dispatched := created.Add(50 * time.Millisecond)
+
+	breakdown := batchWaitBreakdown(created, previous, dispatched)
+	if breakdown.QueueWait != 20*time.Millisecond {
+		t.Errorf("Expected 20ms queue wait, got %v", breakdown.QueueWait)
+	}
+	if breakdown.BatchWait != 30*time.Millisecond {
+		t.Errorf("Expected 30ms batch wait, got %v", breakdown.BatchWait)
+	}
+
+	fresh := batchWaitBreakdown(previous, created, dispatched)
+	if fresh.QueueWait != 0 || fresh.BatchWait != 30*time.Millisecond {
+		t.Errorf("Request arriving after the previous dispatch should only see batch wait, got %+v", fresh)
+	}
+}
+
+func TestStageHistogramQuantile(t *testing.T) {
+	tracker := NewLatencyTracker([]float64{0.01, 0.1, 1})
+	for i := 0; i < 90; i++ {
+		tracker.Observe("m", LatencyBreakdown{Execution: 5 * time.Millisecond})
+	}
+	for i := 0; i < 10; i++ {
+		tracker.Observe("m", LatencyBreakdown{Execution: 500 * time.Millisecond})
+	}
+
+	hist := tracker.GetHistograms("m")[StageExecution]
+	if p50 := hist.Quantile(0.5); p50 > 0.01 {
+		t.Errorf("Expected p50 within first bucket, got %f", p50)
+	}
+	if p95 := hist.Quantile(0.95); p95 <= 0.1 || p95 > 1 {
+		t.Errorf("Expected p95 in the slowest bucket, got %f", p95)
+	}
+}
@@ -0,0 +1,428 @@
+package serving
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ModelLoadFunc loads or unloads a model on a GPU
+type ModelLoadFunc func(model *Model, gpuID string) error
+
+// loadedModel is a model resident in, or moving in or out of, GPU memory
+type loadedModel struct {
+	model    *Model
+	gpuID    string
+	loadedAt time.Time
+	lastUsed time.Time
+	inUse    int           // Requests running on the model; models in use are never evicted
+	loading  bool          // Memory is reserved but the loader has not finished
+	busy     chan struct{} // Non-nil while the model loads or unloads, closed when done
+	loadErr  error         // Why the load failed, set before busy is closed
+}
+
+// gpuMemory tracks the models resident on a single GPU
+type gpuMemory struct {
+	capacityMB uint64
+	usedMB     uint64
+	models     map[string]*loadedModel
+}
+
+// ModelLifecycleManager loads and evicts models across GPUs with limited memory,
+// evicting the least recently used, lowest priority models first
+type ModelLifecycleManager struct {
+	gpus       map[string]*gpuMemory
+	models     map[string]*Model
+	priorities map[string]int // Higher values are more important
+	loader     ModelLoadFunc
+	unloader   ModelLoadFunc
+
+	loads             int64
+	loadFailures      int64
+	evictions         int64
+	evictionsByModel  map[string]int64
+	totalLoadTime     time.Duration
+	totalUnloadTime   time.Duration
+	unloads           int64
+	lastLoadDurations map[string]time.Duration
+	mu                sync.Mutex
+}
+
+// NewModelLifecycleManager creates a new model lifecycle manager; nil functions are no-ops
+func NewModelLifecycleManager(loader, unloader ModelLoadFunc) *ModelLifecycleManager {
+	return &ModelLifecycleManager{
+		gpus:              make(map[string]*gpuMemory),
+		models:            make(map[string]*Model),
+		priorities:        make(map[string]int),
+		loader:            loader,
+		unloader:          unloader,
+		evictionsByModel:  make(map[string]int64),
+		lastLoadDurations: make(map[string]time.Duration),
+	}
+}
+
+// AddGPU makes a GPU's memory available for model loading
+func (lm *ModelLifecycleManager) AddGPU(gpuID string, memoryMB uint64) error {
+	if gpuID == "" {
+		return fmt.Errorf("GPU ID cannot be empty")
+	}
+	if memoryMB == 0 {
+		return fmt.Errorf("GPU memory must be positive")
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	if _, exists := lm.gpus[gpuID]; exists {
+		return fmt.Errorf("GPU %s already added", gpuID)
+	}
+	lm.gpus[gpuID] = &gpuMemory{capacityMB: memoryMB, models: make(map[string]*loadedModel)}
+	return nil
+}
+
+// RegisterModel makes a model eligible for loading with the given priority
+func (lm *ModelLifecycleManager) RegisterModel(model *Model, priority int) error {
+	if model == nil {
+		return fmt.Errorf("model cannot be nil")
+	}
+	if model.ID == "" {
+		return fmt.Errorf("model ID cannot be empty")
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lm.models[model.ID] = model
+	lm.priorities[model.ID] = priority
+	return nil
+}
+
+// EnsureLoaded returns the GPU holding the model, loading it (and evicting others) if needed
+func (lm *ModelLifecycleManager) EnsureLoaded(modelID string) (string, error) {
+	gpuID, release, err := lm.Acquire(modelID)
+	if err != nil {
+		return "", err
+	}
+	release()
+	return gpuID, nil
+}
+
+// Acquire loads the model like EnsureLoaded and pins it so it is not evicted
+// until release is called. Loads and evictions run without the manager's lock,
+// and concurrent callers wait for the load already in progress.
+func (lm *ModelLifecycleManager) Acquire(modelID string) (string, func(), error) {
+	lm.mu.Lock()
+	model, exists := lm.models[modelID]
+	if !exists {
+		lm.mu.Unlock()
+		return "", nil, fmt.Errorf("model %s not registered", modelID)
+	}
+
+	for {
+		loaded := lm.findLoadedLocked(modelID)
+		if loaded == nil {
+			break
+		}
+		if busy := loaded.busy; busy != nil {
+			lm.mu.Unlock()
+			<-busy
+			if loaded.loadErr != nil {
+				return "", nil, loaded.loadErr
+			}
+			lm.mu.Lock()
+			continue
+		}
+		loaded.lastUsed = time.Now()
+		loaded.inUse++
+		lm.mu.Unlock()
+		return loaded.gpuID, lm.releaser(loaded), nil
+	}
+
+	gpuID, victims, err := lm.planPlacementLocked(model)
+	if err != nil {
+		lm.mu.Unlock()
+		return "", nil, err
+	}
+
+	// Claim the victims and reserve the model's memory before letting go of the lock
+	for _, victim := range victims {
+		victim.busy = make(chan struct{})
+	}
+	now := time.Now()
+	pending := &loadedModel{model: model, gpuID: gpuID, loadedAt: now, lastUsed: now, inUse: 1, loading: true, busy: make(chan struct{})}
+	gpu := lm.gpus[gpuID]
+	gpu.models[modelID] = pending
+	gpu.usedMB += model.MemorySize
+	lm.mu.Unlock()
+
+	for i, victim := range victims {
+		if err := lm.unload(victim); err != nil {
+			lm.mu.Lock()
+			for _, remaining := range victims[i+1:] {
+				lm.settleLocked(remaining)
+			}
+			lm.mu.Unlock()
+			return "", nil, lm.abandonLoad(pending, fmt.Errorf("failed to evict model %s: %w", victim.model.ID, err))
+		}
+		lm.mu.Lock()
+		lm.evictions++
+		lm.evictionsByModel[victim.model.ID]++
+		lm.mu.Unlock()
+	}
+
+	start := time.Now()
+	if lm.loader != nil {
+		if err := lm.loader(model, gpuID); err != nil {
+			return "", nil, lm.abandonLoad(pending, fmt.Errorf("failed to load model %s on GPU %s: %w", modelID, gpuID, err))
+		}
+	}
+	duration := time.Since(start)
+
+	lm.mu.Lock()
+	pending.lastUsed = time.Now()
+	lm.loads++
+	lm.totalLoadTime += duration
+	lm.lastLoadDurations[modelID] = duration
+	lm.settleLocked(pending)
+	lm.mu.Unlock()
+
+	return gpuID, lm.releaser(pending), nil
+}
+
+// releaser returns the function that unpins a model acquired by Acquire
+func (lm *ModelLifecycleManager) releaser(loaded *loadedModel) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			lm.mu.Lock()
+			defer lm.mu.Unlock()
+			loaded.inUse--
+			loaded.lastUsed = time.Now()
+		})
+	}
+}
+
+// abandonLoad gives back the memory reserved for a failed load and fails the
+// callers waiting on it
+func (lm *ModelLifecycleManager) abandonLoad(pending *loadedModel, err error) error {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	gpu := lm.gpus[pending.gpuID]
+	delete(gpu.models, pending.model.ID)
+	gpu.usedMB -= pending.model.MemorySize
+	lm.loadFailures++
+	pending.loadErr = err
+	lm.settleLocked(pending)
+	return err
+}
+
+// settleLocked ends a model's load or unload and wakes its waiters; caller must hold the lock
+func (lm *ModelLifecycleManager) settleLocked(loaded *loadedModel) {
+	loaded.loading = false
+	if loaded.busy != nil {
+		close(loaded.busy)
+		loaded.busy = nil
+	}
+}
+
+// Unload removes a model from GPU memory; models in use or being loaded cannot be unloaded
+func (lm *ModelLifecycleManager) Unload(modelID string) error {
+	lm.mu.Lock()
+	loaded := lm.findLoadedLocked(modelID)
+	switch {
+	case loaded == nil:
+		lm.mu.Unlock()
+		return fmt.Errorf("model %s is not loaded", modelID)
+	case loaded.busy != nil:
+		lm.mu.Unlock()
+		return fmt.Errorf("model %s is being loaded or unloaded", modelID)
+	case loaded.inUse > 0:
+		lm.mu.Unlock()
+		return fmt.Errorf("model %s is serving %d requests", modelID, loaded.inUse)
+	}
+	loaded.busy = make(chan struct{})
+	lm.mu.Unlock()
+
+	return lm.unload(loaded)
+}
+
+// findLoadedLocked returns the resident copy of a model; caller must hold the lock
+func (lm *ModelLifecycleManager) findLoadedLocked(modelID string) *loadedModel {
+	for _, gpu := range lm.gpus {
+		if loaded, exists := gpu.models[modelID]; exists {
+			return loaded
+		}
+	}
+	return nil
+}
+
+// unload runs the unloader on a model claimed with busy, without holding the
+// lock. The model's memory stays in use until it is unloaded; a model that
+// fails to unload stays resident.
+func (lm *ModelLifecycleManager) unload(loaded *loadedModel) error {
+	start := time.Now()
+	var err error
+	if lm.unloader != nil {
+		err = lm.unloader(loaded.model, loaded.gpuID)
+	}
+	duration := time.Since(start)
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	defer lm.settleLocked(loaded)
+	if err != nil {
+		return err
+	}
+
+	gpu := lm.gpus[loaded.gpuID]
+	delete(gpu.models, loaded.model.ID)
+	gpu.usedMB -= loaded.model.MemorySize
+
+	lm.unloads++
+	lm.totalUnloadTime += duration
+	return nil
+}
+
+// planPlacementLocked picks a GPU for the model and the models that must be evicted from it.
+// Free memory is preferred; otherwise the GPU needing the cheapest evictions is chosen.
+// Models with higher priority than the one being loaded, in use, or moving in or
+// out of memory are never evicted.
+func (lm *ModelLifecycleManager) planPlacementLocked(model *Model) (string, []*loadedModel, error) {
+	priority := lm.priorities[model.ID]
+
+	gpuIDs := make([]string, 0, len(lm.gpus))
+	for gpuID := range lm.gpus {
+		gpuIDs = append(gpuIDs, gpuID)
+	}
+	sort.Strings(gpuIDs)
+
+	// Best fit among GPUs with enough free memory
+	bestGPU := ""
+	var bestLeftover uint64
+	for _, gpuID := range gpuIDs {
+		gpu := lm.gpus[gpuID]
+		if gpu.usedMB <= gpu.capacityMB && gpu.capacityMB-gpu.usedMB >= model.MemorySize {
+			leftover := gpu.capacityMB - gpu.usedMB - model.MemorySize
+			if bestGPU == "" || leftover < bestLeftover {
+				bestGPU = gpuID
+				bestLeftover = leftover
+			}
+		}
+	}
+	if bestGPU != "" {
+		return bestGPU, nil, nil
+	}
+
+	// Otherwise evict the least valuable models: lowest priority, then least recently used
+	var bestVictims []*loadedModel
+	for _, gpuID := range gpuIDs {
+		gpu := lm.gpus[gpuID]
+		if gpu.capacityMB < model.MemorySize {
+			continue
+		}
+
+		candidates := make([]*loadedModel, 0, len(gpu.models))
+		for _, loaded := range gpu.models {
+			if lm.priorities[loaded.model.ID] <= priority && loaded.inUse == 0 && loaded.busy == nil {
+				candidates = append(candidates, loaded)
+			}
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			pi, pj := lm.priorities[candidates[i].model.ID], lm.priorities[candidates[j].model.ID]
+			if pi != pj {
+				return pi < pj
+			}
+			return candidates[i].lastUsed.Before(candidates[j].lastUsed)
+		})
+
+		// Memory of models still unloading elsewhere can exceed capacity for a while
+		free := int64(gpu.capacityMB) - int64(gpu.usedMB)
+		victims := make([]*loadedModel, 0)
+		for _, candidate := range candidates {
+			if free >= int64(model.MemorySize) {
+				break
+			}
+			victims = append(victims, candidate)
+			free += int64(candidate.model.MemorySize)
+		}
+
+		if free >= int64(model.MemorySize) && (bestGPU == "" || len(victims) < len(bestVictims)) {
+			bestGPU = gpuID
+			bestVictims = victims
+		}
+	}
+
+	if bestGPU == "" {
+		return "", nil, fmt.Errorf("insufficient GPU memory to load model %s (%d MB)", model.ID, model.MemorySize)
+	}
+	return bestGPU, bestVictims, nil
+}
+
+// GetLoadedModels returns the resident model IDs per GPU
+func (lm *ModelLifecycleManager) GetLoadedModels() map[string][]string {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	result := make(map[string][]string)
+	for gpuID, gpu := range lm.gpus {
+		models := make([]string, 0, len(gpu.models))
+		for modelID, loaded := range gpu.models {
+			if !loaded.loading {
+				models = append(models, modelID)
+			}
+		}
+		sort.Strings(models)
+		result[gpuID] = models
+	}
+	return result
+}
+
+// GetStats returns model load and eviction statistics
+func (lm *ModelLifecycleManager) GetStats() map[string]interface{} {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	avgLoadMs, avgUnloadMs := 0.0, 0.0
+	if lm.loads > 0 {
+		avgLoadMs = float64(lm.totalLoadTime.Microseconds()) / float64(lm.loads) / 1000
+	}
+	if lm.unloads > 0 {
+		avgUnloadMs = float64(lm.totalUnloadTime.Microseconds()) / float64(lm.unloads) / 1000
+	}
+
+	evictionsByModel := make(map[string]int64, len(lm.evictionsByModel))
+	for modelID, count := range lm.evictionsByModel {
+		evictionsByModel[modelID] = count
+	}
+
+	lastLoadMs := make(map[string]float64, len(lm.lastLoadDurations))
+	for modelID, duration := range lm.lastLoadDurations {
+		lastLoadMs[modelID] = float64(duration.Microseconds()) / 1000
+	}
+
+	loadedModels, inUse := 0, 0
+	for _, gpu := range lm.gpus {
+		for _, loaded := range gpu.models {
+			if !loaded.loading {
+				loadedModels++
+			}
+			inUse += loaded.inUse
+		}
+	}
+
+	return map[string]interface{}{
+		"registered_models":  len(lm.models),
+		"loaded_models":      loadedModels,
+		"requests_in_use":    inUse,
+		"loads_total":        lm.loads,
+		"load_failures":      lm.loadFailures,
+		"unloads_total":      lm.unloads,
+		"evictions_total":    lm.evictions,
+		"evictions_by_model": evictionsByModel,
+		"avg_load_ms":        avgLoadMs,
+		"avg_unload_ms":      avgUnloadMs,
+		"last_load_ms":       lastLoadMs,
+	}
+}
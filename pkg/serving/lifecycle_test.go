@@ -0,0 +1,154 @@
+package serving
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLifecycleEvictsLeastRecentlyUsed(t *testing.T) {
+	unloaded := make([]string, 0)
+	lm := NewModelLifecycleManager(nil, func(model *Model, gpuID string) error {
+		unloaded = append(unloaded, model.ID)
+		return nil
+	})
+	lm.AddGPU("gpu-0", 10000)
+
+	for _, id := range []string{"a", "b", "c"} {
+		lm.RegisterModel(&Model{ID: id, Name: id, MemorySize: 4000}, 0)
+	}
+
+	if _, err := lm.EnsureLoaded("a"); err != nil {
+		t.Fatalf("Failed to load a: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	lm.EnsureLoaded("b")
+	time.Sleep(time.Millisecond)
+	lm.EnsureLoaded("a") // a is now more recently used than b
+
+	if _, err := lm.EnsureLoaded("c"); err != nil {
+		t.Fatalf("Failed to load c: %v", err)
+	}
+	if len(unloaded) != 1 || unloaded[0] != "b" {
+		t.Errorf("Expected b to be evicted, got %v", unloaded)
+	}
+
+	loaded := lm.GetLoadedModels()["gpu-0"]
+	if len(loaded) != 2 || loaded[0] != "a" || loaded[1] != "c" {
+		t.Errorf("Expected a and c resident, got %v", loaded)
+	}
+
+	stats := lm.GetStats()
+	if stats["evictions_total"].(int64) != 1 || stats["loads_total"].(int64) != 3 {
+		t.Errorf("Unexpected stats: %v", stats)
+	}
+}
+
+func TestLifecycleRespectsPriority(t *testing.T) {
+	lm := NewModelLifecycleManager(nil, nil)
+	lm.AddGPU("gpu-0", 8000)
+	lm.RegisterModel(&Model{ID: "critical", Name: "critical", MemorySize: 6000}, 10)
+	lm.RegisterModel(&Model{ID: "batch", Name: "batch", MemorySize: 6000}, 1)
+
+	lm.EnsureLoaded("critical")
+	if _, err := lm.EnsureLoaded("batch"); err == nil {
+		t.Error("Low-priority model should not evict a higher-priority model")
+	}
+
+	lm.Unload("critical")
+	lm.EnsureLoaded("batch")
+	if _, err := lm.EnsureLoaded("critical"); err != nil {
+		t.Errorf("High-priority model should evict lower-priority model: %v", err)
+	}
+}
+
+func TestServingManagerLoadsModelsOnDemand(t *testing.T) {
+	manager := NewServingManager(nil, time.Minute)
+	manager.RegisterModel(&Model{ID: "m", Name: "Model", MemorySize: 1000})
+
+	lm := NewModelLifecycleManager(func(model *Model, gpuID string) error {
+		return errors.New("out of memory")
+	}, nil)
+	lm.AddGPU("gpu-0", 2000)
+	lm.RegisterModel(&Model{ID: "m", Name: "Model", MemorySize: 1000}, 0)
+	manager.SetLifecycleManager(lm)
+
+	if _, err := manager.SubmitInferenceRequest(&InferenceRequest{ID: "1", ModelID: "m", Input: []byte("x")}); err == nil {
+		t.Error("Expected load failure to fail the request")
+	}
+	if lm.GetStats()["load_failures"].(int64) != 1 {
+		t.Error("Expected load failure to be counted")
+	}
+}
+
+func TestLifecycleLoadsOutsideTheLock(t *testing.T) {
+	release := make(chan struct{})
+	var loads int32
+	lm := NewModelLifecycleManager(func(model *Model, gpuID string) error {
+		if model.ID == "slow" {
+			atomic.AddInt32(&loads, 1)
+			<-release
+		}
+		return nil
+	}, nil)
+	lm.AddGPU("gpu-0", 10000)
+	lm.RegisterModel(&Model{ID: "slow", Name: "slow", MemorySize: 4000}, 0)
+	lm.RegisterModel(&Model{ID: "fast", Name: "fast", MemorySize: 4000}, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := lm.EnsureLoaded("slow"); err != nil {
+				t.Errorf("Failed to load slow: %v", err)
+			}
+		}()
+	}
+	for atomic.LoadInt32(&loads) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Other models load, and stats are readable, while slow is still loading
+	if _, err := lm.EnsureLoaded("fast"); err != nil {
+		t.Fatalf("Failed to load fast: %v", err)
+	}
+	if loaded := lm.GetLoadedModels()["gpu-0"]; len(loaded) != 1 || loaded[0] != "fast" {
+		t.Errorf("Expected only fast resident during the slow load, got %v", loaded)
+	}
+
+	close(release)
+	wg.Wait()
+	if loads != 1 || lm.GetStats()["loads_total"].(int64) != 2 {
+		t.Errorf("Expected concurrent callers to share one load, loaded slow %d times", loads)
+	}
+}
+
+func TestLifecycleDoesNotEvictModelsInUse(t *testing.T) {
+	lm := NewModelLifecycleManager(nil, nil)
+	lm.AddGPU("gpu-0", 8000)
+	lm.RegisterModel(&Model{ID: "a", Name: "a", MemorySize: 6000}, 0)
+	lm.RegisterModel(&Model{ID: "b", Name: "b", MemorySize: 6000}, 0)
+
+	_, release, err := lm.Acquire("a")
+	if err != nil {
+		t.Fatalf("Failed to acquire a: %v", err)
+	}
+	if _, err := lm.EnsureLoaded("b"); err == nil {
+		t.Error("Expected a model in use not to be evicted")
+	}
+	if err := lm.Unload("a"); err == nil {
+		t.Error("Expected a model in use not to be unloaded")
+	}
+	if lm.GetStats()["requests_in_use"].(int) != 1 {
+		t.Error("Expected the pinned request to be reported")
+	}
+
+	release()
+	release()
+	if _, err := lm.EnsureLoaded("b"); err != nil {
+		t.Errorf("Expected a to be evicted once released: %v", err)
+	}
+}
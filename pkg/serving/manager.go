@@ -3,11 +3,16 @@ package serving
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 )
 
+// DefaultTenant owns models registered without a tenant, matching gpu.DefaultTenant
+const DefaultTenant = "default"
+
 // Model represents an AI model being served
 type Model struct {
 	ID         string
@@ -16,6 +21,12 @@ type Model struct {
 	Framework  string
 	MemorySize uint64
 	LoadedAt   time.Time
+	Tenant     string // Team or namespace owning the model; DefaultTenant when empty
+
+	// Training workload and artifact URI the model was built from, linking
+	// it to the run that produced it
+	SourceWorkload string
+	SourceArtifact string
 }
 
 // InferenceRequest represents a request for model inference
@@ -25,7 +36,13 @@ type InferenceRequest struct {
 	Input     []byte
 	BatchID   string
 	Priority  int
-	CreatedAt time.Time
+	TraceID   string // Optional serving trace the request belongs to
+	SessionID string // Optional conversation session used for sticky routing
+	APIKey    string // Optional caller the request's cost is metered to
+
+	// Prompt tokens for metering; estimated from Input when zero
+	InputTokens int
+	CreatedAt   time.Time
 }
 
 // InferenceResponse represents the result of an inference
@@ -34,15 +51,32 @@ type InferenceResponse struct {
 	Output      []byte
 	Latency     time.Duration
 	CacheHit    bool
+	Coalesced   bool // Result was shared with an identical in-flight request
 	BatchSize   int
+	Breakdown   LatencyBreakdown // Per-stage latency of this request
 	CompletedAt time.Time
+
+	// Generated tokens for metering; estimated from Output when zero
+	OutputTokens int
+	Cost         *RequestCost // Estimated cost, set when metering is enabled
+
+	// Experiment arm that served the request, for attributing RecordQuality scores
+	ExperimentID string
+	Variant      string
 }
 
+// InferenceBackend runs a model on a request input and returns the output
+type InferenceBackend func(req *InferenceRequest) ([]byte, error)
+
 // BatchConfig defines batching behavior
 type BatchConfig struct {
 	MaxBatchSize int
 	MaxWaitTime  time.Duration
 	MinBatchSize int
+
+	// Requests waiting to be served before new ones are rejected; 0 is
+	// unbounded. Each model is admitted up to its SLA capacity share.
+	MaxQueueDepth int
 }
 
 // CacheEntry stores cached inference results
@@ -61,6 +95,35 @@ type ServingManager struct {
 	batchConfig  *BatchConfig
 	mu           sync.RWMutex
 	cacheTTL     time.Duration
+
+	// Synchronous requests admitted and not yet served, per model
+	admitted map[string]int
+
+	// Optional request/response logging
+	requestLogger *RequestLogger
+
+	// Per-model SLA tracking and mitigation
+	slaManager *SLAManager
+
+	// Per-stage latency instrumentation
+	latencyTracker    *LatencyTracker
+	lastBatchDispatch time.Time
+
+	// Backend execution and coalescing of identical in-flight requests
+	backend  InferenceBackend
+	inflight *inflightGroup
+
+	// Optional GPU memory management for models
+	lifecycle *ModelLifecycleManager
+
+	// A/B experiments across model variants
+	experiments *ExperimentManager
+
+	// Micro-batching of embedding requests
+	embeddings *embeddingBatcher
+
+	// Optional per-request cost estimates aggregated per API key
+	meter *Meter
 }
 
 // NewServingManager creates a new serving manager
@@ -76,9 +139,16 @@ func NewServingManager(batchConfig *BatchConfig, cacheTTL time.Duration) *Servin
 	return &ServingManager{
 		models:       make(map[string]*Model),
 		requestQueue: make([]*InferenceRequest, 0),
+		admitted:     make(map[string]int),
 		cache:        make(map[string]*CacheEntry),
 		batchConfig:  batchConfig,
 		cacheTTL:     cacheTTL,
+		slaManager:   NewSLAManager(),
+
+		latencyTracker: NewLatencyTracker(nil),
+		inflight:       newInflightGroup(),
+		experiments:    NewExperimentManager(),
+		embeddings:     newEmbeddingBatcher(DefaultEmbeddingConfig()),
 	}
 }
 
@@ -97,16 +167,120 @@ func (sm *ServingManager) RegisterModel(model *Model) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
+	if model.Tenant == "" {
+		model.Tenant = DefaultTenant
+	}
 	model.LoadedAt = time.Now()
 	sm.models[model.ID] = model
 	return nil
 }
 
+// Models returns every registered model, ordered by ID
+func (sm *ServingManager) Models() []*Model {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	models := make([]*Model, 0, len(sm.models))
+	for _, model := range sm.models {
+		models = append(models, model)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models
+}
+
+// GetModel returns a registered model
+func (sm *ServingManager) GetModel(modelID string) (*Model, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	model, exists := sm.models[modelID]
+	return model, exists
+}
+
+// UnregisterModel removes a model, reporting whether it was registered
+func (sm *ServingManager) UnregisterModel(modelID string) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	_, exists := sm.models[modelID]
+	delete(sm.models, modelID)
+	return exists
+}
+
+// ModelsFromWorkload returns the registered models a training workload
+// produced, ordered by ID
+func (sm *ServingManager) ModelsFromWorkload(workloadID string) []*Model {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	models := make([]*Model, 0)
+	for _, model := range sm.models {
+		if model.SourceWorkload == workloadID {
+			models = append(models, model)
+		}
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models
+}
+
+// ModelsForTenant returns the registered models a tenant owns, ordered by ID
+func (sm *ServingManager) ModelsForTenant(tenant string) []*Model {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	models := make([]*Model, 0)
+	for _, model := range sm.models {
+		if model.Tenant == tenant {
+			models = append(models, model)
+		}
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models
+}
+
 // SubmitInferenceRequest submits a new inference request
 func (sm *ServingManager) SubmitInferenceRequest(req *InferenceRequest) (*InferenceResponse, error) {
 	if req == nil {
 		return nil, fmt.Errorf("inference request cannot be nil")
 	}
+
+	// Route the request to an experiment variant when an experiment runs for the model
+	key := req.SessionID
+	if key == "" {
+		key = req.ID
+	}
+	experimentID, variant, inExperiment := sm.experiments.AssignVariant(req.ModelID, key)
+	if !inExperiment {
+		return sm.submitInferenceRequest(req)
+	}
+
+	// Route a copy so the caller's request still names the model it asked for
+	routed := *req
+	routed.ModelID = variant.ModelID
+	start := time.Now()
+	resp, err := sm.submitInferenceRequest(&routed)
+	if err != nil {
+		sm.experiments.RecordOutcome(experimentID, variant.Name, time.Since(start), false)
+		return nil, err
+	}
+	sm.experiments.RecordOutcome(experimentID, variant.Name, resp.Latency, true)
+
+	// The metered cost when metering is enabled, else the variant's flat cost
+	cost := variant.CostPerRequest
+	if resp.Cost != nil {
+		cost = resp.Cost.Total
+	}
+	if cost > 0 {
+		sm.experiments.RecordCost(experimentID, variant.Name, cost)
+	}
+
+	// Responses may be shared with the cache, so tag a copy
+	tagged := *resp
+	tagged.ExperimentID = experimentID
+	tagged.Variant = variant.Name
+	return &tagged, nil
+}
+
+// submitInferenceRequest validates, deduplicates and executes a request
+func (sm *ServingManager) submitInferenceRequest(req *InferenceRequest) (*InferenceResponse, error) {
 	if req.ID == "" {
 		return nil, fmt.Errorf("request ID cannot be empty")
 	}
@@ -117,37 +291,329 @@ func (sm *ServingManager) SubmitInferenceRequest(req *InferenceRequest) (*Infere
 		return nil, fmt.Errorf("request input cannot be empty")
 	}
 
+	if sm.slaManager.IsShed(req.ModelID) {
+		return nil, fmt.Errorf("traffic for model %s is being shed to protect higher-tier SLAs", req.ModelID)
+	}
+
 	req.CreatedAt = time.Now()
 
 	// Check cache first
+	cacheStart := time.Now()
 	cacheKey := sm.generateCacheKey(req.ModelID, req.Input)
-	if cached := sm.checkCache(cacheKey); cached != nil {
-		cached.CacheHit = true
+	cached := sm.checkCache(cacheKey)
+	cacheCheck := time.Since(cacheStart)
+
+	if cached != nil {
 		sm.incrementCacheHit(cacheKey)
-		return cached, nil
+		hit := *cached
+		hit.CacheHit = true
+		hit.Breakdown = LatencyBreakdown{CacheCheck: cacheCheck}
+		sm.latencyTracker.Observe(req.ModelID, hit.Breakdown)
+		sm.meterRequest(req, &hit)
+		sm.logRequest(req, &hit)
+		return &hit, nil
 	}
 
-	sm.mu.Lock()
-	sm.requestQueue = append(sm.requestQueue, req)
-	sm.mu.Unlock()
+	// Coalesce identical in-flight requests so the backend runs once
+	call, leader := sm.inflight.join(cacheKey, req.ModelID)
+	if !leader {
+		return sm.awaitCoalesced(req, call, cacheCheck)
+	}
+
+	return sm.inflight.do(cacheKey, call, func() (*InferenceResponse, error) {
+		return sm.lead(req, cacheKey, cacheCheck)
+	})
+}
+
+// lead executes a request on behalf of every identical request coalesced onto it
+func (sm *ServingManager) lead(req *InferenceRequest, cacheKey string, cacheCheck time.Duration) (*InferenceResponse, error) {
+	release, err := sm.admit(req)
+	if err != nil {
+		sm.recordFailure(req.ModelID, 0, err)
+		return nil, err
+	}
+	defer release()
+
+	// Make sure the model is resident on a GPU before running it
+	if lifecycle := sm.GetLifecycleManager(); lifecycle != nil {
+		loadStart := time.Now()
+		_, release, err := lifecycle.Acquire(req.ModelID)
+		if err != nil {
+			sm.recordFailure(req.ModelID, time.Since(loadStart), err)
+			return nil, fmt.Errorf("failed to load model for request %s: %w", req.ID, err)
+		}
+		// Pinned so it is not evicted while the request runs
+		defer release()
+	}
 
 	// In a real implementation, this would process asynchronously
-	// For now, simulate processing
+	output, execution, err := sm.execute(req)
+	if err != nil {
+		sm.recordFailure(req.ModelID, execution, err)
+		return nil, fmt.Errorf("inference failed for request %s: %w", req.ID, err)
+	}
+
+	breakdown := LatencyBreakdown{CacheCheck: cacheCheck, Execution: execution}
 	response := &InferenceResponse{
 		RequestID:   req.ID,
-		Output:      []byte(fmt.Sprintf("processed_%s", req.ID)),
-		Latency:     50 * time.Millisecond,
+		Output:      output,
+		Latency:     execution,
 		CacheHit:    false,
 		BatchSize:   1,
+		Breakdown:   breakdown,
 		CompletedAt: time.Now(),
 	}
+	sm.latencyTracker.Observe(req.ModelID, breakdown)
 
 	// Store in cache
+	sm.meterRequest(req, response)
 	sm.storeInCache(cacheKey, response)
+	sm.logRequest(req, response)
+	sm.slaManager.RecordOutcome(req.ModelID, response.Latency, true)
 
 	return response, nil
 }
 
+// awaitCoalesced waits for the leader of an identical in-flight request and shares its result
+func (sm *ServingManager) awaitCoalesced(req *InferenceRequest, call *inflightCall, cacheCheck time.Duration) (*InferenceResponse, error) {
+	waitStart := time.Now()
+	<-call.done
+	waited := time.Since(waitStart)
+
+	if call.err != nil {
+		sm.recordFailure(req.ModelID, waited, call.err)
+		return nil, fmt.Errorf("inference failed for request %s: %w", req.ID, call.err)
+	}
+
+	shared := *call.response
+	shared.RequestID = req.ID
+	shared.Coalesced = true
+	shared.Latency = waited
+	shared.Breakdown = LatencyBreakdown{CacheCheck: cacheCheck, Execution: waited}
+	shared.CompletedAt = time.Now()
+
+	sm.latencyTracker.Observe(req.ModelID, shared.Breakdown)
+	sm.meterRequest(req, &shared)
+	sm.logRequest(req, &shared)
+	sm.slaManager.RecordOutcome(req.ModelID, shared.Latency, true)
+	return &shared, nil
+}
+
+// admit counts a request as waiting until the returned release is called,
+// rejecting it once the requests waiting in the batch queue or being served
+// reach MaxQueueDepth for the model's capacity share
+func (sm *ServingManager) admit(req *InferenceRequest) (func(), error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if depth := sm.batchConfig.MaxQueueDepth; depth > 0 {
+		queued := make(map[string]int)
+		for modelID, count := range sm.admitted {
+			queued[modelID] = count
+		}
+		for _, pending := range sm.requestQueue {
+			queued[pending.ModelID]++
+		}
+		if quota := sm.slaManager.QueueQuota(req.ModelID, depth, queued); queued[req.ModelID] >= quota {
+			return nil, fmt.Errorf("queue share of model %s exhausted (%d of %d queued requests)", req.ModelID, queued[req.ModelID], quota)
+		}
+	}
+	sm.admitted[req.ModelID]++
+	return func() {
+		sm.mu.Lock()
+		defer sm.mu.Unlock()
+		if sm.admitted[req.ModelID]--; sm.admitted[req.ModelID] == 0 {
+			delete(sm.admitted, req.ModelID)
+		}
+	}, nil
+}
+
+// recordFailure counts a failed request against the model's SLA availability
+func (sm *ServingManager) recordFailure(modelID string, elapsed time.Duration, err error) {
+	if errors.Is(err, ErrInferenceTimeout) {
+		sm.slaManager.RecordTimeout(modelID, elapsed)
+		return
+	}
+	sm.slaManager.RecordOutcome(modelID, elapsed, false)
+}
+
+// SetInferenceBackend sets the function used to run models; nil restores simulated execution
+func (sm *ServingManager) SetInferenceBackend(backend InferenceBackend) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.backend = backend
+}
+
+// GetExperimentManager returns the A/B experiment manager
+func (sm *ServingManager) GetExperimentManager() *ExperimentManager {
+	return sm.experiments
+}
+
+// SetLifecycleManager enables loading and evicting models on demand
+func (sm *ServingManager) SetLifecycleManager(lifecycle *ModelLifecycleManager) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.lifecycle = lifecycle
+}
+
+// GetLifecycleManager returns the model lifecycle manager, or nil if not set
+func (sm *ServingManager) GetLifecycleManager() *ModelLifecycleManager {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.lifecycle
+}
+
+// execute runs the request on the backend and returns the output and execution
+// time, failing with ErrInferenceTimeout once the model SLA's timeout passes
+func (sm *ServingManager) execute(req *InferenceRequest) ([]byte, time.Duration, error) {
+	sm.mu.RLock()
+	backend := sm.backend
+	sm.mu.RUnlock()
+	timeout := sm.slaManager.Timeout(req.ModelID)
+
+	if backend == nil {
+		// Simulated processing
+		execution := 50 * time.Millisecond
+		if timeout > 0 && execution > timeout {
+			return nil, timeout, fmt.Errorf("%w after %v", ErrInferenceTimeout, timeout)
+		}
+		return []byte(fmt.Sprintf("processed_%s", req.ID)), execution, nil
+	}
+
+	start := time.Now()
+	if timeout <= 0 {
+		output, err := backend(req)
+		return output, time.Since(start), err
+	}
+
+	// The backend keeps running after a timeout; its result is discarded
+	type result struct {
+		output   []byte
+		err      error
+		panicked interface{}
+	}
+	done := make(chan result, 1)
+	go func() {
+		// A backend panic is raised again in the caller rather than crashing the process
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{panicked: r}
+			}
+		}()
+		output, err := backend(req)
+		done <- result{output: output, err: err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		if r.panicked != nil {
+			panic(r.panicked)
+		}
+		return r.output, time.Since(start), r.err
+	case <-timer.C:
+		return nil, time.Since(start), fmt.Errorf("%w after %v", ErrInferenceTimeout, timeout)
+	}
+}
+
+// EnableRequestLogging turns on sampled request/response logging
+func (sm *ServingManager) EnableRequestLogging(config RequestLogConfig) error {
+	logger, err := NewRequestLogger(config)
+	if err != nil {
+		return err
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.requestLogger = logger
+	return nil
+}
+
+// DisableRequestLogging turns off request/response logging and discards logged entries
+func (sm *ServingManager) DisableRequestLogging() {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.requestLogger = nil
+}
+
+// GetRequestLogger returns the request logger, or nil if logging is disabled
+func (sm *ServingManager) GetRequestLogger() *RequestLogger {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.requestLogger
+}
+
+// logRequest forwards an exchange to the request logger when enabled
+func (sm *ServingManager) logRequest(req *InferenceRequest, resp *InferenceResponse) {
+	if logger := sm.GetRequestLogger(); logger != nil {
+		logger.Record(req, resp)
+	}
+}
+
+// EnableMetering attaches an estimated cost to every response and aggregates it per API key
+func (sm *ServingManager) EnableMetering(config MeteringConfig) error {
+	meter, err := NewMeter(config)
+	if err != nil {
+		return err
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.meter = meter
+	return nil
+}
+
+// DisableMetering stops cost estimates and discards the per-key aggregates
+func (sm *ServingManager) DisableMetering() {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.meter = nil
+}
+
+// GetMeter returns the request meter, or nil if metering is disabled
+func (sm *ServingManager) GetMeter() *Meter {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.meter
+}
+
+// meterRequest prices a response when metering is enabled. Responses copied
+// from the cache or a coalesced leader drop the leader's cost either way.
+func (sm *ServingManager) meterRequest(req *InferenceRequest, resp *InferenceResponse) {
+	resp.Cost = nil
+	if meter := sm.GetMeter(); meter != nil {
+		meter.Record(req, resp)
+	}
+}
+
+// SetModelSLA attaches an SLA to a registered model
+func (sm *ServingManager) SetModelSLA(modelID string, sla ModelSLA) error {
+	sm.mu.RLock()
+	_, exists := sm.models[modelID]
+	sm.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("model %s not registered", modelID)
+	}
+	return sm.slaManager.SetSLA(modelID, sla)
+}
+
+// RecordInferenceOutcome records an externally observed inference result against the model's SLA
+func (sm *ServingManager) RecordInferenceOutcome(modelID string, latency time.Duration, success bool) {
+	sm.slaManager.RecordOutcome(modelID, latency, success)
+}
+
+// EvaluateSLAs checks every model SLA and applies capacity and shedding decisions
+func (sm *ServingManager) EvaluateSLAs() []SLAReport {
+	return sm.slaManager.Evaluate()
+}
+
+// GetSLAManager returns the SLA manager
+func (sm *ServingManager) GetSLAManager() *SLAManager {
+	return sm.slaManager
+}
+
 // generateCacheKey creates a unique key for caching
 func (sm *ServingManager) generateCacheKey(modelID string, input []byte) string {
 	hash := sha256.Sum256(append([]byte(modelID), input...))
@@ -204,28 +670,78 @@ func (sm *ServingManager) ProcessBatch() ([]*InferenceResponse, error) {
 		return nil, nil
 	}
 
+	// Models with a raised SLA capacity share are served first
+	sort.SliceStable(sm.requestQueue, func(i, j int) bool {
+		return sm.slaManager.CapacityShare(sm.requestQueue[i].ModelID) > sm.slaManager.CapacityShare(sm.requestQueue[j].ModelID)
+	})
+
 	batchSize := min(len(sm.requestQueue), sm.batchConfig.MaxBatchSize)
 	batch := sm.requestQueue[:batchSize]
 	sm.requestQueue = sm.requestQueue[batchSize:]
 
+	dispatchedAt := time.Now()
+	previousDispatch := sm.lastBatchDispatch
+	sm.lastBatchDispatch = dispatchedAt
+
 	sm.mu.Unlock()
 
 	// Process batch
 	responses := make([]*InferenceResponse, len(batch))
 	for i, req := range batch {
+		breakdown := batchWaitBreakdown(req.CreatedAt, previousDispatch, dispatchedAt)
+		breakdown.Execution = 30 * time.Millisecond
+		sm.latencyTracker.Observe(req.ModelID, breakdown)
+
 		responses[i] = &InferenceResponse{
 			RequestID:   req.ID,
 			Output:      []byte(fmt.Sprintf("batch_processed_%s", req.ID)),
-			Latency:     30 * time.Millisecond,
+			Latency:     breakdown.Total(),
 			CacheHit:    false,
 			BatchSize:   batchSize,
+			Breakdown:   breakdown,
 			CompletedAt: time.Now(),
 		}
+		sm.meterRequest(req, responses[i])
+		sm.logRequest(req, responses[i])
+		sm.slaManager.RecordOutcome(req.ModelID, responses[i].Latency, true)
 	}
 
 	return responses, nil
 }
 
+// batchWaitBreakdown splits the time a request spent before dispatch into queue wait
+// (left behind by an earlier batch) and batch wait (waiting for its own batch to form)
+func batchWaitBreakdown(createdAt, previousDispatch, dispatchedAt time.Time) LatencyBreakdown {
+	var breakdown LatencyBreakdown
+	if createdAt.IsZero() {
+		return breakdown
+	}
+
+	batchStart := createdAt
+	if !previousDispatch.IsZero() && previousDispatch.After(createdAt) {
+		breakdown.QueueWait = previousDispatch.Sub(createdAt)
+		batchStart = previousDispatch
+	}
+	if dispatchedAt.After(batchStart) {
+		breakdown.BatchWait = dispatchedAt.Sub(batchStart)
+	}
+	return breakdown
+}
+
+// GetLatencyTracker returns the per-stage latency tracker
+func (sm *ServingManager) GetLatencyTracker() *LatencyTracker {
+	return sm.latencyTracker
+}
+
+// GetLatencyBreakdown returns mean and p95 latency per stage for every model
+func (sm *ServingManager) GetLatencyBreakdown() map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, modelID := range sm.latencyTracker.GetModels() {
+		result[modelID] = sm.latencyTracker.GetBreakdownSummary(modelID)
+	}
+	return result
+}
+
 // GetCacheMetrics returns cache performance statistics
 func (sm *ServingManager) GetCacheMetrics() map[string]interface{} {
 	sm.mu.RLock()
@@ -262,6 +778,8 @@ func (sm *ServingManager) GetServingMetrics() map[string]interface{} {
 		"max_batch_size":   sm.batchConfig.MaxBatchSize,
 		"min_batch_size":   sm.batchConfig.MinBatchSize,
 		"max_wait_time_ms": sm.batchConfig.MaxWaitTime.Milliseconds(),
+		"coalescing":       sm.inflight.getStats(),
+		"embeddings":       sm.embeddings.getStats(),
 	}
 }
 
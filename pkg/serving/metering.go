@@ -0,0 +1,193 @@
+package serving
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// AnonymousAPIKey aggregates metered requests that carry no API key
+const AnonymousAPIKey = "anonymous"
+
+// ModelPricing prices the serving of a model
+type ModelPricing struct {
+	GPUHourlyRate     float64 `yaml:"gpu_hourly_rate" json:"gpu_hourly_rate"`
+	InputPer1KTokens  float64 `yaml:"input_per_1k_tokens" json:"input_per_1k_tokens"`
+	OutputPer1KTokens float64 `yaml:"output_per_1k_tokens" json:"output_per_1k_tokens"`
+}
+
+// MeteringConfig controls the cost estimate attached to inference responses
+type MeteringConfig struct {
+	Currency string                  `yaml:"currency" json:"currency"`
+	Default  ModelPricing            `yaml:"default" json:"default"`
+	Models   map[string]ModelPricing `yaml:"models" json:"models"` // Overrides Default per model ID
+
+	// Payload bytes per token when a request or response carries no token count
+	BytesPerToken float64 `yaml:"bytes_per_token" json:"bytes_per_token"`
+}
+
+// DefaultMeteringConfig prices GPU time like an on-demand A100 with no token charges
+func DefaultMeteringConfig() MeteringConfig {
+	return MeteringConfig{
+		Currency:      "USD",
+		Default:       ModelPricing{GPUHourlyRate: 3.06},
+		Models:        make(map[string]ModelPricing),
+		BytesPerToken: 4,
+	}
+}
+
+// RequestCost is the estimated cost of serving one request
+type RequestCost struct {
+	GPUSeconds   float64 `json:"gpu_seconds"` // Backend time shared across the request's batch
+	GPUCost      float64 `json:"gpu_cost"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	TokenCost    float64 `json:"token_cost"`
+	Total        float64 `json:"total"`
+	Currency     string  `json:"currency"`
+}
+
+// APIKeyUsage is the metered serving usage of one API key
+type APIKeyUsage struct {
+	APIKey       string  `json:"api_key"`
+	Requests     int64   `json:"requests"`
+	GPUSeconds   float64 `json:"gpu_seconds"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	Cost         float64 `json:"cost"`
+}
+
+// Meter estimates request costs and aggregates them per API key
+type Meter struct {
+	config MeteringConfig
+	usage  map[string]*APIKeyUsage
+	mu     sync.RWMutex
+}
+
+// NewMeter creates a meter, rejecting negative prices
+func NewMeter(config MeteringConfig) (*Meter, error) {
+	pricings := map[string]ModelPricing{"default": config.Default}
+	for modelID, pricing := range config.Models {
+		pricings[modelID] = pricing
+	}
+	for name, pricing := range pricings {
+		if pricing.GPUHourlyRate < 0 || pricing.InputPer1KTokens < 0 || pricing.OutputPer1KTokens < 0 {
+			return nil, fmt.Errorf("pricing for %s cannot be negative", name)
+		}
+	}
+	if config.BytesPerToken <= 0 {
+		config.BytesPerToken = 4
+	}
+
+	return &Meter{
+		config: config,
+		usage:  make(map[string]*APIKeyUsage),
+	}, nil
+}
+
+// pricing returns the pricing of a model
+func (m *Meter) pricing(modelID string) ModelPricing {
+	if pricing, exists := m.config.Models[modelID]; exists {
+		return pricing
+	}
+	return m.config.Default
+}
+
+// tokens returns a known token count or estimates one from the payload size
+func (m *Meter) tokens(count int, payload []byte) int {
+	if count > 0 {
+		return count
+	}
+	return int(float64(len(payload))/m.config.BytesPerToken + 0.5)
+}
+
+// Estimate prices a response. Cache hits and coalesced responses used no
+// backend time of their own, so they are charged for tokens only.
+func (m *Meter) Estimate(req *InferenceRequest, resp *InferenceResponse) RequestCost {
+	pricing := m.pricing(req.ModelID)
+	cost := RequestCost{
+		InputTokens:  m.tokens(req.InputTokens, req.Input),
+		OutputTokens: m.tokens(resp.OutputTokens, resp.Output),
+		Currency:     m.config.Currency,
+	}
+	if !resp.CacheHit && !resp.Coalesced {
+		batchSize := resp.BatchSize
+		if batchSize < 1 {
+			batchSize = 1
+		}
+		cost.GPUSeconds = resp.Breakdown.Execution.Seconds() / float64(batchSize)
+	}
+	cost.GPUCost = cost.GPUSeconds / 3600 * pricing.GPUHourlyRate
+	cost.TokenCost = float64(cost.InputTokens)/1000*pricing.InputPer1KTokens +
+		float64(cost.OutputTokens)/1000*pricing.OutputPer1KTokens
+	cost.Total = cost.GPUCost + cost.TokenCost
+	return cost
+}
+
+// Record estimates a response's cost, attaches it and adds it to the request's API key
+func (m *Meter) Record(req *InferenceRequest, resp *InferenceResponse) {
+	cost := m.Estimate(req, resp)
+	resp.Cost = &cost
+
+	key := req.APIKey
+	if key == "" {
+		key = AnonymousAPIKey
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	usage, exists := m.usage[key]
+	if !exists {
+		usage = &APIKeyUsage{APIKey: key}
+		m.usage[key] = usage
+	}
+	usage.Requests++
+	usage.GPUSeconds += cost.GPUSeconds
+	usage.InputTokens += int64(cost.InputTokens)
+	usage.OutputTokens += int64(cost.OutputTokens)
+	usage.Cost += cost.Total
+}
+
+// GetUsage returns the metered usage of every API key, most expensive first
+func (m *Meter) GetUsage() []APIKeyUsage {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.sortedUsage()
+}
+
+// sortedUsage copies the aggregates, most expensive first; callers must hold the lock
+func (m *Meter) sortedUsage() []APIKeyUsage {
+	usage := make([]APIKeyUsage, 0, len(m.usage))
+	for _, keyUsage := range m.usage {
+		usage = append(usage, *keyUsage)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Cost != usage[j].Cost {
+			return usage[i].Cost > usage[j].Cost
+		}
+		return usage[i].APIKey < usage[j].APIKey
+	})
+	return usage
+}
+
+// GetKeyUsage returns the metered usage of one API key
+func (m *Meter) GetKeyUsage(apiKey string) (APIKeyUsage, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	usage, exists := m.usage[apiKey]
+	if !exists {
+		return APIKeyUsage{}, false
+	}
+	return *usage, true
+}
+
+// ResetUsage clears the aggregates, e.g. after a billing export, and returns what was cleared
+func (m *Meter) ResetUsage() []APIKeyUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usage := m.sortedUsage()
+	m.usage = make(map[string]*APIKeyUsage)
+	return usage
+}
@@ -0,0 +1,97 @@
+package serving
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMeterEstimate(t *testing.T) {
+	config := DefaultMeteringConfig()
+	config.Default = ModelPricing{GPUHourlyRate: 3.6, InputPer1KTokens: 0.5, OutputPer1KTokens: 1.5}
+	config.Models["small"] = ModelPricing{GPUHourlyRate: 0.36}
+	meter, err := NewMeter(config)
+	if err != nil {
+		t.Fatalf("NewMeter failed: %v", err)
+	}
+
+	// 2 seconds of backend time shared by a batch of 4, 1000 prompt tokens, 40 output bytes
+	req := &InferenceRequest{ModelID: "large", Input: []byte("prompt"), InputTokens: 1000}
+	resp := &InferenceResponse{Output: make([]byte, 40), BatchSize: 4, Breakdown: LatencyBreakdown{Execution: 2 * time.Second}}
+	cost := meter.Estimate(req, resp)
+	if cost.GPUSeconds != 0.5 || math.Abs(cost.GPUCost-0.0005) > 1e-12 {
+		t.Errorf("Expected half a GPU second at 3.60/h, got %+v", cost)
+	}
+	if cost.InputTokens != 1000 || cost.OutputTokens != 10 || math.Abs(cost.TokenCost-0.515) > 1e-12 {
+		t.Errorf("Expected 1000 input and 10 estimated output tokens, got %+v", cost)
+	}
+	if math.Abs(cost.Total-(cost.GPUCost+cost.TokenCost)) > 1e-12 || cost.Currency != "USD" {
+		t.Errorf("Unexpected total %+v", cost)
+	}
+
+	// Cache hits pay for tokens only
+	hit := *resp
+	hit.CacheHit = true
+	if cost := meter.Estimate(req, &hit); cost.GPUSeconds != 0 || cost.GPUCost != 0 || cost.TokenCost == 0 {
+		t.Errorf("Expected a cache hit to cost tokens only, got %+v", cost)
+	}
+
+	small := &InferenceRequest{ModelID: "small", Input: []byte("x")}
+	if cost := meter.Estimate(small, resp); math.Abs(cost.GPUCost-0.00005) > 1e-12 || cost.TokenCost != 0 {
+		t.Errorf("Expected the small model's pricing, got %+v", cost)
+	}
+
+	config.Models["bad"] = ModelPricing{OutputPer1KTokens: -1}
+	if _, err := NewMeter(config); err == nil {
+		t.Error("Expected negative pricing to be rejected")
+	}
+}
+
+func TestServingManagerMetering(t *testing.T) {
+	manager := NewServingManager(nil, time.Minute)
+	manager.RegisterModel(&Model{ID: "m", Name: "Model"})
+
+	resp, err := manager.SubmitInferenceRequest(&InferenceRequest{ID: "r0", ModelID: "m", Input: []byte("hello")})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.Cost != nil {
+		t.Errorf("Expected no cost without metering, got %+v", resp.Cost)
+	}
+
+	if err := manager.EnableMetering(DefaultMeteringConfig()); err != nil {
+		t.Fatalf("EnableMetering failed: %v", err)
+	}
+	first, err := manager.SubmitInferenceRequest(&InferenceRequest{ID: "r1", ModelID: "m", Input: []byte("prompt"), APIKey: "acme"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if first.Cost == nil || first.Cost.GPUSeconds != 0.05 || first.Cost.Total <= 0 {
+		t.Fatalf("Expected the simulated 50ms to be priced, got %+v", first.Cost)
+	}
+	cached, err := manager.SubmitInferenceRequest(&InferenceRequest{ID: "r2", ModelID: "m", Input: []byte("prompt"), APIKey: "acme"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if !cached.CacheHit || cached.Cost == nil || cached.Cost.GPUSeconds != 0 {
+		t.Errorf("Expected a cache hit without GPU time, got %+v", cached.Cost)
+	}
+	manager.SubmitInferenceRequest(&InferenceRequest{ID: "r3", ModelID: "m", Input: []byte("other")})
+
+	usage, exists := manager.GetMeter().GetKeyUsage("acme")
+	if !exists || usage.Requests != 2 || usage.GPUSeconds != 0.05 || math.Abs(usage.Cost-(first.Cost.Total+cached.Cost.Total)) > 1e-12 {
+		t.Errorf("Unexpected acme usage %+v", usage)
+	}
+	all := manager.GetMeter().ResetUsage()
+	if len(all) != 2 || (all[0].APIKey != AnonymousAPIKey && all[1].APIKey != AnonymousAPIKey) {
+		t.Errorf("Expected acme and anonymous usage, got %+v", all)
+	}
+	if len(manager.GetMeter().GetUsage()) != 0 {
+		t.Error("Expected usage to be cleared")
+	}
+
+	manager.DisableMetering()
+	if manager.GetMeter() != nil {
+		t.Error("Expected metering to be disabled")
+	}
+}
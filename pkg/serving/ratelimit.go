@@ -0,0 +1,77 @@
+package serving
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a keyed token bucket limiter (e.g. per client or per API key)
+type RateLimiter struct {
+	rate    float64 // Tokens added per second
+	burst   float64 // Maximum bucket size
+	buckets map[string]*tokenBucket
+	mu      sync.Mutex
+}
+
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// NewRateLimiter creates a limiter allowing rate requests per second with the given burst
+func NewRateLimiter(rate float64, burst int) (*RateLimiter, error) {
+	if rate <= 0 {
+		return nil, fmt.Errorf("rate must be positive")
+	}
+	if burst <= 0 {
+		return nil, fmt.Errorf("burst must be positive")
+	}
+
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+	}, nil
+}
+
+// Allow reports whether a request for key may proceed, consuming a token if so
+func (rl *RateLimiter) Allow(key string) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	bucket, exists := rl.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: rl.burst, lastSeen: now}
+		rl.buckets[key] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.lastSeen).Seconds() * rl.rate
+	if bucket.tokens > rl.burst {
+		bucket.tokens = rl.burst
+	}
+	bucket.lastSeen = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// Cleanup removes buckets idle for longer than maxIdle and returns how many were removed
+func (rl *RateLimiter) Cleanup(maxIdle time.Duration) int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	removed := 0
+	cutoff := time.Now().Add(-maxIdle)
+	for key, bucket := range rl.buckets {
+		if bucket.lastSeen.Before(cutoff) {
+			delete(rl.buckets, key)
+			removed++
+		}
+	}
+	return removed
+}
@@ -0,0 +1,36 @@
+package serving
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurstAndRefill(t *testing.T) {
+	limiter, err := NewRateLimiter(100, 2)
+	if err != nil {
+		t.Fatalf("Failed to create limiter: %v", err)
+	}
+
+	if !limiter.Allow("a") || !limiter.Allow("a") {
+		t.Fatal("Expected burst of 2 to be allowed")
+	}
+	if limiter.Allow("a") {
+		t.Error("Expected third request to be limited")
+	}
+	if !limiter.Allow("b") {
+		t.Error("Keys should be limited independently")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !limiter.Allow("a") {
+		t.Error("Expected tokens to refill over time")
+	}
+
+	if removed := limiter.Cleanup(0); removed != 2 {
+		t.Errorf("Expected 2 idle buckets removed, got %d", removed)
+	}
+
+	if _, err := NewRateLimiter(0, 1); err == nil {
+		t.Error("Expected error for zero rate")
+	}
+}
@@ -0,0 +1,251 @@
+package serving
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Redactor rewrites a request or response payload before it is logged
+type Redactor func(modelID string, payload []byte) []byte
+
+// RequestLogConfig controls optional inference payload logging
+type RequestLogConfig struct {
+	SampleRate      float64       // Fraction of requests to log (0.0-1.0)
+	MaxEntries      int           // Maximum number of retained entries
+	MaxAge          time.Duration // Entries older than this are dropped
+	MaxPayloadBytes int           // Payloads are truncated beyond this size
+	LogInputs       bool          // Whether request inputs are logged
+	LogOutputs      bool          // Whether response outputs are logged
+	Redactors       []Redactor    // Applied in order to every logged payload
+}
+
+// DefaultRequestLogConfig returns a conservative logging configuration
+func DefaultRequestLogConfig() RequestLogConfig {
+	return RequestLogConfig{
+		SampleRate:      0.01,
+		MaxEntries:      1000,
+		MaxAge:          24 * time.Hour,
+		MaxPayloadBytes: 4096,
+		LogInputs:       true,
+		LogOutputs:      true,
+		Redactors:       DefaultPIIRedactors(),
+	}
+}
+
+// RequestLogEntry is a single logged inference exchange
+type RequestLogEntry struct {
+	RequestID string        `json:"request_id"`
+	ModelID   string        `json:"model_id"`
+	TraceID   string        `json:"trace_id,omitempty"`
+	Input     string        `json:"input,omitempty"`
+	Output    string        `json:"output,omitempty"`
+	Truncated bool          `json:"truncated"`
+	CacheHit  bool          `json:"cache_hit"`
+	Latency   time.Duration `json:"latency"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// RequestLogger keeps a bounded, sampled log of inference payloads
+type RequestLogger struct {
+	config  RequestLogConfig
+	entries []RequestLogEntry
+	sampled int64
+	skipped int64
+	mu      sync.RWMutex
+}
+
+// NewRequestLogger creates a new request logger
+func NewRequestLogger(config RequestLogConfig) (*RequestLogger, error) {
+	if config.SampleRate < 0 || config.SampleRate > 1 {
+		return nil, fmt.Errorf("sample rate must be between 0 and 1")
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 1000
+	}
+
+	return &RequestLogger{
+		config:  config,
+		entries: make([]RequestLogEntry, 0),
+	}, nil
+}
+
+// Record logs an inference exchange if the request is sampled
+func (rl *RequestLogger) Record(req *InferenceRequest, resp *InferenceResponse) bool {
+	if req == nil || resp == nil {
+		return false
+	}
+
+	if !rl.shouldSample(req.ID) {
+		rl.mu.Lock()
+		rl.skipped++
+		rl.mu.Unlock()
+		return false
+	}
+
+	entry := RequestLogEntry{
+		RequestID: req.ID,
+		ModelID:   req.ModelID,
+		TraceID:   req.TraceID,
+		CacheHit:  resp.CacheHit,
+		Latency:   resp.Latency,
+		Timestamp: time.Now(),
+	}
+
+	if rl.config.LogInputs {
+		input, truncated := rl.preparePayload(req.ModelID, req.Input)
+		entry.Input = input
+		entry.Truncated = entry.Truncated || truncated
+	}
+	if rl.config.LogOutputs {
+		output, truncated := rl.preparePayload(req.ModelID, resp.Output)
+		entry.Output = output
+		entry.Truncated = entry.Truncated || truncated
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.sampled++
+	rl.entries = append(rl.entries, entry)
+	rl.pruneLocked(entry.Timestamp)
+
+	return true
+}
+
+// shouldSample deterministically samples by request ID so retries are treated consistently
+func (rl *RequestLogger) shouldSample(requestID string) bool {
+	if rl.config.SampleRate <= 0 {
+		return false
+	}
+	if rl.config.SampleRate >= 1 {
+		return true
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(requestID))
+	return float64(h.Sum32())/float64(^uint32(0)) < rl.config.SampleRate
+}
+
+// preparePayload redacts and truncates a payload for storage
+func (rl *RequestLogger) preparePayload(modelID string, payload []byte) (string, bool) {
+	redacted := append([]byte{}, payload...)
+	for _, redactor := range rl.config.Redactors {
+		redacted = redactor(modelID, redacted)
+	}
+
+	if rl.config.MaxPayloadBytes > 0 && len(redacted) > rl.config.MaxPayloadBytes {
+		return string(redacted[:rl.config.MaxPayloadBytes]), true
+	}
+	return string(redacted), false
+}
+
+// pruneLocked enforces count and age retention; caller must hold the lock
+func (rl *RequestLogger) pruneLocked(now time.Time) {
+	if rl.config.MaxAge > 0 {
+		cutoff := now.Add(-rl.config.MaxAge)
+		firstValid := 0
+		for firstValid < len(rl.entries) && rl.entries[firstValid].Timestamp.Before(cutoff) {
+			firstValid++
+		}
+		rl.entries = rl.entries[firstValid:]
+	}
+
+	if len(rl.entries) > rl.config.MaxEntries {
+		rl.entries = rl.entries[len(rl.entries)-rl.config.MaxEntries:]
+	}
+}
+
+// Prune removes entries that exceed the retention limits and returns how many were removed
+func (rl *RequestLogger) Prune() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	before := len(rl.entries)
+	rl.pruneLocked(time.Now())
+	return before - len(rl.entries)
+}
+
+// GetEntries returns logged entries for a model (all models if empty) since a point in time
+func (rl *RequestLogger) GetEntries(modelID string, since time.Time) []RequestLogEntry {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	result := make([]RequestLogEntry, 0)
+	for _, entry := range rl.entries {
+		if entry.Timestamp.Before(since) {
+			continue
+		}
+		if modelID == "" || entry.ModelID == modelID {
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
+
+// GetEntriesByTrace returns logged entries linked to a serving trace
+func (rl *RequestLogger) GetEntriesByTrace(traceID string) []RequestLogEntry {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	result := make([]RequestLogEntry, 0)
+	for _, entry := range rl.entries {
+		if traceID != "" && entry.TraceID == traceID {
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
+
+// GetStats returns request logging statistics
+func (rl *RequestLogger) GetStats() map[string]interface{} {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	return map[string]interface{}{
+		"retained_entries": len(rl.entries),
+		"sampled_total":    rl.sampled,
+		"skipped_total":    rl.skipped,
+		"sample_rate":      rl.config.SampleRate,
+		"max_entries":      rl.config.MaxEntries,
+		"max_age_sec":      rl.config.MaxAge.Seconds(),
+	}
+}
+
+// NewRegexRedactor creates a redactor replacing every match of pattern with replacement
+func NewRegexRedactor(pattern, replacement string) (Redactor, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redaction pattern: %w", err)
+	}
+
+	return func(modelID string, payload []byte) []byte {
+		return re.ReplaceAll(payload, []byte(replacement))
+	}, nil
+}
+
+// DefaultPIIRedactors returns redactors for common PII (emails, phone and card numbers)
+func DefaultPIIRedactors() []Redactor {
+	patterns := []struct {
+		pattern     string
+		replacement string
+	}{
+		{`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`, "[REDACTED_EMAIL]"},
+		{`\b(?:\d[ \-]?){13,16}\b`, "[REDACTED_CARD]"},
+		{`(?:\+?\d{1,3}[ .\-]?)?\(?\d{3}\)?[ .\-]?\d{3}[ .\-]\d{4}\b`, "[REDACTED_PHONE]"},
+	}
+
+	redactors := make([]Redactor, 0, len(patterns))
+	for _, p := range patterns {
+		redactor, err := NewRegexRedactor(p.pattern, p.replacement)
+		if err == nil {
+			redactors = append(redactors, redactor)
+		}
+	}
+
+	return redactors
+}
@@ -0,0 +1,81 @@
+package serving
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestLoggingRedactsPII(t *testing.T) {
+	manager := NewServingManager(nil, 5*time.Minute)
+	manager.RegisterModel(&Model{ID: "chat", Name: "Chat Model"})
+
+	config := DefaultRequestLogConfig()
+	config.SampleRate = 1.0
+	if err := manager.EnableRequestLogging(config); err != nil {
+		t.Fatalf("Failed to enable request logging: %v", err)
+	}
+
+	req := &InferenceRequest{
+		ID:      "req-1",
+		ModelID: "chat",
+		Input:   []byte("contact me at jane.doe@example.com or 555-123-4567"),
+		TraceID: "trace-abc",
+	}
+	if _, err := manager.SubmitInferenceRequest(req); err != nil {
+		t.Fatalf("Failed to submit request: %v", err)
+	}
+
+	entries := manager.GetRequestLogger().GetEntriesByTrace("trace-abc")
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry linked to trace, got %d", len(entries))
+	}
+
+	input := entries[0].Input
+	if strings.Contains(input, "jane.doe@example.com") {
+		t.Errorf("Email was not redacted: %s", input)
+	}
+	if strings.Contains(input, "555-123-4567") {
+		t.Errorf("Phone number was not redacted: %s", input)
+	}
+	if !strings.Contains(input, "[REDACTED_EMAIL]") {
+		t.Errorf("Expected email placeholder in %s", input)
+	}
+}
+
+func TestRequestLoggingSamplingAndRetention(t *testing.T) {
+	logger, err := NewRequestLogger(RequestLogConfig{
+		SampleRate:      1.0,
+		MaxEntries:      3,
+		MaxPayloadBytes: 4,
+		LogInputs:       true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		req := &InferenceRequest{ID: string(rune('a' + i)), ModelID: "m", Input: []byte("long payload")}
+		logger.Record(req, &InferenceResponse{RequestID: req.ID})
+	}
+
+	entries := logger.GetEntries("", time.Time{})
+	if len(entries) != 3 {
+		t.Fatalf("Expected retention to keep 3 entries, got %d", len(entries))
+	}
+	if entries[0].RequestID != "c" {
+		t.Errorf("Expected oldest retained entry to be c, got %s", entries[0].RequestID)
+	}
+	if !entries[0].Truncated || entries[0].Input != "long" {
+		t.Errorf("Expected truncated payload 'long', got %q", entries[0].Input)
+	}
+
+	disabled, _ := NewRequestLogger(RequestLogConfig{SampleRate: 0})
+	if disabled.Record(&InferenceRequest{ID: "x"}, &InferenceResponse{}) {
+		t.Error("Request should not be sampled at rate 0")
+	}
+
+	if _, err := NewRequestLogger(RequestLogConfig{SampleRate: 1.5}); err == nil {
+		t.Error("Expected error for sample rate above 1")
+	}
+}
@@ -13,6 +13,10 @@ const (
 	RouteRoundRobin   RoutingStrategy = "round_robin"
 	RouteLeastLatency RoutingStrategy = "least_latency"
 	RouteLeastLoad    RoutingStrategy = "least_load"
+	// RouteSessionAffinity pins each session to a consistent instance (see RouteRequestWithSession)
+	RouteSessionAffinity RoutingStrategy = "session_affinity"
+	// RouteKVCacheAware prefers instances already holding the prompt prefix (see RouteRequestWithPrompt)
+	RouteKVCacheAware RoutingStrategy = "kv_cache_aware"
 )
 
 // ModelInstance represents a running instance of a model
@@ -31,13 +35,34 @@ type Router struct {
 	instances map[string][]*ModelInstance
 	strategy  RoutingStrategy
 	mu        sync.RWMutex
+
+	// Session affinity state
+	sessions          map[string]*sessionBinding
+	sessionTTL        time.Duration
+	sessionRebalances int64
+	sessionSpillover  bool
+	sessionSpillovers int64
+	cleanupStop       chan struct{}
+	cleanupDone       chan struct{}
+
+	// KV cache state reported by backends
+	kvStates        map[string]*KVCacheState
+	kvStateTTL      time.Duration
+	kvHighWatermark float64
+	kvStats         kvRoutingStats
 }
 
 // NewRouter creates a new request router
 func NewRouter(strategy RoutingStrategy) *Router {
 	return &Router{
-		instances: make(map[string][]*ModelInstance),
-		strategy:  strategy,
+		instances:  make(map[string][]*ModelInstance),
+		strategy:   strategy,
+		sessions:   make(map[string]*sessionBinding),
+		sessionTTL: 30 * time.Minute,
+
+		kvStates:        make(map[string]*KVCacheState),
+		kvStateTTL:      30 * time.Second,
+		kvHighWatermark: 0.95,
 	}
 }
 
@@ -66,7 +91,7 @@ func (r *Router) RouteRequest(modelID string) (*ModelInstance, error) {
 	switch r.strategy {
 	case RouteLeastLatency:
 		return r.routeByLatency(instances)
-	case RouteLeastLoad:
+	case RouteLeastLoad, RouteSessionAffinity, RouteKVCacheAware:
 		return r.routeByLoad(instances)
 	case RouteRoundRobin:
 		fallthrough
@@ -146,9 +171,15 @@ func (r *Router) GetRoutingMetrics() map[string]interface{} {
 	}
 
 	return map[string]interface{}{
-		"total_instances":     totalInstances,
-		"available_instances": availableInstances,
-		"routing_strategy":    string(r.strategy),
-		"models_registered":   len(r.instances),
+		"total_instances":      totalInstances,
+		"available_instances":  availableInstances,
+		"routing_strategy":     string(r.strategy),
+		"models_registered":    len(r.instances),
+		"session_bindings":     len(r.sessions),
+		"session_rebalances":   r.sessionRebalances,
+		"session_spillovers":   r.sessionSpillovers,
+		"prefix_cache_hits":    r.kvStats.prefixHits,
+		"prefix_cache_misses":  r.kvStats.prefixMisses,
+		"matched_prefix_bytes": r.kvStats.matchedPrefixBytes,
 	}
 }
@@ -238,3 +238,47 @@ func TestServingMetrics(t *testing.T) {
 		t.Errorf("Expected max_wait_time_ms 50, got %v", metrics["max_wait_time_ms"])
 	}
 }
+
+func TestModelsFromWorkload(t *testing.T) {
+	sm := NewServingManager(&BatchConfig{MaxBatchSize: 8, MaxWaitTime: time.Millisecond}, time.Minute)
+	sm.RegisterModel(&Model{ID: "ft-v2", Name: "llama-ft", SourceWorkload: "train-7", SourceArtifact: "s3://runs/train-7/model"})
+	sm.RegisterModel(&Model{ID: "ft-v1", Name: "llama-ft", SourceWorkload: "train-7"})
+	sm.RegisterModel(&Model{ID: "base", Name: "llama"})
+
+	models := sm.ModelsFromWorkload("train-7")
+	if len(models) != 2 || models[0].ID != "ft-v1" || models[1].SourceArtifact != "s3://runs/train-7/model" {
+		t.Errorf("models = %+v, want both models trained by train-7", models)
+	}
+	if models := sm.ModelsFromWorkload("train-8"); len(models) != 0 {
+		t.Errorf("models = %+v, want none", models)
+	}
+}
+
+func TestModelsForTenant(t *testing.T) {
+	sm := NewServingManager(&BatchConfig{MaxBatchSize: 8, MaxWaitTime: time.Millisecond}, time.Minute)
+	sm.RegisterModel(&Model{ID: "detector", Name: "yolo", Tenant: "vision"})
+	sm.RegisterModel(&Model{ID: "chat", Name: "llama"})
+
+	if models := sm.ModelsForTenant("vision"); len(models) != 1 || models[0].ID != "detector" {
+		t.Errorf("vision models = %+v", models)
+	}
+	if models := sm.ModelsForTenant(DefaultTenant); len(models) != 1 || models[0].ID != "chat" {
+		t.Errorf("default models = %+v, want models registered without a tenant", models)
+	}
+}
+
+func TestUnregisterModel(t *testing.T) {
+	sm := NewServingManager(&BatchConfig{MaxBatchSize: 8, MaxWaitTime: time.Millisecond}, time.Minute)
+	sm.RegisterModel(&Model{ID: "b", Name: "llama"})
+	sm.RegisterModel(&Model{ID: "a", Name: "yolo"})
+
+	if models := sm.Models(); len(models) != 2 || models[0].ID != "a" {
+		t.Errorf("models = %+v, want both ordered by ID", models)
+	}
+	if !sm.UnregisterModel("a") || sm.UnregisterModel("a") {
+		t.Error("Expected only the first unregister to report the model")
+	}
+	if _, exists := sm.GetModel("a"); exists {
+		t.Error("Expected the model to be gone")
+	}
+}
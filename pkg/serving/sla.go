@@ -0,0 +1,446 @@
+package serving
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SLAStatus represents how a model is tracking against its SLA
+type SLAStatus string
+
+const (
+	SLAStatusOK       SLAStatus = "ok"
+	SLAStatusAtRisk   SLAStatus = "at_risk"
+	SLAStatusViolated SLAStatus = "violated"
+)
+
+// ErrInferenceTimeout marks a request that ran longer than its model SLA's timeout
+var ErrInferenceTimeout = errors.New("inference timed out")
+
+// ModelSLA defines the service level a model is expected to meet
+type ModelSLA struct {
+	MaxP95Latency   time.Duration // Maximum acceptable p95 latency
+	MinAvailability float64       // Minimum fraction of successful requests (0.0-1.0)
+	Tier            int           // Traffic tier; lower values are more important
+	Window          time.Duration // Evaluation window for samples
+	RiskMargin      float64       // Fraction of the limit at which the SLA is considered at risk
+	Timeout         time.Duration // Requests running longer than this fail as timed out; 0 disables
+}
+
+// SLAEvent describes an SLA state change or an automatic mitigation decision
+type SLAEvent struct {
+	ModelID      string    `json:"model_id"`
+	Type         string    `json:"type"` // sla_at_risk, sla_violated, sla_recovered, capacity_boost, traffic_shed, traffic_restored
+	Message      string    `json:"message"`
+	Status       SLAStatus `json:"status"`
+	P95Latency   float64   `json:"p95_latency_ms"`
+	Availability float64   `json:"availability"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// SLAReport is the current evaluation of a model against its SLA
+type SLAReport struct {
+	ModelID       string    `json:"model_id"`
+	Status        SLAStatus `json:"status"`
+	P95Latency    float64   `json:"p95_latency_ms"`
+	Availability  float64   `json:"availability"`
+	SampleCount   int       `json:"sample_count"`
+	Failures      int       `json:"failures"` // Failed requests in the window, timeouts included
+	Timeouts      int       `json:"timeouts"`
+	CapacityShare float64   `json:"capacity_share"`
+	Shed          bool      `json:"shed"`
+}
+
+type slaSample struct {
+	latency   time.Duration
+	success   bool
+	timedOut  bool
+	timestamp time.Time
+}
+
+// SLAManager tracks per-model SLAs and applies automatic mitigations
+type SLAManager struct {
+	slas           map[string]ModelSLA
+	samples        map[string][]slaSample
+	status         map[string]SLAStatus
+	capacityShares map[string]float64
+	shedTier       int // Models with a tier above this value are shed; 0 disables shedding
+	events         []SLAEvent
+	handlers       []func(SLAEvent)
+	maxEvents      int
+	maxBoost       float64
+	mu             sync.RWMutex
+}
+
+// NewSLAManager creates a new SLA manager
+func NewSLAManager() *SLAManager {
+	return &SLAManager{
+		slas:           make(map[string]ModelSLA),
+		samples:        make(map[string][]slaSample),
+		status:         make(map[string]SLAStatus),
+		capacityShares: make(map[string]float64),
+		events:         make([]SLAEvent, 0),
+		handlers:       make([]func(SLAEvent), 0),
+		maxEvents:      1000,
+		maxBoost:       4.0,
+	}
+}
+
+// SetSLA attaches an SLA to a model
+func (m *SLAManager) SetSLA(modelID string, sla ModelSLA) error {
+	if modelID == "" {
+		return fmt.Errorf("model ID cannot be empty")
+	}
+	if sla.MinAvailability < 0 || sla.MinAvailability > 1 {
+		return fmt.Errorf("min availability must be between 0 and 1")
+	}
+	if sla.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	if sla.Window <= 0 {
+		sla.Window = 5 * time.Minute
+	}
+	if sla.RiskMargin <= 0 || sla.RiskMargin > 1 {
+		sla.RiskMargin = 0.8
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.slas[modelID] = sla
+	m.status[modelID] = SLAStatusOK
+	m.capacityShares[modelID] = 1.0
+	return nil
+}
+
+// RemoveSLA detaches the SLA from a model
+func (m *SLAManager) RemoveSLA(modelID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.slas, modelID)
+	delete(m.samples, modelID)
+	delete(m.status, modelID)
+	delete(m.capacityShares, modelID)
+}
+
+// OnEvent registers a handler called for every SLA event
+func (m *SLAManager) OnEvent(handler func(SLAEvent)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers = append(m.handlers, handler)
+}
+
+// RecordOutcome records the latency and success of a request for a model
+func (m *SLAManager) RecordOutcome(modelID string, latency time.Duration, success bool) {
+	m.record(modelID, slaSample{latency: latency, success: success})
+}
+
+// RecordTimeout records a request for a model that failed by running past its timeout
+func (m *SLAManager) RecordTimeout(modelID string, latency time.Duration) {
+	m.record(modelID, slaSample{latency: latency, timedOut: true})
+}
+
+// record adds a sample to a model's evaluation window
+func (m *SLAManager) record(modelID string, sample slaSample) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sla, exists := m.slas[modelID]
+	if !exists {
+		return
+	}
+
+	now := time.Now()
+	sample.timestamp = now
+	samples := append(m.samples[modelID], sample)
+
+	// Drop samples outside the evaluation window
+	cutoff := now.Add(-sla.Window)
+	firstValid := 0
+	for firstValid < len(samples) && samples[firstValid].timestamp.Before(cutoff) {
+		firstValid++
+	}
+	m.samples[modelID] = samples[firstValid:]
+}
+
+// Timeout returns how long a model's requests may run before failing, 0 for no limit
+func (m *SLAManager) Timeout(modelID string) time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.slas[modelID].Timeout
+}
+
+// IsShed reports whether traffic for a model is currently being shed
+func (m *SLAManager) IsShed(modelID string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.isShedLocked(modelID)
+}
+
+func (m *SLAManager) isShedLocked(modelID string) bool {
+	if m.shedTier <= 0 {
+		return false
+	}
+	sla, exists := m.slas[modelID]
+	return exists && sla.Tier > m.shedTier
+}
+
+// CapacityShare returns the relative batch/queue capacity share of a model (1.0 is the baseline)
+func (m *SLAManager) CapacityShare(modelID string) float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if share, exists := m.capacityShares[modelID]; exists {
+		return share
+	}
+	return 1.0
+}
+
+// QueueQuota returns how many queued requests a model may hold out of a
+// queue of depth, in proportion to its capacity share among the models with
+// queued requests. A model alone in the queue may fill it; a boosted model
+// keeps its share however much other models have queued.
+func (m *SLAManager) QueueQuota(modelID string, depth int, queued map[string]int) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	share := func(id string) float64 {
+		if share, exists := m.capacityShares[id]; exists {
+			return share
+		}
+		return 1.0
+	}
+
+	total := share(modelID)
+	for id, count := range queued {
+		if id != modelID && count > 0 {
+			total += share(id)
+		}
+	}
+	quota := int(float64(depth) * share(modelID) / total)
+	if quota < 1 {
+		quota = 1
+	}
+	return quota
+}
+
+// Evaluate checks every SLA, applies mitigations and returns the current reports
+func (m *SLAManager) Evaluate() []SLAReport {
+	m.mu.Lock()
+
+	now := time.Now()
+	pending := make([]SLAEvent, 0)
+	emit := func(event SLAEvent) {
+		event.Timestamp = now
+		pending = append(pending, event)
+	}
+
+	modelIDs := make([]string, 0, len(m.slas))
+	for modelID := range m.slas {
+		modelIDs = append(modelIDs, modelID)
+	}
+	sort.Strings(modelIDs)
+
+	reports := make([]SLAReport, 0, len(modelIDs))
+	mostImportantAtRisk := 0
+
+	for _, modelID := range modelIDs {
+		sla := m.slas[modelID]
+		p95, availability, count := m.computeStatsLocked(modelID)
+		status := classifySLA(sla, p95, availability, count)
+
+		previous := m.status[modelID]
+		m.status[modelID] = status
+
+		event := SLAEvent{
+			ModelID:      modelID,
+			Status:       status,
+			P95Latency:   float64(p95.Milliseconds()),
+			Availability: availability,
+		}
+
+		if status != previous {
+			switch status {
+			case SLAStatusAtRisk:
+				event.Type = "sla_at_risk"
+				event.Message = fmt.Sprintf("Model %s is at risk of missing its SLA (p95 %v, availability %.3f)", modelID, p95, availability)
+			case SLAStatusViolated:
+				event.Type = "sla_violated"
+				event.Message = fmt.Sprintf("Model %s is violating its SLA (p95 %v, availability %.3f)", modelID, p95, availability)
+			case SLAStatusOK:
+				event.Type = "sla_recovered"
+				event.Message = fmt.Sprintf("Model %s is meeting its SLA again", modelID)
+			}
+			emit(event)
+		}
+
+		// Raise the capacity share of models that are struggling, decay it once they recover
+		share := m.capacityShares[modelID]
+		if status != SLAStatusOK {
+			if share < m.maxBoost {
+				share = share * 2
+				if share > m.maxBoost {
+					share = m.maxBoost
+				}
+				boost := event
+				boost.Type = "capacity_boost"
+				boost.Message = fmt.Sprintf("Raised capacity share of model %s to %.1fx", modelID, share)
+				emit(boost)
+			}
+			if mostImportantAtRisk == 0 || sla.Tier < mostImportantAtRisk {
+				mostImportantAtRisk = sla.Tier
+			}
+		} else if share > 1.0 {
+			share = share / 2
+			if share < 1.0 {
+				share = 1.0
+			}
+		}
+		m.capacityShares[modelID] = share
+	}
+
+	// Shed traffic from tiers below the most important model at risk
+	if mostImportantAtRisk != m.shedTier {
+		previousShedTier := m.shedTier
+		m.shedTier = mostImportantAtRisk
+		for _, modelID := range modelIDs {
+			wasShed := previousShedTier > 0 && m.slas[modelID].Tier > previousShedTier
+			isShed := m.isShedLocked(modelID)
+			if isShed && !wasShed {
+				emit(SLAEvent{
+					ModelID: modelID,
+					Type:    "traffic_shed",
+					Status:  m.status[modelID],
+					Message: fmt.Sprintf("Shedding traffic for model %s (tier %d) to protect tier %d", modelID, m.slas[modelID].Tier, m.shedTier),
+				})
+			} else if wasShed && !isShed {
+				emit(SLAEvent{
+					ModelID: modelID,
+					Type:    "traffic_restored",
+					Status:  m.status[modelID],
+					Message: fmt.Sprintf("Restored traffic for model %s", modelID),
+				})
+			}
+		}
+	}
+
+	for _, modelID := range modelIDs {
+		p95, availability, count := m.computeStatsLocked(modelID)
+		failures, timeouts := m.countFailuresLocked(modelID)
+		reports = append(reports, SLAReport{
+			ModelID:       modelID,
+			Status:        m.status[modelID],
+			P95Latency:    float64(p95.Milliseconds()),
+			Availability:  availability,
+			SampleCount:   count,
+			Failures:      failures,
+			Timeouts:      timeouts,
+			CapacityShare: m.capacityShares[modelID],
+			Shed:          m.isShedLocked(modelID),
+		})
+	}
+
+	m.events = append(m.events, pending...)
+	if len(m.events) > m.maxEvents {
+		m.events = m.events[len(m.events)-m.maxEvents:]
+	}
+	handlers := append([]func(SLAEvent){}, m.handlers...)
+	m.mu.Unlock()
+
+	for _, event := range pending {
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+
+	return reports
+}
+
+// GetEvents returns the recorded SLA events since a point in time
+func (m *SLAManager) GetEvents(since time.Time) []SLAEvent {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]SLAEvent, 0)
+	for _, event := range m.events {
+		if event.Timestamp.After(since) {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+// computeStatsLocked returns p95 latency, availability and sample count; caller must hold the lock
+func (m *SLAManager) computeStatsLocked(modelID string) (time.Duration, float64, int) {
+	samples := m.samples[modelID]
+	if len(samples) == 0 {
+		return 0, 1.0, 0
+	}
+
+	latencies := make([]time.Duration, 0, len(samples))
+	successes := 0
+	for _, sample := range samples {
+		latencies = append(latencies, sample.latency)
+		if sample.success {
+			successes++
+		}
+	}
+
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+
+	index := int(float64(len(latencies))*0.95+0.5) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(latencies) {
+		index = len(latencies) - 1
+	}
+
+	return latencies[index], float64(successes) / float64(len(samples)), len(samples)
+}
+
+// countFailuresLocked returns the failed and timed out requests in a model's window; caller must hold the lock
+func (m *SLAManager) countFailuresLocked(modelID string) (int, int) {
+	failures, timeouts := 0, 0
+	for _, sample := range m.samples[modelID] {
+		if !sample.success {
+			failures++
+		}
+		if sample.timedOut {
+			timeouts++
+		}
+	}
+	return failures, timeouts
+}
+
+// classifySLA determines the SLA status for the given statistics
+func classifySLA(sla ModelSLA, p95 time.Duration, availability float64, count int) SLAStatus {
+	if count == 0 {
+		return SLAStatusOK
+	}
+
+	if (sla.MaxP95Latency > 0 && p95 > sla.MaxP95Latency) ||
+		(sla.MinAvailability > 0 && availability < sla.MinAvailability) {
+		return SLAStatusViolated
+	}
+
+	if sla.MaxP95Latency > 0 && float64(p95) > float64(sla.MaxP95Latency)*sla.RiskMargin {
+		return SLAStatusAtRisk
+	}
+
+	// Availability is at risk once the error budget is mostly consumed
+	if sla.MinAvailability > 0 && sla.MinAvailability < 1 {
+		errorBudget := 1 - sla.MinAvailability
+		if 1-availability > errorBudget*sla.RiskMargin {
+			return SLAStatusAtRisk
+		}
+	}
+
+	return SLAStatusOK
+}
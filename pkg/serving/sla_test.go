@@ -0,0 +1,229 @@
+package serving
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSLAAtRiskBoostsCapacityAndShedsLowerTiers(t *testing.T) {
+	manager := NewServingManager(nil, 5*time.Minute)
+	manager.RegisterModel(&Model{ID: "critical", Name: "Critical Model"})
+	manager.RegisterModel(&Model{ID: "batch", Name: "Batch Model"})
+
+	if err := manager.SetModelSLA("critical", ModelSLA{MaxP95Latency: 100 * time.Millisecond, MinAvailability: 0.99, Tier: 1}); err != nil {
+		t.Fatalf("Failed to set SLA: %v", err)
+	}
+	if err := manager.SetModelSLA("batch", ModelSLA{MaxP95Latency: time.Second, Tier: 3}); err != nil {
+		t.Fatalf("Failed to set SLA: %v", err)
+	}
+	if err := manager.SetModelSLA("missing", ModelSLA{}); err == nil {
+		t.Error("Expected error for unregistered model")
+	}
+
+	events := make([]SLAEvent, 0)
+	manager.GetSLAManager().OnEvent(func(e SLAEvent) {
+		events = append(events, e)
+	})
+
+	for i := 0; i < 20; i++ {
+		manager.RecordInferenceOutcome("critical", 150*time.Millisecond, true)
+	}
+
+	reports := manager.EvaluateSLAs()
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 reports, got %d", len(reports))
+	}
+
+	var critical SLAReport
+	for _, r := range reports {
+		if r.ModelID == "critical" {
+			critical = r
+		}
+	}
+	if critical.Status != SLAStatusViolated {
+		t.Errorf("Expected critical model to violate SLA, got %s", critical.Status)
+	}
+	if critical.CapacityShare <= 1.0 {
+		t.Errorf("Expected capacity share to be raised, got %.1f", critical.CapacityShare)
+	}
+
+	if _, err := manager.SubmitInferenceRequest(&InferenceRequest{ID: "b-1", ModelID: "batch", Input: []byte("x")}); err == nil {
+		t.Error("Expected lower-tier traffic to be shed")
+	}
+	if _, err := manager.SubmitInferenceRequest(&InferenceRequest{ID: "c-1", ModelID: "critical", Input: []byte("x")}); err != nil {
+		t.Errorf("Critical traffic should not be shed: %v", err)
+	}
+
+	types := make(map[string]bool)
+	for _, e := range events {
+		types[e.Type] = true
+	}
+	for _, expected := range []string{"sla_violated", "capacity_boost", "traffic_shed"} {
+		if !types[expected] {
+			t.Errorf("Expected %s event to be logged", expected)
+		}
+	}
+	if len(manager.GetSLAManager().GetEvents(time.Time{})) != len(events) {
+		t.Error("Expected recorded events to match delivered events")
+	}
+}
+
+func TestSLARecoveryRestoresTraffic(t *testing.T) {
+	sla := NewSLAManager()
+	sla.SetSLA("critical", ModelSLA{MinAvailability: 0.9, Tier: 1, Window: 50 * time.Millisecond})
+	sla.SetSLA("batch", ModelSLA{Tier: 2})
+
+	for i := 0; i < 5; i++ {
+		sla.RecordOutcome("critical", 10*time.Millisecond, false)
+	}
+	sla.Evaluate()
+	if !sla.IsShed("batch") {
+		t.Fatal("Expected batch traffic to be shed while critical SLA is violated")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		sla.RecordOutcome("critical", 10*time.Millisecond, true)
+	}
+	reports := sla.Evaluate()
+	if sla.IsShed("batch") {
+		t.Error("Expected batch traffic to be restored after recovery")
+	}
+	for _, r := range reports {
+		if r.ModelID == "critical" && r.Status != SLAStatusOK {
+			t.Errorf("Expected critical model to recover, got %s", r.Status)
+		}
+	}
+}
+
+func TestProcessBatchPrefersBoostedModels(t *testing.T) {
+	manager := NewServingManager(&BatchConfig{MaxBatchSize: 1, MinBatchSize: 1}, time.Minute)
+	manager.RegisterModel(&Model{ID: "a", Name: "A"})
+	manager.RegisterModel(&Model{ID: "b", Name: "B"})
+	manager.SetModelSLA("b", ModelSLA{MaxP95Latency: 10 * time.Millisecond, Tier: 1})
+
+	manager.RecordInferenceOutcome("b", 50*time.Millisecond, true)
+	manager.EvaluateSLAs()
+
+	// Served requests never enter the batch queue, so queue these directly
+	manager.mu.Lock()
+	manager.requestQueue = append(manager.requestQueue,
+		&InferenceRequest{ID: "a-1", ModelID: "a", Input: []byte("1")},
+		&InferenceRequest{ID: "b-1", ModelID: "b", Input: []byte("1")})
+	manager.mu.Unlock()
+
+	responses, err := manager.ProcessBatch()
+	if err != nil {
+		t.Fatalf("ProcessBatch failed: %v", err)
+	}
+	if len(responses) != 1 || responses[0].RequestID != "b-1" {
+		t.Errorf("Expected boosted model request to be served first, got %+v", responses)
+	}
+}
+
+func TestSLAAvailabilityCountsFailuresAndTimeouts(t *testing.T) {
+	manager := NewServingManager(nil, time.Minute)
+	manager.RegisterModel(&Model{ID: "m", Name: "M"})
+	manager.SetModelSLA("m", ModelSLA{MinAvailability: 0.9, Tier: 1, Timeout: 20 * time.Millisecond})
+
+	release := make(chan struct{})
+	defer close(release)
+	manager.SetInferenceBackend(func(req *InferenceRequest) ([]byte, error) {
+		switch string(req.Input) {
+		case "slow":
+			<-release
+		case "fail":
+			return nil, errors.New("backend unavailable")
+		}
+		return []byte("ok"), nil
+	})
+
+	if _, err := manager.SubmitInferenceRequest(&InferenceRequest{ID: "1", ModelID: "m", Input: []byte("fast")}); err != nil {
+		t.Fatalf("Expected the request to succeed: %v", err)
+	}
+	if _, err := manager.SubmitInferenceRequest(&InferenceRequest{ID: "2", ModelID: "m", Input: []byte("slow")}); !errors.Is(err, ErrInferenceTimeout) {
+		t.Errorf("Expected the slow request to time out, got %v", err)
+	}
+	if _, err := manager.SubmitInferenceRequest(&InferenceRequest{ID: "3", ModelID: "m", Input: []byte("fail")}); err == nil {
+		t.Error("Expected the failing request to fail")
+	}
+
+	reports := manager.EvaluateSLAs()
+	if len(reports) != 1 || reports[0].SampleCount != 3 || reports[0].Failures != 2 || reports[0].Timeouts != 1 {
+		t.Fatalf("Expected 2 failures including 1 timeout, got %+v", reports)
+	}
+	if reports[0].Availability > 0.34 || reports[0].Status != SLAStatusViolated {
+		t.Errorf("Expected failures to violate the availability SLA, got %+v", reports[0])
+	}
+}
+
+func TestQueueAdmissionEnforcesCapacityShare(t *testing.T) {
+	manager := NewServingManager(&BatchConfig{MaxBatchSize: 8, MinBatchSize: 1, MaxQueueDepth: 4}, time.Minute)
+	manager.RegisterModel(&Model{ID: "critical", Name: "Critical"})
+	manager.RegisterModel(&Model{ID: "bulk", Name: "Bulk"})
+	manager.SetModelSLA("critical", ModelSLA{MaxP95Latency: 10 * time.Millisecond, Tier: 1})
+
+	// Requests served one after another never wait, so they are never rejected
+	for i := 0; i < 10; i++ {
+		if _, err := manager.SubmitInferenceRequest(&InferenceRequest{ID: fmt.Sprintf("seq-%d", i), ModelID: "bulk", Input: []byte(fmt.Sprint("seq", i))}); err != nil {
+			t.Fatalf("Expected sequential request %d to be admitted: %v", i, err)
+		}
+	}
+	if responses, _ := manager.ProcessBatch(); len(responses) != 0 {
+		t.Fatalf("Expected served requests to stay out of the batch queue, got %d", len(responses))
+	}
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	manager.SetInferenceBackend(func(req *InferenceRequest) ([]byte, error) {
+		started <- struct{}{}
+		<-unblock
+		return []byte("ok"), nil
+	})
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer close(unblock)
+
+	// submit returns once the request is running on the backend or rejected
+	submit := func(modelID string, i int) error {
+		rejected := make(chan error, 1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := manager.SubmitInferenceRequest(&InferenceRequest{ID: fmt.Sprintf("%s-%d", modelID, i), ModelID: modelID, Input: []byte(fmt.Sprint(i))})
+			rejected <- err
+		}()
+		select {
+		case <-started:
+			return nil
+		case err := <-rejected:
+			return err
+		}
+	}
+
+	// A model alone in the queue may fill it
+	for i := 0; i < 4; i++ {
+		if err := submit("bulk", i); err != nil {
+			t.Fatalf("Expected bulk request %d to be admitted: %v", i, err)
+		}
+	}
+	if err := submit("bulk", 4); err == nil {
+		t.Error("Expected the full queue to reject bulk traffic")
+	}
+
+	// A boosted model is guaranteed its share of the queue however full it is
+	manager.RecordInferenceOutcome("critical", 50*time.Millisecond, true)
+	manager.EvaluateSLAs()
+	admitted := 0
+	for i := 0; i < 4; i++ {
+		if submit("critical", i) == nil {
+			admitted++
+		}
+	}
+	if share := manager.GetSLAManager().CapacityShare("critical"); admitted != int(4*share/(share+1)) {
+		t.Errorf("Expected critical admitted up to its %.0fx share, got %d", share, admitted)
+	}
+}
@@ -0,0 +1,55 @@
+terraform {
+  required_providers {
+    agentaflow = {
+      source = "finoptimize/agentaflow"
+    }
+  }
+}
+
+# Token from AGENTAFLOW_TOKEN: a control token or an API key with the admin scope
+provider "agentaflow" {
+  endpoint = "http://localhost:8080"
+}
+
+resource "agentaflow_alert_rule" "h100" {
+  name                 = "h100"
+  pattern              = "(?i)h100"
+  high_temperature     = 80
+  critical_temperature = 90
+  clear_temperature    = 75
+  for                  = "2m"
+}
+
+resource "agentaflow_pool" "research" {
+  name     = "research"
+  strategy = "best_fit"
+  max_gpus = 8
+}
+
+resource "agentaflow_budget" "search" {
+  name             = "search"
+  team             = "search"
+  monthly_limit    = 25000
+  alert_at_percent = 75
+}
+
+resource "agentaflow_model" "chat" {
+  model_id        = "chat-v3"
+  name            = "llama"
+  version         = "3"
+  framework       = "pytorch"
+  memory_size_mb  = 16000
+  tenant          = "search"
+  source_artifact = "s3://runs/train-42/model"
+}
+
+resource "agentaflow_api_key" "ci" {
+  name   = "ci"
+  scopes = ["submit-workloads"]
+  tenant = "search"
+}
+
+output "ci_api_key" {
+  value     = agentaflow_api_key.ci.secret
+  sensitive = true
+}
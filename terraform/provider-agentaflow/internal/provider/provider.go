@@ -0,0 +1,138 @@
+// Package provider implements the AgentaFlow Terraform provider on top of the
+// Go client of the dashboard API
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/client"
+)
+
+// New returns a function creating the provider, as plugin.Serve expects
+func New(version string) func() *schema.Provider {
+	return func() *schema.Provider {
+		p := &schema.Provider{
+			Schema: map[string]*schema.Schema{
+				"endpoint": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("AGENTAFLOW_ENDPOINT", "http://localhost:8080"),
+					Description: "Base URL of the AgentaFlow dashboard. May also be set with AGENTAFLOW_ENDPOINT.",
+				},
+				"token": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					DefaultFunc: schema.EnvDefaultFunc("AGENTAFLOW_TOKEN", nil),
+					Description: "Control token or admin API key. May also be set with AGENTAFLOW_TOKEN.",
+				},
+				"timeout": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "30s",
+					Description: "Timeout of each API request, e.g. 30s.",
+				},
+				"max_retries": {
+					Type:        schema.TypeInt,
+					Optional:    true,
+					Default:     3,
+					Description: "Retries of requests the dashboard turned away or that failed to connect.",
+				},
+			},
+			ResourcesMap: map[string]*schema.Resource{
+				"agentaflow_alert_rule": resourceAlertRule(),
+				"agentaflow_pool":       resourcePool(),
+				"agentaflow_budget":     resourceBudget(),
+				"agentaflow_model":      resourceModel(),
+				"agentaflow_api_key":    resourceAPIKey(),
+			},
+		}
+		p.ConfigureContextFunc = configure(version)
+		return p
+	}
+}
+
+// configure creates the API client resources are managed through
+func configure(version string) schema.ConfigureContextFunc {
+	return func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+		timeout, err := time.ParseDuration(d.Get("timeout").(string))
+		if err != nil {
+			return nil, diag.Errorf("invalid timeout: %v", err)
+		}
+
+		config := client.DefaultConfig(d.Get("endpoint").(string))
+		config.Token = d.Get("token").(string)
+		config.Timeout = timeout
+		config.MaxRetries = d.Get("max_retries").(int)
+		config.UserAgent = "terraform-provider-agentaflow/" + version
+
+		c, err := client.New(config)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		return c, nil
+	}
+}
+
+// notFound clears a resource deleted outside Terraform from state so the
+// next plan recreates it, and reports any other read error
+func notFound(d *schema.ResourceData, err error) diag.Diagnostics {
+	if client.IsNotFound(err) {
+		d.SetId("")
+		return nil
+	}
+	return diag.FromErr(err)
+}
+
+// ignoreNotFound reports a delete error unless the resource is already gone
+func ignoreNotFound(err error) diag.Diagnostics {
+	if err == nil || client.IsNotFound(err) {
+		return nil
+	}
+	return diag.FromErr(err)
+}
+
+// setAll sets resource attributes, stopping at the first that fails
+func setAll(d *schema.ResourceData, values map[string]interface{}) diag.Diagnostics {
+	for key, value := range values {
+		if err := d.Set(key, value); err != nil {
+			return diag.Errorf("failed to set %s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// validateDuration checks an attribute parses as a duration such as 5m
+func validateDuration(value interface{}, key string) ([]string, []error) {
+	if _, err := time.ParseDuration(value.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%s must be a duration such as 5m: %v", key, err)}
+	}
+	return nil, nil
+}
+
+// equalDurations suppresses diffs between spellings of the same duration,
+// such as 5m and 5m0s
+func equalDurations(key, old, new string, d *schema.ResourceData) bool {
+	if old == "" || new == "" {
+		return old == new
+	}
+	oldDuration, oldErr := time.ParseDuration(old)
+	newDuration, newErr := time.ParseDuration(new)
+	return oldErr == nil && newErr == nil && oldDuration == newDuration
+}
+
+// equalTimes suppresses diffs between RFC 3339 spellings of the same
+// instant, such as in different time zones
+func equalTimes(key, old, new string, d *schema.ResourceData) bool {
+	if old == "" || new == "" {
+		return old == new
+	}
+	oldTime, oldErr := time.Parse(time.RFC3339, old)
+	newTime, newErr := time.Parse(time.RFC3339, new)
+	return oldErr == nil && newErr == nil && oldTime.Equal(newTime)
+}
@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/client"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+)
+
+func TestProvider(t *testing.T) {
+	if err := New("test")().InternalValidate(); err != nil {
+		t.Fatalf("Expected a valid provider schema, got %v", err)
+	}
+}
+
+func TestAlertRuleLifecycle(t *testing.T) {
+	rules := make(map[string]observability.GPUThresholdProfile)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path[len("/api/v1/resources/alert-rules/"):]
+		switch r.Method {
+		case http.MethodPut:
+			var rule observability.GPUThresholdProfile
+			json.NewDecoder(r.Body).Decode(&rule)
+			rules[name] = rule
+			json.NewEncoder(w).Encode(rule)
+		case http.MethodGet:
+			rule, exists := rules[name]
+			if !exists {
+				http.NotFound(w, r)
+				return
+			}
+			json.NewEncoder(w).Encode(rule)
+		case http.MethodDelete:
+			delete(rules, name)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+	c, err := client.New(client.DefaultConfig(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	resource := resourceAlertRule()
+	d := schema.TestResourceDataRaw(t, resource.Schema, map[string]interface{}{
+		"name":             "h100",
+		"pattern":          "(?i)h100",
+		"high_temperature": 80.0,
+		"for":              "5m",
+	})
+	if diags := resource.CreateContext(ctx, d, c); diags.HasError() {
+		t.Fatalf("Expected the rule created, got %v", diags)
+	}
+	rule := rules["h100"]
+	if d.Id() != "h100" || rule.Thresholds.HighTemperature != 80 || rule.Thresholds.For != 5*time.Minute {
+		t.Errorf("Expected the rule stored with its thresholds, got %q %+v", d.Id(), rule)
+	}
+
+	// A rule deleted outside Terraform leaves state so it is recreated
+	delete(rules, "h100")
+	if diags := resource.ReadContext(ctx, d, c); diags.HasError() || d.Id() != "" {
+		t.Errorf("Expected the rule removed from state, got %q %v", d.Id(), diags)
+	}
+}
+
+func TestEqualDurations(t *testing.T) {
+	if !equalDurations("for", "5m0s", "5m", nil) || equalDurations("for", "5m0s", "6m", nil) || equalDurations("for", "", "5m", nil) {
+		t.Error("Expected only spellings of the same duration to be equal")
+	}
+}
@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/client"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+)
+
+// thresholdFields maps alert rule attributes to the thresholds they set
+var thresholdFields = map[string]func(*observability.GPUAlertThresholds) *float64{
+	"high_temperature":      func(t *observability.GPUAlertThresholds) *float64 { return &t.HighTemperature },
+	"critical_temperature":  func(t *observability.GPUAlertThresholds) *float64 { return &t.CriticalTemperature },
+	"high_memory_usage":     func(t *observability.GPUAlertThresholds) *float64 { return &t.HighMemoryUsage },
+	"critical_memory_usage": func(t *observability.GPUAlertThresholds) *float64 { return &t.CriticalMemoryUsage },
+	"high_power_usage":      func(t *observability.GPUAlertThresholds) *float64 { return &t.HighPowerUsage },
+	"critical_power_usage":  func(t *observability.GPUAlertThresholds) *float64 { return &t.CriticalPowerUsage },
+	"low_utilization":       func(t *observability.GPUAlertThresholds) *float64 { return &t.LowUtilization },
+	"high_utilization":      func(t *observability.GPUAlertThresholds) *float64 { return &t.HighUtilization },
+	"clear_temperature":     func(t *observability.GPUAlertThresholds) *float64 { return &t.ClearTemperature },
+	"clear_memory_usage":    func(t *observability.GPUAlertThresholds) *float64 { return &t.ClearMemoryUsage },
+	"clear_power_usage":     func(t *observability.GPUAlertThresholds) *float64 { return &t.ClearPowerUsage },
+}
+
+func resourceAlertRule() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Required:    true,
+			ForceNew:    true,
+			Description: "Name of the rule, reported in alert metadata.",
+		},
+		"pattern": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Regular expression matched against GPU names, e.g. (?i)h100.",
+		},
+		"for": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			Description:      "How long a breach must hold before the alert fires, e.g. 5m.",
+			ValidateFunc:     validateDuration,
+			DiffSuppressFunc: equalDurations,
+		},
+	}
+	for name := range thresholdFields {
+		s[name] = &schema.Schema{
+			Type:        schema.TypeFloat,
+			Optional:    true,
+			Description: "Threshold in percent, or degrees Celsius for temperatures; 0 disables it.",
+		}
+	}
+
+	return &schema.Resource{
+		Description:   "Alert thresholds for GPU models whose name matches a pattern. Rules are matched in the order they were created.",
+		CreateContext: resourceAlertRulePut,
+		ReadContext:   resourceAlertRuleRead,
+		UpdateContext: resourceAlertRulePut,
+		DeleteContext: resourceAlertRuleDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: s,
+	}
+}
+
+func resourceAlertRulePut(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	rule := observability.GPUThresholdProfile{
+		Name:    d.Get("name").(string),
+		Pattern: d.Get("pattern").(string),
+	}
+	for name, field := range thresholdFields {
+		*field(&rule.Thresholds) = d.Get(name).(float64)
+	}
+	if value := d.Get("for").(string); value != "" {
+		rule.Thresholds.For, _ = time.ParseDuration(value)
+	}
+
+	if _, err := meta.(*client.Client).PutAlertRule(ctx, rule); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(rule.Name)
+	return resourceAlertRuleRead(ctx, d, meta)
+}
+
+func resourceAlertRuleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	rule, err := meta.(*client.Client).AlertRule(ctx, d.Id())
+	if err != nil {
+		return notFound(d, err)
+	}
+
+	values := map[string]interface{}{
+		"name":    rule.Name,
+		"pattern": rule.Pattern,
+		"for":     "",
+	}
+	if rule.Thresholds.For > 0 {
+		values["for"] = rule.Thresholds.For.String()
+	}
+	for name, field := range thresholdFields {
+		values[name] = *field(&rule.Thresholds)
+	}
+	return setAll(d, values)
+}
+
+func resourceAlertRuleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return ignoreNotFound(meta.(*client.Client).DeleteAlertRule(ctx, d.Id()))
+}
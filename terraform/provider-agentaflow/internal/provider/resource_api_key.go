@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/apikeys"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/client"
+)
+
+func resourceAPIKey() *schema.Resource {
+	return &schema.Resource{
+		Description:   "An API key. Destroying it revokes the key, which stays listed by the dashboard for audit.",
+		CreateContext: resourceAPIKeyCreate,
+		ReadContext:   resourceAPIKeyRead,
+		UpdateContext: resourceAPIKeyUpdate,
+		DeleteContext: resourceAPIKeyDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"scopes": {
+				Type:     schema.TypeSet,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+					ValidateFunc: validation.StringInSlice([]string{
+						string(apikeys.ScopeReadMetrics), string(apikeys.ScopeSubmitWorkloads), string(apikeys.ScopePushMetrics), string(apikeys.ScopeWriteAnnotations), string(apikeys.ScopeAdmin),
+					}, false),
+				},
+			},
+			"tenant": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Tenant the key acts for; every tenant when unset.",
+			},
+			"expires_at": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "RFC 3339 time the key stops working; never when unset.",
+				ValidateFunc:     validation.IsRFC3339Time,
+				DiffSuppressFunc: equalTimes,
+			},
+			"prefix": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Start of the secret, to tell keys apart.",
+			},
+			"secret": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The key's secret. Only known for keys created by Terraform, not imported ones.",
+			},
+			"created_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// apiKeyRequest builds the create or update request from the configuration
+func apiKeyRequest(d *schema.ResourceData) apikeys.KeyRequest {
+	request := apikeys.KeyRequest{
+		Name:   d.Get("name").(string),
+		Tenant: d.Get("tenant").(string),
+	}
+	for _, scope := range d.Get("scopes").(*schema.Set).List() {
+		request.Scopes = append(request.Scopes, apikeys.Scope(scope.(string)))
+	}
+	if value := d.Get("expires_at").(string); value != "" {
+		expiresAt, _ := time.Parse(time.RFC3339, value)
+		request.ExpiresAt = &expiresAt
+	}
+	return request
+}
+
+func resourceAPIKeyCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	key, secret, err := meta.(*client.Client).CreateAPIKey(ctx, apiKeyRequest(d))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(key.ID)
+	if err := d.Set("secret", secret); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceAPIKeyRead(ctx, d, meta)
+}
+
+// resourceAPIKeyRead treats a revoked key as deleted so the next plan
+// creates a replacement
+func resourceAPIKeyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	key, err := meta.(*client.Client).APIKey(ctx, d.Id())
+	if err != nil {
+		return notFound(d, err)
+	}
+	if key.RevokedAt != nil {
+		d.SetId("")
+		return nil
+	}
+
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	values := map[string]interface{}{
+		"name":       key.Name,
+		"scopes":     scopes,
+		"tenant":     key.Tenant,
+		"prefix":     key.Prefix,
+		"created_at": key.CreatedAt.Format(time.RFC3339),
+		"expires_at": "",
+	}
+	if key.ExpiresAt != nil {
+		values["expires_at"] = key.ExpiresAt.Format(time.RFC3339)
+	}
+	return setAll(d, values)
+}
+
+func resourceAPIKeyUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if _, err := meta.(*client.Client).UpdateAPIKey(ctx, d.Id(), apiKeyRequest(d)); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceAPIKeyRead(ctx, d, meta)
+}
+
+func resourceAPIKeyDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	_, err := meta.(*client.Client).RevokeAPIKey(ctx, d.Id())
+	return ignoreNotFound(err)
+}
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/client"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+)
+
+func resourceBudget() *schema.Resource {
+	return &schema.Resource{
+		Description:   "A monthly limit on what a showback team is charged for GPU time.",
+		CreateContext: resourceBudgetPut,
+		ReadContext:   resourceBudgetRead,
+		UpdateContext: resourceBudgetPut,
+		DeleteContext: resourceBudgetDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Budget name.",
+			},
+			"team": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Showback team the budget applies to.",
+			},
+			"monthly_limit": {
+				Type:         schema.TypeFloat,
+				Required:     true,
+				Description:  "Most the team may be charged in a month, in the pricing currency.",
+				ValidateFunc: validation.FloatAtLeast(0.01),
+			},
+			"alert_at_percent": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Description:  "Percentage of the limit at which the budget alerts; 80 when unset.",
+				ValidateFunc: validation.FloatBetween(0, 100),
+			},
+			"currency": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"spent": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "What the team was charged this month when last refreshed.",
+			},
+			"percent_used": {
+				Type:     schema.TypeFloat,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceBudgetPut(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	budget := observability.Budget{
+		Name:           d.Get("name").(string),
+		Team:           d.Get("team").(string),
+		MonthlyLimit:   d.Get("monthly_limit").(float64),
+		AlertAtPercent: d.Get("alert_at_percent").(float64),
+	}
+	if _, err := meta.(*client.Client).PutBudget(ctx, budget); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(budget.Name)
+	return resourceBudgetRead(ctx, d, meta)
+}
+
+func resourceBudgetRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	budget, err := meta.(*client.Client).Budget(ctx, d.Id())
+	if err != nil {
+		return notFound(d, err)
+	}
+	return setAll(d, map[string]interface{}{
+		"name":             budget.Name,
+		"team":             budget.Team,
+		"monthly_limit":    budget.MonthlyLimit,
+		"alert_at_percent": budget.AlertAtPercent,
+		"currency":         budget.Currency,
+		"spent":            budget.Spent,
+		"percent_used":     budget.PercentUsed,
+	})
+}
+
+func resourceBudgetDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return ignoreNotFound(meta.(*client.Client).DeleteBudget(ctx, d.Id()))
+}
@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/client"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+)
+
+func resourceModel() *schema.Resource {
+	return &schema.Resource{
+		Description:   "A model registered for serving.",
+		CreateContext: resourceModelPut,
+		ReadContext:   resourceModelRead,
+		UpdateContext: resourceModelPut,
+		DeleteContext: resourceModelDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"model_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID inference requests name the model by.",
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"version": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"framework": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Serving framework, e.g. pytorch or onnx.",
+			},
+			"memory_size_mb": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "GPU memory the model needs once loaded.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"tenant": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "Team or namespace owning the model; the default tenant when unset.",
+			},
+			"source_workload": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Training workload the model was built by.",
+			},
+			"source_artifact": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "URI of the artifact the model was built from.",
+			},
+			"loaded_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceModelPut(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	model := observability.ModelRegistration{
+		ID:             d.Get("model_id").(string),
+		Name:           d.Get("name").(string),
+		Version:        d.Get("version").(string),
+		Framework:      d.Get("framework").(string),
+		MemorySize:     uint64(d.Get("memory_size_mb").(int)),
+		Tenant:         d.Get("tenant").(string),
+		SourceWorkload: d.Get("source_workload").(string),
+		SourceArtifact: d.Get("source_artifact").(string),
+	}
+	if _, err := meta.(*client.Client).RegisterModel(ctx, model); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(model.ID)
+	return resourceModelRead(ctx, d, meta)
+}
+
+func resourceModelRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	model, err := meta.(*client.Client).Model(ctx, d.Id())
+	if err != nil {
+		return notFound(d, err)
+	}
+	return setAll(d, map[string]interface{}{
+		"model_id":        model.ID,
+		"name":            model.Name,
+		"version":         model.Version,
+		"framework":       model.Framework,
+		"memory_size_mb":  int(model.MemorySize),
+		"tenant":          model.Tenant,
+		"source_workload": model.SourceWorkload,
+		"source_artifact": model.SourceArtifact,
+		"loaded_at":       model.LoadedAt.Format(time.RFC3339),
+	})
+}
+
+func resourceModelDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return ignoreNotFound(meta.(*client.Client).UnregisterModel(ctx, d.Id()))
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/client"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/gpu"
+	"github.com/Finoptimize/agentaflow-sro-community/pkg/observability"
+)
+
+func resourcePool() *schema.Resource {
+	return &schema.Resource{
+		Description:   "A scheduler pool with its own strategy and GPU and memory limits.",
+		CreateContext: resourcePoolPut,
+		ReadContext:   resourcePoolRead,
+		UpdateContext: resourcePoolPut,
+		DeleteContext: resourcePoolDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Pool name, as set in workloads' pool field.",
+			},
+			"strategy": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Scheduling strategy of the pool; the scheduler's when unset.",
+				ValidateFunc: validation.StringInSlice([]string{
+					string(gpu.StrategyRoundRobin), string(gpu.StrategyLeastUtilized), string(gpu.StrategyBestFit),
+					string(gpu.StrategyPriority), string(gpu.StrategyEnergyAware),
+				}, false),
+			},
+			"max_gpus": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "Most GPUs the pool's workloads may occupy; 0 for no limit.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"max_memory_mb": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "Most GPU memory the pool's workloads may hold; 0 for no limit.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+		},
+	}
+}
+
+func resourcePoolPut(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	pool := observability.PoolResource{Name: d.Get("name").(string)}
+	pool.Strategy = gpu.SchedulingStrategy(d.Get("strategy").(string))
+	pool.MaxGPUs = d.Get("max_gpus").(int)
+	pool.MaxMemoryMB = uint64(d.Get("max_memory_mb").(int))
+
+	if _, err := meta.(*client.Client).PutPool(ctx, pool); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(pool.Name)
+	return resourcePoolRead(ctx, d, meta)
+}
+
+func resourcePoolRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	pool, err := meta.(*client.Client).PoolConfig(ctx, d.Id())
+	if err != nil {
+		return notFound(d, err)
+	}
+	return setAll(d, map[string]interface{}{
+		"name":          pool.Name,
+		"strategy":      string(pool.Strategy),
+		"max_gpus":      pool.MaxGPUs,
+		"max_memory_mb": int(pool.MaxMemoryMB),
+	})
+}
+
+func resourcePoolDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return ignoreNotFound(meta.(*client.Client).DeletePool(ctx, d.Id()))
+}
@@ -0,0 +1,26 @@
+// Command terraform-provider-agentaflow is a Terraform provider managing
+// AgentaFlow alert rules, pools, budgets, model registrations and API keys
+// through the dashboard API.
+package main
+
+import (
+	"flag"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+
+	"github.com/Finoptimize/agentaflow-sro-community/terraform/provider-agentaflow/internal/provider"
+)
+
+// version is set by the release build
+var version = "dev"
+
+func main() {
+	debug := flag.Bool("debug", false, "Run the provider with support for debuggers like delve")
+	flag.Parse()
+
+	plugin.Serve(&plugin.ServeOpts{
+		ProviderFunc: provider.New(version),
+		ProviderAddr: "registry.terraform.io/finoptimize/agentaflow",
+		Debug:        *debug,
+	})
+}